@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DoneSignalKind names a kind of evidence that an agent finished its turn.
+type DoneSignalKind string
+
+const (
+	// DoneFinalAnswer: the agent's last output is a closing message rather
+	// than a tool call (or the CLI printed its end-of-turn marker).
+	DoneFinalAnswer DoneSignalKind = "final_answer"
+	// DonePromptReturned: the CLI is back at its input prompt.
+	DonePromptReturned DoneSignalKind = "prompt_returned"
+	// DoneCostSummary: the CLI printed its usage or cost summary.
+	DoneCostSummary DoneSignalKind = "cost_summary"
+	// DoneSelfReport: the agent's latest NTM-REPORT says it is done.
+	DoneSelfReport DoneSignalKind = "self_report"
+)
+
+// DoneSignal is one piece of evidence that an agent finished its turn.
+type DoneSignal struct {
+	Kind     DoneSignalKind `json:"kind"`
+	Evidence string         `json:"evidence,omitempty"` // The line that matched
+}
+
+// Strong reports whether the signal indicates a finished turn on its own.
+// A returned prompt alone may just mean the agent stopped to ask something.
+func (s DoneSignal) Strong() bool {
+	return s.Kind != DonePromptReturned
+}
+
+// doneTailLines is how many trailing non-empty lines are searched for
+// end-of-turn markers; older ones may belong to an earlier turn.
+const doneTailLines = 15
+
+// doneSignalPatterns are the per-agent end-of-turn markers.
+type doneSignalPatterns struct {
+	// busy matches the CLI's in-progress indicator; while it shows, the
+	// agent is not done whatever else is on screen.
+	busy *regexp.Regexp
+	// turnEnd matches a marker the CLI prints when a turn finishes.
+	turnEnd *regexp.Regexp
+	// cost matches the usage or cost summary.
+	cost *regexp.Regexp
+	// message and tools tell the agent's prose apart from its tool calls,
+	// to find whether the last thing it did was answer.
+	message *regexp.Regexp
+	tools   func(lines []string) []ToolCall
+}
+
+var doneSignalSets = map[AgentType]doneSignalPatterns{
+	AgentTypeClaudeCode: {
+		busy:    regexp.MustCompile(`(?i)esc to interrupt|\S+…\s+\(\d+s`),
+		cost:    regexp.MustCompile(`(?i)total cost:\s*\$\d|total duration \(api\)`),
+		message: regexp.MustCompile(`^[⏺●]\s`),
+		tools:   parseClaudeToolCalls,
+	},
+	AgentTypeCodex: {
+		busy:    regexp.MustCompile(`(?i)esc to interrupt|^•\s*Working\s*\(`),
+		turnEnd: regexp.MustCompile(`^─*\s*Worked for\s+\d`),
+		cost:    codTokenPattern,
+	},
+	AgentTypeGemini: {
+		busy:    regexp.MustCompile(`(?i)esc to cancel`),
+		cost:    regexp.MustCompile(`(?i)interaction summary|session stats|agent powering down`),
+		message: regexp.MustCompile(`^✦\s`),
+		tools:   parseGeminiToolCalls,
+	},
+}
+
+// DetectDoneSignals returns the evidence in output that an agent of the
+// given type has finished its turn. Nothing is returned while the CLI shows
+// it is still working. Unknown types only get prompt and self-report
+// signals. Output may contain ANSI codes.
+func DetectDoneSignals(agentType AgentType, output string) []DoneSignal {
+	output = stripANSICodes(output)
+	tail := lastNonEmptyLines(output, doneTailLines)
+	if len(tail) == 0 {
+		return nil
+	}
+	set, known := doneSignalSets[agentType]
+	if known && set.busy != nil {
+		for _, line := range tail {
+			if set.busy.MatchString(line) {
+				return nil
+			}
+		}
+	}
+
+	var signals []DoneSignal
+	add := func(kind DoneSignalKind, evidence string) {
+		signals = append(signals, DoneSignal{Kind: kind, Evidence: strings.TrimSpace(evidence)})
+	}
+
+	if known {
+		for _, line := range tail {
+			trimmed := strings.TrimSpace(line)
+			if set.turnEnd != nil && set.turnEnd.MatchString(trimmed) {
+				add(DoneFinalAnswer, trimmed)
+			}
+			if set.cost != nil && set.cost.MatchString(trimmed) {
+				add(DoneCostSummary, trimmed)
+			}
+		}
+		if set.message != nil {
+			if last := lastMessage(output, set); last != "" {
+				add(DoneFinalAnswer, last)
+			}
+		}
+	}
+
+	if state, err := NewParser().ParseWithHint(output, agentType); err == nil && state.IsIdle && !state.IsRateLimited {
+		add(DonePromptReturned, tail[len(tail)-1])
+	}
+
+	if reports := ParseReports(strings.Join(tail, "\n")); len(reports) > 0 && reports[len(reports)-1].Status == ReportDone {
+		r := reports[len(reports)-1]
+		add(DoneSelfReport, r.Summary)
+	}
+	return signals
+}
+
+// lastMessage returns the agent's last prose line if no tool call follows
+// it.
+func lastMessage(output string, set doneSignalPatterns) string {
+	lines := strings.Split(output, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		if len(set.tools([]string{trimmed})) > 0 {
+			return ""
+		}
+		if set.message.MatchString(trimmed) {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+func lastNonEmptyLines(output string, n int) []string {
+	lines := strings.Split(output, "\n")
+	var tail []string
+	for i := len(lines) - 1; i >= 0 && len(tail) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			tail = append(tail, lines[i])
+		}
+	}
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+	return tail
+}
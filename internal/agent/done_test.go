@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func doneKinds(signals []DoneSignal) string {
+	var kinds []string
+	for _, s := range signals {
+		kinds = append(kinds, string(s.Kind))
+	}
+	return strings.Join(kinds, ",")
+}
+
+func TestDetectDoneSignals(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentType AgentType
+		output    string
+		want      string
+	}{
+		{
+			name:      "claude answered and back at prompt",
+			agentType: AgentTypeClaudeCode,
+			output: strings.Join([]string{
+				"⏺ Bash(go test ./...)",
+				"  ⎿  ok  	example.com/pkg	0.2s",
+				"⏺ All tests pass. The parser now handles empty input.",
+				"",
+				"❯ ",
+			}, "\n"),
+			want: "final_answer,prompt_returned",
+		},
+		{
+			name:      "claude still running a tool",
+			agentType: AgentTypeClaudeCode,
+			output: strings.Join([]string{
+				"⏺ All tests pass.",
+				"⏺ Bash(go vet ./...)",
+				"✻ Cogitating… (12s · esc to interrupt)",
+				"│ >                             │",
+			}, "\n"),
+			want: "",
+		},
+		{
+			name:      "claude last output is a tool call",
+			agentType: AgentTypeClaudeCode,
+			output:    "⏺ Looking at the parser.\n⏺ Read(parser.go)\n  ⎿  Read 80 lines\n> ",
+			want:      "prompt_returned",
+		},
+		{
+			name:      "claude exited with cost summary",
+			agentType: AgentTypeClaudeCode,
+			output:    "⏺ Done.\nTotal cost:            $0.4213\nTotal duration (API):  2m 3.1s\n",
+			want:      "cost_summary,cost_summary,final_answer",
+		},
+		{
+			name:      "codex turn finished",
+			agentType: AgentTypeCodex,
+			output: strings.Join([]string{
+				"• Edited internal/a.go (+3 -1)",
+				"─ Worked for 2m 05s ────────────────────────",
+				"• Fixed the nil check in internal/a.go.",
+				"› Ask Codex to do anything",
+				"  82% context left · ? for shortcuts",
+			}, "\n"),
+			want: "final_answer,prompt_returned",
+		},
+		{
+			name:      "codex working",
+			agentType: AgentTypeCodex,
+			output:    "• Working (14s • esc to interrupt)\n› \n",
+			want:      "",
+		},
+		{
+			name:      "gemini answered",
+			agentType: AgentTypeGemini,
+			output: strings.Join([]string{
+				" │ ✔  WriteFile Writing to internal/new.go  │",
+				"✦ I created internal/new.go with the loader.",
+				"> ",
+			}, "\n"),
+			want: "final_answer,prompt_returned",
+		},
+		{
+			name:      "self report done",
+			agentType: AgentTypeUnknown,
+			output:    `NTM-REPORT{"task_id":"bd-3","status":"done","summary":"loader shipped"}` + "\n> ",
+			want:      "prompt_returned,self_report",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := doneKinds(DetectDoneSignals(tt.agentType, tt.output)); got != tt.want {
+				t.Errorf("signals = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoneSignalStrong(t *testing.T) {
+	if (DoneSignal{Kind: DonePromptReturned}).Strong() {
+		t.Error("a returned prompt alone should be weak")
+	}
+	if !(DoneSignal{Kind: DoneCostSummary}).Strong() {
+		t.Error("a cost summary should be strong")
+	}
+}
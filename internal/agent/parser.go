@@ -26,7 +26,8 @@ func NewParserWithConfig(cfg ParserConfig) Parser {
 // 2. Extract quantitative metrics (context %, tokens, memory)
 // 3. Detect qualitative state flags (working, idle, rate limited, error)
 // 4. Calculate confidence score
-// 5. Keep raw sample for debugging
+// 5. Extract the latest self-report, if any
+// 6. Keep raw sample for debugging
 func (p *parserImpl) Parse(output string) (*AgentState, error) {
 	return p.ParseWithHint(output, AgentTypeUnknown)
 }
@@ -56,7 +57,10 @@ func (p *parserImpl) ParseWithHint(output string, hint AgentType) (*AgentState,
 	// Step 4: Calculate confidence
 	state.Confidence = p.calculateConfidence(state)
 
-	// Step 5: Keep sample for debugging (last N chars)
+	// Step 5: Pick up the agent's own latest progress report
+	state.Report = LatestReport(cleanOutput)
+
+	// Step 6: Keep sample for debugging (last N chars)
 	if len(cleanOutput) > p.config.SampleLength {
 		state.RawSample = cleanOutput[len(cleanOutput)-p.config.SampleLength:]
 	} else {
@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// ReportMarker prefixes a self-report block in agent output:
+//
+//	NTM-REPORT{"task_id":"bd-12","percent":40,"status":"working","files_touched":["api.go"]}
+//
+// Agents print one whenever their progress changes, giving the orchestrator
+// a higher-signal status than output heuristics.
+const ReportMarker = "NTM-REPORT"
+
+// Report statuses. Other values are kept as reported (lowercased).
+const (
+	ReportWorking = "working"
+	ReportBlocked = "blocked"
+	ReportDone    = "done"
+	ReportFailed  = "failed"
+)
+
+// maxReportBytes bounds how far past a marker the JSON object may extend.
+const maxReportBytes = 8 * 1024
+
+// ReportProtocol is the instruction to give agents so they self-report.
+// Its example is deliberately not valid JSON so echoing it is not parsed
+// as a report.
+const ReportProtocol = `When your progress changes, print one line of the form:
+NTM-REPORT{"task_id": "<bead or task id>", "percent": <0-100>, "status": "working|blocked|done|failed", "summary": "<one line>", "blockers": ["<what you are waiting on>"], "files_touched": ["<paths>"]}
+Keep it on a single line and omit fields you do not know.`
+
+// ProgressReport is a structured self-report an agent printed.
+type ProgressReport struct {
+	TaskID       string   `json:"task_id,omitempty"`
+	Percent      *float64 `json:"percent,omitempty"` // 0-100
+	Status       string   `json:"status,omitempty"`  // working, blocked, done, failed
+	Summary      string   `json:"summary,omitempty"`
+	Blockers     []string `json:"blockers,omitempty"`
+	FilesTouched []string `json:"files_touched,omitempty"`
+}
+
+// IsBlocked reports whether the agent says it cannot make progress.
+func (r *ProgressReport) IsBlocked() bool {
+	return r.Status == ReportBlocked || (r.Status != ReportDone && len(r.Blockers) > 0)
+}
+
+var reportMarkerRe = regexp.MustCompile(ReportMarker + `:?[ \t]*\{`)
+
+// continuationIndentRe matches a line break plus the indentation terminals
+// and agent UIs add to wrapped lines.
+var continuationIndentRe = regexp.MustCompile(`\r?\n[ \t│┃|]*`)
+
+// ParseReports returns the self-reports in output, oldest first. Blocks
+// that are not valid JSON (including ones cut off by the capture window)
+// are skipped. Output is expected to be free of ANSI codes.
+func ParseReports(output string) []ProgressReport {
+	var reports []ProgressReport
+	for _, loc := range reportMarkerRe.FindAllStringIndex(output, -1) {
+		start := loc[1] - 1 // the opening brace
+		body, ok := jsonObjectAt(output[start:])
+		if !ok {
+			continue
+		}
+		if r, ok := decodeReport(body); ok {
+			reports = append(reports, r)
+		}
+	}
+	return reports
+}
+
+// LatestReport returns the most recent self-report in output, or nil.
+func LatestReport(output string) *ProgressReport {
+	reports := ParseReports(output)
+	if len(reports) == 0 {
+		return nil
+	}
+	return &reports[len(reports)-1]
+}
+
+// jsonObjectAt returns the balanced {...} object at the start of s.
+func jsonObjectAt(s string) (string, bool) {
+	if len(s) > maxReportBytes {
+		s = s[:maxReportBytes]
+	}
+	depth := 0
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return s[:i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// decodeReport parses a report body. Long reports are often wrapped by the
+// terminal, so on failure it retries with the line breaks and continuation
+// indentation removed.
+func decodeReport(body string) (ProgressReport, bool) {
+	candidates := []string{body}
+	if strings.Contains(body, "\n") {
+		candidates = append(candidates, continuationIndentRe.ReplaceAllString(body, ""))
+	}
+	for _, c := range candidates {
+		var r ProgressReport
+		if err := json.Unmarshal([]byte(c), &r); err != nil {
+			continue
+		}
+		r.normalize()
+		if r.empty() {
+			return ProgressReport{}, false
+		}
+		return r, true
+	}
+	return ProgressReport{}, false
+}
+
+func (r *ProgressReport) normalize() {
+	r.TaskID = strings.TrimSpace(r.TaskID)
+	r.Status = strings.ToLower(strings.TrimSpace(r.Status))
+	r.Summary = strings.TrimSpace(r.Summary)
+	if r.Percent != nil {
+		p := *r.Percent
+		if p < 0 {
+			p = 0
+		} else if p > 100 {
+			p = 100
+		}
+		r.Percent = &p
+	}
+	r.Blockers = compactStrings(r.Blockers)
+	r.FilesTouched = compactStrings(r.FilesTouched)
+}
+
+func (r *ProgressReport) empty() bool {
+	return r.TaskID == "" && r.Percent == nil && r.Status == "" && r.Summary == "" &&
+		len(r.Blockers) == 0 && len(r.FilesTouched) == 0
+}
+
+// compactStrings trims entries and drops empty ones.
+func compactStrings(list []string) []string {
+	var out []string
+	for _, s := range list {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseReports(t *testing.T) {
+	output := strings.Join([]string{
+		`⏺ Working on the API`,
+		`NTM-REPORT{"task_id":"bd-12","percent":40,"status":"Working","files_touched":["api.go"," "]}`,
+		`some output with a { brace`,
+		`NTM-REPORT: {"task_id":"bd-12","percent":140,"status":"blocked","blockers":["waiting on bd-9"],"summary":"needs schema"}`,
+		ReportProtocol,
+		`NTM-REPORT{"task_id":"bd-13"`, // cut off
+	}, "\n")
+
+	reports := ParseReports(output)
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2: %+v", len(reports), reports)
+	}
+	first := reports[0]
+	if first.TaskID != "bd-12" || first.Status != ReportWorking || *first.Percent != 40 {
+		t.Errorf("first = %+v", first)
+	}
+	if len(first.FilesTouched) != 1 || first.FilesTouched[0] != "api.go" {
+		t.Errorf("files = %v", first.FilesTouched)
+	}
+	second := reports[1]
+	if *second.Percent != 100 {
+		t.Errorf("percent = %v, want clamped to 100", *second.Percent)
+	}
+	if !second.IsBlocked() || second.Summary != "needs schema" {
+		t.Errorf("second = %+v", second)
+	}
+
+	latest := LatestReport(output)
+	if latest == nil || latest.Status != ReportBlocked {
+		t.Errorf("LatestReport = %+v", latest)
+	}
+}
+
+func TestParseReportsWrapped(t *testing.T) {
+	output := "NTM-REPORT{\"task_id\":\"bd-7\",\"status\":\"done\",\"summary\":\"migrated the sto\n  rage layer\"}\n"
+	r := LatestReport(output)
+	if r == nil || r.Summary != "migrated the storage layer" {
+		t.Fatalf("LatestReport = %+v", r)
+	}
+	if r.IsBlocked() {
+		t.Error("done report should not be blocked")
+	}
+}
+
+func TestParseReportsIgnoresEmptyAndInvalid(t *testing.T) {
+	for _, output := range []string{
+		`NTM-REPORT{}`,
+		`NTM-REPORT{"unknown":"field"}`,
+		`NTM-REPORT{not json}`,
+		`no marker {"task_id":"bd-1"}`,
+	} {
+		if r := ParseReports(output); len(r) != 0 {
+			t.Errorf("ParseReports(%q) = %+v, want none", output, r)
+		}
+	}
+}
+
+func TestParserSetsReport(t *testing.T) {
+	state, err := NewParser().Parse("building...\nNTM-REPORT{\"task_id\":\"bd-3\",\"percent\":75}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Report == nil || state.Report.TaskID != "bd-3" {
+		t.Errorf("Report = %+v", state.Report)
+	}
+}
@@ -0,0 +1,277 @@
+package agent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ToolKind classifies what a tool call does.
+type ToolKind string
+
+const (
+	ToolRead   ToolKind = "read"
+	ToolEdit   ToolKind = "edit"
+	ToolWrite  ToolKind = "write" // Creates or overwrites a file
+	ToolDelete ToolKind = "delete"
+	ToolShell  ToolKind = "shell"
+	ToolSearch ToolKind = "search"
+	ToolOther  ToolKind = "other"
+)
+
+// ToolCall is a tool invocation an agent CLI printed in its output.
+type ToolCall struct {
+	Tool  string   `json:"tool"` // Name as printed (Read, Update, Edited, WriteFile)
+	Kind  ToolKind `json:"kind"`
+	Args  string   `json:"args,omitempty"`
+	Files []string `json:"files,omitempty"` // Target files named by the call
+}
+
+// Modifies reports whether the call changes the files it names.
+func (c ToolCall) Modifies() bool {
+	return c.Kind == ToolEdit || c.Kind == ToolWrite || c.Kind == ToolDelete
+}
+
+// Claude Code (cc) tool call formats.
+var (
+	// ccToolCallPattern matches a tool header, whose arguments may be
+	// truncated without the closing parenthesis.
+	// Examples: "⏺ Read(internal/agent/parser.go)", "● Bash(go test ./...)"
+	ccToolCallPattern = regexp.MustCompile(`^[⏺●]\s*([A-Z][A-Za-z]+)\((.*?)\)?$`)
+
+	// ccFileArgPattern extracts the path from keyword arguments.
+	// Example: `file_path: "/repo/main.go", limit: 50`
+	ccFileArgPattern = regexp.MustCompile(`(?:file_path|notebook_path|path):\s*"([^"]+)"`)
+
+	ccToolKinds = map[string]ToolKind{
+		"Read":         ToolRead,
+		"Edit":         ToolEdit,
+		"MultiEdit":    ToolEdit,
+		"Update":       ToolEdit,
+		"NotebookEdit": ToolEdit,
+		"Write":        ToolWrite,
+		"Create":       ToolWrite,
+		"Bash":         ToolShell,
+		"Grep":         ToolSearch,
+		"Glob":         ToolSearch,
+		"Search":       ToolSearch,
+		"List":         ToolSearch,
+	}
+)
+
+// Codex CLI (cod) tool call formats.
+var (
+	// codToolCallPattern matches an action header.
+	// Examples: "• Ran go test ./...", "• Edited main.go (+3 -1)"
+	codToolCallPattern = regexp.MustCompile(`^•\s*(Ran|Edited|Added|Deleted|Explored)\b\s*(.*)$`)
+
+	// codDetailPattern matches a detail line under an action.
+	// Examples: "└ Read parser.go, types.go", "  Search LikelyModifiers in internal"
+	codDetailPattern = regexp.MustCompile(`^(?:└\s*)?(Read\b|Search\b|List\b|[^\s]+\s\(\+\d+\s-\d+\))\s*(.*)$`)
+
+	// codDiffStatPattern strips a trailing diff stat from an edited path.
+	codDiffStatPattern = regexp.MustCompile(`\s*\(\+\d+\s+-\d+\)$`)
+
+	// codFileCountPattern matches a multi-file edit header: "3 files (+12 -4)".
+	codFileCountPattern = regexp.MustCompile(`^\d+ files?\b`)
+
+	codToolKinds = map[string]ToolKind{
+		"Ran":     ToolShell,
+		"Edited":  ToolEdit,
+		"Added":   ToolWrite,
+		"Deleted": ToolDelete,
+		"Read":    ToolRead,
+		"Search":  ToolSearch,
+		"List":    ToolSearch,
+	}
+)
+
+// Gemini CLI (gmi) tool call formats.
+var (
+	// gmiToolCallPattern matches a tool box status line.
+	// Examples: "│ ✔  ReadFile internal/main.go │", "│ ✔  Shell go test ./... │"
+	gmiToolCallPattern = regexp.MustCompile(`^│?\s*[✔✓✗x⊷?o]\s+([A-Z][A-Za-z]+)\s*(.*?)\s*│?$`)
+
+	// gmiWritePattern extracts the path from WriteFile's "Writing to <path>".
+	gmiWritePattern = regexp.MustCompile(`^Writing to\s+(.+)$`)
+
+	gmiToolKinds = map[string]ToolKind{
+		"ReadFile":      ToolRead,
+		"ReadManyFiles": ToolRead,
+		"Edit":          ToolEdit,
+		"WriteFile":     ToolWrite,
+		"Shell":         ToolShell,
+		"SearchText":    ToolSearch,
+		"FindFiles":     ToolSearch,
+		"ReadFolder":    ToolSearch,
+		"GoogleSearch":  ToolOther,
+		"WebFetch":      ToolOther,
+	}
+)
+
+// ParseToolCalls extracts the tool calls in output, oldest first, using the
+// format of the given agent CLI. For unknown types every format is tried;
+// they do not overlap. Output is expected to be free of ANSI codes.
+func ParseToolCalls(agentType AgentType, output string) []ToolCall {
+	lines := strings.Split(output, "\n")
+	switch agentType {
+	case AgentTypeClaudeCode:
+		return parseClaudeToolCalls(lines)
+	case AgentTypeCodex:
+		return parseCodexToolCalls(lines)
+	case AgentTypeGemini:
+		return parseGeminiToolCalls(lines)
+	case AgentTypeUnknown, "":
+		var calls []ToolCall
+		calls = append(calls, parseClaudeToolCalls(lines)...)
+		calls = append(calls, parseCodexToolCalls(lines)...)
+		calls = append(calls, parseGeminiToolCalls(lines)...)
+		return calls
+	default:
+		return nil
+	}
+}
+
+// ModifiedFiles returns the files the calls changed, in first-seen order.
+func ModifiedFiles(calls []ToolCall) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, c := range calls {
+		if !c.Modifies() {
+			continue
+		}
+		for _, f := range c.Files {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files
+}
+
+func parseClaudeToolCalls(lines []string) []ToolCall {
+	var calls []ToolCall
+	for _, line := range lines {
+		m := ccToolCallPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		kind, ok := ccToolKinds[m[1]]
+		if !ok {
+			kind = ToolOther
+		}
+		call := ToolCall{Tool: m[1], Kind: kind, Args: m[2]}
+		switch kind {
+		case ToolRead, ToolEdit, ToolWrite:
+			path := m[2]
+			if fm := ccFileArgPattern.FindStringSubmatch(path); fm != nil {
+				path = fm[1]
+			}
+			call.Files = toolFiles(path)
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+func parseCodexToolCalls(lines []string) []ToolCall {
+	var calls []ToolCall
+	// Detail lines belong to the last Explored or multi-file Edited header;
+	// any other bullet ends its block.
+	var parent string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "•") && !strings.HasPrefix(trimmed, "›") {
+			if parent != "" {
+				if call, ok := codexDetailCall(parent, trimmed); ok {
+					calls = append(calls, call)
+				}
+			}
+			continue
+		}
+		parent = ""
+		if m := codToolCallPattern.FindStringSubmatch(trimmed); m != nil {
+			tool, args := m[1], m[2]
+			switch {
+			case tool == "Explored":
+				parent = tool
+				continue
+			case tool != "Ran" && (args == "" || codFileCountPattern.MatchString(args)):
+				parent = tool
+				continue
+			}
+			call := ToolCall{Tool: tool, Kind: codToolKinds[tool], Args: args}
+			if call.Modifies() {
+				call.Files = toolFiles(codDiffStatPattern.ReplaceAllString(args, ""))
+			}
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// codexDetailCall parses a detail line under an Explored or multi-file
+// edit header. Diff bodies and other lines are skipped.
+func codexDetailCall(parent, line string) (ToolCall, bool) {
+	m := codDetailPattern.FindStringSubmatch(line)
+	if m == nil {
+		return ToolCall{}, false
+	}
+	switch m[1] {
+	case "Read", "Search", "List":
+		if parent != "Explored" {
+			return ToolCall{}, false
+		}
+		call := ToolCall{Tool: m[1], Kind: codToolKinds[m[1]], Args: m[2]}
+		if m[1] == "Read" {
+			for _, f := range strings.Split(m[2], ",") {
+				call.Files = append(call.Files, toolFiles(f)...)
+			}
+		}
+		return call, true
+	}
+	if parent == "Explored" {
+		return ToolCall{}, false
+	}
+	path := codDiffStatPattern.ReplaceAllString(m[1], "")
+	return ToolCall{Tool: parent, Kind: codToolKinds[parent], Args: m[1], Files: toolFiles(path)}, true
+}
+
+func parseGeminiToolCalls(lines []string) []ToolCall {
+	var calls []ToolCall
+	for _, line := range lines {
+		m := gmiToolCallPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		kind, ok := gmiToolKinds[m[1]]
+		if !ok {
+			continue // Too generic to trust outside a known tool name
+		}
+		call := ToolCall{Tool: m[1], Kind: kind, Args: m[2]}
+		switch m[1] {
+		case "ReadFile":
+			call.Files = toolFiles(m[2])
+		case "WriteFile":
+			if wm := gmiWritePattern.FindStringSubmatch(m[2]); wm != nil {
+				call.Files = toolFiles(wm[1])
+			}
+		case "Edit":
+			// "Edit path: old => new"
+			path, _, _ := strings.Cut(m[2], ": ")
+			call.Files = toolFiles(path)
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// toolFiles cleans a path argument, dropping ones that were truncated or
+// are not a single path.
+func toolFiles(arg string) []string {
+	path := strings.Trim(strings.TrimSpace(arg), `"'`)
+	if path == "" || strings.ContainsAny(path, " \t…") {
+		return nil
+	}
+	return []string{path}
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseToolCallsClaude(t *testing.T) {
+	output := strings.Join([]string{
+		`⏺ I'll look at the parser first.`,
+		`⏺ Read(internal/agent/parser.go)`,
+		`  ⎿  Read 120 lines (ctrl+r to expand)`,
+		`⏺ Update(internal/agent/parser.go)`,
+		`  ⎿  Updated internal/agent/parser.go with 3 additions`,
+		`● Write(file_path: "/repo/internal/agent/toolcalls.go")`,
+		`⏺ Bash(go test ./internal/agent/...)`,
+		`⏺ Edit(internal/very/long/path/that/was/trunc…`,
+		`⏺ Task(Find callers)`,
+	}, "\n")
+
+	calls := ParseToolCalls(AgentTypeClaudeCode, output)
+	var got []string
+	for _, c := range calls {
+		got = append(got, c.Tool+":"+string(c.Kind))
+	}
+	want := []string{"Read:read", "Update:edit", "Write:write", "Bash:shell", "Edit:edit", "Task:other"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	if calls[3].Args != "go test ./internal/agent/..." {
+		t.Errorf("bash args = %q", calls[3].Args)
+	}
+	if calls[4].Files != nil {
+		t.Errorf("truncated path should be dropped, got %v", calls[4].Files)
+	}
+	files := ModifiedFiles(calls)
+	if !reflect.DeepEqual(files, []string{"internal/agent/parser.go", "/repo/internal/agent/toolcalls.go"}) {
+		t.Errorf("ModifiedFiles = %v", files)
+	}
+}
+
+func TestParseToolCallsCodex(t *testing.T) {
+	output := strings.Join([]string{
+		`• Explored`,
+		`  └ Read parser.go, types.go`,
+		`    Search LikelyModifiers in internal`,
+		`• Edited internal/robot/synthesis.go (+12 -3)`,
+		`    436 +func (cd *ConflictDetector) findLikelyModifiers(file GitFileStatus) []string {`,
+		`• Edited 2 files (+5 -1)`,
+		`  └ internal/a.go (+3 -1)`,
+		`    12 +Read more`,
+		`    internal/b.go (+2 -0)`,
+		`• Ran go test ./internal/robot`,
+		`  └ ok  	github.com/Dicklesworthstone/ntm/internal/robot	3.1s`,
+		`• Reading the remaining files now.`,
+		`  internal/c.go (+1 -0)`,
+		`• Deleted old.go (+0 -40)`,
+	}, "\n")
+
+	calls := ParseToolCalls(AgentTypeCodex, output)
+	var got []string
+	for _, c := range calls {
+		got = append(got, c.Tool+":"+strings.Join(c.Files, ","))
+	}
+	want := []string{
+		"Read:parser.go,types.go",
+		"Search:",
+		"Edited:internal/robot/synthesis.go",
+		"Edited:internal/a.go",
+		"Edited:internal/b.go",
+		"Ran:",
+		"Deleted:old.go",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	if calls[5].Kind != ToolShell || calls[5].Args != "go test ./internal/robot" {
+		t.Errorf("ran = %+v", calls[5])
+	}
+	if calls[6].Kind != ToolDelete || !calls[6].Modifies() {
+		t.Errorf("deleted = %+v", calls[6])
+	}
+}
+
+func TestParseToolCallsGemini(t *testing.T) {
+	output := strings.Join([]string{
+		` ╭──────────────────────────────────────────╮`,
+		` │ ✔  ReadFile internal/main.go             │`,
+		` ╰──────────────────────────────────────────╯`,
+		` │ ✔  WriteFile Writing to internal/new.go  │`,
+		` │ ✔  Edit internal/main.go: old => new     │`,
+		` │ ✔  Shell go vet ./... (vet the module)   │`,
+		` │ ✔  Thinking about it                     │`,
+	}, "\n")
+
+	calls := ParseToolCalls(AgentTypeGemini, output)
+	if len(calls) != 4 {
+		t.Fatalf("got %d calls, want 4: %+v", len(calls), calls)
+	}
+	if calls[0].Kind != ToolRead || !reflect.DeepEqual(calls[0].Files, []string{"internal/main.go"}) {
+		t.Errorf("read = %+v", calls[0])
+	}
+	if !reflect.DeepEqual(ModifiedFiles(calls), []string{"internal/new.go", "internal/main.go"}) {
+		t.Errorf("ModifiedFiles = %v", ModifiedFiles(calls))
+	}
+	if calls[3].Kind != ToolShell {
+		t.Errorf("shell = %+v", calls[3])
+	}
+}
+
+func TestParseToolCallsByType(t *testing.T) {
+	output := "⏺ Read(a.go)\n• Edited b.go (+1 -0)\n│ ✔  ReadFile c.go │"
+	if n := len(ParseToolCalls(AgentTypeUnknown, output)); n != 3 {
+		t.Errorf("unknown type: got %d calls, want 3", n)
+	}
+	if n := len(ParseToolCalls(AgentTypeClaudeCode, output)); n != 1 {
+		t.Errorf("claude: got %d calls, want 1", n)
+	}
+	if calls := ParseToolCalls(AgentTypeAider, output); calls != nil {
+		t.Errorf("aider: got %+v, want none", calls)
+	}
+}
@@ -117,6 +117,9 @@ type AgentState struct {
 	LimitIndicators []string `json:"limit_indicators,omitempty"` // Patterns that indicate rate limiting
 	RawSample       string   `json:"raw_sample,omitempty"`       // Last N chars for debugging
 
+	// Report is the latest self-report the agent printed (see ReportMarker).
+	Report *ProgressReport `json:"report,omitempty"`
+
 	// Confidence in this assessment (0.0-1.0)
 	// Higher confidence means more pattern matches or explicit indicators
 	Confidence float64 `json:"confidence"`
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -41,6 +42,12 @@ type Client struct {
 	projectKey  string // Cached project path
 	requestID   atomic.Int64
 
+	// Resilience: retry transient failures, trip a breaker when the backend
+	// is down, and optionally queue outbound writes while offline.
+	retry   RetryPolicy
+	breaker *circuitBreaker
+	outbox  *Outbox
+
 	// Availability cache (30s TTL)
 	healthCheckMu      sync.Mutex
 	availableCache     atomic.Bool
@@ -94,6 +101,8 @@ func WithTimeout(timeout time.Duration) Option {
 func NewClient(opts ...Option) *Client {
 	c := &Client{
 		baseURL: DefaultBaseURL,
+		retry:   DefaultRetryPolicy(),
+		breaker: newCircuitBreaker(DefaultBreakerConfig()),
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 			Transport: &http.Transport{
@@ -123,16 +132,36 @@ func NewClient(opts ...Option) *Client {
 		opt(c)
 	}
 
+	c.applyDefaultBackend()
+
 	return c
 }
 
 // IsAvailable checks if the Agent Mail server is reachable.
-// Results are cached for 30 seconds to avoid repeated health checks.
+// Results are cached for 30 seconds to avoid repeated health checks. When the
+// server becomes reachable, the outbox (if any) is flushed.
 func (c *Client) IsAvailable() bool {
+	available, reconnected := c.checkAvailable()
+
+	// Deliver writes queued while the server was unreachable. This runs after
+	// healthCheckMu is released so a slow backend does not hold up other
+	// availability checks.
+	if reconnected && c.outbox != nil && c.outbox.Len() > 0 {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), DefaultTimeout)
+		defer flushCancel()
+		_, _ = c.FlushOutbox(flushCtx)
+	}
+
+	return available
+}
+
+// checkAvailable returns the cached or freshly checked availability, and
+// whether a fresh check found the server back after it was unreachable.
+func (c *Client) checkAvailable() (available, reconnected bool) {
 	// Optimistic check (lock-free)
 	cacheTime := c.availableCacheTime.Load()
 	if cacheTime > 0 && time.Now().Unix()-cacheTime < int64(AvailabilityCacheTTL.Seconds()) {
-		return c.availableCache.Load()
+		return c.availableCache.Load(), false
 	}
 
 	// Acquire lock to prevent thundering herd
@@ -142,21 +171,22 @@ func (c *Client) IsAvailable() bool {
 	// Double-check after acquiring lock
 	cacheTime = c.availableCacheTime.Load()
 	if cacheTime > 0 && time.Now().Unix()-cacheTime < int64(AvailabilityCacheTTL.Seconds()) {
-		return c.availableCache.Load()
+		return c.availableCache.Load(), false
 	}
+	wasAvailable := cacheTime > 0 && c.availableCache.Load()
 
 	// Perform health check
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
 	_, err := c.HealthCheck(ctx)
-	available := err == nil
+	available = err == nil
 
 	// Cache the result
 	c.availableCache.Store(available)
 	c.availableCacheTime.Store(time.Now().Unix())
 
-	return available
+	return available, available && !wasAvailable
 }
 
 // InvalidateCache clears the availability cache, forcing the next IsAvailable() call
@@ -314,8 +344,21 @@ type ToolCallParams struct {
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
-// callTool makes a JSON-RPC call to the Agent Mail server.
+// callTool makes a JSON-RPC call to the Agent Mail server, retrying transient
+// failures and short-circuiting while the backend is known to be down.
 func (c *Client) callTool(ctx context.Context, toolName string, args map[string]interface{}) (json.RawMessage, error) {
+	result, err := c.doWithResilience(ctx, func() ([]byte, error) {
+		return c.callToolOnce(ctx, toolName, args)
+	})
+	var apiErr *APIError
+	if errors.Is(err, ErrCircuitOpen) && !errors.As(err, &apiErr) {
+		return nil, NewAPIError(toolName, 0, err)
+	}
+	return result, err
+}
+
+// callToolOnce makes a single JSON-RPC call to the Agent Mail server.
+func (c *Client) callToolOnce(ctx context.Context, toolName string, args map[string]interface{}) (json.RawMessage, error) {
 	reqID := c.requestID.Add(1)
 
 	rpcReq := JSONRPCRequest{
@@ -0,0 +1,351 @@
+package agentmail
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+// Backend names accepted by SetDefaultBackend and AGENT_MAIL_BACKEND.
+const (
+	// BackendHTTP talks to an external Agent Mail server (the default).
+	BackendHTTP = "http"
+	// BackendEmbedded uses the built-in SQLite backend under .ntm/.
+	BackendEmbedded = "embedded"
+)
+
+const (
+	// EmbeddedDBFileName is the embedded backend database name under .ntm/.
+	EmbeddedDBFileName = "agentmail.db"
+
+	// EmbeddedBaseURL is the base URL reported by clients using the embedded backend.
+	EmbeddedBaseURL = "embedded://agentmail/mcp/"
+)
+
+var (
+	defaultBackendMu sync.RWMutex
+	defaultBackend   = BackendHTTP
+
+	embeddedMu       sync.Mutex
+	embeddedBackends = make(map[string]*EmbeddedBackend)
+)
+
+// SetDefaultBackend selects the backend used by NewClient when no explicit
+// transport is configured. AGENT_MAIL_BACKEND overrides it.
+func SetDefaultBackend(name string) {
+	defaultBackendMu.Lock()
+	defer defaultBackendMu.Unlock()
+	if name == "" {
+		name = BackendHTTP
+	}
+	defaultBackend = name
+}
+
+// DefaultBackend returns the backend NewClient will use.
+func DefaultBackend() string {
+	if env := os.Getenv("AGENT_MAIL_BACKEND"); env != "" {
+		return env
+	}
+	defaultBackendMu.RLock()
+	defer defaultBackendMu.RUnlock()
+	return defaultBackend
+}
+
+// DefaultEmbeddedPath returns the embedded backend database path for a project.
+func DefaultEmbeddedPath(projectDir string) string {
+	return filepath.Join(projectDir, ".ntm", EmbeddedDBFileName)
+}
+
+// EmbeddedBackend is a built-in Agent Mail backend storing projects, agents,
+// messages and file reservations in SQLite. It answers the same JSON-RPC
+// tool calls as the Agent Mail server and plugs into a Client as its HTTP
+// transport, so every Client method works without a separate service.
+type EmbeddedBackend struct {
+	db   *sql.DB
+	mu   sync.Mutex // serializes writes to avoid SQLITE_BUSY
+	path string
+	now  func() time.Time
+}
+
+// OpenEmbeddedBackend opens or creates the embedded backend database at path.
+func OpenEmbeddedBackend(path string) (*EmbeddedBackend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create embedded agent mail dir: %w", err)
+	}
+
+	dsn := fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=ON", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open embedded agent mail db: %w", err)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping embedded agent mail db: %w", err)
+	}
+	if _, err := db.Exec(embeddedSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate embedded agent mail db: %w", err)
+	}
+
+	return &EmbeddedBackend{db: db, path: path, now: time.Now}, nil
+}
+
+// sharedEmbeddedBackend returns a process-wide backend for path so the many
+// short-lived clients created across ntm share one connection pool.
+func sharedEmbeddedBackend(path string) (*EmbeddedBackend, error) {
+	embeddedMu.Lock()
+	defer embeddedMu.Unlock()
+
+	if b, ok := embeddedBackends[path]; ok {
+		return b, nil
+	}
+	b, err := OpenEmbeddedBackend(path)
+	if err != nil {
+		return nil, err
+	}
+	embeddedBackends[path] = b
+	return b, nil
+}
+
+// Close closes the database.
+func (b *EmbeddedBackend) Close() error {
+	return b.db.Close()
+}
+
+// Path returns the database file path.
+func (b *EmbeddedBackend) Path() string {
+	return b.path
+}
+
+// WithEmbeddedBackend routes all client calls to b instead of an HTTP server.
+func WithEmbeddedBackend(b *EmbeddedBackend) Option {
+	return func(c *Client) {
+		timeout := DefaultTimeout
+		if c.httpClient != nil {
+			timeout = c.httpClient.Timeout
+		}
+		c.baseURL = EmbeddedBaseURL
+		c.httpClient = &http.Client{Transport: b, Timeout: timeout}
+	}
+}
+
+// applyDefaultBackend attaches the shared embedded backend for the client's
+// project when it is the selected backend.
+func (c *Client) applyDefaultBackend() {
+	if c.baseURL == EmbeddedBaseURL || DefaultBackend() != BackendEmbedded {
+		return
+	}
+	dir := c.projectKey
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		dir = wd
+	}
+	b, err := sharedEmbeddedBackend(DefaultEmbeddedPath(dir))
+	if err != nil {
+		// Leave the HTTP transport in place; calls will surface as unavailable.
+		return
+	}
+	WithEmbeddedBackend(b)(c)
+}
+
+// RoundTrip implements http.RoundTripper by answering JSON-RPC and overseer
+// REST requests in-process.
+func (b *EmbeddedBackend) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(req.URL.Path, "/overseer/send") {
+		return b.serveOverseer(req)
+	}
+
+	var rpcReq struct {
+		ID     interface{}     `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&rpcReq); err != nil {
+		return jsonResponse(req, http.StatusBadRequest, map[string]string{"detail": err.Error()})
+	}
+
+	resp := JSONRPCResponse{JSONRPC: "2.0", ID: rpcReq.ID}
+	var (
+		result interface{}
+		err    error
+	)
+	switch rpcReq.Method {
+	case "tools/call":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err = json.Unmarshal(rpcReq.Params, &params); err == nil {
+			result, err = b.callTool(req.Context(), params.Name, toolArgs(params.Arguments))
+			if err == nil {
+				// Wrap in the MCP envelope the HTTP server uses.
+				var data []byte
+				if data, err = json.Marshal(result); err == nil {
+					result = MCPToolResult{StructuredContent: data}
+				}
+			}
+		}
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if err = json.Unmarshal(rpcReq.Params, &params); err == nil {
+			result, err = b.readResource(req.Context(), params.URI)
+		}
+	default:
+		err = &JSONRPCError{Code: -32601, Message: "method not found: " + rpcReq.Method}
+	}
+
+	if err != nil {
+		rpcErr, ok := err.(*JSONRPCError)
+		if !ok {
+			rpcErr = &JSONRPCError{Code: -32603, Message: err.Error()}
+		}
+		resp.Error = rpcErr
+	} else {
+		data, mErr := json.Marshal(result)
+		if mErr != nil {
+			resp.Error = &JSONRPCError{Code: -32603, Message: mErr.Error()}
+		} else {
+			resp.Result = data
+		}
+	}
+	return jsonResponse(req, http.StatusOK, resp)
+}
+
+// serveOverseer handles POST /mail/{slug}/overseer/send.
+func (b *EmbeddedBackend) serveOverseer(req *http.Request) (*http.Response, error) {
+	var body struct {
+		Recipients []string `json:"recipients"`
+		Subject    string   `json:"subject"`
+		BodyMD     string   `json:"body_md"`
+		ThreadID   string   `json:"thread_id"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		return jsonResponse(req, http.StatusBadRequest, map[string]string{"detail": err.Error()})
+	}
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) < 4 {
+		return jsonResponse(req, http.StatusBadRequest, map[string]string{"detail": "missing project slug"})
+	}
+	slug, _ := url.PathUnescape(segments[len(segments)-3])
+
+	result, err := b.sendOverseer(req.Context(), slug, body.Recipients, body.Subject, body.BodyMD, body.ThreadID)
+	if err != nil {
+		return jsonResponse(req, http.StatusBadRequest, map[string]string{"detail": err.Error()})
+	}
+	return jsonResponse(req, http.StatusOK, result)
+}
+
+func jsonResponse(req *http.Request, status int, v interface{}) (*http.Response, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// withTx runs fn in a write transaction.
+func (b *EmbeddedBackend) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+const embeddedSchema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	slug       TEXT NOT NULL UNIQUE,
+	human_key  TEXT NOT NULL UNIQUE,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS agents (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id       INTEGER NOT NULL REFERENCES projects(id),
+	name             TEXT NOT NULL,
+	program          TEXT NOT NULL DEFAULT '',
+	model            TEXT NOT NULL DEFAULT '',
+	task_description TEXT NOT NULL DEFAULT '',
+	inception_ts     TEXT NOT NULL,
+	last_active_ts   TEXT NOT NULL,
+	UNIQUE(project_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id   INTEGER NOT NULL REFERENCES projects(id),
+	sender_id    INTEGER NOT NULL REFERENCES agents(id),
+	thread_id    TEXT,
+	subject      TEXT NOT NULL,
+	body_md      TEXT NOT NULL,
+	importance   TEXT NOT NULL DEFAULT 'normal',
+	ack_required INTEGER NOT NULL DEFAULT 0,
+	created_ts   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_messages_thread ON messages(project_id, thread_id);
+
+CREATE TABLE IF NOT EXISTS message_recipients (
+	message_id INTEGER NOT NULL REFERENCES messages(id),
+	agent_id   INTEGER NOT NULL REFERENCES agents(id),
+	kind       TEXT NOT NULL DEFAULT 'to',
+	read_ts    TEXT,
+	ack_ts     TEXT,
+	PRIMARY KEY (message_id, agent_id)
+);
+CREATE INDEX IF NOT EXISTS idx_recipients_agent ON message_recipients(agent_id);
+
+CREATE TABLE IF NOT EXISTS file_reservations (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id   INTEGER NOT NULL REFERENCES projects(id),
+	agent_id     INTEGER NOT NULL REFERENCES agents(id),
+	path_pattern TEXT NOT NULL,
+	exclusive    INTEGER NOT NULL DEFAULT 1,
+	reason       TEXT NOT NULL DEFAULT '',
+	created_ts   TEXT NOT NULL,
+	expires_ts   TEXT NOT NULL,
+	released_ts  TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_project ON file_reservations(project_id, released_ts);
+`
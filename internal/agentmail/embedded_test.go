@@ -0,0 +1,246 @@
+package agentmail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newEmbeddedTestClient(t *testing.T) (*Client, *EmbeddedBackend, string) {
+	t.Helper()
+	dir := t.TempDir()
+	backend, err := OpenEmbeddedBackend(DefaultEmbeddedPath(dir))
+	if err != nil {
+		t.Fatalf("OpenEmbeddedBackend() error = %v", err)
+	}
+	t.Cleanup(func() { backend.Close() })
+	return NewClient(WithEmbeddedBackend(backend), WithProjectKey(dir)), backend, dir
+}
+
+func registerEmbeddedAgent(t *testing.T, c *Client, project, name string) *Agent {
+	t.Helper()
+	agent, err := c.RegisterAgent(context.Background(), RegisterAgentOptions{
+		ProjectKey: project, Program: "claude-code", Model: "test", Name: name,
+	})
+	if err != nil {
+		t.Fatalf("RegisterAgent(%q) error = %v", name, err)
+	}
+	return agent
+}
+
+func TestEmbeddedBackend_HealthAndProject(t *testing.T) {
+	t.Parallel()
+	c, _, dir := newEmbeddedTestClient(t)
+	ctx := context.Background()
+
+	if !c.IsAvailable() {
+		t.Fatal("embedded backend should always be available")
+	}
+	if c.BaseURL() != EmbeddedBaseURL {
+		t.Errorf("BaseURL() = %q, want %q", c.BaseURL(), EmbeddedBaseURL)
+	}
+
+	p1, err := c.EnsureProject(ctx, dir)
+	if err != nil {
+		t.Fatalf("EnsureProject() error = %v", err)
+	}
+	p2, err := c.EnsureProject(ctx, dir)
+	if err != nil {
+		t.Fatalf("EnsureProject() second call error = %v", err)
+	}
+	if p1.ID != p2.ID || p1.Slug != ProjectSlugFromPath(dir) {
+		t.Errorf("EnsureProject not idempotent or wrong slug: %+v vs %+v", p1, p2)
+	}
+}
+
+func TestEmbeddedBackend_Messaging(t *testing.T) {
+	t.Parallel()
+	c, _, dir := newEmbeddedTestClient(t)
+	ctx := context.Background()
+
+	registerEmbeddedAgent(t, c, dir, "BlueLake")
+	registerEmbeddedAgent(t, c, dir, "GreenCastle")
+
+	sent, err := c.SendMessage(ctx, SendMessageOptions{
+		ProjectKey: dir, SenderName: "BlueLake", To: []string{"GreenCastle"},
+		Subject: "Plan", BodyMD: "Take the parser", Importance: "urgent", ThreadID: "FEAT-1",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if sent.Count != 1 || sent.Deliveries[0].Payload.From != "BlueLake" {
+		t.Fatalf("SendMessage() result = %+v", sent)
+	}
+
+	inbox, err := c.FetchInbox(ctx, FetchInboxOptions{ProjectKey: dir, AgentName: "GreenCastle", UrgentOnly: true, IncludeBodies: true})
+	if err != nil {
+		t.Fatalf("FetchInbox() error = %v", err)
+	}
+	if len(inbox) != 1 || inbox[0].BodyMD != "Take the parser" || inbox[0].ReadAt != nil {
+		t.Fatalf("FetchInbox() = %+v, want one unread message with body", inbox)
+	}
+
+	if err := c.MarkMessageRead(ctx, dir, "GreenCastle", inbox[0].ID); err != nil {
+		t.Fatalf("MarkMessageRead() error = %v", err)
+	}
+	inbox, _ = c.FetchInbox(ctx, FetchInboxOptions{ProjectKey: dir, AgentName: "GreenCastle"})
+	if inbox[0].ReadAt == nil {
+		t.Error("message not marked read")
+	}
+
+	reply, err := c.ReplyMessage(ctx, ReplyMessageOptions{ProjectKey: dir, MessageID: inbox[0].ID, SenderName: "GreenCastle", BodyMD: "On it"})
+	if err != nil {
+		t.Fatalf("ReplyMessage() error = %v", err)
+	}
+	if reply.Subject != "Re: Plan" || len(reply.To) != 1 || reply.To[0] != "BlueLake" || reply.ThreadID == nil || *reply.ThreadID != "FEAT-1" {
+		t.Errorf("ReplyMessage() = %+v", reply)
+	}
+
+	results, err := c.SearchMessages(ctx, SearchOptions{ProjectKey: dir, Query: "parser"})
+	if err != nil || len(results) != 1 {
+		t.Errorf("SearchMessages() = %v, %v; want one result", results, err)
+	}
+
+	_, err = c.SendMessage(ctx, SendMessageOptions{ProjectKey: dir, SenderName: "BlueLake", To: []string{"Nobody"}, Subject: "x", BodyMD: "x"})
+	if !errors.Is(err, ErrAgentNotRegistered) {
+		t.Errorf("send to unknown agent error = %v, want ErrAgentNotRegistered", err)
+	}
+
+	agents, err := c.ListProjectAgents(ctx, dir)
+	if err != nil || len(agents) != 2 {
+		t.Errorf("ListProjectAgents() = %v, %v; want 2 agents", agents, err)
+	}
+}
+
+func TestEmbeddedBackend_Reservations(t *testing.T) {
+	t.Parallel()
+	c, backend, dir := newEmbeddedTestClient(t)
+	ctx := context.Background()
+
+	registerEmbeddedAgent(t, c, dir, "BlueLake")
+	registerEmbeddedAgent(t, c, dir, "GreenCastle")
+
+	res, err := c.ReservePaths(ctx, FileReservationOptions{ProjectKey: dir, AgentName: "BlueLake", Paths: []string{"internal/cli/**"}, Exclusive: true, Reason: "refactor"})
+	if err != nil || len(res.Granted) != 1 {
+		t.Fatalf("ReservePaths() = %+v, %v", res, err)
+	}
+
+	res, err = c.ReservePaths(ctx, FileReservationOptions{ProjectKey: dir, AgentName: "GreenCastle", Paths: []string{"internal/cli/send.go", "README.md"}, Exclusive: true})
+	if !IsReservationConflict(err) {
+		t.Fatalf("overlapping reservation error = %v, want conflict", err)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Holders[0] != "BlueLake" || len(res.Granted) != 1 || res.Granted[0].PathPattern != "README.md" {
+		t.Errorf("ReservePaths() = %+v, want README.md granted and send.go in conflict", res)
+	}
+
+	all, err := c.ListReservations(ctx, dir, "", true)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("ListReservations() = %v, %v; want 2", all, err)
+	}
+	mine, err := c.ListReservations(ctx, dir, "BlueLake", false)
+	if err != nil || len(mine) != 1 || mine[0].Reason != "refactor" {
+		t.Fatalf("ListReservations(BlueLake) = %v, %v", mine, err)
+	}
+
+	renewed, err := c.RenewReservations(ctx, RenewReservationsOptions{ProjectKey: dir, AgentName: "BlueLake", ExtendSeconds: 600})
+	if err != nil || renewed.Renewed != 1 || !renewed.Reservations[0].NewExpiresTS.After(renewed.Reservations[0].OldExpiresTS.Time) {
+		t.Fatalf("RenewReservations() = %+v, %v", renewed, err)
+	}
+
+	// A still-active holder cannot be force-released.
+	if _, err := c.ForceReleaseReservation(ctx, ForceReleaseOptions{ProjectKey: dir, AgentName: "GreenCastle", ReservationID: mine[0].ID}); err == nil {
+		t.Fatal("force release of an active holder should fail")
+	}
+	backend.now = func() time.Time { return time.Now().Add(2 * embeddedStaleAfter) }
+	fr, err := c.ForceReleaseReservation(ctx, ForceReleaseOptions{ProjectKey: dir, AgentName: "GreenCastle", ReservationID: mine[0].ID, NotifyPrevious: true})
+	backend.now = time.Now
+	if err != nil || !fr.Success || fr.PreviousHolder != "BlueLake" || !fr.Notified {
+		t.Fatalf("ForceReleaseReservation() = %+v, %v", fr, err)
+	}
+
+	if err := c.ReleaseReservations(ctx, dir, "GreenCastle", nil, nil); err != nil {
+		t.Fatalf("ReleaseReservations() error = %v", err)
+	}
+	all, _ = c.ListReservations(ctx, dir, "", true)
+	if len(all) != 0 {
+		t.Errorf("reservations after release = %v, want none", all)
+	}
+}
+
+func TestEmbeddedBackend_OverseerAndUnsupported(t *testing.T) {
+	t.Parallel()
+	c, _, dir := newEmbeddedTestClient(t)
+	ctx := context.Background()
+
+	registerEmbeddedAgent(t, c, dir, "BlueLake")
+	project, err := c.EnsureProject(ctx, dir)
+	if err != nil {
+		t.Fatalf("EnsureProject() error = %v", err)
+	}
+
+	sent, err := c.SendOverseerMessage(ctx, OverseerMessageOptions{ProjectSlug: project.Slug, Recipients: []string{"BlueLake"}, Subject: "Stop", BodyMD: "Pause work"})
+	if err != nil || !sent.Success {
+		t.Fatalf("SendOverseerMessage() = %+v, %v", sent, err)
+	}
+	inbox, err := c.FetchInbox(ctx, FetchInboxOptions{ProjectKey: dir, AgentName: "BlueLake"})
+	if err != nil || len(inbox) != 1 || inbox[0].From != overseerName || inbox[0].Importance != "high" {
+		t.Fatalf("inbox after overseer send = %+v, %v", inbox, err)
+	}
+
+	if _, err := c.SummarizeThread(ctx, SummarizeThreadOptions{ProjectKey: dir, ThreadID: "x"}); !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("unsupported tool error = %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestEmbeddedBackend_SelectedByDefault(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AGENT_MAIL_BACKEND", BackendEmbedded)
+
+	c := NewClient(WithProjectKey(dir))
+	if c.BaseURL() != EmbeddedBaseURL {
+		t.Fatalf("BaseURL() = %q, want embedded", c.BaseURL())
+	}
+	if _, err := c.EnsureProject(context.Background(), dir); err != nil {
+		t.Fatalf("EnsureProject() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".ntm", EmbeddedDBFileName)); err != nil {
+		t.Fatalf("embedded database not created: %v", err)
+	}
+
+	// Clients for the same project share one backend.
+	b1, _ := sharedEmbeddedBackend(DefaultEmbeddedPath(dir))
+	b2, _ := sharedEmbeddedBackend(DefaultEmbeddedPath(dir))
+	if b1 != b2 {
+		t.Error("expected shared backend instance")
+	}
+}
+
+func TestReservationPatternsOverlap(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"src/main.go", "src/main.go", true},
+		{"src/**", "src/pkg/x.go", true},
+		{"src/*.go", "src/main.go", true},
+		{"src", "src/main.go", true},
+		{"./docs/a.md", "docs/a.md", true},
+		{"src/*.go", "docs/a.md", false},
+		{"src/main.go", "src/other.go", false},
+		{"src/*.go", "src/a*", true},
+		{"src/a?.go", "src/[ab]*", true},
+		{"src/*.go", "src/pkg/*_test.go", true},
+		{"*.md", "docs/*.md", true},
+		{"src/*.go", "docs/*.md", false},
+		{"src/pkg/*.go", "src/cmd/*.go", false},
+	}
+	for _, tt := range tests {
+		if got := reservationPatternsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("reservationPatternsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
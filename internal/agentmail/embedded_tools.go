@@ -0,0 +1,1131 @@
+package agentmail
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// embeddedDefaultTTL is the reservation TTL when the caller gives none.
+	embeddedDefaultTTL = time.Hour
+
+	// embeddedStaleAfter is how long a holder must be inactive before another
+	// agent may force-release its reservation.
+	embeddedStaleAfter = 30 * time.Minute
+
+	// overseerName is the sender identity used for Human Overseer messages.
+	overseerName = "HumanOverseer"
+)
+
+// toolArgs wraps decoded JSON-RPC arguments with typed accessors.
+type toolArgs map[string]interface{}
+
+func (a toolArgs) str(key string) string {
+	s, _ := a[key].(string)
+	return s
+}
+
+func (a toolArgs) integer(key string) int {
+	switch v := a[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
+
+func (a toolArgs) boolean(key string, def bool) bool {
+	if v, ok := a[key].(bool); ok {
+		return v
+	}
+	return def
+}
+
+func (a toolArgs) strings(key string) []string {
+	switch v := a[key].(type) {
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case []string:
+		return v
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	}
+	return nil
+}
+
+func (a toolArgs) ints(key string) []int {
+	raw, ok := a[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]int, 0, len(raw))
+	for _, item := range raw {
+		if f, ok := item.(float64); ok {
+			out = append(out, int(f))
+		}
+	}
+	return out
+}
+
+func invalidParams(format string, args ...interface{}) error {
+	return &JSONRPCError{Code: -32602, Message: fmt.Sprintf(format, args...)}
+}
+
+func toolFailure(format string, args ...interface{}) error {
+	return &JSONRPCError{Code: -32000, Message: fmt.Sprintf(format, args...)}
+}
+
+func errAgentNotRegistered(name string) error {
+	return toolFailure("agent not registered: %s", name)
+}
+
+func formatTS(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTS(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+func flexTS(s string) FlexTime {
+	return FlexTime{Time: parseTS(s)}
+}
+
+func nullFlexTS(ns sql.NullString) *FlexTime {
+	if !ns.Valid || ns.String == "" {
+		return nil
+	}
+	ft := flexTS(ns.String)
+	return &ft
+}
+
+// callTool dispatches a tools/call request.
+func (b *EmbeddedBackend) callTool(ctx context.Context, name string, a toolArgs) (interface{}, error) {
+	var (
+		result interface{}
+		err    error
+	)
+	run := func(fn func(tx *sql.Tx) (interface{}, error)) {
+		err = b.withTx(ctx, func(tx *sql.Tx) error {
+			var fnErr error
+			result, fnErr = fn(tx)
+			return fnErr
+		})
+	}
+
+	switch name {
+	case "health_check":
+		return HealthStatus{Status: "ok", Timestamp: formatTS(b.now())}, nil
+	case "ensure_project":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.ensureProject(tx, a.str("human_key")) })
+	case "register_agent":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.registerAgent(tx, a, a.str("name"), false) })
+	case "create_agent_identity":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.registerAgent(tx, a, a.str("name_hint"), true) })
+	case "whois":
+		run(func(tx *sql.Tx) (interface{}, error) {
+			p, err := b.ensureProject(tx, a.str("project_key"))
+			if err != nil {
+				return nil, err
+			}
+			return b.lookupAgent(tx, p.ID, a.str("agent_name"))
+		})
+	case "send_message":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.sendMessage(tx, a) })
+	case "reply_message":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.replyMessage(tx, a) })
+	case "fetch_inbox":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.fetchInbox(tx, a) })
+	case "mark_message_read":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.markMessage(tx, a, false) })
+	case "acknowledge_message":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.markMessage(tx, a, true) })
+	case "get_message":
+		run(func(tx *sql.Tx) (interface{}, error) {
+			p, err := b.ensureProject(tx, a.str("project_key"))
+			if err != nil {
+				return nil, err
+			}
+			return b.loadMessage(tx, p.ID, a.integer("message_id"))
+		})
+	case "search_messages":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.searchMessages(tx, a) })
+	case "file_reservation_paths":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.reservePaths(tx, a) })
+	case "release_file_reservations":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.releaseReservations(tx, a) })
+	case "renew_file_reservations":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.renewReservations(tx, a) })
+	case "force_release_file_reservation":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.forceRelease(tx, a) })
+	case "list_file_reservations", "list_reservations":
+		run(func(tx *sql.Tx) (interface{}, error) {
+			p, err := b.ensureProject(tx, a.str("project_key"))
+			if err != nil {
+				return nil, err
+			}
+			agent := a.str("agent_name")
+			if a.boolean("all_agents", false) {
+				agent = ""
+			}
+			return b.activeReservations(tx, p.ID, agent)
+		})
+	case "macro_start_session":
+		run(func(tx *sql.Tx) (interface{}, error) { return b.startSession(tx, a) })
+	case "request_contact", "macro_contact_handshake":
+		// Every agent on a single machine may contact every other agent.
+		run(func(tx *sql.Tx) (interface{}, error) {
+			if name == "request_contact" {
+				return ContactRequestResult{Status: "approved"}, nil
+			}
+			res := ContactHandshakeResult{ContactStatus: "approved"}
+			if a.str("agent_name") != "" || a.str("program") != "" {
+				agent, err := b.registerAgent(tx, toolArgs{
+					"project_key": a.str("project_key"),
+					"program":     a.str("program"),
+					"model":       a.str("model"),
+				}, a.str("agent_name"), false)
+				if err != nil {
+					return nil, err
+				}
+				res.Agent = agent
+			}
+			return res, nil
+		})
+	case "respond_contact", "set_contact_policy":
+		return map[string]bool{"ok": true}, nil
+	case "list_contacts":
+		return []ContactLink{}, nil
+	default:
+		return nil, &JSONRPCError{Code: -32601, Message: "tool not supported by embedded backend: " + name}
+	}
+	return result, err
+}
+
+// readResource answers resources/read for the agents and file_reservations views.
+func (b *EmbeddedBackend) readResource(ctx context.Context, uri string) (interface{}, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "resource" {
+		return nil, invalidParams("resource not found: %s", uri)
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(u.EscapedPath(), "/"))
+	if err != nil {
+		return nil, invalidParams("resource not found: %s", uri)
+	}
+
+	var payload interface{}
+	err = b.withTx(ctx, func(tx *sql.Tx) error {
+		p, err := b.ensureProject(tx, key)
+		if err != nil {
+			return err
+		}
+		switch u.Host {
+		case "agents":
+			agents, err := b.listAgents(tx, p.ID)
+			if err != nil {
+				return err
+			}
+			payload = map[string]interface{}{"agents": agents}
+		case "file_reservations":
+			reservations, err := b.activeReservations(tx, p.ID, "")
+			if err != nil {
+				return err
+			}
+			type resourceReservation struct {
+				FileReservation
+				Agent string `json:"agent"`
+			}
+			view := make([]resourceReservation, 0, len(reservations))
+			for _, r := range reservations {
+				view = append(view, resourceReservation{FileReservation: r, Agent: r.AgentName})
+			}
+			payload = view
+		default:
+			return invalidParams("resource not found: %s", uri)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"contents": []map[string]string{{"uri": uri, "mimeType": "application/json", "text": string(text)}},
+	}, nil
+}
+
+// ensureProject finds a project by human key or slug, creating it on first use.
+func (b *EmbeddedBackend) ensureProject(tx *sql.Tx, key string) (*Project, error) {
+	if key == "" {
+		return nil, invalidParams("project_key is required")
+	}
+
+	var (
+		p       Project
+		created string
+	)
+	err := tx.QueryRow(`SELECT id, slug, human_key, created_at FROM projects WHERE human_key = ? OR slug = ?`, key, key).
+		Scan(&p.ID, &p.Slug, &p.HumanKey, &created)
+	if err == nil {
+		p.CreatedAt = flexTS(created)
+		return &p, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	slug := key
+	if strings.ContainsAny(key, `/\`) {
+		slug = ProjectSlugFromPath(key)
+	}
+	created = formatTS(b.now())
+	res, err := tx.Exec(`INSERT INTO projects (slug, human_key, created_at) VALUES (?, ?, ?)`, slug, key, created)
+	if err != nil {
+		return nil, fmt.Errorf("create project: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return &Project{ID: int(id), Slug: slug, HumanKey: key, CreatedAt: flexTS(created)}, nil
+}
+
+const agentColumns = `id, project_id, name, program, model, task_description, inception_ts, last_active_ts`
+
+func scanAgent(row interface{ Scan(...interface{}) error }) (*Agent, error) {
+	var (
+		a                Agent
+		inception, lastA string
+	)
+	if err := row.Scan(&a.ID, &a.ProjectID, &a.Name, &a.Program, &a.Model, &a.TaskDescription, &inception, &lastA); err != nil {
+		return nil, err
+	}
+	a.InceptionTS = flexTS(inception)
+	a.LastActiveTS = flexTS(lastA)
+	return &a, nil
+}
+
+// lookupAgent finds an agent by name (case-insensitive) within a project.
+func (b *EmbeddedBackend) lookupAgent(tx *sql.Tx, projectID int, name string) (*Agent, error) {
+	if name == "" {
+		return nil, invalidParams("agent_name is required")
+	}
+	agent, err := scanAgent(tx.QueryRow(`SELECT `+agentColumns+` FROM agents WHERE project_id = ? AND name = ? COLLATE NOCASE`, projectID, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errAgentNotRegistered(name)
+	}
+	return agent, err
+}
+
+func (b *EmbeddedBackend) listAgents(tx *sql.Tx, projectID int) ([]Agent, error) {
+	rows, err := tx.Query(`SELECT `+agentColumns+` FROM agents WHERE project_id = ? ORDER BY name`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	agents := []Agent{}
+	for rows.Next() {
+		a, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, *a)
+	}
+	return agents, rows.Err()
+}
+
+func (b *EmbeddedBackend) touchAgent(tx *sql.Tx, agentID int) error {
+	_, err := tx.Exec(`UPDATE agents SET last_active_ts = ? WHERE id = ?`, formatTS(b.now()), agentID)
+	return err
+}
+
+// registerAgent creates or updates an agent. With forceNew, an existing name
+// is treated as a hint and a fresh identity is generated instead.
+func (b *EmbeddedBackend) registerAgent(tx *sql.Tx, a toolArgs, name string, forceNew bool) (*Agent, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	now := formatTS(b.now())
+
+	if name != "" {
+		existing, err := b.lookupAgent(tx, p.ID, name)
+		switch {
+		case err == nil && !forceNew:
+			if _, err := tx.Exec(`UPDATE agents SET program = ?, model = ?, task_description = COALESCE(NULLIF(?, ''), task_description), last_active_ts = ? WHERE id = ?`,
+				a.str("program"), a.str("model"), a.str("task_description"), now, existing.ID); err != nil {
+				return nil, fmt.Errorf("update agent: %w", err)
+			}
+			return b.lookupAgent(tx, p.ID, existing.Name)
+		case err == nil:
+			name = ""
+		case !isAgentNotRegistered(err):
+			return nil, err
+		}
+	}
+	if name == "" {
+		if name, err = b.generateAgentName(tx, p.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO agents (project_id, name, program, model, task_description, inception_ts, last_active_ts) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		p.ID, name, a.str("program"), a.str("model"), a.str("task_description"), now, now); err != nil {
+		return nil, fmt.Errorf("create agent: %w", err)
+	}
+	return b.lookupAgent(tx, p.ID, name)
+}
+
+func isAgentNotRegistered(err error) bool {
+	var rpcErr *JSONRPCError
+	return errors.As(err, &rpcErr) && strings.HasPrefix(rpcErr.Message, "agent not registered")
+}
+
+var (
+	agentNameAdjectives = []string{"Amber", "Blue", "Bright", "Copper", "Crimson", "Golden", "Green", "Grey", "Jade", "Misty", "Quiet", "Red", "Silver", "Swift", "Violet", "White"}
+	agentNameNouns      = []string{"Bridge", "Castle", "Cliff", "Creek", "Forest", "Harbor", "Hill", "Lake", "Meadow", "Mountain", "Pond", "River", "Stone", "Valley", "Willow", "Wolf"}
+)
+
+// generateAgentName picks an unused adjective+noun name, as the server does.
+func (b *EmbeddedBackend) generateAgentName(tx *sql.Tx, projectID int) (string, error) {
+	taken := func(name string) (bool, error) {
+		var n int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM agents WHERE project_id = ? AND name = ? COLLATE NOCASE`, projectID, name).Scan(&n)
+		return n > 0, err
+	}
+
+	for i := 0; i < 64; i++ {
+		name := agentNameAdjectives[rand.Intn(len(agentNameAdjectives))] + agentNameNouns[rand.Intn(len(agentNameNouns))]
+		used, err := taken(name)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return name, nil
+		}
+	}
+	for n := 2; ; n++ {
+		name := fmt.Sprintf("%s%s%d", agentNameAdjectives[0], agentNameNouns[0], n)
+		used, err := taken(name)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return name, nil
+		}
+	}
+}
+
+// insertMessage stores a message and its recipients, returning the payload.
+func (b *EmbeddedBackend) insertMessage(tx *sql.Tx, p *Project, sender *Agent, to, cc, bcc []string, subject, body, importance, threadID string, ackRequired bool) (*Message, error) {
+	if len(to)+len(cc)+len(bcc) == 0 {
+		return nil, invalidParams("at least one recipient is required")
+	}
+	if importance == "" {
+		importance = "normal"
+	}
+
+	type recipient struct {
+		agent *Agent
+		kind  string
+	}
+	var recipients []recipient
+	seen := make(map[int]bool)
+	for _, group := range []struct {
+		names []string
+		kind  string
+	}{{to, "to"}, {cc, "cc"}, {bcc, "bcc"}} {
+		for _, name := range group.names {
+			agent, err := b.lookupAgent(tx, p.ID, name)
+			if err != nil {
+				return nil, err
+			}
+			if !seen[agent.ID] {
+				seen[agent.ID] = true
+				recipients = append(recipients, recipient{agent, group.kind})
+			}
+		}
+	}
+
+	now := b.now()
+	var thread interface{}
+	if threadID != "" {
+		thread = threadID
+	}
+	res, err := tx.Exec(`INSERT INTO messages (project_id, sender_id, thread_id, subject, body_md, importance, ack_required, created_ts) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.ID, sender.ID, thread, subject, body, importance, ackRequired, formatTS(now))
+	if err != nil {
+		return nil, fmt.Errorf("insert message: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	for _, r := range recipients {
+		if _, err := tx.Exec(`INSERT INTO message_recipients (message_id, agent_id, kind) VALUES (?, ?, ?)`, id, r.agent.ID, r.kind); err != nil {
+			return nil, fmt.Errorf("insert recipient: %w", err)
+		}
+	}
+	if err := b.touchAgent(tx, sender.ID); err != nil {
+		return nil, err
+	}
+	return b.loadMessage(tx, p.ID, int(id))
+}
+
+func (b *EmbeddedBackend) sendMessage(tx *sql.Tx, a toolArgs) (*SendResult, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	sender, err := b.lookupAgent(tx, p.ID, a.str("sender_name"))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := b.insertMessage(tx, p, sender, a.strings("to"), a.strings("cc"), a.strings("bcc"),
+		a.str("subject"), a.str("body_md"), a.str("importance"), a.str("thread_id"), a.boolean("ack_required", false))
+	if err != nil {
+		return nil, err
+	}
+	return &SendResult{Deliveries: []MessageDelivery{{Project: p.HumanKey, Payload: msg}}, Count: 1}, nil
+}
+
+func (b *EmbeddedBackend) replyMessage(tx *sql.Tx, a toolArgs) (*Message, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	orig, err := b.loadMessage(tx, p.ID, a.integer("message_id"))
+	if err != nil {
+		return nil, err
+	}
+	sender, err := b.lookupAgent(tx, p.ID, a.str("sender_name"))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := a.str("subject_prefix")
+	if prefix == "" {
+		prefix = "Re:"
+	}
+	subject := orig.Subject
+	if !strings.HasPrefix(strings.ToLower(subject), strings.ToLower(prefix)) {
+		subject = prefix + " " + subject
+	}
+	to := a.strings("to")
+	if len(to) == 0 {
+		to = []string{orig.From}
+	}
+	thread := strconv.Itoa(orig.ID)
+	if orig.ThreadID != nil && *orig.ThreadID != "" {
+		thread = *orig.ThreadID
+	}
+	return b.insertMessage(tx, p, sender, to, a.strings("cc"), a.strings("bcc"),
+		subject, a.str("body_md"), orig.Importance, thread, orig.AckRequired)
+}
+
+// loadMessage reads a message with its sender and recipient lists.
+func (b *EmbeddedBackend) loadMessage(tx *sql.Tx, projectID, id int) (*Message, error) {
+	var (
+		m       Message
+		thread  sql.NullString
+		created string
+	)
+	err := tx.QueryRow(`
+		SELECT m.id, m.project_id, m.sender_id, m.thread_id, m.subject, m.body_md, m.importance, m.ack_required, m.created_ts, a.name
+		FROM messages m JOIN agents a ON a.id = m.sender_id
+		WHERE m.id = ? AND m.project_id = ?`, id, projectID).
+		Scan(&m.ID, &m.ProjectID, &m.SenderID, &thread, &m.Subject, &m.BodyMD, &m.Importance, &m.AckRequired, &created, &m.From)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, toolFailure("message not found: %d", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.CreatedTS = flexTS(created)
+	if thread.Valid {
+		m.ThreadID = &thread.String
+	}
+
+	rows, err := tx.Query(`SELECT a.name, r.kind FROM message_recipients r JOIN agents a ON a.id = r.agent_id WHERE r.message_id = ? ORDER BY a.name`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	m.To = []string{}
+	for rows.Next() {
+		var name, kind string
+		if err := rows.Scan(&name, &kind); err != nil {
+			return nil, err
+		}
+		switch kind {
+		case "cc":
+			m.CC = append(m.CC, name)
+		case "bcc":
+			m.BCC = append(m.BCC, name)
+		default:
+			m.To = append(m.To, name)
+		}
+	}
+	return &m, rows.Err()
+}
+
+func (b *EmbeddedBackend) fetchInbox(tx *sql.Tx, a toolArgs) ([]InboxMessage, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	agent, err := b.lookupAgent(tx, p.ID, a.str("agent_name"))
+	if err != nil {
+		return nil, err
+	}
+	if err := b.touchAgent(tx, agent.ID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT m.id, m.subject, s.name, m.created_ts, m.thread_id, m.importance, m.ack_required, r.kind, m.body_md, r.read_ts
+		FROM message_recipients r
+		JOIN messages m ON m.id = r.message_id
+		JOIN agents s ON s.id = m.sender_id
+		WHERE r.agent_id = ?`
+	params := []interface{}{agent.ID}
+	if a.boolean("urgent_only", false) {
+		query += ` AND m.importance IN ('high', 'urgent')`
+	}
+	if since := a.str("since_ts"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, invalidParams("invalid since_ts: %v", err)
+		}
+		query += ` AND m.created_ts > ?`
+		params = append(params, formatTS(t))
+	}
+	limit := a.integer("limit")
+	if limit <= 0 {
+		limit = 20
+	}
+	query += ` ORDER BY m.created_ts DESC, m.id DESC LIMIT ?`
+	params = append(params, limit)
+
+	rows, err := tx.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	includeBodies := a.boolean("include_bodies", false)
+	messages := []InboxMessage{}
+	for rows.Next() {
+		var (
+			m       InboxMessage
+			created string
+			thread  sql.NullString
+			body    string
+			readTS  sql.NullString
+		)
+		if err := rows.Scan(&m.ID, &m.Subject, &m.From, &created, &thread, &m.Importance, &m.AckRequired, &m.Kind, &body, &readTS); err != nil {
+			return nil, err
+		}
+		m.CreatedTS = flexTS(created)
+		if thread.Valid {
+			m.ThreadID = &thread.String
+		}
+		if includeBodies {
+			m.BodyMD = body
+		}
+		m.ReadAt = nullFlexTS(readTS)
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// markMessage records a read (and optionally an acknowledgement) for the agent.
+func (b *EmbeddedBackend) markMessage(tx *sql.Tx, a toolArgs, ack bool) (map[string]interface{}, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	agent, err := b.lookupAgent(tx, p.ID, a.str("agent_name"))
+	if err != nil {
+		return nil, err
+	}
+	id := a.integer("message_id")
+	now := formatTS(b.now())
+
+	query := `UPDATE message_recipients SET read_ts = COALESCE(read_ts, ?) WHERE message_id = ? AND agent_id = ?`
+	params := []interface{}{now, id, agent.ID}
+	if ack {
+		query = `UPDATE message_recipients SET read_ts = COALESCE(read_ts, ?), ack_ts = COALESCE(ack_ts, ?) WHERE message_id = ? AND agent_id = ?`
+		params = []interface{}{now, now, id, agent.ID}
+	}
+	res, err := tx.Exec(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, toolFailure("message not found: %d", id)
+	}
+	if ack {
+		return map[string]interface{}{"message_id": id, "acknowledged": true, "acknowledged_at": now}, nil
+	}
+	return map[string]interface{}{"message_id": id, "read": true, "read_at": now}, nil
+}
+
+func (b *EmbeddedBackend) searchMessages(tx *sql.Tx, a toolArgs) ([]SearchResult, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	limit := a.integer("limit")
+	if limit <= 0 {
+		limit = 20
+	}
+	like := "%" + a.str("query") + "%"
+
+	rows, err := tx.Query(`
+		SELECT m.id, m.subject, m.importance, m.ack_required, m.created_ts, m.thread_id, s.name
+		FROM messages m JOIN agents s ON s.id = m.sender_id
+		WHERE m.project_id = ? AND (m.subject LIKE ? OR m.body_md LIKE ?)
+		ORDER BY m.created_ts DESC, m.id DESC LIMIT ?`, p.ID, like, like, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var (
+			r       SearchResult
+			created string
+			thread  sql.NullString
+		)
+		if err := rows.Scan(&r.ID, &r.Subject, &r.Importance, &r.AckRequired, &created, &thread, &r.From); err != nil {
+			return nil, err
+		}
+		r.CreatedTS = flexTS(created)
+		if thread.Valid {
+			r.ThreadID = &thread.String
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// activeReservations lists unreleased, unexpired reservations, optionally for one agent.
+func (b *EmbeddedBackend) activeReservations(tx *sql.Tx, projectID int, agentName string) ([]FileReservation, error) {
+	query := `
+		SELECT r.id, r.path_pattern, a.name, r.project_id, r.exclusive, r.reason, r.expires_ts, r.created_ts
+		FROM file_reservations r JOIN agents a ON a.id = r.agent_id
+		WHERE r.project_id = ? AND r.released_ts IS NULL AND r.expires_ts > ?`
+	params := []interface{}{projectID, formatTS(b.now())}
+	if agentName != "" {
+		query += ` AND a.name = ? COLLATE NOCASE`
+		params = append(params, agentName)
+	}
+	query += ` ORDER BY r.id`
+
+	rows, err := tx.Query(query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []FileReservation{}
+	for rows.Next() {
+		var (
+			r                FileReservation
+			expires, created string
+		)
+		if err := rows.Scan(&r.ID, &r.PathPattern, &r.AgentName, &r.ProjectID, &r.Exclusive, &r.Reason, &expires, &created); err != nil {
+			return nil, err
+		}
+		r.ExpiresTS = flexTS(expires)
+		r.CreatedTS = flexTS(created)
+		reservations = append(reservations, r)
+	}
+	return reservations, rows.Err()
+}
+
+// reservePaths grants reservations for paths that do not conflict with other
+// agents' active reservations. Without agent_name it only reports conflicts.
+func (b *EmbeddedBackend) reservePaths(tx *sql.Tx, a toolArgs) (*ReservationResult, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	paths := a.strings("paths")
+	if len(paths) == 0 {
+		return nil, invalidParams("paths is required")
+	}
+
+	var agent *Agent
+	if name := a.str("agent_name"); name != "" {
+		if agent, err = b.lookupAgent(tx, p.ID, name); err != nil {
+			return nil, err
+		}
+	}
+	exclusive := a.boolean("exclusive", true)
+	ttl := time.Duration(a.integer("ttl_seconds")) * time.Second
+	if ttl <= 0 {
+		ttl = embeddedDefaultTTL
+	}
+
+	active, err := b.activeReservations(tx, p.ID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReservationResult{Granted: []FileReservation{}, Conflicts: []ReservationConflict{}}
+	now := b.now()
+	for _, pattern := range paths {
+		var holders []string
+		var own *FileReservation
+		for i := range active {
+			r := &active[i]
+			if !reservationPatternsOverlap(pattern, r.PathPattern) {
+				continue
+			}
+			if agent != nil && strings.EqualFold(r.AgentName, agent.Name) {
+				if r.PathPattern == pattern {
+					own = r
+				}
+				continue
+			}
+			if exclusive || r.Exclusive {
+				holders = append(holders, r.AgentName)
+			}
+		}
+		if len(holders) > 0 {
+			result.Conflicts = append(result.Conflicts, ReservationConflict{Path: pattern, Holders: holders})
+			continue
+		}
+		if agent == nil {
+			continue
+		}
+
+		expires := formatTS(now.Add(ttl))
+		if own != nil {
+			if _, err := tx.Exec(`UPDATE file_reservations SET expires_ts = ?, exclusive = ?, reason = ? WHERE id = ?`,
+				expires, exclusive, a.str("reason"), own.ID); err != nil {
+				return nil, fmt.Errorf("extend reservation: %w", err)
+			}
+			own.ExpiresTS = flexTS(expires)
+			own.Exclusive = exclusive
+			own.Reason = a.str("reason")
+			result.Granted = append(result.Granted, *own)
+			continue
+		}
+
+		created := formatTS(now)
+		res, err := tx.Exec(`INSERT INTO file_reservations (project_id, agent_id, path_pattern, exclusive, reason, created_ts, expires_ts) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			p.ID, agent.ID, pattern, exclusive, a.str("reason"), created, expires)
+		if err != nil {
+			return nil, fmt.Errorf("insert reservation: %w", err)
+		}
+		id, _ := res.LastInsertId()
+		granted := FileReservation{
+			ID:          int(id),
+			PathPattern: pattern,
+			AgentName:   agent.Name,
+			ProjectID:   p.ID,
+			Exclusive:   exclusive,
+			Reason:      a.str("reason"),
+			CreatedTS:   flexTS(created),
+			ExpiresTS:   flexTS(expires),
+		}
+		active = append(active, granted)
+		result.Granted = append(result.Granted, granted)
+	}
+
+	if agent != nil {
+		if err := b.touchAgent(tx, agent.ID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// selectOwnReservations returns the agent's active reservations filtered by
+// ids or paths; with neither filter, all of them.
+func (b *EmbeddedBackend) selectOwnReservations(tx *sql.Tx, projectID int, agentName string, ids []int, paths []string) ([]FileReservation, error) {
+	own, err := b.activeReservations(tx, projectID, agentName)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 && len(paths) == 0 {
+		return own, nil
+	}
+	wantID := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wantID[id] = true
+	}
+	wantPath := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		wantPath[p] = true
+	}
+	var selected []FileReservation
+	for _, r := range own {
+		if wantID[r.ID] || wantPath[r.PathPattern] {
+			selected = append(selected, r)
+		}
+	}
+	return selected, nil
+}
+
+func (b *EmbeddedBackend) releaseReservations(tx *sql.Tx, a toolArgs) (map[string]interface{}, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	agent, err := b.lookupAgent(tx, p.ID, a.str("agent_name"))
+	if err != nil {
+		return nil, err
+	}
+	selected, err := b.selectOwnReservations(tx, p.ID, agent.Name, a.ints("file_reservation_ids"), a.strings("paths"))
+	if err != nil {
+		return nil, err
+	}
+
+	now := formatTS(b.now())
+	for _, r := range selected {
+		if _, err := tx.Exec(`UPDATE file_reservations SET released_ts = ? WHERE id = ?`, now, r.ID); err != nil {
+			return nil, fmt.Errorf("release: %w", err)
+		}
+	}
+	return map[string]interface{}{"released": len(selected), "released_at": now}, nil
+}
+
+func (b *EmbeddedBackend) renewReservations(tx *sql.Tx, a toolArgs) (*RenewReservationsResult, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	agent, err := b.lookupAgent(tx, p.ID, a.str("agent_name"))
+	if err != nil {
+		return nil, err
+	}
+	extend := time.Duration(a.integer("extend_seconds")) * time.Second
+	if extend <= 0 {
+		extend = embeddedDefaultTTL / 2
+	}
+	selected, err := b.selectOwnReservations(tx, p.ID, agent.Name, a.ints("file_reservation_ids"), a.strings("paths"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RenewReservationsResult{Reservations: []RenewedReservation{}}
+	now := b.now()
+	for _, r := range selected {
+		base := r.ExpiresTS.Time
+		if base.Before(now) {
+			base = now
+		}
+		newExpires := formatTS(base.Add(extend))
+		if _, err := tx.Exec(`UPDATE file_reservations SET expires_ts = ? WHERE id = ?`, newExpires, r.ID); err != nil {
+			return nil, fmt.Errorf("renew: %w", err)
+		}
+		result.Reservations = append(result.Reservations, RenewedReservation{
+			ID:           r.ID,
+			PathPattern:  r.PathPattern,
+			OldExpiresTS: r.ExpiresTS,
+			NewExpiresTS: flexTS(newExpires),
+		})
+	}
+	result.Renewed = len(result.Reservations)
+	return result, b.touchAgent(tx, agent.ID)
+}
+
+// forceRelease releases another agent's reservation once the holder has gone
+// quiet, optionally notifying the previous holder.
+func (b *EmbeddedBackend) forceRelease(tx *sql.Tx, a toolArgs) (*ForceReleaseResult, error) {
+	p, err := b.ensureProject(tx, a.str("project_key"))
+	if err != nil {
+		return nil, err
+	}
+	requester, err := b.lookupAgent(tx, p.ID, a.str("agent_name"))
+	if err != nil {
+		return nil, err
+	}
+
+	id := a.integer("file_reservation_id")
+	var (
+		holderID             int
+		pattern, expires     string
+		released, lastActive sql.NullString
+	)
+	err = tx.QueryRow(`
+		SELECT r.agent_id, r.path_pattern, r.expires_ts, r.released_ts, a.last_active_ts
+		FROM file_reservations r JOIN agents a ON a.id = r.agent_id
+		WHERE r.id = ? AND r.project_id = ?`, id, p.ID).
+		Scan(&holderID, &pattern, &expires, &released, &lastActive)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, invalidParams("lock %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	holder, err := b.agentByID(tx, holderID)
+	if err != nil {
+		return nil, err
+	}
+	result := &ForceReleaseResult{PreviousHolder: holder.Name, PathPattern: pattern}
+	if released.Valid {
+		result.Success = true
+		result.ReleasedAt = nullFlexTS(released)
+		return result, nil
+	}
+
+	now := b.now()
+	if parseTS(expires).After(now) && now.Sub(parseTS(lastActive.String)) < embeddedStaleAfter {
+		return nil, toolFailure("holder %s is still active; wait for it to go quiet or ask it to release %s", holder.Name, pattern)
+	}
+
+	releasedAt := formatTS(now)
+	if _, err := tx.Exec(`UPDATE file_reservations SET released_ts = ? WHERE id = ?`, releasedAt, id); err != nil {
+		return nil, fmt.Errorf("force release: %w", err)
+	}
+	result.Success = true
+	result.ReleasedAt = nullFlexTS(sql.NullString{String: releasedAt, Valid: true})
+
+	if a.boolean("notify_previous", false) {
+		body := fmt.Sprintf("%s force-released your hold on `%s`.", requester.Name, pattern)
+		if note := a.str("note"); note != "" {
+			body += "\n\n" + note
+		}
+		if _, err := b.insertMessage(tx, p, requester, []string{holder.Name}, nil, nil,
+			"Released "+pattern, body, "normal", "", false); err != nil {
+			return nil, err
+		}
+		result.Notified = true
+	}
+	return result, nil
+}
+
+func (b *EmbeddedBackend) agentByID(tx *sql.Tx, id int) (*Agent, error) {
+	return scanAgent(tx.QueryRow(`SELECT `+agentColumns+` FROM agents WHERE id = ?`, id))
+}
+
+func (b *EmbeddedBackend) startSession(tx *sql.Tx, a toolArgs) (*SessionStartResult, error) {
+	key := a.str("human_key")
+	p, err := b.ensureProject(tx, key)
+	if err != nil {
+		return nil, err
+	}
+	regArgs := toolArgs{
+		"project_key":      key,
+		"program":          a.str("program"),
+		"model":            a.str("model"),
+		"task_description": a.str("task_description"),
+	}
+	agent, err := b.registerAgent(tx, regArgs, a.str("agent_name"), false)
+	if err != nil {
+		return nil, err
+	}
+	inbox, err := b.fetchInbox(tx, toolArgs{"project_key": key, "agent_name": agent.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SessionStartResult{Project: p, Agent: agent, Inbox: inbox}
+	if paths := a.strings("file_reservation_paths"); len(paths) > 0 {
+		reserveArgs := toolArgs{"project_key": key, "agent_name": agent.Name, "paths": paths}
+		if result.FileReservations, err = b.reservePaths(tx, reserveArgs); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// sendOverseer delivers a Human Overseer message to the named agents.
+func (b *EmbeddedBackend) sendOverseer(ctx context.Context, slug string, recipients []string, subject, body, threadID string) (*OverseerSendResult, error) {
+	var result *OverseerSendResult
+	err := b.withTx(ctx, func(tx *sql.Tx) error {
+		var known int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM projects WHERE slug = ?`, slug).Scan(&known); err != nil {
+			return err
+		}
+		if known == 0 {
+			return fmt.Errorf("unknown project %q", slug)
+		}
+		p, err := b.ensureProject(tx, slug)
+		if err != nil {
+			return err
+		}
+		sender, err := b.registerAgent(tx, toolArgs{"project_key": slug, "program": "ntm", "model": "human"}, overseerName, false)
+		if err != nil {
+			return err
+		}
+		msg, err := b.insertMessage(tx, p, sender, recipients, nil, nil, subject, body, "high", threadID, false)
+		if err != nil {
+			return err
+		}
+		result = &OverseerSendResult{Success: true, MessageID: msg.ID, Recipients: msg.To, SentAt: msg.CreatedTS}
+		return nil
+	})
+	return result, err
+}
+
+// PatternsOverlap reports whether two reservation patterns (paths or globs)
+// may cover a common path.
+func PatternsOverlap(a, b string) bool {
+	return reservationPatternsOverlap(a, b)
+}
+
+// reservationPatternsOverlap reports whether two reservation patterns may
+// cover a common path. It errs toward reporting overlap: reservations are
+// advisory, and a spurious conflict is cheaper than a missed one.
+func reservationPatternsOverlap(a, b string) bool {
+	a, b = strings.TrimPrefix(path.Clean(a), "./"), strings.TrimPrefix(path.Clean(b), "./")
+	if a == b || patternCovers(a, b) || patternCovers(b, a) {
+		return true
+	}
+	// Two globs may match a common path even when neither matches the
+	// other's text, as with src/*.go and src/a*; assume they do when their
+	// literal directories nest.
+	dirA, globA := globDir(a)
+	dirB, globB := globDir(b)
+	return globA && globB && (strings.HasPrefix(dirA, dirB) || strings.HasPrefix(dirB, dirA))
+}
+
+// globDir returns the literal directory (with trailing slash) before
+// pattern's first glob metacharacter, and whether it has one.
+func globDir(pattern string) (string, bool) {
+	i := strings.IndexAny(pattern, "*?[")
+	if i < 0 {
+		return "", false
+	}
+	return pattern[:strings.LastIndex(pattern[:i], "/")+1], true
+}
+
+// patternCovers reports whether pattern matches target or a path under it.
+func patternCovers(pattern, target string) bool {
+	if i := strings.Index(pattern, "**"); i >= 0 {
+		return strings.HasPrefix(target, pattern[:i])
+	}
+	if ok, _ := path.Match(pattern, target); ok {
+		return true
+	}
+	return strings.HasPrefix(target, strings.TrimSuffix(pattern, "/")+"/")
+}
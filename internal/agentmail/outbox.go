@@ -0,0 +1,325 @@
+package agentmail
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// OutboxFileName is the name of the offline queue file under .ntm/.
+const OutboxFileName = "agentmail_outbox.jsonl"
+
+// OutboxEntry is a tool call queued while the Agent Mail backend was offline.
+type OutboxEntry struct {
+	ID         string                 `json:"id"`
+	Tool       string                 `json:"tool"`
+	Args       map[string]interface{} `json:"args"`
+	EnqueuedAt time.Time              `json:"enqueued_at"`
+}
+
+// Outbox is a durable FIFO of outbound tool calls, persisted as JSONL so
+// queued messages and reservations survive process restarts. Every ntm
+// process in a project shares the file: changes re-read it under its file
+// lock, so one process never drops or repeats another's entries.
+type Outbox struct {
+	mu      sync.Mutex // guards entries and seq
+	path    string
+	entries []OutboxEntry
+	seq     int64
+}
+
+// DefaultOutboxPath returns the outbox path for a project directory.
+func DefaultOutboxPath(projectDir string) string {
+	return filepath.Join(projectDir, ".ntm", OutboxFileName)
+}
+
+// OpenOutbox loads the outbox at path, creating an empty one if the file does
+// not exist.
+func OpenOutbox(path string) (*Outbox, error) {
+	entries, err := readOutbox(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Outbox{path: path, entries: entries}, nil
+}
+
+// readOutbox reads the queued entries at path; a missing file is empty.
+func readOutbox(path string) ([]OutboxEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("open outbox: %w", err)
+	}
+	defer f.Close()
+
+	var entries []OutboxEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var entry OutboxEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// Skip a torn trailing write rather than losing the whole queue.
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	return entries, nil
+}
+
+// refresh re-reads the file, keeping the last known entries if it cannot.
+func (o *Outbox) refresh() {
+	if entries, err := readOutbox(o.path); err == nil {
+		o.setEntries(entries)
+	}
+}
+
+func (o *Outbox) setEntries(entries []OutboxEntry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = entries
+}
+
+// Len returns the number of queued entries.
+func (o *Outbox) Len() int {
+	o.refresh()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+// Entries returns a copy of the queued entries in delivery order.
+func (o *Outbox) Entries() []OutboxEntry {
+	o.refresh()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]OutboxEntry(nil), o.entries...)
+}
+
+// update runs fn with the entries currently on disk while holding the
+// outbox file lock, which serializes goroutines and processes alike.
+func (o *Outbox) update(fn func(entries []OutboxEntry) error) error {
+	return util.WithFileLock(o.path, func() error {
+		entries, err := readOutbox(o.path)
+		if err != nil {
+			return err
+		}
+		o.setEntries(entries)
+		return fn(entries)
+	})
+}
+
+// Enqueue appends a tool call to the queue and persists it.
+func (o *Outbox) Enqueue(tool string, args map[string]interface{}) (OutboxEntry, error) {
+	o.mu.Lock()
+	o.seq++
+	seq := o.seq
+	o.mu.Unlock()
+
+	now := time.Now().UTC()
+	entry := OutboxEntry{
+		ID:         fmt.Sprintf("%d-%d-%d", now.UnixNano(), os.Getpid(), seq),
+		Tool:       tool,
+		Args:       args,
+		EnqueuedAt: now,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return OutboxEntry{}, fmt.Errorf("marshal outbox entry: %w", err)
+	}
+
+	err = o.update(func(entries []OutboxEntry) error {
+		f, err := os.OpenFile(o.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("open outbox: %w", err)
+		}
+		defer f.Close()
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("write outbox: %w", err)
+		}
+		o.setEntries(append(entries, entry))
+		return nil
+	})
+	if err != nil {
+		return OutboxEntry{}, err
+	}
+	return entry, nil
+}
+
+// removeHead drops the first n of entries, as read by update, and rewrites
+// the file. It must be called from within update.
+func (o *Outbox) removeHead(entries []OutboxEntry, n int) error {
+	if n > len(entries) {
+		n = len(entries)
+	}
+	entries = append([]OutboxEntry(nil), entries[n:]...)
+	o.setEntries(entries)
+
+	if len(entries) == 0 {
+		if err := os.Remove(o.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove outbox: %w", err)
+		}
+		return nil
+	}
+
+	tmp := o.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("rewrite outbox: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("rewrite outbox: %w", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rewrite outbox: %w", err)
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		return fmt.Errorf("rewrite outbox: %w", err)
+	}
+	return nil
+}
+
+// WithOutbox enables the offline queue used by the *OrQueue methods.
+func WithOutbox(o *Outbox) Option {
+	return func(c *Client) {
+		c.outbox = o
+	}
+}
+
+// Outbox returns the client's offline queue, or nil if none is configured.
+func (c *Client) Outbox() *Outbox {
+	return c.outbox
+}
+
+// FlushResult summarises an outbox flush.
+type FlushResult struct {
+	Delivered int           `json:"delivered"`
+	Dropped   []OutboxEntry `json:"dropped,omitempty"`
+	Remaining int           `json:"remaining"`
+}
+
+// FlushOutbox delivers queued tool calls in order. Delivery stops at the
+// first call that fails because the backend is still unavailable, leaving it
+// and everything after it queued. Calls the backend rejects outright (for
+// example an unregistered agent) can never succeed on retry, so they are
+// dropped and reported in the result.
+//
+// The outbox file stays locked from reading the queue until the delivered
+// entries are removed, so concurrent flushes from other ntm processes wait
+// and then see only what is still undelivered.
+func (c *Client) FlushOutbox(ctx context.Context) (*FlushResult, error) {
+	result := &FlushResult{}
+	if c.outbox == nil {
+		return result, nil
+	}
+
+	var flushErr error
+	err := c.outbox.update(func(entries []OutboxEntry) error {
+		done := 0
+		for _, entry := range entries {
+			if err := ctx.Err(); err != nil {
+				flushErr = err
+				break
+			}
+			_, err := c.callTool(ctx, entry.Tool, entry.Args)
+			if err != nil && (IsServerUnavailable(err) || IsTimeout(err)) {
+				flushErr = err
+				break
+			}
+			if err != nil {
+				result.Dropped = append(result.Dropped, entry)
+			} else {
+				result.Delivered++
+			}
+			done++
+		}
+		if done == 0 {
+			return nil
+		}
+		return c.outbox.removeHead(entries, done)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Remaining = c.outbox.Len()
+	return result, flushErr
+}
+
+// queueable runs a write through the outbox: earlier queued calls are flushed
+// first so ordering is preserved, and the call itself is queued if the
+// backend is unavailable. queued reports whether the call was deferred.
+func (c *Client) queueable(ctx context.Context, tool string, args map[string]interface{}) (result json.RawMessage, queued bool, err error) {
+	if c.outbox == nil {
+		result, err = c.callTool(ctx, tool, args)
+		return result, false, err
+	}
+
+	if c.outbox.Len() > 0 {
+		if _, err := c.FlushOutbox(ctx); err != nil || c.outbox.Len() > 0 {
+			// Backend still down (or a flush is incomplete): queue behind
+			// the pending entries to keep delivery order.
+			if _, qerr := c.outbox.Enqueue(tool, args); qerr != nil {
+				return nil, false, qerr
+			}
+			return nil, true, nil
+		}
+	}
+
+	result, err = c.callTool(ctx, tool, args)
+	if err != nil && IsServerUnavailable(err) {
+		if _, qerr := c.outbox.Enqueue(tool, args); qerr != nil {
+			return nil, false, fmt.Errorf("%w (queue failed: %v)", err, qerr)
+		}
+		return nil, true, nil
+	}
+	return result, false, err
+}
+
+// SendMessageOrQueue sends a message, queueing it for later delivery if the
+// backend is unavailable. When queued is true the result is nil.
+func (c *Client) SendMessageOrQueue(ctx context.Context, opts SendMessageOptions) (*SendResult, bool, error) {
+	result, queued, err := c.queueable(ctx, "send_message", sendMessageArgs(opts))
+	if err != nil || queued {
+		return nil, queued, err
+	}
+
+	var sendResult SendResult
+	if err := json.Unmarshal(result, &sendResult); err != nil {
+		return nil, false, NewAPIError("send_message", 0, err)
+	}
+	return &sendResult, false, nil
+}
+
+// ReservePathsOrQueue requests file reservations, queueing the request for
+// later delivery if the backend is unavailable. When queued is true the
+// result is nil.
+func (c *Client) ReservePathsOrQueue(ctx context.Context, opts FileReservationOptions) (*ReservationResult, bool, error) {
+	result, queued, err := c.queueable(ctx, "file_reservation_paths", reservePathsArgs(opts))
+	if err != nil || queued {
+		return nil, queued, err
+	}
+
+	res, err := parseReservationResult(result)
+	return res, false, err
+}
@@ -0,0 +1,245 @@
+package agentmail
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestOutboxPersistsAcrossReopen(t *testing.T) {
+	t.Parallel()
+
+	path := DefaultOutboxPath(t.TempDir())
+	o, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	if o.Len() != 0 {
+		t.Fatalf("new outbox Len() = %d, want 0", o.Len())
+	}
+	if _, err := o.Enqueue("send_message", map[string]interface{}{"subject": "one"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if _, err := o.Enqueue("file_reservation_paths", map[string]interface{}{"paths": []string{"a.go"}}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	reopened, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("reopen error = %v", err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("reopened Len() = %d, want 2", len(entries))
+	}
+	if entries[0].Tool != "send_message" || entries[1].Tool != "file_reservation_paths" {
+		t.Errorf("entries out of order: %s, %s", entries[0].Tool, entries[1].Tool)
+	}
+	if entries[0].Args["subject"] != "one" {
+		t.Errorf("args not persisted: %v", entries[0].Args)
+	}
+}
+
+func TestSendMessageOrQueue_QueuesWhileOfflineAndFlushesInOrder(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		subjects []string
+		online   atomic.Bool
+	)
+	inner := mockMCPHandler(t, map[string]func(args map[string]interface{}) (interface{}, *JSONRPCError){
+		"send_message": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			mu.Lock()
+			subjects = append(subjects, args["subject"].(string))
+			mu.Unlock()
+			return map[string]interface{}{"deliveries": []interface{}{}, "count": 1}, nil
+		},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !online.Load() {
+			// Simulate a dropped connection so the client sees the server as unreachable.
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Fatal("response writer does not support hijacking")
+			}
+			conn, _, _ := hj.Hijack()
+			conn.Close()
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	outbox, err := OpenOutbox(filepath.Join(t.TempDir(), OutboxFileName))
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	c := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetry(1)), WithOutbox(outbox))
+	ctx := context.Background()
+
+	for _, subject := range []string{"first", "second"} {
+		res, queued, err := c.SendMessageOrQueue(ctx, SendMessageOptions{
+			ProjectKey: "/p", SenderName: "A", To: []string{"B"}, Subject: subject, BodyMD: "x",
+		})
+		if err != nil || !queued || res != nil {
+			t.Fatalf("offline send %q: res=%v queued=%v err=%v, want queued", subject, res, queued, err)
+		}
+	}
+	if got := c.Health().QueuedOutbound; got != 2 {
+		t.Fatalf("QueuedOutbound = %d, want 2", got)
+	}
+
+	online.Store(true)
+	res, queued, err := c.SendMessageOrQueue(ctx, SendMessageOptions{
+		ProjectKey: "/p", SenderName: "A", To: []string{"B"}, Subject: "third", BodyMD: "x",
+	})
+	if err != nil || queued || res == nil {
+		t.Fatalf("online send: res=%v queued=%v err=%v, want delivered", res, queued, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"first", "second", "third"}
+	if len(subjects) != len(want) {
+		t.Fatalf("delivered %v, want %v", subjects, want)
+	}
+	for i := range want {
+		if subjects[i] != want[i] {
+			t.Fatalf("delivered %v, want %v", subjects, want)
+		}
+	}
+	if outbox.Len() != 0 {
+		t.Errorf("outbox Len() = %d after flush, want 0", outbox.Len())
+	}
+}
+
+func TestFlushOutbox_DropsRejectedEntries(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(mockMCPHandler(t, map[string]func(args map[string]interface{}) (interface{}, *JSONRPCError){
+		"send_message": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			return map[string]interface{}{"count": 1}, nil
+		},
+	}))
+	defer server.Close()
+
+	outbox, err := OpenOutbox(filepath.Join(t.TempDir(), OutboxFileName))
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	outbox.Enqueue("unknown_tool", map[string]interface{}{})
+	outbox.Enqueue("send_message", map[string]interface{}{"subject": "ok"})
+
+	c := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetry(1)), WithOutbox(outbox))
+	result, err := c.FlushOutbox(context.Background())
+	if err != nil {
+		t.Fatalf("FlushOutbox() error = %v", err)
+	}
+	if result.Delivered != 1 || len(result.Dropped) != 1 || result.Remaining != 0 {
+		t.Errorf("result = %+v, want 1 delivered, 1 dropped, 0 remaining", result)
+	}
+	if result.Dropped[0].Tool != "unknown_tool" {
+		t.Errorf("dropped %s, want unknown_tool", result.Dropped[0].Tool)
+	}
+}
+
+func TestIsAvailable_FlushesOutboxOnReconnect(t *testing.T) {
+	t.Parallel()
+
+	var delivered atomic.Int32
+	server := httptest.NewServer(mockMCPHandler(t, map[string]func(args map[string]interface{}) (interface{}, *JSONRPCError){
+		"health_check": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			return map[string]interface{}{"status": "ok"}, nil
+		},
+		"send_message": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			delivered.Add(1)
+			return map[string]interface{}{"count": 1}, nil
+		},
+	}))
+	defer server.Close()
+
+	outbox, err := OpenOutbox(filepath.Join(t.TempDir(), OutboxFileName))
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	outbox.Enqueue("send_message", map[string]interface{}{"subject": "queued offline"})
+
+	c := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetry(1)), WithOutbox(outbox))
+	if !c.IsAvailable() {
+		t.Fatal("IsAvailable() = false, want true")
+	}
+	if delivered.Load() != 1 || outbox.Len() != 0 {
+		t.Errorf("delivered %d, outbox Len() = %d; want the queued message delivered", delivered.Load(), outbox.Len())
+	}
+}
+
+func TestOutbox_SharedFileAcrossInstances(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu        sync.Mutex
+		delivered = map[string]int{}
+	)
+	server := httptest.NewServer(mockMCPHandler(t, map[string]func(args map[string]interface{}) (interface{}, *JSONRPCError){
+		"send_message": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			mu.Lock()
+			delivered[args["subject"].(string)]++
+			mu.Unlock()
+			return map[string]interface{}{"count": 1}, nil
+		},
+	}))
+	defer server.Close()
+
+	// Two processes open the same outbox before either writes to it.
+	path := filepath.Join(t.TempDir(), OutboxFileName)
+	first, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	second, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	subjects := []string{"a", "b", "c", "d"}
+	for i, subject := range subjects {
+		o := first
+		if i%2 == 1 {
+			o = second
+		}
+		if _, err := o.Enqueue("send_message", map[string]interface{}{"subject": subject}); err != nil {
+			t.Fatalf("Enqueue(%s) error = %v", subject, err)
+		}
+	}
+	if first.Len() != len(subjects) || second.Len() != len(subjects) {
+		t.Fatalf("Len() = %d, %d; want both instances to see %d entries", first.Len(), second.Len(), len(subjects))
+	}
+
+	var wg sync.WaitGroup
+	for _, o := range []*Outbox{first, second} {
+		c := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetry(1)), WithOutbox(o))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.FlushOutbox(context.Background()); err != nil {
+				t.Errorf("FlushOutbox() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, subject := range subjects {
+		if delivered[subject] != 1 {
+			t.Errorf("%q delivered %d times, want once (all: %v)", subject, delivered[subject], delivered)
+		}
+	}
+	if first.Len() != 0 || second.Len() != 0 {
+		t.Errorf("Len() = %d, %d after flush, want 0", first.Len(), second.Len())
+	}
+}
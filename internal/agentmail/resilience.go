@@ -0,0 +1,257 @@
+package agentmail
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the server while the circuit
+// breaker is open. It wraps ErrServerUnavailable so IsServerUnavailable and
+// errors.Is(err, ErrServerUnavailable) keep working.
+var ErrCircuitOpen = &circuitOpenError{}
+
+type circuitOpenError struct{}
+
+func (*circuitOpenError) Error() string {
+	return "circuit breaker open: " + ErrServerUnavailable.Error()
+}
+func (*circuitOpenError) Unwrap() error { return ErrServerUnavailable }
+
+// RetryPolicy controls retries of transient Agent Mail failures.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first (<=1 disables retry).
+	MaxAttempts int
+	// BaseDelay is the initial backoff; each retry doubles it.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff before jitter.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewClient.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+	}
+}
+
+// backoff returns the delay before retry number attempt (1-based) using
+// "full jitter": a uniform random duration in [0, min(MaxDelay, Base*2^(n-1))].
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// isRetryable reports whether err is a transient failure worth retrying.
+// Timeouts are not retried: the caller's deadline has already been spent.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	if errors.Is(err, ErrServerUnavailable) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode >= 500 {
+		return true
+	}
+	return false
+}
+
+// BreakerState is the state of the client's circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerConfig configures the circuit breaker.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the breaker.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before allowing a probe.
+	Cooldown time.Duration
+}
+
+// DefaultBreakerConfig returns the breaker configuration used by NewClient.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		Cooldown:         30 * time.Second,
+	}
+}
+
+// circuitBreaker trips after consecutive transport failures so callers stop
+// waiting on a backend that is down.
+type circuitBreaker struct {
+	mu          sync.Mutex
+	cfg         BreakerConfig
+	state       BreakerState
+	failures    int
+	openedAt    time.Time
+	lastError   string
+	lastFailure time.Time
+	lastSuccess time.Time
+	now         func() time.Time
+}
+
+func newCircuitBreaker(cfg BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{cfg: cfg, state: BreakerClosed, now: time.Now}
+}
+
+// allow reports whether a request may proceed. An open breaker moves to
+// half-open once the cooldown elapses, letting a single probe through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) >= b.cfg.Cooldown {
+			b.state = BreakerHalfOpen
+			return true
+		}
+		return false
+	case BreakerHalfOpen:
+		// A probe is already in flight.
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.lastSuccess = b.now()
+}
+
+func (b *circuitBreaker) recordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.lastError = err.Error()
+	b.lastFailure = b.now()
+	if b.state == BreakerHalfOpen || (b.cfg.FailureThreshold > 0 && b.failures >= b.cfg.FailureThreshold) {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+// BackendHealth summarises Agent Mail backend health as seen by this client.
+type BackendHealth struct {
+	Breaker             BreakerState `json:"breaker"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	LastFailure         *time.Time   `json:"last_failure,omitempty"`
+	LastSuccess         *time.Time   `json:"last_success,omitempty"`
+	QueuedOutbound      int          `json:"queued_outbound"`
+}
+
+func (b *circuitBreaker) snapshot() BackendHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	h := BackendHealth{
+		Breaker:             b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if !b.lastFailure.IsZero() {
+		t := b.lastFailure
+		h.LastFailure = &t
+	}
+	if !b.lastSuccess.IsZero() {
+		t := b.lastSuccess
+		h.LastSuccess = &t
+	}
+	return h
+}
+
+// WithRetryPolicy overrides the retry policy for transient failures.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithBreaker overrides the circuit breaker configuration.
+func WithBreaker(cfg BreakerConfig) Option {
+	return func(c *Client) {
+		c.breaker = newCircuitBreaker(cfg)
+	}
+}
+
+// Health returns the client's view of backend health, including the number
+// of outbound operations waiting in the offline queue.
+func (c *Client) Health() BackendHealth {
+	h := BackendHealth{Breaker: BreakerClosed}
+	if c.breaker != nil {
+		h = c.breaker.snapshot()
+	}
+	if c.outbox != nil {
+		h.QueuedOutbound = c.outbox.Len()
+	}
+	return h
+}
+
+// doWithResilience runs call through the circuit breaker, retrying transient
+// failures with jittered exponential backoff.
+func (c *Client) doWithResilience(ctx context.Context, call func() ([]byte, error)) ([]byte, error) {
+	attempts := c.retry.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if !c.breaker.allow() {
+			if lastErr != nil {
+				return nil, lastErr
+			}
+			return nil, ErrCircuitOpen
+		}
+
+		result, err := call()
+		if err == nil || !isRetryable(err) {
+			// Non-transient errors (bad request, auth, tool errors) mean the
+			// backend answered, so they count as healthy for the breaker.
+			// Timeouts are not retried but still count against it.
+			if errors.Is(err, ErrTimeout) {
+				c.breaker.recordFailure(err)
+			} else {
+				c.breaker.recordSuccess()
+			}
+			return result, err
+		}
+
+		c.breaker.recordFailure(err)
+		lastErr = err
+
+		if attempt == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, lastErr
+		case <-time.After(c.retry.backoff(attempt)):
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,163 @@
+package agentmail
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetry(attempts int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+}
+
+func TestRetryPolicyBackoffBounds(t *testing.T) {
+	t.Parallel()
+
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 40 * time.Millisecond}
+	for attempt := 1; attempt <= 6; attempt++ {
+		for i := 0; i < 50; i++ {
+			d := p.backoff(attempt)
+			if d < 0 || d > p.MaxDelay {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", attempt, d, p.MaxDelay)
+			}
+		}
+	}
+	if d := (RetryPolicy{}).backoff(3); d != 0 {
+		t.Errorf("zero policy backoff = %v, want 0", d)
+	}
+}
+
+func TestCallTool_RetriesTransientFailure(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	inner := mockMCPHandler(t, map[string]func(args map[string]interface{}) (interface{}, *JSONRPCError){
+		"health_check": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			return map[string]string{"status": "ok"}, nil
+		},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetry(3)))
+	if _, err := c.callTool(context.Background(), "health_check", nil); err != nil {
+		t.Fatalf("callTool() error = %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("server calls = %d, want 3", got)
+	}
+	if h := c.Health(); h.Breaker != BreakerClosed || h.ConsecutiveFailures != 0 {
+		t.Errorf("health after recovery = %+v, want closed with no failures", h)
+	}
+}
+
+func TestCallTool_DoesNotRetryClientErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetry(3)))
+	if _, err := c.callTool(context.Background(), "any", nil); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("server calls = %d, want 1", got)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	t.Parallel()
+
+	var healthy atomic.Bool
+	var calls atomic.Int32
+	inner := mockMCPHandler(t, map[string]func(args map[string]interface{}) (interface{}, *JSONRPCError){
+		"health_check": func(args map[string]interface{}) (interface{}, *JSONRPCError) {
+			return map[string]string{"status": "ok"}, nil
+		},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		inner.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	c := NewClient(
+		WithBaseURL(server.URL),
+		WithRetryPolicy(fastRetry(1)),
+		WithBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: time.Hour}),
+	)
+	now := time.Now()
+	c.breaker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := c.callTool(ctx, "health_check", nil); err == nil {
+			t.Fatal("expected failure while server is unhealthy")
+		}
+	}
+	if h := c.Health(); h.Breaker != BreakerOpen || h.ConsecutiveFailures != 2 || h.LastError == "" {
+		t.Fatalf("health = %+v, want open breaker with 2 failures", h)
+	}
+
+	before := calls.Load()
+	_, err := c.callTool(ctx, "health_check", nil)
+	if !errors.Is(err, ErrCircuitOpen) || !IsServerUnavailable(err) {
+		t.Fatalf("err = %v, want ErrCircuitOpen wrapping ErrServerUnavailable", err)
+	}
+	if calls.Load() != before {
+		t.Error("open breaker should not contact the server")
+	}
+
+	// After the cooldown a single probe goes through and closes the breaker.
+	healthy.Store(true)
+	now = now.Add(2 * time.Hour)
+	if _, err := c.callTool(ctx, "health_check", nil); err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+	if h := c.Health(); h.Breaker != BreakerClosed || h.LastSuccess == nil {
+		t.Errorf("health = %+v, want closed after successful probe", h)
+	}
+}
+
+func TestCircuitBreakerHalfOpenFailureReopens(t *testing.T) {
+	t.Parallel()
+
+	b := newCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.recordFailure(errors.New("down"))
+	if b.allow() {
+		t.Fatal("open breaker allowed a request")
+	}
+	now = now.Add(2 * time.Minute)
+	if !b.allow() {
+		t.Fatal("breaker did not allow a probe after cooldown")
+	}
+	if b.allow() {
+		t.Fatal("half-open breaker allowed a second concurrent probe")
+	}
+	b.recordFailure(errors.New("still down"))
+	if got := b.snapshot().Breaker; got != BreakerOpen {
+		t.Errorf("state after failed probe = %s, want open", got)
+	}
+}
@@ -107,6 +107,21 @@ func (c *Client) Whois(ctx context.Context, projectKey, agentName string, includ
 
 // SendMessage sends a message to one or more agents.
 func (c *Client) SendMessage(ctx context.Context, opts SendMessageOptions) (*SendResult, error) {
+	result, err := c.callTool(ctx, "send_message", sendMessageArgs(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var sendResult SendResult
+	if err := json.Unmarshal(result, &sendResult); err != nil {
+		return nil, NewAPIError("send_message", 0, err)
+	}
+
+	return &sendResult, nil
+}
+
+// sendMessageArgs builds the send_message tool arguments.
+func sendMessageArgs(opts SendMessageOptions) map[string]interface{} {
 	args := map[string]interface{}{
 		"project_key": opts.ProjectKey,
 		"sender_name": opts.SenderName,
@@ -132,18 +147,7 @@ func (c *Client) SendMessage(ctx context.Context, opts SendMessageOptions) (*Sen
 	if opts.ConvertImages != nil {
 		args["convert_images"] = *opts.ConvertImages
 	}
-
-	result, err := c.callTool(ctx, "send_message", args)
-	if err != nil {
-		return nil, err
-	}
-
-	var sendResult SendResult
-	if err := json.Unmarshal(result, &sendResult); err != nil {
-		return nil, NewAPIError("send_message", 0, err)
-	}
-
-	return &sendResult, nil
+	return args
 }
 
 // ReplyMessage replies to an existing message.
@@ -381,6 +385,16 @@ func (c *Client) SummarizeThread(ctx context.Context, opts SummarizeThreadOption
 
 // ReservePaths requests file path reservations.
 func (c *Client) ReservePaths(ctx context.Context, opts FileReservationOptions) (*ReservationResult, error) {
+	result, err := c.callTool(ctx, "file_reservation_paths", reservePathsArgs(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseReservationResult(result)
+}
+
+// reservePathsArgs builds the file_reservation_paths tool arguments.
+func reservePathsArgs(opts FileReservationOptions) map[string]interface{} {
 	args := map[string]interface{}{
 		"project_key": opts.ProjectKey,
 		"agent_name":  opts.AgentName,
@@ -395,12 +409,11 @@ func (c *Client) ReservePaths(ctx context.Context, opts FileReservationOptions)
 	if opts.Reason != "" {
 		args["reason"] = opts.Reason
 	}
+	return args
+}
 
-	result, err := c.callTool(ctx, "file_reservation_paths", args)
-	if err != nil {
-		return nil, err
-	}
-
+// parseReservationResult decodes a file_reservation_paths result and reports conflicts.
+func parseReservationResult(result json.RawMessage) (*ReservationResult, error) {
 	var reservationResult ReservationResult
 	if err := json.Unmarshal(result, &reservationResult); err != nil {
 		return nil, NewAPIError("file_reservation_paths", 0, err)
@@ -110,18 +110,33 @@ func (m *UnifiedMessenger) Inbox(ctx context.Context) ([]UnifiedMessage, error)
 	return unified, nil
 }
 
+// queueingSender is implemented by clients that can queue a message in an
+// offline outbox (see Client.SendMessageOrQueue).
+type queueingSender interface {
+	SendMessageOrQueue(ctx context.Context, opts SendMessageOptions) (*SendResult, bool, error)
+}
+
 // Send sends a message via the preferred channel (defaulting to Agent Mail if available, else BD)
-// For now, it tries Agent Mail first.
+// For now, it tries Agent Mail first. With neither channel reachable, the
+// message is queued in the Agent Mail client's outbox, if it has one.
 func (m *UnifiedMessenger) Send(ctx context.Context, to, subject, body string) error {
+	opts := SendMessageOptions{
+		ProjectKey: m.projectKey,
+		SenderName: m.agentName,
+		To:         []string{to},
+		Subject:    subject,
+		BodyMD:     body,
+	}
+	queuer, canQueue := m.amClient.(queueingSender)
+
 	// Try Agent Mail first
 	if m.amClient != nil && m.amClient.IsAvailable() {
-		_, err := m.amClient.SendMessage(ctx, SendMessageOptions{
-			ProjectKey: m.projectKey,
-			SenderName: m.agentName,
-			To:         []string{to},
-			Subject:    subject,
-			BodyMD:     body,
-		})
+		var err error
+		if canQueue {
+			_, _, err = queuer.SendMessageOrQueue(ctx, opts)
+		} else {
+			_, err = m.amClient.SendMessage(ctx, opts)
+		}
 		if err == nil {
 			return nil
 		}
@@ -135,6 +150,12 @@ func (m *UnifiedMessenger) Send(ctx context.Context, to, subject, body string) e
 		return m.bdClient.Send(ctx, to, body)
 	}
 
+	if canQueue {
+		if _, _, err := queuer.SendMessageOrQueue(ctx, opts); err == nil {
+			return nil
+		}
+	}
+
 	return fmt.Errorf("no message channels available")
 }
 
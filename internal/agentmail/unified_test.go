@@ -186,6 +186,37 @@ func TestUnifiedMessengerSend_FallsBackToBDWhenNoAgentMail(t *testing.T) {
 	}
 }
 
+// fakeQueueingAMClient is a fakeAMClient with an offline outbox.
+type fakeQueueingAMClient struct {
+	fakeAMClient
+	queued []SendMessageOptions
+}
+
+func (f *fakeQueueingAMClient) SendMessageOrQueue(ctx context.Context, opts SendMessageOptions) (*SendResult, bool, error) {
+	if !f.available {
+		f.queued = append(f.queued, opts)
+		return nil, true, nil
+	}
+	res, err := f.SendMessage(ctx, opts)
+	return res, false, err
+}
+
+func TestUnifiedMessengerSend_QueuesWhenNoChannelReachable(t *testing.T) {
+	am := &fakeQueueingAMClient{}
+	unified := &UnifiedMessenger{
+		amClient:   am,
+		projectKey: "proj",
+		agentName:  "agent",
+	}
+
+	if err := unified.Send(context.Background(), "target", "subject", "body"); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if len(am.queued) != 1 || len(am.sendCalls) != 0 {
+		t.Fatalf("queued %d, sent %d; want the message queued", len(am.queued), len(am.sendCalls))
+	}
+}
+
 func TestUnifiedMessengerRead_AgentMailMarksRead(t *testing.T) {
 	now := time.Now()
 	am := &fakeAMClient{
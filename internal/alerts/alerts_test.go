@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/process"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 )
 
@@ -954,6 +955,77 @@ func TestPrintAlerts_DisabledConfig(t *testing.T) {
 	}
 }
 
+func TestGeneratorDetectResourceUsage(t *testing.T) {
+	t.Parallel()
+
+	pane := tmux.Pane{ID: "%3", Type: tmux.AgentClaude}
+	tests := []struct {
+		name  string
+		cfg   Config
+		usage process.Usage
+		want  map[AlertType]Severity
+	}{
+		{
+			name:  "within limits",
+			cfg:   DefaultConfig(),
+			usage: process.Usage{PID: 10, CPUPercent: 120, MemPercent: 10},
+			want:  map[AlertType]Severity{},
+		},
+		{
+			name:  "cpu runaway",
+			cfg:   DefaultConfig(),
+			usage: process.Usage{PID: 10, CPUPercent: 400, MemPercent: 10},
+			want:  map[AlertType]Severity{AlertHighCPU: SeverityWarning},
+		},
+		{
+			name:  "memory warning",
+			cfg:   DefaultConfig(),
+			usage: process.Usage{PID: 10, CPUPercent: 5, MemPercent: 60},
+			want:  map[AlertType]Severity{AlertHighMemory: SeverityWarning},
+		},
+		{
+			name:  "memory critical",
+			cfg:   DefaultConfig(),
+			usage: process.Usage{PID: 10, CPUPercent: 350, MemPercent: 85, Top: "go"},
+			want:  map[AlertType]Severity{AlertHighCPU: SeverityWarning, AlertHighMemory: SeverityCritical},
+		},
+		{
+			name:  "custom thresholds",
+			cfg:   Config{Enabled: true, AgentCPUPercent: 100, AgentMemoryPercent: 5},
+			usage: process.Usage{PID: 10, CPUPercent: 150, MemPercent: 6},
+			want:  map[AlertType]Severity{AlertHighCPU: SeverityWarning, AlertHighMemory: SeverityWarning},
+		},
+		{
+			name:  "zero thresholds use defaults",
+			cfg:   Config{Enabled: true},
+			usage: process.Usage{PID: 10, CPUPercent: 150, MemPercent: 6},
+			want:  map[AlertType]Severity{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := NewGenerator(tt.cfg).detectResourceUsage("sess", pane, tt.usage)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d alerts, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for _, a := range got {
+				if sev, ok := tt.want[a.Type]; !ok || a.Severity != sev {
+					t.Errorf("alert %s/%s not expected (want %v)", a.Type, a.Severity, tt.want)
+				}
+				if a.Source != "resources" || a.Session != "sess" || a.Pane != "%3" {
+					t.Errorf("alert = %+v", a)
+				}
+				if a.Context["pid"] != 10 {
+					t.Errorf("context pid = %v", a.Context["pid"])
+				}
+			}
+		})
+	}
+}
+
 func captureStdout(t *testing.T, fn func() error) ([]byte, error) {
 	t.Helper()
 
@@ -1,6 +1,7 @@
 package alerts
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/bv"
+	"github.com/Dicklesworthstone/ntm/internal/process"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 )
 
@@ -73,6 +75,13 @@ func (g *Generator) GenerateAll() ([]Alert, []string) {
 		alerts = append(alerts, agentAlerts...)
 	}
 
+	// Check agent process resource usage
+	if resourceAlerts, err := g.checkResourceUsage(); err != nil {
+		failed = append(failed, "resources")
+	} else {
+		alerts = append(alerts, resourceAlerts...)
+	}
+
 	// Check disk space
 	if alert, err := g.checkDiskSpace(); err != nil {
 		failed = append(failed, "disk")
@@ -236,6 +245,120 @@ func (g *Generator) detectRateLimit(session string, pane tmux.Pane, lines []stri
 	return nil
 }
 
+// criticalMemoryPercent is the share of system memory at which a runaway
+// agent process tree is reported as critical rather than a warning.
+const criticalMemoryPercent = 80.0
+
+// checkResourceUsage samples the process tree of every agent pane and
+// alerts on runaways, such as a build loop consuming all RAM.
+func (g *Generator) checkResourceUsage() ([]Alert, error) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+
+	type paneRef struct {
+		session string
+		pane    tmux.Pane
+	}
+	byPID := make(map[int]paneRef)
+	var pids []int
+	for _, sess := range sessions {
+		if g.config.SessionFilter != "" && sess.Name != g.config.SessionFilter {
+			continue
+		}
+		panes, err := tmux.GetPanes(sess.Name)
+		if err != nil {
+			continue
+		}
+		for _, pane := range panes {
+			if pane.PID > 0 {
+				byPID[pane.PID] = paneRef{session: sess.Name, pane: pane}
+				pids = append(pids, pane.PID)
+			}
+		}
+	}
+	if len(pids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	usage, err := process.DefaultSampler().SampleTrees(ctx, pids)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []Alert
+	for pid, u := range usage {
+		ref := byPID[pid]
+		alerts = append(alerts, g.detectResourceUsage(ref.session, ref.pane, u)...)
+	}
+	return alerts, nil
+}
+
+// detectResourceUsage compares one pane's process tree usage to the
+// configured thresholds.
+func (g *Generator) detectResourceUsage(session string, pane tmux.Pane, u process.Usage) []Alert {
+	cpuLimit := g.config.AgentCPUPercent
+	if cpuLimit <= 0 {
+		cpuLimit = DefaultConfig().AgentCPUPercent
+	}
+	memLimit := g.config.AgentMemoryPercent
+	if memLimit <= 0 {
+		memLimit = DefaultConfig().AgentMemoryPercent
+	}
+
+	ctx := map[string]interface{}{
+		"pid":         u.PID,
+		"processes":   u.Processes,
+		"cpu_percent": u.CPUPercent,
+		"rss_bytes":   u.RSSBytes,
+		"mem_percent": u.MemPercent,
+		"agent_type":  string(pane.Type),
+	}
+	if u.Top != "" {
+		ctx["top_process"] = u.Top
+	}
+
+	var alerts []Alert
+	if u.CPUPercent >= cpuLimit {
+		alerts = append(alerts, Alert{
+			ID:         generateAlertID(AlertHighCPU, session, pane.ID),
+			Type:       AlertHighCPU,
+			Severity:   SeverityWarning,
+			Source:     "resources",
+			Message:    fmt.Sprintf("Agent processes using %.0f%% CPU (threshold %.0f%%)", u.CPUPercent, cpuLimit),
+			Session:    session,
+			Pane:       pane.ID,
+			Context:    ctx,
+			CreatedAt:  time.Now(),
+			LastSeenAt: time.Now(),
+			Count:      1,
+		})
+	}
+	if u.MemPercent >= memLimit {
+		severity := SeverityWarning
+		if u.MemPercent >= criticalMemoryPercent {
+			severity = SeverityCritical
+		}
+		alerts = append(alerts, Alert{
+			ID:         generateAlertID(AlertHighMemory, session, pane.ID),
+			Type:       AlertHighMemory,
+			Severity:   severity,
+			Source:     "resources",
+			Message:    fmt.Sprintf("Agent processes using %.0f%% of system memory (threshold %.0f%%)", u.MemPercent, memLimit),
+			Session:    session,
+			Pane:       pane.ID,
+			Context:    ctx,
+			CreatedAt:  time.Now(),
+			LastSeenAt: time.Now(),
+			Count:      1,
+		})
+	}
+	return alerts
+}
+
 // checkDiskSpace is implemented in platform-specific files:
 // - generator_unix.go for Unix systems
 // - (stub implementation returns nil on unsupported platforms)
@@ -16,8 +16,10 @@ const (
 	AlertAgentCrashed AlertType = "agent_crashed"
 	// AlertAgentError indicates an error state detected in agent output
 	AlertAgentError AlertType = "agent_error"
-	// AlertHighCPU indicates excessive CPU consumption (reserved for future)
+	// AlertHighCPU indicates an agent's process tree is using excessive CPU
 	AlertHighCPU AlertType = "high_cpu"
+	// AlertHighMemory indicates an agent's process tree is using excessive memory
+	AlertHighMemory AlertType = "high_memory"
 	// AlertDiskLow indicates low disk space on the system
 	AlertDiskLow AlertType = "disk_low"
 	// AlertBeadStale indicates an in-progress bead with no recent activity
@@ -130,6 +132,10 @@ type Config struct {
 	SessionFilter string `json:"session_filter,omitempty"`
 	// ContextWarningThreshold is the context usage percentage that triggers a warning (0-100)
 	ContextWarningThreshold float64 `toml:"context_warning_threshold" json:"context_warning_threshold,omitempty"`
+	// AgentCPUPercent is the CPU usage of a pane's process tree that triggers an alert (100 = one core)
+	AgentCPUPercent float64 `toml:"agent_cpu_percent" json:"agent_cpu_percent,omitempty"`
+	// AgentMemoryPercent is the share of system memory a pane's process tree may use before alerting
+	AgentMemoryPercent float64 `toml:"agent_memory_percent" json:"agent_memory_percent,omitempty"`
 }
 
 // DefaultConfig returns sensible default alert thresholds
@@ -142,6 +148,8 @@ func DefaultConfig() Config {
 		ResolvedPruneMinutes:    60,
 		Enabled:                 true,
 		ContextWarningThreshold: 75.0, // Warn at 75% context usage
+		AgentCPUPercent:         300,  // Three cores busy
+		AgentMemoryPercent:      50,
 	}
 }
 
@@ -4,23 +4,37 @@ package archive
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/storage"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 const (
-	// DefaultInterval is the default capture interval.
-	DefaultInterval = 30 * time.Second
+	// DefaultInterval is the default capture interval while a pane's output
+	// is changing.
+	DefaultInterval = 5 * time.Second
+
+	// DefaultMaxInterval is the default interval ceiling for idle panes.
+	DefaultMaxInterval = 2 * time.Minute
+
+	// DefaultBackoff is the default interval multiplier per idle capture.
+	DefaultBackoff = 2.0
 
 	// DefaultOutputDir is the default archive output directory.
 	DefaultOutputDir = "~/.ntm/archive"
@@ -29,6 +43,28 @@ const (
 	DefaultLinesPerCapture = 500
 )
 
+var (
+	outputDirMu       sync.RWMutex
+	outputDirOverride string
+)
+
+// SetOutputDir moves archives to dir. An empty dir restores DefaultOutputDir.
+func SetOutputDir(dir string) {
+	outputDirMu.Lock()
+	defer outputDirMu.Unlock()
+	outputDirOverride = dir
+}
+
+// OutputDir returns the directory archives are written to by default.
+func OutputDir() string {
+	outputDirMu.RLock()
+	defer outputDirMu.RUnlock()
+	if outputDirOverride != "" {
+		return util.ExpandPath(outputDirOverride)
+	}
+	return util.ExpandPath(DefaultOutputDir)
+}
+
 // ArchiveRecord represents a single CASS-compatible archive entry.
 type ArchiveRecord struct {
 	Session   string    `json:"session"`
@@ -39,49 +75,140 @@ type ArchiveRecord struct {
 	Timestamp time.Time `json:"timestamp"`
 	Content   string    `json:"content"`
 	Lines     int       `json:"lines"`
-	Sequence  int       `json:"sequence"` // Monotonic sequence number per pane
+	Sequence  int       `json:"sequence"`        // Monotonic sequence number per pane
+	Human     bool      `json:"human,omitempty"` // Captured while a human had taken over the pane
 }
 
 // PaneState tracks the state of a single pane for incremental capture.
 type PaneState struct {
-	LastHash    uint64 // Hash of last captured content for deduplication
+	Name        string // Archived pane name, e.g. cc_2
+	LastHash    uint64 // Hash of last captured content, normalized, for deduplication
 	LastCapture time.Time
 	Sequence    int
 	TotalLines  int
 	LastContent string // Last captured content for diff
+
+	// Adaptive scheduling: Interval starts at the archiver's interval,
+	// resets to it when output changes and grows by the backoff factor on
+	// each capture that finds nothing new.
+	Interval     time.Duration
+	NextCapture  time.Time
+	LastChange   time.Time
+	Captures     int // Captures taken
+	Changes      int // Captures that found new output
+	IdleCaptures int // Consecutive captures without new output
+	Deduped      int // Captures trimmed or skipped as redraws of the previous one
+
+	// Mode is how the pane is ingested: ModePoll or ModePipe.
+	Mode          string
+	StreamedLines int // Lines received through pipe-pane
+
+	streamer paneStreamer // Attached pipe-pane stream, nil when polled
+	pane     tmux.Pane    // Pane being streamed
+	human    bool         // A human has taken over the streamed pane
+	pending  []string     // Streamed lines not yet archived
+	lastNorm []string     // Normalized lines of the last capture or streamed batch
+}
+
+// schedule sets the pane's next capture after a capture at now that did or
+// did not find new output.
+func (s *PaneState) schedule(changed bool, now time.Time, minInterval, maxInterval time.Duration, backoff float64) {
+	s.Captures++
+	if changed || s.Interval == 0 {
+		s.Interval = minInterval
+	}
+	if changed {
+		s.Changes++
+		s.IdleCaptures = 0
+		s.LastChange = now
+	} else {
+		s.IdleCaptures++
+		s.Interval = time.Duration(float64(s.Interval) * backoff)
+	}
+	if s.Interval > maxInterval {
+		s.Interval = maxInterval
+	}
+	if s.Interval < minInterval {
+		s.Interval = minInterval
+	}
+	s.NextCapture = now.Add(s.Interval)
 }
 
 // Archiver captures agent output for CASS indexing.
 type Archiver struct {
 	sessionName     string
 	outputDir       string
-	interval        time.Duration
+	interval        time.Duration // Capture interval while output changes
+	maxInterval     time.Duration // Interval ceiling for idle panes
+	backoff         float64
+	dedupeThreshold float64
 	linesPerCapture int
 	paneStates      map[int]*PaneState // Keyed by pane index
 	mu              sync.RWMutex
 	file            *os.File
-	encoder         *json.Encoder
+	fileDate        string // Day the open archive file is for
 	started         time.Time
 	totalRecords    int
 	onRecord        func(*ArchiveRecord) // Optional callback for testing
+	beforeRecord    func(*ArchiveRecord) bool
+	minFreeBytes    uint64
+	onDiskState     func(storage.Disk)
+	paused          bool // Archiving is paused for low disk space
+	checkDisk       func(path string, minFree uint64) (storage.Disk, error)
+
+	// Pipe-pane ingestion (ModePipe)
+	mode        string
+	streamCh    chan streamBatch
+	streamDone  chan struct{}
+	stopOnce    sync.Once
+	queueFull   atomic.Int64 // Batches that had to wait for room in streamCh
+	newStreamer func(target string, callback tmux.StreamCallback) paneStreamer
 }
 
 // ArchiverOptions configures the Archiver.
 type ArchiverOptions struct {
-	SessionName     string
-	OutputDir       string
-	Interval        time.Duration
+	SessionName string
+	OutputDir   string
+	Interval    time.Duration // Capture interval while a pane's output changes
+	// MaxInterval caps the interval of idle panes, which grows by Backoff
+	// on each capture that finds no new output. MaxInterval <= Interval or
+	// Backoff <= 1 captures every pane every Interval.
+	MaxInterval     time.Duration
+	Backoff         float64
 	LinesPerCapture int
+	// Mode selects how pane output is ingested: ModePoll (default) or
+	// ModePipe. Streamed output is archived every Interval or once
+	// LinesPerCapture lines are pending.
+	Mode string
+	// StreamQueue is how many streamed batches are buffered before pane
+	// readers block (ModePipe only).
+	StreamQueue int
+	// DedupeThreshold is the similarity (0-1) to the previous capture above
+	// which new output counts as a redraw and only its novel lines are
+	// archived. 1 only drops captures identical after normalization.
+	DedupeThreshold float64
 	OnRecord        func(*ArchiveRecord) // Callback when record is written
+	// BeforeRecord is called before a record is written. It may rewrite
+	// the record's Content and returns false to drop the record.
+	BeforeRecord func(*ArchiveRecord) bool
+	// MinFreeBytes pauses archiving while free space on the archive's
+	// filesystem is below it (0 = never pause).
+	MinFreeBytes uint64
+	// OnDiskState is called when archiving pauses for low disk space or
+	// resumes after it recovers.
+	OnDiskState func(storage.Disk)
 }
 
 // DefaultArchiverOptions returns sensible defaults.
 func DefaultArchiverOptions(sessionName string) ArchiverOptions {
 	return ArchiverOptions{
 		SessionName:     sessionName,
-		OutputDir:       util.ExpandPath(DefaultOutputDir),
+		OutputDir:       OutputDir(),
 		Interval:        DefaultInterval,
+		MaxInterval:     DefaultMaxInterval,
+		Backoff:         DefaultBackoff,
 		LinesPerCapture: DefaultLinesPerCapture,
+		DedupeThreshold: DefaultDedupeThreshold,
 	}
 }
 
@@ -91,43 +218,72 @@ func NewArchiver(opts ArchiverOptions) (*Archiver, error) {
 		return nil, fmt.Errorf("session name required")
 	}
 	if opts.OutputDir == "" {
-		opts.OutputDir = util.ExpandPath(DefaultOutputDir)
+		opts.OutputDir = OutputDir()
 	}
 	if opts.Interval == 0 {
 		opts.Interval = DefaultInterval
 	}
+	if opts.MaxInterval < opts.Interval {
+		opts.MaxInterval = opts.Interval
+	}
+	if opts.Backoff < 1 {
+		opts.Backoff = 1
+	}
 	if opts.LinesPerCapture == 0 {
 		opts.LinesPerCapture = DefaultLinesPerCapture
 	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = ModePoll
+	case ModePoll, ModePipe:
+	default:
+		return nil, fmt.Errorf("unknown archive mode %q (valid: %s, %s)", opts.Mode, ModePoll, ModePipe)
+	}
+	if opts.StreamQueue <= 0 {
+		opts.StreamQueue = DefaultStreamQueue
+	}
+	if opts.DedupeThreshold <= 0 || opts.DedupeThreshold > 1 {
+		opts.DedupeThreshold = DefaultDedupeThreshold
+	}
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating archive directory: %w", err)
 	}
 
-	// Create archive file (one per session, append mode)
-	filename := fmt.Sprintf("%s_%s.jsonl", opts.SessionName, time.Now().Format("2006-01-02"))
-	filePath := filepath.Join(opts.OutputDir, filename)
-
-	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	// Create archive file (one per session and day, append mode)
+	date := time.Now().Format("2006-01-02")
+	f, err := openArchiveFile(opts.OutputDir, opts.SessionName, date)
 	if err != nil {
-		return nil, fmt.Errorf("opening archive file: %w", err)
+		return nil, err
 	}
 
 	return &Archiver{
 		sessionName:     opts.SessionName,
 		outputDir:       opts.OutputDir,
 		interval:        opts.Interval,
+		maxInterval:     opts.MaxInterval,
+		backoff:         opts.Backoff,
+		dedupeThreshold: opts.DedupeThreshold,
 		linesPerCapture: opts.LinesPerCapture,
 		paneStates:      make(map[int]*PaneState),
 		file:            f,
-		encoder:         json.NewEncoder(f),
+		fileDate:        date,
 		started:         time.Now(),
 		onRecord:        opts.OnRecord,
+		beforeRecord:    opts.BeforeRecord,
+		minFreeBytes:    opts.MinFreeBytes,
+		onDiskState:     opts.OnDiskState,
+		checkDisk:       storage.CheckDisk,
+		mode:            opts.Mode,
+		streamCh:        make(chan streamBatch, opts.StreamQueue),
+		streamDone:      make(chan struct{}),
+		newStreamer:     newTmuxStreamer,
 	}, nil
 }
 
 // Run starts the archive loop. It blocks until the context is cancelled.
+// The loop wakes every interval and captures the panes that are due.
 func (a *Archiver) Run(ctx context.Context) error {
 	ticker := time.NewTicker(a.interval)
 	defer ticker.Stop()
@@ -142,10 +298,13 @@ func (a *Archiver) Run(ctx context.Context) error {
 		select {
 		case <-ctx.Done():
 			// Final flush on shutdown
+			a.stopStreams()
 			if err := a.flush(); err != nil {
 				slog.Warn("archive flush on shutdown error", "session", a.sessionName, "error", err)
 			}
 			return ctx.Err()
+		case batch := <-a.streamCh:
+			a.ingest(batch)
 		case <-ticker.C:
 			if err := a.archiveNewContent(ctx); err != nil {
 				// Log but continue
@@ -166,10 +325,20 @@ func (a *Archiver) archiveNewContent(ctx context.Context) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	if a.diskLow() {
+		return nil
+	}
+
+	// Panes a human has taken over are still archived, marked as human work.
+	takenOver, _ := tmux.PaneOptions(a.sessionName, tmux.PaneTakeoverOption)
+
+	now := time.Now()
+	present := make(map[int]bool, len(session.Panes))
 	for _, pane := range session.Panes {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		present[pane.Index] = true
 
 		// Skip control pane (pane 1)
 		if pane.Index == 1 {
@@ -181,17 +350,87 @@ func (a *Archiver) archiveNewContent(ctx context.Context) error {
 			continue
 		}
 
-		if err := a.capturePane(ctx, pane); err != nil {
+		human := takenOver[pane.ID] != ""
+		state, seen := a.paneStates[pane.Index]
+		if a.mode == ModePipe && (!seen || state.Mode == ModePipe) {
+			a.streamPane(ctx, pane, state, human)
+			continue
+		}
+
+		// Idle panes are captured less often
+		if seen && now.Before(state.NextCapture) {
+			continue
+		}
+
+		if err := a.capturePane(ctx, pane, human); err != nil {
 			// Log but continue with other panes
 			slog.Warn("archive pane capture error", "pane", pane.Index, "error", err)
 		}
 	}
 
+	for index, state := range a.paneStates {
+		if !present[index] {
+			a.stopStream(state)
+		}
+	}
+
+	if err := a.writeCaptureStats(); err != nil {
+		slog.Debug("archive capture stats write error", "session", a.sessionName, "error", err)
+	}
 	return nil
 }
 
-// capturePane captures new content from a single pane.
-func (a *Archiver) capturePane(ctx context.Context, pane tmux.Pane) error {
+// streamPane archives what a streamed pane printed since the last tick, or
+// starts streaming a new pane. The caller must hold a.mu.
+func (a *Archiver) streamPane(ctx context.Context, pane tmux.Pane, state *PaneState, human bool) {
+	if state != nil && state.streamer != nil {
+		state.pane, state.human = pane, human
+		if err := a.flushPending(state); err != nil {
+			slog.Warn("archive stream write error", "pane", pane.Index, "error", err)
+		}
+		return
+	}
+	// pipe-pane only sees new output, so archive the scrollback first.
+	if err := a.capturePane(ctx, pane, human); err != nil {
+		slog.Warn("archive pane capture error", "pane", pane.Index, "error", err)
+		return
+	}
+	state = a.paneStates[pane.Index]
+	state.human = human
+	if !a.startStream(ctx, pane, state) {
+		state.Mode = ModePoll
+	}
+}
+
+// diskLow reports whether archiving is paused for low disk space, updating
+// the paused state and notifying OnDiskState when it changes. The caller
+// must hold a.mu.
+func (a *Archiver) diskLow() bool {
+	if a.minFreeBytes == 0 {
+		return false
+	}
+	d, err := a.checkDisk(a.outputDir, a.minFreeBytes)
+	if err != nil {
+		slog.Warn("archive disk check error", "dir", a.outputDir, "error", err)
+		return a.paused
+	}
+	if d.Low != a.paused {
+		a.paused = d.Low
+		if d.Low {
+			slog.Warn("archiving paused: low disk space", "session", a.sessionName, "free_bytes", d.FreeBytes, "min_free_bytes", a.minFreeBytes)
+		} else {
+			slog.Info("archiving resumed", "session", a.sessionName, "free_bytes", d.FreeBytes)
+		}
+		if a.onDiskState != nil {
+			a.onDiskState(d)
+		}
+	}
+	return a.paused
+}
+
+// capturePane captures new content from a single pane. human marks a pane
+// a human has taken over.
+func (a *Archiver) capturePane(ctx context.Context, pane tmux.Pane, human bool) error {
 	target := fmt.Sprintf("%s:1.%d", a.sessionName, pane.Index)
 
 	// Capture content
@@ -201,21 +440,31 @@ func (a *Archiver) capturePane(ctx context.Context, pane tmux.Pane) error {
 	}
 
 	// Get or create pane state
-	state, ok := a.paneStates[pane.Index]
-	if !ok {
-		state = &PaneState{}
+	state, seen := a.paneStates[pane.Index]
+	if !seen {
+		state = &PaneState{Mode: ModePoll}
 		a.paneStates[pane.Index] = state
 	}
 
-	// Check for new content using simple hash
-	contentHash := simpleHash(content)
-	if contentHash == state.LastHash && state.LastCapture.Add(a.interval*2).After(time.Now()) {
-		// No new content and recent capture - skip
+	// Check for new content using a hash of the normalized content, so
+	// spinner frames and ticking timers alone do not count as output
+	normalized := normalizeCapture(content)
+	contentHash := hashLines(normalized)
+	if contentHash == state.LastHash {
+		// No new content - back off
+		if seen && content != state.LastContent {
+			state.Deduped++
+			state.LastContent = content
+		}
+		state.schedule(false, time.Now(), a.interval, a.maxInterval, a.backoff)
 		return nil
 	}
 
-	// Find new content by diffing
-	newContent := findNewContent(state.LastContent, content)
+	// Find new content by diffing, then drop what only a redraw repeated
+	state.Name = fmt.Sprintf("%s_%d", pane.Type, pane.Index)
+	newContent := a.dedupe(state, findNewContent(state.LastContent, content), state.lastNorm)
+	state.lastNorm = normalized
+	state.schedule(newContent != "", time.Now(), a.interval, a.maxInterval, a.backoff)
 	if newContent == "" {
 		// Update hash but no new record needed
 		state.LastHash = contentHash
@@ -226,35 +475,333 @@ func (a *Archiver) capturePane(ctx context.Context, pane tmux.Pane) error {
 	// Update state
 	state.LastHash = contentHash
 	state.LastContent = content
-	state.LastCapture = time.Now()
-	state.Sequence++
-	state.TotalLines += countLines(newContent)
 
-	// Create record
-	paneName := fmt.Sprintf("%s_%d", pane.Type, pane.Index)
+	// The first capture is the pane's existing scrollback; only reports
+	// printed after that are new.
+	return a.appendRecord(pane, state, newContent, human, seen)
+}
+
+// appendRecord archives newContent of pane and, when reports is set and
+// the pane is not a human's, publishes the NTM-REPORT self-reports in it.
+func (a *Archiver) appendRecord(pane tmux.Pane, state *PaneState, newContent string, human, reports bool) error {
 	record := &ArchiveRecord{
 		Session:   a.sessionName,
-		Pane:      paneName,
+		Pane:      state.Name,
 		PaneIndex: pane.Index,
 		Agent:     string(pane.Type),
 		Model:     pane.Variant,
 		Timestamp: time.Now().UTC(),
 		Content:   newContent,
 		Lines:     countLines(newContent),
-		Sequence:  state.Sequence,
+		Sequence:  state.Sequence + 1,
+		Human:     human,
+	}
+	if a.beforeRecord != nil {
+		if !a.beforeRecord(record) {
+			return nil
+		}
+		record.Lines = countLines(record.Content)
 	}
 
-	// Write record
+	state.LastCapture = time.Now()
+	state.Sequence++
+	state.TotalLines += record.Lines
+
 	if err := a.writeRecord(record); err != nil {
 		return fmt.Errorf("writing record: %w", err)
 	}
 
+	// A human's output is not an agent's report.
+	if reports && !human {
+		emitReports(record)
+	}
+	return nil
+}
+
+// emitReports publishes an agent.progress event for each NTM-REPORT
+// self-report in a record's new content.
+func emitReports(record *ArchiveRecord) {
+	for _, r := range agent.ParseReports(record.Content) {
+		data, err := json.Marshal(r)
+		if err != nil {
+			continue
+		}
+		details := map[string]string{
+			"pane_index": fmt.Sprintf("%d", record.PaneIndex),
+			"report":     string(data),
+		}
+		msg := fmt.Sprintf("%s reported", record.Pane)
+		if r.TaskID != "" {
+			details["task_id"] = r.TaskID
+			msg += " " + r.TaskID
+		}
+		if r.Percent != nil {
+			details["percent"] = fmt.Sprintf("%g", *r.Percent)
+			msg += fmt.Sprintf(" %g%%", *r.Percent)
+		}
+		if r.Status != "" {
+			details["status"] = r.Status
+			msg += " " + r.Status
+		}
+		if r.Summary != "" {
+			msg += ": " + r.Summary
+		}
+		if len(r.Blockers) > 0 {
+			details["blockers"] = strings.Join(r.Blockers, "; ")
+		}
+		if len(r.FilesTouched) > 0 {
+			details["files_touched"] = strings.Join(r.FilesTouched, ",")
+		}
+		events.DefaultEmitter().Emit(events.NewWebhookEvent(
+			events.WebhookAgentProgress,
+			record.Session,
+			record.Pane,
+			record.Agent,
+			msg,
+			details,
+		))
+	}
+}
+
+// ReadRecords loads the records of an archive file, decrypting them if
+// needed. Corrupt or undecryptable lines are skipped.
+func ReadRecords(path string) ([]ArchiveRecord, error) {
+	var records []ArchiveRecord
+	_, err := jsonlutil.ScanFile(path, jsonlutil.Options{}, func(_ int, line []byte) error {
+		plain, err := decryptJSONLine(line)
+		if err != nil {
+			return nil
+		}
+		var record ArchiveRecord
+		if err := json.Unmarshal(plain, &record); err != nil {
+			return nil
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	return records, nil
+}
+
+// PurgeSession removes the archived records of a session from dir
+// (OutputDir if empty). If before is non-zero, only records captured
+// before it are removed and lines that cannot be read are kept; otherwise
+// the session's archive files are deleted outright. It returns the number of
+// records removed.
+func PurgeSession(dir, session string, before time.Time) (int, error) {
+	if dir == "" {
+		dir = OutputDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("reading archive directory: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !isSessionArchive(entry.Name(), session) {
+			continue
+		}
+		n, err := purgeFile(filepath.Join(dir, entry.Name()), before)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// RecentPaneRecords returns up to n of the most recent archived records of
+// one pane of session from dir (OutputDir if empty), oldest first.
+func RecentPaneRecords(dir, session string, paneIndex, n int) ([]ArchiveRecord, error) {
+	if dir == "" {
+		dir = OutputDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading archive directory: %w", err)
+	}
+
+	// Archive names end in the date, so walking them backwards reads the
+	// newest files first and stops once enough records are found.
+	var records []ArchiveRecord
+	for i := len(entries) - 1; i >= 0 && len(records) < n; i-- {
+		if entries[i].IsDir() || !isSessionArchive(entries[i].Name(), session) {
+			continue
+		}
+		fileRecords, err := ReadRecords(filepath.Join(dir, entries[i].Name()))
+		if err != nil {
+			return nil, err
+		}
+		var pane []ArchiveRecord
+		for _, r := range fileRecords {
+			if r.PaneIndex == paneIndex {
+				pane = append(pane, r)
+			}
+		}
+		records = append(pane, records...)
+	}
+	if len(records) > n {
+		records = records[len(records)-n:]
+	}
+	return records, nil
+}
+
+// SessionRecords returns the archived records of session from dir
+// (OutputDir if empty) captured at or after since, oldest first.
+func SessionRecords(dir, session string, since time.Time) ([]ArchiveRecord, error) {
+	if dir == "" {
+		dir = OutputDir()
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading archive directory: %w", err)
+	}
+
+	// Files are named by local date; a day of slack covers clock zones.
+	sinceDay := since.AddDate(0, 0, -1).Format("2006-01-02")
+	var records []ArchiveRecord
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isSessionArchive(name, session) {
+			continue
+		}
+		// Files of days before since hold nothing newer.
+		if !since.IsZero() && strings.TrimSuffix(strings.TrimPrefix(name, session+"_"), ".jsonl") < sinceDay {
+			continue
+		}
+		fileRecords, err := ReadRecords(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range fileRecords {
+			if !r.Timestamp.Before(since) {
+				records = append(records, r)
+			}
+		}
+	}
+	return records, nil
+}
+
+// isSessionArchive reports whether name is an archive file
+// (<session>_<date>.jsonl) of session.
+func isSessionArchive(name, session string) bool {
+	rest, ok := strings.CutPrefix(name, session+"_")
+	if !ok {
+		return false
+	}
+	date, ok := strings.CutSuffix(rest, ".jsonl")
+	if !ok {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+func purgeFile(path string, before time.Time) (int, error) {
+	if before.IsZero() {
+		res, err := jsonlutil.ScanFile(path, jsonlutil.Options{}, nil)
+		if err != nil {
+			return 0, fmt.Errorf("reading archive: %w", err)
+		}
+		if err := os.Remove(path); err != nil {
+			return 0, fmt.Errorf("removing archive: %w", err)
+		}
+		return res.Lines, nil
+	}
+
+	var kept bytes.Buffer
+	removed := 0
+	acceptAll := jsonlutil.Options{Validate: func([]byte) error { return nil }}
+	_, err := jsonlutil.ScanFile(path, acceptAll, func(_ int, line []byte) error {
+		var record ArchiveRecord
+		plain, err := decryptJSONLine(line)
+		if err == nil {
+			err = json.Unmarshal(plain, &record)
+		}
+		if err == nil && record.Timestamp.Before(before) {
+			removed++
+			return nil
+		}
+		kept.Write(line)
+		kept.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("reading archive: %w", err)
+	}
+	switch {
+	case removed == 0:
+		return 0, nil
+	case kept.Len() == 0:
+		err = os.Remove(path)
+	default:
+		err = util.AtomicWriteFile(path, kept.Bytes(), 0644)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("rewriting archive: %w", err)
+	}
+	return removed, nil
+}
+
+// openArchiveFile opens the archive file of session for date for appending.
+func openArchiveFile(dir, session, date string) (*os.File, error) {
+	filename := fmt.Sprintf("%s_%s.jsonl", session, date)
+	f, err := os.OpenFile(filepath.Join(dir, filename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive file: %w", err)
+	}
+	return f, nil
+}
+
+// rotate switches to a new archive file when the day changes, so a
+// long-running archiver keeps one file per day.
+func (a *Archiver) rotate(now time.Time) error {
+	date := now.Format("2006-01-02")
+	if date == a.fileDate {
+		return nil
+	}
+	f, err := openArchiveFile(a.outputDir, a.sessionName, date)
+	if err != nil {
+		return err
+	}
+	if err := a.flush(); err != nil {
+		slog.Warn("archive flush on rotate error", "session", a.sessionName, "error", err)
+	}
+	_ = a.file.Close()
+	a.file = f
+	a.fileDate = date
 	return nil
 }
 
 // writeRecord writes an archive record to the JSONL file.
 func (a *Archiver) writeRecord(record *ArchiveRecord) error {
-	if err := a.encoder.Encode(record); err != nil {
+	if a.file == nil {
+		return fmt.Errorf("archive closed")
+	}
+	if err := a.rotate(time.Now()); err != nil {
+		return err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	data, err = encryptJSONLine(data)
+	if err != nil {
+		return fmt.Errorf("encrypting record: %w", err)
+	}
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
 		return err
 	}
 	a.totalRecords++
@@ -311,18 +858,117 @@ func (a *Archiver) Stats() ArchiverStats {
 		TotalRecords: a.totalRecords,
 		PanesTracked: panesTracked,
 		TotalLines:   totalLines,
+		Paused:       a.paused,
+		Panes:        a.paneCaptureStats(),
+	}
+}
+
+// paneCaptureStats returns the scheduling metrics of each pane, ordered by
+// pane index. The caller must hold a.mu.
+func (a *Archiver) paneCaptureStats() []PaneCaptureStats {
+	panes := make([]PaneCaptureStats, 0, len(a.paneStates))
+	for index, state := range a.paneStates {
+		panes = append(panes, PaneCaptureStats{
+			PaneIndex:    index,
+			Pane:         state.Name,
+			IntervalMs:   state.Interval.Milliseconds(),
+			Captures:     state.Captures,
+			Changes:      state.Changes,
+			IdleCaptures: state.IdleCaptures,
+			LastChange:   state.LastChange,
+			NextCapture:  state.NextCapture,
+			Mode:         state.Mode,
+			Deduped:      state.Deduped,
+			Streamed:     state.StreamedLines,
+			Pending:      len(state.pending),
+		})
 	}
+	sort.Slice(panes, func(i, j int) bool { return panes[i].PaneIndex < panes[j].PaneIndex })
+	return panes
+}
+
+// CaptureStatsPath returns where the archiver of session publishes its
+// capture scheduling metrics in dir.
+func CaptureStatsPath(dir, session string) string {
+	return filepath.Join(dir, session+".capture.json")
+}
+
+// writeCaptureStats publishes the archiver's metrics for ntm archive stats.
+// The caller must hold a.mu.
+func (a *Archiver) writeCaptureStats() error {
+	stats := CaptureStats{
+		Session:       a.sessionName,
+		UpdatedAt:     time.Now().UTC(),
+		MinIntervalMs: a.interval.Milliseconds(),
+		MaxIntervalMs: a.maxInterval.Milliseconds(),
+		Backoff:       a.backoff,
+		Dedupe:        a.dedupeThreshold,
+		Paused:        a.paused,
+		Mode:          a.mode,
+		QueueFull:     a.queueFull.Load(),
+		Panes:         a.paneCaptureStats(),
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(CaptureStatsPath(a.outputDir, a.sessionName), append(data, '\n'), 0644)
+}
+
+// ReadCaptureStats reads the capture metrics last published for session.
+func ReadCaptureStats(dir, session string) (*CaptureStats, error) {
+	data, err := os.ReadFile(CaptureStatsPath(dir, session))
+	if err != nil {
+		return nil, err
+	}
+	var stats CaptureStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("parsing capture stats: %w", err)
+	}
+	return &stats, nil
 }
 
 // ArchiverStats contains archiver statistics.
 type ArchiverStats struct {
-	Session      string        `json:"session"`
-	OutputDir    string        `json:"output_dir"`
-	Started      time.Time     `json:"started"`
-	Duration     time.Duration `json:"duration"`
-	TotalRecords int           `json:"total_records"`
-	PanesTracked int           `json:"panes_tracked"`
-	TotalLines   int           `json:"total_lines"`
+	Session      string             `json:"session"`
+	OutputDir    string             `json:"output_dir"`
+	Started      time.Time          `json:"started"`
+	Duration     time.Duration      `json:"duration"`
+	TotalRecords int                `json:"total_records"`
+	PanesTracked int                `json:"panes_tracked"`
+	TotalLines   int                `json:"total_lines"`
+	Paused       bool               `json:"paused,omitempty"` // Paused for low disk space
+	Panes        []PaneCaptureStats `json:"panes,omitempty"`
+}
+
+// CaptureStats is the capture scheduling state an archiver publishes.
+type CaptureStats struct {
+	Session       string             `json:"session"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	MinIntervalMs int64              `json:"min_interval_ms"`
+	MaxIntervalMs int64              `json:"max_interval_ms"`
+	Backoff       float64            `json:"backoff"`
+	Dedupe        float64            `json:"dedupe_threshold"`
+	Paused        bool               `json:"paused,omitempty"`
+	Mode          string             `json:"mode"`                 // Configured ingestion mode
+	QueueFull     int64              `json:"queue_full,omitempty"` // Streamed batches that waited for the archive loop
+	Panes         []PaneCaptureStats `json:"panes"`
+}
+
+// PaneCaptureStats describes how often one pane is being captured.
+type PaneCaptureStats struct {
+	PaneIndex    int       `json:"pane_index"`
+	Pane         string    `json:"pane"`
+	IntervalMs   int64     `json:"interval_ms"`   // Current capture interval
+	Captures     int       `json:"captures"`      // Captures taken
+	Changes      int       `json:"changes"`       // Captures that found new output
+	IdleCaptures int       `json:"idle_captures"` // Consecutive captures without new output
+	LastChange   time.Time `json:"last_change"`
+	NextCapture  time.Time `json:"next_capture"`
+	Deduped      int       `json:"deduped"`                  // Captures trimmed or skipped as redraws
+	Mode         string    `json:"mode"`                     // How the pane is ingested: poll or pipe
+	Streamed     int       `json:"streamed_lines,omitempty"` // Lines received through pipe-pane
+	Pending      int       `json:"pending_lines,omitempty"`  // Streamed lines not yet archived
 }
 
 // Helper functions
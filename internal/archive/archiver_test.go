@@ -2,13 +2,18 @@ package archive
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/storage"
 	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
@@ -183,6 +188,42 @@ func TestArchiver_Stats(t *testing.T) {
 	}
 }
 
+func TestArchiver_PausesOnLowDisk(t *testing.T) {
+	var states []bool
+	a, err := NewArchiver(ArchiverOptions{
+		SessionName:  "disk-test",
+		OutputDir:    t.TempDir(),
+		MinFreeBytes: 100,
+		OnDiskState:  func(d storage.Disk) { states = append(states, d.Low) },
+	})
+	if err != nil {
+		t.Fatalf("NewArchiver() error: %v", err)
+	}
+	defer a.Close()
+
+	free := uint64(50)
+	a.checkDisk = func(path string, minFree uint64) (storage.Disk, error) {
+		return storage.Disk{Path: path, FreeBytes: free, Low: free < minFree}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if !a.diskLow() {
+			t.Fatal("archiving should pause below MinFreeBytes")
+		}
+	}
+	if !a.Stats().Paused {
+		t.Error("Stats().Paused = false while paused")
+	}
+
+	free = 500
+	if a.diskLow() {
+		t.Fatal("archiving should resume once space is freed")
+	}
+	if len(states) != 2 || !states[0] || states[1] {
+		t.Errorf("OnDiskState calls = %v, want [true false] (once per change)", states)
+	}
+}
+
 func TestArchiver_RunContextCancellation(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -426,6 +467,81 @@ func TestPaneState(t *testing.T) {
 	}
 }
 
+func TestPaneState_Schedule(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := &PaneState{}
+
+	state.schedule(true, now, 5*time.Second, 40*time.Second, 2)
+	if state.Interval != 5*time.Second || !state.NextCapture.Equal(now.Add(5*time.Second)) {
+		t.Fatalf("after change: interval %v next %v", state.Interval, state.NextCapture)
+	}
+
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 40 * time.Second}
+	for i, w := range want {
+		state.schedule(false, now, 5*time.Second, 40*time.Second, 2)
+		if state.Interval != w {
+			t.Errorf("idle capture %d: interval = %v, want %v", i+1, state.Interval, w)
+		}
+	}
+	if state.IdleCaptures != 4 || state.Captures != 5 || state.Changes != 1 {
+		t.Errorf("counters = %+v", state)
+	}
+
+	later := now.Add(time.Minute)
+	state.schedule(true, later, 5*time.Second, 40*time.Second, 2)
+	if state.Interval != 5*time.Second || state.IdleCaptures != 0 || !state.LastChange.Equal(later) {
+		t.Errorf("change should reset to the fast interval: %+v", state)
+	}
+
+	fixed := &PaneState{}
+	fixed.schedule(false, now, 5*time.Second, 5*time.Second, 1)
+	fixed.schedule(false, now, 5*time.Second, 5*time.Second, 1)
+	if fixed.Interval != 5*time.Second {
+		t.Errorf("backoff 1 should keep a fixed interval, got %v", fixed.Interval)
+	}
+}
+
+func TestArchiver_CaptureStats(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	a, err := NewArchiver(ArchiverOptions{
+		SessionName: "capture-stats",
+		OutputDir:   tmpDir,
+		Interval:    2 * time.Second,
+		MaxInterval: time.Second, // Raised to Interval
+	})
+	if err != nil {
+		t.Fatalf("NewArchiver() error: %v", err)
+	}
+	defer a.Close()
+	if a.maxInterval != 2*time.Second || a.backoff != 1 {
+		t.Errorf("maxInterval = %v, backoff = %v", a.maxInterval, a.backoff)
+	}
+
+	a.mu.Lock()
+	a.paneStates[3] = &PaneState{Name: "cod_3", Interval: 8 * time.Second, Captures: 4, IdleCaptures: 2}
+	a.paneStates[2] = &PaneState{Name: "cc_2", Interval: 2 * time.Second, Captures: 4, Changes: 4}
+	err = a.writeCaptureStats()
+	a.mu.Unlock()
+	if err != nil {
+		t.Fatalf("writeCaptureStats() error: %v", err)
+	}
+
+	stats, err := ReadCaptureStats(tmpDir, "capture-stats")
+	if err != nil {
+		t.Fatalf("ReadCaptureStats() error: %v", err)
+	}
+	if stats.MinIntervalMs != 2000 || len(stats.Panes) != 2 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	if p := stats.Panes[0]; p.Pane != "cc_2" || p.IntervalMs != 2000 || p.Changes != 4 {
+		t.Errorf("first pane = %+v", p)
+	}
+	if p := stats.Panes[1]; p.Pane != "cod_3" || p.IntervalMs != 8000 || p.IdleCaptures != 2 {
+		t.Errorf("second pane = %+v", p)
+	}
+}
+
 // ============================================================================
 // Additional tests for bd-s2l4: Output archive capture and storage
 // ============================================================================
@@ -1075,3 +1191,157 @@ func TestExpandPath_NoHome(t *testing.T) {
 		t.Errorf("ExpandPath('') = %q, want ''", result)
 	}
 }
+
+func TestArchiver_EncryptedRecords(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	key := make([]byte, encryption.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	SetEncryptionConfig(&EncryptionConfig{Enabled: true, EncryptKey: key, DecryptKeys: [][]byte{key}})
+	defer SetEncryptionConfig(nil)
+
+	a, err := NewArchiver(ArchiverOptions{SessionName: "enc", OutputDir: tmpDir})
+	if err != nil {
+		t.Fatalf("NewArchiver() error: %v", err)
+	}
+	for i, content := range []string{"api output one", "api output two"} {
+		record := &ArchiveRecord{Session: "enc", Pane: "cc_1", Timestamp: time.Now().UTC(), Content: content, Sequence: i + 1}
+		if err := a.writeRecord(record); err != nil {
+			t.Fatalf("writeRecord() error: %v", err)
+		}
+	}
+	a.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(tmpDir, "enc_*.jsonl"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one archive file, got %v", matches)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "api output") {
+		t.Fatal("archive contains plaintext content")
+	}
+
+	records, err := ReadRecords(matches[0])
+	if err != nil {
+		t.Fatalf("ReadRecords() error: %v", err)
+	}
+	if len(records) != 2 || records[1].Content != "api output two" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	SetEncryptionConfig(nil)
+	if records, _ := ReadRecords(matches[0]); len(records) != 0 {
+		t.Fatalf("expected no readable records without the key, got %d", len(records))
+	}
+}
+
+func TestPurgeSession(t *testing.T) {
+	tmpDir := t.TempDir()
+	cutoff := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	old, _ := json.Marshal(ArchiveRecord{Session: "proj", Timestamp: cutoff.Add(-time.Hour), Content: "old"})
+	recent, _ := json.Marshal(ArchiveRecord{Session: "proj", Timestamp: cutoff.Add(time.Hour), Content: "recent"})
+	files := map[string]string{
+		"proj_2026-01-02.jsonl":       string(old) + "\n" + string(recent) + "\n",
+		"proj_2026-01-01.jsonl":       string(old) + "\n",
+		"proj-other_2026-01-02.jsonl": string(old) + "\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := PurgeSession(tmpDir, "proj", cutoff)
+	if err != nil {
+		t.Fatalf("PurgeSession(before) error: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "proj_2026-01-01.jsonl")); !os.IsNotExist(err) {
+		t.Error("emptied archive file should be removed")
+	}
+	records, err := ReadRecords(filepath.Join(tmpDir, "proj_2026-01-02.jsonl"))
+	if err != nil || len(records) != 1 || records[0].Content != "recent" {
+		t.Fatalf("remaining records = %+v, err = %v", records, err)
+	}
+
+	removed, err = PurgeSession(tmpDir, "proj", time.Time{})
+	if err != nil || removed != 1 {
+		t.Fatalf("PurgeSession() = %d, %v; want 1", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "proj-other_2026-01-02.jsonl")); err != nil {
+		t.Error("another session's archive was removed")
+	}
+}
+
+func TestRecentPaneRecords(t *testing.T) {
+	tmpDir := t.TempDir()
+	line := func(pane, seq int) string {
+		data, _ := json.Marshal(ArchiveRecord{Session: "proj", PaneIndex: pane, Sequence: seq})
+		return string(data) + "\n"
+	}
+	files := map[string]string{
+		"proj_2026-01-01.jsonl":       line(1, 1) + line(2, 1) + line(1, 2),
+		"proj_2026-01-02.jsonl":       line(1, 3) + line(2, 2) + line(1, 4),
+		"proj-other_2026-01-03.jsonl": line(1, 9),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := RecentPaneRecords(tmpDir, "proj", 1, 3)
+	if err != nil {
+		t.Fatalf("RecentPaneRecords() error: %v", err)
+	}
+	var seqs []int
+	for _, r := range records {
+		seqs = append(seqs, r.Sequence)
+	}
+	if fmt.Sprint(seqs) != "[2 3 4]" {
+		t.Errorf("sequences = %v, want [2 3 4]", seqs)
+	}
+
+	records, err = RecentPaneRecords(filepath.Join(tmpDir, "missing"), "proj", 1, 3)
+	if err != nil || len(records) != 0 {
+		t.Errorf("missing dir = %v, %v; want no records", records, err)
+	}
+}
+
+func TestEmitReports(t *testing.T) {
+	got := make(chan events.WebhookEvent, 4)
+	unsub := events.Subscribe(events.WebhookAgentProgress, func(e events.BusEvent) {
+		if we, ok := e.(events.WebhookEvent); ok && we.Session == "report-test" {
+			got <- we
+		}
+	})
+	defer unsub()
+
+	emitReports(&ArchiveRecord{
+		Session:   "report-test",
+		Pane:      "cc_2",
+		PaneIndex: 2,
+		Agent:     "cc",
+		Content:   "working\nNTM-REPORT{\"task_id\":\"bd-4\",\"percent\":50,\"blockers\":[\"needs review\"]}\n",
+	})
+
+	select {
+	case e := <-got:
+		if e.Details["task_id"] != "bd-4" || e.Details["percent"] != "50" || e.Details["blockers"] != "needs review" {
+			t.Errorf("details = %v", e.Details)
+		}
+		if e.Pane != "cc_2" || !strings.Contains(e.Details["report"], `"task_id":"bd-4"`) {
+			t.Errorf("event = %+v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no agent.progress event emitted")
+	}
+}
@@ -0,0 +1,125 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultCastHeight is the terminal height written to cast headers.
+	DefaultCastHeight = 40
+
+	// minCastWidth and maxCastWidth bound the width inferred from content.
+	minCastWidth = 80
+	maxCastWidth = 250
+)
+
+// CastOptions configures an asciinema export.
+type CastOptions struct {
+	Width         int     // Terminal columns; 0 sizes to the longest line
+	Height        int     // Terminal rows; 0 uses DefaultCastHeight
+	Title         string  // Recording title shown by players
+	IdleTimeLimit float64 // Seconds; players compress longer pauses (0 = off)
+}
+
+// castHeader is the first line of an asciinema v2 file.
+type castHeader struct {
+	Version       int               `json:"version"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Timestamp     int64             `json:"timestamp,omitempty"`
+	IdleTimeLimit float64           `json:"idle_time_limit,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+}
+
+// CastStats describes a written cast file.
+type CastStats struct {
+	Events   int     `json:"events"`
+	Duration float64 `json:"duration_seconds"`
+	Width    int     `json:"width"`
+	Height   int     `json:"height"`
+}
+
+// WriteCast writes records as an asciinema v2 cast. Each record becomes one
+// output event at its capture time relative to the first record, so replay
+// follows the original pacing.
+func WriteCast(w io.Writer, records []ArchiveRecord, opts CastOptions) (CastStats, error) {
+	if len(records) == 0 {
+		return CastStats{}, fmt.Errorf("no archived output to export")
+	}
+
+	sorted := make([]ArchiveRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		}
+		return sorted[i].Sequence < sorted[j].Sequence
+	})
+
+	width := opts.Width
+	if width <= 0 {
+		width = castWidth(sorted)
+	}
+	height := opts.Height
+	if height <= 0 {
+		height = DefaultCastHeight
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	start := sorted[0].Timestamp
+	header := castHeader{
+		Version:       2,
+		Width:         width,
+		Height:        height,
+		Timestamp:     start.Unix(),
+		IdleTimeLimit: opts.IdleTimeLimit,
+		Title:         opts.Title,
+		Env:           map[string]string{"TERM": "xterm-256color"},
+	}
+	if err := enc.Encode(header); err != nil {
+		return CastStats{}, fmt.Errorf("writing cast header: %w", err)
+	}
+
+	stats := CastStats{Width: width, Height: height}
+	for _, record := range sorted {
+		if record.Content == "" {
+			continue
+		}
+		offset := math.Round(record.Timestamp.Sub(start).Seconds()*1e6) / 1e6
+		if err := enc.Encode([]any{offset, "o", castOutput(record.Content)}); err != nil {
+			return stats, fmt.Errorf("writing cast event: %w", err)
+		}
+		stats.Events++
+		stats.Duration = offset
+	}
+	return stats, nil
+}
+
+// castOutput converts captured lines to what a terminal would have received:
+// CRLF line endings, ending on a fresh line.
+func castOutput(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	return strings.ReplaceAll(content, "\n", "\r\n") + "\r\n"
+}
+
+// castWidth picks a terminal width wide enough for the longest line.
+func castWidth(records []ArchiveRecord) int {
+	width := minCastWidth
+	for _, record := range records {
+		for _, line := range strings.Split(record.Content, "\n") {
+			if n := utf8.RuneCountInString(line); n > width {
+				width = n
+			}
+		}
+	}
+	return min(width, maxCastWidth)
+}
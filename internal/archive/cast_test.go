@@ -0,0 +1,93 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCast(t *testing.T) {
+	start := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	records := []ArchiveRecord{
+		{Pane: "cc_2", Timestamp: start.Add(30 * time.Second), Sequence: 2, Content: "second\n<b>&"},
+		{Pane: "cc_2", Timestamp: start, Sequence: 1, Content: "first line\nnext"},
+		{Pane: "cc_2", Timestamp: start.Add(45 * time.Second), Sequence: 3, Content: ""},
+	}
+
+	var buf bytes.Buffer
+	stats, err := WriteCast(&buf, records, CastOptions{Title: "ntm proj cc_2", IdleTimeLimit: 2})
+	if err != nil {
+		t.Fatalf("WriteCast: %v", err)
+	}
+	if stats.Events != 2 || stats.Duration != 30 || stats.Width != minCastWidth || stats.Height != DefaultCastHeight {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("cast has %d lines, want header and 2 events:\n%s", len(lines), buf.String())
+	}
+
+	var header castHeader
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatalf("header: %v", err)
+	}
+	if header.Version != 2 || header.Timestamp != start.Unix() || header.IdleTimeLimit != 2 || header.Title != "ntm proj cc_2" {
+		t.Errorf("header = %+v", header)
+	}
+
+	want := []struct {
+		offset float64
+		data   string
+	}{
+		{0, "first line\r\nnext\r\n"},
+		{30, "second\r\n<b>&\r\n"},
+	}
+	for i, w := range want {
+		var event []any
+		if err := json.Unmarshal([]byte(lines[i+1]), &event); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if len(event) != 3 || event[0] != w.offset || event[1] != "o" || event[2] != w.data {
+			t.Errorf("event %d = %v, want [%v o %q]", i, event, w.offset, w.data)
+		}
+	}
+	if strings.Contains(buf.String(), `\u003c`) {
+		t.Error("cast output should not HTML-escape content")
+	}
+}
+
+func TestWriteCastWidth(t *testing.T) {
+	long := strings.Repeat("x", 132)
+	records := []ArchiveRecord{{Timestamp: time.Now(), Content: "short\n" + long}}
+
+	var buf bytes.Buffer
+	stats, err := WriteCast(&buf, records, CastOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Width != 132 {
+		t.Errorf("width = %d, want 132", stats.Width)
+	}
+
+	stats, err = WriteCast(&buf, records, CastOptions{Width: 100, Height: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Width != 100 || stats.Height != 30 {
+		t.Errorf("explicit size = %dx%d, want 100x30", stats.Width, stats.Height)
+	}
+
+	records[0].Content = strings.Repeat("y", 400)
+	if stats, _ := WriteCast(&buf, records, CastOptions{}); stats.Width != maxCastWidth {
+		t.Errorf("width = %d, want cap %d", stats.Width, maxCastWidth)
+	}
+}
+
+func TestWriteCastEmpty(t *testing.T) {
+	if _, err := WriteCast(&bytes.Buffer{}, nil, CastOptions{}); err == nil {
+		t.Error("expected error for no records")
+	}
+}
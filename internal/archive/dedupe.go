@@ -0,0 +1,108 @@
+package archive
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+// DefaultDedupeThreshold is the similarity to the previous capture above
+// which new content is treated as a redraw: only its novel lines are kept.
+const DefaultDedupeThreshold = 0.9
+
+var (
+	// spinnerRunes are the animation frames agent TUIs redraw constantly.
+	spinnerRunes = regexp.MustCompile(`[\x{2800}-\x{28FF}✻✽✶✳✢·●○◐◓◑◒◴◷◶◵]`)
+	// tickingValues are elapsed times, percentages and token counters that
+	// change on every redraw of a status line.
+	tickingValues = regexp.MustCompile(`\b\d+(\.\d+)?(ms|s|m|h)\b|\b\d+(\.\d+)?%|\b\d+(\.\d+)?k?\s+tokens\b`)
+)
+
+// normalizeLine reduces a captured line to what matters for deduplication:
+// escape sequences, spinner frames and ticking numbers are removed and
+// whitespace is collapsed.
+func normalizeLine(line string) string {
+	line = status.StripANSI(line)
+	line = spinnerRunes.ReplaceAllString(line, "")
+	line = tickingValues.ReplaceAllString(line, "#")
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// normalizeCapture returns the non-blank normalized lines of content.
+func normalizeCapture(content string) []string {
+	var lines []string
+	for _, line := range splitLines(content) {
+		if n := normalizeLine(line); n != "" {
+			lines = append(lines, n)
+		}
+	}
+	return lines
+}
+
+// hashLines hashes normalized lines.
+func hashLines(lines []string) uint64 {
+	return simpleHash(strings.Join(lines, "\n"))
+}
+
+// similarity returns the Dice coefficient of two multisets of lines: 1 when
+// they hold the same lines, 0 when they share none.
+func similarity(a, b []string) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	counts := make(map[string]int, len(b))
+	for _, line := range b {
+		counts[line]++
+	}
+	common := 0
+	for _, line := range a {
+		if counts[line] > 0 {
+			counts[line]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
+
+// dedupe drops the redrawn part of content. When content is at least
+// a.dedupeThreshold similar to previous, the normalized lines of the last
+// capture, only lines previous does not hold are kept, possibly none.
+func (a *Archiver) dedupe(state *PaneState, content string, previous []string) string {
+	if content == "" || len(previous) == 0 {
+		return content
+	}
+	lines := splitLines(content)
+	normalized := make([]string, 0, len(lines))
+	for _, line := range lines {
+		normalized = append(normalized, normalizeLine(line))
+	}
+	var nonBlank []string
+	for _, n := range normalized {
+		if n != "" {
+			nonBlank = append(nonBlank, n)
+		}
+	}
+	if similarity(nonBlank, previous) < a.dedupeThreshold {
+		return content
+	}
+
+	seen := make(map[string]int, len(previous))
+	for _, line := range previous {
+		seen[line]++
+	}
+	var kept []string
+	for i, line := range lines {
+		n := normalized[i]
+		if n == "" {
+			continue
+		}
+		if seen[n] > 0 {
+			seen[n]--
+			continue
+		}
+		kept = append(kept, line)
+	}
+	state.Deduped++
+	return strings.Join(kept, "\n")
+}
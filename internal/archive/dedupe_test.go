@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"testing"
+)
+
+func TestNormalizeLine(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"⠋ Thinking… (12s)", "⠙ Thinking… (13s)", true},
+		{"✻ Working (1m 3s · 1.2k tokens)", "✶ Working (1m 4s · 1.3k tokens)", true},
+		{"\x1b[32mProgress 40%\x1b[0m   ", "Progress 45%", true},
+		{"Tests: 12 passed", "Tests: 13 passed", false},
+		{"edit main.go", "edit util.go", false},
+	}
+	for _, tt := range tests {
+		if got := normalizeLine(tt.a) == normalizeLine(tt.b); got != tt.same {
+			t.Errorf("normalizeLine(%q) == normalizeLine(%q) is %v, want %v (%q vs %q)",
+				tt.a, tt.b, got, tt.same, normalizeLine(tt.a), normalizeLine(tt.b))
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := similarity([]string{"a", "b", "c", "d"}, []string{"a", "b", "c", "d"}); got != 1 {
+		t.Errorf("identical = %v", got)
+	}
+	if got := similarity([]string{"a", "b", "c", "d"}, []string{"a", "b", "c", "x"}); got != 0.75 {
+		t.Errorf("one line differs = %v, want 0.75", got)
+	}
+	if got := similarity([]string{"a", "a"}, []string{"a"}); got < 0.66 || got > 0.67 {
+		t.Errorf("repeated lines = %v, want 2/3", got)
+	}
+	if got := similarity(nil, []string{"a"}); got != 0 {
+		t.Errorf("empty vs non-empty = %v", got)
+	}
+}
+
+func TestArchiver_Dedupe(t *testing.T) {
+	a, err := NewArchiver(ArchiverOptions{SessionName: "dedupe", OutputDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewArchiver() error: %v", err)
+	}
+	defer a.Close()
+	if a.dedupeThreshold != DefaultDedupeThreshold {
+		t.Fatalf("dedupeThreshold = %v, want default", a.dedupeThreshold)
+	}
+
+	screen := "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\n⠋ Thinking (3s)"
+	previous := normalizeCapture(screen)
+	state := &PaneState{}
+
+	// A redraw with only the spinner ticking keeps nothing.
+	redraw := "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\n⠙ Thinking (4s)"
+	if got := a.dedupe(state, redraw, previous); got != "" {
+		t.Errorf("redraw kept %q", got)
+	}
+
+	// A redraw that adds a line keeps just that line.
+	grown := "line 1\nline 2\nline 3\nline 4\nline 5\nline 6\nline 7\nline 8\nline 9\nline 10\nwrote main.go\n⠹ Thinking (5s)"
+	if got := a.dedupe(state, grown, previous); got != "wrote main.go" {
+		t.Errorf("grown redraw kept %q, want the new line", got)
+	}
+	if state.Deduped != 2 {
+		t.Errorf("Deduped = %d, want 2", state.Deduped)
+	}
+
+	// Dissimilar output, e.g. a scroll diff, is kept whole.
+	if got := a.dedupe(state, "new output\nmore output", previous); got != "new output\nmore output" {
+		t.Errorf("new output = %q", got)
+	}
+	if got := a.dedupe(state, "first capture", nil); got != "first capture" {
+		t.Errorf("first capture = %q", got)
+	}
+}
+
+func TestArchiver_DedupeStream(t *testing.T) {
+	var records []*ArchiveRecord
+	a, _ := newPipeArchiver(t, ArchiverOptions{OnRecord: func(r *ArchiveRecord) { records = append(records, r) }})
+
+	state := &PaneState{Name: "cc_2", streamer: &fakeStreamer{}}
+	a.paneStates[2] = state
+	screen := []string{"> task", "step 1", "step 2", "step 3", "step 4", "step 5", "step 6", "step 7", "step 8"}
+	for _, tail := range [][]string{
+		{"⠋ Working (1s)"},
+		{"⠙ Working (2s)"},
+		{"⠹ Working (3s)", "done"},
+	} {
+		state.pending = append(append([]string{}, screen...), tail...)
+		if err := a.flushPending(state); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(records) != 2 || records[1].Content != "done" {
+		t.Fatalf("records = %+v", records)
+	}
+	if state.Captures != 3 || state.Changes != 2 || state.Deduped != 2 {
+		t.Errorf("counters = captures %d changes %d deduped %d", state.Captures, state.Changes, state.Deduped)
+	}
+}
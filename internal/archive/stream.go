@@ -0,0 +1,193 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Ingestion modes for ArchiverOptions.Mode.
+const (
+	// ModePoll captures panes with capture-pane on an adaptive schedule.
+	ModePoll = "poll"
+	// ModePipe streams pane output continuously through tmux pipe-pane.
+	// Panes where pipe-pane cannot be attached are polled.
+	ModePipe = "pipe"
+)
+
+// DefaultStreamQueue is how many batches of streamed lines are buffered
+// before pane readers block.
+const DefaultStreamQueue = 256
+
+// paneStreamer is the part of tmux.PaneStreamer the archiver uses.
+type paneStreamer interface {
+	Start(ctx context.Context) error
+	Stop()
+	UsingFallback() bool
+}
+
+func newTmuxStreamer(target string, callback tmux.StreamCallback) paneStreamer {
+	return tmux.NewPaneStreamer(tmux.DefaultClient, target, callback, tmux.DefaultPaneStreamerConfig())
+}
+
+// streamBatch is a batch of lines streamed from one pane.
+type streamBatch struct {
+	paneIndex int
+	lines     []string
+}
+
+// startStream attaches pipe-pane to pane and reports whether it is
+// streaming. The caller must hold a.mu.
+func (a *Archiver) startStream(ctx context.Context, pane tmux.Pane, state *PaneState) bool {
+	index := pane.Index
+	streamer := a.newStreamer(fmt.Sprintf("%s:1.%d", a.sessionName, index), func(ev tmux.StreamEvent) {
+		// Full captures come from the streamer's own polling fallback;
+		// the archiver polls such panes itself.
+		if !ev.IsFull {
+			a.enqueue(streamBatch{paneIndex: index, lines: ev.Lines})
+		}
+	})
+	if err := streamer.Start(ctx); err != nil || streamer.UsingFallback() {
+		streamer.Stop()
+		slog.Warn("archive pipe-pane unavailable, polling pane", "pane", index, "error", err)
+		return false
+	}
+	state.streamer = streamer
+	state.pane = pane
+	state.Mode = ModePipe
+	return true
+}
+
+// enqueue hands a batch to the archive loop. When the queue is full the
+// pane's reader blocks until there is room, so a slow disk holds back the
+// pipe instead of dropping output.
+func (a *Archiver) enqueue(batch streamBatch) {
+	select {
+	case a.streamCh <- batch:
+		return
+	default:
+	}
+	a.queueFull.Add(1)
+	select {
+	case a.streamCh <- batch:
+	case <-a.streamDone:
+	}
+}
+
+// ingest buffers a streamed batch and archives it once linesPerCapture
+// lines are pending.
+func (a *Archiver) ingest(batch streamBatch) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ingestLocked(batch)
+}
+
+func (a *Archiver) ingestLocked(batch streamBatch) {
+	state, ok := a.paneStates[batch.paneIndex]
+	if !ok || state.streamer == nil || a.paused {
+		return
+	}
+	for _, line := range batch.lines {
+		state.pending = append(state.pending, cleanStreamLine(line))
+	}
+	state.StreamedLines += len(batch.lines)
+	if len(state.pending) >= a.linesPerCapture {
+		if err := a.flushPending(state); err != nil {
+			slog.Warn("archive stream write error", "pane", batch.paneIndex, "error", err)
+		}
+	}
+}
+
+// flushPending archives the lines streamed from a pane since the last
+// record. The caller must hold a.mu.
+func (a *Archiver) flushPending(state *PaneState) error {
+	if len(state.pending) == 0 {
+		return nil
+	}
+	raw := strings.Join(state.pending, "\n")
+	state.pending = nil
+	state.Captures++
+	content := a.dedupe(state, raw, state.lastNorm)
+	state.lastNorm = normalizeCapture(raw)
+	if content == "" {
+		return nil
+	}
+	state.Changes++
+	err := a.appendRecord(state.pane, state, content, state.human, true)
+	state.LastChange = state.LastCapture
+	return err
+}
+
+// stopStream detaches pipe-pane from a pane and archives what it streamed.
+// The caller must hold a.mu.
+func (a *Archiver) stopStream(state *PaneState) {
+	streamer := state.streamer
+	if streamer == nil {
+		return
+	}
+	state.streamer = nil
+	if err := a.flushPending(state); err != nil {
+		slog.Warn("archive stream write error", "pane", state.pane.Index, "error", err)
+	}
+	// Stop waits for the pane's reader, which may be blocked on a full
+	// queue that only the archive loop, now holding a.mu, drains.
+	go streamer.Stop()
+}
+
+// stopStreams detaches every stream, archiving the output still queued.
+func (a *Archiver) stopStreams() {
+	a.stopOnce.Do(func() { close(a.streamDone) })
+
+	a.mu.Lock()
+	var streamers []paneStreamer
+	for _, state := range a.paneStates {
+		if state.streamer != nil {
+			streamers = append(streamers, state.streamer)
+		}
+	}
+	a.mu.Unlock()
+
+	// Readers blocked on a full queue return once streamDone is closed.
+	for _, s := range streamers {
+		s.Stop()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for drained := false; !drained; {
+		select {
+		case batch := <-a.streamCh:
+			a.ingestLocked(batch)
+		default:
+			drained = true
+		}
+	}
+	for _, state := range a.paneStates {
+		if state.streamer != nil {
+			if err := a.flushPending(state); err != nil {
+				slog.Warn("archive stream write error", "pane", state.pane.Index, "error", err)
+			}
+			state.streamer = nil
+		}
+	}
+}
+
+// cleanStreamLine turns a line of raw terminal output into text: escape
+// sequences are removed and carriage-return redraws keep their last state.
+func cleanStreamLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	line = status.StripANSI(line)
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, line)
+}
@@ -0,0 +1,209 @@
+package archive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+type fakeStreamer struct {
+	target   string
+	callback tmux.StreamCallback
+	fallback bool
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+func (f *fakeStreamer) Start(context.Context) error { return nil }
+func (f *fakeStreamer) UsingFallback() bool         { return f.fallback }
+func (f *fakeStreamer) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+}
+
+func newPipeArchiver(t *testing.T, opts ArchiverOptions) (*Archiver, *fakeStreamer) {
+	t.Helper()
+	opts.SessionName = "pipe"
+	opts.OutputDir = t.TempDir()
+	opts.Mode = ModePipe
+	a, err := NewArchiver(opts)
+	if err != nil {
+		t.Fatalf("NewArchiver() error: %v", err)
+	}
+	t.Cleanup(func() { a.Close() })
+	fake := &fakeStreamer{}
+	a.newStreamer = func(target string, callback tmux.StreamCallback) paneStreamer {
+		fake.target, fake.callback = target, callback
+		return fake
+	}
+	return a, fake
+}
+
+func TestArchiver_PipeIngestion(t *testing.T) {
+	var records []*ArchiveRecord
+	a, fake := newPipeArchiver(t, ArchiverOptions{
+		LinesPerCapture: 3,
+		OnRecord:        func(r *ArchiveRecord) { records = append(records, r) },
+	})
+
+	a.mu.Lock()
+	state := &PaneState{Name: "cc_2", Mode: ModePoll}
+	a.paneStates[2] = state
+	ok := a.startStream(context.Background(), tmux.Pane{Index: 2, Type: tmux.AgentClaude}, state)
+	a.mu.Unlock()
+	if !ok || state.Mode != ModePipe || fake.target != "pipe:1.2" {
+		t.Fatalf("startStream = %v, mode %q, target %q", ok, state.Mode, fake.target)
+	}
+
+	fake.callback(tmux.StreamEvent{Lines: []string{"\x1b[32mhello\x1b[0m\r", "50%\r100%"}})
+	a.ingest(<-a.streamCh)
+	if len(records) != 0 || len(state.pending) != 2 {
+		t.Fatalf("records before LinesPerCapture: %d, pending %d", len(records), len(state.pending))
+	}
+	fake.callback(tmux.StreamEvent{Lines: []string{"third"}})
+	a.ingest(<-a.streamCh)
+	if len(records) != 1 || records[0].Content != "hello\n100%\nthird" || records[0].Pane != "cc_2" {
+		t.Fatalf("records = %+v", records)
+	}
+
+	fake.callback(tmux.StreamEvent{Lines: []string{"full capture"}, IsFull: true})
+	if len(a.streamCh) != 0 {
+		t.Error("full captures from the streamer's fallback should be ignored")
+	}
+
+	// Output still queued at shutdown is archived.
+	fake.callback(tmux.StreamEvent{Lines: []string{"tail"}})
+	a.stopStreams()
+	if len(records) != 2 || records[1].Content != "tail" || records[1].Sequence != 2 {
+		t.Fatalf("records after stop = %+v", records)
+	}
+	if !fake.stopped || state.streamer != nil || state.StreamedLines != 4 {
+		t.Errorf("stream not detached: stopped %v, state %+v", fake.stopped, state)
+	}
+}
+
+func TestArchiver_PipeFallsBackToPolling(t *testing.T) {
+	a, fake := newPipeArchiver(t, ArchiverOptions{})
+	fake.fallback = true
+
+	a.mu.Lock()
+	state := &PaneState{Mode: ModePoll}
+	ok := a.startStream(context.Background(), tmux.Pane{Index: 3}, state)
+	a.mu.Unlock()
+	if ok || state.streamer != nil || !fake.stopped {
+		t.Errorf("startStream with fallback = %v, state %+v, stopped %v", ok, state, fake.stopped)
+	}
+}
+
+func TestArchiver_StreamBackpressure(t *testing.T) {
+	a, _ := newPipeArchiver(t, ArchiverOptions{StreamQueue: 1})
+
+	a.enqueue(streamBatch{paneIndex: 2, lines: []string{"one"}})
+	done := make(chan struct{})
+	go func() {
+		a.enqueue(streamBatch{paneIndex: 2, lines: []string{"two"}})
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for a.queueFull.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("enqueue should block while the queue is full")
+	default:
+	}
+
+	if got := <-a.streamCh; got.lines[0] != "one" {
+		t.Errorf("first batch = %v", got.lines)
+	}
+	<-done
+	if got := <-a.streamCh; got.lines[0] != "two" {
+		t.Errorf("second batch = %v", got.lines)
+	}
+	if a.queueFull.Load() != 1 {
+		t.Errorf("queueFull = %d, want 1", a.queueFull.Load())
+	}
+}
+
+func TestNewArchiver_InvalidMode(t *testing.T) {
+	if _, err := NewArchiver(ArchiverOptions{SessionName: "s", OutputDir: t.TempDir(), Mode: "mmap"}); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestArchiver_RotatesDaily(t *testing.T) {
+	tmpDir := t.TempDir()
+	a, err := NewArchiver(ArchiverOptions{SessionName: "rotate", OutputDir: tmpDir})
+	if err != nil {
+		t.Fatalf("NewArchiver() error: %v", err)
+	}
+	defer a.Close()
+
+	a.fileDate = "2000-01-01"
+	if err := a.writeRecord(&ArchiveRecord{Session: "rotate", Content: "x"}); err != nil {
+		t.Fatalf("writeRecord() error: %v", err)
+	}
+	today := time.Now().Format("2006-01-02")
+	if a.fileDate != today {
+		t.Errorf("fileDate = %q, want %q", a.fileDate, today)
+	}
+	records, err := ReadRecords(filepath.Join(tmpDir, "rotate_"+today+".jsonl"))
+	if err != nil || len(records) != 1 {
+		t.Errorf("today's archive = %v, %v", records, err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "rotate_2000-01-01.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("rotation should not create a file for the old day: %v", err)
+	}
+}
+
+func TestCleanStreamLine(t *testing.T) {
+	tests := map[string]string{
+		"plain":                    "plain",
+		"crlf\r":                   "crlf",
+		"10%\r50%\r100%":           "100%",
+		"\x1b[1;31mred\x1b[0m":     "red",
+		"bell\a and\tab":           "bell and\tab",
+		"\x1b[2K\rredrawn status ": "redrawn status ",
+	}
+	for in, want := range tests {
+		if got := cleanStreamLine(in); got != want {
+			t.Errorf("cleanStreamLine(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestArchiver_BeforeRecord(t *testing.T) {
+	var records []*ArchiveRecord
+	a, _ := newPipeArchiver(t, ArchiverOptions{
+		OnRecord: func(r *ArchiveRecord) { records = append(records, r) },
+		BeforeRecord: func(r *ArchiveRecord) bool {
+			if r.Content == "secret" {
+				return false
+			}
+			r.Content += "\nredacted"
+			return true
+		},
+	})
+
+	state := &PaneState{Name: "cc_1"}
+	for _, content := range []string{"secret", "kept"} {
+		if err := a.appendRecord(tmux.Pane{Index: 1}, state, content, false, false); err != nil {
+			t.Fatalf("appendRecord(%q) error: %v", content, err)
+		}
+	}
+	if len(records) != 1 || records[0].Content != "kept\nredacted" || records[0].Lines != 2 || records[0].Sequence != 1 {
+		t.Fatalf("records = %+v", records)
+	}
+	if state.Sequence != 1 || state.TotalLines != 2 {
+		t.Errorf("dropped record counted: state %+v", state)
+	}
+}
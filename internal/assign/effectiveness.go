@@ -90,6 +90,10 @@ type EffectivenessIntegrator struct {
 	matrix  *CapabilityMatrix
 	cache   map[string]map[string]*scoring.AgentTaskEffectiveness
 	cacheAt time.Time
+
+	// comparisons caches pairwise significance tests by task type.
+	comparisons map[string][]*scoring.AgentComparison
+	comparedAt  map[string]time.Time
 }
 
 // NewEffectivenessIntegrator creates an integrator with the given config.
@@ -170,8 +174,52 @@ func (ei *EffectivenessIntegrator) GetEffectivenessScore(agentType, taskType str
 	return ei.tracker.QueryEffectiveness(agentType, taskType, ei.config.WindowDays)
 }
 
+// comparisonsFor returns the pairwise significance tests between agent types
+// for taskType, cached like effectiveness scores.
+func (ei *EffectivenessIntegrator) comparisonsFor(taskType string) []*scoring.AgentComparison {
+	ei.mu.RLock()
+	if at, ok := ei.comparedAt[taskType]; ok && time.Since(at) < 5*time.Minute {
+		comparisons := ei.comparisons[taskType]
+		ei.mu.RUnlock()
+		return comparisons
+	}
+	ei.mu.RUnlock()
+
+	comparisons, err := ei.tracker.CompareAllAgents(taskType, ei.config.WindowDays, 0)
+	if err != nil {
+		return nil
+	}
+
+	ei.mu.Lock()
+	defer ei.mu.Unlock()
+	if ei.comparisons == nil {
+		ei.comparisons = make(map[string][]*scoring.AgentComparison)
+		ei.comparedAt = make(map[string]time.Time)
+	}
+	ei.comparisons[taskType] = comparisons
+	ei.comparedAt[taskType] = time.Now()
+	return comparisons
+}
+
+// significant reports whether agentType scores significantly better (or,
+// if !better, worse) than some other agent type on taskType. Effectiveness
+// only moves an agent's standing in a direction this supports, so rankings
+// do not flip on a few noisy scores.
+func (ei *EffectivenessIntegrator) significant(agentType, taskType string, better bool) bool {
+	for _, c := range ei.comparisonsFor(taskType) {
+		if !c.Significant || (c.AgentA != agentType && c.AgentB != agentType) {
+			continue
+		}
+		if (c.Better == agentType) == better {
+			return true
+		}
+	}
+	return false
+}
+
 // GetEffectivenessBonus calculates an assignment bonus based on effectiveness.
-// Returns a bonus value and explanation.
+// The bonus is zero unless the agent differs significantly from another agent
+// type in the same direction. Returns a bonus value and explanation.
 func (ei *EffectivenessIntegrator) GetEffectivenessBonus(agentType, taskType string) (float64, string) {
 	if !ei.config.Enabled {
 		return 0, "effectiveness scoring disabled"
@@ -186,6 +234,10 @@ func (ei *EffectivenessIntegrator) GetEffectivenessBonus(agentType, taskType str
 	// Score > 0.5 = positive bonus, Score < 0.5 = negative bonus
 	baseline := 0.5
 	bonus := (eff.Score - baseline) * ei.config.EffectivenessWeight()
+	if bonus != 0 && !ei.significant(agentType, taskType, bonus > 0) {
+		return 0, fmt.Sprintf("effectiveness %.2f (%d samples) not significantly different from other agents",
+			eff.Score, eff.SampleCount)
+	}
 
 	reason := fmt.Sprintf("effectiveness %.2f (%d samples, %.0f%% confidence, mode=%s)",
 		eff.Score, eff.SampleCount, eff.Confidence*100, ei.config.Mode)
@@ -255,12 +307,17 @@ func (ei *EffectivenessIntegrator) RankAgentsForTask(taskType string) (*Effectiv
 			rank.SampleCount = eff.SampleCount
 			rank.Confidence = eff.Confidence
 
-			// Apply effectiveness weight
+			// Apply effectiveness weight, unless the difference it would
+			// make is not significant
 			weight := ei.config.EffectivenessWeight() * eff.Confidence
-			rank.Score = baseScore*(1-weight) + eff.Score*weight
-
-			rank.Explanation = fmt.Sprintf("base=%.2f, eff=%.2f (%d samples), weight=%.2f",
-				baseScore, eff.Score, eff.SampleCount, weight)
+			if eff.Score != baseScore && !ei.significant(string(agent), taskType, eff.Score > baseScore) {
+				rank.Explanation = fmt.Sprintf("base=%.2f, eff=%.2f (%d samples), not significant",
+					baseScore, eff.Score, eff.SampleCount)
+			} else {
+				rank.Score = baseScore*(1-weight) + eff.Score*weight
+				rank.Explanation = fmt.Sprintf("base=%.2f, eff=%.2f (%d samples), weight=%.2f",
+					baseScore, eff.Score, eff.SampleCount, weight)
+			}
 		} else {
 			rank.Explanation = fmt.Sprintf("base=%.2f (no effectiveness data)", baseScore)
 		}
@@ -1,6 +1,7 @@
 package assign
 
 import (
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -291,6 +292,29 @@ func populateCache(ei *EffectivenessIntegrator, data map[string]map[string]*scor
 	ei.cacheAt = at
 }
 
+// populateComparisons caches significance tests for taskType in which each
+// pair's first agent type is significantly better than its second.
+func populateComparisons(ei *EffectivenessIntegrator, taskType string, pairs ...[2]string) {
+	ei.mu.Lock()
+	defer ei.mu.Unlock()
+	if ei.comparisons == nil {
+		ei.comparisons = make(map[string][]*scoring.AgentComparison)
+		ei.comparedAt = make(map[string]time.Time)
+	}
+	comparisons := []*scoring.AgentComparison{}
+	for _, p := range pairs {
+		comparisons = append(comparisons, &scoring.AgentComparison{
+			TaskType:    taskType,
+			AgentA:      p[0],
+			AgentB:      p[1],
+			Significant: true,
+			Better:      p[0],
+		})
+	}
+	ei.comparisons[taskType] = comparisons
+	ei.comparedAt[taskType] = time.Now()
+}
+
 func TestGetEffectivenessScoreCacheHit(t *testing.T) {
 	ei := NewEffectivenessIntegrator(nil)
 
@@ -419,6 +443,12 @@ func TestGetEffectivenessBonusWithData(t *testing.T) {
 					Confidence:  0.95,
 				}},
 			}, time.Now())
+			switch tt.wantSign {
+			case 1:
+				populateComparisons(ei, "bug", [2]string{"cc", "cod"})
+			case -1:
+				populateComparisons(ei, "bug", [2]string{"cod", "cc"})
+			}
 
 			bonus, reason := ei.GetEffectivenessBonus("cc", "bug")
 
@@ -459,6 +489,7 @@ func TestGetEffectivenessBonusMagnitude(t *testing.T) {
 			Confidence:  0.95,
 		}},
 	}, time.Now())
+	populateComparisons(ei, "bug", [2]string{"cc", "cod"})
 
 	bonus, _ := ei.GetEffectivenessBonus("cc", "bug")
 
@@ -506,6 +537,7 @@ func TestRankAgentsForTaskWithEffectivenessData(t *testing.T) {
 		"cod": {"bug": {HasData: true, Score: 0.30, SampleCount: 15, Confidence: 1.0}},
 		"gmi": {"bug": {HasData: true, Score: 0.99, SampleCount: 8, Confidence: 1.0}},
 	}, time.Now())
+	populateComparisons(ei, "bug", [2]string{"gmi", "cc"}, [2]string{"gmi", "cod"})
 
 	ranking, err := ei.RankAgentsForTask("bug")
 	if err != nil {
@@ -553,6 +585,62 @@ func TestRankAgentsForTaskWithEffectivenessData(t *testing.T) {
 	}
 }
 
+func TestRankAgentsForTaskIgnoresInsignificantDifferences(t *testing.T) {
+	tracker, err := scoring.NewTracker(scoring.TrackerOptions{Path: filepath.Join(t.TempDir(), "scores.jsonl"), Enabled: true})
+	if err != nil {
+		t.Fatalf("NewTracker() error: %v", err)
+	}
+	defer tracker.Close()
+
+	// A few overlapping scores: gmi looks better on average, but not
+	// significantly.
+	now := time.Now().UTC()
+	for agent, values := range map[string][]float64{
+		"cc":  {0.40, 0.70, 0.55},
+		"cod": {0.45, 0.65, 0.50},
+		"gmi": {0.60, 0.75, 0.50},
+	} {
+		for i, v := range values {
+			tracker.Record(&scoring.Score{
+				Timestamp: now.Add(-time.Duration(i+1) * time.Minute),
+				AgentType: agent,
+				TaskType:  "bug",
+				Metrics:   scoring.ScoreMetrics{Overall: v},
+			})
+		}
+	}
+
+	cfg := DefaultEffectivenessConfig()
+	cfg.Mode = ModeExploitation
+	ei := NewEffectivenessIntegrator(cfg)
+	ei.tracker = tracker
+	populateCache(ei, map[string]map[string]*scoring.AgentTaskEffectiveness{
+		"cc":  {"bug": {HasData: true, Score: 0.55, SampleCount: 3, Confidence: 1.0}},
+		"cod": {"bug": {HasData: true, Score: 0.53, SampleCount: 3, Confidence: 1.0}},
+		"gmi": {"bug": {HasData: true, Score: 0.99, SampleCount: 3, Confidence: 1.0}},
+	}, time.Now())
+
+	ranking, err := ei.RankAgentsForTask("bug")
+	if err != nil {
+		t.Fatalf("RankAgentsForTask error: %v", err)
+	}
+
+	// Base order for bug: cod, cc, gmi.
+	want := []string{"cod", "cc", "gmi"}
+	for i, r := range ranking.Rankings {
+		if r.AgentType != want[i] {
+			t.Errorf("rank %d = %s (score=%.3f), want %s", i+1, r.AgentType, r.Score, want[i])
+		}
+		if r.Score != r.BaseScore {
+			t.Errorf("%s score = %.3f, want base %.3f", r.AgentType, r.Score, r.BaseScore)
+		}
+	}
+
+	if bonus, reason := ei.GetEffectivenessBonus("gmi", "bug"); bonus != 0 || !containsStr(reason, "not significantly different") {
+		t.Errorf("GetEffectivenessBonus(gmi) = %f, %q; want no bonus", bonus, reason)
+	}
+}
+
 func TestRankAgentsForTaskPartialData(t *testing.T) {
 	cfg := DefaultEffectivenessConfig()
 	cfg.Mode = ModeBalanced
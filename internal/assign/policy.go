@@ -0,0 +1,285 @@
+// policy.go runs Lua assignment policy scripts.
+package assign
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// DefaultPolicyTimeout bounds each call into a policy script.
+const DefaultPolicyTimeout = time.Second
+
+// PolicyBead is a bead as a policy script sees it.
+type PolicyBead struct {
+	ID       string
+	Title    string
+	Priority int // 0 (critical) to 4 (backlog)
+	TaskType string
+}
+
+// PolicyAgent is a candidate agent as a policy script sees it.
+type PolicyAgent struct {
+	Pane          int
+	Type          string
+	Name          string
+	Model         string
+	Workload      int     // Active assignments
+	Capability    float64 // Capability matrix score for the bead's task type
+	Effectiveness float64 // Effectiveness bonus for the bead's task type
+}
+
+// PolicyOptions configures a policy script.
+type PolicyOptions struct {
+	// Timeout bounds each call into the script (DefaultPolicyTimeout if zero).
+	Timeout time.Duration
+	// Owner returns the owner (agent name or type) of a path, or "" if the
+	// path is unowned. It backs ntm.owner() in scripts.
+	Owner func(path string) string
+}
+
+// PolicyScript is a loaded Lua assignment policy. A script customizes
+// assignment by defining any of these global functions:
+//
+//	allow(bead, agent)               -> false[, reason] to forbid the pairing
+//	score(bead, agent, base)         -> the pairing's score (base is the strategy's)
+//	reviewer(bead, agent, candidates) -> the agent (or its pane) to review the work
+//
+// Scripts get the base, string, table and math libraries and an ntm table
+// with owner(path) and log(msg); they cannot touch files, processes or the
+// network. A PolicyScript is safe for concurrent use.
+type PolicyScript struct {
+	Path string
+
+	mu      sync.Mutex
+	state   *lua.LState
+	timeout time.Duration
+}
+
+// LoadPolicyScript loads and runs the script at path.
+func LoadPolicyScript(path string, opts PolicyOptions) (*PolicyScript, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy script: %w", err)
+	}
+	return NewPolicyScript(path, string(src), opts)
+}
+
+// NewPolicyScript runs src as the policy script named path.
+func NewPolicyScript(path, src string, opts PolicyOptions) (*PolicyScript, error) {
+	s := &PolicyScript{Path: path, timeout: opts.Timeout}
+	if s.timeout <= 0 {
+		s.timeout = DefaultPolicyTimeout
+	}
+
+	L := lua.NewState(lua.Options{SkipOpenLibs: true, CallStackSize: 256, RegistryMaxSize: 1 << 16})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	logFn := L.NewFunction(func(L *lua.LState) int {
+		slog.Info("assign policy", "script", path, "msg", L.ToString(1))
+		return 0
+	})
+	L.SetGlobal("print", logFn)
+	ntm := L.NewTable()
+	ntm.RawSetString("log", logFn)
+	ntm.RawSetString("owner", L.NewFunction(func(L *lua.LState) int {
+		owner := ""
+		if opts.Owner != nil {
+			owner = opts.Owner(L.CheckString(1))
+		}
+		if owner == "" {
+			L.Push(lua.LNil)
+		} else {
+			L.Push(lua.LString(owner))
+		}
+		return 1
+	}))
+	L.SetGlobal("ntm", ntm)
+	s.state = L
+
+	fn, err := L.LoadString(src)
+	if err != nil {
+		L.Close()
+		return nil, fmt.Errorf("policy script %s: %w", path, err)
+	}
+	if _, err := s.call(fn, 0); err != nil {
+		L.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close releases the script's interpreter.
+func (s *PolicyScript) Close() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Close()
+}
+
+// Has reports whether the script defines the global function name.
+func (s *PolicyScript) Has(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.GetGlobal(name).Type() == lua.LTFunction
+}
+
+// Allow reports whether the script allows giving bead to agent, with the
+// script's reason when it does not. Scripts without allow() allow all.
+func (s *PolicyScript) Allow(bead PolicyBead, agent PolicyAgent) (bool, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn := s.state.GetGlobal("allow")
+	if fn.Type() != lua.LTFunction {
+		return true, "", nil
+	}
+	ret, err := s.call(fn, 2, s.beadTable(bead), s.agentTable(agent))
+	if err != nil {
+		return false, "", err
+	}
+	if ret[0] == lua.LNil || lua.LVAsBool(ret[0]) {
+		return true, "", nil
+	}
+	reason := ""
+	if ret[1] != lua.LNil {
+		reason = ret[1].String()
+	}
+	return false, reason, nil
+}
+
+// Score returns the script's score for giving bead to agent, where base is
+// the strategy's own score. Scripts without score(), or returning nil, keep
+// base.
+func (s *PolicyScript) Score(bead PolicyBead, agent PolicyAgent, base float64) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn := s.state.GetGlobal("score")
+	if fn.Type() != lua.LTFunction {
+		return base, nil
+	}
+	ret, err := s.call(fn, 1, s.beadTable(bead), s.agentTable(agent), lua.LNumber(base))
+	if err != nil {
+		return base, err
+	}
+	switch v := ret[0].(type) {
+	case *lua.LNilType:
+		return base, nil
+	case lua.LNumber:
+		if f := float64(v); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f, nil
+		}
+	}
+	return base, fmt.Errorf("policy script %s: score() returned %s, want a number", s.Path, ret[0].Type())
+}
+
+// Reviewer asks the script which of candidates should review agent's work
+// on bead. It returns nil when the script names none. The script may
+// return a candidate, its pane number or an agent type, which picks the
+// first candidate of that type.
+func (s *PolicyScript) Reviewer(bead PolicyBead, agent PolicyAgent, candidates []PolicyAgent) (*PolicyAgent, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn := s.state.GetGlobal("reviewer")
+	if fn.Type() != lua.LTFunction {
+		return nil, nil
+	}
+	list := s.state.NewTable()
+	for _, c := range candidates {
+		list.Append(s.agentTable(c))
+	}
+	ret, err := s.call(fn, 1, s.beadTable(bead), s.agentTable(agent), list)
+	if err != nil {
+		return nil, err
+	}
+	match := func(ok func(PolicyAgent) bool) *PolicyAgent {
+		for i := range candidates {
+			if ok(candidates[i]) {
+				return &candidates[i]
+			}
+		}
+		return nil
+	}
+	switch v := ret[0].(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case lua.LNumber:
+		return match(func(c PolicyAgent) bool { return c.Pane == int(v) }), nil
+	case lua.LString:
+		return match(func(c PolicyAgent) bool { return ParseAgentType(c.Type) == ParseAgentType(string(v)) }), nil
+	case *lua.LTable:
+		if pane, ok := v.RawGetString("pane").(lua.LNumber); ok {
+			return match(func(c PolicyAgent) bool { return c.Pane == int(pane) }), nil
+		}
+	}
+	return nil, fmt.Errorf("policy script %s: reviewer() returned %s, want an agent, pane or agent type", s.Path, ret[0].Type())
+}
+
+// call runs fn with args under the script timeout and returns nret
+// results. The caller holds s.mu.
+func (s *PolicyScript) call(fn lua.LValue, nret int, args ...lua.LValue) ([]lua.LValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	s.state.SetContext(ctx)
+	defer s.state.RemoveContext()
+
+	top := s.state.GetTop()
+	if err := s.state.CallByParam(lua.P{Fn: fn, NRet: nret, Protect: true}, args...); err != nil {
+		s.state.SetTop(top)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("policy script %s timed out after %v", s.Path, s.timeout)
+		}
+		return nil, fmt.Errorf("policy script %s: %w", s.Path, err)
+	}
+	ret := make([]lua.LValue, nret)
+	for i := range ret {
+		ret[i] = s.state.Get(top + 1 + i)
+	}
+	s.state.SetTop(top)
+	return ret, nil
+}
+
+func (s *PolicyScript) beadTable(b PolicyBead) *lua.LTable {
+	t := s.state.NewTable()
+	t.RawSetString("id", lua.LString(b.ID))
+	t.RawSetString("title", lua.LString(b.Title))
+	t.RawSetString("priority", lua.LNumber(b.Priority))
+	t.RawSetString("task_type", lua.LString(b.TaskType))
+	return t
+}
+
+func (s *PolicyScript) agentTable(a PolicyAgent) *lua.LTable {
+	t := s.state.NewTable()
+	t.RawSetString("pane", lua.LNumber(a.Pane))
+	t.RawSetString("type", lua.LString(a.Type))
+	t.RawSetString("name", lua.LString(a.Name))
+	t.RawSetString("model", lua.LString(a.Model))
+	t.RawSetString("workload", lua.LNumber(a.Workload))
+	t.RawSetString("capability", lua.LNumber(a.Capability))
+	t.RawSetString("effectiveness", lua.LNumber(a.Effectiveness))
+	return t
+}
@@ -0,0 +1,156 @@
+package assign
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicy = `
+function allow(bead, agent)
+  if bead.title:lower():find("migration") and agent.type == "gemini" then
+    return false, "no migrations for gemini"
+  end
+  if ntm.owner(bead.title:match("%S+/%S+") or "") == "BlueLake" and agent.name ~= "BlueLake" then
+    return false, "owned by BlueLake"
+  end
+  return true
+end
+
+function score(bead, agent, base)
+  if agent.workload > 2 then return nil end
+  return base + agent.effectiveness - agent.workload * 0.1
+end
+
+function reviewer(bead, agent, candidates)
+  if bead.task_type ~= "refactor" then return nil end
+  for _, c in ipairs(candidates) do
+    if c.type ~= agent.type then return c end
+  end
+  return "claude"
+end
+`
+
+func TestPolicyScript(t *testing.T) {
+	t.Parallel()
+
+	owner := func(path string) string {
+		if strings.HasPrefix(path, "web/") {
+			return "BlueLake"
+		}
+		return ""
+	}
+	s, err := NewPolicyScript("test.lua", testPolicy, PolicyOptions{Owner: owner})
+	if err != nil {
+		t.Fatalf("NewPolicyScript: %v", err)
+	}
+	defer s.Close()
+
+	migration := PolicyBead{ID: "bd-1", Title: "Add migration for users", TaskType: "feature"}
+	web := PolicyBead{ID: "bd-2", Title: "Fix web/app.ts layout", TaskType: "bug"}
+	refactor := PolicyBead{ID: "bd-3", Title: "Refactor parser", TaskType: "refactor"}
+	gemini := PolicyAgent{Pane: 1, Type: "gemini", Name: "RedFox"}
+	claude := PolicyAgent{Pane: 2, Type: "claude", Name: "BlueLake", Effectiveness: 0.1}
+	codex := PolicyAgent{Pane: 3, Type: "codex", Name: "GreenHill", Workload: 1}
+
+	allowTests := []struct {
+		bead   PolicyBead
+		agent  PolicyAgent
+		want   bool
+		reason string
+	}{
+		{migration, gemini, false, "no migrations for gemini"},
+		{migration, claude, true, ""},
+		{web, codex, false, "owned by BlueLake"},
+		{web, claude, true, ""},
+		{refactor, gemini, true, ""},
+	}
+	for _, tt := range allowTests {
+		ok, reason, err := s.Allow(tt.bead, tt.agent)
+		if err != nil || ok != tt.want || reason != tt.reason {
+			t.Errorf("Allow(%s, %s) = %v, %q, %v; want %v, %q", tt.bead.ID, tt.agent.Type, ok, reason, err, tt.want, tt.reason)
+		}
+	}
+
+	if got, err := s.Score(refactor, claude, 0.5); err != nil || got != 0.6 {
+		t.Errorf("Score(claude) = %v, %v; want 0.6", got, err)
+	}
+	if got, err := s.Score(refactor, codex, 0.5); err != nil || got != 0.4 {
+		t.Errorf("Score(codex) = %v, %v; want 0.4", got, err)
+	}
+	busy := codex
+	busy.Workload = 3
+	if got, err := s.Score(refactor, busy, 0.5); err != nil || got != 0.5 {
+		t.Errorf("Score(busy) = %v, %v; want base kept", got, err)
+	}
+
+	rev, err := s.Reviewer(refactor, claude, []PolicyAgent{gemini, codex})
+	if err != nil || rev == nil || rev.Pane != 1 {
+		t.Errorf("Reviewer(refactor) = %+v, %v; want pane 1", rev, err)
+	}
+	rev, err = s.Reviewer(refactor, codex, []PolicyAgent{{Pane: 4, Type: "codex"}, {Pane: 5, Type: "cc"}})
+	if err != nil || rev == nil || rev.Pane != 5 {
+		t.Errorf("Reviewer by type = %+v, %v; want pane 5", rev, err)
+	}
+	if rev, err := s.Reviewer(migration, claude, []PolicyAgent{gemini}); err != nil || rev != nil {
+		t.Errorf("Reviewer(feature) = %+v, %v; want none", rev, err)
+	}
+}
+
+func TestPolicyScript_Errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := NewPolicyScript("bad.lua", "function allow(", PolicyOptions{}); err == nil {
+		t.Error("syntax error not reported")
+	}
+	if _, err := LoadPolicyScript(filepath.Join(t.TempDir(), "missing.lua"), PolicyOptions{}); err == nil {
+		t.Error("missing script not reported")
+	}
+
+	// Scripts cannot reach files or processes.
+	for _, src := range []string{`dofile("/etc/passwd")`, `io.open("/etc/passwd")`, `os.execute("true")`, `require("os")`} {
+		if _, err := NewPolicyScript("escape.lua", src, PolicyOptions{}); err == nil {
+			t.Errorf("%s: want error", src)
+		}
+	}
+
+	s, err := NewPolicyScript("spin.lua", `
+function allow() while true do end end
+function score() return "high" end
+function reviewer() return true end
+`, PolicyOptions{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	start := time.Now()
+	if _, _, err := s.Allow(PolicyBead{}, PolicyAgent{}); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Allow of spinning script = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout took %v", elapsed)
+	}
+	// The interpreter stays usable after a timeout.
+	if got, err := s.Score(PolicyBead{}, PolicyAgent{}, 0.7); err == nil || !strings.Contains(err.Error(), "want a number") || got != 0.7 {
+		t.Errorf("Score returning a string = %v, %v; want base and error", got, err)
+	}
+	if _, err := s.Reviewer(PolicyBead{}, PolicyAgent{}, []PolicyAgent{{Pane: 1}}); err == nil {
+		t.Error("Reviewer returning a boolean: want error")
+	}
+
+	path := filepath.Join(t.TempDir(), "ok.lua")
+	if err := os.WriteFile(path, []byte(`function allow() return false end`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ok, err := LoadPolicyScript(path, PolicyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ok.Close()
+	if allowed, _, err := ok.Allow(PolicyBead{}, PolicyAgent{}); err != nil || allowed {
+		t.Errorf("Allow = %v, %v; want false", allowed, err)
+	}
+}
@@ -7,12 +7,14 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/util"
+	"github.com/Dicklesworthstone/ntm/internal/verify"
 )
 
 const (
@@ -27,6 +29,7 @@ type AssignmentStatus string
 const (
 	StatusAssigned   AssignmentStatus = "assigned"   // Prompt sent, waiting to start
 	StatusWorking    AssignmentStatus = "working"    // Agent actively working
+	StatusVerifying  AssignmentStatus = "verifying"  // Agent done, verification commands running
 	StatusCompleted  AssignmentStatus = "completed"  // Bead closed successfully
 	StatusFailed     AssignmentStatus = "failed"     // Agent crashed or gave up
 	StatusReassigned AssignmentStatus = "reassigned" // Moved to different agent
@@ -48,6 +51,18 @@ type Assignment struct {
 	FailureReason string           `json:"failure_reason,omitempty"` // Detailed failure reason
 	RetryCount    int              `json:"retry_count,omitempty"`    // Number of retry attempts
 	PromptSent    string           `json:"prompt_sent,omitempty"`    // The actual prompt sent
+	WorkingSet    *WorkingSet      `json:"working_set,omitempty"`    // What the task has touched so far
+	Verification  *Verification    `json:"verification,omitempty"`   // Latest verification outcome
+	HumanTakeover bool             `json:"human_takeover,omitempty"` // A human took over the pane while the task was active
+}
+
+// Verification records the latest verification run for an assignment and
+// how many times failures were sent back to the agent.
+type Verification struct {
+	Passed     bool                 `json:"passed"`
+	Checks     []verify.CheckResult `json:"checks"`
+	VerifiedAt time.Time            `json:"verified_at"`
+	Bounces    int                  `json:"bounces,omitempty"`
 }
 
 // AssignmentStore manages bead-to-agent assignments for a session
@@ -88,13 +103,14 @@ func (e *InvalidTransitionError) Error() string {
 }
 
 // StorageDir returns the path to the assignment storage directory.
-// Uses ~/.ntm/sessions/ (assignments are stored within session directories).
+// Uses util.SessionsDir, ~/.ntm/sessions/ by default (assignments are stored
+// within session directories).
 func StorageDir() string {
-	ntmDir, err := util.NTMDir()
+	dir, err := util.SessionsDir()
 	if err != nil {
 		return filepath.Join(os.TempDir(), "ntm", "sessions")
 	}
-	return filepath.Join(ntmDir, "sessions")
+	return dir
 }
 
 // NewStore creates a new AssignmentStore for a session
@@ -192,6 +208,13 @@ func (s *AssignmentStore) saveLocked() error {
 		return &PersistenceError{Operation: "save", Path: s.path, Cause: fmt.Errorf("create directory: %w", err)}
 	}
 
+	// Serialize with other processes sharing the fixed temp and backup paths.
+	unlock, err := util.LockFile(s.path)
+	if err != nil {
+		return &PersistenceError{Operation: "save", Path: s.path, Cause: err}
+	}
+	defer unlock()
+
 	s.UpdatedAt = time.Now().UTC()
 
 	data, err := json.MarshalIndent(s, "", "  ")
@@ -340,10 +363,11 @@ func (s *AssignmentStore) ListActive() []*Assignment {
 // ValidTransitions defines valid state transitions
 var ValidTransitions = map[AssignmentStatus][]AssignmentStatus{
 	StatusAssigned:   {StatusWorking, StatusFailed},
-	StatusWorking:    {StatusCompleted, StatusFailed, StatusReassigned},
-	StatusFailed:     {StatusAssigned}, // Retry
-	StatusCompleted:  {},               // Terminal
-	StatusReassigned: {},               // Terminal (new assignment created)
+	StatusWorking:    {StatusVerifying, StatusCompleted, StatusFailed, StatusReassigned},
+	StatusVerifying:  {StatusCompleted, StatusWorking, StatusFailed}, // Working = bounced back
+	StatusFailed:     {StatusAssigned},                               // Retry
+	StatusCompleted:  {},                                             // Terminal
+	StatusReassigned: {},                                             // Terminal (new assignment created)
 }
 
 // isValidTransition checks if a state transition is valid
@@ -386,7 +410,9 @@ func (s *AssignmentStore) UpdateStatus(beadID string, newStatus AssignmentStatus
 	assignment.Status = newStatus
 	switch newStatus {
 	case StatusWorking:
-		assignment.StartedAt = &now
+		if assignment.StartedAt == nil {
+			assignment.StartedAt = &now
+		}
 	case StatusCompleted:
 		assignment.CompletedAt = &now
 	case StatusFailed:
@@ -409,6 +435,89 @@ func (s *AssignmentStore) MarkWorking(beadID string) error {
 	return s.UpdateStatus(beadID, StatusWorking)
 }
 
+// MarkVerifying marks an assignment as done by the agent and awaiting
+// verification
+func (s *AssignmentStore) MarkVerifying(beadID string) error {
+	return s.UpdateStatus(beadID, StatusVerifying)
+}
+
+// RecordVerification attaches a verification result to an assignment
+// without changing its status
+func (s *AssignmentStore) RecordVerification(beadID string, result *verify.Result) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	assignment, ok := s.Assignments[beadID]
+	if !ok {
+		return fmt.Errorf("[ASSIGN] Assignment not found: %s", beadID)
+	}
+
+	v := assignment.Verification
+	if v == nil {
+		v = &Verification{}
+		assignment.Verification = v
+	}
+	v.Passed = result.Passed
+	v.Checks = result.Checks
+	v.VerifiedAt = result.FinishedAt
+
+	if err := s.saveLocked(); err != nil {
+		slog.Warn("failed to persist assignment store", "error", err)
+	}
+	return nil
+}
+
+// MarkHumanTakeover flags the active assignments on a pane as worked on by
+// a human and returns their bead IDs
+func (s *AssignmentStore) MarkHumanTakeover(pane int) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var beads []string
+	for _, a := range s.Assignments {
+		if a.Pane != pane {
+			continue
+		}
+		switch a.Status {
+		case StatusAssigned, StatusWorking, StatusVerifying:
+			a.HumanTakeover = true
+			beads = append(beads, a.BeadID)
+		}
+	}
+	if len(beads) == 0 {
+		return nil
+	}
+	sort.Strings(beads)
+
+	if err := s.saveLocked(); err != nil {
+		slog.Warn("failed to persist assignment store", "error", err)
+	}
+	return beads
+}
+
+// Bounce sends an assignment that failed verification back to working and
+// returns how many times it has been bounced
+func (s *AssignmentStore) Bounce(beadID string) (int, error) {
+	s.mutex.Lock()
+	assignment, ok := s.Assignments[beadID]
+	if !ok {
+		s.mutex.Unlock()
+		return 0, fmt.Errorf("[ASSIGN] Assignment not found: %s", beadID)
+	}
+	if assignment.Status != StatusVerifying {
+		s.mutex.Unlock()
+		return 0, &InvalidTransitionError{BeadID: beadID, From: assignment.Status, To: StatusWorking}
+	}
+	if assignment.Verification == nil {
+		assignment.Verification = &Verification{}
+	}
+	assignment.Verification.Bounces++
+	bounces := assignment.Verification.Bounces
+	s.mutex.Unlock()
+
+	return bounces, s.UpdateStatus(beadID, StatusWorking)
+}
+
 // MarkCompleted marks an assignment as completed
 func (s *AssignmentStore) MarkCompleted(beadID string) error {
 	return s.UpdateStatus(beadID, StatusCompleted)
@@ -481,6 +590,7 @@ func (s *AssignmentStore) Reassign(beadID string, newPane int, newAgentType, new
 		Status:     StatusAssigned,
 		AssignedAt: now,
 		PromptSent: oldAssignment.PromptSent,
+		WorkingSet: oldAssignment.WorkingSet, // The work done so far stays with the task
 	}
 
 	s.Assignments[beadID] = newAssignment
@@ -529,6 +639,8 @@ func (s *AssignmentStore) Stats() AssignmentStats {
 			stats.Assigned++
 		case StatusWorking:
 			stats.Working++
+		case StatusVerifying:
+			stats.Verifying++
 		case StatusCompleted:
 			stats.Completed++
 		case StatusFailed:
@@ -545,6 +657,7 @@ type AssignmentStats struct {
 	Total      int `json:"total"`
 	Assigned   int `json:"assigned"`
 	Working    int `json:"working"`
+	Verifying  int `json:"verifying"`
 	Completed  int `json:"completed"`
 	Failed     int `json:"failed"`
 	Reassigned int `json:"reassigned"`
@@ -628,7 +741,7 @@ func (s *AssignmentStore) maybeEmitAgentIdleLocked(a *Assignment, prevStatus, ne
 	if a == nil {
 		return
 	}
-	if prevStatus != StatusWorking {
+	if prevStatus != StatusWorking && prevStatus != StatusVerifying {
 		return
 	}
 	if newStatus != StatusCompleted && newStatus != StatusFailed {
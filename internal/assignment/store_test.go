@@ -7,6 +7,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/verify"
 )
 
 func TestNewStore(t *testing.T) {
@@ -274,6 +276,59 @@ func TestMarkFailed(t *testing.T) {
 	}
 }
 
+func TestVerificationBounce(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	store := NewStore("test-session")
+	_, _ = store.Assign("bd-123", "Test bead", 1, "claude", "", "")
+	_ = store.MarkWorking("bd-123")
+	started := *store.Get("bd-123").StartedAt
+
+	if _, err := store.Bounce("bd-123"); err == nil {
+		t.Error("expected bounce from working to fail")
+	}
+	if err := store.MarkVerifying("bd-123"); err != nil {
+		t.Fatalf("MarkVerifying: %v", err)
+	}
+	if n := len(store.ListActive()); n != 0 {
+		t.Errorf("expected verifying assignment to be inactive, got %d active", n)
+	}
+
+	failed := &verify.Result{Checks: []verify.CheckResult{{Name: "test", ExitCode: 1}}, FinishedAt: time.Now()}
+	if err := store.RecordVerification("bd-123", failed); err != nil {
+		t.Fatalf("RecordVerification: %v", err)
+	}
+	bounces, err := store.Bounce("bd-123")
+	if err != nil || bounces != 1 {
+		t.Fatalf("Bounce = %d, %v; want 1, nil", bounces, err)
+	}
+
+	a := store.Get("bd-123")
+	if a.Status != StatusWorking {
+		t.Errorf("expected status working after bounce, got %s", a.Status)
+	}
+	if !a.StartedAt.Equal(started) {
+		t.Error("expected StartedAt to survive the bounce")
+	}
+	if a.Verification.Passed || len(a.Verification.Checks) != 1 {
+		t.Errorf("verification = %+v", a.Verification)
+	}
+
+	_ = store.MarkVerifying("bd-123")
+	_ = store.RecordVerification("bd-123", &verify.Result{Passed: true, FinishedAt: time.Now()})
+	if err := store.MarkCompleted("bd-123"); err != nil {
+		t.Fatalf("MarkCompleted: %v", err)
+	}
+	a = store.Get("bd-123")
+	if !a.Verification.Passed || a.Verification.Bounces != 1 {
+		t.Errorf("verification = %+v, want passed after 1 bounce", a.Verification)
+	}
+	if got := store.Stats(); got.Completed != 1 || got.Verifying != 0 {
+		t.Errorf("stats = %+v", got)
+	}
+}
+
 func TestReassign(t *testing.T) {
 	tmpDir := t.TempDir()
 	t.Setenv("HOME", tmpDir)
@@ -589,3 +644,25 @@ func TestPersistenceErrorTypes(t *testing.T) {
 		t.Error("expected non-empty error string")
 	}
 }
+
+func TestMarkHumanTakeover(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+
+	store := NewStore("test-session")
+	_, _ = store.Assign("bd-1", "Active", 2, "claude", "", "")
+	_, _ = store.Assign("bd-2", "Done", 2, "claude", "", "")
+	_ = store.MarkWorking("bd-2")
+	_ = store.MarkCompleted("bd-2")
+	_, _ = store.Assign("bd-3", "Other pane", 3, "codex", "", "")
+
+	if got := store.MarkHumanTakeover(2); len(got) != 1 || got[0] != "bd-1" {
+		t.Errorf("MarkHumanTakeover = %v, want [bd-1]", got)
+	}
+	if !store.Get("bd-1").HumanTakeover || store.Get("bd-2").HumanTakeover || store.Get("bd-3").HumanTakeover {
+		t.Error("only the active assignment on the pane should be flagged")
+	}
+	if got := store.MarkHumanTakeover(5); got != nil {
+		t.Errorf("MarkHumanTakeover on an empty pane = %v", got)
+	}
+}
@@ -0,0 +1,178 @@
+package assignment
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxWorkingSetCommands bounds the commands kept per working set.
+const maxWorkingSetCommands = 50
+
+// WorkingSet is what a task has touched so far: files its agent read and
+// wrote (from tool calls and git changes), commands it ran, and the paths
+// it holds reservations for. It is persisted with the assignment so new
+// work can be checked against in-flight work before it is assigned.
+type WorkingSet struct {
+	FilesRead    []string  `json:"files_read,omitempty"`
+	FilesWritten []string  `json:"files_written,omitempty"`
+	Commands     []string  `json:"commands,omitempty"` // Most recent last
+	Reserved     []string  `json:"reserved,omitempty"` // Reservation path patterns
+	UpdatedAt    time.Time `json:"updated_at,omitempty"`
+}
+
+// Merge adds other's entries to w and reports whether anything was new.
+// Reserved is replaced, as it reflects the reservations currently held.
+func (w *WorkingSet) Merge(other WorkingSet) bool {
+	changed := false
+	add := func(list []string, items []string) []string {
+		for _, item := range items {
+			if item != "" && !slices.Contains(list, item) {
+				list = append(list, item)
+				changed = true
+			}
+		}
+		return list
+	}
+	w.FilesRead = add(w.FilesRead, other.FilesRead)
+	w.FilesWritten = add(w.FilesWritten, other.FilesWritten)
+	w.Commands = add(w.Commands, other.Commands)
+	if len(w.Commands) > maxWorkingSetCommands {
+		w.Commands = w.Commands[len(w.Commands)-maxWorkingSetCommands:]
+	}
+	if other.Reserved != nil && !slices.Equal(w.Reserved, other.Reserved) {
+		w.Reserved = other.Reserved
+		changed = true
+	}
+	return changed
+}
+
+// RecordWorkingSet merges ws into the working set of a bead's assignment
+// and persists the store if it changed.
+func (s *AssignmentStore) RecordWorkingSet(beadID string, ws WorkingSet) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	a, ok := s.Assignments[beadID]
+	if !ok {
+		return fmt.Errorf("[ASSIGN] Assignment not found: %s", beadID)
+	}
+	if a.WorkingSet == nil {
+		a.WorkingSet = &WorkingSet{}
+	}
+	if !a.WorkingSet.Merge(ws) {
+		return nil
+	}
+	a.WorkingSet.UpdatedAt = time.Now().UTC()
+
+	if err := s.saveLocked(); err != nil {
+		slog.Warn("failed to persist assignment store", "error", err)
+	}
+	return nil
+}
+
+// WorkingSetOverlap describes how a new task's paths collide with an
+// in-flight assignment's working set.
+type WorkingSetOverlap struct {
+	BeadID    string   `json:"bead_id"`
+	Pane      int      `json:"pane"`
+	AgentType string   `json:"agent_type"`
+	Written   []string `json:"written,omitempty"`  // Files the task wrote
+	Reserved  []string `json:"reserved,omitempty"` // Reservation patterns it holds
+	Read      []string `json:"read,omitempty"`     // Files it only read
+}
+
+// Conflicting reports whether the overlap involves files the in-flight
+// task changes or holds; shared reads alone do not conflict.
+func (o WorkingSetOverlap) Conflicting() bool {
+	return len(o.Written) > 0 || len(o.Reserved) > 0
+}
+
+// Overlaps compares paths (files, directories or globs, as extracted from a
+// task description) with the working sets of active assignments and returns
+// the assignments they overlap.
+func (s *AssignmentStore) Overlaps(paths []string) []WorkingSetOverlap {
+	if len(paths) == 0 {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var overlaps []WorkingSetOverlap
+	for _, a := range s.Assignments {
+		if a.WorkingSet == nil || (a.Status != StatusAssigned && a.Status != StatusWorking) {
+			continue
+		}
+		o := WorkingSetOverlap{BeadID: a.BeadID, Pane: a.Pane, AgentType: a.AgentType}
+		for _, f := range a.WorkingSet.FilesWritten {
+			if matchesAnyPath(f, paths) {
+				o.Written = append(o.Written, f)
+			}
+		}
+		for _, r := range a.WorkingSet.Reserved {
+			for _, p := range paths {
+				if MatchPath(p, r) || MatchPath(r, p) {
+					o.Reserved = append(o.Reserved, r)
+					break
+				}
+			}
+		}
+		for _, f := range a.WorkingSet.FilesRead {
+			if matchesAnyPath(f, paths) && !slices.Contains(o.Written, f) {
+				o.Read = append(o.Read, f)
+			}
+		}
+		if len(o.Written)+len(o.Reserved)+len(o.Read) > 0 {
+			overlaps = append(overlaps, o)
+		}
+	}
+	slices.SortFunc(overlaps, func(a, b WorkingSetOverlap) int { return strings.Compare(a.BeadID, b.BeadID) })
+	return overlaps
+}
+
+func matchesAnyPath(file string, patterns []string) bool {
+	for _, p := range patterns {
+		if MatchPath(file, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether file is pattern, lies under it as a directory,
+// or matches it as a glob ("**" matches any number of segments).
+func MatchPath(file, pattern string) bool {
+	file = path.Clean(strings.TrimPrefix(file, "./"))
+	pattern = strings.TrimSuffix(path.Clean(strings.TrimPrefix(pattern, "./")), "/")
+	if file == pattern || strings.HasPrefix(file, pattern+"/") {
+		return true
+	}
+	if !strings.Contains(pattern, "*") {
+		return false
+	}
+	if prefix, suffix, ok := strings.Cut(pattern, "**"); ok {
+		if !strings.HasPrefix(file, prefix) {
+			return false
+		}
+		suffix = strings.TrimPrefix(suffix, "/")
+		if suffix == "" {
+			return true
+		}
+		rest := strings.TrimPrefix(file, prefix)
+		for {
+			if ok, _ := path.Match(suffix, rest); ok {
+				return true
+			}
+			i := strings.Index(rest, "/")
+			if i < 0 {
+				return false
+			}
+			rest = rest[i+1:]
+		}
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
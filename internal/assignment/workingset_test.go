@@ -0,0 +1,128 @@
+package assignment
+
+import (
+	"testing"
+)
+
+func TestWorkingSetMerge(t *testing.T) {
+	var ws WorkingSet
+	if !ws.Merge(WorkingSet{FilesRead: []string{"a.go"}, FilesWritten: []string{"b.go"}, Commands: []string{"go test"}}) {
+		t.Fatal("first merge should report a change")
+	}
+	if ws.Merge(WorkingSet{FilesRead: []string{"a.go"}, Commands: []string{"go test"}}) {
+		t.Error("merging known entries should not report a change")
+	}
+	if !ws.Merge(WorkingSet{Reserved: []string{"internal/**"}}) {
+		t.Error("new reservations should report a change")
+	}
+	if !ws.Merge(WorkingSet{Reserved: []string{}}) || len(ws.Reserved) != 0 {
+		t.Errorf("released reservations should replace the list, got %v", ws.Reserved)
+	}
+
+	for i := 0; i < maxWorkingSetCommands+5; i++ {
+		ws.Merge(WorkingSet{Commands: []string{string(rune('a'+i%26)) + string(rune('0'+i/26))}})
+	}
+	if len(ws.Commands) != maxWorkingSetCommands {
+		t.Errorf("commands = %d, want capped at %d", len(ws.Commands), maxWorkingSetCommands)
+	}
+}
+
+func TestRecordWorkingSetAndOverlaps(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store := NewStore("ws-session")
+	if _, err := store.Assign("bd-1", "Refactor the API", 1, "claude", "BlueLake", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Assign("bd-2", "Docs", 2, "codex", "GreenHill", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordWorkingSet("bd-1", WorkingSet{
+		FilesRead:    []string{"internal/api/types.go"},
+		FilesWritten: []string{"internal/api/routes.go"},
+		Reserved:     []string{"internal/api/**"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordWorkingSet("bd-2", WorkingSet{FilesRead: []string{"README.md"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RecordWorkingSet("bd-missing", WorkingSet{}); err == nil {
+		t.Error("expected error for unknown bead")
+	}
+
+	// Persisted with the assignment
+	reloaded, _ := LoadStore("ws-session")
+	if ws := reloaded.Get("bd-1").WorkingSet; ws == nil || len(ws.FilesWritten) != 1 || ws.UpdatedAt.IsZero() {
+		t.Fatalf("reloaded working set = %+v", ws)
+	}
+
+	overlaps := store.Overlaps([]string{"internal/api/routes.go"})
+	if len(overlaps) != 1 || overlaps[0].BeadID != "bd-1" || !overlaps[0].Conflicting() {
+		t.Fatalf("overlaps = %+v", overlaps)
+	}
+	if len(overlaps[0].Written) != 1 || len(overlaps[0].Reserved) != 1 {
+		t.Errorf("overlap = %+v", overlaps[0])
+	}
+
+	// A directory reaching into the reservation, and a read-only overlap
+	overlaps = store.Overlaps([]string{"internal", "README.md"})
+	if len(overlaps) != 2 {
+		t.Fatalf("overlaps = %+v", overlaps)
+	}
+	if overlaps[1].BeadID != "bd-2" || overlaps[1].Conflicting() || len(overlaps[1].Read) != 1 {
+		t.Errorf("read-only overlap = %+v", overlaps[1])
+	}
+
+	if got := store.Overlaps([]string{"cmd/main.go"}); len(got) != 0 {
+		t.Errorf("unrelated path overlaps = %+v", got)
+	}
+
+	// Finished work no longer counts
+	_ = store.MarkWorking("bd-1")
+	_ = store.MarkCompleted("bd-1")
+	if got := store.Overlaps([]string{"internal/api/routes.go"}); len(got) != 0 {
+		t.Errorf("completed assignment still overlaps: %+v", got)
+	}
+}
+
+func TestReassignKeepsWorkingSet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store := NewStore("ws-reassign")
+	_, _ = store.Assign("bd-1", "Task", 1, "claude", "", "")
+	_ = store.MarkWorking("bd-1")
+	_ = store.RecordWorkingSet("bd-1", WorkingSet{FilesWritten: []string{"main.go"}})
+
+	a, err := store.Reassign("bd-1", 2, "codex", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.WorkingSet == nil || a.WorkingSet.FilesWritten[0] != "main.go" {
+		t.Errorf("working set = %+v", a.WorkingSet)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		file, pattern string
+		want          bool
+	}{
+		{"internal/api/routes.go", "internal/api/routes.go", true},
+		{"./internal/api/routes.go", "internal/api/routes.go", true},
+		{"internal/api/routes.go", "internal/api", true},
+		{"internal/api/routes.go", "internal/api/", true},
+		{"internal/apix/routes.go", "internal/api", false},
+		{"internal/api/routes.go", "internal/api/*.go", true},
+		{"internal/api/v1/routes.go", "internal/api/*.go", false},
+		{"internal/api/v1/routes.go", "internal/**/*.go", true},
+		{"internal/api/v1/routes.go", "internal/**", true},
+		{"cmd/main.go", "internal/**", false},
+		{"routes.go", "*.go", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.file, tt.pattern); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.file, tt.pattern, got, tt.want)
+		}
+	}
+}
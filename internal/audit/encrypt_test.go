@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/encryption"
+)
+
+func TestEncryptedAuditLogRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	key := make([]byte, encryption.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	SetEncryptionConfig(&EncryptionConfig{Enabled: true, EncryptKey: key, DecryptKeys: [][]byte{key}})
+	defer SetEncryptionConfig(nil)
+
+	logger, err := NewAuditLogger(&LoggerConfig{SessionID: "enc-session", BufferSize: 1, FlushInterval: time.Second})
+	if err != nil {
+		t.Fatalf("NewAuditLogger: %v", err)
+	}
+	for _, msg := range []string{"secret prompt alpha", "secret prompt beta"} {
+		if err := logger.Log(AuditEntry{
+			EventType: EventTypeSend,
+			Actor:     ActorUser,
+			Target:    "cc_1",
+			Payload:   map[string]interface{}{"message": msg},
+		}); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	auditDir := filepath.Join(tempDir, ".local", "share", "ntm", "audit")
+	matches, _ := filepath.Glob(filepath.Join(auditDir, "enc-session-*.jsonl"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one audit log, got %v", matches)
+	}
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret prompt")) {
+		t.Fatal("audit log contains plaintext payload")
+	}
+
+	if err := VerifyIntegrity(matches[0]); err != nil {
+		t.Fatalf("VerifyIntegrity on encrypted log: %v", err)
+	}
+
+	// A reopened logger continues the chain from the encrypted tail.
+	logger, err = NewAuditLogger(&LoggerConfig{SessionID: "enc-session", BufferSize: 1, FlushInterval: time.Second})
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if err := logger.Log(AuditEntry{EventType: EventTypeCommand, Actor: ActorSystem, Target: "cc_1"}); err != nil {
+		t.Fatalf("Log after reopen: %v", err)
+	}
+	logger.Close()
+	if err := VerifyIntegrity(matches[0]); err != nil {
+		t.Fatalf("VerifyIntegrity after reopen: %v", err)
+	}
+
+	result, err := NewSearcherWithPath(auditDir).Search(Query{GrepPattern: "alpha"})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Payload["message"] != "secret prompt alpha" {
+		t.Fatalf("unexpected search result: %+v", result.Entries)
+	}
+
+	// Without the key the entries cannot be read.
+	SetEncryptionConfig(nil)
+	if err := VerifyIntegrity(matches[0]); err == nil {
+		t.Fatal("expected VerifyIntegrity to fail without the key")
+	}
+}
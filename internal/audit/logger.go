@@ -16,6 +16,7 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/privacy"
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // EventType represents the type of audit event
@@ -28,6 +29,10 @@ const (
 	EventTypeResponse    EventType = "response"
 	EventTypeError       EventType = "error"
 	EventTypeStateChange EventType = "state_change"
+	EventTypePurge       EventType = "purge"
+	EventTypeRepair      EventType = "chain_repair"
+	EventTypeConfirm     EventType = "confirmation"
+	EventTypeAPIRequest  EventType = "api_request"
 )
 
 // Actor represents who performed the action
@@ -78,7 +83,13 @@ type LoggerConfig struct {
 	FlushInterval time.Duration // Maximum time between flushes
 }
 
+// DefaultDir is where audit logs are written unless SetDir moves them.
+const DefaultDir = "~/.local/share/ntm/audit"
+
 var (
+	dirMu       sync.RWMutex
+	dirOverride string
+
 	redactionMu     sync.RWMutex
 	redactionCfg    redaction.Config
 	redactionCfgSet bool
@@ -101,6 +112,23 @@ func SetRedactionConfig(cfg *redaction.Config) {
 	redactionCfgSet = true
 }
 
+// SetDir moves audit logs to dir. An empty dir restores DefaultDir.
+func SetDir(dir string) {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	dirOverride = dir
+}
+
+// Dir returns the directory audit logs are written to.
+func Dir() string {
+	dirMu.RLock()
+	defer dirMu.RUnlock()
+	if dirOverride != "" {
+		return util.ExpandPath(dirOverride)
+	}
+	return util.ExpandPath(DefaultDir)
+}
+
 // NewCorrelationID returns a unique correlation ID for command tracing.
 func NewCorrelationID() string {
 	ms := time.Now().UnixMilli()
@@ -204,11 +232,11 @@ func sanitizeMap(input map[string]interface{}) map[string]interface{} {
 func sanitizeValue(value interface{}) interface{} {
 	switch v := value.(type) {
 	case string:
-		return redactString(v)
+		return RedactString(v)
 	case []string:
 		out := make([]string, len(v))
 		for i, item := range v {
-			out[i] = redactString(item)
+			out[i] = RedactString(item)
 		}
 		return out
 	case []interface{}:
@@ -224,7 +252,10 @@ func sanitizeValue(value interface{}) interface{} {
 	}
 }
 
-func redactString(value string) string {
+// RedactString redacts value with the audit redaction config, as logged
+// payloads are. Callers that shorten values before logging them must redact
+// first: a truncated secret no longer matches its pattern.
+func RedactString(value string) string {
 	cfg := getRedactionConfig()
 	if cfg.Mode == redaction.ModeOff {
 		return value
@@ -259,12 +290,7 @@ func NewAuditLogger(config *LoggerConfig) (*AuditLogger, error) {
 	}
 
 	// Create audit log directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	auditDir := filepath.Join(homeDir, ".local", "share", "ntm", "audit")
+	auditDir := Dir()
 	if err := os.MkdirAll(auditDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create audit directory: %w", err)
 	}
@@ -376,10 +402,10 @@ func (al *AuditLogger) readLastEntry(fileSize int64) (*AuditEntry, error) {
 				}
 
 				scanner := bufio.NewScanner(readFile)
+				scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 				if scanner.Scan() {
-					line := scanner.Text()
 					var entry AuditEntry
-					if err := json.Unmarshal([]byte(line), &entry); err == nil {
+					if err := unmarshalEntry(scanner.Bytes(), &entry); err == nil {
 						return &entry, nil
 					}
 					// If invalid JSON, keep searching backwards (skip corrupted tail)
@@ -393,10 +419,10 @@ func (al *AuditLogger) readLastEntry(fileSize int64) (*AuditEntry, error) {
 		return nil, err
 	}
 	scanner := bufio.NewScanner(readFile)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 	if scanner.Scan() {
-		line := scanner.Text()
 		var entry AuditEntry
-		if err := json.Unmarshal([]byte(line), &entry); err == nil {
+		if err := unmarshalEntry(scanner.Bytes(), &entry); err == nil {
 			return &entry, nil
 		}
 	}
@@ -449,6 +475,13 @@ func (al *AuditLogger) Log(entry AuditEntry) error {
 		return fmt.Errorf("failed to marshal final audit entry: %w", err)
 	}
 
+	// The checksum covers the plaintext entry, so the chain can be verified
+	// by anyone holding a decryption key.
+	entryData, err = encryptJSONLine(entryData)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt audit entry: %w", err)
+	}
+
 	// Write to buffer
 	if _, err := al.writer.Write(entryData); err != nil {
 		return fmt.Errorf("failed to write audit entry: %w", err)
@@ -531,17 +564,18 @@ func VerifyIntegrity(logPath string) error {
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 	var prevHash string
 	var sequenceNum uint64
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
+		line := scanner.Bytes()
+		if len(line) == 0 {
 			continue
 		}
 
 		var entry AuditEntry
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		if err := unmarshalEntry(line, &entry); err != nil {
 			return fmt.Errorf("invalid JSON in audit log: %w", err)
 		}
 
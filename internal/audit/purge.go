@@ -0,0 +1,265 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultSigningKeyPath is where the key used to sign purge tombstones is
+// kept. It is created on first use.
+const DefaultSigningKeyPath = "~/.config/ntm/audit-signing.key"
+
+// TombstoneSignatureAlg identifies the tombstone signature scheme.
+const TombstoneSignatureAlg = "hmac-sha256"
+
+// PurgeResult describes the result of PurgeSession.
+type PurgeResult struct {
+	Files      []string `json:"files,omitempty"`
+	Anonymized int      `json:"anonymized"`
+}
+
+// LoadSigningKey reads the tombstone signing key at path
+// (DefaultSigningKeyPath if empty), generating one if it does not exist yet.
+func LoadSigningKey(path string) ([]byte, error) {
+	if path == "" {
+		path = DefaultSigningKeyPath
+	}
+	path = util.ExpandPath(path)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) == 0 {
+			return nil, fmt.Errorf("invalid audit signing key in %s", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read audit signing key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate audit signing key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write audit signing key: %w", err)
+	}
+	return key, nil
+}
+
+// PurgeSession anonymizes the audit entries of session in auditDir. If
+// before is non-zero, only entries recorded before it are anonymized.
+//
+// Anonymized entries keep their timestamp, event type, actor, target, and
+// sequence number; payload and metadata are dropped. The chain is resealed
+// from the first changed entry and closed with a purge tombstone that
+// records the original chain head and a digest of the original checksums of
+// the anonymized entries, signed with key. Logs whose chain does not verify
+// are left alone and reported as an error.
+func PurgeSession(auditDir, session string, before time.Time, key []byte) (*PurgeResult, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("audit signing key required")
+	}
+	entries, err := os.ReadDir(auditDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &PurgeResult{}, nil
+		}
+		return nil, fmt.Errorf("failed to read audit directory: %w", err)
+	}
+
+	result := &PurgeResult{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isSessionLog(entry.Name(), session) {
+			continue
+		}
+		path := filepath.Join(auditDir, entry.Name())
+		n, err := purgeLog(path, session, before, key)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if n > 0 {
+			result.Files = append(result.Files, path)
+			result.Anonymized += n
+		}
+	}
+	return result, nil
+}
+
+// isSessionLog reports whether name is a log file (<session>-<date>.jsonl)
+// of session.
+func isSessionLog(name, session string) bool {
+	rest, ok := strings.CutPrefix(name, session+"-")
+	if !ok {
+		return false
+	}
+	date, ok := strings.CutSuffix(rest, ".jsonl")
+	if !ok {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+func purgeLog(logPath, session string, before time.Time, key []byte) (int, error) {
+	if err := VerifyIntegrity(logPath); err != nil {
+		return 0, fmt.Errorf("refusing to reseal a broken chain (run 'ntm fsck --repair' first): %w", err)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []AuditEntry
+	var raw [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := unmarshalEntry(line, &entry); err != nil {
+			return 0, fmt.Errorf("invalid JSON in audit log: %w", err)
+		}
+		entries = append(entries, entry)
+		raw = append(raw, line)
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	first := -1
+	anonymized := 0
+	digest := sha256.New()
+	for i := range entries {
+		entry := &entries[i]
+		if entry.EventType == EventTypePurge || entry.Metadata["purged"] == true {
+			continue
+		}
+		if !before.IsZero() && !entry.Timestamp.Before(before) {
+			continue
+		}
+		digest.Write([]byte(entry.Checksum + "\n"))
+		entry.Payload = nil
+		entry.Metadata = map[string]interface{}{"purged": true}
+		anonymized++
+		if first < 0 {
+			first = i
+		}
+	}
+	if anonymized == 0 {
+		return 0, nil
+	}
+
+	originalHead := entries[len(entries)-1].Checksum
+	var out bytes.Buffer
+	var prevHash string
+	for i := range entries {
+		entry := entries[i]
+		if i < first {
+			out.Write(raw[i])
+			out.WriteByte('\n')
+			prevHash = entry.Checksum
+			continue
+		}
+		entry.PrevHash = prevHash
+		entry.Checksum = entryChecksum(entry)
+		if err := writeEntryLine(&out, entry); err != nil {
+			return 0, err
+		}
+		prevHash = entry.Checksum
+	}
+
+	payload := map[string]interface{}{
+		"session":          session,
+		"anonymized":       anonymized,
+		"original_entries": len(entries),
+		"original_head":    originalHead,
+		"purged_digest":    hex.EncodeToString(digest.Sum(nil)),
+	}
+	if !before.IsZero() {
+		payload["before"] = before.UTC().Format(time.RFC3339)
+	}
+	tombstone := AuditEntry{
+		Timestamp:   time.Now().UTC(),
+		SessionID:   entries[0].SessionID,
+		EventType:   EventTypePurge,
+		Actor:       ActorUser,
+		Target:      session,
+		Payload:     payload,
+		PrevHash:    prevHash,
+		SequenceNum: uint64(len(entries) + 1),
+	}
+	tombstone.Metadata = map[string]interface{}{
+		"signature_alg": TombstoneSignatureAlg,
+		"signature":     hex.EncodeToString(tombstoneMAC(tombstone, key)),
+	}
+	tombstone.Checksum = entryChecksum(tombstone)
+	if err := writeEntryLine(&out, tombstone); err != nil {
+		return 0, err
+	}
+
+	if err := util.AtomicWriteFile(logPath, out.Bytes(), info.Mode().Perm()); err != nil {
+		return 0, fmt.Errorf("failed to write purged audit log: %w", err)
+	}
+	return anonymized, nil
+}
+
+func writeEntryLine(out *bytes.Buffer, entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if line, err = encryptJSONLine(line); err != nil {
+		return fmt.Errorf("failed to encrypt audit entry: %w", err)
+	}
+	out.Write(line)
+	out.WriteByte('\n')
+	return nil
+}
+
+// tombstoneMAC signs everything in a tombstone except its metadata (which
+// carries the signature) and checksum (which covers the signature).
+func tombstoneMAC(entry AuditEntry, key []byte) []byte {
+	entry.Metadata = nil
+	entry.Checksum = ""
+	data, _ := json.Marshal(entry)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// VerifyTombstone checks the signature of a purge tombstone, or of the repair
+// entry RepairChain appends, against key.
+func VerifyTombstone(entry AuditEntry, key []byte) error {
+	if entry.EventType != EventTypePurge && entry.EventType != EventTypeRepair {
+		return fmt.Errorf("entry %d is not a purge tombstone", entry.SequenceNum)
+	}
+	sig, _ := entry.Metadata["signature"].(string)
+	if alg, _ := entry.Metadata["signature_alg"].(string); alg != TombstoneSignatureAlg {
+		return fmt.Errorf("unsupported tombstone signature algorithm %q", alg)
+	}
+	want, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(want, tombstoneMAC(entry, key)) {
+		return fmt.Errorf("tombstone signature mismatch at sequence %d", entry.SequenceNum)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestLog(t *testing.T, session string, messages ...string) string {
+	t.Helper()
+	logger, err := NewAuditLogger(&LoggerConfig{SessionID: session, BufferSize: 1, FlushInterval: time.Second})
+	if err != nil {
+		t.Fatalf("NewAuditLogger: %v", err)
+	}
+	for _, msg := range messages {
+		if err := logger.Log(AuditEntry{
+			EventType: EventTypeSend,
+			Actor:     ActorUser,
+			Target:    "cc_1",
+			Payload:   map[string]interface{}{"message": msg},
+		}); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return filepath.Join(os.Getenv("HOME"), ".local", "share", "ntm", "audit",
+		session+"-"+time.Now().Format("2006-01-02")+".jsonl")
+}
+
+func TestPurgeSessionAnonymizesAndSeals(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	auditDir := filepath.Join(tempDir, ".local", "share", "ntm", "audit")
+
+	logPath := writeTestLog(t, "proj", "secret one", "secret two")
+	otherPath := writeTestLog(t, "proj-other", "keep me")
+
+	key, err := LoadSigningKey(filepath.Join(tempDir, "signing.key"))
+	if err != nil {
+		t.Fatalf("LoadSigningKey: %v", err)
+	}
+	again, err := LoadSigningKey(filepath.Join(tempDir, "signing.key"))
+	if err != nil || !bytes.Equal(key, again) {
+		t.Fatalf("signing key not persisted: %v", err)
+	}
+
+	result, err := PurgeSession(auditDir, "proj", time.Time{}, key)
+	if err != nil {
+		t.Fatalf("PurgeSession: %v", err)
+	}
+	if result.Anonymized != 2 || len(result.Files) != 1 || result.Files[0] != logPath {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	data, _ := os.ReadFile(logPath)
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("purged log still contains payload")
+	}
+	if err := VerifyIntegrity(logPath); err != nil {
+		t.Fatalf("VerifyIntegrity after purge: %v", err)
+	}
+	if other, _ := os.ReadFile(otherPath); !bytes.Contains(other, []byte("keep me")) {
+		t.Fatal("purge touched another session's log")
+	}
+
+	entries, err := NewSearcherWithPath(auditDir).Search(Query{Sessions: []string{"proj"}, EventTypes: []EventType{EventTypePurge}})
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(entries.Entries) != 1 {
+		t.Fatalf("expected one tombstone, got %d", len(entries.Entries))
+	}
+	tombstone := entries.Entries[0]
+	if tombstone.SequenceNum != 3 || tombstone.Payload["anonymized"] != float64(2) {
+		t.Fatalf("unexpected tombstone: %+v", tombstone)
+	}
+	if err := VerifyTombstone(tombstone, key); err != nil {
+		t.Fatalf("VerifyTombstone: %v", err)
+	}
+	if err := VerifyTombstone(tombstone, []byte("wrong key")); err == nil {
+		t.Fatal("expected signature mismatch with wrong key")
+	}
+
+	// A second purge has nothing left to anonymize.
+	result, err = PurgeSession(auditDir, "proj", time.Time{}, key)
+	if err != nil || result.Anonymized != 0 {
+		t.Fatalf("second purge: result=%+v err=%v", result, err)
+	}
+}
+
+func TestPurgeSessionBeforeAndBrokenChain(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	auditDir := filepath.Join(tempDir, ".local", "share", "ntm", "audit")
+	key := []byte("test-key")
+
+	logPath := writeTestLog(t, "proj", "old message")
+	cutoff := time.Now().UTC()
+	time.Sleep(10 * time.Millisecond)
+	writeTestLog(t, "proj", "new message")
+
+	result, err := PurgeSession(auditDir, "proj", cutoff, key)
+	if err != nil {
+		t.Fatalf("PurgeSession: %v", err)
+	}
+	if result.Anonymized != 1 {
+		t.Fatalf("anonymized = %d, want 1", result.Anonymized)
+	}
+	data, _ := os.ReadFile(logPath)
+	if bytes.Contains(data, []byte("old message")) || !bytes.Contains(data, []byte("new message")) {
+		t.Fatalf("unexpected log content: %s", data)
+	}
+	if err := VerifyIntegrity(logPath); err != nil {
+		t.Fatalf("VerifyIntegrity: %v", err)
+	}
+
+	// Tampered logs are not resealed.
+	tampered := bytes.Replace(data, []byte("new message"), []byte("NEW MESSAGE"), 1)
+	if err := os.WriteFile(logPath, tampered, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PurgeSession(auditDir, "proj", time.Time{}, key); err == nil {
+		t.Fatal("expected purge to refuse a broken chain")
+	}
+}
@@ -8,9 +8,12 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/pagination"
 )
 
 // Query represents filter criteria for searching audit logs
@@ -35,6 +38,10 @@ type Query struct {
 	// Pagination
 	Limit  int `json:"limit,omitempty"`
 	Offset int `json:"offset,omitempty"`
+	// Cursor resumes a search after the NextPageCursor of a previous page.
+	// Entries logged since then do not shift later pages, as they do with
+	// Offset, so the two cannot be combined.
+	Cursor string `json:"cursor,omitempty"`
 
 	// Timeout for long-running queries
 	Timeout time.Duration `json:"timeout,omitempty"`
@@ -47,6 +54,9 @@ type QueryResult struct {
 	Scanned    int           `json:"scanned"`
 	Duration   time.Duration `json:"duration"`
 	Truncated  bool          `json:"truncated"`
+	// NextPageCursor is set when the limit cut the results short; pass it
+	// back as Query.Cursor for the next page.
+	NextPageCursor string `json:"next_page_cursor,omitempty"`
 }
 
 // StreamResult wraps an entry or error for streaming
@@ -88,15 +98,8 @@ type Searcher struct {
 
 // NewSearcher creates a new audit log searcher
 func NewSearcher() (*Searcher, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	auditDir := filepath.Join(homeDir, ".local", "share", "ntm", "audit")
-
 	return &Searcher{
-		auditDir: auditDir,
+		auditDir: Dir(),
 		index:    newIndex(),
 	}, nil
 }
@@ -160,6 +163,11 @@ func (s *Searcher) SearchContext(ctx context.Context, query Query) (*QueryResult
 		}
 	}
 
+	after, err := decodeSearchCursor(query)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build filter function
 	filter := s.buildFilter(query, targetRegex)
 
@@ -171,6 +179,7 @@ func (s *Searcher) SearchContext(ctx context.Context, query Query) (*QueryResult
 	}
 
 	// Search each file
+	var last searchPosition
 	for _, filePath := range files {
 		select {
 		case <-ctx.Done():
@@ -180,7 +189,14 @@ func (s *Searcher) SearchContext(ctx context.Context, query Query) (*QueryResult
 		default:
 		}
 
+		file := filepath.Base(filePath)
+		if after != nil && file < after.file {
+			continue
+		}
 		err := s.searchFile(ctx, filePath, filter, grepRegex, func(entry AuditEntry) bool {
+			if after != nil && file == after.file && entry.SequenceNum <= after.seq {
+				return true // Returned on an earlier page
+			}
 			result.Scanned++
 
 			// Handle offset (skip entries)
@@ -192,10 +208,12 @@ func (s *Searcher) SearchContext(ctx context.Context, query Query) (*QueryResult
 			// Check limit
 			if collected >= limit {
 				result.Truncated = true
+				result.NextPageCursor = last.encode()
 				return false // Stop scanning
 			}
 
 			result.Entries = append(result.Entries, entry)
+			last = searchPosition{file: file, seq: entry.SequenceNum}
 			collected++
 			return true
 		})
@@ -215,6 +233,37 @@ func (s *Searcher) SearchContext(ctx context.Context, query Query) (*QueryResult
 	return result, nil
 }
 
+// searchPosition is where a search page ended: log files are read in name
+// order and entries within a file in sequence order.
+type searchPosition struct {
+	file string
+	seq  uint64
+}
+
+func (p searchPosition) encode() string {
+	return pagination.Encode(p.file, strconv.FormatUint(p.seq, 10))
+}
+
+// decodeSearchCursor returns the position query.Cursor resumes after, or nil
+// without a cursor.
+func decodeSearchCursor(query Query) (*searchPosition, error) {
+	key, err := pagination.Decode(query.Cursor)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	if query.Offset > 0 {
+		return nil, fmt.Errorf("cursor and offset are mutually exclusive")
+	}
+	if len(key) != 2 {
+		return nil, pagination.ErrInvalidCursor
+	}
+	seq, err := strconv.ParseUint(key[1], 10, 64)
+	if err != nil {
+		return nil, pagination.ErrInvalidCursor
+	}
+	return &searchPosition{file: key[0], seq: seq}, nil
+}
+
 // StreamSearch returns a channel of matching entries for memory efficiency
 func (s *Searcher) StreamSearch(ctx context.Context, query Query) (<-chan StreamResult, error) {
 	results := make(chan StreamResult, 100)
@@ -504,18 +553,22 @@ func (s *Searcher) searchFile(ctx context.Context, filePath string, filter func(
 		default:
 		}
 
-		line := scanner.Text()
-		if line == "" {
+		raw := scanner.Bytes()
+		if len(raw) == 0 {
+			continue
+		}
+		line, err := decryptJSONLine(raw)
+		if err != nil {
 			continue
 		}
 
 		// Full-text grep filter (before parsing JSON for efficiency)
-		if grepRegex != nil && !grepRegex.MatchString(line) {
+		if grepRegex != nil && !grepRegex.Match(line) {
 			continue
 		}
 
 		var entry AuditEntry
-		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		if err := json.Unmarshal(line, &entry); err != nil {
 			// Skip malformed entries
 			continue
 		}
@@ -564,13 +617,13 @@ func (s *Searcher) BuildIndex(ctx context.Context) error {
 		scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
 
 		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
+			line := scanner.Bytes()
+			if len(line) == 0 {
 				continue
 			}
 
 			var entry AuditEntry
-			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			if err := unmarshalEntry(line, &entry); err != nil {
 				continue
 			}
 
@@ -647,6 +647,47 @@ func TestSearcher_Search_Pagination(t *testing.T) {
 			t.Errorf("Expected 3 entries, got %d", len(result.Entries))
 		}
 	})
+
+	// Test: Cursor pages through every entry once, even as entries are added
+	t.Run("Cursor", func(t *testing.T) {
+		var seen []uint64
+		cursor := ""
+		for page := 0; ; page++ {
+			result, err := searcher.Search(Query{Limit: 4, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			for _, e := range result.Entries {
+				seen = append(seen, e.SequenceNum)
+			}
+			if page == 0 {
+				// A later session's log sorts after this one.
+				writeTestEntries(t, auditDir, "zz-session", now.Format("2006-01-02"), []AuditEntry{
+					{Timestamp: now, SessionID: "zz-session", EventType: EventTypeCommand, SequenceNum: 1},
+				})
+			}
+			if result.NextPageCursor == "" {
+				break
+			}
+			cursor = result.NextPageCursor
+		}
+		want := []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 1}
+		if len(seen) != len(want) {
+			t.Fatalf("paged sequence numbers = %v, want %v", seen, want)
+		}
+		for i := range want {
+			if seen[i] != want[i] {
+				t.Fatalf("paged sequence numbers = %v, want %v", seen, want)
+			}
+		}
+
+		if _, err := searcher.Search(Query{Cursor: "not-a-cursor"}); err == nil {
+			t.Error("expected an error for an invalid cursor")
+		}
+		if _, err := searcher.Search(Query{Cursor: cursor, Offset: 1}); err == nil {
+			t.Error("expected an error combining cursor and offset")
+		}
+	})
 }
 
 func TestSearcher_Count(t *testing.T) {
@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// ChainRepair describes the result of RepairChain.
+type ChainRepair struct {
+	Path     string `json:"path"`
+	Entries  int    `json:"entries"`
+	BrokenAt uint64 `json:"broken_at,omitempty"` // sequence number of the first broken link
+	Missing  int    `json:"missing"`             // entries missing from the chain
+	Resealed int    `json:"resealed"`
+	OldHead  string `json:"old_head"` // checksum of the last entry before the repair
+}
+
+// RepairChain restores hash-chain continuity in an audit log after
+// jsonlutil.Repair removed its unreadable records; removed is the number of
+// records it quarantined.
+//
+// It only reseals across gaps those records left: a well-formed entry whose
+// checksum does not match its content, a link broken with no entry missing,
+// or more entries missing than were removed means the log was altered, and
+// RepairChain refuses to touch it.
+//
+// Entries up to the first gap are left untouched. From there on, entries are
+// renumbered and re-linked; each resealed entry records its original sequence
+// number and checksum in Metadata ("repaired_from_sequence",
+// "repaired_from_checksum"). A repair entry signed with key, like a purge
+// tombstone, is appended recording the old chain head and what was resealed.
+// It returns nil if the chain was already intact.
+func RepairChain(logPath string, removed int, key []byte) (*ChainRepair, error) {
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []AuditEntry
+	var raw [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry AuditEntry
+		if err := unmarshalEntry(line, &entry); err != nil {
+			return nil, fmt.Errorf("invalid JSON in audit log (quarantine bad lines first): %w", err)
+		}
+		entries = append(entries, entry)
+		raw = append(raw, line)
+	}
+
+	result := &ChainRepair{Path: logPath, Entries: len(entries)}
+	var prev *AuditEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Checksum != entryChecksum(*entry) {
+			return nil, fmt.Errorf("checksum mismatch at sequence %d: entry was modified, refusing to reseal", entry.SequenceNum)
+		}
+		var prevSeq uint64
+		var prevHash string
+		if prev != nil {
+			prevSeq, prevHash = prev.SequenceNum, prev.Checksum
+		}
+		switch {
+		case entry.SequenceNum == prevSeq+1 && entry.PrevHash == prevHash:
+			// Intact link.
+		case entry.SequenceNum > prevSeq+1:
+			if result.BrokenAt == 0 {
+				result.BrokenAt = uint64(i + 1)
+			}
+			result.Missing += int(entry.SequenceNum - prevSeq - 1)
+		default:
+			return nil, fmt.Errorf("broken link at sequence %d with no entry missing: log was altered, refusing to reseal", entry.SequenceNum)
+		}
+		prev = entry
+	}
+	if result.BrokenAt == 0 {
+		return nil, nil
+	}
+	if result.Missing > removed {
+		return nil, fmt.Errorf("%d entries missing but only %d records were quarantined: refusing to reseal", result.Missing, removed)
+	}
+	if len(key) == 0 {
+		return nil, fmt.Errorf("audit signing key required")
+	}
+	result.OldHead = entries[len(entries)-1].Checksum
+
+	var out bytes.Buffer
+	var prevHash string
+	for i := range entries {
+		entry := entries[i]
+		seq := uint64(i + 1)
+		if seq < result.BrokenAt {
+			out.Write(raw[i])
+			out.WriteByte('\n')
+			prevHash = entry.Checksum
+			continue
+		}
+
+		if entry.Metadata == nil {
+			entry.Metadata = map[string]interface{}{}
+		}
+		entry.Metadata["repaired_from_sequence"] = entry.SequenceNum
+		entry.Metadata["repaired_from_checksum"] = entry.Checksum
+		entry.SequenceNum = seq
+		entry.PrevHash = prevHash
+		entry.Checksum = entryChecksum(entry)
+		result.Resealed++
+		if err := writeEntryLine(&out, entry); err != nil {
+			return nil, err
+		}
+		prevHash = entry.Checksum
+	}
+
+	repair := AuditEntry{
+		Timestamp: time.Now().UTC(),
+		SessionID: entries[0].SessionID,
+		EventType: EventTypeRepair,
+		Actor:     ActorSystem,
+		Target:    filepath.Base(logPath),
+		Payload: map[string]interface{}{
+			"old_head":  result.OldHead,
+			"broken_at": result.BrokenAt,
+			"missing":   result.Missing,
+			"removed":   removed,
+			"resealed":  result.Resealed,
+		},
+		PrevHash:    prevHash,
+		SequenceNum: uint64(len(entries) + 1),
+	}
+	repair.Metadata = map[string]interface{}{
+		"signature_alg": TombstoneSignatureAlg,
+		"signature":     hex.EncodeToString(tombstoneMAC(repair, key)),
+	}
+	repair.Checksum = entryChecksum(repair)
+	if err := writeEntryLine(&out, repair); err != nil {
+		return nil, err
+	}
+
+	if err := util.AtomicWriteFile(logPath, out.Bytes(), info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("failed to write repaired audit log: %w", err)
+	}
+	return result, nil
+}
+
+// entryChecksum computes an entry's checksum the same way Log does.
+func entryChecksum(entry AuditEntry) string {
+	entry.Checksum = ""
+	hashData, err := json.Marshal(entry)
+	if err != nil {
+		return ""
+	}
+	hash := sha256.Sum256(hashData)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func writeTestChain(t *testing.T, session string, n int) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	logger, err := NewAuditLogger(DefaultConfig(session))
+	if err != nil {
+		t.Fatalf("NewAuditLogger: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		if err := logger.Log(AuditEntry{
+			EventType: EventTypeCommand,
+			Actor:     ActorUser,
+			Target:    "pane",
+			Payload:   map[string]interface{}{"i": i},
+		}); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+	path := logger.file.Name()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return path
+}
+
+func TestRepairChainIntact(t *testing.T) {
+	path := writeTestChain(t, "repair-intact", 3)
+	before, _ := os.ReadFile(path)
+
+	result, err := RepairChain(path, 0, []byte("key"))
+	if err != nil {
+		t.Fatalf("RepairChain: %v", err)
+	}
+	if result != nil {
+		t.Fatalf("expected no repair for intact chain, got %+v", result)
+	}
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(before, after) {
+		t.Fatal("intact log was rewritten")
+	}
+}
+
+func TestRepairChainAfterRemovedEntry(t *testing.T) {
+	path := writeTestChain(t, "repair-gap", 5)
+
+	// Drop entry 3, as fsck does when quarantining a corrupt record.
+	data, _ := os.ReadFile(path)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	lines = append(lines[:2], lines[3:]...)
+	if err := os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyIntegrity(path); err == nil {
+		t.Fatal("expected broken chain before repair")
+	}
+
+	var head AuditEntry
+	if err := json.Unmarshal(lines[len(lines)-1], &head); err != nil {
+		t.Fatal(err)
+	}
+	key := []byte("signing key")
+	result, err := RepairChain(path, 1, key)
+	if err != nil {
+		t.Fatalf("RepairChain: %v", err)
+	}
+	if result == nil || result.BrokenAt != 3 || result.Missing != 1 || result.Resealed != 2 || result.Entries != 4 || result.OldHead != head.Checksum {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if err := VerifyIntegrity(path); err != nil {
+		t.Fatalf("chain still broken after repair: %v", err)
+	}
+
+	// Untouched prefix is byte-identical; resealed entries keep their origin.
+	data, _ = os.ReadFile(path)
+	repaired := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	if !bytes.Equal(repaired[1], lines[1]) {
+		t.Error("entry before the break was modified")
+	}
+	var entry AuditEntry
+	if err := json.Unmarshal(repaired[2], &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.SequenceNum != 3 || entry.Metadata["repaired_from_sequence"] != float64(4) {
+		t.Errorf("resealed entry = seq %d, metadata %v", entry.SequenceNum, entry.Metadata)
+	}
+
+	// A signed repair entry records the old chain head.
+	var repair AuditEntry
+	if err := json.Unmarshal(repaired[len(repaired)-1], &repair); err != nil {
+		t.Fatal(err)
+	}
+	if repair.EventType != EventTypeRepair || repair.SequenceNum != 5 || repair.Payload["old_head"] != head.Checksum {
+		t.Errorf("repair entry = %+v", repair)
+	}
+	if err := VerifyTombstone(repair, key); err != nil {
+		t.Errorf("VerifyTombstone: %v", err)
+	}
+
+	// Appending after the repair continues the chain.
+	logger, err := NewAuditLogger(DefaultConfig("repair-gap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(AuditEntry{EventType: EventTypeCommand, Actor: ActorUser}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyIntegrity(path); err != nil {
+		t.Fatalf("chain broken after append: %v", err)
+	}
+}
+
+func TestRepairChainRefusesTampering(t *testing.T) {
+	path := writeTestChain(t, "repair-tamper", 4)
+	data, _ := os.ReadFile(path)
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	write := func(lines [][]byte) {
+		t.Helper()
+		if err := os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An entry edited in place keeps its old checksum.
+	var entry AuditEntry
+	if err := json.Unmarshal(lines[1], &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Target = "other"
+	edited, _ := json.Marshal(entry)
+	write([][]byte{lines[0], edited, lines[2], lines[3]})
+	if _, err := RepairChain(path, 0, []byte("key")); err == nil {
+		t.Error("expected refusal for an edited entry")
+	}
+
+	// An entry removed without being quarantined.
+	write([][]byte{lines[0], lines[2], lines[3]})
+	if _, err := RepairChain(path, 0, []byte("key")); err == nil {
+		t.Error("expected refusal for an entry removed outside fsck")
+	}
+
+	// Entries reordered.
+	write([][]byte{lines[0], lines[2], lines[1], lines[3]})
+	if _, err := RepairChain(path, 1, []byte("key")); err == nil {
+		t.Error("expected refusal for reordered entries")
+	}
+	after, _ := os.ReadFile(path)
+	if !bytes.Equal(after, append(bytes.Join([][]byte{lines[0], lines[2], lines[1], lines[3]}, []byte("\n")), '\n')) {
+		t.Error("refused repair rewrote the log")
+	}
+}
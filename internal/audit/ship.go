@@ -0,0 +1,295 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// ManifestVersion is the version of SegmentManifest written by Ship.
+const ManifestVersion = 1
+
+// SegmentManifest describes a shipped audit segment: one session's log for
+// one finished day. It is stored beside the segment and signed with the
+// audit signing key, so a copy altered on either side can be detected.
+type SegmentManifest struct {
+	Version       int       `json:"version"`
+	Host          string    `json:"host"`
+	Session       string    `json:"session"`
+	Date          string    `json:"date"`
+	File          string    `json:"file"`
+	Object        string    `json:"object"`
+	Bytes         int64     `json:"bytes"`
+	SHA256        string    `json:"sha256"`
+	Entries       int       `json:"entries"`
+	FirstSequence uint64    `json:"first_sequence,omitempty"`
+	LastSequence  uint64    `json:"last_sequence,omitempty"`
+	ChainHead     string    `json:"chain_head,omitempty"`
+	ChainVerified bool      `json:"chain_verified"`
+	ChainError    string    `json:"chain_error,omitempty"`
+	ShippedAt     time.Time `json:"shipped_at"`
+	SignatureAlg  string    `json:"signature_alg"`
+	Signature     string    `json:"signature"`
+}
+
+// ShippedSegment is a segment uploaded (or, in a dry run, due) by Ship.
+type ShippedSegment struct {
+	File     string `json:"file"`
+	Object   string `json:"object"`
+	Manifest string `json:"manifest"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// ShipResult describes the result of Ship.
+type ShipResult struct {
+	Sink    string           `json:"sink"`
+	DryRun  bool             `json:"dry_run,omitempty"`
+	Shipped []ShippedSegment `json:"shipped"`
+	Pending int              `json:"pending"` // Segments still due after an error
+}
+
+// ShipOptions configures Ship.
+type ShipOptions struct {
+	AuditDir  string // Directory of the audit logs
+	StatePath string // Record of shipped segments (default: <audit dir>.shipped.json)
+	Prefix    string // Key prefix in the sink
+	Host      string // Host name keys are filed under (default: os.Hostname)
+	Key       []byte // Signing key for manifests
+	Now       time.Time
+	DryRun    bool
+}
+
+// shipState maps each shipped log file to the SHA-256 it was shipped with.
+type shipState struct {
+	Shipped map[string]string `json:"shipped"`
+}
+
+// DefaultShipStatePath returns where Ship records what it has shipped from
+// auditDir. It sits beside the directory, not in it, so pruning the logs
+// does not reset it.
+func DefaultShipStatePath(auditDir string) string {
+	return filepath.Clean(auditDir) + ".shipped.json"
+}
+
+// Ship uploads the finished segments of auditDir to sink: every
+// <session>-<date>.jsonl log for a day before today that was not shipped
+// yet, or has changed since (after a purge, say). Each goes to
+// <prefix>/<host>/<session>/<date>-<sha256 prefix>.jsonl, so a changed
+// segment never overwrites the copy shipped before it, followed by its
+// signed manifest. Today's log is still being written and waits.
+func Ship(ctx context.Context, sink Sink, opts ShipOptions) (*ShipResult, error) {
+	if len(opts.Key) == 0 {
+		return nil, fmt.Errorf("audit signing key required")
+	}
+	if opts.StatePath == "" {
+		opts.StatePath = DefaultShipStatePath(opts.AuditDir)
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+		if opts.Host == "" {
+			opts.Host = "unknown"
+		}
+	}
+
+	result := &ShipResult{Sink: sink.Name(), DryRun: opts.DryRun, Shipped: []ShippedSegment{}}
+	segments, err := finishedSegments(opts.AuditDir, opts.Now)
+	if err != nil {
+		return result, err
+	}
+	state, err := loadShipState(opts.StatePath)
+	if err != nil {
+		return result, err
+	}
+
+	for i, name := range segments {
+		if err := ctx.Err(); err != nil {
+			result.Pending = len(segments) - i
+			return result, err
+		}
+		data, err := os.ReadFile(filepath.Join(opts.AuditDir, name))
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		sum := sha256Hex(data)
+		if state.Shipped[name] == sum {
+			continue
+		}
+
+		m := buildManifest(opts, name, data, sum)
+		manifestData, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return result, err
+		}
+		seg := ShippedSegment{File: name, Object: m.Object, Manifest: m.Object + ".manifest.json", Bytes: m.Bytes}
+		if !opts.DryRun {
+			if err := sink.Put(ctx, seg.Object, data); err != nil {
+				result.Pending = len(segments) - i
+				return result, err
+			}
+			if err := sink.Put(ctx, seg.Manifest, append(manifestData, '\n')); err != nil {
+				result.Pending = len(segments) - i
+				return result, err
+			}
+			state.Shipped[name] = sum
+			if err := saveShipState(opts.StatePath, state); err != nil {
+				return result, err
+			}
+		}
+		result.Shipped = append(result.Shipped, seg)
+	}
+	return result, nil
+}
+
+// finishedSegments lists the session logs in dir dated before now's day.
+func finishedSegments(dir string, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit directory: %w", err)
+	}
+	today := now.Format("2006-01-02")
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		_, date, ok := splitSegmentName(e.Name())
+		if ok && date < today {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// splitSegmentName splits a log file name <session>-<date>.jsonl.
+func splitSegmentName(name string) (session, date string, ok bool) {
+	base, ok := strings.CutSuffix(name, ".jsonl")
+	if !ok || len(base) < len("x-2006-01-02") {
+		return "", "", false
+	}
+	session, date = base[:len(base)-len("2006-01-02")-1], base[len(base)-len("2006-01-02"):]
+	if base[len(session)] != '-' {
+		return "", "", false
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return "", "", false
+	}
+	return session, date, true
+}
+
+func buildManifest(opts ShipOptions, name string, data []byte, sum string) *SegmentManifest {
+	session, date, _ := splitSegmentName(name)
+	m := &SegmentManifest{
+		Version:      ManifestVersion,
+		Host:         opts.Host,
+		Session:      session,
+		Date:         date,
+		File:         name,
+		Object:       path.Join(opts.Prefix, opts.Host, session, date+"-"+sum[:12]+".jsonl"),
+		Bytes:        int64(len(data)),
+		SHA256:       sum,
+		ShippedAt:    opts.Now.UTC().Truncate(time.Second),
+		SignatureAlg: TombstoneSignatureAlg,
+	}
+
+	// Entries are summarized when they can be read; an encrypted log
+	// without its keys is still shipped, by line count and digest alone.
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		m.Entries++
+		var entry AuditEntry
+		if unmarshalEntry(line, &entry) != nil {
+			continue
+		}
+		if m.FirstSequence == 0 {
+			m.FirstSequence = entry.SequenceNum
+		}
+		m.LastSequence = entry.SequenceNum
+		m.ChainHead = entry.Checksum
+	}
+	if err := VerifyIntegrity(filepath.Join(opts.AuditDir, name)); err != nil {
+		m.ChainError = err.Error()
+	} else {
+		m.ChainVerified = true
+	}
+
+	m.Signature = hex.EncodeToString(manifestMAC(*m, opts.Key))
+	return m
+}
+
+// manifestMAC signs everything in a manifest but its signature.
+func manifestMAC(m SegmentManifest, key []byte) []byte {
+	m.Signature = ""
+	data, _ := json.Marshal(m)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// VerifyManifest checks a manifest's signature against key and, if data is
+// not nil, that data is the segment it describes.
+func VerifyManifest(m SegmentManifest, data []byte, key []byte) error {
+	if m.SignatureAlg != TombstoneSignatureAlg {
+		return fmt.Errorf("unsupported manifest signature algorithm %q", m.SignatureAlg)
+	}
+	want, err := hex.DecodeString(m.Signature)
+	if err != nil || !hmac.Equal(want, manifestMAC(m, key)) {
+		return fmt.Errorf("manifest signature mismatch for %s", m.File)
+	}
+	if data == nil {
+		return nil
+	}
+	if int64(len(data)) != m.Bytes || sha256Hex(data) != m.SHA256 {
+		return fmt.Errorf("segment %s does not match its manifest", m.File)
+	}
+	return nil
+}
+
+func loadShipState(path string) (*shipState, error) {
+	state := &shipState{Shipped: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, fmt.Errorf("failed to read audit ship state: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("invalid audit ship state %s: %w", path, err)
+	}
+	if state.Shipped == nil {
+		state.Shipped = map[string]string{}
+	}
+	return state, nil
+}
+
+func saveShipState(path string, state *shipState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit ship state dir: %w", err)
+	}
+	return util.AtomicWriteFile(path, append(data, '\n'), 0600)
+}
@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShipFinishedSegments(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+	auditDir := filepath.Join(tempDir, ".local", "share", "ntm", "audit")
+	logPath := writeTestLog(t, "my-proj", "one", "two")
+
+	sinkDir := filepath.Join(tempDir, "offhost")
+	sink := &DirSink{Dir: sinkDir}
+	key := []byte("signing-key")
+	opts := ShipOptions{AuditDir: auditDir, Prefix: "ntm-audit", Host: "box", Key: key, Now: time.Now()}
+
+	// Today's log is still open.
+	res, err := Ship(context.Background(), sink, opts)
+	if err != nil || len(res.Shipped) != 0 {
+		t.Fatalf("Ship today = %+v, %v", res, err)
+	}
+
+	opts.Now = opts.Now.Add(24 * time.Hour)
+	res, err = Ship(context.Background(), sink, opts)
+	if err != nil || len(res.Shipped) != 1 {
+		t.Fatalf("Ship = %+v, %v", res, err)
+	}
+	seg := res.Shipped[0]
+	if !strings.HasPrefix(seg.Object, "ntm-audit/box/my-proj/") {
+		t.Errorf("object = %s", seg.Object)
+	}
+
+	data, err := os.ReadFile(filepath.Join(sinkDir, filepath.FromSlash(seg.Object)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(sinkDir, filepath.FromSlash(seg.Manifest)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m SegmentManifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Session != "my-proj" || m.Entries != 2 || m.LastSequence != 2 || !m.ChainVerified {
+		t.Errorf("manifest = %+v", m)
+	}
+	if err := VerifyManifest(m, data, key); err != nil {
+		t.Errorf("VerifyManifest: %v", err)
+	}
+	if err := VerifyManifest(m, append(data, '\n'), key); err == nil {
+		t.Error("altered segment should not verify")
+	}
+	m.Entries = 1
+	if err := VerifyManifest(m, nil, key); err == nil {
+		t.Error("altered manifest should not verify")
+	}
+
+	// Nothing new: nothing shipped.
+	if res, err := Ship(context.Background(), sink, opts); err != nil || len(res.Shipped) != 0 {
+		t.Fatalf("Ship again = %+v, %v", res, err)
+	}
+
+	// A changed segment is shipped again beside the first copy.
+	if _, err := PurgeSession(auditDir, "my-proj", time.Time{}, key); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Ship(context.Background(), sink, opts)
+	if err != nil || len(res.Shipped) != 1 || res.Shipped[0].Object == seg.Object {
+		t.Fatalf("Ship after purge = %+v, %v", res, err)
+	}
+	if _, err := os.Stat(filepath.Join(sinkDir, filepath.FromSlash(seg.Object))); err != nil {
+		t.Errorf("first copy should remain: %v", err)
+	}
+	if _, err := os.Stat(logPath); err != nil {
+		t.Errorf("local log should remain: %v", err)
+	}
+}
+
+func TestSplitSegmentName(t *testing.T) {
+	tests := []struct {
+		name, session, date string
+		ok                  bool
+	}{
+		{"proj-2026-01-02.jsonl", "proj", "2026-01-02", true},
+		{"my-proj-2026-01-02.jsonl", "my-proj", "2026-01-02", true},
+		{"proj-2026-13-02.jsonl", "", "", false},
+		{"proj.jsonl", "", "", false},
+		{"proj-2026-01-02.json", "", "", false},
+	}
+	for _, tt := range tests {
+		session, date, ok := splitSegmentName(tt.name)
+		if session != tt.session || date != tt.date || ok != tt.ok {
+			t.Errorf("splitSegmentName(%q) = %q, %q, %v", tt.name, session, date, ok)
+		}
+	}
+}
+
+func TestObjectSinkPut(t *testing.T) {
+	var gotPath, gotAuth, gotHash string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotHash = r.Header.Get("X-Amz-Content-Sha256")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(SinkOptions{Type: "s3", Bucket: "trail", Endpoint: srv.URL, AccessKey: "AKID", SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.(*ObjectSink).now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	if err := sink.Put(context.Background(), "a/b.jsonl", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/trail/a/b.jsonl" || string(gotBody) != "data" || gotHash != sha256Hex([]byte("data")) {
+		t.Errorf("path=%s body=%q hash=%s", gotPath, gotBody, gotHash)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/20260102/us-east-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=") {
+		t.Errorf("auth = %s", gotAuth)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	})
+	if err := sink.Put(context.Background(), "a/c.jsonl", []byte("x")); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Put error = %v", err)
+	}
+}
+
+func TestNewSinkGCSDefaults(t *testing.T) {
+	sink, err := NewSink(SinkOptions{Type: "gcs", Bucket: "trail", AccessKey: "GOOG1", SecretKey: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sink.(*ObjectSink)
+	if s.Endpoint != "https://storage.googleapis.com" || s.Region != "auto" || s.Name() != "gcs://trail" {
+		t.Errorf("sink = %+v", s)
+	}
+	if _, err := NewSink(SinkOptions{Type: "s3", Bucket: "trail"}); err == nil {
+		t.Error("s3 without credentials should fail")
+	}
+}
@@ -0,0 +1,228 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Sink stores audit segments off-host. Keys are slash-separated paths;
+// a key that already exists is never overwritten with different content.
+type Sink interface {
+	// Name describes the sink for logs and reports, e.g. "s3://bucket".
+	Name() string
+	// Put stores data under key.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// SinkOptions selects and configures a Sink.
+type SinkOptions struct {
+	Type         string // s3, gcs or dir
+	Bucket       string
+	Region       string // S3 region (default us-east-1)
+	Endpoint     string // Service endpoint override, e.g. "http://localhost:9000"
+	Dir          string // Target directory for dir
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // Optional temporary-credential token (s3)
+}
+
+// NewSink returns the sink described by opts.
+//
+// GCS is reached through its S3-compatible XML API, which takes the same
+// request signing with an HMAC key pair (see "HMAC keys" in the GCS docs).
+func NewSink(opts SinkOptions) (Sink, error) {
+	switch opts.Type {
+	case "dir":
+		if opts.Dir == "" {
+			return nil, fmt.Errorf("dir sink needs a directory")
+		}
+		return &DirSink{Dir: util.ExpandPath(opts.Dir)}, nil
+	case "s3", "gcs":
+		if opts.Bucket == "" {
+			return nil, fmt.Errorf("%s sink needs a bucket", opts.Type)
+		}
+		if opts.AccessKey == "" || opts.SecretKey == "" {
+			return nil, fmt.Errorf("%s sink needs an access key and secret key", opts.Type)
+		}
+		s := &ObjectSink{
+			Scheme:       opts.Type,
+			Endpoint:     opts.Endpoint,
+			Region:       opts.Region,
+			Bucket:       opts.Bucket,
+			AccessKey:    opts.AccessKey,
+			SecretKey:    opts.SecretKey,
+			SessionToken: opts.SessionToken,
+		}
+		if opts.Type == "gcs" {
+			if s.Endpoint == "" {
+				s.Endpoint = "https://storage.googleapis.com"
+			}
+			if s.Region == "" {
+				s.Region = "auto"
+			}
+		}
+		if s.Region == "" {
+			s.Region = "us-east-1"
+		}
+		if s.Endpoint == "" {
+			s.Endpoint = "https://s3." + s.Region + ".amazonaws.com"
+		}
+		return s, nil
+	case "":
+		return nil, fmt.Errorf("no audit sink configured")
+	default:
+		return nil, fmt.Errorf("unknown audit sink type %q", opts.Type)
+	}
+}
+
+// DirSink stores segments in a directory, typically a mount of another
+// machine's disk.
+type DirSink struct {
+	Dir string
+}
+
+// Name implements Sink.
+func (s *DirSink) Name() string { return s.Dir }
+
+// Put implements Sink.
+func (s *DirSink) Put(ctx context.Context, key string, data []byte) error {
+	target := filepath.Join(s.Dir, filepath.FromSlash(path.Clean("/"+key)))
+	if existing, err := os.ReadFile(target); err == nil {
+		if bytes.Equal(existing, data) {
+			return nil
+		}
+		return fmt.Errorf("%s already exists with different content", target)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return fmt.Errorf("failed to create sink directory: %w", err)
+	}
+	return util.AtomicWriteFile(target, data, 0400)
+}
+
+// ObjectSink stores segments in an S3-compatible bucket (S3, GCS, MinIO),
+// signing requests with AWS Signature Version 4. Objects are addressed
+// path-style: <endpoint>/<bucket>/<key>.
+type ObjectSink struct {
+	Scheme       string // s3 or gcs, for Name
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Client       *http.Client
+	now          func() time.Time
+}
+
+// Name implements Sink.
+func (s *ObjectSink) Name() string { return s.Scheme + "://" + s.Bucket }
+
+// Put implements Sink.
+func (s *ObjectSink) Put(ctx context.Context, key string, data []byte) error {
+	u, err := url.Parse(strings.TrimRight(s.Endpoint, "/"))
+	if err != nil {
+		return fmt.Errorf("invalid sink endpoint: %w", err)
+	}
+	u.Path = "/" + s.Bucket + "/" + strings.TrimLeft(key, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, data, now().UTC())
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload %s: %s: %s", key, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds SigV4 headers to req for a payload of body.
+func (s *ObjectSink) sign(req *http.Request, body []byte, t time.Time) {
+	const alg = "AWS4-HMAC-SHA256"
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	names := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	if s.SessionToken != "" {
+		names = append(names, "x-amz-security-token")
+	}
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{alg, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		alg, s.AccessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,300 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/githost"
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+)
+
+func writePlan(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPlan_Defaults(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlan(t, dir, "Nightly Cleanup.yaml", `
+agents: {cc: 2, cod: 1}
+tasks:
+  - id: lint
+    prompt: fix lint
+    timeout: 10m
+`)
+	p, err := LoadPlan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Nightly Cleanup" || p.Session != "batch-nightly-cleanup" {
+		t.Errorf("name=%q session=%q", p.Name, p.Session)
+	}
+	if p.Timeout.Duration != DefaultTimeout || p.ReadyTimeout.Duration != DefaultReadyTimeout {
+		t.Errorf("timeouts = %v, %v", p.Timeout, p.ReadyTimeout)
+	}
+	if p.Artifacts.Dir != DefaultArtifactsDir || p.Artifacts.Lines != DefaultCaptureLines {
+		t.Errorf("artifacts = %+v", p.Artifacts)
+	}
+	if p.Agents.Total() != 3 {
+		t.Errorf("agents = %+v", p.Agents)
+	}
+
+	wf, err := p.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wf.Steps) != 1 || wf.Steps[0].Timeout.Duration != 10*time.Minute || wf.Settings.Timeout.Duration != DefaultTimeout {
+		t.Errorf("workflow = %+v", wf)
+	}
+}
+
+func TestPlanValidate_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writePlan(t, dir, "wf.yaml", `
+schema_version: "2.0"
+name: from-file
+steps:
+  - id: one
+    prompt: hi
+`)
+	tests := []struct {
+		name, plan, want string
+	}{
+		{"no agents", "tasks: [{id: a, prompt: x}]", "at least one agent"},
+		{"no tasks", "agents: {cc: 1}", "no tasks"},
+		{"both", "agents: {cc: 1}\nworkflow: wf.yaml\ntasks: [{id: a, prompt: x}]", "not both"},
+		{"bad task", "agents: {cc: 1}\ntasks: [{prompt: x}]", "invalid tasks"},
+		{"missing workflow", "agents: {cc: 1}\nworkflow: nope.yaml", "nope.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := LoadPlan(writePlan(t, dir, "plan.yaml", tt.plan))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// A workflow file is resolved relative to the plan.
+	p, err := LoadPlan(writePlan(t, dir, "plan.yaml", "agents: {cc: 1}\nworkflow: wf.yaml\non_error: continue"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wf, err := p.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wf.Name != "from-file" || wf.Settings.OnError != pipeline.ErrorActionContinue {
+		t.Errorf("workflow = %+v", wf)
+	}
+}
+
+func TestSanitizeSessionName(t *testing.T) {
+	for in, want := range map[string]string{
+		"Nightly Cleanup": "nightly-cleanup",
+		"a--b":            "a-b",
+		"--":              "run",
+		"ci_run.1":        "ci_run-1",
+	} {
+		if got := sanitizeSessionName(in); got != want {
+			t.Errorf("sanitizeSessionName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIssuePlan(t *testing.T) {
+	p := IssuePlan("Nightly Triage", AgentCounts{Claude: 2}, []githost.Issue{
+		{Number: 3, Title: "Crash on start", Body: "Set ${HOME} and run", URL: "https://github.com/acme/widgets/issues/3"},
+		{Number: 5, Title: "Typo"},
+	})
+	if p.Session != "sched-nightly-triage" || p.Timeout.Duration != DefaultTimeout || len(p.Tasks) != 2 {
+		t.Fatalf("plan = %+v", p)
+	}
+	wf, err := p.Validate()
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+	if wf.Settings.OnError != pipeline.ErrorActionContinue || wf.Steps[0].ID != "issue-3" {
+		t.Errorf("workflow = %+v", wf)
+	}
+
+	prompt, err := pipeline.NewSubstitutor(&pipeline.ExecutionState{}, p.Session, wf.Name).Substitute(wf.Steps[0].Prompt)
+	if err != nil {
+		t.Fatalf("Substitute() error: %v", err)
+	}
+	if !strings.Contains(prompt, "Resolve issue #3: Crash on start") || !strings.Contains(prompt, "Set ${HOME} and run") {
+		t.Errorf("prompt = %q", prompt)
+	}
+}
+
+func testPlan(t *testing.T) *Plan {
+	t.Helper()
+	p := &Plan{
+		Name:   "ci",
+		Agents: AgentCounts{Claude: 1},
+		Tasks: []pipeline.Step{
+			{ID: "a", Prompt: "one"},
+			{ID: "b", Prompt: "two", DependsOn: []string{"a"}},
+			{ID: "c", Prompt: "three"},
+		},
+		Artifacts: ArtifactOptions{Dir: filepath.Join(t.TempDir(), "artifacts")},
+	}
+	p.applyDefaults()
+	return p
+}
+
+func TestRun_ReportsTaskOutcomes(t *testing.T) {
+	p := testPlan(t)
+	var calls []string
+	now := time.Now()
+	report, err := Run(context.Background(), p, Hooks{
+		Spawn: func(context.Context, *Plan) error { calls = append(calls, "spawn"); return nil },
+		RunTasks: func(_ context.Context, _ *Plan, wf *pipeline.Workflow) (*pipeline.ExecutionState, error) {
+			calls = append(calls, "tasks")
+			return &pipeline.ExecutionState{Steps: map[string]pipeline.StepResult{
+				"a": {Status: pipeline.StatusCompleted, PaneUsed: "%1", StartedAt: now, FinishedAt: now.Add(2 * time.Second)},
+				"b": {Status: pipeline.StatusFailed, Error: &pipeline.StepError{Message: "agent crashed", PaneOutput: "panic"}},
+				"c": {Status: pipeline.StatusSkipped, SkipReason: "condition false"},
+			}}, errors.New("workflow failed")
+		},
+		Collect:  func(context.Context, *Plan, string) error { calls = append(calls, "collect"); return nil },
+		Teardown: func(*Plan) error { calls = append(calls, "teardown"); return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ","); got != "spawn,tasks,collect,teardown" {
+		t.Errorf("calls = %s", got)
+	}
+
+	want := []struct{ name, status string }{
+		{"spawn", PhasePass}, {"task:a", PhasePass}, {"task:b", PhaseFail},
+		{"task:c", PhaseSkip}, {"artifacts", PhasePass}, {"teardown", PhasePass},
+	}
+	if len(report.Phases) != len(want) {
+		t.Fatalf("phases = %+v", report.Phases)
+	}
+	for i, w := range want {
+		if report.Phases[i].Name != w.name || report.Phases[i].Status != w.status {
+			t.Errorf("phase %d = %+v, want %s %s", i, report.Phases[i], w.name, w.status)
+		}
+	}
+	if report.Phases[1].DurationMs != 2000 || report.Phases[2].Details != "panic" {
+		t.Errorf("task phases = %+v", report.Phases[1:3])
+	}
+	if report.Summary != (Summary{Total: 6, Passed: 4, Failed: 1, Skipped: 1}) || !report.Failed() {
+		t.Errorf("summary = %+v", report.Summary)
+	}
+	if got := report.FailedPhases(); len(got) != 1 || got[0] != "task:b" {
+		t.Errorf("failed phases = %v", got)
+	}
+
+	// The report on disk has the integration report's fields.
+	data, err := os.ReadFile(filepath.Join(p.Artifacts.Dir, ReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"test_id", "timestamp", "duration_seconds", "phases", "summary"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("report missing %q", key)
+		}
+	}
+}
+
+func TestRun_SpawnFailureSkipsTasks(t *testing.T) {
+	p := testPlan(t)
+	p.KeepSession = true
+	ranTasks := false
+	report, err := Run(context.Background(), p, Hooks{
+		Spawn: func(context.Context, *Plan) error { return errors.New("tmux exploded") },
+		RunTasks: func(context.Context, *Plan, *pipeline.Workflow) (*pipeline.ExecutionState, error) {
+			ranTasks = true
+			return nil, nil
+		},
+		Collect:  func(context.Context, *Plan, string) error { return errors.New("session gone") },
+		Teardown: func(*Plan) error { t.Error("teardown ran with keep_session"); return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ranTasks {
+		t.Error("tasks ran after spawn failure")
+	}
+	if report.Summary != (Summary{Total: 6, Passed: 0, Failed: 2, Skipped: 4}) {
+		t.Errorf("summary = %+v, phases = %+v", report.Summary, report.Phases)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	p := testPlan(t)
+	p.Timeout.Duration = 20 * time.Millisecond
+	collected := false
+	report, err := Run(context.Background(), p, Hooks{
+		Spawn: func(context.Context, *Plan) error { return nil },
+		RunTasks: func(ctx context.Context, _ *Plan, _ *pipeline.Workflow) (*pipeline.ExecutionState, error) {
+			<-ctx.Done()
+			return &pipeline.ExecutionState{Steps: map[string]pipeline.StepResult{
+				"a": {Status: pipeline.StatusCompleted},
+			}}, ctx.Err()
+		},
+		Collect: func(ctx context.Context, _ *Plan, _ string) error {
+			// Collection gets its own deadline after the batch timeout.
+			collected = ctx.Err() == nil
+			return nil
+		},
+		Teardown: func(*Plan) error { return nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.TimedOut || !collected {
+		t.Errorf("timed_out=%v collected=%v", report.TimedOut, collected)
+	}
+	if report.Phases[2].Status != PhaseFail || !strings.Contains(report.Phases[2].Message, "timeout") {
+		t.Errorf("task b = %+v", report.Phases[2])
+	}
+}
+
+func TestPublishGitHub(t *testing.T) {
+	dir := t.TempDir()
+	summaryPath := filepath.Join(dir, "summary.md")
+	outputPath := filepath.Join(dir, "output")
+	t.Setenv("GITHUB_STEP_SUMMARY", summaryPath)
+	t.Setenv("GITHUB_OUTPUT", outputPath)
+
+	report := NewReport(&Plan{Name: "ci", Session: "batch-ci"})
+	report.ArtifactsDir = "/tmp/artifacts"
+	report.Phases = []PhaseResult{{Name: "task:a", Status: PhaseFail, Message: "x | y"}}
+	report.Finalize()
+	if err := PublishGitHub(report); err != nil {
+		t.Fatal(err)
+	}
+
+	md, _ := os.ReadFile(summaryPath)
+	if !strings.Contains(string(md), "❌ ntm batch: ci") || !strings.Contains(string(md), `x \| y`) {
+		t.Errorf("summary = %s", md)
+	}
+	out, _ := os.ReadFile(outputPath)
+	want := "report=" + filepath.Join("/tmp/artifacts", ReportFile) + "\nartifacts_dir=/tmp/artifacts\nfailed=1\n"
+	if string(out) != want {
+		t.Errorf("outputs = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,42 @@
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PublishGitHub exposes the report to a GitHub Actions job when running
+// inside one: the Markdown table is appended to the job summary, and the
+// report and artifacts paths are set as step outputs for upload-artifact.
+// Outside Actions it does nothing.
+func PublishGitHub(report *Report) error {
+	if summary := os.Getenv("GITHUB_STEP_SUMMARY"); summary != "" {
+		if err := appendFile(summary, report.Markdown()+"\n"); err != nil {
+			return fmt.Errorf("write job summary: %w", err)
+		}
+	}
+	if out := os.Getenv("GITHUB_OUTPUT"); out != "" {
+		var b strings.Builder
+		fmt.Fprintf(&b, "report=%s\n", filepath.Join(report.ArtifactsDir, ReportFile))
+		fmt.Fprintf(&b, "artifacts_dir=%s\n", report.ArtifactsDir)
+		fmt.Fprintf(&b, "failed=%d\n", report.Summary.Failed)
+		if err := appendFile(out, b.String()); err != nil {
+			return fmt.Errorf("write step outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
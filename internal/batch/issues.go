@@ -0,0 +1,43 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/githost"
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+)
+
+// IssuePlan returns a plan that works through issues with agents, one task
+// per issue. A failed task does not stop the others.
+func IssuePlan(name string, agents AgentCounts, issues []githost.Issue) *Plan {
+	p := &Plan{
+		Name:    name,
+		Session: "sched-" + sanitizeSessionName(name),
+		Agents:  agents,
+		OnError: pipeline.ErrorActionContinue,
+	}
+	for _, issue := range issues {
+		p.Tasks = append(p.Tasks, pipeline.Step{
+			ID:     fmt.Sprintf("issue-%d", issue.Number),
+			Name:   issue.Title,
+			Prompt: issuePrompt(issue),
+		})
+	}
+	p.applyDefaults()
+	return p
+}
+
+// issuePrompt asks an agent to resolve issue. Issue text is escaped so
+// pipeline variable substitution leaves it alone.
+func issuePrompt(issue githost.Issue) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Resolve issue #%d: %s\n%s\n", issue.Number, issue.Title, issue.URL)
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		b.WriteString("\n")
+		b.WriteString(body)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nCommit your changes when done and reply with a short summary of what you changed.")
+	return strings.ReplaceAll(b.String(), "${", `\${`)
+}
@@ -0,0 +1,197 @@
+// Package batch runs a task plan to completion without an attached terminal,
+// for CI systems such as GitHub Actions.
+//
+// A plan names the agents to spawn and the tasks to run. Tasks are pipeline
+// steps, so dependencies, retries, and per-task timeouts work as they do in
+// 'ntm pipeline run'. The outcome is written as an integration report in the
+// format the e2e suite uses.
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+)
+
+// Defaults applied to plans that leave a setting empty.
+const (
+	DefaultTimeout      = 30 * time.Minute
+	DefaultReadyTimeout = 2 * time.Minute
+	DefaultArtifactsDir = "ntm-artifacts"
+	DefaultCaptureLines = 2000
+)
+
+// Plan describes a headless batch run.
+type Plan struct {
+	Name string `yaml:"name" json:"name"`
+	// Session is the tmux session to create; empty derives one from Name.
+	Session string `yaml:"session,omitempty" json:"session,omitempty"`
+	// Agents are spawned into a fresh session before the tasks run.
+	Agents AgentCounts `yaml:"agents" json:"agents"`
+	// Timeout bounds the whole run, spawn through the last task.
+	Timeout pipeline.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// ReadyTimeout bounds waiting for spawned agents to become ready.
+	ReadyTimeout pipeline.Duration `yaml:"ready_timeout,omitempty" json:"ready_timeout,omitempty"`
+	// OnError is the pipeline error action: fail (default), fail_fast, or continue.
+	OnError pipeline.ErrorAction `yaml:"on_error,omitempty" json:"on_error,omitempty"`
+	// Vars are passed to the tasks as pipeline variables.
+	Vars map[string]interface{} `yaml:"vars,omitempty" json:"vars,omitempty"`
+
+	// Tasks are the steps to run. Exactly one of Tasks and Workflow is set.
+	Tasks []pipeline.Step `yaml:"tasks,omitempty" json:"tasks,omitempty"`
+	// Workflow is a pipeline workflow file, relative to the plan.
+	Workflow string `yaml:"workflow,omitempty" json:"workflow,omitempty"`
+
+	Artifacts ArtifactOptions `yaml:"artifacts,omitempty" json:"artifacts,omitempty"`
+	// KeepSession leaves the session running after the batch finishes.
+	KeepSession bool `yaml:"keep_session,omitempty" json:"keep_session,omitempty"`
+
+	// path is the file the plan was loaded from.
+	path string
+}
+
+// AgentCounts is the number of agents of each type to spawn.
+type AgentCounts struct {
+	Claude int `yaml:"cc,omitempty" json:"cc,omitempty"`
+	Codex  int `yaml:"cod,omitempty" json:"cod,omitempty"`
+	Gemini int `yaml:"gmi,omitempty" json:"gmi,omitempty"`
+}
+
+// Total returns the number of agents across all types.
+func (a AgentCounts) Total() int {
+	return a.Claude + a.Codex + a.Gemini
+}
+
+// ArtifactOptions controls what is kept after the run.
+type ArtifactOptions struct {
+	// Dir receives the report, pane captures, and summary.
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty"`
+	// Lines is the scrollback captured per pane.
+	Lines int `yaml:"lines,omitempty" json:"lines,omitempty"`
+}
+
+// LoadPlan reads a YAML plan and applies defaults. The result is not
+// validated; call Validate.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read plan: %w", err)
+	}
+	var p Plan
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse plan %s: %w", path, err)
+	}
+	p.path = path
+	p.applyDefaults()
+	return &p, nil
+}
+
+func (p *Plan) applyDefaults() {
+	if p.Name == "" && p.path != "" {
+		p.Name = strings.TrimSuffix(filepath.Base(p.path), filepath.Ext(p.path))
+	}
+	if p.Session == "" {
+		p.Session = "batch-" + sanitizeSessionName(p.Name)
+	}
+	if p.Timeout.Duration == 0 {
+		p.Timeout.Duration = DefaultTimeout
+	}
+	if p.ReadyTimeout.Duration == 0 {
+		p.ReadyTimeout.Duration = DefaultReadyTimeout
+	}
+	if p.Artifacts.Dir == "" {
+		p.Artifacts.Dir = DefaultArtifactsDir
+	}
+	if p.Artifacts.Lines <= 0 {
+		p.Artifacts.Lines = DefaultCaptureLines
+	}
+}
+
+// Validate checks the plan and returns the workflow its tasks run as.
+func (p *Plan) Validate() (*pipeline.Workflow, error) {
+	if p.Agents.Total() == 0 {
+		return nil, fmt.Errorf("plan %q: agents: at least one agent is required", p.Name)
+	}
+	if p.Agents.Claude < 0 || p.Agents.Codex < 0 || p.Agents.Gemini < 0 {
+		return nil, fmt.Errorf("plan %q: agents: counts must be >= 0", p.Name)
+	}
+
+	wf, err := p.workflow()
+	if err != nil {
+		return nil, err
+	}
+	result := pipeline.Validate(wf)
+	if !result.Valid {
+		msgs := make([]string, 0, len(result.Errors))
+		for _, e := range result.Errors {
+			msgs = append(msgs, e.Error())
+		}
+		return nil, fmt.Errorf("plan %q: invalid tasks: %s", p.Name, strings.Join(msgs, "; "))
+	}
+	return wf, nil
+}
+
+// workflow builds the pipeline workflow from the inline tasks or loads the
+// referenced workflow file. Plan-level settings override the file's.
+func (p *Plan) workflow() (*pipeline.Workflow, error) {
+	var wf *pipeline.Workflow
+	switch {
+	case len(p.Tasks) > 0 && p.Workflow != "":
+		return nil, fmt.Errorf("plan %q: set either tasks or workflow, not both", p.Name)
+	case p.Workflow != "":
+		path := p.Workflow
+		if !filepath.IsAbs(path) && p.path != "" {
+			path = filepath.Join(filepath.Dir(p.path), path)
+		}
+		loaded, err := pipeline.ParseFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("plan %q: %w", p.Name, err)
+		}
+		wf = loaded
+	case len(p.Tasks) > 0:
+		wf = &pipeline.Workflow{
+			SchemaVersion: pipeline.SchemaVersion,
+			Name:          p.Name,
+			Steps:         p.Tasks,
+		}
+	default:
+		return nil, fmt.Errorf("plan %q: no tasks (set tasks or workflow)", p.Name)
+	}
+
+	if p.OnError != "" {
+		wf.Settings.OnError = p.OnError
+	}
+	wf.Settings.Timeout = p.Timeout
+	return wf, nil
+}
+
+// sanitizeSessionName keeps characters tmux accepts in session names.
+func sanitizeSessionName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		case r == '-':
+			// "--" is reserved as the session label separator.
+			if !strings.HasSuffix(b.String(), "-") {
+				b.WriteRune(r)
+			}
+		default:
+			if !strings.HasSuffix(b.String(), "-") {
+				b.WriteRune('-')
+			}
+		}
+	}
+	s := strings.Trim(b.String(), "-")
+	if s == "" {
+		s = "run"
+	}
+	return s
+}
@@ -0,0 +1,196 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Phase statuses, as in the e2e integration report.
+const (
+	PhasePass = "pass"
+	PhaseFail = "fail"
+	PhaseSkip = "skip"
+)
+
+// PhaseResult is one phase of a batch run: spawn, a task, artifact
+// collection, or teardown.
+type PhaseResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // "pass", "fail", "skip"
+	DurationMs int64  `json:"duration_ms"`
+	Message    string `json:"message,omitempty"`
+	Details    string `json:"details,omitempty"`
+}
+
+// Summary counts phases by status.
+type Summary struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// Report is the outcome of a batch run. Its fields match the integration
+// report the e2e suite writes (integration_report.json), with the batch
+// context added.
+type Report struct {
+	TestID          string        `json:"test_id"`
+	Timestamp       string        `json:"timestamp"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Phases          []PhaseResult `json:"phases"`
+	Summary         Summary       `json:"summary"`
+
+	Plan         string `json:"plan"`
+	Session      string `json:"session"`
+	TimedOut     bool   `json:"timed_out,omitempty"`
+	ArtifactsDir string `json:"artifacts_dir,omitempty"`
+
+	start time.Time
+}
+
+// NewReport starts a report for plan.
+func NewReport(plan *Plan) *Report {
+	now := time.Now()
+	return &Report{
+		TestID:    fmt.Sprintf("batch-%d", now.Unix()),
+		Timestamp: now.UTC().Format(time.RFC3339),
+		Phases:    []PhaseResult{},
+		Plan:      plan.Name,
+		Session:   plan.Session,
+		start:     now,
+	}
+}
+
+// Run executes fn as a phase and records its result. A nil error passes;
+// an error fails the phase.
+func (r *Report) Run(name string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	result := PhaseResult{
+		Name:       name,
+		Status:     PhasePass,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		result.Status = PhaseFail
+		result.Message = err.Error()
+	}
+	r.Phases = append(r.Phases, result)
+	return err
+}
+
+// Skip records a phase that did not run.
+func (r *Report) Skip(name, reason string) {
+	r.Phases = append(r.Phases, PhaseResult{Name: name, Status: PhaseSkip, Message: reason})
+}
+
+// AddTasks records one phase per task from the pipeline's final state, in
+// workflow order. Tasks that never finished fail; ctxErr explains why.
+func (r *Report) AddTasks(wf *pipeline.Workflow, state *pipeline.ExecutionState, ctxErr error) {
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		r.TimedOut = true
+	}
+	for _, step := range wf.Steps {
+		r.Phases = append(r.Phases, taskPhase(step.ID, state, r.TimedOut))
+	}
+}
+
+func taskPhase(id string, state *pipeline.ExecutionState, timedOut bool) PhaseResult {
+	phase := PhaseResult{Name: "task:" + id, Status: PhaseFail}
+	var res pipeline.StepResult
+	ok := false
+	if state != nil {
+		res, ok = state.Steps[id]
+	}
+	if ok && !res.StartedAt.IsZero() && !res.FinishedAt.IsZero() {
+		phase.DurationMs = res.FinishedAt.Sub(res.StartedAt).Milliseconds()
+	}
+
+	switch {
+	case ok && res.Status == pipeline.StatusCompleted:
+		phase.Status = PhasePass
+		if res.PaneUsed != "" {
+			phase.Message = "pane " + res.PaneUsed
+		}
+	case ok && res.Status == pipeline.StatusSkipped:
+		phase.Status = PhaseSkip
+		phase.Message = res.SkipReason
+	case ok && res.Error != nil:
+		phase.Message = res.Error.Message
+		phase.Details = res.Error.PaneOutput
+	case timedOut:
+		phase.Message = "did not finish before the batch timeout"
+	default:
+		phase.Message = "did not run"
+		if ok && res.Status != "" {
+			phase.Message = "ended " + string(res.Status)
+		}
+	}
+	return phase
+}
+
+// Finalize computes the summary and duration.
+func (r *Report) Finalize() *Report {
+	r.DurationSeconds = time.Since(r.start).Seconds()
+	r.Summary = Summary{Total: len(r.Phases)}
+	for _, p := range r.Phases {
+		switch p.Status {
+		case PhasePass:
+			r.Summary.Passed++
+		case PhaseFail:
+			r.Summary.Failed++
+		case PhaseSkip:
+			r.Summary.Skipped++
+		}
+	}
+	return r
+}
+
+// Failed reports whether any phase failed.
+func (r *Report) Failed() bool {
+	return r.Summary.Failed > 0
+}
+
+// Markdown renders the report as a table, for CI job summaries.
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	icon := "✅"
+	if r.Failed() {
+		icon = "❌"
+	}
+	fmt.Fprintf(&b, "### %s ntm batch: %s\n\n", icon, r.Plan)
+	fmt.Fprintf(&b, "Session `%s` · %d passed, %d failed, %d skipped · %.1fs",
+		r.Session, r.Summary.Passed, r.Summary.Failed, r.Summary.Skipped, r.DurationSeconds)
+	if r.TimedOut {
+		b.WriteString(" · **timed out**")
+	}
+	b.WriteString("\n\n| Phase | Status | Duration | Message |\n|---|---|---|---|\n")
+	for _, p := range r.Phases {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", p.Name, p.Status,
+			(time.Duration(p.DurationMs) * time.Millisecond).String(), markdownCell(p.Message))
+	}
+	return b.String()
+}
+
+// FailedPhases returns the names of failed phases, in run order.
+func (r *Report) FailedPhases() []string {
+	var names []string
+	for _, p := range r.Phases {
+		if p.Status == PhaseFail {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return util.Truncate(s, 200)
+}
@@ -0,0 +1,106 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+)
+
+// ReportFile is the name of the report written to the artifacts directory.
+const ReportFile = "report.json"
+
+// collectTimeout bounds artifact collection, which runs even after the
+// batch timeout has expired.
+const collectTimeout = 2 * time.Minute
+
+// Hooks are the side effects of a run. The CLI wires them to tmux and the
+// pipeline executor; tests substitute fakes.
+type Hooks struct {
+	// Spawn creates the session and its agents and waits for them to be ready.
+	Spawn func(ctx context.Context, plan *Plan) error
+	// RunTasks runs the workflow in the session and returns its final state.
+	RunTasks func(ctx context.Context, plan *Plan, wf *pipeline.Workflow) (*pipeline.ExecutionState, error)
+	// Collect writes pane captures and summaries to dir.
+	Collect func(ctx context.Context, plan *Plan, dir string) error
+	// Teardown kills the session.
+	Teardown func(plan *Plan) error
+	// Logf reports progress; nil discards it.
+	Logf func(format string, args ...any)
+}
+
+// Run executes plan: spawn, tasks, artifact collection, and teardown. Each
+// is recorded as a phase; a failed spawn skips the tasks but artifacts are
+// still collected and the session torn down. The report is written to the
+// artifacts directory. The error is non-nil only when the plan is invalid
+// or the report cannot be written; check Report.Failed for the outcome.
+func Run(ctx context.Context, plan *Plan, hooks Hooks) (*Report, error) {
+	wf, err := plan.Validate()
+	if err != nil {
+		return nil, err
+	}
+	logf := hooks.Logf
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+
+	dir, err := filepath.Abs(plan.Artifacts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("artifacts dir: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create artifacts dir: %w", err)
+	}
+
+	report := NewReport(plan)
+	report.ArtifactsDir = dir
+
+	runCtx, cancel := context.WithTimeout(ctx, plan.Timeout.Duration)
+	defer cancel()
+
+	logf("spawning %d agents in session %s", plan.Agents.Total(), plan.Session)
+	spawnErr := report.Run("spawn", func() error { return hooks.Spawn(runCtx, plan) })
+	if spawnErr != nil {
+		logf("spawn failed: %v", spawnErr)
+		for _, step := range wf.Steps {
+			report.Skip("task:"+step.ID, "spawn failed")
+		}
+	} else {
+		logf("running %d tasks (timeout %s)", len(wf.Steps), plan.Timeout.Duration)
+		state, runErr := hooks.RunTasks(runCtx, plan, wf)
+		if runErr != nil && state == nil {
+			logf("tasks did not run: %v", runErr)
+		}
+		report.AddTasks(wf, state, runCtx.Err())
+	}
+
+	collectCtx, cancelCollect := context.WithTimeout(context.WithoutCancel(ctx), collectTimeout)
+	defer cancelCollect()
+	logf("collecting artifacts in %s", dir)
+	_ = report.Run("artifacts", func() error { return hooks.Collect(collectCtx, plan, dir) })
+
+	if plan.KeepSession {
+		report.Skip("teardown", "keep_session is set")
+	} else {
+		_ = report.Run("teardown", func() error { return hooks.Teardown(plan) })
+	}
+
+	report.Finalize()
+	if err := writeJSON(filepath.Join(dir, ReportFile), report); err != nil {
+		return report, fmt.Errorf("write report: %w", err)
+	}
+	logf("done: %d passed, %d failed, %d skipped", report.Summary.Passed, report.Summary.Failed, report.Summary.Skipped)
+	return report, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
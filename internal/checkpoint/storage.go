@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -32,6 +33,19 @@ const (
 
 var checkpointIDRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
 
+var (
+	baseDirMu       sync.RWMutex
+	baseDirOverride string
+)
+
+// SetBaseDir moves checkpoints to dir for storages created by NewStorage.
+// An empty dir restores DefaultCheckpointDir under the home directory.
+func SetBaseDir(dir string) {
+	baseDirMu.Lock()
+	defer baseDirMu.Unlock()
+	baseDirOverride = dir
+}
+
 // Storage manages checkpoint storage on disk.
 type Storage struct {
 	// BaseDir is the base directory for all checkpoints
@@ -41,6 +55,13 @@ type Storage struct {
 // NewStorage creates a new Storage with the default directory.
 // Falls back to /tmp if the user's home directory cannot be determined.
 func NewStorage() *Storage {
+	baseDirMu.RLock()
+	override := baseDirOverride
+	baseDirMu.RUnlock()
+	if override != "" {
+		return &Storage{BaseDir: util.ExpandPath(override)}
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil || home == "" {
 		// Fallback to /tmp when home directory is unavailable (e.g., containers)
@@ -325,6 +346,53 @@ func (s *Storage) Delete(sessionName, checkpointID string) error {
 	return os.RemoveAll(dir)
 }
 
+// PurgeSession removes the checkpoints of a session. If before is non-zero,
+// only checkpoints created before it are removed; otherwise the whole
+// session directory goes, including checkpoints that fail to load. It
+// returns the number of checkpoints removed.
+func (s *Storage) PurgeSession(sessionName string, before time.Time) (int, error) {
+	sessionDir, err := s.safeSessionDir(sessionName)
+	if err != nil {
+		return 0, err
+	}
+
+	if before.IsZero() {
+		entries, err := os.ReadDir(sessionDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return 0, nil
+			}
+			return 0, fmt.Errorf("reading session directory: %w", err)
+		}
+		removed := 0
+		for _, entry := range entries {
+			if entry.IsDir() {
+				removed++
+			}
+		}
+		if err := os.RemoveAll(sessionDir); err != nil {
+			return 0, fmt.Errorf("removing session checkpoints: %w", err)
+		}
+		return removed, nil
+	}
+
+	checkpoints, err := s.List(sessionName)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, cp := range checkpoints {
+		if !cp.CreatedAt.Before(before) {
+			continue
+		}
+		if err := s.Delete(sessionName, cp.ID); err != nil {
+			return removed, fmt.Errorf("deleting checkpoint %s: %w", cp.ID, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
 // GetLatest returns the most recent checkpoint for a session.
 func (s *Storage) GetLatest(sessionName string) (*Checkpoint, error) {
 	checkpoints, err := s.List(sessionName)
@@ -36,6 +36,8 @@ type AddOptions struct {
 	CassContextQuery string
 	NoCassContext    bool
 	Prompt           string
+	IgnoreSchedule   bool
+	OverrideGuards   bool // Add past an inherited maxAgents (audited)
 }
 
 func newAddCmd() *cobra.Command {
@@ -47,6 +49,8 @@ func newAddCmd() *cobra.Command {
 	var contextDays int
 	var prompt string
 	var label string
+	var ignoreSchedule bool
+	var overrideGuards bool
 
 	cmd := &cobra.Command{
 		Use:   "add <session-name>",
@@ -146,6 +150,8 @@ func newAddCmd() *cobra.Command {
 				CassContextQuery: contextQuery,
 				NoCassContext:    noCassContext,
 				Prompt:           prompt,
+				IgnoreSchedule:   ignoreSchedule,
+				OverrideGuards:   overrideGuards,
 			}
 
 			return runAdd(opts)
@@ -169,6 +175,8 @@ func newAddCmd() *cobra.Command {
 	cmd.Flags().IntVar(&contextLimit, "cass-context-limit", 0, "Max past sessions to include")
 	cmd.Flags().IntVar(&contextDays, "cass-context-days", 0, "Look back N days")
 	cmd.Flags().StringVar(&prompt, "prompt", "", "Prompt to initialize agents with")
+	cmd.Flags().BoolVar(&ignoreSchedule, "ignore-schedule", false, "Add agents even during a rate limit schedule pause window")
+	cmd.Flags().BoolVar(&overrideGuards, "override-guards", false, "Add agents past the session template's maxAgents (audited)")
 
 	// Register plugin flags
 	configDir := filepath.Dir(config.DefaultPath())
@@ -218,6 +226,30 @@ func runAdd(opts AddOptions) error {
 		return outputError(fmt.Errorf("no agents specified"))
 	}
 
+	if !opts.IgnoreSchedule {
+		var types []AgentType
+		for _, spec := range opts.Agents {
+			if spec.Count > 0 {
+				types = append(types, spec.Type)
+			}
+		}
+		if err := scheduledSpawnPause(rateLimitSchedule(), types, time.Now()); err != nil {
+			return outputError(err)
+		}
+	}
+
+	if panes, err := tmux.GetPanes(session); err == nil {
+		existing := 0
+		for _, p := range panes {
+			if p.Type != tmux.AgentUser {
+				existing++
+			}
+		}
+		if err := checkTemplateAgents(session, existing+totalAgents, opts.OverrideGuards); err != nil {
+			return outputError(err)
+		}
+	}
+
 	dir := cfg.GetProjectDir(session)
 
 	// Enable project webhooks (if configured) so add lifecycle events can fan out.
@@ -348,11 +380,12 @@ func runAdd(opts AddOptions) error {
 	openAICooldownWaited := false
 
 	for _, agent := range flatAgents {
-		if agent.Type == AgentTypeCodex {
+		if agent.Type == AgentTypeCodex || cfg.Accounts.RoundRobin {
 			rateLimitTracker = ratelimit.NewRateLimitTracker(dir)
 			if err := rateLimitTracker.LoadFromDir(dir); err != nil && !IsJSONOutput() {
 				output.PrintWarningf("Failed to load rate limit history: %v", err)
 			}
+			rateLimitTracker.SetSchedule(rateLimitSchedule())
 			break
 		}
 	}
@@ -522,6 +555,26 @@ func runAdd(opts AddOptions) error {
 			return outputError(fmt.Errorf("generating command for %s agent: %w", agent.Type, err))
 		}
 
+		// Apply [spawn_env] injection; secrets travel in a self-deleting file
+		paneAgentName := fmt.Sprintf("%s_%d", strings.ToLower(agentTypeStr), num)
+		account, accountWait := pickPaneAccount(rateLimitTracker, agentTypeStr, num)
+		if account != nil && !IsJSONOutput() {
+			output.PrintInfof("Assigning account %s to %s", account.Name(), paneAgentName)
+			if accountWait > 0 {
+				output.PrintWarningf("All %s accounts are cooling down; %s frees up in %s", agentTypeStr, account.Name(), ratelimit.FormatDelay(accountWait))
+			}
+		}
+		injected, err := preparePaneEnv(session, agentTypeStr, paneAgentName, account)
+		if err != nil {
+			return outputError(fmt.Errorf("preparing environment for %s: %w", paneAgentName, err))
+		}
+		if injected != nil {
+			finalCmd = injected.Prefix + finalCmd
+			if !IsJSONOutput() {
+				output.PrintInfof("Injecting %d env var(s) into %s (%d secret)", len(injected.Plain)+len(injected.Secrets), paneAgentName, len(injected.Secrets))
+			}
+		}
+
 		// Apply plugin env vars
 		if len(envVars) > 0 {
 			var envPrefix string
@@ -533,12 +586,13 @@ func runAdd(opts AddOptions) error {
 
 		safeCmd, err := tmux.SanitizePaneCommand(finalCmd)
 		if err != nil {
+			injected.discard()
 			return outputError(fmt.Errorf("invalid agent command: %w", err))
 		}
 
 		if agent.Type == AgentTypeCodex {
 			var cooldown time.Duration
-			cooldown, openAICooldownWaited = codexCooldownRemaining(rateLimitTracker, openAICooldownWaited)
+			cooldown, openAICooldownWaited = codexCooldownRemaining(rateLimitTracker, ratelimit.AccountKey("openai", accountName(account)), openAICooldownWaited)
 			if cooldown > 0 {
 				if !IsJSONOutput() {
 					output.PrintWarningf("Codex cooldown active; waiting %s before launching", ratelimit.FormatDelay(cooldown))
@@ -549,14 +603,21 @@ func runAdd(opts AddOptions) error {
 
 		cmd, err := tmux.BuildPaneCommand(dir, safeCmd)
 		if err != nil {
+			injected.discard()
 			return outputError(fmt.Errorf("building agent command: %w", err))
 		}
 
-		if err := tmux.SendKeys(paneID, cmd, true); err != nil {
+		if err := tmux.SendKeys(paneID, injected.wrap(cmd), true); err != nil {
+			injected.discard()
 			return outputError(fmt.Errorf("launching agent: %w", err))
 		}
+		if account != nil {
+			if err := tmux.SetPaneOption(paneID, tmux.PaneAccountOption, account.Name()); err != nil && !IsJSONOutput() {
+				output.PrintWarningf("Failed to record account for %s: %v", paneAgentName, err)
+			}
+		}
 		if rateLimitTracker != nil && agent.Type == AgentTypeCodex {
-			rateLimitTracker.RecordSuccess("openai")
+			rateLimitTracker.RecordSuccess(ratelimit.AccountKey("openai", accountName(account)))
 			if err := rateLimitTracker.SaveToDir(dir); err != nil && !IsJSONOutput() {
 				output.PrintWarningf("Failed to persist rate limit history: %v", err)
 			}
@@ -640,6 +701,7 @@ func runAdd(opts AddOptions) error {
 			Type:    agentTypeStr,
 			Variant: agent.Model,
 			Command: cmd,
+			Account: accountName(account),
 		})
 	}
 
@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+)
+
+// archiveExportResult is the output of `ntm archive export`.
+type archiveExportResult struct {
+	Session string `json:"session"`
+	Pane    string `json:"pane"`
+	Format  string `json:"format"`
+	Path    string `json:"path"`
+	archive.CastStats
+}
+
+func newArchiveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Work with archived pane output",
+		Long: `Work with the pane output that ntm monitor archives to ~/.ntm/archive.
+
+Examples:
+  ntm archive export myproject --pane cc_1 --format cast
+  ntm archive stats myproject`,
+	}
+	cmd.AddCommand(newArchiveExportCmd())
+	cmd.AddCommand(newArchiveStatsCmd())
+	return cmd
+}
+
+func newArchiveExportCmd() *cobra.Command {
+	var (
+		pane      string
+		format    string
+		out       string
+		cols      int
+		rows      int
+		idleLimit float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export [session]",
+		Short: "Export a pane's archived output as a replayable recording",
+		Long: `Export one pane's archived output as an asciinema v2 cast file.
+
+Every archived capture becomes an output event at its original time, so the
+recording replays in any asciinema player (asciinema play, asciinema-player,
+asciinema.org) with the session's real pacing. Idle panes are captured as
+rarely as every 2 minutes, so --idle-limit (default 2s) tells players to
+compress the gaps between captures; use --idle-limit 0 to keep real time.
+
+The pane is given by name as archived (cc_1, cod_2) or by pane index. It may
+be omitted when the session archive holds only one pane. Without a session,
+the most recently archived session is used.
+
+Examples:
+  ntm archive export myproject --pane cc_1 --format cast
+  ntm archive export myproject --pane 2 --out review.cast
+  ntm archive export --pane cc_1 --idle-limit 0`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			}
+			return runArchiveExport(session, pane, format, out, archive.CastOptions{
+				Width:         cols,
+				Height:        rows,
+				IdleTimeLimit: idleLimit,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&pane, "pane", "", "Pane to export (name such as cc_1, or pane index)")
+	cmd.Flags().StringVar(&format, "format", "cast", "Export format: cast")
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Output file (default: <session>_<pane>.cast)")
+	cmd.Flags().IntVar(&cols, "cols", 0, "Terminal width (default: fit the longest line)")
+	cmd.Flags().IntVar(&rows, "rows", archive.DefaultCastHeight, "Terminal height")
+	cmd.Flags().Float64Var(&idleLimit, "idle-limit", 2, "Seconds of inactivity players compress to (0 = real time)")
+	return cmd
+}
+
+func runArchiveExport(session, pane, format, out string, opts archive.CastOptions) error {
+	if format != "cast" {
+		return fmt.Errorf("unsupported format %q (supported: cast)", format)
+	}
+	if opts.IdleTimeLimit < 0 {
+		return fmt.Errorf("--idle-limit must be >= 0")
+	}
+
+	session, records, err := loadSessionArchive(session)
+	if err != nil {
+		return err
+	}
+	pane, records, err = selectArchivePane(records, pane)
+	if err != nil {
+		return fmt.Errorf("session %s: %w", session, err)
+	}
+
+	opts.Title = fmt.Sprintf("ntm %s %s", session, pane)
+	var buf bytes.Buffer
+	stats, err := archive.WriteCast(&buf, records, opts)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		out = fmt.Sprintf("%s_%s.cast", session, pane)
+	}
+	if err := os.WriteFile(out, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(archiveExportResult{
+			Session:   session,
+			Pane:      pane,
+			Format:    format,
+			Path:      out,
+			CastStats: stats,
+		})
+	}
+	t := theme.Current()
+	fmt.Printf("%s✓%s Exported %d captures of %s (%s) to %s\n",
+		colorize(t.Success), colorize(t.Text),
+		stats.Events, pane, formatDuration(time.Duration(stats.Duration*float64(time.Second))), out)
+	return nil
+}
+
+func newArchiveStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats [session]",
+		Short: "Show how often each pane is being captured",
+		Long: `Show the capture schedule ntm monitor keeps for each pane.
+
+Panes are captured every [archive] min_interval_seconds while their output
+changes. Each capture that finds nothing new multiplies the pane's interval
+by backoff, up to max_interval_seconds; new output resets it. The table
+shows each pane's current interval, how many captures found new output, how
+many were trimmed or skipped as TUI redraws, and how many captures in a row
+found none. Use it to tune the [archive] settings
+against missed output or CPU use. In pipe mode, streamed panes show how many
+lines pipe-pane delivered instead of an interval.
+
+Examples:
+  ntm archive stats myproject
+  ntm archive stats myproject --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) > 0 {
+				session = args[0]
+			} else {
+				res, err := ResolveSession("", cmd.OutOrStdout())
+				if err != nil {
+					return err
+				}
+				if res.Session == "" {
+					return nil
+				}
+				session = res.Session
+			}
+			return runArchiveStats(session)
+		},
+	}
+}
+
+func runArchiveStats(session string) error {
+	stats, err := archive.ReadCaptureStats(archive.OutputDir(), session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no capture stats for session %q; is ntm monitor running for it?", session)
+		}
+		return err
+	}
+	if IsJSONOutput() {
+		return output.PrintJSON(stats)
+	}
+
+	fmt.Printf("Session %s (%s mode): captures every %s while active, up to %s when idle (backoff x%g)\n",
+		stats.Session, stats.Mode,
+		formatDuration(time.Duration(stats.MinIntervalMs)*time.Millisecond),
+		formatDuration(time.Duration(stats.MaxIntervalMs)*time.Millisecond),
+		stats.Backoff)
+	if stats.Paused {
+		fmt.Println("Archiving is paused for low disk space")
+	}
+	if stats.QueueFull > 0 {
+		fmt.Printf("Streamed output waited for the archive %d times\n", stats.QueueFull)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PANE\tMODE\tINTERVAL\tCAPTURES\tCHANGED\tDEDUPED\tIDLE STREAK\tSTREAMED\tLAST CHANGE\tNEXT")
+	for _, p := range stats.Panes {
+		lastChange := "-"
+		if !p.LastChange.IsZero() {
+			lastChange = formatAge(p.LastChange)
+		}
+		next := "due"
+		if d := time.Until(p.NextCapture); d > 0 {
+			next = "in " + formatDuration(d)
+		}
+		interval, streamed := formatDuration(time.Duration(p.IntervalMs)*time.Millisecond), "-"
+		if p.Mode == archive.ModePipe {
+			interval, next = "stream", "-"
+			streamed = fmt.Sprintf("%d lines", p.Streamed)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			p.Pane, p.Mode, interval, p.Captures, p.Changes, p.Deduped, p.IdleCaptures, streamed, lastChange, next)
+	}
+	_ = w.Flush()
+	fmt.Printf("Updated %s\n", formatAge(stats.UpdatedAt))
+	return nil
+}
+
+// loadSessionArchive reads every archive file of session in date order. An
+// empty session selects the most recently archived one.
+func loadSessionArchive(session string) (string, []archive.ArchiveRecord, error) {
+	files, err := listArchiveFiles()
+	if err != nil {
+		return "", nil, err
+	}
+	if session == "" {
+		if len(files) == 0 {
+			return "", nil, fmt.Errorf("no archived output found")
+		}
+		session = files[0].Session
+	}
+
+	var records []archive.ArchiveRecord
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].Session != session {
+			continue
+		}
+		fileRecords, err := archive.ReadRecords(files[i].Path)
+		if err != nil {
+			return session, nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+	if len(records) == 0 {
+		return session, nil, fmt.Errorf("no archived output found for session %q", session)
+	}
+	return session, records, nil
+}
+
+// selectArchivePane returns the records of one pane, matched by archived
+// name or pane index. An empty pane is allowed when only one pane exists.
+func selectArchivePane(records []archive.ArchiveRecord, pane string) (string, []archive.ArchiveRecord, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, r := range records {
+		if !seen[r.Pane] {
+			seen[r.Pane] = true
+			names = append(names, r.Pane)
+		}
+	}
+	sort.Strings(names)
+
+	if pane == "" {
+		if len(names) != 1 {
+			return "", nil, fmt.Errorf("--pane required; archived panes: %s", strings.Join(names, ", "))
+		}
+		pane = names[0]
+	}
+
+	var selected []archive.ArchiveRecord
+	for _, r := range records {
+		if r.Pane == pane || strconv.Itoa(r.PaneIndex) == pane {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 {
+		return "", nil, fmt.Errorf("no archived output for pane %q; archived panes: %s", pane, strings.Join(names, ", "))
+	}
+	return selected[0].Pane, selected, nil
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+)
+
+func writeTestArchive(t *testing.T, dir, name string, records ...archive.ArchiveRecord) {
+	t.Helper()
+	var lines []string
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(data))
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunArchiveExportCast(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	archiveDir := filepath.Join(home, ".ntm", "archive")
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	day1 := time.Date(2026, 3, 1, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Minute)
+	writeTestArchive(t, archiveDir, "proj_2026-03-01.jsonl",
+		archive.ArchiveRecord{Session: "proj", Pane: "cc_2", PaneIndex: 2, Timestamp: day1, Sequence: 1, Content: "hello"},
+		archive.ArchiveRecord{Session: "proj", Pane: "cod_3", PaneIndex: 3, Timestamp: day1, Sequence: 1, Content: "other pane"},
+	)
+	writeTestArchive(t, archiveDir, "proj_2026-03-02.jsonl",
+		archive.ArchiveRecord{Session: "proj", Pane: "cc_2", PaneIndex: 2, Timestamp: day2, Sequence: 2, Content: "world"},
+	)
+
+	out := filepath.Join(t.TempDir(), "out.cast")
+	if err := runArchiveExport("proj", "2", "cast", out, archive.CastOptions{}); err != nil {
+		t.Fatalf("runArchiveExport: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("cast = %s, want header and both days of cc_2", data)
+	}
+	if !strings.Contains(lines[0], `"title":"ntm proj cc_2"`) || !strings.Contains(lines[2], `[120,"o","world\r\n"]`) {
+		t.Errorf("cast = %s", data)
+	}
+	if strings.Contains(string(data), "other pane") {
+		t.Error("cast includes another pane's output")
+	}
+
+	err = runArchiveExport("proj", "", "cast", out, archive.CastOptions{})
+	if err == nil || !strings.Contains(err.Error(), "cc_2, cod_3") {
+		t.Errorf("missing --pane error = %v", err)
+	}
+	if err := runArchiveExport("proj", "cc_2", "gif", out, archive.CastOptions{}); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if err := runArchiveExport("missing", "cc_2", "cast", out, archive.CastOptions{}); err == nil {
+		t.Error("expected error for session without archive")
+	}
+}
+
+func TestRunArchiveStats(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	archiveDir := filepath.Join(home, ".ntm", "archive")
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runArchiveStats("proj"); err == nil || !strings.Contains(err.Error(), "ntm monitor") {
+		t.Errorf("missing stats error = %v", err)
+	}
+
+	data, err := json.Marshal(archive.CaptureStats{
+		Session:       "proj",
+		UpdatedAt:     time.Now(),
+		MinIntervalMs: 5000,
+		MaxIntervalMs: 120000,
+		Backoff:       2,
+		Panes:         []archive.PaneCaptureStats{{PaneIndex: 2, Pane: "cc_2", IntervalMs: 20000, Captures: 9, Changes: 6, IdleCaptures: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archive.CaptureStatsPath(archiveDir, "proj"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runArchiveStats("proj"); err != nil {
+		t.Errorf("runArchiveStats: %v", err)
+	}
+}
@@ -26,8 +26,10 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/config"
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/takeover"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+	"github.com/Dicklesworthstone/ntm/internal/verify"
 	"github.com/Dicklesworthstone/ntm/internal/webhook"
 )
 
@@ -45,8 +47,9 @@ var (
 	assignVerbose      bool
 	assignQuiet        bool
 	assignTimeout      time.Duration
-	assignDryRun       bool // Alias for no --auto
-	assignReserveFiles bool // Enable Agent Mail file reservations
+	assignDryRun       bool   // Alias for no --auto
+	assignReserveFiles bool   // Enable Agent Mail file reservations
+	assignPolicyFile   string // Lua assignment policy script
 
 	// Direct pane assignment flags
 	assignPane       int    // Direct pane assignment (0 = disabled, since pane 0 is valid we use -1 as default)
@@ -126,7 +129,17 @@ Clear Assignments:
 
 Watch Mode (Dependency-Aware Auto-Assignment):
   Use --watch to enable continuous monitoring for task completions and automatic
-  reassignment of newly unblocked beads to idle agents.
+  reassignment of newly unblocked beads to idle agents. A task is complete when
+  its bead closes, or when its agent shows done signals (final answer, prompt
+  back, cost summary, NTM-REPORT done) and its output stays quiet for a short
+  per-agent period; otherwise after 2 minutes of inactivity.
+
+  With [assign.verify] enabled in config, completed tasks first run the
+  configured commands (tests, build, lint) in the project directory. Failures
+  go back to the agent with the failing output, up to max_bounces times, then
+  the task is marked failed. Results are stored with the assignment and the
+  agent's score. Failing commands are retried; flaky ones do not count against
+  the agent and are quarantined after repeated flakes (see ntm flaky).
 
   ntm assign myproject --watch                      # Watch mode with auto-reassignment
   ntm assign myproject --watch --strategy=dependency # Watch with dependency-first strategy
@@ -135,6 +148,14 @@ Watch Mode (Dependency-Aware Auto-Assignment):
   ntm assign myproject --watch --delay=5s           # 5s delay between assignments
   ntm assign myproject --watch --watch-interval=10s # Check every 10 seconds
 
+Working Sets:
+  While --watch runs, each active assignment records its working set: files
+  read and written (from the agent's tool calls and from uncommitted changes
+  under its Agent Mail reservations), commands run, and reserved paths. It is
+  stored with the assignment. Beads whose paths overlap files an in-flight
+  assignment writes or reserves are skipped with reason overlaps_in_flight_work
+  and the overlap listed; --force assigns them anyway.
+
 Reassignment (Move Bead Between Agents):
   Use --reassign to move an assigned bead from one agent to another. This is useful
   when an agent is stuck, or when you want to redistribute work to a different agent.
@@ -199,10 +220,11 @@ Examples:
 	cmd.Flags().DurationVar(&assignTimeout, "timeout", 30*time.Second, "Timeout for external calls (bv, br, Agent Mail)")
 	cmd.Flags().BoolVar(&assignDryRun, "dry-run", false, "Preview mode (alias for no --auto)")
 	cmd.Flags().BoolVar(&assignReserveFiles, "reserve-files", true, "Reserve file paths via Agent Mail before assignment")
+	cmd.Flags().StringVar(&assignPolicyFile, "policy", "", "Lua assignment policy script (default: [assign] script, then .ntm/assign.lua)")
 
 	// Direct pane assignment flags
 	cmd.Flags().IntVar(&assignPane, "pane", -1, "Assign bead directly to a specific pane (requires --beads)")
-	cmd.Flags().BoolVar(&assignForce, "force", false, "Force assignment even if pane is busy (also allows --clear to remove completed assignments)")
+	cmd.Flags().BoolVar(&assignForce, "force", false, "Force assignment even if pane is busy or overlaps in-flight work (also allows --clear to remove completed assignments)")
 	cmd.Flags().BoolVar(&assignIgnoreDeps, "ignore-deps", false, "Ignore dependency checks for assignment")
 	cmd.Flags().StringVar(&assignPrompt, "prompt", "", "Custom prompt for direct assignment")
 
@@ -268,6 +290,9 @@ func runAssign(cmd *cobra.Command, args []string) error {
 			defer bridge.Close()
 		}
 	}
+	if hookBridge := startExecHookBridge(projectDir, session); hookBridge != nil {
+		defer hookBridge.Close()
+	}
 
 	// Apply config default for strategy if not explicitly set via flag
 	if !cmd.Flags().Changed("strategy") {
@@ -288,6 +313,10 @@ func runAssign(cmd *cobra.Command, args []string) error {
 		return runClearAssignments(cmd, session)
 	}
 
+	if err := checkWindingDown(session); err != nil {
+		return err
+	}
+
 	// Handle reassignment operation
 	if assignReassign != "" {
 		return runReassignment(cmd, session)
@@ -336,6 +365,7 @@ func runAssign(cmd *cobra.Command, args []string) error {
 		Quiet:           assignQuiet,
 		Timeout:         assignTimeout,
 		ReserveFiles:    assignReserveFiles,
+		PolicyFile:      assignPolicyFile,
 		Pane:            assignPane,
 		Force:           assignForce,
 		IgnoreDeps:      assignIgnoreDeps,
@@ -410,6 +440,7 @@ func runWatchMode(cmd *cobra.Command, session string) error {
 		Quiet:           assignQuiet,
 		Timeout:         assignTimeout,
 		AgentTypeFilter: agentTypeFilter,
+		PolicyFile:      assignPolicyFile,
 	}
 
 	// Load or create assignment store
@@ -449,6 +480,7 @@ func runWatchMode(cmd *cobra.Command, session string) error {
 		Quiet:           true, // Suppress normal output during initial pass
 		Timeout:         assignTimeout,
 		ReserveFiles:    assignReserveFiles,
+		PolicyFile:      assignPolicyFile,
 	}
 
 	initialOutput, err := getAssignOutputEnhanced(assignOpts)
@@ -538,7 +570,9 @@ type AssignCommandOptions struct {
 	Verbose         bool
 	Quiet           bool
 	Timeout         time.Duration
-	ReserveFiles    bool // Reserve file paths via Agent Mail before assignment
+	ReserveFiles    bool                 // Reserve file paths via Agent Mail before assignment
+	PolicyFile      string               // Lua assignment policy script (default: [assign] script, then .ntm/assign.lua)
+	Policy          *assign.PolicyScript // Loaded policy, set while generating assignments
 
 	// Direct pane assignment options
 	Pane       int    // Direct pane assignment (-1 = disabled)
@@ -572,14 +606,25 @@ type AssignmentItem struct {
 	AssignedAt string  `json:"assigned_at"` // ISO8601 timestamp
 	Score      float64 `json:"score,omitempty"`
 	Reasoning  string  `json:"-"`
+
+	Reviewer *AssignmentReviewer `json:"reviewer,omitempty"` // Paired by the policy script's reviewer()
+}
+
+// AssignmentReviewer is an idle agent a policy script paired with an
+// assignment to review its work.
+type AssignmentReviewer struct {
+	Pane      int    `json:"pane"`
+	AgentType string `json:"agent_type"`
+	AgentName string `json:"agent_name"`
 }
 
 // SkippedItem represents a skipped bead
 type SkippedItem struct {
-	BeadID       string   `json:"bead_id"`
-	BeadTitle    string   `json:"bead_title"`
-	Reason       string   `json:"reason"`
-	BlockedByIDs []string `json:"blocked_by_ids,omitempty"` // Only set when reason is "blocked"
+	BeadID       string                         `json:"bead_id"`
+	BeadTitle    string                         `json:"bead_title"`
+	Reason       string                         `json:"reason"`
+	BlockedByIDs []string                       `json:"blocked_by_ids,omitempty"` // Only set when reason is "blocked"
+	Overlaps     []assignment.WorkingSetOverlap `json:"overlaps,omitempty"`       // Only set when reason is "overlaps_in_flight_work"
 }
 
 // AssignSummaryEnhanced contains summary statistics
@@ -1183,12 +1228,16 @@ func getAssignOutputEnhanced(opts *AssignCommandOptions) (*AssignOutputEnhanced,
 
 	// Build agent info and filter by type if needed
 	var idleAgents []assignAgentInfo
+	takenOver := takeover.Panes(opts.Session)
 
 	for _, pane := range panes {
 		at := detectAgentTypeFromTitle(pane.Title)
 		if at == "user" || at == "unknown" {
 			continue
 		}
+		if takenOver[pane.ID] != nil {
+			continue // A human has the pane
+		}
 
 		// Apply agent type filter
 		if opts.AgentTypeFilter != "" && at != opts.AgentTypeFilter {
@@ -1336,20 +1385,31 @@ func getAssignOutputEnhanced(opts *AssignCommandOptions) (*AssignOutputEnhanced,
 		readyBeads = nonCyclic
 	}
 
+	// Hold back beads that would touch files in-flight work is changing
+	var overlappingBeads []SkippedItem
+	if !opts.Force {
+		readyBeads, overlappingBeads = filterOverlappingBeads(opts.Session, readyBeads)
+		if opts.Verbose {
+			for _, b := range overlappingBeads {
+				fmt.Fprintf(os.Stderr, "[WORKSET] Skipping %s - overlaps in-flight %s\n", b.BeadID, b.Overlaps[0].BeadID)
+			}
+		}
+	}
+
 	// Limit ready beads to 50
 	if len(readyBeads) > 50 {
 		readyBeads = readyBeads[:50]
 	}
 
 	// Combine all skipped beads
-	allSkipped := append(blockedBeads, cyclicBeads...)
+	allSkipped := append(append(blockedBeads, cyclicBeads...), overlappingBeads...)
 
 	result := &AssignOutputEnhanced{
 		Strategy:    opts.Strategy,
 		Assignments: make([]AssignmentItem, 0),
 		Skipped:     allSkipped, // Blocked + cyclic beads
 		Summary: AssignSummaryEnhanced{
-			TotalBeadCount:    len(readyBeads) + len(blockedBeads) + cycleWarnings,
+			TotalBeadCount:    len(readyBeads) + len(blockedBeads) + cycleWarnings + len(overlappingBeads),
 			ActionableCount:   len(readyBeads),
 			BlockedCount:      len(blockedBeads),
 			IdleAgents:        len(idleAgents),
@@ -1375,8 +1435,15 @@ func getAssignOutputEnhanced(opts *AssignCommandOptions) (*AssignOutputEnhanced,
 		return result, nil
 	}
 
-	// Generate assignments using strategy
-	assignments := generateAssignmentsEnhanced(idleAgents, readyBeads, opts)
+	// Generate assignments using strategy, under the policy script if any
+	policy, err := loadAssignPolicy(opts.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer policy.Close()
+	genOpts := *opts
+	genOpts.Policy = policy
+	assignments := generateAssignmentsEnhanced(idleAgents, readyBeads, &genOpts)
 
 	// Apply limit
 	if opts.Limit > 0 && len(assignments) > opts.Limit {
@@ -1397,11 +1464,42 @@ func getAssignOutputEnhanced(opts *AssignCommandOptions) (*AssignOutputEnhanced,
 	return result, nil
 }
 
+// filterOverlappingBeads splits off the beads whose paths (taken from their
+// titles) overlap files that active assignments write or reserve.
+func filterOverlappingBeads(session string, beads []bv.BeadPreview) ([]bv.BeadPreview, []SkippedItem) {
+	store, err := assignment.LoadStore(session)
+	if err != nil {
+		return beads, nil
+	}
+	var kept []bv.BeadPreview
+	var skipped []SkippedItem
+	for _, bead := range beads {
+		var conflicting []assignment.WorkingSetOverlap
+		for _, o := range store.Overlaps(assign.ExtractFilePaths(bead.Title, "")) {
+			if o.BeadID != bead.ID && o.Conflicting() {
+				conflicting = append(conflicting, o)
+			}
+		}
+		if len(conflicting) == 0 {
+			kept = append(kept, bead)
+			continue
+		}
+		skipped = append(skipped, SkippedItem{
+			BeadID:    bead.ID,
+			BeadTitle: bead.Title,
+			Reason:    "overlaps_in_flight_work",
+			Overlaps:  conflicting,
+		})
+	}
+	return kept, skipped
+}
+
 // generateAssignmentsEnhanced creates assignment recommendations using the enhanced strategy logic
 func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPreview, opts *AssignCommandOptions) []AssignmentItem {
 	var assignments []AssignmentItem
 	assignedAt := time.Now().UTC().Format(time.RFC3339)
 	defaultStatus := string(assignment.StatusAssigned)
+	policy := newAssignPolicy(opts)
 
 	switch strings.ToLower(opts.Strategy) {
 	case "round-robin":
@@ -1425,7 +1523,19 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 			if len(agents) == 0 {
 				break
 			}
-			agent := agents[i%len(agents)]
+			// A policy that denies the slot's agent moves the bead to the
+			// next agent in rotation that it allows.
+			slot := -1
+			for j := 0; j < len(agents); j++ {
+				if policy.allows(bead, agents[(i+j)%len(agents)]) {
+					slot = (i + j) % len(agents)
+					break
+				}
+			}
+			if slot < 0 {
+				continue
+			}
+			agent := agents[slot]
 			assignments = append(assignments, AssignmentItem{
 				BeadID:     bead.ID,
 				BeadTitle:  bead.Title,
@@ -1435,8 +1545,8 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 				Status:     defaultStatus,
 				PromptSent: false,
 				AssignedAt: assignedAt,
-				Score:      1.0, // Round-robin: all assignments equally valid
-				Reasoning:  fmt.Sprintf("round-robin slot %d → agent %d", i+1, i%len(agents)),
+				Score:      policy.score(bead, agent, 1.0), // Round-robin: all assignments equally valid
+				Reasoning:  fmt.Sprintf("round-robin slot %d → agent %d", i+1, slot),
 			})
 		}
 
@@ -1448,10 +1558,10 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 			var bestScore float64
 
 			for i := range agents {
-				if usedAgents[agents[i].pane.Index] {
+				if usedAgents[agents[i].pane.Index] || !policy.allows(bead, agents[i]) {
 					continue
 				}
-				score := assign.GetAgentScoreByString(agents[i].agentType, inferTaskTypeFromBead(bead))
+				score := policy.score(bead, agents[i], assign.GetAgentScoreByString(agents[i].agentType, inferTaskTypeFromBead(bead)))
 				if score > bestScore {
 					bestScore = score
 					bestAgent = &agents[i]
@@ -1480,10 +1590,10 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 		usedAgents := make(map[int]bool)
 		for _, bead := range beads {
 			for i := range agents {
-				if usedAgents[agents[i].pane.Index] {
+				if usedAgents[agents[i].pane.Index] || !policy.allows(bead, agents[i]) {
 					continue
 				}
-				score := (calculateMatchConfidence(agents[i].agentType, bead, "speed") + 0.9) / 2
+				score := policy.score(bead, agents[i], (calculateMatchConfidence(agents[i].agentType, bead, "speed")+0.9)/2)
 				assignments = append(assignments, AssignmentItem{
 					BeadID:     bead.ID,
 					BeadTitle:  bead.Title,
@@ -1509,7 +1619,7 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 			var bestScore float64
 
 			for i := range agents {
-				if usedAgents[agents[i].pane.Index] {
+				if usedAgents[agents[i].pane.Index] || !policy.allows(bead, agents[i]) {
 					continue
 				}
 				score := calculateMatchConfidence(agents[i].agentType, bead, "dependency")
@@ -1518,6 +1628,7 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 				if priority <= 1 {
 					score = min(score+0.1, 0.95)
 				}
+				score = policy.score(bead, agents[i], score)
 				if score > bestScore {
 					bestScore = score
 					bestAgent = &agents[i]
@@ -1567,8 +1678,11 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 			var leastRecentTime time.Time
 
 			for i := range agents {
+				if !policy.allows(bead, agents[i]) {
+					continue
+				}
 				count := agentAssignCounts[agents[i].pane.Index]
-				score := calculateMatchConfidence(agents[i].agentType, bead, "balanced")
+				score := policy.score(bead, agents[i], calculateMatchConfidence(agents[i].agentType, bead, "balanced"))
 				lastAssign := agentLastAssigned[agents[i].pane.Index]
 
 				// Tie-breaker cascade:
@@ -1627,6 +1741,7 @@ func generateAssignmentsEnhanced(agents []assignAgentInfo, beads []bv.BeadPrevie
 		}
 	}
 
+	policy.pairReviewers(assignments, agents, beads)
 	return assignments
 }
 
@@ -1687,6 +1802,9 @@ func displayAssignOutputEnhanced(out *AssignOutputEnhanced, verbose bool) {
 
 			fmt.Printf("  %s → %s %s\n", agentBadge, item.BeadID, confStr)
 			fmt.Printf("     %s\n", item.BeadTitle)
+			if r := item.Reviewer; r != nil {
+				fmt.Printf("     reviewed by %s\n", getAgentStyle(r.AgentType, th).Render(fmt.Sprintf("[%s pane %d]", r.AgentType, r.Pane)))
+			}
 			if verbose && item.Reasoning != "" {
 				fmt.Printf("     %s\n", subtitleStyle.Render(item.Reasoning))
 			}
@@ -1858,6 +1976,23 @@ func executeAssignmentsEnhanced(session string, out *AssignOutputEnhanced, opts
 		if !opts.Quiet {
 			fmt.Printf("  ✓ Assigned %s to pane %d (%s)\n", item.BeadID, item.Pane, item.AgentType)
 		}
+
+		// Brief the reviewer the policy paired with the assignment
+		if r := item.Reviewer; r != nil {
+			reviewPrompt := fmt.Sprintf("%s Pane %d (%s) is implementing it; review their changes when they finish and report problems to them.",
+				expandPromptTemplate(item.BeadID, item.BeadTitle, "review", ""), item.Pane, item.AgentType)
+			sendErr := fmt.Errorf("pane not found")
+			if reviewerID, ok := paneIDByIndex[r.Pane]; ok {
+				sendErr = sendPromptWithDoubleEnter(reviewerID, reviewPrompt)
+			}
+			if !opts.Quiet {
+				if sendErr != nil {
+					fmt.Printf("  ⚠ Failed to brief reviewer pane %d for %s: %v\n", r.Pane, item.BeadID, sendErr)
+				} else {
+					fmt.Printf("    Reviewer: pane %d (%s)\n", r.Pane, r.AgentType)
+				}
+			}
+		}
 	}
 
 	if !opts.Quiet {
@@ -3665,6 +3800,7 @@ type AutoReassignOptions struct {
 	Quiet           bool
 	Timeout         time.Duration
 	AgentTypeFilter string
+	PolicyFile      string // Lua assignment policy script
 }
 
 // AutoReassignResult contains the result of an auto-reassignment operation
@@ -3798,6 +3934,12 @@ func PerformAutoReassignment(completedBeadID string, opts *AutoReassignOptions)
 		Timeout:         opts.Timeout,
 		ReserveFiles:    opts.ReserveFiles,
 	}
+	policy, err := loadAssignPolicy(opts.PolicyFile)
+	if err != nil {
+		return result, err
+	}
+	defer policy.Close()
+	assignOpts.Policy = policy
 
 	assignments := generateAssignmentsEnhanced(idleAgents, filteredBeads, assignOpts)
 	result.Assignments = assignments
@@ -3832,12 +3974,16 @@ func getIdleAgents(session, agentTypeFilter string, verbose bool) ([]assignAgent
 	}
 
 	var idleAgents []assignAgentInfo
+	takenOver := takeover.Panes(session)
 
 	for _, pane := range panes {
 		agentType := detectAgentTypeFromTitle(pane.Title)
 		if agentType == "user" || agentType == "unknown" {
 			continue
 		}
+		if takenOver[pane.ID] != nil {
+			continue // A human has the pane
+		}
 
 		// Apply agent type filter
 		if agentTypeFilter != "" && agentType != agentTypeFilter {
@@ -3959,6 +4105,10 @@ type WatchLoop struct {
 	detector *completion.CompletionDetector
 	opts     *AutoReassignOptions
 
+	// Verification of completed tasks (nil verifier = off)
+	verifier   *verify.Runner
+	maxBounces int
+
 	// Configuration
 	stopWhenDone bool
 	delay        time.Duration
@@ -3982,7 +4132,13 @@ type WatchLoop struct {
 
 // NewWatchLoop creates a new watch loop for a session
 func NewWatchLoop(session string, store *assignment.AssignmentStore, opts *AutoReassignOptions) *WatchLoop {
+	verifyCfg := config.DefaultAssignConfig().Verify
+	if cfg != nil {
+		verifyCfg = cfg.Assign.Verify
+	}
 	return &WatchLoop{
+		verifier:     newVerifyRunner(verifyCfg, GetProjectRoot()),
+		maxBounces:   verifyCfg.MaxBounces,
 		session:      session,
 		strategy:     opts.Strategy,
 		store:        store,
@@ -3997,6 +4153,32 @@ func NewWatchLoop(session string, store *assignment.AssignmentStore, opts *AutoR
 	}
 }
 
+// workingSetSources returns the repository and reservation lookup used to
+// build assignment working sets. Reservations are nil without Agent Mail.
+func workingSetSources() (string, completion.ReservationLister) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", nil
+	}
+	amClient := agentmail.NewClient(agentmail.WithProjectKey(wd))
+	if !amClient.IsAvailable() {
+		return wd, nil
+	}
+	return wd, func(ctx context.Context, agentName string) ([]string, error) {
+		reservations, err := amClient.ListReservations(ctx, wd, agentName, false)
+		if err != nil {
+			return nil, err
+		}
+		var patterns []string
+		for _, r := range reservations {
+			if r.AgentName == agentName && r.ReleasedTS == nil {
+				patterns = append(patterns, r.PathPattern)
+			}
+		}
+		return patterns, nil
+	}
+}
+
 // logf prints a timestamped log message
 func (w *WatchLoop) logf(format string, args ...interface{}) {
 	if w.quiet {
@@ -4019,8 +4201,11 @@ func (w *WatchLoop) Run(ctx context.Context) error {
 		DedupWindow:       5 * time.Second,
 		GracefulDegrading: true,
 		CaptureLines:      50,
+		QuietPeriods:      completion.DefaultQuietPeriods(),
 	}
 	w.detector = completion.NewWithConfig(w.session, w.store, detectorCfg)
+	w.detector.RepoDir, w.detector.Reservations = workingSetSources()
+	w.detector.VerifyCompletions = w.verifier != nil
 
 	// Start watching for completions
 	watchCtx, watchCancel := context.WithCancel(ctx)
@@ -4054,7 +4239,7 @@ func (w *WatchLoop) Run(ctx context.Context) error {
 				return nil
 			}
 
-			if err := w.handleCompletion(event); err != nil {
+			if err := w.handleCompletion(ctx, event); err != nil {
 				w.logf("Error handling completion: %v", err)
 			}
 
@@ -4078,18 +4263,42 @@ func (w *WatchLoop) Run(ctx context.Context) error {
 }
 
 // handleCompletion processes a single completion event
-func (w *WatchLoop) handleCompletion(event completion.CompletionEvent) error {
+func (w *WatchLoop) handleCompletion(ctx context.Context, event completion.CompletionEvent) error {
+	if event.Candidate {
+		w.logCandidate(event)
+	}
+
+	// Verification runs before taking the lock; it can take minutes.
+	outcome := ""
+	if w.verifier != nil && !event.IsFailed {
+		var err error
+		if outcome, err = w.verifyCompletion(ctx, event); err != nil {
+			return err
+		}
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
 	duration := event.Duration.Round(time.Second)
 
-	if event.IsFailed {
+	if event.IsFailed || outcome == verifyFailed {
 		w.totalFailed++
-		w.logf("Failed: %s by pane %d (%s) - %s", event.BeadID, event.Pane, event.AgentType, event.FailReason)
+		if event.IsFailed {
+			w.logf("Failed: %s by pane %d (%s) - %s", event.BeadID, event.Pane, event.AgentType, event.FailReason)
+		}
+		return nil
+	}
+	if outcome == verifyBounced {
 		return nil
 	}
 
+	if event.Candidate && w.verifier == nil {
+		if err := w.store.MarkCompleted(event.BeadID); err != nil {
+			return err
+		}
+	}
+
 	w.totalCompleted++
 	w.logf("Completion: %s by pane %d (%s, %v)", event.BeadID, event.Pane, event.AgentType, duration)
 
@@ -4103,6 +4312,11 @@ func (w *WatchLoop) handleCompletion(event completion.CompletionEvent) error {
 		}
 	}
 
+	if windingDown(w.session) {
+		w.logf("Session is winding down; not assigning new work")
+		return nil
+	}
+
 	// Perform auto-reassignment if enabled
 	if assignAutoReassign {
 		result, err := PerformAutoReassignment(event.BeadID, w.opts)
@@ -4141,6 +4355,18 @@ func (w *WatchLoop) handleCompletion(event completion.CompletionEvent) error {
 	return nil
 }
 
+// logCandidate logs a candidate completion, which the detector proposes
+// when an agent shows its done signals and goes quiet instead of waiting out
+// the idle timeout. Without verification the assignment stays active until
+// handleCompletion accepts it.
+func (w *WatchLoop) logCandidate(event completion.CompletionEvent) {
+	kinds := make([]string, 0, len(event.Signals))
+	for _, s := range event.Signals {
+		kinds = append(kinds, string(s.Kind))
+	}
+	w.logf("Candidate complete: %s by pane %d (%s: %s)", event.BeadID, event.Pane, event.AgentType, strings.Join(kinds, ", "))
+}
+
 // shouldStop checks if watch mode should exit
 func (w *WatchLoop) shouldStop() bool {
 	// Check if there are any active assignments
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+)
+
+// defaultAssignPolicy is the project's policy script, used when neither
+// --policy nor [assign] script names one.
+const defaultAssignPolicy = ".ntm/assign.lua"
+
+// loadAssignPolicy loads the Lua assignment policy: path if set, else the
+// configured [assign] script, else the project's .ntm/assign.lua when it
+// exists. Relative paths resolve against the project root. It returns nil
+// when there is no policy.
+func loadAssignPolicy(path string) (*assign.PolicyScript, error) {
+	root := GetProjectRoot()
+	if path == "" && cfg != nil {
+		path = cfg.Assign.Script
+	}
+	if path == "" {
+		if root == "" {
+			return nil, nil
+		}
+		path = filepath.Join(root, defaultAssignPolicy)
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil
+		}
+	} else if !filepath.IsAbs(path) && root != "" {
+		path = filepath.Join(root, path)
+	}
+	return assign.LoadPolicyScript(path, assign.PolicyOptions{Owner: pathOwner})
+}
+
+// pathOwner returns the owner of path under the [file_reservation]
+// ownership patterns, the longest matching pattern winning.
+func pathOwner(path string) string {
+	if cfg == nil || path == "" {
+		return ""
+	}
+	owner, longest := "", -1
+	for pattern, who := range cfg.FileReservation.Ownership {
+		if assignment.MatchPath(path, pattern) && len(pattern) > longest {
+			owner, longest = who, len(pattern)
+		}
+	}
+	return owner
+}
+
+// assignPolicy applies a policy script to one round of assignment. A nil
+// assignPolicy allows every pairing and keeps every score.
+type assignPolicy struct {
+	script   *assign.PolicyScript
+	opts     *AssignCommandOptions
+	workload map[int]int // Active assignments by pane
+}
+
+func newAssignPolicy(opts *AssignCommandOptions) *assignPolicy {
+	if opts.Policy == nil {
+		return nil
+	}
+	p := &assignPolicy{script: opts.Policy, opts: opts, workload: make(map[int]int)}
+	if opts.Session != "" {
+		if store, err := assignment.LoadStore(opts.Session); err == nil && store != nil {
+			for _, a := range store.ListActive() {
+				p.workload[a.Pane]++
+			}
+		}
+	}
+	return p
+}
+
+func (p *assignPolicy) bead(b bv.BeadPreview) assign.PolicyBead {
+	return assign.PolicyBead{
+		ID:       b.ID,
+		Title:    b.Title,
+		Priority: parsePriorityString(b.Priority),
+		TaskType: inferTaskTypeFromBead(b),
+	}
+}
+
+func (p *assignPolicy) agent(a assignAgentInfo, b bv.BeadPreview) assign.PolicyAgent {
+	taskType := inferTaskTypeFromBead(b)
+	bonus, _ := assign.GetEffectivenessBonus(a.agentType, taskType)
+	return assign.PolicyAgent{
+		Pane:          a.pane.Index,
+		Type:          a.agentType,
+		Name:          assignmentAgentName(p.opts.Session, a.agentType, a.pane.Index),
+		Model:         a.model,
+		Workload:      p.workload[a.pane.Index],
+		Capability:    assign.GetAgentScoreByString(a.agentType, taskType),
+		Effectiveness: bonus,
+	}
+}
+
+// warn reports a failing policy script. Failures never go unnoticed: a
+// broken allow() denies the pairing rather than ignoring the policy.
+func (p *assignPolicy) warn(err error) {
+	if !p.opts.Quiet {
+		fmt.Fprintf(os.Stderr, "[POLICY] %v\n", err)
+	}
+}
+
+// allows reports whether the policy lets agent take bead.
+func (p *assignPolicy) allows(b bv.BeadPreview, a assignAgentInfo) bool {
+	if p == nil {
+		return true
+	}
+	ok, reason, err := p.script.Allow(p.bead(b), p.agent(a, b))
+	if err != nil {
+		p.warn(err)
+		return false
+	}
+	if !ok && p.opts.Verbose {
+		if reason == "" {
+			reason = "denied by policy"
+		}
+		fmt.Fprintf(os.Stderr, "[POLICY] %s not for pane %d (%s): %s\n", b.ID, a.pane.Index, a.agentType, reason)
+	}
+	return ok
+}
+
+// score returns the policy's score for agent taking bead, given the
+// strategy's base score.
+func (p *assignPolicy) score(b bv.BeadPreview, a assignAgentInfo, base float64) float64 {
+	if p == nil {
+		return base
+	}
+	score, err := p.script.Score(p.bead(b), p.agent(a, b), base)
+	if err != nil {
+		p.warn(err)
+		return base
+	}
+	return score
+}
+
+// pairReviewers asks the policy for a reviewer for each assignment, drawn
+// from the agents that got no assignment. Each agent reviews at most one.
+func (p *assignPolicy) pairReviewers(items []AssignmentItem, agents []assignAgentInfo, beads []bv.BeadPreview) {
+	if p == nil || !p.script.Has("reviewer") {
+		return
+	}
+	busy := make(map[int]bool)
+	for _, item := range items {
+		busy[item.Pane] = true
+	}
+	beadByID := make(map[string]bv.BeadPreview, len(beads))
+	for _, b := range beads {
+		beadByID[b.ID] = b
+	}
+	agentByPane := make(map[int]assignAgentInfo, len(agents))
+	for _, a := range agents {
+		agentByPane[a.pane.Index] = a
+	}
+
+	for i := range items {
+		b := beadByID[items[i].BeadID]
+		var candidates []assign.PolicyAgent
+		for _, a := range agents {
+			if !busy[a.pane.Index] {
+				candidates = append(candidates, p.agent(a, b))
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+		rev, err := p.script.Reviewer(p.bead(b), p.agent(agentByPane[items[i].Pane], b), candidates)
+		if err != nil {
+			p.warn(err)
+			continue
+		}
+		if rev == nil {
+			continue
+		}
+		items[i].Reviewer = &AssignmentReviewer{Pane: rev.Pane, AgentType: rev.Type, AgentName: rev.Name}
+		busy[rev.Pane] = true
+	}
+}
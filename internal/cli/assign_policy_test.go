@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+)
+
+const testAssignPolicy = `
+function allow(bead, agent)
+  return not (bead.title:lower():find("migration") and agent.type == "gemini"), "no migrations for gemini"
+end
+
+function score(bead, agent, base)
+  if agent.type == "codex" then return base + 1 end
+end
+
+function reviewer(bead, agent, candidates)
+  if bead.task_type == "refactor" then return "gemini" end
+end
+`
+
+func newTestAssignPolicy(t *testing.T) *assign.PolicyScript {
+	t.Helper()
+	p, err := assign.NewPolicyScript("assign.lua", testAssignPolicy, assign.PolicyOptions{})
+	if err != nil {
+		t.Fatalf("NewPolicyScript: %v", err)
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+func TestGenerateAssignmentsEnhanced_Policy(t *testing.T) {
+	t.Parallel()
+	policy := newTestAssignPolicy(t)
+
+	for _, strategy := range []string{"round-robin", "quality", "speed", "dependency", "balanced"} {
+		agents := []assignAgentInfo{makeTestAgent(0, "gemini"), makeTestAgent(1, "claude")}
+		beads := []bv.BeadPreview{makeTestBead("b1", "Add migration for users", "P1")}
+		got := generateAssignmentsEnhanced(agents, beads, &AssignCommandOptions{Strategy: strategy, Policy: policy, Quiet: true})
+		if len(got) != 1 || got[0].Pane != 1 {
+			t.Errorf("%s: assignments = %+v, want the migration on pane 1", strategy, got)
+		}
+
+		// No agent the policy allows leaves the bead unassigned.
+		got = generateAssignmentsEnhanced(agents[:1], beads, &AssignCommandOptions{Strategy: strategy, Policy: policy, Quiet: true})
+		if len(got) != 0 {
+			t.Errorf("%s: assignments = %+v, want none", strategy, got)
+		}
+	}
+
+	// Scores from the policy drive the choice.
+	agents := []assignAgentInfo{makeTestAgent(0, "claude"), makeTestAgent(1, "codex")}
+	beads := []bv.BeadPreview{makeTestBead("b1", "Analyze the parser", "P2")}
+	got := generateAssignmentsEnhanced(agents, beads, &AssignCommandOptions{Strategy: "quality", Policy: policy})
+	if len(got) != 1 || got[0].Pane != 1 || got[0].Score <= 1 {
+		t.Errorf("quality with score(): assignments = %+v, want codex on pane 1", got)
+	}
+}
+
+func TestGenerateAssignmentsEnhanced_PolicyReviewer(t *testing.T) {
+	t.Parallel()
+	policy := newTestAssignPolicy(t)
+
+	agents := []assignAgentInfo{makeTestAgent(0, "claude"), makeTestAgent(1, "gemini"), makeTestAgent(2, "gemini")}
+	beads := []bv.BeadPreview{
+		makeTestBead("b1", "Refactor the parser", "P1"),
+		makeTestBead("b2", "Refactor the lexer", "P1"),
+	}
+	got := generateAssignmentsEnhanced(agents, beads, &AssignCommandOptions{Strategy: "quality", Policy: policy})
+	if len(got) != 2 {
+		t.Fatalf("assignments = %+v, want 2", got)
+	}
+	// Three agents and two beads leave one agent to review one of them.
+	reviewed := 0
+	for _, item := range got {
+		if r := item.Reviewer; r != nil {
+			reviewed++
+			if r.AgentType != "gemini" || r.Pane == got[0].Pane || r.Pane == got[1].Pane {
+				t.Errorf("reviewer = %+v, want the idle gemini agent", r)
+			}
+		}
+	}
+	if reviewed != 1 {
+		t.Errorf("reviewed %d assignments, want 1", reviewed)
+	}
+
+	// Without a policy there are no reviewers.
+	for _, item := range generateAssignmentsEnhanced(agents, beads, &AssignCommandOptions{Strategy: "quality"}) {
+		if item.Reviewer != nil {
+			t.Errorf("reviewer without policy: %+v", item.Reviewer)
+		}
+	}
+}
+
+func TestLoadAssignPolicy(t *testing.T) {
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = config.Default()
+	cfg.FileReservation.Ownership = map[string]string{"web/**": "frontend", "web/admin/**": "BlueLake"}
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if p, err := loadAssignPolicy(""); err != nil || p != nil {
+		t.Fatalf("loadAssignPolicy without script = %v, %v; want none", p, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".ntm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	src := `function allow(bead, agent) return (ntm.owner(bead.title) or "") == agent.name end`
+	if err := os.WriteFile(filepath.Join(dir, defaultAssignPolicy), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := loadAssignPolicy("")
+	if err != nil || p == nil {
+		t.Fatalf("loadAssignPolicy = %v, %v; want .ntm/assign.lua", p, err)
+	}
+	defer p.Close()
+	for path, want := range map[string]string{"web/admin/users.ts": "BlueLake", "web/app.ts": "frontend", "cmd/main.go": ""} {
+		ok, _, err := p.Allow(assign.PolicyBead{Title: path}, assign.PolicyAgent{Name: want})
+		if err != nil || !ok {
+			t.Errorf("owner of %s: want %q (%v)", path, want, err)
+		}
+	}
+
+	cfg.Assign.Script = "missing.lua"
+	if _, err := loadAssignPolicy(""); err == nil {
+		t.Error("missing configured script not reported")
+	}
+}
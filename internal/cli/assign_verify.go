@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+	"github.com/Dicklesworthstone/ntm/internal/completion"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/exchange"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/takeover"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/verify"
+)
+
+// newVerifyRunner builds the verification runner from [assign.verify], or
+// returns nil when verification is off.
+func newVerifyRunner(vc config.AssignVerifyConfig, dir string) *verify.Runner {
+	if !vc.Enabled || len(vc.Commands) == 0 {
+		return nil
+	}
+	checks := make([]verify.Check, 0, len(vc.Commands))
+	for i, c := range vc.Commands {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("check%d", i+1)
+		}
+		checks = append(checks, verify.Check{Name: name, Command: c.Run})
+	}
+	return &verify.Runner{
+		Dir:         dir,
+		Checks:      checks,
+		Timeout:     time.Duration(vc.TimeoutSeconds) * time.Second,
+		OutputLines: vc.OutputLines,
+
+		Retries:         vc.Retries,
+		QuarantinePath:  verify.QuarantinePath(dir),
+		QuarantineAfter: vc.QuarantineAfter,
+	}
+}
+
+// Verification outcomes for a completed task.
+const (
+	verifyPassed  = "passed"
+	verifyBounced = "bounced"
+	verifyFailed  = "failed"
+)
+
+// verifyCompletion runs the verification commands for a task its agent
+// finished. A pass completes the assignment; a failure is sent back to the
+// agent with the failing output until max bounces is reached, then the
+// assignment fails.
+func (w *WatchLoop) verifyCompletion(ctx context.Context, event completion.CompletionEvent) (string, error) {
+	w.logf("Verifying: %s by pane %d (%d checks)", event.BeadID, event.Pane, len(w.verifier.Checks))
+	result := w.verifier.Run(ctx)
+	w.logFlakes(event.BeadID, result)
+	if err := w.store.RecordVerification(event.BeadID, result); err != nil {
+		return "", err
+	}
+
+	a := w.store.Get(event.BeadID)
+	bounces := 0
+	if a != nil && a.Verification != nil {
+		bounces = a.Verification.Bounces
+	}
+
+	if result.Passed {
+		recordVerificationScore(w.session, a, result, bounces)
+		w.logf("Verified: %s passed %d checks", event.BeadID, len(result.Checks))
+		return verifyPassed, w.store.MarkCompleted(event.BeadID)
+	}
+
+	if bounces < w.maxBounces {
+		n, err := w.store.Bounce(event.BeadID)
+		if err != nil {
+			return "", err
+		}
+		w.logf("Verification failed: %s (%s); sending back to pane %d (bounce %d/%d)",
+			event.BeadID, result.FailedNames(), event.Pane, n, w.maxBounces)
+		reopenBead(event.BeadID)
+		if err := w.sendBounce(event, result.BouncePrompt(event.BeadID)); err != nil {
+			w.logf("Warning: could not send failures to pane %d: %v", event.Pane, err)
+		}
+		return verifyBounced, nil
+	}
+
+	recordVerificationScore(w.session, a, result, bounces)
+	reason := fmt.Sprintf("verification failed: %s", result.FailedNames())
+	w.logf("Verification failed: %s (%s) after %d bounces", event.BeadID, result.FailedNames(), bounces)
+	return verifyFailed, w.store.MarkFailed(event.BeadID, reason)
+}
+
+// logFlakes reports flaky and quarantined checks, which do not count
+// against the agent.
+func (w *WatchLoop) logFlakes(beadID string, result *verify.Result) {
+	for _, c := range result.Flaky() {
+		switch {
+		case c.Quarantined && !c.Flaky:
+			w.logf("Quarantined check failed: %s on %s (ignored)", c.Name, beadID)
+		case c.Passed:
+			w.logf("Flaky: %s on %s passed on attempt %d", c.Name, beadID, c.Attempts)
+		default:
+			w.logf("Flaky: %s on %s failed differently on retry", c.Name, beadID)
+		}
+	}
+	for _, name := range result.NewlyQuarantined {
+		w.logf("Quarantined: %s (see ntm flaky)", name)
+	}
+	for _, warning := range result.Warnings {
+		w.logf("Warning: %s", warning)
+	}
+}
+
+// sendBounce types the failing verification output into the agent's pane,
+// unless a human has taken it over.
+func (w *WatchLoop) sendBounce(event completion.CompletionEvent, prompt string) error {
+	panes, err := tmux.GetPanes(w.session)
+	if err != nil {
+		return err
+	}
+	for _, p := range panes {
+		if p.Index == event.Pane {
+			if s := takeover.Panes(w.session)[p.ID]; s != nil {
+				return fmt.Errorf("pane is taken over by %s", s.By)
+			}
+			return sendPromptWithDoubleEnter(p.ID, prompt)
+		}
+	}
+	return fmt.Errorf("pane %d not found", event.Pane)
+}
+
+// reopenBead moves a bounced bead back to in progress, since the agent may
+// have closed it (stubbed in tests).
+var reopenBead = func(beadID string) {
+	if wd, err := os.Getwd(); err == nil {
+		_, _ = bv.RunBd(wd, "update", beadID, "--status", "in_progress")
+	}
+}
+
+// recordScore is the effectiveness tracker's Record (stubbed in tests).
+var recordScore = func(score *scoring.Score) error {
+	return scoring.DefaultTracker().Record(score)
+}
+
+// exchangeStore opens the prompt exchange store (stubbed in tests).
+var exchangeStore = func() *exchange.Store { return exchange.NewStore("") }
+
+// responseEfficiency scores how quickly the assignment's pane answered the
+// prompts sent during the task, against the session's median response
+// latency. It returns 0 when no exchanges were paired for the pane.
+func responseEfficiency(session string, a *assignment.Assignment, w scoring.Weights) float64 {
+	exchanges, err := exchangeStore().Query(exchange.Query{Session: session})
+	if err != nil {
+		return 0
+	}
+	pane := strconv.Itoa(a.Pane)
+	var own []exchange.PromptExchange
+	for _, ex := range exchanges {
+		if ex.Pane == pane && (a.StartedAt == nil || ex.SentAt.After(*a.StartedAt)) {
+			own = append(own, ex)
+		}
+	}
+	if len(own) == 0 {
+		return 0
+	}
+	raw := scoring.RawMetrics{BaselineLatencyMs: int(exchange.BaselineLatencyMs(exchanges))}
+	for _, sum := range exchange.Summarize(own) {
+		sum.ApplyTo(&raw)
+	}
+	return raw.ToEffectivenessScore(w).TimeEfficiency
+}
+
+// recordVerificationScore records the verified outcome of a task with the
+// effectiveness tracker. Quality and efficiency are scored with the weights
+// in scoring.DefaultWeightsPath.
+func recordVerificationScore(session string, a *assignment.Assignment, result *verify.Result, bounces int) {
+	if a == nil {
+		return
+	}
+	metrics := scoring.ScoreMetrics{
+		Quality:    result.PassRate(),
+		ErrorCount: result.Errors(),
+	}
+	if result.Passed {
+		metrics.Completion = 1
+	}
+	if a.StartedAt != nil {
+		metrics.DurationMinutes = int(time.Since(*a.StartedAt).Minutes())
+	}
+	weights, err := scoring.LoadDefaultWeightsConfig()
+	if err != nil {
+		weights = &scoring.WeightsConfig{Default: scoring.DefaultWeights()}
+	}
+	taskType := inferTaskTypeFromBead(bv.BeadPreview{ID: a.BeadID, Title: a.BeadTitle})
+	metrics.Efficiency = responseEfficiency(session, a, weights.GetWeights(taskType))
+	signals := scoring.NewQualitySignals()
+	signals.TestsAfter = result.PassRate()
+	scoring.NewQualityScorer(weights.QualityWeights).Evaluate(context.Background(), signals).ApplyTo(&metrics)
+	var flaky []string
+	for _, c := range result.Flaky() {
+		flaky = append(flaky, c.Name)
+	}
+	_ = recordScore(&scoring.Score{
+		Session:   session,
+		AgentType: a.AgentType,
+		AgentName: a.AgentName,
+		TaskType:  taskType,
+		BeadID:    a.BeadID,
+		Metrics:   metrics,
+		Human:     a.HumanTakeover,
+		Context: map[string]interface{}{
+			"verification": result.Checks,
+			"bounces":      bounces,
+			"flaky":        flaky,
+		},
+	})
+}
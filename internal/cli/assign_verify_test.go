@@ -0,0 +1,125 @@
+//go:build unix
+
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/completion"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/exchange"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/verify"
+)
+
+func TestNewVerifyRunner(t *testing.T) {
+	if r := newVerifyRunner(config.DefaultAssignConfig().Verify, "/repo"); r != nil {
+		t.Errorf("expected no runner when disabled, got %+v", r)
+	}
+	r := newVerifyRunner(config.AssignVerifyConfig{
+		Enabled:        true,
+		Commands:       []config.AssignVerifyCommand{{Name: "test", Run: "go test ./..."}, {Run: "make lint"}},
+		TimeoutSeconds: 30,
+	}, "/repo")
+	if r == nil || len(r.Checks) != 2 || r.Dir != "/repo" {
+		t.Fatalf("runner = %+v", r)
+	}
+	if r.Checks[1].Name != "check2" {
+		t.Errorf("unnamed check = %q, want check2", r.Checks[1].Name)
+	}
+}
+
+func TestWatchLoopVerifyCompletion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var scores []*scoring.Score
+	origScore, origReopen := recordScore, reopenBead
+	recordScore = func(s *scoring.Score) error { scores = append(scores, s); return nil }
+	reopenBead = func(string) {}
+	t.Cleanup(func() { recordScore, reopenBead = origScore, origReopen })
+
+	store := assignment.NewStore("verify-test")
+	_, _ = store.Assign("bd-1", "Fix parser", 1, "claude", "", "")
+	_ = store.MarkWorking("bd-1")
+	_ = store.MarkVerifying("bd-1")
+
+	w := &WatchLoop{
+		session:    "verify-test-no-such-session",
+		store:      store,
+		quiet:      true,
+		maxBounces: 1,
+		verifier:   &verify.Runner{Dir: t.TempDir(), Checks: []verify.Check{{Name: "test", Command: "echo FAIL; exit 1"}}},
+	}
+	event := completion.CompletionEvent{BeadID: "bd-1", Pane: 1, AgentType: "claude"}
+
+	outcome, err := w.verifyCompletion(context.Background(), event)
+	if err != nil || outcome != verifyBounced {
+		t.Fatalf("first failure = %q, %v; want bounced", outcome, err)
+	}
+	if a := store.Get("bd-1"); a.Status != assignment.StatusWorking || a.Verification.Bounces != 1 {
+		t.Fatalf("after bounce: status %s, verification %+v", a.Status, a.Verification)
+	}
+	if len(scores) != 0 {
+		t.Errorf("bounce should not record a score, got %d", len(scores))
+	}
+
+	_ = store.MarkVerifying("bd-1")
+	outcome, err = w.verifyCompletion(context.Background(), event)
+	if err != nil || outcome != verifyFailed {
+		t.Fatalf("second failure = %q, %v; want failed", outcome, err)
+	}
+	a := store.Get("bd-1")
+	if a.Status != assignment.StatusFailed || a.FailReason != "verification failed: test" {
+		t.Errorf("status %s reason %q", a.Status, a.FailReason)
+	}
+	if len(scores) != 1 || scores[0].Metrics.Completion != 0 || scores[0].Metrics.ErrorCount != 1 || scores[0].TaskType != "bug" {
+		t.Fatalf("scores = %+v", scores)
+	}
+
+	_, _ = store.Assign("bd-2", "Add loader", 2, "codex", "", "")
+	_ = store.MarkWorking("bd-2")
+	_ = store.MarkVerifying("bd-2")
+	w.verifier.Checks = []verify.Check{{Name: "build", Command: "true"}}
+	event = completion.CompletionEvent{BeadID: "bd-2", Pane: 2, AgentType: "codex"}
+	if outcome, err := w.verifyCompletion(context.Background(), event); err != nil || outcome != verifyPassed {
+		t.Fatalf("pass = %q, %v", outcome, err)
+	}
+	if a := store.Get("bd-2"); a.Status != assignment.StatusCompleted || !a.Verification.Passed {
+		t.Errorf("status %s, verification %+v", a.Status, a.Verification)
+	}
+	if len(scores) != 2 || scores[1].Metrics.Completion != 1 || scores[1].Metrics.Quality != 1 {
+		t.Errorf("pass score = %+v", scores[len(scores)-1].Metrics)
+	}
+}
+
+func TestResponseEfficiency(t *testing.T) {
+	store := exchange.NewStore(filepath.Join(t.TempDir(), "exchanges.jsonl"))
+	orig := exchangeStore
+	exchangeStore = func() *exchange.Store { return store }
+	t.Cleanup(func() { exchangeStore = orig })
+
+	started := time.Now().Add(-time.Hour)
+	add := func(pane string, at time.Time, latencyMs int64) {
+		out := at.Add(time.Duration(latencyMs) * time.Millisecond)
+		if err := store.Append(exchange.PromptExchange{Session: "proj", Pane: pane, AgentType: "cc",
+			SentAt: at, FirstOutputAt: &out, LatencyMs: latencyMs, Outcome: exchange.OutcomeCompleted}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("1", started.Add(-time.Minute), 1000) // before the task started
+	add("1", started.Add(time.Minute), 4000)
+	add("2", started.Add(time.Minute), 2000)
+	add("3", started.Add(time.Minute), 2000)
+
+	a := &assignment.Assignment{Pane: 1, StartedAt: &started}
+	if got := responseEfficiency("proj", a, scoring.DefaultWeights()); got != 0.5 {
+		t.Errorf("efficiency = %v, want 0.5 (median 2000ms / 4000ms)", got)
+	}
+	if got := responseEfficiency("proj", &assignment.Assignment{Pane: 4}, scoring.DefaultWeights()); got != 0 {
+		t.Errorf("efficiency without exchanges = %v, want 0", got)
+	}
+}
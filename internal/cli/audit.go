@@ -29,7 +29,8 @@ Examples:
   ntm audit search "spawn"                  # Search all logs
   ntm audit search --type=error --days=7    # Errors in last week
   ntm audit verify myproject                # Verify log integrity
-  ntm audit export myproject --format=json  # Export session log`,
+  ntm audit export myproject --format=json  # Export session log
+  ntm audit ship                            # Upload finished logs off-host`,
 	}
 
 	cmd.AddCommand(
@@ -38,6 +39,7 @@ Examples:
 		newAuditVerifyCmd(),
 		newAuditExportCmd(),
 		newAuditListCmd(),
+		newAuditShipCmd(),
 	)
 
 	return cmd
@@ -443,13 +445,17 @@ func renderAuditEntries(result *audit.QueryResult) error {
 	return nil
 }
 
-// parseTimeArg parses a time argument that can be RFC3339 or a relative duration.
+// parseTimeArg parses a time argument that can be RFC3339, a date
+// (2006-01-02, local midnight), or a relative duration.
 // Relative durations: "1h" (1 hour ago), "7d" (7 days ago), "30m" (30 minutes ago).
 func parseTimeArg(s string) (time.Time, error) {
 	// Try RFC3339 first
 	if t, err := time.Parse(time.RFC3339, s); err == nil {
 		return t, nil
 	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
 
 	// Try relative duration
 	s = strings.TrimSpace(s)
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+)
+
+func newAuditShipCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "ship",
+		Short: "Upload finished audit logs to the configured sink",
+		Long: `Upload finished audit logs to the off-host sink in [audit.sink].
+
+Each session's log for a finished day is uploaded once, followed by an
+integrity manifest: its SHA-256, entry count, sequence range, chain head,
+and whether its hash chain verified, signed with the audit signing key
+(~/.config/ntm/audit-signing.key). A log that changes after shipping, e.g.
+after ntm purge, is uploaded again under a new name; earlier copies are
+never overwritten. Today's log is shipped the day after.
+
+ntm monitor ships on its own every interval_minutes.
+
+Examples:
+  ntm audit ship             # Upload what is due
+  ntm audit ship --dry-run   # Show what would be uploaded`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAuditShip(cmd.Context(), dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be uploaded without uploading")
+	return cmd
+}
+
+func auditSinkConfig() config.AuditSinkConfig {
+	if cfg != nil {
+		return cfg.Audit.Sink
+	}
+	return config.DefaultAuditConfig().Sink
+}
+
+// newAuditSink builds the configured sink, reading its credentials from the
+// environment.
+func newAuditSink(sc config.AuditSinkConfig) (audit.Sink, error) {
+	var accessEnv, secretEnv string
+	switch sc.Type {
+	case "s3":
+		accessEnv, secretEnv = "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"
+	case "gcs":
+		accessEnv, secretEnv = "GCS_HMAC_ACCESS_ID", "GCS_HMAC_SECRET"
+	}
+	if sc.AccessKeyEnv != "" {
+		accessEnv = sc.AccessKeyEnv
+	}
+	if sc.SecretKeyEnv != "" {
+		secretEnv = sc.SecretKeyEnv
+	}
+	opts := audit.SinkOptions{
+		Type:     sc.Type,
+		Bucket:   sc.Bucket,
+		Region:   sc.Region,
+		Endpoint: sc.Endpoint,
+		Dir:      sc.Dir,
+	}
+	if accessEnv != "" {
+		opts.AccessKey = os.Getenv(accessEnv)
+		opts.SecretKey = os.Getenv(secretEnv)
+	}
+	if sc.Type == "s3" {
+		opts.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	sink, err := audit.NewSink(opts)
+	if err != nil && accessEnv != "" && opts.AccessKey == "" {
+		return nil, fmt.Errorf("%w (set %s and %s)", err, accessEnv, secretEnv)
+	}
+	return sink, err
+}
+
+// shipAudit uploads due audit segments to the configured sink.
+func shipAudit(ctx context.Context, dryRun bool) (*audit.ShipResult, error) {
+	sc := auditSinkConfig()
+	sink, err := newAuditSink(sc)
+	if err != nil {
+		return nil, err
+	}
+	searcher, err := newAuditSearcherFunc()
+	if err != nil {
+		return nil, err
+	}
+	key, err := audit.LoadSigningKey("")
+	if err != nil {
+		return nil, err
+	}
+	return audit.Ship(ctx, sink, audit.ShipOptions{
+		AuditDir: searcher.AuditDir(),
+		Prefix:   sc.Prefix,
+		Key:      key,
+		DryRun:   dryRun,
+	})
+}
+
+// AuditShipResponse is the JSON output for ntm audit ship.
+type AuditShipResponse struct {
+	output.TimestampedResponse
+	*audit.ShipResult
+}
+
+func runAuditShip(ctx context.Context, dryRun bool) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	res, err := shipAudit(ctx, dryRun)
+	if res != nil {
+		if IsJSONOutput() {
+			if jerr := output.PrintJSON(AuditShipResponse{TimestampedResponse: output.NewTimestamped(), ShipResult: res}); jerr != nil {
+				return jerr
+			}
+		} else {
+			verb := "Shipped"
+			if dryRun {
+				verb = "Would ship"
+			}
+			for _, s := range res.Shipped {
+				fmt.Printf("%s %s -> %s/%s\n", verb, s.File, res.Sink, s.Object)
+			}
+			if len(res.Shipped) == 0 && err == nil {
+				fmt.Println("Nothing to ship")
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("audit ship: %w", err)
+	}
+	return nil
+}
+
+// startAuditShipper ships finished audit segments now and then every
+// interval_minutes until ctx is done, when a sink is configured.
+func startAuditShipper(ctx context.Context) {
+	sc := auditSinkConfig()
+	if sc.Type == "" || sc.IntervalMinutes <= 0 {
+		return
+	}
+	ship := func() {
+		res, err := shipAudit(ctx, false)
+		if err != nil {
+			slog.Warn("audit ship failed", "sink", sc.Type, "error", err)
+		}
+		if res != nil && len(res.Shipped) > 0 {
+			slog.Info("audit shipped", "sink", res.Sink, "segments", len(res.Shipped))
+		}
+	}
+	go func() {
+		ship()
+		ticker := time.NewTicker(time.Duration(sc.IntervalMinutes) * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ship()
+			}
+		}
+	}()
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/config"
 )
 
 // --- Pure function tests (safe to run in parallel) ---
@@ -30,6 +31,19 @@ func TestParseTimeArg_RFC3339(t *testing.T) {
 	}
 }
 
+func TestParseTimeArg_Date(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseTimeArg("2026-01-15")
+	if err != nil {
+		t.Fatalf("parseTimeArg(2026-01-15) error: %v", err)
+	}
+	want := time.Date(2026, 1, 15, 0, 0, 0, 0, time.Local)
+	if !got.Equal(want) {
+		t.Errorf("parseTimeArg(2026-01-15) = %v, want %v", got, want)
+	}
+}
+
 func TestParseTimeArg_RelativeMinutes(t *testing.T) {
 	t.Parallel()
 
@@ -398,3 +412,19 @@ func writeTestAuditLog(t *testing.T, dir, session string, count int) {
 		fmt.Fprintln(f, string(line))
 	}
 }
+
+func TestNewAuditSinkCredentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AUDIT_KEY", "AKID")
+	t.Setenv("AUDIT_SECRET", "secret")
+
+	_, err := newAuditSink(config.AuditSinkConfig{Type: "s3", Bucket: "trail"})
+	if err == nil || !strings.Contains(err.Error(), "AWS_ACCESS_KEY_ID") {
+		t.Errorf("missing credentials error = %v", err)
+	}
+
+	sink, err := newAuditSink(config.AuditSinkConfig{Type: "s3", Bucket: "trail", AccessKeyEnv: "AUDIT_KEY", SecretKeyEnv: "AUDIT_SECRET"})
+	if err != nil || sink.Name() != "s3://trail" {
+		t.Errorf("newAuditSink = %v, %v", sink, err)
+	}
+}
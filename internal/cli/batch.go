@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/batch"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newBatchCmd() *cobra.Command {
+	var (
+		planFile     string
+		headless     bool
+		timeout      string
+		artifactsDir string
+		keepSession  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "batch --plan <plan.yaml>",
+		Short: "Run a task plan to completion without attaching (for CI)",
+		Long: `Spawn agents, run a plan's tasks to completion or timeout, collect
+artifacts, and tear the session down. Nothing attaches to tmux, so batch runs
+in CI jobs such as GitHub Actions.
+
+The plan names the agents and the tasks; tasks are pipeline steps (see
+'ntm pipeline run'), inline or from a workflow file:
+
+  name: nightly-cleanup
+  agents: {cc: 2, cod: 1}
+  timeout: 45m
+  tasks:
+    - id: lint
+      prompt: Fix all lint warnings in internal/
+      timeout: 15m
+    - id: tests
+      depends_on: [lint]
+      prompt: Make 'go test ./...' pass
+
+The report (report.json, in the e2e integration report format), pane
+captures, and a session summary are written to the artifacts directory.
+Under GitHub Actions the report is added to the job summary and the
+'report', 'artifacts_dir', and 'failed' step outputs are set. The command
+exits non-zero when any phase fails.
+
+Examples:
+  ntm batch --plan plan.yaml --headless              # CI: JSON report on stdout
+  ntm batch --plan plan.yaml --timeout 1h            # Override the plan timeout
+  ntm batch --plan plan.yaml --artifacts out/ntm     # Custom artifacts directory
+  ntm batch --plan plan.yaml --keep-session          # Leave the session for inspection`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if planFile == "" {
+				return fmt.Errorf("--plan is required")
+			}
+			plan, err := batch.LoadPlan(planFile)
+			if err != nil {
+				return err
+			}
+			if timeout != "" {
+				d, err := parseDuration(timeout)
+				if err != nil {
+					return fmt.Errorf("invalid --timeout: %w", err)
+				}
+				plan.Timeout.Duration = d
+			}
+			if artifactsDir != "" {
+				plan.Artifacts.Dir = artifactsDir
+			}
+			if keepSession {
+				plan.KeepSession = true
+			}
+			return runBatch(cmd.Context(), plan, headless)
+		},
+	}
+
+	cmd.Flags().StringVar(&planFile, "plan", "", "Plan file (YAML, required)")
+	cmd.Flags().BoolVar(&headless, "headless", false, "CI mode: plain progress on stderr, JSON report on stdout")
+	cmd.Flags().StringVar(&timeout, "timeout", "", "Override the plan timeout (e.g. 45m)")
+	cmd.Flags().StringVar(&artifactsDir, "artifacts", "", "Override the artifacts directory (default ntm-artifacts)")
+	cmd.Flags().BoolVar(&keepSession, "keep-session", false, "Do not kill the session when done")
+	_ = cmd.MarkFlagFilename("plan", "yaml", "yml")
+
+	return cmd
+}
+
+func runBatch(ctx context.Context, plan *batch.Plan, headless bool) error {
+	if err := tmux.EnsureInstalled(); err != nil {
+		return err
+	}
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	// CI cancels jobs with SIGTERM; still collect artifacts and tear down.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logw := io.Writer(os.Stdout)
+	if headless || IsJSONOutput() {
+		logw = os.Stderr
+	}
+	logf := func(format string, args ...any) {
+		fmt.Fprintf(logw, "[batch] "+format+"\n", args...)
+	}
+
+	report, err := batch.Run(ctx, plan, batchHooks(projectDir, logf, func(ev pipeline.ProgressEvent) {
+		if headless || IsJSONOutput() {
+			logf("%s %s %s", ev.Type, ev.StepID, ev.Message)
+		} else {
+			printProgressEvent(ev)
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	if err := batch.PublishGitHub(report); err != nil {
+		logf("warning: %v", err)
+	}
+
+	if headless || IsJSONOutput() {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		for _, p := range report.Phases {
+			line := fmt.Sprintf("%-4s %s", p.Status, p.Name)
+			if p.Message != "" {
+				line += ": " + p.Message
+			}
+			fmt.Println("  " + line)
+		}
+		fmt.Printf("\nReport: %s\n", filepath.Join(report.ArtifactsDir, batch.ReportFile))
+		if !report.Failed() {
+			output.SuccessCheck(fmt.Sprintf("Batch %s completed: %d phases passed", report.Plan, report.Summary.Passed))
+		}
+	}
+
+	if report.Failed() {
+		return fmt.Errorf("batch %s failed: %d of %d phases failed (%v)",
+			report.Plan, report.Summary.Failed, report.Summary.Total, report.FailedPhases())
+	}
+	return nil
+}
+
+// batchHooks wires a batch run in projectDir to tmux and the pipeline
+// executor, reporting task progress through onEvent.
+func batchHooks(projectDir string, logf func(format string, args ...any), onEvent func(pipeline.ProgressEvent)) batch.Hooks {
+	return batch.Hooks{
+		Spawn: func(ctx context.Context, plan *batch.Plan) error {
+			return batchSpawn(plan, projectDir)
+		},
+		RunTasks: func(ctx context.Context, plan *batch.Plan, wf *pipeline.Workflow) (*pipeline.ExecutionState, error) {
+			return batchRunTasks(ctx, plan, wf, projectDir, onEvent)
+		},
+		Collect: func(ctx context.Context, plan *batch.Plan, dir string) error {
+			return batchCollect(ctx, plan, dir, projectDir)
+		},
+		Teardown: func(plan *batch.Plan) error {
+			if !tmux.SessionExists(plan.Session) {
+				return nil
+			}
+			return tmux.KillSession(plan.Session)
+		},
+		Logf: logf,
+	}
+}
+
+// batchSpawn creates a fresh session with the plan's agents and waits for
+// them to become ready.
+func batchSpawn(plan *batch.Plan, projectDir string) error {
+	out, err := robot.GetSpawn(robot.SpawnOptions{
+		Session:      plan.Session,
+		CCCount:      plan.Agents.Claude,
+		CodCount:     plan.Agents.Codex,
+		GmiCount:     plan.Agents.Gemini,
+		WorkingDir:   projectDir,
+		WaitReady:    true,
+		ReadyTimeout: int(plan.ReadyTimeout.Seconds()),
+		Safety:       true,
+	}, cfg)
+	if err != nil {
+		return err
+	}
+	if !out.Success {
+		if out.Error != "" {
+			return fmt.Errorf("%s", out.Error)
+		}
+		return fmt.Errorf("spawn failed")
+	}
+	for _, a := range out.Agents {
+		if a.Error != "" {
+			return fmt.Errorf("agent %s (%s): %s", a.Pane, a.Type, a.Error)
+		}
+		if !a.Ready {
+			return fmt.Errorf("agent %s (%s) not ready after %s", a.Pane, a.Type, plan.ReadyTimeout.Duration)
+		}
+	}
+	return nil
+}
+
+// batchRunTasks runs the workflow in the plan's session, reporting progress
+// through onEvent.
+func batchRunTasks(ctx context.Context, plan *batch.Plan, wf *pipeline.Workflow, projectDir string, onEvent func(pipeline.ProgressEvent)) (*pipeline.ExecutionState, error) {
+	execCfg := pipeline.DefaultExecutorConfig(plan.Session)
+	execCfg.ProjectDir = projectDir
+	execCfg.GlobalTimeout = plan.Timeout.Duration
+	executor := pipeline.NewExecutor(execCfg)
+
+	progress := make(chan pipeline.ProgressEvent, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range progress {
+			onEvent(ev)
+		}
+	}()
+
+	vars := make(map[string]interface{}, len(plan.Vars))
+	for k, v := range plan.Vars {
+		vars[k] = v
+	}
+	state, err := executor.Run(ctx, wf, vars, progress)
+	close(progress)
+	<-done
+	return state, err
+}
+
+// batchCollect writes pane captures and the session summary to dir.
+func batchCollect(ctx context.Context, plan *batch.Plan, dir, projectDir string) error {
+	if !tmux.SessionExists(plan.Session) {
+		return fmt.Errorf("session %q not found; nothing to capture", plan.Session)
+	}
+	if err := runSave(io.Discard, plan.Session, filepath.Join(dir, "panes"), plan.Artifacts.Lines, AgentFilter{All: true}); err != nil {
+		return fmt.Errorf("capture panes: %w", err)
+	}
+
+	panes, err := tmux.GetPanes(plan.Session)
+	if err != nil {
+		return fmt.Errorf("list panes: %w", err)
+	}
+	var outputs []summary.AgentOutput
+	for _, pane := range panes {
+		agentType := string(pane.Type)
+		if agentType == "" || agentType == "unknown" || agentType == "user" {
+			continue
+		}
+		out, _ := tmux.CapturePaneOutput(pane.ID, 500)
+		outputs = append(outputs, summary.AgentOutput{AgentID: pane.ID, AgentType: agentType, Output: out})
+	}
+	sum, err := summary.SummarizeSession(ctx, summary.Options{
+		Session:        plan.Session,
+		Outputs:        outputs,
+		Format:         summary.FormatDetailed,
+		ProjectKey:     projectDir,
+		ProjectDir:     projectDir,
+		IncludeGitDiff: true,
+	})
+	if err != nil {
+		return fmt.Errorf("summarize session: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "summary.md"), []byte(summary.RenderSummary(sum, summary.FormatDetailed)+"\n"), 0o644); err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+	data, err := json.MarshalIndent(sum, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "summary.json"), append(data, '\n'), 0o644)
+}
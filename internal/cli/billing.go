@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/daemon"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+)
+
+// billingSourceFn builds billing pollers; tests replace it.
+var billingSourceFn = cost.NewBillingSource
+
+// BillingStatusResult is the JSON output for budget billing.
+type BillingStatusResult struct {
+	Providers []cost.ProviderSpend `json:"providers"`
+}
+
+func newBudgetBillingCmd() *cobra.Command {
+	var sync bool
+
+	cmd := &cobra.Command{
+		Use:   "billing",
+		Short: "Show provider-reported spend against org monthly caps",
+		Long: `Show the month-to-date spend reported by provider billing APIs, ntm's
+own token-based estimate for the same period, and how close each provider
+is to its [billing] monthly cap.
+
+Polling is optional and configured per provider in [billing]; 'ntm daemon'
+polls every poll_minutes, and --sync polls now. From throttle_at of the cap
+spawn and send pacing slows, up to max_delay_factor; from pause_at new
+spawns pause until the month ends. Admin keys are read from the variable
+named by admin_key_env and are never logged.
+
+Examples:
+  ntm budget billing
+  ntm budget billing --sync
+  ntm budget billing --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBudgetBilling(cmd.Context(), sync)
+		},
+	}
+
+	cmd.Flags().BoolVar(&sync, "sync", false, "Poll the billing APIs now")
+	return cmd
+}
+
+// billingConfig returns the [billing] settings.
+func billingConfig() config.BillingConfig {
+	if cfg == nil {
+		return config.DefaultBillingConfig()
+	}
+	return cfg.Billing
+}
+
+func runBudgetBilling(ctx context.Context, sync bool) error {
+	bc := billingConfig()
+	enabled := bc.Enabled()
+	if len(enabled) == 0 {
+		if IsJSONOutput() {
+			return output.PrintJSON(BillingStatusResult{Providers: []cost.ProviderSpend{}})
+		}
+		fmt.Printf("No billing pollers enabled (set enabled = true under [billing.%s])\n", strings.Join(config.BillingProviders, "] or [billing."))
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var pollErr error
+	state := cost.NewBillingState()
+	if sync {
+		state, pollErr = pollBilling(ctx, time.Now())
+	} else if err := state.Load(cost.BillingStatePath()); err != nil {
+		return err
+	}
+
+	result := BillingStatusResult{Providers: []cost.ProviderSpend{}}
+	for _, p := range state.Providers() {
+		if slices.Contains(enabled, p.Provider) {
+			result.Providers = append(result.Providers, p)
+		}
+	}
+
+	if IsJSONOutput() {
+		if err := output.PrintJSON(result); err != nil {
+			return err
+		}
+		return pollErr
+	}
+
+	if len(result.Providers) == 0 {
+		fmt.Println("Not polled yet (run 'ntm budget billing --sync' or 'ntm daemon').")
+		return pollErr
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Provider\tActual\tEstimated\tDrift\tCap\tUsed\tLevel\tPolled")
+	fmt.Fprintln(w, "────────\t──────\t─────────\t─────\t───\t────\t─────\t──────")
+	for _, p := range result.Providers {
+		polled := "-"
+		if !p.PolledAt.IsZero() {
+			polled = p.PolledAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.0f%%\t%s\t%s\n",
+			p.Provider, cost.FormatCost(p.ActualUSD), cost.FormatCost(p.EstimatedUSD),
+			formatDrift(p.DriftUSD), formatBudgetLimit(p.CapUSD), p.CapFraction*100, p.Level, polled)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, p := range result.Providers {
+		if p.Error != "" {
+			fmt.Fprintf(os.Stderr, "Warning: last %s poll failed: %s\n", p.Provider, p.Error)
+		}
+	}
+	return pollErr
+}
+
+func formatDrift(usd float64) string {
+	if usd < 0 {
+		return "-" + cost.FormatCost(-usd)
+	}
+	return "+" + cost.FormatCost(usd)
+}
+
+// spendCapRules returns the schedule rules that slow or pause providers near
+// their monthly cap, from the last poll. Providers no longer enabled are
+// left alone.
+func spendCapRules(now time.Time) []ratelimit.ScheduleRule {
+	enabled := billingConfig().Enabled()
+	if len(enabled) == 0 {
+		return nil
+	}
+	state := cost.NewBillingState()
+	if err := state.Load(cost.BillingStatePath()); err != nil {
+		slog.Default().Debug("ignoring unreadable billing state", "error", err)
+		return nil
+	}
+	return slices.DeleteFunc(state.ScheduleRules(now), func(r ratelimit.ScheduleRule) bool {
+		return !slices.Contains(enabled, r.Providers[0])
+	})
+}
+
+// estimatedProviderSpend sums ntm's estimated spend per provider since
+// since, over the current project and the projects of running sessions.
+func estimatedProviderSpend(since time.Time) map[string]float64 {
+	var dirs []string
+	if root := GetProjectRoot(); root != "" {
+		dirs = append(dirs, root)
+	}
+	names, _ := tmuxSessionNames()
+	for _, name := range names {
+		if dir := sessionProjectDir(name); dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	total := make(map[string]float64)
+	for _, dir := range dirs {
+		tracker := cost.NewCostTracker(dir)
+		if err := tracker.LoadFromDir(dir); err != nil {
+			continue
+		}
+		for provider, usd := range tracker.ProviderSpendSince(since) {
+			total[provider] += usd
+		}
+	}
+	return total
+}
+
+// pollBilling polls every enabled provider's billing API, reconciles the
+// result with ntm's estimate and saves the state. A failed provider keeps
+// its last spend; the errors are joined. Audit entries name the key's
+// environment variable, never the key.
+func pollBilling(ctx context.Context, now time.Time) (*cost.BillingState, error) {
+	bc := billingConfig()
+	path := cost.BillingStatePath()
+	state := cost.NewBillingState()
+	if err := state.Load(path); err != nil {
+		return nil, err
+	}
+
+	since := cost.MonthStart(now)
+	estimates := estimatedProviderSpend(since)
+	var errs []error
+	for _, name := range bc.Enabled() {
+		pc, _ := bc.Provider(name)
+		keyEnv := strings.TrimSpace(pc.AdminKeyEnv)
+
+		actual, err := pollBillingProvider(ctx, name, keyEnv, pc.BaseURL, since)
+		if err != nil {
+			state.RecordError(name, keyEnv, err, now)
+			_ = audit.LogEvent("", audit.EventTypeStateChange, audit.ActorSystem, "billing", map[string]interface{}{
+				"action":   "poll_failed",
+				"provider": name,
+				"key_env":  keyEnv,
+				"error":    err.Error(),
+			}, nil)
+			errs = append(errs, err)
+			continue
+		}
+
+		limit := cost.SpendCap{
+			MonthlyUSD:     pc.MonthlyCapUSD,
+			ThrottleAt:     pc.ThrottleAt,
+			PauseAt:        pc.PauseAt,
+			MaxDelayFactor: pc.MaxDelayFactor,
+		}
+		spend, prev := state.Record(name, keyEnv, actual, estimates[name], limit, now)
+		_ = audit.LogEvent("", audit.EventTypeStateChange, audit.ActorSystem, "billing", map[string]interface{}{
+			"action":        "poll",
+			"provider":      name,
+			"key_env":       keyEnv,
+			"actual_usd":    spend.ActualUSD,
+			"estimated_usd": spend.EstimatedUSD,
+			"cap_usd":       spend.CapUSD,
+			"level":         string(spend.Level),
+		}, nil)
+		if spend.Level != prev {
+			slog.Warn("provider spend cap level changed", "provider", name, "from", prev, "to", spend.Level,
+				"spend", cost.FormatCost(spend.ActualUSD), "cap", cost.FormatCost(spend.CapUSD))
+			events.Emit(events.EventSpendCapLevel, "", map[string]interface{}{
+				"provider":     name,
+				"from":         string(prev),
+				"level":        string(spend.Level),
+				"actual_usd":   spend.ActualUSD,
+				"cap_usd":      spend.CapUSD,
+				"delay_factor": spend.DelayFactor,
+			})
+		}
+	}
+
+	if err := state.Save(path); err != nil {
+		errs = append(errs, err)
+	}
+	return state, errors.Join(errs...)
+}
+
+func pollBillingProvider(ctx context.Context, provider, keyEnv, baseURL string, since time.Time) (float64, error) {
+	key := os.Getenv(keyEnv)
+	if key == "" {
+		return 0, fmt.Errorf("%s billing: %s is not set", provider, keyEnv)
+	}
+	source, err := billingSourceFn(provider, key, baseURL)
+	if err != nil {
+		return 0, err
+	}
+	return source.SpendSince(ctx, since)
+}
+
+// runDaemonBilling polls the billing APIs every [billing] poll_minutes
+// until ctx is done. It idles when no provider is enabled.
+func runDaemonBilling(ctx context.Context, rep *daemon.Reporter) error {
+	bc := billingConfig()
+	if len(bc.Enabled()) == 0 || bc.PollMinutes <= 0 {
+		<-ctx.Done()
+		return nil
+	}
+	poll := func() {
+		if _, err := pollBilling(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			rep.Error(err)
+		}
+	}
+	poll()
+	ticker := time.NewTicker(time.Duration(bc.PollMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			poll()
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+)
+
+type stubBillingSource struct {
+	provider string
+	usd      float64
+}
+
+func (s stubBillingSource) Provider() string { return s.provider }
+
+func (s stubBillingSource) SpendSince(context.Context, time.Time) (float64, error) {
+	return s.usd, nil
+}
+
+func TestPollBilling_TightensSchedule(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("TEST_OPENAI_ADMIN_KEY", "sk-admin-test")
+
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = config.Default()
+	cfg.Billing.OpenAI.Enabled = true
+	cfg.Billing.OpenAI.MonthlyCapUSD = 100
+	cfg.Billing.OpenAI.AdminKeyEnv = "TEST_OPENAI_ADMIN_KEY"
+	cfg.Billing.Anthropic.Enabled = true
+	cfg.Billing.Anthropic.MonthlyCapUSD = 100
+	cfg.Billing.Anthropic.AdminKeyEnv = "TEST_UNSET_ADMIN_KEY"
+
+	oldSource := billingSourceFn
+	defer func() { billingSourceFn = oldSource }()
+	var gotKey string
+	billingSourceFn = func(provider, key, baseURL string) (cost.BillingSource, error) {
+		gotKey = key
+		return stubBillingSource{provider, 97}, nil
+	}
+
+	now := time.Now()
+	state, err := pollBilling(context.Background(), now)
+	if err == nil || !strings.Contains(err.Error(), "TEST_UNSET_ADMIN_KEY is not set") {
+		t.Errorf("error = %v, want the unset anthropic key reported", err)
+	}
+	if gotKey != "sk-admin-test" {
+		t.Errorf("poller got key %q", gotKey)
+	}
+	openai := state.Get("openai")
+	if openai == nil || openai.Level != cost.SpendPaused || openai.KeyEnv != "TEST_OPENAI_ADMIN_KEY" {
+		t.Fatalf("openai spend = %+v", openai)
+	}
+	if a := state.Get("anthropic"); a == nil || a.Error == "" || a.Level != cost.SpendOK {
+		t.Errorf("anthropic spend = %+v", a)
+	}
+
+	if rules := spendCapRules(now); len(rules) != 1 || !rules[0].Pause {
+		t.Fatalf("rules = %+v", rules)
+	}
+	if _, _, paused := rateLimitSchedule().PausedUntil("cod", now); !paused {
+		t.Error("codex spawns not paused at the spend cap")
+	}
+
+	// Disabling the provider lifts its throttle.
+	cfg.Billing.OpenAI.Enabled = false
+	if rules := spendCapRules(now); len(rules) != 0 {
+		t.Errorf("rules after disabling = %+v", rules)
+	}
+}
@@ -0,0 +1,483 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/templates"
+)
+
+// BudgetStatusResult is the JSON output for budget status.
+type BudgetStatusResult struct {
+	Budgets []BudgetStatusEntry `json:"budgets"`
+}
+
+// BudgetStatusEntry describes one session's budget state.
+type BudgetStatusEntry struct {
+	cost.BudgetCheck
+	Template   string                 `json:"template,omitempty"`
+	Extensions []cost.BudgetExtension `json:"extensions,omitempty"`
+
+	// Limits and guards inherited from the session template.
+	Inherited  bool               `json:"inherited,omitempty"`
+	MaxTokens  int64              `json:"max_tokens,omitempty"`
+	MaxAgents  int                `json:"max_agents,omitempty"`
+	MaxRuntime string             `json:"max_runtime,omitempty"`
+	Guards     []cost.PromptGuard `json:"guards,omitempty"`
+}
+
+func newBudgetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Manage session spend guardrails",
+		Long: `Manage per-session spend guardrails.
+
+When session spend crosses the soft limit a budget_soft_limit event is raised.
+At the hard limit all prompt sends for the session are paused until an
+extension is approved with 'ntm budget approve'. Approvals are audited.
+
+Limits can be set directly or taken from a session template's
+options.budget (softLimit / hardLimit, in USD).
+
+Sessions spawned with 'ntm spawn --session-template' inherit the template's
+options.budget (dollars, tokens, maxAgents, maxRuntime) and options.guards.
+Changing or bypassing inherited limits requires --override-guards, which is
+audited.
+
+'ntm budget billing' compares provider-reported spend with org-level
+monthly caps configured in [billing].
+
+Examples:
+  ntm budget set myproject --soft 5 --hard 10
+  ntm budget set myproject --template feature
+  ntm budget status myproject
+  ntm budget approve myproject --extend 20 --reason "finishing migration"
+  ntm budget billing --sync`,
+	}
+
+	cmd.AddCommand(newBudgetStatusCmd(), newBudgetSetCmd(), newBudgetApproveCmd(), newBudgetBillingCmd())
+	return cmd
+}
+
+func newBudgetStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [session]",
+		Short: "Show budget limits and current spend",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var session string
+			if len(args) > 0 {
+				session = args[0]
+			}
+			return runBudgetStatus(session)
+		},
+	}
+}
+
+func newBudgetSetCmd() *cobra.Command {
+	var (
+		soft           float64
+		hard           float64
+		templateName   string
+		overrideGuards bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set <session>",
+		Short: "Set soft and hard spend limits for a session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBudgetSet(args[0], soft, hard, templateName,
+				cmd.Flags().Changed("soft"), cmd.Flags().Changed("hard"), overrideGuards)
+		},
+	}
+
+	cmd.Flags().Float64Var(&soft, "soft", 0, "Soft limit in USD (raises an event)")
+	cmd.Flags().Float64Var(&hard, "hard", 0, "Hard limit in USD (pauses sends)")
+	cmd.Flags().StringVar(&templateName, "template", "", "Take limits from a session template's options.budget")
+	cmd.Flags().BoolVar(&overrideGuards, "override-guards", false, "Replace limits inherited from a session template (audited)")
+	return cmd
+}
+
+func newBudgetApproveCmd() *cobra.Command {
+	var (
+		extend float64
+		reason string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "approve <session>",
+		Short: "Approve a budget extension and resume sends",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBudgetApprove(args[0], extend, reason)
+		},
+	}
+
+	cmd.Flags().Float64Var(&extend, "extend", 0, "Amount in USD to add to the hard limit")
+	cmd.Flags().StringVar(&reason, "reason", "", "Reason for the extension (recorded in the audit log)")
+	_ = cmd.MarkFlagRequired("extend")
+	return cmd
+}
+
+// loadBudgetState loads the budget guard and cost tracker for the project.
+func loadBudgetState(dir string) (*cost.BudgetGuard, *cost.CostTracker, error) {
+	guard := cost.NewBudgetGuard()
+	if err := guard.LoadFromDir(dir); err != nil {
+		return nil, nil, err
+	}
+	tracker := cost.NewCostTracker(dir)
+	if err := tracker.LoadFromDir(dir); err != nil {
+		return nil, nil, err
+	}
+	return guard, tracker, nil
+}
+
+func runBudgetStatus(session string) error {
+	dir := GetProjectRoot()
+	guard, tracker, err := loadBudgetState(dir)
+	if err != nil {
+		return err
+	}
+
+	sessions := guard.Sessions()
+	if session != "" {
+		sessions = []string{session}
+	}
+
+	result := BudgetStatusResult{Budgets: []BudgetStatusEntry{}}
+	for _, name := range sessions {
+		check := guard.Check(name, tracker.GetSessionCost(name))
+		entry := BudgetStatusEntry{BudgetCheck: *check}
+		if b := guard.Get(name); b != nil {
+			entry.Template = b.Template
+			entry.Extensions = b.Extensions
+			entry.Inherited = b.Inherited
+			entry.MaxTokens = b.MaxTokens
+			entry.MaxAgents = b.MaxAgents
+			entry.Guards = b.Guards
+			if d := b.MaxRuntime(); d > 0 {
+				entry.MaxRuntime = d.String()
+			}
+		}
+		result.Budgets = append(result.Budgets, entry)
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(result)
+	}
+
+	if len(result.Budgets) == 0 {
+		fmt.Println("No session budgets configured.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Session\tSpend\tSoft\tHard\tLevel\tPaused")
+	fmt.Fprintln(w, "───────\t─────\t────\t────\t─────\t──────")
+	for _, e := range result.Budgets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%v\n",
+			e.Session, cost.FormatCost(e.SpendUSD), formatBudgetLimit(e.SoftLimitUSD),
+			formatBudgetLimit(e.HardLimitUSD), e.Level, e.Paused)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, e := range result.Budgets {
+		if !e.Inherited {
+			continue
+		}
+		var limits []string
+		if e.MaxTokens > 0 {
+			limits = append(limits, fmt.Sprintf("%d tokens", e.MaxTokens))
+		}
+		if e.MaxAgents > 0 {
+			limits = append(limits, fmt.Sprintf("%d agents", e.MaxAgents))
+		}
+		if e.MaxRuntime != "" {
+			limits = append(limits, e.MaxRuntime+" runtime")
+		}
+		if len(e.Guards) > 0 {
+			limits = append(limits, fmt.Sprintf("%d prompt guard(s)", len(e.Guards)))
+		}
+		if len(limits) > 0 {
+			fmt.Printf("%s inherits from template %s: %s\n", e.Session, e.Template, strings.Join(limits, ", "))
+		}
+	}
+	return nil
+}
+
+func formatBudgetLimit(usd float64) string {
+	if usd <= 0 {
+		return "-"
+	}
+	return cost.FormatCost(usd)
+}
+
+func runBudgetSet(session string, soft, hard float64, templateName string, softSet, hardSet, overrideGuards bool) error {
+	if templateName != "" {
+		tmpl, err := templates.NewSessionTemplateLoader().Load(templateName)
+		if err != nil {
+			return err
+		}
+		if tmpl.Spec.Options.Budget == nil {
+			return fmt.Errorf("session template %q has no options.budget", templateName)
+		}
+		// Explicit flags override the template.
+		if !softSet {
+			soft = tmpl.Spec.Options.Budget.SoftLimit
+		}
+		if !hardSet {
+			hard = tmpl.Spec.Options.Budget.HardLimit
+		}
+	} else if !softSet && !hardSet {
+		return fmt.Errorf("specify --soft, --hard, or --template")
+	}
+
+	dir := GetProjectRoot()
+	guard, _, err := loadBudgetState(dir)
+	if err != nil {
+		return err
+	}
+	if b := guard.Get(session); b != nil && b.Inherited {
+		violation := &cost.GuardViolationError{
+			Session:  session,
+			Template: b.Template,
+			Guard:    "budget",
+			Detail:   "limits are inherited",
+		}
+		if !overrideGuards {
+			return violation
+		}
+		auditGuardOverride(session, "budget_set", violation)
+	}
+	if err := guard.SetLimits(session, soft, hard, templateName); err != nil {
+		return err
+	}
+	if err := guard.SaveToDir(dir); err != nil {
+		return err
+	}
+
+	_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorUser, "budget", map[string]interface{}{
+		"action":         "set",
+		"soft_limit_usd": soft,
+		"hard_limit_usd": hard,
+		"template":       templateName,
+	}, nil)
+
+	if IsJSONOutput() {
+		return output.PrintJSON(guard.Get(session))
+	}
+	fmt.Printf("Budget for %s: soft %s, hard %s\n", session, formatBudgetLimit(soft), formatBudgetLimit(hard))
+	return nil
+}
+
+func runBudgetApprove(session string, extend float64, reason string) error {
+	dir := GetProjectRoot()
+	guard, tracker, err := loadBudgetState(dir)
+	if err != nil {
+		return err
+	}
+
+	approver := getCurrentApprover()
+	var budget *cost.SessionBudget
+	err = guard.UpdateInDir(dir, func() error {
+		var err error
+		budget, err = guard.Extend(session, extend, approver, reason)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	spend := tracker.GetSessionCost(session)
+	_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorUser, "budget", map[string]interface{}{
+		"action":         "approve_extension",
+		"extend_usd":     extend,
+		"new_hard_limit": budget.EffectiveHardLimit(),
+		"spend_usd":      spend,
+		"approved_by":    approver,
+		"reason":         reason,
+	}, nil)
+	events.Emit(events.EventBudgetExtended, session, map[string]interface{}{
+		"extend_usd":     extend,
+		"new_hard_limit": budget.EffectiveHardLimit(),
+		"approved_by":    approver,
+	})
+
+	if IsJSONOutput() {
+		return output.PrintJSON(budget)
+	}
+	fmt.Printf("Extended %s hard limit by %s to %s (spend %s)\n",
+		session, cost.FormatCost(extend), cost.FormatCost(budget.EffectiveHardLimit()), cost.FormatCost(spend))
+	return nil
+}
+
+// enforceSendBudget checks the session's spend guardrails before a prompt
+// send. It raises budget events on first crossing and returns a
+// BudgetExceededError while the session is paused, or a GuardViolationError
+// when a guard inherited from the session template refuses the prompt
+// (unless overrideGuards, which is audited). Sessions without a configured
+// budget are never blocked; state that cannot be read refuses the send, since
+// the budget cannot be enforced.
+func enforceSendBudget(session, prompt string, overrideGuards bool) error {
+	dir := GetProjectRoot()
+	if dir == "" {
+		return nil
+	}
+	guard := cost.NewBudgetGuard()
+	if err := guard.LoadFromDir(dir); err != nil {
+		return fmt.Errorf("checking budget for %s: %w", session, err)
+	}
+	if guard.Get(session) == nil {
+		return nil
+	}
+	tracker := cost.NewCostTracker(dir)
+	if err := tracker.LoadFromDir(dir); err != nil {
+		return fmt.Errorf("checking budget for %s: %w", session, err)
+	}
+
+	var tokens int64
+	if sc := tracker.GetSession(session); sc != nil {
+		in, out := sc.TotalTokens()
+		tokens = int64(in + out)
+	}
+	if err := guard.CheckGuards(session, tokens, prompt, time.Now()); err != nil {
+		if !overrideGuards {
+			return err
+		}
+		auditGuardOverride(session, "send", err)
+	}
+
+	var check *cost.BudgetCheck
+	var allowErr error
+	err := guard.UpdateInDir(dir, func() error {
+		check, allowErr = guard.Allow(session, tracker.GetSessionCost(session))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("checking budget for %s: %w", session, err)
+	}
+	data := map[string]interface{}{
+		"spend_usd":      check.SpendUSD,
+		"soft_limit_usd": check.SoftLimitUSD,
+		"hard_limit_usd": check.HardLimitUSD,
+	}
+	if check.SoftCrossed {
+		events.Emit(events.EventBudgetSoftLimit, session, data)
+		if !IsJSONOutput() {
+			fmt.Fprintf(os.Stderr, "Warning: session %s spend %s crossed soft budget limit %s\n",
+				session, cost.FormatCost(check.SpendUSD), cost.FormatCost(check.SoftLimitUSD))
+		}
+	}
+	if check.HardCrossed {
+		events.Emit(events.EventBudgetHardLimit, session, data)
+		_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorSystem, "budget", map[string]interface{}{
+			"action":         "pause",
+			"spend_usd":      check.SpendUSD,
+			"hard_limit_usd": check.HardLimitUSD,
+		}, nil)
+	}
+	return allowErr
+}
+
+// templateLimits converts a session template's options.budget and
+// options.guards into the limits its sessions inherit.
+func templateLimits(tmpl *templates.SessionTemplate) cost.TemplateLimits {
+	var limits cost.TemplateLimits
+	if b := tmpl.Spec.Options.Budget; b != nil {
+		limits.SoftUSD = b.SoftLimit
+		limits.HardUSD = b.HardLimit
+		limits.Tokens = b.Tokens
+		limits.MaxAgents = b.MaxAgents
+		// Validated when the template was loaded.
+		limits.MaxRuntime, _ = time.ParseDuration(b.MaxRuntime)
+	}
+	if g := tmpl.Spec.Options.Guards; g != nil {
+		for _, rule := range g.Blocked {
+			limits.Guards = append(limits.Guards, cost.PromptGuard{Pattern: rule.Pattern, Reason: rule.Reason})
+		}
+	}
+	return limits
+}
+
+// inheritTemplateBudget records the limits and guards session inherits from
+// its session template.
+func inheritTemplateBudget(session string, tmpl *templates.SessionTemplate, startedAt time.Time) error {
+	dir := GetProjectRoot()
+	if dir == "" {
+		return nil
+	}
+	guard, _, err := loadBudgetState(dir)
+	if err != nil {
+		return err
+	}
+	limits := templateLimits(tmpl)
+	if err := guard.Inherit(session, tmpl.Metadata.Name, limits, startedAt); err != nil {
+		return err
+	}
+	if err := guard.SaveToDir(dir); err != nil {
+		return err
+	}
+	_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorUser, "budget", map[string]interface{}{
+		"action":         "inherit",
+		"template":       tmpl.Metadata.Name,
+		"soft_limit_usd": limits.SoftUSD,
+		"hard_limit_usd": limits.HardUSD,
+		"max_tokens":     limits.Tokens,
+		"max_agents":     limits.MaxAgents,
+		"max_runtime":    limits.MaxRuntime.String(),
+		"guards":         len(limits.Guards),
+	}, nil)
+	return nil
+}
+
+// checkTemplateAgents enforces the inherited agent cap of an existing
+// session before total agents run in it.
+func checkTemplateAgents(session string, total int, overrideGuards bool) error {
+	dir := GetProjectRoot()
+	if dir == "" {
+		return nil
+	}
+	guard, _, err := loadBudgetState(dir)
+	if err != nil {
+		return nil
+	}
+	if err := guard.AllowAgents(session, total); err != nil {
+		if !overrideGuards {
+			return err
+		}
+		auditGuardOverride(session, "add", err)
+	}
+	return nil
+}
+
+// auditGuardOverride records that --override-guards bypassed an inherited
+// template guard.
+func auditGuardOverride(session, action string, err error) {
+	data := map[string]interface{}{
+		"action":      "override_guards",
+		"command":     action,
+		"approved_by": getCurrentApprover(),
+	}
+	var violation *cost.GuardViolationError
+	if errors.As(err, &violation) {
+		data["template"] = violation.Template
+		data["guard"] = violation.Guard
+		data["detail"] = violation.Detail
+	}
+	_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorUser, "budget", data, nil)
+	if !IsJSONOutput() {
+		fmt.Fprintf(os.Stderr, "Warning: overriding template guard for %s: %v\n", session, err)
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/templates"
+)
+
+func TestTemplateBudgetInheritance(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	tmpl := &templates.SessionTemplate{}
+	tmpl.Metadata.Name = "triage"
+	tmpl.Spec.Options.Budget = &templates.BudgetSpec{HardLimit: 25, MaxAgents: 2, MaxRuntime: "4h"}
+	tmpl.Spec.Options.Guards = &templates.GuardsSpec{
+		Blocked: []templates.GuardRuleSpec{{Pattern: `rm\s+-rf`, Reason: "no bulk deletes"}},
+	}
+
+	limits := templateLimits(tmpl)
+	if limits.HardUSD != 25 || limits.MaxAgents != 2 || limits.MaxRuntime != 4*time.Hour || len(limits.Guards) != 1 {
+		t.Fatalf("templateLimits() = %+v", limits)
+	}
+
+	if err := inheritTemplateBudget("s1", tmpl, time.Now()); err != nil {
+		t.Fatalf("inheritTemplateBudget() error: %v", err)
+	}
+
+	var violation *cost.GuardViolationError
+	if err := enforceSendBudget("s1", "rm -rf build/", false); !errors.As(err, &violation) || violation.Guard != "prompt" {
+		t.Errorf("enforceSendBudget() error = %v, want prompt guard violation", err)
+	}
+	if err := enforceSendBudget("s1", "rm -rf build/", true); err != nil {
+		t.Errorf("enforceSendBudget() with override error: %v", err)
+	}
+	if err := enforceSendBudget("s1", "run the tests", false); err != nil {
+		t.Errorf("enforceSendBudget() error: %v", err)
+	}
+
+	if err := checkTemplateAgents("s1", 3, false); !errors.As(err, &violation) || violation.Guard != "agents" {
+		t.Errorf("checkTemplateAgents() error = %v, want agents violation", err)
+	}
+	if err := checkTemplateAgents("s1", 3, true); err != nil {
+		t.Errorf("checkTemplateAgents() with override error: %v", err)
+	}
+
+	if err := runBudgetSet("s1", 0, 100, "", false, true, false); !errors.As(err, &violation) {
+		t.Errorf("runBudgetSet() error = %v, want inherited limits refused", err)
+	}
+}
+
+func TestEnforceSendBudget_UnreadableStateRefusesSend(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if err := enforceSendBudget("s1", "hello", false); err != nil {
+		t.Fatalf("enforceSendBudget() without budget state error: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".ntm"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".ntm", "budget.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := enforceSendBudget("s1", "hello", false); err == nil {
+		t.Error("enforceSendBudget() with unreadable budget state = nil, want the send refused")
+	}
+}
@@ -55,7 +55,8 @@ func newConflictsCmd() *cobra.Command {
 		Examples:
 		  ntm conflicts
 		  ntm conflicts myproject
-		  ntm conflicts --since 6h --limit 10`,
+		  ntm conflicts --since 6h --limit 10
+		  ntm conflicts ack cf-3f2a91c0 --note "intentional"`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			session := ""
@@ -67,6 +68,7 @@ func newConflictsCmd() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&since, "since", "24h", "Look back window (e.g. 6h, 30m)")
 	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum conflicts to display (0 = no limit)")
+	cmd.AddCommand(newConflictsAckCmd())
 	return cmd
 }
 
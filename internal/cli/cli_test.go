@@ -148,6 +148,30 @@ func TestResolveRobotFormat_ConfigFallback(t *testing.T) {
 	}
 }
 
+func TestResolveRobotFormat_TOONOptionsFromConfig(t *testing.T) {
+	resetFlags()
+	t.Cleanup(func() { robot.OutputTOONOptions = robot.TOONOptions{} })
+
+	cfg := &config.Config{
+		Robot: config.RobotConfig{
+			Output: config.RobotOutputConfig{
+				TOONAlign:         true,
+				TOONAbbreviations: map[string]string{"agent_type": "t"},
+			},
+		},
+	}
+
+	resolveRobotFormat(cfg)
+	if !robot.OutputTOONOptions.Align || robot.OutputTOONOptions.Abbreviations["agent_type"] != "t" {
+		t.Errorf("OutputTOONOptions = %+v, want config's", robot.OutputTOONOptions)
+	}
+
+	resolveRobotFormat(nil)
+	if !robot.OutputTOONOptions.IsZero() {
+		t.Errorf("OutputTOONOptions without config = %+v, want none", robot.OutputTOONOptions)
+	}
+}
+
 func TestRobotOutputFormatFlagAliasRegistered(t *testing.T) {
 	if rootCmd.Flags().Lookup("robot-output-format") == nil {
 		t.Fatal("expected --robot-output-format flag to be registered (alias for --robot-format)")
@@ -12,8 +12,10 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/Dicklesworthstone/ntm/internal/ensemble"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 )
 
@@ -122,6 +124,45 @@ func completeTierValues(_ *cobra.Command, _ []string, toComplete string) ([]stri
 	return filterByPrefix(tiers, toComplete), cobra.ShellCompDirectiveNoFileComp
 }
 
+func completeSessionFlag(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterByPrefix(listSessions(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeValues(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterByPrefix(values, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// registerRobotFlagCompletions adds value completion to the --robot-* flags
+// of cmd: session names for flags documented as taking a SESSION, and the
+// fixed choices of the format, verbosity, and schema flags.
+func registerRobotFlagCompletions(cmd *cobra.Command) {
+	schemaTypes := make([]string, 0, len(robot.SchemaCommand)+1)
+	for name := range robot.SchemaCommand {
+		schemaTypes = append(schemaTypes, name)
+	}
+	sort.Strings(schemaTypes)
+	schemaTypes = append(schemaTypes, "all")
+
+	fixed := map[string][]string{
+		"robot-format":        {"json", "toon", "auto"},
+		"robot-output-format": {"json", "toon", "auto"},
+		"robot-verbosity":     {"terse", "default", "debug"},
+		"robot-schema":        schemaTypes,
+		"schema":              schemaTypes,
+	}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if values, ok := fixed[f.Name]; ok {
+			_ = cmd.RegisterFlagCompletionFunc(f.Name, completeValues(values...))
+			return
+		}
+		if strings.HasPrefix(f.Name, "robot-") && f.Value.Type() == "string" && strings.Contains(f.Usage, "SESSION") {
+			_ = cmd.RegisterFlagCompletionFunc(f.Name, completeSessionFlag)
+		}
+	})
+}
+
 func sessionFromArgsOrFlag(cmd *cobra.Command, args []string) string {
 	if session := sessionFromFlag(cmd); session != "" {
 		return session
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newConflictsAckCmd() *cobra.Command {
+	var opts robot.ConflictAckOptions
+
+	cmd := &cobra.Command{
+		Use:   "ack <id>",
+		Short: "Acknowledge a conflict so it stops alerting",
+		Long: `Acknowledge a conflict reported by 'ntm robot conflicts' by its id.
+
+An acknowledged conflict is listed as muted instead of alerting, in one-shot
+checks and in watch mode, until it clears. If the file conflicts again later,
+it alerts again. The acknowledgement and its note are kept in the conflict
+history (~/.config/ntm/analytics/conflicts.jsonl).
+
+To mute a path for good, add a [[conflicts.suppress]] rule to the config.
+
+Examples:
+  ntm conflicts ack cf-3f2a91c0 --note "intentional"
+  ntm conflicts ack cf-3f2a91c0 --session myproject --note "regenerated"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ID = args[0]
+			if err := applyConflictsConfig(&opts.Conflicts); err != nil {
+				return err
+			}
+			out, err := robot.AckConflict(opts)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(out)
+			}
+			if !out.Success {
+				return fmt.Errorf("%s", out.Error)
+			}
+			fmt.Printf("Acknowledged %s (%s)\n", out.ID, out.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Note, "note", "", "Why the conflict is acceptable")
+	cmd.Flags().StringVar(&opts.Conflicts.Session, "session", "", "Session to record the conflict under if it is not in the history yet")
+	cmd.Flags().StringVar(&opts.Conflicts.RepoPath, "repo", "", "Repository of the conflict (default: project root)")
+	return cmd
+}
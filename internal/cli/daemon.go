@@ -0,0 +1,395 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/daemon"
+	"github.com/Dicklesworthstone/ntm/internal/git"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/resilience"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/shutdown"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newDaemonCmd() *cobra.Command {
+	var enable, disable []string
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run background subsystems in one supervised process",
+		Long: `Run ntm's background subsystems in one long-lived process:
+
+  capture      archive pane output of sessions that have no monitor
+  watchdog     restart resilience monitors that died
+  conflicts    scan each session's repository for file conflicts
+  checkpoints  checkpoint each session every [checkpoints] interval_minutes
+  retention    prune storage and ship audit logs
+  billing      poll provider billing APIs for [billing] spend caps
+  winddown     wind sessions down at their maximum runtime ([wind_down])
+  schedules    start recurring fleet runs from [[schedules]]
+
+Subsystems are enabled in [daemon] and all are on by default; --enable and
+--disable override the config for this run. A subsystem that fails or panics
+is restarted with backoff without affecting the others. Use
+'ntm daemon status' from another shell to see what the daemon is doing.
+
+Examples:
+  ntm daemon
+  ntm daemon --disable capture,conflicts
+  ntm daemon status
+  ntm daemon status --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDaemon(enable, disable)
+		},
+	}
+
+	names := strings.Join(config.DaemonSubsystems, ", ")
+	cmd.Flags().StringSliceVar(&enable, "enable", nil, "Subsystems to run even if disabled in config ("+names+")")
+	cmd.Flags().StringSliceVar(&disable, "disable", nil, "Subsystems not to run")
+	cmd.AddCommand(newDaemonStatusCmd())
+	return cmd
+}
+
+func newDaemonStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the state of the running daemon's subsystems",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDaemonStatus()
+		},
+	}
+}
+
+// daemonConfig returns the [daemon] settings.
+func daemonConfig() config.DaemonConfig {
+	if cfg == nil {
+		return config.DefaultDaemonConfig()
+	}
+	return cfg.Daemon
+}
+
+// daemonDisabled returns the subsystems to skip: those off in config,
+// overridden by --enable and --disable.
+func daemonDisabled(dc config.DaemonConfig, enable, disable []string) ([]string, error) {
+	for _, name := range slices.Concat(enable, disable) {
+		if !slices.Contains(config.DaemonSubsystems, name) {
+			return nil, fmt.Errorf("unknown subsystem %q (valid: %s)", name, strings.Join(config.DaemonSubsystems, ", "))
+		}
+	}
+	disabled := slices.DeleteFunc(dc.Disabled(), func(name string) bool {
+		return slices.Contains(enable, name)
+	})
+	for _, name := range disable {
+		if slices.Contains(enable, name) {
+			return nil, fmt.Errorf("subsystem %q both enabled and disabled", name)
+		}
+		if !slices.Contains(disabled, name) {
+			disabled = append(disabled, name)
+		}
+	}
+	slices.Sort(disabled)
+	return disabled, nil
+}
+
+func runDaemon(enable, disable []string) error {
+	dc := daemonConfig()
+	disabled, err := daemonDisabled(dc, enable, disable)
+	if err != nil {
+		return err
+	}
+	if err := daemon.CheckNotRunning(""); err != nil {
+		return fmt.Errorf("%w; see 'ntm daemon status'", err)
+	}
+	reconcile := time.Duration(dc.ReconcileSeconds) * time.Second
+
+	d := daemon.New(daemon.Config{Disabled: disabled}, daemonSubsystems(reconcile))
+	markRunning("daemon")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	go func() {
+		<-sigCh
+		fmt.Println("\nDaemon stopping...")
+		cancel()
+	}()
+
+	for _, st := range d.Status().Subsystems {
+		if st.Enabled {
+			fmt.Printf("Starting %s: %s\n", st.Name, st.Description)
+		}
+	}
+	fmt.Println("Press Ctrl+C to stop")
+
+	return d.Run(ctx)
+}
+
+// daemonSubsystems builds the subsystems run by ntm daemon. Per-session
+// subsystems re-list sessions every reconcile interval.
+func daemonSubsystems(reconcile time.Duration) []daemon.Subsystem {
+	return []daemon.Subsystem{
+		{
+			Name:        "capture",
+			Description: "archive pane output of sessions without a monitor",
+			Run:         daemon.PerSession(reconcile, unmonitoredSessions, runDaemonCapture),
+		},
+		{
+			Name:        "watchdog",
+			Description: "restart dead resilience monitors",
+			Run:         daemon.PerSession(reconcile, manifestSessions, runDaemonWatchdog(reconcile)),
+		},
+		{
+			Name:        "conflicts",
+			Description: "scan session repositories for file conflicts",
+			Run:         daemon.PerSession(reconcile, tmuxSessionNames, runDaemonConflicts),
+		},
+		{
+			Name:        "checkpoints",
+			Description: "periodic session checkpoints",
+			Run:         daemon.PerSession(reconcile, tmuxSessionNames, runDaemonCheckpoints),
+		},
+		{
+			Name:        "retention",
+			Description: "storage pruning and audit log shipping",
+			Run: func(ctx context.Context, _ *daemon.Reporter) error {
+				startStoragePruner(ctx)
+				startAuditShipper(ctx)
+				<-ctx.Done()
+				return nil
+			},
+		},
+		{
+			Name:        "billing",
+			Description: "poll provider billing APIs and tighten pacing near spend caps",
+			Run:         runDaemonBilling,
+		},
+		{
+			Name:        "winddown",
+			Description: "stop, verify, summarize and checkpoint sessions at their max runtime",
+			Run:         daemon.PerSession(reconcile, tmuxSessionNames, runDaemonWindDown),
+		},
+		{
+			Name:        "schedules",
+			Description: "start recurring fleet runs when their cron schedule is due",
+			Run:         runDaemonSchedules,
+		},
+	}
+}
+
+// tmuxSessionNames lists the running tmux sessions.
+func tmuxSessionNames() ([]string, error) {
+	sessions, err := tmux.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// monitorRunning reports whether session has a live internal-monitor.
+func monitorRunning(session string) bool {
+	return shutdown.IsRunning("", "monitor-"+session)
+}
+
+// unmonitoredSessions lists running sessions whose output no monitor is
+// already archiving.
+func unmonitoredSessions() ([]string, error) {
+	names, err := tmuxSessionNames()
+	return slices.DeleteFunc(names, monitorRunning), err
+}
+
+// manifestSessions lists running sessions spawned with a resilience
+// manifest, i.e. those that should have a monitor.
+func manifestSessions() ([]string, error) {
+	names, err := tmuxSessionNames()
+	return slices.DeleteFunc(names, func(session string) bool {
+		_, err := resilience.LoadManifest(session)
+		return err != nil
+	}), err
+}
+
+// runDaemonCapture archives a session's pane output until ctx is done.
+func runDaemonCapture(ctx context.Context, session string) error {
+	archiver, err := newSessionArchiver(session)
+	if err != nil {
+		return err
+	}
+	defer archiver.Close()
+	if err := archiver.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// runDaemonWatchdog returns a per-session run that starts the session's
+// internal-monitor whenever none is alive.
+func runDaemonWatchdog(interval time.Duration) func(context.Context, string) error {
+	if interval <= 0 {
+		interval = daemon.DefaultReconcileInterval
+	}
+	return func(ctx context.Context, session string) error {
+		if !shouldStartInternalMonitor() {
+			return nil
+		}
+		var child chan struct{} // Closed when the monitor we started exits
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			childAlive := false
+			if child != nil {
+				select {
+				case <-child:
+				default:
+					childAlive = true
+				}
+			}
+			// Our own child may not have written its marker yet.
+			if !childAlive && !monitorRunning(session) {
+				cmd, err := startInternalMonitor(session)
+				if err != nil {
+					return fmt.Errorf("starting monitor: %w", err)
+				}
+				slog.Info("daemon restarted session monitor", "session", session, "pid", cmd.Process.Pid)
+				done := make(chan struct{})
+				go func() {
+					_ = cmd.Wait()
+					close(done)
+				}()
+				child = done
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// sessionProjectDir returns the project directory of session: the one it
+// was spawned in if known, else the configured one.
+func sessionProjectDir(session string) string {
+	if manifest, err := resilience.LoadManifest(session); err == nil && manifest.ProjectDir != "" {
+		return manifest.ProjectDir
+	}
+	if cfg == nil {
+		return ""
+	}
+	return cfg.GetProjectDir(session)
+}
+
+// runDaemonConflicts watches a session's repository for conflicts,
+// recording them in the conflict history. Sessions outside a git
+// repository are skipped.
+func runDaemonConflicts(ctx context.Context, session string) error {
+	dir := sessionProjectDir(session)
+	if dir == "" || !git.IsGitRepository(dir) {
+		return nil
+	}
+	opts := robot.ConflictsOptions{RepoPath: dir, Session: session, Output: io.Discard}
+	if err := applyConflictsConfig(&opts); err != nil {
+		return err
+	}
+	return robot.WatchConflicts(ctx, opts)
+}
+
+// runDaemonCheckpoints checkpoints a session every [checkpoints]
+// interval_minutes until ctx is done.
+func runDaemonCheckpoints(ctx context.Context, session string) error {
+	cc := config.DefaultCheckpointsConfig()
+	if cfg != nil {
+		cc = cfg.Checkpoints
+	}
+	if !cc.Enabled || cc.IntervalMinutes <= 0 {
+		return nil
+	}
+	worker := checkpoint.NewBackgroundWorker(session, checkpoint.AutoCheckpointConfig{
+		Enabled:         true,
+		IntervalMinutes: cc.IntervalMinutes,
+		MaxCheckpoints:  cc.MaxAutoCheckpoints,
+		ScrollbackLines: cc.ScrollbackLines,
+		IncludeGit:      cc.IncludeGit,
+	})
+	worker.Start(ctx)
+	<-ctx.Done()
+	worker.Stop()
+	return nil
+}
+
+func runDaemonStatus() error {
+	st, err := daemon.ReadStatus("")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"running": false})
+			}
+			fmt.Println("Daemon has not been run (start it with 'ntm daemon')")
+			return nil
+		}
+		return err
+	}
+
+	running := st.Running()
+	if IsJSONOutput() {
+		return output.PrintJSON(struct {
+			Running bool `json:"running"`
+			*daemon.Status
+		}{running, st})
+	}
+
+	if running {
+		fmt.Printf("Daemon running (pid %d, since %s, updated %s ago)\n",
+			st.PID, st.StartedAt.Local().Format(time.DateTime), time.Since(st.UpdatedAt).Round(time.Second))
+	} else {
+		fmt.Printf("Daemon not running (last pid %d, updated %s)\n", st.PID, st.UpdatedAt.Local().Format(time.DateTime))
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SUBSYSTEM\tSTATE\tRESTARTS\tSESSIONS\tLAST ERROR")
+	for _, sub := range st.Subsystems {
+		state := string(sub.State)
+		if !running && sub.Enabled {
+			state = string(daemon.StateStopped)
+		}
+		lastErr := "-"
+		if sub.LastError != "" {
+			lastErr = fmt.Sprintf("%s (%s ago)", firstLine(sub.LastError), time.Since(sub.LastErrorAt).Round(time.Second))
+		}
+		sessions := "-"
+		if len(sub.Sessions) > 0 {
+			sessions = strings.Join(sub.Sessions, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", sub.Name, state, sub.Restarts, sessions, lastErr)
+	}
+	return w.Flush()
+}
+
+// firstLine returns s up to its first newline, dropping e.g. panic stacks.
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+)
+
+func TestDaemonDisabled(t *testing.T) {
+	dc := config.DefaultDaemonConfig()
+	dc.Capture = false
+
+	tests := []struct {
+		name            string
+		enable, disable []string
+		want            []string
+		wantErr         bool
+	}{
+		{"config", nil, nil, []string{"capture"}, false},
+		{"enable overrides config", []string{"capture"}, nil, nil, false},
+		{"disable adds", nil, []string{"retention", "conflicts"}, []string{"capture", "conflicts", "retention"}, false},
+		{"disable already off", nil, []string{"capture"}, []string{"capture"}, false},
+		{"unknown", nil, []string{"captures"}, nil, true},
+		{"both", []string{"watchdog"}, []string{"watchdog"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := daemonDisabled(dc, tt.enable, tt.disable)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal(got, tt.want) {
+				t.Errorf("disabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if subs := daemonSubsystems(0); len(subs) != len(config.DaemonSubsystems) {
+		t.Errorf("daemonSubsystems has %d subsystems, config knows %d", len(subs), len(config.DaemonSubsystems))
+	} else {
+		for i, sub := range subs {
+			if sub.Name != config.DaemonSubsystems[i] {
+				t.Errorf("subsystem %d = %q, want %q", i, sub.Name, config.DaemonSubsystems[i])
+			}
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
 	"github.com/Dicklesworthstone/ntm/internal/config"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
 	"github.com/Dicklesworthstone/ntm/internal/tui/dashboard"
 	"github.com/Dicklesworthstone/ntm/internal/watcher"
 )
@@ -314,12 +315,23 @@ func runDashboard(w io.Writer, errW io.Writer, session string, debug bool) error
 				Debug:                 cfg.FileReservation.Debug,
 			}
 
-			// Create conflict callback for notifications
+			// Create conflict callback for notifications and conflict history
+			conflictHistory := tracker.NewConflictHistory("")
 			conflictCallback := func(conflict watcher.FileConflict) {
 				if cfg.FileReservation.Debug {
 					log.Printf("[FileReservation] Conflict: %s requested by %s, held by %v",
 						conflict.Path, conflict.RequestorAgent, conflict.Holders)
 				}
+				if _, err := conflictHistory.RecordDetected(tracker.ConflictRecord{
+					Timestamp: conflict.DetectedAt,
+					Source:    tracker.ConflictSourceReservation,
+					Session:   conflict.SessionName,
+					Path:      conflict.Path,
+					Agents:    append([]string{conflict.RequestorAgent}, conflict.Holders...),
+					ExpiresAt: conflict.ExpiresAt,
+				}); err != nil && cfg.FileReservation.Debug {
+					log.Printf("[FileReservation] Recording conflict: %v", err)
+				}
 				// TODO: Integrate with dashboard notification system
 			}
 
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/deadline"
+)
+
+// applyDeadlines sets the default limits of long-running operations from
+// the [deadlines] config section.
+func applyDeadlines(c config.DeadlinesConfig) {
+	seconds := func(n int) time.Duration { return time.Duration(n) * time.Second }
+	deadline.Set(deadline.Git, seconds(c.GitSeconds))
+	deadline.Set(deadline.GitRemote, seconds(c.GitRemoteSeconds))
+	deadline.Set(deadline.ConflictScan, seconds(c.ConflictScanSeconds))
+	deadline.Set(deadline.OIDC, seconds(c.OIDCSeconds))
+}
+
+// interruptContext returns the context commands run under. The first
+// SIGINT or SIGTERM cancels it so that commands can stop what they are
+// doing and return. A command still running after the shutdown timeout, or
+// a second signal, ends the process. stop releases the signal handler once
+// the command has returned.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigCh:
+		case <-done:
+			return
+		}
+		cancel()
+		// Let a second signal kill the process the default way.
+		signal.Stop(sigCh)
+		select {
+		case <-time.After(shutdownTimeout()):
+			fmt.Fprintln(os.Stderr, "Error: interrupted")
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(sigCh)
+		close(done)
+		cancel()
+	}
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/deadline"
+)
+
+func TestApplyDeadlines(t *testing.T) {
+	defer deadline.Reset()
+
+	c := config.DefaultDeadlinesConfig()
+	c.GitSeconds = 7
+	c.OIDCSeconds = 0
+	applyDeadlines(c)
+	if got := deadline.Limit(deadline.Git); got != 7*time.Second {
+		t.Errorf("git = %v, want 7s", got)
+	}
+	if got := deadline.Limit(deadline.OIDC); got != 0 {
+		t.Errorf("oidc = %v, want none", got)
+	}
+	if got := deadline.Limit(deadline.GitRemote); got != 2*time.Minute {
+		t.Errorf("git_remote = %v, want the default", got)
+	}
+}
+
+func TestInterruptContext(t *testing.T) {
+	ctx, stop := interruptContext()
+	if ctx.Err() != nil {
+		t.Fatal("context cancelled before any signal")
+	}
+	stop()
+	if ctx.Err() == nil {
+		t.Error("stop did not cancel the context")
+	}
+}
@@ -121,6 +121,7 @@ Primary usage:
 	cmd.AddCommand(newEnsemblePresetsCmd())
 	cmd.AddCommand(newEnsembleExportCmd())
 	cmd.AddCommand(newEnsembleImportCmd())
+	cmd.AddCommand(newEnsembleValidateCmd())
 	cmd.AddCommand(newEnsembleStatusCmd())
 	cmd.AddCommand(newEnsembleStopCmd())
 	cmd.AddCommand(newEnsembleSuggestCmd())
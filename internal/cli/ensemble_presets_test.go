@@ -463,3 +463,86 @@ func TestRunEnsembleImport_RemoteChecksum(t *testing.T) {
 
 	t.Log("TEST: TestRunEnsembleImport_RemoteChecksum - assertion: checksum enforcement works")
 }
+
+func TestRunEnsembleValidate_Fix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ensembles.toml")
+	original := `[[ensembles]]
+name = "my-review"
+description = "review"
+modes = [{id = "deductiv"}, {code = "A1"}, {id = "edge-case"}]
+
+[ensembles.budget]
+max_tokens_per_mode = 300000
+max_total_tokens = 100000
+`
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err := runEnsembleValidate(&buf, nil, ensembleValidateOptions{File: path})
+	if err == nil {
+		t.Fatal("expected validation errors before fixing")
+	}
+	if !strings.Contains(buf.String(), "can be auto-fixed with --fix") {
+		t.Errorf("output does not mention --fix:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := runEnsembleValidate(&buf, nil, ensembleValidateOptions{File: path, Fix: true, DryRun: true}); err == nil {
+		t.Error("dry run reported the unchanged file as valid")
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Error("dry run wrote the file")
+	}
+
+	// Writing would reformat the file, so it takes --force.
+	buf.Reset()
+	if err := runEnsembleValidate(&buf, nil, ensembleValidateOptions{File: path, Fix: true}); err == nil {
+		t.Error("fix without --force reported the unwritten file as valid")
+	}
+	out := buf.String()
+	if !strings.Contains(out, `-modes = [{id = "deductiv"}`) || !strings.Contains(out, "--force") {
+		t.Errorf("output lacks the diff against the file on disk or the --force hint:\n%s", out)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Error("fix without --force rewrote a reformatted file")
+	}
+
+	buf.Reset()
+	if err := runEnsembleValidate(&buf, nil, ensembleValidateOptions{File: path, Fix: true, Force: true}); err != nil {
+		t.Fatalf("fix: %v\n%s", err, buf.String())
+	}
+	if out := buf.String(); !strings.Contains(out, "Wrote "+path) {
+		t.Errorf("output lacks the write notice:\n%s", out)
+	}
+
+	presets, err := ensemble.LoadEnsemblesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := presets[0]
+	if len(p.Modes) != 2 || p.Modes[0].ID != "deductive" || p.Modes[1].ID != "edge-case" {
+		t.Errorf("modes = %v, want [deductive edge-case]", p.Modes)
+	}
+	if p.Budget.MaxTokensPerMode != 100000 {
+		t.Errorf("max_tokens_per_mode = %d, want 100000", p.Budget.MaxTokensPerMode)
+	}
+
+	// A file already in canonical layout is written without --force.
+	p.Modes = append(p.Modes, ensemble.ModeRefFromID("deductiv"))
+	canonical, err := ensemble.MarshalEnsemblesFile([]ensemble.EnsemblePreset{p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, canonical, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := runEnsembleValidate(&buf, nil, ensembleValidateOptions{File: path, Fix: true}); err != nil {
+		t.Fatalf("fix canonical file: %v\n%s", err, buf.String())
+	}
+	if presets, _ := ensemble.LoadEnsemblesFile(path); len(presets) != 1 || len(presets[0].Modes) != 2 {
+		t.Errorf("canonical file was not fixed: %+v", presets)
+	}
+}
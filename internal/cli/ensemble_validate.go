@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/cm"
+	"github.com/Dicklesworthstone/ntm/internal/ensemble"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+)
+
+// ensembleValidateOptions holds flags for the ensemble validate command.
+type ensembleValidateOptions struct {
+	File   string
+	Fix    bool
+	DryRun bool
+	Force  bool
+}
+
+// ensembleValidatePreset is the validation result for one preset.
+type ensembleValidatePreset struct {
+	Name     string                     `json:"name"`
+	Errors   []ensemble.ValidationIssue `json:"errors"`
+	Warnings []ensemble.ValidationIssue `json:"warnings"`
+	// Fixes were applied with --fix; without it they are the fixes --fix
+	// would apply.
+	Fixes []ensemble.PresetFix `json:"fixes,omitempty"`
+}
+
+// ensembleValidateFile is the validation result for one preset file.
+type ensembleValidateFile struct {
+	Path    string                   `json:"path"`
+	Source  string                   `json:"source,omitempty"`
+	Presets []ensembleValidatePreset `json:"presets"`
+	Diff    string                   `json:"diff,omitempty"`
+	Written bool                     `json:"written,omitempty"`
+	// Reformatted is set when writing also changes the file's layout or
+	// drops its comments. Such a file is only written with --force.
+	Reformatted bool `json:"reformatted,omitempty"`
+}
+
+// ensembleValidateOutput is the top-level output structure.
+type ensembleValidateOutput struct {
+	GeneratedAt time.Time              `json:"generated_at"`
+	Valid       bool                   `json:"valid"`
+	Fixable     int                    `json:"fixable"`
+	Files       []ensembleValidateFile `json:"files"`
+}
+
+func newEnsembleValidateCmd() *cobra.Command {
+	var opts ensembleValidateOptions
+
+	cmd := &cobra.Command{
+		Use:   "validate [preset...]",
+		Short: "Validate ensemble preset files, optionally fixing them",
+		Long: `Validate the presets in the imported, user and project ensemble files
+(or --file), reporting errors, warnings and suggestions per preset.
+
+--fix applies the fixes that are unambiguous, writes the corrected file and
+prints a diff against the file on disk:
+  - an unknown mode id or code is replaced by its single nearest match,
+    if that is close enough
+  - a mode listed twice is removed
+  - negative budgets are reset to 0 (the default); budgets above their
+    bounds, or a per-mode budget above the total, are clamped
+
+The file is written in canonical TOML layout. If that would change more
+than the fixes, such as its formatting or comments, the diff shows it and
+the file is only written with --force.
+
+Other errors are reported for you to fix by hand. The command exits
+non-zero while any error remains.
+
+Examples:
+  ntm ensemble validate
+  ntm ensemble validate my-review --fix
+  ntm ensemble validate --file .ntm/ensembles.toml --fix --dry-run
+  ntm ensemble validate --file .ntm/ensembles.toml --fix --force`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEnsembleValidate(cmd.OutOrStdout(), args, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.File, "file", "", "Validate this ensembles TOML file only")
+	cmd.Flags().BoolVar(&opts.Fix, "fix", false, "Apply unambiguous fixes and write the corrected file")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "With --fix, print the diff without writing")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "With --fix, write even if the file's layout or comments change")
+	return cmd
+}
+
+func runEnsembleValidate(w io.Writer, names []string, opts ensembleValidateOptions) error {
+	catalog, err := ensemble.GlobalCatalog()
+	if err != nil {
+		return fmt.Errorf("load mode catalog: %w", err)
+	}
+
+	files := ensemble.NewEnsembleLoader(catalog).Files()
+	if opts.File != "" {
+		files = []ensemble.EnsembleFile{{Path: opts.File}}
+	}
+
+	// Presets may extend embedded presets or those of another file.
+	all := slices.Clone(ensemble.EmbeddedEnsembles)
+	loaded := make([][]ensemble.EnsemblePreset, len(files))
+	for i, f := range files {
+		if _, err := os.Stat(f.Path); err != nil {
+			if opts.File != "" {
+				return err
+			}
+			continue
+		}
+		presets, err := ensemble.LoadEnsemblesFile(f.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+		loaded[i] = presets
+		all = append(all, presets...)
+	}
+	registry := ensemble.NewEnsembleRegistry(all, catalog)
+
+	result := ensembleValidateOutput{
+		GeneratedAt: output.Timestamp(),
+		Valid:       true,
+		Files:       []ensembleValidateFile{},
+	}
+	found := make(map[string]bool, len(names))
+	for i, f := range files {
+		presets := loaded[i]
+		if presets == nil {
+			continue
+		}
+		fileResult := ensembleValidateFile{Path: f.Path, Source: f.Source, Presets: []ensembleValidatePreset{}}
+		changed := false
+		for j := range presets {
+			if len(names) > 0 && !slices.Contains(names, presets[j].Name) {
+				continue
+			}
+			found[presets[j].Name] = true
+
+			fixed, fixes := ensemble.FixEnsemblePreset(presets[j], catalog)
+			if opts.Fix && len(fixes) > 0 {
+				presets[j] = fixed
+				changed = true
+			}
+			report := ensemble.ValidateEnsemblePreset(&presets[j], catalog, registry)
+			if report.HasErrors() {
+				result.Valid = false
+			}
+			if !opts.Fix {
+				result.Fixable += len(fixes)
+			}
+			fileResult.Presets = append(fileResult.Presets, ensembleValidatePreset{
+				Name:     presets[j].Name,
+				Errors:   report.Errors,
+				Warnings: report.Warnings,
+				Fixes:    fixes,
+			})
+		}
+
+		if changed {
+			// Diff against the bytes on disk, so the diff shows everything
+			// writing would change, layout and comments included.
+			onDisk, err := os.ReadFile(f.Path)
+			if err != nil {
+				return err
+			}
+			original, err := ensemble.LoadEnsemblesFile(f.Path)
+			if err != nil {
+				return err
+			}
+			before, err := ensemble.MarshalEnsemblesFile(original)
+			if err != nil {
+				return err
+			}
+			after, err := ensemble.MarshalEnsemblesFile(presets)
+			if err != nil {
+				return err
+			}
+			fileResult.Diff = cm.UnifiedDiff(filepath.Base(f.Path), string(onDisk), string(after))
+			fileResult.Reformatted = string(onDisk) != string(before)
+			if opts.DryRun || (fileResult.Reformatted && !opts.Force) {
+				// The file on disk still has the errors the fixes address.
+				result.Valid = false
+			} else {
+				if err := ensemble.SaveEnsemblesFile(f.Path, presets); err != nil {
+					return err
+				}
+				fileResult.Written = true
+			}
+		}
+		result.Files = append(result.Files, fileResult)
+	}
+	for _, name := range names {
+		if !found[name] {
+			return fmt.Errorf("preset %q not found in any ensemble file", name)
+		}
+	}
+
+	if jsonOutput {
+		if err := output.WriteJSON(w, result, true); err != nil {
+			return err
+		}
+	} else {
+		renderEnsembleValidate(w, result, opts)
+	}
+	if !result.Valid {
+		return fmt.Errorf("ensemble presets have validation errors")
+	}
+	return nil
+}
+
+func renderEnsembleValidate(w io.Writer, result ensembleValidateOutput, opts ensembleValidateOptions) {
+	if len(result.Files) == 0 {
+		fmt.Fprintln(w, "No ensemble preset files found.")
+		return
+	}
+	for _, f := range result.Files {
+		fmt.Fprintf(w, "%s\n", f.Path)
+		for _, p := range f.Presets {
+			if len(p.Errors) == 0 && len(p.Warnings) == 0 && (len(p.Fixes) == 0 || opts.Fix) {
+				fmt.Fprintf(w, "  ✓ %s\n", p.Name)
+			}
+			if opts.Fix {
+				for _, fix := range p.Fixes {
+					fmt.Fprintf(w, "  ✎ %s: %s: %s\n", p.Name, fix.Field, fix.Message)
+				}
+			}
+			for _, issue := range p.Errors {
+				fmt.Fprintf(w, "  ✗ %s: %s%s\n", p.Name, ensembleIssueText(issue), ensembleFixableSuffix(issue, p.Fixes, opts.Fix))
+			}
+			for _, issue := range p.Warnings {
+				fmt.Fprintf(w, "  ⚠ %s: %s\n", p.Name, ensembleIssueText(issue))
+			}
+		}
+		if f.Diff != "" {
+			fmt.Fprintf(w, "\n%s", f.Diff)
+			switch {
+			case f.Written && f.Reformatted:
+				fmt.Fprintf(w, "Wrote %s (rewritten in canonical TOML layout; comments are not kept)\n", f.Path)
+			case f.Written:
+				fmt.Fprintf(w, "Wrote %s\n", f.Path)
+			case opts.DryRun:
+				fmt.Fprintln(w, "Dry run: file not written")
+			default:
+				fmt.Fprintf(w, "Not written: %s would be rewritten in canonical TOML layout, changing more than the fixes; use --force to write it anyway\n", f.Path)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	if result.Fixable > 0 {
+		fmt.Fprintf(w, "%d issues can be auto-fixed with --fix\n", result.Fixable)
+	}
+}
+
+func ensembleIssueText(issue ensemble.ValidationIssue) string {
+	text := issue.Message
+	if issue.Field != "" {
+		text = issue.Field + ": " + text
+	}
+	if len(issue.Suggestions) > 0 {
+		text += fmt.Sprintf(" (did you mean %v?)", issue.Suggestions)
+	}
+	return text
+}
+
+// ensembleFixableSuffix marks an error that --fix would resolve.
+func ensembleFixableSuffix(issue ensemble.ValidationIssue, fixes []ensemble.PresetFix, applied bool) string {
+	if applied {
+		return ""
+	}
+	for _, fix := range fixes {
+		if fix.Code == issue.Code && strings.HasPrefix(fix.Field, issue.Field) {
+			return " (--fix)"
+		}
+	}
+	return ""
+}
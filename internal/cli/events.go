@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/webhook"
+)
+
+func newEventsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Inspect and replay the persisted event stream",
+		Long: `Inspect and replay events published on the NTM event bus.
+
+Every event published during an ntm command is appended to the bus journal
+(~/.config/ntm/analytics/bus_events.jsonl), subject to the same redaction,
+encryption, and privacy settings as the analytics event log.
+
+Examples:
+  ntm events catalog
+  ntm events replay --session myproject --from 2h --speed 10x
+  ntm events replay --session myproject --from 2026-01-02T15:00:00Z --to 2026-01-02T15:30:00Z --type 'agent.*'
+  ntm events replay --session myproject --from 1h --webhooks --speed max`,
+	}
+
+	cmd.AddCommand(newEventsCatalogCmd(), newEventsReplayCmd())
+	return cmd
+}
+
+func newEventsCatalogCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "catalog [type]",
+		Short: "List documented event types and their payload fields",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			specs := events.Catalog()
+			if len(args) > 0 {
+				spec, ok := events.LookupEvent(args[0])
+				if !ok {
+					return fmt.Errorf("unknown event type %q", args[0])
+				}
+				specs = []events.EventSpec{spec}
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(specs)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, spec := range specs {
+				fmt.Fprintf(w, "%s\t%s\n", spec.Type, spec.Description)
+				if len(args) == 0 {
+					continue
+				}
+				for _, f := range spec.Fields {
+					opt := ""
+					if f.Optional {
+						opt = " (optional)"
+					}
+					fmt.Fprintf(w, "  %s\t%s%s\n", f.Name, f.Type, opt)
+				}
+			}
+			return w.Flush()
+		},
+	}
+}
+
+type eventsReplayOptions struct {
+	session  string
+	from     string
+	to       string
+	speed    string
+	maxGap   time.Duration
+	types    []string
+	webhooks bool
+	journal  string
+}
+
+func newEventsReplayCmd() *cobra.Command {
+	var opts eventsReplayOptions
+
+	cmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Re-emit recorded events with their original timing",
+		Long: `Re-emit recorded events to subscribers, preserving their relative timing.
+
+Events are printed to stdout as they are replayed (one JSON object per line
+with --json). With --webhooks, they are also dispatched to the project's
+configured webhooks so downstream dashboards see the incident as it happened.
+
+--from and --to accept RFC3339 timestamps or relative times such as 30m, 2h, 7d.
+--speed accepts a multiplier (1, 10x, 0.5x) or "max" to replay without delays.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEventsReplay(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.session, "session", "", "Only replay events from this session")
+	cmd.Flags().StringVar(&opts.from, "from", "", "Start of the replay window (RFC3339 or relative, e.g. 2h)")
+	cmd.Flags().StringVar(&opts.to, "to", "", "End of the replay window (RFC3339 or relative)")
+	cmd.Flags().StringVar(&opts.speed, "speed", "1x", "Playback speed multiplier, or 'max' for no delay")
+	cmd.Flags().DurationVar(&opts.maxGap, "max-gap", 0, "Cap the delay between consecutive events (0 = no cap)")
+	cmd.Flags().StringSliceVar(&opts.types, "type", nil, "Only replay these event types (glob patterns allowed)")
+	cmd.Flags().BoolVar(&opts.webhooks, "webhooks", false, "Also dispatch replayed events to project webhooks")
+	cmd.Flags().StringVar(&opts.journal, "journal", "", "Journal file to read (default: the bus event journal)")
+	return cmd
+}
+
+// parseReplaySpeed parses "10x", "0.5", or "max" into a multiplier.
+// Zero means replay without delay.
+func parseReplaySpeed(s string) (float64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 1, nil
+	}
+	if s == "max" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "x"), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid speed %q: use a multiplier like 10x or 'max'", s)
+	}
+	return v, nil
+}
+
+func runEventsReplay(ctx context.Context, opts eventsReplayOptions) error {
+	speed, err := parseReplaySpeed(opts.speed)
+	if err != nil {
+		return err
+	}
+
+	filter := events.JournalFilter{Session: opts.session, Types: opts.types}
+	if opts.from != "" {
+		if filter.From, err = parseTimeArg(opts.from); err != nil {
+			return fmt.Errorf("--from: %w", err)
+		}
+	}
+	if opts.to != "" {
+		if filter.To, err = parseTimeArg(opts.to); err != nil {
+			return fmt.Errorf("--to: %w", err)
+		}
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return fmt.Errorf("--to must not be before --from")
+	}
+
+	entries, err := events.ReadJournal(opts.journal, filter)
+	if err != nil {
+		return err
+	}
+
+	// Replay onto a private bus so replayed events are not re-recorded into
+	// the journal or mixed with live events.
+	bus := events.NewEventBus(100)
+	if IsJSONOutput() {
+		unsubscribe := bus.EnableRobotMode(os.Stdout)
+		defer unsubscribe()
+	} else {
+		var mu sync.Mutex
+		unsubscribe := bus.SubscribeAll(func(e events.BusEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			session := e.EventSession()
+			if session == "" {
+				session = "-"
+			}
+			fmt.Printf("%s  %-12s  %s\n", e.EventTimestamp().Local().Format("15:04:05.000"), session, e.EventType())
+		})
+		defer unsubscribe()
+	}
+
+	if opts.webhooks {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		var bridge *webhook.BusBridge
+		if cfg != nil {
+			redactCfg := cfg.Redaction.ToRedactionLibConfig()
+			bridge, err = webhook.StartBridgeFromProjectConfig(dir, opts.session, bus, &redactCfg)
+		} else {
+			bridge, err = webhook.StartBridgeFromProjectConfig(dir, opts.session, bus, nil)
+		}
+		if err != nil {
+			return fmt.Errorf("starting webhooks: %w", err)
+		}
+		if bridge == nil {
+			output.PrintWarningf("no webhooks configured for %s; replaying to stdout only", dir)
+		} else {
+			defer bridge.Close()
+		}
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	published, err := events.Replay(ctx, bus, entries, events.ReplayOptions{
+		Speed:  speed,
+		MaxGap: opts.maxGap,
+		Typed:  true,
+	})
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Println("No recorded events match the replay window.")
+		return nil
+	}
+	fmt.Printf("\nReplayed %d of %d events", published, len(entries))
+	if speed > 0 {
+		fmt.Printf(" at %gx", speed)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,25 @@
+package cli
+
+import "testing"
+
+func TestParseReplaySpeed(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"", 1, false},
+		{"1x", 1, false},
+		{"10x", 10, false},
+		{"0.5", 0.5, false},
+		{"MAX", 0, false},
+		{"fast", 0, true},
+		{"-2x", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseReplaySpeed(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseReplaySpeed(%q) = %v, %v; want %v, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
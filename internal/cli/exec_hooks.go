@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/hooks"
+)
+
+// execHooksTimeout bounds a whole chain of exec hooks for one event; each
+// hook has its own, shorter timeout.
+const execHooksTimeout = 5 * time.Minute
+
+// runExecHooks runs the exec hooks in projectDir/.ntm/hooks for event. It
+// returns nil when there are none, and an error wrapping hooks.ErrVetoed
+// when one vetoed the action.
+func runExecHooks(projectDir string, event hooks.ExecEvent, session string, payload map[string]interface{}) (*hooks.ExecOutcome, error) {
+	if projectDir == "" {
+		return nil, nil
+	}
+	runner := hooks.NewExecRunner(projectDir)
+	if !runner.HasHooks(event) {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), execHooksTimeout)
+	defer cancel()
+	out := runner.Run(ctx, event, session, payload)
+	return out, out.Err()
+}
+
+// notifyExecHooks runs the exec hooks of a notification event, logging
+// the hooks that failed.
+func notifyExecHooks(projectDir string, event hooks.ExecEvent, session string, payload map[string]interface{}) {
+	out, _ := runExecHooks(projectDir, event, session, payload)
+	if out == nil {
+		return
+	}
+	for _, res := range out.Results {
+		if res.Error != "" {
+			slog.Warn("exec hook failed", "event", event, "hook", res.Path, "error", res.Error)
+		}
+	}
+}
+
+// execHookBridge runs on-complete exec hooks for the agent completions a
+// session publishes on the event bus.
+type execHookBridge struct {
+	unsubscribe events.UnsubscribeFunc
+	mu          sync.Mutex
+	closed      bool
+	wg          sync.WaitGroup
+}
+
+// startExecHookBridge subscribes to the session's agent completions. It
+// returns nil when the project has no on-complete hooks.
+func startExecHookBridge(projectDir, session string) *execHookBridge {
+	if projectDir == "" || !hooks.NewExecRunner(projectDir).HasHooks(hooks.ExecOnComplete) {
+		return nil
+	}
+	b := &execHookBridge{}
+	b.unsubscribe = events.DefaultBus.Subscribe(events.WebhookAgentCompleted, func(e events.BusEvent) {
+		var ev events.WebhookEvent
+		switch v := e.(type) {
+		case events.WebhookEvent:
+			ev = v
+		case *events.WebhookEvent:
+			if v == nil {
+				return
+			}
+			ev = *v
+		default:
+			return
+		}
+		if strings.TrimSpace(session) != "" && ev.Session != session {
+			return
+		}
+
+		payload := map[string]interface{}{
+			"pane":    ev.Pane,
+			"agent":   ev.Agent,
+			"message": ev.Message,
+		}
+		for k, v := range ev.Details {
+			if _, ok := payload[k]; !ok {
+				payload[k] = v
+			}
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.closed {
+			return
+		}
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			notifyExecHooks(projectDir, hooks.ExecOnComplete, ev.Session, payload)
+		}()
+	})
+	return b
+}
+
+// Close stops the bridge and waits for running hooks to finish.
+func (b *execHookBridge) Close() {
+	if b == nil {
+		return
+	}
+	b.unsubscribe()
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+func newHooksExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec",
+		Short: "List and try the project's exec hooks in .ntm/hooks/",
+		Long: `Exec hooks are executables in the project's .ntm/hooks/ directory that run
+on lifecycle events. Each receives the event as JSON on stdin and may reply
+with JSON on stdout to change the action or veto it.
+
+Hooks for an event are .ntm/hooks/<event> followed by the files in
+.ntm/hooks/<event>.d/ in name order. Events:
+
+  pre-spawn     Before agents are spawned (can modify prompt, init_prompt; can veto)
+  post-capture  Before captured output is archived (can modify content; veto drops it)
+  pre-send      Before a prompt is sent (can modify message; can veto)
+  on-conflict   Conflict detection found new conflicts (notification)
+  on-complete   An agent completed its task (notification)`,
+	}
+	cmd.AddCommand(newHooksExecListCmd(), newHooksExecRunCmd())
+	return cmd
+}
+
+func newHooksExecListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List exec hooks by event",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runner := hooks.NewExecRunner(GetProjectRoot())
+			found := make(map[hooks.ExecEvent][]string)
+			for _, event := range hooks.AllExecEvents() {
+				if paths := runner.Hooks(event); len(paths) > 0 {
+					found[event] = paths
+				}
+			}
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(map[string]interface{}{
+					"hooks_dir": runner.Dir(),
+					"hooks":     found,
+				})
+			}
+			if len(found) == 0 {
+				fmt.Printf("No exec hooks in %s\n", runner.Dir())
+				return nil
+			}
+			fmt.Printf("Exec hooks in %s:\n", runner.Dir())
+			for _, event := range hooks.AllExecEvents() {
+				for _, path := range found[event] {
+					rel, err := filepath.Rel(runner.Dir(), path)
+					if err != nil {
+						rel = path
+					}
+					fmt.Printf("  %-13s %s\n", event, filepath.ToSlash(rel))
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newHooksExecRunCmd() *cobra.Command {
+	var payload, session string
+
+	cmd := &cobra.Command{
+		Use:   "run <event>",
+		Short: "Run an event's exec hooks with a payload and show the outcome",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			event := hooks.ExecEvent(args[0])
+			if !slices.Contains(hooks.AllExecEvents(), event) {
+				return fmt.Errorf("unknown exec hook event %q", args[0])
+			}
+			var data map[string]interface{}
+			if payload != "" {
+				if err := json.Unmarshal([]byte(payload), &data); err != nil {
+					return fmt.Errorf("parsing --payload: %w", err)
+				}
+			}
+
+			runner := hooks.NewExecRunner(GetProjectRoot())
+			ctx, cancel := context.WithTimeout(context.Background(), execHooksTimeout)
+			defer cancel()
+			out := runner.Run(ctx, event, session, data)
+			if jsonOutput {
+				if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+					return err
+				}
+				return out.Err()
+			}
+
+			if len(out.Results) == 0 {
+				fmt.Printf("No %s hooks in %s\n", event, runner.Dir())
+				return nil
+			}
+			for _, res := range out.Results {
+				status := "ok"
+				switch {
+				case res.Vetoed:
+					status = "veto: " + res.Reason
+				case res.Error != "":
+					status = "error: " + res.Error
+				case len(res.Modified) > 0:
+					status = "modified " + strings.Join(res.Modified, ", ")
+				}
+				fmt.Printf("  %-30s %6s  %s\n", filepath.Base(res.Path), res.Duration.Round(time.Millisecond), status)
+			}
+			if out.Modified() {
+				data, _ := json.MarshalIndent(out.Payload, "", "  ")
+				fmt.Printf("Payload:\n%s\n", data)
+			}
+			return out.Err()
+		},
+	}
+
+	cmd.Flags().StringVar(&payload, "payload", "", "Event payload as a JSON object")
+	cmd.Flags().StringVar(&session, "session", "", "Session name to pass to the hooks")
+	return cmd
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/hooks"
+)
+
+func TestExecHookBridge_OnComplete(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec hook tests use shell scripts")
+	}
+	dir := t.TempDir()
+	if startExecHookBridge(dir, "proj") != nil {
+		t.Fatal("bridge started without on-complete hooks")
+	}
+	hook := filepath.Join(dir, ".ntm", "hooks", "on-complete")
+	if err := os.MkdirAll(filepath.Dir(hook), 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat >> \"$NTM_PROJECT_DIR/completed.jsonl\"\n"
+	if err := os.WriteFile(hook, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	bridge := startExecHookBridge(dir, "proj")
+	if bridge == nil {
+		t.Fatal("bridge not started")
+	}
+	events.DefaultBus.PublishSync(events.NewWebhookEvent(events.WebhookAgentCompleted, "other", "1", "claude", "done", nil))
+	events.DefaultBus.PublishSync(events.NewWebhookEvent(events.WebhookAgentCompleted, "proj", "2", "claude", "done",
+		map[string]string{"bead_id": "bd-7"}))
+	bridge.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "completed.jsonl"))
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 {
+		t.Fatalf("hook ran %d times, want 1: %s", len(lines), data)
+	}
+	for _, want := range []string{`"event":"on-complete"`, `"session":"proj"`, `"pane":"2"`, `"bead_id":"bd-7"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("request missing %s: %s", want, data)
+		}
+	}
+
+	// Vetoes are reported for actionable events.
+	if err := os.WriteFile(filepath.Join(dir, ".ntm", "hooks", "pre-send"), []byte("#!/bin/sh\nexit 2\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runExecHooks(dir, hooks.ExecPreSend, "proj", nil); !errors.Is(err, hooks.ErrVetoed) {
+		t.Errorf("runExecHooks err = %v", err)
+	}
+	if out, err := runExecHooks(dir, hooks.ExecPreSpawn, "proj", nil); out != nil || err != nil {
+		t.Errorf("runExecHooks without hooks = %v, %v", out, err)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -137,6 +138,12 @@ func runExtract(sessionName, paneIndex, language string, lastPane bool, lines in
 		source = fmt.Sprintf("%s:%s", sessionName, paneIndex)
 	}
 
+	wasmCtx := context.Background()
+	wasmRuntime, wasmPlugins := loadWasmPlugins(wasmCtx)
+	if wasmRuntime != nil {
+		defer wasmRuntime.Close(wasmCtx)
+	}
+
 	for _, pane := range targetPanes {
 		// Capture pane output
 		captured, err := tmux.CapturePaneOutput(pane.ID, lines)
@@ -144,8 +151,9 @@ func runExtract(sessionName, paneIndex, language string, lastPane bool, lines in
 			continue // Skip panes that fail
 		}
 
-		// Parse code blocks
+		// Parse code blocks, adding any found by WASM extract plugins
 		blocks := parser.Parse(captured)
+		blocks = append(blocks, wasmExtractBlocks(wasmCtx, wasmPlugins, captured, language)...)
 
 		// Add source pane info
 		for i := range blocks {
@@ -0,0 +1,564 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/replay"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// recordEnvVar names a fixture bundle that every ntm command records into.
+const recordEnvVar = "NTM_RECORD"
+
+// RecordResult is the JSON output of `ntm fixture record`.
+type RecordResult struct {
+	Session      string `json:"session"`
+	Bundle       string `json:"bundle"`
+	Interactions int    `json:"interactions"`
+	Captures     int    `json:"captures"`
+	Sends        int    `json:"sends"`
+}
+
+// TranscriptAddResult is the JSON output of `ntm fixture transcript add`.
+type TranscriptAddResult struct {
+	Path      string                  `json:"path"`
+	AgentType string                  `json:"agent_type"`
+	Version   string                  `json:"version"`
+	Redacted  bool                    `json:"redacted"`
+	Expect    replay.TranscriptResult `json:"expect"`
+}
+
+// TranscriptCheckResult is the JSON output of `ntm fixture transcript check`.
+type TranscriptCheckResult struct {
+	Corpus      string                 `json:"corpus"`
+	Transcripts int                    `json:"transcripts"`
+	Failures    []TranscriptRegression `json:"failures,omitempty"`
+	Updated     int                    `json:"updated,omitempty"`
+}
+
+// TranscriptRegression is a corpus transcript whose detector results
+// changed.
+type TranscriptRegression struct {
+	Path  string   `json:"path"`
+	Diffs []string `json:"diffs"`
+}
+
+// FixtureReplayResult is the JSON output of `ntm fixture replay`.
+type FixtureReplayResult struct {
+	Report *replay.Report `json:"report"`
+	// Expect is the golden report compared against, if any.
+	Expect  string   `json:"expect,omitempty"`
+	Diffs   []string `json:"diffs,omitempty"`
+	Updated bool     `json:"updated,omitempty"`
+}
+
+func newFixtureCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fixture",
+		Short: "Record and replay agent interactions as test fixtures",
+		Long: `Record a session's tmux interactions (prompts, captures, and outputs) into
+a fixture bundle, and replay bundles deterministically through the capture,
+extraction, and scoring pipelines to regression-test the analysis stack
+against real transcripts.
+
+A bundle is a directory holding manifest.json and interactions.jsonl.
+
+Single agent screens are kept in a transcript corpus instead; see
+'ntm fixture transcript'.`,
+	}
+	cmd.AddCommand(newFixtureRecordCmd(), newFixtureReplayCmd(), newFixtureTranscriptCmd())
+	return cmd
+}
+
+func newFixtureRecordCmd() *cobra.Command {
+	var (
+		outDir   string
+		interval time.Duration
+		lines    int
+		duration time.Duration
+		noRedact bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "record [session]",
+		Short: "Record a session's tmux interactions into a fixture bundle",
+		Long: `Capture every pane of a session at an interval, recording outputs (and
+the tmux commands used to read them) into a fixture bundle until Ctrl-C or
+--duration. Unchanged captures are not recorded again.
+
+Prompts sent by other ntm commands are recorded when those commands run
+with NTM_RECORD set to the bundle directory:
+
+  NTM_RECORD=.ntm/fixtures/myproject-20260101-120000 ntm send myproject "fix the tests"
+
+Secrets are redacted from the bundle unless --no-redact is given.
+Replay the bundle with 'ntm fixture replay'.
+
+Examples:
+  ntm fixture record myproject
+  ntm fixture record myproject --interval 5s --duration 30m --out testdata/login-fix`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := tmux.EnsureInstalled(); err != nil {
+				return err
+			}
+			session := ""
+			if len(args) == 1 {
+				session = args[0]
+			}
+			res, err := ResolveSession(session, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			if res.Session == "" {
+				return nil
+			}
+			session = res.Session
+
+			panes, err := tmux.GetPanes(session)
+			if err != nil {
+				return err
+			}
+			if outDir == "" {
+				dir := getSessionWorkingDir(session)
+				if dir == "" {
+					dir, _ = os.Getwd()
+				}
+				outDir = filepath.Join(dir, ".ntm", "fixtures", fmt.Sprintf("%s-%s", session, time.Now().Format("20060102-150405")))
+			}
+			m := replay.Manifest{Session: session}
+			for _, p := range panes {
+				m.Panes = append(m.Panes, replay.PaneInfo{Index: p.Index, ID: p.ID, Title: p.Title, AgentType: string(p.Type)})
+			}
+			if err := replay.Create(outDir, m); err != nil {
+				return err
+			}
+			rec, err := replay.NewRecorder(outDir)
+			if err != nil {
+				return err
+			}
+			if !noRedact {
+				rec.SetRedaction(fixtureRedaction())
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if duration > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, duration)
+				defer cancel()
+			}
+			if !IsJSONOutput() {
+				fmt.Fprintf(cmd.OutOrStdout(), "Recording %s into %s (Ctrl-C to stop)\n", session, outDir)
+			}
+
+			stopRecording := rec.Start()
+			err = recordSession(ctx, session, panes, interval, lines)
+			stopRecording()
+			if err != nil {
+				return err
+			}
+			if err := rec.Err(); err != nil {
+				return fmt.Errorf("writing fixture bundle: %w", err)
+			}
+
+			b, err := replay.Load(outDir)
+			if err != nil {
+				return err
+			}
+			result := RecordResult{
+				Session:      session,
+				Bundle:       outDir,
+				Interactions: len(b.Interactions),
+				Captures:     b.Count(replay.KindCapture),
+				Sends:        b.Count(replay.KindSend),
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(result)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "✓ Recorded %d interactions (%d captures, %d sends)\n", result.Interactions, result.Captures, result.Sends)
+			return nil
+		},
+		ValidArgsFunction: completeSessionArgs,
+	}
+
+	cmd.Flags().StringVar(&outDir, "out", "", "Bundle directory (default .ntm/fixtures/<session>-<time>)")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Time between captures")
+	cmd.Flags().IntVar(&lines, "lines", 500, "Scrollback lines per capture")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "Stop after this long (0 = until Ctrl-C)")
+	cmd.Flags().BoolVar(&noRedact, "no-redact", false, "Keep secrets in recorded prompts and outputs")
+	return cmd
+}
+
+// recordSession captures every pane at interval until ctx is done or the
+// session ends.
+func recordSession(ctx context.Context, session string, panes []tmux.Pane, interval time.Duration, lines int) error {
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, p := range panes {
+			if _, err := tmux.CapturePaneOutputContext(ctx, p.ID, lines); err != nil && ctx.Err() == nil && !tmux.SessionExists(session) {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fixtureRedaction redacts secrets using the configured patterns, whatever
+// the configured mode: fixtures are meant to be shared.
+func fixtureRedaction() *redaction.Config {
+	rc := redaction.DefaultConfig()
+	if cfg != nil {
+		rc = cfg.Redaction.ToRedactionLibConfig()
+	}
+	rc.Mode = redaction.ModeRedact
+	return &rc
+}
+
+// startRecordingFromEnv records this process's tmux interactions into the
+// bundle named by NTM_RECORD, if set.
+func startRecordingFromEnv() {
+	dir := os.Getenv(recordEnvVar)
+	if dir == "" {
+		return
+	}
+	rec, err := replay.NewRecorder(dir)
+	if err != nil {
+		output.PrintWarningf("%s: %v", recordEnvVar, err)
+		return
+	}
+	rec.SetRedaction(fixtureRedaction())
+	rec.Start()
+}
+
+func newFixtureReplayCmd() *cobra.Command {
+	var (
+		expect string
+		update bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay <bundle>",
+		Short: "Replay a fixture bundle through the analysis pipelines",
+		Long: `Feed a recorded fixture bundle back through tmux capture, agent state
+parsing, error and compaction detection, code block extraction, and session
+summarization, without a tmux server. The result is deterministic.
+
+With --expect, the report is compared to a golden report and the command
+fails on any difference. --update rewrites the golden report instead.
+
+Examples:
+  ntm fixture replay .ntm/fixtures/myproject-20260101-120000
+  ntm fixture replay testdata/login-fix --expect testdata/login-fix.golden.json
+  ntm fixture replay testdata/login-fix --expect testdata/login-fix.golden.json --update`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := replay.Load(args[0])
+			if err != nil {
+				return err
+			}
+			report, err := replay.Analyze(cmd.Context(), b)
+			if err != nil {
+				return err
+			}
+			result := FixtureReplayResult{Report: report, Expect: expect}
+
+			if expect != "" && update {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := util.AtomicWriteFile(expect, append(data, '\n'), 0644); err != nil {
+					return fmt.Errorf("writing golden report: %w", err)
+				}
+				result.Updated = true
+			} else if expect != "" {
+				data, err := os.ReadFile(expect)
+				if err != nil {
+					return fmt.Errorf("reading golden report: %w", err)
+				}
+				var want replay.Report
+				if err := json.Unmarshal(data, &want); err != nil {
+					return fmt.Errorf("parsing golden report %s: %w", expect, err)
+				}
+				result.Diffs = replay.Compare(report, &want)
+			}
+
+			if IsJSONOutput() {
+				if err := output.PrintJSON(result); err != nil {
+					return err
+				}
+			} else {
+				printFixtureReplay(cmd, result)
+			}
+			if len(result.Diffs) > 0 {
+				return fmt.Errorf("replay of %s differs from %s in %d place(s)", args[0], expect, len(result.Diffs))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&expect, "expect", "", "Golden report to compare against")
+	cmd.Flags().BoolVar(&update, "update", false, "Rewrite the golden report from this replay")
+	return cmd
+}
+
+func printFixtureReplay(cmd *cobra.Command, r FixtureReplayResult) {
+	w := cmd.OutOrStdout()
+	rep := r.Report
+	fmt.Fprintf(w, "Replayed %s: %d captures, %d sends\n\n", rep.Session, rep.Captures, rep.Sends)
+	for _, s := range rep.Steps {
+		line := fmt.Sprintf("  #%-4d pane %-2d %-4s %-22s %.2f  %d code blocks", s.Seq, s.Pane, s.AgentType, s.Recommendation, s.Confidence, s.CodeBlocks)
+		if len(s.Errors) > 0 {
+			line += fmt.Sprintf("  errors: %v", s.Errors)
+		}
+		if s.Compaction {
+			line += "  compaction"
+		}
+		fmt.Fprintln(w, line)
+	}
+	switch {
+	case r.Updated:
+		fmt.Fprintf(w, "\n✓ Updated %s\n", r.Expect)
+	case r.Expect != "" && len(r.Diffs) == 0:
+		fmt.Fprintf(w, "\n✓ Matches %s\n", r.Expect)
+	case len(r.Diffs) > 0:
+		fmt.Fprintf(w, "\n✗ %d difference(s) from %s:\n", len(r.Diffs), r.Expect)
+		for _, d := range r.Diffs {
+			fmt.Fprintln(w, d)
+		}
+	}
+}
+
+// defaultTranscriptCorpus is the corpus directory, relative to the ntm
+// source tree, that the detector regression tests read.
+const defaultTranscriptCorpus = "testdata/transcripts"
+
+func newFixtureTranscriptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transcript",
+		Short: "Collect agent screens into the detector regression corpus",
+		Long: `Manage the transcript corpus: single, sanitized agent screens stored at
+<corpus>/<agent>/<version>/<name>.txt, each with a <name>.json beside it
+holding what the status, rate limit, completion, and extraction detectors
+must report for it. The corpus tests fail when a pattern change alters any
+of those results.
+
+Name transcripts <phase>.<description>, e.g. working.spinner or
+awaiting_input.edit-permission, after what the screen shows.`,
+	}
+	cmd.AddCommand(newFixtureTranscriptAddCmd(), newFixtureTranscriptCheckCmd())
+	return cmd
+}
+
+func newFixtureTranscriptAddCmd() *cobra.Command {
+	var (
+		corpus     string
+		name       string
+		agentType  string
+		cliVersion string
+		lines      int
+		force      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add [session] <pane>",
+		Short: "Capture a pane into the transcript corpus",
+		Long: `Capture the visible screen of an agent pane, sanitize it, and add it to
+the corpus with the detectors' current results as its expectations.
+Review the written .json before committing: it records what the detectors
+say now, which is only right if they read the screen correctly.
+
+Sanitizing strips ANSI codes, redacts secrets with the configured
+patterns, and replaces home directories with ~. Read the transcript for
+anything else private (hostnames, customer names) before committing it.
+
+The version directory comes from the agent's banner when the screen shows
+one; pass --cli-version otherwise.
+
+Examples:
+  ntm fixture transcript add myproject cc_1 --name working.spinner
+  ntm fixture transcript add myproject 2 --name ready.prompt --cli-version 2.0.14`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name == "" {
+				return fmt.Errorf("--name is required")
+			}
+			if err := tmux.EnsureInstalled(); err != nil {
+				return err
+			}
+			session, paneArg := "", args[0]
+			if len(args) == 2 {
+				session, paneArg = args[0], args[1]
+			}
+			res, err := ResolveSession(session, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			if res.Session == "" {
+				return nil
+			}
+			pane, err := resolvePane(res.Session, paneArg)
+			if err != nil {
+				return err
+			}
+			if agentType == "" {
+				agentType = string(pane.Type)
+			}
+
+			out, err := tmux.CapturePaneOutputContext(cmd.Context(), pane.ID, lines)
+			if err != nil {
+				return fmt.Errorf("capturing %s: %w", pane.ID, err)
+			}
+			clean := replay.SanitizeTranscript(out, *fixtureRedaction())
+			t := replay.Transcript{
+				AgentType: agentType,
+				Version:   cliVersion,
+				Name:      name,
+				Output:    clean,
+				Expect:    replay.AnalyzeTranscript(agentType, clean),
+			}
+			if t.Version == "" {
+				t.Version = replay.TranscriptVersion(agentType, clean)
+			}
+			path, err := replay.WriteTranscript(corpus, t, force)
+			if err != nil {
+				return err
+			}
+
+			result := TranscriptAddResult{
+				Path:      path,
+				AgentType: agentType,
+				Version:   t.Version,
+				Redacted:  len(redaction.Scan(out, *fixtureRedaction())) > 0,
+				Expect:    t.Expect,
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(result)
+			}
+			w := cmd.OutOrStdout()
+			fmt.Fprintf(w, "✓ Added %s\n", path)
+			state := t.Expect.State
+			if t.Expect.Phase != "" {
+				state += " (" + t.Expect.Phase + ")"
+			}
+			fmt.Fprintf(w, "  state %s, detector %s\n", state, t.Expect.Detector)
+			if result.Redacted {
+				fmt.Fprintln(w, "  secrets were redacted")
+			}
+			if t.Version == replay.UnknownVersion {
+				fmt.Fprintln(w, "  no version banner on screen; pass --cli-version to file it under a version")
+			}
+			fmt.Fprintln(w, "  review the expectations in the .json beside it before committing")
+			return nil
+		},
+		ValidArgsFunction: completeSessionArgs,
+	}
+
+	cmd.Flags().StringVar(&corpus, "corpus", defaultTranscriptCorpus, "Corpus directory")
+	cmd.Flags().StringVar(&name, "name", "", "Transcript name, <phase>.<description> (required)")
+	cmd.Flags().StringVar(&agentType, "agent", "", "Agent type (default: the pane's)")
+	cmd.Flags().StringVar(&cliVersion, "cli-version", "", "Agent CLI version (default: from its banner)")
+	cmd.Flags().IntVar(&lines, "lines", 60, "Scrollback lines to capture")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing transcript")
+	return cmd
+}
+
+func newFixtureTranscriptCheckCmd() *cobra.Command {
+	var (
+		corpus string
+		update bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Run the detectors over the transcript corpus",
+		Long: `Run the status, rate limit, completion, and extraction detectors over
+every corpus transcript and compare the results with its expectations.
+This is what the corpus tests do; use it to see every regression at once.
+
+--update rewrites the expectations from the current results, for after a
+deliberate detector change. Review the diff before committing.
+
+Examples:
+  ntm fixture transcript check
+  ntm fixture transcript check --update`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			transcripts, err := replay.LoadCorpus(corpus)
+			if err != nil {
+				return err
+			}
+			if len(transcripts) == 0 {
+				return fmt.Errorf("no transcripts in %s", corpus)
+			}
+
+			result := TranscriptCheckResult{Corpus: corpus, Transcripts: len(transcripts)}
+			for _, t := range transcripts {
+				got := replay.AnalyzeTranscript(t.AgentType, t.Output)
+				diffs := replay.CompareTranscript(got, t.Expect)
+				if t.NoExpect {
+					diffs = []string{"no expectations file"}
+				}
+				if len(diffs) == 0 {
+					continue
+				}
+				if update {
+					if err := replay.WriteTranscriptExpectation(t.Path, got); err != nil {
+						return err
+					}
+					result.Updated++
+					continue
+				}
+				result.Failures = append(result.Failures, TranscriptRegression{Path: t.Path, Diffs: diffs})
+			}
+
+			if IsJSONOutput() {
+				if err := output.PrintJSON(result); err != nil {
+					return err
+				}
+			} else {
+				w := cmd.OutOrStdout()
+				for _, f := range result.Failures {
+					fmt.Fprintf(w, "✗ %s\n", f.Path)
+					for _, d := range f.Diffs {
+						fmt.Fprintf(w, "    %s\n", d)
+					}
+				}
+				switch {
+				case update:
+					fmt.Fprintf(w, "✓ Checked %d transcripts, updated %d\n", result.Transcripts, result.Updated)
+				case len(result.Failures) == 0:
+					fmt.Fprintf(w, "✓ %d transcripts match their expectations\n", result.Transcripts)
+				}
+			}
+			if len(result.Failures) > 0 {
+				return fmt.Errorf("%d of %d transcripts regressed", len(result.Failures), result.Transcripts)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&corpus, "corpus", defaultTranscriptCorpus, "Corpus directory")
+	cmd.Flags().BoolVar(&update, "update", false, "Rewrite expectations from the current results")
+	return cmd
+}
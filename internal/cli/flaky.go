@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/verify"
+)
+
+func newFlakyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flaky",
+		Short: "Show and manage flaky verification checks",
+		Long: `Show the verification checks seen to fail intermittently in this project,
+and which are quarantined.
+
+ntm assign --watch reruns a failing [assign.verify] command. A command that
+passes on retry, or fails differently, is counted as flaky; after
+quarantine_after flakes it is quarantined. Quarantined commands still run,
+but their failures do not fail verification or count against agent scores.
+The list is stored in .ntm/flaky.json.
+
+Examples:
+  ntm flaky                                  # List flaky checks
+  ntm flaky quarantine test --reason "race in TestServer"
+  ntm flaky release test                     # Count it again`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFlakyList()
+		},
+	}
+
+	cmd.AddCommand(
+		newFlakyQuarantineCmd(),
+		newFlakyReleaseCmd(),
+	)
+
+	return cmd
+}
+
+// FlakyListResponse is the JSON output for ntm flaky.
+type FlakyListResponse struct {
+	output.TimestampedResponse
+	Path   string              `json:"path"`
+	Checks []verify.FlakyCheck `json:"checks"`
+}
+
+func runFlakyList() error {
+	path := verify.QuarantinePath(GetProjectRoot())
+	q, err := verify.LoadQuarantine(path)
+	if err != nil {
+		return err
+	}
+	checks := q.List()
+
+	if IsJSONOutput() {
+		return output.PrintJSON(FlakyListResponse{
+			TimestampedResponse: output.NewTimestamped(),
+			Path:                path,
+			Checks:              checks,
+		})
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	fmt.Println()
+	if len(checks) == 0 {
+		fmt.Printf("  %s No flaky checks recorded\n", mutedStyle.Render("•"))
+		fmt.Println()
+		return nil
+	}
+	for _, c := range checks {
+		icon, state := mutedStyle.Render("○"), "watching"
+		if c.Quarantined {
+			icon, state = warnStyle.Render("⚠"), "quarantined"
+		}
+		fmt.Printf("  %s %-20s %-12s %d flakes, last %s\n", icon, c.Name, state, c.Flakes, formatFlakySeen(c.LastSeen))
+		if c.Command != "" {
+			fmt.Printf("      %s\n", mutedStyle.Render(c.Command))
+		}
+		if c.Reason != "" {
+			fmt.Printf("      %s\n", mutedStyle.Render(c.Reason))
+		}
+	}
+	fmt.Println()
+	return nil
+}
+
+func formatFlakySeen(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// FlakyUpdateResponse is the JSON output for ntm flaky quarantine/release.
+type FlakyUpdateResponse struct {
+	output.TimestampedResponse
+	Success     bool   `json:"success"`
+	Check       string `json:"check"`
+	Quarantined bool   `json:"quarantined"`
+	Message     string `json:"message,omitempty"`
+}
+
+func newFlakyQuarantineCmd() *cobra.Command {
+	var reason string
+
+	cmd := &cobra.Command{
+		Use:   "quarantine <check>",
+		Short: "Quarantine a verification check by name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reason == "" {
+				reason = "quarantined by hand"
+			}
+			return updateQuarantine(args[0], func(q *verify.Quarantine) (bool, string) {
+				q.Add(args[0], reason)
+				return true, ""
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the check is quarantined")
+
+	return cmd
+}
+
+func newFlakyReleaseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "release <check>",
+		Short: "Take a check out of quarantine and reset its flake count",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateQuarantine(args[0], func(q *verify.Quarantine) (bool, string) {
+				if !q.Release(args[0]) {
+					return false, "not listed"
+				}
+				return false, ""
+			})
+		},
+	}
+}
+
+// updateQuarantine applies change to the project's quarantine list and
+// saves it. change reports the check's new quarantine state and, if nothing
+// changed, why.
+func updateQuarantine(name string, change func(*verify.Quarantine) (bool, string)) error {
+	q, err := verify.LoadQuarantine(verify.QuarantinePath(GetProjectRoot()))
+	if err != nil {
+		return err
+	}
+	quarantined, unchanged := change(q)
+	if unchanged == "" {
+		if err := q.Save(); err != nil {
+			return err
+		}
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(FlakyUpdateResponse{
+			TimestampedResponse: output.NewTimestamped(),
+			Success:             true,
+			Check:               name,
+			Quarantined:         quarantined,
+			Message:             unchanged,
+		})
+	}
+
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	fmt.Println()
+	switch {
+	case unchanged != "":
+		fmt.Printf("  %s %s: %s\n", mutedStyle.Render("•"), name, unchanged)
+	case quarantined:
+		fmt.Printf("  %s Quarantined %s\n", okStyle.Render("✓"), name)
+	default:
+		fmt.Printf("  %s Released %s\n", okStyle.Render("✓"), name)
+	}
+	fmt.Println()
+	return nil
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/verify"
+)
+
+func TestFlakyQuarantineAndRelease(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	cmd := newFlakyCmd()
+	cmd.SetArgs([]string{"quarantine", "test", "--reason", "race in TestServer"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("quarantine: %v", err)
+	}
+	q, err := verify.LoadQuarantine(verify.QuarantinePath(GetProjectRoot()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.IsQuarantined("test") || q.Checks["test"].Reason != "race in TestServer" {
+		t.Fatalf("quarantine list = %+v", q.Checks)
+	}
+
+	cmd = newFlakyCmd()
+	cmd.SetArgs([]string{"release", "test"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	q, _ = verify.LoadQuarantine(verify.QuarantinePath(GetProjectRoot()))
+	if len(q.Checks) != 0 {
+		t.Errorf("expected empty list after release, got %+v", q.Checks)
+	}
+}
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+const defaultQuarantineDir = "~/.ntm/quarantine"
+
+// fsckFile is the check result for one JSONL file.
+type fsckFile struct {
+	Path        string              `json:"path"`
+	Lines       int                 `json:"lines"`
+	Bad         []jsonlutil.BadLine `json:"bad,omitempty"`
+	Quarantine  string              `json:"quarantine,omitempty"`
+	Repaired    bool                `json:"repaired,omitempty"`
+	ChainError  string              `json:"chain_error,omitempty"`
+	ChainRepair *audit.ChainRepair  `json:"chain_repair,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+func (f fsckFile) healthy() bool {
+	return f.Error == "" && ((len(f.Bad) == 0 && f.ChainError == "") || f.Repaired)
+}
+
+// fsckReport is the output of `ntm fsck`.
+type fsckReport struct {
+	Dirs          []string   `json:"dirs"`
+	QuarantineDir string     `json:"quarantine_dir"`
+	Scanned       int        `json:"scanned"`
+	Corrupt       int        `json:"corrupt"`
+	Repaired      int        `json:"repaired"`
+	RepairRun     bool       `json:"repair_run"`
+	Files         []fsckFile `json:"files"`
+}
+
+type fsckOptions struct {
+	repair        bool
+	dirs          []string
+	quarantineDir string
+	verbose       bool
+}
+
+func newFsckCmd() *cobra.Command {
+	var opts fsckOptions
+
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Check and repair corrupt JSONL state files",
+		Long: `Scan ntm's JSONL files (audit logs, scores, archives, event logs) for
+records left partial or garbled by a crash or power loss.
+
+By default fsck only reports problems. With --repair, corrupt records are
+moved to the quarantine directory, the files are rewritten without them, and
+audit logs have their hash chain re-linked across the quarantined records
+(the original sequence numbers and checksums are kept in each resealed
+entry's metadata, and a signed chain_repair entry records the old chain
+head). An audit log whose chain is broken in any other way, such as an entry
+whose checksum no longer matches, is reported and left alone.
+
+Scanned by default: the project's .ntm directory, ~/.ntm, ~/.config/ntm, and
+~/.local/share/ntm.
+
+Examples:
+  ntm fsck
+  ntm fsck --repair
+  ntm fsck --dir ~/.ntm/archive --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFsck(opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.repair, "repair", false, "Quarantine corrupt records and repair audit chains")
+	cmd.Flags().StringSliceVar(&opts.dirs, "dir", nil, "Directories to scan (default: all ntm state directories)")
+	cmd.Flags().StringVar(&opts.quarantineDir, "quarantine-dir", defaultQuarantineDir, "Where quarantined records are written")
+	cmd.Flags().BoolVarP(&opts.verbose, "verbose", "v", false, "List every scanned file, not just problems")
+	return cmd
+}
+
+func defaultFsckDirs() []string {
+	var dirs []string
+	if root := GetProjectRoot(); root != "" {
+		dirs = append(dirs, filepath.Join(root, ".ntm"))
+	}
+	dirs = append(dirs,
+		util.ExpandPath("~/.ntm"),
+		util.ExpandPath("~/.config/ntm"),
+		util.ExpandPath("~/.local/share/ntm"),
+	)
+	// Artifact roots moved out of the defaults by [storage.roots].
+	for _, root := range storageRootDirs() {
+		if !underAny(root, dirs) {
+			dirs = append(dirs, root)
+		}
+	}
+	return dirs
+}
+
+// underAny reports whether path is one of dirs or inside one.
+func underAny(path string, dirs []string) bool {
+	for _, d := range dirs {
+		if path == d || strings.HasPrefix(path, d+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func runFsck(opts fsckOptions) error {
+	dirs := append([]string(nil), opts.dirs...)
+	if len(dirs) == 0 {
+		dirs = defaultFsckDirs()
+	}
+	for i, d := range dirs {
+		dirs[i] = util.ExpandPath(d)
+	}
+	quarantineDir := util.ExpandPath(opts.quarantineDir)
+
+	paths := findJSONLFiles(dirs, quarantineDir)
+	report := fsckReport{Dirs: dirs, QuarantineDir: quarantineDir, Scanned: len(paths), RepairRun: opts.repair, Files: []fsckFile{}}
+	for _, path := range paths {
+		f := checkJSONLFile(path, opts.repair, quarantineDir)
+		if len(f.Bad) > 0 || f.ChainError != "" || f.Error != "" {
+			report.Corrupt++
+		}
+		if f.Repaired {
+			report.Repaired++
+		}
+		if opts.verbose || IsJSONOutput() || !f.healthy() || f.Repaired {
+			report.Files = append(report.Files, f)
+		}
+	}
+
+	if IsJSONOutput() {
+		if err := output.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printFsckReport(report)
+	}
+
+	if unrepaired := report.Corrupt - report.Repaired; unrepaired > 0 {
+		if opts.repair {
+			return fmt.Errorf("%d file(s) could not be repaired", unrepaired)
+		}
+		return fmt.Errorf("%d corrupt file(s) found; run 'ntm fsck --repair' to fix", unrepaired)
+	}
+	return nil
+}
+
+// findJSONLFiles returns the .jsonl files under dirs, skipping the quarantine
+// directory and duplicates from overlapping roots.
+func findJSONLFiles(dirs []string, skipDir string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, dir := range dirs {
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if path == skipDir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || filepath.Ext(path) != ".jsonl" || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			paths = append(paths, path)
+			return nil
+		})
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// checkJSONLFile scans one file and, if repair is set, fixes it.
+func checkJSONLFile(path string, repair bool, quarantineDir string) fsckFile {
+	f := fsckFile{Path: path}
+	res, err := jsonlutil.ScanFile(path, jsonlutil.Options{}, nil)
+	if err != nil {
+		f.Error = err.Error()
+		return f
+	}
+	f.Lines = res.Lines
+	f.Bad = res.Bad
+
+	isAudit := strings.HasPrefix(path, audit.Dir()+string(filepath.Separator))
+	if isAudit && res.OK() {
+		if err := audit.VerifyIntegrity(path); err != nil {
+			f.ChainError = err.Error()
+		}
+	}
+	if !repair || (res.OK() && f.ChainError == "") {
+		return f
+	}
+
+	// Hold the file's lock so a writer that takes it cannot append mid-repair.
+	err = util.WithFileLock(path, func() error {
+		removed := 0
+		if !res.OK() {
+			rr, err := jsonlutil.Repair(path, jsonlutil.Options{}, quarantineDir)
+			if err != nil {
+				return err
+			}
+			if rr != nil {
+				f.Quarantine = rr.Quarantine
+				removed = rr.Removed
+			}
+		}
+		if isAudit {
+			key, err := audit.LoadSigningKey("")
+			if err != nil {
+				return err
+			}
+			chain, err := audit.RepairChain(path, removed, key)
+			if err != nil {
+				return err
+			}
+			f.ChainRepair = chain
+			if err := audit.VerifyIntegrity(path); err != nil {
+				f.ChainError = err.Error()
+				return nil
+			}
+		}
+		f.Repaired = true
+		return nil
+	})
+	if err != nil {
+		f.Error = err.Error()
+	}
+	return f
+}
+
+func printFsckReport(r fsckReport) {
+	if r.Scanned == 0 {
+		fmt.Println("No JSONL files found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, f := range r.Files {
+		status := "ok"
+		switch {
+		case f.Error != "":
+			status = "error: " + f.Error
+		case f.Repaired:
+			parts := []string{}
+			if len(f.Bad) > 0 {
+				parts = append(parts, fmt.Sprintf("quarantined %d record(s)", len(f.Bad)))
+			}
+			if f.ChainRepair != nil {
+				parts = append(parts, fmt.Sprintf("resealed %d audit entries from seq %d", f.ChainRepair.Resealed, f.ChainRepair.BrokenAt))
+			}
+			status = "repaired: " + strings.Join(parts, ", ")
+		case len(f.Bad) > 0:
+			status = fmt.Sprintf("%d corrupt record(s)", len(f.Bad))
+		case f.ChainError != "":
+			status = "audit chain: " + f.ChainError
+		}
+		fmt.Fprintf(w, "%s\t%s\n", f.Path, status)
+		if !f.Repaired {
+			for _, b := range f.Bad {
+				fmt.Fprintf(w, "  line %d\t%s\n", b.Line, b.Reason)
+			}
+		}
+	}
+	_ = w.Flush()
+
+	if len(r.Files) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("Scanned %d file(s): %d corrupt, %d repaired\n", r.Scanned, r.Corrupt, r.Repaired)
+	if r.Repaired > 0 {
+		fmt.Printf("Quarantined records are in %s\n", r.QuarantineDir)
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFsckReportsAndRepairs(t *testing.T) {
+	dir := t.TempDir()
+	archiveDir := filepath.Join(dir, "archive")
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := filepath.Join(archiveDir, "proj_2026-01-02.jsonl")
+	clean := filepath.Join(dir, "scores.jsonl")
+	if err := os.WriteFile(corrupt, []byte("{\"pane\":1}\n{\"pane\":2,\"lin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(clean, []byte("{\"score\":1}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	quarantineDir := filepath.Join(dir, "quarantine")
+
+	opts := fsckOptions{dirs: []string{dir}, quarantineDir: quarantineDir}
+	err := runFsck(opts)
+	if err == nil || !strings.Contains(err.Error(), "1 corrupt file") {
+		t.Fatalf("check-only run: err = %v, want corrupt file error", err)
+	}
+	if data, _ := os.ReadFile(corrupt); !strings.Contains(string(data), "lin") {
+		t.Fatal("check-only run modified the file")
+	}
+
+	opts.repair = true
+	if err := runFsck(opts); err != nil {
+		t.Fatalf("repair run: %v", err)
+	}
+	if data, _ := os.ReadFile(corrupt); string(data) != "{\"pane\":1}\n" {
+		t.Fatalf("repaired content = %q", data)
+	}
+	matches, _ := filepath.Glob(filepath.Join(quarantineDir, "proj_2026-01-02.jsonl.*.bad"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one quarantine file, got %v", matches)
+	}
+
+	// Quarantine files are not rescanned and everything is clean now.
+	opts.repair = false
+	if err := runFsck(opts); err != nil {
+		t.Fatalf("post-repair run: %v", err)
+	}
+}
@@ -103,6 +103,7 @@ Reports:
   - Process status (running/exited)
   - Activity level (active/idle/stale)
   - Uptime and restart counts
+  - CPU and memory of each pane's process tree
   - Detected issues (rate limits, crashes, errors)
 
 Examples:
@@ -570,10 +571,10 @@ func renderHealthTUI(result *health.SessionHealth) error {
 	fmt.Println()
 
 	// Build table header - include Uptime column
-	header := fmt.Sprintf("%-6s │ %-10s │ %-10s │ %-10s │ %-12s │ %s",
-		"Pane", "Agent", "Status", "Activity", "Uptime", "Issues")
+	header := fmt.Sprintf("%-6s │ %-10s │ %-10s │ %-10s │ %-12s │ %-5s │ %-9s │ %s",
+		"Pane", "Agent", "Status", "Activity", "Uptime", "CPU", "Memory", "Issues")
 	fmt.Println(mutedStyle.Render(header))
-	fmt.Println(mutedStyle.Render(strings.Repeat("─", 85)))
+	fmt.Println(mutedStyle.Render(strings.Repeat("─", 105)))
 
 	// Build table rows
 	for _, agent := range result.Agents {
@@ -612,12 +613,21 @@ func renderHealthTUI(result *health.SessionHealth) error {
 			}
 		}
 
-		row := fmt.Sprintf("%-6d │ %-10s │ %-10s │ %-10s │ %-12s │ %s",
+		// Process tree resource usage
+		cpuStr, memStr := "-", "-"
+		if r := agent.Resources; r != nil {
+			cpuStr = fmt.Sprintf("%.0f%%", r.CPUPercent)
+			memStr = formatBytes(int64(r.RSSBytes))
+		}
+
+		row := fmt.Sprintf("%-6d │ %-10s │ %-10s │ %-10s │ %-12s │ %-5s │ %-9s │ %s",
 			agent.Pane,
 			truncateString(agent.AgentType, 10),
 			statusIcon(agent.Status),
 			activityStr(agent.Activity),
 			truncateString(uptimeStr, 12),
+			cpuStr,
+			memStr,
 			issueStr)
 		fmt.Println(row)
 
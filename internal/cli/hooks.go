@@ -38,7 +38,10 @@ Examples:
 
   ntm hooks guard install           # Install Agent Mail pre-commit guard
   ntm hooks guard install --warn-only  # Print warn-only setup instructions
-  ntm hooks guard uninstall          # Remove Agent Mail pre-commit guard`,
+  ntm hooks guard uninstall          # Remove Agent Mail pre-commit guard
+
+  ntm hooks exec list               # List exec hooks in .ntm/hooks/
+  ntm hooks exec run pre-send --payload '{"message":"hi"}'  # Try exec hooks`,
 	}
 
 	cmd.AddCommand(
@@ -47,6 +50,7 @@ Examples:
 		newHooksStatusCmd(),
 		newHooksRunCmd(),
 		newHooksGuardCmd(),
+		newHooksExecCmd(),
 	)
 
 	return cmd
@@ -58,47 +58,63 @@ type LockResult struct {
 	Conflicts []agentmail.ReservationConflict `json:"conflicts,omitempty"`
 	TTL       string                          `json:"ttl"`
 	ExpiresAt *time.Time                      `json:"expires_at,omitempty"`
-	Error     string                          `json:"error,omitempty"`
+	// Queued is set when Agent Mail was unreachable and the reservation was
+	// queued in the project outbox, to be requested once it is back.
+	Queued bool   `json:"queued,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 func runLock(session string, patterns []string, reason, ttlStr string, shared bool) error {
+	result, err := lockPaths(session, "", patterns, reason, ttlStr, shared)
+	if IsJSONOutput() && (err == nil || result.Error != "") {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	if err != nil {
+		return err
+	}
+	return printLockResult(result, shared)
+}
+
+// lockPaths reserves patterns for agentName, or for the session's Agent Mail
+// identity when agentName is empty. Reservation conflicts are reported in the
+// result; an error means nothing was attempted, and when it is set the
+// result's Error explains it for JSON output.
+func lockPaths(session, agentName string, patterns []string, reason, ttlStr string, shared bool) (LockResult, error) {
+	result := LockResult{Session: session, TTL: ttlStr}
+
 	ttlDuration, err := util.ParseDuration(ttlStr)
 	if err != nil {
-		return fmt.Errorf("invalid TTL format '%s': use format like 30m, 1h, 1d", ttlStr)
+		return result, fmt.Errorf("invalid TTL format '%s': use format like 30m, 1h, 1d", ttlStr)
 	}
 	ttlSeconds := int(ttlDuration.Seconds())
 	if ttlSeconds < 60 {
-		return fmt.Errorf("TTL must be at least 1 minute")
+		return result, fmt.Errorf("TTL must be at least 1 minute")
 	}
 
 	wd := GetProjectRoot()
 	if wd == "" {
-		return fmt.Errorf("getting project root failed")
+		return result, fmt.Errorf("getting project root failed")
 	}
 
-	sessionAgent, err := agentmail.LoadSessionAgent(session, wd)
-	if err != nil {
-		return fmt.Errorf("loading session agent: %w", err)
-	}
-	if sessionAgent == nil {
-		if IsJSONOutput() {
-			result := LockResult{Success: false, Session: session, Error: "Session has no Agent Mail identity"}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(result)
+	if agentName == "" {
+		sessionAgent, err := agentmail.LoadSessionAgent(session, wd)
+		if err != nil {
+			return result, fmt.Errorf("loading session agent: %w", err)
+		}
+		if sessionAgent == nil {
+			result.Error = "Session has no Agent Mail identity"
+			return result, fmt.Errorf("session '%s' has no Agent Mail identity", session)
 		}
-		return fmt.Errorf("session '%s' has no Agent Mail identity", session)
+		agentName = sessionAgent.AgentName
 	}
+	result.Agent = agentName
 
 	client := newAgentMailClient(wd)
-	if !client.IsAvailable() {
-		if IsJSONOutput() {
-			result := LockResult{Success: false, Session: session, Agent: sessionAgent.AgentName, Error: "Agent Mail server unavailable"}
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			return enc.Encode(result)
-		}
-		return fmt.Errorf("agent mail server unavailable")
+	if !client.IsAvailable() && client.Outbox() == nil {
+		result.Error = "Agent Mail server unavailable"
+		return result, fmt.Errorf("agent mail server unavailable")
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -106,42 +122,35 @@ func runLock(session string, patterns []string, reason, ttlStr string, shared bo
 
 	opts := agentmail.FileReservationOptions{
 		ProjectKey: wd,
-		AgentName:  sessionAgent.AgentName,
+		AgentName:  agentName,
 		Paths:      patterns,
 		TTLSeconds: ttlSeconds,
 		Exclusive:  !shared,
 		Reason:     reason,
 	}
 
-	reservation, err := client.ReservePaths(ctx, opts)
-
-	result := LockResult{Session: session, Agent: sessionAgent.AgentName, TTL: ttlStr}
-
+	reservation, queued, err := client.ReservePathsOrQueue(ctx, opts)
+	if queued {
+		result.Queued = true
+		return result, nil
+	}
 	if err != nil {
 		if reservation != nil && len(reservation.Conflicts) > 0 {
-			result.Success = false
 			result.Granted = reservation.Granted
 			result.Conflicts = reservation.Conflicts
 		} else {
-			result.Success = false
 			result.Error = err.Error()
 		}
-	} else {
-		result.Success = true
-		result.Granted = reservation.Granted
-		if len(reservation.Granted) > 0 {
-			t := reservation.Granted[0].ExpiresTS.Time
-			result.ExpiresAt = &t
-		}
+		return result, nil
 	}
 
-	if IsJSONOutput() {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+	result.Success = true
+	result.Granted = reservation.Granted
+	if len(reservation.Granted) > 0 {
+		t := reservation.Granted[0].ExpiresTS.Time
+		result.ExpiresAt = &t
 	}
-
-	return printLockResult(result, shared)
+	return result, nil
 }
 
 func printLockResult(result LockResult, shared bool) error {
@@ -165,6 +174,13 @@ func printLockResult(result LockResult, shared bool) error {
 		return nil
 	}
 
+	if result.Queued {
+		fmt.Printf("Agent Mail is unavailable; queued the %s reservation\n", lockType)
+		fmt.Printf("  Agent: %s\n", result.Agent)
+		fmt.Println("  It will be requested when Agent Mail is reachable again.")
+		return nil
+	}
+
 	if len(result.Conflicts) > 0 {
 		fmt.Printf("Conflict detected!\n\n")
 		for _, c := range result.Conflicts {
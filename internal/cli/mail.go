@@ -175,6 +175,13 @@ func newAgentMailClient(projectKey string) *agentmail.Client {
 			opts = append(opts, agentmail.WithToken(cfg.AgentMail.Token))
 		}
 	}
+	if projectKey != "" {
+		// Writes made while the server is down are queued in the project's
+		// outbox and delivered once it is reachable again.
+		if outbox, err := agentmail.OpenOutbox(agentmail.DefaultOutboxPath(projectKey)); err == nil {
+			opts = append(opts, agentmail.WithOutbox(outbox))
+		}
+	}
 	return agentmail.NewClient(opts...)
 }
 
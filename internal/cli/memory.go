@@ -14,8 +14,9 @@ import (
 
 func newMemoryCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "memory",
-		Short: "Interact with CASS Memory (cm) system",
+		Use:     "memory",
+		Aliases: []string{"cm"},
+		Short:   "Interact with CASS Memory (cm) system",
 	}
 
 	cmd.AddCommand(
@@ -23,6 +24,9 @@ func newMemoryCmd() *cobra.Command {
 		newMemoryContextCmd(),
 		newMemoryOutcomeCmd(),
 		newMemoryPrivacyCmd(),
+		newMemoryRefreshCmd(),
+		newMemoryStatusCmd(),
+		newMemoryDistillCmd(),
 	)
 
 	return cmd
@@ -0,0 +1,355 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/cm"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+// MemoryRefreshResult is the JSON output of `ntm cm refresh`.
+type MemoryRefreshResult struct {
+	Session  string           `json:"session"`
+	Pane     int              `json:"pane"`
+	Version  string           `json:"version"`
+	Sections []cm.SectionKind `json:"sections"`
+	Sent     bool             `json:"sent"`
+}
+
+// MemoryStatusResult is the JSON output of `ntm cm status`.
+type MemoryStatusResult struct {
+	Session string            `json:"session"`
+	Version string            `json:"version"`
+	Files   []string          `json:"files"`
+	Panes   []MemoryPaneState `json:"panes"`
+}
+
+// MemoryPaneState is the memory version one pane has loaded.
+type MemoryPaneState struct {
+	cm.MemoryLoad
+	Stale bool `json:"stale"`
+}
+
+func newMemoryRefreshCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh [session] <pane>",
+		Short: "Send the project memory to a pane",
+		Long: `Send the sections of .ntm/memory/*.md selected for the pane's agent type
+and record the memory version the pane has loaded.
+
+The pane is an index or an agent type (first matching pane).
+Which sections each agent type receives is set with [memory.sections].
+
+Examples:
+  ntm cm refresh 2              # Refresh pane 2 of the current session
+  ntm cm refresh myproject cod  # Refresh the first Codex pane`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session, pane := "", args[0]
+			if len(args) == 2 {
+				session, pane = args[0], args[1]
+			}
+			return runMemoryRefresh(cmd, session, pane)
+		},
+		ValidArgsFunction: completeSessionThenPane,
+	}
+}
+
+func runMemoryRefresh(cmd *cobra.Command, session, paneArg string) error {
+	if err := tmux.EnsureInstalled(); err != nil {
+		return err
+	}
+	res, err := ResolveSession(session, cmd.OutOrStdout())
+	if err != nil {
+		return err
+	}
+	if res.Session == "" {
+		return nil
+	}
+	session = res.Session
+
+	paneIdx, err := strconv.Atoi(paneArg)
+	if err != nil {
+		if paneIdx, err = resolvePaneSelector(session, paneArg); err != nil {
+			return err
+		}
+	}
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return err
+	}
+	var target *tmux.Pane
+	for i := range panes {
+		if panes[i].Index == paneIdx {
+			target = &panes[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("pane %d not found in session '%s'", paneIdx, session)
+	}
+	if target.Type == tmux.AgentUser {
+		return fmt.Errorf("pane %d is not an agent pane", paneIdx)
+	}
+
+	projectDir := getSessionWorkingDir(session)
+	mem, err := cm.LoadMemory(projectDir)
+	if err != nil {
+		return err
+	}
+	memCfg := config.DefaultMemoryConfig()
+	if cfg != nil {
+		memCfg = cfg.Memory
+	}
+	kinds := memorySectionsFor(memCfg, string(target.Type))
+	sent, err := deliverProjectMemory(session, projectDir, mem, *target, kinds)
+	if err != nil {
+		return err
+	}
+
+	result := MemoryRefreshResult{Session: session, Pane: paneIdx, Version: mem.Version, Sections: kinds, Sent: sent}
+	if IsJSONOutput() {
+		return output.PrintJSON(result)
+	}
+	if !sent {
+		fmt.Fprintf(cmd.OutOrStdout(), "No memory sections for pane %d (%s/*.md)\n", paneIdx, cm.MemoryDir)
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ Pane %d loaded memory version %s (%s)\n", paneIdx, mem.Version, joinSectionKinds(kinds))
+	return nil
+}
+
+func newMemoryStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status [session]",
+		Short: "Show which memory version each pane has loaded",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) == 1 {
+				session = args[0]
+			}
+			res, err := ResolveSession(session, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			if res.Session == "" {
+				return nil
+			}
+			result, err := buildMemoryStatus(res.Session, getSessionWorkingDir(res.Session))
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(result)
+			}
+			w := cmd.OutOrStdout()
+			if result.Version == "" {
+				fmt.Fprintf(w, "No memory files in %s\n", cm.MemoryDir)
+			} else {
+				fmt.Fprintf(w, "Memory version %s (%d files)\n", result.Version, len(result.Files))
+			}
+			for _, p := range result.Panes {
+				state := "current"
+				if p.Stale {
+					state = "stale"
+				}
+				fmt.Fprintf(w, "  pane %d  %-8s %s  %s  %s\n", p.Pane, p.AgentType, p.Version, state, p.LoadedAt.Local().Format("2006-01-02 15:04"))
+			}
+			return nil
+		},
+	}
+}
+
+func buildMemoryStatus(session, projectDir string) (*MemoryStatusResult, error) {
+	mem, err := cm.LoadMemory(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	loads, err := cm.MemoryLoads(projectDir, session)
+	if err != nil {
+		return nil, err
+	}
+	result := &MemoryStatusResult{Session: session, Version: mem.Version, Files: mem.Files, Panes: []MemoryPaneState{}}
+	for _, l := range loads {
+		result.Panes = append(result.Panes, MemoryPaneState{MemoryLoad: l, Stale: l.Version != mem.Version})
+	}
+	return result, nil
+}
+
+// memorySectionsFor returns the memory sections an agent type receives.
+// Agent types without a [memory.sections] entry receive every section.
+func memorySectionsFor(mc config.MemoryConfig, agentType string) []cm.SectionKind {
+	want := normalizeAgentType(agentType)
+	for name, sections := range mc.Sections {
+		if normalizeAgentType(name) != want {
+			continue
+		}
+		kinds := make([]cm.SectionKind, 0, len(sections))
+		for _, s := range sections {
+			if k, err := cm.ParseSectionKind(s); err == nil {
+				kinds = append(kinds, k)
+			}
+		}
+		return kinds
+	}
+	return cm.AllSectionKinds
+}
+
+// deliverProjectMemory sends a pane its selection of the project memory and
+// records the version it loaded. It reports false when the selection is empty.
+func deliverProjectMemory(session, projectDir string, mem *cm.Memory, p tmux.Pane, kinds []cm.SectionKind) (bool, error) {
+	text := mem.Render(kinds)
+	if text == "" {
+		return false, nil
+	}
+	if err := sendPromptToPane(session, p, text); err != nil {
+		return false, err
+	}
+	err := cm.RecordMemoryLoad(projectDir, cm.MemoryLoad{
+		Session:   session,
+		Pane:      p.Index,
+		AgentType: agentTypeToString(p.Type),
+		Version:   mem.Version,
+		Sections:  kinds,
+	})
+	return true, err
+}
+
+func joinSectionKinds(kinds []cm.SectionKind) string {
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = string(k)
+	}
+	return strings.Join(names, ", ")
+}
+
+// MemoryDistillResult is the JSON output of `ntm cm distill`.
+type MemoryDistillResult struct {
+	Session   string              `json:"session"`
+	Proposals []cm.MemoryProposal `json:"proposals"`
+	Diff      string              `json:"diff,omitempty"`
+	// Path is the saved proposal, applied with `git apply` from the
+	// project root. Empty when there is nothing to propose.
+	Path string `json:"path,omitempty"`
+}
+
+func newMemoryDistillCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "distill [session]",
+		Short: "Propose memory additions from a session",
+		Long: `Propose additions to .ntm/memory/MEMORY.md (patterns, gotchas, decisions)
+from the session's latest summary and its file conflict history.
+
+Proposals are never applied automatically. The diff is printed and saved
+under .ntm/memory/proposals; apply it from the project root after review:
+
+  git apply .ntm/memory/proposals/<session>-<time>.patch
+
+Distillation also runs after 'ntm kill --summarize' unless
+memory.distill_on_kill is false.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			session := ""
+			if len(args) == 1 {
+				session = args[0]
+			}
+			res, err := ResolveSession(session, cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			if res.Session == "" {
+				return nil
+			}
+			session = res.Session
+
+			wd, _ := os.Getwd()
+			projectDir := resolveProjectDir(session, wd)
+			sum, err := loadLatestSummary(projectDir, session)
+			if err != nil {
+				return err
+			}
+			result, err := distillSessionMemory(session, projectDir, sum)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(result)
+			}
+			if result.Path == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), "No new memory proposals")
+				return nil
+			}
+			fmt.Fprint(cmd.OutOrStdout(), result.Diff)
+			fmt.Fprintf(cmd.OutOrStdout(), "\nSaved proposal to %s\n", result.Path)
+			return nil
+		},
+	}
+}
+
+// loadLatestSummary returns the session's most recent saved summary, or a
+// fresh one when none is saved and the session is still running.
+func loadLatestSummary(projectDir, session string) (*summary.SessionSummary, error) {
+	files, err := listSummaryFiles(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := latestSummaryForSession(files, session); ok {
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		var sum summary.SessionSummary
+		if err := json.Unmarshal(data, &sum); err != nil {
+			return nil, fmt.Errorf("failed to parse summary %s: %w", f.Path, err)
+		}
+		return &sum, nil
+	}
+	if tmux.SessionExists(session) {
+		return generateKillSummary(session)
+	}
+	return nil, fmt.Errorf("no summary found for session '%s'", session)
+}
+
+// distillSessionMemory proposes memory additions from a session summary and
+// the session's conflict history, and saves them as a diff to MEMORY.md.
+func distillSessionMemory(session, projectDir string, sum *summary.SessionSummary) (*MemoryDistillResult, error) {
+	mem, err := cm.LoadMemory(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []tracker.ConflictEpisode
+	if episodes, err := tracker.NewConflictHistory("").Episodes(time.Time{}); err == nil {
+		for _, ep := range episodes {
+			if ep.Session == session {
+				conflicts = append(conflicts, ep)
+			}
+		}
+	}
+
+	result := &MemoryDistillResult{Session: session, Proposals: cm.Distill(mem, cm.DistillInput{Summary: sum, Conflicts: conflicts})}
+	if result.Proposals == nil {
+		result.Proposals = []cm.MemoryProposal{}
+	}
+	if result.Diff, err = cm.MemoryProposalDiff(projectDir, result.Proposals); err != nil {
+		return nil, err
+	}
+	if result.Diff == "" {
+		return result, nil
+	}
+	if result.Path, err = cm.WriteMemoryProposal(projectDir, session, result.Diff, time.Now()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/cm"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+)
+
+func TestMemorySectionsFor(t *testing.T) {
+	mc := config.DefaultMemoryConfig()
+	mc.Sections = map[string][]string{
+		"cod":    {"guards"},
+		"gemini": {},
+	}
+	if got := memorySectionsFor(mc, "codex"); !reflect.DeepEqual(got, []cm.SectionKind{cm.SectionGuards}) {
+		t.Errorf("codex sections = %v", got)
+	}
+	if got := memorySectionsFor(mc, "gmi"); len(got) != 0 {
+		t.Errorf("gemini sections = %v, want none", got)
+	}
+	if got := memorySectionsFor(mc, "cc"); !reflect.DeepEqual(got, cm.AllSectionKinds) {
+		t.Errorf("claude sections = %v, want all", got)
+	}
+}
+
+func TestBuildMemoryStatus(t *testing.T) {
+	dir := t.TempDir()
+	memDir := filepath.Join(dir, cm.MemoryDir)
+	if err := os.MkdirAll(memDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(memDir, "MEMORY.md"), []byte("## Guards\n- Never commit .env files\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mem, err := cm.LoadMemory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []cm.MemoryLoad{
+		{Session: "proj", Pane: 1, Version: mem.Version},
+		{Session: "proj", Pane: 2, Version: "old"},
+	} {
+		if err := cm.RecordMemoryLoad(dir, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status, err := buildMemoryStatus("proj", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Version != mem.Version || len(status.Panes) != 2 {
+		t.Fatalf("status = %+v", status)
+	}
+	if status.Panes[0].Stale || !status.Panes[1].Stale {
+		t.Errorf("stale flags = %v, %v", status.Panes[0].Stale, status.Panes[1].Stale)
+	}
+}
+
+func TestDistillSessionMemory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+
+	res, err := distillSessionMemory("proj", dir, &summary.SessionSummary{Session: "proj"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Proposals) != 0 || res.Path != "" {
+		t.Errorf("empty summary produced %+v", res)
+	}
+
+	res, err = distillSessionMemory("proj", dir, &summary.SessionSummary{Session: "proj", Decisions: []string{"Use chi for routing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Proposals) != 1 || res.Path == "" {
+		t.Fatalf("result = %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cm.MemoryDir, cm.MemoryFile)); !os.IsNotExist(err) {
+		t.Error("distillation must not modify MEMORY.md")
+	}
+	data, err := os.ReadFile(res.Path)
+	if err != nil || string(data) != res.Diff {
+		t.Errorf("saved proposal = %q, %v", data, err)
+	}
+}
@@ -84,7 +84,7 @@ func newMessageSendCmd() *cobra.Command {
 			}
 			agentName := fmt.Sprintf("ntm_%s", session)
 
-			amClient := agentmail.NewClient(agentmail.WithProjectKey(dir))
+			amClient := newAgentMailClient(dir)
 			bdClient := bd.NewMessageClient(dir, agentName)
 
 			unified := agentmail.NewUnifiedMessenger(amClient, bdClient, dir, agentName)
@@ -15,10 +15,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
 	"github.com/Dicklesworthstone/ntm/internal/config"
 	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/hooks"
 	"github.com/Dicklesworthstone/ntm/internal/plugins"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
 	"github.com/Dicklesworthstone/ntm/internal/resilience"
+	"github.com/Dicklesworthstone/ntm/internal/scheduler"
+	"github.com/Dicklesworthstone/ntm/internal/shutdown"
 	"github.com/Dicklesworthstone/ntm/internal/summary"
 	"github.com/Dicklesworthstone/ntm/internal/supervisor"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
@@ -73,6 +78,9 @@ func runMonitor(session string) error {
 			defer bridge.Close()
 		}
 	}
+	if hookBridge := startExecHookBridge(manifest.ProjectDir, session); hookBridge != nil {
+		defer hookBridge.Close()
+	}
 
 	// Initialize Supervisor
 	sup, err := supervisor.New(supervisor.Config{
@@ -105,8 +113,21 @@ func runMonitor(session string) error {
 		}
 	}
 
+	// Record that the monitor is running so a SIGKILL is detected as an
+	// unclean shutdown by the next ntm invocation.
+	markRunning("monitor-" + session)
+
 	// Initialize resilience monitor
 	monitor := resilience.NewMonitor(session, manifest.ProjectDir, cfg, manifest.AutoRestart)
+	throttle := ratelimit.NewCodexThrottle(scheduler.CodexCapConfig().MaxConcurrent)
+	if err := throttle.LoadFromDir(manifest.ProjectDir); err != nil {
+		slog.Default().Warn("ignoring saved codex throttle", "error", err)
+	}
+	monitor.SetCodexThrottle(throttle)
+	shutdown.Register("rate limits", func(context.Context) error {
+		monitor.Stop()
+		return monitor.FlushState()
+	})
 
 	// Register agents
 	for _, agent := range manifest.Agents {
@@ -120,8 +141,7 @@ func runMonitor(session string) error {
 	monitor.Start(ctx)
 
 	// Initialize archiver for background CASS capture
-	archiverOpts := archive.DefaultArchiverOptions(session)
-	archiver, err := archive.NewArchiver(archiverOpts)
+	archiver, err := newSessionArchiver(session)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize archiver: %v\n", err)
 	} else {
@@ -131,9 +151,17 @@ func runMonitor(session string) error {
 				fmt.Fprintf(os.Stderr, "Archiver error: %v\n", err)
 			}
 		}()
-		defer archiver.Close()
+		shutdown.Register("capture archive", func(context.Context) error {
+			return archiver.Close()
+		})
 	}
 
+	// Keep archives, captures and audit logs within their quotas
+	startStoragePruner(ctx)
+
+	// Ship finished audit logs off-host
+	startAuditShipper(ctx)
+
 	// Wait for termination signal or session end
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -155,6 +183,11 @@ func runMonitor(session string) error {
 		case <-sigChan:
 			fmt.Println("Monitor stopping...")
 			monitor.Stop()
+			// The session is still alive, so checkpoint it before exiting;
+			// the remaining state is flushed by the shutdown coordinator.
+			shutdown.Register("final checkpoint", func(context.Context) error {
+				return createShutdownCheckpoint(session)
+			})
 			// Try to generate summary on signal too
 			generateEndSessionSummary(session, lastOutputs, manifest)
 			return nil
@@ -182,6 +215,67 @@ func runMonitor(session string) error {
 	}
 }
 
+// newSessionArchiver creates the pane output archiver for session using
+// the [archive] settings and the storage low-disk guard.
+func newSessionArchiver(session string) (*archive.Archiver, error) {
+	archiverOpts := archive.DefaultArchiverOptions(session)
+	if cfg != nil {
+		if sec := cfg.Archive.MinIntervalSeconds; sec > 0 {
+			archiverOpts.Interval = time.Duration(sec) * time.Second
+		}
+		if sec := cfg.Archive.MaxIntervalSeconds; sec > 0 {
+			archiverOpts.MaxInterval = time.Duration(sec) * time.Second
+		}
+		if cfg.Archive.Backoff > 0 {
+			archiverOpts.Backoff = cfg.Archive.Backoff
+		}
+		archiverOpts.Mode = cfg.Archive.Mode
+		if cfg.Archive.DedupeThreshold > 0 {
+			archiverOpts.DedupeThreshold = cfg.Archive.DedupeThreshold
+		}
+	}
+	archiverOpts.MinFreeBytes = storageMinFreeBytes()
+	archiverOpts.OnDiskState = archiverDiskHandler(session)
+	archiverOpts.BeforeRecord = postCaptureHook(session)
+	return archive.NewArchiver(archiverOpts)
+}
+
+// postCaptureHook runs the project's post-capture exec hooks on each
+// record before it is archived. Hooks may rewrite the content (e.g. to
+// redact it) or veto the record, which drops it.
+func postCaptureHook(session string) func(*archive.ArchiveRecord) bool {
+	projectDir := sessionProjectDir(session)
+	return func(r *archive.ArchiveRecord) bool {
+		out, err := runExecHooks(projectDir, hooks.ExecPostCapture, session, map[string]interface{}{
+			"pane":       r.Pane,
+			"pane_index": r.PaneIndex,
+			"agent":      r.Agent,
+			"model":      r.Model,
+			"human":      r.Human,
+			"content":    r.Content,
+		})
+		if err != nil {
+			slog.Debug("capture dropped by exec hook", "session", session, "pane", r.Pane, "error", err)
+			return false
+		}
+		r.Content = out.String("content", r.Content)
+		return true
+	}
+}
+
+// createShutdownCheckpoint saves a final checkpoint of a session whose
+// monitor is being terminated.
+func createShutdownCheckpoint(session string) error {
+	if !tmux.SessionExists(session) {
+		return nil
+	}
+	_, err := checkpoint.NewCapturer().Create(session, "shutdown",
+		checkpoint.WithDescription("Automatic checkpoint on monitor shutdown"),
+		checkpoint.WithGitCapture(false),
+	)
+	return err
+}
+
 func detectSessionTerminationCause(session string) string {
 	output, err := tmux.DefaultClient.Run("list-sessions", "-F", "#{session_name}")
 	if err != nil {
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Dicklesworthstone/ntm/internal/clientgen"
 	"github.com/Dicklesworthstone/ntm/internal/kernel"
 	"github.com/Dicklesworthstone/ntm/internal/serve"
 )
@@ -130,10 +133,12 @@ func newOpenAPICmd() *cobra.Command {
 Examples:
   ntm openapi generate              # Generate docs/openapi.json
   ntm openapi generate --stdout     # Print to stdout
-  ntm openapi generate -o api.json  # Custom output path`,
+  ntm openapi generate -o api.json  # Custom output path
+  ntm openapi clients               # Generate the Python client`,
 	}
 
 	cmd.AddCommand(newOpenAPIGenerateCmd())
+	cmd.AddCommand(newOpenAPIClientsCmd())
 	return cmd
 }
 
@@ -195,3 +200,49 @@ Examples:
 
 	return cmd
 }
+
+func newOpenAPIClientsCmd() *cobra.Command {
+	var (
+		lang   string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clients",
+		Short: "Generate thin API clients for other languages",
+		Long: `Generate a thin client for the REST API, with its test suite, from embedded
+templates. Request bodies follow the server's request schemas.
+
+The Python client covers sessions, send/interrupt, the event stream, and
+reservation conflicts, and needs only the standard library.
+
+Examples:
+  ntm openapi clients                          # Write dist/clients/python
+  ntm openapi clients -o scripts/ntm_client    # Custom output directory
+  cd dist/clients/python && python3 -m unittest test_ntm_client`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output == "" {
+				output = filepath.Join("dist", "clients", lang)
+			}
+			files, err := clientgen.Generate(lang, output, Version)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return json.NewEncoder(os.Stdout).Encode(map[string]any{
+					"success":  true,
+					"language": lang,
+					"files":    files,
+				})
+			}
+			fmt.Printf("Wrote %s client to %s\n", lang, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&lang, "lang", "python", "Client language ("+strings.Join(clientgen.Languages, ", ")+")")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output directory (default dist/clients/<lang>)")
+
+	return cmd
+}
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -18,14 +20,14 @@ func newPluginsCmd() *cobra.Command {
 		Short: "Manage and list installed plugins",
 	}
 
-	cmd.AddCommand(newPluginsListCmd())
+	cmd.AddCommand(newPluginsListCmd(), newPluginsRunCmd())
 	return cmd
 }
 
 func newPluginsListCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
-		Short: "List installed agent and command plugins",
+		Short: "List installed agent, command and WASM plugins",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			configDir := filepath.Dir(config.DefaultPath())
 
@@ -37,7 +39,14 @@ func newPluginsListCmd() *cobra.Command {
 			cmdDir := filepath.Join(configDir, "commands")
 			commandPlugins, _ := plugins.LoadCommandPlugins(cmdDir)
 
-			if len(agentPlugins) == 0 && len(commandPlugins) == 0 {
+			// Load WASM Plugins
+			ctx := context.Background()
+			wasmRuntime, wasmPlugins := loadWasmPlugins(ctx)
+			if wasmRuntime != nil {
+				defer wasmRuntime.Close(ctx)
+			}
+
+			if len(agentPlugins) == 0 && len(commandPlugins) == 0 && len(wasmPlugins) == 0 {
 				fmt.Println("No plugins installed.")
 				return nil
 			}
@@ -68,6 +77,20 @@ func newPluginsListCmd() *cobra.Command {
 				fmt.Println()
 			}
 
+			if len(wasmPlugins) > 0 {
+				fmt.Println("WASM Plugins:")
+				fmt.Fprintln(w, "NAME\tKINDS\tPATH")
+				for _, p := range wasmPlugins {
+					kinds := make([]string, len(p.Kinds))
+					for i, k := range p.Kinds {
+						kinds[i] = string(k)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, strings.Join(kinds, ","), p.Path)
+				}
+				w.Flush()
+				fmt.Println()
+			}
+
 			return nil
 		},
 	}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"time"
 
 	"github.com/spf13/cobra"
 
@@ -368,8 +367,3 @@ type preflightError struct {
 func (e preflightError) Error() string {
 	return fmt.Sprintf("preflight blocked: %d errors found", e.result.ErrorCount)
 }
-
-// FormatTimestamp formats a time for robot output.
-func FormatTimestamp(t time.Time) string {
-	return t.UTC().Format(time.RFC3339)
-}
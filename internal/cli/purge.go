@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+	"github.com/Dicklesworthstone/ntm/internal/warehouse"
+)
+
+// purgeStore is the purge result for one kind of artifact.
+type purgeStore struct {
+	Store      string   `json:"store"`
+	Removed    int      `json:"removed"`
+	Anonymized int      `json:"anonymized,omitempty"`
+	Files      []string `json:"files,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// purgeReport is the output of `ntm purge`.
+type purgeReport struct {
+	Session string       `json:"session"`
+	Before  *time.Time   `json:"before,omitempty"`
+	Stores  []purgeStore `json:"stores"`
+}
+
+func newPurgeCmd() *cobra.Command {
+	var (
+		session      string
+		before       string
+		force        bool
+		confirmToken string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "purge --session <name>",
+		Short: "Remove a session's stored data for retention compliance",
+		Long: `Remove every artifact ntm has stored for a session: checkpoint captures,
+prompt history, output archives, effectiveness scores, cost records, and
+conflict history, along with their rows in the analytics warehouse.
+
+Audit logs are append-only and hash-chained, so their entries are anonymized
+instead: payloads and metadata are dropped, the chain is resealed, and a
+signed purge tombstone recording the original chain head is appended. The
+signing key lives in ~/.config/ntm/audit-signing.key.
+
+With --before, only data recorded before that time is purged. The time can be
+RFC3339, a date (2006-01-02), or relative (7d, 24h).
+
+The session must not be running.
+
+With --json there is no interactive prompt. Purge is in the default
+[robot.confirm] classes, so the first call returns a confirmation token and
+a summary instead of purging; re-run with --confirm-token to purge.
+
+Examples:
+  ntm purge --session myproject
+  ntm purge --session myproject --before 2025-01-01
+  ntm purge --session myproject --before 30d --json
+  ntm purge --session myproject --before 30d --json --confirm-token cfm_...`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var cutoff time.Time
+			if before != "" {
+				t, err := parseTimeArg(before)
+				if err != nil {
+					return err
+				}
+				cutoff = t
+			}
+			if tmux.SessionExists(session) {
+				return fmt.Errorf("session %q is running; kill it before purging its data", session)
+			}
+
+			if !force && !IsJSONOutput() {
+				scope := "all stored data"
+				if !cutoff.IsZero() {
+					scope = "data recorded before " + cutoff.Format(time.RFC3339)
+				}
+				if !confirm(fmt.Sprintf("Purge %s for session %s? This cannot be undone.", scope, session)) {
+					fmt.Println("Aborted.")
+					return nil
+				}
+			}
+
+			if IsJSONOutput() {
+				scope := "all stored data"
+				if !cutoff.IsZero() {
+					scope = "data recorded before " + cutoff.Format(time.RFC3339)
+				}
+				if out := robot.CheckConfirmation(robot.ConfirmRequest{
+					Class:   robot.ConfirmClassPurge,
+					Command: "purge",
+					Session: session,
+					Target:  "before=" + before,
+					Summary: fmt.Sprintf("Purge %s for session %s", scope, session),
+					Effects: []string{
+						"Captures, prompt history, archives, scores, cost records, conflict history, and warehouse rows are deleted",
+						"Audit entries are anonymized and sealed with a signed tombstone",
+					},
+				}, confirmToken); out != nil {
+					return output.PrintJSON(out)
+				}
+			}
+
+			report := runPurge(session, cutoff)
+			if IsJSONOutput() {
+				if err := output.PrintJSON(report); err != nil {
+					return err
+				}
+			} else {
+				printPurgeReport(report)
+			}
+
+			var failed []string
+			for _, s := range report.Stores {
+				if s.Error != "" {
+					failed = append(failed, s.Store)
+				}
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("purge incomplete: %s failed", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "Session whose data to purge (required)")
+	cmd.Flags().StringVar(&before, "before", "", "Only purge data recorded before this time")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation")
+	cmd.Flags().StringVar(&confirmToken, "confirm-token", "", "Token confirming the purge in --json mode")
+	_ = cmd.MarkFlagRequired("session")
+	return cmd
+}
+
+// runPurge purges every store, carrying on past failures so one broken
+// store does not leave the others untouched.
+func runPurge(session string, before time.Time) purgeReport {
+	report := purgeReport{Session: session}
+	if !before.IsZero() {
+		report.Before = &before
+	}
+
+	add := func(name string, removed int, err error) {
+		s := purgeStore{Store: name, Removed: removed}
+		if err != nil {
+			s.Error = err.Error()
+		}
+		report.Stores = append(report.Stores, s)
+	}
+
+	n, err := checkpoint.NewStorage().PurgeSession(session, before)
+	add("checkpoints", n, err)
+
+	n, err = history.PurgeSession(session, before)
+	add("history", n, err)
+
+	n, err = archive.PurgeSession("", session, before)
+	add("archives", n, err)
+
+	n, err = purgeScores(session, before)
+	add("scores", n, err)
+
+	n, err = purgeCosts(session, before)
+	add("costs", n, err)
+
+	n, err = tracker.NewConflictHistory("").PurgeSession(session, before)
+	add("conflicts", n, err)
+
+	n, err = purgeWarehouse(session, before)
+	add("warehouse", n, err)
+
+	report.Stores = append(report.Stores, purgeAudit(session, before))
+	return report
+}
+
+func purgeScores(session string, before time.Time) (int, error) {
+	tracker, err := scoring.NewTracker(scoring.DefaultTrackerOptions())
+	if err != nil {
+		return 0, err
+	}
+	return tracker.PurgeSession(session, before)
+}
+
+func purgeCosts(session string, before time.Time) (int, error) {
+	dir := GetProjectRoot()
+	if dir == "" {
+		return 0, nil
+	}
+	tracker := cost.NewCostTracker(dir)
+	if err := tracker.LoadFromDir(dir); err != nil {
+		return 0, err
+	}
+	n := tracker.PurgeSession(session, before)
+	if n == 0 {
+		return 0, nil
+	}
+	return n, tracker.SaveToDir(dir)
+}
+
+func purgeWarehouse(session string, before time.Time) (int, error) {
+	if _, err := os.Stat(util.ExpandPath(warehouse.DefaultPath)); os.IsNotExist(err) {
+		return 0, nil
+	}
+	w, err := warehouse.Open("")
+	if err != nil {
+		return 0, err
+	}
+	defer w.Close()
+	return w.PurgeSession(context.Background(), session, before)
+}
+
+func purgeAudit(session string, before time.Time) purgeStore {
+	s := purgeStore{Store: "audit"}
+	searcher, err := newAuditSearcherFunc()
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	key, err := audit.LoadSigningKey("")
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	res, err := audit.PurgeSession(searcher.AuditDir(), session, before, key)
+	if res != nil {
+		s.Anonymized = res.Anonymized
+		s.Files = res.Files
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	return s
+}
+
+func printPurgeReport(r purgeReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, s := range r.Stores {
+		status := fmt.Sprintf("%d removed", s.Removed)
+		switch {
+		case s.Error != "":
+			status = "error: " + s.Error
+		case s.Store == "audit":
+			status = fmt.Sprintf("%d anonymized in %d log(s), tombstone appended", s.Anonymized, len(s.Files))
+			if s.Anonymized == 0 {
+				status = "nothing to anonymize"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\n", s.Store, status)
+	}
+	_ = w.Flush()
+}
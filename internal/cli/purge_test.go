@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+	"github.com/Dicklesworthstone/ntm/internal/warehouse"
+)
+
+func TestRunPurgeRemovesSessionData(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, ".local", "share"))
+	const session = "purge-test-proj"
+
+	storage := checkpoint.NewStorage()
+	for _, id := range []string{"20260101-000000", "20260102-000000"} {
+		if err := storage.Save(&checkpoint.Checkpoint{ID: id, SessionName: session, CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("saving checkpoint: %v", err)
+		}
+	}
+
+	if err := history.BatchAppend([]*history.HistoryEntry{
+		history.NewEntry(session, []string{"1"}, "secret prompt", history.SourceCLI),
+		history.NewEntry("other", []string{"1"}, "unrelated prompt", history.SourceCLI),
+	}); err != nil {
+		t.Fatalf("appending history: %v", err)
+	}
+
+	archiveDir := filepath.Join(home, ".ntm", "archive")
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(archiveDir, session+"_2026-01-02.jsonl")
+	if err := os.WriteFile(archivePath, []byte("{\"session\":\"purge-test-proj\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scores, err := scoring.NewTracker(scoring.DefaultTrackerOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scores.Record(&scoring.Score{Session: session, AgentType: "claude"}); err != nil {
+		t.Fatalf("recording score: %v", err)
+	}
+
+	if _, err := tracker.NewConflictHistory("").RecordDetected(tracker.ConflictRecord{
+		Source: tracker.ConflictSourceReservation, Session: session, Path: "main.go",
+	}); err != nil {
+		t.Fatalf("recording conflict: %v", err)
+	}
+
+	logger, err := audit.NewAuditLogger(&audit.LoggerConfig{SessionID: session, BufferSize: 1, FlushInterval: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(audit.AuditEntry{EventType: audit.EventTypeSend, Actor: audit.ActorUser, Payload: map[string]interface{}{"message": "secret"}}); err != nil {
+		t.Fatal(err)
+	}
+	logger.Close()
+
+	wh, err := warehouse.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed := wh.Sync(context.Background(), warehouse.Sources{}).Failed(); len(failed) > 0 {
+		t.Fatalf("warehouse sync failed: %v", failed)
+	}
+	wh.Close()
+
+	report := runPurge(session, time.Time{})
+	got := make(map[string]purgeStore)
+	for _, s := range report.Stores {
+		if s.Error != "" {
+			t.Errorf("%s: %s", s.Store, s.Error)
+		}
+		got[s.Store] = s
+	}
+	want := map[string]int{"checkpoints": 2, "history": 1, "archives": 1, "scores": 1, "conflicts": 1, "warehouse": 3}
+	for store, n := range want {
+		if got[store].Removed != n {
+			t.Errorf("%s removed = %d, want %d", store, got[store].Removed, n)
+		}
+	}
+	if got["audit"].Anonymized != 1 || len(got["audit"].Files) != 1 {
+		t.Errorf("audit = %+v, want 1 anonymized entry", got["audit"])
+	}
+
+	if _, err := os.Stat(archivePath); !os.IsNotExist(err) {
+		t.Error("archive file not removed")
+	}
+	remaining, err := history.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].Session != "other" {
+		t.Errorf("history after purge = %+v", remaining)
+	}
+	data, _ := os.ReadFile(got["audit"].Files[0])
+	if strings.Contains(string(data), "secret") {
+		t.Error("audit log still contains payload")
+	}
+	if err := audit.VerifyIntegrity(got["audit"].Files[0]); err != nil {
+		t.Errorf("audit chain broken after purge: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "ntm", "audit-signing.key")); err != nil {
+		t.Errorf("signing key not created: %v", err)
+	}
+}
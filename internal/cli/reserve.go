@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newReserveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reserve",
+		Short: "Plan file reservations for tasks",
+	}
+	cmd.AddCommand(newReservePlanCmd())
+	return cmd
+}
+
+func newReservePlanCmd() *cobra.Command {
+	var (
+		opts  robot.ReservePlanOptions
+		apply bool
+		ttl   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Propose reservation patterns for a task",
+		Long: `Propose the file reservations an agent needs for a task, from the task
+text and the repository:
+
+  - paths the task mentions
+  - keywords mapped to paths in [file_reservation.keywords]
+  - directories named like words in the task
+  - files written by earlier tasks with similar titles
+
+Patterns owned by another agent in [file_reservation.ownership] are flagged.
+With --apply, the patterns are reserved for the assigned agent in one call.
+
+Examples:
+  ntm reserve plan --task bd-123
+  ntm reserve plan --task bd-123 --session myproject --apply
+  ntm reserve plan --task bd-123 --session myproject --apply --ttl 2h`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ProjectDir = GetProjectRoot()
+			if cfg != nil {
+				opts.Keywords = cfg.FileReservation.Keywords
+				opts.Ownership = cfg.FileReservation.Ownership
+			}
+			if apply && opts.Session == "" {
+				return fmt.Errorf("--apply requires --session")
+			}
+			return runReservePlan(opts, apply, ttl)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Task, "task", "", "Bead ID of the task")
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session the task is assigned in")
+	cmd.Flags().StringVar(&opts.Agent, "agent", "", "Agent to plan for (default: the assigned agent)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", robot.DefaultReservePlanLimit, "Maximum patterns to propose")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Reserve the proposed patterns")
+	cmd.Flags().StringVar(&ttl, "ttl", "1h", "Time to live when applying (e.g., 30m, 2h)")
+	_ = cmd.MarkFlagRequired("task")
+	return cmd
+}
+
+// ReservePlanResult is the JSON output of an applied reservation plan.
+type ReservePlanResult struct {
+	Plan *robot.ReservePlanOutput `json:"plan"`
+	Lock *LockResult              `json:"lock,omitempty"`
+}
+
+func runReservePlan(opts robot.ReservePlanOptions, apply bool, ttl string) error {
+	plan, err := robot.GetReservePlan(opts)
+	if err != nil {
+		return err
+	}
+	if !plan.Success {
+		if IsJSONOutput() {
+			return output.PrintJSON(plan)
+		}
+		return fmt.Errorf("%s", plan.Error)
+	}
+
+	if !apply || len(plan.Patterns) == 0 {
+		if IsJSONOutput() {
+			return output.PrintJSON(plan)
+		}
+		printReservePlan(plan)
+		return nil
+	}
+
+	result, err := lockPaths(opts.Session, plan.Agent, plan.PatternList(), plan.Reason, ttl, false)
+	if IsJSONOutput() && (err == nil || result.Error != "") {
+		return output.PrintJSON(ReservePlanResult{Plan: plan, Lock: &result})
+	}
+	if err != nil {
+		return err
+	}
+	printReservePlan(plan)
+	fmt.Println()
+	return printLockResult(result, false)
+}
+
+func printReservePlan(plan *robot.ReservePlanOutput) {
+	fmt.Printf("Reservation plan for %s\n", plan.Reason)
+	if plan.Agent != "" {
+		fmt.Printf("  Agent: %s\n", plan.Agent)
+	}
+	fmt.Println()
+
+	for _, p := range plan.Patterns {
+		fmt.Printf("  %.2f  %s (%d file(s))\n", p.Score, p.Pattern, p.Files)
+		for _, r := range p.Reasons {
+			fmt.Printf("        %s\n", r)
+		}
+	}
+	if len(plan.Similar) > 0 {
+		fmt.Println("\nSimilar tasks:")
+		for _, s := range plan.Similar {
+			fmt.Printf("  %s %q (%s)\n", s.BeadID, s.Title, s.Session)
+		}
+	}
+	if len(plan.Warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for _, w := range plan.Warnings {
+			fmt.Printf("  %s\n", w)
+		}
+	}
+
+	if len(plan.Patterns) > 0 {
+		session := plan.Session
+		if session == "" {
+			session = "<session>"
+		}
+		quoted := make([]string, len(plan.Patterns))
+		for i, p := range plan.PatternList() {
+			quoted[i] = fmt.Sprintf("%q", p)
+		}
+		fmt.Printf("\nApply with:\n  ntm lock %s %s --reason %q\n", session, strings.Join(quoted, " "), plan.Reason)
+	}
+}
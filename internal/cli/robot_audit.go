@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotAuditCmd() *cobra.Command {
+	var (
+		opts         robot.AuditOptions
+		since, until string
+		eventTypes   []string
+		actors       []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Search the audit logs one page at a time (JSON)",
+		Long: `Search the audit logs, in log order, one page of --limit entries at a time
+(default 100). A page cut short by the limit carries a next_page_cursor;
+pass it back with --cursor for the next page. Entries logged in the
+meantime never shift later pages.
+
+--since and --until accept RFC3339, a date (2006-01-02), or a relative time
+(7d, 24h).
+
+Examples:
+  ntm robot audit --session myproject --limit 50
+  ntm robot audit --event-type command,send --since 24h
+  ntm robot audit --session myproject --cursor WyJteXByb2plY3QtMjAyNi0wMS0wMS5qc29ubCIsIjUwIl0`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "audit"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := parseTimeArg(since)
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+			if until != "" {
+				t, err := parseTimeArg(until)
+				if err != nil {
+					return err
+				}
+				opts.Until = t
+			}
+			for _, et := range eventTypes {
+				opts.EventTypes = append(opts.EventTypes, audit.EventType(et))
+			}
+			for _, a := range actors {
+				opts.Actors = append(opts.Actors, audit.Actor(a))
+			}
+			return robot.PrintAudit(opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Sessions, "session", nil, "Only search these sessions (comma-separated)")
+	cmd.Flags().StringSliceVar(&eventTypes, "event-type", nil, "Only include these event types (comma-separated)")
+	cmd.Flags().StringSliceVar(&actors, "actor", nil, "Only include these actors (comma-separated)")
+	cmd.Flags().StringVar(&opts.Target, "target", "", "Only include targets matching this glob")
+	cmd.Flags().StringVar(&opts.Grep, "grep", "", "Only include entries matching this regex")
+	cmd.Flags().StringVar(&since, "since", "", "Only include entries logged after this time")
+	cmd.Flags().StringVar(&until, "until", "", "Only include entries logged before this time")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Entries per page (default 100, max 1000)")
+	cmd.Flags().StringVar(&opts.Cursor, "cursor", "", "Resume after the next_page_cursor of a previous page")
+	return cmd
+}
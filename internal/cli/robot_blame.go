@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotBlameCmd() *cobra.Command {
+	var opts robot.BlameOptions
+
+	cmd := &cobra.Command{
+		Use:   "blame <path>",
+		Short: "Show which agents modified a file and when (JSON)",
+		Long: `Report, for a file, which panes and agents of a session changed it over time.
+
+Changes come from three sources:
+  working_set  files an assignment's working set lists as written
+  tool_call    edit, write, and delete tool calls in archived pane output
+  git_commit   commits on the agents' worktree branches (ntm/<session>/<agent>)
+
+The path may be a file, a directory, or a glob ("**" matches any number of
+segments), relative to the project root. Paths inside agent worktrees are
+mapped to the same file in the project.
+
+The response lists events oldest first, the most recent change, and a
+per-agent summary. --limit keeps only the newest events.
+
+Examples:
+  ntm robot blame internal/cli/root.go --session myproject
+  ntm robot blame internal/auth --session myproject --since 2h
+  ntm robot blame 'internal/**/*_test.go' --session myproject | jq '.last_change'`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "blame"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Path = args[0]
+			opts.ProjectDir = GetProjectRoot()
+			return robot.PrintBlame(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session whose agents to blame (required)")
+	cmd.Flags().DurationVar(&opts.Since, "since", 0, "Only changes within this duration (default: all history)")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 100, "Newest events to list (0 = all)")
+	return cmd
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotCapturesCmd() *cobra.Command {
+	var (
+		opts  robot.CapturesOptions
+		since string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "captures",
+		Short: "List a session's archived pane output one page at a time (JSON)",
+		Long: `List the pane output ntm monitor archived for a session, oldest first, one
+page of --limit captures at a time (default 100). A page cut short by the
+limit carries a next_page_cursor; pass it back with --cursor for the next
+page. Captures archived in the meantime never shift later pages.
+
+--since accepts RFC3339, a date (2006-01-02), or a relative time (7d, 24h).
+
+Examples:
+  ntm robot captures --session myproject
+  ntm robot captures --session myproject --pane cc_1 --since 1h
+  ntm robot captures --session myproject --limit 20 --cursor WyIyMDI2LTAxLTAxVDEyOjAwOjAwLjAwMDAwMDAwMFp8Y2NfMXwwMDAwMDAwMDIwIl0`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "captures"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := parseTimeArg(since)
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+			return robot.PrintCaptures(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session to list (required)")
+	cmd.Flags().StringVar(&opts.Pane, "pane", "", "Only list this pane (name such as cc_1, or pane index)")
+	cmd.Flags().StringVar(&since, "since", "", "Only list captures taken after this time")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Captures per page (default 100, max 1000)")
+	cmd.Flags().StringVar(&opts.Cursor, "cursor", "", "Resume after the next_page_cursor of a previous page")
+	return cmd
+}
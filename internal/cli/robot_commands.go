@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotCommandsCmd() *cobra.Command {
+	var schemas bool
+
+	cmd := &cobra.Command{
+		Use:   "commands [command...]",
+		Short: "Dump every command, flag, type, and output schema (JSON)",
+		Long: `Describe the CLI surface as JSON so agents can discover it without scraping
+help text.
+
+Each command lists its path, aliases, subcommands, and flags with their type,
+default, and usage. Commands and --robot-* flags that print a robot response
+name their output schema; --schemas embeds those JSON Schemas (the same ones
+--robot-schema prints). Hidden and deprecated commands and flags are omitted.
+
+Pass a command path to describe only that subtree.
+
+Examples:
+  ntm robot commands
+  ntm robot commands robot --schemas
+  ntm robot commands spawn | jq '.commands[0].flags[].name'`,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "commands"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			if len(args) > 0 {
+				found, rest, err := root.Find(args)
+				if err != nil || len(rest) > 0 {
+					return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+				}
+				root = found
+			}
+			return robot.PrintCommands(robot.CommandsOptions{
+				Commands: collectCLICommands(root),
+				Schemas:  schemas,
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&schemas, "schemas", false, "Embed the JSON Schema of each referenced output")
+	return cmd
+}
+
+// collectCLICommands describes c and its visible subcommands, depth first.
+func collectCLICommands(c *cobra.Command) []robot.CLICommand {
+	info := robot.CLICommand{
+		Path:         c.CommandPath(),
+		Use:          c.Use,
+		Short:        c.Short,
+		Aliases:      c.Aliases,
+		Runnable:     c.Runnable(),
+		Flags:        []robot.CLIFlag{},
+		OutputSchema: c.Annotations[robot.OutputSchemaAnnotation],
+	}
+	c.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" || f.Name == "help" {
+			return
+		}
+		info.Flags = append(info.Flags, robot.CLIFlag{
+			Name:         f.Name,
+			Shorthand:    f.Shorthand,
+			Type:         f.Value.Type(),
+			Default:      f.DefValue,
+			Usage:        f.Usage,
+			Persistent:   c.PersistentFlags().Lookup(f.Name) != nil,
+			OutputSchema: robot.SchemaTypeForFlag(f.Name),
+		})
+	})
+
+	var out []robot.CLICommand
+	var children []robot.CLICommand
+	for _, sub := range c.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+		info.Subcommands = append(info.Subcommands, sub.Name())
+		children = append(children, collectCLICommands(sub)...)
+	}
+	out = append(out, info)
+	return append(out, children...)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func TestCollectCLICommands(t *testing.T) {
+	root := &cobra.Command{Use: "ntm"}
+	var verbose bool
+	var tail, secret string
+	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	root.Flags().StringVar(&tail, "robot-tail", "", "Capture output. Required: SESSION")
+	root.Flags().StringVar(&secret, "internal", "", "")
+	_ = root.Flags().MarkHidden("internal")
+
+	run := func(*cobra.Command, []string) error { return nil }
+	child := &cobra.Command{Use: "spawn <session>", Short: "Create a session", Aliases: []string{"sp"}, RunE: run}
+	child.Flags().Int("cc", 0, "Claude agents")
+	hidden := &cobra.Command{Use: "secret", Hidden: true, RunE: run}
+	root.AddCommand(child, hidden)
+
+	cmds := collectCLICommands(root)
+	if len(cmds) != 2 {
+		t.Fatalf("got %d commands, want 2 (hidden omitted): %+v", len(cmds), cmds)
+	}
+
+	top := cmds[0]
+	if top.Path != "ntm" || top.Runnable || len(top.Subcommands) != 1 || top.Subcommands[0] != "spawn" {
+		t.Errorf("root = %+v", top)
+	}
+	flags := map[string]robot.CLIFlag{}
+	for _, f := range top.Flags {
+		flags[f.Name] = f
+	}
+	if _, ok := flags["internal"]; ok {
+		t.Error("hidden flag listed")
+	}
+	if f := flags["verbose"]; !f.Persistent || f.Shorthand != "v" || f.Type != "bool" {
+		t.Errorf("verbose = %+v", f)
+	}
+	if f := flags["robot-tail"]; f.OutputSchema != "tail" || f.Type != "string" {
+		t.Errorf("robot-tail = %+v", f)
+	}
+
+	spawn := cmds[1]
+	if spawn.Path != "ntm spawn" || !spawn.Runnable || spawn.Aliases[0] != "sp" {
+		t.Errorf("spawn = %+v", spawn)
+	}
+	if len(spawn.Flags) != 1 || spawn.Flags[0].Name != "cc" || spawn.Flags[0].Type != "int" {
+		t.Errorf("spawn flags = %+v (inherited flags belong to the parent)", spawn.Flags)
+	}
+}
+
+func TestRobotSubcommandsDeclareOutputSchemas(t *testing.T) {
+	for _, sub := range newRobotCmd().Commands() {
+		name := sub.Annotations[robot.OutputSchemaAnnotation]
+		if name == "" {
+			t.Errorf("robot %s has no output schema annotation", sub.Name())
+			continue
+		}
+		if _, ok := robot.SchemaCommand[name]; !ok {
+			t.Errorf("robot %s names unknown schema %q", sub.Name(), name)
+		}
+	}
+}
+
+func TestRegisterRobotFlagCompletions(t *testing.T) {
+	cmd := &cobra.Command{Use: "ntm"}
+	var tail, format, search string
+	cmd.Flags().StringVar(&tail, "robot-tail", "", "Capture output. Required: SESSION")
+	cmd.Flags().StringVar(&format, "robot-format", "", "Output format")
+	cmd.Flags().StringVar(&search, "robot-search", "", "Search. Required: QUERY")
+	registerRobotFlagCompletions(cmd)
+
+	if fn, ok := cmd.GetFlagCompletionFunc("robot-tail"); !ok || fn == nil {
+		t.Error("robot-tail should complete sessions")
+	}
+	if _, ok := cmd.GetFlagCompletionFunc("robot-search"); ok {
+		t.Error("robot-search should not complete sessions")
+	}
+	fn, ok := cmd.GetFlagCompletionFunc("robot-format")
+	if !ok {
+		t.Fatal("robot-format has no completion")
+	}
+	got, _ := fn(cmd, nil, "t")
+	if len(got) != 1 || got[0] != "toon" {
+		t.Errorf("robot-format completions for %q = %v", "t", got)
+	}
+}
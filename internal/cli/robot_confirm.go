@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+// confirmPolicyFromConfig converts [robot.confirm] into a robot.ConfirmPolicy.
+func confirmPolicyFromConfig(c config.RobotConfirmConfig) robot.ConfirmPolicy {
+	policy := robot.ConfirmPolicy{
+		Require: make(map[string]bool, len(c.Require)),
+		TTL:     time.Duration(c.TTLSeconds) * time.Second,
+	}
+	for _, class := range c.Require {
+		policy.Require[class] = true
+	}
+	return policy
+}
+
+// confirmRobotAction gates a destructive robot flag on --confirm-token. It
+// returns true when the command may proceed; otherwise the confirmation
+// response has already been printed.
+func confirmRobotAction(req robot.ConfirmRequest) bool {
+	out := robot.CheckConfirmation(req, robotConfirmToken)
+	if out == nil {
+		return true
+	}
+	if err := robot.PrintConfirmation(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return false
+}
+
+// paneScope describes which panes a pane-targeting robot flag acts on.
+func paneScope(panes string, all bool, agentType string) string {
+	switch {
+	case panes != "":
+		return "panes " + panes
+	case agentType != "":
+		return agentType + " panes"
+	case all:
+		return "all panes"
+	default:
+		return "all agent panes"
+	}
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/hooks"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func newRobotConflictsCmd() *cobra.Command {
+	var (
+		opts          robot.ConflictsOptions
+		watch         bool
+		minConfidence float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "conflicts",
+		Short: "Detect likely file conflicts between agents (JSON, or NDJSON with --watch)",
+		Long: `Check modified files in the repository for likely conflicts: files touched
+while several agents were active, edits outside an Agent Mail reservation,
+overlapping reservations, and changes nobody claims.
+
+With --watch, the worktree and the git directory are watched for changes.
+After each burst of changes settles (--debounce), detection runs again and a
+single NDJSON line is written, but only if the set of conflicts changed:
+
+  {"type":"conflicts","timestamp":"...","repo_path":"...","conflicts":[...],"added":[...],"resolved":[...]}
+
+The first line always reports the initial state. Stop with Ctrl+C. Conflicts
+seen in watch mode are recorded in the conflict history (see conflict-stats).
+
+Each file in an untracked directory is checked on its own. Files matching
+.gitignore rules, such as tracked build artifacts, are skipped unless
+--include-ignored is given. Generated paths can be left out entirely with
+--exclude or the exclude list in the [conflicts] config section.
+
+Git submodules are scanned like repositories of their own, and
+--workspace-repo adds the other roots of a multi-repo workspace. Their files
+are reported as "repo:path", where repo is the submodule's path or the
+root's directory name. Reservations match either that form or, for
+repositories inside the project, the path relative to the project root.
+
+Pass --session to attribute changes to panes. Files named by an edit in a
+pane's tool calls (Claude Code, Codex and Gemini output formats) are
+attributed to that pane; for other panes, changes are attributed to those
+active when each file was modified. Activity comes from status detection:
+a pane is active while it is working, and whenever it printed output since
+it was last observed. In watch mode panes are observed every 5 seconds.
+
+Each conflict has an id. Conflicts on paths matching a [[conflicts.suppress]]
+rule, and conflicts acknowledged with 'ntm conflicts ack <id>', are muted:
+they are listed under "muted" instead of "conflicts", never appear in
+"added", and changes to them do not emit watch events. They are still
+recorded in the conflict history.
+
+Each conflict's confidence is the weight the scoring profile gives its
+situation, and conflicts below the profile's min_confidence (default 0.5)
+are not reported. Weights, the activity tolerance around a modification,
+and the threshold are set in [conflicts.scoring]; the profile in effect is
+included in the output ("scoring") so results can be reproduced.
+
+Examples:
+  ntm robot conflicts
+  ntm robot conflicts --session myproject
+  ntm robot conflicts --exclude 'gen/**' --exclude '*.pb.go'
+  ntm robot conflicts --workspace-repo ../api --workspace-repo ../web
+  ntm robot conflicts --min-confidence 0.8
+  ntm robot conflicts --watch --session myproject --debounce 1s`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "conflicts"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyConflictsConfig(&opts); err != nil {
+				return err
+			}
+			if minConfidence < 0 || minConfidence > 1 {
+				return fmt.Errorf("--min-confidence must be between 0 and 1, got %v", minConfidence)
+			}
+			if minConfidence > 0 {
+				opts.Scoring.MinConfidence = minConfidence
+			}
+			if !watch {
+				return robot.PrintConflicts(cmd.Context(), opts)
+			}
+			return robot.WatchConflicts(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch for changes and stream NDJSON when the conflict set changes")
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Attribute changes to panes of this session")
+	cmd.Flags().StringVar(&opts.RepoPath, "repo", "", "Repository to check (default: project root)")
+	cmd.Flags().DurationVar(&opts.Debounce, "debounce", robot.DefaultConflictsDebounce, "Quiet period before re-checking in watch mode")
+	cmd.Flags().StringSliceVar(&opts.Exclude, "exclude", nil, "Path patterns to leave out, in addition to [conflicts] exclude (repeatable)")
+	cmd.Flags().BoolVar(&opts.IncludeIgnored, "include-ignored", false, "Also analyze changed files matching .gitignore rules")
+	cmd.Flags().StringSliceVar(&opts.Repos, "workspace-repo", nil, "Another repository root of a multi-repo workspace, in addition to [conflicts] repos (repeatable)")
+	cmd.Flags().BoolVar(&opts.SkipSubmodules, "no-submodules", false, "Report changed submodules as single entries instead of scanning them")
+	cmd.Flags().Float64Var(&minConfidence, "min-confidence", 0, "Lowest confidence to report (default: [conflicts.scoring] min_confidence, else 0.5)")
+	return cmd
+}
+
+// applyConflictsConfig fills in the project root, the [conflicts] config
+// section, and the conflict history, whose acknowledgements mute conflicts.
+func applyConflictsConfig(opts *robot.ConflictsOptions) error {
+	if opts.RepoPath == "" {
+		opts.RepoPath = GetProjectRoot()
+	}
+	opts.History = tracker.NewConflictHistory("")
+	repoPath, session := opts.RepoPath, opts.Session
+	opts.OnConflict = func(ev *robot.ConflictEvent) {
+		notifyExecHooks(repoPath, hooks.ExecOnConflict, session, map[string]interface{}{
+			"repo_path": ev.RepoPath,
+			"added":     ev.Added,
+			"conflicts": ev.Conflicts,
+		})
+	}
+	if cfg == nil {
+		return nil
+	}
+	opts.Exclude = append(opts.Exclude, cfg.Conflicts.Exclude...)
+	opts.IncludeIgnored = opts.IncludeIgnored || cfg.Conflicts.IncludeIgnored
+	opts.Repos = append(opts.Repos, cfg.Conflicts.Repos...)
+	opts.SkipSubmodules = opts.SkipSubmodules || cfg.Conflicts.SkipSubmodules
+	scoring := cfg.Conflicts.Scoring
+	opts.Scoring = robot.ConflictScoring{
+		Profile:          scoring.Profile,
+		Weights:          scoring.Weights,
+		ToleranceSeconds: scoring.ToleranceSeconds,
+		MinConfidence:    scoring.MinConfidence,
+	}
+	for _, rule := range cfg.Conflicts.Suppress {
+		expires, err := rule.ExpiresAt()
+		if err != nil {
+			return fmt.Errorf("conflicts.suppress %q: %w", rule.Path, err)
+		}
+		opts.Suppress = append(opts.Suppress, robot.ConflictSuppression{Path: rule.Path, Reason: rule.Reason, Expires: expires})
+	}
+	return nil
+}
+
+func newRobotConflictStatsCmd() *cobra.Command {
+	var (
+		opts  robot.ConflictStatsOptions
+		since string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "conflict-stats",
+		Short: "Analyze recorded conflicts: recurring files, colliding agents, time to resolution (JSON)",
+		Long: `Analyze the conflict history recorded by the dashboard's file reservation
+watcher and by 'ntm robot conflicts --watch'. Every conflict is stored with
+how it was resolved (wait, request, force, dismiss, cleared, or expired) in
+~/.config/ntm/analytics/conflicts.jsonl.
+
+The report lists the files that conflict most often, file pairs that were in
+conflict at the same time, agent pairs that collide most, and the mean time
+to resolution overall and per outcome - a basis for re-partitioning file
+ownership between agents.
+
+--since accepts RFC3339, a date (2006-01-02), or a relative time (7d, 24h).
+
+Examples:
+  ntm robot conflict-stats
+  ntm robot conflict-stats --since 7d --top 5
+  ntm robot conflict-stats --session myproject`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "conflict_stats"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := parseTimeArg(since)
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+			return robot.PrintConflictStats(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only analyze conflicts detected after this time")
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Only analyze conflicts in this session")
+	cmd.Flags().IntVar(&opts.Top, "top", robot.DefaultConflictStatsTop, "Number of files and pairs to list (0 = all)")
+	return cmd
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/resilience"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotCrashesCmd() *cobra.Command {
+	var opts robot.CrashesOptions
+
+	cmd := &cobra.Command{
+		Use:   "crashes",
+		Short: "List forensic bundles saved for crashed agents (JSON)",
+		Long: `List the crash bundles of the current project, newest first.
+
+When the resilience monitor classifies an agent pane as crashed it saves a
+forensic bundle under .ntm/crashes/<id>/:
+
+  manifest.json     crash reason, pane, agent, restart count, file index
+  scrollback.txt    the pane's tmux scrollback at the time of the crash
+  segments.jsonl    the pane's last archived output segments
+  prompts.json      recent prompts sent to the pane
+  rate_limits.json  the pane's and providers' rate-limit state
+  env.json          environment fingerprint (versions, OS, allowlisted vars)
+
+Secrets are redacted from captured text unless redaction mode is off.
+Fetch a bundle's contents with 'ntm robot crash <id>'.
+
+Examples:
+  ntm robot crashes
+  ntm robot crashes --session myproject --limit 5`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "crashes"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ProjectDir = crashProjectDir(opts.Session)
+			return robot.PrintCrashes(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Only list crashes of this session")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Only list the most recent N crashes (0 = all)")
+	return cmd
+}
+
+func newRobotCrashCmd() *cobra.Command {
+	var session string
+
+	cmd := &cobra.Command{
+		Use:   "crash <id>",
+		Short: "Fetch a crash bundle's manifest and contents (JSON)",
+		Long: `Print one crash bundle: its manifest, pane scrollback, archived output
+segments, recent prompts, rate-limit state and environment fingerprint.
+
+Examples:
+  ntm robot crash 20260301T120000Z-myproject-2
+  ntm robot crash "$(ntm robot crashes --limit 1 | jq -r '.crashes[0].id')"`,
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "crash"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return robot.PrintCrash(crashProjectDir(session), args[0])
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "Session whose project holds the bundle (default: current project)")
+	return cmd
+}
+
+// crashProjectDir is the project whose .ntm/crashes holds a session's
+// bundles: the directory the session was spawned in, or the current project.
+func crashProjectDir(session string) string {
+	if session != "" {
+		if m, err := resilience.LoadManifest(session); err == nil && m.ProjectDir != "" {
+			return m.ProjectDir
+		}
+	}
+	return GetProjectRoot()
+}
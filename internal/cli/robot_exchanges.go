@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/exchange"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotExchangesCmd() *cobra.Command {
+	var (
+		opts  robot.ExchangesOptions
+		since string
+		watch bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "exchanges",
+		Short: "Prompt/response pairs with response latency and output size (JSON, or NDJSON with --watch)",
+		Long: `Report prompt exchanges: each prompt sent with 'ntm send' paired with the
+agent's output burst that answered it. Every exchange records the latency to
+the first output, the time until the output went quiet, and the size of the
+new output. The summary aggregates latency percentiles and response rate
+per agent type, the inputs to scoring's time-efficiency metric.
+
+With --watch, the session's panes are captured every --interval and each new
+prompt in the send history is paired with the output that follows it. An
+exchange ends once the pane has been quiet for --quiet (or after --max-wait).
+Finished exchanges are stored in ~/.config/ntm/analytics/exchanges.jsonl and
+written as NDJSON:
+
+  {"type":"exchange","timestamp":"...","exchange":{"latency_ms":2300,...}}
+
+--since accepts RFC3339, a date (2006-01-02), or a relative time (7d, 24h).
+
+Examples:
+  ntm robot exchanges --session myproject
+  ntm robot exchanges --since 24h --agent-type claude
+  ntm robot exchanges --watch --session myproject --quiet 10s`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "exchanges"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := parseTimeArg(since)
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+			if !watch {
+				return robot.PrintExchanges(opts)
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return robot.WatchExchanges(ctx, opts)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Capture panes and record exchanges as prompts are sent (NDJSON)")
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session to report on (required with --watch)")
+	cmd.Flags().StringVar(&opts.AgentType, "agent-type", "", "Only include this agent type (claude, codex, gemini)")
+	cmd.Flags().StringVar(&since, "since", "", "Only include exchanges sent after this time")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Only include the most recent N exchanges (0 = all)")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", robot.DefaultExchangesInterval, "Capture interval in watch mode")
+	cmd.Flags().DurationVar(&opts.QuietPeriod, "quiet", exchange.DefaultQuietPeriod, "Quiet period that ends a response in watch mode")
+	cmd.Flags().DurationVar(&opts.MaxWait, "max-wait", exchange.DefaultMaxWait, "Longest an exchange stays open in watch mode")
+	return cmd
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "robot",
+		Short: "Machine-readable subcommands for AI agents",
+		Long: `Machine-readable subcommands for AI agents.
+
+These complement the --robot-* flags for checks that take options.
+All output is JSON.`,
+	}
+	cmd.PersistentFlags().BoolVar(&robotHumanize, "humanize", false, "Add \"_human\" siblings to time fields (\"3m ago\", \"3m 12s\"). Env: NTM_ROBOT_HUMANIZE")
+	cmd.AddCommand(newRobotStatusCmd())
+	cmd.AddCommand(newRobotHealthCmd())
+	cmd.AddCommand(newRobotConflictsCmd())
+	cmd.AddCommand(newRobotConflictStatsCmd())
+	cmd.AddCommand(newRobotSendCmd())
+	cmd.AddCommand(newRobotExchangesCmd())
+	cmd.AddCommand(newRobotAuditCmd())
+	cmd.AddCommand(newRobotCapturesCmd())
+	cmd.AddCommand(newRobotPlanCmd())
+	cmd.AddCommand(newRobotReportsCmd())
+	cmd.AddCommand(newRobotSummarizeCmd())
+	cmd.AddCommand(newRobotWhatIfCmd())
+	cmd.AddCommand(newRobotBlameCmd())
+	cmd.AddCommand(newRobotCrashesCmd())
+	cmd.AddCommand(newRobotCrashCmd())
+	cmd.AddCommand(newRobotRateLimitCmd())
+	cmd.AddCommand(newRobotCommandsCmd())
+	cmd.AddCommand(newRobotJobCmd())
+	for _, sub := range cmd.Commands() {
+		if sub.Flags().Lookup("session") != nil {
+			_ = sub.RegisterFlagCompletionFunc("session", completeSessionFlag)
+		}
+	}
+	return cmd
+}
+
+func newRobotHealthCmd() *cobra.Command {
+	var opts robot.DeepHealthOptions
+
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Run deep component health probes (JSON)",
+		Long: `Probe each NTM component and report its status and latency.
+
+Components:
+  tmux          tmux installed and server reachable
+  state_store   state database opens and accepts writes
+  agent_mail    Agent Mail backend answers health_check (degraded if not)
+  archive_disk  free space where archives are written
+  audit_chain   hash chain of recent audit logs is intact
+  jwks          OIDC JWKS endpoint is fetchable (skipped without --jwks-url)
+
+The overall status is the worst component status: ok, degraded, or failed.
+The same report is served at /api/robot/health by 'ntm serve'.
+
+Examples:
+  ntm robot health
+  ntm robot health --jwks-url https://idp.example.com/.well-known/jwks.json
+  ntm robot health --min-disk-gb 10 --timeout 2s`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "deep_health"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ProjectDir = GetProjectRoot()
+			return robot.PrintDeepHealth(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.JWKSURL, "jwks-url", "", "OIDC JWKS URL to probe")
+	cmd.Flags().StringVar(&opts.ArchiveDir, "archive-dir", "", "Directory to check for archive disk space (default: project root)")
+	cmd.Flags().Float64Var(&opts.MinDiskFreeGB, "min-disk-gb", robot.DefaultMinDiskFreeGB, "Free space below which the disk probe is degraded")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 5*time.Second, "Timeout for each probe")
+	return cmd
+}
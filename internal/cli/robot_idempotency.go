@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/Dicklesworthstone/ntm/internal/idempotency"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+// robotMutationFlags are the robot flags that change state. Only these
+// honor --idempotency-key; read-only flags are naturally repeatable.
+var robotMutationFlags = []string{
+	"robot-send",
+	"robot-spawn",
+	"robot-controller-spawn",
+	"robot-ensemble-spawn",
+	"robot-ensemble-stop",
+	"robot-smart-restart",
+	"robot-restart-pane",
+	"robot-health-restart-stuck",
+	"robot-interrupt",
+	"robot-bulk-assign",
+	"robot-ack",
+	"robot-save",
+	"robot-restore",
+	"robot-jfp-install",
+	"robot-pipeline-run",
+	"robot-pipeline-cancel",
+	"robot-dismiss-alert",
+	"robot-bead-claim",
+	"robot-bead-create",
+	"robot-bead-close",
+	"robot-switch-account",
+	"robot-slb-approve",
+	"robot-slb-deny",
+	"robot-ru-sync",
+	"robot-context-inject",
+}
+
+// activeRobotMutation returns the mutating robot flag set on cmd, or "".
+func activeRobotMutation(cmd *cobra.Command) string {
+	for _, name := range robotMutationFlags {
+		if cmd.Flags().Changed(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// robotRequestFingerprint identifies a robot invocation by its flags and
+// arguments, excluding the idempotency key itself.
+func robotRequestFingerprint(cmd *cobra.Command, args []string) string {
+	parts := append([]string{}, args...)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name == "idempotency-key" {
+			return
+		}
+		parts = append(parts, "--"+f.Name+"="+f.Value.String())
+	})
+	return idempotency.Fingerprint(parts...)
+}
+
+// beginRobotIdempotency applies --idempotency-key to a mutating robot flag.
+// When done is true the command must not run: a recorded result was
+// replayed or the key was rejected, and the output has been written.
+// Otherwise the caller runs the command and then calls finish, which
+// records a successful result for replay and releases the key on failure.
+func beginRobotIdempotency(cmd *cobra.Command, args []string, dryRun bool) (finish func(), done bool) {
+	finish = func() {}
+	scope := activeRobotMutation(cmd)
+	if robotIdempotencyKey == "" || scope == "" || dryRun {
+		return finish, false
+	}
+
+	if err := idempotency.ValidateKey(robotIdempotencyKey); err != nil {
+		_ = robot.RobotError(err, robot.ErrCodeInvalidFlag, "Pass a non-empty key of at most 255 bytes")
+		return finish, true
+	}
+	store := idempotency.NewStore("", 0)
+	replay, err := store.Begin(scope, robotIdempotencyKey, robotRequestFingerprint(cmd, args))
+	switch {
+	case errors.Is(err, idempotency.ErrMismatch):
+		_ = robot.RobotError(err, robot.ErrCodeIdempotencyConflict,
+			"Use a new --idempotency-key for a different request")
+		return finish, true
+	case errors.Is(err, idempotency.ErrInProgress):
+		_ = robot.RobotError(err, robot.ErrCodeResourceBusy,
+			"Wait for the earlier command to finish, then retry with the same key")
+		return finish, true
+	case err != nil:
+		// The store is an optimization for retries; never block the
+		// command because it is unavailable.
+		fmt.Fprintf(os.Stderr, "Warning: idempotency store unavailable: %v\n", err)
+		return finish, false
+	case replay != nil:
+		fmt.Fprintf(os.Stderr, "Replaying result recorded for idempotency key %q at %s\n",
+			robotIdempotencyKey, robot.FormatTimestamp(replay.CompletedAt))
+		_, _ = os.Stdout.Write(replay.Body)
+		return finish, true
+	}
+
+	// Results are recorded as soon as they are written, since many robot
+	// handlers exit the process directly after printing.
+	var (
+		rendered  []byte
+		completed bool
+	)
+	remove := robot.SetOutputHook(func(payload any, out string) {
+		rendered = append(rendered, out...)
+		if robotPayloadSucceeded(payload) {
+			completed = store.Complete(scope, robotIdempotencyKey, robot.GetContentType(robot.OutputFormat), rendered) == nil
+		}
+	})
+	return func() {
+		remove()
+		if !completed {
+			_ = store.Abandon(scope, robotIdempotencyKey)
+		}
+	}, false
+}
+
+// robotPayloadSucceeded reports whether a robot payload describes success.
+// Payloads without a success field are treated as successful.
+func robotPayloadSucceeded(payload any) bool {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+	var resp struct {
+		Success *bool `json:"success"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil || resp.Success == nil {
+		return err == nil
+	}
+	return *resp.Success
+}
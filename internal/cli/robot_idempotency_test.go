@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func TestRobotPayloadSucceeded(t *testing.T) {
+	if !robotPayloadSucceeded(robot.NewRobotResponse(true)) {
+		t.Error("success response should count as succeeded")
+	}
+	if robotPayloadSucceeded(robot.NewErrorResponse(nil, robot.ErrCodeInternalError, "")) {
+		t.Error("error response should not count as succeeded")
+	}
+	if !robotPayloadSucceeded(map[string]int{"count": 1}) {
+		t.Error("payload without a success field should count as succeeded")
+	}
+}
+
+func TestRobotRequestFingerprint_IgnoresKey(t *testing.T) {
+	newCmd := func(argv ...string) *cobra.Command {
+		cmd := &cobra.Command{Use: "ntm"}
+		var send, msg, key string
+		cmd.Flags().StringVar(&send, "robot-send", "", "")
+		cmd.Flags().StringVar(&msg, "msg", "", "")
+		cmd.Flags().StringVar(&key, "idempotency-key", "", "")
+		if err := cmd.ParseFlags(argv); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+
+	a := newCmd("--robot-send=proj", "--msg=hi", "--idempotency-key=k1")
+	b := newCmd("--idempotency-key=k2", "--msg=hi", "--robot-send=proj")
+	c := newCmd("--robot-send=proj", "--msg=bye", "--idempotency-key=k1")
+
+	if activeRobotMutation(a) != "robot-send" {
+		t.Errorf("activeRobotMutation = %q, want robot-send", activeRobotMutation(a))
+	}
+	if robotRequestFingerprint(a, nil) != robotRequestFingerprint(b, nil) {
+		t.Error("fingerprint should not depend on the key or flag order")
+	}
+	if robotRequestFingerprint(a, nil) == robotRequestFingerprint(c, nil) {
+		t.Error("different messages should have different fingerprints")
+	}
+}
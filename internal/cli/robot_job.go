@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+// robotJobStore is the job store of this process: the worker's when it
+// runs as one, else the default.
+func robotJobStore() *jobs.Store {
+	return jobs.NewStore(os.Getenv(jobs.EnvJobDir))
+}
+
+func newRobotJobCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "Run long robot commands as background jobs (JSON)",
+		Long: `Run long robot commands in the background and poll them, instead of
+blocking on a call that takes minutes.
+
+'submit' starts any ntm command as a job and returns its ID at once. The
+command runs in a detached worker; commands that report progress (such as
+'robot blame' or 'robot summarize' with a summarizer) show a percentage, a
+message and partial results while they run. Jobs are kept in ~/.ntm/jobs
+for 7 days after they finish. The same jobs are served at /api/v1/jobs by
+'ntm serve'.
+
+Examples:
+  ntm robot job submit -- robot blame internal/cli/root.go --session myproject
+  ntm robot job status                  # All jobs, newest first
+  ntm robot job status <id>             # Status, progress and message
+  ntm robot job result <id>             # Partial results, then the result
+  ntm robot job cancel <id>`,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "jobs"},
+	}
+	cmd.AddCommand(newRobotJobSubmitCmd(), newRobotJobStatusCmd(), newRobotJobResultCmd(), newRobotJobCancelCmd(), newRobotJobRunCmd())
+	return cmd
+}
+
+func newRobotJobSubmitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "submit -- <ntm args...>",
+		Short:       "Start an ntm command as a background job",
+		Args:        cobra.ArbitraryArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "job"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return robot.PrintSubmitJob(robotJobStore(), args)
+		},
+	}
+}
+
+func newRobotJobStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "status [id]",
+		Short:       "Show a job's status and progress, or list all jobs",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "job"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return robot.PrintJobs(robotJobStore())
+			}
+			return robot.PrintJob(robotJobStore(), args[0])
+		},
+	}
+}
+
+func newRobotJobResultCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "result <id>",
+		Short:       "Show a job's partial results and, once finished, its result",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "job_result"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return robot.PrintJobResult(robotJobStore(), args[0])
+		},
+	}
+}
+
+func newRobotJobCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "cancel <id>",
+		Short:       "Stop a pending or running job",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "job"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return robot.PrintCancelJob(robotJobStore(), args[0])
+		},
+	}
+}
+
+// newRobotJobRunCmd is the worker 'submit' starts for each job.
+func newRobotJobRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "run <id>",
+		Short:  "Run a submitted job (internal)",
+		Hidden: true,
+		Args:   cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return robotJobStore().Run(ctx, args[0])
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotPlanCmd() *cobra.Command {
+	var opts robot.TaskGraphOptions
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Dependency-aware task graph: what can run in parallel vs. must serialize (JSON)",
+		Long: `Build a task graph from in-progress, ready, and blocked beads (via bv and br)
+and the active Agent Mail file reservations.
+
+Edges order tasks that cannot run at the same time:
+  depends_on     the target is blocked by the source bead
+  file_overlap   both tasks mention overlapping files; the source goes first
+
+Tasks are layered into waves: every task in a wave can run in parallel once
+the earlier waves it depends on are done. start_now lists ready tasks with
+no ordering constraints, no outside blockers, and no files reserved by other
+agents - the ones an orchestrator can assign immediately.
+
+Files are extracted from each bead's title and description. --dot adds the
+graph in Graphviz DOT format (render with: jq -r .dot | dot -Tsvg).
+
+Examples:
+  ntm robot plan
+  ntm robot plan --limit 20 --dot`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "task_graph"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return robot.PrintTaskGraph(opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.Limit, "limit", robot.DefaultTaskGraphLimit, "Maximum ready and blocked tasks to plan (0 = all)")
+	cmd.Flags().BoolVar(&opts.DOT, "dot", false, "Include the graph in Graphviz DOT format")
+	cmd.Flags().StringVar(&opts.ProjectKey, "project", "", "Agent Mail project key for reservations (default: current directory)")
+	return cmd
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/scheduler"
+)
+
+func newRobotRateLimitCmd() *cobra.Command {
+	var opts robot.RateLimitOptions
+
+	cmd := &cobra.Command{
+		Use:   "ratelimit [session]",
+		Short: "Show rate-limit cooldowns, learned delays and blocked panes (JSON)",
+		Long: `Report rate limiting for a project in one response:
+
+  providers       learned delay, schedule-adjusted delay and cooldown of
+                  every provider and pool account (provider/account)
+  codex_throttle  the Codex launch throttle: phase, allowed launches,
+                  paused pool accounts
+  panes           with a session, each agent pane's impact: whether it is
+                  blocked, why, and until when
+
+State comes from the project's .ntm directory, where 'ntm monitor' saves
+it; a pane's current output is also checked for rate-limit messages.
+Add --humanize for "in 3m 12s" beside times and durations; set
+NTM_ROBOT_FORMAT=toon for compact output.
+
+Examples:
+  ntm robot ratelimit
+  ntm robot ratelimit myproject
+  ntm robot ratelimit myproject --humanize | jq '.panes[] | select(.blocked)'
+  NTM_ROBOT_FORMAT=toon ntm robot ratelimit myproject`,
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "ratelimit"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ProjectDir = GetProjectRoot()
+			if len(args) == 1 {
+				opts.Session = args[0]
+				if dir := sessionProjectDir(opts.Session); dir != "" {
+					opts.ProjectDir = dir
+				}
+			}
+			opts.MaxConcurrent = scheduler.CodexCapConfig().MaxConcurrent
+			opts.Schedule = rateLimitSchedule()
+			return robot.PrintRateLimit(opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.Lines, "lines", 20, "Lines of each pane to scan for rate-limit messages")
+	return cmd
+}
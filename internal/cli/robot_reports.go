@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newRobotReportsCmd() *cobra.Command {
+	var (
+		opts  robot.ReportsOptions
+		since string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reports",
+		Short: "Agent self-reports: task, percent, blockers, files touched (JSON)",
+		Long: `Report what agents say about their own progress.
+
+Agents can print structured self-reports in their output:
+
+  NTM-REPORT{"task_id":"bd-12","percent":40,"status":"working","summary":"wiring the API","blockers":[],"files_touched":["api/routes.go"]}
+
+status is one of working, blocked, done or failed. All fields are optional.
+The archiver in 'ntm monitor' picks up new reports as it captures output and
+emits an agent.progress event for each, which is journaled and sent to
+webhooks. This command returns the journaled reports plus any still on screen
+that have not been captured yet. latest holds each pane's current report;
+summary lists blocked and done panes.
+
+The response's protocol field is an instruction to include in agent prompts
+so they self-report.
+
+Examples:
+  ntm robot reports --session myproject
+  ntm robot reports --session myproject --panes 2,3 --since 1h
+  ntm robot reports --session myproject | jq '.summary.blocked'`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "reports"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := parseTimeArg(since)
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+			return robot.PrintReports(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session to report on (required)")
+	cmd.Flags().IntSliceVar(&opts.Panes, "panes", nil, "Only include these pane indices")
+	cmd.Flags().StringVar(&since, "since", "", "Only include journaled reports after this time (RFC3339, date, or 24h/7d)")
+	cmd.Flags().IntVar(&opts.Lines, "lines", tmux.LinesFullContext, "Scrollback lines to scan for reports not yet journaled")
+	return cmd
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotSendCmd() *cobra.Command {
+	var (
+		opts    robot.FanoutSendOptions
+		panes   string
+		vars    []string
+		noEnter bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "send",
+		Short: "Send a templated prompt to several panes with staggering and confirmation (JSON)",
+		Long: `Render a prompt once per pane and deliver it, returning per-pane results.
+
+Panes are selected by index, pane ID, title, or title suffix (cc_1 matches
+myproject__cc_1). The prompt comes from a template (--template) or --msg;
+either is rendered for each pane with the --var values plus the pane's agent
+context ({{agent_num}}, {{agent_type}}, {{send_index}}, ...).
+
+Sends to panes of the same provider are staggered by the delay learned by
+the rate-limit tracker (.ntm/rate_limits.json), adjusted by any
+[[resilience.rate_limit.schedule]] window in effect; --delay sets a minimum gap
+between any two sends. Panes whose provider is cooling down are reported as
+"deferred" with retry_after_ms instead of being sent.
+
+Prompts that contain destructive commands (rm -rf, git reset --hard, ...)
+are not sent until confirmed: the first attempt fails with
+CONFIRMATION_REQUIRED and returns a confirm_token bound to the exact panes
+and prompts; re-run with --confirm-token to deliver.
+
+With --idempotency-key (or a confirm token), the per-pane results are
+recorded for 24 hours and a retry with the same key replays them instead of
+sending twice. Reusing a key for a different send is an error.
+
+Examples:
+  ntm robot send --session myproject --panes cc_1,cc_2 --template review --var pr=42
+  ntm robot send --session myproject --panes 1,2,3 --msg "Rebase onto main" --delay 2000
+  ntm robot send --session myproject --panes cc_1 --msg "rm -rf build" --confirm-token snd_...`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "fanout_send"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, p := range strings.Split(panes, ",") {
+				if p = strings.TrimSpace(p); p != "" {
+					opts.Panes = append(opts.Panes, p)
+				}
+			}
+			opts.Vars = make(map[string]string)
+			for _, v := range vars {
+				parts := strings.SplitN(v, "=", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("invalid --var format '%s' (expected key=value)", v)
+				}
+				opts.Vars[parts[0]] = parts[1]
+			}
+			if noEnter {
+				enter := false
+				opts.Enter = &enter
+			}
+			opts.ProjectDir = GetProjectRoot()
+			opts.Schedule = rateLimitSchedule()
+			if cfg != nil {
+				opts.Redaction = cfg.Redaction.ToRedactionLibConfig()
+			}
+			return robot.PrintFanoutSend(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session containing the panes")
+	cmd.Flags().StringVar(&panes, "panes", "", "Comma-separated panes (index, ID, title, or suffix like cc_1)")
+	cmd.Flags().StringVarP(&opts.Template, "template", "t", "", "Prompt template name")
+	cmd.Flags().StringVar(&opts.Message, "msg", "", "Inline prompt (may use template variables)")
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Template variable in key=value format (repeatable)")
+	cmd.Flags().StringVar(&opts.ConfirmToken, "confirm-token", "", "Token confirming a destructive send")
+	cmd.Flags().StringVar(&opts.IdempotencyKey, "idempotency-key", "", "Replay the recorded result when retried with the same key")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Render prompts and report what would be sent")
+	cmd.Flags().IntVar(&opts.DelayMs, "delay", 0, "Minimum delay between sends in milliseconds")
+	cmd.Flags().BoolVar(&noEnter, "no-enter", false, "Type the prompt without pressing Enter")
+	return cmd
+}
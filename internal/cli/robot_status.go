@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+func newRobotStatusCmd() *cobra.Command {
+	var (
+		opts robot.StatusDiffOptions
+		diff bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Fleet status (JSON), or only what changed since the last check with --diff",
+		Long: `Print the same fleet status as --robot-status.
+
+With --diff, compare the current status to the last status checkpoint and
+report only what changed: agents added or removed, agent status transitions
+(e.g. running -> rate_limited), new and resolved file conflicts, and the cost
+delta per session. Every --diff run then becomes the new checkpoint, so
+repeated calls report incremental progress - handy for orchestrators
+summarizing progress to a human.
+
+Use --save to store a named snapshot and --against to diff with it instead
+of the last checkpoint. Snapshots live in ~/.config/ntm/status/.
+
+Examples:
+  ntm robot status --diff
+  ntm robot status --save standup            # Record a named baseline
+  ntm robot status --diff --against standup  # Everything since standup`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "status"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.ProjectDir = GetProjectRoot()
+			if diff || opts.Against != "" {
+				return robot.PrintStatusDiff(opts)
+			}
+			if opts.Save != "" {
+				if _, err := robot.SaveCurrentStatus(opts, opts.Save); err != nil {
+					return err
+				}
+			}
+			return robot.PrintStatus()
+		},
+	}
+
+	cmd.Flags().BoolVar(&diff, "diff", false, "Report only changes since the last checkpoint (or --against)")
+	cmd.Flags().StringVar(&opts.Against, "against", "", "Diff against this named snapshot instead of the last checkpoint (implies --diff)")
+	cmd.Flags().StringVar(&opts.Save, "save", "", "Also store the current status as a named snapshot")
+	return cmd
+}
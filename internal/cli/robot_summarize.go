@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newRobotSummarizeCmd() *cobra.Command {
+	var (
+		opts       robot.SummarizeOptions
+		model      string
+		ollamaHost string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "summarize",
+		Short: "Summarize a pane's recent output within a token budget (JSON)",
+		Long: `Catch up on a pane cheaply.
+
+Recent archived captures (from 'ntm monitor') and the live scrollback are
+combined and stripped of noise: spinners, status lines, progress bars,
+borders and repeated lines. The most informative remaining lines are kept,
+in order, until the token budget is used. Self-reports, errors, test
+results, file edits and agent messages rank highest, then recent lines.
+
+With --summarizer-model, that digest (at four times the budget) is instead
+rewritten by a local Ollama model. If the model cannot be reached, the
+extractive summary is returned with a warning.
+
+A stopped session can still be summarized from its archive by pane index.
+
+Examples:
+  ntm robot summarize --session myproject --pane cc_1
+  ntm robot summarize --session myproject --pane 2 --budget 300 --archived 20
+  ntm robot summarize --session myproject --pane cc_1 --summarizer-model llama3.2 | jq -r '.summary.text'`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "summarize"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if model != "" {
+				opts.Summarizer = &ollamaSummarizer{host: ollamaHost, model: model}
+			}
+			return robot.PrintSummarize(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session containing the pane (required)")
+	cmd.Flags().StringVar(&opts.Pane, "pane", "", "Pane index, name (cc_1) or ID (required)")
+	cmd.Flags().IntVar(&opts.Budget, "budget", robot.DefaultSummarizeBudget, "Maximum summary size in tokens")
+	cmd.Flags().IntVar(&opts.Lines, "lines", tmux.LinesFullContext, "Live scrollback lines to capture")
+	cmd.Flags().IntVar(&opts.Archived, "archived", 10, "Archived capture segments to include (0 = live output only)")
+	cmd.Flags().StringVar(&model, "summarizer-model", "", "Ollama model to write the summary (default: extractive only)")
+	cmd.Flags().StringVar(&ollamaHost, "ollama-host", "", "Ollama host for --summarizer-model (default: NTM_OLLAMA_HOST, OLLAMA_HOST, or localhost)")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", 60*time.Second, "Time limit for the summarizer model")
+	return cmd
+}
+
+// ollamaSummarizer summarizes with a local Ollama model.
+type ollamaSummarizer struct {
+	host  string
+	model string
+}
+
+func (s *ollamaSummarizer) Summarize(ctx context.Context, prompt string, maxTokens int) (string, error) {
+	adapter, err := connectOllamaAdapter(s.host)
+	if err != nil {
+		return "", err
+	}
+	defer adapter.Close()
+	adapter.SetModel(s.model)
+	resp, err := adapter.SendPrompt(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
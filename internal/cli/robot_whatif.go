@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newRobotWhatIfCmd() *cobra.Command {
+	var (
+		opts         robot.WhatIfOptions
+		cc, cod, gmi int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "what-if",
+		Short: "Project completion time, cost, and rate-limit risk of a fleet change (JSON)",
+		Long: `Answer "what happens if I add 2 more codex agents?" before spawning them.
+
+Pacing comes from the session's assignments completed in the window: the
+average task time per agent type (falling back to the fleet average, then
+30 minutes). Spend per agent-hour comes from the cost tracker, and each
+provider's rate-limit pressure from the adaptive limiter's history.
+
+Agents of one provider share its quota, so adding agents raises how often
+each of them is rate limited. The projection discounts throughput and spend
+by the time agents are expected to stall, and rates each provider's risk
+as none, low, medium, or high.
+
+The response has the current and projected throughput, time to finish the
+remaining work (active assignments plus ready beads, or --tasks), its cost,
+and, if the session has a budget, whether the change would exceed it.
+
+Examples:
+  ntm robot what-if --session myproject --cod 2
+  ntm robot what-if --session myproject --cc 1 --gmi -1 --tasks 40
+  ntm robot what-if --session myproject --cod 3 --budget 50 | jq '.verdict'`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{robot.OutputSchemaAnnotation: "whatif"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.Add = map[tmux.AgentType]int{}
+			for t, n := range map[tmux.AgentType]*int{tmux.AgentClaude: &cc, tmux.AgentCodex: &cod, tmux.AgentGemini: &gmi} {
+				if cmd.Flags().Changed(string(t)) {
+					opts.Add[t] = *n
+				}
+			}
+			opts.ProjectDir = GetProjectRoot()
+			return robot.PrintWhatIf(opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Session, "session", "", "Session to project (required)")
+	cmd.Flags().IntVar(&cc, "cc", 0, "Claude agents to add (negative removes)")
+	cmd.Flags().IntVar(&cod, "cod", 0, "Codex agents to add (negative removes)")
+	cmd.Flags().IntVar(&gmi, "gmi", 0, "Gemini agents to add (negative removes)")
+	cmd.Flags().IntVar(&opts.Tasks, "tasks", 0, "Remaining tasks (default: active assignments plus ready beads)")
+	cmd.Flags().DurationVar(&opts.Window, "window", 24*time.Hour, "History to learn pacing from")
+	cmd.Flags().Float64Var(&opts.BudgetUSD, "budget", 0, "Hard budget in USD (default: the session's budget)")
+	return cmd
+}
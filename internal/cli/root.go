@@ -15,9 +15,12 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/archive"
 	"github.com/Dicklesworthstone/ntm/internal/audit"
 	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/crashbundle"
 	"github.com/Dicklesworthstone/ntm/internal/encryption"
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/history"
@@ -88,6 +91,12 @@ Shell Integration:
 			tmux.DefaultClient = tmux.NewClient(sshHost)
 		}
 
+		// Record tmux interactions into a fixture bundle if NTM_RECORD is set
+		startRecordingFromEnv()
+
+		// --humanize applies to --robot-* flags and ntm robot subcommands alike
+		resolveRobotHumanize()
+
 		// Handle --no-color flag by setting environment variable
 		// This integrates with the existing theme.NoColorEnabled() system
 		if noColor {
@@ -127,6 +136,9 @@ Shell Integration:
 			// Apply redaction flag overrides
 			applyRedactionFlagOverrides(cfg)
 
+			// Route Agent Mail clients to the configured backend (http or embedded).
+			agentmail.SetDefaultBackend(cfg.AgentMail.Backend)
+
 			// Ensure persisted prompt history + event logs never store raw secrets/PII when redaction is enabled.
 			// (bd-3sl0s)
 			if cfg != nil {
@@ -138,18 +150,33 @@ Shell Integration:
 				audit.SetRedactionConfig(&redactCfg)
 				session.SetRedactionConfig(&redactCfg)
 				checkpoint.SetRedactionConfig(&redactCfg)
+
+				robot.SetConfirmPolicy(confirmPolicyFromConfig(cfg.Robot.Confirm))
+				tmux.SetPasteOptions(pasteOptionsFromConfig(cfg.Send))
+				tmux.SetCaptureLimits(tmux.CaptureLimits{
+					MaxCaptureBytes: cfg.Tmux.MaxCaptureBytes,
+					MaxPaneBytes:    cfg.Tmux.MaxPaneBytes,
+				})
+				applyDeadlines(cfg.Deadlines)
+				if err := applySendProfiles(cfg.Send.Profiles); err != nil {
+					output.PrintWarningf("ignoring [send.profiles]: %v", err)
+					tmux.ResetSendProfiles()
+				}
 			}
 
-			// Wire encryption into history + event log persistence (bd-3ld77)
+			// Wire encryption into history, event log, archive, and audit
+			// persistence (bd-3ld77)
 			if cfg != nil && cfg.Encryption.Enabled {
 				keyCfg := encryption.KeyConfig{
-					KeySource:   cfg.Encryption.KeySource,
-					KeyEnv:      cfg.Encryption.KeyEnv,
-					KeyFile:     cfg.Encryption.KeyFile,
-					KeyCommand:  cfg.Encryption.KeyCommand,
-					KeyFormat:   cfg.Encryption.KeyFormat,
-					ActiveKeyID: cfg.Encryption.ActiveKeyID,
-					Keyring:     cfg.Encryption.Keyring,
+					KeySource:       cfg.Encryption.KeySource,
+					KeyEnv:          cfg.Encryption.KeyEnv,
+					KeyFile:         cfg.Encryption.KeyFile,
+					KeyCommand:      cfg.Encryption.KeyCommand,
+					KeychainService: cfg.Encryption.KeychainService,
+					KeychainAccount: cfg.Encryption.KeychainAccount,
+					KeyFormat:       cfg.Encryption.KeyFormat,
+					ActiveKeyID:     cfg.Encryption.ActiveKeyID,
+					Keyring:         cfg.Encryption.Keyring,
 				}
 				encKey, err := encryption.ResolveKey(keyCfg)
 				if err != nil {
@@ -169,10 +196,34 @@ Shell Integration:
 							EncryptKey:  encKey,
 							DecryptKeys: allKeys,
 						})
+						archive.SetEncryptionConfig(&archive.EncryptionConfig{
+							Enabled:     true,
+							EncryptKey:  encKey,
+							DecryptKeys: allKeys,
+						})
+						audit.SetEncryptionConfig(&audit.EncryptionConfig{
+							Enabled:     true,
+							EncryptKey:  encKey,
+							DecryptKeys: allKeys,
+						})
 					}
 				}
 			}
 
+			// Put each artifact class under its configured root before
+			// anything is read or written.
+			applyStorageRoots(cfg.Storage.Roots)
+			if cmd.Name() != "migrate" {
+				warnUnmigratedStorage()
+			}
+
+			// Repair logs left half-written by a run that was killed before
+			// flushing, so appends below resume from the last complete record.
+			recoverUncleanShutdown()
+
+			// Persist bus events so incidents can be reconstructed with `ntm events replay`.
+			events.EnableDefaultJournal()
+
 			// Run automatic temp file cleanup if enabled
 			MaybeRunStartupCleanup(
 				cfg.Cleanup.AutoCleanOnStartup,
@@ -192,6 +243,16 @@ Shell Integration:
 		resolveRobotFormat(cfg)
 		resolveRobotVerbosity(cfg)
 		robotDryRunEffective := robotDryRun || robotRestoreDry
+		if cmd.Flags().Changed("if-changed") {
+			robot.IfChanged.Enabled = true
+			robot.IfChanged.ETag = strings.TrimSpace(robotIfChanged)
+		}
+
+		finishIdempotency, done := beginRobotIdempotency(cmd, args, robotDryRunEffective)
+		if done {
+			return
+		}
+		defer finishIdempotency()
 
 		// Handle robot flags for AI agent integration
 		if robotHelp {
@@ -440,6 +501,23 @@ Shell Integration:
 				Force:     robotEnsembleStopForce,
 				NoCollect: robotEnsembleStopNoCollect,
 			}
+			var effects []string
+			if opts.Force {
+				effects = append(effects, "Agents are killed without graceful shutdown")
+			}
+			if opts.NoCollect {
+				effects = append(effects, "Partial outputs are not collected")
+			}
+			if !confirmRobotAction(robot.ConfirmRequest{
+				Class:   robot.ConfirmClassStop,
+				Command: "robot-ensemble-stop",
+				Session: robotEnsembleStop,
+				Target:  fmt.Sprintf("force=%t no_collect=%t", opts.Force, opts.NoCollect),
+				Summary: fmt.Sprintf("Stop the ensemble in session %s", robotEnsembleStop),
+				Effects: effects,
+			}) {
+				return
+			}
 			if err := robot.PrintEnsembleStop(robotEnsembleStop, opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -724,6 +802,7 @@ Shell Integration:
 				Stats:     robotHistoryStats,
 				Limit:     pagination.Limit,
 				Offset:    pagination.Offset,
+				Cursor:    pagination.Cursor,
 			}
 			if err := robot.PrintHistory(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -969,6 +1048,22 @@ Shell Integration:
 				LinesCaptured: robotLines,
 				Verbose:       robotSmartRestartVerbose,
 			}
+			if !opts.DryRun {
+				effects := []string{"Idle agents are exited and relaunched, losing their conversation context"}
+				if opts.Force {
+					effects = append(effects, "Working agents are restarted too (--force)")
+				}
+				if !confirmRobotAction(robot.ConfirmRequest{
+					Class:   robot.ConfirmClassRestart,
+					Command: "robot-smart-restart",
+					Session: robotSmartRestart,
+					Target:  fmt.Sprintf("panes=%s force=%t prompt=%q", robotPanes, opts.Force, opts.Prompt),
+					Summary: fmt.Sprintf("Restart %s in session %s", paneScope(robotPanes, false, ""), robotSmartRestart),
+					Effects: effects,
+				}) {
+					return
+				}
+			}
 			if err := robot.PrintSmartRestart(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -1178,6 +1273,16 @@ Shell Integration:
 				Threshold: threshold,
 				DryRun:    robotDryRunEffective,
 			}
+			if !opts.DryRun && !confirmRobotAction(robot.ConfirmRequest{
+				Class:   robot.ConfirmClassRestart,
+				Command: "robot-health-restart-stuck",
+				Session: robotHealthRestartStuck,
+				Target:  fmt.Sprintf("threshold=%s", threshold),
+				Summary: fmt.Sprintf("Restart agents with no output for %s in session %s", threshold, robotHealthRestartStuck),
+				Effects: []string{"Stuck agents are killed and relaunched, losing their conversation context"},
+			}) {
+				return
+			}
 			if err := robot.PrintAutoRestartStuck(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -1409,6 +1514,22 @@ Shell Integration:
 				TimeoutMs: int(interruptTimeout.Milliseconds()),
 				DryRun:    robotDryRunEffective,
 			}
+			if !opts.DryRun {
+				effects := []string{"Agents abandon their current task"}
+				if opts.Message != "" {
+					effects = append(effects, "Then sends: "+opts.Message)
+				}
+				if !confirmRobotAction(robot.ConfirmRequest{
+					Class:   robot.ConfirmClassInterrupt,
+					Command: "robot-interrupt",
+					Session: robotInterrupt,
+					Target:  fmt.Sprintf("panes=%s all=%t force=%t msg=%q", robotPanes, opts.All, opts.Force, opts.Message),
+					Summary: fmt.Sprintf("Send Ctrl+C to %s in session %s", paneScope(robotPanes, opts.All, ""), robotInterrupt),
+					Effects: effects,
+				}) {
+					return
+				}
+			}
 			if err := robot.PrintInterrupt(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -1430,6 +1551,16 @@ Shell Integration:
 				Bead:    robotRestartPaneBead,
 				Prompt:  robotRestartPanePrompt,
 			}
+			if !opts.DryRun && !confirmRobotAction(robot.ConfirmRequest{
+				Class:   robot.ConfirmClassRestart,
+				Command: "robot-restart-pane",
+				Session: robotRestartPane,
+				Target:  fmt.Sprintf("panes=%s type=%s all=%t bead=%s prompt=%q", robotPanes, opts.Type, opts.All, opts.Bead, opts.Prompt),
+				Summary: fmt.Sprintf("Restart %s in session %s", paneScope(robotPanes, opts.All, opts.Type), robotRestartPane),
+				Effects: []string{"Agent processes are killed and relaunched, losing their conversation context"},
+			}) {
+				return
+			}
 			if err := robot.PrintRestartPane(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
@@ -1676,7 +1807,7 @@ Shell Integration:
 				Session: robotDiff,
 				Since:   since,
 			}
-			if err := robot.PrintDiff(opts); err != nil {
+			if err := robot.PrintDiff(cmd.Context(), opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -1966,9 +2097,12 @@ Shell Integration:
 }
 
 func Execute() error {
-	err := rootCmd.Execute()
+	registerBaseShutdownHooks()
+	ctx, stop := interruptContext()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	logCommandAuditEnd(err)
-	_ = audit.CloseAll()
+	runShutdown()
 	if err != nil {
 		// If not in JSON mode, print the error to stderr
 		// (SilenceErrors is set to true to handle JSON mode properly)
@@ -1997,6 +2131,13 @@ func resolveRobotPagination(cmd *cobra.Command) (robot.PaginationOptions, error)
 		return opts, fmt.Errorf("pagination values must be >= 0")
 	}
 
+	if cmd.Flags().Changed("robot-cursor") {
+		if opts.Offset > 0 {
+			return opts, fmt.Errorf("--robot-cursor and --robot-offset are mutually exclusive")
+		}
+		opts.Cursor = robotCursor
+	}
+
 	return opts, nil
 }
 
@@ -2116,6 +2257,9 @@ var (
 	robotBeadLimit             int    // limit for ready/in-progress beads in snapshot
 	robotLimit                 int    // pagination limit for robot list outputs
 	robotOffset                int    // pagination offset for robot list outputs
+	robotCursor                string // opaque pagination cursor for robot list outputs
+	robotIfChanged             string // etag from a previous robot output (--if-changed)
+	robotHumanize              bool   // add humanized time fields to robot output (--humanize)
 	robotDashboard             bool   // dashboard summary output
 	robotContext               string // session name for context usage
 	robotEnsemble              string // session name for ensemble state
@@ -2435,6 +2579,8 @@ var (
 	robotRestartPane       string // session name for pane restart
 	robotRestartPaneBead   string // bead ID to assign after restart
 	robotRestartPanePrompt string // custom prompt to send after restart
+	robotConfirmToken      string // token confirming a destructive robot flag
+	robotIdempotencyKey    string // key under which a mutating robot flag's result is recorded
 
 	// Robot-probe flags for active pane responsiveness testing (bd-1cu1f)
 	robotProbe           string // session name to probe
@@ -2638,6 +2784,10 @@ func init() {
 	rootCmd.Flags().IntVar(&robotBeadLimit, "bead-limit", 5, "Max beads per category in snapshot. Optional with --robot-snapshot, --robot-status. Example: --bead-limit=10")
 	rootCmd.Flags().IntVar(&robotLimit, "robot-limit", 0, "Max items to return for robot list outputs (status, snapshot, history). Example: --robot-limit=10")
 	rootCmd.Flags().IntVar(&robotOffset, "robot-offset", 0, "Pagination offset for robot list outputs (status, snapshot, history). Example: --robot-offset=20")
+	rootCmd.Flags().StringVar(&robotCursor, "robot-cursor", "", "Resume a robot list output (status, snapshot, history) after the next_page_cursor of the previous page. Stable when items are added or removed. Example: --robot-limit=10 --robot-cursor=WyJteXByb2oiXQ")
+	rootCmd.Flags().StringVar(&robotIfChanged, "if-changed", "", "Add an etag content hash to robot output; when it equals the given etag, print only {\"not_modified\":true}. Pass without a value on the first poll. Example: ntm --robot-status --if-changed=3f2a...")
+	rootCmd.Flags().Lookup("if-changed").NoOptDefVal = " "
+	rootCmd.Flags().BoolVar(&robotHumanize, "humanize", false, "Add \"_human\" siblings to robot time fields (\"3m ago\", \"3m 12s\"); machine fields stay UTC. Env: NTM_ROBOT_HUMANIZE")
 	rootCmd.Flags().StringVar(&robotVerbosity, "robot-verbosity", "", "Robot verbosity profile for JSON/TOON: terse, default, or debug. Env: NTM_ROBOT_VERBOSITY")
 
 	// BV Analysis robot flags for advanced analysis modes
@@ -2766,6 +2916,8 @@ func init() {
 	rootCmd.Flags().StringVar(&robotRestartPane, "robot-restart-pane", "", "Restart pane process (kill and respawn). Required: SESSION. Example: ntm --robot-restart-pane=proj --panes=1,2")
 	rootCmd.Flags().StringVar(&robotRestartPaneBead, "restart-bead", "", "Assign bead to agent after restart. Fetches info via br show --json, sends prompt. Use with --robot-restart-pane. Example: --restart-bead=bd-abc12")
 	rootCmd.Flags().StringVar(&robotRestartPanePrompt, "restart-prompt", "", "Custom prompt to send after restart. Overrides --restart-bead template. Use with --robot-restart-pane")
+	rootCmd.Flags().StringVar(&robotConfirmToken, "confirm-token", "", "Token confirming a destructive robot command (restart, interrupt, ensemble stop), as returned by the unconfirmed call. See [robot.confirm] in config")
+	rootCmd.Flags().StringVar(&robotIdempotencyKey, "idempotency-key", "", "Record the result of a mutating robot command under this key for 24h; retrying with the same key returns the original result instead of running again")
 	rootCmd.Flags().StringVar(&robotProbe, "robot-probe", "", "Probe pane responsiveness. Required: SESSION. Example: ntm --robot-probe=proj --panes=1,2")
 	rootCmd.Flags().StringVar(&robotProbeMethod, "probe-method", "", "Probe method: keystroke_echo, interrupt_test (used with --robot-probe)")
 	rootCmd.Flags().IntVar(&robotProbeTimeout, "probe-timeout", 0, "Probe timeout in ms (100-60000, used with --robot-probe)")
@@ -3288,8 +3440,11 @@ func init() {
 	// Bead prefixed flags → canonical forms (for filters on bead operations)
 	rootCmd.Flags().MarkDeprecated("bead-limit", "use --limit instead")
 
+	registerRobotFlagCompletions(rootCmd)
+
 	// Sync version info with robot package
 	robot.Version = Version
+	crashbundle.NTMVersion = Version
 	robot.Commit = Commit
 	robot.Date = Date
 	robot.BuiltBy = BuiltBy
@@ -3312,6 +3467,8 @@ func init() {
 		newRotateCmd(),
 		newQuotaCmd(),
 		newPipelineCmd(),
+		newBatchCmd(),
+		newScheduleCmd(),
 		newWaitCmd(),
 		newMailCmd(),
 		newPluginsCmd(),
@@ -3344,6 +3501,8 @@ func init() {
 		newChangesCmd(),
 		newConflictsCmd(),
 		newSummaryCmd(),
+		newArchiveCmd(),
+		newStorageCmd(),
 		newLogsCmd(),
 
 		// Session persistence
@@ -3376,11 +3535,24 @@ func init() {
 		newOpenAPICmd(),
 		newGuardsCmd(),
 		newApproveCmd(),
+		newBudgetCmd(),
+		newSimulateCmd(),
+		newFixtureCmd(),
+		newFlakyCmd(),
+		newTakeoverCmd(),
 		newServeCmd(),
+		newDaemonCmd(),
+		newShareCmd(),
 		newSetupCmd(),
 		newActivityCmd(),
 		newHistoryCmd(),
+		newEventsCmd(),
+		newTimelineCmd(),
+		newFsckCmd(),
+		newPurgeCmd(),
+		newRobotCmd(),
 		newAnalyticsCmd(),
+		newSQLCmd(),
 		newMetricsCmd(),
 		newWorkCmd(),
 		newEnsembleCmd(),
@@ -3413,6 +3585,7 @@ func init() {
 		// Agent Mail & File Reservations
 		newLockCmd(),
 		newUnlockCmd(),
+		newReserveCmd(),
 		newLocksCmd(),
 		newMessageCmd(),     // Unified messaging
 		newCoordinatorCmd(), // Multi-agent coordination
@@ -4057,6 +4230,14 @@ func printDefaultPrompts() error {
 }
 
 func resolveRobotFormat(cfg *config.Config) {
+	robot.OutputTOONOptions = robot.TOONOptions{}
+	if cfg != nil {
+		robot.OutputTOONOptions = robot.TOONOptions{
+			Align:         cfg.Robot.Output.TOONAlign,
+			Abbreviations: cfg.Robot.Output.TOONAbbreviations,
+		}
+	}
+
 	formatStr := robotFormat
 
 	// Fall back to environment variable if flag not set
@@ -4118,6 +4299,18 @@ func resolveRobotVerbosity(cfg *config.Config) {
 	robot.OutputVerbosity = verbosity
 }
 
+// resolveRobotHumanize sets robot.Humanize from --humanize or NTM_ROBOT_HUMANIZE.
+func resolveRobotHumanize() {
+	if robotHumanize {
+		robot.Humanize = true
+		return
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("NTM_ROBOT_HUMANIZE"))) {
+	case "1", "true", "yes", "on":
+		robot.Humanize = true
+	}
+}
+
 // applyRedactionFlagOverrides applies CLI flag overrides to the redaction config.
 // Priority: --allow-secret > --redact > config > default
 func applyRedactionFlagOverrides(cfg *config.Config) {
@@ -141,6 +141,9 @@ func (r *RotationHistoryResult) Text(w io.Writer) error {
 				resultStr += " " + truncateStr(rec.FailureReason, 20)
 			}
 		}
+		if rec.Method == ctxmon.RotationContextReset {
+			resultStr += fmt.Sprintf(" %sreset:%s%s", colorize(t.Overlay), rec.CompactionMethod, colorize(t.Text))
+		}
 
 		fmt.Fprintf(w, " %-14s %-13s %-10s %7s  %s\n",
 			timeStr,
@@ -251,8 +251,9 @@ type CheckResponse struct {
 	Pattern string `json:"pattern,omitempty"`
 	Reason  string `json:"reason,omitempty"`
 
-	Policy *CheckPolicyVerdict `json:"policy,omitempty"`
-	DCG    *CheckDCGVerdict    `json:"dcg,omitempty"`
+	Policy  *CheckPolicyVerdict  `json:"policy,omitempty"`
+	DCG     *CheckDCGVerdict     `json:"dcg,omitempty"`
+	Plugins []CheckPluginVerdict `json:"plugins,omitempty"` // WASM check plugins
 }
 
 type CheckPolicyVerdict struct {
@@ -324,6 +325,11 @@ func runSafetyCheck(command string) error {
 			if resp.Policy != nil && resp.Pattern == "dcg" {
 				fmt.Printf("    %s\n", mutedStyle.Render("Policy: "+resp.Policy.Action+" (pattern: "+resp.Policy.Pattern+")"))
 			}
+			for _, v := range resp.Plugins {
+				if v.Error != "" {
+					fmt.Printf("    %s\n", mutedStyle.Render("Plugin "+v.Plugin+" error: "+v.Error))
+				}
+			}
 		}
 
 		fmt.Println()
@@ -396,6 +402,15 @@ func evaluateSafetyCheck(command string) (CheckResponse, int, error) {
 		resp.DCG = dcg
 	}
 
+	// WASM check plugins may tighten the verdict further.
+	if resp.Action != string(policy.ActionBlock) {
+		ctx := context.Background()
+		if rt, loaded := loadWasmPlugins(ctx); rt != nil {
+			applyWasmChecks(ctx, loaded, &resp)
+			rt.Close(ctx)
+		}
+	}
+
 	exitCode := 0
 	if resp.Action == string(policy.ActionBlock) {
 		exitCode = 1
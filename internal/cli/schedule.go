@@ -0,0 +1,678 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/batch"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cron"
+	"github.com/Dicklesworthstone/ntm/internal/daemon"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/githost"
+	"github.com/Dicklesworthstone/ntm/internal/notify"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/pipeline"
+	"github.com/Dicklesworthstone/ntm/internal/templates"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// scheduleInterval is how often the daemon checks for due schedules.
+const scheduleInterval = 30 * time.Second
+
+// scheduleHistory returns the run history; tests replace it.
+var scheduleHistory = func() *cron.History { return cron.NewHistory("") }
+
+// ScheduleStatus is one schedule in the JSON output of schedule list.
+type ScheduleStatus struct {
+	Name       string     `json:"name"`
+	Cron       string     `json:"cron"`
+	Timezone   string     `json:"timezone,omitempty"`
+	Template   string     `json:"template"`
+	TasksFrom  string     `json:"tasks_from"`
+	ProjectDir string     `json:"project_dir"`
+	Disabled   bool       `json:"disabled,omitempty"`
+	NextRun    *time.Time `json:"next_run,omitempty"`
+	LastRun    *cron.Run  `json:"last_run,omitempty"`
+}
+
+// ScheduleListResult is the JSON output of schedule list.
+type ScheduleListResult struct {
+	Schedules []ScheduleStatus `json:"schedules"`
+}
+
+// ScheduleHistoryResult is the JSON output of schedule history.
+type ScheduleHistoryResult struct {
+	Runs []cron.Run `json:"runs"`
+}
+
+func newScheduleCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Run fleets on a recurring cron schedule",
+		Long: `Start fleet runs on a cron schedule, e.g. nightly issue triage.
+
+Each run spawns the agents of a session template, turns the open issues
+matching the task source into tasks, runs them to completion as 'ntm batch'
+does, and tears the session down. Schedules are stored as [[schedules]] in
+the config file; 'ntm daemon' starts the runs, records them in the run
+history, and sends a schedule.failed notification when one fails.
+
+Task sources:
+  github:label=triage               Open issues of the origin repository
+  github:label=bug,label=p1,limit=5 Issues with every label, at most 5
+  gitlab:repo=group/app,label=ops   Issues of another project
+
+Examples:
+  ntm schedule add nightly-triage --cron "0 2 * * *" --template triage --tasks-from github:label=triage
+  ntm schedule list
+  ntm schedule run nightly-triage
+  ntm schedule history nightly-triage
+  ntm schedule remove nightly-triage`,
+	}
+
+	cmd.AddCommand(
+		newScheduleAddCmd(),
+		newScheduleListCmd(),
+		newScheduleRemoveCmd(),
+		newScheduleRunCmd(),
+		newScheduleHistoryCmd(),
+	)
+	return cmd
+}
+
+func newScheduleAddCmd() *cobra.Command {
+	var sc config.ScheduleConfig
+
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a schedule to the config file",
+		Long: `Add a schedule to the config file. --cron takes five fields (minute hour
+day-of-month month day-of-week) or @hourly, @daily, @weekly, @monthly,
+@yearly, evaluated in --timezone (default local time).
+
+Examples:
+  ntm schedule add nightly-triage --cron "0 2 * * *" --template triage --tasks-from github:label=triage
+  ntm schedule add weekly-deps --cron "30 6 * * mon" --timezone Europe/Berlin \
+    --template maintenance --tasks-from github:label=dependencies,limit=10 --timeout 2h`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc.Name = args[0]
+			return runScheduleAdd(sc)
+		},
+	}
+
+	cmd.Flags().StringVar(&sc.Cron, "cron", "", "Cron expression (required)")
+	cmd.Flags().StringVar(&sc.Template, "template", "", "Session template naming the agents (required)")
+	cmd.Flags().StringVar(&sc.TasksFrom, "tasks-from", "", "Task source, e.g. github:label=triage (required)")
+	cmd.Flags().StringVar(&sc.Timezone, "timezone", "", "IANA time zone for --cron (default local)")
+	cmd.Flags().StringVar(&sc.ProjectDir, "project", "", "Repository the fleet works in (default current directory)")
+	cmd.Flags().StringVar(&sc.Timeout, "timeout", "", "Bound on each run (default template maxRuntime, else 30m)")
+	_ = cmd.MarkFlagRequired("cron")
+	_ = cmd.MarkFlagRequired("template")
+	_ = cmd.MarkFlagRequired("tasks-from")
+	return cmd
+}
+
+func runScheduleAdd(sc config.ScheduleConfig) error {
+	if sc.ProjectDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		sc.ProjectDir = wd
+	}
+	dir, err := filepath.Abs(sc.ProjectDir)
+	if err != nil {
+		return err
+	}
+	sc.ProjectDir = dir
+	if err := config.ValidateScheduleConfig(&sc); err != nil {
+		return err
+	}
+	if _, err := templates.NewSessionTemplateLoaderWithProject(sc.ProjectDir).Load(sc.Template); err != nil {
+		return fmt.Errorf("template %q: %w", sc.Template, err)
+	}
+	if err := config.AddSchedule(cfgFile, sc); err != nil {
+		return err
+	}
+	_ = audit.LogEvent("", audit.EventTypeCommand, audit.ActorUser, "schedule.add", map[string]interface{}{
+		"schedule":   sc.Name,
+		"cron":       sc.Cron,
+		"template":   sc.Template,
+		"tasks_from": sc.TasksFrom,
+	}, nil)
+
+	next, _ := scheduleNext(sc, time.Now())
+	if IsJSONOutput() {
+		return output.PrintJSON(scheduleStatus(sc, next, nil))
+	}
+	output.SuccessCheck(fmt.Sprintf("Added schedule %s", sc.Name))
+	if !next.IsZero() {
+		fmt.Printf("Next run: %s (runs start while 'ntm daemon' is running)\n", next.Format("2006-01-02 15:04 MST"))
+	}
+	return nil
+}
+
+func newScheduleListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List schedules with their next and last run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScheduleList()
+		},
+	}
+}
+
+func runScheduleList() error {
+	schedules, err := loadSchedules()
+	if err != nil {
+		return err
+	}
+	hist := scheduleHistory()
+	now := time.Now()
+	result := ScheduleListResult{Schedules: []ScheduleStatus{}}
+	for _, sc := range schedules {
+		var next time.Time
+		if !sc.Disabled {
+			next, _ = scheduleNext(sc, now)
+		}
+		last, err := hist.Last(sc.Name)
+		if err != nil {
+			return err
+		}
+		result.Schedules = append(result.Schedules, scheduleStatus(sc, next, last))
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(result)
+	}
+	if len(result.Schedules) == 0 {
+		fmt.Println("No schedules (add one with 'ntm schedule add').")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tCron\tTemplate\tTasks From\tNext Run\tLast Run")
+	fmt.Fprintln(w, "────\t────\t────────\t──────────\t────────\t────────")
+	for _, s := range result.Schedules {
+		next := "-"
+		if s.Disabled {
+			next = "disabled"
+		} else if s.NextRun != nil {
+			next = s.NextRun.Format("2006-01-02 15:04 MST")
+		}
+		last := "-"
+		if s.LastRun != nil {
+			last = fmt.Sprintf("%s (%s)", s.LastRun.StartedAt.Local().Format("2006-01-02 15:04"), s.LastRun.Status)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, s.Cron, s.Template, s.TasksFrom, next, last)
+	}
+	return w.Flush()
+}
+
+func newScheduleRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a schedule from the config file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			removed, err := config.RemoveSchedule(cfgFile, args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("schedule %q not found", args[0])
+			}
+			_ = audit.LogEvent("", audit.EventTypeCommand, audit.ActorUser, "schedule.remove", map[string]interface{}{
+				"schedule": args[0],
+			}, nil)
+			if IsJSONOutput() {
+				return output.PrintJSON(map[string]interface{}{"removed": args[0]})
+			}
+			output.SuccessCheck(fmt.Sprintf("Removed schedule %s", args[0]))
+			return nil
+		},
+	}
+}
+
+func newScheduleRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run a schedule now, in the foreground",
+		Long: `Run a schedule now, in the foreground, as the daemon would when it is
+due. The run is recorded in the history like a scheduled one.
+
+Examples:
+  ntm schedule run nightly-triage`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schedules, err := loadSchedules()
+			if err != nil {
+				return err
+			}
+			for _, sc := range schedules {
+				if sc.Name == args[0] {
+					return runScheduleNow(cmd.Context(), sc)
+				}
+			}
+			return fmt.Errorf("schedule %q not found", args[0])
+		},
+	}
+}
+
+func runScheduleNow(ctx context.Context, sc config.ScheduleConfig) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logw := os.Stdout
+	if IsJSONOutput() {
+		logw = os.Stderr
+	}
+	logf := func(format string, args ...any) {
+		fmt.Fprintf(logw, "[schedule] "+format+"\n", args...)
+	}
+	run, err := runSchedule(ctx, sc, time.Now(), logf)
+	if err != nil {
+		return err
+	}
+
+	if IsJSONOutput() {
+		if err := output.PrintJSON(run); err != nil {
+			return err
+		}
+	}
+	switch run.Status {
+	case cron.RunFailed:
+		return fmt.Errorf("schedule %s failed: %s", sc.Name, run.Error)
+	case cron.RunSkipped:
+		if !IsJSONOutput() {
+			fmt.Printf("Skipped: %s\n", run.Error)
+		}
+	default:
+		if !IsJSONOutput() {
+			output.SuccessCheck(fmt.Sprintf("Schedule %s completed %d tasks (artifacts in %s)", sc.Name, run.Tasks, run.ArtifactsDir))
+		}
+	}
+	return nil
+}
+
+func newScheduleHistoryCmd() *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "history [name]",
+		Short: "Show past runs of one or all schedules",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) == 1 {
+				name = args[0]
+			}
+			return runScheduleHistory(name, limit)
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum runs to show (0 = all)")
+	return cmd
+}
+
+func runScheduleHistory(name string, limit int) error {
+	runs, err := scheduleHistory().Runs(name, limit)
+	if err != nil {
+		return err
+	}
+	if IsJSONOutput() {
+		if runs == nil {
+			runs = []cron.Run{}
+		}
+		return output.PrintJSON(ScheduleHistoryResult{Runs: runs})
+	}
+	if len(runs) == 0 {
+		fmt.Println("No scheduled runs yet.")
+		return nil
+	}
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Run\tStatus\tStarted\tDuration\tTasks\tNote")
+	fmt.Fprintln(w, "───\t──────\t───────\t────────\t─────\t────")
+	for _, r := range runs {
+		note := r.Error
+		if note == "" {
+			note = r.ArtifactsDir
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", r.ID, r.Status,
+			r.StartedAt.Local().Format("2006-01-02 15:04"), r.Duration(now).Round(time.Second), r.Tasks, note)
+	}
+	return w.Flush()
+}
+
+// loadSchedules reads the schedules from the config file. It reloads the
+// file so the daemon sees schedules added since it started.
+func loadSchedules() ([]config.ScheduleConfig, error) {
+	loaded, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	return loaded.Schedules, nil
+}
+
+// scheduleNext returns the first time after now that sc is due, or zero.
+func scheduleNext(sc config.ScheduleConfig, now time.Time) (time.Time, error) {
+	expr, err := sc.Expr()
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := sc.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return expr.Next(now.In(loc)), nil
+}
+
+func scheduleStatus(sc config.ScheduleConfig, next time.Time, last *cron.Run) ScheduleStatus {
+	s := ScheduleStatus{
+		Name:       sc.Name,
+		Cron:       sc.Cron,
+		Timezone:   sc.Timezone,
+		Template:   sc.Template,
+		TasksFrom:  sc.TasksFrom,
+		ProjectDir: sc.ProjectDir,
+		Disabled:   sc.Disabled,
+		LastRun:    last,
+	}
+	if !next.IsZero() {
+		s.NextRun = &next
+	}
+	return s
+}
+
+// runSchedule runs sc once and records the run. The returned error covers
+// only bookkeeping; a run that fails is reported in its status.
+func runSchedule(ctx context.Context, sc config.ScheduleConfig, scheduledFor time.Time, logf func(format string, args ...any)) (*cron.Run, error) {
+	hist := scheduleHistory()
+	started := time.Now()
+	run := &cron.Run{
+		ID:           sc.Name + "-" + started.UTC().Format("20060102T150405Z"),
+		Schedule:     sc.Name,
+		ScheduledFor: scheduledFor,
+		StartedAt:    started,
+		Status:       cron.RunRunning,
+	}
+	if err := hist.Record(*run); err != nil {
+		return nil, err
+	}
+
+	report, err := executeSchedule(ctx, sc, run, logf)
+	finished := time.Now()
+	run.FinishedAt = &finished
+	switch {
+	case err != nil:
+		run.Status = cron.RunFailed
+		run.Error = err.Error()
+	case report == nil:
+		run.Status = cron.RunSkipped
+		run.Error = "no open issues match " + sc.TasksFrom
+	case report.Failed():
+		run.Status = cron.RunFailed
+		run.Error = fmt.Sprintf("%d of %d phases failed (%v)", report.Summary.Failed, report.Summary.Total, report.FailedPhases())
+	default:
+		run.Status = cron.RunSucceeded
+	}
+	recordErr := hist.Record(*run)
+	reportScheduleRun(run)
+	return run, recordErr
+}
+
+// executeSchedule runs sc's fleet over the issues its task source yields.
+// It returns a nil report when there is nothing to do.
+func executeSchedule(ctx context.Context, sc config.ScheduleConfig, run *cron.Run, logf func(format string, args ...any)) (*batch.Report, error) {
+	src, err := githost.ParseIssueSource(sc.TasksFrom)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := templates.NewSessionTemplateLoaderWithProject(sc.ProjectDir).Load(sc.Template)
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %w", sc.Template, err)
+	}
+	provider, err := scheduleIssueProvider(sc.ProjectDir, src)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := provider.ListIssues(ctx, src.Query)
+	if err != nil {
+		return nil, fmt.Errorf("list issues: %w", err)
+	}
+	run.Tasks = len(issues)
+	if len(issues) == 0 {
+		logf("no open issues match %s", sc.TasksFrom)
+		return nil, nil
+	}
+	if err := tmux.EnsureInstalled(); err != nil {
+		return nil, err
+	}
+
+	plan := batch.IssuePlan(sc.Name, templateAgentCounts(tmpl), issues)
+	if d, _ := sc.RunTimeout(); d > 0 {
+		plan.Timeout.Duration = d
+	} else if b := tmpl.Spec.Options.Budget; b != nil && b.MaxRuntime != "" {
+		if d, err := time.ParseDuration(b.MaxRuntime); err == nil && d > 0 {
+			plan.Timeout.Duration = d
+		}
+	}
+	plan.Artifacts.Dir = filepath.Join(sc.ProjectDir, ".ntm", "schedules", sc.Name, run.ID)
+	run.Session = plan.Session
+	run.ArtifactsDir = plan.Artifacts.Dir
+
+	logf("running %d issues from %s with template %s", len(issues), sc.TasksFrom, sc.Template)
+	return batch.Run(ctx, plan, batchHooks(sc.ProjectDir, logf, func(ev pipeline.ProgressEvent) {
+		logf("%s %s %s", ev.Type, ev.StepID, ev.Message)
+	}))
+}
+
+// scheduleIssueProvider returns the git host API for src: its repo if set,
+// else the origin remote of projectDir.
+func scheduleIssueProvider(projectDir string, src githost.IssueSource) (githost.Provider, error) {
+	var remote githost.Remote
+	if src.Repo != "" {
+		host := "github.com"
+		if src.Kind == githost.GitLab {
+			host = "gitlab.com"
+		}
+		remote = githost.Remote{Kind: src.Kind, Host: host, Path: src.Repo}
+	} else {
+		remoteURL, err := prGit(projectDir, "remote", "get-url", "origin")
+		if err != nil {
+			return nil, fmt.Errorf("remote origin: %w", err)
+		}
+		if remote, err = githost.ParseRemote(remoteURL); err != nil {
+			return nil, err
+		}
+		remote.Kind = src.Kind
+	}
+	token := githost.TokenFromEnv(remote.Kind)
+	if token == "" {
+		return nil, fmt.Errorf("no API token for %s: set GITHUB_TOKEN (or GH_TOKEN) or GITLAB_TOKEN", remote.Host)
+	}
+	return githost.New(remote, token, "")
+}
+
+// templateAgentCounts returns the agents tmpl spawns.
+func templateAgentCounts(tmpl *templates.SessionTemplate) batch.AgentCounts {
+	var counts batch.AgentCounts
+	if a := tmpl.Spec.Agents.Claude; a != nil {
+		counts.Claude = a.TotalCount()
+	}
+	if a := tmpl.Spec.Agents.Codex; a != nil {
+		counts.Codex = a.TotalCount()
+	}
+	if a := tmpl.Spec.Agents.Gemini; a != nil {
+		counts.Gemini = a.TotalCount()
+	}
+	return counts
+}
+
+// reportScheduleRun audits a finished run, emits it, and notifies failures.
+func reportScheduleRun(run *cron.Run) {
+	data := map[string]interface{}{
+		"schedule": run.Schedule,
+		"run_id":   run.ID,
+		"status":   string(run.Status),
+		"tasks":    run.Tasks,
+	}
+	if run.Error != "" {
+		data["error"] = run.Error
+	}
+	if run.ArtifactsDir != "" {
+		data["artifacts_dir"] = run.ArtifactsDir
+	}
+	_ = audit.LogEvent(run.Session, audit.EventTypeStateChange, audit.ActorSystem, "schedule", data, nil)
+	events.Emit(events.EventScheduleRun, run.Session, data)
+
+	if run.Status != cron.RunFailed || cfg == nil || !cfg.Notifications.Enabled {
+		return
+	}
+	notifier := notify.NewWithRedaction(cfg.Notifications, cfg.Redaction.ToRedactionLibConfig())
+	if err := notifier.Notify(notify.NewScheduleFailedEvent(run.Schedule, run.ID, run.Session, run.Error)); err != nil {
+		slog.Warn("schedule notification failed", "schedule", run.Schedule, "error", err)
+	}
+}
+
+// scheduleRunner starts schedules when they are due. Runs missed while the
+// daemon was down are not made up, and a run that comes due while the
+// previous one is still going is recorded as skipped.
+type scheduleRunner struct {
+	// start runs a due schedule; it is called on its own goroutine.
+	start func(ctx context.Context, sc config.ScheduleConfig, scheduledFor time.Time)
+	load  func() ([]config.ScheduleConfig, error)
+
+	mu      sync.Mutex
+	next    map[string]time.Time
+	spec    map[string]string // Cron and timezone next was computed from
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+func newScheduleRunner(start func(ctx context.Context, sc config.ScheduleConfig, scheduledFor time.Time)) *scheduleRunner {
+	return &scheduleRunner{
+		start:   start,
+		load:    loadSchedules,
+		next:    make(map[string]time.Time),
+		spec:    make(map[string]string),
+		running: make(map[string]bool),
+	}
+}
+
+// tick starts the schedules due at now.
+func (r *scheduleRunner) tick(ctx context.Context, now time.Time) error {
+	schedules, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	seen := make(map[string]bool, len(schedules))
+	for _, sc := range schedules {
+		if sc.Disabled {
+			continue
+		}
+		seen[sc.Name] = true
+		key := sc.Cron + "|" + sc.Timezone
+		due, known := r.next[sc.Name]
+		if !known || r.spec[sc.Name] != key {
+			next, err := scheduleNext(sc, now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("schedule %s: %w", sc.Name, err))
+				continue
+			}
+			r.next[sc.Name], r.spec[sc.Name] = next, key
+			continue
+		}
+		if due.IsZero() || now.Before(due) {
+			continue
+		}
+		r.next[sc.Name], _ = scheduleNext(sc, now)
+
+		if r.running[sc.Name] {
+			finished := now
+			skipped := cron.Run{
+				ID:           sc.Name + "-" + now.UTC().Format("20060102T150405Z"),
+				Schedule:     sc.Name,
+				ScheduledFor: due,
+				StartedAt:    now,
+				FinishedAt:   &finished,
+				Status:       cron.RunSkipped,
+				Error:        "previous run still in progress",
+			}
+			if err := scheduleHistory().Record(skipped); err != nil {
+				errs = append(errs, err)
+			}
+			reportScheduleRun(&skipped)
+			continue
+		}
+
+		r.running[sc.Name] = true
+		r.wg.Add(1)
+		go func(sc config.ScheduleConfig, due time.Time) {
+			defer r.wg.Done()
+			r.start(ctx, sc, due)
+			r.mu.Lock()
+			delete(r.running, sc.Name)
+			r.mu.Unlock()
+		}(sc, due)
+	}
+
+	// Forget schedules removed or disabled since the last tick.
+	for name := range r.next {
+		if !seen[name] {
+			delete(r.next, name)
+			delete(r.spec, name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// wait blocks until the runs started so far have finished.
+func (r *scheduleRunner) wait() {
+	r.wg.Wait()
+}
+
+// runDaemonSchedules starts [[schedules]] runs as they come due.
+func runDaemonSchedules(ctx context.Context, rep *daemon.Reporter) error {
+	r := newScheduleRunner(func(ctx context.Context, sc config.ScheduleConfig, scheduledFor time.Time) {
+		logf := func(format string, args ...any) {
+			slog.Debug(fmt.Sprintf(format, args...), "schedule", sc.Name)
+		}
+		if _, err := runSchedule(ctx, sc, scheduledFor, logf); err != nil {
+			rep.Error(fmt.Errorf("schedule %s: %w", sc.Name, err))
+		}
+	})
+	// A cancelled run still collects artifacts and tears its session down.
+	defer r.wait()
+
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.tick(ctx, time.Now()); err != nil {
+			rep.Error(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cron"
+)
+
+func TestScheduleRunner_Tick(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	hist := cron.NewHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	oldHistory := scheduleHistory
+	defer func() { scheduleHistory = oldHistory }()
+	scheduleHistory = func() *cron.History { return hist }
+
+	started := make(chan time.Time, 4)
+	release := make(chan struct{})
+	r := newScheduleRunner(func(ctx context.Context, sc config.ScheduleConfig, scheduledFor time.Time) {
+		started <- scheduledFor
+		<-release
+	})
+	r.load = func() ([]config.ScheduleConfig, error) {
+		return []config.ScheduleConfig{
+			{Name: "hourly", Cron: "0 * * * *", Timezone: "UTC"},
+			{Name: "off", Cron: "* * * * *", Timezone: "UTC", Disabled: true},
+		}, nil
+	}
+
+	ctx := context.Background()
+	base := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	// The first tick only schedules; a run missed before it is not made up.
+	if err := r.tick(ctx, base); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.tick(ctx, base.Add(20*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 0 {
+		t.Fatalf("started %d runs before the schedule was due", len(started))
+	}
+
+	if err := r.tick(ctx, base.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case at := <-started:
+		if want := base.Add(30 * time.Minute); !at.Equal(want) {
+			t.Errorf("scheduledFor = %v, want %v", at, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("due schedule was not started")
+	}
+
+	// Due again while the first run is still going: recorded as skipped.
+	if err := r.tick(ctx, base.Add(90*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	r.wait()
+	if len(started) != 0 {
+		t.Errorf("overlapping run was started")
+	}
+	last, err := hist.Last("hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last == nil || last.Status != cron.RunSkipped {
+		t.Fatalf("last run = %+v, want a skipped run", last)
+	}
+	if runs, _ := hist.Runs("off", 0); len(runs) != 0 {
+		t.Errorf("disabled schedule has %d runs", len(runs))
+	}
+}
@@ -37,6 +37,7 @@ import (
 	sessionPkg "github.com/Dicklesworthstone/ntm/internal/session"
 	"github.com/Dicklesworthstone/ntm/internal/state"
 	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/takeover"
 	"github.com/Dicklesworthstone/ntm/internal/templates"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/tools"
@@ -239,6 +240,15 @@ type SendOptions struct {
 	// Hooks
 	NoHooks bool
 
+	// Delivery verification (nil Delivery = [send] config defaults)
+	Delivery         *tmux.DeliveryOptions
+	NoVerifyDelivery bool
+	FileHandoff      bool // Always hand the prompt to agents through a file
+
+	// OverrideGuards sends even when a guard inherited from the session
+	// template refuses the prompt. Overrides are audited.
+	OverrideGuards bool
+
 	// Batch processing options
 	BatchFile       string        // Path to batch file
 	BatchDelay      time.Duration // Delay between prompts
@@ -460,6 +470,10 @@ func newSendCmd() *cobra.Command {
 	var paneIndex int
 	var panesArg string
 	var promptFile, prefix, suffix string
+	var noVerifyDelivery bool
+	var fileHandoff bool
+	var overrideGuards bool
+	var deliveryRetries int
 	var contextFiles []string
 	var templateName string
 	var templateVars []string
@@ -528,6 +542,27 @@ func newSendCmd() *cobra.Command {
 		Use --route to specify the strategy (default: least-loaded).
 		Strategies: least-loaded, round-robin, affinity, sticky, random.
 
+		Delivery Verification:
+		After each send, the agent pane is captured and compared with a capture taken
+		just before it. If the prompt's opening text never appears (the pane was
+		mid-redraw or sitting at a confirmation prompt), it is resent with backoff up
+		to --delivery-retries times; if it still cannot be confirmed, a
+		prompt_undelivered event is raised and the pane counts as failed. Long prompts
+		are also checked for truncation against the echoed text or the paste
+		placeholder's reported size; a truncated prompt is reported, not resent.
+		Use --no-verify-delivery or [send] verify_delivery = false to disable.
+
+		File Handoff:
+		Prompts larger than [send] file_handoff_over bytes (default 50000), or any
+		prompt with --file-handoff, are written to an owner-only file under
+		[send] file_handoff_dir and agents are sent a short instruction to read it
+		(per-agent wording via [send.profiles.<agent>] file_handoff). The file path
+		is recorded in the audit log; files are removed after file_handoff_keep_hours.
+
+		Long prompts are typed in chunks ([send] paste_chunk_size bytes, with
+		paste_chunk_delay_ms between them) and multi-line prompts use bracketed
+		paste ([send] bracketed_paste) so agent TUIs receive them intact.
+
 		Examples:
 		  ntm send myproject "fix the linting errors"           # All agents
 		  ntm send myproject --cc "review the changes"          # All Claude agents
@@ -578,6 +613,16 @@ func newSendCmd() *cobra.Command {
 				return err
 			}
 
+			var delivery *tmux.DeliveryOptions
+			if cmd.Flags().Changed("delivery-retries") {
+				if deliveryRetries < 0 {
+					return fmt.Errorf("--delivery-retries must be >= 0")
+				}
+				d := tmux.DefaultDeliveryOptions()
+				d.Retries = deliveryRetries
+				delivery = &d
+			}
+
 			// Handle --distribute mode: auto-distribute work from bv triage
 			if distribute {
 				if dryRun && distributeAuto {
@@ -597,29 +642,33 @@ func newSendCmd() *cobra.Command {
 					}
 				}
 				batchOpts := SendOptions{
-					Session:         session,
-					BasePrompt:      resolvedBasePrompt,
-					Targets:         targets,
-					TargetAll:       targetAll,
-					SkipFirst:       skipFirst,
-					PaneIndex:       paneIndex,
-					Tags:            tags,
-					SmartRoute:      smartRoute,
-					RouteStrategy:   routeStrategy,
-					CassCheck:       cassCheck && !noCassCheck,
-					CassSimilarity:  cassSimilarity,
-					CassCheckDays:   cassCheckDays,
-					NoHooks:         noHooks,
-					DryRun:          dryRun,
-					BatchFile:       batchFile,
-					BatchDelay:      delay,
-					BatchConfirm:    batchConfirm,
-					BatchStopOnErr:  batchStopOnErr,
-					BatchBroadcast:  batchBroadcast,
-					BatchAgentIndex: batchAgentIndex,
-					Randomize:       randomize,
-					Seed:            seed,
-					PriorityOrder:   priorityOrder,
+					Session:          session,
+					BasePrompt:       resolvedBasePrompt,
+					Targets:          targets,
+					TargetAll:        targetAll,
+					SkipFirst:        skipFirst,
+					PaneIndex:        paneIndex,
+					Tags:             tags,
+					SmartRoute:       smartRoute,
+					RouteStrategy:    routeStrategy,
+					CassCheck:        cassCheck && !noCassCheck,
+					CassSimilarity:   cassSimilarity,
+					CassCheckDays:    cassCheckDays,
+					NoHooks:          noHooks,
+					DryRun:           dryRun,
+					BatchFile:        batchFile,
+					BatchDelay:       delay,
+					BatchConfirm:     batchConfirm,
+					BatchStopOnErr:   batchStopOnErr,
+					BatchBroadcast:   batchBroadcast,
+					BatchAgentIndex:  batchAgentIndex,
+					Randomize:        randomize,
+					Seed:             seed,
+					PriorityOrder:    priorityOrder,
+					Delivery:         delivery,
+					NoVerifyDelivery: noVerifyDelivery,
+					FileHandoff:      fileHandoff,
+					OverrideGuards:   overrideGuards,
 				}
 				return runSendBatch(batchOpts)
 			}
@@ -638,24 +687,28 @@ func newSendCmd() *cobra.Command {
 			}
 
 			opts := SendOptions{
-				Session:        session,
-				BasePrompt:     resolvedBasePrompt,
-				Targets:        targets,
-				TargetAll:      targetAll,
-				SkipFirst:      skipFirst,
-				PaneIndex:      paneIndex,
-				Panes:          panes,
-				PanesSpecified: panesSpecified,
-				Tags:           tags,
-				SmartRoute:     smartRoute,
-				RouteStrategy:  routeStrategy,
-				CassCheck:      cassCheck && !noCassCheck,
-				CassSimilarity: cassSimilarity,
-				CassCheckDays:  cassCheckDays,
-				NoHooks:        noHooks,
-				DryRun:         dryRun,
-				Randomize:      randomize,
-				Seed:           seed,
+				Session:          session,
+				BasePrompt:       resolvedBasePrompt,
+				Targets:          targets,
+				TargetAll:        targetAll,
+				SkipFirst:        skipFirst,
+				PaneIndex:        paneIndex,
+				Panes:            panes,
+				PanesSpecified:   panesSpecified,
+				Tags:             tags,
+				SmartRoute:       smartRoute,
+				RouteStrategy:    routeStrategy,
+				CassCheck:        cassCheck && !noCassCheck,
+				CassSimilarity:   cassSimilarity,
+				CassCheckDays:    cassCheckDays,
+				NoHooks:          noHooks,
+				DryRun:           dryRun,
+				Randomize:        randomize,
+				Seed:             seed,
+				Delivery:         delivery,
+				NoVerifyDelivery: noVerifyDelivery,
+				FileHandoff:      fileHandoff,
+				OverrideGuards:   overrideGuards,
 			}
 
 			// Handle template-based prompts
@@ -730,6 +783,10 @@ func newSendCmd() *cobra.Command {
 	cmd.Flags().IntVar(&cassCheckDays, "cass-check-days", 7, "Look back N days for duplicates")
 	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Disable command hooks")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview what would be sent without sending")
+	cmd.Flags().BoolVar(&noVerifyDelivery, "no-verify-delivery", false, "Skip confirming that prompts appear in agent panes")
+	cmd.Flags().BoolVar(&overrideGuards, "override-guards", false, "Send even when a guard inherited from the session template refuses it (audited)")
+	cmd.Flags().BoolVar(&fileHandoff, "file-handoff", false, "Write the prompt to a file and tell agents to read it (automatic above [send] file_handoff_over bytes)")
+	cmd.Flags().IntVar(&deliveryRetries, "delivery-retries", 0, "Resends before an unconfirmed prompt fails (default from [send] delivery_retries)")
 
 	// Randomization flags
 	cmd.Flags().BoolVar(&randomize, "randomize", false, "Randomize send order for individualized prompts (reduces thundering herd)")
@@ -1185,6 +1242,12 @@ func runSendInternal(opts SendOptions) (err error) {
 		return outputError(redactionBlockedError{summary: *redactionSummary})
 	}
 
+	if !dryRun {
+		if err := enforceSendBudget(session, prompt, opts.OverrideGuards); err != nil {
+			return outputError(err)
+		}
+	}
+
 	// Smart routing: select best agent automatically.
 	// Explicit pane selection (--pane/--panes) wins over automatic routing.
 	if opts.SmartRoute && (opts.PanesSpecified || paneIndex >= 0) {
@@ -1340,6 +1403,22 @@ func runSendInternal(opts SendOptions) (err error) {
 		}
 	}
 
+	// Run pre-send exec hooks, which may rewrite the message or veto the send
+	if !dryRun && !opts.NoHooks {
+		out, err := runExecHooks(hookCtx.ProjectDir, hooks.ExecPreSend, session, map[string]interface{}{
+			"message":    prompt,
+			"targets":    targetDesc,
+			"pane_index": paneIndex,
+			"source":     promptSource,
+			"template":   templateName,
+		})
+		if err != nil {
+			return outputError(err)
+		}
+		prompt = out.String("message", prompt)
+		opts.Prompt = prompt
+	}
+
 	// Auto-checkpoint before broadcast sends
 	isBroadcast := !opts.PanesSpecified && paneIndex < 0 && (targetAll || (!targetCC && !targetCod && !targetGmi && len(tags) == 0))
 	if !dryRun && isBroadcast && cfg != nil && cfg.Checkpoints.Enabled && cfg.Checkpoints.BeforeBroadcast {
@@ -1421,12 +1500,22 @@ func runSendInternal(opts SendOptions) (err error) {
 			skipFirst = true
 		}
 
+		// Panes a human has taken over only get prompts sent to them by
+		// index (--pane/--panes).
+		takenOver := takeover.Panes(session)
+		var heldPanes []int
+
 		for i, p := range panes {
 			// Skip first pane if requested
 			if skipFirst && i == 0 {
 				continue
 			}
 
+			if takenOver[p.ID] != nil {
+				heldPanes = append(heldPanes, p.Index)
+				continue
+			}
+
 			// Apply filters
 			if !targetAll && !noFilter {
 				// Check tags
@@ -1469,6 +1558,9 @@ func runSendInternal(opts SendOptions) (err error) {
 
 			selectedPanes = append(selectedPanes, p)
 		}
+		if len(heldPanes) > 0 && !jsonOutput {
+			fmt.Fprintf(os.Stderr, "Note: skipping pane(s) %v, taken over by a human (ntm takeover)\n", heldPanes)
+		}
 	}
 
 	// Track results for JSON output
@@ -1512,7 +1604,7 @@ func runSendInternal(opts SendOptions) (err error) {
 	// If specific pane requested
 	if paneIndex >= 0 {
 		p := selectedPanes[0]
-		if err := sendPromptToPane(session, p, prompt); err != nil {
+		if err := sendPromptToPaneWith(session, p, prompt, opts.paneSendOptions()); err != nil {
 			failed++
 			histErr = err
 			if jsonOutput {
@@ -1564,7 +1656,7 @@ func runSendInternal(opts SendOptions) (err error) {
 	}
 
 	for _, p := range selectedPanes {
-		if err := sendPromptToPane(session, p, prompt); err != nil {
+		if err := sendPromptToPaneWith(session, p, prompt, opts.paneSendOptions()); err != nil {
 			failed++
 			histErr = err
 			if !jsonOutput {
@@ -1845,6 +1937,7 @@ func newKillCmd() *cobra.Command {
 	var noHooks bool
 	var summarize bool
 	var project string
+	var confirmToken string
 
 	cmd := &cobra.Command{
 		Use:   "kill <session>",
@@ -1853,6 +1946,10 @@ func newKillCmd() *cobra.Command {
 
 Use --project to kill all sessions for a base project (requires confirmation).
 
+With --json, --force is implied. If [robot.confirm] requires the "kill"
+class, the first call instead returns a confirmation token and a summary;
+re-run with --confirm-token to kill.
+
 Examples:
   ntm kill myproject              # Prompts for confirmation
   ntm kill myproject --force      # No confirmation
@@ -1864,12 +1961,17 @@ Examples:
 			if project != "" && len(args) > 0 {
 				return fmt.Errorf("cannot use --project with a specific session name")
 			}
+			if project == "" && len(args) == 0 {
+				return fmt.Errorf("session name or --project required")
+			}
+			if IsJSONOutput() {
+				if out := checkKillConfirmation(project, args, tags, summarize, confirmToken); out != nil {
+					return output.PrintJSON(out)
+				}
+			}
 			if project != "" {
 				return runKillProject(cmd.OutOrStdout(), project, force, tags, noHooks, summarize)
 			}
-			if len(args) == 0 {
-				return fmt.Errorf("session name or --project required")
-			}
 			return runKill(cmd.OutOrStdout(), args[0], force, tags, noHooks, summarize)
 		},
 	}
@@ -1879,10 +1981,34 @@ Examples:
 	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Disable command hooks")
 	cmd.Flags().BoolVar(&summarize, "summarize", false, "Generate session summary before killing")
 	cmd.Flags().StringVarP(&project, "project", "p", "", "kill all sessions for a base project name")
+	cmd.Flags().StringVar(&confirmToken, "confirm-token", "", "token confirming the kill in --json mode (see [robot.confirm])")
 
 	return cmd
 }
 
+// checkKillConfirmation gates a --json kill on the "kill" confirmation class.
+func checkKillConfirmation(project string, args, tags []string, summarize bool, token string) *robot.ConfirmationOutput {
+	req := robot.ConfirmRequest{
+		Class:   robot.ConfirmClassKill,
+		Command: "kill",
+		Effects: []string{"All agent processes in the affected panes are terminated"},
+	}
+	if project != "" {
+		req.Session = project
+		req.Summary = "Kill all sessions of project " + project
+		req.Target = "project:" + project
+	} else {
+		req.Session = args[0]
+		req.Summary = "Kill session " + args[0]
+		req.Target = "session:" + args[0]
+	}
+	if len(tags) > 0 {
+		req.Summary += " (panes tagged " + strings.Join(tags, ",") + ")"
+	}
+	req.Target += fmt.Sprintf(" tags=%s summarize=%t", strings.Join(tags, ","), summarize)
+	return robot.CheckConfirmation(req, token)
+}
+
 func runKill(w io.Writer, session string, force bool, tags []string, noHooks bool, summarize bool) (err error) {
 	// Use kernel for JSON output mode
 	if IsJSONOutput() {
@@ -2010,6 +2136,15 @@ func runKill(w io.Writer, session string, force bool, tags []string, noHooks boo
 			fmt.Printf("⚠ Summary generation failed: %v\n", err)
 		} else {
 			fmt.Println("\n" + summaryResult.Text + "\n")
+			if cfg != nil && cfg.Memory.DistillOnKill {
+				res, err := distillSessionMemory(session, getSessionWorkingDir(session), summaryResult)
+				switch {
+				case err != nil:
+					fmt.Printf("⚠ Memory distillation failed: %v\n", err)
+				case res.Path != "":
+					fmt.Printf("Proposed %d memory addition(s); review with: git apply --stat %s\n", len(res.Proposals), res.Path)
+				}
+			}
 		}
 	}
 
@@ -2640,19 +2775,167 @@ func looksLikeShellCommand(line string) bool {
 }
 
 func sendPromptToPane(session string, p tmux.Pane, prompt string) error {
+	return sendPromptToPaneWith(session, p, prompt, configPaneSendOptions())
+}
+
+// paneSendOptions controls how a prompt reaches one pane.
+type paneSendOptions struct {
+	// Delivery enables delivery verification (nil = fire and forget).
+	Delivery *tmux.DeliveryOptions
+	// HandoffOver hands prompts larger than this many bytes to agents
+	// through a file (0 = never); ForceHandoff does so for every prompt.
+	HandoffOver  int
+	ForceHandoff bool
+	HandoffDir   string
+	HandoffKeep  time.Duration
+}
+
+// sendPromptToPaneWith sends a prompt and, when ps.Delivery is non-nil,
+// confirms it reached an agent pane, resending up to Delivery.Retries times.
+// Prompts that never show up are escalated as prompt_undelivered events.
+// Prompts too large to type are written to a file and the agent is sent an
+// instruction to read it instead.
+func sendPromptToPaneWith(session string, p tmux.Pane, prompt string, ps paneSendOptions) error {
 	if p.Type == tmux.AgentUser {
 		if err := tmux.PasteKeys(p.ID, prompt, true); err != nil {
 			return err
 		}
 		return nil
 	}
-	if err := sendPromptWithDoubleEnterForAgent(p.ID, prompt, p.Type); err != nil {
-		return err
+	text := prompt
+	if ps.ForceHandoff || (ps.HandoffOver > 0 && len(prompt) > ps.HandoffOver) {
+		var err error
+		if text, err = handOffPrompt(session, p, prompt, ps); err != nil {
+			return err
+		}
+	}
+	if ps.Delivery == nil {
+		if err := sendPromptWithDoubleEnterForAgent(p.ID, text, p.Type); err != nil {
+			return err
+		}
+	} else {
+		res, err := tmux.SendKeysForAgentVerified(p.ID, text, p.Type, *ps.Delivery)
+		if errors.Is(err, tmux.ErrDeliveryUnconfirmed) || errors.Is(err, tmux.ErrPromptTruncated) {
+			emitPromptUndelivered(session, p, text, res, err)
+		}
+		if err != nil {
+			return err
+		}
 	}
 	addTimelinePromptMarker(session, p, prompt)
 	return nil
 }
 
+// handOffPrompt writes text to a handoff file, records the path in the audit
+// log, and returns the agent's instruction to read it. Expired handoff files
+// are cleaned up on the way.
+func handOffPrompt(session string, p tmux.Pane, text string, ps paneSendOptions) (string, error) {
+	h, err := prompt.WriteHandoff(ps.HandoffDir, session, p.Index, text)
+	if err != nil {
+		return "", err
+	}
+	_ = audit.LogEvent(session, audit.EventTypeSend, audit.ActorUser, "send.file_handoff", map[string]interface{}{
+		"pane_index": p.Index,
+		"agent_type": agentTypeToString(p.Type),
+		"path":       h.Path,
+		"bytes":      h.Bytes,
+		"sha256":     h.SHA256,
+	}, nil)
+
+	keep := ps.HandoffKeep
+	if keep <= 0 {
+		keep = prompt.DefaultHandoffKeep
+	}
+	if removed, err := prompt.SweepHandoffs(ps.HandoffDir, keep, time.Now()); err == nil && len(removed) > 0 {
+		_ = audit.LogEvent(session, audit.EventTypeSend, audit.ActorSystem, "send.file_handoff_cleanup", map[string]interface{}{
+			"removed": removed,
+		}, nil)
+	}
+	return prompt.HandoffInstruction(tmux.SendProfileFor(p.Type).FileHandoff, h.Path), nil
+}
+
+// configPaneSendOptions returns the per-pane send settings from the [send]
+// config.
+func configPaneSendOptions() paneSendOptions {
+	sendCfg := config.DefaultSendConfig()
+	if cfg != nil {
+		sendCfg = cfg.Send
+	}
+	return paneSendOptions{
+		Delivery:    configDeliveryOptions(),
+		HandoffOver: sendCfg.FileHandoffOver,
+		HandoffDir:  sendCfg.FileHandoffDir,
+		HandoffKeep: time.Duration(sendCfg.FileHandoffKeepHours) * time.Hour,
+	}
+}
+
+// paneSendOptions resolves the per-pane send settings for a send: flags
+// override the [send] config.
+func (o SendOptions) paneSendOptions() paneSendOptions {
+	ps := configPaneSendOptions()
+	ps.Delivery = o.deliveryOptions()
+	ps.ForceHandoff = o.FileHandoff
+	return ps
+}
+
+// configDeliveryOptions returns the delivery verification settings from the
+// [send] config, or nil when verification is disabled.
+func configDeliveryOptions() *tmux.DeliveryOptions {
+	sendCfg := config.DefaultSendConfig()
+	if cfg != nil {
+		sendCfg = cfg.Send
+	}
+	if !sendCfg.VerifyDelivery {
+		return nil
+	}
+	opts := tmux.DefaultDeliveryOptions()
+	opts.Retries = sendCfg.DeliveryRetries
+	return &opts
+}
+
+// pasteOptionsFromConfig maps [send] paste settings onto tmux paste options.
+func pasteOptionsFromConfig(c config.SendConfig) tmux.PasteOptions {
+	return tmux.PasteOptions{
+		BracketedPaste: c.BracketedPaste,
+		ChunkSize:      c.PasteChunkSize,
+		ChunkDelay:     time.Duration(c.PasteChunkDelayMs) * time.Millisecond,
+	}
+}
+
+// deliveryOptions resolves delivery verification for a send: flags override
+// the [send] config.
+func (o SendOptions) deliveryOptions() *tmux.DeliveryOptions {
+	if o.NoVerifyDelivery {
+		return nil
+	}
+	if o.Delivery != nil {
+		return o.Delivery
+	}
+	return configDeliveryOptions()
+}
+
+func emitPromptUndelivered(session string, p tmux.Pane, prompt string, res tmux.DeliveryResult, err error) {
+	events.Emit(events.EventPromptUndelivered, session, map[string]interface{}{
+		"pane_index":    p.Index,
+		"agent_type":    agentTypeToString(p.Type),
+		"attempts":      res.Attempts,
+		"prompt_length": len(prompt),
+		"error":         err.Error(),
+	})
+	events.DefaultEmitter().Emit(events.NewWebhookEvent(
+		events.WebhookPromptUndelivered,
+		session,
+		p.ID,
+		agentTypeToString(p.Type),
+		fmt.Sprintf("Prompt not confirmed in pane %d after %d attempt(s)", p.Index, res.Attempts),
+		map[string]string{
+			"pane_index":     fmt.Sprintf("%d", p.Index),
+			"attempts":       fmt.Sprintf("%d", res.Attempts),
+			"prompt_preview": truncatePrompt(prompt, 50),
+		},
+	))
+}
+
 func sendPromptWithDoubleEnter(paneID, prompt string) error {
 	// Default to AgentUnknown for backward compatibility
 	return tmux.SendKeysForAgentDoubleEnter(paneID, prompt, tmux.AgentUnknown)
@@ -3510,6 +3793,11 @@ func runSendBatch(opts SendOptions) error {
 		// Send to each target pane
 		var paneDelivered, paneFailed int
 		var sendErr error
+		if err := enforceSendBudget(opts.Session, promptText, opts.OverrideGuards); err != nil {
+			paneFailed++
+			sendErr = err
+			targetPanes = nil
+		}
 		for _, paneIdx := range targetPanes {
 			p, ok := paneByIndex[paneIdx]
 			if !ok {
@@ -3517,7 +3805,7 @@ func runSendBatch(opts SendOptions) error {
 				sendErr = fmt.Errorf("pane %d not found", paneIdx)
 				continue
 			}
-			if err := sendPromptToPane(opts.Session, p, promptText); err != nil {
+			if err := sendPromptToPaneWith(opts.Session, p, promptText, opts.paneSendOptions()); err != nil {
 				paneFailed++
 				sendErr = err
 			} else {
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// applySendProfiles registers the [send.profiles] overrides on top of the
+// built-in per-agent send profiles. Profiles are applied in name order and
+// the first invalid one stops the rest.
+func applySendProfiles(profiles map[string]config.SendProfileConfig) error {
+	tmux.ResetSendProfiles()
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		agentType, ok := sendProfileAgentType(name)
+		if !ok {
+			return fmt.Errorf("send profile %q: unknown agent type", name)
+		}
+		p := tmux.DefaultSendProfile(agentType).Merge(sendProfileFromConfig(profiles[name]))
+		if err := tmux.RegisterSendProfile(agentType, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendProfileAgentType maps a [send.profiles] key to an agent type. Both the
+// full names (claude, codex, gemini) and pane short codes (cc, cod, gmi) work.
+func sendProfileAgentType(name string) (tmux.AgentType, bool) {
+	switch normalizeAgentType(name) {
+	case "claude":
+		return tmux.AgentClaude, true
+	case "codex":
+		return tmux.AgentCodex, true
+	case "gemini":
+		return tmux.AgentGemini, true
+	}
+	t := agent.AgentType(normalizeAgentType(name))
+	return t, t.IsValid()
+}
+
+func sendProfileFromConfig(c config.SendProfileConfig) tmux.SendProfile {
+	p := tmux.SendProfile{
+		Submit:         c.Submit,
+		SubmitDelay:    time.Duration(c.SubmitDelayMs) * time.Millisecond,
+		SubmitInterval: time.Duration(c.SubmitIntervalMs) * time.Millisecond,
+		Paste:          tmux.PasteMode(c.Paste),
+		PasteOver:      c.PasteOver,
+		Newline:        c.Newline,
+		PreKeys:        c.PreKeys,
+		Escapes:        tmux.EscapeMode(c.Escapes),
+		FileHandoff:    c.FileHandoff,
+	}
+	for _, r := range c.AutoConfirm {
+		p.AutoConfirm = append(p.AutoConfirm, tmux.AutoConfirmRule{Match: r.Match, Keys: r.Keys})
+	}
+	return p
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func TestApplySendProfiles(t *testing.T) {
+	defer tmux.ResetSendProfiles()
+
+	err := applySendProfiles(map[string]config.SendProfileConfig{
+		"cod": {Submit: []string{"C-j"}, SubmitDelayMs: 200},
+		"aider": {
+			Escapes:     "strip",
+			AutoConfirm: []config.AutoConfirmConfig{{Match: `\(Y\)es/\(N\)o`, Keys: []string{"n", "Enter"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	codex := tmux.SendProfileFor(tmux.AgentCodex)
+	if !reflect.DeepEqual(codex.Submit, []string{"C-j"}) || codex.SubmitDelay.Milliseconds() != 200 || codex.PasteOver != 512 {
+		t.Errorf("codex profile = %+v, want overrides merged onto built-in", codex)
+	}
+	if aider := tmux.SendProfileFor(tmux.AgentAider); aider.Escapes != tmux.EscapesStrip || len(aider.AutoConfirm) != 1 {
+		t.Errorf("aider profile = %+v", aider)
+	}
+
+	if err := applySendProfiles(map[string]config.SendProfileConfig{"vim": {}}); err == nil {
+		t.Error("unknown agent type should be rejected")
+	}
+	if err := applySendProfiles(map[string]config.SendProfileConfig{"claude": {Paste: "sometimes"}}); err == nil {
+		t.Error("invalid paste mode should be rejected")
+	}
+}
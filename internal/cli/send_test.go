@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/config"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/tests/testutil"
 )
@@ -1450,3 +1451,71 @@ func TestParseBatchFile_PriorityAnnotations(t *testing.T) {
 		}
 	})
 }
+
+func TestSendOptionsDeliveryOptions(t *testing.T) {
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+
+	cfg = config.Default()
+	got := SendOptions{}.deliveryOptions()
+	if got == nil || got.Retries != tmux.DefaultDeliveryRetries {
+		t.Fatalf("default delivery = %+v, want verification with %d retries", got, tmux.DefaultDeliveryRetries)
+	}
+
+	cfg.Send.DeliveryRetries = 5
+	if got := (SendOptions{}).deliveryOptions(); got == nil || got.Retries != 5 {
+		t.Errorf("config retries not applied: %+v", got)
+	}
+	override := tmux.DefaultDeliveryOptions()
+	override.Retries = 0
+	if got := (SendOptions{Delivery: &override}).deliveryOptions(); got != &override {
+		t.Errorf("flag override not used: %+v", got)
+	}
+	if got := (SendOptions{NoVerifyDelivery: true, Delivery: &override}).deliveryOptions(); got != nil {
+		t.Errorf("--no-verify-delivery should disable verification, got %+v", got)
+	}
+
+	cfg.Send.VerifyDelivery = false
+	if got := (SendOptions{}).deliveryOptions(); got != nil {
+		t.Errorf("verify_delivery = false should disable verification, got %+v", got)
+	}
+}
+
+func TestPasteOptionsFromConfig(t *testing.T) {
+	t.Parallel()
+	got := pasteOptionsFromConfig(config.DefaultSendConfig())
+	want := tmux.DefaultPasteOptions()
+	if got != want {
+		t.Errorf("default [send] paste options = %+v, want %+v", got, want)
+	}
+}
+
+func TestHandOffPrompt(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("x", 120000)
+	ps := paneSendOptions{HandoffDir: dir}
+
+	instruction, err := handOffPrompt("proj", tmux.Pane{Index: 2, Type: tmux.AgentCodex}, big, ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "ntm-prompt-proj-2-*.md"))
+	if len(matches) != 1 {
+		t.Fatalf("handoff files = %v", matches)
+	}
+	if !strings.Contains(instruction, matches[0]) || len(instruction) > 500 {
+		t.Errorf("instruction = %q, want a short message naming %s", instruction, matches[0])
+	}
+	data, _ := os.ReadFile(matches[0])
+	if string(data) != big {
+		t.Error("handoff file does not hold the full prompt")
+	}
+
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = config.Default()
+	cfg.Send.FileHandoffOver = 1000
+	if got := (SendOptions{FileHandoff: true}).paneSendOptions(); got.HandoffOver != 1000 || !got.ForceHandoff || got.HandoffKeep != 24*time.Hour {
+		t.Errorf("pane send options = %+v", got)
+	}
+}
@@ -7,11 +7,14 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
 	"github.com/Dicklesworthstone/ntm/internal/serve"
 	"github.com/Dicklesworthstone/ntm/internal/state"
 )
@@ -37,13 +40,28 @@ API Endpoints:
   GET /api/robot/status      Robot status (JSON)
   GET /api/robot/health      Robot health (JSON)
   GET /events                Server-Sent Events stream
-  GET /health                Health check
+  GET /health                Liveness probe (process is up)
+  GET /ready                 Readiness probe (503 until the state store, event
+                             bus, and tmux are usable)
 
 Examples:
   ntm serve                              # Start on 127.0.0.1:7337
   ntm serve --port 8080                  # Start on custom port
   ntm serve --host 0.0.0.0 --auth-mode api_key --api-key $KEY
-  ntm serve --auth-mode oidc --oidc-issuer https://issuer --oidc-jwks-url https://issuer/.well-known/jwks.json`,
+  ntm serve --auth-mode oidc --oidc-issuer https://issuer
+                                         # JWKS via discovery; RS256/ES256/EdDSA
+  ntm serve --compression off            # Disable gzip response compression
+  ntm serve --h2c                        # Cleartext HTTP/2 behind a trusted proxy
+  ntm serve --listen unix://$HOME/.local/share/ntm/ntm.sock
+                                         # No TCP port; socket mode 0600
+  curl --unix-socket ~/.local/share/ntm/ntm.sock http://ntm/health
+  ntm serve --host 0.0.0.0 --auth-mode api_key --api-key $KEY \
+    --max-body-size 262144 --request-timeout 15s \
+    --route-timeout "/api/v1/sessions/*/agents/wait=30m"
+
+Request bodies are capped at 1 MiB and handlers at 30s by default; event
+streams and WebSocket connections are exempt. A negative value disables a
+limit.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runServe(opts)
 		},
@@ -51,16 +69,27 @@ Examples:
 
 	cmd.Flags().StringVar(&opts.Host, "host", opts.Host, "HTTP bind host (default 127.0.0.1)")
 	cmd.Flags().IntVar(&opts.Port, "port", opts.Port, "HTTP server port")
+	cmd.Flags().StringVar(&opts.Listen, "listen", "", "Listen on a Unix domain socket (unix:///path/ntm.sock) instead of --host/--port; only the current user can connect")
 	cmd.Flags().StringVar(&opts.AuthMode, "auth-mode", opts.AuthMode, "Auth mode: local|api_key|oidc|mtls")
 	cmd.Flags().StringVar(&opts.APIKey, "api-key", "", "API key for api_key auth mode")
 	cmd.Flags().StringVar(&opts.OIDCIssuer, "oidc-issuer", "", "OIDC issuer URL for oidc auth mode")
 	cmd.Flags().StringVar(&opts.OIDCAudience, "oidc-audience", "", "OIDC audience for oidc auth mode")
-	cmd.Flags().StringVar(&opts.OIDCJWKSURL, "oidc-jwks-url", "", "JWKS URL for oidc auth mode")
+	cmd.Flags().StringVar(&opts.OIDCJWKSURL, "oidc-jwks-url", "", "JWKS URL for oidc auth mode (default: discovered from the issuer's /.well-known/openid-configuration)")
 	cmd.Flags().StringVar(&opts.MTLSCert, "mtls-cert", "", "Server TLS cert file for mtls auth mode")
 	cmd.Flags().StringVar(&opts.MTLSKey, "mtls-key", "", "Server TLS key file for mtls auth mode")
 	cmd.Flags().StringVar(&opts.MTLSCA, "mtls-ca", "", "Client CA bundle for mtls auth mode")
 	cmd.Flags().StringArrayVar(&opts.CORSAllowOrigins, "cors-allow-origin", nil, "Allowed CORS origins (repeatable). Defaults to localhost only.")
 	cmd.Flags().StringVar(&opts.PublicBaseURL, "public-base-url", "", "Public base URL for external clients (optional)")
+	cmd.Flags().StringSliceVar(&opts.Compression, "compression", []string{"gzip"}, "Response compression encodings in preference order, or \"off\". Event streams are never compressed")
+	cmd.Flags().IntVar(&opts.CompressionLevel, "compression-level", 0, "Compression level (0 = encoder default; gzip: 1-9)")
+	cmd.Flags().IntVar(&opts.CompressionMinSize, "compression-min-size", serve.DefaultCompressionMinSize, "Smallest response body in bytes worth compressing")
+	cmd.Flags().BoolVar(&opts.NoHTTP2, "no-http2", false, "Disable HTTP/2 (otherwise negotiated via ALPN in mtls mode)")
+	cmd.Flags().BoolVar(&opts.H2C, "h2c", false, "Also accept cleartext HTTP/2 (prior knowledge) without TLS, e.g. behind a trusted proxy")
+	cmd.Flags().Int64Var(&opts.MaxBodySize, "max-body-size", serve.DefaultMaxBodyBytes, "Largest request body in bytes; larger bodies get 413")
+	cmd.Flags().DurationVar(&opts.RequestTimeout, "request-timeout", serve.DefaultRequestTimeout, "Longest a request may take, excluding event streams and WebSockets")
+	cmd.Flags().StringArrayVar(&opts.RouteTimeouts, "route-timeout", nil, "Per-route request timeout as PATTERN=DURATION, e.g. /api/v1/wait=30m (repeatable; 0 = none)")
+	cmd.Flags().DurationVar(&opts.ReadHeaderTimeout, "read-header-timeout", serve.DefaultReadHeaderTimeout, "Longest a client may take to send request headers")
+	cmd.Flags().DurationVar(&opts.IdleTimeout, "idle-timeout", serve.DefaultIdleTimeout, "Longest a keep-alive connection may sit idle")
 
 	return cmd
 }
@@ -68,6 +97,7 @@ Examples:
 type serveOptions struct {
 	Host             string
 	Port             int
+	Listen           string
 	PublicBaseURL    string
 	AuthMode         string
 	APIKey           string
@@ -78,6 +108,18 @@ type serveOptions struct {
 	MTLSKey          string
 	MTLSCA           string
 	CORSAllowOrigins []string
+
+	Compression        []string
+	CompressionLevel   int
+	CompressionMinSize int
+	NoHTTP2            bool
+	H2C                bool
+
+	MaxBodySize       int64
+	RequestTimeout    time.Duration
+	RouteTimeouts     []string
+	ReadHeaderTimeout time.Duration
+	IdleTimeout       time.Duration
 }
 
 func runServe(opts serveOptions) error {
@@ -104,13 +146,23 @@ func runServe(opts serveOptions) error {
 	if err != nil {
 		return err
 	}
+	limits, err := limitsConfig(opts)
+	if err != nil {
+		return err
+	}
 	cfg := serve.Config{
 		Host:           opts.Host,
 		Port:           opts.Port,
+		Listen:         opts.Listen,
 		PublicBaseURL:  opts.PublicBaseURL,
 		EventBus:       events.DefaultBus,
 		StateStore:     stateStore,
 		AllowedOrigins: opts.CORSAllowOrigins,
+		Compression:    compressionConfig(opts),
+		DisableHTTP2:   opts.NoHTTP2,
+		H2C:            opts.H2C,
+		Limits:         limits,
+		JobsDir:        jobs.DefaultDir,
 		Auth: serve.AuthConfig{
 			Mode:   mode,
 			APIKey: opts.APIKey,
@@ -131,6 +183,7 @@ func runServe(opts serveOptions) error {
 	}
 	// Create server with default event bus
 	srv := serve.New(cfg)
+	markRunning("serve")
 
 	// Setup signal handling for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -153,13 +206,57 @@ func runServe(opts serveOptions) error {
 	slog.Info("server starting",
 		"host", opts.Host,
 		"port", opts.Port,
+		"listen", opts.Listen,
 		"auth_mode", opts.AuthMode,
 		"tls_enabled", mode == serve.AuthModeMTLS,
 		"public_base_url", opts.PublicBaseURL,
 		"allowed_origins", len(opts.CORSAllowOrigins),
+		"compression", strings.Join(opts.Compression, ","),
+		"http2", !opts.NoHTTP2,
 	)
-	fmt.Printf("Starting NTM server on %s://%s:%d\n", scheme, opts.Host, opts.Port)
+	if opts.Listen != "" {
+		fmt.Printf("Starting NTM server on %s\n", opts.Listen)
+	} else {
+		fmt.Printf("Starting NTM server on %s://%s:%d\n", scheme, opts.Host, opts.Port)
+	}
 	fmt.Println("Press Ctrl+C to stop")
 
 	return srv.Start(ctx)
 }
+
+// compressionConfig converts the --compression flags.
+func compressionConfig(opts serveOptions) serve.CompressionConfig {
+	if len(opts.Compression) == 0 || (len(opts.Compression) == 1 && strings.EqualFold(opts.Compression[0], "off")) {
+		return serve.CompressionConfig{Disabled: true}
+	}
+	return serve.CompressionConfig{
+		Encodings: opts.Compression,
+		Level:     opts.CompressionLevel,
+		MinSize:   opts.CompressionMinSize,
+	}
+}
+
+// limitsConfig converts the request limit flags.
+func limitsConfig(opts serveOptions) (serve.LimitsConfig, error) {
+	cfg := serve.LimitsConfig{
+		MaxBodyBytes:      opts.MaxBodySize,
+		RequestTimeout:    opts.RequestTimeout,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+	}
+	for _, rt := range opts.RouteTimeouts {
+		pattern, value, ok := strings.Cut(rt, "=")
+		if !ok {
+			return cfg, fmt.Errorf("invalid --route-timeout %q: want PATTERN=DURATION", rt)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid --route-timeout %q: %w", rt, err)
+		}
+		if cfg.RouteTimeouts == nil {
+			cfg.RouteTimeouts = make(map[string]time.Duration)
+		}
+		cfg.RouteTimeouts[pattern] = d
+	}
+	return cfg, nil
+}
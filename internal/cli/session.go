@@ -22,6 +22,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/handoff"
 	"github.com/Dicklesworthstone/ntm/internal/kernel"
 	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/process"
 	sessionPkg "github.com/Dicklesworthstone/ntm/internal/session"
 	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
@@ -638,6 +639,35 @@ func estimatePaneContextUsage(p tmux.Pane) (paneContextUsage, bool) {
 	}, true
 }
 
+// samplePaneResources measures the process tree of each pane, keyed by pane
+// index (best-effort; panes without a PID are skipped).
+func samplePaneResources(panes []tmux.Pane) map[int]process.Usage {
+	byIndex := make(map[int]process.Usage)
+	var pids []int
+	for _, p := range panes {
+		if p.PID > 0 {
+			pids = append(pids, p.PID)
+		}
+	}
+	if len(pids) == 0 {
+		return byIndex
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	usage, err := process.DefaultSampler().SampleTrees(ctx, pids)
+	if err != nil {
+		return byIndex
+	}
+	for _, p := range panes {
+		if u, ok := usage[p.PID]; ok {
+			byIndex[p.Index] = u
+		}
+	}
+	return byIndex
+}
+
 func buildStatusResponse(session string, opts statusOptions) (output.StatusResponse, error) {
 	if err := tmux.EnsureInstalled(); err != nil {
 		return output.StatusResponse{}, err
@@ -715,6 +745,7 @@ func buildStatusResponse(session string, opts statusOptions) (output.StatusRespo
 		}
 		contextByIndex[p.Index] = usage
 	}
+	resourcesByIndex := samplePaneResources(panes)
 
 	// Load assignments if requested (or if filtering/summary requires them)
 	var assignmentStore *assignment.AssignmentStore
@@ -780,6 +811,12 @@ func buildStatusResponse(session string, opts statusOptions) (output.StatusRespo
 			paneResp.ContextPercent = usage.Percent
 			paneResp.ContextModel = usage.Model
 		}
+		if usage, ok := resourcesByIndex[p.Index]; ok {
+			paneResp.CPUPercent = usage.CPUPercent
+			paneResp.MemoryBytes = usage.RSSBytes
+			paneResp.MemoryPercent = usage.MemPercent
+			paneResp.Processes = usage.Processes
+		}
 		resp.Panes = append(resp.Panes, paneResp)
 	}
 
@@ -821,6 +858,7 @@ func buildStatusResponse(session string, opts statusOptions) (output.StatusRespo
 			Total:      stats.Total,
 			Assigned:   stats.Assigned,
 			Working:    stats.Working,
+			Verifying:  stats.Verifying,
 			Completed:  stats.Completed,
 			Failed:     stats.Failed,
 			Reassigned: stats.Reassigned,
@@ -1061,6 +1099,7 @@ func runStatusOnce(w io.Writer, session string, opts statusOptions) error {
 		}
 		contextByIndex[p.Index] = usage
 	}
+	resourcesByIndex := samplePaneResources(panes)
 
 	// Load assignments if requested (or if filtering/summary requires them)
 	var assignmentStore *assignment.AssignmentStore
@@ -1319,6 +1358,41 @@ func runStatusOnce(w io.Writer, session string, opts statusOptions) error {
 		fmt.Fprintln(w)
 	}
 
+	if len(resourcesByIndex) > 0 {
+		warnColor := color(t.Warning)
+		fmt.Fprintf(w, "  %sResources%s\n", bold, reset)
+		fmt.Fprintf(w, "  %s%s%s\n", surface, "─────────────────────────────────────────────────────────", reset)
+
+		for _, p := range panes {
+			usage, ok := resourcesByIndex[p.Index]
+			if !ok {
+				continue
+			}
+			cpuColor := text
+			if usage.CPUPercent >= 300 {
+				cpuColor = errorColor
+			} else if usage.CPUPercent >= 100 {
+				cpuColor = warnColor
+			}
+			memColor := text
+			if usage.MemPercent >= 50 {
+				memColor = errorColor
+			} else if usage.MemPercent >= 25 {
+				memColor = warnColor
+			}
+			top := ""
+			if usage.Top != "" {
+				top = fmt.Sprintf("  %stop: %s%s", overlay, layout.TruncateWidthDefault(usage.Top, 20), reset)
+			}
+			fmt.Fprintf(w, "    %s%-12s%s %s%5.0f%% CPU%s  %s%9s%s (%.1f%%)  %s%d proc%s%s\n",
+				text, layout.TruncateWidthDefault(paneLabel(session, p), 12), reset,
+				cpuColor, usage.CPUPercent, reset,
+				memColor, formatBytes(int64(usage.RSSBytes)), reset, usage.MemPercent,
+				overlay, usage.Processes, reset, top)
+		}
+		fmt.Fprintln(w)
+	}
+
 	// Agent summary with icons
 	fmt.Fprintf(w, "  %sAgents%s\n", bold, reset)
 
@@ -1407,6 +1481,9 @@ func runStatusOnce(w io.Writer, session string, opts statusOptions) error {
 					case assignment.StatusWorking:
 						statusIcon = "▶"
 						statusColor = success
+					case assignment.StatusVerifying:
+						statusIcon = "◐"
+						statusColor = assignColor
 					case assignment.StatusCompleted:
 						statusIcon = "✓"
 						statusColor = success
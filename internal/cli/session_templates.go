@@ -219,7 +219,8 @@ func runSessionTemplatesShow(name string) error {
 		if tmpl.Spec.Beads.AutoAssign || tmpl.Spec.Beads.Filter != "" {
 			result.Beads = &tmpl.Spec.Beads
 		}
-		if tmpl.Spec.Options.Stagger != nil || tmpl.Spec.Options.Checkpoint != nil {
+		if tmpl.Spec.Options.Stagger != nil || tmpl.Spec.Options.Checkpoint != nil ||
+			tmpl.Spec.Options.Budget != nil || tmpl.Spec.Options.Guards != nil {
 			result.Options = &tmpl.Spec.Options
 		}
 		return json.NewEncoder(os.Stdout).Encode(result)
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/share"
+)
+
+// ShareResult is the output of `ntm share`.
+type ShareResult struct {
+	Token     string     `json:"token,omitempty"`
+	ID        string     `json:"id,omitempty"`
+	Session   string     `json:"session,omitempty"`
+	Scope     string     `json:"scope,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	StreamURL string     `json:"stream_url,omitempty"`
+	StatusURL string     `json:"status_url,omitempty"`
+	Revoked   bool       `json:"revoked,omitempty"`
+}
+
+func newShareCmd() *cobra.Command {
+	var (
+		session   string
+		ttl       time.Duration
+		readOnly  bool
+		baseURL   string
+		revokeAll bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Mint a read-only, expiring link to watch a session via ntm serve",
+		Long: `Mint a scoped share token that lets a teammate watch one session's
+SSE stream and status endpoints on a running ntm serve, without full API
+credentials. The token is read-only, limited to the named session, and
+expires after --ttl (at most 7 days).
+
+Tokens are signed with ~/.config/ntm/share.key, which ntm serve reads on
+each request. --revoke-all rotates the key, invalidating every outstanding
+share link at once.
+
+The token works as a bearer token or as the share_token query parameter:
+  GET /events?share_token=T                     Event stream for the session
+  GET /api/v1/sessions/:id[/status|/agents|/events]?share_token=T
+
+Examples:
+  ntm share --session myproject                  # 1h read-only link
+  ntm share --session myproject --ttl 2h --read-only
+  ntm share --session myproject --base-url https://ntm.example.com
+  ntm share --revoke-all                         # Invalidate all share links`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if revokeAll {
+				return runShareRevokeAll()
+			}
+			if !readOnly {
+				return fmt.Errorf("only read-only share links are supported")
+			}
+			if session == "" {
+				return fmt.Errorf("--session is required")
+			}
+			return runShare(session, ttl, baseURL)
+		},
+	}
+
+	cmd.Flags().StringVar(&session, "session", "", "Session to share")
+	cmd.Flags().DurationVar(&ttl, "ttl", share.DefaultTTL, "How long the link stays valid (max 168h)")
+	cmd.Flags().BoolVar(&readOnly, "read-only", true, "Grant read-only access (the only supported scope)")
+	cmd.Flags().StringVar(&baseURL, "base-url", "http://127.0.0.1:7337", "Base URL of the ntm serve instance, used in the printed links")
+	cmd.Flags().BoolVar(&revokeAll, "revoke-all", false, "Rotate the signing key, revoking every share link")
+
+	return cmd
+}
+
+func runShare(session string, ttl time.Duration, baseURL string) error {
+	key, err := share.LoadOrCreateKey("")
+	if err != nil {
+		return fmt.Errorf("load share key: %w", err)
+	}
+	token, grant, err := share.Mint(key, session, ttl, time.Now())
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(baseURL, "/")
+	q := url.Values{"share_token": {token}}.Encode()
+	result := ShareResult{
+		Token:     token,
+		ID:        grant.ID,
+		Session:   grant.Session,
+		Scope:     grant.Scope,
+		ExpiresAt: &grant.ExpiresAt,
+		StreamURL: base + "/events?" + q,
+		StatusURL: base + "/api/v1/sessions/" + url.PathEscape(session) + "/status?" + q,
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(result)
+	}
+
+	fmt.Printf("Read-only share for session '%s' (expires %s)\n\n", session, grant.ExpiresAt.Local().Format(time.RFC1123))
+	fmt.Printf("  Token:   %s\n", token)
+	fmt.Printf("  Stream:  %s\n", result.StreamURL)
+	fmt.Printf("  Status:  %s\n\n", result.StatusURL)
+	fmt.Println("Revoke all share links with: ntm share --revoke-all")
+	return nil
+}
+
+func runShareRevokeAll() error {
+	if _, err := share.RotateKey(""); err != nil {
+		return err
+	}
+	if IsJSONOutput() {
+		return output.PrintJSON(ShareResult{Revoked: true})
+	}
+	fmt.Println("Share key rotated; all existing share links are revoked.")
+	return nil
+}
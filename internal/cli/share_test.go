@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShareCmdValidation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cmd := newShareCmd()
+	cmd.SetArgs([]string{})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error without --session")
+	}
+
+	cmd = newShareCmd()
+	cmd.SetArgs([]string{"--session", "proj", "--read-only=false"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for writable share")
+	}
+
+	cmd = newShareCmd()
+	cmd.SetArgs([]string{"--session", "proj", "--ttl", "1000h"})
+	if err := cmd.Execute(); err == nil {
+		t.Error("expected error for ttl above maximum")
+	}
+}
+
+func TestShareCmdCreatesKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cmd := newShareCmd()
+	cmd.SetArgs([]string{"--session", "proj", "--ttl", "2h"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(home, ".config", "ntm", "share.key"))
+	if err != nil {
+		t.Fatalf("share key not created: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("share key mode = %o, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/shutdown"
+)
+
+// registerBaseShutdownHooks registers the flushes every command needs. They
+// are registered first so they run last, after command-specific hooks have
+// had a chance to emit their final events and audit records.
+func registerBaseShutdownHooks() {
+	shutdown.Register("audit log", func(context.Context) error {
+		return audit.CloseAll()
+	})
+	shutdown.Register("event journal", func(context.Context) error {
+		return events.CloseDefaultJournal()
+	})
+	shutdown.Register("scores", func(context.Context) error {
+		return scoring.DefaultTracker().Close()
+	})
+}
+
+// shutdownTimeout returns the configured deadline for flushing state.
+func shutdownTimeout() time.Duration {
+	if cfg != nil && cfg.Resilience.ShutdownTimeoutSeconds > 0 {
+		return time.Duration(cfg.Resilience.ShutdownTimeoutSeconds) * time.Second
+	}
+	return shutdown.DefaultTimeout
+}
+
+// runShutdown flushes registered state, warning about hooks that failed or
+// overran the deadline.
+func runShutdown() {
+	report := shutdown.Run(shutdownTimeout())
+	if err := report.Err(); err != nil {
+		// Leave the running marker so the next invocation repairs anything
+		// the failed hooks left half-written.
+		fmt.Fprintf(os.Stderr, "Warning: shutdown did not complete cleanly: %v\n", err)
+		return
+	}
+	if clearRunningMarker != nil {
+		clearRunningMarker()
+	}
+}
+
+// clearRunningMarker removes the marker written by markRunning once all
+// state has been flushed.
+var clearRunningMarker func()
+
+// markRunning records that a long-running command is active so the next
+// invocation can tell whether it exited cleanly.
+func markRunning(name string) {
+	remove, err := shutdown.MarkRunning("", name)
+	if err != nil {
+		output.PrintWarningf("could not write running marker: %v", err)
+		return
+	}
+	clearRunningMarker = remove
+}
+
+// recoverUncleanShutdown repairs JSONL files left with a partial trailing
+// record by a previous run that was killed before flushing its state.
+// recoveryDirs returns shutdown.DefaultRecoveryDirs with the audit and
+// archive directories at their configured roots.
+func recoveryDirs() []string {
+	dirs := make([]string, 0, len(shutdown.DefaultRecoveryDirs))
+	for _, dir := range shutdown.DefaultRecoveryDirs {
+		switch dir {
+		case audit.DefaultDir:
+			dir = audit.Dir()
+		case archive.DefaultOutputDir:
+			dir = archive.OutputDir()
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+func recoverUncleanShutdown() {
+	report, err := shutdown.RecoverUnclean("", recoveryDirs())
+	if err != nil || report == nil || IsJSONOutput() {
+		return
+	}
+	if len(report.Repaired) > 0 {
+		output.PrintWarningf("previous ntm run exited uncleanly; repaired %d log file(s)", len(report.Repaired))
+	}
+	for _, e := range report.Errors {
+		output.PrintWarningf("unclean shutdown recovery: %s", e)
+	}
+}
@@ -0,0 +1,494 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/workflow"
+)
+
+// SimulateTask is one entry of a simulation tasks file.
+type SimulateTask struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	// Files the task is expected to touch, in addition to any paths
+	// mentioned in the title or description.
+	Files []string `json:"files,omitempty"`
+	// Prompt is the text that would be sent; defaults to title + description.
+	Prompt string `json:"prompt,omitempty"`
+	// Minutes and OutputTokens override the simulation defaults.
+	Minutes      int `json:"estimated_minutes,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+}
+
+// SimulateOptions configures a fleet simulation.
+type SimulateOptions struct {
+	Strategy     string
+	StaggerMode  string
+	StaggerDelay time.Duration
+	TaskDuration time.Duration
+	OutputTokens int
+	BudgetUSD    float64
+	Models       config.ModelsConfig
+	// RateLimits informs the smart stagger interval; nil uses the default.
+	RateLimits *ratelimit.RateLimitTracker
+}
+
+// SimulateResult is the JSON output of `ntm simulate`.
+type SimulateResult struct {
+	Template        string              `json:"template"`
+	Strategy        string              `json:"strategy"`
+	StaggerMode     string              `json:"stagger_mode"`
+	StaggerInterval string              `json:"stagger_interval"`
+	Agents          []SimulatedAgent    `json:"agents"`
+	Timeline        []SimulateEvent     `json:"timeline"`
+	Cost            SimulateCost        `json:"cost"`
+	Conflicts       []SimulatedConflict `json:"conflicts"`
+	Unassigned      []string            `json:"unassigned,omitempty"`
+	Duration        string              `json:"duration"`
+	Warnings        []string            `json:"warnings,omitempty"`
+}
+
+// SimulatedAgent is one pane the template would spawn.
+type SimulatedAgent struct {
+	Pane      int      `json:"pane"`
+	AgentType string   `json:"agent_type"`
+	Role      string   `json:"role,omitempty"`
+	Model     string   `json:"model"`
+	Tasks     []string `json:"tasks"`
+	CostUSD   float64  `json:"cost_usd"`
+}
+
+// Simulated event kinds.
+const (
+	SimEventSpawn    = "spawn"
+	SimEventSend     = "send"
+	SimEventComplete = "complete"
+)
+
+// SimulateEvent is one planned action on the simulation timeline.
+type SimulateEvent struct {
+	Offset    time.Duration `json:"-"`
+	At        string        `json:"at"`
+	AtSeconds float64       `json:"at_seconds"`
+	Kind      string        `json:"kind"`
+	Pane      int           `json:"pane"`
+	AgentType string        `json:"agent_type"`
+	TaskID    string        `json:"task_id,omitempty"`
+	Detail    string        `json:"detail,omitempty"`
+}
+
+// SimulateCost is the expected spend of the plan.
+type SimulateCost struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	EstimatedUSD float64 `json:"estimated_usd"`
+	// BudgetUSD and Level are set when a budget was given.
+	BudgetUSD float64          `json:"budget_usd,omitempty"`
+	Level     cost.BudgetLevel `json:"level,omitempty"`
+}
+
+// SimulatedConflict is a file two agents are predicted to edit at once.
+type SimulatedConflict struct {
+	Path  string    `json:"path"`
+	Tasks [2]string `json:"tasks"`
+	Panes [2]int    `json:"panes"`
+	From  string    `json:"from"`
+	Until string    `json:"until"`
+}
+
+func newSimulateCmd() *cobra.Command {
+	var (
+		templateName string
+		tasksFile    string
+		opts         SimulateOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Dry-run a fleet plan without spawning agents",
+		Long: `Run the assignment engine, stagger scheduler, and cost estimator against
+a workflow template and a tasks file without spawning or sending anything.
+
+The result is a timeline of planned spawns and sends, the expected cost,
+and the file conflicts predicted between agents working at the same time.
+
+The tasks file is a JSON array (or an object with a "tasks" array):
+
+  [{"id": "t1", "title": "Fix login bug in internal/auth/login.go",
+    "priority": 1, "files": ["internal/auth/*.go"], "estimated_minutes": 30}]
+
+Examples:
+  ntm simulate --template red-green --tasks tasks.json
+  ntm simulate -t specialist-team --tasks tasks.json --stagger-mode=fixed
+  ntm simulate -t parallel-explore --tasks tasks.json --budget 20 --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tmpl, err := workflow.NewLoader().Get(templateName)
+			if err != nil {
+				return fmt.Errorf("template %q not found (builtins: %s)", templateName, strings.Join(workflow.BuiltinNames(), ", "))
+			}
+			if err := tmpl.Validate(); err != nil {
+				return fmt.Errorf("invalid template %q: %w", templateName, err)
+			}
+			tasks, err := loadSimulateTasks(tasksFile)
+			if err != nil {
+				return err
+			}
+
+			opts.Models = config.DefaultModels()
+			if cfg != nil {
+				opts.Models = cfg.Models
+			}
+			if opts.StaggerMode == "smart" {
+				dir := GetProjectRoot()
+				tracker := ratelimit.NewRateLimitTracker(dir)
+				if err := tracker.LoadFromDir(dir); err == nil {
+					opts.RateLimits = tracker
+				}
+			}
+
+			result, err := simulateFleet(tmpl, tasks, opts)
+			if err != nil {
+				return err
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(result)
+			}
+			return printSimulateResult(cmd.OutOrStdout(), result)
+		},
+	}
+
+	cmd.Flags().StringVarP(&templateName, "template", "t", "", "Workflow template to simulate")
+	cmd.Flags().StringVar(&tasksFile, "tasks", "", "JSON file of tasks to assign")
+	cmd.Flags().StringVar(&opts.Strategy, "strategy", "balanced", "Assignment strategy: balanced, speed, quality, dependency, round-robin")
+	cmd.Flags().StringVar(&opts.StaggerMode, "stagger-mode", "none", "Stagger mode: smart (adaptive), fixed, or none")
+	cmd.Flags().DurationVar(&opts.StaggerDelay, "stagger-delay", 30*time.Second, "Fixed delay between agents (used with --stagger-mode=fixed)")
+	cmd.Flags().DurationVar(&opts.TaskDuration, "task-duration", 20*time.Minute, "Assumed duration of tasks without estimated_minutes")
+	cmd.Flags().IntVar(&opts.OutputTokens, "output-tokens", 4000, "Assumed output tokens of tasks without output_tokens")
+	cmd.Flags().Float64Var(&opts.BudgetUSD, "budget", 0, "Hard budget in USD to check the estimate against")
+	_ = cmd.MarkFlagRequired("template")
+	_ = cmd.MarkFlagRequired("tasks")
+	_ = cmd.RegisterFlagCompletionFunc("template", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return workflow.BuiltinNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+// loadSimulateTasks reads a tasks file holding either a JSON array of tasks
+// or an object with a "tasks" array.
+func loadSimulateTasks(path string) ([]SimulateTask, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading tasks file: %w", err)
+	}
+	var tasks []SimulateTask
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		var wrapped struct {
+			Tasks []SimulateTask `json:"tasks"`
+		}
+		if err2 := json.Unmarshal(data, &wrapped); err2 != nil {
+			return nil, fmt.Errorf("parsing tasks file %s: %w", path, err)
+		}
+		tasks = wrapped.Tasks
+	}
+	seen := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		if tasks[i].ID == "" {
+			tasks[i].ID = fmt.Sprintf("task-%d", i+1)
+		}
+		if seen[tasks[i].ID] {
+			return nil, fmt.Errorf("duplicate task id %q in %s", tasks[i].ID, path)
+		}
+		seen[tasks[i].ID] = true
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks in %s", path)
+	}
+	return tasks, nil
+}
+
+// simulateFleet plans a template's spawn and the assignment of tasks to its
+// agents without touching tmux.
+func simulateFleet(tmpl *workflow.WorkflowTemplate, tasks []SimulateTask, opts SimulateOptions) (*SimulateResult, error) {
+	if opts.TaskDuration <= 0 {
+		opts.TaskDuration = 20 * time.Minute
+	}
+	strategy := assign.ParseStrategy(opts.Strategy)
+	result := &SimulateResult{
+		Template:    tmpl.Name,
+		Strategy:    string(strategy),
+		StaggerMode: opts.StaggerMode,
+		Agents:      []SimulatedAgent{},
+		Timeline:    []SimulateEvent{},
+		Conflicts:   []SimulatedConflict{},
+	}
+
+	// Expand the template into panes; pane 0 is the user pane.
+	var spawnOpts SpawnOptions
+	for _, a := range tmpl.Agents {
+		agentType := workflow.ProfileToAgentType(a.Profile)
+		count := max(a.Count, 1)
+		for i := 0; i < count; i++ {
+			result.Agents = append(result.Agents, SimulatedAgent{
+				Pane:      len(result.Agents) + 1,
+				AgentType: agentType,
+				Role:      a.Role,
+				Model:     opts.Models.GetModelName(agentType, ""),
+				Tasks:     []string{},
+			})
+			spawnOpts.Agents = append(spawnOpts.Agents, FlatAgent{Type: AgentType(agentType), Index: i + 1})
+		}
+	}
+	if len(result.Agents) == 0 {
+		return nil, fmt.Errorf("template %q defines no agents", tmpl.Name)
+	}
+	recomputeSpawnAgentCounts(&spawnOpts)
+
+	spawnOpts.StaggerMode = opts.StaggerMode
+	spawnOpts.StaggerDelay = opts.StaggerDelay
+	var interval time.Duration
+	if opts.StaggerMode != "" && opts.StaggerMode != "none" {
+		if opts.StaggerMode == "smart" && opts.RateLimits == nil {
+			opts.RateLimits = ratelimit.NewRateLimitTracker("")
+		}
+		interval = resolveStaggerInterval(opts.StaggerMode, spawnOpts, opts.RateLimits)
+	}
+	result.StaggerInterval = interval.String()
+
+	// Assign in rounds: strategies other than balanced give each agent at
+	// most one task per call, so repeat until nothing more is placed.
+	byID := make(map[string]SimulateTask, len(tasks))
+	beads := make([]assign.Bead, 0, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+		taskType := t.Type
+		if taskType == "" {
+			taskType = inferTaskTypeFromBead(bv.BeadPreview{Title: t.Title})
+		}
+		beads = append(beads, assign.Bead{ID: t.ID, Title: t.Title, Priority: t.Priority, TaskType: assign.ParseTaskType(taskType), Labels: t.Labels})
+	}
+	agents := make([]assign.Agent, len(result.Agents))
+	for i, a := range result.Agents {
+		agents[i] = assign.Agent{ID: fmt.Sprintf("%d", a.Pane), AgentType: assign.ParseAgentType(a.AgentType), Model: a.Model, Idle: true}
+	}
+	matcher := assign.NewMatcher()
+	remaining := beads
+	for len(remaining) > 0 {
+		placed := map[string]bool{}
+		for _, as := range matcher.AssignTasks(remaining, agents, strategy) {
+			if placed[as.Bead.ID] {
+				continue
+			}
+			placed[as.Bead.ID] = true
+			for i := range agents {
+				if agents[i].ID == as.Agent.ID {
+					agents[i].Assignments++
+					result.Agents[i].Tasks = append(result.Agents[i].Tasks, as.Bead.ID)
+				}
+			}
+		}
+		if len(placed) == 0 {
+			break
+		}
+		var next []assign.Bead
+		for _, b := range remaining {
+			if !placed[b.ID] {
+				next = append(next, b)
+			}
+		}
+		remaining = next
+	}
+	for _, b := range remaining {
+		result.Unassigned = append(result.Unassigned, b.ID)
+	}
+	if len(result.Unassigned) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%d task(s) matched no agent above the confidence threshold", len(result.Unassigned)))
+	}
+
+	// Lay out the timeline: every pane spawns at once, first prompts are
+	// staggered, and each later task is sent when the previous one ends.
+	type window struct {
+		task       SimulateTask
+		pane       int
+		from, till time.Duration
+		files      []string
+	}
+	var windows []window
+	var end time.Duration
+	for i := range result.Agents {
+		a := &result.Agents[i]
+		result.Timeline = append(result.Timeline, SimulateEvent{Kind: SimEventSpawn, Pane: a.Pane, AgentType: a.AgentType, Detail: a.Model})
+		at := time.Duration(i) * interval
+		pricing := cost.GetModelPricing(a.Model)
+		for _, id := range a.Tasks {
+			t := byID[id]
+			prompt := t.Prompt
+			if prompt == "" {
+				prompt = strings.TrimSpace(t.Title + "\n\n" + t.Description)
+			}
+			in := cost.EstimateTokens(prompt)
+			out := t.OutputTokens
+			if out <= 0 {
+				out = opts.OutputTokens
+			}
+			usd := float64(in)/1000*pricing.InputPer1K + float64(out)/1000*pricing.OutputPer1K
+			a.CostUSD += usd
+			result.Cost.InputTokens += in
+			result.Cost.OutputTokens += out
+			result.Cost.EstimatedUSD += usd
+
+			d := opts.TaskDuration
+			if t.Minutes > 0 {
+				d = time.Duration(t.Minutes) * time.Minute
+			}
+			result.Timeline = append(result.Timeline,
+				SimulateEvent{Offset: at, Kind: SimEventSend, Pane: a.Pane, AgentType: a.AgentType, TaskID: id, Detail: t.Title},
+				SimulateEvent{Offset: at + d, Kind: SimEventComplete, Pane: a.Pane, AgentType: a.AgentType, TaskID: id})
+			files := append(append([]string{}, t.Files...), assign.ExtractFilePaths(t.Title, t.Description)...)
+			windows = append(windows, window{task: t, pane: a.Pane, from: at, till: at + d, files: files})
+			at += d
+		}
+		end = max(end, at)
+	}
+	sort.SliceStable(result.Timeline, func(i, j int) bool {
+		if result.Timeline[i].Offset != result.Timeline[j].Offset {
+			return result.Timeline[i].Offset < result.Timeline[j].Offset
+		}
+		// Spawns come first: every pane is created before any prompt.
+		if si, sj := result.Timeline[i].Kind == SimEventSpawn, result.Timeline[j].Kind == SimEventSpawn; si != sj {
+			return si
+		}
+		return result.Timeline[i].Pane < result.Timeline[j].Pane
+	})
+	for i := range result.Timeline {
+		e := &result.Timeline[i]
+		e.At = formatSimulateOffset(e.Offset)
+		e.AtSeconds = e.Offset.Seconds()
+	}
+	result.Duration = end.Round(time.Second).String()
+
+	// Predict conflicts between tasks on different panes whose files overlap
+	// while both are in progress.
+	for i := 0; i < len(windows); i++ {
+		for j := i + 1; j < len(windows); j++ {
+			a, b := windows[i], windows[j]
+			if a.pane == b.pane || a.from >= b.till || b.from >= a.till {
+				continue
+			}
+			if path, ok := firstOverlap(a.files, b.files); ok {
+				if b.from < a.from {
+					a, b = b, a
+				}
+				result.Conflicts = append(result.Conflicts, SimulatedConflict{
+					Path:  path,
+					Tasks: [2]string{a.task.ID, b.task.ID},
+					Panes: [2]int{a.pane, b.pane},
+					From:  formatSimulateOffset(max(a.from, b.from)),
+					Until: formatSimulateOffset(min(a.till, b.till)),
+				})
+			}
+		}
+	}
+
+	if opts.BudgetUSD > 0 {
+		guard := cost.NewBudgetGuard()
+		if err := guard.SetLimits(tmpl.Name, 0, opts.BudgetUSD, tmpl.Name); err != nil {
+			return nil, err
+		}
+		check := guard.Check(tmpl.Name, result.Cost.EstimatedUSD)
+		result.Cost.BudgetUSD = opts.BudgetUSD
+		result.Cost.Level = check.Level
+		if check.Level == cost.BudgetHardStop {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("estimated cost %s reaches the %s budget; sends would pause",
+				cost.FormatCost(result.Cost.EstimatedUSD), cost.FormatCost(opts.BudgetUSD)))
+		}
+	}
+	return result, nil
+}
+
+func firstOverlap(a, b []string) (string, bool) {
+	for _, x := range a {
+		for _, y := range b {
+			if agentmail.PatternsOverlap(x, y) {
+				if strings.ContainsAny(x, "*?[") {
+					return y, true
+				}
+				return x, true
+			}
+		}
+	}
+	return "", false
+}
+
+func formatSimulateOffset(d time.Duration) string {
+	return "+" + d.Round(time.Second).String()
+}
+
+func printSimulateResult(w io.Writer, r *SimulateResult) error {
+	fmt.Fprintf(w, "Simulation of template %s (%s assignment, stagger %s %s)\n\n", r.Template, r.Strategy, r.StaggerMode, r.StaggerInterval)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Pane\tAgent\tRole\tModel\tTasks\tCost")
+	for _, a := range r.Agents {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\n", a.Pane, a.AgentType, a.Role, a.Model, len(a.Tasks), cost.FormatCost(a.CostUSD))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nTimeline:")
+	for _, e := range r.Timeline {
+		line := fmt.Sprintf("  %-10s %-9s pane %d (%s)", e.At, e.Kind, e.Pane, e.AgentType)
+		if e.TaskID != "" {
+			line += "  " + e.TaskID
+		}
+		if e.Detail != "" && e.Kind != SimEventSpawn {
+			line += "  " + e.Detail
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintf(w, "\nExpected duration: %s\n", r.Duration)
+	fmt.Fprintf(w, "Expected cost: %s (%d input + %d output tokens)", cost.FormatCost(r.Cost.EstimatedUSD), r.Cost.InputTokens, r.Cost.OutputTokens)
+	if r.Cost.BudgetUSD > 0 {
+		fmt.Fprintf(w, " against budget %s [%s]", cost.FormatCost(r.Cost.BudgetUSD), r.Cost.Level)
+	}
+	fmt.Fprintln(w)
+
+	if len(r.Conflicts) == 0 {
+		fmt.Fprintln(w, "Predicted conflicts: none")
+	} else {
+		fmt.Fprintf(w, "Predicted conflicts (%d):\n", len(r.Conflicts))
+		for _, c := range r.Conflicts {
+			fmt.Fprintf(w, "  %s: %s (pane %d) and %s (pane %d), %s to %s\n", c.Path, c.Tasks[0], c.Panes[0], c.Tasks[1], c.Panes[1], c.From, c.Until)
+		}
+	}
+	if len(r.Unassigned) > 0 {
+		fmt.Fprintf(w, "Unassigned: %s\n", strings.Join(r.Unassigned, ", "))
+	}
+	for _, warn := range r.Warnings {
+		fmt.Fprintf(w, "⚠ %s\n", warn)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/workflow"
+)
+
+func simulateTestTemplate() *workflow.WorkflowTemplate {
+	return &workflow.WorkflowTemplate{
+		Name:         "pair",
+		Coordination: workflow.CoordParallel,
+		Agents: []workflow.WorkflowAgent{
+			{Profile: "claude", Role: "lead"},
+			{Profile: "codex", Role: "impl"},
+		},
+	}
+}
+
+func TestSimulateFleet(t *testing.T) {
+	tasks := []SimulateTask{
+		{ID: "t1", Title: "Fix login bug in internal/auth/login.go", Priority: 0},
+		{ID: "t2", Title: "Add rate limiting", Priority: 1, Files: []string{"internal/auth/*.go"}},
+		{ID: "t3", Title: "Document the API", Priority: 2, Minutes: 5},
+	}
+	opts := SimulateOptions{
+		Strategy:     "balanced",
+		StaggerMode:  "fixed",
+		StaggerDelay: 30 * time.Second,
+		TaskDuration: 10 * time.Minute,
+		OutputTokens: 1000,
+		Models:       config.DefaultModels(),
+	}
+
+	r, err := simulateFleet(simulateTestTemplate(), tasks, opts)
+	if err != nil {
+		t.Fatalf("simulateFleet: %v", err)
+	}
+	if len(r.Agents) != 2 || r.Agents[0].AgentType != "cc" || r.Agents[1].AgentType != "cod" {
+		t.Fatalf("agents = %+v", r.Agents)
+	}
+	if r.StaggerInterval != "30s" {
+		t.Errorf("stagger interval = %s, want 30s", r.StaggerInterval)
+	}
+	if len(r.Unassigned) != 0 {
+		t.Errorf("unassigned = %v", r.Unassigned)
+	}
+	assigned := len(r.Agents[0].Tasks) + len(r.Agents[1].Tasks)
+	if assigned != 3 {
+		t.Fatalf("assigned %d tasks, want 3", assigned)
+	}
+
+	var spawns, sends int
+	for i, e := range r.Timeline {
+		if i > 0 && e.Offset < r.Timeline[i-1].Offset {
+			t.Fatalf("timeline not ordered at %d: %+v", i, r.Timeline)
+		}
+		switch e.Kind {
+		case SimEventSpawn:
+			spawns++
+			if e.Offset != 0 {
+				t.Errorf("spawn at %s, want +0s", e.At)
+			}
+		case SimEventSend:
+			sends++
+			if e.Pane == 2 && e.Offset < 30*time.Second {
+				t.Errorf("pane 2 send at %s before the stagger interval", e.At)
+			}
+		}
+	}
+	if spawns != 2 || sends != 3 {
+		t.Errorf("spawns=%d sends=%d, want 2 and 3", spawns, sends)
+	}
+
+	if r.Cost.OutputTokens != 3000 || r.Cost.InputTokens == 0 || r.Cost.EstimatedUSD <= 0 {
+		t.Errorf("cost = %+v", r.Cost)
+	}
+
+	if len(r.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want 1", r.Conflicts)
+	}
+	c := r.Conflicts[0]
+	pair := c.Tasks == [2]string{"t1", "t2"} || c.Tasks == [2]string{"t2", "t1"}
+	if c.Path != "internal/auth/login.go" || !pair || c.Panes[0] == c.Panes[1] || c.From != "+30s" {
+		t.Errorf("conflict = %+v", c)
+	}
+}
+
+func TestSimulateFleetBudget(t *testing.T) {
+	tasks := []SimulateTask{{ID: "t1", Title: "Implement feature", OutputTokens: 100000}}
+	r, err := simulateFleet(simulateTestTemplate(), tasks, SimulateOptions{
+		StaggerMode: "none",
+		BudgetUSD:   0.01,
+		Models:      config.DefaultModels(),
+	})
+	if err != nil {
+		t.Fatalf("simulateFleet: %v", err)
+	}
+	if r.Cost.Level != cost.BudgetHardStop {
+		t.Errorf("level = %s, want %s", r.Cost.Level, cost.BudgetHardStop)
+	}
+	if len(r.Warnings) == 0 || !strings.Contains(r.Warnings[0], "budget") {
+		t.Errorf("warnings = %v", r.Warnings)
+	}
+	if r.StaggerInterval != "0s" {
+		t.Errorf("stagger interval = %s, want 0s", r.StaggerInterval)
+	}
+}
+
+func TestLoadSimulateTasks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tasks, err := loadSimulateTasks(write("array.json", `[{"title":"a"},{"id":"x","title":"b"}]`))
+	if err != nil {
+		t.Fatalf("array: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != "task-1" || tasks[1].ID != "x" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+
+	tasks, err = loadSimulateTasks(write("wrapped.json", `{"tasks":[{"id":"a","title":"a"}]}`))
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("wrapped: tasks=%+v err=%v", tasks, err)
+	}
+
+	if _, err := loadSimulateTasks(write("dup.json", `[{"id":"a"},{"id":"a"}]`)); err == nil {
+		t.Error("expected duplicate id error")
+	}
+	if _, err := loadSimulateTasks(write("empty.json", `[]`)); err == nil {
+		t.Error("expected empty tasks error")
+	}
+}
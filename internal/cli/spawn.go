@@ -27,6 +27,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
 	"github.com/Dicklesworthstone/ntm/internal/cm"
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/gemini"
 	"github.com/Dicklesworthstone/ntm/internal/handoff"
@@ -39,8 +40,10 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/recipe"
 	"github.com/Dicklesworthstone/ntm/internal/resilience"
 	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/templates"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/webhook"
+	"github.com/Dicklesworthstone/ntm/internal/winddown"
 	"github.com/Dicklesworthstone/ntm/internal/workflow"
 	"github.com/Dicklesworthstone/ntm/internal/worktrees"
 )
@@ -326,11 +329,113 @@ func resolveStaggerInterval(mode string, opts SpawnOptions, tracker *ratelimit.R
 	return interval
 }
 
-func codexCooldownRemaining(tracker *ratelimit.RateLimitTracker, alreadyWaited bool) (time.Duration, bool) {
+// codexCooldownRemaining returns the cooldown to wait before launching a
+// Codex pane on key (see ratelimit.AccountKey). It waits at most once per
+// spawn.
+func codexCooldownRemaining(tracker *ratelimit.RateLimitTracker, key string, alreadyWaited bool) (time.Duration, bool) {
 	if tracker == nil || alreadyWaited {
 		return 0, alreadyWaited
 	}
-	return tracker.CooldownRemaining("openai"), true
+	return tracker.CooldownRemaining(key), true
+}
+
+// rateLimitSchedule compiles [[resilience.rate_limit.schedule]] together
+// with the rules tightening providers near their [billing] spend cap.
+// Invalid rules are reported by config validation, so they are ignored here.
+func rateLimitSchedule() *ratelimit.Schedule {
+	if cfg == nil {
+		return nil
+	}
+	rules, err := cfg.Resilience.RateLimit.ScheduleRules()
+	if err != nil {
+		slog.Default().Debug("ignoring invalid rate limit schedule", "error", err)
+		rules = nil
+	}
+	rules = append(rules, spendCapRules(time.Now())...)
+	if len(rules) == 0 {
+		return nil
+	}
+	schedule, err := ratelimit.NewSchedule(rules)
+	if err != nil {
+		slog.Default().Debug("ignoring invalid rate limit schedule", "error", err)
+		return nil
+	}
+	return schedule
+}
+
+// spawnAgentTypes returns the distinct agent types a spawn launches.
+func spawnAgentTypes(opts SpawnOptions) []AgentType {
+	var types []AgentType
+	if len(opts.Agents) > 0 {
+		seen := make(map[AgentType]bool)
+		for _, a := range opts.Agents {
+			if !seen[a.Type] {
+				seen[a.Type] = true
+				types = append(types, a.Type)
+			}
+		}
+		return types
+	}
+	for _, c := range []struct {
+		t AgentType
+		n int
+	}{
+		{AgentTypeClaude, opts.CCCount},
+		{AgentTypeCodex, opts.CodCount},
+		{AgentTypeGemini, opts.GmiCount},
+		{AgentTypeCursor, opts.CursorCount},
+		{AgentTypeWindsurf, opts.WindsurfCount},
+		{AgentTypeAider, opts.AiderCount},
+	} {
+		if c.n > 0 {
+			types = append(types, c.t)
+		}
+	}
+	return types
+}
+
+// scheduledSpawnPause returns an error if a schedule rule pauses spawns for
+// any of the agent types at now.
+func scheduledSpawnPause(schedule *ratelimit.Schedule, agentTypes []AgentType, now time.Time) error {
+	for _, agentType := range agentTypes {
+		rules, until, paused := schedule.PausedUntil(string(agentType), now)
+		if !paused {
+			continue
+		}
+		return fmt.Errorf("%s spawns are paused by rate limit schedule %s until %s (use --ignore-schedule to override)",
+			ratelimit.NormalizeProvider(string(agentType)), strings.Join(rules, ", "), until.Local().Format("Mon 15:04"))
+	}
+	return nil
+}
+
+// startInternalMonitor launches a detached `ntm internal-monitor` for
+// session, which reads the resilience manifest saved for it. Output goes to
+// the session's monitor log.
+func startInternalMonitor(session string) (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(exe, "internal-monitor", session)
+
+	// Setup logging
+	logDir := resilience.LogDir()
+	if err := os.MkdirAll(logDir, 0755); err == nil {
+		logPath := filepath.Join(logDir, fmt.Sprintf("%s-monitor.log", session))
+		if logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			// The child has its own copy once started
+			defer logFile.Close()
+			cmd.Stdout = logFile
+			cmd.Stderr = logFile
+		}
+	}
+
+	// Detach from terminal so it survives when the parent exits
+	setDetachedProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
 }
 
 func shouldStartInternalMonitor() bool {
@@ -387,6 +492,15 @@ type SpawnOptions struct {
 	// Safety mode: fail if session already exists
 	Safety bool
 
+	// IgnoreSchedule spawns even while a rate limit schedule rule pauses
+	// spawns for one of the agents' providers.
+	IgnoreSchedule bool
+
+	// SessionTemplate supplies the default budget and guards the session
+	// inherits. OverrideGuards spawns past its agent cap (audited).
+	SessionTemplate *templates.SessionTemplate
+	OverrideGuards  bool
+
 	// Stagger configuration for thundering herd prevention
 	// StaggerMode: "smart", "fixed", or "none" (default)
 	// - smart: Use learned optimal delays from RateLimitTracker
@@ -554,6 +668,9 @@ func newSpawnCmd() *cobra.Command {
 	var staggerDuration time.Duration
 	var staggerEnabled bool
 	var safety bool
+	var ignoreSchedule bool
+	var sessionTemplateName string
+	var overrideGuards bool
 	var localCount int
 	var ollamaCount int
 	var localModel string
@@ -818,6 +935,33 @@ Examples:
 				}
 			}
 
+			// Handle session template: agent counts (CLI flags override these)
+			// plus the budget and guards the session inherits.
+			var sessionTmpl *templates.SessionTemplate
+			if sessionTemplateName != "" {
+				var err error
+				sessionTmpl, err = templates.NewSessionTemplateLoader().Load(sessionTemplateName)
+				if err != nil {
+					return err
+				}
+				agents := sessionTmpl.Spec.Agents
+				for _, a := range []struct {
+					typ  AgentType
+					spec *templates.AgentTypeSpec
+				}{
+					{AgentTypeClaude, agents.Claude},
+					{AgentTypeCodex, agents.Codex},
+					{AgentTypeGemini, agents.Gemini},
+				} {
+					if a.spec != nil && a.spec.TotalCount() > 0 && agentSpecs.ByType(a.typ).TotalCount() == 0 {
+						agentSpecs = append(agentSpecs, AgentSpec{Type: a.typ, Count: a.spec.TotalCount(), Model: a.spec.Model})
+					}
+				}
+				if !IsJSONOutput() {
+					fmt.Printf("Using session template '%s': %s\n", sessionTmpl.Metadata.Name, sessionTmpl.Metadata.Description)
+				}
+			}
+
 			var err error
 			localModel, err = appendOllamaAgentSpecs(&agentSpecs, localCount, ollamaCount, localModel)
 			if err != nil {
@@ -940,6 +1084,9 @@ Examples:
 				NoHooks:               noHooks,
 				Agent:                 agentFlag,
 				Safety:                safety,
+				IgnoreSchedule:        ignoreSchedule,
+				SessionTemplate:       sessionTmpl,
+				OverrideGuards:        overrideGuards,
 				StaggerMode:           staggerMode,
 				StaggerDelay:          staggerDelay,
 				Stagger:               staggerDuration,
@@ -1017,6 +1164,9 @@ Examples:
 	cmd.Flags().StringVar(&initPrompt, "init-prompt", "", "Prompt to send after agents are ready (used with --assign)")
 	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Disable command hooks")
 	cmd.Flags().BoolVar(&safety, "safety", false, "Fail if session already exists (prevents accidental reuse)")
+	cmd.Flags().BoolVar(&ignoreSchedule, "ignore-schedule", false, "Spawn even during a rate limit schedule pause window")
+	cmd.Flags().StringVar(&sessionTemplateName, "session-template", "", "use a session template for agents, default budget and guards (see 'ntm session-templates list')")
+	cmd.Flags().BoolVar(&overrideGuards, "override-guards", false, "spawn past the session template's maxAgents (audited)")
 
 	// Assignment flags for spawn+assign workflow
 	cmd.Flags().BoolVar(&assignEnabled, "assign", false, "Auto-assign beads to spawned agents after ready")
@@ -1108,6 +1258,27 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 		totalAgents = len(opts.Agents)
 	}
 
+	if !opts.IgnoreSchedule {
+		if err := scheduledSpawnPause(rateLimitSchedule(), spawnAgentTypes(opts), time.Now()); err != nil {
+			return outputError(err)
+		}
+	}
+
+	if tmpl := opts.SessionTemplate; tmpl != nil && tmpl.Spec.Options.Budget != nil {
+		if max := tmpl.Spec.Options.Budget.MaxAgents; max > 0 && totalAgents > max {
+			violation := &cost.GuardViolationError{
+				Session:  opts.Session,
+				Template: tmpl.Metadata.Name,
+				Guard:    "agents",
+				Detail:   fmt.Sprintf("%d agents exceeds max %d", totalAgents, max),
+			}
+			if !opts.OverrideGuards {
+				return outputError(violation)
+			}
+			auditGuardOverride(opts.Session, "spawn", violation)
+		}
+	}
+
 	dir := cfg.GetProjectDir(opts.Session)
 	auditStart := time.Now()
 	auditSessionCreated := false
@@ -1206,6 +1377,29 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 		}
 	}
 
+	// Run pre-spawn exec hooks, which may rewrite the prompts or veto the spawn
+	if !opts.NoHooks {
+		out, err := runExecHooks(dir, hooks.ExecPreSpawn, opts.Session, map[string]interface{}{
+			"agents": map[string]int{
+				"cc":       opts.CCCount,
+				"cod":      opts.CodCount,
+				"gmi":      opts.GmiCount,
+				"cursor":   opts.CursorCount,
+				"windsurf": opts.WindsurfCount,
+				"aider":    opts.AiderCount,
+			},
+			"total_agents": totalAgents,
+			"prompt":       opts.Prompt,
+			"init_prompt":  opts.InitPrompt,
+			"recipe":       opts.RecipeName,
+		})
+		if err != nil {
+			return outputError(err)
+		}
+		opts.Prompt = out.String("prompt", opts.Prompt)
+		opts.InitPrompt = out.String("init_prompt", opts.InitPrompt)
+	}
+
 	// Check if directory exists
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if IsJSONOutput() {
@@ -1249,11 +1443,19 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 			return outputError(fmt.Errorf("creating session: %w", err))
 		}
 		auditSessionCreated = true
+		// A new session starts its runtime afresh.
+		_ = winddown.Remove(opts.Session)
 		if !IsJSONOutput() {
 			steps.Done()
 		}
 	}
 
+	if opts.SessionTemplate != nil {
+		if err := inheritTemplateBudget(opts.Session, opts.SessionTemplate, time.Now()); err != nil {
+			return outputError(fmt.Errorf("applying session template budget: %w", err))
+		}
+	}
+
 	getPanesWithRetry := func(session string, attempts int, delay time.Duration) ([]tmux.Pane, error) {
 		var lastErr error
 		for i := 0; i < attempts; i++ {
@@ -1380,6 +1582,7 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 		resolvedModel string // full name
 		command       string
 		promptDelay   time.Duration // Stagger delay before prompt delivery
+		account       string        // Pool account from [accounts], if any
 	}
 	var launchedAgents []launchedAgent
 
@@ -1405,13 +1608,14 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 			}
 		}
 	}
-	if opts.StaggerMode == "smart" || hasCodex {
+	if opts.StaggerMode == "smart" || hasCodex || cfg.Accounts.RoundRobin {
 		rateLimitTracker = ratelimit.NewRateLimitTracker(dir)
 		if err := rateLimitTracker.LoadFromDir(dir); err != nil {
 			if !IsJSONOutput() {
 				output.PrintWarningf("Failed to load rate limit history: %v", err)
 			}
 		}
+		rateLimitTracker.SetSchedule(rateLimitSchedule())
 	}
 
 	// Determine effective stagger mode (new mode takes precedence over legacy)
@@ -1465,6 +1669,19 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 		}
 	}
 
+	// Load the project memory (.ntm/memory) injected into each agent
+	var projectMemory *cm.Memory
+	if cfg.Memory.Enabled && cfg.Memory.InjectOnSpawn {
+		mem, err := cm.LoadMemory(dir)
+		if err != nil {
+			if !IsJSONOutput() {
+				fmt.Printf("⚠ Warning: failed to load project memory: %v\n", err)
+			}
+		} else if !mem.Empty() {
+			projectMemory = mem
+		}
+	}
+
 	// Launch agents using flattened specs (preserves model info for pane naming)
 	for _, agent := range opts.Agents {
 		if agentNum >= len(panes) {
@@ -1664,6 +1881,26 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 			return outputError(fmt.Errorf("generating command for %s agent: %w", agent.Type, err))
 		}
 
+		// Apply [spawn_env] injection; secrets travel in a self-deleting file
+		paneAgentName := fmt.Sprintf("%s_%d", strings.ToLower(string(agent.Type)), agent.Index)
+		account, accountWait := pickPaneAccount(rateLimitTracker, string(agent.Type), agent.Index)
+		if account != nil && !IsJSONOutput() {
+			output.PrintInfof("Assigning account %s to %s", account.Name(), paneAgentName)
+			if accountWait > 0 {
+				output.PrintWarningf("All %s accounts are cooling down; %s frees up in %s", agent.Type, account.Name(), ratelimit.FormatDelay(accountWait))
+			}
+		}
+		injected, err := preparePaneEnv(opts.Session, string(agent.Type), paneAgentName, account)
+		if err != nil {
+			return outputError(fmt.Errorf("preparing environment for %s: %w", paneAgentName, err))
+		}
+		if injected != nil {
+			agentCmd = injected.Prefix + agentCmd
+			if !IsJSONOutput() {
+				output.PrintInfof("Injecting %d env var(s) into %s (%d secret)", len(injected.Plain)+len(injected.Secrets), paneAgentName, len(injected.Secrets))
+			}
+		}
+
 		// Apply plugin env vars if any
 		if len(envVars) > 0 {
 			var envPrefix string
@@ -1688,21 +1925,21 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 
 		safeAgentCmd, err := tmux.SanitizePaneCommand(agentCmd)
 		if err != nil {
+			injected.discard()
 			return outputError(fmt.Errorf("invalid %s agent command: %w", agent.Type, err))
 		}
 
 		// Use worktree directory if worktree isolation is enabled
 		workingDir := dir
 		if opts.UseWorktrees && worktreeManager != nil {
-			agentName := fmt.Sprintf("%s_%d", strings.ToLower(string(agent.Type)), agent.Index)
-			if wtInfo, err := worktreeManager.GetWorktreeForAgent(agentName); err == nil && wtInfo.Created && wtInfo.Error == "" {
+			if wtInfo, err := worktreeManager.GetWorktreeForAgent(paneAgentName); err == nil && wtInfo.Created && wtInfo.Error == "" {
 				workingDir = wtInfo.Path
 			}
 		}
 
 		if agent.Type == AgentTypeCodex {
 			var cooldown time.Duration
-			cooldown, openAICooldownWaited = codexCooldownRemaining(rateLimitTracker, openAICooldownWaited)
+			cooldown, openAICooldownWaited = codexCooldownRemaining(rateLimitTracker, ratelimit.AccountKey("openai", accountName(account)), openAICooldownWaited)
 			if cooldown > 0 {
 				if !IsJSONOutput() {
 					output.PrintWarningf("Codex cooldown active; waiting %s before launching", ratelimit.FormatDelay(cooldown))
@@ -1713,14 +1950,21 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 
 		cmd, err := tmux.BuildPaneCommand(workingDir, safeAgentCmd)
 		if err != nil {
+			injected.discard()
 			return outputError(fmt.Errorf("building %s agent command: %w", agent.Type, err))
 		}
 
-		if err := tmux.SendKeys(pane.ID, cmd, true); err != nil {
+		if err := tmux.SendKeys(pane.ID, injected.wrap(cmd), true); err != nil {
+			injected.discard()
 			return outputError(fmt.Errorf("launching %s agent: %w", agent.Type, err))
 		}
+		if account != nil {
+			if err := tmux.SetPaneOption(pane.ID, tmux.PaneAccountOption, account.Name()); err != nil && !IsJSONOutput() {
+				output.PrintWarningf("Failed to record account for %s: %v", paneAgentName, err)
+			}
+		}
 		if rateLimitTracker != nil && agent.Type == AgentTypeCodex {
-			rateLimitTracker.RecordSuccess("openai")
+			rateLimitTracker.RecordSuccess(ratelimit.AccountKey("openai", accountName(account)))
 			if err := rateLimitTracker.SaveToDir(dir); err != nil && !IsJSONOutput() {
 				output.PrintWarningf("Failed to persist rate limit history: %v", err)
 			}
@@ -1734,6 +1978,7 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 		pID := pane.ID
 		pTitle := title
 		idx := agent.Index
+		pIndex := pane.Index
 
 		go func(paneID, paneTitle string, idx int, agentType AgentType, agent FlatAgent) {
 			defer setupWg.Done()
@@ -1815,6 +2060,16 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 				}
 			}
 
+			// Inject the project memory sections selected for this agent type
+			if projectMemory != nil {
+				time.Sleep(300 * time.Millisecond)
+				memPane := tmux.Pane{ID: paneID, Index: pIndex, Title: paneTitle, Type: tmux.AgentType(agentType)}
+				kinds := memorySectionsFor(cfg.Memory, string(agentType))
+				if _, err := deliverProjectMemory(opts.Session, dir, projectMemory, memPane, kinds); err != nil && !IsJSONOutput() {
+					fmt.Printf("⚠ Warning: failed to inject project memory for agent %d: %v\n", idx, err)
+				}
+			}
+
 			// Send user prompt (Staggered or Immediate)
 			if hasPrompt {
 				// Combine CASS context with user prompt if not sent yet
@@ -1884,6 +2139,7 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 			resolvedModel: resolvedModel,
 			command:       safeAgentCmd,
 			promptDelay:   promptDelay,
+			account:       accountName(account),
 		})
 		auditAgentsLaunched = len(launchedAgents)
 
@@ -1930,36 +2186,15 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 			}
 		} else {
 			// Launch monitor in background
-			exe, err := os.Executable()
-			if err == nil {
-				cmd := exec.Command(exe, "internal-monitor", opts.Session)
-
-				// Setup logging
-				logDir := resilience.LogDir()
-				if err := os.MkdirAll(logDir, 0755); err == nil {
-					logPath := filepath.Join(logDir, fmt.Sprintf("%s-monitor.log", opts.Session))
-					if logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
-						// Ensure file is closed after spawn
-						defer logFile.Close()
-						cmd.Stdout = logFile
-						cmd.Stderr = logFile
-					}
+			if cmd, err := startInternalMonitor(opts.Session); err != nil {
+				if !IsJSONOutput() {
+					output.PrintWarningf("Failed to start session monitor: %v", err)
 				}
-
-				// Detach from terminal so it survives when ntm spawn exits
-				setDetachedProcess(cmd)
-				if err := cmd.Start(); err != nil {
-					if !IsJSONOutput() {
-						output.PrintWarningf("Failed to start session monitor: %v", err)
-					}
+			} else if !IsJSONOutput() {
+				if manifest.AutoRestart {
+					output.PrintInfof("Session monitor started (auto-restart enabled, pid: %d)", cmd.Process.Pid)
 				} else {
-					if !IsJSONOutput() {
-						if manifest.AutoRestart {
-							output.PrintInfof("Session monitor started (auto-restart enabled, pid: %d)", cmd.Process.Pid)
-						} else {
-							output.PrintInfof("Session monitor started (pid: %d)", cmd.Process.Pid)
-						}
-					}
+					output.PrintInfof("Session monitor started (pid: %d)", cmd.Process.Pid)
 				}
 			}
 		}
@@ -2082,8 +2317,10 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 	if IsJSONOutput() {
 		// Build map of pane index -> stagger delay for lookup
 		paneDelays := make(map[int]time.Duration)
+		paneAccounts := make(map[int]string)
 		for _, agent := range launchedAgents {
 			paneDelays[agent.paneIndex] = agent.promptDelay
+			paneAccounts[agent.paneIndex] = agent.account
 		}
 
 		paneResponses := make([]output.PaneResponse, len(finalPanes))
@@ -2099,6 +2336,7 @@ func spawnSessionLogic(opts SpawnOptions) (err error) {
 				Height:        p.Height,
 				Command:       p.Command,
 				PromptDelayMs: paneDelays[p.Index].Milliseconds(),
+				Account:       paneAccounts[p.Index],
 			}
 			switch p.Type {
 			case tmux.AgentClaude:
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// paneEnv is the [spawn_env] injection for one agent pane.
+type paneEnv struct {
+	// Prefix holds the plain variables as a KEY='value' command prefix.
+	Prefix string
+	// SecretFile is a 0600 script exporting the secret variables. The pane
+	// sources it and the script deletes itself, so secrets never appear in
+	// the typed command or in pane captures.
+	SecretFile string
+	Plain      []string
+	Secrets    []string
+}
+
+// pickPaneAccount assigns the index-th pane (1-based) of agentType an
+// account from its [accounts] pool, round-robin and skipping accounts in
+// cooldown. wait is non-zero when every account is cooling down. It returns
+// nil when no pool is configured.
+func pickPaneAccount(tracker *ratelimit.RateLimitTracker, agentType string, index int) (account *config.AccountEntry, wait time.Duration) {
+	if cfg == nil {
+		return nil, 0
+	}
+	pool := cfg.Accounts.Pool(agentType)
+	if len(pool) == 0 {
+		return nil, 0
+	}
+	names := make([]string, len(pool))
+	for i, acct := range pool {
+		names[i] = acct.Name()
+	}
+	name, wait := tracker.PickAccount(agentType, names, index-1)
+	for i := range pool {
+		if pool[i].Name() == name {
+			return &pool[i], wait
+		}
+	}
+	return nil, 0
+}
+
+// accountName returns the account's name, or "" for nil.
+func accountName(account *config.AccountEntry) string {
+	if account == nil {
+		return ""
+	}
+	return account.Name()
+}
+
+// preparePaneEnv resolves [spawn_env] (and the pool account's env, if any)
+// for an agent pane and registers its secrets with the redaction layer,
+// both in this process and on disk for other ntm processes. A nil result
+// means nothing is injected.
+func preparePaneEnv(session, agentType, agentName string, account *config.AccountEntry) (*paneEnv, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	spawnEnv := cfg.SpawnEnv
+	if account != nil {
+		spawnEnv = spawnEnv.WithAccount(*account)
+	}
+	env, err := spawnEnv.Resolve(context.Background(), session, agentType, agentName)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Vars) == 0 {
+		return nil, nil
+	}
+
+	pe := &paneEnv{Plain: env.Names(false), Secrets: env.Names(true)}
+	for _, name := range pe.Plain {
+		pe.Prefix += fmt.Sprintf("%s=%s ", name, tmux.ShellQuote(env.Vars[name]))
+	}
+	if len(pe.Secrets) == 0 {
+		return pe, nil
+	}
+
+	var fps []redaction.SecretFingerprint
+	for _, name := range pe.Secrets {
+		if fp, ok := redaction.RegisterSecret(name, env.Vars[name]); ok {
+			fps = append(fps, fp)
+		}
+	}
+	if err := redaction.SaveInjectedSecrets(redaction.InjectedSecretsDir(), session, fps); err != nil {
+		return nil, fmt.Errorf("registering secrets for redaction: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "ntm-env-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating secret env file: %w", err)
+	}
+	var b strings.Builder
+	for _, name := range pe.Secrets {
+		fmt.Fprintf(&b, "export %s=%s\n", name, tmux.ShellQuote(env.Vars[name]))
+	}
+	fmt.Fprintf(&b, "rm -f -- %s\n", tmux.ShellQuote(f.Name()))
+	_, werr := f.WriteString(b.String())
+	if cerr := f.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr != nil {
+		_ = os.Remove(f.Name())
+		return nil, fmt.Errorf("writing secret env file: %w", werr)
+	}
+	pe.SecretFile = f.Name()
+	return pe, nil
+}
+
+// wrap applies the injection to a pane command built by BuildPaneCommand.
+// Sourcing happens before the cd so the secret file is removed even when
+// the project directory is missing.
+func (pe *paneEnv) wrap(paneCmd string) string {
+	if pe == nil || pe.SecretFile == "" {
+		return paneCmd
+	}
+	return ". " + tmux.ShellQuote(pe.SecretFile) + " && " + paneCmd
+}
+
+// discard removes an unused secret file after a failed launch.
+func (pe *paneEnv) discard() {
+	if pe != nil && pe.SecretFile != "" {
+		_ = os.Remove(pe.SecretFile)
+	}
+}
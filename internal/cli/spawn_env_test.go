@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+)
+
+func TestPreparePaneEnv(t *testing.T) {
+	t.Setenv("NTM_INJECTED_SECRETS_DIR", t.TempDir())
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = config.Default()
+
+	secret := "acct-two-" + strings.Repeat("x", 16)
+	cfg.SpawnEnv = config.SpawnEnvConfig{
+		SpawnEnvSource: config.SpawnEnvSource{Vars: map[string]string{"LOG_LEVEL": "debug"}},
+		Agents: map[string]config.SpawnEnvSource{
+			"cc_2": {Vars: map[string]string{"WORK_KEY": secret}, Secret: []string{"WORK_KEY"}},
+		},
+	}
+
+	none, err := preparePaneEnv("proj", "cc", "cc_1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if none.SecretFile != "" || none.Prefix != "LOG_LEVEL='debug' " {
+		t.Errorf("cc_1 env = %+v", none)
+	}
+
+	pe, err := preparePaneEnv("proj", "cc", "cc_2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pe.discard()
+	if info, err := os.Stat(pe.SecretFile); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("secret file: %v %v", info, err)
+	}
+
+	// The typed command carries only the file path, never the secret.
+	paneCmd := pe.wrap("cd /tmp && " + pe.Prefix + "printenv WORK_KEY LOG_LEVEL")
+	if strings.Contains(paneCmd, secret) {
+		t.Fatalf("secret in pane command: %q", paneCmd)
+	}
+	out, err := exec.Command("sh", "-c", paneCmd).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != secret+"\ndebug\n" {
+		t.Errorf("pane env = %q", out)
+	}
+	if _, err := os.Stat(pe.SecretFile); !os.IsNotExist(err) {
+		t.Error("secret file not removed after sourcing")
+	}
+
+	// Captures containing the secret are redacted even in warn mode.
+	res := redaction.ScanAndRedact("key is "+secret, redaction.Config{Mode: redaction.ModeWarn})
+	if strings.Contains(res.Output, secret) {
+		t.Errorf("capture not redacted: %q", res.Output)
+	}
+}
+
+func TestPickPaneAccount(t *testing.T) {
+	t.Setenv("NTM_INJECTED_SECRETS_DIR", t.TempDir())
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = config.Default()
+	cfg.Accounts.Codex = []config.AccountEntry{
+		{Alias: "personal", Priority: 2},
+		{Alias: "work", Priority: 1, Env: config.SpawnEnvSource{
+			Vars:   map[string]string{"OPENAI_API_KEY": "sk-work-" + strings.Repeat("w", 12)},
+			Secret: []string{"OPENAI_API_KEY"},
+		}},
+	}
+
+	if acct, _ := pickPaneAccount(nil, "cod", 1); acct != nil {
+		t.Fatalf("pool used without round_robin: %+v", acct)
+	}
+	cfg.Accounts.RoundRobin = true
+
+	tracker := ratelimit.NewRateLimitTracker("")
+	var got []string
+	for i := 1; i <= 3; i++ {
+		acct, _ := pickPaneAccount(tracker, "cod", i)
+		got = append(got, accountName(acct))
+	}
+	if strings.Join(got, ",") != "work,personal,work" {
+		t.Errorf("round-robin = %v", got)
+	}
+
+	// An exhausted account is skipped; the other keeps taking panes.
+	tracker.RecordRateLimitWithCooldown(ratelimit.AccountKey("cod", "work"), "send", 60)
+	if acct, wait := pickPaneAccount(tracker, "cod", 3); accountName(acct) != "personal" || wait != 0 {
+		t.Errorf("cooling account not skipped: %v %v", accountName(acct), wait)
+	}
+	if acct, _ := pickPaneAccount(tracker, "cc", 1); acct != nil {
+		t.Errorf("cc has no pool, got %+v", acct)
+	}
+
+	work, _ := pickPaneAccount(nil, "cod", 1)
+	pe, err := preparePaneEnv("proj", "cod", "cod_1", work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pe.discard()
+	if pe.Prefix != "NTM_ACCOUNT='work' " || strings.Join(pe.Secrets, ",") != "OPENAI_API_KEY" {
+		t.Errorf("account env = %+v", pe)
+	}
+}
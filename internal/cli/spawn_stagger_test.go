@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -505,7 +506,7 @@ func TestCodexCooldownRemaining_Once(t *testing.T) {
 	tracker := ratelimit.NewRateLimitTracker("")
 	tracker.RecordRateLimitWithCooldown("openai", "spawn", 30)
 
-	cooldown, waited := codexCooldownRemaining(tracker, false)
+	cooldown, waited := codexCooldownRemaining(tracker, "openai", false)
 	if cooldown <= 0 {
 		t.Fatalf("expected positive cooldown, got %v", cooldown)
 	}
@@ -513,7 +514,7 @@ func TestCodexCooldownRemaining_Once(t *testing.T) {
 		t.Fatal("expected waited=true after first check")
 	}
 
-	cooldown, waited = codexCooldownRemaining(tracker, waited)
+	cooldown, waited = codexCooldownRemaining(tracker, "openai", waited)
 	if cooldown != 0 {
 		t.Fatalf("expected cooldown=0 after already waited, got %v", cooldown)
 	}
@@ -521,3 +522,31 @@ func TestCodexCooldownRemaining_Once(t *testing.T) {
 		t.Fatal("expected waited to remain true")
 	}
 }
+
+func TestScheduledSpawnPause(t *testing.T) {
+	schedule, err := ratelimit.NewSchedule([]ratelimit.ScheduleRule{
+		{Name: "openai-quiet", Providers: []string{"openai"}, Days: "mon-fri", Hours: "9-17", Pause: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	monday10 := time.Date(2026, 10, 12, 10, 0, 0, 0, time.Local)
+
+	types := spawnAgentTypes(SpawnOptions{CCCount: 2, CodCount: 1})
+	if len(types) != 2 || types[0] != AgentTypeClaude || types[1] != AgentTypeCodex {
+		t.Fatalf("spawnAgentTypes = %v", types)
+	}
+	err = scheduledSpawnPause(schedule, types, monday10)
+	if err == nil || !strings.Contains(err.Error(), "openai-quiet") || !strings.Contains(err.Error(), "--ignore-schedule") {
+		t.Errorf("err = %v", err)
+	}
+	if err := scheduledSpawnPause(schedule, []AgentType{AgentTypeClaude}, monday10); err != nil {
+		t.Errorf("claude paused: %v", err)
+	}
+	if err := scheduledSpawnPause(schedule, types, monday10.Add(8*time.Hour)); err != nil {
+		t.Errorf("paused outside window: %v", err)
+	}
+	if err := scheduledSpawnPause(nil, types, monday10); err != nil {
+		t.Errorf("nil schedule: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/warehouse"
+)
+
+func newSQLCmd() *cobra.Command {
+	var (
+		format     string
+		schema     bool
+		syncOnly   bool
+		noSync     bool
+		dbPath     string
+		timeoutSec int
+	)
+
+	cmd := &cobra.Command{
+		Use:   `sql ["SELECT ..."]`,
+		Short: "Query costs, scores, conflicts, rate limits and audit summaries with SQL",
+		Long: `Run ad-hoc SQL against the analytics warehouse.
+
+The warehouse (~/.config/ntm/analytics/analytics.db) is a SQLite database
+built from ntm's own stores:
+
+  costs        token usage and spend per pane (current project's .ntm/costs.json)
+  scores       agent effectiveness scores
+  conflicts    file conflict episodes, detection to resolution
+  rate_limits  agent rate-limit events from the event journal
+  audit_daily  audit log entry counts per day, session, event type and actor
+  sync_state   how far each source has been ingested
+
+Before each query the warehouse is brought up to date incrementally: only
+records newer than the last sync are read. Queries run on a read-only
+connection. Use --schema for the documented column list, or open the file
+with any SQLite client.
+
+Examples:
+  ntm sql "SELECT session, ROUND(SUM(cost_usd), 2) AS usd FROM costs GROUP BY session"
+  ntm sql "SELECT agent_type, AVG(overall) FROM scores GROUP BY agent_type"
+  ntm sql "SELECT path, COUNT(*) n FROM conflicts GROUP BY path ORDER BY n DESC LIMIT 10"
+  ntm sql "SELECT date(timestamp) d, COUNT(*) FROM rate_limits GROUP BY d" --format csv
+  ntm sql --schema
+  ntm sql --sync`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if schema {
+				fmt.Print(warehouse.Schema())
+				return nil
+			}
+			if len(args) == 0 && !syncOnly {
+				return fmt.Errorf("a query is required (or use --schema / --sync)")
+			}
+			if syncOnly && noSync {
+				return fmt.Errorf("--sync and --no-sync are mutually exclusive")
+			}
+			if IsJSONOutput() {
+				format = "json"
+			}
+			switch format {
+			case "table", "csv", "json":
+			default:
+				return fmt.Errorf("unsupported format %q (supported: table, csv, json)", format)
+			}
+
+			ctx := context.Background()
+			if timeoutSec > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(timeoutSec)*time.Second)
+				defer cancel()
+			}
+
+			w, err := warehouse.Open(dbPath)
+			if err != nil {
+				return err
+			}
+			defer w.Close()
+
+			if !noSync {
+				report := w.Sync(ctx, warehouse.Sources{ProjectDir: GetProjectRoot()})
+				if syncOnly {
+					return printSyncReport(report, format)
+				}
+				for _, s := range report.Sources {
+					if s.Error != "" {
+						fmt.Fprintf(os.Stderr, "warning: %s not synced: %s\n", s.Source, s.Error)
+					}
+				}
+			}
+
+			res, err := w.Query(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("query failed: %w", err)
+			}
+			return printSQLResult(res, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "table", "Output format: table, csv, json")
+	cmd.Flags().BoolVar(&schema, "schema", false, "Print the documented warehouse schema")
+	cmd.Flags().BoolVar(&syncOnly, "sync", false, "Only sync the warehouse, without querying")
+	cmd.Flags().BoolVar(&noSync, "no-sync", false, "Query without syncing first")
+	cmd.Flags().StringVar(&dbPath, "db", "", "Warehouse path (default: "+warehouse.DefaultPath+")")
+	cmd.Flags().IntVar(&timeoutSec, "timeout", 60, "Abort after this many seconds (0 = no limit)")
+	return cmd
+}
+
+func printSyncReport(report *warehouse.SyncReport, format string) error {
+	if format == "json" {
+		if err := output.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, s := range report.Sources {
+			status := fmt.Sprintf("%d rows", s.Rows)
+			switch {
+			case s.Error != "":
+				status = "error: " + s.Error
+			case s.Skipped:
+				status = "skipped"
+			}
+			fmt.Fprintf(w, "%s\t%s\n", s.Source, status)
+		}
+		_ = w.Flush()
+		fmt.Printf("Warehouse: %s\n", report.Path)
+	}
+	if failed := report.Failed(); len(failed) > 0 {
+		return fmt.Errorf("sync incomplete: %s failed", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func printSQLResult(res *warehouse.Result, format string) error {
+	switch format {
+	case "json":
+		rows := make([]map[string]any, 0, len(res.Rows))
+		for _, row := range res.Rows {
+			obj := make(map[string]any, len(res.Columns))
+			for i, col := range res.Columns {
+				obj[col] = row[i]
+			}
+			rows = append(rows, obj)
+		}
+		return output.PrintJSON(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		if err := w.Write(res.Columns); err != nil {
+			return err
+		}
+		for _, row := range res.Rows {
+			if err := w.Write(sqlRowStrings(row, "")); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(res.Columns, "\t"))
+		for _, row := range res.Rows {
+			fmt.Fprintln(w, strings.Join(sqlRowStrings(row, "NULL"), "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "(%d rows)\n", len(res.Rows))
+		return nil
+	}
+}
+
+// sqlRowStrings formats a result row, writing null for SQL NULLs.
+func sqlRowStrings(row []any, null string) []string {
+	out := make([]string, len(row))
+	for i, v := range row {
+		if v == nil {
+			out[i] = null
+			continue
+		}
+		out[i] = fmt.Sprint(v)
+	}
+	return out
+}
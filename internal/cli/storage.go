@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/storage"
+	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+)
+
+// storagePruneInterval is how often the session monitor enforces storage
+// quotas when auto_prune is enabled.
+const storagePruneInterval = time.Hour
+
+// storageReport is the output of `ntm storage status` and `ntm storage prune`.
+type storageReport struct {
+	Disk       storage.Disk             `json:"disk"`
+	MinFreeMB  int                      `json:"min_free_mb"`
+	DryRun     bool                     `json:"dry_run,omitempty"`
+	Categories []storage.CategoryStatus `json:"categories"`
+}
+
+func newStorageCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Report and limit disk use of archives, captures and audit logs",
+		Long: `Manage the disk space used by ntm's artifacts:
+
+  archives  pane output archives (~/.ntm/archive)
+  captures  checkpoint captures (~/.local/share/ntm/checkpoints)
+  audit     audit logs (~/.local/share/ntm/audit)
+
+Each category has a quota and retention rules in the [storage] config section.
+Pruning removes the oldest artifacts first: anything past max_age_days, then
+the oldest until the category fits quota_mb. Artifacts newer than keep_days
+are never pruned. With auto_prune, ntm monitor enforces the rules hourly.
+
+When free disk space drops below min_free_mb, archiving pauses and a
+storage.low_disk event is emitted; it resumes once space is freed.
+
+[storage.roots] moves a class (audit, archives, captures, state) to another
+directory; ntm storage migrate moves the data already written.
+
+Examples:
+  ntm storage status
+  ntm storage prune --dry-run
+  ntm storage prune --category archives
+  ntm storage migrate --dry-run`,
+	}
+	cmd.AddCommand(newStorageStatusCmd(), newStoragePruneCmd(), newStorageMigrateCmd())
+	return cmd
+}
+
+func newStorageStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show disk use per artifact category against its quota",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := storageReport{MinFreeMB: storageConfig().MinFreeMB}
+			report.Disk = storageDisk()
+			now := time.Now()
+			for _, c := range storageCategories() {
+				report.Categories = append(report.Categories, storage.Status(c, now))
+			}
+			if IsJSONOutput() {
+				return output.PrintJSON(report)
+			}
+			printStorageReport(report, false)
+			return nil
+		},
+	}
+}
+
+func newStoragePruneCmd() *cobra.Command {
+	var (
+		dryRun   bool
+		category string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the oldest artifacts that exceed quotas or retention",
+		Long: `Apply the [storage] quotas and retention rules now.
+
+Examples:
+  ntm storage prune --dry-run          # List what would be removed
+  ntm storage prune                    # Prune every category
+  ntm storage prune --category captures`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			categories := storageCategories()
+			if category != "" {
+				var names []string
+				var selected []storage.Category
+				for _, c := range categories {
+					names = append(names, c.Name)
+					if c.Name == category {
+						selected = append(selected, c)
+					}
+				}
+				if len(selected) == 0 {
+					return fmt.Errorf("unknown category %q (valid: %s)", category, strings.Join(names, ", "))
+				}
+				categories = selected
+			}
+
+			report := storageReport{MinFreeMB: storageConfig().MinFreeMB, DryRun: dryRun}
+			now := time.Now()
+			var failed []string
+			for _, c := range categories {
+				st := storage.Prune(c, now, dryRun)
+				if st.Error != "" {
+					failed = append(failed, c.Name)
+				}
+				report.Categories = append(report.Categories, st)
+			}
+			report.Disk = storageDisk()
+
+			if IsJSONOutput() {
+				if err := output.PrintJSON(report); err != nil {
+					return err
+				}
+			} else {
+				printStorageReport(report, true)
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("prune incomplete: %s failed", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed without deleting")
+	cmd.Flags().StringVar(&category, "category", "", "Only prune this category (archives, captures, audit)")
+	return cmd
+}
+
+func storageConfig() config.StorageConfig {
+	if cfg != nil {
+		return cfg.Storage
+	}
+	return config.DefaultStorageConfig()
+}
+
+// storageCategories returns the artifact categories with their configured rules.
+func storageCategories() []storage.Category {
+	sc := storageConfig()
+	auditDir := ""
+	if searcher, err := newAuditSearcherFunc(); err == nil {
+		auditDir = searcher.AuditDir()
+	}
+	categories := []storage.Category{
+		{Name: "archives", Dir: archive.OutputDir(), Depth: 1, Rule: storageRule(sc.Archives)},
+		{Name: "captures", Dir: checkpoint.NewStorage().BaseDir, Depth: 2, Rule: storageRule(sc.Captures)},
+	}
+	if auditDir != "" {
+		categories = append(categories, storage.Category{Name: "audit", Dir: auditDir, Depth: 1, Rule: storageRule(sc.Audit)})
+	}
+	return categories
+}
+
+func storageRule(q config.StorageQuota) storage.Rule {
+	const day = 24 * time.Hour
+	return storage.Rule{
+		QuotaBytes: int64(q.QuotaMB) * 1024 * 1024,
+		MaxAge:     time.Duration(q.MaxAgeDays) * day,
+		KeepFor:    time.Duration(q.KeepDays) * day,
+	}
+}
+
+// storageMinFreeBytes is the free space below which archiving pauses.
+func storageMinFreeBytes() uint64 {
+	if mb := storageConfig().MinFreeMB; mb > 0 {
+		return uint64(mb) * 1024 * 1024
+	}
+	return 0
+}
+
+// storageDisk reports free space where archives are written.
+func storageDisk() storage.Disk {
+	d, err := storage.CheckDisk(archive.OutputDir(), storageMinFreeBytes())
+	if err != nil {
+		slog.Debug("storage disk check failed", "error", err)
+	}
+	return d
+}
+
+func printStorageReport(r storageReport, pruned bool) {
+	t := theme.Current()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch {
+	case pruned && r.DryRun:
+		fmt.Fprintln(w, "CATEGORY\tWOULD REMOVE\tWOULD FREE\tSIZE\tQUOTA\tERROR")
+	case pruned:
+		fmt.Fprintln(w, "CATEGORY\tREMOVED\tFREED\tSIZE\tQUOTA\tERROR")
+	default:
+		fmt.Fprintln(w, "CATEGORY\tSIZE\tQUOTA\tARTIFACTS\tOLDEST\tPRUNABLE\tDIR")
+	}
+	for _, c := range r.Categories {
+		quota := "unlimited"
+		if c.QuotaBytes > 0 {
+			quota = formatBytes(c.QuotaBytes)
+			if c.OverQuota {
+				quota += " (over)"
+			}
+		}
+		if pruned {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", c.Name, len(c.RemovedEntries), formatBytes(c.RemovedBytes), formatBytes(c.Bytes), quota, c.Error)
+			continue
+		}
+		oldest := "-"
+		if c.Oldest != nil {
+			oldest = formatAge(*c.Oldest)
+		}
+		prunable := "-"
+		if c.Prunable > 0 {
+			prunable = fmt.Sprintf("%d (%s)", c.Prunable, formatBytes(c.PrunableBytes))
+		}
+		if c.Error != "" {
+			prunable = "error: " + c.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", c.Name, formatBytes(c.Bytes), quota, c.Artifacts, oldest, prunable, c.Dir)
+	}
+	_ = w.Flush()
+
+	if r.DryRun {
+		for _, c := range r.Categories {
+			for _, path := range c.RemovedEntries {
+				fmt.Printf("would remove %s\n", path)
+			}
+		}
+	}
+
+	if r.Disk.TotalBytes > 0 {
+		line := fmt.Sprintf("Disk: %s free of %s", formatBytes(int64(r.Disk.FreeBytes)), formatBytes(int64(r.Disk.TotalBytes)))
+		if r.Disk.Low {
+			line += fmt.Sprintf(" — below min_free_mb (%d MB), archiving paused", r.MinFreeMB)
+			fmt.Printf("%s%s%s\n", colorize(t.Warning), line, colorize(t.Text))
+		} else {
+			fmt.Println(line)
+		}
+	}
+}
+
+// autoPruneStorage enforces the storage rules, logging what was removed.
+func autoPruneStorage() {
+	now := time.Now()
+	for _, c := range storageCategories() {
+		st := storage.Prune(c, now, false)
+		if st.Error != "" {
+			slog.Warn("storage prune failed", "category", c.Name, "error", st.Error)
+		}
+		if len(st.RemovedEntries) > 0 {
+			slog.Info("storage pruned", "category", c.Name, "removed", len(st.RemovedEntries), "bytes", st.RemovedBytes)
+		}
+	}
+}
+
+// startStoragePruner enforces the storage rules now and then hourly until
+// ctx is done, when auto_prune is enabled.
+func startStoragePruner(ctx context.Context) {
+	if !storageConfig().AutoPrune {
+		return
+	}
+	go func() {
+		autoPruneStorage()
+		ticker := time.NewTicker(storagePruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				autoPruneStorage()
+			}
+		}
+	}()
+}
+
+// archiverDiskHandler emits storage.low_disk when a session's archiver pauses
+// for low disk space, and prunes straight away to try to free some.
+func archiverDiskHandler(session string) func(storage.Disk) {
+	return func(d storage.Disk) {
+		if !d.Low {
+			return
+		}
+		events.DefaultEmitter().Emit(events.NewWebhookEvent(
+			events.WebhookStorageLowDisk,
+			session,
+			"",
+			"",
+			fmt.Sprintf("Archiving paused: %s free on %s", formatBytes(int64(d.FreeBytes)), d.Path),
+			map[string]string{
+				"path":           d.Path,
+				"free_bytes":     fmt.Sprintf("%d", d.FreeBytes),
+				"min_free_bytes": fmt.Sprintf("%d", storageMinFreeBytes()),
+			},
+		))
+		if storageConfig().AutoPrune {
+			go autoPruneStorage()
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/storage"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// storageRootsRecord is where the artifact roots in use after the last
+// migration are remembered, so the next one knows where to move data from.
+const storageRootsRecord = "~/.ntm/storage-roots.json"
+
+// storageRoot is one artifact class and where it is stored.
+type storageRoot struct {
+	Name    string
+	Default string // Directory used without [storage.roots]
+	Dir     string // Directory in use now
+}
+
+// applyStorageRoots points each artifact class at its configured root.
+func applyStorageRoots(roots config.StorageRoots) {
+	audit.SetDir(roots.Audit)
+	archive.SetOutputDir(roots.Archives)
+	checkpoint.SetBaseDir(roots.Captures)
+	util.SetSessionsDir(roots.State)
+}
+
+// storageRoots returns the artifact classes with their default and current
+// directories.
+func storageRoots() []storageRoot {
+	home, _ := os.UserHomeDir()
+	state, _ := util.SessionsDir()
+	return []storageRoot{
+		{Name: "audit", Default: util.ExpandPath(audit.DefaultDir), Dir: audit.Dir()},
+		{Name: "archives", Default: util.ExpandPath(archive.DefaultOutputDir), Dir: archive.OutputDir()},
+		{Name: "captures", Default: filepath.Join(home, checkpoint.DefaultCheckpointDir), Dir: checkpoint.NewStorage().BaseDir},
+		{Name: "state", Default: util.ExpandPath("~/.ntm/sessions"), Dir: state},
+	}
+}
+
+// storageRootDirs returns the artifact directories that are not at their
+// defaults.
+func storageRootDirs() []string {
+	var dirs []string
+	for _, r := range storageRoots() {
+		if filepath.Clean(r.Dir) != filepath.Clean(r.Default) {
+			dirs = append(dirs, r.Dir)
+		}
+	}
+	return dirs
+}
+
+func loadStorageRootsRecord() map[string]string {
+	record := map[string]string{}
+	data, err := os.ReadFile(util.ExpandPath(storageRootsRecord))
+	if err == nil {
+		_ = json.Unmarshal(data, &record)
+	}
+	return record
+}
+
+func saveStorageRootsRecord(record map[string]string) error {
+	path := util.ExpandPath(storageRootsRecord)
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(path, append(data, '\n'), 0600)
+}
+
+// previousRoot returns where a class was stored before: where the last
+// migration put it, or its default.
+func previousRoot(r storageRoot, record map[string]string) string {
+	if dir := record[r.Name]; dir != "" {
+		return dir
+	}
+	return r.Default
+}
+
+// warnUnmigratedStorage points out artifacts left behind in a class's
+// previous root after [storage.roots] moved it.
+func warnUnmigratedStorage() {
+	if IsJSONOutput() {
+		return
+	}
+	record := loadStorageRootsRecord()
+	for _, r := range storageRoots() {
+		from := previousRoot(r, record)
+		if filepath.Clean(from) == filepath.Clean(r.Dir) {
+			continue
+		}
+		if entries, err := os.ReadDir(from); err == nil && len(entries) > 0 {
+			output.PrintWarningf("%s data is still in %s; run 'ntm storage migrate' to move it to %s", r.Name, from, r.Dir)
+		}
+	}
+}
+
+// storageMigrateReport is the output of ntm storage migrate.
+type storageMigrateReport struct {
+	DryRun  bool                    `json:"dry_run,omitempty"`
+	Classes []storage.MigrateResult `json:"classes"`
+}
+
+func newStorageMigrateCmd() *cobra.Command {
+	var (
+		dryRun   bool
+		category string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move artifacts to the roots set in [storage.roots]",
+		Long: `Move existing artifacts to the directories configured in [storage.roots].
+
+Each class (audit, archives, captures, state) is moved from where it was
+last stored, its default directory unless an earlier migrate moved it, to
+its configured root. Directories are merged; a file that already exists at
+the destination is left where it is and reported as a conflict. Files are
+renamed when both roots are on the same filesystem and copied otherwise.
+
+Stop ntm monitor and other long-running ntm processes first, so nothing
+writes to the old location while it is moved.
+
+Examples:
+  ntm storage migrate --dry-run          # Show what would move
+  ntm storage migrate                    # Move every class
+  ntm storage migrate --category audit`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStorageMigrate(category, dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be moved without moving")
+	cmd.Flags().StringVar(&category, "category", "", "Only migrate this class (audit, archives, captures, state)")
+	return cmd
+}
+
+func runStorageMigrate(category string, dryRun bool) error {
+	roots := storageRoots()
+	if category != "" {
+		var names []string
+		var selected []storageRoot
+		for _, r := range roots {
+			names = append(names, r.Name)
+			if r.Name == category {
+				selected = append(selected, r)
+			}
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("unknown category %q (valid: %s)", category, strings.Join(names, ", "))
+		}
+		roots = selected
+	}
+
+	// Loggers hold their files open in the old directory.
+	_ = audit.CloseAll()
+
+	record := loadStorageRootsRecord()
+	report := storageMigrateReport{DryRun: dryRun}
+	var failed []string
+	for _, r := range roots {
+		res := storage.Migrate(r.Name, previousRoot(r, record), r.Dir, dryRun)
+		report.Classes = append(report.Classes, res)
+		if res.Error != "" {
+			failed = append(failed, r.Name)
+			continue
+		}
+		if !dryRun && len(res.Conflicts) == 0 {
+			record[r.Name] = r.Dir
+		}
+	}
+	if !dryRun {
+		if err := saveStorageRootsRecord(record); err != nil {
+			return fmt.Errorf("record storage roots: %w", err)
+		}
+	}
+
+	if IsJSONOutput() {
+		if err := output.PrintJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printStorageMigrateReport(report)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("migrate incomplete: %s failed", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func printStorageMigrateReport(r storageMigrateReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	moved := "MOVED"
+	if r.DryRun {
+		moved = "WOULD MOVE"
+	}
+	fmt.Fprintf(w, "CLASS\tFROM\tTO\t%s\tSIZE\tCONFLICTS\tERROR\n", moved)
+	for _, c := range r.Classes {
+		if filepath.Clean(c.From) == filepath.Clean(c.To) {
+			fmt.Fprintf(w, "%s\t%s\t(unchanged)\t-\t-\t-\t\n", c.Name, c.From)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\n", c.Name, c.From, c.To, len(c.Moved), formatBytes(c.Bytes), len(c.Conflicts), c.Error)
+	}
+	_ = w.Flush()
+	for _, c := range r.Classes {
+		for _, f := range c.Conflicts {
+			fmt.Printf("conflict: %s exists in both %s and %s; left in place\n", f, c.From, c.To)
+		}
+	}
+}
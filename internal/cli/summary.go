@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -559,23 +558,14 @@ func parseArchiveFilename(name string) (string, time.Time, bool) {
 }
 
 func loadArchiveOutputs(path string) ([]summary.AgentOutput, error) {
-	file, err := os.Open(path)
+	records, err := archive.ReadRecords(path)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	dec := json.NewDecoder(file)
 	paneBuilders := make(map[string]*strings.Builder)
 	paneTypes := make(map[string]string)
-	for {
-		var record archive.ArchiveRecord
-		if err := dec.Decode(&record); err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
-		}
+	for _, record := range records {
 		if record.Content == "" {
 			continue
 		}
@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/takeover"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func newTakeoverCmd() *cobra.Command {
+	var end bool
+	var by string
+
+	cmd := &cobra.Command{
+		Use:   "takeover <session> [pane]",
+		Short: "Hand an agent pane to a human and back",
+		Long: `Mark an agent pane as human-controlled so you can work in it directly.
+
+While a pane is taken over ntm sends it no automated prompts: broadcasts
+from ntm send and robot send skip it, ntm assign gives it no new beads and
+sends it no verification bounces, and the resilience monitor leaves it
+alone. Beads already assigned to the pane are flagged as human work, so
+their scores are kept out of agent summaries.
+
+Handing the pane back with --end compares its scrollback with the one saved
+at takeover and writes each command typed in the meantime to the audit log,
+with the user as actor.
+
+Without a pane, lists the session's taken-over panes.
+
+Examples:
+  ntm takeover myproject 2          # Take over pane 2
+  ntm takeover myproject cc_1       # By agent name
+  ntm takeover myproject 2 --end    # Hand it back
+  ntm takeover myproject            # List taken-over panes`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := tmux.EnsureInstalled(); err != nil {
+				return err
+			}
+			res, err := ResolveSession(args[0], cmd.OutOrStdout())
+			if err != nil {
+				return err
+			}
+			if res.Session == "" {
+				return nil
+			}
+			session := res.Session
+			if !tmux.SessionExists(session) {
+				return fmt.Errorf("session '%s' not found", session)
+			}
+
+			if len(args) == 1 {
+				if end {
+					return fmt.Errorf("--end needs a pane")
+				}
+				return runTakeoverList(session)
+			}
+			if end {
+				return runTakeoverEnd(session, args[1])
+			}
+			return runTakeoverStart(session, args[1], by)
+		},
+	}
+
+	cmd.Flags().BoolVar(&end, "end", false, "Hand the pane back to its agent")
+	cmd.Flags().StringVar(&by, "by", defaultTakeoverBy(), "Who is taking the pane over")
+
+	return cmd
+}
+
+func defaultTakeoverBy() string {
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "human"
+}
+
+// TakeoverResponse is the JSON output for taking over or handing back a pane.
+type TakeoverResponse struct {
+	output.TimestampedResponse
+	Session         string    `json:"session"`
+	Pane            int       `json:"pane"`
+	PaneID          string    `json:"pane_id"`
+	TakenOver       bool      `json:"taken_over"`
+	By              string    `json:"by"`
+	Since           time.Time `json:"since,omitempty"`
+	Beads           []string  `json:"beads,omitempty"`
+	DurationSeconds int       `json:"duration_seconds,omitempty"`
+	Commands        []string  `json:"commands,omitempty"`
+}
+
+// TakeoverPane is a taken-over pane in TakeoverListResponse.
+type TakeoverPane struct {
+	Pane   int       `json:"pane"`
+	PaneID string    `json:"pane_id"`
+	Title  string    `json:"title"`
+	By     string    `json:"by"`
+	Since  time.Time `json:"since,omitempty"`
+}
+
+// TakeoverListResponse is the JSON output for ntm takeover <session>.
+type TakeoverListResponse struct {
+	output.TimestampedResponse
+	Session string         `json:"session"`
+	Panes   []TakeoverPane `json:"panes"`
+}
+
+func runTakeoverStart(session, target, by string) error {
+	pane, err := resolvePane(session, target)
+	if err != nil {
+		return err
+	}
+	if pane.Type == tmux.AgentUser {
+		return fmt.Errorf("pane %d is the user pane, not an agent", pane.Index)
+	}
+
+	// Saved before marking the pane so nothing typed after the mark is
+	// missed at handback.
+	scrollback, err := tmux.CapturePaneOutput(pane.ID, tmux.LinesCheckpoint)
+	if err != nil {
+		return fmt.Errorf("capture pane %d: %w", pane.Index, err)
+	}
+	snapshot := takeover.SnapshotPath(assignment.StorageDir(), session, pane.Index)
+	if err := takeover.SaveSnapshot(snapshot, scrollback); err != nil {
+		return err
+	}
+	state, err := takeover.Start(pane.ID, by)
+	if err != nil {
+		_, _ = takeover.LoadSnapshot(snapshot)
+		return fmt.Errorf("pane %d: %w", pane.Index, err)
+	}
+
+	var beads []string
+	if store, err := assignment.LoadStore(session); err == nil {
+		beads = store.MarkHumanTakeover(pane.Index)
+	}
+
+	_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorUser, "takeover.start", map[string]interface{}{
+		"pane":    pane.Index,
+		"pane_id": pane.ID,
+		"by":      state.By,
+		"beads":   beads,
+	}, nil)
+
+	if IsJSONOutput() {
+		return output.PrintJSON(TakeoverResponse{
+			TimestampedResponse: output.NewTimestamped(),
+			Session:             session,
+			Pane:                pane.Index,
+			PaneID:              pane.ID,
+			TakenOver:           true,
+			By:                  state.By,
+			Since:               state.Since,
+			Beads:               beads,
+		})
+	}
+
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	fmt.Printf("%s Pane %d (%s) taken over by %s\n", warnStyle.Render("✋"), pane.Index, pane.Title, state.By)
+	if len(beads) > 0 {
+		fmt.Printf("  %s\n", mutedStyle.Render(fmt.Sprintf("Marked as human work: %v", beads)))
+	}
+	fmt.Printf("  %s\n", mutedStyle.Render(fmt.Sprintf("Hand it back with: ntm takeover %s %d --end", session, pane.Index)))
+	return nil
+}
+
+func runTakeoverEnd(session, target string) error {
+	pane, err := resolvePane(session, target)
+	if err != nil {
+		return err
+	}
+
+	// Capture while the pane is still held, before the agent is prompted
+	// again.
+	scrollback, err := tmux.CapturePaneOutput(pane.ID, tmux.LinesCheckpoint)
+	if err != nil {
+		return fmt.Errorf("capture pane %d: %w", pane.Index, err)
+	}
+	state, err := takeover.End(pane.ID)
+	if errors.Is(err, takeover.ErrNotTakenOver) {
+		return fmt.Errorf("pane %d is not taken over", pane.Index)
+	}
+	if err != nil {
+		return err
+	}
+
+	before, err := takeover.LoadSnapshot(takeover.SnapshotPath(assignment.StorageDir(), session, pane.Index))
+	if err != nil {
+		return err
+	}
+	commands := takeover.Commands(before, scrollback)
+	for _, c := range commands {
+		_ = audit.LogEvent(session, audit.EventTypeCommand, audit.ActorUser, "takeover.command", map[string]interface{}{
+			"pane":    pane.Index,
+			"pane_id": pane.ID,
+			"by":      state.By,
+			"command": c,
+		}, nil)
+	}
+
+	var duration int
+	if !state.Since.IsZero() {
+		duration = int(time.Since(state.Since).Seconds())
+	}
+	_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorUser, "takeover.end", map[string]interface{}{
+		"pane":             pane.Index,
+		"pane_id":          pane.ID,
+		"by":               state.By,
+		"since":            state.Since,
+		"duration_seconds": duration,
+		"commands":         len(commands),
+	}, nil)
+
+	if IsJSONOutput() {
+		return output.PrintJSON(TakeoverResponse{
+			TimestampedResponse: output.NewTimestamped(),
+			Session:             session,
+			Pane:                pane.Index,
+			PaneID:              pane.ID,
+			By:                  state.By,
+			Since:               state.Since,
+			DurationSeconds:     duration,
+			Commands:            commands,
+		})
+	}
+
+	okStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	fmt.Printf("%s Pane %d (%s) handed back by %s", okStyle.Render("✓"), pane.Index, pane.Title, state.By)
+	if duration > 0 {
+		fmt.Printf(" after %s", (time.Duration(duration) * time.Second).String())
+	}
+	fmt.Println()
+	if len(commands) == 0 {
+		fmt.Printf("  %s\n", mutedStyle.Render("No commands journaled"))
+		return nil
+	}
+	fmt.Printf("  Journaled %d command(s):\n", len(commands))
+	for _, c := range commands {
+		fmt.Printf("    %s %s\n", mutedStyle.Render("$"), c)
+	}
+	return nil
+}
+
+func runTakeoverList(session string) error {
+	held := takeover.Panes(session)
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return err
+	}
+
+	list := []TakeoverPane{}
+	for _, p := range panes {
+		s := held[p.ID]
+		if s == nil {
+			continue
+		}
+		list = append(list, TakeoverPane{Pane: p.Index, PaneID: p.ID, Title: p.Title, By: s.By, Since: s.Since})
+	}
+
+	if IsJSONOutput() {
+		return output.PrintJSON(TakeoverListResponse{
+			TimestampedResponse: output.NewTimestamped(),
+			Session:             session,
+			Panes:               list,
+		})
+	}
+
+	mutedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	fmt.Println()
+	if len(list) == 0 {
+		fmt.Printf("  %s No panes taken over in %s\n", mutedStyle.Render("•"), session)
+		fmt.Println()
+		return nil
+	}
+	for _, p := range list {
+		since := ""
+		if !p.Since.IsZero() {
+			since = mutedStyle.Render(fmt.Sprintf(" since %s", p.Since.Local().Format("15:04")))
+		}
+		fmt.Printf("  %s pane %d (%s) held by %s%s\n", warnStyle.Render("✋"), p.Pane, p.Title, p.By, since)
+	}
+	fmt.Println()
+	return nil
+}
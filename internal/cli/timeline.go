@@ -17,6 +17,14 @@ import (
 )
 
 func newTimelineCmd() *cobra.Command {
+	var (
+		session    string
+		outputFile string
+		since      string
+		until      string
+		lightTheme bool
+	)
+
 	cmd := &cobra.Command{
 		Use:   "timeline",
 		Short: "View and manage session timeline history",
@@ -32,13 +40,33 @@ Examples:
   ntm timeline show <session-id>       # Show timeline details
   ntm timeline delete <session-id>     # Delete a timeline
   ntm timeline cleanup                 # Remove old timelines
-  ntm timeline export <session-id>     # Export timeline data`,
+  ntm timeline export <session-id>     # Export timeline data
+  ntm timeline --session myproject --out timeline.html
+                                       # Post-mortem HTML timeline`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runTimelineList()
+			if session == "" {
+				if outputFile != "" {
+					return fmt.Errorf("--out requires --session")
+				}
+				return runTimelineList()
+			}
+			return runTimelineExport(session, exportOptions{
+				format:     "html",
+				outputFile: outputFile,
+				since:      since,
+				until:      until,
+				lightTheme: lightTheme,
+			})
 		},
 	}
 
+	cmd.Flags().StringVar(&session, "session", "", "Export an HTML timeline for this session")
+	cmd.Flags().StringVar(&outputFile, "out", "", "HTML output file (default: <session>_timeline.html)")
+	cmd.Flags().StringVar(&since, "since", "", "With --session: only events since duration (e.g., 2h)")
+	cmd.Flags().StringVar(&until, "until", "", "With --session: only events until time/duration")
+	cmd.Flags().BoolVar(&lightTheme, "light", false, "With --session: use the light theme (better for print)")
+
 	// Subcommands
 	cmd.AddCommand(newTimelineListCmd())
 	cmd.AddCommand(newTimelineShowCmd())
@@ -495,11 +523,14 @@ Supported formats:
   jsonl - JSON Lines format (one event per line)
   svg   - Scalable Vector Graphics (for docs/reports)
   png   - PNG raster image (supports --scale for resolution)
+  html  - Self-contained post-mortem page: per-pane lanes with prompts,
+          output bursts, conflicts, rate-limit pauses, handoffs and cost
 
 Examples:
   ntm timeline export myproject                        # Export to JSONL
   ntm timeline export myproject --format=svg           # Export to SVG
   ntm timeline export myproject --format=png --scale=2 # Export to 2x PNG
+  ntm timeline export myproject --format=html          # Post-mortem HTML page
   ntm timeline export myproject -o timeline.svg        # Specify output file
   ntm timeline export myproject --since=1h             # Last hour only
   ntm timeline export myproject --light                # Light theme for print`,
@@ -520,7 +551,7 @@ Examples:
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "format", "f", "jsonl", "Output format: jsonl, svg, png")
+	cmd.Flags().StringVarP(&format, "format", "f", "jsonl", "Output format: jsonl, svg, png, html")
 	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (default: <session>_timeline.<format>)")
 	cmd.Flags().IntVarP(&scale, "scale", "s", 1, "Scale multiplier for PNG output (1, 2, or 3)")
 	cmd.Flags().StringVar(&since, "since", "", "Filter events since duration (e.g., 1h, 30m)")
@@ -548,6 +579,14 @@ type exportOptions struct {
 func runTimelineExport(sessionID string, opts exportOptions) error {
 	t := theme.Current()
 
+	if strings.EqualFold(opts.format, "html") {
+		sinceTime, untilTime, err := parseTimelineRange(opts.since, opts.until)
+		if err != nil {
+			return err
+		}
+		return runTimelineHTML(sessionID, opts, sinceTime, untilTime)
+	}
+
 	persister, err := state.GetDefaultTimelinePersister()
 	if err != nil {
 		return fmt.Errorf("failed to get timeline persister: %w", err)
@@ -594,29 +633,13 @@ func runTimelineExport(sessionID string, opts exportOptions) error {
 
 	// Handle SVG/PNG export
 	if format != "svg" && format != "png" {
-		return fmt.Errorf("unsupported format: %s (use jsonl, svg, or png)", format)
+		return fmt.Errorf("unsupported format: %s (use jsonl, svg, png, or html)", format)
 	}
 
 	// Parse time filters
-	var sinceTime, untilTime time.Time
-	if opts.since != "" {
-		duration, err := time.ParseDuration(opts.since)
-		if err != nil {
-			return fmt.Errorf("invalid --since value: %w", err)
-		}
-		sinceTime = time.Now().Add(-duration)
-	}
-	if opts.until != "" {
-		duration, err := time.ParseDuration(opts.until)
-		if err != nil {
-			// Try parsing as absolute time
-			untilTime, err = time.Parse(time.RFC3339, opts.until)
-			if err != nil {
-				return fmt.Errorf("invalid --until value: %w", err)
-			}
-		} else {
-			untilTime = time.Now().Add(-duration)
-		}
+	sinceTime, untilTime, err := parseTimelineRange(opts.since, opts.until)
+	if err != nil {
+		return err
 	}
 
 	// Filter events by time range if specified
@@ -682,6 +705,31 @@ func runTimelineExport(sessionID string, opts exportOptions) error {
 	return nil
 }
 
+// parseTimelineRange parses the --since duration and the --until duration
+// or RFC3339 time.
+func parseTimelineRange(since, until string) (sinceTime, untilTime time.Time, err error) {
+	if since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --since value: %w", err)
+		}
+		sinceTime = time.Now().Add(-duration)
+	}
+	if until != "" {
+		duration, err := time.ParseDuration(until)
+		if err != nil {
+			// Try parsing as absolute time
+			untilTime, err = time.Parse(time.RFC3339, until)
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("invalid --until value: %w", err)
+			}
+		} else {
+			untilTime = time.Now().Add(-duration)
+		}
+	}
+	return sinceTime, untilTime, nil
+}
+
 func newTimelineStatsCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "stats",
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/export"
+	"github.com/Dicklesworthstone/ntm/internal/handoff"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+)
+
+// timelinePromptPreview is how much of a prompt is shown on its marker.
+const timelinePromptPreview = 200
+
+// runTimelineHTML writes the post-mortem HTML timeline for a session. Unlike
+// the SVG/PNG exports it does not require a saved state timeline: prompt
+// history, the event journal, rotation history, handoffs and cost data are
+// enough to draw a session.
+func runTimelineHTML(session string, opts exportOptions, sinceTime, untilTime time.Time) error {
+	t := theme.Current()
+
+	var agentEvents []state.AgentEvent
+	if persister, err := state.GetDefaultTimelinePersister(); err == nil {
+		if loaded, err := persister.LoadTimeline(session); err == nil {
+			agentEvents = loaded
+		}
+	}
+	filtered := agentEvents[:0]
+	for _, ev := range agentEvents {
+		if inTimelineRange(ev.Timestamp, sinceTime, untilTime) {
+			filtered = append(filtered, ev)
+		}
+	}
+	agentEvents = filtered
+
+	var markers []state.TimelineMarker
+	for _, m := range collectTimelineMarkers(session, newTimelinePaneIndex(session)) {
+		if inTimelineRange(m.Timestamp, sinceTime, untilTime) {
+			markers = append(markers, m)
+		}
+	}
+	if len(agentEvents) == 0 && len(markers) == 0 {
+		return fmt.Errorf("no timeline data found for session %s", session)
+	}
+
+	exportOpts := export.DefaultExportOptions()
+	exportOpts.SessionName = session
+	exportOpts.Since = sinceTime
+	exportOpts.Until = untilTime
+	if opts.lightTheme {
+		exportOpts.Theme = export.LightTheme()
+	}
+	data, err := export.NewTimelineExporter(exportOpts).ExportHTML(agentEvents, markers)
+	if err != nil {
+		return fmt.Errorf("failed to export: %w", err)
+	}
+
+	outputFile := opts.outputFile
+	if outputFile == "" {
+		outputFile = session + "_timeline.html"
+	}
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	fmt.Printf("%s✓%s Exported %d state changes and %d events to %s (HTML format)\n",
+		colorize(t.Success), colorize(t.Text),
+		len(agentEvents), len(markers), outputFile)
+	return nil
+}
+
+func inTimelineRange(ts, since, until time.Time) bool {
+	if !since.IsZero() && ts.Before(since) {
+		return false
+	}
+	if !until.IsZero() && ts.After(until) {
+		return false
+	}
+	return true
+}
+
+// timelinePaneIndex maps tmux pane IDs and indices to timeline agent IDs
+// (cc_1, cod_2, ...). It is empty when the session is no longer running;
+// markers that cannot be attributed then go on the session lane.
+type timelinePaneIndex struct {
+	byID    map[string]string
+	byIndex map[string]string
+}
+
+func newTimelinePaneIndex(session string) timelinePaneIndex {
+	idx := timelinePaneIndex{byID: map[string]string{}, byIndex: map[string]string{}}
+	if !tmux.SessionExists(session) {
+		return idx
+	}
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return idx
+	}
+	for _, p := range panes {
+		if p.Type == tmux.AgentUser || p.Type == tmux.AgentUnknown || p.NTMIndex <= 0 {
+			continue
+		}
+		id := fmt.Sprintf("%s_%d", p.Type, p.NTMIndex)
+		idx.byID[p.ID] = id
+		idx.byIndex[strconv.Itoa(p.Index)] = id
+	}
+	return idx
+}
+
+// timelineTitleAgent matches the agent part of a pane title
+// ("proj__cc_1_opus" -> "cc_1").
+var timelineTitleAgent = regexp.MustCompile(`([a-z]+_\d+)`)
+
+// agentFromTitle returns the timeline agent ID for a pane title or agent ID.
+func agentFromTitle(title string) string {
+	if parts := strings.SplitN(title, "__", 2); len(parts) == 2 {
+		title = parts[1]
+	}
+	return timelineTitleAgent.FindString(title)
+}
+
+// collectTimelineMarkers gathers the session's discrete events from the
+// stores ntm already keeps.
+func collectTimelineMarkers(session string, panes timelinePaneIndex) []state.TimelineMarker {
+	var markers []state.TimelineMarker
+	add := func(agentID string, kind state.MarkerType, ts time.Time, msg string, details map[string]string) {
+		markers = append(markers, state.TimelineMarker{
+			AgentID:   agentID,
+			SessionID: session,
+			Type:      kind,
+			Timestamp: ts,
+			Message:   msg,
+			Details:   details,
+		})
+	}
+
+	// Prompts sent with ntm send, the palette or replay.
+	if entries, err := history.ReadForSession(session); err == nil {
+		for _, e := range entries {
+			msg := truncateStr(strings.TrimSpace(e.Prompt), timelinePromptPreview)
+			if !e.Success && e.Error != "" {
+				msg += " (failed: " + e.Error + ")"
+			}
+			var unmapped []string
+			for _, target := range e.Targets {
+				if id, ok := panes.byIndex[target]; ok {
+					add(id, state.MarkerPrompt, e.Timestamp, msg, nil)
+				} else {
+					unmapped = append(unmapped, target)
+				}
+			}
+			if len(unmapped) > 0 || len(e.Targets) == 0 {
+				prefix := "all panes"
+				if len(unmapped) > 0 {
+					prefix = "pane " + strings.Join(unmapped, ",")
+				}
+				add("", state.MarkerPrompt, e.Timestamp, prefix+": "+msg, nil)
+			}
+		}
+	}
+
+	// Rate limits, crashes, undelivered prompts and file conflicts from the
+	// bus journal.
+	entries, _ := events.ReadJournal("", events.JournalFilter{
+		Session: session,
+		Types: []string{
+			events.WebhookAgentRateLimit,
+			events.WebhookAgentCrashed,
+			events.WebhookAgentError,
+			events.WebhookPromptUndelivered,
+			events.BusAlert,
+		},
+	})
+	for _, entry := range entries {
+		switch ev := entry.Decode().(type) {
+		case events.WebhookEvent:
+			agentID := panes.byID[ev.Pane]
+			switch ev.Type {
+			case events.WebhookAgentRateLimit:
+				add(agentID, state.MarkerRateLimit, ev.Timestamp, ev.Message, map[string]string{"wait_seconds": ev.Details["wait_seconds"]})
+			default:
+				add(agentID, state.MarkerError, ev.Timestamp, ev.Message, nil)
+			}
+		case events.AlertEvent:
+			if ev.AlertType == "file_conflict" {
+				add("", state.MarkerConflict, ev.Timestamp, ev.Message, nil)
+			}
+		}
+	}
+
+	// Rotations and context resets.
+	if records, err := ctxmon.DefaultRotationHistoryStore.ReadForSession(session); err == nil {
+		for _, r := range records {
+			msg := fmt.Sprintf("%s at %.0f%% context", strings.ReplaceAll(string(r.Method), "_", " "), r.ContextBefore)
+			if r.CompactionMethod != "" && r.Method == ctxmon.RotationContextReset {
+				msg += " via " + r.CompactionMethod
+			}
+			if !r.Success && r.FailureReason != "" {
+				msg += " (failed: " + r.FailureReason + ")"
+			}
+			add(agentFromTitle(r.AgentID), state.MarkerHandoff, r.Timestamp, msg, nil)
+		}
+	}
+
+	// Session handoff documents.
+	if metas, err := handoff.NewReader(GetProjectRoot()).ListHandoffs(session); err == nil {
+		for _, m := range metas {
+			add("", state.MarkerHandoff, m.Date, "handoff: "+m.Goal, nil)
+		}
+	}
+
+	// Spend: budget limits from the event log and per-agent cost totals.
+	if logged, err := events.DefaultLogger().Since(time.Time{}); err == nil {
+		for _, e := range logged {
+			if e.Session != session {
+				continue
+			}
+			switch e.Type {
+			case events.EventBudgetSoftLimit, events.EventBudgetHardLimit:
+				limit := "soft"
+				if e.Type == events.EventBudgetHardLimit {
+					limit = "hard"
+				}
+				spend, _ := e.Data["spend_usd"].(float64)
+				add("", state.MarkerCost, e.Timestamp, fmt.Sprintf("%s budget limit crossed at %s", limit, cost.FormatCost(spend)), nil)
+			}
+		}
+	}
+	dir := GetProjectRoot()
+	tracker := cost.NewCostTracker(dir)
+	if err := tracker.LoadFromDir(dir); err == nil {
+		if sc := tracker.GetSession(session); sc != nil {
+			panesWithCost := make([]string, 0, len(sc.Agents))
+			for pane := range sc.Agents {
+				panesWithCost = append(panesWithCost, pane)
+			}
+			sort.Strings(panesWithCost)
+			for _, pane := range panesWithCost {
+				ac := sc.Agents[pane]
+				if ac.LastUpdated.IsZero() {
+					continue
+				}
+				agentID := panes.byID[pane]
+				if agentID == "" {
+					agentID = panes.byIndex[pane]
+				}
+				add(agentID, state.MarkerCost, ac.LastUpdated, fmt.Sprintf("pane %s: %s (%d in / %d out tokens, %s)",
+					pane, cost.FormatCost(ac.Cost()), ac.InputTokens, ac.OutputTokens, ac.Model), nil)
+			}
+		}
+	}
+
+	return markers
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func TestAgentFromTitle(t *testing.T) {
+	tests := map[string]string{
+		"proj__cc_1":       "cc_1",
+		"proj__cod_2_opus": "cod_2",
+		"my_proj__gmi_3":   "gmi_3",
+		"cc_4":             "cc_4",
+		"proj__user":       "",
+		"":                 "",
+	}
+	for title, want := range tests {
+		if got := agentFromTitle(title); got != want {
+			t.Errorf("agentFromTitle(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestCollectTimelineMarkers_Prompts(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	const session = "timeline-html-test"
+	sent := history.NewEntry(session, []string{"1", "3"}, "Run the migration", history.SourceCLI)
+	sent.SetSuccess()
+	if err := history.Append(sent); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Append(history.NewEntry("other-session", []string{"1"}, "unrelated", history.SourceCLI)); err != nil {
+		t.Fatal(err)
+	}
+
+	panes := timelinePaneIndex{
+		byID:    map[string]string{"%1": "cc_1"},
+		byIndex: map[string]string{"1": "cc_1"},
+	}
+	var prompts []state.TimelineMarker
+	for _, m := range collectTimelineMarkers(session, panes) {
+		if m.Type == state.MarkerPrompt {
+			prompts = append(prompts, m)
+		}
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("prompt markers = %+v, want one mapped and one unmapped", prompts)
+	}
+	if prompts[0].AgentID != "cc_1" || prompts[0].Message != "Run the migration" {
+		t.Errorf("mapped marker = %+v", prompts[0])
+	}
+	if prompts[1].AgentID != "" || prompts[1].Message != "pane 3: Run the migration" {
+		t.Errorf("unmapped marker = %+v", prompts[1])
+	}
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/codeblock"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/plugins"
+	"github.com/Dicklesworthstone/ntm/internal/policy"
+)
+
+// wasmPluginDirs returns where WASM plugins are loaded from: the user's
+// config directory, then the project.
+func wasmPluginDirs() []string {
+	dirs := []string{filepath.Join(filepath.Dir(config.DefaultPath()), "wasm")}
+	if root := GetProjectRoot(); root != "" {
+		dirs = append(dirs, filepath.Join(root, ".ntm", "wasm"))
+	}
+	return dirs
+}
+
+// loadWasmPlugins loads the WASM plugins when [wasm] is enabled. The
+// caller closes the runtime, which is nil when plugins are disabled.
+// Plugins that fail to load are logged and skipped.
+func loadWasmPlugins(ctx context.Context) (*plugins.WasmRuntime, []*plugins.WasmPlugin) {
+	if cfg == nil || !cfg.Wasm.Enabled {
+		return nil, nil
+	}
+	rt, err := plugins.NewWasmRuntime(ctx, plugins.WasmOptions{
+		MemoryLimitMB: cfg.Wasm.MemoryLimitMB,
+		Timeout:       time.Duration(cfg.Wasm.TimeoutMs) * time.Millisecond,
+	})
+	if err != nil {
+		slog.Warn("wasm runtime unavailable", "error", err)
+		return nil, nil
+	}
+	var loaded []*plugins.WasmPlugin
+	for _, dir := range wasmPluginDirs() {
+		ps, err := rt.LoadDir(ctx, dir)
+		if err != nil {
+			slog.Warn("loading wasm plugins", "dir", dir, "error", err)
+		}
+		loaded = append(loaded, ps...)
+	}
+	return rt, loaded
+}
+
+// wasmExtractBlocks runs the extract plugins on text, keeping the blocks
+// in language (any language if empty).
+func wasmExtractBlocks(ctx context.Context, ps []*plugins.WasmPlugin, text, language string) []codeblock.CodeBlock {
+	var blocks []codeblock.CodeBlock
+	for _, p := range ps {
+		if !p.Has(plugins.WasmExtract) {
+			continue
+		}
+		found, err := p.Extract(ctx, text, language)
+		if err != nil {
+			slog.Warn("wasm extract plugin failed", "plugin", p.Name, "error", err)
+			continue
+		}
+		for _, b := range found {
+			if language == "" || strings.EqualFold(b.Language, language) {
+				blocks = append(blocks, b)
+			}
+		}
+	}
+	return blocks
+}
+
+// CheckPluginVerdict is a WASM check plugin's verdict in a safety check.
+type CheckPluginVerdict struct {
+	Plugin string `json:"plugin"`
+	Action string `json:"action,omitempty"` // allow, block, approve; empty for no opinion
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// applyWasmChecks consults the check plugins about resp.Command. Plugins
+// can only tighten the verdict: block beats approve beats allow. A plugin
+// that fails is recorded and otherwise ignored, like an unavailable DCG.
+func applyWasmChecks(ctx context.Context, ps []*plugins.WasmPlugin, resp *CheckResponse) {
+	rank := map[string]int{string(policy.ActionAllow): 0, string(policy.ActionApprove): 1, string(policy.ActionBlock): 2}
+	for _, p := range ps {
+		if !p.Has(plugins.WasmCheck) {
+			continue
+		}
+		v := CheckPluginVerdict{Plugin: p.Name}
+		res, err := p.Check(ctx, resp.Command)
+		if err != nil {
+			v.Error = err.Error()
+		} else {
+			v.Action, v.Reason = res.Action, res.Reason
+		}
+		resp.Plugins = append(resp.Plugins, v)
+
+		if v.Action != "" && rank[v.Action] > rank[resp.Action] {
+			resp.Action = v.Action
+			resp.Pattern = "wasm:" + p.Name
+			resp.Reason = v.Reason
+			if resp.Reason == "" {
+				resp.Reason = "flagged by plugin " + p.Name
+			}
+		}
+	}
+}
+
+func newPluginsRunCmd() *cobra.Command {
+	var input string
+
+	cmd := &cobra.Command{
+		Use:   "run <plugin> <extract|evaluate|check>",
+		Short: "Call a WASM plugin with a JSON request and print its response",
+		Long: `Call a WASM plugin's entry point directly, for developing plugins.
+
+Plugins are .wasm modules in ~/.config/ntm/wasm/ and the project's .ntm/wasm/,
+loaded when [wasm] enabled = true.
+
+Examples:
+  ntm plugins run guard check --input '{"command":"git push --force"}'
+  ntm plugins run blocks extract --input '{"text":"...","language":"go"}'`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cfg == nil || !cfg.Wasm.Enabled {
+				return fmt.Errorf("wasm plugins are disabled; set [wasm] enabled = true")
+			}
+			kind := plugins.WasmKind(args[1])
+			if !slices.Contains(plugins.AllWasmKinds(), kind) {
+				return fmt.Errorf("unknown plugin kind %q (want extract, evaluate or check)", args[1])
+			}
+			req := map[string]interface{}{}
+			if input != "" {
+				if err := json.Unmarshal([]byte(input), &req); err != nil {
+					return fmt.Errorf("parsing --input: %w", err)
+				}
+			}
+
+			ctx := context.Background()
+			rt, loaded := loadWasmPlugins(ctx)
+			if rt != nil {
+				defer rt.Close(ctx)
+			}
+			for _, p := range loaded {
+				if p.Name != args[0] {
+					continue
+				}
+				var resp interface{}
+				if err := p.Call(ctx, kind, req, &resp); err != nil {
+					return err
+				}
+				return output.PrintJSON(resp)
+			}
+			return fmt.Errorf("wasm plugin %q not found", args[0])
+		},
+	}
+	cmd.Flags().StringVar(&input, "input", "", "Request as a JSON object")
+	return cmd
+}
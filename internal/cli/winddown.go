@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/checkpoint"
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/winddown"
+)
+
+// windDownInterval is how often the daemon re-evaluates a session's runtime.
+const windDownInterval = time.Minute
+
+func windDownConfig() config.WindDownConfig {
+	if cfg != nil {
+		return cfg.WindDown
+	}
+	return config.DefaultWindDownConfig()
+}
+
+// windingDown reports whether session has entered its wind-down and takes
+// no new tasks.
+func windingDown(session string) bool {
+	st, err := winddown.Load(session)
+	return err == nil && st.Stopping()
+}
+
+// checkWindingDown returns an error when session no longer takes new tasks.
+func checkWindingDown(session string) error {
+	st, err := winddown.Load(session)
+	if err != nil || !st.Stopping() {
+		return nil
+	}
+	return fmt.Errorf("session %s is winding down (max runtime reached at %s); not assigning new work",
+		session, st.Deadline.Local().Format(time.DateTime))
+}
+
+// windDownSchedule returns session's runtime limit: the maxRuntime of the
+// session template it was spawned from, else [wind_down] max_session_minutes.
+// The session's start is taken from the template budget, else from tmux.
+func windDownSchedule(session string) (winddown.Schedule, error) {
+	wc := windDownConfig()
+	sched := winddown.Schedule{
+		MaxRuntime: time.Duration(wc.MaxSessionMinutes) * time.Minute,
+		WrapUp:     time.Duration(wc.WrapUpMinutes) * time.Minute,
+	}
+	for _, dir := range []string{sessionProjectDir(session), GetProjectRoot()} {
+		if dir == "" {
+			continue
+		}
+		guard, _, err := loadBudgetState(dir)
+		if err != nil {
+			continue
+		}
+		if b := guard.Get(session); b != nil && b.MaxRuntime() > 0 {
+			sched.MaxRuntime = b.MaxRuntime()
+			if b.StartedAt != nil {
+				sched.StartedAt = *b.StartedAt
+			}
+			break
+		}
+	}
+	if sched.MaxRuntime <= 0 {
+		return sched, nil
+	}
+	// A wrap-up window as long as the session would stop it from the start.
+	if sched.WrapUp >= sched.MaxRuntime {
+		sched.WrapUp = sched.MaxRuntime / 2
+	}
+	if sched.StartedAt.IsZero() {
+		created, err := tmux.SessionCreated(session)
+		if err != nil {
+			return sched, err
+		}
+		sched.StartedAt = created
+	}
+	return sched, nil
+}
+
+// runDaemonWindDown winds session down once it reaches its maximum runtime.
+func runDaemonWindDown(ctx context.Context, session string) error {
+	ticker := time.NewTicker(windDownInterval)
+	defer ticker.Stop()
+	for {
+		if err := windDownTick(ctx, session, time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// windDownTick runs the wind-down steps of session that are due at now and
+// records them.
+func windDownTick(ctx context.Context, session string, now time.Time) error {
+	sched, err := windDownSchedule(session)
+	if err != nil {
+		return err
+	}
+	st, err := winddown.Load(session)
+	if err != nil {
+		return err
+	}
+	// State left by an earlier session of the same name, or by limits
+	// since changed, no longer applies.
+	if st != nil && !st.Deadline.Equal(sched.Deadline()) {
+		if err := winddown.Remove(session); err != nil {
+			return err
+		}
+		st = nil
+	}
+	if st == nil {
+		if sched.PhaseAt(now) == winddown.PhaseRunning {
+			return nil
+		}
+		st = &winddown.State{Session: session}
+	}
+
+	prev := st.Phase
+	ran := winddown.Advance(ctx, st, sched, windDownSteps(session, sched, now), now)
+	if len(ran) == 0 && st.Phase == prev {
+		return nil
+	}
+	if err := winddown.Save(st); err != nil {
+		return err
+	}
+
+	for _, step := range ran {
+		data := map[string]interface{}{
+			"step":     step.Name,
+			"ok":       step.OK,
+			"phase":    string(st.Phase),
+			"deadline": st.Deadline.Format(time.RFC3339),
+		}
+		if step.Detail != "" {
+			data["detail"] = step.Detail
+		}
+		if step.Error != "" {
+			data["error"] = step.Error
+		}
+		_ = audit.LogEvent(session, audit.EventTypeStateChange, audit.ActorSystem, "winddown", data, nil)
+		events.Emit(events.EventSessionWindDown, session, data)
+	}
+	return nil
+}
+
+// windDownSteps builds the wind-down actions for session.
+func windDownSteps(session string, sched winddown.Schedule, now time.Time) winddown.Steps {
+	wc := windDownConfig()
+	dir := sessionProjectDir(session)
+
+	steps := winddown.Steps{
+		// Recording the wrap-up in the state is what stops assignment;
+		// this step only makes that visible in the audit log.
+		StopAssigning: func(context.Context) error { return nil },
+		WrapUp: func(context.Context) error {
+			return sendWrapUpPrompt(session, wc.WrapUpPrompt, sched.Deadline().Sub(now))
+		},
+		Summarize: func(context.Context) (string, error) {
+			sum, err := generateKillSummary(session)
+			if err != nil {
+				return "", err
+			}
+			if err := writeSummaryFile(dir, session, sum); err != nil {
+				return "", err
+			}
+			return filepath.Join(dir, ".ntm", "summaries"), nil
+		},
+		Checkpoint: func(context.Context) (string, error) {
+			cp, err := checkpoint.NewCapturer().Create(session, "winddown",
+				checkpoint.WithDescription("Automatic checkpoint at max session runtime"),
+			)
+			if err != nil {
+				return "", err
+			}
+			return cp.ID, nil
+		},
+	}
+
+	if wc.Verify && cfg != nil {
+		if runner := newVerifyRunner(cfg.Assign.Verify, dir); runner != nil {
+			steps.Verify = func(ctx context.Context) (string, error) {
+				res := runner.Run(ctx)
+				detail := fmt.Sprintf("%d/%d checks passed", len(res.Checks)-len(res.Failed()), len(res.Checks))
+				if !res.Passed {
+					return detail, fmt.Errorf("checks failed: %s", res.FailedNames())
+				}
+				return detail, nil
+			}
+		}
+	}
+	if wc.Kill {
+		steps.Kill = func(context.Context) error { return tmux.KillSession(session) }
+	}
+	return steps
+}
+
+// sendWrapUpPrompt asks every agent pane of session to wrap up, with left
+// substituted for {{minutes}} in prompt.
+func sendWrapUpPrompt(session, prompt string, left time.Duration) error {
+	if prompt == "" {
+		prompt = config.DefaultWrapUpPrompt
+	}
+	minutes := int(left.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	prompt = strings.ReplaceAll(prompt, "{{minutes}}", strconv.Itoa(minutes))
+
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return err
+	}
+	opts := configPaneSendOptions()
+	var failed []string
+	for _, p := range panes {
+		if p.Type == tmux.AgentUser {
+			continue
+		}
+		if err := sendPromptToPaneWith(session, p, prompt, opts); err != nil {
+			failed = append(failed, fmt.Sprintf("pane %d: %v", p.Index, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("wrap-up prompt not delivered: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
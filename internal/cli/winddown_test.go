@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+	"github.com/Dicklesworthstone/ntm/internal/winddown"
+)
+
+func TestWindDownStopsAssignment(t *testing.T) {
+	util.SetSessionsDir(t.TempDir())
+	t.Cleanup(func() { util.SetSessionsDir("") })
+	t.Chdir(t.TempDir())
+	oldCfg := cfg
+	defer func() { cfg = oldCfg }()
+	cfg = config.Default()
+
+	if err := checkWindingDown("s1"); err != nil {
+		t.Fatalf("checkWindingDown() without state error: %v", err)
+	}
+
+	now := time.Now().UTC()
+	if err := winddown.Save(&winddown.State{
+		Session:  "s1",
+		Deadline: now.Add(time.Hour),
+		Phase:    winddown.PhaseWrapUp,
+		WrapUpAt: &now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !windingDown("s1") || checkWindingDown("s1") == nil {
+		t.Fatal("session in its wrap-up window still takes new tasks")
+	}
+
+	// No limit is configured any more, so the state is stale.
+	if err := windDownTick(context.Background(), "s1", now); err != nil {
+		t.Fatalf("windDownTick() error: %v", err)
+	}
+	if windingDown("s1") {
+		t.Error("stale wind-down state not cleared")
+	}
+}
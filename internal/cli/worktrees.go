@@ -29,6 +29,7 @@ directory with its own branch.`,
 		newWorktreesMergeCmd(),
 		newWorktreesCleanCmd(),
 		newWorktreesRemoveCmd(),
+		newWorktreesPRCmd(),
 	)
 
 	return cmd
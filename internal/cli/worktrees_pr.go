@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Dicklesworthstone/ntm/internal/githost"
+	"github.com/Dicklesworthstone/ntm/internal/output"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+// maxPRScores caps how many score records go into a pull request body.
+const maxPRScores = 5
+
+type worktreePROptions struct {
+	session  string
+	task     string
+	taskURL  string
+	base     string
+	remote   string
+	title    string
+	provider string
+	apiURL   string
+	draft    bool
+	noPush   bool
+	dryRun   bool
+}
+
+// worktreePRResult is the JSON output of `ntm worktrees pr`.
+type worktreePRResult struct {
+	Provider    githost.Kind         `json:"provider"`
+	Session     string               `json:"session"`
+	Agent       string               `json:"agent"`
+	Branch      string               `json:"branch"`
+	Base        string               `json:"base"`
+	Title       string               `json:"title"`
+	Body        string               `json:"body,omitempty"`
+	DryRun      bool                 `json:"dry_run,omitempty"`
+	PullRequest *githost.PullRequest `json:"pull_request,omitempty"`
+	Conflicts   []githost.Conflict   `json:"conflicts"`
+	Commented   int                  `json:"commented"`
+	Warnings    []string             `json:"warnings,omitempty"`
+}
+
+func newWorktreesPRCmd() *cobra.Command {
+	var opts worktreePROptions
+
+	cmd := &cobra.Command{
+		Use:   "pr <agent-name>",
+		Short: "Open a pull request for an agent's worktree branch",
+		Long: `Push an agent's worktree branch and open a pull request (GitHub) or
+merge request (GitLab) for it.
+
+The description is built from the agent's session summary and recorded
+score metrics, and links the task ID when --task is given. Files the branch
+changes that other agents' branches also change, or that conflict detection
+flags (see 'ntm robot conflicts'), are listed in the description and get a
+review comment each.
+
+The host is detected from the remote URL. The API token is read from
+GITHUB_TOKEN (or GH_TOKEN) or GITLAB_TOKEN. If a request for the branch is
+already open it is reused and only the conflict comments are posted.
+
+Examples:
+  ntm worktrees pr cc_1 --task bd-42
+  ntm worktrees pr cod_2 --base develop --draft
+  ntm worktrees pr cc_1 --dry-run                       # Print the title and body only
+  ntm worktrees pr cc_1 --provider gitlab --api-url https://git.corp/api/v4`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runWorktreesPR(cmd.Context(), args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.session, "session", "", "Session that owns the worktree (default: current session or directory name)")
+	cmd.Flags().StringVar(&opts.task, "task", "", "Task or bead ID to link in the title and description")
+	cmd.Flags().StringVar(&opts.taskURL, "task-url", "", "URL the task ID links to")
+	cmd.Flags().StringVar(&opts.base, "base", "", "Target branch (default: the remote's default branch)")
+	cmd.Flags().StringVar(&opts.remote, "remote", "origin", "Git remote to push to and open the request on")
+	cmd.Flags().StringVar(&opts.title, "title", "", "Title (default: task ID and first accomplishment)")
+	cmd.Flags().StringVar(&opts.provider, "provider", "", "Hosting service: github or gitlab (default: from the remote URL)")
+	cmd.Flags().StringVar(&opts.apiURL, "api-url", "", "API root for self-hosted instances")
+	cmd.Flags().BoolVar(&opts.draft, "draft", false, "Open as a draft")
+	cmd.Flags().BoolVar(&opts.noPush, "no-push", false, "Do not push the branch first")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Build the title, body, and conflicts without pushing or calling the API")
+
+	return cmd
+}
+
+func runWorktreesPR(ctx context.Context, agentName string, opts worktreePROptions) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	session := opts.session
+	if session == "" {
+		session = tmux.GetCurrentSession()
+	}
+	if session == "" {
+		session = filepath.Base(dir)
+	}
+
+	manager := worktrees.NewManager(dir, session)
+	info, err := manager.GetWorktreeForAgent(agentName)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree info: %w", err)
+	}
+	if !info.Created {
+		return fmt.Errorf("no worktree found for agent: %s", agentName)
+	}
+
+	remoteURL, err := prGit(dir, "remote", "get-url", opts.remote)
+	if err != nil {
+		return fmt.Errorf("remote %q: %w", opts.remote, err)
+	}
+	remote, err := githost.ParseRemote(remoteURL)
+	if err != nil {
+		return err
+	}
+	if opts.provider != "" {
+		remote.Kind = githost.Kind(strings.ToLower(opts.provider))
+	}
+
+	base := opts.base
+	if base == "" {
+		base = prDefaultBranch(dir, opts.remote)
+	}
+	baseRef := base
+	if _, err := prGit(dir, "rev-parse", "--verify", "--quiet", opts.remote+"/"+base); err == nil {
+		baseRef = opts.remote + "/" + base
+	}
+	changed, err := prChangedFiles(dir, baseRef, info.BranchName)
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return fmt.Errorf("branch %s has no changes against %s", info.BranchName, baseRef)
+	}
+
+	result := &worktreePRResult{
+		Provider: remote.Kind,
+		Session:  session,
+		Agent:    agentName,
+		Branch:   info.BranchName,
+		Base:     base,
+		DryRun:   opts.dryRun,
+	}
+	warn := func(format string, args ...any) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	in := githost.BodyInput{
+		TaskID:  opts.task,
+		TaskURL: opts.taskURL,
+		Session: session,
+		Agent:   agentName,
+		Branch:  info.BranchName,
+	}
+	if sum, err := prAgentSummary(ctx, session, agentName, info.Path); err != nil {
+		warn("no session summary: %v", err)
+	} else {
+		in.Summary = sum
+	}
+	in.Scores = prAgentScores(session, agentName)
+	in.Conflicts = prConflicts(ctx, manager, dir, session, agentName, baseRef, changed, warn)
+
+	result.Title = opts.title
+	if result.Title == "" {
+		result.Title = in.Title()
+	}
+	result.Body = in.Body()
+	result.Conflicts = in.Conflicts
+	if result.Conflicts == nil {
+		result.Conflicts = []githost.Conflict{}
+	}
+
+	if opts.dryRun {
+		if IsJSONOutput() {
+			return output.PrintJSON(result)
+		}
+		for _, w := range result.Warnings {
+			output.PrintWarning(w)
+		}
+		fmt.Printf("Title: %s\n\n%s", result.Title, result.Body)
+		return nil
+	}
+
+	token := githost.TokenFromEnv(remote.Kind)
+	if token == "" {
+		return fmt.Errorf("no API token for %s: set GITHUB_TOKEN (or GH_TOKEN) or GITLAB_TOKEN", remote.Host)
+	}
+	provider, err := githost.New(remote, token, opts.apiURL)
+	if err != nil {
+		return err
+	}
+	if !opts.noPush {
+		if _, err := prGit(info.Path, "push", "--set-upstream", opts.remote, info.BranchName); err != nil {
+			return fmt.Errorf("push %s: %w", info.BranchName, err)
+		}
+	}
+
+	pr, err := provider.OpenPullRequest(ctx, githost.PullRequestOptions{
+		Head:  info.BranchName,
+		Base:  base,
+		Title: result.Title,
+		Body:  result.Body,
+		Draft: opts.draft,
+	})
+	if err != nil {
+		return fmt.Errorf("open pull request: %w", err)
+	}
+	result.PullRequest = pr
+	for _, c := range in.Conflicts {
+		if err := provider.CommentFile(ctx, pr, c.Path, c.Comment()); err != nil {
+			warn("comment on %s: %v", c.Path, err)
+			continue
+		}
+		result.Commented++
+	}
+	result.Body = ""
+
+	if IsJSONOutput() {
+		return output.PrintJSON(result)
+	}
+	for _, w := range result.Warnings {
+		output.PrintWarning(w)
+	}
+	verb := "Opened"
+	if pr.Existing {
+		verb = "Found open"
+	}
+	output.SuccessCheck(fmt.Sprintf("%s pull request #%d: %s", verb, pr.Number, pr.URL))
+	if len(in.Conflicts) > 0 {
+		fmt.Printf("  Posted %d of %d conflict warnings\n", result.Commented, len(in.Conflicts))
+	}
+	return nil
+}
+
+// prAgentSummary summarizes the agent's pane output, if its session is running.
+func prAgentSummary(ctx context.Context, session, agentName, worktreePath string) (*summary.SessionSummary, error) {
+	if !tmux.SessionExists(session) {
+		return nil, fmt.Errorf("session %q is not running", session)
+	}
+	panes, err := tmux.GetPanes(session)
+	if err != nil {
+		return nil, err
+	}
+	for _, pane := range panes {
+		if fmt.Sprintf("%s_%d", strings.ToLower(string(pane.Type)), pane.NTMIndex) != agentName {
+			continue
+		}
+		out, err := tmux.CapturePaneOutput(pane.ID, 500)
+		if err != nil {
+			return nil, err
+		}
+		return summary.SummarizeSession(ctx, summary.Options{
+			Session:    session,
+			Outputs:    []summary.AgentOutput{{AgentID: pane.ID, AgentType: string(pane.Type), Output: out}},
+			Format:     summary.FormatDetailed,
+			ProjectDir: worktreePath,
+		})
+	}
+	return nil, fmt.Errorf("no pane for agent %s in session %s", agentName, session)
+}
+
+// prAgentScores returns the agent's most recent score records in the session.
+func prAgentScores(session, agentName string) []*scoring.Score {
+	scores, err := scoring.DefaultTracker().QueryScores(scoring.Query{
+		Session: session,
+		Since:   time.Now().AddDate(0, 0, -scoring.DefaultRetentionDays),
+	})
+	if err != nil {
+		return nil
+	}
+	var mine []*scoring.Score
+	for _, s := range scores {
+		if s.AgentName == agentName {
+			mine = append(mine, s)
+		}
+	}
+	if len(mine) > maxPRScores {
+		mine = mine[len(mine)-maxPRScores:]
+	}
+	return mine
+}
+
+// prConflicts finds files changed on the branch that other agents' branches
+// also change, plus detected conflicts in the main worktree on those files.
+func prConflicts(ctx context.Context, manager *worktrees.WorktreeManager, dir, session, agentName, baseRef string, changed []string, warn func(string, ...any)) []githost.Conflict {
+	others := make(map[string][]string)
+	list, err := manager.ListWorktrees()
+	if err != nil {
+		warn("list worktrees: %v", err)
+	}
+	for _, wt := range list {
+		if wt.AgentName == agentName || wt.Error != "" {
+			continue
+		}
+		files, err := prChangedFiles(dir, baseRef, wt.BranchName)
+		if err != nil {
+			warn("diff %s: %v", wt.BranchName, err)
+			continue
+		}
+		others[wt.AgentName] = files
+	}
+	conflicts := githost.BranchConflicts(changed, others)
+
+	inBranch := make(map[string]bool, len(changed))
+	for _, f := range changed {
+		inBranch[f] = true
+	}
+	detected, err := robot.GetConflicts(ctx, robot.ConflictsOptions{RepoPath: dir, Session: session})
+	if err != nil || !detected.Success {
+		warn("conflict detection unavailable")
+		return conflicts
+	}
+	for _, c := range detected.Conflicts {
+		if !inBranch[c.Path] {
+			continue
+		}
+		conflicts = append(conflicts, githost.Conflict{
+			Path:    c.Path,
+			Reason:  strings.ReplaceAll(string(c.Reason), "_", " "),
+			Agents:  append(append([]string{}, c.LikelyModifiers...), c.ReservationHolders...),
+			Details: c.Details,
+		})
+	}
+	return conflicts
+}
+
+// prDefaultBranch returns the remote's default branch, falling back to main.
+func prDefaultBranch(dir, remote string) string {
+	ref, err := prGit(dir, "symbolic-ref", "--short", "refs/remotes/"+remote+"/HEAD")
+	if err != nil {
+		return "main"
+	}
+	return strings.TrimPrefix(ref, remote+"/")
+}
+
+func prChangedFiles(dir, baseRef, branch string) ([]string, error) {
+	out, err := prGit(dir, "diff", "--name-only", baseRef+"..."+branch)
+	if err != nil {
+		return nil, fmt.Errorf("diff %s...%s: %w", baseRef, branch, err)
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+func prGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
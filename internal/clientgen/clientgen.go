@@ -0,0 +1,384 @@
+// Package clientgen generates thin REST API clients for other languages.
+//
+// The operations come from Endpoints and the request bodies from the robot
+// JSON schemas of the server's request types, so a generated client follows
+// the server when either changes. Templates are embedded; each language
+// ships its client together with a generated test suite.
+package clientgen
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+)
+
+//go:embed templates/python/*.tmpl
+var templateFS embed.FS
+
+// Languages lists the client languages Generate supports.
+var Languages = []string{"python"}
+
+// Param is a path or query parameter of an endpoint.
+type Param struct {
+	Name     string
+	Type     string // JSON Schema type of the value (or of each item when Repeated)
+	Repeated bool   // query parameter given once per value
+	Required bool
+	Doc      string
+}
+
+// Field is a property of a JSON request body.
+type Field struct {
+	Name     string
+	Type     string // JSON Schema type
+	Items    string // item type for arrays
+	Required bool
+	Doc      string
+}
+
+// Endpoint is one REST operation exposed by generated clients.
+type Endpoint struct {
+	Name    string // snake_case method name
+	Method  string
+	Path    string // with {param} placeholders
+	Summary string
+	// Query lists the query parameters; path parameters are taken from Path.
+	Query []Param
+	// Body lists the JSON request body fields, if any.
+	Body []Field
+	// Result is the response key holding the value to return; empty returns
+	// the whole response.
+	Result string
+	// Stream marks a server-sent event stream.
+	Stream bool
+}
+
+// PathParams returns the placeholder names in the endpoint path, in order.
+func (e Endpoint) PathParams() []string {
+	var names []string
+	for _, part := range strings.Split(e.Path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			names = append(names, part[1:len(part)-1])
+		}
+	}
+	return names
+}
+
+// HasOptional reports whether the endpoint takes optional arguments.
+func (e Endpoint) HasOptional() bool {
+	for _, p := range e.Query {
+		if !p.Required {
+			return true
+		}
+	}
+	for _, f := range e.Body {
+		if !f.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoints returns the operations generated clients cover: sessions,
+// sending, the event stream, and reservation conflicts.
+func Endpoints() []Endpoint {
+	return []Endpoint{
+		{
+			Name:    "health",
+			Method:  "GET",
+			Path:    "/api/v1/health",
+			Summary: "Report whether the server is up.",
+		},
+		{
+			Name:    "list_sessions",
+			Method:  "GET",
+			Path:    "/api/v1/sessions",
+			Summary: "List sessions from the state store, one page at a time.",
+			Query: []Param{
+				{Name: "limit", Type: "integer", Doc: "page size; server default when omitted"},
+				{Name: "cursor", Type: "string", Doc: "next_cursor of the previous page"},
+			},
+		},
+		{
+			Name:    "get_session",
+			Method:  "GET",
+			Path:    "/api/v1/sessions/{session}",
+			Summary: "Return one session from the state store.",
+			Result:  "session",
+		},
+		{
+			Name:    "list_agents",
+			Method:  "GET",
+			Path:    "/api/v1/sessions/{session}/agents",
+			Summary: "Return the agents recorded for a session.",
+			Result:  "agents",
+		},
+		{
+			Name:    "send",
+			Method:  "POST",
+			Path:    "/api/v1/sessions/{session}/agents/send",
+			Summary: "Send a message to agents in a session.",
+			Body:    bodyFields(serve.AgentSendRequest{}),
+		},
+		{
+			Name:    "interrupt",
+			Method:  "POST",
+			Path:    "/api/v1/sessions/{session}/agents/interrupt",
+			Summary: "Send Ctrl+C to agents in a session, optionally followed by a message.",
+			Body:    bodyFields(serve.AgentInterruptRequest{}),
+		},
+		{
+			Name:    "check_conflicts",
+			Method:  "GET",
+			Path:    "/api/v1/reservations/conflicts",
+			Summary: "Report file reservations that conflict with the given paths.",
+			Query: []Param{
+				{Name: "paths", Type: "string", Repeated: true, Required: true, Doc: "project-relative paths or globs"},
+			},
+		},
+		{
+			Name:    "events",
+			Method:  "GET",
+			Path:    "/events",
+			Summary: "Stream server events, optionally for one session.",
+			Query: []Param{
+				{Name: "session", Type: "string", Doc: "only events for this session"},
+			},
+			Stream: true,
+		},
+	}
+}
+
+// bodyFields lists the request body fields of v from its robot schema:
+// required fields first in declaration order, then optional ones by name.
+func bodyFields(v any) []Field {
+	schema := robot.SchemaFor(v, "request")
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		if !required[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	names = append(append([]string{}, schema.Required...), names...)
+
+	fields := make([]Field, 0, len(names))
+	for _, name := range names {
+		prop := schema.Properties[name]
+		f := Field{Name: name, Type: prop.Type, Required: required[name], Doc: prop.Description}
+		if prop.Items != nil {
+			f.Items = prop.Items.Type
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// Generate renders the client for lang into dir, creating it if needed, and
+// returns the paths written.
+func Generate(lang, dir, version string) ([]string, error) {
+	files, err := Render(lang, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create output dir: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	written := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0o644); err != nil {
+			return written, fmt.Errorf("write %s: %w", name, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// Render renders the client for lang and returns its files by name.
+func Render(lang, version string) (map[string][]byte, error) {
+	if lang != "python" {
+		return nil, fmt.Errorf("unsupported client language %q (supported: %s)", lang, strings.Join(Languages, ", "))
+	}
+	if version == "" {
+		version = "dev"
+	}
+
+	tmpl, err := template.New(lang).Funcs(pythonFuncs).ParseFS(templateFS, "templates/"+lang+"/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("parse %s templates: %w", lang, err)
+	}
+
+	data := struct {
+		Version   string
+		Endpoints []Endpoint
+	}{version, Endpoints()}
+
+	files := make(map[string][]byte)
+	for _, t := range tmpl.Templates() {
+		name, ok := strings.CutSuffix(t.Name(), ".tmpl")
+		if !ok {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("render %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// pythonFuncs are the template helpers of the Python client.
+var pythonFuncs = template.FuncMap{
+	"pyType":     pyType,
+	"pyField":    func(f Field) string { return pyType(f.Type, f.Items) },
+	"pyParam":    pyParam,
+	"pyString":   pyString,
+	"sampleArgs": sampleArgs,
+	"samplePath": samplePath,
+	"sampleBody": sampleBody,
+}
+
+// pyType maps a JSON Schema type to a Python annotation.
+func pyType(typ, items string) string {
+	switch typ {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		if items == "" {
+			return "List[Any]"
+		}
+		return "List[" + pyType(items, "") + "]"
+	case "object":
+		return "Dict[str, Any]"
+	default:
+		return "Any"
+	}
+}
+
+func pyParam(p Param) string {
+	if p.Repeated {
+		return pyType("array", p.Type)
+	}
+	return pyType(p.Type, "")
+}
+
+// pyString quotes s as a Python string literal.
+func pyString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// pySample returns a Python literal usable as a value of the given type in
+// generated tests.
+func pySample(typ, items string) string {
+	switch typ {
+	case "string":
+		return `"x"`
+	case "integer":
+		return "1"
+	case "number":
+		return "1.5"
+	case "boolean":
+		return "True"
+	case "array":
+		return "[" + pySample(items, "") + "]"
+	case "object":
+		return "{}"
+	default:
+		return "None"
+	}
+}
+
+// sampleArgs is the Python argument list generated tests pass: "my proj/1"
+// for path parameters and a pySample value for each required argument.
+func sampleArgs(e Endpoint) string {
+	var args []string
+	for range e.PathParams() {
+		args = append(args, pyString("my proj/1"))
+	}
+	for _, p := range e.Query {
+		if !p.Required {
+			continue
+		}
+		if p.Repeated {
+			args = append(args, pySample("array", p.Type))
+		} else {
+			args = append(args, pySample(p.Type, ""))
+		}
+	}
+	for _, f := range e.Body {
+		if f.Required {
+			args = append(args, pySample(f.Type, f.Items))
+		}
+	}
+	return strings.Join(args, ", ")
+}
+
+// samplePath is the escaped request path generated tests expect when every
+// path parameter is "my proj/1", which exercises escaping.
+func samplePath(e Endpoint) string {
+	path := e.Path
+	for _, name := range e.PathParams() {
+		path = strings.ReplaceAll(path, "{"+name+"}", "my%20proj%2F1")
+	}
+	return path
+}
+
+// sampleBody is the JSON body generated tests expect when only the required
+// fields are passed, with pySample values.
+func sampleBody(e Endpoint) string {
+	body := make(map[string]any)
+	for _, f := range e.Body {
+		if !f.Required {
+			continue
+		}
+		var v any
+		_ = json.Unmarshal([]byte(jsonSample(f.Type, f.Items)), &v)
+		body[f.Name] = v
+	}
+	data, _ := json.Marshal(body)
+	return string(data)
+}
+
+func jsonSample(typ, items string) string {
+	switch typ {
+	case "boolean":
+		return "true"
+	case "array":
+		return "[" + jsonSample(items, "") + "]"
+	case "":
+		return "null"
+	default:
+		// Strings, numbers, and objects are written the same in both.
+		return pySample(typ, items)
+	}
+}
@@ -0,0 +1,104 @@
+package clientgen
+
+import (
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+)
+
+var placeholder = regexp.MustCompile(`\{[^}]+\}`)
+
+// Every generated method must hit a route the server actually serves.
+func TestEndpoints_AreRouted(t *testing.T) {
+	routes := make(map[string]bool)
+	err := chi.Walk(serve.New(serve.Config{}).Router(), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[method+" "+placeholder.ReplaceAllString(strings.TrimSuffix(route, "/"), "{}")] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range Endpoints() {
+		key := e.Method + " " + placeholder.ReplaceAllString(e.Path, "{}")
+		if !routes[key] {
+			t.Errorf("%s: %s %s is not routed by the server", e.Name, e.Method, e.Path)
+		}
+	}
+}
+
+func TestEndpoints_BodyFromSchema(t *testing.T) {
+	var send *Endpoint
+	for _, e := range Endpoints() {
+		if e.Name == "send" {
+			send = &e
+		}
+	}
+	if send == nil {
+		t.Fatal("send endpoint missing")
+	}
+	if len(send.Body) == 0 || send.Body[0].Name != "message" || !send.Body[0].Required || send.Body[0].Type != "string" {
+		t.Fatalf("send body = %+v, want required message first", send.Body)
+	}
+	for _, f := range send.Body[1:] {
+		if f.Required {
+			t.Errorf("field %s should be optional", f.Name)
+		}
+		if f.Name == "panes" && (f.Type != "array" || f.Items != "string") {
+			t.Errorf("panes = %+v", f)
+		}
+	}
+}
+
+func TestRender_UnsupportedLanguage(t *testing.T) {
+	if _, err := Render("cobol", "dev"); err == nil {
+		t.Error("expected error for unsupported language")
+	}
+}
+
+func TestGenerate_Python(t *testing.T) {
+	dir := t.TempDir()
+	files, err := Generate("python", dir, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("files = %v", files)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "ntm_client.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(data)
+	for _, want := range []string{
+		`VERSION = "1.2.3"`,
+		"def send(\n        self,\n        session: str,\n        message: str,\n        *,",
+		"def check_conflicts(",
+		"def events(",
+		"-> Iterator[Event]:",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("ntm_client.py missing %q", want)
+		}
+	}
+
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not installed; skipping generated test suite")
+	}
+	cmd := exec.Command(python, "-m", "unittest", "test_ntm_client")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PYTHONDONTWRITEBYTECODE=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated tests failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,334 @@
+package cm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// MemoryFile is the memory file distillation proposes additions to.
+const MemoryFile = "MEMORY.md"
+
+// Distillation limits.
+const (
+	// distillMaxPerHeading caps the proposals added under one heading.
+	distillMaxPerHeading = 5
+	// distillMinConflicts is how often a file (or pair of files) must have
+	// been contested before it is worth remembering.
+	distillMinConflicts = 2
+	// distillMaxText truncates proposal text taken from agent output.
+	distillMaxText = 200
+	// diffContext is the number of unchanged lines shown around each change.
+	diffContext = 3
+)
+
+// Default headings for sections that do not exist yet.
+const (
+	headingPatterns  = "Patterns"
+	headingGotchas   = "Gotchas"
+	headingDecisions = "Decisions"
+)
+
+// DistillInput is what a distillation pass learns from.
+type DistillInput struct {
+	Summary *summary.SessionSummary
+	// Conflicts are the session's file conflict episodes.
+	Conflicts []tracker.ConflictEpisode
+}
+
+// MemoryProposal is one bullet proposed for the project memory.
+type MemoryProposal struct {
+	Kind    SectionKind `json:"kind"`
+	Heading string      `json:"heading"`
+	Text    string      `json:"text"`
+	Reason  string      `json:"reason"`
+}
+
+// Distill proposes memory additions from a session's summary and conflict
+// history. Proposals already present in mem are dropped.
+func Distill(mem *Memory, in DistillInput) []MemoryProposal {
+	d := distiller{mem: mem, seen: map[string]bool{}, perHeading: map[string]int{}}
+	if mem != nil {
+		for _, s := range mem.Sections {
+			for _, line := range strings.Split(s.Body, "\n") {
+				d.seen[normalizeBullet(line)] = true
+			}
+		}
+	}
+
+	if s := in.Summary; s != nil {
+		for _, dec := range s.Decisions {
+			d.add(SectionDecisions, headingDecisions, dec, "decision recorded in session "+s.Session)
+		}
+		for _, e := range s.Errors {
+			d.add(SectionGuards, headingGotchas, "Watch for: "+e, "error seen in session "+s.Session)
+		}
+	}
+
+	if len(in.Conflicts) > 0 {
+		stats := tracker.AnalyzeConflicts(in.Conflicts, 0, time.Now())
+		for _, f := range stats.Files {
+			if f.Count < distillMinConflicts {
+				continue
+			}
+			text := fmt.Sprintf("Coordinate before editing %s: agents contended for it %d times", f.Path, f.Count)
+			if agents := conflictAgents(in.Conflicts, f.Path); len(agents) > 0 {
+				text += " (" + strings.Join(agents, ", ") + ")"
+			}
+			d.add(SectionGuards, headingGotchas, text, "file conflict history")
+		}
+		for _, p := range stats.FilePairs {
+			if p.Count < distillMinConflicts {
+				continue
+			}
+			text := fmt.Sprintf("Reserve %s and %s together; they are contended at the same time", p.A, p.B)
+			d.add(SectionPatterns, headingPatterns, text, "file conflict history")
+		}
+	}
+	return d.out
+}
+
+type distiller struct {
+	mem        *Memory
+	seen       map[string]bool
+	perHeading map[string]int
+	out        []MemoryProposal
+}
+
+func (d *distiller) add(kind SectionKind, heading, text, reason string) {
+	text = util.Truncate(strings.Join(strings.Fields(text), " "), distillMaxText)
+	if text == "" {
+		return
+	}
+	key := normalizeBullet(text)
+	if d.seen[key] {
+		return
+	}
+	heading = d.heading(kind, heading)
+	if d.perHeading[heading] >= distillMaxPerHeading {
+		return
+	}
+	d.seen[key] = true
+	d.perHeading[heading]++
+	d.out = append(d.out, MemoryProposal{Kind: kind, Heading: heading, Text: text, Reason: reason})
+}
+
+// heading returns the existing MEMORY.md section a proposal belongs under,
+// or the default heading when there is none.
+func (d *distiller) heading(kind SectionKind, fallback string) string {
+	if d.mem == nil {
+		return fallback
+	}
+	for _, s := range d.mem.Sections {
+		if s.Source != MemoryFile {
+			continue
+		}
+		if fallback == headingGotchas {
+			if strings.Contains(strings.ToLower(s.Title), "gotcha") {
+				return s.Title
+			}
+			continue
+		}
+		if s.Kind == kind {
+			return s.Title
+		}
+	}
+	return fallback
+}
+
+var bulletPrefix = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+`)
+
+func normalizeBullet(line string) string {
+	line = bulletPrefix.ReplaceAllString(line, "")
+	return strings.ToLower(strings.Join(strings.Fields(line), " "))
+}
+
+func conflictAgents(episodes []tracker.ConflictEpisode, path string) []string {
+	set := map[string]bool{}
+	for _, ep := range episodes {
+		if ep.Path != path {
+			continue
+		}
+		for _, a := range ep.Agents {
+			set[a] = true
+		}
+	}
+	agents := make([]string, 0, len(set))
+	for a := range set {
+		agents = append(agents, a)
+	}
+	sort.Strings(agents)
+	return agents
+}
+
+// ApplyProposals returns content with each proposal added as a bullet at the
+// end of its section. Sections that do not exist are appended.
+func ApplyProposals(content string, proposals []MemoryProposal) string {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	if content == "" {
+		lines = []string{"# Project Memory"}
+	}
+
+	var headings []string
+	byHeading := map[string][]string{}
+	for _, p := range proposals {
+		if _, ok := byHeading[p.Heading]; !ok {
+			headings = append(headings, p.Heading)
+		}
+		byHeading[p.Heading] = append(byHeading[p.Heading], "- "+p.Text)
+	}
+
+	for _, h := range headings {
+		bullets := byHeading[h]
+		start := -1
+		for i, line := range lines {
+			if strings.TrimSpace(strings.TrimPrefix(line, "## ")) == h && strings.HasPrefix(line, "## ") {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			lines = append(lines, "", "## "+h)
+			lines = append(lines, bullets...)
+			continue
+		}
+		// Insert after the last non-blank line of the section.
+		end := start
+		for i := start + 1; i < len(lines) && !strings.HasPrefix(lines[i], "## ") && !strings.HasPrefix(lines[i], "# "); i++ {
+			if strings.TrimSpace(lines[i]) != "" {
+				end = i
+			}
+		}
+		rest := append(append([]string{}, bullets...), lines[end+1:]...)
+		lines = append(lines[:end+1], rest...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// UnifiedDiff renders the change from before to after as a unified diff of
+// path (relative to the project root), suitable for `git apply`.
+func UnifiedDiff(path, before, after string) string {
+	if before == after {
+		return ""
+	}
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	type diffLine struct {
+		op   byte
+		text string
+	}
+	var ops []diffLine
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line != "" {
+				ops = append(ops, diffLine{op, line})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	oldName, newName := "a/"+filepath.ToSlash(path), "b/"+filepath.ToSlash(path)
+	if before == "" {
+		oldName = "/dev/null"
+	}
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(ops); {
+		if ops[i].op == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk while changes are within 2*context lines.
+		start := max(0, i-diffContext)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].op != ' ' {
+				end = j
+			} else if j-end > 2*diffContext {
+				break
+			}
+		}
+		stop := min(len(ops), end+diffContext+1)
+
+		oldStart, newStart := 1, 1
+		for _, op := range ops[:start] {
+			if op.op != '+' {
+				oldStart++
+			}
+			if op.op != '-' {
+				newStart++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:stop] {
+			if op.op != '+' {
+				oldCount++
+			}
+			if op.op != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, op := range ops[start:stop] {
+			sb.WriteByte(op.op)
+			sb.WriteString(op.text)
+			if !strings.HasSuffix(op.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = stop
+	}
+	return sb.String()
+}
+
+// MemoryProposalDiff renders proposals as a diff to the project's MEMORY.md.
+func MemoryProposalDiff(projectDir string, proposals []MemoryProposal) (string, error) {
+	if len(proposals) == 0 {
+		return "", nil
+	}
+	data, err := os.ReadFile(filepath.Join(projectDir, MemoryDir, MemoryFile))
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading memory file: %w", err)
+	}
+	before := string(data)
+	return UnifiedDiff(filepath.Join(MemoryDir, MemoryFile), before, ApplyProposals(before, proposals)), nil
+}
+
+// WriteMemoryProposal saves a proposal diff under .ntm/memory/proposals and
+// returns its path. The diff applies from the project root with `git apply`.
+func WriteMemoryProposal(projectDir, session, diff string, now time.Time) (string, error) {
+	dir := filepath.Join(projectDir, MemoryDir, "proposals")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating proposals directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.patch", session, now.Format("20060102-150405")))
+	if err := util.AtomicWriteFile(path, []byte(diff), 0644); err != nil {
+		return "", fmt.Errorf("writing memory proposal: %w", err)
+	}
+	return path, nil
+}
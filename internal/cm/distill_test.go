@@ -0,0 +1,123 @@
+package cm
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func TestDistill(t *testing.T) {
+	t.Parallel()
+	mem := &Memory{Sections: ParseMemory(MemoryFile, testMemory)}
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	episode := func(path string, at time.Time, agents ...string) tracker.ConflictEpisode {
+		resolved := at.Add(time.Minute)
+		return tracker.ConflictEpisode{Session: "proj", Path: path, Agents: agents, DetectedAt: at, ResolvedAt: &resolved}
+	}
+
+	got := Distill(mem, DistillInput{
+		Summary: &summary.SessionSummary{
+			Session:   "proj",
+			Decisions: []string{"Use chi for routing", "config is loaded ONCE in PersistentPreRun"},
+			Errors:    []string{"go test ./... failed: missing go.sum entry"},
+		},
+		Conflicts: []tracker.ConflictEpisode{
+			episode("internal/cli/send.go", base, "BlueLake", "GreenCastle"),
+			episode("internal/cli/root.go", base),
+			episode("internal/cli/send.go", base.Add(10*time.Second), "BlueLake"),
+			episode("internal/cli/root.go", base.Add(10*time.Second)),
+			episode("README.md", base.Add(time.Hour)),
+		},
+	})
+
+	want := map[string]string{
+		"Use chi for routing": "Architecture Decisions",
+		"Watch for: go test ./... failed: missing go.sum entry":                                                   "Gotchas",
+		"Coordinate before editing internal/cli/send.go: agents contended for it 2 times (BlueLake, GreenCastle)": "Gotchas",
+		"Reserve internal/cli/root.go and internal/cli/send.go together; they are contended at the same time":     "Key Patterns",
+	}
+	if len(got) != len(want)+1 {
+		t.Fatalf("got %d proposals, want %d: %+v", len(got), len(want)+1, got)
+	}
+	for _, p := range got {
+		if strings.Contains(p.Text, "PersistentPreRun") {
+			t.Errorf("decision already in memory was proposed: %q", p.Text)
+		}
+		if h, ok := want[p.Text]; ok && h != p.Heading {
+			t.Errorf("%q under %q, want %q", p.Text, p.Heading, h)
+		}
+	}
+}
+
+func TestApplyProposals(t *testing.T) {
+	t.Parallel()
+	in := "# Project Memory\n## Guards\n- Never commit .env files\n\n## Patterns\n- Use table-driven tests\n"
+	got := ApplyProposals(in, []MemoryProposal{
+		{Heading: "Guards", Text: "Never force-push main"},
+		{Heading: "Gotchas", Text: "Watch for: flaky tmux tests"},
+	})
+	want := "# Project Memory\n## Guards\n- Never commit .env files\n- Never force-push main\n\n## Patterns\n- Use table-driven tests\n\n## Gotchas\n- Watch for: flaky tmux tests\n"
+	if got != want {
+		t.Errorf("ApplyProposals =\n%s\nwant\n%s", got, want)
+	}
+	if got := ApplyProposals("", []MemoryProposal{{Heading: "Decisions", Text: "Use chi"}}); got != "# Project Memory\n\n## Decisions\n- Use chi\n" {
+		t.Errorf("ApplyProposals on empty file = %q", got)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	t.Parallel()
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n"
+	after := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	want := "--- a/x.md\n+++ b/x.md\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -10,3 +10,4 @@\n j\n k\n l\n+m\n"
+	if got := UnifiedDiff("x.md", before, after); got != want {
+		t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("x.md", before, before); got != "" {
+		t.Errorf("UnifiedDiff of identical content = %q", got)
+	}
+	if got := UnifiedDiff("x.md", "", "a\n"); !strings.HasPrefix(got, "--- /dev/null\n+++ b/x.md\n@@ -0,0 +1,1 @@\n+a\n") {
+		t.Errorf("new-file diff = %q", got)
+	}
+}
+
+func TestMemoryProposalAppliesWithGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	writeMemory(t, dir, MemoryFile, testMemory)
+	proposals := []MemoryProposal{{Heading: "Guards", Text: "Never force-push main"}, {Heading: "Gotchas", Text: "Watch for: flaky tmux tests"}}
+	diff, err := MemoryProposalDiff(dir, proposals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := WriteMemoryProposal(dir, "proj", diff, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "proj-20260301-100000.patch" {
+		t.Errorf("proposal path = %s", path)
+	}
+
+	cmd := exec.Command("git", "apply", path)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git apply: %v\n%s\n%s", err, out, diff)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, MemoryDir, MemoryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != ApplyProposals(testMemory, proposals) {
+		t.Errorf("applied memory =\n%s", data)
+	}
+}
@@ -0,0 +1,277 @@
+package cm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// MemoryDir is the project-relative directory holding memory files.
+const MemoryDir = ".ntm/memory"
+
+// memoryLoadsFile records which memory version each pane has loaded.
+const memoryLoadsFile = "loaded.json"
+
+// SectionKind classifies a memory section.
+type SectionKind string
+
+const (
+	// SectionPatterns holds conventions and practices to follow.
+	SectionPatterns SectionKind = "patterns"
+	// SectionGuards holds rules that must never be broken.
+	SectionGuards SectionKind = "guards"
+	// SectionDecisions holds past decisions and their rationale.
+	SectionDecisions SectionKind = "decisions"
+	// SectionOther holds any section that is not one of the above.
+	SectionOther SectionKind = "other"
+)
+
+// AllSectionKinds lists every section kind in render order.
+var AllSectionKinds = []SectionKind{SectionPatterns, SectionGuards, SectionDecisions, SectionOther}
+
+// ParseSectionKind parses a section kind name.
+func ParseSectionKind(s string) (SectionKind, error) {
+	k := SectionKind(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range AllSectionKinds {
+		if k == known {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown memory section %q (use patterns, guards, decisions, or other)", s)
+}
+
+// MemorySection is one "## " section of a memory file.
+type MemorySection struct {
+	Kind   SectionKind `json:"kind"`
+	Title  string      `json:"title"`
+	Body   string      `json:"body"`
+	Source string      `json:"source"`
+}
+
+// Memory is the parsed content of a project's memory files.
+type Memory struct {
+	// Version identifies the memory content; it changes whenever any
+	// memory file is edited, added, or removed.
+	Version  string          `json:"version"`
+	Files    []string        `json:"files"`
+	Sections []MemorySection `json:"sections"`
+}
+
+// Empty reports whether the memory has no sections.
+func (m *Memory) Empty() bool {
+	return m == nil || len(m.Sections) == 0
+}
+
+// LoadMemory reads every *.md file in the project's memory directory, in
+// name order. A missing directory yields empty memory.
+func LoadMemory(projectDir string) (*Memory, error) {
+	dir := filepath.Join(projectDir, MemoryDir)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	m := &Memory{}
+	h := sha256.New()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading memory file: %w", err)
+		}
+		name := filepath.Base(path)
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(data))
+		h.Write(data)
+		m.Files = append(m.Files, path)
+		m.Sections = append(m.Sections, ParseMemory(name, string(data))...)
+	}
+	if len(m.Files) > 0 {
+		m.Version = hex.EncodeToString(h.Sum(nil))[:12]
+	}
+	return m, nil
+}
+
+// ParseMemory splits a memory file into its "## " sections. Text before the
+// first section (such as the "# " title) is ignored, as are empty sections.
+func ParseMemory(source, content string) []MemorySection {
+	var sections []MemorySection
+	var cur *MemorySection
+	var body []string
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		cur.Body = strings.TrimSpace(strings.Join(body, "\n"))
+		if cur.Body != "" {
+			sections = append(sections, *cur)
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if title, ok := strings.CutPrefix(line, "## "); ok {
+			flush()
+			title = strings.TrimSpace(title)
+			cur = &MemorySection{Kind: classifySection(title), Title: title, Source: source}
+			body = nil
+			continue
+		}
+		if cur != nil {
+			body = append(body, line)
+		}
+	}
+	flush()
+	return sections
+}
+
+// classifySection maps a section heading to its kind.
+func classifySection(title string) SectionKind {
+	t := strings.ToLower(title)
+	switch {
+	case strings.Contains(t, "guard"), strings.Contains(t, "rule"), strings.Contains(t, "never"), strings.Contains(t, "constraint"):
+		return SectionGuards
+	case strings.Contains(t, "decision"), strings.Contains(t, "adr"):
+		return SectionDecisions
+	case strings.Contains(t, "pattern"), strings.Contains(t, "convention"), strings.Contains(t, "practice"):
+		return SectionPatterns
+	}
+	return SectionOther
+}
+
+// Select returns the sections of the given kinds, grouped in kind order.
+// Nil kinds selects every section.
+func (m *Memory) Select(kinds []SectionKind) []MemorySection {
+	if m == nil {
+		return nil
+	}
+	if kinds == nil {
+		kinds = AllSectionKinds
+	}
+	var out []MemorySection
+	for _, k := range AllSectionKinds {
+		if !containsKind(kinds, k) {
+			continue
+		}
+		for _, s := range m.Sections {
+			if s.Kind == k {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+func containsKind(kinds []SectionKind, k SectionKind) bool {
+	for _, kk := range kinds {
+		if kk == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Render formats the selected sections as a prompt. It returns "" when no
+// section is selected.
+func (m *Memory) Render(kinds []SectionKind) string {
+	sections := m.Select(kinds)
+	if len(sections) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Project Memory (version %s)\n", m.Version)
+	sb.WriteString("Keep the following project memory in mind for the rest of this session.\n")
+	for _, s := range sections {
+		fmt.Fprintf(&sb, "\n## %s\n%s\n", s.Title, s.Body)
+	}
+	return sb.String()
+}
+
+// MemoryLoad records the memory a pane was given.
+type MemoryLoad struct {
+	Session   string        `json:"session"`
+	Pane      int           `json:"pane"`
+	AgentType string        `json:"agent_type,omitempty"`
+	Version   string        `json:"version"`
+	Sections  []SectionKind `json:"sections"`
+	LoadedAt  time.Time     `json:"loaded_at"`
+}
+
+func memoryLoadKey(session string, pane int) string {
+	return fmt.Sprintf("%s:%d", session, pane)
+}
+
+// memoryLoadsMu serializes read-modify-write of the loads file; spawn
+// records loads from one goroutine per pane.
+var memoryLoadsMu sync.Mutex
+
+// RecordMemoryLoad stores the memory version a pane has loaded, replacing
+// any earlier record for the pane.
+func RecordMemoryLoad(projectDir string, load MemoryLoad) error {
+	memoryLoadsMu.Lock()
+	defer memoryLoadsMu.Unlock()
+
+	loads, err := readMemoryLoads(projectDir)
+	if err != nil {
+		return err
+	}
+	if load.LoadedAt.IsZero() {
+		load.LoadedAt = time.Now().UTC()
+	}
+	loads[memoryLoadKey(load.Session, load.Pane)] = load
+
+	data, err := json.MarshalIndent(loads, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(projectDir, MemoryDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating memory directory: %w", err)
+	}
+	return util.AtomicWriteFile(filepath.Join(dir, memoryLoadsFile), data, 0644)
+}
+
+// MemoryLoads returns the recorded loads for a session's panes, ordered by
+// pane index. An empty session returns every record.
+func MemoryLoads(projectDir, session string) ([]MemoryLoad, error) {
+	memoryLoadsMu.Lock()
+	loads, err := readMemoryLoads(projectDir)
+	memoryLoadsMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MemoryLoad, 0, len(loads))
+	for _, l := range loads {
+		if session == "" || l.Session == session {
+			out = append(out, l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Session != out[j].Session {
+			return out[i].Session < out[j].Session
+		}
+		return out[i].Pane < out[j].Pane
+	})
+	return out, nil
+}
+
+func readMemoryLoads(projectDir string) (map[string]MemoryLoad, error) {
+	loads := map[string]MemoryLoad{}
+	data, err := os.ReadFile(filepath.Join(projectDir, MemoryDir, memoryLoadsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return loads, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &loads); err != nil {
+		return nil, fmt.Errorf("parsing memory loads: %w", err)
+	}
+	return loads, nil
+}
@@ -0,0 +1,155 @@
+package cm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMemory = `# Project Memory
+## Key Patterns
+- Use table-driven tests
+- Follow Go idioms
+
+## Guards
+- Never commit .env files
+- Always run tests before commit
+
+## Architecture Decisions
+- Config is loaded once in PersistentPreRun
+
+## Empty
+
+## Glossary
+- ntm: named tmux manager
+`
+
+func writeMemory(t *testing.T, dir, name, content string) {
+	t.Helper()
+	memDir := filepath.Join(dir, MemoryDir)
+	if err := os.MkdirAll(memDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(memDir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+	t.Parallel()
+	sections := ParseMemory("MEMORY.md", testMemory)
+	want := []struct {
+		kind  SectionKind
+		title string
+	}{
+		{SectionPatterns, "Key Patterns"},
+		{SectionGuards, "Guards"},
+		{SectionDecisions, "Architecture Decisions"},
+		{SectionOther, "Glossary"},
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %d sections, want %d: %+v", len(sections), len(want), sections)
+	}
+	for i, w := range want {
+		if sections[i].Kind != w.kind || sections[i].Title != w.title || sections[i].Source != "MEMORY.md" {
+			t.Errorf("section %d = %+v, want %s %q", i, sections[i], w.kind, w.title)
+		}
+	}
+	if got := sections[1].Body; got != "- Never commit .env files\n- Always run tests before commit" {
+		t.Errorf("guards body = %q", got)
+	}
+}
+
+func TestLoadMemorySelectAndRender(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	empty, err := LoadMemory(dir)
+	if err != nil || !empty.Empty() || empty.Version != "" {
+		t.Fatalf("LoadMemory without files = %+v, %v", empty, err)
+	}
+
+	writeMemory(t, dir, "MEMORY.md", testMemory)
+	writeMemory(t, dir, "notes.txt", "## Patterns\n- ignored\n")
+	mem, err := LoadMemory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mem.Files) != 1 || len(mem.Version) != 12 {
+		t.Fatalf("memory = %+v", mem)
+	}
+
+	out := mem.Render([]SectionKind{SectionGuards, SectionPatterns})
+	if !strings.Contains(out, "version "+mem.Version) {
+		t.Errorf("render missing version: %q", out)
+	}
+	if strings.Index(out, "## Key Patterns") > strings.Index(out, "## Guards") {
+		t.Error("sections should render in kind order")
+	}
+	if strings.Contains(out, "Architecture Decisions") || strings.Contains(out, "Glossary") {
+		t.Errorf("unselected sections rendered: %q", out)
+	}
+	if got := mem.Render([]SectionKind{}); got != "" {
+		t.Errorf("empty selection rendered %q", got)
+	}
+	if got := len(mem.Select(nil)); got != 4 {
+		t.Errorf("Select(nil) = %d sections, want 4", got)
+	}
+
+	before := mem.Version
+	writeMemory(t, dir, "decisions.md", "## Decisions\n- Use chi for routing\n")
+	mem, err = LoadMemory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mem.Version == before || len(mem.Select([]SectionKind{SectionDecisions})) != 2 {
+		t.Errorf("adding a file: version %s -> %s, sections %+v", before, mem.Version, mem.Sections)
+	}
+}
+
+func TestParseSectionKind(t *testing.T) {
+	t.Parallel()
+	if k, err := ParseSectionKind(" Guards "); err != nil || k != SectionGuards {
+		t.Errorf("ParseSectionKind = %q, %v", k, err)
+	}
+	if _, err := ParseSectionKind("todos"); err == nil {
+		t.Error("unknown kind should fail")
+	}
+}
+
+func TestRecordMemoryLoad(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	if loads, err := MemoryLoads(dir, "proj"); err != nil || len(loads) != 0 {
+		t.Fatalf("MemoryLoads before any record = %v, %v", loads, err)
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, l := range []MemoryLoad{
+		{Session: "proj", Pane: 2, Version: "aaa", LoadedAt: at},
+		{Session: "proj", Pane: 1, Version: "aaa", LoadedAt: at},
+		{Session: "other", Pane: 1, Version: "ccc", LoadedAt: at},
+		{Session: "proj", Pane: 2, Version: "bbb", Sections: []SectionKind{SectionGuards}},
+	} {
+		if err := RecordMemoryLoad(dir, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loads, err := MemoryLoads(dir, "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loads) != 2 || loads[0].Pane != 1 || loads[1].Pane != 2 {
+		t.Fatalf("loads = %+v", loads)
+	}
+	if loads[1].Version != "bbb" || loads[1].LoadedAt.IsZero() || loads[1].Sections[0] != SectionGuards {
+		t.Errorf("latest load for pane 2 = %+v", loads[1])
+	}
+	if all, _ := MemoryLoads(dir, ""); len(all) != 3 {
+		t.Errorf("all loads = %d, want 3", len(all))
+	}
+}
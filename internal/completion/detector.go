@@ -10,7 +10,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/assign"
 	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 )
 
@@ -28,6 +31,8 @@ const (
 	MethodAgentMail DetectionMethod = "agent_mail"
 	// MethodPaneLost indicates the pane no longer exists
 	MethodPaneLost DetectionMethod = "pane_lost"
+	// MethodDoneSignal indicates per-agent done signals followed by a quiet period
+	MethodDoneSignal DetectionMethod = "done_signal"
 )
 
 // CompletionEvent represents a detected completion
@@ -41,6 +46,11 @@ type CompletionEvent struct {
 	Output     string          `json:"output"`      // Last N lines (for debugging)
 	IsFailed   bool            `json:"is_failed"`   // True if failure detected
 	FailReason string          `json:"fail_reason"` // Reason for failure
+
+	// Candidate marks a completion inferred from done signals. The detector
+	// leaves the assignment active; the consumer verifies and records it.
+	Candidate bool               `json:"candidate,omitempty"`
+	Signals   []agent.DoneSignal `json:"signals,omitempty"`
 }
 
 // DetectionConfig configures the detector behavior
@@ -53,6 +63,23 @@ type DetectionConfig struct {
 	DedupWindow       time.Duration // Prevent duplicate events (default 5s)
 	GracefulDegrading bool          // Fall back to lesser methods (default true)
 	CaptureLines      int           // Lines to capture for pattern matching (default 50)
+
+	// QuietPeriods is how long output must stay unchanged after an agent
+	// shows done signals before a candidate completion is emitted, keyed by
+	// agent type ("" = other types). Doubled when the only signal is a
+	// returned prompt.
+	QuietPeriods map[string]time.Duration
+}
+
+// DefaultQuietPeriods returns the per-agent quiet periods. Codex and Claude
+// Code print a clear end of turn; Gemini tends to pause between tool calls.
+func DefaultQuietPeriods() map[string]time.Duration {
+	return map[string]time.Duration{
+		string(agent.AgentTypeClaudeCode): 10 * time.Second,
+		string(agent.AgentTypeCodex):      10 * time.Second,
+		string(agent.AgentTypeGemini):     20 * time.Second,
+		"":                                30 * time.Second,
+	}
 }
 
 // DefaultConfig returns sensible default configuration
@@ -66,6 +93,7 @@ func DefaultConfig() DetectionConfig {
 		DedupWindow:       5 * time.Second,
 		GracefulDegrading: true,
 		CaptureLines:      50,
+		QuietPeriods:      DefaultQuietPeriods(),
 	}
 }
 
@@ -77,10 +105,22 @@ type CompletionDetector struct {
 	Patterns    []*regexp.Regexp // Completion patterns
 	FailPattern []*regexp.Regexp // Failure patterns
 
+	// RepoDir is the repository the agents work in; its uncommitted changes
+	// feed working sets ("" = not tracked).
+	RepoDir string
+	// Reservations lists an agent's reserved paths for its working set (optional).
+	Reservations ReservationLister
+	// VerifyCompletions moves completed assignments (candidates included)
+	// to verifying instead of completed; the consumer runs the checks and
+	// records the outcome.
+	VerifyCompletions bool
+
 	mu              sync.RWMutex
 	activityTracker map[int]*activityState // pane -> activity state
 	recentEvents    map[string]time.Time   // beadID -> last event time (for dedup)
 	brAvailable     *bool                  // nil = unknown, cached after first check
+	changed         []string               // Cached changed files in RepoDir
+	changedAt       time.Time
 }
 
 // activityState tracks output activity per pane
@@ -89,6 +129,7 @@ type activityState struct {
 	lastOutput     string
 	burstStarted   time.Time
 	burstActive    bool
+	candidateFor   string // Output a candidate completion was emitted for
 }
 
 // Default completion patterns (case-insensitive)
@@ -217,10 +258,16 @@ func (d *CompletionDetector) checkAll(ctx context.Context, events chan<- Complet
 				d.mu.Unlock()
 
 				// Update assignment store
-				if event.IsFailed {
+				if event.Candidate {
+					emitCandidate(d.Session, event)
+				}
+				switch {
+				case event.IsFailed:
 					_ = d.Store.MarkFailed(a.BeadID, event.FailReason)
-				} else {
-					_ = d.Store.MarkCompleted(a.BeadID)
+				case d.VerifyCompletions:
+					_ = d.finish(a, assignment.StatusVerifying)
+				case !event.Candidate:
+					_ = d.finish(a, assignment.StatusCompleted)
 				}
 
 				events <- *event
@@ -229,6 +276,17 @@ func (d *CompletionDetector) checkAll(ctx context.Context, events chan<- Complet
 	}
 }
 
+// finish moves an assignment to a done status, passing through working if
+// the agent never visibly started.
+func (d *CompletionDetector) finish(a *assignment.Assignment, status assignment.AssignmentStatus) error {
+	if a.Status == assignment.StatusAssigned {
+		if err := d.Store.MarkWorking(a.BeadID); err != nil {
+			return err
+		}
+	}
+	return d.Store.UpdateStatus(a.BeadID, status)
+}
+
 // checkAssignment checks a single assignment for completion
 func (d *CompletionDetector) checkAssignment(ctx context.Context, a *assignment.Assignment) *CompletionEvent {
 	startTime := a.AssignedAt
@@ -289,6 +347,9 @@ func (d *CompletionDetector) checkAssignment(ctx context.Context, a *assignment.
 		return nil
 	}
 
+	// Record what the task has touched before judging completion
+	d.trackWorkingSet(ctx, a, output)
+
 	// 4. Check for failure patterns
 	if reason := d.matchFailurePatterns(output); reason != "" {
 		return &CompletionEvent{
@@ -322,7 +383,8 @@ func (d *CompletionDetector) checkAssignment(ctx context.Context, a *assignment.
 		return event
 	}
 
-	return nil
+	// 7. Check per-agent done signals once output has gone quiet
+	return d.checkDoneSignals(a, output, startTime)
 }
 
 // CheckNow performs an immediate check for a specific pane
@@ -444,6 +506,9 @@ func (d *CompletionDetector) checkIdle(a *assignment.Assignment, output string,
 		if !state.burstActive {
 			state.burstActive = true
 			state.burstStarted = time.Now()
+			if d.Store != nil && a.Status == assignment.StatusAssigned {
+				_ = d.Store.MarkWorking(a.BeadID)
+			}
 		}
 		return nil
 	}
@@ -467,6 +532,91 @@ func (d *CompletionDetector) checkIdle(a *assignment.Assignment, output string,
 	return nil
 }
 
+// checkDoneSignals proposes a candidate completion when the agent's output
+// shows it finished its turn (final answer, returned prompt, cost summary,
+// or a done self-report) and has not changed for its quiet period. Each
+// quiet output is proposed once.
+func (d *CompletionDetector) checkDoneSignals(a *assignment.Assignment, output string, startTime time.Time) *CompletionEvent {
+	d.mu.RLock()
+	state := d.activityTracker[a.Pane]
+	if state == nil || !state.burstActive || state.candidateFor == output {
+		d.mu.RUnlock()
+		return nil
+	}
+	quietFor := time.Since(state.lastOutputTime)
+	d.mu.RUnlock()
+
+	agentType := agent.AgentType(assign.ParseAgentType(a.AgentType))
+	signals := agent.DetectDoneSignals(agentType, output)
+	if len(signals) == 0 {
+		return nil
+	}
+	quiet := d.quietPeriod(string(agentType))
+	strong := false
+	for _, s := range signals {
+		strong = strong || s.Strong()
+	}
+	if !strong {
+		quiet *= 2
+	}
+	if quietFor < quiet {
+		return nil
+	}
+
+	d.mu.Lock()
+	state.candidateFor = output
+	d.mu.Unlock()
+
+	return &CompletionEvent{
+		Pane:      a.Pane,
+		AgentType: a.AgentType,
+		BeadID:    a.BeadID,
+		Method:    MethodDoneSignal,
+		Timestamp: time.Now(),
+		Duration:  time.Since(startTime),
+		Output:    truncateOutput(output, 500),
+		Candidate: true,
+		Signals:   signals,
+	}
+}
+
+// quietPeriod returns the configured quiet period for an agent type.
+func (d *CompletionDetector) quietPeriod(agentType string) time.Duration {
+	periods := d.Config.QuietPeriods
+	if periods == nil {
+		periods = DefaultQuietPeriods()
+	}
+	if q, ok := periods[agentType]; ok {
+		return q
+	}
+	if q, ok := periods[""]; ok {
+		return q
+	}
+	return d.Config.IdleThreshold
+}
+
+// emitCandidate publishes a task.candidate_complete event.
+func emitCandidate(session string, event *CompletionEvent) {
+	kinds := make([]string, 0, len(event.Signals))
+	for _, s := range event.Signals {
+		kinds = append(kinds, string(s.Kind))
+	}
+	events.DefaultEmitter().Emit(events.NewWebhookEvent(
+		events.WebhookTaskCandidateComplete,
+		session,
+		fmt.Sprintf("%d", event.Pane),
+		event.AgentType,
+		fmt.Sprintf("Task %s looks complete (%s)", event.BeadID, strings.Join(kinds, ", ")),
+		map[string]string{
+			"bead_id":    event.BeadID,
+			"pane_index": fmt.Sprintf("%d", event.Pane),
+			"agent_type": event.AgentType,
+			"signals":    strings.Join(kinds, ","),
+			"duration":   event.Duration.Round(time.Second).String(),
+		},
+	))
+}
+
 // truncateOutput limits output to maxLen characters
 func truncateOutput(output string, maxLen int) string {
 	if len(output) <= maxLen {
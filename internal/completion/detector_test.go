@@ -277,6 +277,73 @@ func TestIdleDetection(t *testing.T) {
 	}
 }
 
+func TestDoneSignalDetection(t *testing.T) {
+	store := assignment.NewStore("test-session")
+	cfg := DefaultConfig()
+	cfg.QuietPeriods = map[string]time.Duration{"cc": 10 * time.Millisecond, "": time.Hour}
+	d := NewWithConfig("test-session", store, cfg)
+
+	now := time.Now()
+	a := &assignment.Assignment{BeadID: "bd-test", Pane: 1, AgentType: "claude", AssignedAt: now}
+	working := "⏺ Bash(go test ./...)\n✻ Cogitating… (3s · esc to interrupt)\n"
+	done := "⏺ Bash(go test ./...)\n  ⎿  ok\n⏺ Tests pass; the fix is in.\n❯ "
+
+	d.checkIdle(a, working, now)
+	if event := d.checkDoneSignals(a, working, now); event != nil {
+		t.Fatalf("no burst yet, got %+v", event)
+	}
+
+	d.checkIdle(a, done, now)
+	if event := d.checkDoneSignals(a, done, now); event != nil {
+		t.Fatalf("quiet period not elapsed, got %+v", event)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	d.checkIdle(a, done, now)
+	event := d.checkDoneSignals(a, done, now)
+	if event == nil || !event.Candidate || event.Method != MethodDoneSignal || event.IsFailed {
+		t.Fatalf("expected candidate completion, got %+v", event)
+	}
+	if len(event.Signals) != 2 {
+		t.Errorf("signals = %+v, want final answer and prompt", event.Signals)
+	}
+
+	// The same quiet output is proposed once.
+	if event := d.checkDoneSignals(a, done, now); event != nil {
+		t.Errorf("candidate repeated: %+v", event)
+	}
+
+	// Other agent types use the default quiet period.
+	b := &assignment.Assignment{BeadID: "bd-other", Pane: 2, AgentType: "aider", AssignedAt: now}
+	d.checkIdle(b, "start", now)
+	d.checkIdle(b, "Applied edit to main.go\n> ", now)
+	if event := d.checkDoneSignals(b, "Applied edit to main.go\n> ", now); event != nil {
+		t.Errorf("default quiet period should not have elapsed: %+v", event)
+	}
+}
+
+func TestBurstMarksWorkingAndFinishVerifies(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := assignment.NewStore("test-session")
+	d := NewWithConfig("test-session", store, DefaultConfig())
+	d.VerifyCompletions = true
+
+	a, _ := store.Assign("bd-1", "Test", 1, "claude", "", "")
+	d.checkIdle(a, "prompt sent", a.AssignedAt)
+	d.checkIdle(a, "agent typing", a.AssignedAt)
+	if a.Status != assignment.StatusWorking || a.StartedAt == nil {
+		t.Fatalf("status = %s, want working once output moves", a.Status)
+	}
+
+	b, _ := store.Assign("bd-2", "Other", 2, "codex", "", "")
+	if err := d.finish(b, assignment.StatusVerifying); err != nil {
+		t.Fatalf("finish from assigned: %v", err)
+	}
+	if b.Status != assignment.StatusVerifying {
+		t.Errorf("status = %s, want verifying", b.Status)
+	}
+}
+
 func TestWatchCancellation(t *testing.T) {
 	store := assignment.NewStore("test-session")
 	cfg := DefaultConfig()
@@ -0,0 +1,130 @@
+package completion
+
+import (
+	"context"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+)
+
+// ReservationLister returns the path patterns an agent holds reservations
+// for.
+type ReservationLister func(ctx context.Context, agentName string) ([]string, error)
+
+// trackWorkingSet updates an active assignment's working set from the tool
+// calls in its pane output, the agent's file reservations, and the files
+// changed in the repository that those reservations cover.
+func (d *CompletionDetector) trackWorkingSet(ctx context.Context, a *assignment.Assignment, output string) {
+	if d.Store == nil {
+		return
+	}
+
+	var ws assignment.WorkingSet
+	calls := agent.ParseToolCalls(agent.AgentType(assign.ParseAgentType(a.AgentType)), output)
+	for _, c := range calls {
+		switch {
+		case c.Kind == agent.ToolShell:
+			if c.Args != "" {
+				ws.Commands = append(ws.Commands, c.Args)
+			}
+		case c.Modifies():
+			ws.FilesWritten = append(ws.FilesWritten, d.repoPaths(c.Files)...)
+		case c.Kind == agent.ToolRead:
+			ws.FilesRead = append(ws.FilesRead, d.repoPaths(c.Files)...)
+		}
+	}
+
+	if d.Reservations != nil && a.AgentName != "" {
+		if reserved, err := d.Reservations(ctx, a.AgentName); err == nil {
+			ws.Reserved = reserved
+			if ws.Reserved == nil {
+				ws.Reserved = []string{}
+			}
+			// Changes under the task's reservations are its writes.
+			for _, f := range d.changedFiles(ctx) {
+				for _, pattern := range reserved {
+					if assignment.MatchPath(f, pattern) {
+						ws.FilesWritten = append(ws.FilesWritten, f)
+						break
+					}
+				}
+			}
+		} else {
+			slog.Debug("working set: list reservations", "agent", a.AgentName, "error", err)
+		}
+	}
+
+	if err := d.Store.RecordWorkingSet(a.BeadID, ws); err != nil {
+		slog.Debug("working set: record", "bead", a.BeadID, "error", err)
+	}
+}
+
+// repoPaths makes tool-call paths relative to RepoDir, dropping ones outside
+// it. Without a RepoDir, paths are kept as printed.
+func (d *CompletionDetector) repoPaths(files []string) []string {
+	if d.RepoDir == "" {
+		return files
+	}
+	var out []string
+	for _, f := range files {
+		if filepath.IsAbs(f) {
+			rel, err := filepath.Rel(d.RepoDir, f)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			f = rel
+		}
+		out = append(out, filepath.ToSlash(filepath.Clean(f)))
+	}
+	return out
+}
+
+// changedFiles returns the files with uncommitted changes in RepoDir. The
+// result is cached for one poll interval, as every assignment uses it.
+func (d *CompletionDetector) changedFiles(ctx context.Context) []string {
+	if d.RepoDir == "" {
+		return nil
+	}
+	d.mu.RLock()
+	cached, at := d.changed, d.changedAt
+	d.mu.RUnlock()
+	if !at.IsZero() && time.Since(at) < d.Config.PollInterval {
+		return cached
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-C", d.RepoDir, "status", "--porcelain", "-z", "--untracked-files=all")
+	out, err := cmd.Output()
+	if err != nil {
+		return cached
+	}
+	changed := parseStatusZ(string(out))
+
+	d.mu.Lock()
+	d.changed, d.changedAt = changed, time.Now()
+	d.mu.Unlock()
+	return changed
+}
+
+// parseStatusZ extracts paths from `git status --porcelain -z` output.
+func parseStatusZ(out string) []string {
+	var files []string
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		files = append(files, entry[3:])
+		// Renames and copies are followed by the original path.
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return files
+}
@@ -0,0 +1,77 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+)
+
+func TestTrackWorkingSet(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	if out, err := exec.Command("git", "-C", repo, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	for _, f := range []string{"internal/api/routes.go", "docs/notes.md"} {
+		path := filepath.Join(repo, f)
+		_ = os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store := assignment.NewStore("ws-track")
+	a, _ := store.Assign("bd-1", "API", 1, "claude", "BlueLake", "")
+
+	d := New("ws-track", store)
+	d.RepoDir = repo
+	d.Reservations = func(ctx context.Context, agentName string) ([]string, error) {
+		if agentName != "BlueLake" {
+			t.Errorf("agent = %q", agentName)
+		}
+		return []string{"internal/api/**"}, nil
+	}
+
+	output := "⏺ Read(" + filepath.Join(repo, "internal/api/types.go") + ")\n" +
+		"⏺ Update(internal/api/handlers.go)\n" +
+		"⏺ Bash(go test ./internal/api/...)\n" +
+		"⏺ Write(/tmp/scratch.txt)\n"
+	d.trackWorkingSet(context.Background(), a, output)
+
+	ws := store.Get("bd-1").WorkingSet
+	if ws == nil {
+		t.Fatal("working set not recorded")
+	}
+	if !reflect.DeepEqual(ws.FilesRead, []string{"internal/api/types.go"}) {
+		t.Errorf("read = %v", ws.FilesRead)
+	}
+	written := append([]string(nil), ws.FilesWritten...)
+	sort.Strings(written)
+	if !reflect.DeepEqual(written, []string{"internal/api/handlers.go", "internal/api/routes.go"}) {
+		t.Errorf("written = %v (docs/notes.md is outside the reservation, /tmp outside the repo)", written)
+	}
+	if !reflect.DeepEqual(ws.Commands, []string{"go test ./internal/api/..."}) {
+		t.Errorf("commands = %v", ws.Commands)
+	}
+	if !reflect.DeepEqual(ws.Reserved, []string{"internal/api/**"}) {
+		t.Errorf("reserved = %v", ws.Reserved)
+	}
+}
+
+func TestParseStatusZ(t *testing.T) {
+	out := " M a.go\x00?? dir/b.go\x00R  new.go\x00old.go\x00"
+	got := parseStatusZ(out)
+	if !reflect.DeepEqual(got, []string{"a.go", "dir/b.go", "new.go"}) {
+		t.Errorf("parseStatusZ = %v", got)
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BillingProviders are the providers whose billing APIs ntm can poll.
+// Google exposes spend only through Cloud Billing exports, so it has no
+// poller.
+var BillingProviders = []string{"anthropic", "openai"}
+
+// BillingProviderConfig enables spend polling for one provider. The admin
+// API key is read from the environment variable named by AdminKeyEnv at
+// poll time; the key itself never appears in config, state or audit logs.
+type BillingProviderConfig struct {
+	Enabled        bool    `toml:"enabled"`          // Poll this provider's billing API
+	AdminKeyEnv    string  `toml:"admin_key_env"`    // Env var holding an org admin API key
+	MonthlyCapUSD  float64 `toml:"monthly_cap_usd"`  // Org-level monthly spend cap
+	ThrottleAt     float64 `toml:"throttle_at"`      // Fraction of the cap where pacing starts slowing
+	PauseAt        float64 `toml:"pause_at"`         // Fraction of the cap where new spawns pause
+	MaxDelayFactor float64 `toml:"max_delay_factor"` // Delay multiplier reached just below pause_at
+	BaseURL        string  `toml:"base_url"`         // API base URL override (proxies, gateways)
+}
+
+// BillingConfig configures [billing]: optional pollers that read the
+// month-to-date spend from provider billing APIs, reconcile it with ntm's
+// token-based estimates, and tighten rate limit pacing as an org-level
+// monthly cap approaches.
+type BillingConfig struct {
+	PollMinutes int                   `toml:"poll_minutes"` // How often ntm daemon polls (default 60)
+	Anthropic   BillingProviderConfig `toml:"anthropic"`
+	OpenAI      BillingProviderConfig `toml:"openai"`
+}
+
+// DefaultBillingConfig returns billing defaults: polling off, hourly when
+// enabled, slowing from 80% of the cap and pausing at 95%.
+func DefaultBillingConfig() BillingConfig {
+	provider := func(keyEnv string) BillingProviderConfig {
+		return BillingProviderConfig{
+			AdminKeyEnv:    keyEnv,
+			ThrottleAt:     0.8,
+			PauseAt:        0.95,
+			MaxDelayFactor: 4,
+		}
+	}
+	return BillingConfig{
+		PollMinutes: 60,
+		Anthropic:   provider("ANTHROPIC_ADMIN_KEY"),
+		OpenAI:      provider("OPENAI_ADMIN_KEY"),
+	}
+}
+
+// Provider returns the settings for a provider in BillingProviders.
+func (c BillingConfig) Provider(name string) (BillingProviderConfig, bool) {
+	switch name {
+	case "anthropic":
+		return c.Anthropic, true
+	case "openai":
+		return c.OpenAI, true
+	}
+	return BillingProviderConfig{}, false
+}
+
+// Enabled returns the providers with polling turned on.
+func (c BillingConfig) Enabled() []string {
+	var enabled []string
+	for _, name := range BillingProviders {
+		if p, _ := c.Provider(name); p.Enabled {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled
+}
+
+// ValidateBillingConfig validates the billing configuration.
+func ValidateBillingConfig(cfg *BillingConfig) error {
+	if cfg.PollMinutes < 0 {
+		return fmt.Errorf("poll_minutes must be >= 0, got %d", cfg.PollMinutes)
+	}
+	for _, name := range BillingProviders {
+		p, _ := cfg.Provider(name)
+		if !p.Enabled {
+			continue
+		}
+		if p.MonthlyCapUSD <= 0 {
+			return fmt.Errorf("%s.monthly_cap_usd must be > 0 when enabled", name)
+		}
+		if env := strings.TrimSpace(p.AdminKeyEnv); !envNameRe.MatchString(env) {
+			return fmt.Errorf("%s.admin_key_env must name an environment variable, got %q", name, p.AdminKeyEnv)
+		}
+		if p.ThrottleAt <= 0 || p.ThrottleAt > p.PauseAt {
+			return fmt.Errorf("%s.throttle_at must be > 0 and <= pause_at, got %g", name, p.ThrottleAt)
+		}
+		if p.PauseAt > 1 {
+			return fmt.Errorf("%s.pause_at must be <= 1, got %g", name, p.PauseAt)
+		}
+		if p.MaxDelayFactor < 1 {
+			return fmt.Errorf("%s.max_delay_factor must be >= 1, got %g", name, p.MaxDelayFactor)
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -82,7 +84,17 @@ type Config struct {
 	ContextRotation    ContextRotationConfig `toml:"context_rotation"` // Context window rotation
 	SessionRecovery    SessionRecoveryConfig `toml:"recovery"`         // Smart session recovery
 	Cleanup            CleanupConfig         `toml:"cleanup"`          // Temp file cleanup configuration
+	Storage            StorageConfig         `toml:"storage"`          // Artifact quotas, retention and low-disk guard
+	Audit              AuditConfig           `toml:"audit"`            // Off-host shipping of audit logs
+	Billing            BillingConfig         `toml:"billing"`          // Provider billing API pollers and spend caps
+	WindDown           WindDownConfig        `toml:"wind_down"`        // Max session runtime and auto-wind-down
+	Schedules          []ScheduleConfig      `toml:"schedules"`        // Recurring fleet runs started by ntm daemon
+	Archive            ArchiveConfig         `toml:"archive"`          // Pane output capture scheduling
 	FileReservation    FileReservationConfig `toml:"file_reservation"` // Auto file reservation via Agent Mail
+	Conflicts          ConflictsConfig       `toml:"conflicts"`        // Conflict detection path filters and scoring
+	Daemon             DaemonConfig          `toml:"daemon"`           // Background subsystems run by ntm daemon
+	Wasm               WasmConfig            `toml:"wasm"`             // Sandboxed WASM plugin runtime
+	Deadlines          DeadlinesConfig       `toml:"deadlines"`        // Default time limits of long-running operations
 	Memory             MemoryConfig          `toml:"memory"`           // CASS Memory (cm) integration
 	Assign             AssignConfig          `toml:"assign"`           // Assignment strategy configuration
 	Ensemble           EnsembleConfig        `toml:"ensemble"`         // Reasoning ensemble defaults
@@ -95,6 +107,7 @@ type Config struct {
 	Encryption         EncryptionConfig      `toml:"encryption"`       // Encryption at rest for artifacts
 	Send               SendConfig            `toml:"send"`             // Send command defaults
 	Prompts            PromptsConfig         `toml:"prompts"`          // Per-agent-type default prompts
+	SpawnEnv           SpawnEnvConfig        `toml:"spawn_env"`        // Per-pane env/credential injection
 
 	// Runtime-only fields (populated by project config merging)
 	ProjectDefaults map[string]int `toml:"-"`
@@ -102,8 +115,50 @@ type Config struct {
 
 // RobotConfig holds defaults for robot output behavior.
 type RobotConfig struct {
-	Verbosity string            `toml:"verbosity"` // terse, default, or debug
-	Output    RobotOutputConfig `toml:"output"`    // Output format configuration
+	Verbosity string             `toml:"verbosity"` // terse, default, or debug
+	Output    RobotOutputConfig  `toml:"output"`    // Output format configuration
+	Confirm   RobotConfirmConfig `toml:"confirm"`   // Two-phase confirmation of destructive commands
+}
+
+// RobotConfirmConfig selects which destructive command classes need a
+// confirmation token when run non-interactively (robot flags or --json).
+type RobotConfirmConfig struct {
+	// Require lists the classes that need confirmation:
+	// kill, restart, interrupt, stop, purge.
+	Require    []string `toml:"require"`
+	TTLSeconds int      `toml:"ttl_seconds"` // How long an issued token stays valid
+}
+
+// RobotConfirmClasses are the command classes that can require confirmation.
+var RobotConfirmClasses = []string{"kill", "restart", "interrupt", "stop", "purge"}
+
+// DefaultRobotConfirmConfig returns the default confirmation policy: only
+// purge, which cannot be undone, is confirmed out of the box.
+func DefaultRobotConfirmConfig() RobotConfirmConfig {
+	return RobotConfirmConfig{
+		Require:    []string{"purge"},
+		TTLSeconds: 300,
+	}
+}
+
+// ValidateRobotConfirmConfig validates the confirmation policy.
+func ValidateRobotConfirmConfig(cfg *RobotConfirmConfig) error {
+	for _, class := range cfg.Require {
+		known := false
+		for _, c := range RobotConfirmClasses {
+			if class == c {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown confirmation class %q: must be one of %s", class, strings.Join(RobotConfirmClasses, ", "))
+		}
+	}
+	if cfg.TTLSeconds < 0 {
+		return fmt.Errorf("ttl_seconds must be non-negative, got %d", cfg.TTLSeconds)
+	}
+	return nil
 }
 
 // RobotOutputConfig holds configuration for robot mode output format.
@@ -112,6 +167,12 @@ type RobotOutputConfig struct {
 	Pretty     bool   `toml:"pretty"`     // Pretty print output (adds whitespace for readability)
 	Timestamps bool   `toml:"timestamps"` // Include timestamps in output
 	Compress   bool   `toml:"compress"`   // Compression for large outputs
+
+	// TOON table layout: pad cells to column width, and replace long
+	// field names in table headers with short aliases (field -> alias),
+	// listed in a legend line under the header.
+	TOONAlign         bool              `toml:"toon_align"`
+	TOONAbbreviations map[string]string `toml:"toon_abbreviations"`
 }
 
 // DefaultRobotOutputConfig returns sensible robot output defaults.
@@ -128,12 +189,34 @@ func DefaultRobotOutputConfig() RobotOutputConfig {
 func ValidateRobotOutputConfig(cfg *RobotOutputConfig) error {
 	// Empty format is valid - defaults to "json"
 	if cfg.Format == "" {
-		return nil
+		return validateTOONAbbreviations(cfg.TOONAbbreviations)
 	}
 	validFormats := map[string]bool{"json": true, "toon": true, "auto": true}
 	if !validFormats[cfg.Format] {
 		return fmt.Errorf("invalid robot output format %q: must be \"json\", \"toon\", or \"auto\"", cfg.Format)
 	}
+	return validateTOONAbbreviations(cfg.TOONAbbreviations)
+}
+
+// validateTOONAbbreviations checks that every alias is a plain name and
+// that no two fields share one.
+func validateTOONAbbreviations(abbrevs map[string]string) error {
+	fields := make([]string, 0, len(abbrevs))
+	for field := range abbrevs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	owner := make(map[string]string, len(abbrevs))
+	for _, field := range fields {
+		alias := abbrevs[field]
+		if alias == "" || strings.ContainsAny(alias, " \t\n,|:{}[]\"#") {
+			return fmt.Errorf("toon_abbreviations: invalid alias %q for %q", alias, field)
+		}
+		if other, ok := owner[alias]; ok {
+			return fmt.Errorf("toon_abbreviations: %q and %q share alias %q", other, field, alias)
+		}
+		owner[alias] = field
+	}
 	return nil
 }
 
@@ -142,6 +225,7 @@ func DefaultRobotConfig() RobotConfig {
 	return RobotConfig{
 		Verbosity: "default",
 		Output:    DefaultRobotOutputConfig(),
+		Confirm:   DefaultRobotConfirmConfig(),
 	}
 }
 
@@ -183,6 +267,8 @@ type AlertsConfig struct {
 	MailBacklogThreshold int     `toml:"mail_backlog_threshold"` // Unread messages before alerting
 	BeadStaleHours       int     `toml:"bead_stale_hours"`       // Hours before in-progress bead is stale
 	ResolvedPruneMinutes int     `toml:"resolved_prune_minutes"` // How long to keep resolved alerts
+	AgentCPUPercent      float64 `toml:"agent_cpu_percent"`      // CPU of an agent's process tree before alerting (100 = one core)
+	AgentMemoryPercent   float64 `toml:"agent_memory_percent"`   // Share of system memory an agent's process tree may use
 }
 
 // DefaultAlertsConfig returns sensible alert defaults
@@ -194,6 +280,8 @@ func DefaultAlertsConfig() AlertsConfig {
 		MailBacklogThreshold: 10,
 		BeadStaleHours:       24,
 		ResolvedPruneMinutes: 60,
+		AgentCPUPercent:      300,
+		AgentMemoryPercent:   50,
 	}
 }
 
@@ -207,6 +295,12 @@ type ResilienceConfig struct {
 	NotifyOnCrash       bool            `toml:"notify_on_crash"`        // Send notification when agent crashes
 	NotifyOnMaxRestarts bool            `toml:"notify_on_max_restarts"` // Notify when max restarts exceeded
 	RateLimit           RateLimitConfig `toml:"rate_limit"`             // Rate limit detection configuration
+
+	CrashBundles        bool `toml:"crash_bundles"`         // Save a forensic bundle under .ntm/crashes when an agent crashes
+	CrashBundleSegments int  `toml:"crash_bundle_segments"` // Archived output segments and prompts per bundle
+	CrashBundleKeep     int  `toml:"crash_bundle_keep"`     // Bundles kept per project; older ones are pruned
+
+	ShutdownTimeoutSeconds int `toml:"shutdown_timeout_seconds"` // Deadline for flushing state on shutdown
 }
 
 // RateLimitConfig holds configuration for rate limit detection
@@ -214,22 +308,38 @@ type RateLimitConfig struct {
 	Detect   bool     `toml:"detect"`   // Enable rate limit detection
 	Notify   bool     `toml:"notify"`   // Send notification on rate limit
 	Patterns []string `toml:"patterns"` // Custom patterns to detect (in addition to defaults)
+
+	// AutoResume re-sends the pending prompt of a pane parked by a rate
+	// limit once its cooldown ends, after a random delay of up to
+	// ResumeJitterSeconds so parked panes do not all resume at once.
+	AutoResume          bool `toml:"auto_resume"`
+	ResumeJitterSeconds int  `toml:"resume_jitter_seconds"`
+
+	// Schedule holds quiet-hour and burst-window rules layered on the
+	// learned spawn/send delays.
+	Schedule []RateLimitScheduleRule `toml:"schedule"`
 }
 
 // DefaultResilienceConfig returns sensible resilience defaults
 func DefaultResilienceConfig() ResilienceConfig {
 	return ResilienceConfig{
-		AutoRestart:         false, // Disabled by default, opt-in via --auto-restart
-		MaxRestarts:         3,     // Stop after 3 restart attempts
-		RestartDelaySeconds: 30,    // Wait 30 seconds before restarting
-		HealthCheckSeconds:  10,    // Check health every 10 seconds
-		CrashThreshold:      3,     // 3 consecutive text-based failures before restart
-		NotifyOnCrash:       true,  // Notify on crash by default
-		NotifyOnMaxRestarts: true,  // Notify when max restarts exceeded
+		AutoRestart:            false, // Disabled by default, opt-in via --auto-restart
+		MaxRestarts:            3,     // Stop after 3 restart attempts
+		RestartDelaySeconds:    30,    // Wait 30 seconds before restarting
+		HealthCheckSeconds:     10,    // Check health every 10 seconds
+		CrashThreshold:         3,     // 3 consecutive text-based failures before restart
+		NotifyOnCrash:          true,  // Notify on crash by default
+		NotifyOnMaxRestarts:    true,  // Notify when max restarts exceeded
+		CrashBundles:           true,  // Collect crash forensics by default
+		CrashBundleSegments:    20,    // Last 20 archived segments and prompts
+		CrashBundleKeep:        50,    // Keep the 50 newest bundles
+		ShutdownTimeoutSeconds: 10,    // Flush state for up to 10 seconds on shutdown
 		RateLimit: RateLimitConfig{
-			Detect:   true, // Detect rate limits by default
-			Notify:   true, // Notify on rate limit by default
-			Patterns: nil,  // Use default patterns (rate limit, 429, too many requests, quota exceeded)
+			Detect:              true, // Detect rate limits by default
+			Notify:              true, // Notify on rate limit by default
+			Patterns:            nil,  // Use default patterns (rate limit, 429, too many requests, quota exceeded)
+			AutoResume:          true, // Resume parked panes when their cooldown ends
+			ResumeJitterSeconds: 30,   // Spread resumes over up to 30 seconds
 		},
 	}
 }
@@ -284,9 +394,18 @@ func ValidateHealthConfig(cfg *HealthConfig) error {
 
 // AccountEntry represents a single account for a provider
 type AccountEntry struct {
-	Email    string `toml:"email"`
-	Alias    string `toml:"alias"`
-	Priority int    `toml:"priority"`
+	Email    string         `toml:"email"`
+	Alias    string         `toml:"alias"`
+	Priority int            `toml:"priority"`
+	Env      SpawnEnvSource `toml:"env"` // Credentials injected into panes assigned this account
+}
+
+// Name returns the account's identifier: its alias, else its email.
+func (e AccountEntry) Name() string {
+	if e.Alias != "" {
+		return e.Alias
+	}
+	return e.Email
 }
 
 // AccountsConfig holds multi-account management configuration
@@ -294,11 +413,61 @@ type AccountsConfig struct {
 	StateFile          string         `toml:"state_file"`           // Path to account state JSON
 	AutoRotate         bool           `toml:"auto_rotate"`          // Auto-rotate on limit detection
 	ResetBufferMinutes int            `toml:"reset_buffer_minutes"` // Minutes before reset to consider available
+	RoundRobin         bool           `toml:"round_robin"`          // Spread spawned panes across each provider's accounts
 	Claude             []AccountEntry `toml:"claude"`               // Claude accounts
 	Codex              []AccountEntry `toml:"codex"`                // Codex accounts
 	Gemini             []AccountEntry `toml:"gemini"`               // Gemini accounts
 }
 
+// Pool returns the accounts new panes of agentType are spread across, in
+// priority order (lower first, ties keep config order). It is empty unless
+// round_robin is enabled.
+func (c AccountsConfig) Pool(agentType string) []AccountEntry {
+	if !c.RoundRobin {
+		return nil
+	}
+	var entries []AccountEntry
+	switch agentType {
+	case "cc", "claude":
+		entries = c.Claude
+	case "cod", "codex":
+		entries = c.Codex
+	case "gmi", "gemini":
+		entries = c.Gemini
+	}
+	pool := append([]AccountEntry(nil), entries...)
+	sort.SliceStable(pool, func(i, j int) bool { return pool[i].Priority < pool[j].Priority })
+	return pool
+}
+
+// ValidateAccountsConfig checks that pool accounts are uniquely named and
+// that their env settings are valid.
+func ValidateAccountsConfig(cfg *AccountsConfig) error {
+	for _, group := range []struct {
+		name    string
+		entries []AccountEntry
+	}{{"claude", cfg.Claude}, {"codex", cfg.Codex}, {"gemini", cfg.Gemini}} {
+		seen := make(map[string]bool)
+		for i, acct := range group.entries {
+			name := acct.Name()
+			if name == "" {
+				return fmt.Errorf("%s[%d]: alias or email is required", group.name, i)
+			}
+			if strings.Contains(name, "/") {
+				return fmt.Errorf("%s[%d]: account name %q must not contain '/'", group.name, i, name)
+			}
+			if seen[name] {
+				return fmt.Errorf("%s[%d]: duplicate account %q", group.name, i, name)
+			}
+			seen[name] = true
+			if err := ValidateSpawnEnvConfig(&SpawnEnvConfig{SpawnEnvSource: acct.Env}); err != nil {
+				return fmt.Errorf("%s.%s.env: %w", group.name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // DefaultAccountsConfig returns the default accounts configuration
 func DefaultAccountsConfig() AccountsConfig {
 	return AccountsConfig{
@@ -505,6 +674,8 @@ type ContextRotationConfig struct {
 	RequireConfirm       bool    `toml:"require_confirm"`        // Require user confirmation before rotating
 	ConfirmTimeoutSec    int     `toml:"confirm_timeout_sec"`    // Seconds to wait for confirmation (0 = no auto-rotate)
 	DefaultConfirmAction string  `toml:"default_confirm_action"` // Action if timeout expires: "rotate", "ignore", "compact"
+	AutoCompact          bool    `toml:"auto_compact"`           // Monitor resets agents past warning_threshold (summary + /compact or restart)
+	SummaryWaitSec       int     `toml:"summary_wait_sec"`       // Seconds an agent gets to write its summary before compaction
 }
 
 // DefaultContextRotationConfig returns sensible defaults for context rotation
@@ -519,6 +690,8 @@ func DefaultContextRotationConfig() ContextRotationConfig {
 		RequireConfirm:       false,    // Don't require confirmation by default
 		ConfirmTimeoutSec:    60,       // 60 seconds timeout for confirmation
 		DefaultConfirmAction: "rotate", // Auto-rotate on timeout
+		AutoCompact:          false,    // Opt-in: the monitor interrupts agents to compact them
+		SummaryWaitSec:       45,       // 45 seconds to write the summary
 	}
 }
 
@@ -543,6 +716,9 @@ func ValidateContextRotationConfig(cfg *ContextRotationConfig) error {
 	if cfg.ConfirmTimeoutSec < 0 {
 		return fmt.Errorf("confirm_timeout_sec must be non-negative, got %d", cfg.ConfirmTimeoutSec)
 	}
+	if cfg.SummaryWaitSec < 0 {
+		return fmt.Errorf("summary_wait_sec must be non-negative, got %d", cfg.SummaryWaitSec)
+	}
 	validActions := map[string]bool{"rotate": true, "ignore": true, "compact": true, "": true}
 	if !validActions[cfg.DefaultConfirmAction] {
 		return fmt.Errorf("default_confirm_action must be 'rotate', 'ignore', or 'compact', got %q", cfg.DefaultConfirmAction)
@@ -690,6 +866,433 @@ func DefaultCleanupConfig() CleanupConfig {
 	}
 }
 
+// StorageConfig holds quotas and retention rules for ntm's on-disk artifacts.
+type StorageConfig struct {
+	AutoPrune bool         `toml:"auto_prune"`  // Prune over-quota and expired artifacts from the session monitor
+	MinFreeMB int          `toml:"min_free_mb"` // Pause archiving when free disk drops below this (0 = never)
+	Archives  StorageQuota `toml:"archives"`    // Pane output archives (~/.ntm/archive)
+	Captures  StorageQuota `toml:"captures"`    // Checkpoint captures (~/.local/share/ntm/checkpoints)
+	Audit     StorageQuota `toml:"audit"`       // Audit logs (~/.local/share/ntm/audit)
+	Roots     StorageRoots `toml:"roots"`       // Where each artifact class is stored
+}
+
+// StorageRoots moves artifact classes out of their default directories, e.g.
+// audit logs to an encrypted volume and archives to bulk storage. Empty
+// fields keep the default. Existing data is moved by ntm storage migrate.
+type StorageRoots struct {
+	Audit    string `toml:"audit"`    // Audit logs (default ~/.local/share/ntm/audit)
+	Archives string `toml:"archives"` // Pane output archives (default ~/.ntm/archive)
+	Captures string `toml:"captures"` // Checkpoint captures (default ~/.local/share/ntm/checkpoints)
+	State    string `toml:"state"`    // Per-session state: assignments, prompt history, saved sessions (default ~/.ntm/sessions)
+}
+
+// StorageQuota limits one category of artifacts.
+type StorageQuota struct {
+	QuotaMB    int `toml:"quota_mb"`     // Maximum size; oldest artifacts are pruned first (0 = unlimited)
+	MaxAgeDays int `toml:"max_age_days"` // Prune artifacts older than this (0 = keep forever)
+	KeepDays   int `toml:"keep_days"`    // Never prune artifacts newer than this
+}
+
+// DefaultStorageConfig returns storage defaults. Audit logs are kept in full
+// unless a quota is configured.
+func DefaultStorageConfig() StorageConfig {
+	return StorageConfig{
+		AutoPrune: true,
+		MinFreeMB: 1024,
+		Archives:  StorageQuota{QuotaMB: 2048, MaxAgeDays: 90, KeepDays: 1},
+		Captures:  StorageQuota{QuotaMB: 2048, KeepDays: 7},
+		Audit:     StorageQuota{KeepDays: 30},
+	}
+}
+
+// ValidateStorageConfig validates the storage configuration.
+func ValidateStorageConfig(cfg *StorageConfig) error {
+	if cfg.MinFreeMB < 0 {
+		return fmt.Errorf("min_free_mb must be >= 0, got %d", cfg.MinFreeMB)
+	}
+	quotas := []struct {
+		name string
+		q    StorageQuota
+	}{{"archives", cfg.Archives}, {"captures", cfg.Captures}, {"audit", cfg.Audit}}
+	roots := map[string]string{
+		"audit":    cfg.Roots.Audit,
+		"archives": cfg.Roots.Archives,
+		"captures": cfg.Roots.Captures,
+		"state":    cfg.Roots.State,
+	}
+	seen := map[string]string{}
+	for _, name := range []string{"audit", "archives", "captures", "state"} {
+		root := roots[name]
+		if root == "" {
+			continue
+		}
+		if !filepath.IsAbs(ExpandHome(root)) {
+			return fmt.Errorf("roots.%s must be an absolute path, got %q", name, root)
+		}
+		clean := filepath.Clean(ExpandHome(root))
+		if other, ok := seen[clean]; ok {
+			return fmt.Errorf("roots.%s and roots.%s are the same directory", other, name)
+		}
+		seen[clean] = name
+	}
+	for _, entry := range quotas {
+		name, q := entry.name, entry.q
+		if q.QuotaMB < 0 || q.MaxAgeDays < 0 || q.KeepDays < 0 {
+			return fmt.Errorf("%s: quota_mb, max_age_days and keep_days must be >= 0", name)
+		}
+		if q.MaxAgeDays > 0 && q.KeepDays > q.MaxAgeDays {
+			return fmt.Errorf("%s: keep_days (%d) exceeds max_age_days (%d)", name, q.KeepDays, q.MaxAgeDays)
+		}
+	}
+	return nil
+}
+
+// AuditConfig holds audit log settings.
+type AuditConfig struct {
+	Sink AuditSinkConfig `toml:"sink"` // Where finished audit segments are shipped
+}
+
+// AuditSinkConfig selects an off-host store for audit logs. Each finished
+// day's log is uploaded once, with a signed integrity manifest.
+type AuditSinkConfig struct {
+	Type            string `toml:"type"`             // s3, gcs or dir ("" = off)
+	Bucket          string `toml:"bucket"`           // Bucket name (s3, gcs)
+	Prefix          string `toml:"prefix"`           // Key prefix inside the bucket
+	Region          string `toml:"region"`           // S3 region (default us-east-1)
+	Endpoint        string `toml:"endpoint"`         // Override the service endpoint, e.g. for MinIO
+	Dir             string `toml:"dir"`              // Target directory (dir), e.g. a network mount
+	AccessKeyEnv    string `toml:"access_key_env"`   // Env var holding the access key ID
+	SecretKeyEnv    string `toml:"secret_key_env"`   // Env var holding the secret key
+	IntervalMinutes int    `toml:"interval_minutes"` // How often ntm monitor ships new segments
+}
+
+// AuditSinkTypes are the supported audit sink backends.
+var AuditSinkTypes = []string{"s3", "gcs", "dir"}
+
+// DefaultAuditConfig returns audit defaults: no sink, hourly shipping once
+// one is configured.
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		Sink: AuditSinkConfig{
+			Prefix:          "ntm-audit",
+			IntervalMinutes: 60,
+		},
+	}
+}
+
+// ValidateAuditConfig validates the audit configuration.
+func ValidateAuditConfig(cfg *AuditConfig) error {
+	sink := cfg.Sink
+	switch sink.Type {
+	case "":
+		return nil
+	case "s3", "gcs":
+		if sink.Bucket == "" {
+			return fmt.Errorf("sink.bucket is required for %s", sink.Type)
+		}
+	case "dir":
+		if sink.Dir == "" {
+			return fmt.Errorf("sink.dir is required for dir")
+		}
+	default:
+		return fmt.Errorf("invalid sink.type %q: must be %s", sink.Type, strings.Join(AuditSinkTypes, ", "))
+	}
+	if sink.IntervalMinutes < 0 {
+		return fmt.Errorf("sink.interval_minutes must be >= 0, got %d", sink.IntervalMinutes)
+	}
+	return nil
+}
+
+// ArchiveConfig controls how ntm monitor captures pane output for the
+// archive. In poll mode each pane is captured every min_interval_seconds
+// while its output changes; each idle capture multiplies its interval by
+// backoff, up to max_interval_seconds. In pipe mode output is streamed
+// through tmux pipe-pane and archived every min_interval_seconds.
+type ArchiveConfig struct {
+	Mode               string  `toml:"mode"`                 // poll (capture-pane) or pipe (pipe-pane streaming)
+	MinIntervalSeconds int     `toml:"min_interval_seconds"` // Interval while output is changing
+	MaxIntervalSeconds int     `toml:"max_interval_seconds"` // Interval ceiling for idle panes
+	Backoff            float64 `toml:"backoff"`              // Interval multiplier per idle capture (1 = fixed interval)
+	DedupeThreshold    float64 `toml:"dedupe_threshold"`     // Similarity (0-1) above which a capture counts as a redraw
+}
+
+// DefaultArchiveConfig returns capture defaults: every 5s while active,
+// backing off to every 2 minutes when idle.
+func DefaultArchiveConfig() ArchiveConfig {
+	return ArchiveConfig{
+		Mode:               "poll",
+		MinIntervalSeconds: 5,
+		MaxIntervalSeconds: 120,
+		Backoff:            2,
+		DedupeThreshold:    0.9,
+	}
+}
+
+// ValidateArchiveConfig validates the archive configuration. Zero values
+// select the defaults.
+func ValidateArchiveConfig(cfg *ArchiveConfig) error {
+	switch cfg.Mode {
+	case "", "poll", "pipe":
+	default:
+		return fmt.Errorf("invalid mode %q: must be poll or pipe", cfg.Mode)
+	}
+	if cfg.MinIntervalSeconds < 0 {
+		return fmt.Errorf("min_interval_seconds must be >= 0, got %d", cfg.MinIntervalSeconds)
+	}
+	if cfg.MaxIntervalSeconds < 0 {
+		return fmt.Errorf("max_interval_seconds must be >= 0, got %d", cfg.MaxIntervalSeconds)
+	}
+	if cfg.MinIntervalSeconds > 0 && cfg.MaxIntervalSeconds > 0 && cfg.MaxIntervalSeconds < cfg.MinIntervalSeconds {
+		return fmt.Errorf("max_interval_seconds (%d) must be >= min_interval_seconds (%d)", cfg.MaxIntervalSeconds, cfg.MinIntervalSeconds)
+	}
+	if cfg.Backoff != 0 && cfg.Backoff < 1 {
+		return fmt.Errorf("backoff must be >= 1, got %g", cfg.Backoff)
+	}
+	if cfg.DedupeThreshold < 0 || cfg.DedupeThreshold > 1 {
+		return fmt.Errorf("dedupe_threshold must be between 0 and 1, got %g", cfg.DedupeThreshold)
+	}
+	return nil
+}
+
+// ConflictsConfig controls which changed files conflict detection
+// (ntm robot conflicts) analyzes. Files matching .gitignore rules are
+// skipped unless include_ignored is set; exclude removes further paths,
+// such as generated code that is committed. Submodules are scanned like
+// repositories of their own, and repos adds the other roots of a
+// multi-repo workspace; their files are reported as "repo:path". Scoring
+// tunes how confident each reported conflict is. Suppress rules mute
+// conflicts on known-noisy paths without hiding them from the history.
+type ConflictsConfig struct {
+	Exclude        []string `toml:"exclude"`         // Path patterns to leave out, e.g. "gen/**", "*.pb.go"
+	IncludeIgnored bool     `toml:"include_ignored"` // Analyze tracked files matching .gitignore rules
+	Repos          []string `toml:"repos"`           // More repository roots, absolute or relative to the project
+	SkipSubmodules bool     `toml:"skip_submodules"` // Report changed submodules as single entries
+
+	Scoring  ConflictScoringConfig       `toml:"scoring"`  // Confidence weights and reporting threshold
+	Suppress []ConflictSuppressionConfig `toml:"suppress"` // Muted paths, as [[conflicts.suppress]] tables
+}
+
+// ConflictSuppressionConfig mutes conflicts on paths matching Path until
+// Expires, a date (2006-01-02) or RFC3339 time; empty never expires.
+type ConflictSuppressionConfig struct {
+	Path    string `toml:"path"`    // Pattern as for exclude, e.g. "package-lock.json"
+	Reason  string `toml:"reason"`  // Why the path is muted, shown with its conflicts
+	Expires string `toml:"expires"` // When the rule stops applying
+}
+
+// ExpiresAt parses Expires; a date means the start of that day in local
+// time. It returns the zero time when the rule never expires.
+func (s ConflictSuppressionConfig) ExpiresAt() (time.Time, error) {
+	if s.Expires == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s.Expires, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s.Expires)
+}
+
+// ConflictScoringConfig is the scoring profile of conflict detection. Each
+// weight is the confidence (0.0-1.0) given to a situation, keyed by one of
+// ConflictScoringKeys; unset weights, tolerance, and threshold keep their
+// defaults. The profile name is echoed in conflict output.
+type ConflictScoringConfig struct {
+	Profile          string             `toml:"profile"`           // Name reported with results
+	Weights          map[string]float64 `toml:"weights"`           // Situation -> confidence
+	ToleranceSeconds int                `toml:"tolerance_seconds"` // Activity window slack around a modification (default 60)
+	MinConfidence    float64            `toml:"min_confidence"`    // Lowest confidence reported (default 0.5)
+}
+
+// ConflictScoringKeys are the situations a conflict scoring weight applies to.
+// This is kept in sync with the robot package's Score* constants.
+var ConflictScoringKeys = []string{
+	"concurrent_activity",
+	"reservation_violation",
+	"holder_modification",
+	"overlapping_reservations",
+	"reserved_unattributed",
+	"unclaimed_modification",
+	"single_modifier",
+}
+
+// DefaultConflictsConfig returns conflict detection defaults: ignored files
+// are skipped and nothing else is excluded.
+func DefaultConflictsConfig() ConflictsConfig {
+	return ConflictsConfig{}
+}
+
+// ValidateConflictsConfig validates the conflict detection configuration.
+func ValidateConflictsConfig(cfg *ConflictsConfig) error {
+	for _, pattern := range cfg.Exclude {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("exclude patterns must not be empty")
+		}
+		if filepath.IsAbs(pattern) || strings.HasPrefix(pattern, "/") {
+			return fmt.Errorf("exclude pattern %q must be relative to the repository root", pattern)
+		}
+	}
+	for _, repo := range cfg.Repos {
+		if strings.TrimSpace(repo) == "" {
+			return fmt.Errorf("repos entries must not be empty")
+		}
+	}
+	for key, weight := range cfg.Scoring.Weights {
+		if !slices.Contains(ConflictScoringKeys, key) {
+			return fmt.Errorf("scoring weight %q: unknown situation (valid: %s)", key, strings.Join(ConflictScoringKeys, ", "))
+		}
+		if weight < 0 || weight > 1 {
+			return fmt.Errorf("scoring weight %q must be between 0 and 1, got %v", key, weight)
+		}
+	}
+	if cfg.Scoring.ToleranceSeconds < 0 {
+		return fmt.Errorf("scoring tolerance_seconds must be non-negative, got %d", cfg.Scoring.ToleranceSeconds)
+	}
+	if cfg.Scoring.MinConfidence < 0 || cfg.Scoring.MinConfidence > 1 {
+		return fmt.Errorf("scoring min_confidence must be between 0 and 1, got %v", cfg.Scoring.MinConfidence)
+	}
+	for _, rule := range cfg.Suppress {
+		if strings.TrimSpace(rule.Path) == "" {
+			return fmt.Errorf("suppress rules need a path")
+		}
+		if strings.TrimSpace(rule.Reason) == "" {
+			return fmt.Errorf("suppress rule %q needs a reason", rule.Path)
+		}
+		if _, err := rule.ExpiresAt(); err != nil {
+			return fmt.Errorf("suppress rule %q: expires %q must be a date (2006-01-02) or RFC3339 time", rule.Path, rule.Expires)
+		}
+	}
+	return nil
+}
+
+// DaemonConfig selects which subsystems `ntm daemon` runs. Every subsystem
+// is on by default; --enable/--disable override these per run.
+type DaemonConfig struct {
+	Capture          bool `toml:"capture"`           // Archive pane output of sessions without a monitor
+	Watchdog         bool `toml:"watchdog"`          // Restart dead resilience monitors
+	Conflicts        bool `toml:"conflicts"`         // Scan for file conflicts between agents
+	Checkpoints      bool `toml:"checkpoints"`       // Periodic session checkpoints
+	Retention        bool `toml:"retention"`         // Storage pruning and audit log shipping
+	Billing          bool `toml:"billing"`           // Poll provider billing APIs ([billing])
+	WindDown         bool `toml:"winddown"`          // Wind sessions down at their max runtime ([wind_down])
+	Schedules        bool `toml:"schedules"`         // Start recurring fleet runs ([[schedules]])
+	ReconcileSeconds int  `toml:"reconcile_seconds"` // How often sessions are re-listed (default 30)
+}
+
+// DaemonSubsystems are the subsystem names `ntm daemon` accepts.
+var DaemonSubsystems = []string{"capture", "watchdog", "conflicts", "checkpoints", "retention", "billing", "winddown", "schedules"}
+
+// DefaultDaemonConfig returns daemon defaults: every subsystem enabled.
+func DefaultDaemonConfig() DaemonConfig {
+	return DaemonConfig{
+		Capture:          true,
+		Watchdog:         true,
+		Conflicts:        true,
+		Checkpoints:      true,
+		Retention:        true,
+		Billing:          true,
+		WindDown:         true,
+		Schedules:        true,
+		ReconcileSeconds: 30,
+	}
+}
+
+// Disabled returns the names of the subsystems turned off.
+func (c DaemonConfig) Disabled() []string {
+	var disabled []string
+	for name, on := range map[string]bool{
+		"capture":     c.Capture,
+		"watchdog":    c.Watchdog,
+		"conflicts":   c.Conflicts,
+		"checkpoints": c.Checkpoints,
+		"retention":   c.Retention,
+		"billing":     c.Billing,
+		"winddown":    c.WindDown,
+		"schedules":   c.Schedules,
+	} {
+		if !on {
+			disabled = append(disabled, name)
+		}
+	}
+	slices.Sort(disabled)
+	return disabled
+}
+
+// ValidateDaemonConfig validates the daemon configuration.
+func ValidateDaemonConfig(cfg *DaemonConfig) error {
+	if cfg.ReconcileSeconds < 0 {
+		return fmt.Errorf("reconcile_seconds must be non-negative, got %d", cfg.ReconcileSeconds)
+	}
+	return nil
+}
+
+// WasmConfig controls the WASM plugin runtime. Plugins are .wasm modules
+// in ~/.config/ntm/wasm/ and the project's .ntm/wasm/, run in a sandbox
+// with no filesystem, network or environment access.
+type WasmConfig struct {
+	Enabled       bool `toml:"enabled"`         // Load WASM plugins (default false)
+	MemoryLimitMB int  `toml:"memory_limit_mb"` // Linear memory a plugin instance may grow to
+	TimeoutMs     int  `toml:"timeout_ms"`      // Per-call time limit
+}
+
+// DefaultWasmConfig returns WASM runtime defaults: disabled, 16MB, 1s.
+func DefaultWasmConfig() WasmConfig {
+	return WasmConfig{
+		MemoryLimitMB: 16,
+		TimeoutMs:     1000,
+	}
+}
+
+// ValidateWasmConfig validates the WASM runtime configuration.
+func ValidateWasmConfig(cfg *WasmConfig) error {
+	if cfg.MemoryLimitMB < 0 || cfg.MemoryLimitMB > 4096 {
+		return fmt.Errorf("memory_limit_mb must be between 0 and 4096, got %d", cfg.MemoryLimitMB)
+	}
+	if cfg.TimeoutMs < 0 {
+		return fmt.Errorf("timeout_ms must be non-negative, got %d", cfg.TimeoutMs)
+	}
+	return nil
+}
+
+// DeadlinesConfig sets the default time limits of long-running operations.
+// A caller's own deadline or cancellation still applies; 0 removes the
+// default limit.
+type DeadlinesConfig struct {
+	GitSeconds          int `toml:"git_seconds"`           // A local git command
+	GitRemoteSeconds    int `toml:"git_remote_seconds"`    // git fetch, pull or push
+	ConflictScanSeconds int `toml:"conflict_scan_seconds"` // One conflict analysis of a workspace
+	OIDCSeconds         int `toml:"oidc_seconds"`          // A JWKS or discovery fetch from the identity provider
+}
+
+// DefaultDeadlinesConfig returns the default limits: 30s for git, 2m for
+// remote git, 1m for a conflict scan and 5s for OIDC fetches.
+func DefaultDeadlinesConfig() DeadlinesConfig {
+	return DeadlinesConfig{
+		GitSeconds:          30,
+		GitRemoteSeconds:    120,
+		ConflictScanSeconds: 60,
+		OIDCSeconds:         5,
+	}
+}
+
+// ValidateDeadlinesConfig validates the operation deadlines.
+func ValidateDeadlinesConfig(cfg *DeadlinesConfig) error {
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"git_seconds", cfg.GitSeconds},
+		{"git_remote_seconds", cfg.GitRemoteSeconds},
+		{"conflict_scan_seconds", cfg.ConflictScanSeconds},
+		{"oidc_seconds", cfg.OIDCSeconds},
+	}
+	for _, l := range limits {
+		if l.value < 0 {
+			return fmt.Errorf("%s must be non-negative, got %d", l.name, l.value)
+		}
+	}
+	return nil
+}
+
 // FileReservationConfig holds configuration for automatic file reservation via Agent Mail.
 // When enabled, NTM monitors pane output for file edits and automatically reserves
 // those files in Agent Mail, preventing other agents from conflicting edits.
@@ -703,6 +1306,14 @@ type FileReservationConfig struct {
 	PollIntervalSec       int  `toml:"poll_interval_seconds"`     // How often to poll pane output for edits
 	CaptureLinesForDetect int  `toml:"capture_lines"`             // Lines of output to scan for file edits
 	Debug                 bool `toml:"debug"`                     // Enable debug logging
+
+	// Keywords maps words in a task to the path patterns it likely
+	// touches, for `ntm reserve plan`, e.g. auth = ["internal/auth/**"].
+	Keywords map[string][]string `toml:"keywords"`
+	// Ownership maps path patterns to the agent name or type that owns
+	// them. Planned reservations of paths owned by another agent are
+	// flagged.
+	Ownership map[string]string `toml:"ownership"`
 }
 
 // DefaultFileReservationConfig returns sensible defaults for file reservation.
@@ -734,6 +1345,24 @@ func ValidateFileReservationConfig(cfg *FileReservationConfig) error {
 	if cfg.CaptureLinesForDetect < 10 {
 		return fmt.Errorf("capture_lines must be at least 10, got %d", cfg.CaptureLinesForDetect)
 	}
+	for keyword, patterns := range cfg.Keywords {
+		if strings.TrimSpace(keyword) == "" {
+			return fmt.Errorf("keywords must not be empty")
+		}
+		for _, pattern := range patterns {
+			if strings.TrimSpace(pattern) == "" || strings.HasPrefix(pattern, "/") {
+				return fmt.Errorf("keyword %q: pattern %q must be a relative path pattern", keyword, pattern)
+			}
+		}
+	}
+	for pattern, owner := range cfg.Ownership {
+		if strings.TrimSpace(pattern) == "" || strings.HasPrefix(pattern, "/") {
+			return fmt.Errorf("ownership pattern %q must be a relative path pattern", pattern)
+		}
+		if strings.TrimSpace(owner) == "" {
+			return fmt.Errorf("ownership pattern %q needs an owner", pattern)
+		}
+	}
 	return nil
 }
 
@@ -747,6 +1376,16 @@ type MemoryConfig struct {
 	IncludeAntiPatterns bool `toml:"include_anti_patterns"` // Include anti-patterns in context
 	IncludeHistory      bool `toml:"include_history"`       // Include historical snippets
 	QueryTimeoutSeconds int  `toml:"query_timeout_seconds"` // Timeout for cm command
+
+	// InjectOnSpawn sends the project's .ntm/memory sections to new agents.
+	InjectOnSpawn bool `toml:"inject_on_spawn"`
+	// Sections selects which memory sections (patterns, guards, decisions,
+	// other) each agent type receives, keyed by agent type. Agent types
+	// without an entry receive every section.
+	Sections map[string][]string `toml:"sections"`
+	// DistillOnKill proposes memory additions from the session summary
+	// when a session is killed with --summarize.
+	DistillOnKill bool `toml:"distill_on_kill"`
 }
 
 // DefaultMemoryConfig returns sensible defaults for memory integration.
@@ -758,6 +1397,8 @@ func DefaultMemoryConfig() MemoryConfig {
 		IncludeAntiPatterns: true, // Include anti-patterns by default
 		IncludeHistory:      true, // Include historical snippets
 		QueryTimeoutSeconds: 5,    // 5 second timeout for cm queries
+		InjectOnSpawn:       true, // Inject .ntm/memory sections into new agents
+		DistillOnKill:       true, // Propose memory additions after summarized sessions
 	}
 }
 
@@ -769,6 +1410,15 @@ func ValidateMemoryConfig(cfg *MemoryConfig) error {
 	if cfg.QueryTimeoutSeconds < 1 {
 		return fmt.Errorf("query_timeout_seconds must be at least 1, got %d", cfg.QueryTimeoutSeconds)
 	}
+	for agentType, sections := range cfg.Sections {
+		for _, s := range sections {
+			switch s {
+			case "patterns", "guards", "decisions", "other":
+			default:
+				return fmt.Errorf("sections.%s: unknown memory section %q", agentType, s)
+			}
+		}
+	}
 	return nil
 }
 
@@ -837,6 +1487,8 @@ type TmuxConfig struct {
 	PaletteKey      string `toml:"palette_key"`
 	PaneInitDelayMs int    `toml:"pane_init_delay_ms"` // Delay before sending keys to new panes
 	HistoryLimit    int    `toml:"history_limit"`      // Scrollback buffer lines per pane (default 50000)
+	MaxCaptureBytes int    `toml:"max_capture_bytes"`  // One capture is truncated (head and tail kept) beyond this (default 4 MiB)
+	MaxPaneBytes    int    `toml:"max_pane_bytes"`     // Captured bytes retained per pane (default 16 MiB)
 	// ActivityIndicators control pane border activity coloring.
 	ActivityIndicators ActivityIndicatorConfig `toml:"activity_indicators"`
 }
@@ -871,12 +1523,23 @@ func ValidateActivityIndicatorConfig(cfg *ActivityIndicatorConfig) error {
 // AgentMailConfig holds Agent Mail server settings
 type AgentMailConfig struct {
 	Enabled      bool   `toml:"enabled"`       // Master toggle
+	Backend      string `toml:"backend"`       // "http" (external server) or "embedded" (SQLite under .ntm/)
 	URL          string `toml:"url"`           // Server endpoint
 	Token        string `toml:"token"`         // Bearer token
 	AutoRegister bool   `toml:"auto_register"` // Auto-register sessions as agents
 	ProgramName  string `toml:"program_name"`  // Program identifier for registration
 }
 
+// ValidateAgentMailConfig validates the Agent Mail configuration.
+func ValidateAgentMailConfig(cfg *AgentMailConfig) error {
+	switch cfg.Backend {
+	case "", "http", "embedded":
+		return nil
+	default:
+		return fmt.Errorf("backend must be \"http\" or \"embedded\", got %q", cfg.Backend)
+	}
+}
+
 // IntegrationsConfig holds external tool integration settings.
 type IntegrationsConfig struct {
 	DCG           DCGConfig           `toml:"dcg"`
@@ -901,7 +1564,66 @@ type DCGConfig struct {
 
 // AssignConfig holds configuration for the ntm assign command
 type AssignConfig struct {
-	Strategy string `toml:"strategy"` // Default strategy: balanced, speed, quality, dependency, round-robin
+	Strategy string             `toml:"strategy"` // Default strategy: balanced, speed, quality, dependency, round-robin
+	Verify   AssignVerifyConfig `toml:"verify"`   // Checks run when an agent completes a task
+
+	// Script is a Lua policy script that can forbid pairings, adjust
+	// scores and pair reviewers. Relative paths resolve against the
+	// project; .ntm/assign.lua is used when it exists and Script is empty.
+	Script string `toml:"script"`
+}
+
+// AssignVerifyConfig configures the verification commands ntm assign --watch
+// runs against a task an agent reports as complete. Failing tasks are sent
+// back to the agent with the failing output.
+type AssignVerifyConfig struct {
+	Enabled        bool                  `toml:"enabled"`
+	Commands       []AssignVerifyCommand `toml:"commands"`        // Run in order in the project directory
+	TimeoutSeconds int                   `toml:"timeout_seconds"` // Per command
+	MaxBounces     int                   `toml:"max_bounces"`     // Times a failing task is sent back before it is marked failed
+	OutputLines    int                   `toml:"output_lines"`    // Output lines kept per failing command
+
+	// Flaky commands: a failing command is rerun Retries times. Passing on
+	// retry, or failing differently, counts as a flake; after
+	// QuarantineAfter flakes (0 = never) the command is quarantined in
+	// .ntm/flaky.json and its failures no longer fail verification.
+	Retries         int `toml:"retries"`
+	QuarantineAfter int `toml:"quarantine_after"`
+}
+
+// AssignVerifyCommand is one verification command.
+type AssignVerifyCommand struct {
+	Name string `toml:"name"` // e.g. test, build, lint
+	Run  string `toml:"run"`  // Shell command
+}
+
+// ValidateAssignConfig validates the assign verification settings.
+func ValidateAssignConfig(cfg *AssignConfig) error {
+	v := cfg.Verify
+	if v.TimeoutSeconds < 0 {
+		return fmt.Errorf("verify.timeout_seconds must be non-negative, got %d", v.TimeoutSeconds)
+	}
+	if v.MaxBounces < 0 {
+		return fmt.Errorf("verify.max_bounces must be non-negative, got %d", v.MaxBounces)
+	}
+	if v.OutputLines < 0 {
+		return fmt.Errorf("verify.output_lines must be non-negative, got %d", v.OutputLines)
+	}
+	if v.Retries < 0 {
+		return fmt.Errorf("verify.retries must be non-negative, got %d", v.Retries)
+	}
+	if v.QuarantineAfter < 0 {
+		return fmt.Errorf("verify.quarantine_after must be non-negative, got %d", v.QuarantineAfter)
+	}
+	for i, c := range v.Commands {
+		if strings.TrimSpace(c.Run) == "" {
+			return fmt.Errorf("verify.commands[%d]: run is required", i)
+		}
+	}
+	if v.Enabled && len(v.Commands) == 0 {
+		return fmt.Errorf("verify is enabled but has no commands")
+	}
+	return nil
 }
 
 // ValidAssignStrategies are the recognized assignment strategies
@@ -921,6 +1643,13 @@ func IsValidStrategy(strategy string) bool {
 func DefaultAssignConfig() AssignConfig {
 	return AssignConfig{
 		Strategy: "balanced",
+		Verify: AssignVerifyConfig{
+			TimeoutSeconds:  600,
+			MaxBounces:      2,
+			OutputLines:     40,
+			Retries:         1,
+			QuarantineAfter: 3,
+		},
 	}
 }
 
@@ -1786,11 +2515,12 @@ func ValidatePrivacyConfig(cfg *PrivacyConfig) error {
 }
 
 // EncryptionConfig controls encryption at rest for NTM artifacts
-// (prompt history, event logs, checkpoint exports).
+// (prompt history, event logs, capture archives, audit logs, checkpoint exports).
 type EncryptionConfig struct {
 	// Enabled is the master toggle for encryption at rest (default false).
 	Enabled bool `toml:"enabled"`
-	// KeySource selects how the encryption key is provided: env, file, or command.
+	// KeySource selects how the encryption key is provided: env, file,
+	// command (e.g. a KMS CLI), or keychain (macOS Keychain / Secret Service).
 	KeySource string `toml:"key_source"`
 	// KeyEnv is the environment variable name holding the key (for key_source=env).
 	KeyEnv string `toml:"key_env"`
@@ -1798,6 +2528,10 @@ type EncryptionConfig struct {
 	KeyFile string `toml:"key_file"`
 	// KeyCommand is a shell command that prints the key to stdout (for key_source=command).
 	KeyCommand string `toml:"key_command"`
+	// KeychainService and KeychainAccount locate the key for key_source=keychain
+	// (defaults: "ntm" / "encryption-key").
+	KeychainService string `toml:"keychain_service"`
+	KeychainAccount string `toml:"keychain_account"`
 	// KeyFormat is the encoding of the key material: hex or base64.
 	KeyFormat string `toml:"key_format"`
 	// ActiveKeyID selects which keyring entry to use for new writes (optional).
@@ -1822,12 +2556,12 @@ func ValidateEncryptionConfig(cfg *EncryptionConfig) error {
 		return nil
 	}
 	switch cfg.KeySource {
-	case "env", "file", "command":
+	case "env", "file", "command", "keychain":
 		// valid
 	case "":
 		return fmt.Errorf("encryption.key_source is required when encryption is enabled")
 	default:
-		return fmt.Errorf("invalid encryption.key_source %q: must be env, file, or command", cfg.KeySource)
+		return fmt.Errorf("invalid encryption.key_source %q: must be env, file, command, or keychain", cfg.KeySource)
 	}
 	switch cfg.KeyFormat {
 	case "hex", "base64", "":
@@ -1845,8 +2579,56 @@ func ValidateEncryptionConfig(cfg *EncryptionConfig) error {
 
 // SendConfig holds defaults for the send command.
 type SendConfig struct {
-	BasePrompt     string `toml:"base_prompt"`      // Text prepended to all prompts
-	BasePromptFile string `toml:"base_prompt_file"` // File whose contents are prepended to all prompts
+	BasePrompt           string `toml:"base_prompt"`             // Text prepended to all prompts
+	BasePromptFile       string `toml:"base_prompt_file"`        // File whose contents are prepended to all prompts
+	VerifyDelivery       bool   `toml:"verify_delivery"`         // Confirm prompts appear in agent panes and retry if not
+	DeliveryRetries      int    `toml:"delivery_retries"`        // Resends before an unconfirmed prompt is reported
+	BracketedPaste       bool   `toml:"bracketed_paste"`         // Paste multi-line prompts with bracketed-paste markers
+	PasteChunkSize       int    `toml:"paste_chunk_size"`        // Largest piece of a prompt sent at once, in bytes
+	PasteChunkDelayMs    int    `toml:"paste_chunk_delay_ms"`    // Pause between chunks of a long prompt
+	FileHandoffOver      int    `toml:"file_handoff_over"`       // Hand prompts larger than this (bytes) to agents as a file (0 = never)
+	FileHandoffDir       string `toml:"file_handoff_dir"`        // Where handoff files are written (default: $TMPDIR/ntm-prompts, kept owner-only)
+	FileHandoffKeepHours int    `toml:"file_handoff_keep_hours"` // Handoff files older than this are removed
+
+	// Profiles overrides how prompts are typed and submitted per agent type,
+	// keyed by claude, codex, gemini, cursor, windsurf, aider, ...
+	Profiles map[string]SendProfileConfig `toml:"profiles"`
+}
+
+// SendProfileConfig overrides the built-in send profile for one agent type
+// ([send.profiles.codex] etc.). Unset fields keep the built-in behavior.
+type SendProfileConfig struct {
+	Submit           []string            `toml:"submit"`             // Keys pressed after the prompt, e.g. ["Enter"] or ["C-j"]
+	SubmitDelayMs    int                 `toml:"submit_delay_ms"`    // Pause before the first submit key
+	SubmitIntervalMs int                 `toml:"submit_interval_ms"` // Pause between submit keys
+	Paste            string              `toml:"paste"`              // never, multiline, or always
+	PasteOver        int                 `toml:"paste_over"`         // Also paste prompts longer than this many bytes
+	Newline          string              `toml:"newline"`            // Key typed for line breaks when typing (e.g. "C-j")
+	PreKeys          []string            `toml:"pre_keys"`           // Keys pressed before the prompt (e.g. ["Escape"])
+	Escapes          string              `toml:"escapes"`            // keep or strip
+	AutoConfirm      []AutoConfirmConfig `toml:"auto_confirm"`       // Answers to confirmation prompts
+	FileHandoff      string              `toml:"file_handoff"`       // Instruction sent for file handoffs; {path} is the file
+}
+
+// AutoConfirmConfig answers a confirmation prompt: when Match (a regular
+// expression) matches the last lines of the pane, Keys are pressed before the
+// prompt is sent.
+type AutoConfirmConfig struct {
+	Match string   `toml:"match"`
+	Keys  []string `toml:"keys"`
+}
+
+// DefaultSendConfig returns the default send configuration.
+func DefaultSendConfig() SendConfig {
+	return SendConfig{
+		VerifyDelivery:       true,
+		DeliveryRetries:      2,
+		BracketedPaste:       true,
+		PasteChunkSize:       4096,
+		PasteChunkDelayMs:    20,
+		FileHandoffOver:      50000,
+		FileHandoffKeepHours: 24,
+	}
 }
 
 // PromptsConfig holds per-agent-type default prompts (bd-2ywo).
@@ -1942,11 +2724,14 @@ func Default() *Config {
 			PaletteKey:         "F6",
 			PaneInitDelayMs:    1000,
 			HistoryLimit:       50000,
+			MaxCaptureBytes:    4 << 20,
+			MaxPaneBytes:       16 << 20,
 			ActivityIndicators: DefaultActivityIndicatorConfig(),
 		},
 		Robot: DefaultRobotConfig(),
 		AgentMail: AgentMailConfig{
 			Enabled:      true,
+			Backend:      "http",
 			URL:          DefaultAgentMailURL,
 			Token:        "",
 			AutoRegister: true,
@@ -1968,7 +2753,16 @@ func Default() *Config {
 		ContextRotation: DefaultContextRotationConfig(),
 		SessionRecovery: DefaultSessionRecoveryConfig(),
 		Cleanup:         DefaultCleanupConfig(),
+		Storage:         DefaultStorageConfig(),
+		Audit:           DefaultAuditConfig(),
+		Billing:         DefaultBillingConfig(),
+		WindDown:        DefaultWindDownConfig(),
+		Archive:         DefaultArchiveConfig(),
 		FileReservation: DefaultFileReservationConfig(),
+		Conflicts:       DefaultConflictsConfig(),
+		Daemon:          DefaultDaemonConfig(),
+		Wasm:            DefaultWasmConfig(),
+		Deadlines:       DefaultDeadlinesConfig(),
 		Memory:          DefaultMemoryConfig(),
 		Assign:          DefaultAssignConfig(),
 		Ensemble:        DefaultEnsembleConfig(),
@@ -1979,6 +2773,7 @@ func Default() *Config {
 		Privacy:         DefaultPrivacyConfig(),
 		Encryption:      DefaultEncryptionConfig(),
 		SpawnPacing:     DefaultSpawnPacingConfig(),
+		Send:            DefaultSendConfig(),
 	}
 
 	// Apply safety profile defaults (standard/safe/paranoid).
@@ -2174,6 +2969,9 @@ func Load(path string) (*Config, error) {
 	if enabled := os.Getenv("AGENT_MAIL_ENABLED"); enabled != "" {
 		cfg.AgentMail.Enabled = enabled == "1" || enabled == "true"
 	}
+	if backend := os.Getenv("AGENT_MAIL_BACKEND"); backend != "" {
+		cfg.AgentMail.Backend = backend
+	}
 
 	// Scanner Env Overrides
 	applyEnvOverrides(&cfg.Scanner)
@@ -2469,6 +3267,8 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "default_panes = %d\n", cfg.Tmux.DefaultPanes)
 	fmt.Fprintf(w, "palette_key = %q\n", cfg.Tmux.PaletteKey)
 	fmt.Fprintf(w, "pane_init_delay_ms = %d  # Delay before send-keys to new panes\n", cfg.Tmux.PaneInitDelayMs)
+	fmt.Fprintf(w, "max_capture_bytes = %d  # Truncate a single capture beyond this (head and tail kept)\n", cfg.Tmux.MaxCaptureBytes)
+	fmt.Fprintf(w, "max_pane_bytes = %d    # Captured bytes retained per pane\n", cfg.Tmux.MaxPaneBytes)
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "[robot]")
@@ -2490,12 +3290,42 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "pretty = %t\n", cfg.Robot.Output.Pretty)
 	fmt.Fprintf(w, "timestamps = %t\n", cfg.Robot.Output.Timestamps)
 	fmt.Fprintf(w, "compress = %t\n", cfg.Robot.Output.Compress)
+	fmt.Fprintf(w, "toon_align = %t\n", cfg.Robot.Output.TOONAlign)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "[robot.output.toon_abbreviations]")
+	fmt.Fprintln(w, "# Short TOON table header aliases (field = \"alias\")")
+	if len(cfg.Robot.Output.TOONAbbreviations) == 0 {
+		fmt.Fprintln(w, "# agent_type = \"t\"")
+	} else {
+		abbrevFields := make([]string, 0, len(cfg.Robot.Output.TOONAbbreviations))
+		for field := range cfg.Robot.Output.TOONAbbreviations {
+			abbrevFields = append(abbrevFields, field)
+		}
+		sort.Strings(abbrevFields)
+		for _, field := range abbrevFields {
+			fmt.Fprintf(w, "%s = %q\n", field, cfg.Robot.Output.TOONAbbreviations[field])
+		}
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "[robot.confirm]")
+	fmt.Fprintln(w, "# Destructive commands run via robot flags or --json need a confirmation token")
+	fmt.Fprintln(w, "# Classes: kill, restart, interrupt, stop, purge")
+	confirmItems := make([]string, 0, len(cfg.Robot.Confirm.Require))
+	for _, c := range cfg.Robot.Confirm.Require {
+		confirmItems = append(confirmItems, fmt.Sprintf("\"%s\"", c))
+	}
+	fmt.Fprintf(w, "require = [%s]\n", strings.Join(confirmItems, ", "))
+	fmt.Fprintf(w, "ttl_seconds = %d\n", cfg.Robot.Confirm.TTLSeconds)
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "[agent_mail]")
 	fmt.Fprintln(w, "# Agent Mail server settings for multi-agent coordination")
-	fmt.Fprintln(w, "# Environment variables: AGENT_MAIL_URL, AGENT_MAIL_TOKEN, AGENT_MAIL_ENABLED")
+	fmt.Fprintln(w, "# Environment variables: AGENT_MAIL_URL, AGENT_MAIL_TOKEN, AGENT_MAIL_ENABLED, AGENT_MAIL_BACKEND")
 	fmt.Fprintf(w, "enabled = %t\n", cfg.AgentMail.Enabled)
+	fmt.Fprintln(w, "# backend: \"http\" uses the server at url; \"embedded\" keeps mail and reservations in .ntm/agentmail.db")
+	fmt.Fprintf(w, "backend = %q\n", cfg.AgentMail.Backend)
 	fmt.Fprintf(w, "url = %q\n", cfg.AgentMail.URL)
 	if cfg.AgentMail.Token != "" {
 		// Mask token in output for security
@@ -2652,6 +3482,8 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "mail_backlog_threshold = %d  # Unread messages before alerting\n", cfg.Alerts.MailBacklogThreshold)
 	fmt.Fprintf(w, "bead_stale_hours = %d       # Hours before in-progress bead is stale\n", cfg.Alerts.BeadStaleHours)
 	fmt.Fprintf(w, "resolved_prune_minutes = %d # How long to keep resolved alerts\n", cfg.Alerts.ResolvedPruneMinutes)
+	fmt.Fprintf(w, "agent_cpu_percent = %.0f     # CPU of an agent's process tree before alerting (100 = one core)\n", cfg.Alerts.AgentCPUPercent)
+	fmt.Fprintf(w, "agent_memory_percent = %.0f   # Share of system memory an agent's process tree may use\n", cfg.Alerts.AgentMemoryPercent)
 	fmt.Fprintln(w)
 
 	// Write checkpoints configuration
@@ -2725,6 +3557,7 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "health_check_seconds = %d   # Seconds between health checks\n", cfg.Resilience.HealthCheckSeconds)
 	fmt.Fprintf(w, "notify_on_crash = %t       # Send notification when agent crashes\n", cfg.Resilience.NotifyOnCrash)
 	fmt.Fprintf(w, "notify_on_max_restarts = %t # Notify when max restarts exceeded\n", cfg.Resilience.NotifyOnMaxRestarts)
+	fmt.Fprintf(w, "shutdown_timeout_seconds = %d # Deadline for flushing state on shutdown\n", cfg.Resilience.ShutdownTimeoutSeconds)
 	fmt.Fprintln(w)
 
 	// Write rate limit sub-configuration
@@ -2732,6 +3565,8 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintln(w, "# Rate limit detection configuration")
 	fmt.Fprintf(w, "detect = %t   # Enable rate limit detection\n", cfg.Resilience.RateLimit.Detect)
 	fmt.Fprintf(w, "notify = %t   # Send notification on rate limit\n", cfg.Resilience.RateLimit.Notify)
+	fmt.Fprintf(w, "auto_resume = %t   # Re-send a parked pane's prompt when its cooldown ends\n", cfg.Resilience.RateLimit.AutoResume)
+	fmt.Fprintf(w, "resume_jitter_seconds = %d   # Random delay of up to this long before a resume\n", cfg.Resilience.RateLimit.ResumeJitterSeconds)
 	if len(cfg.Resilience.RateLimit.Patterns) > 0 {
 		patternItems := make([]string, 0, len(cfg.Resilience.RateLimit.Patterns))
 		for _, p := range cfg.Resilience.RateLimit.Patterns {
@@ -2742,6 +3577,41 @@ func Print(cfg *Config, w io.Writer) error {
 		fmt.Fprintln(w, "# patterns = [\"custom pattern\"]  # Custom patterns (in addition to defaults)")
 	}
 	fmt.Fprintln(w)
+	if len(cfg.Resilience.RateLimit.Schedule) == 0 {
+		fmt.Fprintln(w, "# Quiet hours / burst windows layered on the learned delays:")
+		fmt.Fprintln(w, "# [[resilience.rate_limit.schedule]]")
+		fmt.Fprintln(w, "# name = \"business-hours\"")
+		fmt.Fprintln(w, "# days = \"mon-fri\"        # Cron day-of-week field")
+		fmt.Fprintln(w, "# hours = \"9-17\"          # Cron hour field (\"22-5\" wraps midnight)")
+		fmt.Fprintln(w, "# min_delay_seconds = 60   # Or delay_factor = 0.5, or pause = true")
+		fmt.Fprintln(w)
+	}
+	for _, r := range cfg.Resilience.RateLimit.Schedule {
+		fmt.Fprintln(w, "[[resilience.rate_limit.schedule]]")
+		fmt.Fprintf(w, "name = %q\n", r.Name)
+		if len(r.Providers) > 0 {
+			items := make([]string, 0, len(r.Providers))
+			for _, p := range r.Providers {
+				items = append(items, fmt.Sprintf("%q", p))
+			}
+			fmt.Fprintf(w, "providers = [%s]\n", strings.Join(items, ", "))
+		}
+		fmt.Fprintf(w, "days = %q\n", r.Days)
+		fmt.Fprintf(w, "hours = %q\n", r.Hours)
+		if r.Timezone != "" {
+			fmt.Fprintf(w, "timezone = %q\n", r.Timezone)
+		}
+		if r.MinDelaySeconds > 0 {
+			fmt.Fprintf(w, "min_delay_seconds = %d\n", r.MinDelaySeconds)
+		}
+		if r.DelayFactor > 0 {
+			fmt.Fprintf(w, "delay_factor = %g\n", r.DelayFactor)
+		}
+		if r.Pause {
+			fmt.Fprintln(w, "pause = true")
+		}
+		fmt.Fprintln(w)
+	}
 
 	// Write accounts configuration
 	fmt.Fprintln(w, "[accounts]")
@@ -2749,6 +3619,7 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "state_file = %q            # Path to account state JSON\n", cfg.Accounts.StateFile)
 	fmt.Fprintf(w, "auto_rotate = %t            # Auto-rotate when limit detected\n", cfg.Accounts.AutoRotate)
 	fmt.Fprintf(w, "reset_buffer_minutes = %d   # Minutes before reset to consider available\n", cfg.Accounts.ResetBufferMinutes)
+	fmt.Fprintf(w, "round_robin = %t           # Spread spawned panes across each provider's accounts\n", cfg.Accounts.RoundRobin)
 	fmt.Fprintln(w)
 
 	// Write Claude accounts if any
@@ -2890,6 +3761,8 @@ func Print(cfg *Config, w io.Writer) error {
 	fmt.Fprintf(w, "min_session_age_sec = %d        # Don't rotate agents younger than this\n", cfg.ContextRotation.MinSessionAgeSec)
 	fmt.Fprintf(w, "try_compact_first = %t         # Try to compact before rotating\n", cfg.ContextRotation.TryCompactFirst)
 	fmt.Fprintf(w, "require_confirm = %t           # Require user confirmation before rotating\n", cfg.ContextRotation.RequireConfirm)
+	fmt.Fprintf(w, "auto_compact = %t              # Summarize + /compact (or restart) agents past warning_threshold\n", cfg.ContextRotation.AutoCompact)
+	fmt.Fprintf(w, "summary_wait_sec = %d           # Seconds an agent gets to write its summary\n", cfg.ContextRotation.SummaryWaitSec)
 	fmt.Fprintln(w)
 
 	fmt.Fprintln(w, "[ensemble]")
@@ -3156,6 +4029,10 @@ func GetValue(cfg *Config, path string) (interface{}, error) {
 			return cfg.Tmux.PaletteKey, nil
 		case "pane_init_delay_ms":
 			return cfg.Tmux.PaneInitDelayMs, nil
+		case "max_capture_bytes":
+			return cfg.Tmux.MaxCaptureBytes, nil
+		case "max_pane_bytes":
+			return cfg.Tmux.MaxPaneBytes, nil
 		}
 	case "agent_mail":
 		if len(parts) < 2 {
@@ -3164,6 +4041,8 @@ func GetValue(cfg *Config, path string) (interface{}, error) {
 		switch parts[1] {
 		case "enabled":
 			return cfg.AgentMail.Enabled, nil
+		case "backend":
+			return cfg.AgentMail.Backend, nil
 		case "url":
 			return cfg.AgentMail.URL, nil
 		case "token":
@@ -3206,6 +4085,10 @@ func GetValue(cfg *Config, path string) (interface{}, error) {
 			return cfg.Alerts.AgentStuckMinutes, nil
 		case "disk_low_threshold_gb":
 			return cfg.Alerts.DiskLowThresholdGB, nil
+		case "agent_cpu_percent":
+			return cfg.Alerts.AgentCPUPercent, nil
+		case "agent_memory_percent":
+			return cfg.Alerts.AgentMemoryPercent, nil
 		}
 	case "checkpoints":
 		if len(parts) < 2 {
@@ -3228,6 +4111,8 @@ func GetValue(cfg *Config, path string) (interface{}, error) {
 			return cfg.Resilience.AutoRestart, nil
 		case "max_restarts":
 			return cfg.Resilience.MaxRestarts, nil
+		case "shutdown_timeout_seconds":
+			return cfg.Resilience.ShutdownTimeoutSeconds, nil
 		}
 	case "context_rotation":
 		if len(parts) < 2 {
@@ -3240,6 +4125,8 @@ func GetValue(cfg *Config, path string) (interface{}, error) {
 			return cfg.ContextRotation.WarningThreshold, nil
 		case "rotate_threshold":
 			return cfg.ContextRotation.RotateThreshold, nil
+		case "auto_compact":
+			return cfg.ContextRotation.AutoCompact, nil
 		}
 	case "context":
 		if len(parts) < 2 {
@@ -3442,9 +4329,12 @@ func Diff(cfg *Config) []ConfigDiff {
 	addDiff("tmux.default_panes", defaults.Tmux.DefaultPanes, cfg.Tmux.DefaultPanes)
 	addDiff("tmux.palette_key", defaults.Tmux.PaletteKey, cfg.Tmux.PaletteKey)
 	addDiff("tmux.pane_init_delay_ms", defaults.Tmux.PaneInitDelayMs, cfg.Tmux.PaneInitDelayMs)
+	addDiff("tmux.max_capture_bytes", defaults.Tmux.MaxCaptureBytes, cfg.Tmux.MaxCaptureBytes)
+	addDiff("tmux.max_pane_bytes", defaults.Tmux.MaxPaneBytes, cfg.Tmux.MaxPaneBytes)
 
 	// Agent Mail
 	addDiff("agent_mail.enabled", defaults.AgentMail.Enabled, cfg.AgentMail.Enabled)
+	addDiff("agent_mail.backend", defaults.AgentMail.Backend, cfg.AgentMail.Backend)
 	addDiff("agent_mail.url", defaults.AgentMail.URL, cfg.AgentMail.URL)
 	addDiff("agent_mail.auto_register", defaults.AgentMail.AutoRegister, cfg.AgentMail.AutoRegister)
 
@@ -3469,6 +4359,7 @@ func Diff(cfg *Config) []ConfigDiff {
 	// Resilience
 	addDiff("resilience.auto_restart", defaults.Resilience.AutoRestart, cfg.Resilience.AutoRestart)
 	addDiff("resilience.max_restarts", defaults.Resilience.MaxRestarts, cfg.Resilience.MaxRestarts)
+	addDiff("resilience.shutdown_timeout_seconds", defaults.Resilience.ShutdownTimeoutSeconds, cfg.Resilience.ShutdownTimeoutSeconds)
 
 	// Context pack options
 	addDiff("context.ms_skills", defaults.Context.MSSkills, cfg.Context.MSSkills)
@@ -3477,6 +4368,7 @@ func Diff(cfg *Config) []ConfigDiff {
 	addDiff("context_rotation.enabled", defaults.ContextRotation.Enabled, cfg.ContextRotation.Enabled)
 	addDiff("context_rotation.warning_threshold", defaults.ContextRotation.WarningThreshold, cfg.ContextRotation.WarningThreshold)
 	addDiff("context_rotation.rotate_threshold", defaults.ContextRotation.RotateThreshold, cfg.ContextRotation.RotateThreshold)
+	addDiff("context_rotation.auto_compact", defaults.ContextRotation.AutoCompact, cfg.ContextRotation.AutoCompact)
 
 	// Ensemble defaults
 	addDiff("ensemble.default_ensemble", defaults.Ensemble.DefaultEnsemble, cfg.Ensemble.DefaultEnsemble)
@@ -3556,6 +4448,19 @@ func Validate(cfg *Config) []error {
 	if err := ValidateRobotOutputConfig(&cfg.Robot.Output); err != nil {
 		errs = append(errs, fmt.Errorf("robot.output: %w", err))
 	}
+	if err := ValidateRobotConfirmConfig(&cfg.Robot.Confirm); err != nil {
+		errs = append(errs, fmt.Errorf("robot.confirm: %w", err))
+	}
+
+	// Validate assign verification commands
+	if err := ValidateAssignConfig(&cfg.Assign); err != nil {
+		errs = append(errs, fmt.Errorf("assign: %w", err))
+	}
+
+	// Validate Agent Mail backend selection
+	if err := ValidateAgentMailConfig(&cfg.AgentMail); err != nil {
+		errs = append(errs, fmt.Errorf("agent_mail: %w", err))
+	}
 
 	// Validate DCG integration config
 	if err := ValidateDCGConfig(&cfg.Integrations.DCG); err != nil {
@@ -3595,11 +4500,79 @@ func Validate(cfg *Config) []error {
 		errs = append(errs, fmt.Errorf("encryption: %w", err))
 	}
 
+	// Validate storage quotas
+	if err := ValidateStorageConfig(&cfg.Storage); err != nil {
+		errs = append(errs, fmt.Errorf("storage: %w", err))
+	}
+
+	// Validate audit sink
+	if err := ValidateAuditConfig(&cfg.Audit); err != nil {
+		errs = append(errs, fmt.Errorf("audit: %w", err))
+	}
+
+	// Validate billing pollers
+	if err := ValidateBillingConfig(&cfg.Billing); err != nil {
+		errs = append(errs, fmt.Errorf("billing: %w", err))
+	}
+
+	// Validate session wind-down
+	if err := ValidateWindDownConfig(&cfg.WindDown); err != nil {
+		errs = append(errs, fmt.Errorf("wind_down: %w", err))
+	}
+
+	// Validate recurring fleet runs
+	if err := ValidateSchedules(cfg.Schedules); err != nil {
+		errs = append(errs, fmt.Errorf("schedules: %w", err))
+	}
+
+	// Validate archive capture scheduling
+	if err := ValidateArchiveConfig(&cfg.Archive); err != nil {
+		errs = append(errs, fmt.Errorf("archive: %w", err))
+	}
+
+	// Validate conflict detection filters
+	if err := ValidateConflictsConfig(&cfg.Conflicts); err != nil {
+		errs = append(errs, fmt.Errorf("conflicts: %w", err))
+	}
+
+	// Validate daemon subsystem selection
+	if err := ValidateDaemonConfig(&cfg.Daemon); err != nil {
+		errs = append(errs, fmt.Errorf("daemon: %w", err))
+	}
+
+	// Validate WASM runtime limits
+	if err := ValidateWasmConfig(&cfg.Wasm); err != nil {
+		errs = append(errs, fmt.Errorf("wasm: %w", err))
+	}
+
+	// Validate operation deadlines
+	if err := ValidateDeadlinesConfig(&cfg.Deadlines); err != nil {
+		errs = append(errs, fmt.Errorf("deadlines: %w", err))
+	}
+
 	// Validate spawn pacing config
 	if err := ValidateSpawnPacingConfig(&cfg.SpawnPacing); err != nil {
 		errs = append(errs, fmt.Errorf("spawn_pacing: %w", err))
 	}
 
+	// Validate account pools
+	if err := ValidateAccountsConfig(&cfg.Accounts); err != nil {
+		errs = append(errs, fmt.Errorf("accounts: %w", err))
+	}
+
+	// Validate rate limit schedule rules
+	if _, err := cfg.Resilience.RateLimit.BuildSchedule(); err != nil {
+		errs = append(errs, fmt.Errorf("resilience.rate_limit: %w", err))
+	}
+	if cfg.Resilience.RateLimit.ResumeJitterSeconds < 0 {
+		errs = append(errs, fmt.Errorf("resilience.rate_limit: resume_jitter_seconds must be >= 0"))
+	}
+
+	// Validate per-pane env injection
+	if err := ValidateSpawnEnvConfig(&cfg.SpawnEnv); err != nil {
+		errs = append(errs, fmt.Errorf("spawn_env: %w", err))
+	}
+
 	// Validate projects_base if set
 	if cfg.ProjectsBase != "" {
 		expanded := ExpandHome(cfg.ProjectsBase)
@@ -3643,6 +4616,9 @@ func Validate(cfg *Config) []error {
 	if cfg.Resilience.RestartDelaySeconds < 0 {
 		errs = append(errs, fmt.Errorf("resilience.restart_delay_seconds: must be non-negative, got %d", cfg.Resilience.RestartDelaySeconds))
 	}
+	if cfg.Resilience.ShutdownTimeoutSeconds < 0 {
+		errs = append(errs, fmt.Errorf("resilience.shutdown_timeout_seconds: must be non-negative, got %d", cfg.Resilience.ShutdownTimeoutSeconds))
+	}
 
 	// Validate CASS timeout
 	if cfg.CASS.Timeout < 0 {
@@ -3673,6 +4649,12 @@ func Validate(cfg *Config) []error {
 	if cfg.Tmux.PaneInitDelayMs < 0 {
 		errs = append(errs, fmt.Errorf("tmux.pane_init_delay_ms: must be non-negative, got %d", cfg.Tmux.PaneInitDelayMs))
 	}
+	if cfg.Tmux.MaxCaptureBytes < 0 {
+		errs = append(errs, fmt.Errorf("tmux.max_capture_bytes: must be non-negative, got %d", cfg.Tmux.MaxCaptureBytes))
+	}
+	if cfg.Tmux.MaxPaneBytes < 0 {
+		errs = append(errs, fmt.Errorf("tmux.max_pane_bytes: must be non-negative, got %d", cfg.Tmux.MaxPaneBytes))
+	}
 
 	return errs
 }
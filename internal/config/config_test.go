@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -862,6 +863,59 @@ priority = 2
 	}
 }
 
+func TestAccountsPoolFromTOML(t *testing.T) {
+	configContent := `
+[accounts]
+round_robin = true
+
+[[accounts.codex]]
+alias = "personal"
+priority = 2
+
+[[accounts.codex]]
+alias = "work"
+priority = 1
+env = { files = ["~/.config/ntm/openai-work.env"] }
+`
+	configPath := createTempConfig(t, configContent)
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if errs := Validate(cfg); len(errs) > 0 {
+		t.Fatalf("Validate: %v", errs)
+	}
+
+	pool := cfg.Accounts.Pool("cod")
+	if len(pool) != 2 || pool[0].Name() != "work" || pool[1].Name() != "personal" {
+		t.Fatalf("pool = %+v, want work then personal", pool)
+	}
+	if len(pool[0].Env.Files) != 1 {
+		t.Errorf("work env = %+v", pool[0].Env)
+	}
+	if len(cfg.Accounts.Pool("cc")) != 0 {
+		t.Error("expected empty claude pool")
+	}
+
+	cfg.Accounts.RoundRobin = false
+	if len(cfg.Accounts.Pool("cod")) != 0 {
+		t.Error("pool should be empty without round_robin")
+	}
+}
+
+func TestValidateAccountsConfig(t *testing.T) {
+	for _, bad := range []AccountsConfig{
+		{Codex: []AccountEntry{{Priority: 1}}},
+		{Claude: []AccountEntry{{Alias: "a"}, {Email: "x@example.com", Alias: "a"}}},
+		{Gemini: []AccountEntry{{Alias: "team/a"}}},
+		{Codex: []AccountEntry{{Alias: "a", Env: SpawnEnvSource{Secret: []string{"MISSING"}}}}},
+	} {
+		if err := ValidateAccountsConfig(&bad); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
+
 func TestRotationFromTOML(t *testing.T) {
 	configContent := `
 [rotation]
@@ -2238,6 +2292,70 @@ strategy = "quality"
 	}
 }
 
+func TestAssignVerifyConfigFromTOML(t *testing.T) {
+	configContent := `
+[assign.verify]
+enabled = true
+max_bounces = 1
+
+[[assign.verify.commands]]
+name = "test"
+run = "go test ./..."
+
+[[assign.verify.commands]]
+name = "lint"
+run = "go vet ./..."
+`
+	configPath := createTempConfig(t, configContent)
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	v := cfg.Assign.Verify
+	if !v.Enabled || v.MaxBounces != 1 || len(v.Commands) != 2 {
+		t.Fatalf("verify = %+v, want enabled with 2 commands and 1 bounce", v)
+	}
+	if v.Commands[1].Name != "lint" || v.Commands[1].Run != "go vet ./..." {
+		t.Errorf("commands[1] = %+v", v.Commands[1])
+	}
+	if v.TimeoutSeconds != 600 {
+		t.Errorf("timeout_seconds = %d, want default 600", v.TimeoutSeconds)
+	}
+}
+
+func TestValidateAssignConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		verify  AssignVerifyConfig
+		wantErr string
+	}{
+		{name: "default config is valid", verify: DefaultAssignConfig().Verify},
+		{name: "enabled with command", verify: AssignVerifyConfig{Enabled: true, Commands: []AssignVerifyCommand{{Name: "test", Run: "make test"}}}},
+		{name: "enabled without commands", verify: AssignVerifyConfig{Enabled: true}, wantErr: "no commands"},
+		{name: "empty run", verify: AssignVerifyConfig{Commands: []AssignVerifyCommand{{Name: "test"}}}, wantErr: "run is required"},
+		{name: "negative bounces", verify: AssignVerifyConfig{MaxBounces: -1}, wantErr: "max_bounces"},
+		{name: "negative timeout", verify: AssignVerifyConfig{TimeoutSeconds: -5}, wantErr: "timeout_seconds"},
+		{name: "negative retries", verify: AssignVerifyConfig{Retries: -1}, wantErr: "retries"},
+		{name: "negative quarantine_after", verify: AssignVerifyConfig{QuarantineAfter: -1}, wantErr: "quarantine_after"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAssignConfig(&AssignConfig{Verify: tt.verify})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestAssignConfigDefaultInFullConfig(t *testing.T) {
 	cfg := Default()
 
@@ -2918,6 +3036,39 @@ func TestValidateRobotOutputConfig(t *testing.T) {
 			cfg:     DefaultRobotOutputConfig(),
 			wantErr: false,
 		},
+		{
+			name: "toon abbreviations",
+			cfg: RobotOutputConfig{
+				TOONAlign:         true,
+				TOONAbbreviations: map[string]string{"agent_type": "t", "status": "s"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty toon alias",
+			cfg: RobotOutputConfig{
+				TOONAbbreviations: map[string]string{"agent_type": ""},
+			},
+			wantErr: true,
+			errMsg:  "invalid alias",
+		},
+		{
+			name: "toon alias with delimiter",
+			cfg: RobotOutputConfig{
+				Format:            "toon",
+				TOONAbbreviations: map[string]string{"agent_type": "a,t"},
+			},
+			wantErr: true,
+			errMsg:  "invalid alias",
+		},
+		{
+			name: "shared toon alias",
+			cfg: RobotOutputConfig{
+				TOONAbbreviations: map[string]string{"agent_type": "t", "title": "t"},
+			},
+			wantErr: true,
+			errMsg:  "share alias",
+		},
 	}
 
 	for _, tt := range tests {
@@ -2938,6 +3089,36 @@ func TestValidateRobotOutputConfig(t *testing.T) {
 	}
 }
 
+// TestValidateRobotConfirmConfig tests validation of the confirmation policy
+func TestValidateRobotConfirmConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RobotConfirmConfig
+		wantErr string
+	}{
+		{name: "default config is valid", cfg: DefaultRobotConfirmConfig()},
+		{name: "all classes", cfg: RobotConfirmConfig{Require: RobotConfirmClasses, TTLSeconds: 60}},
+		{name: "empty require", cfg: RobotConfirmConfig{}},
+		{name: "unknown class", cfg: RobotConfirmConfig{Require: []string{"reboot"}}, wantErr: "unknown confirmation class"},
+		{name: "negative ttl", cfg: RobotConfirmConfig{TTLSeconds: -1}, wantErr: "ttl_seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRobotConfirmConfig(&tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestRobotOutputConfigFromTOML tests parsing robot.output from TOML
 func TestRobotOutputConfigFromTOML(t *testing.T) {
 	content := `
@@ -3613,6 +3794,21 @@ func TestValidateFileReservationConfig(t *testing.T) {
 			cfg:     FileReservationConfig{AutoReleaseIdleMin: 0, DefaultTTLMin: 5, PollIntervalSec: 5, CaptureLinesForDetect: 5},
 			wantErr: true,
 		},
+		{
+			name:    "keyword patterns",
+			cfg:     FileReservationConfig{DefaultTTLMin: 5, PollIntervalSec: 5, CaptureLinesForDetect: 20, Keywords: map[string][]string{"auth": {"internal/auth/**"}}, Ownership: map[string]string{"web/**": "BlueLake"}},
+			wantErr: false,
+		},
+		{
+			name:    "absolute keyword pattern",
+			cfg:     FileReservationConfig{DefaultTTLMin: 5, PollIntervalSec: 5, CaptureLinesForDetect: 20, Keywords: map[string][]string{"auth": {"/etc/**"}}},
+			wantErr: true,
+		},
+		{
+			name:    "ownership without owner",
+			cfg:     FileReservationConfig{DefaultTTLMin: 5, PollIntervalSec: 5, CaptureLinesForDetect: 20, Ownership: map[string]string{"web/**": " "}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -3654,6 +3850,16 @@ func TestValidateMemoryConfig(t *testing.T) {
 			cfg:     MemoryConfig{MaxRules: 10, QueryTimeoutSeconds: 0},
 			wantErr: true,
 		},
+		{
+			name:    "known sections",
+			cfg:     MemoryConfig{QueryTimeoutSeconds: 5, Sections: map[string][]string{"codex": {"guards", "patterns"}}},
+			wantErr: false,
+		},
+		{
+			name:    "unknown section",
+			cfg:     MemoryConfig{QueryTimeoutSeconds: 5, Sections: map[string][]string{"codex": {"todos"}}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -4481,3 +4687,266 @@ func TestConfigReset_NoExistingFile(t *testing.T) {
 		t.Fatalf("config file should exist after reset: %v", err)
 	}
 }
+
+func TestValidateArchiveConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ArchiveConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultArchiveConfig(), false},
+		{"zero selects defaults", ArchiveConfig{}, false},
+		{"fixed interval", ArchiveConfig{MinIntervalSeconds: 10, MaxIntervalSeconds: 10, Backoff: 1}, false},
+		{"max below min", ArchiveConfig{MinIntervalSeconds: 30, MaxIntervalSeconds: 10}, true},
+		{"negative interval", ArchiveConfig{MinIntervalSeconds: -1}, true},
+		{"shrinking backoff", ArchiveConfig{Backoff: 0.5}, true},
+		{"pipe mode", ArchiveConfig{Mode: "pipe"}, false},
+		{"unknown mode", ArchiveConfig{Mode: "mmap"}, true},
+		{"dedupe above 1", ArchiveConfig{DedupeThreshold: 1.5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateArchiveConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateArchiveConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConflictsConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ConflictsConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultConflictsConfig(), false},
+		{"relative patterns", ConflictsConfig{Exclude: []string{"gen/**", "*.pb.go", "dist/"}}, false},
+		{"empty pattern", ConflictsConfig{Exclude: []string{" "}}, true},
+		{"absolute pattern", ConflictsConfig{Exclude: []string{"/repo/gen"}}, true},
+		{"workspace repos", ConflictsConfig{Repos: []string{"../api", "/src/web"}}, false},
+		{"empty repo", ConflictsConfig{Repos: []string{""}}, true},
+		{"scoring profile", ConflictsConfig{Scoring: ConflictScoringConfig{
+			Profile: "strict", Weights: map[string]float64{"single_modifier": 0.6}, ToleranceSeconds: 30, MinConfidence: 0.4,
+		}}, false},
+		{"unknown scoring weight", ConflictsConfig{Scoring: ConflictScoringConfig{Weights: map[string]float64{"typo": 0.5}}}, true},
+		{"scoring weight above 1", ConflictsConfig{Scoring: ConflictScoringConfig{Weights: map[string]float64{"concurrent_activity": 1.5}}}, true},
+		{"negative tolerance", ConflictsConfig{Scoring: ConflictScoringConfig{ToleranceSeconds: -1}}, true},
+		{"min confidence above 1", ConflictsConfig{Scoring: ConflictScoringConfig{MinConfidence: 2}}, true},
+		{"suppress rules", ConflictsConfig{Suppress: []ConflictSuppressionConfig{
+			{Path: "package-lock.json", Reason: "lockfile churn"},
+			{Path: "gen/**", Reason: "codegen", Expires: "2026-12-31"},
+			{Path: "*.pb.go", Reason: "protos", Expires: "2026-12-31T12:00:00Z"},
+		}}, false},
+		{"suppress without reason", ConflictsConfig{Suppress: []ConflictSuppressionConfig{{Path: "go.sum"}}}, true},
+		{"suppress without path", ConflictsConfig{Suppress: []ConflictSuppressionConfig{{Reason: "noise"}}}, true},
+		{"suppress bad expiry", ConflictsConfig{Suppress: []ConflictSuppressionConfig{{Path: "go.sum", Reason: "noise", Expires: "soon"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateConflictsConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConflictsConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDaemonConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     DaemonConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultDaemonConfig(), false},
+		{"zero", DaemonConfig{}, false},
+		{"negative reconcile", DaemonConfig{ReconcileSeconds: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDaemonConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDaemonConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := DefaultDaemonConfig().Disabled(); len(got) != 0 {
+		t.Errorf("default Disabled() = %v, want none", got)
+	}
+	cfg := DefaultDaemonConfig()
+	cfg.Watchdog, cfg.Capture = false, false
+	if got := cfg.Disabled(); !slices.Equal(got, []string{"capture", "watchdog"}) {
+		t.Errorf("Disabled() = %v", got)
+	}
+}
+
+func TestValidateBillingConfig(t *testing.T) {
+	enabled := func(mod func(*BillingProviderConfig)) BillingConfig {
+		cfg := DefaultBillingConfig()
+		cfg.OpenAI.Enabled = true
+		cfg.OpenAI.MonthlyCapUSD = 500
+		if mod != nil {
+			mod(&cfg.OpenAI)
+		}
+		return cfg
+	}
+	tests := []struct {
+		name    string
+		cfg     BillingConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultBillingConfig(), false},
+		{"enabled", enabled(nil), false},
+		{"negative poll", BillingConfig{PollMinutes: -1}, true},
+		{"no cap", enabled(func(p *BillingProviderConfig) { p.MonthlyCapUSD = 0 }), true},
+		{"key in place of env name", enabled(func(p *BillingProviderConfig) { p.AdminKeyEnv = "sk-admin-abc" }), true},
+		{"throttle above pause", enabled(func(p *BillingProviderConfig) { p.ThrottleAt = 0.99 }), true},
+		{"pause above cap", enabled(func(p *BillingProviderConfig) { p.PauseAt = 1.5 }), true},
+		{"factor below one", enabled(func(p *BillingProviderConfig) { p.MaxDelayFactor = 0.5 }), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBillingConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateBillingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := enabled(nil).Enabled(); !slices.Equal(got, []string{"openai"}) {
+		t.Errorf("Enabled() = %v", got)
+	}
+}
+
+func TestValidateWindDownConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     WindDownConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultWindDownConfig(), false},
+		{"limit", WindDownConfig{MaxSessionMinutes: 480, WrapUpMinutes: 30}, false},
+		{"negative limit", WindDownConfig{MaxSessionMinutes: -1}, true},
+		{"negative wrap-up", WindDownConfig{WrapUpMinutes: -5}, true},
+		{"wrap-up longer than session", WindDownConfig{MaxSessionMinutes: 30, WrapUpMinutes: 30}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWindDownConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWindDownConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSchedules(t *testing.T) {
+	ok := ScheduleConfig{Name: "nightly-triage", Cron: "0 2 * * *", Template: "triage", TasksFrom: "github:label=triage", ProjectDir: "/src/app"}
+	with := func(fn func(*ScheduleConfig)) []ScheduleConfig {
+		s := ok
+		fn(&s)
+		return []ScheduleConfig{s}
+	}
+	tests := []struct {
+		name      string
+		schedules []ScheduleConfig
+		wantErr   bool
+	}{
+		{"none", nil, false},
+		{"valid", []ScheduleConfig{ok}, false},
+		{"timezone and timeout", with(func(s *ScheduleConfig) { s.Timezone = "UTC"; s.Timeout = "2h" }), false},
+		{"bad name", with(func(s *ScheduleConfig) { s.Name = "nightly triage" }), true},
+		{"bad cron", with(func(s *ScheduleConfig) { s.Cron = "0 25 * * *" }), true},
+		{"bad timezone", with(func(s *ScheduleConfig) { s.Timezone = "Mars/Olympus" }), true},
+		{"no template", with(func(s *ScheduleConfig) { s.Template = "" }), true},
+		{"bad source", with(func(s *ScheduleConfig) { s.TasksFrom = "jira:label=x" }), true},
+		{"no project", with(func(s *ScheduleConfig) { s.ProjectDir = "" }), true},
+		{"bad timeout", with(func(s *ScheduleConfig) { s.Timeout = "soon" }), true},
+		{"duplicate", []ScheduleConfig{ok, ok}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSchedules(tt.schedules); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSchedules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAddRemoveSchedule(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	original := "# my settings\nprojects_base = \"/src\"\n\n[daemon]\ncapture = false\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := ScheduleConfig{Name: "a", Cron: "@daily", Template: "triage", TasksFrom: "github:label=triage", ProjectDir: "/src/app"}
+	b := a
+	b.Name, b.Timezone = "b", "UTC"
+	for _, s := range []ScheduleConfig{a, b} {
+		if err := AddSchedule(path, s); err != nil {
+			t.Fatalf("AddSchedule(%s) error: %v", s.Name, err)
+		}
+	}
+	if err := AddSchedule(path, a); err == nil {
+		t.Error("AddSchedule() accepted a duplicate name")
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Schedules) != 2 || cfg.Schedules[1].Timezone != "UTC" || cfg.Daemon.Capture {
+		t.Fatalf("loaded schedules = %+v, daemon = %+v", cfg.Schedules, cfg.Daemon)
+	}
+
+	for _, name := range []string{"a", "b"} {
+		if removed, err := RemoveSchedule(path, name); err != nil || !removed {
+			t.Fatalf("RemoveSchedule(%s) = %v, %v", name, removed, err)
+		}
+	}
+	if removed, _ := RemoveSchedule(path, "a"); removed {
+		t.Error("RemoveSchedule() removed a missing schedule")
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != original {
+		t.Errorf("config after removal = %q, want %q", data, original)
+	}
+}
+
+func TestValidateWasmConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     WasmConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultWasmConfig(), false},
+		{"zero", WasmConfig{}, false},
+		{"negative memory", WasmConfig{MemoryLimitMB: -1}, true},
+		{"memory over 4GB", WasmConfig{MemoryLimitMB: 4097}, true},
+		{"negative timeout", WasmConfig{TimeoutMs: -5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWasmConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateWasmConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateDeadlinesConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     DeadlinesConfig
+		wantErr bool
+	}{
+		{"defaults", DefaultDeadlinesConfig(), false},
+		{"zero disables", DeadlinesConfig{}, false},
+		{"negative git", DeadlinesConfig{GitSeconds: -1}, true},
+		{"negative oidc", DeadlinesConfig{OIDCSeconds: -5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDeadlinesConfig(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateDeadlinesConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
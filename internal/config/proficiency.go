@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/cli/tiers"
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // ProficiencyConfig stores user proficiency tier and usage statistics.
@@ -114,19 +115,9 @@ func (c *ProficiencyConfig) Save() error {
 	defer c.mu.RUnlock()
 
 	path := proficiencyConfigPath()
-
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(c, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0644)
+	return util.WithFileLock(path, func() error {
+		return c.writeUnlocked(path)
+	})
 }
 
 // GetTier returns the effective proficiency tier.
@@ -155,20 +146,23 @@ func (c *ProficiencyConfig) SetTier(newTier tiers.Tier, reason string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	oldTier := c.Tier
-	if oldTier == int(newTier) {
+	if c.Tier == int(newTier) {
 		return nil // No change
 	}
 
-	c.Tier = int(newTier)
-	c.PromotionHistory = append(c.PromotionHistory, PromotionRecord{
-		From:   oldTier,
-		To:     int(newTier),
-		At:     time.Now(),
-		Reason: reason,
+	return c.updateUnlocked(func() {
+		oldTier := c.Tier
+		if oldTier == int(newTier) {
+			return
+		}
+		c.Tier = int(newTier)
+		c.PromotionHistory = append(c.PromotionHistory, PromotionRecord{
+			From:   oldTier,
+			To:     int(newTier),
+			At:     time.Now(),
+			Reason: reason,
+		})
 	})
-
-	return c.saveUnlocked()
 }
 
 // RecordUsage updates usage statistics.
@@ -176,12 +170,12 @@ func (c *ProficiencyConfig) RecordUsage(commandsRun, sessionsCreated, promptsSen
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.UsageStats.CommandsRun += commandsRun
-	c.UsageStats.SessionsCreated += sessionsCreated
-	c.UsageStats.PromptsSent += promptsSent
-	c.UsageStats.LastUse = time.Now()
-
-	return c.saveUnlocked()
+	return c.updateUnlocked(func() {
+		c.UsageStats.CommandsRun += commandsRun
+		c.UsageStats.SessionsCreated += sessionsCreated
+		c.UsageStats.PromptsSent += promptsSent
+		c.UsageStats.LastUse = time.Now()
+	})
 }
 
 // IncrementCommand increments the command counter and updates last use.
@@ -199,10 +193,28 @@ func (c *ProficiencyConfig) IncrementPrompt() error {
 	return c.RecordUsage(0, 0, 1)
 }
 
-// saveUnlocked persists config (caller must hold lock).
-func (c *ProficiencyConfig) saveUnlocked() error {
+// updateUnlocked applies fn to the config and persists it (caller must hold
+// lock). Other ntm processes update the same file, so under the file lock the
+// config is first reloaded from disk and fn applied to what was read.
+func (c *ProficiencyConfig) updateUnlocked(fn func()) error {
 	path := proficiencyConfigPath()
+	return util.WithFileLock(path, func() error {
+		if data, err := os.ReadFile(path); err == nil {
+			var disk ProficiencyConfig
+			if json.Unmarshal(data, &disk) == nil {
+				c.Tier = disk.Tier
+				c.UsageStats = disk.UsageStats
+				c.PromotionHistory = disk.PromotionHistory
+				c.Suggestion = disk.Suggestion
+			}
+		}
+		fn()
+		return c.writeUnlocked(path)
+	})
+}
 
+// writeUnlocked writes config to path (caller must hold lock).
+func (c *ProficiencyConfig) writeUnlocked(path string) error {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -213,7 +225,7 @@ func (c *ProficiencyConfig) saveUnlocked() error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return util.AtomicWriteFile(path, data, 0644)
 }
 
 // GetUsageStats returns a copy of current usage statistics.
@@ -238,25 +250,25 @@ func (c *ProficiencyConfig) Reset() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	now := time.Now()
-	oldTier := c.Tier
-	c.Tier = int(tiers.TierApprentice)
-	c.UsageStats = UsageStats{
-		UniqueCommands: make(map[string]int),
-		FirstUse:       now,
-		LastUse:        now,
-		LastActiveDate: now.Format("2006-01-02"),
-		DaysActive:     1,
-	}
-	c.Suggestion = SuggestionState{} // Clear suggestion state
-	c.PromotionHistory = append(c.PromotionHistory, PromotionRecord{
-		From:   oldTier,
-		To:     int(tiers.TierApprentice),
-		At:     now,
-		Reason: "reset",
+	return c.updateUnlocked(func() {
+		now := time.Now()
+		oldTier := c.Tier
+		c.Tier = int(tiers.TierApprentice)
+		c.UsageStats = UsageStats{
+			UniqueCommands: make(map[string]int),
+			FirstUse:       now,
+			LastUse:        now,
+			LastActiveDate: now.Format("2006-01-02"),
+			DaysActive:     1,
+		}
+		c.Suggestion = SuggestionState{} // Clear suggestion state
+		c.PromotionHistory = append(c.PromotionHistory, PromotionRecord{
+			From:   oldTier,
+			To:     int(tiers.TierApprentice),
+			At:     now,
+			Reason: "reset",
+		})
 	})
-
-	return c.saveUnlocked()
 }
 
 // DaysSinceFirstUse returns the number of days since first use.
@@ -323,24 +335,24 @@ func (c *ProficiencyConfig) RecordCommand(commandName string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Initialize map if needed
-	if c.UsageStats.UniqueCommands == nil {
-		c.UsageStats.UniqueCommands = make(map[string]int)
-	}
+	return c.updateUnlocked(func() {
+		// Initialize map if needed
+		if c.UsageStats.UniqueCommands == nil {
+			c.UsageStats.UniqueCommands = make(map[string]int)
+		}
 
-	// Track command
-	c.UsageStats.CommandsRun++
-	c.UsageStats.UniqueCommands[commandName]++
-	c.UsageStats.LastUse = time.Now()
+		// Track command
+		c.UsageStats.CommandsRun++
+		c.UsageStats.UniqueCommands[commandName]++
+		c.UsageStats.LastUse = time.Now()
 
-	// Track daily activity
-	today := time.Now().Format("2006-01-02")
-	if c.UsageStats.LastActiveDate != today {
-		c.UsageStats.DaysActive++
-		c.UsageStats.LastActiveDate = today
-	}
-
-	return c.saveUnlocked()
+		// Track daily activity
+		today := time.Now().Format("2006-01-02")
+		if c.UsageStats.LastActiveDate != today {
+			c.UsageStats.DaysActive++
+			c.UsageStats.LastActiveDate = today
+		}
+	})
 }
 
 // RecordAdvancedAttempt tracks when user tries a command above their tier.
@@ -348,10 +360,10 @@ func (c *ProficiencyConfig) RecordAdvancedAttempt(commandName string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.UsageStats.AdvancedAttempts++
-	c.UsageStats.LastUse = time.Now()
-
-	return c.saveUnlocked()
+	return c.updateUnlocked(func() {
+		c.UsageStats.AdvancedAttempts++
+		c.UsageStats.LastUse = time.Now()
+	})
 }
 
 // CheckPromotion checks if promotion should be suggested and returns a non-intrusive message.
@@ -402,10 +414,11 @@ func (c *ProficiencyConfig) CheckPromotion(sessionID string) (bool, string) {
 	}
 
 	// Mark suggestion as shown
-	c.Suggestion.LastShownTime = time.Now()
-	c.Suggestion.LastShownSession = sessionID
-	c.Suggestion.TimesShown++
-	_ = c.saveUnlocked()
+	_ = c.updateUnlocked(func() {
+		c.Suggestion.LastShownTime = time.Now()
+		c.Suggestion.LastShownSession = sessionID
+		c.Suggestion.TimesShown++
+	})
 
 	msg := fmt.Sprintf("Tip: You've been using NTM like a pro! Run 'ntm level %s' to unlock more features.",
 		nextTier.String())
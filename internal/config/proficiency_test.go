@@ -59,6 +59,26 @@ func TestProficiencyLoadSaveCycle(t *testing.T) {
 	}
 }
 
+func TestProficiencyConcurrentInstances(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// Two processes loaded the same file; neither may drop the other's usage.
+	a, _ := LoadProficiency()
+	b, _ := LoadProficiency()
+	if err := a.RecordCommand("spawn"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RecordCommand("send"); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _ := LoadProficiency()
+	stats := loaded.GetUsageStats()
+	if stats.CommandsRun != 2 || stats.UniqueCommands["spawn"] != 1 || stats.UniqueCommands["send"] != 1 {
+		t.Errorf("stats = %+v, want both commands recorded", stats)
+	}
+}
+
 func TestProficiencyEnvOverride(t *testing.T) {
 	tmpDir := t.TempDir()
 	os.Setenv("XDG_CONFIG_HOME", tmpDir)
@@ -344,8 +364,11 @@ func TestProficiencyDaysActive(t *testing.T) {
 		t.Errorf("expected still 1 day active, got %d", stats.DaysActive)
 	}
 
-	// Simulate previous day
+	// Simulate previous day; updates start from the saved file
 	cfg.UsageStats.LastActiveDate = "2024-01-01"
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
 	cfg.RecordCommand("send")
 	stats = cfg.GetUsageStats()
 	if stats.DaysActive != 2 {
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+)
+
+// RateLimitScheduleRule is one [[resilience.rate_limit.schedule]] entry: a
+// recurring window (cron-like day-of-week and hour fields) during which
+// spawn/send pacing is raised, relaxed or paused on top of the delays the
+// rate limit tracker learns.
+type RateLimitScheduleRule struct {
+	Name            string   `toml:"name"`              // Label shown in messages
+	Providers       []string `toml:"providers"`         // Providers or agent types (cc, cod, gmi); empty = all
+	Days            string   `toml:"days"`              // Cron day-of-week field, e.g. "mon-fri" (default "*")
+	Hours           string   `toml:"hours"`             // Cron hour field, e.g. "9-17" or "22-5" (default "*")
+	Timezone        string   `toml:"timezone"`          // IANA zone for days/hours (default local)
+	MinDelaySeconds int      `toml:"min_delay_seconds"` // Raise the stagger delay to at least this
+	DelayFactor     float64  `toml:"delay_factor"`      // Multiply the learned delay (<1 relaxes, >1 slows)
+	Pause           bool     `toml:"pause"`             // Refuse new spawns while the window is active
+}
+
+// BuildSchedule compiles the schedule rules. It returns nil when no rules
+// are configured.
+func (c RateLimitConfig) BuildSchedule() (*ratelimit.Schedule, error) {
+	if len(c.Schedule) == 0 {
+		return nil, nil
+	}
+	rules, err := c.ScheduleRules()
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.NewSchedule(rules)
+}
+
+// ScheduleRules converts the configured rules, resolving time zones, so
+// callers can combine them with rules from other sources before compiling.
+func (c RateLimitConfig) ScheduleRules() ([]ratelimit.ScheduleRule, error) {
+	rules := make([]ratelimit.ScheduleRule, 0, len(c.Schedule))
+	for i, r := range c.Schedule {
+		rule := ratelimit.ScheduleRule{
+			Name:        strings.TrimSpace(r.Name),
+			Providers:   r.Providers,
+			Days:        r.Days,
+			Hours:       r.Hours,
+			MinDelay:    time.Duration(r.MinDelaySeconds) * time.Second,
+			DelayFactor: r.DelayFactor,
+			Pause:       r.Pause,
+		}
+		if tz := strings.TrimSpace(r.Timezone); tz != "" {
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				return nil, fmt.Errorf("schedule[%d]: timezone: %w", i, err)
+			}
+			rule.Location = loc
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
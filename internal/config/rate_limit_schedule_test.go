@@ -0,0 +1,63 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestRateLimitConfig_BuildSchedule(t *testing.T) {
+	var cfg ResilienceConfig
+	if _, err := toml.Decode(`
+[rate_limit]
+detect = true
+
+[[rate_limit.schedule]]
+name = "business-hours"
+providers = ["cc"]
+days = "mon-fri"
+hours = "9-17"
+timezone = "America/New_York"
+min_delay_seconds = 45
+
+[[rate_limit.schedule]]
+name = "night"
+hours = "22-5"
+delay_factor = 0.5
+`, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	schedule, err := cfg.RateLimit.BuildSchedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	// Monday 10:00 in New York.
+	eff := schedule.At("anthropic", time.Date(2026, 10, 12, 10, 0, 0, 0, ny))
+	if eff.MinDelay != 45*time.Second || strings.Join(eff.Rules, ",") != "business-hours" {
+		t.Errorf("effect = %+v", eff)
+	}
+
+	if none, err := (RateLimitConfig{}).BuildSchedule(); none != nil || err != nil {
+		t.Errorf("empty schedule = %v, %v", none, err)
+	}
+}
+
+func TestRateLimitConfig_BuildScheduleErrors(t *testing.T) {
+	for _, rule := range []RateLimitScheduleRule{
+		{Name: "tz", Hours: "9-17", Timezone: "Mars/Olympus", Pause: true},
+		{Name: "hours", Hours: "9-25", Pause: true},
+		{Name: "noop", Days: "sat,sun"},
+	} {
+		cfg := RateLimitConfig{Schedule: []RateLimitScheduleRule{rule}}
+		if _, err := cfg.BuildSchedule(); err == nil {
+			t.Errorf("expected error for %+v", rule)
+		}
+	}
+}
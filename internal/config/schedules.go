@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/Dicklesworthstone/ntm/internal/cron"
+	"github.com/Dicklesworthstone/ntm/internal/githost"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// ScheduleConfig is one [[schedules]] entry: a fleet run that ntm daemon
+// starts on a cron schedule. Each run spawns the session template's agents,
+// turns the open issues matching TasksFrom into tasks, and runs them to
+// completion as 'ntm batch' would.
+type ScheduleConfig struct {
+	Name       string `toml:"name"`
+	Cron       string `toml:"cron"`               // Five-field cron expression or @daily etc.
+	Timezone   string `toml:"timezone,omitempty"` // IANA zone for Cron (default local)
+	Template   string `toml:"template"`           // Session template naming the agents
+	TasksFrom  string `toml:"tasks_from"`         // e.g. "github:label=triage"
+	ProjectDir string `toml:"project_dir"`        // Repository the fleet works in
+	Timeout    string `toml:"timeout,omitempty"`  // Bound on the whole run (default: template maxRuntime, else 30m)
+	Disabled   bool   `toml:"disabled,omitempty"`
+}
+
+var scheduleNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Expr parses the schedule's cron expression.
+func (s ScheduleConfig) Expr() (*cron.Expr, error) {
+	return cron.Parse(s.Cron)
+}
+
+// Location returns the time zone the cron expression is evaluated in.
+func (s ScheduleConfig) Location() (*time.Location, error) {
+	if tz := strings.TrimSpace(s.Timezone); tz != "" {
+		return time.LoadLocation(tz)
+	}
+	return time.Local, nil
+}
+
+// RunTimeout returns the configured run timeout, or 0 if unset.
+func (s ScheduleConfig) RunTimeout() (time.Duration, error) {
+	if strings.TrimSpace(s.Timeout) == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(s.Timeout)
+}
+
+// ValidateScheduleConfig validates one schedule.
+func ValidateScheduleConfig(s *ScheduleConfig) error {
+	if !scheduleNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("name %q must be letters, digits, '-' or '_'", s.Name)
+	}
+	if _, err := s.Expr(); err != nil {
+		return err
+	}
+	if _, err := s.Location(); err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+	if strings.TrimSpace(s.Template) == "" {
+		return fmt.Errorf("template is required")
+	}
+	if _, err := githost.ParseIssueSource(s.TasksFrom); err != nil {
+		return err
+	}
+	if s.ProjectDir == "" {
+		return fmt.Errorf("project_dir is required")
+	}
+	if d, err := s.RunTimeout(); err != nil || d < 0 {
+		return fmt.Errorf("timeout %q must be a positive duration", s.Timeout)
+	}
+	return nil
+}
+
+// ValidateSchedules validates every schedule and that names are unique.
+func ValidateSchedules(schedules []ScheduleConfig) error {
+	seen := make(map[string]bool, len(schedules))
+	for i := range schedules {
+		s := &schedules[i]
+		if err := ValidateScheduleConfig(s); err != nil {
+			return fmt.Errorf("%s: %w", scheduleLabel(s, i), err)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("%s: duplicate name", scheduleLabel(s, i))
+		}
+		seen[s.Name] = true
+	}
+	return nil
+}
+
+func scheduleLabel(s *ScheduleConfig, i int) string {
+	if s.Name != "" {
+		return fmt.Sprintf("schedule %q", s.Name)
+	}
+	return fmt.Sprintf("schedule #%d", i+1)
+}
+
+// FindSchedule returns the schedule named name, or nil.
+func (c *Config) FindSchedule(name string) *ScheduleConfig {
+	for i := range c.Schedules {
+		if c.Schedules[i].Name == name {
+			return &c.Schedules[i]
+		}
+	}
+	return nil
+}
+
+// AddSchedule appends s as a [[schedules]] table to the config file at path
+// (DefaultPath if empty), leaving the rest of the file untouched.
+func AddSchedule(path string, s ScheduleConfig) error {
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := ValidateScheduleConfig(&s); err != nil {
+		return err
+	}
+	unlock, err := util.LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	var existing struct {
+		Schedules []ScheduleConfig `toml:"schedules"`
+	}
+	if _, err := toml.Decode(string(data), &existing); err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+	for _, e := range existing.Schedules {
+		if e.Name == s.Name {
+			return fmt.Errorf("schedule %q already exists", s.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(struct {
+		Schedules []ScheduleConfig `toml:"schedules"`
+	}{[]ScheduleConfig{s}}); err != nil {
+		return fmt.Errorf("encoding schedule: %w", err)
+	}
+	out := strings.TrimRight(string(data), "\n")
+	if out != "" {
+		out += "\n\n"
+	}
+	out += buf.String()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, []byte(out), 0644); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
+// RemoveSchedule deletes the [[schedules]] table named name from the config
+// file at path (DefaultPath if empty). It reports whether one was removed.
+func RemoveSchedule(path, name string) (bool, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	unlock, err := util.LockFile(path)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading config: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	var out []string
+	removed := false
+	for i := 0; i < len(lines); {
+		if strings.TrimSpace(lines[i]) != "[[schedules]]" {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "[") {
+			end++
+		}
+		var block struct {
+			Name string `toml:"name"`
+		}
+		if _, err := toml.Decode(strings.Join(lines[i+1:end], "\n"), &block); err == nil && block.Name == name {
+			removed = true
+			// Drop the blank lines that separated the table from the next.
+			for len(out) > 0 && strings.TrimSpace(out[len(out)-1]) == "" {
+				out = out[:len(out)-1]
+			}
+			if end < len(lines) {
+				out = append(out, "")
+			}
+		} else {
+			out = append(out, lines[i:end]...)
+		}
+		i = end
+	}
+	if !removed {
+		return false, nil
+	}
+	content := strings.TrimRight(strings.Join(out, "\n"), "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := util.AtomicWriteFile(path, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("writing config: %w", err)
+	}
+	return true, nil
+}
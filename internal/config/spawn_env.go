@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spawnEnvCommandTimeout bounds how long a secret command may run.
+const spawnEnvCommandTimeout = 30 * time.Second
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SpawnEnvSource is one set of environment variables injected into agent
+// panes. Values read from Files or printed by Command are treated as
+// secrets; inline Vars are plain unless listed in Secret.
+type SpawnEnvSource struct {
+	Vars    map[string]string `toml:"vars"`    // Inline KEY = "value" pairs
+	Secret  []string          `toml:"secret"`  // Names in Vars that are secrets
+	Files   []string          `toml:"files"`   // Env files (KEY=VALUE lines)
+	Command string            `toml:"command"` // Shell command printing KEY=VALUE lines
+}
+
+func (s SpawnEnvSource) empty() bool {
+	return len(s.Vars) == 0 && len(s.Files) == 0 && strings.TrimSpace(s.Command) == ""
+}
+
+// SpawnEnvConfig injects environment variables into agent panes at spawn.
+// The top-level source applies to every agent; the pane's pool account (if
+// any) and then Agents entries keyed by agent type ("cc") or pane agent name
+// ("cc_2") are layered on top, in that order, so agents using different API
+// accounts can share a session.
+type SpawnEnvConfig struct {
+	SpawnEnvSource
+	Agents map[string]SpawnEnvSource `toml:"agents"`
+
+	// account is the pool account's env, layered between the top-level
+	// source and the Agents entries (see WithAccount).
+	account *spawnEnvLayer
+}
+
+// WithAccount returns a copy of c that also injects the env of the pool
+// account assigned to the pane, plus NTM_ACCOUNT set to its name.
+func (c SpawnEnvConfig) WithAccount(account AccountEntry) SpawnEnvConfig {
+	src := account.Env
+	src.Vars = make(map[string]string, len(account.Env.Vars)+1)
+	for k, v := range account.Env.Vars {
+		src.Vars[k] = v
+	}
+	src.Vars["NTM_ACCOUNT"] = account.Name()
+	c.account = &spawnEnvLayer{"accounts." + account.Name() + ".env", src}
+	return c
+}
+
+// SpawnEnv is the resolved environment for one pane.
+type SpawnEnv struct {
+	Vars   map[string]string
+	Secret map[string]bool
+}
+
+func (e *SpawnEnv) set(name, value string, secret bool) {
+	e.Vars[name] = value
+	e.Secret[name] = secret
+}
+
+// Names returns the variable names in sorted order, optionally limited to
+// secret or plain variables.
+func (e *SpawnEnv) Names(secret bool) []string {
+	var names []string
+	for name := range e.Vars {
+		if e.Secret[name] == secret {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type spawnEnvLayer struct {
+	label  string
+	source SpawnEnvSource
+}
+
+// Resolve builds the environment for an agent pane. Secret commands run
+// with NTM_SESSION, NTM_AGENT_TYPE and NTM_AGENT_NAME set so a single
+// command can serve several panes.
+func (c SpawnEnvConfig) Resolve(ctx context.Context, session, agentType, agentName string) (*SpawnEnv, error) {
+	env := &SpawnEnv{Vars: map[string]string{}, Secret: map[string]bool{}}
+
+	layers := []spawnEnvLayer{{"spawn_env", c.SpawnEnvSource}}
+	if c.account != nil {
+		layers = append(layers, *c.account)
+	}
+	if s, ok := c.Agents[agentType]; ok {
+		layers = append(layers, spawnEnvLayer{"spawn_env.agents." + agentType, s})
+	}
+	if s, ok := c.Agents[agentName]; ok && agentName != agentType {
+		layers = append(layers, spawnEnvLayer{"spawn_env.agents." + agentName, s})
+	}
+
+	for _, layer := range layers {
+		src := layer.source
+		if src.empty() {
+			continue
+		}
+		for _, path := range src.Files {
+			data, err := os.ReadFile(ExpandHome(path))
+			if err != nil {
+				return nil, fmt.Errorf("%s: reading env file: %w", layer.label, err)
+			}
+			vars, err := ParseEnvFile(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", layer.label, path, err)
+			}
+			for k, v := range vars {
+				env.set(k, v, true)
+			}
+		}
+		if cmd := strings.TrimSpace(src.Command); cmd != "" {
+			vars, err := runSpawnEnvCommand(ctx, cmd, session, agentType, agentName)
+			if err != nil {
+				return nil, fmt.Errorf("%s: command: %w", layer.label, err)
+			}
+			for k, v := range vars {
+				env.set(k, v, true)
+			}
+		}
+		secret := make(map[string]bool, len(src.Secret))
+		for _, name := range src.Secret {
+			secret[name] = true
+		}
+		for k, v := range src.Vars {
+			env.set(k, v, secret[k])
+		}
+	}
+	return env, nil
+}
+
+// runSpawnEnvCommand runs a secret command and parses its stdout. Output is
+// never included in errors.
+func runSpawnEnvCommand(ctx context.Context, command, session, agentType, agentName string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, spawnEnvCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"NTM_SESSION="+session,
+		"NTM_AGENT_TYPE="+agentType,
+		"NTM_AGENT_NAME="+agentName,
+	)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", spawnEnvCommandTimeout)
+		}
+		return nil, err
+	}
+	return ParseEnvFile(stdout.Bytes())
+}
+
+// ParseEnvFile parses KEY=VALUE lines as written in .env files. Blank lines,
+// # comments and a leading "export " are ignored; values may be single- or
+// double-quoted. Errors name the line but never its value.
+func ParseEnvFile(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envNameRe.MatchString(name) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", i+1)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value for %s", i+1, name)
+			}
+			value = unquoted
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// ValidateSpawnEnvConfig checks variable names in the spawn_env section.
+func ValidateSpawnEnvConfig(cfg *SpawnEnvConfig) error {
+	check := func(label string, src SpawnEnvSource) error {
+		for name := range src.Vars {
+			if !envNameRe.MatchString(name) {
+				return fmt.Errorf("%svars: invalid variable name %q", label, name)
+			}
+		}
+		for _, name := range src.Secret {
+			if _, ok := src.Vars[name]; !ok {
+				return fmt.Errorf("%ssecret: %q is not defined in vars", label, name)
+			}
+		}
+		for _, path := range src.Files {
+			if strings.TrimSpace(path) == "" {
+				return fmt.Errorf("%sfiles: empty path", label)
+			}
+		}
+		return nil
+	}
+	if err := check("", cfg.SpawnEnvSource); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(cfg.Agents))
+	for key := range cfg.Agents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("agents: empty agent key")
+		}
+		if err := check("agents."+key+".", cfg.Agents[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
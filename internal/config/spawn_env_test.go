@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	vars, err := ParseEnvFile([]byte(`
+# work account
+export ANTHROPIC_API_KEY=sk-work
+SINGLE='a b # not a comment'
+DOUBLE="line\nnext"
+EMPTY=
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"ANTHROPIC_API_KEY": "sk-work",
+		"SINGLE":            "a b # not a comment",
+		"DOUBLE":            "line\nnext",
+		"EMPTY":             "",
+	}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("%s = %q, want %q", k, vars[k], v)
+		}
+	}
+
+	_, err = ParseEnvFile([]byte("OK=1\nnot a pair with secret-value\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") || strings.Contains(err.Error(), "secret-value") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSpawnEnvConfig_ResolveLayers(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, "work.env")
+	if err := os.WriteFile(envFile, []byte("ANTHROPIC_API_KEY=sk-work-account\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg SpawnEnvConfig
+	if _, err := toml.Decode(`
+vars = { LOG_LEVEL = "info", SHARED_TOKEN = "shared-secret-value" }
+secret = ["SHARED_TOKEN"]
+
+[agents.cc]
+vars = { LOG_LEVEL = "debug" }
+
+[agents.cc_2]
+files = ["`+envFile+`"]
+
+[agents.cod]
+command = "echo OPENAI_API_KEY=key-for-$NTM_AGENT_NAME"
+`, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateSpawnEnvConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	cc1, err := cfg.Resolve(ctx, "proj", "cc", "cc_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc1.Vars["LOG_LEVEL"] != "debug" || cc1.Vars["ANTHROPIC_API_KEY"] != "" {
+		t.Errorf("cc_1 vars = %v", cc1.Vars)
+	}
+	if got := strings.Join(cc1.Names(true), ","); got != "SHARED_TOKEN" {
+		t.Errorf("cc_1 secrets = %s", got)
+	}
+
+	cc2, err := cfg.Resolve(ctx, "proj", "cc", "cc_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc2.Vars["ANTHROPIC_API_KEY"] != "sk-work-account" || !cc2.Secret["ANTHROPIC_API_KEY"] {
+		t.Errorf("cc_2 env = %+v", cc2)
+	}
+
+	cod, err := cfg.Resolve(ctx, "proj", "cod", "cod_3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cod.Vars["OPENAI_API_KEY"] != "key-for-cod_3" || !cod.Secret["OPENAI_API_KEY"] {
+		t.Errorf("cod env = %+v", cod)
+	}
+	if got := strings.Join(cod.Names(false), ","); got != "LOG_LEVEL" {
+		t.Errorf("cod plain = %s", got)
+	}
+}
+
+func TestSpawnEnvConfig_Errors(t *testing.T) {
+	cfg := SpawnEnvConfig{Agents: map[string]SpawnEnvSource{
+		"cc": {Command: "echo leaked-output; exit 3"},
+	}}
+	_, err := cfg.Resolve(context.Background(), "s", "cc", "cc_1")
+	if err == nil || !strings.Contains(err.Error(), "spawn_env.agents.cc: command") || strings.Contains(err.Error(), "leaked-output") {
+		t.Errorf("err = %v", err)
+	}
+
+	for _, bad := range []SpawnEnvConfig{
+		{SpawnEnvSource: SpawnEnvSource{Vars: map[string]string{"BAD-NAME": "x"}}},
+		{SpawnEnvSource: SpawnEnvSource{Secret: []string{"MISSING"}}},
+		{Agents: map[string]SpawnEnvSource{"cc": {Files: []string{" "}}}},
+	} {
+		if err := ValidateSpawnEnvConfig(&bad); err == nil {
+			t.Errorf("expected validation error for %+v", bad)
+		}
+	}
+}
@@ -30,6 +30,20 @@ type WebhookFilterConfig struct {
 	Severity []string `yaml:"severity"`
 }
 
+// WebhookDigestConfig batches low-severity events into periodic summaries
+// instead of one delivery per event.
+type WebhookDigestConfig struct {
+	// Interval is how long events are collected per digest (e.g. "15m").
+	// Empty disables digests.
+	Interval string `yaml:"interval"`
+
+	// Severities are batched; others are sent immediately (default info, success).
+	Severities []string `yaml:"severities"`
+
+	// MaxEvents flushes a digest early once this many events are pending.
+	MaxEvents int `yaml:"max_events"`
+}
+
 type WebhookRetryConfig struct {
 	MaxAttempts int    `yaml:"max_attempts"`
 	Backoff     string `yaml:"backoff"`
@@ -42,6 +56,7 @@ type WebhookConfig struct {
 	Formatter string              `yaml:"formatter"`
 	Filter    WebhookFilterConfig `yaml:"filter"`
 	Retry     WebhookRetryConfig  `yaml:"retry"`
+	Digest    WebhookDigestConfig `yaml:"digest"`
 	Timeout   string              `yaml:"timeout"`
 	Secret    string              `yaml:"secret"`
 }
@@ -123,6 +138,24 @@ func (c *WebhookConfig) ValidateConfig() error {
 		return fmt.Errorf("invalid retry.max_attempts %d (must be >= 0)", c.Retry.MaxAttempts)
 	}
 
+	if strings.TrimSpace(c.Digest.Interval) != "" {
+		d, err := time.ParseDuration(strings.TrimSpace(c.Digest.Interval))
+		if err != nil {
+			return fmt.Errorf("invalid digest.interval %q: %w", c.Digest.Interval, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("invalid digest.interval %q (must be > 0)", c.Digest.Interval)
+		}
+	}
+	for _, s := range c.Digest.Severities {
+		if !isValidWebhookSeverity(s) {
+			return fmt.Errorf("invalid digest.severities %q (supported: info, success, warning, error)", strings.TrimSpace(s))
+		}
+	}
+	if c.Digest.MaxEvents < 0 {
+		return fmt.Errorf("invalid digest.max_events %d (must be >= 0)", c.Digest.MaxEvents)
+	}
+
 	return nil
 }
 
@@ -353,14 +386,20 @@ func isRecognizedWebhookEvent(ev string) bool {
 		"agent.idle",
 		"agent.busy",
 		"agent.rate_limit",
+		"agent.resumed",
 		"agent.completed",
+		"agent.progress",
 		"rotation.needed",
+		"context.reset",
 		"session.created",
 		"session.killed",
 		"session.ended",
 		"bead.assigned",
 		"bead.completed",
 		"bead.failed",
+		"task.candidate_complete",
+		"prompt.undelivered",
+		"storage.low_disk",
 		"health.degraded":
 		return true
 	default:
@@ -173,6 +173,38 @@ webhooks:
 	}
 }
 
+func TestParseWebhookConfig_Digest(t *testing.T) {
+	content := `
+webhooks:
+  - name: digest
+    url: https://example.com/hook
+    digest:
+      interval: 15m
+      severities: [info, success]
+      max_events: 50
+`
+	cfgs, err := ParseWebhookConfig([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseWebhookConfig failed: %v", err)
+	}
+	if d := cfgs[0].Digest; d.Interval != "15m" || len(d.Severities) != 2 || d.MaxEvents != 50 {
+		t.Fatalf("unexpected digest: %#v", d)
+	}
+
+	for _, tc := range []struct{ digest, want string }{
+		{"{interval: soon}", "invalid digest.interval"},
+		{"{interval: 0s}", "must be > 0"},
+		{"{interval: 1m, severities: [loud]}", "invalid digest.severities"},
+		{"{interval: 1m, max_events: -1}", "invalid digest.max_events"},
+	} {
+		content := "webhooks:\n  - name: bad\n    url: https://example.com/hook\n    digest: " + tc.digest + "\n"
+		_, err := ParseWebhookConfig([]byte(content))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("digest %q: err = %v, want %q", tc.digest, err, tc.want)
+		}
+	}
+}
+
 // TestIsValidWebhookAgentType tests all branches of the agent type validator.
 func TestIsValidWebhookAgentType(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,43 @@
+package config
+
+import "fmt"
+
+// DefaultWrapUpPrompt asks agents to finish up before the session ends.
+const DefaultWrapUpPrompt = "This session ends in {{minutes}} minutes. Do not start new work. " +
+	"Finish or checkpoint what you are doing, commit anything worth keeping, " +
+	"and reply with a short summary of what you did and what is left."
+
+// WindDownConfig configures [wind_down]: a maximum session runtime after
+// which ntm daemon winds the session down.
+type WindDownConfig struct {
+	MaxSessionMinutes int    `toml:"max_session_minutes"` // 0 = no limit (templates may still set maxRuntime)
+	WrapUpMinutes     int    `toml:"wrap_up_minutes"`     // Lead time: stop assigning and ask agents to wrap up
+	WrapUpPrompt      string `toml:"wrap_up_prompt"`      // Sent to agent panes; {{minutes}} is the time left
+	Verify            bool   `toml:"verify"`              // Run the [assign.verify] commands at the deadline
+	Kill              bool   `toml:"kill"`                // Kill the session after checkpointing
+}
+
+// DefaultWindDownConfig returns wind-down defaults: no limit, a 30 minute
+// wrap-up window, final verification on and sessions left running.
+func DefaultWindDownConfig() WindDownConfig {
+	return WindDownConfig{
+		WrapUpMinutes: 30,
+		WrapUpPrompt:  DefaultWrapUpPrompt,
+		Verify:        true,
+	}
+}
+
+// ValidateWindDownConfig validates the wind-down configuration.
+func ValidateWindDownConfig(cfg *WindDownConfig) error {
+	if cfg.MaxSessionMinutes < 0 {
+		return fmt.Errorf("max_session_minutes must be >= 0, got %d", cfg.MaxSessionMinutes)
+	}
+	if cfg.WrapUpMinutes < 0 {
+		return fmt.Errorf("wrap_up_minutes must be >= 0, got %d", cfg.WrapUpMinutes)
+	}
+	if cfg.MaxSessionMinutes > 0 && cfg.WrapUpMinutes >= cfg.MaxSessionMinutes {
+		return fmt.Errorf("wrap_up_minutes (%d) must be less than max_session_minutes (%d)",
+			cfg.WrapUpMinutes, cfg.MaxSessionMinutes)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Package context provides context window monitoring for AI agent orchestration.
+// assistant.go implements the compaction assistant that resets an agent's
+// context in place before quality collapses near the limit.
+package context
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+const (
+	contextSummariesDir = "context_summaries"
+
+	// summaryCaptureLines is how much scrollback is searched for the
+	// agent's summary.
+	summaryCaptureLines = 300
+)
+
+// CompactionAssistantConfig configures a CompactionAssistant.
+type CompactionAssistantConfig struct {
+	SummaryWait      time.Duration // Time the agent gets to write its summary (default: 45s)
+	RestartWait      time.Duration // Time a compacted or restarted agent gets before the summary is sent (default: 10s)
+	SummaryMaxTokens int           // Maximum tokens kept from the summary (default: 2000)
+	SummaryDir       string        // Where summaries are saved (default: ~/.ntm/context_summaries)
+	History          *RotationHistoryStore
+}
+
+// ContextResetRequest identifies the agent whose context should be reset.
+type ContextResetRequest struct {
+	Session      string
+	AgentID      string // Pane title, e.g. "myproject__cc_1"
+	PaneID       string
+	AgentType    string // cc, cod, gmi, ...
+	UsagePercent float64
+
+	// Restart relaunches the agent in its pane. It is used for agents
+	// without a builtin /compact; without it such agents only get their
+	// summary saved.
+	Restart func() error
+}
+
+// ContextReset is the outcome of a compaction assistant run.
+type ContextReset struct {
+	Session       string           `json:"session"`
+	AgentID       string           `json:"agent_id"`
+	PaneID        string           `json:"pane_id"`
+	AgentType     string           `json:"agent_type"`
+	Method        CompactionMethod `json:"method"`
+	UsageBefore   float64          `json:"usage_before"`
+	SummaryPath   string           `json:"summary_path,omitempty"`
+	SummaryTokens int              `json:"summary_tokens"`
+	Fallback      bool             `json:"fallback,omitempty"` // Summary built from scrollback, not the agent's answer
+	Success       bool             `json:"success"`
+	Error         string           `json:"error,omitempty"`
+	Duration      time.Duration    `json:"duration"`
+	Timestamp     time.Time        `json:"timestamp"`
+}
+
+// CompactionAssistant resets an agent's context near its limit: it asks the
+// agent to summarize its working state, saves the summary, triggers the
+// provider's /compact (or restarts the agent and hands it the summary) and
+// records the reset in the rotation history.
+type CompactionAssistant struct {
+	config  CompactionAssistantConfig
+	summary *SummaryGenerator
+
+	sender  paneInputSender
+	capture func(paneID string, lines int) (string, error)
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewCompactionAssistant creates a CompactionAssistant that drives tmux panes.
+func NewCompactionAssistant(cfg CompactionAssistantConfig) *CompactionAssistant {
+	if cfg.SummaryWait <= 0 {
+		cfg.SummaryWait = 45 * time.Second
+	}
+	if cfg.RestartWait <= 0 {
+		cfg.RestartWait = 10 * time.Second
+	}
+	if cfg.SummaryDir == "" {
+		cfg.SummaryDir = defaultContextSummariesDir()
+	}
+	if cfg.History == nil {
+		cfg.History = DefaultRotationHistoryStore
+	}
+	return &CompactionAssistant{
+		config:  cfg,
+		summary: NewSummaryGenerator(SummaryGeneratorConfig{MaxTokens: cfg.SummaryMaxTokens}),
+		sender:  tmuxPaneInputSender{},
+		capture: tmux.CapturePaneOutput,
+		sleep:   sleepContext,
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// defaultContextSummariesDir returns ~/.ntm/context_summaries.
+func defaultContextSummariesDir() string {
+	ntmDir, err := util.NTMDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "ntm", contextSummariesDir)
+	}
+	return filepath.Join(ntmDir, contextSummariesDir)
+}
+
+// Reset runs the compaction flow for one agent. The reset is recorded in
+// the rotation history whether or not it succeeds; the returned error
+// mirrors ContextReset.Error.
+func (a *CompactionAssistant) Reset(ctx context.Context, req ContextResetRequest) (*ContextReset, error) {
+	reset := &ContextReset{
+		Session:     req.Session,
+		AgentID:     req.AgentID,
+		PaneID:      req.PaneID,
+		AgentType:   req.AgentType,
+		UsageBefore: req.UsagePercent,
+		Timestamp:   time.Now(),
+	}
+	err := a.run(ctx, req, reset)
+	if err != nil {
+		reset.Error = err.Error()
+	}
+	reset.Success = err == nil
+	reset.Duration = time.Since(reset.Timestamp)
+	a.record(reset)
+	return reset, err
+}
+
+func (a *CompactionAssistant) run(ctx context.Context, req ContextResetRequest, reset *ContextReset) error {
+	caps := GetAgentCapabilities(req.AgentType)
+	switch {
+	case caps.SupportsBuiltinCompact && caps.BuiltinCompactCommand != "":
+		reset.Method = CompactionBuiltin
+	case req.Restart != nil:
+		reset.Method = CompactionRestart
+	default:
+		reset.Method = CompactionSummarize
+	}
+
+	// 1. Ask the agent for its working state.
+	prompt := a.summary.GeneratePrompt()
+	if err := a.sender.SendBuffer(req.PaneID, prompt, true); err != nil {
+		return fmt.Errorf("requesting summary: %w", err)
+	}
+	if err := a.sleep(ctx, a.config.SummaryWait); err != nil {
+		return err
+	}
+
+	output, err := a.capture(req.PaneID, summaryCaptureLines)
+	if err != nil {
+		return fmt.Errorf("capturing summary: %w", err)
+	}
+	agentType := agentTypeLong(req.AgentType)
+	summary := a.summary.ParseAgentResponse(req.AgentID, agentType, req.Session, responseAfterPrompt(output, prompt))
+	if summary.CurrentTask == "" && summary.Progress == "" {
+		summary = a.summary.GenerateFallbackSummary(req.AgentID, agentType, req.Session, []string{output})
+		reset.Fallback = true
+	}
+	reset.SummaryTokens = summary.TokenEstimate
+
+	// 2. Save it where the agent (or a human) can re-read it.
+	path, err := a.saveSummary(req, summary)
+	if err != nil {
+		return err
+	}
+	reset.SummaryPath = path
+
+	// 3. Compact, or restart with the summary as the new context.
+	switch reset.Method {
+	case CompactionBuiltin:
+		if err := a.sender.SendKeys(req.PaneID, caps.BuiltinCompactCommand, true); err != nil {
+			return fmt.Errorf("sending %s: %w", caps.BuiltinCompactCommand, err)
+		}
+		if err := a.sleep(ctx, a.config.RestartWait); err != nil {
+			return err
+		}
+		msg := fmt.Sprintf("Your context was compacted. The working-state summary you just wrote is saved at %s; re-read it if anything is unclear, then continue your task.", path)
+		if err := a.sender.SendBuffer(req.PaneID, msg, true); err != nil {
+			return fmt.Errorf("sending summary pointer: %w", err)
+		}
+	case CompactionRestart:
+		if err := req.Restart(); err != nil {
+			return fmt.Errorf("restarting agent: %w", err)
+		}
+		if err := a.sleep(ctx, a.config.RestartWait); err != nil {
+			return err
+		}
+		msg := summary.FormatForNewAgent() + fmt.Sprintf("\nThe full summary is saved at %s.\n", path)
+		if err := a.sender.SendBuffer(req.PaneID, msg, true); err != nil {
+			return fmt.Errorf("sending summary to restarted agent: %w", err)
+		}
+	default:
+		return fmt.Errorf("%s has no builtin compaction and cannot be restarted; summary saved to %s", agentType, path)
+	}
+	return nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// saveSummary writes the summary to <dir>/<session>/<agent>-<time>.md.
+func (a *CompactionAssistant) saveSummary(req ContextResetRequest, summary *HandoffSummary) (string, error) {
+	dir := filepath.Join(a.config.SummaryDir, unsafeFileChars.ReplaceAllString(req.Session, "_"))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("creating summary directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.md",
+		unsafeFileChars.ReplaceAllString(req.AgentID, "_"),
+		summary.GeneratedAt.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Context reset: %s\n\n", req.AgentID)
+	fmt.Fprintf(&sb, "Session %s, saved %s at %.0f%% context usage.\n\n",
+		req.Session, summary.GeneratedAt.Format(time.RFC3339), req.UsagePercent)
+	sb.WriteString(strings.TrimSpace(summary.RawSummary))
+	sb.WriteString("\n")
+
+	if err := util.AtomicWriteFile(path, []byte(sb.String()), 0o600); err != nil {
+		return "", fmt.Errorf("saving summary: %w", err)
+	}
+	return path, nil
+}
+
+// record appends the reset to the rotation history (best-effort).
+func (a *CompactionAssistant) record(reset *ContextReset) {
+	record := &RotationRecord{
+		ID:               newRecordID(),
+		Timestamp:        reset.Timestamp,
+		SessionName:      reset.Session,
+		AgentID:          reset.AgentID,
+		AgentType:        agentTypeLong(reset.AgentType),
+		ContextBefore:    reset.UsageBefore,
+		EstimationMethod: "agent_output",
+		CompactionTried:  true,
+		CompactionResult: "success",
+		CompactionMethod: string(reset.Method),
+		Method:           RotationContextReset,
+		Success:          reset.Success,
+		SummaryTokens:    reset.SummaryTokens,
+		SummaryPath:      reset.SummaryPath,
+		DurationMs:       reset.Duration.Milliseconds(),
+	}
+	if !reset.Success {
+		record.CompactionResult = "failed"
+		record.FailureReason = reset.Error
+	}
+	_ = a.config.History.Append(record)
+}
+
+// responseAfterPrompt returns the part of captured output that follows the
+// echoed prompt, so the prompt's own section headers are not parsed as the
+// agent's answer. The whole output is returned if the prompt is not found.
+func responseAfterPrompt(output, prompt string) string {
+	lines := strings.Split(strings.TrimSpace(prompt), "\n")
+	last := strings.TrimSpace(lines[len(lines)-1])
+	if last == "" {
+		return output
+	}
+	if i := strings.LastIndex(output, last); i >= 0 {
+		return output[i+len(last):]
+	}
+	return output
+}
@@ -0,0 +1,145 @@
+package context
+
+import (
+	stdcontext "context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingSender struct {
+	keys    []string
+	buffers []string
+}
+
+func (s *recordingSender) SendKeys(paneID, text string, enter bool) error {
+	s.keys = append(s.keys, text)
+	return nil
+}
+
+func (s *recordingSender) SendBuffer(paneID, text string, enter bool) error {
+	s.buffers = append(s.buffers, text)
+	return nil
+}
+
+const agentSummaryOutput = `
+## CURRENT TASK
+Adding retry support to the uploader
+
+## PROGRESS
+- Backoff implemented
+- Tests still failing on timeouts
+
+## ACTIVE FILES
+- internal/upload/retry.go
+`
+
+func newTestAssistant(t *testing.T, output string) (*CompactionAssistant, *recordingSender, *RotationHistoryStore) {
+	t.Helper()
+	dir := t.TempDir()
+	history := NewRotationHistoryStoreWithPath(filepath.Join(dir, "history.jsonl"))
+	a := NewCompactionAssistant(CompactionAssistantConfig{
+		SummaryDir: filepath.Join(dir, "summaries"),
+		History:    history,
+	})
+	sender := &recordingSender{}
+	a.sender = sender
+	a.sleep = func(stdcontext.Context, time.Duration) error { return nil }
+	a.capture = func(string, int) (string, error) {
+		// The echoed prompt precedes the agent's answer in the scrollback.
+		return "$ " + SummaryPromptTemplate + "\n" + output, nil
+	}
+	return a, sender, history
+}
+
+func TestCompactionAssistant_BuiltinCompact(t *testing.T) {
+	a, sender, history := newTestAssistant(t, agentSummaryOutput)
+
+	reset, err := a.Reset(stdcontext.Background(), ContextResetRequest{
+		Session: "proj", AgentID: "proj__cc_1", PaneID: "%1", AgentType: "cc", UsagePercent: 86,
+	})
+	if err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if reset.Method != CompactionBuiltin || !reset.Success || reset.Fallback {
+		t.Errorf("reset = %+v", reset)
+	}
+	if len(sender.keys) != 1 || sender.keys[0] != "/compact" {
+		t.Errorf("keys = %q, want /compact", sender.keys)
+	}
+	if len(sender.buffers) != 2 || !strings.Contains(sender.buffers[1], reset.SummaryPath) {
+		t.Errorf("buffers = %q", sender.buffers)
+	}
+
+	data, err := os.ReadFile(reset.SummaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Adding retry support") || strings.Contains(string(data), "What task are you currently working on") {
+		t.Errorf("summary file:\n%s", data)
+	}
+
+	records, err := history.ReadAll()
+	if err != nil || len(records) != 1 {
+		t.Fatalf("history = %v, %v", records, err)
+	}
+	rec := records[0]
+	if rec.Method != RotationContextReset || rec.CompactionMethod != string(CompactionBuiltin) || rec.SummaryPath != reset.SummaryPath || !rec.Success {
+		t.Errorf("record = %+v", rec)
+	}
+}
+
+func TestCompactionAssistant_Restart(t *testing.T) {
+	a, sender, _ := newTestAssistant(t, agentSummaryOutput)
+
+	restarted := false
+	reset, err := a.Reset(stdcontext.Background(), ContextResetRequest{
+		Session: "proj", AgentID: "proj__cod_1", PaneID: "%2", AgentType: "cod",
+		Restart: func() error { restarted = true; return nil },
+	})
+	if err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if !restarted || reset.Method != CompactionRestart {
+		t.Errorf("restarted=%v reset=%+v", restarted, reset)
+	}
+	if len(sender.keys) != 0 {
+		t.Errorf("unexpected keys %q", sender.keys)
+	}
+	last := sender.buffers[len(sender.buffers)-1]
+	if !strings.Contains(last, "Adding retry support") || !strings.Contains(last, reset.SummaryPath) {
+		t.Errorf("handoff message = %q", last)
+	}
+}
+
+func TestCompactionAssistant_Failures(t *testing.T) {
+	a, _, history := newTestAssistant(t, "no sections here, just noise")
+
+	// No /compact and no restart: the summary is kept, the reset fails.
+	reset, err := a.Reset(stdcontext.Background(), ContextResetRequest{Session: "proj", AgentID: "proj__gmi_1", PaneID: "%3", AgentType: "gmi"})
+	if err == nil || reset.Success {
+		t.Fatalf("expected failure, got %+v", reset)
+	}
+	if !reset.Fallback {
+		t.Error("expected fallback summary")
+	}
+	if _, statErr := os.Stat(reset.SummaryPath); statErr != nil {
+		t.Errorf("summary not kept: %v", statErr)
+	}
+
+	_, err = a.Reset(stdcontext.Background(), ContextResetRequest{
+		Session: "proj", AgentID: "proj__cod_1", PaneID: "%2", AgentType: "cod",
+		Restart: func() error { return errors.New("respawn failed") },
+	})
+	if err == nil || !strings.Contains(err.Error(), "respawn failed") {
+		t.Errorf("err = %v", err)
+	}
+
+	records, _ := history.ReadAll()
+	if len(records) != 2 || records[0].Success || records[0].FailureReason == "" {
+		t.Errorf("records = %+v", records)
+	}
+}
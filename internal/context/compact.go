@@ -18,6 +18,8 @@ const (
 	CompactionSummarize CompactionMethod = "summarize"
 	// CompactionClearHistory clears non-essential history
 	CompactionClearHistory CompactionMethod = "clear_history"
+	// CompactionRestart relaunches the agent with its saved summary
+	CompactionRestart CompactionMethod = "restart"
 	// CompactionFailed indicates compaction was attempted but failed
 	CompactionFailed CompactionMethod = "failed"
 )
@@ -51,7 +51,8 @@ type RotationRecord struct {
 	Success       bool           `json:"success"`
 	FailureReason string         `json:"failure_reason,omitempty"`
 	SummaryTokens int            `json:"summary_tokens"`
-	ContextAfter  float64        `json:"context_after_percent"` // Usage % after (should be ~0)
+	SummaryPath   string         `json:"summary_path,omitempty"` // Saved summary (context resets)
+	ContextAfter  float64        `json:"context_after_percent"`  // Usage % after (should be ~0)
 
 	// Duration
 	DurationMs int64 `json:"duration_ms"`
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
 )
 
 // modelDateSuffixRegex matches date suffixes like -20251101 in model names
@@ -103,6 +105,7 @@ type EstimationMethod string
 
 const (
 	MethodRobotMode        EstimationMethod = "robot_mode"        // Direct report from agent
+	MethodProviderReport   EstimationMethod = "provider_report"   // Parsed from CLI status lines
 	MethodMessageCount     EstimationMethod = "message_count"     // Estimated from message count
 	MethodCumulativeTokens EstimationMethod = "cumulative_tokens" // Sum of input+output tokens
 	MethodDurationActivity EstimationMethod = "duration_activity" // Time + activity heuristic
@@ -488,6 +491,45 @@ func (m *ContextMonitor) UpdateFromRobotMode(agentID, output string) {
 	}
 }
 
+// UpdateFromUsage updates the context estimate from a provider-reported usage
+// record parsed from the agent's status lines (see tokens.ParseUsage).
+func (m *ContextMonitor) UpdateFromUsage(agentID string, usage *tokens.TokenUsage) {
+	if !usage.HasContext() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, exists := m.states[agentID]
+	if !exists {
+		return
+	}
+
+	limit := usage.ContextLimit
+	if limit <= 0 {
+		limit = GetContextLimit(state.Model)
+	}
+	used := usage.ContextUsed
+	percent := usage.ContextPercent
+	if percent < 0 {
+		percent = float64(used) / float64(limit) * 100
+	} else if used <= 0 {
+		used = int64(percent / 100 * float64(limit))
+	}
+
+	state.Estimate = &ContextEstimate{
+		TokensUsed:   used,
+		ContextLimit: limit,
+		UsagePercent: percent,
+		Confidence:   0.90,
+		Method:       MethodProviderReport,
+		Model:        state.Model,
+		UpdatedAt:    usage.CapturedAt,
+	}
+	state.LastActivity = time.Now()
+}
+
 // GetEstimate computes the current context estimate for an agent.
 // Uses the highest-confidence available strategy.
 func (m *ContextMonitor) GetEstimate(agentID string) *ContextEstimate {
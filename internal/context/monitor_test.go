@@ -3,6 +3,8 @@ package context
 import (
 	"testing"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
 )
 
 func TestGetContextLimit(t *testing.T) {
@@ -1026,3 +1028,31 @@ func TestMultipleAgentTracking(t *testing.T) {
 			a.id, a.model, estimate.ContextLimit, estimate.UsagePercent)
 	}
 }
+
+func TestContextMonitor_UpdateFromUsage(t *testing.T) {
+	t.Parallel()
+
+	monitor := NewContextMonitor(DefaultMonitorConfig())
+	monitor.RegisterAgent("agent-1", "%1", "claude-sonnet-4")
+
+	usage := tokens.ParseUsage("cc", "Context left until auto-compact: 25%")
+	monitor.UpdateFromUsage("agent-1", usage)
+
+	estimate := monitor.GetEstimate("agent-1")
+	if estimate == nil {
+		t.Fatal("expected estimate after usage update")
+	}
+	if estimate.Method != MethodProviderReport {
+		t.Errorf("Method = %s, want %s", estimate.Method, MethodProviderReport)
+	}
+	if estimate.UsagePercent != 75 {
+		t.Errorf("UsagePercent = %v, want 75", estimate.UsagePercent)
+	}
+	if estimate.TokensUsed != 150000 {
+		t.Errorf("TokensUsed = %d, want 150000", estimate.TokensUsed)
+	}
+
+	// Unknown agents and records without context are ignored.
+	monitor.UpdateFromUsage("missing", usage)
+	monitor.UpdateFromUsage("agent-1", nil)
+}
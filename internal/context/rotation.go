@@ -21,6 +21,9 @@ const (
 	RotationManual RotationMethod = "manual"
 	// RotationCompactionFailed indicates rotation after compaction failed.
 	RotationCompactionFailed RotationMethod = "compaction_failed"
+	// RotationContextReset indicates the compaction assistant reset the
+	// agent's context in place (see CompactionAssistant).
+	RotationContextReset RotationMethod = "context_reset"
 )
 
 // RotationState tracks the current state of a rotation.
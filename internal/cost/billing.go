@@ -0,0 +1,511 @@
+package cost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Billing API defaults.
+const (
+	AnthropicBillingURL = "https://api.anthropic.com"
+	OpenAIBillingURL    = "https://api.openai.com"
+
+	anthropicAPIVersion = "2023-06-01"
+	billingHTTPTimeout  = 30 * time.Second
+	billingMaxPages     = 10
+	billingErrorBody    = 4096
+)
+
+// BillingSource reads an organization's actual spend from a provider's
+// billing API.
+type BillingSource interface {
+	// Provider returns the provider name ("anthropic", "openai").
+	Provider() string
+	// SpendSince returns the USD spend from since until now.
+	SpendSince(ctx context.Context, since time.Time) (float64, error)
+}
+
+// NewBillingSource returns the billing poller for provider, authenticated
+// with an org admin key. An empty baseURL uses the provider's public API.
+func NewBillingSource(provider, adminKey, baseURL string) (BillingSource, error) {
+	if adminKey == "" {
+		return nil, fmt.Errorf("%s billing: no admin key", provider)
+	}
+	client := &http.Client{Timeout: billingHTTPTimeout}
+	switch provider {
+	case "anthropic":
+		if baseURL == "" {
+			baseURL = AnthropicBillingURL
+		}
+		return &anthropicBilling{billingClient{provider, adminKey, strings.TrimRight(baseURL, "/"), client}}, nil
+	case "openai":
+		if baseURL == "" {
+			baseURL = OpenAIBillingURL
+		}
+		return &openAIBilling{billingClient{provider, adminKey, strings.TrimRight(baseURL, "/"), client}}, nil
+	}
+	return nil, fmt.Errorf("no billing API for provider %q", provider)
+}
+
+// billingClient is the HTTP plumbing shared by the pollers.
+type billingClient struct {
+	provider string
+	key      string
+	baseURL  string
+	client   *http.Client
+}
+
+func (c *billingClient) Provider() string { return c.provider }
+
+// get fetches path with query and decodes the JSON response into out.
+// Errors never carry the admin key.
+func (c *billingClient) get(ctx context.Context, path string, query url.Values, header http.Header, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("%s billing: %w", c.provider, err)
+	}
+	req.Header = header
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s billing: %s", c.provider, c.scrub(err.Error()))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, billingErrorBody))
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg := resp.Status
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			msg += ": " + apiErr.Error.Message
+		}
+		return fmt.Errorf("%s billing: %s", c.provider, c.scrub(msg))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s billing: decode response: %w", c.provider, err)
+	}
+	return nil
+}
+
+// scrub removes the admin key from s.
+func (c *billingClient) scrub(s string) string {
+	return strings.ReplaceAll(s, c.key, "[REDACTED]")
+}
+
+// anthropicBilling polls the Admin API cost report, whose amounts are
+// decimal strings in cents.
+type anthropicBilling struct{ billingClient }
+
+func (a *anthropicBilling) SpendSince(ctx context.Context, since time.Time) (float64, error) {
+	header := http.Header{}
+	header.Set("x-api-key", a.key)
+	header.Set("anthropic-version", anthropicAPIVersion)
+
+	query := url.Values{}
+	query.Set("starting_at", since.UTC().Format(time.RFC3339))
+	query.Set("limit", "31")
+
+	var cents float64
+	for page := 0; page < billingMaxPages; page++ {
+		var resp struct {
+			Data []struct {
+				Results []struct {
+					Amount string `json:"amount"`
+				} `json:"results"`
+			} `json:"data"`
+			HasMore  bool   `json:"has_more"`
+			NextPage string `json:"next_page"`
+		}
+		if err := a.get(ctx, "/v1/organizations/cost_report", query, header, &resp); err != nil {
+			return 0, err
+		}
+		for _, bucket := range resp.Data {
+			for _, r := range bucket.Results {
+				v, err := strconv.ParseFloat(r.Amount, 64)
+				if err != nil {
+					return 0, fmt.Errorf("anthropic billing: amount %q: %w", r.Amount, err)
+				}
+				cents += v
+			}
+		}
+		if !resp.HasMore || resp.NextPage == "" {
+			break
+		}
+		query.Set("page", resp.NextPage)
+	}
+	return cents / 100, nil
+}
+
+// openAIBilling polls the organization costs endpoint, whose amounts are
+// in dollars.
+type openAIBilling struct{ billingClient }
+
+func (o *openAIBilling) SpendSince(ctx context.Context, since time.Time) (float64, error) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+o.key)
+
+	query := url.Values{}
+	query.Set("start_time", strconv.FormatInt(since.Unix(), 10))
+	query.Set("bucket_width", "1d")
+	query.Set("limit", "31")
+
+	var usd float64
+	for page := 0; page < billingMaxPages; page++ {
+		var resp struct {
+			Data []struct {
+				Results []struct {
+					Amount struct {
+						Value float64 `json:"value"`
+					} `json:"amount"`
+				} `json:"results"`
+			} `json:"data"`
+			HasMore  bool   `json:"has_more"`
+			NextPage string `json:"next_page"`
+		}
+		if err := o.get(ctx, "/v1/organization/costs", query, header, &resp); err != nil {
+			return 0, err
+		}
+		for _, bucket := range resp.Data {
+			for _, r := range bucket.Results {
+				usd += r.Amount.Value
+			}
+		}
+		if !resp.HasMore || resp.NextPage == "" {
+			break
+		}
+		query.Set("page", resp.NextPage)
+	}
+	return usd, nil
+}
+
+// SpendLevel describes where month-to-date spend sits relative to a cap.
+type SpendLevel string
+
+const (
+	SpendOK        SpendLevel = "ok"
+	SpendThrottled SpendLevel = "throttled"
+	SpendPaused    SpendLevel = "paused"
+)
+
+// SpendCap is an org-level monthly cap with the fractions of it at which
+// pacing slows and new spawns pause.
+type SpendCap struct {
+	MonthlyUSD     float64
+	ThrottleAt     float64
+	PauseAt        float64
+	MaxDelayFactor float64
+}
+
+// Evaluate returns the level for spendUSD and the delay factor to apply.
+// The factor grows linearly from 1 at ThrottleAt to MaxDelayFactor at
+// PauseAt.
+func (c SpendCap) Evaluate(spendUSD float64) (SpendLevel, float64) {
+	if c.MonthlyUSD <= 0 {
+		return SpendOK, 1
+	}
+	fraction := spendUSD / c.MonthlyUSD
+	switch {
+	case fraction >= c.PauseAt:
+		return SpendPaused, c.MaxDelayFactor
+	case fraction < c.ThrottleAt:
+		return SpendOK, 1
+	}
+	factor := 1 + (fraction-c.ThrottleAt)/(c.PauseAt-c.ThrottleAt)*(c.MaxDelayFactor-1)
+	return SpendThrottled, math.Round(factor*100) / 100
+}
+
+// ProviderSpend is the reconciled month-to-date spend of one provider.
+type ProviderSpend struct {
+	Provider     string     `json:"provider"`
+	Month        string     `json:"month"` // UTC month, e.g. "2026-10"
+	ActualUSD    float64    `json:"actual_usd"`
+	EstimatedUSD float64    `json:"estimated_usd"` // ntm's token-based estimate
+	DriftUSD     float64    `json:"drift_usd"`     // actual - estimated
+	CapUSD       float64    `json:"cap_usd"`
+	CapFraction  float64    `json:"cap_fraction"`
+	Level        SpendLevel `json:"level"`
+	DelayFactor  float64    `json:"delay_factor"`
+	KeyEnv       string     `json:"key_env"` // Name of the admin key variable, never the key
+	PolledAt     time.Time  `json:"polled_at"`
+	Error        string     `json:"error,omitempty"`
+	ErrorAt      *time.Time `json:"error_at,omitempty"`
+}
+
+// MonthStart returns the start of t's UTC month.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// BillingStatePath returns where polled spend is kept. Spend caps are
+// org-wide, so the state is shared by every project.
+func BillingStatePath() string {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return filepath.Join(os.TempDir(), "ntm", "billing.json")
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "ntm", "billing.json")
+}
+
+// BillingState holds the last polled spend per provider.
+type BillingState struct {
+	mu        sync.Mutex
+	providers map[string]*ProviderSpend
+	// changed marks the providers polled since the last load or save; Save
+	// writes only these over the file's contents.
+	changed map[string]bool
+}
+
+// NewBillingState creates an empty BillingState.
+func NewBillingState() *BillingState {
+	return &BillingState{providers: make(map[string]*ProviderSpend)}
+}
+
+// Load reads billing state from path. A missing file is not an error.
+func (s *BillingState) Load(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	providers, err := readBillingFile(path)
+	if err != nil {
+		return err
+	}
+	s.providers = providers
+	s.changed = nil
+	return nil
+}
+
+// Save writes billing state to path. Other ntm processes poll and save to
+// the same file, so under the file lock it re-reads the file and replaces
+// the providers polled here since the last load or save, keeping the file's
+// entry where another process polled that provider later. It then writes
+// the result and adopts it.
+func (s *BillingState) Save(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create billing state dir: %w", err)
+	}
+	return util.WithFileLock(path, func() error {
+		providers, err := readBillingFile(path)
+		if err != nil {
+			return err
+		}
+		for name := range s.changed {
+			mine, ok := s.providers[name]
+			if !ok {
+				continue
+			}
+			if disk, ok := providers[name]; ok && disk.PolledAt.After(mine.PolledAt) {
+				continue
+			}
+			providers[name] = mine
+		}
+
+		data, err := json.MarshalIndent(providers, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal billing state: %w", err)
+		}
+		if err := util.AtomicWriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("write billing state: %w", err)
+		}
+		s.providers = providers
+		s.changed = nil
+		return nil
+	})
+}
+
+// readBillingFile reads billing state. A missing file gives no providers.
+func readBillingFile(path string) (map[string]*ProviderSpend, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]*ProviderSpend), nil
+		}
+		return nil, fmt.Errorf("read billing state: %w", err)
+	}
+	var providers map[string]*ProviderSpend
+	if err := json.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("parse billing state: %w", err)
+	}
+	if providers == nil {
+		providers = make(map[string]*ProviderSpend)
+	}
+	return providers, nil
+}
+
+// markChanged records that provider's spend differs from the file's. The
+// caller must hold s.mu.
+func (s *BillingState) markChanged(provider string) {
+	if s.changed == nil {
+		s.changed = make(map[string]bool)
+	}
+	s.changed[provider] = true
+}
+
+// Get returns a copy of the provider's spend, or nil.
+func (s *BillingState) Get(provider string) *ProviderSpend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil
+	}
+	cp := *p
+	return &cp
+}
+
+// Providers returns copies of all provider spends, sorted by provider.
+func (s *BillingState) Providers() []ProviderSpend {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]ProviderSpend, 0, len(s.providers))
+	for _, p := range s.providers {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Provider < out[j].Provider })
+	return out
+}
+
+// Record stores a successful poll and returns the new spend along with the
+// level it replaced (SpendOK when the provider is new or the month rolled
+// over).
+func (s *BillingState) Record(provider, keyEnv string, actual, estimated float64, limit SpendCap, now time.Time) (ProviderSpend, SpendLevel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	month := MonthStart(now).Format("2006-01")
+	prev := SpendOK
+	if old, ok := s.providers[provider]; ok && old.Month == month {
+		prev = old.Level
+	}
+	level, factor := limit.Evaluate(actual)
+	p := &ProviderSpend{
+		Provider:     provider,
+		Month:        month,
+		ActualUSD:    actual,
+		EstimatedUSD: estimated,
+		DriftUSD:     actual - estimated,
+		CapUSD:       limit.MonthlyUSD,
+		Level:        level,
+		DelayFactor:  factor,
+		KeyEnv:       keyEnv,
+		PolledAt:     now.UTC(),
+	}
+	if limit.MonthlyUSD > 0 {
+		p.CapFraction = math.Round(actual/limit.MonthlyUSD*1000) / 1000
+	}
+	s.providers[provider] = p
+	s.markChanged(provider)
+	return *p, prev
+}
+
+// RecordError notes a failed poll, keeping the last good spend of the
+// current month so throttling stays in force while the API is unreachable.
+func (s *BillingState) RecordError(provider, keyEnv string, pollErr error, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	month := MonthStart(now).Format("2006-01")
+	p, ok := s.providers[provider]
+	if !ok || p.Month != month {
+		p = &ProviderSpend{Provider: provider, Month: month, Level: SpendOK, DelayFactor: 1}
+		s.providers[provider] = p
+	}
+	at := now.UTC()
+	p.KeyEnv = keyEnv
+	p.Error = pollErr.Error()
+	p.ErrorAt = &at
+	s.markChanged(provider)
+}
+
+// ScheduleRules returns rate limit schedule rules that slow or pause the
+// providers whose current-month spend is near their cap. Each rule expires
+// when the month ends.
+func (s *BillingState) ScheduleRules(now time.Time) []ratelimit.ScheduleRule {
+	start := MonthStart(now)
+	month := start.Format("2006-01")
+	until := start.AddDate(0, 1, 0)
+
+	var rules []ratelimit.ScheduleRule
+	for _, p := range s.Providers() {
+		if p.Month != month || p.Level == SpendOK || p.Level == "" {
+			continue
+		}
+		rule := ratelimit.ScheduleRule{
+			Name:      fmt.Sprintf("%s spend cap (%.0f%% of %s)", p.Provider, p.CapFraction*100, FormatCost(p.CapUSD)),
+			Providers: []string{p.Provider},
+			Location:  time.UTC,
+			Until:     until,
+		}
+		if p.Level == SpendPaused {
+			rule.Pause = true
+		} else {
+			rule.DelayFactor = p.DelayFactor
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ModelProvider returns the provider that bills for model, or "" when it
+// is not recognised.
+func ModelProvider(model string) string {
+	m := normalizeModelName(model)
+	switch {
+	case strings.HasPrefix(m, "claude"), strings.Contains(m, "opus"),
+		strings.Contains(m, "sonnet"), strings.Contains(m, "haiku"):
+		return "anthropic"
+	case strings.HasPrefix(m, "gpt"), strings.HasPrefix(m, "o1"),
+		strings.HasPrefix(m, "o3"), strings.HasPrefix(m, "o4"), strings.Contains(m, "codex"):
+		return "openai"
+	case strings.HasPrefix(m, "gemini"):
+		return "google"
+	}
+	return ""
+}
+
+// ProviderSpendSince returns the estimated spend per provider of the
+// agents updated at or after since.
+func (t *CostTracker) ProviderSpendSince(since time.Time) map[string]float64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	spend := make(map[string]float64)
+	for _, s := range t.sessions {
+		for _, a := range s.Agents {
+			if a.LastUpdated.Before(since) {
+				continue
+			}
+			if provider := ModelProvider(a.Model); provider != "" {
+				spend[provider] += a.Cost()
+			}
+		}
+	}
+	return spend
+}
@@ -0,0 +1,191 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAdminKey = "sk-admin-secret-123"
+
+func TestAnthropicBilling_SpendSince(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/organizations/cost_report" || r.Header.Get("x-api-key") != testAdminKey || r.Header.Get("anthropic-version") == "" {
+			t.Errorf("request %s headers %v", r.URL.Path, r.Header)
+		}
+		if got := r.URL.Query().Get("starting_at"); got != "2026-10-01T00:00:00Z" {
+			t.Errorf("starting_at = %q", got)
+		}
+		// Amounts are in cents; the second page is fetched via next_page.
+		if r.URL.Query().Get("page") == "" {
+			w.Write([]byte(`{"data":[{"results":[{"amount":"1250.5"},{"amount":"49.5"}]}],"has_more":true,"next_page":"p2"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"results":[{"amount":"700"}]}],"has_more":false}`))
+	}))
+	defer srv.Close()
+
+	src, err := NewBillingSource("anthropic", testAdminKey, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := src.SpendSince(context.Background(), since)
+	if err != nil || got != 20 {
+		t.Errorf("SpendSince() = %v, %v; want 20", got, err)
+	}
+}
+
+func TestOpenAIBilling_SpendSince(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/organization/costs" || r.Header.Get("Authorization") != "Bearer "+testAdminKey {
+			t.Errorf("request %s headers %v", r.URL.Path, r.Header)
+		}
+		if got := r.URL.Query().Get("start_time"); got != "1790812800" {
+			t.Errorf("start_time = %q", got)
+		}
+		w.Write([]byte(`{"data":[{"results":[{"amount":{"value":12.25,"currency":"usd"}}]},{"results":[{"amount":{"value":0.75}}]}],"has_more":false}`))
+	}))
+	defer srv.Close()
+
+	src, _ := NewBillingSource("openai", testAdminKey, srv.URL)
+	got, err := src.SpendSince(context.Background(), since)
+	if err != nil || got != 13 {
+		t.Errorf("SpendSince() = %v, %v; want 13", got, err)
+	}
+}
+
+func TestBilling_ErrorsOmitKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"invalid x-api-key ` + testAdminKey + `"}}`))
+	}))
+	defer srv.Close()
+
+	src, _ := NewBillingSource("anthropic", testAdminKey, srv.URL)
+	_, err := src.SpendSince(context.Background(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), testAdminKey) {
+		t.Errorf("error = %v", err)
+	}
+
+	if _, err := NewBillingSource("google", testAdminKey, ""); err == nil {
+		t.Error("google has no billing poller")
+	}
+	if _, err := NewBillingSource("openai", "", ""); err == nil {
+		t.Error("missing key accepted")
+	}
+}
+
+func TestSpendCap_Evaluate(t *testing.T) {
+	limit := SpendCap{MonthlyUSD: 1000, ThrottleAt: 0.8, PauseAt: 0.95, MaxDelayFactor: 4}
+	tests := []struct {
+		spend      float64
+		wantLevel  SpendLevel
+		wantFactor float64
+	}{
+		{500, SpendOK, 1},
+		{800, SpendThrottled, 1},
+		{875, SpendThrottled, 2.5},
+		{950, SpendPaused, 4},
+		{1200, SpendPaused, 4},
+	}
+	for _, tt := range tests {
+		level, factor := limit.Evaluate(tt.spend)
+		if level != tt.wantLevel || factor != tt.wantFactor {
+			t.Errorf("Evaluate(%v) = %s %v, want %s %v", tt.spend, level, factor, tt.wantLevel, tt.wantFactor)
+		}
+	}
+}
+
+func TestBillingState_RecordAndRules(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	limit := SpendCap{MonthlyUSD: 100, ThrottleAt: 0.8, PauseAt: 0.95, MaxDelayFactor: 4}
+	state := NewBillingState()
+
+	spend, prev := state.Record("openai", "OPENAI_ADMIN_KEY", 87.5, 80, limit, now)
+	if prev != SpendOK || spend.Level != SpendThrottled || spend.DriftUSD != 7.5 || spend.CapFraction != 0.875 {
+		t.Fatalf("Record() = %+v, prev %s", spend, prev)
+	}
+	state.Record("anthropic", "ANTHROPIC_ADMIN_KEY", 99, 90, limit, now)
+	if _, prev := state.Record("anthropic", "ANTHROPIC_ADMIN_KEY", 99, 90, limit, now); prev != SpendPaused {
+		t.Errorf("previous level = %s", prev)
+	}
+
+	// A failed poll keeps the last spend in force.
+	state.RecordError("openai", "OPENAI_ADMIN_KEY", errors.New("503"), now)
+	if p := state.Get("openai"); p.Level != SpendThrottled || p.Error != "503" {
+		t.Errorf("after error: %+v", p)
+	}
+
+	path := filepath.Join(t.TempDir(), "billing.json")
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewBillingState()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := loaded.ScheduleRules(now)
+	if len(rules) != 2 {
+		t.Fatalf("rules = %+v", rules)
+	}
+	if r := rules[0]; r.Providers[0] != "anthropic" || !r.Pause || !r.Until.Equal(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("anthropic rule = %+v", r)
+	}
+	if r := rules[1]; r.Providers[0] != "openai" || r.Pause || r.DelayFactor != 2.5 {
+		t.Errorf("openai rule = %+v", r)
+	}
+	// Last month's spend does not throttle the new month.
+	if rules := loaded.ScheduleRules(now.AddDate(0, 1, 0)); len(rules) != 0 {
+		t.Errorf("rules next month = %+v", rules)
+	}
+}
+
+func TestCostTracker_ProviderSpendSince(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	tracker := NewCostTracker("")
+	tracker.RecordTokens("s", "1", "claude-sonnet-4", 1000, 1000)
+	tracker.RecordTokens("s", "2", "gpt-4o", 1000, 0)
+	tracker.RecordTokens("s", "3", "unknown-model", 1000, 0)
+	tracker.sessions["s"].Agents["4"] = &AgentCost{Model: "gpt-4", InputTokens: 1000, LastUpdated: since.Add(-time.Hour)}
+
+	spend := tracker.ProviderSpendSince(since)
+	if len(spend) != 2 || spend["anthropic"] != 0.018 || spend["openai"] != 0.005 {
+		t.Errorf("spend = %v", spend)
+	}
+}
+
+func TestBillingState_SaveMergesConcurrentPolls(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	limit := SpendCap{MonthlyUSD: 100}
+	path := filepath.Join(t.TempDir(), "billing.json")
+
+	// Two processes poll different providers, and b polls openai later.
+	a, b := NewBillingState(), NewBillingState()
+	a.Record("openai", "", 10, 10, limit, now)
+	b.Record("anthropic", "", 20, 20, limit, now)
+	b.Record("openai", "", 30, 30, limit, now.Add(time.Minute))
+	for _, s := range []*BillingState{b, a} {
+		if err := s.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := NewBillingState()
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if p := loaded.Get("anthropic"); p == nil || p.ActualUSD != 20 {
+		t.Errorf("anthropic = %+v, want b's poll kept", p)
+	}
+	if p := loaded.Get("openai"); p == nil || p.ActualUSD != 30 {
+		t.Errorf("openai = %+v, want the later poll", p)
+	}
+}
@@ -0,0 +1,499 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// BudgetLevel describes where a session's spend sits relative to its limits.
+type BudgetLevel string
+
+const (
+	BudgetOK       BudgetLevel = "ok"
+	BudgetSoft     BudgetLevel = "soft_limit"
+	BudgetHardStop BudgetLevel = "hard_limit"
+)
+
+// BudgetExtension records an approved increase to a session's hard limit.
+type BudgetExtension struct {
+	AmountUSD  float64   `json:"amount_usd"`
+	ApprovedBy string    `json:"approved_by"`
+	Reason     string    `json:"reason,omitempty"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+// PromptGuard refuses prompts matching Pattern.
+type PromptGuard struct {
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// SessionBudget holds the guardrail limits and enforcement state for a session.
+// A zero limit disables that guardrail.
+type SessionBudget struct {
+	SoftLimitUSD float64           `json:"soft_limit_usd,omitempty"`
+	HardLimitUSD float64           `json:"hard_limit_usd,omitempty"`
+	Template     string            `json:"template,omitempty"`
+	Extensions   []BudgetExtension `json:"extensions,omitempty"`
+	SoftAlerted  bool              `json:"soft_alerted,omitempty"`
+	Paused       bool              `json:"paused,omitempty"`
+	PausedAt     *time.Time        `json:"paused_at,omitempty"`
+
+	// Set when the session is spawned from a session template. Inherited
+	// limits may only be changed with an audited override.
+	Inherited         bool          `json:"inherited,omitempty"`
+	MaxTokens         int64         `json:"max_tokens,omitempty"`
+	MaxAgents         int           `json:"max_agents,omitempty"`
+	MaxRuntimeSeconds int64         `json:"max_runtime_seconds,omitempty"`
+	StartedAt         *time.Time    `json:"started_at,omitempty"`
+	Guards            []PromptGuard `json:"guards,omitempty"`
+}
+
+// MaxRuntime returns the session's runtime limit, or 0 for none.
+func (b *SessionBudget) MaxRuntime() time.Duration {
+	return time.Duration(b.MaxRuntimeSeconds) * time.Second
+}
+
+// EffectiveHardLimit returns the hard limit plus all approved extensions.
+func (b *SessionBudget) EffectiveHardLimit() float64 {
+	if b.HardLimitUSD <= 0 {
+		return 0
+	}
+	limit := b.HardLimitUSD
+	for _, ext := range b.Extensions {
+		limit += ext.AmountUSD
+	}
+	return limit
+}
+
+// BudgetCheck is the result of evaluating a session's spend against its budget.
+type BudgetCheck struct {
+	Session      string      `json:"session"`
+	SpendUSD     float64     `json:"spend_usd"`
+	SoftLimitUSD float64     `json:"soft_limit_usd,omitempty"`
+	HardLimitUSD float64     `json:"hard_limit_usd,omitempty"` // includes extensions
+	Level        BudgetLevel `json:"level"`
+	Paused       bool        `json:"paused"`
+
+	// SoftCrossed/HardCrossed are true only on the check that first crossed
+	// the limit, so callers raise each event once.
+	SoftCrossed bool `json:"soft_crossed,omitempty"`
+	HardCrossed bool `json:"hard_crossed,omitempty"`
+}
+
+// BudgetExceededError is returned when prompt sends are paused by the hard limit.
+type BudgetExceededError struct {
+	Session      string
+	SpendUSD     float64
+	HardLimitUSD float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("session %q is paused: spend %s reached hard budget limit %s (run 'ntm budget approve %s --extend <usd>' to continue)",
+		e.Session, FormatCost(e.SpendUSD), FormatCost(e.HardLimitUSD), e.Session)
+}
+
+// TemplateLimits are the defaults a session inherits from its session
+// template. A zero limit disables it.
+type TemplateLimits struct {
+	SoftUSD    float64
+	HardUSD    float64
+	Tokens     int64
+	MaxAgents  int
+	MaxRuntime time.Duration
+	Guards     []PromptGuard
+}
+
+// GuardViolationError is returned when an inherited template guard refuses
+// an action.
+type GuardViolationError struct {
+	Session  string
+	Template string
+	Guard    string // "tokens", "runtime", "agents" or "prompt"
+	Detail   string
+}
+
+func (e *GuardViolationError) Error() string {
+	return fmt.Sprintf("session %q: %s (guard inherited from template %q; pass --override-guards to override)",
+		e.Session, e.Detail, e.Template)
+}
+
+// BudgetGuard enforces per-session spend limits.
+type BudgetGuard struct {
+	mu      sync.Mutex
+	budgets map[string]*SessionBudget
+	// changed marks the sessions set, updated or cleared since the last load
+	// or save; SaveToDir writes only these over the file's contents.
+	changed map[string]bool
+}
+
+// NewBudgetGuard creates an empty BudgetGuard.
+func NewBudgetGuard() *BudgetGuard {
+	return &BudgetGuard{budgets: make(map[string]*SessionBudget)}
+}
+
+// budgetPath returns the budget file under dir's .ntm directory.
+func budgetPath(dir string) string {
+	return filepath.Join(dir, ".ntm", "budget.json")
+}
+
+// LoadFromDir loads budget state from the .ntm directory.
+func (g *BudgetGuard) LoadFromDir(dir string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	budgets, err := readBudgetFile(budgetPath(dir))
+	if err != nil {
+		return err
+	}
+	g.budgets = budgets
+	g.changed = nil
+	return nil
+}
+
+// SaveToDir saves budget state to the .ntm directory. Other ntm processes
+// save to the same file, so under the file lock it re-reads the file, replaces
+// the sessions changed here since the last load or save, writes the result
+// and adopts it.
+func (g *BudgetGuard) SaveToDir(dir string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ntmDir := filepath.Join(dir, ".ntm")
+	if err := os.MkdirAll(ntmDir, 0755); err != nil {
+		return fmt.Errorf("create .ntm dir: %w", err)
+	}
+
+	path := budgetPath(dir)
+	return util.WithFileLock(path, func() error {
+		budgets, err := readBudgetFile(path)
+		if err != nil {
+			return err
+		}
+		for name := range g.changed {
+			if b, ok := g.budgets[name]; ok {
+				budgets[name] = b
+			} else {
+				delete(budgets, name)
+			}
+		}
+		return g.writeLocked(path, budgets)
+	})
+}
+
+// UpdateInDir loads budget state from the .ntm directory, applies fn and
+// saves whatever fn changed, all under the budget file's lock, so that a
+// read-modify-write such as an extension or a limit check cannot interleave
+// with another ntm process's. An error from fn is returned without saving.
+func (g *BudgetGuard) UpdateInDir(dir string, fn func() error) error {
+	ntmDir := filepath.Join(dir, ".ntm")
+	if err := os.MkdirAll(ntmDir, 0755); err != nil {
+		return fmt.Errorf("create .ntm dir: %w", err)
+	}
+
+	path := budgetPath(dir)
+	return util.WithFileLock(path, func() error {
+		budgets, err := readBudgetFile(path)
+		if err != nil {
+			return err
+		}
+		g.mu.Lock()
+		g.budgets = budgets
+		g.changed = nil
+		g.mu.Unlock()
+
+		if err := fn(); err != nil {
+			return err
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if len(g.changed) == 0 {
+			return nil
+		}
+		return g.writeLocked(path, g.budgets)
+	})
+}
+
+// writeLocked writes budgets to path and adopts them. The caller must hold
+// g.mu and the file lock.
+func (g *BudgetGuard) writeLocked(path string, budgets map[string]*SessionBudget) error {
+	data, err := json.MarshalIndent(budgets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal budgets: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write budget file: %w", err)
+	}
+	g.budgets = budgets
+	g.changed = nil
+	return nil
+}
+
+// readBudgetFile reads a budget file. A missing file gives no budgets.
+func readBudgetFile(path string) (map[string]*SessionBudget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]*SessionBudget), nil
+		}
+		return nil, fmt.Errorf("read budget file: %w", err)
+	}
+
+	var budgets map[string]*SessionBudget
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("parse budget file: %w", err)
+	}
+	if budgets == nil {
+		budgets = make(map[string]*SessionBudget)
+	}
+	return budgets, nil
+}
+
+// markChanged records that session's budget differs from the file's. The
+// caller must hold g.mu.
+func (g *BudgetGuard) markChanged(session string) {
+	if g.changed == nil {
+		g.changed = make(map[string]bool)
+	}
+	g.changed[session] = true
+}
+
+// SetLimits sets the soft and hard limits for a session, keeping any
+// approved extensions. template records where the limits came from.
+func (g *BudgetGuard) SetLimits(session string, softUSD, hardUSD float64, template string) error {
+	if softUSD < 0 || hardUSD < 0 {
+		return fmt.Errorf("budget limits must not be negative")
+	}
+	if softUSD > 0 && hardUSD > 0 && softUSD > hardUSD {
+		return fmt.Errorf("soft limit %s exceeds hard limit %s", FormatCost(softUSD), FormatCost(hardUSD))
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b := g.getOrCreate(session)
+	g.markChanged(session)
+	b.SoftLimitUSD = softUSD
+	b.HardLimitUSD = hardUSD
+	b.Template = template
+	return nil
+}
+
+// Inherit sets the session's limits and guards from its session template,
+// replacing any earlier budget. startedAt anchors the runtime limit.
+func (g *BudgetGuard) Inherit(session, template string, limits TemplateLimits, startedAt time.Time) error {
+	if limits.SoftUSD < 0 || limits.HardUSD < 0 || limits.Tokens < 0 || limits.MaxAgents < 0 || limits.MaxRuntime < 0 {
+		return fmt.Errorf("template limits must not be negative")
+	}
+	for _, guard := range limits.Guards {
+		if _, err := regexp.Compile(guard.Pattern); err != nil {
+			return fmt.Errorf("template guard %q: %w", guard.Pattern, err)
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	started := startedAt.UTC()
+	g.budgets[session] = &SessionBudget{
+		SoftLimitUSD:      limits.SoftUSD,
+		HardLimitUSD:      limits.HardUSD,
+		Template:          template,
+		Inherited:         true,
+		MaxTokens:         limits.Tokens,
+		MaxAgents:         limits.MaxAgents,
+		MaxRuntimeSeconds: int64(limits.MaxRuntime / time.Second),
+		StartedAt:         &started,
+		Guards:            append([]PromptGuard(nil), limits.Guards...),
+	}
+	g.markChanged(session)
+	return nil
+}
+
+// CheckGuards evaluates a prompt send against the session's inherited
+// token, runtime and prompt guards. Sessions without inherited guards are
+// always allowed.
+func (g *BudgetGuard) CheckGuards(session string, tokens int64, prompt string, now time.Time) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.budgets[session]
+	if !ok || !b.Inherited {
+		return nil
+	}
+	violation := func(guard, detail string) error {
+		return &GuardViolationError{Session: session, Template: b.Template, Guard: guard, Detail: detail}
+	}
+	if b.MaxTokens > 0 && tokens >= b.MaxTokens {
+		return violation("tokens", fmt.Sprintf("used %d of %d tokens", tokens, b.MaxTokens))
+	}
+	if limit := b.MaxRuntime(); limit > 0 && b.StartedAt != nil {
+		if ran := now.Sub(*b.StartedAt); ran >= limit {
+			return violation("runtime", fmt.Sprintf("ran %s of max runtime %s", ran.Round(time.Minute), limit))
+		}
+	}
+	for _, guard := range b.Guards {
+		re, err := regexp.Compile(guard.Pattern)
+		if err != nil || !re.MatchString(prompt) {
+			continue
+		}
+		detail := fmt.Sprintf("prompt matches blocked pattern %q", guard.Pattern)
+		if guard.Reason != "" {
+			detail += ": " + guard.Reason
+		}
+		return violation("prompt", detail)
+	}
+	return nil
+}
+
+// AllowAgents returns a GuardViolationError when total agents would exceed
+// the session's inherited agent cap.
+func (g *BudgetGuard) AllowAgents(session string, total int) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.budgets[session]
+	if !ok || !b.Inherited || b.MaxAgents <= 0 || total <= b.MaxAgents {
+		return nil
+	}
+	return &GuardViolationError{
+		Session:  session,
+		Template: b.Template,
+		Guard:    "agents",
+		Detail:   fmt.Sprintf("%d agents exceeds max %d", total, b.MaxAgents),
+	}
+}
+
+// Get returns a copy of the session budget, or nil if none is configured.
+func (g *BudgetGuard) Get(session string) *SessionBudget {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.budgets[session]
+	if !ok {
+		return nil
+	}
+	cp := *b
+	cp.Extensions = append([]BudgetExtension(nil), b.Extensions...)
+	cp.Guards = append([]PromptGuard(nil), b.Guards...)
+	return &cp
+}
+
+// Sessions returns the names of sessions with a configured budget, sorted.
+func (g *BudgetGuard) Sessions() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, 0, len(g.budgets))
+	for name := range g.budgets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check evaluates spend against the session's limits and updates the
+// enforcement state. Reaching the hard limit pauses the session until an
+// extension is approved. Sessions without a budget always report BudgetOK.
+func (g *BudgetGuard) Check(session string, spendUSD float64) *BudgetCheck {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	check := &BudgetCheck{Session: session, SpendUSD: spendUSD, Level: BudgetOK}
+	b, ok := g.budgets[session]
+	if !ok {
+		return check
+	}
+
+	check.SoftLimitUSD = b.SoftLimitUSD
+	check.HardLimitUSD = b.EffectiveHardLimit()
+
+	if b.SoftLimitUSD > 0 && spendUSD >= b.SoftLimitUSD {
+		check.Level = BudgetSoft
+		if !b.SoftAlerted {
+			b.SoftAlerted = true
+			check.SoftCrossed = true
+			g.markChanged(session)
+		}
+	}
+
+	if hard := check.HardLimitUSD; hard > 0 && spendUSD >= hard {
+		check.Level = BudgetHardStop
+		if !b.Paused {
+			now := time.Now()
+			b.Paused = true
+			b.PausedAt = &now
+			check.HardCrossed = true
+			g.markChanged(session)
+		}
+	}
+
+	check.Paused = b.Paused
+	return check
+}
+
+// Allow returns a BudgetExceededError if the session is paused by its hard
+// limit at the given spend.
+func (g *BudgetGuard) Allow(session string, spendUSD float64) (*BudgetCheck, error) {
+	check := g.Check(session, spendUSD)
+	if check.Paused {
+		return check, &BudgetExceededError{Session: session, SpendUSD: spendUSD, HardLimitUSD: check.HardLimitUSD}
+	}
+	return check, nil
+}
+
+// Extend raises the session's hard limit by amountUSD and lifts the pause.
+// The next Check re-pauses if spend is still at or above the new limit.
+func (g *BudgetGuard) Extend(session string, amountUSD float64, approvedBy, reason string) (*SessionBudget, error) {
+	if amountUSD <= 0 {
+		return nil, fmt.Errorf("extension must be positive")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, ok := g.budgets[session]
+	if !ok || b.HardLimitUSD <= 0 {
+		return nil, fmt.Errorf("session %q has no hard budget limit", session)
+	}
+
+	b.Extensions = append(b.Extensions, BudgetExtension{
+		AmountUSD:  amountUSD,
+		ApprovedBy: approvedBy,
+		Reason:     reason,
+		ApprovedAt: time.Now().UTC(),
+	})
+	b.Paused = false
+	b.PausedAt = nil
+	g.markChanged(session)
+
+	cp := *b
+	cp.Extensions = append([]BudgetExtension(nil), b.Extensions...)
+	return &cp, nil
+}
+
+// Clear removes the budget for a session.
+func (g *BudgetGuard) Clear(session string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.budgets, session)
+	g.markChanged(session)
+}
+
+func (g *BudgetGuard) getOrCreate(session string) *SessionBudget {
+	if b, ok := g.budgets[session]; ok {
+		return b
+	}
+	b := &SessionBudget{}
+	g.budgets[session] = b
+	return b
+}
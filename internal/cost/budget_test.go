@@ -0,0 +1,250 @@
+package cost
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBudgetGuard_CheckLevels(t *testing.T) {
+	g := NewBudgetGuard()
+	if err := g.SetLimits("s1", 5, 10, ""); err != nil {
+		t.Fatalf("SetLimits() error: %v", err)
+	}
+
+	if c := g.Check("s1", 1); c.Level != BudgetOK || c.Paused {
+		t.Errorf("spend 1: got %+v, want ok", c)
+	}
+
+	c := g.Check("s1", 6)
+	if c.Level != BudgetSoft || !c.SoftCrossed {
+		t.Errorf("spend 6: got %+v, want first soft crossing", c)
+	}
+	if c := g.Check("s1", 7); c.SoftCrossed {
+		t.Error("soft crossing should only be reported once")
+	}
+
+	c = g.Check("s1", 10)
+	if c.Level != BudgetHardStop || !c.Paused || !c.HardCrossed {
+		t.Errorf("spend 10: got %+v, want paused at hard limit", c)
+	}
+
+	// Unbudgeted sessions are never blocked.
+	if c := g.Check("other", 1000); c.Level != BudgetOK || c.Paused {
+		t.Errorf("unbudgeted session: got %+v", c)
+	}
+}
+
+func TestBudgetGuard_AllowAndExtend(t *testing.T) {
+	g := NewBudgetGuard()
+	_ = g.SetLimits("s1", 0, 10, "")
+
+	_, err := g.Allow("s1", 12)
+	var exceeded *BudgetExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Allow() error = %v, want BudgetExceededError", err)
+	}
+
+	b, err := g.Extend("s1", 20, "alice", "finish task")
+	if err != nil {
+		t.Fatalf("Extend() error: %v", err)
+	}
+	if b.EffectiveHardLimit() != 30 || b.Paused {
+		t.Errorf("after extend: limit=%v paused=%v", b.EffectiveHardLimit(), b.Paused)
+	}
+	if _, err := g.Allow("s1", 12); err != nil {
+		t.Errorf("Allow() after extension error: %v", err)
+	}
+
+	if _, err := g.Extend("missing", 5, "alice", ""); err == nil {
+		t.Error("expected error extending session without hard limit")
+	}
+	if _, err := g.Extend("s1", 0, "alice", ""); err == nil {
+		t.Error("expected error for non-positive extension")
+	}
+}
+
+func TestBudgetGuard_SetLimitsValidation(t *testing.T) {
+	g := NewBudgetGuard()
+	if err := g.SetLimits("s1", 10, 5, ""); err == nil {
+		t.Error("expected error when soft exceeds hard")
+	}
+	if err := g.SetLimits("s1", -1, 5, ""); err == nil {
+		t.Error("expected error for negative limit")
+	}
+}
+
+func TestBudgetGuard_Persistence(t *testing.T) {
+	dir := t.TempDir()
+	g := NewBudgetGuard()
+	_ = g.SetLimits("s1", 1, 2, "feature")
+	g.Check("s1", 3)
+	if err := g.SaveToDir(dir); err != nil {
+		t.Fatalf("SaveToDir() error: %v", err)
+	}
+
+	loaded := NewBudgetGuard()
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatalf("LoadFromDir() error: %v", err)
+	}
+	b := loaded.Get("s1")
+	if b == nil || !b.Paused || b.Template != "feature" || b.HardLimitUSD != 2 {
+		t.Errorf("loaded budget = %+v", b)
+	}
+
+	// Missing file is not an error.
+	if err := NewBudgetGuard().LoadFromDir(t.TempDir()); err != nil {
+		t.Errorf("LoadFromDir(empty) error: %v", err)
+	}
+}
+
+func TestBudgetGuard_SaveMergesConcurrentGuards(t *testing.T) {
+	dir := t.TempDir()
+
+	// Two processes share the budget file.
+	a := NewBudgetGuard()
+	_ = a.SetLimits("s1", 1, 2, "")
+	_ = a.SetLimits("s2", 1, 2, "")
+	if err := a.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	b := NewBudgetGuard()
+	if err := b.LoadFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Check("s1", 5) // Pauses s1
+	_ = b.SetLimits("s3", 1, 2, "")
+	b.Clear("s2")
+	for _, g := range []*BudgetGuard{a, b, a} {
+		if err := g.SaveToDir(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := NewBudgetGuard()
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s1 := loaded.Get("s1"); s1 == nil || !s1.Paused {
+		t.Errorf("s1 = %+v, want a's pause kept", s1)
+	}
+	if loaded.Get("s2") != nil {
+		t.Error("s2 cleared by b came back")
+	}
+	if loaded.Get("s3") == nil {
+		t.Error("s3 set by b was lost")
+	}
+}
+
+func TestBudgetGuard_UpdateInDirKeepsConcurrentExtensions(t *testing.T) {
+	dir := t.TempDir()
+	seed := NewBudgetGuard()
+	_ = seed.SetLimits("s1", 0, 10, "")
+	if err := seed.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each approval runs in its own guard, as separate processes would.
+	const approvals = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, approvals)
+	for i := 0; i < approvals; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g := NewBudgetGuard()
+			errs <- g.UpdateInDir(dir, func() error {
+				_, err := g.Extend("s1", 1, "alice", "more")
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateInDir() error: %v", err)
+		}
+	}
+
+	loaded := NewBudgetGuard()
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if b := loaded.Get("s1"); b == nil || len(b.Extensions) != approvals || b.EffectiveHardLimit() != 10+approvals {
+		t.Errorf("s1 = %+v, want all %d extensions kept", b, approvals)
+	}
+
+	// A failing update saves nothing.
+	g := NewBudgetGuard()
+	if err := g.UpdateInDir(dir, func() error {
+		g.Clear("s1")
+		return errors.New("abort")
+	}); err == nil {
+		t.Fatal("UpdateInDir() error = nil, want fn's error")
+	}
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Get("s1") == nil {
+		t.Error("aborted update was saved")
+	}
+}
+
+func TestBudgetGuard_InheritedGuards(t *testing.T) {
+	g := NewBudgetGuard()
+	start := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	err := g.Inherit("s1", "triage", TemplateLimits{
+		HardUSD:    20,
+		Tokens:     1000,
+		MaxAgents:  3,
+		MaxRuntime: 2 * time.Hour,
+		Guards:     []PromptGuard{{Pattern: `(?i)git\s+push`, Reason: "review first"}},
+	}, start)
+	if err != nil {
+		t.Fatalf("Inherit() error: %v", err)
+	}
+
+	if err := g.CheckGuards("s1", 500, "fix the tests", start.Add(time.Hour)); err != nil {
+		t.Errorf("CheckGuards() within limits: %v", err)
+	}
+
+	var violation *GuardViolationError
+	for _, tc := range []struct {
+		guard  string
+		tokens int64
+		prompt string
+		at     time.Time
+	}{
+		{"tokens", 1000, "fix the tests", start},
+		{"runtime", 0, "fix the tests", start.Add(2 * time.Hour)},
+		{"prompt", 0, "then Git push it", start},
+	} {
+		err := g.CheckGuards("s1", tc.tokens, tc.prompt, tc.at)
+		if !errors.As(err, &violation) || violation.Guard != tc.guard || violation.Template != "triage" {
+			t.Errorf("%s: CheckGuards() error = %v", tc.guard, err)
+		}
+	}
+
+	if err := g.AllowAgents("s1", 3); err != nil {
+		t.Errorf("AllowAgents(3) error: %v", err)
+	}
+	if err := g.AllowAgents("s1", 4); !errors.As(err, &violation) || violation.Guard != "agents" {
+		t.Errorf("AllowAgents(4) error = %v, want agents violation", err)
+	}
+
+	// Budgets set directly carry no inherited guards.
+	_ = g.SetLimits("s2", 0, 10, "")
+	if err := g.CheckGuards("s2", 1<<40, "git push", start.Add(1000*time.Hour)); err != nil {
+		t.Errorf("CheckGuards() on direct budget: %v", err)
+	}
+	if err := g.AllowAgents("s2", 100); err != nil {
+		t.Errorf("AllowAgents() on direct budget: %v", err)
+	}
+
+	if err := g.Inherit("s3", "bad", TemplateLimits{Guards: []PromptGuard{{Pattern: "("}}}, start); err == nil {
+		t.Error("expected error for invalid guard pattern")
+	}
+}
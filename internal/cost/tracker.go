@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/tokens"
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // ModelPricing defines the cost per 1K tokens for input and output.
@@ -109,6 +110,13 @@ type CostTracker struct {
 	mu       sync.RWMutex
 	sessions map[string]*SessionCost
 	dataDir  string
+
+	// saved holds each agent's token counts as of the last load or save, so
+	// SaveToDir adds only the usage recorded since then to the file.
+	saved map[string]map[string]AgentCost
+	// removed lists the agents cleared or purged since the last load or
+	// save, by session; a nil set stands for the whole session.
+	removed map[string]map[string]bool
 }
 
 // NewCostTracker creates a new CostTracker instance.
@@ -125,28 +133,21 @@ func (t *CostTracker) LoadFromDir(dir string) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	costPath := filepath.Join(dir, ".ntm", "costs.json")
-	data, err := os.ReadFile(costPath)
+	sessions, err := readCostsFile(filepath.Join(dir, ".ntm", "costs.json"))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No cost file yet, that's fine
-		}
-		return fmt.Errorf("read costs file: %w", err)
-	}
-
-	var sessions map[string]*SessionCost
-	if err := json.Unmarshal(data, &sessions); err != nil {
-		return fmt.Errorf("parse costs file: %w", err)
+		return err
 	}
-
-	t.sessions = sessions
+	t.setPersisted(sessions)
 	return nil
 }
 
-// SaveToDir saves cost data to the .ntm directory.
+// SaveToDir saves cost data to the .ntm directory. Other ntm processes record
+// usage to the same file, so under the file lock it re-reads the file, adds
+// the usage recorded here since the last load or save and drops the agents
+// removed here, then writes the result and adopts it.
 func (t *CostTracker) SaveToDir(dir string) error {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
 	ntmDir := filepath.Join(dir, ".ntm")
 	if err := os.MkdirAll(ntmDir, 0755); err != nil {
@@ -154,16 +155,149 @@ func (t *CostTracker) SaveToDir(dir string) error {
 	}
 
 	costPath := filepath.Join(ntmDir, "costs.json")
-	data, err := json.MarshalIndent(t.sessions, "", "  ")
+	return util.WithFileLock(costPath, func() error {
+		sessions, err := readCostsFile(costPath)
+		if err != nil {
+			return err
+		}
+		mergeCosts(sessions, t.sessions, t.saved, t.removed)
+
+		data, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal costs: %w", err)
+		}
+		if err := util.AtomicWriteFile(costPath, data, 0644); err != nil {
+			return fmt.Errorf("write costs file: %w", err)
+		}
+		t.setPersisted(sessions)
+		return nil
+	})
+}
+
+// readCostsFile reads a costs file. A missing file gives no sessions.
+func readCostsFile(path string) (map[string]*SessionCost, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("marshal costs: %w", err)
+		if os.IsNotExist(err) {
+			return make(map[string]*SessionCost), nil // No cost file yet, that's fine
+		}
+		return nil, fmt.Errorf("read costs file: %w", err)
 	}
 
-	if err := os.WriteFile(costPath, data, 0644); err != nil {
-		return fmt.Errorf("write costs file: %w", err)
+	var sessions map[string]*SessionCost
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("parse costs file: %w", err)
 	}
+	if sessions == nil {
+		sessions = make(map[string]*SessionCost)
+	}
+	return sessions, nil
+}
 
-	return nil
+// setPersisted makes sessions, as just read or written, the tracker's data.
+// The caller must hold t.mu.
+func (t *CostTracker) setPersisted(sessions map[string]*SessionCost) {
+	t.sessions = make(map[string]*SessionCost, len(sessions))
+	t.saved = make(map[string]map[string]AgentCost, len(sessions))
+	for name, s := range sessions {
+		if s.Agents == nil {
+			s.Agents = make(map[string]*AgentCost)
+		}
+		sc := &SessionCost{StartTime: s.StartTime, Agents: make(map[string]*AgentCost, len(s.Agents))}
+		t.saved[name] = make(map[string]AgentCost, len(s.Agents))
+		for pane, a := range s.Agents {
+			agentCopy := *a
+			sc.Agents[pane] = &agentCopy
+			t.saved[name][pane] = *a
+		}
+		t.sessions[name] = sc
+	}
+	t.removed = nil
+}
+
+// markRemoved records that pane, or the whole session if pane is empty, was
+// removed, so SaveToDir removes it from the file too. The caller must hold
+// t.mu.
+func (t *CostTracker) markRemoved(session, pane string) {
+	if t.removed == nil {
+		t.removed = make(map[string]map[string]bool)
+	}
+	if pane == "" {
+		t.removed[session] = nil
+		delete(t.saved, session)
+		return
+	}
+	panes, ok := t.removed[session]
+	if ok && panes == nil {
+		return // Whole session already removed
+	}
+	if panes == nil {
+		panes = make(map[string]bool)
+		t.removed[session] = panes
+	}
+	panes[pane] = true
+	delete(t.saved[session], pane)
+}
+
+// mergeCosts applies to disk the agents removed from mem and the tokens
+// recorded in mem since saved was taken.
+func mergeCosts(disk, mem map[string]*SessionCost, saved map[string]map[string]AgentCost, removed map[string]map[string]bool) {
+	for session, panes := range removed {
+		if panes == nil {
+			delete(disk, session)
+			continue
+		}
+		if ds, ok := disk[session]; ok {
+			for pane := range panes {
+				delete(ds.Agents, pane)
+			}
+			if len(ds.Agents) == 0 {
+				delete(disk, session)
+			}
+		}
+	}
+
+	for session, s := range mem {
+		for pane, a := range s.Agents {
+			base := saved[session][pane]
+			input := a.InputTokens - base.InputTokens
+			output := a.OutputTokens - base.OutputTokens
+
+			ds, ok := disk[session]
+			if !ok {
+				if input == 0 && output == 0 {
+					continue // Removed by another process, nothing new
+				}
+				ds = &SessionCost{Agents: make(map[string]*AgentCost), StartTime: s.StartTime}
+				disk[session] = ds
+			}
+			if ds.Agents == nil {
+				ds.Agents = make(map[string]*AgentCost)
+			}
+			if !s.StartTime.IsZero() && s.StartTime.Before(ds.StartTime) {
+				ds.StartTime = s.StartTime
+			}
+			da, ok := ds.Agents[pane]
+			if !ok {
+				if input == 0 && output == 0 {
+					continue
+				}
+				da = &AgentCost{}
+				ds.Agents[pane] = da
+			}
+			da.InputTokens += input
+			da.OutputTokens += output
+			if a.LastUpdated.After(da.LastUpdated) {
+				da.LastUpdated = a.LastUpdated
+				if a.Model != "" {
+					da.Model = a.Model
+				}
+			}
+			if da.Model == "" {
+				da.Model = a.Model
+			}
+		}
+	}
 }
 
 // getOrCreateSession returns the session cost, creating it if needed.
@@ -237,6 +371,21 @@ func (t *CostTracker) RecordTokens(session, pane, model string, inputTokens, out
 	}
 }
 
+// RecordUsage records provider-reported token counts parsed from agent output.
+// Records without token counts are ignored.
+func (t *CostTracker) RecordUsage(session, pane, model string, usage *tokens.TokenUsage) {
+	if !usage.HasTokenCounts() {
+		return
+	}
+	input := usage.InputTokens + usage.CacheReadTokens + usage.CacheWriteTokens
+	output := usage.OutputTokens
+	if input == 0 && output == 0 {
+		// Only a total was reported; attribute it to input as the conservative choice.
+		input = usage.TotalTokens
+	}
+	t.RecordTokens(session, pane, model, int(input), int(output))
+}
+
 // GetSessionCost returns the total USD cost for a session.
 func (t *CostTracker) GetSessionCost(session string) float64 {
 	t.mu.RLock()
@@ -300,6 +449,38 @@ func (t *CostTracker) ClearSession(session string) {
 	defer t.mu.Unlock()
 
 	delete(t.sessions, session)
+	t.markRemoved(session, "")
+}
+
+// PurgeSession removes cost data for a session. If before is non-zero, only
+// agents last updated before it are removed, and the session is dropped once
+// it has no agents left. It returns the number of agent records removed.
+func (t *CostTracker) PurgeSession(session string, before time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.sessions[session]
+	if !ok {
+		return 0
+	}
+	if before.IsZero() {
+		delete(t.sessions, session)
+		t.markRemoved(session, "")
+		return len(s.Agents)
+	}
+
+	removed := 0
+	for pane, agent := range s.Agents {
+		if agent.LastUpdated.Before(before) {
+			delete(s.Agents, pane)
+			t.markRemoved(session, pane)
+			removed++
+		}
+	}
+	if len(s.Agents) == 0 {
+		delete(t.sessions, session)
+	}
+	return removed
 }
 
 func normalizeModelName(model string) string {
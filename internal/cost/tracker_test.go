@@ -7,6 +7,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
 )
 
 func TestEstimateTokens(t *testing.T) {
@@ -213,6 +215,27 @@ func TestCostTracker_RecordTokens(t *testing.T) {
 	}
 }
 
+func TestCostTracker_RecordUsage(t *testing.T) {
+	tracker := NewCostTracker("")
+	tracker.RecordUsage("session1", "pane1", "claude-sonnet", &tokens.TokenUsage{
+		InputTokens:     1000,
+		OutputTokens:    300,
+		CacheReadTokens: 500,
+		ContextPercent:  -1,
+	})
+	// Context-only records carry no token counts and are ignored.
+	tracker.RecordUsage("session1", "pane1", "claude-sonnet", &tokens.TokenUsage{ContextPercent: 40})
+	tracker.RecordUsage("session1", "pane1", "claude-sonnet", nil)
+
+	agent := tracker.GetSession("session1").Agents["pane1"]
+	if agent.InputTokens != 1500 {
+		t.Errorf("InputTokens = %d, want 1500", agent.InputTokens)
+	}
+	if agent.OutputTokens != 300 {
+		t.Errorf("OutputTokens = %d, want 300", agent.OutputTokens)
+	}
+}
+
 func TestCostTracker_GetSessionCost(t *testing.T) {
 	tracker := NewCostTracker("")
 	tracker.RecordTokens("session1", "pane1", "claude-opus", 1000, 1000)
@@ -264,6 +287,27 @@ func TestCostTracker_ClearSession(t *testing.T) {
 	}
 }
 
+func TestCostTracker_PurgeSession(t *testing.T) {
+	tracker := NewCostTracker("")
+	tracker.RecordTokens("session1", "pane1", "claude-opus", 1000, 500)
+	cutoff := time.Now()
+	tracker.RecordTokens("session1", "pane2", "claude-opus", 1000, 500)
+	tracker.sessions["session1"].Agents["pane1"].LastUpdated = cutoff.Add(-time.Hour)
+
+	if n := tracker.PurgeSession("session1", cutoff); n != 1 {
+		t.Errorf("PurgeSession(before) removed %d, want 1", n)
+	}
+	if s := tracker.GetSession("session1"); s == nil || len(s.Agents) != 1 {
+		t.Fatalf("expected pane2 to remain, got %+v", s)
+	}
+	if n := tracker.PurgeSession("session1", time.Time{}); n != 1 {
+		t.Errorf("PurgeSession() removed %d, want 1", n)
+	}
+	if tracker.GetSession("session1") != nil {
+		t.Error("session should be nil after PurgeSession")
+	}
+}
+
 func TestCostTracker_Persistence(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -304,6 +348,59 @@ func TestCostTracker_Persistence(t *testing.T) {
 	}
 }
 
+func TestCostTracker_SaveMergesConcurrentTrackers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Two processes share the costs file.
+	a := NewCostTracker(tmpDir)
+	a.RecordTokens("session1", "pane1", "claude-opus", 1000, 500)
+	a.RecordTokens("session1", "pane2", "claude-opus", 10, 10)
+	if err := a.SaveToDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	b := NewCostTracker(tmpDir)
+	if err := b.LoadFromDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	a.RecordTokens("session1", "pane1", "claude-opus", 100, 50)
+	b.RecordTokens("session1", "pane1", "claude-opus", 200, 20)
+	b.RecordTokens("session2", "pane1", "gpt-4o", 300, 30)
+	b.PurgeSession("session1", time.Now().Add(-time.Hour)) // Removes nothing
+	for _, tr := range []*CostTracker{a, b, a} {
+		if err := tr.SaveToDir(tmpDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded := NewCostTracker(tmpDir)
+	if err := loaded.LoadFromDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	got := loaded.GetSession("session1").Agents["pane1"]
+	if got.InputTokens != 1300 || got.OutputTokens != 570 {
+		t.Errorf("pane1 = %d/%d tokens, want 1300/570", got.InputTokens, got.OutputTokens)
+	}
+	if s := loaded.GetSession("session2"); s == nil || s.Agents["pane1"].InputTokens != 300 {
+		t.Errorf("session2 = %+v, want b's usage kept", s)
+	}
+
+	// A purge is applied to the file, not undone by the next save.
+	b.PurgeSession("session1", time.Time{})
+	if err := b.SaveToDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveToDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadFromDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.GetSession("session1") != nil {
+		t.Error("purged session1 came back")
+	}
+}
+
 func TestCostTracker_LoadFromDir_NoFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	tracker := NewCostTracker(tmpDir)
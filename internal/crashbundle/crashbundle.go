@@ -0,0 +1,516 @@
+// Package crashbundle collects forensic bundles for crashed agents. When the
+// resilience monitor classifies a pane as crashed it saves what is needed to
+// diagnose the crash afterwards — the pane's scrollback, its last archived
+// output segments, recent prompts, rate-limit state and an environment
+// fingerprint — under <project>/.ntm/crashes/<id>/.
+package crashbundle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+const (
+	// DefaultSegments is how many archived output segments and prompts a
+	// bundle includes.
+	DefaultSegments = 20
+
+	// DefaultKeep is how many bundles are kept per project.
+	DefaultKeep = 50
+
+	// ScrollbackLines is how much pane scrollback a bundle captures.
+	ScrollbackLines = tmux.LinesCheckpoint
+)
+
+// Files of a bundle directory.
+const (
+	ManifestFile   = "manifest.json"
+	ScrollbackFile = "scrollback.txt"
+	SegmentsFile   = "segments.jsonl"
+	PromptsFile    = "prompts.json"
+	RateLimitsFile = "rate_limits.json"
+	EnvFile        = "env.json"
+)
+
+// NTMVersion is recorded in each bundle's environment fingerprint. The CLI
+// sets it at startup.
+var NTMVersion = "dev"
+
+// Overridable hooks for tests.
+var (
+	captureFn     = tmux.CapturePaneOutputContext
+	tmuxVersionFn = func(ctx context.Context) (string, error) {
+		return tmux.DefaultClient.RunContext(ctx, "-V")
+	}
+	archiveDir  = ""
+	promptsFn   = history.ReadForSession
+	now         = time.Now
+	hostnameFn  = os.Hostname
+	envFn       = os.Getenv
+	collectTime = 10 * time.Second
+)
+
+// Dir returns the directory holding a project's crash bundles.
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, ".ntm", "crashes")
+}
+
+// Options describes the crash to collect a bundle for.
+type Options struct {
+	ProjectDir   string
+	Session      string
+	PaneID       string
+	PaneIndex    int
+	AgentType    string
+	Model        string
+	Reason       string
+	RestartCount int
+	CrashedAt    time.Time
+
+	// RateLimits is the rate-limit state at the time of the crash, which
+	// only the caller's tracker knows.
+	RateLimits RateLimits
+
+	Segments  int              // Archived segments and prompts to include (DefaultSegments if zero)
+	Keep      int              // Bundles to keep after writing (DefaultKeep if zero, negative = all)
+	Redaction redaction.Config // Secrets are redacted unless Mode is off
+}
+
+// Manifest indexes a bundle and is what listings return.
+type Manifest struct {
+	ID           string            `json:"id"`
+	Session      string            `json:"session"`
+	PaneID       string            `json:"pane_id"`
+	PaneIndex    int               `json:"pane_index"`
+	AgentType    string            `json:"agent_type"`
+	Model        string            `json:"model,omitempty"`
+	Reason       string            `json:"reason"`
+	RestartCount int               `json:"restart_count"`
+	CrashedAt    time.Time         `json:"crashed_at"`
+	Segments     int               `json:"segments"`
+	Prompts      int               `json:"prompts"`
+	Redactions   int               `json:"redactions,omitempty"`
+	Files        []File            `json:"files"`
+	Errors       map[string]string `json:"errors,omitempty"` // Per-file collection failures
+	Path         string            `json:"path"`
+}
+
+// File is one file of a bundle.
+type File struct {
+	Name  string `json:"name"`
+	Bytes int64  `json:"bytes"`
+}
+
+// RateLimits is the rate-limit state saved in a bundle.
+type RateLimits struct {
+	PaneRateLimited bool                `json:"pane_rate_limited"`
+	LastRateLimit   *time.Time          `json:"last_rate_limit,omitempty"`
+	WaitSeconds     int                 `json:"wait_seconds,omitempty"`
+	Providers       []ProviderRateLimit `json:"providers,omitempty"`
+}
+
+// ProviderRateLimit is one tracked provider (or provider account).
+type ProviderRateLimit struct {
+	Provider                 string                     `json:"provider"`
+	State                    ratelimit.ProviderState    `json:"state"`
+	CooldownRemainingSeconds int                        `json:"cooldown_remaining_seconds,omitempty"`
+	Recent                   []ratelimit.RateLimitEvent `json:"recent,omitempty"`
+}
+
+// ProviderRateLimits snapshots every provider a tracker knows about.
+func ProviderRateLimits(tracker *ratelimit.RateLimitTracker) []ProviderRateLimit {
+	if tracker == nil {
+		return nil
+	}
+	providers := tracker.GetAllProviders()
+	sort.Strings(providers)
+	out := make([]ProviderRateLimit, 0, len(providers))
+	for _, p := range providers {
+		state := tracker.GetProviderState(p)
+		if state == nil {
+			continue
+		}
+		out = append(out, ProviderRateLimit{
+			Provider:                 p,
+			State:                    *state,
+			CooldownRemainingSeconds: int(tracker.CooldownRemaining(p).Seconds()),
+			Recent:                   tracker.GetRecentEvents(p, 10),
+		})
+	}
+	return out
+}
+
+// Env fingerprints the environment the agent ran in. Only an allowlist of
+// variables is recorded, never the full environment.
+type Env struct {
+	Fingerprint string            `json:"fingerprint"` // Hash of the fields below
+	NTMVersion  string            `json:"ntm_version"`
+	GoVersion   string            `json:"go_version"`
+	OS          string            `json:"os"`
+	Arch        string            `json:"arch"`
+	NumCPU      int               `json:"num_cpu"`
+	Hostname    string            `json:"hostname,omitempty"`
+	Tmux        string            `json:"tmux,omitempty"`
+	ProjectDir  string            `json:"project_dir"`
+	Vars        map[string]string `json:"vars,omitempty"`
+}
+
+// envVars are the variables recorded in the fingerprint.
+var envVars = []string{"SHELL", "TERM", "LANG", "LC_ALL", "TMUX", "NTM_PROFILE", "NODE_OPTIONS"}
+
+// Bundle is a bundle's manifest and contents.
+type Bundle struct {
+	Manifest   Manifest                `json:"manifest"`
+	Scrollback string                  `json:"scrollback"`
+	Segments   []archive.ArchiveRecord `json:"segments"`
+	Prompts    []history.HistoryEntry  `json:"prompts"`
+	RateLimits *RateLimits             `json:"rate_limits,omitempty"`
+	Env        *Env                    `json:"env,omitempty"`
+}
+
+// Collect gathers a bundle for the crash described by opts and writes it to
+// Dir(opts.ProjectDir)/<id>. Sources that cannot be read are noted in the
+// manifest's Errors rather than failing the bundle; an error is returned
+// only when the bundle cannot be written.
+func Collect(ctx context.Context, opts Options) (*Manifest, error) {
+	if opts.ProjectDir == "" {
+		return nil, fmt.Errorf("crash bundle: project directory is required")
+	}
+	if opts.Segments <= 0 {
+		opts.Segments = DefaultSegments
+	}
+	if opts.CrashedAt.IsZero() {
+		opts.CrashedAt = now()
+	}
+	ctx, cancel := context.WithTimeout(ctx, collectTime)
+	defer cancel()
+
+	b := &Bundle{
+		Manifest: Manifest{
+			Session:      opts.Session,
+			PaneID:       opts.PaneID,
+			PaneIndex:    opts.PaneIndex,
+			AgentType:    opts.AgentType,
+			Model:        opts.Model,
+			Reason:       opts.Reason,
+			RestartCount: opts.RestartCount,
+			CrashedAt:    opts.CrashedAt.UTC(),
+		},
+		RateLimits: &opts.RateLimits,
+	}
+	errs := map[string]string{}
+
+	target := opts.PaneID
+	if target == "" {
+		target = fmt.Sprintf("%s:%d", opts.Session, opts.PaneIndex)
+	}
+	if out, err := captureFn(ctx, target, ScrollbackLines); err != nil {
+		errs[ScrollbackFile] = err.Error()
+	} else {
+		b.Scrollback = out
+	}
+
+	if records, err := archive.RecentPaneRecords(archiveDir, opts.Session, opts.PaneIndex, opts.Segments); err != nil {
+		errs[SegmentsFile] = err.Error()
+	} else {
+		b.Segments = records
+	}
+
+	if prompts, err := recentPrompts(opts.Session, opts.PaneIndex, opts.Segments); err != nil {
+		errs[PromptsFile] = err.Error()
+	} else {
+		b.Prompts = prompts
+	}
+
+	b.Env = fingerprint(ctx, opts.ProjectDir)
+
+	if opts.Redaction.Mode != redaction.ModeOff {
+		b.Manifest.Redactions = redactBundle(b, opts.Redaction)
+	}
+	if len(errs) > 0 {
+		b.Manifest.Errors = errs
+	}
+
+	if err := write(Dir(opts.ProjectDir), b); err != nil {
+		return nil, err
+	}
+	keep := opts.Keep
+	if keep == 0 {
+		keep = DefaultKeep
+	}
+	if keep > 0 {
+		_, _ = Prune(opts.ProjectDir, keep)
+	}
+	return &b.Manifest, nil
+}
+
+// recentPrompts returns the last n prompts of session sent to paneIndex.
+func recentPrompts(session string, paneIndex, n int) ([]history.HistoryEntry, error) {
+	entries, err := promptsFn(session)
+	if err != nil {
+		return nil, err
+	}
+	pane := strconv.Itoa(paneIndex)
+	var out []history.HistoryEntry
+	for _, e := range entries {
+		if len(e.Targets) == 0 || containsString(e.Targets, pane) {
+			out = append(out, e)
+		}
+	}
+	if len(out) > n {
+		out = out[len(out)-n:]
+	}
+	return out, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func fingerprint(ctx context.Context, projectDir string) *Env {
+	env := &Env{
+		NTMVersion: NTMVersion,
+		GoVersion:  runtime.Version(),
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		NumCPU:     runtime.NumCPU(),
+		ProjectDir: projectDir,
+		Vars:       map[string]string{},
+	}
+	env.Hostname, _ = hostnameFn()
+	if v, err := tmuxVersionFn(ctx); err == nil {
+		env.Tmux = strings.TrimSpace(v)
+	}
+	for _, name := range envVars {
+		if v := envFn(name); v != "" {
+			env.Vars[name] = v
+		}
+	}
+
+	data, _ := json.Marshal(env)
+	sum := sha256.Sum256(data)
+	env.Fingerprint = hex.EncodeToString(sum[:8])
+	return env
+}
+
+// redactBundle redacts secrets from the captured text, returning how many
+// were found.
+func redactBundle(b *Bundle, cfg redaction.Config) int {
+	cfg.Mode = redaction.ModeRedact
+	total := 0
+	redact := func(s string) string {
+		out, findings := redaction.Redact(s, cfg)
+		total += len(findings)
+		return out
+	}
+	b.Scrollback = redact(b.Scrollback)
+	for i := range b.Segments {
+		b.Segments[i].Content = redact(b.Segments[i].Content)
+	}
+	for i := range b.Prompts {
+		b.Prompts[i].Prompt = redact(b.Prompts[i].Prompt)
+	}
+	return total
+}
+
+var unsafeIDChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// write saves b under dir in a new directory named after the crash.
+func write(dir string, b *Bundle) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("crash bundle: %w", err)
+	}
+	m := &b.Manifest
+	base := fmt.Sprintf("%s-%s-%d", m.CrashedAt.Format("20060102T150405Z"),
+		unsafeIDChars.ReplaceAllString(m.Session, "_"), m.PaneIndex)
+	var bundleDir string
+	for i := 1; ; i++ {
+		m.ID = base
+		if i > 1 {
+			m.ID = fmt.Sprintf("%s-%d", base, i)
+		}
+		bundleDir = filepath.Join(dir, m.ID)
+		err := os.Mkdir(bundleDir, 0o700)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("crash bundle: %w", err)
+		}
+	}
+	m.Path = bundleDir
+	m.Segments = len(b.Segments)
+	m.Prompts = len(b.Prompts)
+
+	var segments strings.Builder
+	for _, r := range b.Segments {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("crash bundle: %w", err)
+		}
+		segments.Write(line)
+		segments.WriteByte('\n')
+	}
+	files := []struct {
+		name string
+		data any
+	}{
+		{ScrollbackFile, b.Scrollback},
+		{SegmentsFile, segments.String()},
+		{PromptsFile, b.Prompts},
+		{RateLimitsFile, b.RateLimits},
+		{EnvFile, b.Env},
+	}
+	for _, f := range files {
+		var data []byte
+		switch v := f.data.(type) {
+		case string:
+			data = []byte(v)
+		default:
+			var err error
+			if data, err = json.MarshalIndent(v, "", "  "); err != nil {
+				return fmt.Errorf("crash bundle: encoding %s: %w", f.name, err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(bundleDir, f.name), data, 0o600); err != nil {
+			return fmt.Errorf("crash bundle: %w", err)
+		}
+		m.Files = append(m.Files, File{Name: f.name, Bytes: int64(len(data))})
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("crash bundle: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundleDir, ManifestFile), data, 0o600); err != nil {
+		return fmt.Errorf("crash bundle: %w", err)
+	}
+	return nil
+}
+
+// List returns the manifests of a project's bundles, newest first.
+// Directories without a readable manifest are skipped.
+func List(projectDir string) ([]Manifest, error) {
+	dir := Dir(projectDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var manifests []Manifest
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		m, err := readManifest(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		manifests = append(manifests, *m)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		if !manifests[i].CrashedAt.Equal(manifests[j].CrashedAt) {
+			return manifests[i].CrashedAt.After(manifests[j].CrashedAt)
+		}
+		return manifests[i].ID > manifests[j].ID
+	})
+	return manifests, nil
+}
+
+func readManifest(bundleDir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(bundleDir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ManifestFile, err)
+	}
+	m.Path = bundleDir
+	return &m, nil
+}
+
+// Load reads a bundle by ID.
+func Load(projectDir, id string) (*Bundle, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return nil, fmt.Errorf("invalid crash bundle id %q", id)
+	}
+	bundleDir := filepath.Join(Dir(projectDir), id)
+	m, err := readManifest(bundleDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("crash bundle %q not found", id)
+		}
+		return nil, err
+	}
+
+	b := &Bundle{Manifest: *m, Segments: []archive.ArchiveRecord{}, Prompts: []history.HistoryEntry{}}
+	if data, err := os.ReadFile(filepath.Join(bundleDir, ScrollbackFile)); err == nil {
+		b.Scrollback = string(data)
+	}
+	if records, err := archive.ReadRecords(filepath.Join(bundleDir, SegmentsFile)); err == nil && records != nil {
+		b.Segments = records
+	}
+	readJSON(filepath.Join(bundleDir, PromptsFile), &b.Prompts)
+	if b.Prompts == nil {
+		b.Prompts = []history.HistoryEntry{}
+	}
+	var rl RateLimits
+	if readJSON(filepath.Join(bundleDir, RateLimitsFile), &rl) {
+		b.RateLimits = &rl
+	}
+	var env Env
+	if readJSON(filepath.Join(bundleDir, EnvFile), &env) {
+		b.Env = &env
+	}
+	return b, nil
+}
+
+func readJSON(path string, v any) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// Prune removes all but the newest keep bundles of a project, returning how
+// many were removed.
+func Prune(projectDir string, keep int) (int, error) {
+	manifests, err := List(projectDir)
+	if err != nil || len(manifests) <= keep {
+		return 0, err
+	}
+	removed := 0
+	for _, m := range manifests[keep:] {
+		if err := os.RemoveAll(m.Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
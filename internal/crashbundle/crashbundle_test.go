@@ -0,0 +1,151 @@
+package crashbundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+)
+
+func stubSources(t *testing.T, scrollback string, captureErr error) {
+	t.Helper()
+	archives := t.TempDir()
+	var lines []string
+	for i := 1; i <= 3; i++ {
+		data, _ := json.Marshal(archive.ArchiveRecord{Session: "proj", PaneIndex: 2, Sequence: i, Content: "segment"})
+		lines = append(lines, string(data))
+	}
+	if err := os.WriteFile(filepath.Join(archives, "proj_2026-01-01.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldCapture, oldVersion, oldDir, oldPrompts := captureFn, tmuxVersionFn, archiveDir, promptsFn
+	t.Cleanup(func() {
+		captureFn, tmuxVersionFn, archiveDir, promptsFn = oldCapture, oldVersion, oldDir, oldPrompts
+	})
+	captureFn = func(ctx context.Context, target string, lines int) (string, error) {
+		return scrollback, captureErr
+	}
+	tmuxVersionFn = func(ctx context.Context) (string, error) { return "tmux 3.4", nil }
+	archiveDir = archives
+	promptsFn = func(session string) ([]history.HistoryEntry, error) {
+		return []history.HistoryEntry{
+			{ID: "1", Session: session, Targets: []string{"2"}, Prompt: "fix the build"},
+			{ID: "2", Session: session, Targets: []string{"3"}, Prompt: "other pane"},
+			{ID: "3", Session: session, Prompt: "broadcast"},
+		}, nil
+	}
+}
+
+func TestCollectListLoad(t *testing.T) {
+	stubSources(t, "panic: boom\nexport API_KEY=sk-ant-REDACTED\n", nil)
+	project := t.TempDir()
+	crashedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	m, err := Collect(context.Background(), Options{
+		ProjectDir: project,
+		Session:    "proj",
+		PaneID:     "%5",
+		PaneIndex:  2,
+		AgentType:  "cc",
+		Reason:     "Pane no longer exists",
+		CrashedAt:  crashedAt,
+		Segments:   2,
+		RateLimits: RateLimits{PaneRateLimited: true, WaitSeconds: 60},
+		Redaction:  redaction.Config{Mode: redaction.ModeWarn},
+	})
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if m.ID != "20260301T120000Z-proj-2" {
+		t.Errorf("ID = %q", m.ID)
+	}
+	if m.Segments != 2 || m.Prompts != 2 {
+		t.Errorf("segments/prompts = %d/%d, want 2/2", m.Segments, m.Prompts)
+	}
+	if m.Redactions == 0 {
+		t.Error("expected the API key to be redacted")
+	}
+
+	manifests, err := List(project)
+	if err != nil || len(manifests) != 1 || manifests[0].ID != m.ID {
+		t.Fatalf("List() = %+v, %v", manifests, err)
+	}
+
+	b, err := Load(project, m.ID)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !strings.Contains(b.Scrollback, "panic: boom") || strings.Contains(b.Scrollback, "sk-ant-api03") {
+		t.Errorf("scrollback = %q", b.Scrollback)
+	}
+	if len(b.Segments) != 2 || b.Segments[1].Sequence != 3 {
+		t.Errorf("segments = %+v", b.Segments)
+	}
+	if len(b.Prompts) != 2 || b.Prompts[0].Prompt != "fix the build" || b.Prompts[1].Prompt != "broadcast" {
+		t.Errorf("prompts = %+v", b.Prompts)
+	}
+	if b.RateLimits == nil || !b.RateLimits.PaneRateLimited || b.RateLimits.WaitSeconds != 60 {
+		t.Errorf("rate limits = %+v", b.RateLimits)
+	}
+	if b.Env == nil || b.Env.Tmux != "tmux 3.4" || b.Env.Fingerprint == "" {
+		t.Errorf("env = %+v", b.Env)
+	}
+}
+
+func TestCollectRecordsSourceErrors(t *testing.T) {
+	stubSources(t, "", errors.New("can't find pane"))
+	project := t.TempDir()
+
+	m, err := Collect(context.Background(), Options{ProjectDir: project, Session: "proj", PaneIndex: 2})
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if m.Errors[ScrollbackFile] == "" {
+		t.Errorf("errors = %v, want scrollback failure", m.Errors)
+	}
+	if _, err := os.Stat(filepath.Join(m.Path, ScrollbackFile)); err != nil {
+		t.Errorf("scrollback file should still be written: %v", err)
+	}
+}
+
+func TestPruneKeepsNewest(t *testing.T) {
+	stubSources(t, "out", nil)
+	project := t.TempDir()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		if _, err := Collect(context.Background(), Options{
+			ProjectDir: project, Session: "proj", PaneIndex: 1,
+			CrashedAt: base.Add(time.Duration(i) * time.Minute), Keep: 2,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manifests, err := List(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 2 || !manifests[0].CrashedAt.Equal(base.Add(3*time.Minute)) {
+		t.Fatalf("kept = %+v, want the 2 newest", manifests)
+	}
+}
+
+func TestLoadRejectsInvalidID(t *testing.T) {
+	project := t.TempDir()
+	for _, id := range []string{"", "../etc", "a/b", ".hidden"} {
+		if _, err := Load(project, id); err == nil {
+			t.Errorf("Load(%q) should fail", id)
+		}
+	}
+	if _, err := Load(project, "missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Load(missing) error = %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package cron parses cron expressions and records the runs of recurring
+// schedules executed by ntm daemon.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLookahead bounds the search for the next run; an expression that
+// never matches within it (e.g. "0 0 30 2 *") has no next run.
+const maxLookahead = 5 * 366 * 24 * time.Hour
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// Expr is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. As in Vixie cron, when both day fields are
+// restricted a time matches if either does.
+type Expr struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// Parse parses a cron expression or one of the macros @hourly, @daily,
+// @midnight, @weekly, @monthly, @yearly and @annually. Fields accept "*",
+// lists, ranges and steps; months and days of week also accept names.
+func Parse(spec string) (*Expr, error) {
+	spec = strings.TrimSpace(spec)
+	fieldsSpec := spec
+	if m, ok := macros[strings.ToLower(spec)]; ok {
+		fieldsSpec = m
+	}
+	fields := strings.Fields(fieldsSpec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", spec, len(fields))
+	}
+
+	e := &Expr{spec: spec}
+	var err error
+	if e.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("cron expression %q: minute: %w", spec, err)
+	}
+	if e.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("cron expression %q: hour: %w", spec, err)
+	}
+	if e.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("cron expression %q: day of month: %w", spec, err)
+	}
+	if e.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("cron expression %q: month: %w", spec, err)
+	}
+	if e.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("cron expression %q: day of week: %w", spec, err)
+	}
+	// 7 is a second Sunday.
+	if e.dow&(1<<7) != 0 {
+		e.dow = e.dow&^(1<<7) | 1
+	}
+	e.domRestricted = !strings.HasPrefix(fields[2], "*")
+	e.dowRestricted = !strings.HasPrefix(fields[4], "*")
+	return e, nil
+}
+
+// String returns the expression as given to Parse.
+func (e *Expr) String() string {
+	return e.spec
+}
+
+// Next returns the first time after after that matches, in after's
+// location, or the zero time if there is none. Matching is on wall-clock
+// time, so a daily 02:30 run is skipped on the day a DST change removes it.
+func (e *Expr) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(maxLookahead)
+
+	for t.Before(limit) {
+		y, mo, d := t.Date()
+		h := t.Hour()
+		switch {
+		case e.month&(1<<uint(mo)) == 0:
+			t = time.Date(y, mo+1, 1, 0, 0, 0, 0, loc)
+		case !e.dayMatches(t):
+			t = time.Date(y, mo, d+1, 0, 0, 0, 0, loc)
+		case e.hour&(1<<uint(h)) == 0:
+			next := time.Date(y, mo, d, h+1, 0, 0, 0, loc)
+			if !next.After(t) {
+				next = t.Add(time.Hour)
+			}
+			t = next
+		case e.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (e *Expr) dayMatches(t time.Time) bool {
+	dom := e.dom&(1<<uint(t.Day())) != 0
+	dow := e.dow&(1<<uint(t.Weekday())) != 0
+	if e.domRestricted && e.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// parseField parses a cron field into a bitmask over [min, max].
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	field = strings.ToLower(field)
+	value := func(s string) (int, error) {
+		if n, ok := names[s]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("%q is not in %d-%d", s, min, max)
+		}
+		return n, nil
+	}
+
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = value(b); err != nil {
+				return 0, err
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("range %q ends before it starts", rng)
+			}
+		default:
+			n, err := value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = n, n
+			if hasStep {
+				hi = max
+			}
+		}
+		for n := lo; n <= hi; n += step {
+			mask |= 1 << uint(n)
+		}
+	}
+	return mask, nil
+}
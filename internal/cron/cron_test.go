@@ -0,0 +1,118 @@
+package cron
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParse_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"1,,2 * * * *",
+		"@fortnightly",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	utc := time.UTC
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, utc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		{"0 2 * * *", "2026-10-15 01:59", "2026-10-15 02:00"},
+		{"0 2 * * *", "2026-10-15 02:00", "2026-10-16 02:00"},
+		{"*/15 * * * *", "2026-10-15 10:07", "2026-10-15 10:15"},
+		{"30 9 * * mon-fri", "2026-10-16 10:00", "2026-10-19 09:30"}, // Friday -> Monday
+		{"0 0 1 * *", "2026-10-15 00:00", "2026-11-01 00:00"},
+		{"0 0 31 * *", "2026-11-01 00:00", "2026-12-31 00:00"},    // Skips 30-day November
+		{"0 0 29 feb *", "2026-10-15 00:00", "2028-02-29 00:00"},  // Next leap year
+		{"0 12 13 * fri", "2026-10-15 00:00", "2026-10-16 12:00"}, // Either day field
+		{"@hourly", "2026-10-15 10:00", "2026-10-15 11:00"},
+		{"0 0 * * 7", "2026-10-15 00:00", "2026-10-18 00:00"}, // 7 is Sunday
+	}
+	for _, tt := range tests {
+		e, err := Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := e.Next(at(tt.after)); !got.Equal(at(tt.want)) {
+			t.Errorf("%q Next(%s) = %s, want %s", tt.spec, tt.after, got.Format("2006-01-02 15:04"), tt.want)
+		}
+	}
+
+	never, _ := Parse("0 0 30 feb *")
+	if got := never.Next(at("2026-10-15 00:00")); !got.IsZero() {
+		t.Errorf("Feb 30: Next = %s, want zero", got)
+	}
+}
+
+func TestNext_TimeZone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tz database")
+	}
+	e, _ := Parse("0 2 * * *")
+	after := time.Date(2026, 10, 15, 12, 0, 0, 0, ny)
+	if got, want := e.Next(after), time.Date(2026, 10, 16, 2, 0, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+
+	// 02:30 does not exist on 8 March 2026 in New York.
+	e, _ = Parse("30 2 * * *")
+	after = time.Date(2026, 3, 8, 0, 0, 0, 0, ny)
+	if got, want := e.Next(after), time.Date(2026, 3, 9, 2, 30, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("across DST: Next = %s, want %s", got, want)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	if runs, err := h.Runs("", 0); err != nil || len(runs) != 0 {
+		t.Fatalf("empty history = %v, %v", runs, err)
+	}
+
+	start := time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)
+	first := Run{ID: "r1", Schedule: "nightly", StartedAt: start, Status: RunRunning}
+	if err := h.Record(first); err != nil {
+		t.Fatal(err)
+	}
+	done := start.Add(20 * time.Minute)
+	first.Status, first.FinishedAt = RunFailed, &done
+	if err := h.Record(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Record(Run{ID: "r2", Schedule: "other", StartedAt: start.Add(time.Hour), Status: RunSucceeded}); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := h.Runs("", 0)
+	if err != nil || len(runs) != 2 || runs[0].ID != "r2" {
+		t.Fatalf("Runs() = %+v, %v", runs, err)
+	}
+	last, err := h.Last("nightly")
+	if err != nil || last == nil || last.Status != RunFailed || last.Duration(time.Now()) != 20*time.Minute {
+		t.Errorf("Last() = %+v, %v", last, err)
+	}
+}
@@ -0,0 +1,124 @@
+package cron
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// RunStatus is the outcome of a scheduled run.
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+	RunSkipped   RunStatus = "skipped" // E.g. the previous run was still going
+)
+
+// Run is one execution of a schedule.
+type Run struct {
+	ID           string     `json:"id"`
+	Schedule     string     `json:"schedule"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Status       RunStatus  `json:"status"`
+	Session      string     `json:"session,omitempty"`
+	Tasks        int        `json:"tasks"`
+	Error        string     `json:"error,omitempty"` // Why the run failed or was skipped
+	ArtifactsDir string     `json:"artifacts_dir,omitempty"`
+}
+
+// Duration returns how long the run took, or has taken so far.
+func (r Run) Duration(now time.Time) time.Duration {
+	if r.FinishedAt != nil {
+		return r.FinishedAt.Sub(r.StartedAt)
+	}
+	return now.Sub(r.StartedAt)
+}
+
+// History is the append-only log of scheduled runs. A run is recorded when
+// it starts and again when it finishes; the latest record wins.
+type History struct {
+	path string
+}
+
+// NewHistory returns the history kept at path; an empty path means
+// ~/.ntm/schedules/history.jsonl.
+func NewHistory(path string) *History {
+	if path == "" {
+		dir, err := util.NTMDir()
+		if err != nil {
+			dir = filepath.Join(os.TempDir(), "ntm")
+		}
+		path = filepath.Join(dir, "schedules", "history.jsonl")
+	}
+	return &History{path: path}
+}
+
+// Path returns the history file.
+func (h *History) Path() string {
+	return h.path
+}
+
+// Record appends run to the history.
+func (h *History) Record(run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("marshaling run: %w", err)
+	}
+	return util.WithFileLock(h.path, func() error {
+		f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("opening schedule history: %w", err)
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			f.Close()
+			return fmt.Errorf("writing run: %w", err)
+		}
+		return f.Close()
+	})
+}
+
+// Runs returns the runs of schedule (all schedules if empty), newest first,
+// at most limit of them (0 = all).
+func (h *History) Runs(schedule string, limit int) ([]Run, error) {
+	records, _, err := jsonlutil.ReadFile[Run](h.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading schedule history: %w", err)
+	}
+	byID := make(map[string]int)
+	var runs []Run
+	for _, r := range records {
+		if schedule != "" && r.Schedule != schedule {
+			continue
+		}
+		if i, ok := byID[r.ID]; ok {
+			runs[i] = r
+			continue
+		}
+		byID[r.ID] = len(runs)
+		runs = append(runs, r)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+// Last returns the most recent run of schedule, or nil if it never ran.
+func (h *History) Last(schedule string) (*Run, error) {
+	runs, err := h.Runs(schedule, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
@@ -0,0 +1,342 @@
+// Package daemon runs ntm's background subsystems (capture ingestion, the
+// session watchdog, conflict scanning, checkpointing, retention) in one
+// supervised process.
+//
+// Each subsystem runs in its own goroutine. One that returns or panics is
+// restarted with exponential backoff, so a failing subsystem never takes
+// the others down. The daemon periodically writes its status to a file that
+// `ntm daemon status` reads from another process.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/process"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultStatusPath is where the daemon writes its status.
+const DefaultStatusPath = "~/.ntm/daemon/status.json"
+
+// Defaults for Config fields left zero.
+const (
+	DefaultStatusInterval = 5 * time.Second
+	DefaultMinBackoff     = time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+)
+
+// ErrAlreadyRunning is returned by Run when another daemon owns the status
+// file.
+var ErrAlreadyRunning = errors.New("daemon already running")
+
+// State is a subsystem's lifecycle state.
+type State string
+
+const (
+	StateDisabled State = "disabled"
+	StateStarting State = "starting"
+	StateRunning  State = "running"
+	StateBackoff  State = "backoff" // Failed; waiting to restart
+	StateStopped  State = "stopped"
+)
+
+// Subsystem is one background job run by the daemon.
+type Subsystem struct {
+	Name        string
+	Description string
+	// Run does the work until ctx is cancelled. Returning early, with or
+	// without an error, or panicking counts as a failure and the
+	// subsystem is restarted after a backoff.
+	Run func(ctx context.Context, rep *Reporter) error
+}
+
+// SubsystemStatus reports one subsystem.
+type SubsystemStatus struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Enabled     bool      `json:"enabled"`
+	State       State     `json:"state"`
+	Restarts    int       `json:"restarts"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastErrorAt time.Time `json:"last_error_at,omitzero"`
+	// Sessions lists the tmux sessions the subsystem is working on.
+	Sessions []string `json:"sessions,omitempty"`
+}
+
+// Status is the daemon's status file.
+type Status struct {
+	PID        int               `json:"pid"`
+	StartedAt  time.Time         `json:"started_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Stopped    bool              `json:"stopped,omitempty"` // Set on clean exit
+	Subsystems []SubsystemStatus `json:"subsystems"`
+}
+
+// Running reports whether the daemon that wrote the status is still alive.
+func (s *Status) Running() bool {
+	return !s.Stopped && process.IsAlive(s.PID)
+}
+
+// Config configures a Daemon.
+type Config struct {
+	// StatusPath is the status file (DefaultStatusPath if empty).
+	StatusPath string
+	// StatusInterval is how often the status file is rewritten.
+	StatusInterval time.Duration
+	// MinBackoff and MaxBackoff bound the delay before restarting a failed
+	// subsystem. The delay doubles per consecutive failure and resets once
+	// a run outlasts MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Disabled names subsystems not to run; they are still reported.
+	Disabled []string
+	// Logger receives subsystem failures (slog.Default if nil).
+	Logger *slog.Logger
+}
+
+// Daemon supervises a set of subsystems.
+type Daemon struct {
+	cfg        Config
+	subsystems []Subsystem
+	startedAt  time.Time
+
+	mu      sync.Mutex
+	status  map[string]*SubsystemStatus
+	changed chan struct{} // Signalled when a subsystem's status changes
+}
+
+// New creates a daemon for subsystems.
+func New(cfg Config, subsystems []Subsystem) *Daemon {
+	if cfg.StatusPath == "" {
+		cfg.StatusPath = DefaultStatusPath
+	}
+	cfg.StatusPath = util.ExpandPath(cfg.StatusPath)
+	if cfg.StatusInterval <= 0 {
+		cfg.StatusInterval = DefaultStatusInterval
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(DefaultMaxBackoff, cfg.MinBackoff)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+	d := &Daemon{
+		cfg:        cfg,
+		subsystems: subsystems,
+		status:     make(map[string]*SubsystemStatus),
+		changed:    make(chan struct{}, 1),
+	}
+	for _, sub := range subsystems {
+		enabled := !slices.Contains(cfg.Disabled, sub.Name)
+		st := &SubsystemStatus{Name: sub.Name, Description: sub.Description, Enabled: enabled, State: StateDisabled}
+		if enabled {
+			st.State = StateStarting
+		}
+		d.status[sub.Name] = st
+	}
+	return d
+}
+
+// CheckNotRunning returns ErrAlreadyRunning if another live daemon wrote
+// the status file at path (DefaultStatusPath if empty).
+func CheckNotRunning(path string) error {
+	if prev, err := ReadStatus(path); err == nil && prev.Running() && prev.PID != os.Getpid() {
+		return fmt.Errorf("%w (pid %d)", ErrAlreadyRunning, prev.PID)
+	}
+	return nil
+}
+
+// Run starts every enabled subsystem and blocks until ctx is cancelled and
+// all of them have returned. It fails with ErrAlreadyRunning if another
+// live daemon wrote the status file. The status file is rewritten whenever
+// a subsystem changes state and every StatusInterval.
+func (d *Daemon) Run(ctx context.Context) error {
+	if err := CheckNotRunning(d.cfg.StatusPath); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.startedAt = time.Now().UTC()
+	d.mu.Unlock()
+	if err := d.writeStatus(false); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, sub := range d.subsystems {
+		if !d.status[sub.Name].Enabled {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.supervise(ctx, sub)
+		}()
+	}
+
+	ticker := time.NewTicker(d.cfg.StatusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return d.writeStatus(true)
+		case <-ticker.C:
+		case <-d.changed:
+		}
+		if err := d.writeStatus(false); err != nil {
+			d.cfg.Logger.Warn("daemon status write failed", "error", err)
+		}
+	}
+}
+
+// supervise runs sub until ctx is cancelled, restarting it after failures.
+func (d *Daemon) supervise(ctx context.Context, sub Subsystem) {
+	rep := &Reporter{d: d, name: sub.Name}
+	backoff := d.cfg.MinBackoff
+	for {
+		started := time.Now()
+		d.update(sub.Name, func(st *SubsystemStatus) {
+			st.State = StateRunning
+			st.StartedAt = started.UTC()
+		})
+		err := runSafely(ctx, sub, rep)
+		if ctx.Err() != nil {
+			d.update(sub.Name, func(st *SubsystemStatus) {
+				st.State = StateStopped
+				st.Sessions = nil
+			})
+			return
+		}
+		if err == nil {
+			err = errors.New("exited unexpectedly")
+		}
+		if time.Since(started) > d.cfg.MaxBackoff {
+			backoff = d.cfg.MinBackoff
+		}
+		d.cfg.Logger.Warn("daemon subsystem failed", "subsystem", sub.Name, "error", err, "restart_in", backoff)
+		d.update(sub.Name, func(st *SubsystemStatus) {
+			st.State = StateBackoff
+			st.Restarts++
+			st.LastError = err.Error()
+			st.LastErrorAt = time.Now().UTC()
+			st.Sessions = nil
+		})
+
+		select {
+		case <-ctx.Done():
+			d.update(sub.Name, func(st *SubsystemStatus) { st.State = StateStopped })
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, d.cfg.MaxBackoff)
+	}
+}
+
+// runSafely runs sub, turning a panic into an error.
+func runSafely(ctx context.Context, sub Subsystem, rep *Reporter) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v\n%s", p, debug.Stack())
+		}
+	}()
+	return sub.Run(ctx, rep)
+}
+
+func (d *Daemon) update(name string, fn func(*SubsystemStatus)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if st, ok := d.status[name]; ok {
+		fn(st)
+	}
+	select {
+	case d.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Status returns a snapshot of the daemon's status.
+func (d *Daemon) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	st := Status{PID: os.Getpid(), StartedAt: d.startedAt, UpdatedAt: time.Now().UTC()}
+	for _, sub := range d.subsystems {
+		s := *d.status[sub.Name]
+		s.Sessions = slices.Clone(s.Sessions)
+		st.Subsystems = append(st.Subsystems, s)
+	}
+	return st
+}
+
+func (d *Daemon) writeStatus(stopped bool) error {
+	st := d.Status()
+	st.Stopped = stopped
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(d.cfg.StatusPath), 0755); err != nil {
+		return fmt.Errorf("creating daemon status directory: %w", err)
+	}
+	if err := util.AtomicWriteFile(d.cfg.StatusPath, data, 0644); err != nil {
+		return fmt.Errorf("writing daemon status: %w", err)
+	}
+	return nil
+}
+
+// ReadStatus reads the status file at path (DefaultStatusPath if empty).
+func ReadStatus(path string) (*Status, error) {
+	if path == "" {
+		path = DefaultStatusPath
+	}
+	data, err := os.ReadFile(util.ExpandPath(path))
+	if err != nil {
+		return nil, err
+	}
+	var st Status
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parsing daemon status: %w", err)
+	}
+	return &st, nil
+}
+
+// Reporter lets a running subsystem report progress that does not stop it.
+type Reporter struct {
+	d    *Daemon
+	name string
+}
+
+// Error records a non-fatal error, such as one session failing.
+func (r *Reporter) Error(err error) {
+	if r == nil || err == nil {
+		return
+	}
+	r.d.cfg.Logger.Warn("daemon subsystem error", "subsystem", r.name, "error", err)
+	r.d.update(r.name, func(st *SubsystemStatus) {
+		st.LastError = err.Error()
+		st.LastErrorAt = time.Now().UTC()
+	})
+}
+
+// SetSessions records the sessions the subsystem is working on.
+func (r *Reporter) SetSessions(sessions []string) {
+	if r == nil {
+		return
+	}
+	sessions = slices.Clone(sessions)
+	slices.Sort(sessions)
+	r.d.update(r.name, func(st *SubsystemStatus) { st.Sessions = sessions })
+}
@@ -0,0 +1,242 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	return Config{
+		StatusPath:     filepath.Join(t.TempDir(), "status.json"),
+		StatusInterval: 10 * time.Millisecond,
+		MinBackoff:     time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// eventually polls cond until it holds or the test times out.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func subsystemStatus(d *Daemon, name string) SubsystemStatus {
+	for _, st := range d.Status().Subsystems {
+		if st.Name == name {
+			return st
+		}
+	}
+	return SubsystemStatus{}
+}
+
+func TestDaemon_RestartsFailingSubsystems(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Disabled = []string{"off"}
+
+	var failures, panics atomic.Int32
+	d := New(cfg, []Subsystem{
+		{Name: "steady", Run: func(ctx context.Context, _ *Reporter) error {
+			<-ctx.Done()
+			return nil
+		}},
+		{Name: "flaky", Run: func(ctx context.Context, _ *Reporter) error {
+			if failures.Add(1) <= 3 {
+				return errors.New("boom")
+			}
+			<-ctx.Done()
+			return nil
+		}},
+		{Name: "panicky", Run: func(ctx context.Context, _ *Reporter) error {
+			if panics.Add(1) == 1 {
+				panic("kaboom")
+			}
+			<-ctx.Done()
+			return nil
+		}},
+		{Name: "off", Run: func(context.Context, *Reporter) error {
+			t.Error("disabled subsystem ran")
+			return nil
+		}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- d.Run(ctx) }()
+
+	eventually(t, "flaky to recover", func() bool {
+		st := subsystemStatus(d, "flaky")
+		return st.Restarts == 3 && st.State == StateRunning
+	})
+	eventually(t, "panicky to recover", func() bool {
+		st := subsystemStatus(d, "panicky")
+		return st.Restarts == 1 && st.State == StateRunning
+	})
+
+	if st := subsystemStatus(d, "flaky"); st.LastError != "boom" {
+		t.Errorf("flaky last error = %q", st.LastError)
+	}
+	if st := subsystemStatus(d, "panicky"); !strings.HasPrefix(st.LastError, "panic: kaboom") {
+		t.Errorf("panicky last error = %q", st.LastError)
+	}
+	if st := subsystemStatus(d, "steady"); st.Restarts != 0 || st.State != StateRunning {
+		t.Errorf("steady = %+v", st)
+	}
+	if st := subsystemStatus(d, "off"); st.Enabled || st.State != StateDisabled {
+		t.Errorf("off = %+v", st)
+	}
+
+	onDisk, err := ReadStatus(cfg.StatusPath)
+	if err != nil {
+		t.Fatalf("ReadStatus: %v", err)
+	}
+	if onDisk.PID != os.Getpid() || !onDisk.Running() || len(onDisk.Subsystems) != 4 {
+		t.Errorf("status file = %+v", onDisk)
+	}
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	onDisk, err = ReadStatus(cfg.StatusPath)
+	if err != nil {
+		t.Fatalf("ReadStatus: %v", err)
+	}
+	if onDisk.Running() || !onDisk.Stopped {
+		t.Errorf("status after stop = %+v", onDisk)
+	}
+	for _, st := range onDisk.Subsystems {
+		if st.Enabled && st.State != StateStopped {
+			t.Errorf("%s state = %s, want stopped", st.Name, st.State)
+		}
+	}
+}
+
+func TestDaemon_AlreadyRunning(t *testing.T) {
+	cfg := testConfig(t)
+	write := func(st Status) {
+		t.Helper()
+		data, err := json.Marshal(st)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(cfg.StatusPath, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stopped, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Another live process owns the status file.
+	write(Status{PID: os.Getppid()})
+	if err := New(cfg, nil).Run(stopped); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("Run = %v, want ErrAlreadyRunning", err)
+	}
+
+	// A cleanly stopped daemon does not block a new one.
+	write(Status{PID: os.Getppid(), Stopped: true})
+	if err := New(cfg, nil).Run(stopped); err != nil {
+		t.Fatalf("Run after clean stop: %v", err)
+	}
+}
+
+func TestPerSession(t *testing.T) {
+	var mu sync.Mutex
+	sessions := []string{"a", "b"}
+	running := map[string]bool{}
+	attempts := map[string]int{}
+
+	list := func() ([]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(sessions), nil
+	}
+	run := func(ctx context.Context, session string) error {
+		mu.Lock()
+		attempts[session]++
+		n := attempts[session]
+		mu.Unlock()
+		switch {
+		case session == "b" && n == 1:
+			return errors.New("first try fails")
+		case session == "c":
+			return nil // Finishes on its own
+		}
+		mu.Lock()
+		running[session] = true
+		mu.Unlock()
+		<-ctx.Done()
+		mu.Lock()
+		delete(running, session)
+		mu.Unlock()
+		return ctx.Err()
+	}
+
+	cfg := testConfig(t)
+	d := New(cfg, []Subsystem{{Name: "per", Run: PerSession(5*time.Millisecond, list, run)}})
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- d.Run(ctx) }()
+
+	isRunning := func(names ...string) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(running) != len(names) {
+				return false
+			}
+			for _, n := range names {
+				if !running[n] {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	eventually(t, "a and b (after retry) to run", isRunning("a", "b"))
+	if st := subsystemStatus(d, "per"); st.Restarts != 0 || st.LastError == "" {
+		t.Errorf("per-session failure should be reported, not restart: %+v", st)
+	}
+
+	mu.Lock()
+	sessions = []string{"b", "c"}
+	mu.Unlock()
+	eventually(t, "a to stop", isRunning("b"))
+	eventually(t, "sessions to update", func() bool {
+		return slices.Equal(subsystemStatus(d, "per").Sessions, []string{"b"})
+	})
+
+	// c returned nil and must not be restarted while it exists.
+	time.Sleep(30 * time.Millisecond)
+	mu.Lock()
+	if attempts["c"] != 1 || attempts["a"] != 1 {
+		t.Errorf("attempts = %v", attempts)
+	}
+	mu.Unlock()
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !isRunning()() {
+		t.Error("sessions still running after stop")
+	}
+}
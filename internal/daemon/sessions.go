@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// DefaultReconcileInterval is how often PerSession re-lists sessions.
+const DefaultReconcileInterval = 30 * time.Second
+
+// PerSession returns a Subsystem Run func that keeps run going for every
+// session list returns: started as sessions appear and cancelled as they
+// go. A run that fails is retried on the next pass; one that returns nil
+// is done until its session goes away and comes back.
+func PerSession(interval time.Duration, list func() ([]string, error), run func(ctx context.Context, session string) error) func(context.Context, *Reporter) error {
+	if interval <= 0 {
+		interval = DefaultReconcileInterval
+	}
+	return func(ctx context.Context, rep *Reporter) error {
+		type result struct {
+			session string
+			err     error
+		}
+		active := map[string]context.CancelFunc{}
+		done := map[string]bool{}
+		stopped := map[string]bool{} // Cancelled because the session went away
+		results := make(chan result)
+		defer func() {
+			for _, cancel := range active {
+				cancel()
+			}
+			for range active {
+				<-results
+			}
+		}()
+
+		reconcile := func() {
+			sessions, err := list()
+			if err != nil {
+				rep.Error(fmt.Errorf("listing sessions: %w", err))
+				return
+			}
+			for session, cancel := range active {
+				if !slices.Contains(sessions, session) {
+					stopped[session] = true
+					cancel()
+				}
+			}
+			for session := range done {
+				if !slices.Contains(sessions, session) {
+					delete(done, session)
+				}
+			}
+			for _, session := range sessions {
+				if _, ok := active[session]; ok || done[session] {
+					continue
+				}
+				sessionCtx, cancel := context.WithCancel(ctx)
+				active[session] = cancel
+				go func() {
+					results <- result{session, run(sessionCtx, session)}
+				}()
+			}
+			rep.SetSessions(slices.Collect(maps.Keys(active)))
+		}
+
+		reconcile()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				reconcile()
+			case res := <-results:
+				active[res.session]()
+				delete(active, res.session)
+				switch {
+				case stopped[res.session]:
+					delete(stopped, res.session)
+				case res.err != nil:
+					rep.Error(fmt.Errorf("%s: %w", res.session, res.err))
+				default:
+					done[res.session] = true
+				}
+				rep.SetSessions(slices.Collect(maps.Keys(active)))
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package deadline holds the default time limits of long-running
+// operations. They are configured in one place, the [deadlines] section of
+// config.toml, and applied on top of whatever deadline or cancellation the
+// caller's context already carries.
+package deadline
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Op names a kind of long-running operation.
+type Op string
+
+const (
+	Git          Op = "git"           // A local git command
+	GitRemote    Op = "git_remote"    // git fetch, pull or push
+	ConflictScan Op = "conflict_scan" // One conflict analysis of a workspace
+	OIDC         Op = "oidc"          // A fetch from the identity provider
+)
+
+// Defaults are the limits used until Set changes them.
+var Defaults = map[Op]time.Duration{
+	Git:          30 * time.Second,
+	GitRemote:    2 * time.Minute,
+	ConflictScan: time.Minute,
+	OIDC:         5 * time.Second,
+}
+
+var (
+	mu     sync.RWMutex
+	limits = maps.Clone(Defaults)
+)
+
+// Limit returns the limit of op; zero means none.
+func Limit(op Op) time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return limits[op]
+}
+
+// Set changes the limit of op. Zero or less removes it.
+func Set(op Op, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	limits[op] = max(d, 0)
+}
+
+// Reset restores the Defaults.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	limits = maps.Clone(Defaults)
+}
+
+// With returns a context that is cancelled with ctx or when op's limit
+// runs out, whichever comes first.
+func With(ctx context.Context, op Op) (context.Context, context.CancelFunc) {
+	if d := Limit(op); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,54 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWith(t *testing.T) {
+	defer Reset()
+
+	ctx, cancel := With(context.Background(), OIDC)
+	defer cancel()
+	dl, ok := ctx.Deadline()
+	if !ok || time.Until(dl) > Defaults[OIDC] {
+		t.Errorf("deadline = %v, %v; want within %v", dl, ok, Defaults[OIDC])
+	}
+
+	// A caller's earlier deadline wins.
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancelParent()
+	ctx, cancel = With(parent, Git)
+	defer cancel()
+	if dl, _ := ctx.Deadline(); time.Until(dl) > time.Millisecond {
+		t.Errorf("deadline %v is later than the parent's", dl)
+	}
+
+	// Cancelling the caller cancels the operation.
+	parent, cancelParent = context.WithCancel(context.Background())
+	ctx, cancel = With(parent, Git)
+	defer cancel()
+	cancelParent()
+	if ctx.Err() == nil {
+		t.Error("operation not cancelled with its caller")
+	}
+
+	Set(Git, 0)
+	if Limit(Git) != 0 {
+		t.Errorf("Limit after Set(0) = %v", Limit(Git))
+	}
+	ctx, cancel = With(context.Background(), Git)
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("disabled limit still set a deadline")
+	}
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("cancel func does not cancel")
+	}
+
+	Reset()
+	if Limit(Git) != Defaults[Git] {
+		t.Errorf("Limit after Reset = %v", Limit(Git))
+	}
+}
@@ -6,18 +6,21 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"runtime"
 	"strings"
 )
 
 // KeyConfig holds key resolution parameters.
 type KeyConfig struct {
-	KeySource   string            // env, file, or command
-	KeyEnv      string            // Environment variable name
-	KeyFile     string            // Path to key file
-	KeyCommand  string            // Shell command to retrieve key
-	KeyFormat   string            // hex or base64
-	ActiveKeyID string            // Active key for writes
-	Keyring     map[string]string // Key ID -> encoded key material
+	KeySource       string            // env, file, command, or keychain
+	KeyEnv          string            // Environment variable name
+	KeyFile         string            // Path to key file
+	KeyCommand      string            // Shell command to retrieve key
+	KeychainService string            // Keychain service name
+	KeychainAccount string            // Keychain account name
+	KeyFormat       string            // hex or base64
+	ActiveKeyID     string            // Active key for writes
+	Keyring         map[string]string // Key ID -> encoded key material
 }
 
 // ResolveKey loads the encryption key from the configured source.
@@ -41,8 +44,10 @@ func ResolveKey(cfg KeyConfig) ([]byte, error) {
 		encoded, err = resolveFromFile(cfg.KeyFile)
 	case "command":
 		encoded, err = resolveFromCommand(cfg.KeyCommand)
+	case "keychain":
+		encoded, err = resolveFromKeychain(cfg.KeychainService, cfg.KeychainAccount)
 	default:
-		return nil, fmt.Errorf("unsupported key_source %q: use env, file, or command", cfg.KeySource)
+		return nil, fmt.Errorf("unsupported key_source %q: use env, file, command, or keychain", cfg.KeySource)
 	}
 	if err != nil {
 		return nil, err
@@ -106,6 +111,37 @@ func resolveFromCommand(cmd string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// keychainCommand returns the OS command that prints a stored secret:
+// the macOS login keychain or, elsewhere, the Secret Service via secret-tool.
+var keychainCommand = func(service, account string) (*exec.Cmd, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w"), nil
+	case "windows":
+		return nil, fmt.Errorf("key_source=keychain is not supported on Windows: use key_source=command")
+	default:
+		return exec.Command("secret-tool", "lookup", "service", service, "account", account), nil
+	}
+}
+
+func resolveFromKeychain(service, account string) (string, error) {
+	if service == "" {
+		service = "ntm"
+	}
+	if account == "" {
+		account = "encryption-key"
+	}
+	cmd, err := keychainCommand(service, account)
+	if err != nil {
+		return "", err
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("reading key %s/%s from keychain: %w", service, account, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
 func decodeKey(encoded, format string) ([]byte, error) {
 	encoded = strings.TrimSpace(encoded)
 	if encoded == "" {
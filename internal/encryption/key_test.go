@@ -1,8 +1,10 @@
 package encryption
 
 import (
+	"bytes"
 	"encoding/hex"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -109,6 +111,45 @@ func TestResolveKey_Command(t *testing.T) {
 	}
 }
 
+func TestResolveKey_Keychain(t *testing.T) {
+	keyBytes := make([]byte, KeySize)
+	for i := range keyBytes {
+		keyBytes[i] = byte(i + 40)
+	}
+	hexKey := hex.EncodeToString(keyBytes)
+
+	var gotService, gotAccount string
+	old := keychainCommand
+	keychainCommand = func(service, account string) (*exec.Cmd, error) {
+		gotService, gotAccount = service, account
+		return exec.Command("echo", hexKey), nil
+	}
+	defer func() { keychainCommand = old }()
+
+	key, err := ResolveKey(KeyConfig{KeySource: "keychain", KeychainAccount: "work"})
+	if err != nil {
+		t.Fatalf("ResolveKey: %v", err)
+	}
+	if !bytes.Equal(key, keyBytes) {
+		t.Error("keychain key mismatch")
+	}
+	if gotService != "ntm" || gotAccount != "work" {
+		t.Errorf("keychain lookup = %s/%s, want ntm/work", gotService, gotAccount)
+	}
+}
+
+func TestResolveKey_KeychainMissing(t *testing.T) {
+	old := keychainCommand
+	keychainCommand = func(service, account string) (*exec.Cmd, error) {
+		return exec.Command("false"), nil
+	}
+	defer func() { keychainCommand = old }()
+
+	if _, err := ResolveKey(KeyConfig{KeySource: "keychain"}); err == nil {
+		t.Error("expected error when keychain lookup fails")
+	}
+}
+
 func TestResolveKey_InvalidSource(t *testing.T) {
 	cfg := KeyConfig{KeySource: "magic"}
 	_, err := ResolveKey(cfg)
@@ -0,0 +1,207 @@
+package ensemble
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AutoFixMinSimilarity is how alike an unknown mode id or code must be to a
+// catalog entry (1 - edit distance / length) for FixEnsemblePreset to
+// replace it.
+const AutoFixMinSimilarity = 0.6
+
+// PresetFix is one correction applied by FixEnsemblePreset.
+type PresetFix struct {
+	// Code is the code of the validation issue the fix resolves.
+	Code    string      `json:"code"`
+	Field   string      `json:"field"`
+	Message string      `json:"message"`
+	From    interface{} `json:"from,omitempty"`
+	To      interface{} `json:"to,omitempty"`
+}
+
+// FixEnsemblePreset returns a copy of preset with its unambiguous validation
+// errors fixed, and the fixes it applied:
+//
+//   - a mode id or code missing from the catalog is replaced by its nearest
+//     match, if exactly one candidate is nearest and it is at least
+//     AutoFixMinSimilarity alike;
+//   - a mode listed again, by id or by code, is removed;
+//   - negative budgets are reset to 0 (the default), and budgets above their
+//     bounds or a per-mode budget above the total are clamped.
+//
+// Anything else, such as reserves exceeding the total, is left as is.
+func FixEnsemblePreset(preset EnsemblePreset, catalog *ModeCatalog) (EnsemblePreset, []PresetFix) {
+	fixed := preset
+	fixed.Modes = make([]ModeRef, 0, len(preset.Modes))
+	var fixes []PresetFix
+
+	seen := make(map[string]string, len(preset.Modes))
+	for i, ref := range preset.Modes {
+		field := fmt.Sprintf("modes[%d]", i)
+		if catalog != nil {
+			if repaired, fix, ok := fixModeRef(ref, catalog, field); ok {
+				ref = repaired
+				fixes = append(fixes, fix)
+			}
+			if id := lookupModeRef(ref, catalog); id != "" {
+				if first, dup := seen[id]; dup {
+					fixes = append(fixes, PresetFix{
+						Code:    "DUPLICATE_MODE",
+						Field:   field,
+						Message: fmt.Sprintf("removed duplicate of mode %q (already %s)", id, first),
+						From:    ref.String(),
+					})
+					continue
+				}
+				seen[id] = field
+			}
+		}
+		fixed.Modes = append(fixed.Modes, ref)
+	}
+
+	var budgetFixes []PresetFix
+	fixed.Budget, budgetFixes = fixBudget(preset.Budget)
+	fixes = append(fixes, budgetFixes...)
+	return fixed, fixes
+}
+
+// fixModeRef replaces a mode id or code that is not in the catalog with its
+// nearest match. It reports false when ref is fine or the match is unclear.
+func fixModeRef(ref ModeRef, catalog *ModeCatalog, field string) (ModeRef, PresetFix, bool) {
+	switch {
+	case ref.ID != "" && ref.Code != "":
+		return ref, PresetFix{}, false
+	case ref.ID != "":
+		if catalog.GetMode(ref.ID) != nil {
+			return ref, PresetFix{}, false
+		}
+		candidates := make([]string, 0, len(catalog.modes))
+		for _, mode := range catalog.modes {
+			candidates = append(candidates, mode.ID)
+		}
+		match, ok := nearestMatch(strings.ToLower(ref.ID), candidates)
+		if !ok {
+			return ref, PresetFix{}, false
+		}
+		return ModeRef{ID: match}, PresetFix{
+			Code:    "MODE_ID_NOT_FOUND",
+			Field:   field,
+			Message: fmt.Sprintf("replaced unknown mode id %q with %q", ref.ID, match),
+			From:    ref.ID,
+			To:      match,
+		}, true
+	case ref.Code != "":
+		code := strings.ToUpper(ref.Code)
+		if modeCodeRegex.MatchString(code) && catalog.GetModeByCode(code) != nil {
+			return ref, PresetFix{}, false
+		}
+		candidates := make([]string, 0, len(catalog.byCode))
+		for c := range catalog.byCode {
+			candidates = append(candidates, c)
+		}
+		// "A-1" and "a 1" are A1.
+		match, ok := nearestMatch(strings.Map(func(r rune) rune {
+			if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+				return r
+			}
+			return -1
+		}, code), candidates)
+		if !ok {
+			return ref, PresetFix{}, false
+		}
+		issue := "MODE_CODE_NOT_FOUND"
+		if !modeCodeRegex.MatchString(code) {
+			issue = "MODE_CODE_INVALID"
+		}
+		return ModeRef{Code: match}, PresetFix{
+			Code:    issue,
+			Field:   field,
+			Message: fmt.Sprintf("replaced unknown mode code %q with %q", ref.Code, match),
+			From:    ref.Code,
+			To:      match,
+		}, true
+	}
+	return ref, PresetFix{}, false
+}
+
+// lookupModeRef returns the mode id ref names, or "" if it names none.
+func lookupModeRef(ref ModeRef, catalog *ModeCatalog) string {
+	switch {
+	case ref.ID != "" && ref.Code != "":
+		return ""
+	case ref.ID != "":
+		if catalog.GetMode(ref.ID) != nil {
+			return ref.ID
+		}
+	case ref.Code != "":
+		if mode := catalog.GetModeByCode(strings.ToUpper(ref.Code)); mode != nil {
+			return mode.ID
+		}
+	}
+	return ""
+}
+
+// nearestMatch returns the candidate closest to query by edit distance,
+// ignoring case. It fails when several candidates tie or the closest is less
+// than AutoFixMinSimilarity alike.
+func nearestMatch(query string, candidates []string) (string, bool) {
+	query = strings.ToLower(query)
+	best, bestDist, tied := "", -1, false
+	for _, candidate := range candidates {
+		dist := editDistance(query, strings.ToLower(candidate))
+		switch {
+		case bestDist < 0 || dist < bestDist:
+			best, bestDist, tied = candidate, dist, false
+		case dist == bestDist:
+			tied = true
+		}
+	}
+	if bestDist < 0 || tied {
+		return "", false
+	}
+	longest := max(len([]rune(query)), len([]rune(best)))
+	if longest == 0 || 1-float64(bestDist)/float64(longest) < AutoFixMinSimilarity {
+		return "", false
+	}
+	return best, true
+}
+
+// fixBudget clamps out-of-range budget values.
+func fixBudget(b BudgetConfig) (BudgetConfig, []PresetFix) {
+	var fixes []PresetFix
+	clamp := func(field, issue string, v *int, to int, why string) {
+		fixes = append(fixes, PresetFix{
+			Code:    issue,
+			Field:   field,
+			Message: fmt.Sprintf("set to %d (%s)", to, why),
+			From:    *v,
+			To:      to,
+		})
+		*v = to
+	}
+
+	for _, f := range []struct {
+		field string
+		v     *int
+	}{
+		{"budget.max_tokens_per_mode", &b.MaxTokensPerMode},
+		{"budget.max_total_tokens", &b.MaxTotalTokens},
+		{"budget.synthesis_reserve_tokens", &b.SynthesisReserveTokens},
+		{"budget.context_reserve_tokens", &b.ContextReserveTokens},
+	} {
+		if *f.v < 0 {
+			clamp(f.field, "BUDGET_NEGATIVE", f.v, 0, "negative; 0 uses the default")
+		}
+	}
+	if b.MaxTokensPerMode > maxReasonablePerMode {
+		clamp("budget.max_tokens_per_mode", "BUDGET_PER_MODE_TOO_HIGH", &b.MaxTokensPerMode, maxReasonablePerMode, "upper bound")
+	}
+	if b.MaxTotalTokens > maxReasonableTotal {
+		clamp("budget.max_total_tokens", "BUDGET_TOTAL_TOO_HIGH", &b.MaxTotalTokens, maxReasonableTotal, "upper bound")
+	}
+	if b.MaxTokensPerMode > 0 && b.MaxTotalTokens > 0 && b.MaxTokensPerMode > b.MaxTotalTokens {
+		clamp("budget.max_tokens_per_mode", "BUDGET_PER_MODE_EXCEEDS_TOTAL", &b.MaxTokensPerMode, b.MaxTotalTokens, "max_total_tokens")
+	}
+	return b, fixes
+}
@@ -0,0 +1,76 @@
+package ensemble
+
+import "testing"
+
+func TestFixEnsemblePreset(t *testing.T) {
+	catalog := testModeCatalog(t)
+	preset := EnsemblePreset{
+		Name:        "review",
+		Description: "review",
+		Modes: []ModeRef{
+			ModeRefFromID("deductve"),
+			ModeRefFromCode("c-1"),
+			ModeRefFromID("deductive"),
+			ModeRefFromID("zzz"),
+		},
+		Budget: BudgetConfig{
+			MaxTokensPerMode:     500000,
+			MaxTotalTokens:       100000,
+			ContextReserveTokens: -5,
+		},
+	}
+
+	fixed, fixes := FixEnsemblePreset(preset, catalog)
+
+	wantModes := []ModeRef{ModeRefFromID("deductive"), ModeRefFromCode("C1"), ModeRefFromID("zzz")}
+	if len(fixed.Modes) != len(wantModes) {
+		t.Fatalf("modes = %v, want %v", fixed.Modes, wantModes)
+	}
+	for i := range wantModes {
+		if fixed.Modes[i] != wantModes[i] {
+			t.Errorf("modes[%d] = %v, want %v", i, fixed.Modes[i], wantModes[i])
+		}
+	}
+	if preset.Modes[0].ID != "deductve" {
+		t.Error("input preset was modified")
+	}
+	if fixed.Budget.MaxTokensPerMode != 100000 || fixed.Budget.ContextReserveTokens != 0 {
+		t.Errorf("budget = %+v, want per-mode clamped to the total and reserve reset", fixed.Budget)
+	}
+
+	codes := make(map[string]int)
+	for _, f := range fixes {
+		codes[f.Code]++
+	}
+	for _, code := range []string{"MODE_ID_NOT_FOUND", "MODE_CODE_INVALID", "DUPLICATE_MODE", "BUDGET_NEGATIVE", "BUDGET_PER_MODE_TOO_HIGH", "BUDGET_PER_MODE_EXCEEDS_TOTAL"} {
+		if codes[code] != 1 {
+			t.Errorf("fixes with code %s = %d, want 1 (fixes: %+v)", code, codes[code], fixes)
+		}
+	}
+
+	// "zzz" has no close match and is left for the user.
+	report := ValidateEnsemblePreset(&fixed, catalog, nil)
+	if len(report.Errors) != 1 || report.Errors[0].Code != "MODE_ID_NOT_FOUND" {
+		t.Errorf("remaining errors = %+v, want only the unknown mode", report.Errors)
+	}
+}
+
+func TestNearestMatch(t *testing.T) {
+	candidates := []string{"deductive", "abductive", "A1", "A2"}
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{"deductiv", "deductive", true},
+		{"ABDUCTIVE", "abductive", true},
+		{"A3", "", false}, // Ties A1 and A2
+		{"xyz", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := nearestMatch(tt.query, candidates)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("nearestMatch(%q) = %q, %v; want %q, %v", tt.query, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 const (
@@ -95,7 +97,9 @@ func (s *CheckpointStore) WithLogger(logger *slog.Logger) *CheckpointStore {
 	return s
 }
 
-// SaveCheckpoint saves a mode's output as a checkpoint.
+// SaveCheckpoint saves a mode's output as a checkpoint. It is written under
+// the checkpoint's file lock and does not replace a checkpoint for the mode
+// that another process captured later.
 func (s *CheckpointStore) SaveCheckpoint(runID string, checkpoint ModeCheckpoint) error {
 	if s == nil {
 		return errors.New("checkpoint store is nil")
@@ -117,26 +121,36 @@ func (s *CheckpointStore) SaveCheckpoint(runID string, checkpoint ModeCheckpoint
 	}
 
 	filename := filepath.Join(runDir, checkpoint.ModeID+".json")
-	data, err := json.MarshalIndent(checkpoint, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal checkpoint: %w", err)
-	}
-
-	if err := os.WriteFile(filename, data, 0o644); err != nil {
-		return fmt.Errorf("write checkpoint: %w", err)
-	}
+	return util.WithFileLock(filename, func() error {
+		if existing, err := s.LoadCheckpoint(runID, checkpoint.ModeID); err == nil && existing.CapturedAt.After(checkpoint.CapturedAt) {
+			s.logger.Info("newer checkpoint kept",
+				"run_id", runID,
+				"mode_id", checkpoint.ModeID,
+				"status", existing.Status,
+			)
+			return nil
+		}
 
-	s.logger.Info("checkpoint saved",
-		"run_id", runID,
-		"mode_id", checkpoint.ModeID,
-		"status", checkpoint.Status,
-		"tokens", checkpoint.TokensUsed,
-	)
+		data, err := json.MarshalIndent(checkpoint, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal checkpoint: %w", err)
+		}
+		if err := util.AtomicWriteFile(filename, data, 0o644); err != nil {
+			return fmt.Errorf("write checkpoint: %w", err)
+		}
 
-	return nil
+		s.logger.Info("checkpoint saved",
+			"run_id", runID,
+			"mode_id", checkpoint.ModeID,
+			"status", checkpoint.Status,
+			"tokens", checkpoint.TokensUsed,
+		)
+		return nil
+	})
 }
 
-// SaveMetadata saves or updates the checkpoint metadata.
+// SaveMetadata saves or updates the checkpoint metadata. It is written under
+// the metadata's file lock; a zero CreatedAt keeps the saved run's.
 func (s *CheckpointStore) SaveMetadata(meta CheckpointMetadata) error {
 	if s == nil {
 		return errors.New("checkpoint store is nil")
@@ -150,18 +164,50 @@ func (s *CheckpointStore) SaveMetadata(meta CheckpointMetadata) error {
 		return fmt.Errorf("create run directory: %w", err)
 	}
 
+	filename := filepath.Join(runDir, checkpointMetaFile)
+	return util.WithFileLock(filename, func() error {
+		if meta.CreatedAt.IsZero() {
+			if existing, err := s.LoadMetadata(meta.RunID); err == nil {
+				meta.CreatedAt = existing.CreatedAt
+			}
+		}
+		return s.writeMetadata(filename, meta)
+	})
+}
+
+// updateMetadata re-reads the run's metadata and applies fn to it under the
+// metadata's file lock, so updates from concurrent processes, such as modes
+// finishing, are not lost.
+func (s *CheckpointStore) updateMetadata(runID string, fn func(*CheckpointMetadata)) error {
+	// Fail before locking so a missing run is not created by its lock file.
+	if _, err := s.LoadMetadata(runID); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(s.baseDir, runID, checkpointMetaFile)
+	return util.WithFileLock(filename, func() error {
+		meta, err := s.LoadMetadata(runID)
+		if err != nil {
+			return err
+		}
+		fn(meta)
+		return s.writeMetadata(filename, *meta)
+	})
+}
+
+// writeMetadata writes meta to filename. The caller must hold its lock.
+func (s *CheckpointStore) writeMetadata(filename string, meta CheckpointMetadata) error {
 	if meta.CreatedAt.IsZero() {
 		meta.CreatedAt = time.Now().UTC()
 	}
 	meta.UpdatedAt = time.Now().UTC()
 
-	filename := filepath.Join(runDir, checkpointMetaFile)
 	data, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("marshal metadata: %w", err)
 	}
 
-	if err := os.WriteFile(filename, data, 0o644); err != nil {
+	if err := util.AtomicWriteFile(filename, data, 0o644); err != nil {
 		return fmt.Errorf("write metadata: %w", err)
 	}
 
@@ -175,7 +221,9 @@ func (s *CheckpointStore) SaveMetadata(meta CheckpointMetadata) error {
 	return nil
 }
 
-// SaveSynthesisCheckpoint saves streaming synthesis resume state.
+// SaveSynthesisCheckpoint saves streaming synthesis resume state. It is
+// written under the checkpoint's file lock; a zero CreatedAt keeps the saved
+// checkpoint's.
 func (s *CheckpointStore) SaveSynthesisCheckpoint(runID string, checkpoint SynthesisCheckpoint) error {
 	if s == nil {
 		return errors.New("checkpoint store is nil")
@@ -189,28 +237,32 @@ func (s *CheckpointStore) SaveSynthesisCheckpoint(runID string, checkpoint Synth
 		return fmt.Errorf("create run directory: %w", err)
 	}
 
-	if checkpoint.CreatedAt.IsZero() {
-		checkpoint.CreatedAt = time.Now().UTC()
-	}
-	checkpoint.UpdatedAt = time.Now().UTC()
-	checkpoint.RunID = runID
-
 	filename := filepath.Join(runDir, checkpointSynthesisFile)
-	data, err := json.MarshalIndent(checkpoint, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal synthesis checkpoint: %w", err)
-	}
-
-	if err := os.WriteFile(filename, data, 0o644); err != nil {
-		return fmt.Errorf("write synthesis checkpoint: %w", err)
-	}
+	return util.WithFileLock(filename, func() error {
+		if checkpoint.CreatedAt.IsZero() {
+			if existing, err := s.LoadSynthesisCheckpoint(runID); err == nil {
+				checkpoint.CreatedAt = existing.CreatedAt
+			} else {
+				checkpoint.CreatedAt = time.Now().UTC()
+			}
+		}
+		checkpoint.UpdatedAt = time.Now().UTC()
+		checkpoint.RunID = runID
 
-	s.logger.Info("synthesis checkpoint saved",
-		"run_id", runID,
-		"last_index", checkpoint.LastIndex,
-	)
+		data, err := json.MarshalIndent(checkpoint, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal synthesis checkpoint: %w", err)
+		}
+		if err := util.AtomicWriteFile(filename, data, 0o644); err != nil {
+			return fmt.Errorf("write synthesis checkpoint: %w", err)
+		}
 
-	return nil
+		s.logger.Info("synthesis checkpoint saved",
+			"run_id", runID,
+			"last_index", checkpoint.LastIndex,
+		)
+		return nil
+	})
 }
 
 // LoadSynthesisCheckpoint loads streaming synthesis resume state.
@@ -463,32 +515,27 @@ func (s *CheckpointStore) GetPendingModeIDs(runID string) ([]string, error) {
 
 // UpdateModeStatus updates the status of a mode in the metadata.
 func (s *CheckpointStore) UpdateModeStatus(runID, modeID, status string) error {
-	meta, err := s.LoadMetadata(runID)
-	if err != nil {
-		return err
-	}
-
-	// Remove from pending/error lists
-	meta.PendingIDs = removeFromSlice(meta.PendingIDs, modeID)
-	meta.ErrorIDs = removeFromSlice(meta.ErrorIDs, modeID)
-
-	// Add to appropriate list
-	switch AssignmentStatus(status) {
-	case AssignmentDone:
-		if !sliceContains(meta.CompletedIDs, modeID) {
-			meta.CompletedIDs = append(meta.CompletedIDs, modeID)
-		}
-	case AssignmentError:
-		if !sliceContains(meta.ErrorIDs, modeID) {
-			meta.ErrorIDs = append(meta.ErrorIDs, modeID)
-		}
-	default:
-		if !sliceContains(meta.PendingIDs, modeID) {
-			meta.PendingIDs = append(meta.PendingIDs, modeID)
+	return s.updateMetadata(runID, func(meta *CheckpointMetadata) {
+		// Remove from pending/error lists
+		meta.PendingIDs = removeFromSlice(meta.PendingIDs, modeID)
+		meta.ErrorIDs = removeFromSlice(meta.ErrorIDs, modeID)
+
+		// Add to appropriate list
+		switch AssignmentStatus(status) {
+		case AssignmentDone:
+			if !sliceContains(meta.CompletedIDs, modeID) {
+				meta.CompletedIDs = append(meta.CompletedIDs, modeID)
+			}
+		case AssignmentError:
+			if !sliceContains(meta.ErrorIDs, modeID) {
+				meta.ErrorIDs = append(meta.ErrorIDs, modeID)
+			}
+		default:
+			if !sliceContains(meta.PendingIDs, modeID) {
+				meta.PendingIDs = append(meta.PendingIDs, modeID)
+			}
 		}
-	}
-
-	return s.SaveMetadata(*meta)
+	})
 }
 
 func removeFromSlice(slice []string, item string) []string {
@@ -618,16 +665,13 @@ func (m *CheckpointManager) MarkComplete(cleanup bool) error {
 		return errors.New("checkpoint manager is nil")
 	}
 
-	meta, err := m.store.LoadMetadata(m.runID)
+	err := m.store.updateMetadata(m.runID, func(meta *CheckpointMetadata) {
+		meta.Status = EnsembleComplete
+	})
 	if err != nil {
 		return err
 	}
 
-	meta.Status = EnsembleComplete
-	if err := m.store.SaveMetadata(*meta); err != nil {
-		return err
-	}
-
 	if cleanup {
 		return m.store.DeleteRun(m.runID)
 	}
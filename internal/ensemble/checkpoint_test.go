@@ -2,8 +2,10 @@ package ensemble
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 )
@@ -512,6 +514,58 @@ func TestCheckpointStore_UpdateModeStatus(t *testing.T) {
 	t.Logf("TEST: %s - assertion: mode status updated correctly", t.Name())
 }
 
+func TestCheckpointStore_UpdateModeStatus_ConcurrentStores(t *testing.T) {
+	t.Logf("TEST: %s - starting", t.Name())
+
+	tmpDir := t.TempDir()
+	store, err := NewCheckpointStore(tmpDir)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore failed: %v", err)
+	}
+
+	runID := "test-run-concurrent-status"
+	var modes []string
+	for i := 0; i < 8; i++ {
+		modes = append(modes, fmt.Sprintf("mode-%d", i))
+	}
+	if err := store.SaveMetadata(CheckpointMetadata{RunID: runID, PendingIDs: modes}); err != nil {
+		t.Fatalf("SaveMetadata failed: %v", err)
+	}
+
+	// Each mode finishes through its own store, as separate processes would.
+	var wg sync.WaitGroup
+	errs := make(chan error, len(modes))
+	for _, modeID := range modes {
+		wg.Add(1)
+		go func(modeID string) {
+			defer wg.Done()
+			other, err := NewCheckpointStore(tmpDir)
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- other.UpdateModeStatus(runID, modeID, string(AssignmentDone))
+		}(modeID)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateModeStatus failed: %v", err)
+		}
+	}
+
+	loaded, err := store.LoadMetadata(runID)
+	if err != nil {
+		t.Fatalf("LoadMetadata failed: %v", err)
+	}
+	if len(loaded.CompletedIDs) != len(modes) || len(loaded.PendingIDs) != 0 {
+		t.Errorf("CompletedIDs = %v, PendingIDs = %v; want all %d modes completed", loaded.CompletedIDs, loaded.PendingIDs, len(modes))
+	}
+
+	t.Logf("TEST: %s - assertion: no mode status update was lost", t.Name())
+}
+
 func TestCheckpointStore_GetCompletedOutputs(t *testing.T) {
 	t.Logf("TEST: %s - starting", t.Name())
 
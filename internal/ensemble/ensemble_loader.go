@@ -1,6 +1,7 @@
 package ensemble
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -56,23 +57,11 @@ func (l *EnsembleLoader) Load() ([]EnsemblePreset, error) {
 		merged[e.Name] = preset
 	}
 
-	// Layer imported ensembles (user-level imports, lower precedence than user-defined)
-	importedPath := ImportedEnsemblesPath(l.UserConfigDir)
-	if err := l.mergeFromFile(merged, importedPath, "imported"); err != nil {
-		return nil, fmt.Errorf("imported ensembles (%s): %w", importedPath, err)
-	}
-
-	// Layer user ensembles
-	userPath := filepath.Join(l.UserConfigDir, "ensembles.toml")
-	if err := l.mergeFromFile(merged, userPath, "user"); err != nil {
-		return nil, fmt.Errorf("user ensembles (%s): %w", userPath, err)
-	}
-
-	// Layer project ensembles (highest precedence)
-	if l.ProjectDir != "" {
-		projectPath := filepath.Join(l.ProjectDir, ".ntm", "ensembles.toml")
-		if err := l.mergeFromFile(merged, projectPath, "project"); err != nil {
-			return nil, fmt.Errorf("project ensembles (%s): %w", projectPath, err)
+	// Layer imported (user-level imports), user, then project ensembles;
+	// later files take precedence.
+	for _, f := range l.Files() {
+		if err := l.mergeFromFile(merged, f.Path, f.Source); err != nil {
+			return nil, fmt.Errorf("%s ensembles (%s): %w", f.Source, f.Path, err)
 		}
 	}
 
@@ -102,6 +91,25 @@ func (l *EnsembleLoader) Load() ([]EnsemblePreset, error) {
 	return result, nil
 }
 
+// EnsembleFile is a TOML file of user-defined ensemble presets.
+type EnsembleFile struct {
+	Path   string `json:"path"`
+	Source string `json:"source"` // imported, user, or project
+}
+
+// Files returns the ensemble files Load reads, lowest precedence first.
+// They need not exist.
+func (l *EnsembleLoader) Files() []EnsembleFile {
+	files := []EnsembleFile{
+		{Path: ImportedEnsemblesPath(l.UserConfigDir), Source: "imported"},
+		{Path: filepath.Join(l.UserConfigDir, "ensembles.toml"), Source: "user"},
+	}
+	if l.ProjectDir != "" {
+		files = append(files, EnsembleFile{Path: filepath.Join(l.ProjectDir, ".ntm", "ensembles.toml"), Source: "project"})
+	}
+	return files
+}
+
 // mergeFromFile reads a TOML ensembles file and merges entries into the map.
 // Missing files are silently skipped. Invalid content returns an error.
 func (l *EnsembleLoader) mergeFromFile(merged map[string]EnsemblePreset, path, source string) error {
@@ -159,20 +167,26 @@ func LoadEnsemblesFile(path string) ([]EnsemblePreset, error) {
 	return file.Ensembles, nil
 }
 
+// MarshalEnsemblesFile encodes ensembles as SaveEnsemblesFile writes them.
+func MarshalEnsemblesFile(presets []EnsemblePreset) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(ensemblesFile{Ensembles: presets}); err != nil {
+		return nil, fmt.Errorf("encode TOML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 // SaveEnsemblesFile writes ensembles to a TOML file, creating parent directories if needed.
 func SaveEnsemblesFile(path string, presets []EnsemblePreset) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("create ensembles dir: %w", err)
 	}
-	file := ensemblesFile{Ensembles: presets}
-	f, err := os.Create(path)
+	data, err := MarshalEnsemblesFile(presets)
 	if err != nil {
-		return fmt.Errorf("create ensembles file: %w", err)
+		return err
 	}
-	defer f.Close()
-
-	if err := toml.NewEncoder(f).Encode(file); err != nil {
-		return fmt.Errorf("encode TOML: %w", err)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write ensembles file: %w", err)
 	}
 	return nil
 }
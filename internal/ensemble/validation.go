@@ -506,6 +506,12 @@ func validateSynthesisConfig(cfg SynthesisConfig, catalog *ModeCatalog, allowAdv
 	}
 }
 
+// Upper bounds on preset token budgets.
+const (
+	maxReasonablePerMode = 200000
+	maxReasonableTotal   = 1000000
+)
+
 func validateBudgetConfig(cfg BudgetConfig, report *ValidationReport) {
 	if cfg.MaxTokensPerMode < 0 || cfg.MaxTotalTokens < 0 || cfg.SynthesisReserveTokens < 0 || cfg.ContextReserveTokens < 0 {
 		report.add(ValidationIssue{
@@ -542,11 +548,6 @@ func validateBudgetConfig(cfg BudgetConfig, report *ValidationReport) {
 		}
 	}
 
-	const (
-		maxReasonablePerMode = 200000
-		maxReasonableTotal   = 1000000
-	)
-
 	if cfg.MaxTokensPerMode > maxReasonablePerMode {
 		report.add(ValidationIssue{
 			Code:     "BUDGET_PER_MODE_TOO_HIGH",
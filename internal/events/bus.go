@@ -4,6 +4,7 @@ import (
 	"container/ring"
 	"encoding/json"
 	"io"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,20 +27,53 @@ type UnsubscribeFunc func()
 type handlerEntry struct {
 	id      uint64
 	handler EventHandler
+	stats   *subscriberStats
+	queue   *subscriberQueue // nil for unbuffered subscribers
+}
+
+// subscriberStats tracks delivery counters for a single subscription
+type subscriberStats struct {
+	pattern   string
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// subscriberQueue is the per-subscriber buffer used by SubscribeBuffered.
+// Events are enqueued without blocking the publisher; when the buffer is full
+// the event is dropped and counted.
+type subscriberQueue struct {
+	ch   chan BusEvent
+	done chan struct{}
+}
+
+// SubscriberStats reports delivery metrics for one subscription
+type SubscriberStats struct {
+	ID         uint64 `json:"id"`
+	Pattern    string `json:"pattern"`
+	Buffered   bool   `json:"buffered"`
+	BufferSize int    `json:"buffer_size,omitempty"`
+	Queued     int    `json:"queued,omitempty"`
+	Delivered  uint64 `json:"delivered"`
+	Dropped    uint64 `json:"dropped"`
 }
 
 // DefaultMaxConcurrentHandlers limits goroutine spawning to prevent resource exhaustion
 const DefaultMaxConcurrentHandlers = 100
 
+// DefaultSubscriberBuffer is the buffer size used by SubscribeBuffered when
+// a non-positive size is requested
+const DefaultSubscriberBuffer = 256
+
 // EventBus provides a centralized pub/sub system for NTM events
 type EventBus struct {
-	subscribers map[string][]handlerEntry
+	subscribers map[string][]handlerEntry // keyed by event type or glob pattern
 	nextID      atomic.Uint64
 	mu          sync.RWMutex
 	history     *ring.Ring
 	historySize int
 	historyMu   sync.RWMutex
 	handlerSem  chan struct{} // semaphore to limit concurrent handlers
+	dropped     atomic.Uint64 // events dropped across all buffered subscribers
 }
 
 // NewEventBus creates a new event bus with the specified history size
@@ -58,16 +92,67 @@ func NewEventBus(historySize int) *EventBus {
 // DefaultBus is the global default event bus
 var DefaultBus = NewEventBus(100)
 
-// Subscribe registers a handler for a specific event type
+// Subscribe registers a handler for an event type or glob pattern (see
+// MatchEventType). Each event is delivered on its own goroutine.
 // Returns an unsubscribe function
 func (b *EventBus) Subscribe(eventType string, handler EventHandler) UnsubscribeFunc {
+	return b.addSubscriber(eventType, handler, nil)
+}
+
+// SubscribeAll registers a handler for all events (wildcard)
+func (b *EventBus) SubscribeAll(handler EventHandler) UnsubscribeFunc {
+	return b.Subscribe("*", handler)
+}
+
+// SubscribeBuffered registers a handler for an event type or pattern that
+// receives events in publish order on a dedicated goroutine. Up to
+// bufferSize events are queued; when the subscriber falls further behind,
+// new events are dropped for that subscriber only and counted in Stats.
+func (b *EventBus) SubscribeBuffered(eventType string, bufferSize int, handler EventHandler) UnsubscribeFunc {
+	if bufferSize < 1 {
+		bufferSize = DefaultSubscriberBuffer
+	}
+	q := &subscriberQueue{
+		ch:   make(chan BusEvent, bufferSize),
+		done: make(chan struct{}),
+	}
+	unsubscribe := b.addSubscriber(eventType, handler, q)
+
+	var once sync.Once
+	return func() {
+		unsubscribe()
+		once.Do(func() { close(q.done) })
+	}
+}
+
+// SubscribeTyped registers a handler that only receives events of type T
+// matching the event type or pattern. Events with a different concrete type
+// are ignored, which removes the need for type switches in handlers.
+func SubscribeTyped[T BusEvent](b *EventBus, eventType string, handler func(T)) UnsubscribeFunc {
+	return b.Subscribe(eventType, func(e BusEvent) {
+		if evt, ok := e.(T); ok {
+			handler(evt)
+		}
+	})
+}
+
+func (b *EventBus) addSubscriber(eventType string, handler EventHandler, q *subscriberQueue) UnsubscribeFunc {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	id := b.nextID.Add(1)
-	entry := handlerEntry{id: id, handler: handler}
+	entry := handlerEntry{
+		id:      id,
+		handler: handler,
+		stats:   &subscriberStats{pattern: eventType},
+		queue:   q,
+	}
 	b.subscribers[eventType] = append(b.subscribers[eventType], entry)
 
+	if q != nil {
+		go b.drain(entry)
+	}
+
 	// Return unsubscribe function that finds handler by ID
 	return func() {
 		b.mu.Lock()
@@ -79,88 +164,123 @@ func (b *EventBus) Subscribe(eventType string, handler EventHandler) Unsubscribe
 				n := len(handlers)
 				handlers[i] = handlers[n-1]
 				b.subscribers[eventType] = handlers[:n-1]
+				if n == 1 {
+					delete(b.subscribers, eventType)
+				}
 				return
 			}
 		}
 	}
 }
 
-// SubscribeAll registers a handler for all events (wildcard)
-func (b *EventBus) SubscribeAll(handler EventHandler) UnsubscribeFunc {
-	return b.Subscribe("*", handler)
+// drain delivers queued events to a buffered subscriber until it unsubscribes
+func (b *EventBus) drain(entry handlerEntry) {
+	for {
+		select {
+		case <-entry.queue.done:
+			return
+		case event := <-entry.queue.ch:
+			callHandler(entry.handler, event)
+			entry.stats.delivered.Add(1)
+		}
+	}
 }
 
-// Publish sends an event to all matching subscribers
-func (b *EventBus) Publish(event BusEvent) {
-	// Add to history first
+// matching returns the subscribers whose type or pattern matches eventType
+func (b *EventBus) matching(eventType string) []handlerEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entries := make([]handlerEntry, 0, len(b.subscribers[eventType])+len(b.subscribers["*"]))
+	entries = append(entries, b.subscribers[eventType]...)
+	for pattern, handlers := range b.subscribers {
+		if pattern != eventType && isEventPattern(pattern) && MatchEventType(pattern, eventType) {
+			entries = append(entries, handlers...)
+		}
+	}
+	return entries
+}
+
+// enqueue hands an event to a buffered subscriber without blocking
+func (b *EventBus) enqueue(entry handlerEntry, event BusEvent) {
+	select {
+	case <-entry.queue.done:
+	case entry.queue.ch <- event:
+	default:
+		entry.stats.dropped.Add(1)
+		b.dropped.Add(1)
+	}
+}
+
+// callHandler runs a handler, recovering from panics to prevent crashes
+func callHandler(h EventHandler, event BusEvent) {
+	defer func() {
+		_ = recover()
+	}()
+	h(event)
+}
+
+func (b *EventBus) record(event BusEvent) {
 	b.historyMu.Lock()
 	b.history.Value = event
 	b.history = b.history.Next()
 	b.historyMu.Unlock()
+}
 
-	// Get handlers under read lock
-	b.mu.RLock()
-	eventType := event.EventType()
-	entries := make([]handlerEntry, 0, len(b.subscribers[eventType])+len(b.subscribers["*"]))
-	entries = append(entries, b.subscribers[eventType]...)
-	entries = append(entries, b.subscribers["*"]...)
-	b.mu.RUnlock()
+// Publish sends an event to all matching subscribers
+func (b *EventBus) Publish(event BusEvent) {
+	// Add to history first
+	b.record(event)
 
 	// Call handlers outside of lock with bounded concurrency
-	for _, entry := range entries {
+	for _, entry := range b.matching(event.EventType()) {
+		if entry.queue != nil {
+			b.enqueue(entry, event)
+			continue
+		}
+
 		// Acquire semaphore slot (blocks if at capacity to apply backpressure)
 		b.handlerSem <- struct{}{}
 
 		// Run handler in goroutine for non-blocking publish
-		go func(h EventHandler) {
+		go func(entry handlerEntry) {
 			defer func() {
 				// Release semaphore slot
 				<-b.handlerSem
 			}()
-			defer func() {
-				// Recover from panics in event handlers to prevent crashes
-				_ = recover()
-			}()
-			h(event)
-		}(entry.handler)
+			callHandler(entry.handler, event)
+			entry.stats.delivered.Add(1)
+		}(entry)
 	}
 }
 
-// PublishSync sends an event and waits for all handlers to complete
+// PublishSync sends an event and waits for all unbuffered handlers to
+// complete. Buffered subscribers receive the event through their queue.
 func (b *EventBus) PublishSync(event BusEvent) {
 	// Add to history first
-	b.historyMu.Lock()
-	b.history.Value = event
-	b.history = b.history.Next()
-	b.historyMu.Unlock()
-
-	// Get handlers under read lock
-	b.mu.RLock()
-	eventType := event.EventType()
-	entries := make([]handlerEntry, 0, len(b.subscribers[eventType])+len(b.subscribers["*"]))
-	entries = append(entries, b.subscribers[eventType]...)
-	entries = append(entries, b.subscribers["*"]...)
-	b.mu.RUnlock()
+	b.record(event)
 
 	// Call handlers synchronously with bounded concurrency
 	var wg sync.WaitGroup
-	for _, entry := range entries {
+	for _, entry := range b.matching(event.EventType()) {
+		if entry.queue != nil {
+			b.enqueue(entry, event)
+			continue
+		}
+
 		// Acquire semaphore slot (blocks if at capacity to apply backpressure)
 		b.handlerSem <- struct{}{}
 
 		wg.Add(1)
-		go func(h EventHandler) {
+		go func(entry handlerEntry) {
 			defer wg.Done()
 			defer func() {
 				// Release semaphore slot
 				<-b.handlerSem
 			}()
-			defer func() {
-				// Recover from panics in event handlers to prevent crashes
-				_ = recover()
-			}()
-			h(event)
-		}(entry.handler)
+			callHandler(entry.handler, event)
+			entry.stats.delivered.Add(1)
+		}(entry)
 	}
 	wg.Wait()
 }
@@ -208,6 +328,39 @@ func (b *EventBus) SubscriberCount(eventType string) int {
 	return len(b.subscribers[eventType])
 }
 
+// Stats returns delivery metrics for every active subscription, ordered by
+// subscription ID
+func (b *EventBus) Stats() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var stats []SubscriberStats
+	for _, handlers := range b.subscribers {
+		for _, h := range handlers {
+			s := SubscriberStats{
+				ID:        h.id,
+				Pattern:   h.stats.pattern,
+				Buffered:  h.queue != nil,
+				Delivered: h.stats.delivered.Load(),
+				Dropped:   h.stats.dropped.Load(),
+			}
+			if h.queue != nil {
+				s.BufferSize = cap(h.queue.ch)
+				s.Queued = len(h.queue.ch)
+			}
+			stats = append(stats, s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+// DroppedEvents returns the total number of events dropped by buffered
+// subscribers since the bus was created
+func (b *EventBus) DroppedEvents() uint64 {
+	return b.dropped.Load()
+}
+
 // ----------------------------------------------------------------
 // Base Event Implementation
 // ----------------------------------------------------------------
@@ -244,7 +397,7 @@ type ProfileAssignedEvent struct {
 func NewProfileAssignedEvent(session, agentID, profile, previous string) ProfileAssignedEvent {
 	return ProfileAssignedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "profile_assigned",
+			Type:      BusProfileAssigned,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -266,7 +419,7 @@ type ProfileSwitchedEvent struct {
 func NewProfileSwitchedEvent(session, agentID, oldProfile, newProfile string) ProfileSwitchedEvent {
 	return ProfileSwitchedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "profile_switched",
+			Type:      BusProfileSwitched,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -292,7 +445,7 @@ type ContextWarningEvent struct {
 func NewContextWarningEvent(session, agentID string, usagePercent float64, estimatedRoom int64) ContextWarningEvent {
 	return ContextWarningEvent{
 		BaseEvent: BaseEvent{
-			Type:      "context_warning",
+			Type:      BusContextWarning,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -314,7 +467,7 @@ type RotationStartedEvent struct {
 func NewRotationStartedEvent(session, agentID string, usagePercent float64, profile string) RotationStartedEvent {
 	return RotationStartedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "rotation_started",
+			Type:      BusRotationStarted,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -338,7 +491,7 @@ type RotationCompletedEvent struct {
 func NewRotationCompletedEvent(session, oldAgentID, newAgentID string, summaryTokens int, success bool, err string) RotationCompletedEvent {
 	return RotationCompletedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "rotation_completed",
+			Type:      BusRotationCompleted,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -367,7 +520,7 @@ type CheckpointCreatedEvent struct {
 func NewCheckpointCreatedEvent(session, name, level string, sizeBytes int64, agentCount int) CheckpointCreatedEvent {
 	return CheckpointCreatedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "checkpoint_created",
+			Type:      BusCheckpointCreated,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -389,7 +542,7 @@ type CheckpointRestoredEvent struct {
 func NewCheckpointRestoredEvent(session, name string, agentCount int) CheckpointRestoredEvent {
 	return CheckpointRestoredEvent{
 		BaseEvent: BaseEvent{
-			Type:      "checkpoint_restored",
+			Type:      BusCheckpointRestored,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -414,7 +567,7 @@ type WorkflowStartedEvent struct {
 func NewWorkflowStartedEvent(session, workflow, runID string, agents []string) WorkflowStartedEvent {
 	return WorkflowStartedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "workflow_started",
+			Type:      BusWorkflowStarted,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -438,7 +591,7 @@ type StageTransitionEvent struct {
 func NewStageTransitionEvent(session, workflow, runID, fromStage, toStage, trigger string) StageTransitionEvent {
 	return StageTransitionEvent{
 		BaseEvent: BaseEvent{
-			Type:      "stage_transition",
+			Type:      BusStageTransition,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -462,7 +615,7 @@ type WorkflowPausedEvent struct {
 func NewWorkflowPausedEvent(session, workflow, runID, reason string) WorkflowPausedEvent {
 	return WorkflowPausedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "workflow_paused",
+			Type:      BusWorkflowPaused,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -487,7 +640,7 @@ type WorkflowCompletedEvent struct {
 func NewWorkflowCompletedEvent(session, workflow, runID string, durationSec, stageCount int, success bool, err string) WorkflowCompletedEvent {
 	return WorkflowCompletedEvent{
 		BaseEvent: BaseEvent{
-			Type:      "workflow_completed",
+			Type:      BusWorkflowCompleted,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -516,7 +669,7 @@ type AgentStallEvent struct {
 func NewAgentStallEvent(session, agentID string, stallDuration float64, lastActivity string) AgentStallEvent {
 	return AgentStallEvent{
 		BaseEvent: BaseEvent{
-			Type:      "agent_stall",
+			Type:      BusAgentStall,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -538,7 +691,7 @@ type AgentErrorEvent struct {
 func NewAgentErrorEvent(session, agentID, errorType, message string) AgentErrorEvent {
 	return AgentErrorEvent{
 		BaseEvent: BaseEvent{
-			Type:      "agent_error",
+			Type:      BusAgentError,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -565,7 +718,7 @@ type AlertEvent struct {
 func NewAlertEvent(session, alertID, alertType, severity, message string) AlertEvent {
 	return AlertEvent{
 		BaseEvent: BaseEvent{
-			Type:      "alert",
+			Type:      BusAlert,
 			Timestamp: time.Now().UTC(),
 			Session:   session,
 		},
@@ -590,6 +743,11 @@ func SubscribeAll(handler EventHandler) UnsubscribeFunc {
 	return DefaultBus.SubscribeAll(handler)
 }
 
+// SubscribeBuffered registers a buffered handler on the default bus
+func SubscribeBuffered(eventType string, bufferSize int, handler EventHandler) UnsubscribeFunc {
+	return DefaultBus.SubscribeBuffered(eventType, bufferSize, handler)
+}
+
 // Publish sends an event to the default bus
 func Publish(event BusEvent) {
 	DefaultBus.Publish(event)
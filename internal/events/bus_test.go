@@ -515,3 +515,129 @@ func TestEventBus_UnsubscribeMultiple(t *testing.T) {
 			bus.SubscriberCount("test_event"))
 	}
 }
+
+func TestEventBus_PatternSubscriber(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus(10)
+	var workflow, agentWebhook atomic.Int32
+
+	bus.Subscribe("workflow_*", func(e BusEvent) {
+		workflow.Add(1)
+	})
+	bus.Subscribe("agent.*", func(e BusEvent) {
+		agentWebhook.Add(1)
+	})
+
+	bus.PublishSync(NewWorkflowStartedEvent("s", "wf", "run-1", nil))
+	bus.PublishSync(NewWorkflowPausedEvent("s", "wf", "run-1", "manual"))
+	bus.PublishSync(NewStageTransitionEvent("s", "wf", "run-1", "a", "b", ""))
+	bus.PublishSync(NewWebhookEvent(WebhookAgentError, "s", "", "", "boom", nil))
+	bus.PublishSync(NewWebhookEvent(WebhookSessionCreated, "s", "", "", "", nil))
+
+	if got := workflow.Load(); got != 2 {
+		t.Errorf("workflow_* received %d events, want 2", got)
+	}
+	if got := agentWebhook.Load(); got != 1 {
+		t.Errorf("agent.* received %d events, want 1", got)
+	}
+}
+
+func TestSubscribeTyped(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus(10)
+	var got []string
+	var mu sync.Mutex
+
+	SubscribeTyped(bus, "*", func(e AgentErrorEvent) {
+		mu.Lock()
+		got = append(got, e.Message)
+		mu.Unlock()
+	})
+
+	bus.PublishSync(NewAgentErrorEvent("s", "cc_1", "crash", "exit 1"))
+	bus.PublishSync(NewAlertEvent("s", "a1", "warn", "info", "not an agent error"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0] != "exit 1" {
+		t.Errorf("typed handler received %v, want only the agent error", got)
+	}
+}
+
+func TestEventBus_SubscribeBufferedOrderAndDrops(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus(10)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var seen []string
+
+	unsub := bus.SubscribeBuffered("test_*", 2, func(e BusEvent) {
+		<-release
+		mu.Lock()
+		seen = append(seen, e.EventType())
+		mu.Unlock()
+	})
+	defer unsub()
+
+	// The first event is picked up by the drain goroutine and blocks the
+	// handler; give it a moment so the buffer state is deterministic.
+	bus.Publish(BaseEvent{Type: "test_0"})
+	waitFor(t, func() bool { return bus.Stats()[0].Queued == 0 })
+
+	for i := 1; i <= 4; i++ {
+		bus.Publish(BaseEvent{Type: "test_" + string(rune('0'+i))})
+	}
+	if got := bus.DroppedEvents(); got != 2 {
+		t.Fatalf("DroppedEvents() = %d, want 2", got)
+	}
+
+	close(release)
+	waitFor(t, func() bool { return bus.Stats()[0].Delivered == 3 })
+
+	stats := bus.Stats()[0]
+	if !stats.Buffered || stats.BufferSize != 2 || stats.Dropped != 2 || stats.Pattern != "test_*" {
+		t.Errorf("Stats() = %+v", stats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"test_0", "test_1", "test_2"}
+	if len(seen) != len(want) {
+		t.Fatalf("delivered %v, want %v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("delivered %v, want %v", seen, want)
+		}
+	}
+}
+
+func TestEventBus_SubscribeBufferedUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	bus := NewEventBus(10)
+	unsub := bus.SubscribeBuffered("test_event", 0, func(e BusEvent) {})
+	if bus.SubscriberCount("test_event") != 1 {
+		t.Fatalf("expected 1 subscriber, got %d", bus.SubscriberCount("test_event"))
+	}
+	unsub()
+	unsub() // idempotent
+	if bus.SubscriberCount("test_event") != 0 || len(bus.Stats()) != 0 {
+		t.Errorf("subscriber still registered after unsubscribe")
+	}
+	bus.Publish(BaseEvent{Type: "test_event"})
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
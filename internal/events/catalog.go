@@ -0,0 +1,194 @@
+package events
+
+import (
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Bus event types published on the EventBus. Subscribers should use these
+// constants (or patterns built from them) instead of string literals.
+const (
+	BusProfileAssigned    = "profile_assigned"
+	BusProfileSwitched    = "profile_switched"
+	BusContextWarning     = "context_warning"
+	BusRotationStarted    = "rotation_started"
+	BusRotationCompleted  = "rotation_completed"
+	BusCheckpointCreated  = "checkpoint_created"
+	BusCheckpointRestored = "checkpoint_restored"
+	BusWorkflowStarted    = "workflow_started"
+	BusStageTransition    = "stage_transition"
+	BusWorkflowPaused     = "workflow_paused"
+	BusWorkflowCompleted  = "workflow_completed"
+	BusAgentStall         = "agent_stall"
+	BusAgentError         = "agent_error"
+	BusAlert              = "alert"
+)
+
+// EventField describes one field of an event payload.
+type EventField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// EventSpec documents a cataloged event type and its payload schema.
+type EventSpec struct {
+	Type        string       `json:"type"`
+	Description string       `json:"description"`
+	Fields      []EventField `json:"fields"`
+}
+
+type catalogEntry struct {
+	eventType   string
+	description string
+	payload     interface{}
+}
+
+var catalogEntries = []catalogEntry{
+	{BusProfileAssigned, "A profile was assigned to an agent", ProfileAssignedEvent{}},
+	{BusProfileSwitched, "An agent's profile was changed", ProfileSwitchedEvent{}},
+	{BusContextWarning, "An agent's context usage is approaching its threshold", ContextWarningEvent{}},
+	{BusRotationStarted, "Context rotation began for an agent", RotationStartedEvent{}},
+	{BusRotationCompleted, "Context rotation finished for an agent", RotationCompletedEvent{}},
+	{BusCheckpointCreated, "A session checkpoint was created", CheckpointCreatedEvent{}},
+	{BusCheckpointRestored, "A session checkpoint was restored", CheckpointRestoredEvent{}},
+	{BusWorkflowStarted, "A workflow run started", WorkflowStartedEvent{}},
+	{BusStageTransition, "A workflow run moved between stages", StageTransitionEvent{}},
+	{BusWorkflowPaused, "A workflow run was paused", WorkflowPausedEvent{}},
+	{BusWorkflowCompleted, "A workflow run finished", WorkflowCompletedEvent{}},
+	{BusAgentStall, "An agent stopped producing output", AgentStallEvent{}},
+	{BusAgentError, "An agent hit an error or a blocked command", AgentErrorEvent{}},
+	{BusAlert, "An alert was raised", AlertEvent{}},
+	{WebhookSessionCreated, "A session was created", WebhookEvent{}},
+	{WebhookSessionKilled, "A session was killed", WebhookEvent{}},
+	{WebhookAgentStarted, "An agent was started", WebhookEvent{}},
+	{WebhookAgentStopped, "An agent was stopped", WebhookEvent{}},
+	{WebhookAgentError, "An agent reported an error", WebhookEvent{}},
+	{WebhookAgentCrashed, "An agent process crashed", WebhookEvent{}},
+	{WebhookAgentRestarted, "An agent was restarted", WebhookEvent{}},
+	{WebhookAgentIdle, "An agent became idle", WebhookEvent{}},
+	{WebhookAgentBusy, "An agent became busy", WebhookEvent{}},
+	{WebhookAgentRateLimit, "An agent hit a provider rate limit", WebhookEvent{}},
+	{WebhookAgentResumed, "A pane parked by a rate limit had its pending prompt re-sent after the cooldown", WebhookEvent{}},
+	{WebhookAgentCompleted, "An agent completed its task", WebhookEvent{}},
+	{WebhookAgentProgress, "An agent printed an NTM-REPORT self-report of its progress", WebhookEvent{}},
+	{WebhookRotationNeeded, "An agent needs context rotation", WebhookEvent{}},
+	{WebhookContextReset, "An agent near its context limit was summarized and compacted or restarted", WebhookEvent{}},
+	{WebhookHealthDegraded, "Session health degraded", WebhookEvent{}},
+	{WebhookBeadAssigned, "A bead was assigned to an agent", WebhookEvent{}},
+	{WebhookBeadCompleted, "An assigned bead was completed", WebhookEvent{}},
+	{WebhookBeadFailed, "An assigned bead failed", WebhookEvent{}},
+	{WebhookTaskCandidateComplete, "An agent showed per-agent done signals and went quiet; its task awaits verification", WebhookEvent{}},
+	{WebhookPromptUndelivered, "A prompt could not be confirmed in its pane after retries", WebhookEvent{}},
+	{WebhookStorageLowDisk, "Free disk space fell below storage.min_free_mb and archiving was paused", WebhookEvent{}},
+}
+
+// Catalog returns the documented bus event types sorted by type.
+func Catalog() []EventSpec {
+	specs := make([]EventSpec, 0, len(catalogEntries))
+	for _, e := range catalogEntries {
+		specs = append(specs, EventSpec{
+			Type:        e.eventType,
+			Description: e.description,
+			Fields:      payloadFields(reflect.TypeOf(e.payload)),
+		})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Type < specs[j].Type })
+	return specs
+}
+
+// LookupEvent returns the catalog entry for an event type.
+func LookupEvent(eventType string) (EventSpec, bool) {
+	for _, e := range catalogEntries {
+		if e.eventType == eventType {
+			return EventSpec{
+				Type:        e.eventType,
+				Description: e.description,
+				Fields:      payloadFields(reflect.TypeOf(e.payload)),
+			}, true
+		}
+	}
+	return EventSpec{}, false
+}
+
+// MatchEventType reports whether eventType matches a subscription pattern.
+// Patterns use shell glob syntax ("*", "?", "[...]"), so "workflow_*" matches
+// every workflow event and "agent.*" every agent webhook event. A bare "*"
+// matches everything.
+func MatchEventType(pattern, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	if !isEventPattern(pattern) {
+		return false
+	}
+	ok, err := path.Match(pattern, eventType)
+	return err == nil && ok
+}
+
+// isEventPattern reports whether a subscription key contains glob syntax.
+func isEventPattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// payloadFields flattens the JSON fields of an event struct, including the
+// fields promoted from embedded structs such as BaseEvent.
+func payloadFields(t reflect.Type) []EventField {
+	var fields []EventField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, payloadFields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, EventField{
+			Name:     name,
+			Type:     jsonTypeName(f.Type),
+			Optional: strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t.PkgPath() == "time" && t.Name() == "Duration" {
+			return "duration"
+		}
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array<" + jsonTypeName(t.Elem()) + ">"
+	case reflect.Map:
+		return "object<" + jsonTypeName(t.Elem()) + ">"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	case reflect.Struct:
+		if t.PkgPath() == "time" && t.Name() == "Time" {
+			return "timestamp"
+		}
+		return "object"
+	default:
+		return "any"
+	}
+}
@@ -0,0 +1,58 @@
+package events
+
+import "testing"
+
+func TestCatalogCoversBusEvents(t *testing.T) {
+	t.Parallel()
+
+	specs := Catalog()
+	for i := 1; i < len(specs); i++ {
+		if specs[i-1].Type >= specs[i].Type {
+			t.Fatalf("catalog not sorted or has duplicates at %q", specs[i].Type)
+		}
+	}
+
+	spec, ok := LookupEvent(BusRotationCompleted)
+	if !ok {
+		t.Fatal("rotation_completed missing from catalog")
+	}
+	fields := make(map[string]EventField)
+	for _, f := range spec.Fields {
+		fields[f.Name] = f
+	}
+	if fields["timestamp"].Type != "timestamp" || fields["session"].Optional != true {
+		t.Errorf("base fields not flattened correctly: %+v", spec.Fields)
+	}
+	if fields["success"].Type != "boolean" {
+		t.Errorf("success field = %+v, want boolean", fields["success"])
+	}
+
+	if _, ok := LookupEvent("no_such_event"); ok {
+		t.Error("LookupEvent found an unknown type")
+	}
+}
+
+func TestMatchEventType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern, eventType string
+		want               bool
+	}{
+		{"*", "anything", true},
+		{"alert", "alert", true},
+		{"alert", "alerts", false},
+		{"workflow_*", "workflow_paused", true},
+		{"workflow_*", "stage_transition", false},
+		{"agent.*", "agent.crashed", true},
+		{"*_completed", "rotation_completed", true},
+		{"bead.[cf]*", "bead.failed", true},
+		{"bead.[", "bead.[", true},
+		{"bead.[", "bead.x", false},
+	}
+	for _, tt := range tests {
+		if got := MatchEventType(tt.pattern, tt.eventType); got != tt.want {
+			t.Errorf("MatchEventType(%q, %q) = %v, want %v", tt.pattern, tt.eventType, got, tt.want)
+		}
+	}
+}
@@ -22,9 +22,10 @@ const (
 	EventAgentRestart EventType = "agent_restart"
 
 	// Communication events
-	EventPromptSend      EventType = "prompt_send"
-	EventPromptBroadcast EventType = "prompt_broadcast"
-	EventInterrupt       EventType = "interrupt"
+	EventPromptSend        EventType = "prompt_send"
+	EventPromptBroadcast   EventType = "prompt_broadcast"
+	EventPromptUndelivered EventType = "prompt_undelivered"
+	EventInterrupt         EventType = "interrupt"
 
 	// State management events
 	EventCheckpointCreate  EventType = "checkpoint_create"
@@ -35,6 +36,18 @@ const (
 	// Template events
 	EventTemplateUse EventType = "template_use"
 
+	// Budget events
+	EventBudgetSoftLimit EventType = "budget_soft_limit"
+	EventBudgetHardLimit EventType = "budget_hard_limit"
+	EventBudgetExtended  EventType = "budget_extended"
+	EventSpendCapLevel   EventType = "spend_cap_level"
+
+	// Wind-down events
+	EventSessionWindDown EventType = "session_wind_down"
+
+	// Schedule events
+	EventScheduleRun EventType = "schedule_run"
+
 	// Error events
 	EventError EventType = "error"
 )
@@ -0,0 +1,288 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/privacy"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultJournalPath is the default location of the persisted bus event stream.
+const DefaultJournalPath = "~/.config/ntm/analytics/bus_events.jsonl"
+
+// JournalEntry is one persisted bus event. Payload holds the event exactly as
+// it was published (after redaction), so it can be decoded back into the
+// typed event listed in the catalog.
+type JournalEntry struct {
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Session   string          `json:"session,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// journalRecord is the on-disk form of a JournalEntry (without the custom
+// MarshalJSON, which emits only the payload).
+type journalRecord JournalEntry
+
+// EventType returns the event type
+func (e JournalEntry) EventType() string { return e.Type }
+
+// EventTimestamp returns the event timestamp
+func (e JournalEntry) EventTimestamp() time.Time { return e.Timestamp }
+
+// EventSession returns the session name
+func (e JournalEntry) EventSession() string { return e.Session }
+
+// MarshalJSON emits the original event payload so replayed entries look the
+// same to stream consumers as the live event did.
+func (e JournalEntry) MarshalJSON() ([]byte, error) {
+	if len(e.Payload) > 0 {
+		return e.Payload, nil
+	}
+	return json.Marshal(BaseEvent{Type: e.Type, Timestamp: e.Timestamp, Session: e.Session})
+}
+
+// Decode returns the typed event registered in the catalog for the entry's
+// type. Entries with unknown types (or undecodable payloads) are returned as
+// is, which still satisfies BusEvent.
+func (e JournalEntry) Decode() BusEvent {
+	for _, c := range catalogEntries {
+		if c.eventType != e.Type {
+			continue
+		}
+		ptr := reflect.New(reflect.TypeOf(c.payload))
+		if err := json.Unmarshal(e.Payload, ptr.Interface()); err != nil {
+			return e
+		}
+		if evt, ok := ptr.Elem().Interface().(BusEvent); ok {
+			return evt
+		}
+	}
+	return e
+}
+
+// Journal appends every event published on a bus to a JSONL file. The file is
+// created lazily on the first event so commands that publish nothing leave no
+// trace. Redaction, encryption, and privacy mode apply as for the event log.
+type Journal struct {
+	path        string
+	mu          sync.Mutex
+	file        *os.File
+	closed      bool
+	unsubscribe UnsubscribeFunc
+}
+
+// NewJournal creates a journal writing to path (DefaultJournalPath if empty).
+func NewJournal(path string) *Journal {
+	if path == "" {
+		path = util.ExpandPath(DefaultJournalPath)
+	}
+	return &Journal{path: path}
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Attach subscribes the journal to all events on bus. Calling Attach again
+// replaces the previous subscription.
+func (j *Journal) Attach(bus *EventBus) {
+	unsubscribe := bus.SubscribeAll(func(e BusEvent) {
+		_ = j.Append(e)
+	})
+	j.mu.Lock()
+	prev := j.unsubscribe
+	j.unsubscribe = unsubscribe
+	j.mu.Unlock()
+	if prev != nil {
+		prev()
+	}
+}
+
+// Append persists a single event.
+func (j *Journal) Append(e BusEvent) error {
+	if e == nil {
+		return nil
+	}
+	if err := privacy.GetDefaultManager().CanPersist(e.EventSession(), privacy.OpEventLog); err != nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+	if redacted := redactString(string(payload)); json.Valid([]byte(redacted)) {
+		payload = []byte(redacted)
+	}
+
+	data, err := json.Marshal(journalRecord{
+		Type:      e.EventType(),
+		Timestamp: e.EventTimestamp(),
+		Session:   e.EventSession(),
+		Payload:   payload,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling journal entry: %w", err)
+	}
+	data, err = encryptJSONLine(data)
+	if err != nil {
+		return fmt.Errorf("encrypting journal entry: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return nil
+	}
+	if j.file == nil {
+		if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+			return fmt.Errorf("creating journal directory: %w", err)
+		}
+		f, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("opening journal: %w", err)
+		}
+		j.file = f
+	}
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing journal entry: %w", err)
+	}
+	return nil
+}
+
+// Close detaches the journal from its bus and closes the file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	unsubscribe := j.unsubscribe
+	j.unsubscribe = nil
+	j.mu.Unlock()
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.closed = true
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// JournalFilter selects entries when reading a journal. Zero values match
+// everything.
+type JournalFilter struct {
+	Session string
+	From    time.Time
+	To      time.Time
+	Types   []string // event types or glob patterns (see MatchEventType)
+}
+
+func (f JournalFilter) matches(e JournalEntry) bool {
+	if f.Session != "" && e.Session != f.Session {
+		return false
+	}
+	if !f.From.IsZero() && e.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && e.Timestamp.After(f.To) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, pattern := range f.Types {
+		if MatchEventType(pattern, e.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadJournal loads the entries matching filter, ordered by timestamp.
+// Unreadable or malformed lines are skipped. A missing journal yields no
+// entries and no error.
+func ReadJournal(path string, filter JournalFilter) ([]JournalEntry, error) {
+	if path == "" {
+		path = util.ExpandPath(DefaultJournalPath)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("opening journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		plain, err := decryptJSONLine(line)
+		if err != nil {
+			continue
+		}
+		var record journalRecord
+		if err := json.Unmarshal(plain, &record); err != nil {
+			continue
+		}
+		if entry := JournalEntry(record); filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("reading journal: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// Default journal attached to DefaultBus
+var (
+	defaultJournalMu sync.Mutex
+	defaultJournal   *Journal
+)
+
+// EnableDefaultJournal starts persisting DefaultBus events to the default
+// journal path. It is idempotent.
+func EnableDefaultJournal() *Journal {
+	defaultJournalMu.Lock()
+	defer defaultJournalMu.Unlock()
+	if defaultJournal == nil {
+		defaultJournal = NewJournal("")
+		defaultJournal.Attach(DefaultBus)
+	}
+	return defaultJournal
+}
+
+// CloseDefaultJournal detaches and closes the default journal, if enabled.
+func CloseDefaultJournal() error {
+	defaultJournalMu.Lock()
+	j := defaultJournal
+	defaultJournal = nil
+	defaultJournalMu.Unlock()
+	if j == nil {
+		return nil
+	}
+	return j.Close()
+}
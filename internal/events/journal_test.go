@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal_AppendReadAndDecode(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "bus_events.jsonl")
+	j := NewJournal(path)
+
+	bus := NewEventBus(10)
+	j.Attach(bus)
+
+	base := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	stall := NewAgentStallEvent("proj", "cc_1", 120, "no output")
+	stall.Timestamp = base
+	other := NewAlertEvent("other", "a1", "disk", "warning", "low disk")
+	other.Timestamp = base.Add(time.Second)
+	hook := NewWebhookEvent(WebhookAgentCrashed, "proj", "1", "cc_1", "exit 137", nil)
+	hook.Timestamp = base.Add(2 * time.Second)
+
+	// Publish out of order; ReadJournal sorts by timestamp.
+	bus.PublishSync(hook)
+	bus.PublishSync(stall)
+	bus.PublishSync(other)
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	bus.PublishSync(other) // after Close: not recorded
+
+	all, err := ReadJournal(path, JournalFilter{})
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("ReadJournal() returned %d entries, want 3", len(all))
+	}
+	if all[0].Type != BusAgentStall || all[2].Type != WebhookAgentCrashed {
+		t.Errorf("entries not sorted by timestamp: %s, %s, %s", all[0].Type, all[1].Type, all[2].Type)
+	}
+
+	decoded, ok := all[0].Decode().(AgentStallEvent)
+	if !ok {
+		t.Fatalf("Decode() = %T, want AgentStallEvent", all[0].Decode())
+	}
+	if decoded.AgentID != "cc_1" || decoded.StallDuration != 120 || !decoded.Timestamp.Equal(base) {
+		t.Errorf("decoded event = %+v", decoded)
+	}
+
+	filtered, err := ReadJournal(path, JournalFilter{
+		Session: "proj",
+		From:    base.Add(time.Second),
+		Types:   []string{"agent.*"},
+	})
+	if err != nil {
+		t.Fatalf("ReadJournal(filter) error = %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Type != WebhookAgentCrashed {
+		t.Errorf("filtered entries = %+v, want only the crash webhook", filtered)
+	}
+}
+
+func TestReadJournal_MissingFileAndBadLines(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	entries, err := ReadJournal(filepath.Join(dir, "missing.jsonl"), JournalFilter{})
+	if err != nil || entries != nil {
+		t.Fatalf("missing journal = %v, %v; want nil, nil", entries, err)
+	}
+
+	path := filepath.Join(dir, "bus_events.jsonl")
+	data := "not json\n\n" + `{"type":"alert","timestamp":"2026-01-02T15:00:00Z","payload":{"type":"alert"}}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = ReadJournal(path, JournalFilter{})
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ReadJournal() = %v, %v; want one entry", entries, err)
+	}
+	if _, ok := entries[0].Decode().(AlertEvent); !ok {
+		t.Errorf("Decode() = %T, want AlertEvent", entries[0].Decode())
+	}
+	encoded, err := json.Marshal(entries[0])
+	if err != nil || string(encoded) != `{"type":"alert"}` {
+		t.Errorf("MarshalJSON() = %s, %v; want original payload", encoded, err)
+	}
+}
+
+func TestReplay_ScalesGapsAndPreservesOrder(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	entries := []JournalEntry{
+		{Type: "a", Timestamp: base},
+		{Type: "b", Timestamp: base.Add(10 * time.Second)},
+		{Type: "c", Timestamp: base.Add(10 * time.Minute)},
+	}
+
+	bus := NewEventBus(10)
+	var seen []string
+	bus.Subscribe("*", func(e BusEvent) { seen = append(seen, e.EventType()) })
+
+	var delays []time.Duration
+	n, err := Replay(context.Background(), bus, entries, ReplayOptions{
+		Speed:  10,
+		MaxGap: 5 * time.Second,
+		sleep: func(ctx context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		},
+	})
+	if err != nil || n != 3 {
+		t.Fatalf("Replay() = %d, %v; want 3, nil", n, err)
+	}
+	if len(delays) != 2 || delays[0] != time.Second || delays[1] != 5*time.Second {
+		t.Errorf("delays = %v, want [1s 5s]", delays)
+	}
+	if len(seen) != 3 || seen[0] != "a" || seen[1] != "b" || seen[2] != "c" {
+		t.Errorf("replayed order = %v", seen)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	n, err = Replay(ctx, bus, entries, ReplayOptions{})
+	if err == nil || n != 0 {
+		t.Errorf("Replay(cancelled) = %d, %v; want 0 and an error", n, err)
+	}
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// ReplayOptions controls how journal entries are re-emitted.
+type ReplayOptions struct {
+	// Speed scales the original inter-event gaps: 10 replays ten times
+	// faster than real time. Zero or negative replays without delay.
+	Speed float64
+
+	// MaxGap caps the (scaled) delay between two events so idle periods do
+	// not stall a replay. Zero means no cap.
+	MaxGap time.Duration
+
+	// Typed decodes entries into their cataloged event types before
+	// publishing, so typed subscribers receive them.
+	Typed bool
+
+	// sleep is overridable for tests.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Replay publishes entries to bus in order, preserving their relative timing
+// scaled by opts.Speed. Each event is delivered synchronously so subscribers
+// observe the same ordering as the recording. It returns the number of events
+// published, stopping early if ctx is cancelled.
+func Replay(ctx context.Context, bus *EventBus, entries []JournalEntry, opts ReplayOptions) (int, error) {
+	if bus == nil {
+		bus = DefaultBus
+	}
+	sleep := opts.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	published := 0
+	for i, entry := range entries {
+		if i > 0 && opts.Speed > 0 {
+			gap := entry.Timestamp.Sub(entries[i-1].Timestamp)
+			delay := time.Duration(float64(gap) / opts.Speed)
+			if opts.MaxGap > 0 && delay > opts.MaxGap {
+				delay = opts.MaxGap
+			}
+			if delay > 0 {
+				if err := sleep(ctx, delay); err != nil {
+					return published, err
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return published, err
+		}
+
+		var event BusEvent = entry
+		if opts.Typed {
+			event = entry.Decode()
+		}
+		bus.PublishSync(event)
+		published++
+	}
+	return published, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
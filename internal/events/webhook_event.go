@@ -4,23 +4,29 @@ import "time"
 
 // Webhook event types (shared with .ntm.yaml webhooks config).
 const (
-	WebhookSessionCreated = "session.created"
-	WebhookSessionKilled  = "session.killed"
-	WebhookSessionEnded   = "session.ended" // Alias for session.killed (legacy/alternate naming)
-	WebhookAgentStarted   = "agent.started"
-	WebhookAgentStopped   = "agent.stopped"
-	WebhookAgentError     = "agent.error"
-	WebhookAgentCrashed   = "agent.crashed"
-	WebhookAgentRestarted = "agent.restarted"
-	WebhookAgentIdle      = "agent.idle"
-	WebhookAgentBusy      = "agent.busy"
-	WebhookAgentRateLimit = "agent.rate_limit"
-	WebhookAgentCompleted = "agent.completed"
-	WebhookRotationNeeded = "rotation.needed"
-	WebhookHealthDegraded = "health.degraded"
-	WebhookBeadAssigned   = "bead.assigned"
-	WebhookBeadCompleted  = "bead.completed"
-	WebhookBeadFailed     = "bead.failed"
+	WebhookSessionCreated        = "session.created"
+	WebhookSessionKilled         = "session.killed"
+	WebhookSessionEnded          = "session.ended" // Alias for session.killed (legacy/alternate naming)
+	WebhookAgentStarted          = "agent.started"
+	WebhookAgentStopped          = "agent.stopped"
+	WebhookAgentError            = "agent.error"
+	WebhookAgentCrashed          = "agent.crashed"
+	WebhookAgentRestarted        = "agent.restarted"
+	WebhookAgentIdle             = "agent.idle"
+	WebhookAgentBusy             = "agent.busy"
+	WebhookAgentRateLimit        = "agent.rate_limit"
+	WebhookAgentResumed          = "agent.resumed"
+	WebhookAgentCompleted        = "agent.completed"
+	WebhookAgentProgress         = "agent.progress"
+	WebhookRotationNeeded        = "rotation.needed"
+	WebhookContextReset          = "context.reset"
+	WebhookHealthDegraded        = "health.degraded"
+	WebhookBeadAssigned          = "bead.assigned"
+	WebhookBeadCompleted         = "bead.completed"
+	WebhookBeadFailed            = "bead.failed"
+	WebhookTaskCandidateComplete = "task.candidate_complete"
+	WebhookPromptUndelivered     = "prompt.undelivered"
+	WebhookStorageLowDisk        = "storage.low_disk"
 )
 
 // WebhookEvent is a BusEvent intended for downstream dispatch to webhooks and
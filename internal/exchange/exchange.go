@@ -0,0 +1,190 @@
+// Package exchange pairs prompts sent to agents with the output burst that
+// answers them, producing PromptExchange records with response latency and
+// output size.
+//
+// Pairing works from pane captures: after a prompt is sent, the first
+// capture that differs from the pre-send baseline starts the response, and
+// the response ends once the pane has been unchanged for a quiet period.
+package exchange
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultQuietPeriod is how long a pane must stay unchanged before a
+	// response is considered finished.
+	DefaultQuietPeriod = 5 * time.Second
+
+	// DefaultMaxWait bounds how long an exchange stays open.
+	DefaultMaxWait = 10 * time.Minute
+)
+
+// Exchange outcomes.
+const (
+	OutcomeCompleted  = "completed"   // output started and went quiet
+	OutcomeNoResponse = "no_response" // no output before MaxWait
+	OutcomeTimeout    = "timeout"     // still producing output at MaxWait
+)
+
+// PromptExchange is one prompt paired with the agent's response.
+type PromptExchange struct {
+	// ID is the prompt history ID plus the pane, unique per exchange.
+	ID        string `json:"id"`
+	PromptID  string `json:"prompt_id,omitempty"`
+	Session   string `json:"session"`
+	Pane      string `json:"pane"`
+	AgentType string `json:"agent_type,omitempty"`
+
+	PromptBytes int       `json:"prompt_bytes"`
+	SentAt      time.Time `json:"sent_at"`
+	// FirstOutputAt is the capture time of the first changed output.
+	FirstOutputAt *time.Time `json:"first_output_at,omitempty"`
+	// CompletedAt is the time of the last change before the quiet period.
+	CompletedAt time.Time `json:"completed_at"`
+
+	// LatencyMs is the time from send to the first output (0 if none).
+	LatencyMs int64 `json:"latency_ms"`
+	// DurationMs is the time from send to the end of the output burst.
+	DurationMs int64 `json:"duration_ms"`
+
+	OutputLines int    `json:"output_lines"`
+	OutputBytes int    `json:"output_bytes"`
+	Outcome     string `json:"outcome"`
+}
+
+// Responded reports whether the agent produced any output.
+func (e PromptExchange) Responded() bool {
+	return e.FirstOutputAt != nil
+}
+
+// Sample is a pane capture taken at a point in time.
+type Sample struct {
+	At      time.Time
+	Content string
+}
+
+// Pending is an exchange waiting for its response to finish.
+type Pending struct {
+	ex         PromptExchange
+	baseline   string
+	last       string
+	lastChange time.Time
+	quiet      time.Duration
+	maxWait    time.Duration
+}
+
+// Start opens an exchange for a prompt sent at ex.SentAt. baseline is the
+// pane content captured before the prompt was sent.
+func Start(ex PromptExchange, baseline string, quiet, maxWait time.Duration) *Pending {
+	if quiet <= 0 {
+		quiet = DefaultQuietPeriod
+	}
+	if maxWait <= 0 {
+		maxWait = DefaultMaxWait
+	}
+	return &Pending{
+		ex:       ex,
+		baseline: baseline,
+		last:     baseline,
+		quiet:    quiet,
+		maxWait:  maxWait,
+	}
+}
+
+// Observe feeds a capture to the exchange. It returns the finished exchange
+// and true once the response has gone quiet or MaxWait has passed.
+func (p *Pending) Observe(s Sample) (PromptExchange, bool) {
+	if s.At.Before(p.ex.SentAt) {
+		return PromptExchange{}, false
+	}
+	if s.Content != p.last {
+		if p.ex.FirstOutputAt == nil {
+			at := s.At
+			p.ex.FirstOutputAt = &at
+		}
+		p.last = s.Content
+		p.lastChange = s.At
+	}
+
+	switch {
+	case p.ex.FirstOutputAt != nil && s.At.Sub(p.lastChange) >= p.quiet:
+		return p.finish(OutcomeCompleted), true
+	case s.At.Sub(p.ex.SentAt) >= p.maxWait:
+		if p.ex.FirstOutputAt == nil {
+			p.lastChange = s.At
+			return p.finish(OutcomeNoResponse), true
+		}
+		return p.finish(OutcomeTimeout), true
+	}
+	return PromptExchange{}, false
+}
+
+func (p *Pending) finish(outcome string) PromptExchange {
+	ex := p.ex
+	ex.Outcome = outcome
+	ex.CompletedAt = p.lastChange
+	ex.DurationMs = p.lastChange.Sub(ex.SentAt).Milliseconds()
+	if ex.FirstOutputAt != nil {
+		ex.LatencyMs = ex.FirstOutputAt.Sub(ex.SentAt).Milliseconds()
+		added := NewOutput(p.baseline, p.last)
+		ex.OutputLines = len(added)
+		for _, line := range added {
+			ex.OutputBytes += len(line) + 1
+		}
+	}
+	return ex
+}
+
+// NewOutput returns the lines of current that were not already in
+// baseline. Captures are a scrolling window, so the longest suffix of
+// baseline that reappears as a prefix of current is treated as old output.
+// The last baseline line is usually the input prompt the agent is about to
+// overwrite, so a second pass ignores it. Full-screen redraws make this an
+// estimate rather than an exact diff.
+func NewOutput(baseline, current string) []string {
+	old := captureLines(baseline)
+	cur := captureLines(current)
+	if k := overlap(old, cur); k > 0 {
+		return cur[k:]
+	}
+	if len(old) > 1 {
+		if k := overlap(old[:len(old)-1], cur); k > 0 {
+			return cur[k:]
+		}
+	}
+	return cur
+}
+
+// overlap returns the length of the longest suffix of old that is a prefix
+// of cur.
+func overlap(old, cur []string) int {
+	for k := min(len(old), len(cur)); k > 0; k-- {
+		if equalLines(old[len(old)-k:], cur[:k]) {
+			return k
+		}
+	}
+	return 0
+}
+
+func captureLines(s string) []string {
+	s = strings.TrimRight(s, " \t\r\n")
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	return lines
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package exchange
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+)
+
+func TestPending_CompletesAfterQuietPeriod(t *testing.T) {
+	t.Parallel()
+	sent := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	p := Start(PromptExchange{ID: "1:2", SentAt: sent}, "old\n> ", 5*time.Second, time.Minute)
+
+	steps := []struct {
+		offset  time.Duration
+		content string
+		done    bool
+	}{
+		{1 * time.Second, "old\n> ", false},
+		{3 * time.Second, "old\n> fix it\nworking", false},
+		{6 * time.Second, "old\n> fix it\nworking\ndone", false},
+		{10 * time.Second, "old\n> fix it\nworking\ndone", false},
+		{11 * time.Second, "old\n> fix it\nworking\ndone", true},
+	}
+	var ex PromptExchange
+	for _, s := range steps {
+		var done bool
+		ex, done = p.Observe(Sample{At: sent.Add(s.offset), Content: s.content})
+		if done != s.done {
+			t.Fatalf("at +%s: done = %v, want %v", s.offset, done, s.done)
+		}
+	}
+
+	if ex.Outcome != OutcomeCompleted {
+		t.Errorf("outcome = %s", ex.Outcome)
+	}
+	if ex.LatencyMs != 3000 || ex.DurationMs != 6000 {
+		t.Errorf("latency = %d, duration = %d; want 3000, 6000", ex.LatencyMs, ex.DurationMs)
+	}
+	if ex.OutputLines != 3 || ex.OutputBytes != len("> fix it\nworking\ndone\n") {
+		t.Errorf("output = %d lines, %d bytes", ex.OutputLines, ex.OutputBytes)
+	}
+}
+
+func TestPending_NoResponseAndTimeout(t *testing.T) {
+	t.Parallel()
+	sent := time.Now()
+
+	silent := Start(PromptExchange{SentAt: sent}, "x", time.Second, 10*time.Second)
+	if _, done := silent.Observe(Sample{At: sent.Add(5 * time.Second), Content: "x"}); done {
+		t.Fatal("silent exchange finished early")
+	}
+	ex, done := silent.Observe(Sample{At: sent.Add(10 * time.Second), Content: "x"})
+	if !done || ex.Outcome != OutcomeNoResponse || ex.Responded() {
+		t.Fatalf("silent exchange = %+v, done=%v", ex, done)
+	}
+
+	busy := Start(PromptExchange{SentAt: sent}, "", time.Second, 3*time.Second)
+	busy.Observe(Sample{At: sent.Add(time.Second), Content: "a"})
+	busy.Observe(Sample{At: sent.Add(1500 * time.Millisecond), Content: "ab"})
+	busy.Observe(Sample{At: sent.Add(2500 * time.Millisecond), Content: "abc"})
+	ex, done = busy.Observe(Sample{At: sent.Add(3 * time.Second), Content: "abcd"})
+	if !done || ex.Outcome != OutcomeTimeout {
+		t.Fatalf("busy exchange = %+v, done=%v", ex, done)
+	}
+}
+
+func TestNewOutput(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name, baseline, current string
+		want                    []string
+	}{
+		{"appended", "a\nb", "a\nb\nc", []string{"c"}},
+		{"scrolled", "a\nb\nc", "b\nc\nd\ne", []string{"d", "e"}},
+		{"prompt line rewritten", "a\n> ", "a\n> go\nout", []string{"> go", "out"}},
+		{"unrelated", "x", "y\nz", []string{"y", "z"}},
+		{"unchanged", "a\nb\n\n", "a\nb", []string{}},
+	}
+	for _, tt := range tests {
+		got := NewOutput(tt.baseline, tt.current)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: NewOutput = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStoreAndSummarize(t *testing.T) {
+	t.Parallel()
+	store := NewStore(filepath.Join(t.TempDir(), "exchanges.jsonl"))
+	base := time.Now().Add(-time.Hour)
+	first := base.Add(time.Second)
+	for i, latency := range []int64{1000, 2000, 3000, 10000} {
+		at := first.Add(time.Duration(i) * time.Minute)
+		out := at.Add(time.Duration(latency) * time.Millisecond)
+		ex := PromptExchange{ID: string(rune('a' + i)), Session: "proj", AgentType: "claude", SentAt: at,
+			FirstOutputAt: &out, LatencyMs: latency, DurationMs: latency * 2, OutputBytes: 100, Outcome: OutcomeCompleted}
+		if err := store.Append(ex); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Append(PromptExchange{ID: "z", Session: "proj", AgentType: "claude", SentAt: first, Outcome: OutcomeNoResponse}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(PromptExchange{ID: "o", Session: "other", AgentType: "codex", SentAt: first}); err != nil {
+		t.Fatal(err)
+	}
+
+	exs, err := store.Query(Query{Session: "proj"})
+	if err != nil || len(exs) != 5 {
+		t.Fatalf("query = %d exchanges, %v", len(exs), err)
+	}
+	if limited, _ := store.Query(Query{Session: "proj", Limit: 2}); len(limited) != 2 || limited[1].ID != "z" {
+		t.Errorf("limit should keep the most recent exchanges, got %+v", limited)
+	}
+
+	sums := Summarize(exs)
+	if len(sums) != 1 {
+		t.Fatalf("summaries = %+v", sums)
+	}
+	s := sums[0]
+	if s.Exchanges != 5 || s.Responded != 4 || s.ResponseRate != 0.8 {
+		t.Errorf("counts = %+v", s)
+	}
+	if s.AvgLatencyMs != 4000 || s.P50LatencyMs != 2000 || s.P95LatencyMs != 10000 || s.AvgOutputBytes != 100 {
+		t.Errorf("latency stats = %+v", s)
+	}
+
+	var m scoring.RawMetrics
+	s.ApplyTo(&m)
+	if m.SuccessCount != 4 || m.ErrorCount != 1 || m.AvgResponseLatencyMs != 4000 {
+		t.Errorf("raw metrics = %+v", m)
+	}
+	if got := BaselineLatencyMs(exs); got != 2000 {
+		t.Errorf("BaselineLatencyMs = %d, want the median 2000", got)
+	}
+}
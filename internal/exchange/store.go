@@ -0,0 +1,196 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultPath is where exchanges are persisted.
+const DefaultPath = "~/.config/ntm/analytics/exchanges.jsonl"
+
+// Store persists exchanges as JSONL.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns a store at path (DefaultPath if empty).
+func NewStore(path string) *Store {
+	if path == "" {
+		path = DefaultPath
+	}
+	return &Store{path: util.ExpandPath(path)}
+}
+
+// Path returns the store file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append records an exchange.
+func (s *Store) Append(ex PromptExchange) error {
+	data, err := json.Marshal(ex)
+	if err != nil {
+		return fmt.Errorf("marshaling exchange: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating exchange directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening exchanges: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing exchange: %w", err)
+	}
+	return f.Close()
+}
+
+// Query filters stored exchanges.
+type Query struct {
+	// Since filters to exchanges sent after this time
+	Since time.Time
+	// Session filters by session name (empty = all)
+	Session string
+	// AgentType filters by agent type (empty = all)
+	AgentType string
+	// Limit keeps only the most recent N exchanges (0 = unlimited)
+	Limit int
+}
+
+// Query returns stored exchanges matching q, oldest first.
+func (s *Store) Query(q Query) ([]PromptExchange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []PromptExchange
+	res, err := jsonlutil.ScanFile(s.path, jsonlutil.Options{}, func(_ int, line []byte) error {
+		var ex PromptExchange
+		if err := json.Unmarshal(line, &ex); err != nil {
+			return nil // Skip malformed
+		}
+		if !q.Since.IsZero() && !ex.SentAt.After(q.Since) {
+			return nil
+		}
+		if q.Session != "" && ex.Session != q.Session {
+			return nil
+		}
+		if q.AgentType != "" && ex.AgentType != q.AgentType {
+			return nil
+		}
+		out = append(out, ex)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning exchanges: %w", err)
+	}
+	if res != nil && !res.OK() {
+		slog.Debug("skipped corrupt exchange records", "path", s.path, "count", len(res.Bad))
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
+
+// AgentSummary aggregates exchanges for one agent type.
+type AgentSummary struct {
+	AgentType      string  `json:"agent_type"`
+	Exchanges      int     `json:"exchanges"`
+	Responded      int     `json:"responded"`
+	AvgLatencyMs   int64   `json:"avg_latency_ms"`
+	P50LatencyMs   int64   `json:"p50_latency_ms"`
+	P95LatencyMs   int64   `json:"p95_latency_ms"`
+	AvgDurationMs  int64   `json:"avg_duration_ms"`
+	AvgOutputBytes int     `json:"avg_output_bytes"`
+	ResponseRate   float64 `json:"response_rate"`
+}
+
+// Summarize groups exchanges by agent type, sorted by type.
+func Summarize(exchanges []PromptExchange) []AgentSummary {
+	byType := make(map[string][]PromptExchange)
+	for _, ex := range exchanges {
+		t := ex.AgentType
+		if t == "" {
+			t = "unknown"
+		}
+		byType[t] = append(byType[t], ex)
+	}
+	out := make([]AgentSummary, 0, len(byType))
+	for t, exs := range byType {
+		out = append(out, summarizeAgent(t, exs))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AgentType < out[j].AgentType })
+	return out
+}
+
+func summarizeAgent(agentType string, exs []PromptExchange) AgentSummary {
+	sum := AgentSummary{AgentType: agentType, Exchanges: len(exs)}
+	var latencies []int64
+	var duration int64
+	var bytes int
+	for _, ex := range exs {
+		if !ex.Responded() {
+			continue
+		}
+		latencies = append(latencies, ex.LatencyMs)
+		duration += ex.DurationMs
+		bytes += ex.OutputBytes
+	}
+	sum.Responded = len(latencies)
+	if sum.Exchanges > 0 {
+		sum.ResponseRate = math.Round(float64(sum.Responded)/float64(sum.Exchanges)*1000) / 1000
+	}
+	if len(latencies) == 0 {
+		return sum
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total int64
+	for _, l := range latencies {
+		total += l
+	}
+	n := int64(len(latencies))
+	sum.AvgLatencyMs = total / n
+	sum.P50LatencyMs = percentile(latencies, 0.50)
+	sum.P95LatencyMs = percentile(latencies, 0.95)
+	sum.AvgDurationMs = duration / n
+	sum.AvgOutputBytes = bytes / len(latencies)
+	return sum
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []int64, p float64) int64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// ApplyTo feeds the summary into raw scoring metrics: prompts that got no
+// response count as errors, and the mean latency drives time efficiency.
+func (s AgentSummary) ApplyTo(m *scoring.RawMetrics) {
+	m.SuccessCount += s.Responded
+	m.ErrorCount += s.Exchanges - s.Responded
+	m.AvgResponseLatencyMs = int(s.AvgLatencyMs)
+}
+
+// BaselineLatencyMs returns the median response latency of exchanges, as the
+// latency an agent is expected to match (0 if none responded).
+func BaselineLatencyMs(exchanges []PromptExchange) int64 {
+	return summarizeAgent("", exchanges).P50LatencyMs
+}
@@ -42,6 +42,7 @@ const (
 	FormatSVG   ExportFormat = "svg"
 	FormatPNG   ExportFormat = "png"
 	FormatJSONL ExportFormat = "jsonl"
+	FormatHTML  ExportFormat = "html"
 )
 
 // ExportOptions configures the timeline export.
@@ -0,0 +1,304 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+// sessionLaneID is the lane for markers that are not tied to one agent.
+const sessionLaneID = "session"
+
+// htmlMarkerOrder is the order marker kinds appear in the summary and legend.
+var htmlMarkerOrder = []state.MarkerType{
+	state.MarkerPrompt,
+	state.MarkerConflict,
+	state.MarkerRateLimit,
+	state.MarkerHandoff,
+	state.MarkerCost,
+	state.MarkerError,
+	state.MarkerCompletion,
+	state.MarkerStart,
+	state.MarkerStop,
+}
+
+// htmlTimeline is the view model for the HTML template.
+type htmlTimeline struct {
+	Session   string
+	Start     string
+	End       string
+	Duration  string
+	Generated string
+	Theme     ExportTheme
+	Lanes     []htmlLane
+	Ticks     []htmlTick
+	Markers   []htmlMarker // chronological, for the event table
+	Counts    []htmlCount
+	States    []htmlStateLegend
+}
+
+type htmlLane struct {
+	ID       string
+	Color    string
+	Segments []htmlSegment
+	Pauses   []htmlSegment
+	Markers  []htmlMarker
+}
+
+type htmlSegment struct {
+	Left  float64
+	Width float64
+	Color string
+	Title string
+}
+
+type htmlMarker struct {
+	Left    float64
+	Kind    string
+	Symbol  string
+	Time    string
+	Lane    string
+	Message string
+}
+
+type htmlTick struct {
+	Left  float64
+	Label string
+}
+
+type htmlCount struct {
+	Kind   string
+	Symbol string
+	Count  int
+}
+
+type htmlStateLegend struct {
+	State string
+	Color string
+}
+
+// ExportHTML renders a self-contained HTML timeline: one lane per agent
+// showing its state over time (working segments are its output bursts),
+// overlaid with markers for prompts, conflicts, rate-limit pauses,
+// handoffs and cost, followed by a chronological event table. Markers
+// without an AgentID go on a session lane.
+func (e *TimelineExporter) ExportHTML(events []state.AgentEvent, markers []state.TimelineMarker) ([]byte, error) {
+	if len(events) == 0 && len(markers) == 0 {
+		return nil, fmt.Errorf("no events to export")
+	}
+
+	// The time range covers markers too, so a prompt sent before the first
+	// state change is still on the chart.
+	opts := e.options
+	if opts.Since.IsZero() || opts.Until.IsZero() {
+		var first, last time.Time
+		observe := func(t time.Time) {
+			if first.IsZero() || t.Before(first) {
+				first = t
+			}
+			if last.IsZero() || t.After(last) {
+				last = t
+			}
+		}
+		for _, ev := range events {
+			observe(ev.Timestamp)
+		}
+		for _, m := range markers {
+			observe(m.Timestamp)
+		}
+		if opts.Since.IsZero() {
+			opts.Since = first
+		}
+		if opts.Until.IsZero() {
+			opts.Until = last
+		}
+	}
+	data := (&TimelineExporter{options: opts}).prepareData(events)
+	start, duration := data.TimeStart, data.Duration
+
+	pct := func(t time.Time) float64 {
+		p := float64(t.Sub(start)) / float64(duration) * 100
+		if p < 0 {
+			return 0
+		}
+		if p > 100 {
+			return 100
+		}
+		return p
+	}
+	barWidth := float64(data.BarWidth)
+	leftMargin := float64(data.LeftMargin)
+
+	view := &htmlTimeline{
+		Session:   data.SessionName,
+		Start:     start.Local().Format("2006-01-02 15:04:05 MST"),
+		End:       data.TimeEnd.Local().Format("2006-01-02 15:04:05 MST"),
+		Duration:  formatTimelineDuration(data.TimeEnd.Sub(start)),
+		Generated: data.ExportTime.Local().Format(time.RFC3339),
+		Theme:     data.Theme,
+	}
+
+	lanes := make(map[string]*htmlLane)
+	var laneOrder []string
+	lane := func(id string) *htmlLane {
+		if l, ok := lanes[id]; ok {
+			return l
+		}
+		l := &htmlLane{ID: id, Color: e.getAgentColor(id)}
+		lanes[id] = l
+		laneOrder = append(laneOrder, id)
+		return l
+	}
+
+	for _, track := range data.Agents {
+		l := lane(track.AgentID)
+		for _, seg := range track.Segments {
+			l.Segments = append(l.Segments, htmlSegment{
+				Left:  (seg.XStart - leftMargin) / barWidth * 100,
+				Width: seg.Width / barWidth * 100,
+				Color: seg.Color,
+				Title: fmt.Sprintf("%s %s–%s (%s)", seg.State,
+					seg.StartTime.Local().Format("15:04:05"), seg.EndTime.Local().Format("15:04:05"),
+					formatTimelineDuration(seg.Duration)),
+			})
+		}
+	}
+
+	sorted := make([]state.TimelineMarker, 0, len(markers))
+	for _, m := range markers {
+		if m.Timestamp.Before(start) || m.Timestamp.After(data.TimeEnd) {
+			continue
+		}
+		sorted = append(sorted, m)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	counts := make(map[state.MarkerType]int)
+	for _, m := range sorted {
+		laneID := m.AgentID
+		if laneID == "" {
+			laneID = sessionLaneID
+		}
+		hm := htmlMarker{
+			Left:    pct(m.Timestamp),
+			Kind:    string(m.Type),
+			Symbol:  m.Type.Symbol(),
+			Time:    m.Timestamp.Local().Format("15:04:05"),
+			Lane:    laneID,
+			Message: m.Message,
+		}
+		l := lane(laneID)
+		l.Markers = append(l.Markers, hm)
+		if m.Type == state.MarkerRateLimit {
+			if secs, err := strconv.Atoi(m.Details["wait_seconds"]); err == nil && secs > 0 {
+				end := m.Timestamp.Add(time.Duration(secs) * time.Second)
+				l.Pauses = append(l.Pauses, htmlSegment{
+					Left:  hm.Left,
+					Width: pct(end) - hm.Left,
+					Title: fmt.Sprintf("rate-limit pause %s–%s", hm.Time, end.Local().Format("15:04:05")),
+				})
+			}
+		}
+		view.Markers = append(view.Markers, hm)
+		counts[m.Type]++
+	}
+
+	// Agent lanes sorted by ID, the session lane last.
+	sort.SliceStable(laneOrder, func(i, j int) bool {
+		if (laneOrder[i] == sessionLaneID) != (laneOrder[j] == sessionLaneID) {
+			return laneOrder[j] == sessionLaneID
+		}
+		return laneOrder[i] < laneOrder[j]
+	})
+	for _, id := range laneOrder {
+		view.Lanes = append(view.Lanes, *lanes[id])
+	}
+
+	for _, tick := range timelineTicks(start, start.Add(duration), 6) {
+		view.Ticks = append(view.Ticks, htmlTick{Left: pct(tick), Label: tick.Local().Format("15:04:05")})
+	}
+	for _, kind := range htmlMarkerOrder {
+		if counts[kind] > 0 {
+			view.Counts = append(view.Counts, htmlCount{Kind: string(kind), Symbol: kind.Symbol(), Count: counts[kind]})
+		}
+	}
+	for _, st := range []state.TimelineState{state.TimelineWorking, state.TimelineWaiting, state.TimelineIdle, state.TimelineError, state.TimelineStopped} {
+		view.States = append(view.States, htmlStateLegend{State: string(st), Color: e.getStateColor(st)})
+	}
+
+	var buf bytes.Buffer
+	if err := timelineHTMLTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var timelineHTMLTemplate = template.Must(template.New("timeline").Funcs(template.FuncMap{
+	"pct": func(f float64) template.CSS { return template.CSS(strconv.FormatFloat(f, 'f', 3, 64) + "%") },
+	"css": func(s string) template.CSS { return template.CSS(s) },
+}).Parse(timelineHTML))
+
+const timelineHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ntm timeline{{if .Session}} – {{.Session}}{{end}}</title>
+<style>
+  body { margin: 0; padding: 24px; font: 13px/1.4 ui-monospace, SFMono-Regular, Menlo, monospace;
+         background: {{css .Theme.BackgroundColor}}; color: {{css .Theme.TextColor}}; }
+  h1 { font-size: 18px; margin: 0 0 4px; }
+  .meta { color: {{css .Theme.AxisColor}}; margin-bottom: 16px; }
+  .summary span, .legend span { margin-right: 16px; white-space: nowrap; }
+  .swatch { display: inline-block; width: 10px; height: 10px; margin-right: 4px; vertical-align: middle; }
+  .chart { margin: 16px 0 24px; }
+  .row { display: flex; align-items: center; height: 34px; }
+  .label { width: 140px; flex: none; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+  .track { position: relative; flex: 1; height: 24px; background: {{css .Theme.StoppedColor}}; border-radius: 3px; }
+  .seg { position: absolute; top: 0; height: 100%; }
+  .pause { position: absolute; top: 0; height: 100%; opacity: .6;
+           background: repeating-linear-gradient(45deg, {{css .Theme.WaitingColor}} 0 4px, transparent 4px 8px); }
+  .marker { position: absolute; top: -2px; transform: translateX(-50%); font-size: 16px; cursor: default;
+            text-shadow: 0 0 3px {{css .Theme.BackgroundColor}}; }
+  .axis { position: relative; height: 20px; margin-left: 140px; color: {{css .Theme.AxisColor}}; }
+  .tick { position: absolute; transform: translateX(-50%); }
+  .k-prompt { color: {{css .Theme.ClaudeColor}}; }
+  .k-conflict, .k-error { color: {{css .Theme.ErrorColor}}; }
+  .k-rate_limit { color: {{css .Theme.WaitingColor}}; }
+  .k-handoff { color: {{css .Theme.CodexColor}}; }
+  .k-cost { color: {{css .Theme.WorkingColor}}; }
+  table { border-collapse: collapse; width: 100%; }
+  th, td { text-align: left; padding: 3px 8px; border-bottom: 1px solid {{css .Theme.StoppedColor}}; vertical-align: top; }
+  th { color: {{css .Theme.AxisColor}}; font-weight: normal; }
+  td.msg { white-space: pre-wrap; word-break: break-word; }
+</style>
+</head>
+<body>
+<h1>{{if .Session}}{{.Session}}{{else}}Session{{end}} timeline</h1>
+<div class="meta">{{.Start}} → {{.End}} ({{.Duration}}) · generated {{.Generated}} by ntm</div>
+{{if .Counts}}<div class="summary">{{range .Counts}}<span class="k-{{.Kind}}">{{.Symbol}} {{.Count}} {{.Kind}}</span>{{end}}</div>{{end}}
+<div class="legend">{{range .States}}<span><i class="swatch" style="background: {{css .Color}}"></i>{{.State}}</span>{{end}}<span><i class="swatch pause" style="position: static; display: inline-block"></i>rate-limit pause</span></div>
+
+<div class="chart">
+{{range .Lanes}}  <div class="row">
+    <div class="label" style="color: {{css .Color}}" title="{{.ID}}">{{.ID}}</div>
+    <div class="track">
+{{range .Segments}}      <div class="seg" style="left: {{pct .Left}}; width: {{pct .Width}}; background: {{css .Color}}" title="{{.Title}}"></div>
+{{end}}{{range .Pauses}}      <div class="pause" style="left: {{pct .Left}}; width: {{pct .Width}}" title="{{.Title}}"></div>
+{{end}}{{range .Markers}}      <span class="marker k-{{.Kind}}" style="left: {{pct .Left}}" title="{{.Time}} {{.Kind}}: {{.Message}}">{{.Symbol}}</span>
+{{end}}    </div>
+  </div>
+{{end}}  <div class="axis">{{range .Ticks}}<span class="tick" style="left: {{pct .Left}}">{{.Label}}</span>{{end}}</div>
+</div>
+
+{{if .Markers}}<table>
+  <tr><th>Time</th><th>Lane</th><th>Event</th><th>Details</th></tr>
+{{range .Markers}}  <tr><td>{{.Time}}</td><td>{{.Lane}}</td><td class="k-{{.Kind}}">{{.Symbol}} {{.Kind}}</td><td class="msg">{{.Message}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`
@@ -608,3 +608,62 @@ func BenchmarkExportPNG(b *testing.B) {
 		}
 	}
 }
+
+func TestExportHTML(t *testing.T) {
+	events := createTestEvents()
+	start := events[0].Timestamp
+	markers := []state.TimelineMarker{
+		{AgentID: "cc_1", Type: state.MarkerPrompt, Timestamp: start.Add(time.Minute), Message: "Fix <the> tests"},
+		{AgentID: "cc_1", Type: state.MarkerRateLimit, Timestamp: start.Add(10 * time.Minute), Details: map[string]string{"wait_seconds": "120"}},
+		{Type: state.MarkerCost, Timestamp: start.Add(20 * time.Minute), Message: "Budget soft limit"},
+		{Type: state.MarkerConflict, Timestamp: start.Add(-time.Hour), Message: "outside range"},
+	}
+
+	opts := DefaultExportOptions()
+	opts.SessionName = "testproject"
+	opts.Since = start
+	exporter := NewTimelineExporter(opts)
+	data, err := exporter.ExportHTML(events, markers)
+	if err != nil {
+		t.Fatalf("ExportHTML failed: %v", err)
+	}
+	html := string(data)
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"testproject timeline",
+		`class="marker k-prompt"`,
+		"Fix &lt;the&gt; tests", // escaped
+		`class="pause"`,
+		">session<",  // lane for session-wide markers
+		"▶ 1 prompt", // summary counts
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(html, "outside range") {
+		t.Error("marker outside the time range was rendered")
+	}
+	if strings.Contains(html, "<script") || strings.Contains(html, "http://") || strings.Contains(html, "https://") {
+		t.Error("HTML is not self-contained")
+	}
+}
+
+func TestExportHTML_MarkersOnly(t *testing.T) {
+	now := time.Now()
+	exporter := NewTimelineExporter(DefaultExportOptions())
+	data, err := exporter.ExportHTML(nil, []state.TimelineMarker{
+		{AgentID: "cod_1", Type: state.MarkerHandoff, Timestamp: now, Message: "context reset"},
+	})
+	if err != nil {
+		t.Fatalf("ExportHTML failed: %v", err)
+	}
+	if !strings.Contains(string(data), "cod_1") {
+		t.Error("lane for marker-only agent missing")
+	}
+
+	if _, err := exporter.ExportHTML(nil, nil); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
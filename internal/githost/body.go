@@ -0,0 +1,163 @@
+package githost
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Conflict is a warning about a file in the pull request that other agents
+// may also be changing.
+type Conflict struct {
+	Path    string   `json:"path"`
+	Reason  string   `json:"reason"`
+	Agents  []string `json:"agents,omitempty"`
+	Details string   `json:"details,omitempty"`
+}
+
+// Comment renders the review comment posted on the conflicting file.
+func (c Conflict) Comment() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠️ **Possible conflict** (%s)", c.Reason)
+	if len(c.Agents) > 0 {
+		fmt.Fprintf(&b, ": also touched by %s", strings.Join(c.Agents, ", "))
+	}
+	b.WriteString(".")
+	if c.Details != "" {
+		b.WriteString("\n\n" + c.Details)
+	}
+	b.WriteString("\n\n_Posted by ntm. Coordinate with the other agents before merging._")
+	return b.String()
+}
+
+// BranchConflicts reports files changed on this branch that are also
+// changed on other agents' branches. others maps an agent name to the files
+// its branch changes.
+func BranchConflicts(changed []string, others map[string][]string) []Conflict {
+	touched := make(map[string][]string)
+	for agent, files := range others {
+		for _, f := range files {
+			touched[f] = append(touched[f], agent)
+		}
+	}
+	var out []Conflict
+	for _, f := range changed {
+		agents := touched[f]
+		if len(agents) == 0 {
+			continue
+		}
+		sort.Strings(agents)
+		out = append(out, Conflict{Path: f, Reason: "changed on another agent branch", Agents: agents})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// BodyInput is what a pull request description is built from.
+type BodyInput struct {
+	TaskID    string
+	TaskURL   string
+	Session   string
+	Agent     string
+	Branch    string
+	Summary   *summary.SessionSummary
+	Scores    []*scoring.Score
+	Conflicts []Conflict
+}
+
+// Title returns the default pull request title.
+func (in BodyInput) Title() string {
+	title := fmt.Sprintf("Agent %s work from session %s", in.Agent, in.Session)
+	if in.Summary != nil && len(in.Summary.Accomplishments) > 0 {
+		title = firstLine(in.Summary.Accomplishments[0], 72)
+	}
+	if in.TaskID != "" {
+		title = "[" + in.TaskID + "] " + title
+	}
+	return title
+}
+
+// Body renders the pull request description in Markdown.
+func (in BodyInput) Body() string {
+	var b strings.Builder
+
+	if in.TaskID != "" {
+		task := "`" + in.TaskID + "`"
+		if in.TaskURL != "" {
+			task = fmt.Sprintf("[%s](%s)", in.TaskID, in.TaskURL)
+		}
+		fmt.Fprintf(&b, "**Task:** %s\n\n", task)
+	}
+
+	b.WriteString("## Summary\n\n")
+	if s := in.Summary; s != nil && (len(s.Accomplishments)+len(s.Changes)+len(s.Pending)+len(s.Errors) > 0) {
+		writeList(&b, "", s.Accomplishments)
+		writeList(&b, "Changes", s.Changes)
+		writeList(&b, "Pending", s.Pending)
+		writeList(&b, "Errors", s.Errors)
+	} else {
+		b.WriteString("_No session summary available._\n\n")
+	}
+
+	b.WriteString("## Agent\n\n")
+	b.WriteString("| Session | Agent | Branch |\n|---|---|---|\n")
+	fmt.Fprintf(&b, "| %s | %s | `%s` |\n\n", in.Session, in.Agent, in.Branch)
+
+	if len(in.Scores) > 0 {
+		b.WriteString("## Metrics\n\n")
+		b.WriteString("| Recorded | Task type | Overall | Completion | Quality | Efficiency | Prompts | Tokens | Minutes | Errors |\n")
+		b.WriteString("|---|---|---|---|---|---|---|---|---|---|\n")
+		for _, s := range in.Scores {
+			m := s.Metrics
+			fmt.Fprintf(&b, "| %s | %s | %.2f | %.2f | %.2f | %.2f | %d | %d | %d | %d |\n",
+				s.Timestamp.UTC().Format("2006-01-02 15:04"), orDash(s.TaskType),
+				m.Overall, m.Completion, m.Quality, m.Efficiency,
+				m.PromptsUsed, m.TokensUsed, m.DurationMinutes, m.ErrorCount)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(in.Conflicts) > 0 {
+		b.WriteString("## ⚠️ Conflict warnings\n\n")
+		for _, c := range in.Conflicts {
+			fmt.Fprintf(&b, "- `%s`: %s", c.Path, c.Reason)
+			if len(c.Agents) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(c.Agents, ", "))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("---\n_Opened by ntm._\n")
+	return b.String()
+}
+
+func writeList(b *strings.Builder, heading string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	if heading != "" {
+		fmt.Fprintf(b, "### %s\n\n", heading)
+	}
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+	b.WriteString("\n")
+}
+
+func firstLine(s string, max int) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return util.Truncate(s, max)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
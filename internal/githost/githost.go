@@ -0,0 +1,257 @@
+// Package githost opens pull requests for agent branches on GitHub and
+// GitLab, annotates them with conflict warnings, and lists issues to work on.
+package githost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Kind identifies a hosting service.
+type Kind string
+
+// Supported hosting services.
+const (
+	GitHub Kind = "github"
+	GitLab Kind = "gitlab"
+)
+
+// Remote is a parsed git remote URL.
+type Remote struct {
+	Kind Kind   `json:"kind,omitempty"`
+	Host string `json:"host"`
+	// Path is the repository path without ".git": "owner/repo" on GitHub,
+	// possibly "group/subgroup/project" on GitLab.
+	Path string `json:"path"`
+}
+
+// ParseRemote parses an SSH, scp-style, or HTTPS remote URL. Kind is guessed
+// from the host name and left empty for hosts that are neither.
+func ParseRemote(raw string) (Remote, error) {
+	raw = strings.TrimSpace(raw)
+	var host, path string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return Remote{}, fmt.Errorf("parse remote %q: %w", raw, err)
+		}
+		host, path = u.Hostname(), u.Path
+	} else if at := strings.Index(raw, "@"); at >= 0 && strings.Contains(raw[at:], ":") {
+		// scp-style: git@github.com:owner/repo.git
+		rest := raw[at+1:]
+		colon := strings.Index(rest, ":")
+		host, path = rest[:colon], rest[colon+1:]
+	} else {
+		return Remote{}, fmt.Errorf("unsupported remote URL %q", raw)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || strings.Count(path, "/") < 1 {
+		return Remote{}, fmt.Errorf("remote %q has no owner/repository path", raw)
+	}
+
+	r := Remote{Host: strings.ToLower(host), Path: path}
+	switch {
+	case strings.Contains(r.Host, "github"):
+		r.Kind = GitHub
+	case strings.Contains(r.Host, "gitlab"):
+		r.Kind = GitLab
+	}
+	return r, nil
+}
+
+// APIBaseURL returns the REST API root for the remote's host.
+func (r Remote) APIBaseURL() string {
+	switch {
+	case r.Kind == GitHub && r.Host == "github.com":
+		return "https://api.github.com"
+	case r.Kind == GitHub:
+		return "https://" + r.Host + "/api/v3" // GitHub Enterprise Server
+	default:
+		return "https://" + r.Host + "/api/v4"
+	}
+}
+
+// TokenFromEnv returns the API token for kind from the environment:
+// GITHUB_TOKEN or GH_TOKEN for GitHub, GITLAB_TOKEN for GitLab.
+func TokenFromEnv(kind Kind) string {
+	var names []string
+	switch kind {
+	case GitHub:
+		names = []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	case GitLab:
+		names = []string{"GITLAB_TOKEN"}
+	}
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// PullRequestOptions describes a pull request to open.
+type PullRequestOptions struct {
+	Head  string // source branch
+	Base  string // target branch
+	Title string
+	Body  string
+	Draft bool
+}
+
+// PullRequest is an opened (or already open) pull or merge request.
+type PullRequest struct {
+	Number  int    `json:"number"`
+	URL     string `json:"url"`
+	HeadSHA string `json:"head_sha,omitempty"`
+	// Existing is set when an open request for the branch already existed
+	// and was returned instead of creating a new one.
+	Existing bool `json:"existing,omitempty"`
+}
+
+// Provider is a hosting service API.
+type Provider interface {
+	Kind() Kind
+	// OpenPullRequest creates a pull request, or returns the open one for
+	// the same head branch.
+	OpenPullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error)
+	// CommentFile posts a review comment about path on pr.
+	CommentFile(ctx context.Context, pr *PullRequest, path, body string) error
+	// ListIssues lists open issues matching q, oldest first.
+	ListIssues(ctx context.Context, q IssueQuery) ([]Issue, error)
+}
+
+// New returns the provider for remote. baseURL overrides the API root
+// (default Remote.APIBaseURL).
+func New(remote Remote, token, baseURL string) (Provider, error) {
+	if token == "" {
+		return nil, fmt.Errorf("no API token for %s", remote.Host)
+	}
+	if baseURL == "" {
+		baseURL = remote.APIBaseURL()
+	}
+	c := &client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+	switch remote.Kind {
+	case GitHub:
+		c.auth = func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Accept", "application/vnd.github+json")
+			req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		}
+		return &githubProvider{c: c, repo: remote.Path}, nil
+	case GitLab:
+		c.auth = func(req *http.Request) { req.Header.Set("PRIVATE-TOKEN", token) }
+		return &gitlabProvider{c: c, project: url.PathEscape(remote.Path)}, nil
+	default:
+		return nil, fmt.Errorf("cannot tell whether %s is GitHub or GitLab; pass the provider explicitly", remote.Host)
+	}
+}
+
+// APIError is a non-2xx response from a hosting API.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// client is the JSON transport shared by the providers.
+type client struct {
+	baseURL string
+	http    *http.Client
+	auth    func(*http.Request)
+}
+
+func (c *client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.auth(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &APIError{Method: method, Path: path, StatusCode: resp.StatusCode, Message: errorMessage(data, resp.Status)}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// errorMessage extracts the message from a GitHub or GitLab error body.
+func errorMessage(data []byte, status string) string {
+	var e struct {
+		Message json.RawMessage `json:"message"`
+		Error   string          `json:"error"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(data, &e) != nil {
+		return status
+	}
+	var parts []string
+	if len(e.Message) > 0 {
+		var s string
+		if json.Unmarshal(e.Message, &s) == nil {
+			parts = append(parts, s)
+		} else {
+			// GitLab sometimes returns a list or object of messages.
+			parts = append(parts, string(e.Message))
+		}
+	}
+	if e.Error != "" {
+		parts = append(parts, e.Error)
+	}
+	for _, item := range e.Errors {
+		if item.Message != "" {
+			parts = append(parts, item.Message)
+		}
+	}
+	if len(parts) == 0 {
+		return status
+	}
+	return strings.Join(parts, ": ")
+}
+
+func isStatus(err error, code int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == code
+}
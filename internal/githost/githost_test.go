@@ -0,0 +1,307 @@
+package githost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Remote
+	}{
+		{"git@github.com:acme/widgets.git", Remote{Kind: GitHub, Host: "github.com", Path: "acme/widgets"}},
+		{"https://github.com/acme/widgets", Remote{Kind: GitHub, Host: "github.com", Path: "acme/widgets"}},
+		{"ssh://git@gitlab.example.com:2222/group/sub/proj.git", Remote{Kind: GitLab, Host: "gitlab.example.com", Path: "group/sub/proj"}},
+		{"https://git.corp.internal/team/repo.git", Remote{Host: "git.corp.internal", Path: "team/repo"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRemote(tt.raw)
+		if err != nil {
+			t.Errorf("ParseRemote(%q): %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRemote(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+
+	for _, raw := range []string{"/local/path", "https://github.com/onlyowner"} {
+		if _, err := ParseRemote(raw); err == nil {
+			t.Errorf("ParseRemote(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestAPIBaseURL(t *testing.T) {
+	for r, want := range map[Remote]string{
+		{Kind: GitHub, Host: "github.com"}:      "https://api.github.com",
+		{Kind: GitHub, Host: "github.corp.com"}: "https://github.corp.com/api/v3",
+		{Kind: GitLab, Host: "gitlab.com"}:      "https://gitlab.com/api/v4",
+	} {
+		if got := r.APIBaseURL(); got != want {
+			t.Errorf("%+v: %s, want %s", r, got, want)
+		}
+	}
+}
+
+// recorder is a fake hosting API that records requests and replies with
+// the handler's status and body.
+type recorder struct {
+	requests []string
+	bodies   []map[string]any
+	headers  []http.Header
+}
+
+func (rec *recorder) server(t *testing.T, reply func(method, path string) (int, string)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.requests = append(rec.requests, r.Method+" "+r.URL.RequestURI())
+		rec.headers = append(rec.headers, r.Header.Clone())
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		rec.bodies = append(rec.bodies, body)
+		status, resp := reply(r.Method, r.URL.Path)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGitHub_OpenAndComment(t *testing.T) {
+	var rec recorder
+	srv := rec.server(t, func(method, path string) (int, string) {
+		if method == http.MethodPost && path == "/repos/acme/widgets/pulls" {
+			return http.StatusCreated, `{"number":7,"html_url":"https://github.com/acme/widgets/pull/7","head":{"sha":"abc123"}}`
+		}
+		return http.StatusCreated, `{}`
+	})
+
+	p, err := New(Remote{Kind: GitHub, Host: "github.com", Path: "acme/widgets"}, "tok", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	pr, err := p.OpenPullRequest(ctx, PullRequestOptions{Head: "ntm/s/cc_1", Base: "main", Title: "T", Body: "B", Draft: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 7 || pr.HeadSHA != "abc123" || pr.Existing {
+		t.Errorf("pr = %+v", pr)
+	}
+	if err := p.CommentFile(ctx, pr, "main.go", "careful"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rec.headers[0].Get("Authorization"); got != "Bearer tok" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if rec.bodies[0]["head"] != "ntm/s/cc_1" || rec.bodies[0]["draft"] != true {
+		t.Errorf("create body = %v", rec.bodies[0])
+	}
+	if rec.requests[1] != "POST /repos/acme/widgets/pulls/7/comments" {
+		t.Errorf("comment request = %s", rec.requests[1])
+	}
+	c := rec.bodies[1]
+	if c["path"] != "main.go" || c["commit_id"] != "abc123" || c["subject_type"] != "file" {
+		t.Errorf("comment body = %v", c)
+	}
+}
+
+func TestGitHub_ExistingPullRequest(t *testing.T) {
+	var rec recorder
+	srv := rec.server(t, func(method, path string) (int, string) {
+		if method == http.MethodPost {
+			return http.StatusUnprocessableEntity, `{"message":"Validation Failed","errors":[{"message":"A pull request already exists for acme:ntm/s/cc_1."}]}`
+		}
+		return http.StatusOK, `[{"number":3,"html_url":"u","head":{"sha":"def"}}]`
+	})
+	p, _ := New(Remote{Kind: GitHub, Host: "github.com", Path: "acme/widgets"}, "tok", srv.URL)
+	pr, err := p.OpenPullRequest(context.Background(), PullRequestOptions{Head: "ntm/s/cc_1", Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 3 || !pr.Existing {
+		t.Errorf("pr = %+v", pr)
+	}
+	if !strings.Contains(rec.requests[1], "head=acme%3Antm%2Fs%2Fcc_1") {
+		t.Errorf("lookup = %s", rec.requests[1])
+	}
+}
+
+func TestGitHub_Error(t *testing.T) {
+	var rec recorder
+	srv := rec.server(t, func(string, string) (int, string) {
+		return http.StatusUnauthorized, `{"message":"Bad credentials"}`
+	})
+	p, _ := New(Remote{Kind: GitHub, Host: "github.com", Path: "acme/widgets"}, "tok", srv.URL)
+	_, err := p.OpenPullRequest(context.Background(), PullRequestOptions{Head: "h", Base: "main"})
+	if !isStatus(err, http.StatusUnauthorized) || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestGitLab_OpenAndComment(t *testing.T) {
+	var rec recorder
+	srv := rec.server(t, func(method, path string) (int, string) {
+		if strings.HasSuffix(path, "/merge_requests") {
+			return http.StatusCreated, `{"iid":12,"web_url":"https://gitlab.com/g/p/-/merge_requests/12","sha":"fff"}`
+		}
+		return http.StatusCreated, `{}`
+	})
+	p, err := New(Remote{Kind: GitLab, Host: "gitlab.com", Path: "group/sub/proj"}, "tok", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	pr, err := p.OpenPullRequest(ctx, PullRequestOptions{Head: "ntm/s/cod_1", Base: "main", Title: "Fix", Draft: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 12 {
+		t.Errorf("pr = %+v", pr)
+	}
+	if err := p.CommentFile(ctx, pr, "a.go", "careful"); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.requests[0] != "POST /projects/group%2Fsub%2Fproj/merge_requests" {
+		t.Errorf("create request = %s", rec.requests[0])
+	}
+	if rec.headers[0].Get("PRIVATE-TOKEN") != "tok" {
+		t.Errorf("missing PRIVATE-TOKEN header")
+	}
+	if rec.bodies[0]["title"] != "Draft: Fix" || rec.bodies[0]["source_branch"] != "ntm/s/cod_1" {
+		t.Errorf("create body = %v", rec.bodies[0])
+	}
+	if rec.requests[1] != "POST /projects/group%2Fsub%2Fproj/merge_requests/12/notes" ||
+		!strings.Contains(rec.bodies[1]["body"].(string), "`a.go`") {
+		t.Errorf("note = %s %v", rec.requests[1], rec.bodies[1])
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	if _, err := New(Remote{Kind: GitHub, Host: "github.com", Path: "a/b"}, "", ""); err == nil {
+		t.Error("expected error without token")
+	}
+	if _, err := New(Remote{Host: "git.corp", Path: "a/b"}, "tok", ""); err == nil {
+		t.Error("expected error for unknown host kind")
+	}
+}
+
+func TestListIssues(t *testing.T) {
+	var rec recorder
+	srv := rec.server(t, func(method, path string) (int, string) {
+		if strings.HasPrefix(path, "/repos/") {
+			return http.StatusOK, `[
+				{"number":3,"title":"Crash on start","body":"stack","html_url":"https://github.com/acme/widgets/issues/3","labels":[{"name":"triage"}]},
+				{"number":4,"title":"A PR","html_url":"https://github.com/acme/widgets/pull/4","pull_request":{}},
+				{"number":5,"title":"Typo","html_url":"https://github.com/acme/widgets/issues/5"}]`
+		}
+		return http.StatusOK, `[{"iid":9,"title":"Slow query","description":"d","web_url":"https://gitlab.com/g/p/-/issues/9","labels":["triage"]}]`
+	})
+	ctx := context.Background()
+
+	gh, _ := New(Remote{Kind: GitHub, Host: "github.com", Path: "acme/widgets"}, "tok", srv.URL)
+	issues, err := gh.ListIssues(ctx, IssueQuery{Labels: []string{"triage"}, Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || issues[0].Number != 3 || issues[0].Labels[0] != "triage" || issues[1].Number != 5 {
+		t.Errorf("issues = %+v", issues)
+	}
+	if rec.requests[0] != "GET /repos/acme/widgets/issues?direction=asc&labels=triage&per_page=5&sort=created&state=open" {
+		t.Errorf("request = %s", rec.requests[0])
+	}
+
+	gl, _ := New(Remote{Kind: GitLab, Host: "gitlab.com", Path: "g/p"}, "tok", srv.URL)
+	issues, err = gl.ListIssues(ctx, IssueQuery{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Number != 9 || issues[0].Body != "d" {
+		t.Errorf("gitlab issues = %+v", issues)
+	}
+}
+
+func TestParseIssueSource(t *testing.T) {
+	src, err := ParseIssueSource("github:label=triage,label=bug,repo=acme/widgets,limit=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Kind != GitHub || src.Repo != "acme/widgets" || src.Query.Limit != 5 ||
+		strings.Join(src.Query.Labels, ",") != "triage,bug" {
+		t.Errorf("source = %+v", src)
+	}
+	if src, err := ParseIssueSource("gitlab:"); err != nil || src.Kind != GitLab {
+		t.Errorf("gitlab: = %+v, %v", src, err)
+	}
+	for _, spec := range []string{"jira:label=x", "github", "github:label", "github:state=closed", "github:repo=x", "github:limit=0"} {
+		if _, err := ParseIssueSource(spec); err == nil {
+			t.Errorf("ParseIssueSource(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestBranchConflicts(t *testing.T) {
+	got := BranchConflicts([]string{"b.go", "a.go", "c.go"}, map[string][]string{
+		"cod_1": {"a.go", "z.go"},
+		"cc_2":  {"a.go", "b.go"},
+	})
+	if len(got) != 2 || got[0].Path != "a.go" || strings.Join(got[0].Agents, ",") != "cc_2,cod_1" || got[1].Path != "b.go" {
+		t.Errorf("conflicts = %+v", got)
+	}
+}
+
+func TestBodyInput(t *testing.T) {
+	in := BodyInput{
+		TaskID:  "bd-42",
+		TaskURL: "https://example.com/bd-42",
+		Session: "proj",
+		Agent:   "cc_1",
+		Branch:  "ntm/proj/cc_1",
+		Summary: &summary.SessionSummary{
+			Accomplishments: []string{"Added retry logic to the uploader\nwith details"},
+			Pending:         []string{"docs"},
+		},
+		Scores: []*scoring.Score{{
+			Timestamp: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC),
+			Metrics:   scoring.ScoreMetrics{Overall: 0.8, Completion: 1, TokensUsed: 1200},
+		}},
+		Conflicts: []Conflict{{Path: "up.go", Reason: "changed on another agent branch", Agents: []string{"cod_1"}}},
+	}
+
+	if got := in.Title(); got != "[bd-42] Added retry logic to the uploader" {
+		t.Errorf("title = %q", got)
+	}
+	body := in.Body()
+	for _, want := range []string{
+		"**Task:** [bd-42](https://example.com/bd-42)",
+		"- Added retry logic",
+		"### Pending",
+		"| proj | cc_1 | `ntm/proj/cc_1` |",
+		"| 2026-01-02 03:04 | - | 0.80 | 1.00 |",
+		"- `up.go`: changed on another agent branch (cod_1)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	bare := BodyInput{Session: "proj", Agent: "cc_1"}
+	if bare.Title() != "Agent cc_1 work from session proj" || !strings.Contains(bare.Body(), "No session summary") {
+		t.Errorf("bare title/body = %q / %q", bare.Title(), bare.Body())
+	}
+	if c := in.Conflicts[0].Comment(); !strings.Contains(c, "also touched by cod_1") {
+		t.Errorf("comment = %q", c)
+	}
+}
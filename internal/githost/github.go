@@ -0,0 +1,70 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type githubProvider struct {
+	c    *client
+	repo string // owner/repo
+}
+
+type githubPull struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+func (p githubPull) toPullRequest() *PullRequest {
+	return &PullRequest{Number: p.Number, URL: p.HTMLURL, HeadSHA: p.Head.SHA}
+}
+
+func (g *githubProvider) Kind() Kind { return GitHub }
+
+func (g *githubProvider) OpenPullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error) {
+	var pull githubPull
+	err := g.c.do(ctx, http.MethodPost, "/repos/"+g.repo+"/pulls", map[string]any{
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"title": opts.Title,
+		"body":  opts.Body,
+		"draft": opts.Draft,
+	}, &pull)
+	if err == nil {
+		return pull.toPullRequest(), nil
+	}
+	// 422 "A pull request already exists for owner:branch."
+	if !isStatus(err, http.StatusUnprocessableEntity) || !strings.Contains(err.Error(), "already exists") {
+		return nil, err
+	}
+
+	owner, _, _ := strings.Cut(g.repo, "/")
+	q := url.Values{"head": {owner + ":" + opts.Head}, "state": {"open"}}
+	var pulls []githubPull
+	if lookupErr := g.c.do(ctx, http.MethodGet, "/repos/"+g.repo+"/pulls?"+q.Encode(), nil, &pulls); lookupErr != nil || len(pulls) == 0 {
+		return nil, err
+	}
+	pr := pulls[0].toPullRequest()
+	pr.Existing = true
+	return pr, nil
+}
+
+// CommentFile posts a file-level review comment, which GitHub shows on the
+// file in the "Files changed" tab. The file must be part of the diff.
+func (g *githubProvider) CommentFile(ctx context.Context, pr *PullRequest, path, body string) error {
+	if pr.HeadSHA == "" {
+		return fmt.Errorf("pull request #%d has no head commit", pr.Number)
+	}
+	return g.c.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/comments", g.repo, pr.Number), map[string]any{
+		"body":         body,
+		"commit_id":    pr.HeadSHA,
+		"path":         path,
+		"subject_type": "file",
+	}, nil)
+}
@@ -0,0 +1,64 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type gitlabProvider struct {
+	c       *client
+	project string // URL-escaped project path
+}
+
+type gitlabMergeRequest struct {
+	IID    int    `json:"iid"`
+	WebURL string `json:"web_url"`
+	SHA    string `json:"sha"`
+}
+
+func (m gitlabMergeRequest) toPullRequest() *PullRequest {
+	return &PullRequest{Number: m.IID, URL: m.WebURL, HeadSHA: m.SHA}
+}
+
+func (g *gitlabProvider) Kind() Kind { return GitLab }
+
+func (g *gitlabProvider) OpenPullRequest(ctx context.Context, opts PullRequestOptions) (*PullRequest, error) {
+	title := opts.Title
+	if opts.Draft {
+		title = "Draft: " + title
+	}
+	var mr gitlabMergeRequest
+	err := g.c.do(ctx, http.MethodPost, "/projects/"+g.project+"/merge_requests", map[string]any{
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+		"title":         title,
+		"description":   opts.Body,
+	}, &mr)
+	if err == nil {
+		return mr.toPullRequest(), nil
+	}
+	// 409 "Another open merge request already exists for this source branch"
+	if !isStatus(err, http.StatusConflict) {
+		return nil, err
+	}
+
+	q := url.Values{"source_branch": {opts.Head}, "state": {"opened"}}
+	var mrs []gitlabMergeRequest
+	if lookupErr := g.c.do(ctx, http.MethodGet, "/projects/"+g.project+"/merge_requests?"+q.Encode(), nil, &mrs); lookupErr != nil || len(mrs) == 0 {
+		return nil, err
+	}
+	pr := mrs[0].toPullRequest()
+	pr.Existing = true
+	return pr, nil
+}
+
+// CommentFile posts a merge request note naming the file. GitLab diff
+// discussions must anchor to a changed line, which a file-level warning
+// does not have.
+func (g *gitlabProvider) CommentFile(ctx context.Context, pr *PullRequest, path, body string) error {
+	return g.c.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests/%d/notes", g.project, pr.Number), map[string]any{
+		"body": fmt.Sprintf("**`%s`**\n\n%s", path, body),
+	}, nil)
+}
@@ -0,0 +1,161 @@
+package githost
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultIssueLimit caps the issues an IssueSource yields when it sets no limit.
+const DefaultIssueLimit = 20
+
+// Issue is an open issue.
+type Issue struct {
+	Number int      `json:"number"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body,omitempty"`
+	URL    string   `json:"url"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// IssueQuery selects open issues.
+type IssueQuery struct {
+	Labels []string // All must be present
+	Limit  int      // Maximum issues returned (default DefaultIssueLimit)
+}
+
+// IssueSource is a parsed task source such as "github:label=triage": where
+// to read open issues from and which ones.
+type IssueSource struct {
+	Kind Kind
+	// Repo is "owner/repo" (or the GitLab project path); empty means the
+	// project's origin remote.
+	Repo  string
+	Query IssueQuery
+}
+
+// ParseIssueSource parses "<github|gitlab>:key=value,...". Keys are label
+// (repeatable), repo and limit.
+func ParseIssueSource(spec string) (IssueSource, error) {
+	kind, rest, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	src := IssueSource{Kind: Kind(strings.ToLower(kind))}
+	if !ok || (src.Kind != GitHub && src.Kind != GitLab) {
+		return IssueSource{}, fmt.Errorf("task source %q: want github:<filters> or gitlab:<filters>", spec)
+	}
+	for _, part := range strings.Split(rest, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return IssueSource{}, fmt.Errorf("task source %q: %q is not key=value", spec, part)
+		}
+		switch strings.TrimSpace(key) {
+		case "label", "labels":
+			src.Query.Labels = append(src.Query.Labels, value)
+		case "repo":
+			if strings.Count(value, "/") < 1 {
+				return IssueSource{}, fmt.Errorf("task source %q: repo must be owner/repo", spec)
+			}
+			src.Repo = value
+		case "limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return IssueSource{}, fmt.Errorf("task source %q: limit must be a positive number", spec)
+			}
+			src.Query.Limit = n
+		default:
+			return IssueSource{}, fmt.Errorf("task source %q: unknown filter %q (label, repo, limit)", spec, key)
+		}
+	}
+	return src, nil
+}
+
+func (q IssueQuery) limit() int {
+	if q.Limit <= 0 {
+		return DefaultIssueLimit
+	}
+	return q.Limit
+}
+
+type githubIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// ListIssues lists open issues, oldest first. GitHub's issues endpoint also
+// returns pull requests; they are dropped.
+func (g *githubProvider) ListIssues(ctx context.Context, q IssueQuery) ([]Issue, error) {
+	params := url.Values{
+		"state":     {"open"},
+		"sort":      {"created"},
+		"direction": {"asc"},
+		"per_page":  {strconv.Itoa(min(q.limit(), 100))},
+	}
+	if len(q.Labels) > 0 {
+		params.Set("labels", strings.Join(q.Labels, ","))
+	}
+	var items []githubIssue
+	if err := g.c.do(ctx, http.MethodGet, "/repos/"+g.repo+"/issues?"+params.Encode(), nil, &items); err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(items))
+	for _, it := range items {
+		if it.PullRequest != nil {
+			continue
+		}
+		issue := Issue{Number: it.Number, Title: it.Title, Body: it.Body, URL: it.HTMLURL}
+		for _, l := range it.Labels {
+			issue.Labels = append(issue.Labels, l.Name)
+		}
+		issues = append(issues, issue)
+		if len(issues) == q.limit() {
+			break
+		}
+	}
+	return issues, nil
+}
+
+type gitlabIssue struct {
+	IID         int      `json:"iid"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	WebURL      string   `json:"web_url"`
+	Labels      []string `json:"labels"`
+}
+
+// ListIssues lists open issues, oldest first.
+func (g *gitlabProvider) ListIssues(ctx context.Context, q IssueQuery) ([]Issue, error) {
+	params := url.Values{
+		"state":    {"opened"},
+		"order_by": {"created_at"},
+		"sort":     {"asc"},
+		"per_page": {strconv.Itoa(min(q.limit(), 100))},
+	}
+	if len(q.Labels) > 0 {
+		params.Set("labels", strings.Join(q.Labels, ","))
+	}
+	var items []gitlabIssue
+	if err := g.c.do(ctx, http.MethodGet, "/projects/"+g.project+"/issues?"+params.Encode(), nil, &items); err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, 0, len(items))
+	for _, it := range items {
+		issues = append(issues, Issue{Number: it.IID, Title: it.Title, Body: it.Description, URL: it.WebURL, Labels: it.Labels})
+		if len(issues) == q.limit() {
+			break
+		}
+	}
+	return issues, nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
 	"github.com/Dicklesworthstone/ntm/internal/status"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
 )
 
 // Status represents the overall health status of an agent
@@ -72,19 +73,22 @@ type Progress struct {
 
 // AgentHealth contains health information for a single agent
 type AgentHealth struct {
-	Pane          int           `json:"pane"`           // Pane index
-	PaneID        string        `json:"pane_id"`        // Full pane ID
-	AgentType     string        `json:"agent_type"`     // claude, codex, gemini, user, unknown
-	Status        Status        `json:"status"`         // Overall health status
-	ProcessStatus ProcessStatus `json:"process_status"` // Process running state
-	Activity      ActivityLevel `json:"activity"`       // Activity level
-	LastActivity  *time.Time    `json:"last_activity"`  // Last activity timestamp
-	IdleSeconds   int           `json:"idle_seconds"`   // Seconds since last activity
-	Issues        []Issue       `json:"issues"`         // Detected issues
-	RateLimited   bool          `json:"rate_limited"`   // True if agent hit rate limit
-	WaitSeconds   int           `json:"wait_seconds"`   // Suggested wait time (if rate limited)
-	Progress      *Progress     `json:"progress"`       // Detected work progress
-	ShellPID      int           `json:"shell_pid"`      // Shell PID from tmux pane
+	Pane          int                `json:"pane"`                // Pane index
+	PaneID        string             `json:"pane_id"`             // Full pane ID
+	AgentType     string             `json:"agent_type"`          // claude, codex, gemini, user, unknown
+	Status        Status             `json:"status"`              // Overall health status
+	ProcessStatus ProcessStatus      `json:"process_status"`      // Process running state
+	Activity      ActivityLevel      `json:"activity"`            // Activity level
+	LastActivity  *time.Time         `json:"last_activity"`       // Last activity timestamp
+	IdleSeconds   int                `json:"idle_seconds"`        // Seconds since last activity
+	Issues        []Issue            `json:"issues"`              // Detected issues
+	RateLimited   bool               `json:"rate_limited"`        // True if agent hit rate limit
+	WaitSeconds   int                `json:"wait_seconds"`        // Suggested wait time (if rate limited)
+	Progress      *Progress          `json:"progress"`            // Detected work progress
+	ShellPID      int                `json:"shell_pid"`           // Shell PID from tmux pane
+	ContextUsage  float64            `json:"context_usage"`       // Context window used (0-100) from the agent's status line, -1 if unknown
+	Resources     *process.Usage     `json:"resources,omitempty"` // CPU and memory of the pane's process tree
+	Usage         *tokens.TokenUsage `json:"usage,omitempty"`     // Usage record from the agent's status line, as printed (running totals)
 }
 
 // SessionHealth contains health information for an entire session
@@ -124,6 +128,20 @@ func CheckSession(ctx context.Context, session string) (*SessionHealth, error) {
 		OverallStatus: StatusOK,
 	}
 
+	// Sample the pane process trees alongside the tmux checks; the first
+	// sample of a tree has to wait a moment to measure CPU.
+	pids := make([]int, 0, len(panesWithActivity))
+	for _, pa := range panesWithActivity {
+		if pa.Pane.PID > 0 {
+			pids = append(pids, pa.Pane.PID)
+		}
+	}
+	usageCh := make(chan map[int]process.Usage, 1)
+	go func() {
+		usage, _ := process.DefaultSampler().SampleTrees(ctx, pids)
+		usageCh <- usage
+	}()
+
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 	sem := make(chan struct{}, 8) // Limit concurrent tmux checks to 8
@@ -166,11 +184,18 @@ func CheckSession(ctx context.Context, session string) (*SessionHealth, error) {
 		}(pa)
 	}
 	wg.Wait()
+	usage := <-usageCh
 
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
 	}
 
+	for i := range health.Agents {
+		if u, ok := usage[health.Agents[i].ShellPID]; ok {
+			health.Agents[i].Resources = &u
+		}
+	}
+
 	return health, nil
 }
 
@@ -184,6 +209,8 @@ func checkAgent(ctx context.Context, pa tmux.PaneActivity) AgentHealth {
 		ProcessStatus: ProcessUnknown,
 		Activity:      ActivityUnknown,
 		Issues:        []Issue{},
+		ShellPID:      pa.Pane.PID,
+		ContextUsage:  -1,
 	}
 
 	// Set last activity
@@ -218,6 +245,14 @@ func checkAgent(ctx context.Context, pa tmux.PaneActivity) AgentHealth {
 
 	agent.Issues = issues
 
+	// Read context usage from the agent's own status line
+	if usage := tokens.ParseUsage(string(pa.Pane.Type), output); usage != nil {
+		agent.Usage = usage
+		if usage.ContextPercent >= 0 {
+			agent.ContextUsage = usage.ContextPercent
+		}
+	}
+
 	// Determine activity level
 	agent.Activity = detectActivity(output, pa.LastActivity, string(pa.Pane.Type))
 
@@ -424,39 +424,48 @@ func Prune(keep int) (int, error) {
 	toKeep := entries[len(entries)-keep:]
 	removed := len(entries) - keep
 
-	// Rewrite file atomically (re-encrypt if enabled)
-	var buf bytes.Buffer
-	skipped := 0
-	for _, entry := range toKeep {
-		data, err := json.Marshal(entry)
-		if err != nil {
-			slog.Warn("history: prune: skipping entry that failed to marshal", "error", err)
-			skipped++
-			continue
-		}
-		data, err = encryptJSONLine(data)
-		if err != nil {
-			slog.Warn("history: prune: skipping entry that failed to encrypt", "error", err)
-			skipped++
-			continue
+	if err := rewriteLocked(toKeep, "prune"); err != nil {
+		return 0, err
+	}
+
+	return removed, nil
+}
+
+// PruneByTime removes entries older than the cutoff time.
+func PruneByTime(cutoff time.Time) (int, error) {
+	unlock, err := acquireLock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	entries, err := readAllLocked()
+	if err != nil {
+		return 0, err
+	}
+
+	var toKeep []HistoryEntry
+	for _, e := range entries {
+		if e.Timestamp.After(cutoff) {
+			toKeep = append(toKeep, e)
 		}
-		buf.Write(data)
-		buf.WriteByte('\n')
 	}
-	if skipped > 0 {
-		slog.Warn("history: prune: entries lost during rewrite", "skipped", skipped)
+
+	removed := len(entries) - len(toKeep)
+	if removed == 0 {
+		return 0, nil
 	}
 
-	path := StoragePath()
-	if err := util.AtomicWriteFile(path, buf.Bytes(), 0600); err != nil {
+	if err := rewriteLocked(toKeep, "prune-by-time"); err != nil {
 		return 0, err
 	}
 
 	return removed, nil
 }
 
-// PruneByTime removes entries older than the cutoff time.
-func PruneByTime(cutoff time.Time) (int, error) {
+// PurgeSession removes all entries for session. If before is non-zero, only
+// entries recorded before it are removed. It returns the number removed.
+func PurgeSession(session string, before time.Time) (int, error) {
 	unlock, err := acquireLock()
 	if err != nil {
 		return 0, err
@@ -470,7 +479,7 @@ func PruneByTime(cutoff time.Time) (int, error) {
 
 	var toKeep []HistoryEntry
 	for _, e := range entries {
-		if e.Timestamp.After(cutoff) {
+		if e.Session != session || (!before.IsZero() && !e.Timestamp.Before(before)) {
 			toKeep = append(toKeep, e)
 		}
 	}
@@ -479,20 +488,28 @@ func PruneByTime(cutoff time.Time) (int, error) {
 	if removed == 0 {
 		return 0, nil
 	}
+	if err := rewriteLocked(toKeep, "purge"); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
 
-	// Rewrite file atomically (re-encrypt if enabled)
+// rewriteLocked atomically replaces the history file with entries,
+// re-encrypting them if enabled. op names the caller in log messages.
+// The caller must hold the history lock.
+func rewriteLocked(entries []HistoryEntry, op string) error {
 	var buf bytes.Buffer
 	skipped := 0
-	for _, entry := range toKeep {
+	for _, entry := range entries {
 		data, err := json.Marshal(entry)
 		if err != nil {
-			slog.Warn("history: prune-by-time: skipping entry that failed to marshal", "error", err)
+			slog.Warn("history: "+op+": skipping entry that failed to marshal", "error", err)
 			skipped++
 			continue
 		}
 		data, err = encryptJSONLine(data)
 		if err != nil {
-			slog.Warn("history: prune-by-time: skipping entry that failed to encrypt", "error", err)
+			slog.Warn("history: "+op+": skipping entry that failed to encrypt", "error", err)
 			skipped++
 			continue
 		}
@@ -500,15 +517,10 @@ func PruneByTime(cutoff time.Time) (int, error) {
 		buf.WriteByte('\n')
 	}
 	if skipped > 0 {
-		slog.Warn("history: prune-by-time: entries lost during rewrite", "skipped", skipped)
-	}
-
-	path := StoragePath()
-	if err := util.AtomicWriteFile(path, buf.Bytes(), 0600); err != nil {
-		return 0, err
+		slog.Warn("history: "+op+": entries lost during rewrite", "skipped", skipped)
 	}
 
-	return removed, nil
+	return util.AtomicWriteFile(StoragePath(), buf.Bytes(), 0600)
 }
 
 // Search finds entries matching a query string in the prompt.
@@ -0,0 +1,286 @@
+// Exec hooks are project-local executables in .ntm/hooks/ that extend ntm
+// without forking it. Each receives a lifecycle event as JSON on stdin and
+// may answer with JSON on stdout to change the action's payload or veto it.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ExecHooksDir is where exec hooks live, relative to the project directory.
+const ExecHooksDir = ".ntm/hooks"
+
+// ExecProtocolVersion is sent as "protocol" in every request.
+const ExecProtocolVersion = 1
+
+// ExecEvent is a lifecycle event that runs exec hooks.
+type ExecEvent string
+
+// Exec hook events
+const (
+	ExecPreSpawn    ExecEvent = "pre-spawn"    // Before a session's agents are spawned
+	ExecPostCapture ExecEvent = "post-capture" // Before captured pane output is archived
+	ExecPreSend     ExecEvent = "pre-send"     // Before a prompt is sent to panes
+	ExecOnConflict  ExecEvent = "on-conflict"  // Conflict detection found new conflicts
+	ExecOnComplete  ExecEvent = "on-complete"  // An agent completed its task
+)
+
+// AllExecEvents returns all exec hook events.
+func AllExecEvents() []ExecEvent {
+	return []ExecEvent{ExecPreSpawn, ExecPostCapture, ExecPreSend, ExecOnConflict, ExecOnComplete}
+}
+
+// Actionable reports whether hooks for the event can change or veto what
+// ntm does. Hooks for other events are notifications: their replies are
+// ignored and their failures only logged.
+func (e ExecEvent) Actionable() bool {
+	switch e {
+	case ExecPreSpawn, ExecPostCapture, ExecPreSend:
+		return true
+	}
+	return false
+}
+
+// ErrVetoed is returned by ExecOutcome.Err when a hook vetoed the action.
+var ErrVetoed = errors.New("vetoed")
+
+// ExecRequest is the JSON an exec hook reads from stdin.
+type ExecRequest struct {
+	Protocol   int                    `json:"protocol"`
+	Event      ExecEvent              `json:"event"`
+	Session    string                 `json:"session,omitempty"`
+	ProjectDir string                 `json:"project_dir"`
+	Payload    map[string]interface{} `json:"payload"`
+}
+
+// ExecResponse is the JSON an exec hook may write to stdout. Empty output
+// allows the action unchanged. Payload fields replace the fields of the
+// same name in the request payload; later hooks see the result.
+type ExecResponse struct {
+	Veto    bool                   `json:"veto,omitempty"`
+	Reason  string                 `json:"reason,omitempty"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// ExecHookResult reports one exec hook run.
+type ExecHookResult struct {
+	Path     string        `json:"path"`
+	Duration time.Duration `json:"duration"`
+	Modified []string      `json:"modified,omitempty"` // Payload fields the hook replaced
+	Vetoed   bool          `json:"vetoed,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// ExecOutcome is the result of running an event's exec hooks.
+type ExecOutcome struct {
+	Event    ExecEvent              `json:"event"`
+	Payload  map[string]interface{} `json:"payload"` // After modifications
+	Vetoed   bool                   `json:"vetoed"`
+	VetoedBy string                 `json:"vetoed_by,omitempty"`
+	Reason   string                 `json:"reason,omitempty"`
+	Results  []ExecHookResult       `json:"results,omitempty"`
+}
+
+// Err returns an error wrapping ErrVetoed when the action was vetoed.
+func (o *ExecOutcome) Err() error {
+	if o == nil || !o.Vetoed {
+		return nil
+	}
+	name := filepath.Base(o.VetoedBy)
+	if o.Reason == "" {
+		return fmt.Errorf("%s %w by hook %s", o.Event, ErrVetoed, name)
+	}
+	return fmt.Errorf("%s %w by hook %s: %s", o.Event, ErrVetoed, name, o.Reason)
+}
+
+// String returns the payload field key if it is a string, else fallback.
+func (o *ExecOutcome) String(key, fallback string) string {
+	if o == nil {
+		return fallback
+	}
+	if s, ok := o.Payload[key].(string); ok {
+		return s
+	}
+	return fallback
+}
+
+// Modified reports whether any hook replaced a payload field.
+func (o *ExecOutcome) Modified() bool {
+	if o == nil {
+		return false
+	}
+	for _, r := range o.Results {
+		if len(r.Modified) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// execHookWaitDelay bounds how long a timed-out hook's output is drained.
+const execHookWaitDelay = time.Second
+
+// ExecRunner discovers and runs the exec hooks of a project.
+type ExecRunner struct {
+	projectDir string
+	dir        string
+	timeout    time.Duration
+}
+
+// NewExecRunner returns a runner for the hooks in projectDir/.ntm/hooks.
+func NewExecRunner(projectDir string) *ExecRunner {
+	return &ExecRunner{
+		projectDir: projectDir,
+		dir:        filepath.Join(projectDir, filepath.FromSlash(ExecHooksDir)),
+		timeout:    CommandHookDefaults.Timeout,
+	}
+}
+
+// Dir returns the hooks directory.
+func (r *ExecRunner) Dir() string {
+	return r.dir
+}
+
+// Hooks returns the executables for event: .ntm/hooks/<event> followed by
+// the files in .ntm/hooks/<event>.d/ in name order.
+func (r *ExecRunner) Hooks(event ExecEvent) []string {
+	if r == nil || r.projectDir == "" {
+		return nil
+	}
+	var paths []string
+	if path := filepath.Join(r.dir, string(event)); isExecutable(path) {
+		paths = append(paths, path)
+	}
+	entries, _ := os.ReadDir(filepath.Join(r.dir, string(event)+".d"))
+	for _, e := range entries {
+		path := filepath.Join(r.dir, string(event)+".d", e.Name())
+		if !strings.HasPrefix(e.Name(), ".") && isExecutable(path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// HasHooks reports whether any exec hook exists for event.
+func (r *ExecRunner) HasHooks(event ExecEvent) bool {
+	return len(r.Hooks(event)) > 0
+}
+
+// isExecutable reports whether path is a regular file the user may run.
+// Windows has no execute bit, so any regular file counts there.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
+
+// Run runs event's hooks in order, each seeing the payload as modified by
+// the ones before it. For actionable events a hook that vetoes, exits
+// non-zero, times out or prints invalid JSON stops the action: a broken
+// guard fails closed. Notification hooks all run and only their errors are
+// reported. payload is not modified; the outcome holds the result.
+func (r *ExecRunner) Run(ctx context.Context, event ExecEvent, session string, payload map[string]interface{}) *ExecOutcome {
+	out := &ExecOutcome{Event: event, Payload: make(map[string]interface{}, len(payload))}
+	for k, v := range payload {
+		out.Payload[k] = v
+	}
+	for _, path := range r.Hooks(event) {
+		res, resp := r.runOne(ctx, path, ExecRequest{
+			Protocol:   ExecProtocolVersion,
+			Event:      event,
+			Session:    session,
+			ProjectDir: r.projectDir,
+			Payload:    out.Payload,
+		})
+		if !event.Actionable() {
+			out.Results = append(out.Results, res)
+			continue
+		}
+		switch {
+		case res.Error != "":
+			res.Vetoed, res.Reason = true, res.Error
+		case resp.Veto:
+			res.Vetoed, res.Reason = true, resp.Reason
+		default:
+			for k, v := range resp.Payload {
+				out.Payload[k] = v
+				res.Modified = append(res.Modified, k)
+			}
+			slices.Sort(res.Modified)
+		}
+		out.Results = append(out.Results, res)
+		if res.Vetoed {
+			out.Vetoed, out.VetoedBy, out.Reason = true, path, res.Reason
+			break
+		}
+	}
+	return out
+}
+
+// runOne runs a single hook with req on stdin.
+func (r *ExecRunner) runOne(ctx context.Context, path string, req ExecRequest) (ExecHookResult, ExecResponse) {
+	res := ExecHookResult{Path: path}
+	var resp ExecResponse
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		res.Error = fmt.Sprintf("encoding request: %v", err)
+		return res, resp
+	}
+
+	hookCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(hookCtx, path)
+	cmd.Dir = r.projectDir
+	// Don't wait on children of a killed hook that still hold its output.
+	cmd.WaitDelay = execHookWaitDelay
+	cmd.Env = append(os.Environ(),
+		"NTM_HOOK_EVENT="+string(req.Event),
+		"NTM_SESSION="+req.Session,
+		"NTM_PROJECT_DIR="+req.ProjectDir,
+	)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err = cmd.Run()
+	res.Duration = time.Since(start)
+	name := filepath.Base(path)
+	switch {
+	case hookCtx.Err() == context.DeadlineExceeded:
+		res.Error = fmt.Sprintf("hook %q timed out after %v", name, r.timeout)
+	case err != nil:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			res.Error = fmt.Sprintf("hook %q failed with exit code %d", name, exitErr.ExitCode())
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				res.Error += ": " + msg
+			}
+		} else {
+			res.Error = fmt.Sprintf("hook %q failed: %v", name, err)
+		}
+	default:
+		if data := bytes.TrimSpace(stdout.Bytes()); len(data) > 0 {
+			if err := json.Unmarshal(data, &resp); err != nil {
+				res.Error = fmt.Sprintf("hook %q printed invalid JSON: %v", name, err)
+			}
+		}
+	}
+	return res, resp
+}
@@ -0,0 +1,139 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeExecHook writes an executable shell script to projectDir/.ntm/hooks/name.
+func writeExecHook(t *testing.T, projectDir, name, script string) {
+	t.Helper()
+	path := filepath.Join(projectDir, ".ntm", "hooks", filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("exec hook tests use shell scripts")
+	}
+}
+
+func TestExecRunner_Hooks(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writeExecHook(t, dir, "pre-send", "exit 0")
+	writeExecHook(t, dir, "pre-send.d/20-second", "exit 0")
+	writeExecHook(t, dir, "pre-send.d/10-first", "exit 0")
+	writeExecHook(t, dir, "pre-send.d/.hidden", "exit 0")
+	// Not executable
+	if err := os.WriteFile(filepath.Join(dir, ".ntm", "hooks", "pre-send.d", "30-notes.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewExecRunner(dir)
+	var names []string
+	for _, p := range r.Hooks(ExecPreSend) {
+		rel, _ := filepath.Rel(r.Dir(), p)
+		names = append(names, filepath.ToSlash(rel))
+	}
+	if strings.Join(names, ",") != "pre-send,pre-send.d/10-first,pre-send.d/20-second" {
+		t.Errorf("hooks = %v", names)
+	}
+	if r.HasHooks(ExecPreSpawn) {
+		t.Error("no pre-spawn hooks exist")
+	}
+	if NewExecRunner("").HasHooks(ExecPreSend) {
+		t.Error("runner without a project should have no hooks")
+	}
+}
+
+func TestExecRunner_ModifyAndVeto(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	// The first hook rewrites the message; the second sees the rewrite.
+	writeExecHook(t, dir, "pre-send.d/10-rewrite", `cat > "$NTM_PROJECT_DIR/req1.json"
+echo '{"payload":{"message":"rewritten"}}'`)
+	writeExecHook(t, dir, "pre-send.d/20-check", `cat > "$NTM_PROJECT_DIR/req2.json"`)
+
+	r := NewExecRunner(dir)
+	out := r.Run(context.Background(), ExecPreSend, "proj", map[string]interface{}{"message": "hello", "panes": []int{1}})
+	if err := out.Err(); err != nil {
+		t.Fatalf("Err = %v", err)
+	}
+	if out.String("message", "") != "rewritten" || !out.Modified() {
+		t.Errorf("outcome = %+v", out)
+	}
+	req1, _ := os.ReadFile(filepath.Join(dir, "req1.json"))
+	if !strings.Contains(string(req1), `"event":"pre-send"`) || !strings.Contains(string(req1), `"message":"hello"`) ||
+		!strings.Contains(string(req1), `"protocol":1`) || !strings.Contains(string(req1), `"session":"proj"`) {
+		t.Errorf("first request = %s", req1)
+	}
+	req2, _ := os.ReadFile(filepath.Join(dir, "req2.json"))
+	if !strings.Contains(string(req2), `"message":"rewritten"`) {
+		t.Errorf("second request = %s", req2)
+	}
+
+	writeExecHook(t, dir, "pre-send.d/15-veto", `echo '{"veto":true,"reason":"no secrets"}'`)
+	os.Remove(filepath.Join(dir, "req2.json"))
+	out = r.Run(context.Background(), ExecPreSend, "proj", map[string]interface{}{"message": "hello"})
+	if !errors.Is(out.Err(), ErrVetoed) || !strings.Contains(out.Err().Error(), "15-veto: no secrets") {
+		t.Errorf("Err = %v", out.Err())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "req2.json")); err == nil {
+		t.Error("hooks after a veto should not run")
+	}
+}
+
+func TestExecRunner_FailuresFailClosed(t *testing.T) {
+	skipWithoutShell(t)
+	tests := []struct {
+		name, script, want string
+	}{
+		{"exit code", "echo denied >&2; exit 3", "exit code 3: denied"},
+		{"invalid json", "echo not-json", "invalid JSON"},
+		{"timeout", "sleep 5", "timed out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeExecHook(t, dir, "pre-spawn", tt.script)
+			r := NewExecRunner(dir)
+			r.timeout = 200 * time.Millisecond
+			out := r.Run(context.Background(), ExecPreSpawn, "proj", nil)
+			if !out.Vetoed || !strings.Contains(out.Reason, tt.want) {
+				t.Errorf("outcome = %+v, want veto containing %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecRunner_NotificationsCannotVeto(t *testing.T) {
+	skipWithoutShell(t)
+	dir := t.TempDir()
+	writeExecHook(t, dir, "on-complete.d/1", `echo '{"veto":true,"payload":{"bead_id":"x"}}'`)
+	writeExecHook(t, dir, "on-complete.d/2", "exit 1")
+	writeExecHook(t, dir, "on-complete.d/3", `touch "$NTM_PROJECT_DIR/ran"`)
+
+	out := NewExecRunner(dir).Run(context.Background(), ExecOnComplete, "proj", map[string]interface{}{"bead_id": "bd-1"})
+	if out.Err() != nil || out.Modified() || out.String("bead_id", "") != "bd-1" {
+		t.Errorf("outcome = %+v", out)
+	}
+	if len(out.Results) != 3 || out.Results[1].Error == "" {
+		t.Errorf("results = %+v", out.Results)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err != nil {
+		t.Error("later notification hooks should still run")
+	}
+}
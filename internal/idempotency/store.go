@@ -0,0 +1,235 @@
+// Package idempotency records the results of mutating commands by a
+// caller-supplied key, so a retried command returns the original result
+// instead of running again.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/process"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultDir holds one file per recorded key.
+const DefaultDir = "~/.local/share/ntm/idempotency"
+
+// DefaultTTL is how long a completed result is replayed.
+const DefaultTTL = 24 * time.Hour
+
+// PendingTimeout bounds how long a key stays reserved by a command that
+// never completed. Reservations held by a process that has exited are
+// released immediately.
+const PendingTimeout = 10 * time.Minute
+
+// MaxKeyLength bounds caller-supplied keys.
+const MaxKeyLength = 255
+
+var (
+	// ErrInProgress means another command holding the same key is still running.
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+	// ErrMismatch means the key was already used for a different request.
+	ErrMismatch = errors.New("idempotency key was already used for a different request")
+)
+
+// Entry is a recorded result.
+type Entry struct {
+	Scope       string    `json:"scope"`
+	Key         string    `json:"key"`
+	Fingerprint string    `json:"fingerprint"`
+	Pending     bool      `json:"pending,omitempty"`
+	PID         int       `json:"pid,omitempty"` // process holding a pending key
+	CreatedAt   time.Time `json:"created_at"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+}
+
+// Store persists entries under a directory, one file per scope and key,
+// so separate ntm processes see each other's results.
+type Store struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewStore returns a store rooted at dir (DefaultDir if empty) that keeps
+// results for ttl (DefaultTTL if zero).
+func NewStore(dir string, ttl time.Duration) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Store{dir: util.ExpandPath(dir), ttl: ttl, now: time.Now}
+}
+
+// ValidateKey checks a caller-supplied key.
+func ValidateKey(key string) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("idempotency key is empty")
+	}
+	if len(key) > MaxKeyLength {
+		return fmt.Errorf("idempotency key is longer than %d bytes", MaxKeyLength)
+	}
+	return nil
+}
+
+// Fingerprint hashes the parts that identify a request.
+func Fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin claims key within scope for a request with the given fingerprint.
+// It returns the completed entry when the result should be replayed, or
+// nil when the caller should run the command and then call Complete or
+// Abandon. ErrInProgress and ErrMismatch report keys that cannot be used.
+func (s *Store) Begin(scope, key, fingerprint string) (*Entry, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	var replay *Entry
+	err := s.withEntry(scope, key, func(e *Entry) (*Entry, error) {
+		if e != nil {
+			if e.Fingerprint != fingerprint {
+				return nil, ErrMismatch
+			}
+			if !e.Pending {
+				replay = e
+				return nil, nil
+			}
+			return nil, ErrInProgress
+		}
+		return &Entry{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Pending:     true,
+			PID:         os.Getpid(),
+			CreatedAt:   s.now().UTC(),
+		}, nil
+	})
+	return replay, err
+}
+
+// Complete records the result of a command started with Begin.
+func (s *Store) Complete(scope, key, contentType string, body []byte) error {
+	err := s.withEntry(scope, key, func(e *Entry) (*Entry, error) {
+		if e == nil {
+			e = &Entry{Scope: scope, Key: key, CreatedAt: s.now().UTC()}
+		}
+		e.Pending = false
+		e.CompletedAt = s.now().UTC()
+		e.ContentType = contentType
+		e.Body = body
+		return e, nil
+	})
+	if err == nil {
+		s.prune()
+	}
+	return err
+}
+
+// Abandon releases a key claimed by Begin without recording a result, so a
+// retry runs the command again. Completed entries are left alone.
+func (s *Store) Abandon(scope, key string) error {
+	path := s.path(scope, key)
+	return util.WithFileLock(path, func() error {
+		e, _ := s.read(path)
+		if e == nil || !e.Pending {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (s *Store) path(scope, key string) string {
+	return filepath.Join(s.dir, Fingerprint(scope, key)[:32]+".json")
+}
+
+func (s *Store) read(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (s *Store) expired(e *Entry) bool {
+	if e.Pending {
+		if e.PID > 0 && e.PID != os.Getpid() && !process.IsAlive(e.PID) {
+			return true
+		}
+		return s.now().Sub(e.CreatedAt) > PendingTimeout
+	}
+	return s.now().Sub(e.CompletedAt) > s.ttl
+}
+
+// withEntry runs fn on the live entry for scope/key (nil if absent or
+// expired) under the key's lock and writes back the entry fn returns.
+func (s *Store) withEntry(scope, key string, fn func(*Entry) (*Entry, error)) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	path := s.path(scope, key)
+	return util.WithFileLock(path, func() error {
+		e, _ := s.read(path)
+		if e != nil && s.expired(e) {
+			e = nil
+		}
+		next, err := fn(e)
+		if err != nil || next == nil {
+			return err
+		}
+		data, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		return util.AtomicWriteFile(path, data, 0600)
+	})
+}
+
+// prune removes expired entries. It is best effort.
+func (s *Store) prune() {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(s.dir, f.Name())
+		e, err := s.read(path)
+		if err != nil || !s.expired(e) {
+			continue
+		}
+		_ = util.WithFileLock(path, func() error {
+			if e, err := s.read(path); err == nil && s.expired(e) {
+				_ = os.Remove(path)
+				_ = os.Remove(path + ".lock")
+			}
+			return nil
+		})
+	}
+}
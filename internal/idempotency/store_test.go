@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, now *time.Time) *Store {
+	t.Helper()
+	s := NewStore(t.TempDir(), time.Hour)
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestStore_BeginCompleteReplay(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+	fp := Fingerprint("robot-send", "proj", "hello")
+
+	replay, err := s.Begin("robot-send", "k1", fp)
+	if err != nil || replay != nil {
+		t.Fatalf("first Begin = %v, %v; want nil, nil", replay, err)
+	}
+	if _, err := s.Begin("robot-send", "k1", fp); !errors.Is(err, ErrInProgress) {
+		t.Fatalf("concurrent Begin err = %v, want ErrInProgress", err)
+	}
+	if err := s.Complete("robot-send", "k1", "application/json", []byte(`{"success":true}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err = s.Begin("robot-send", "k1", fp)
+	if err != nil || replay == nil || string(replay.Body) != `{"success":true}` || replay.ContentType != "application/json" {
+		t.Fatalf("replay = %+v, %v", replay, err)
+	}
+	if _, err := s.Begin("robot-send", "k1", Fingerprint("robot-send", "proj", "bye")); !errors.Is(err, ErrMismatch) {
+		t.Errorf("different request err = %v, want ErrMismatch", err)
+	}
+	// Keys are scoped: the same key in another scope is independent.
+	if replay, err := s.Begin("robot-spawn", "k1", fp); err != nil || replay != nil {
+		t.Errorf("other scope Begin = %v, %v; want nil, nil", replay, err)
+	}
+
+	// After the TTL the key can be reused.
+	now = now.Add(2 * time.Hour)
+	if replay, err := s.Begin("robot-send", "k1", Fingerprint("other")); err != nil || replay != nil {
+		t.Errorf("Begin after TTL = %v, %v; want nil, nil", replay, err)
+	}
+}
+
+func TestStore_AbandonAndStalePending(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	if _, err := s.Begin("scope", "k", "fp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Abandon("scope", "k"); err != nil {
+		t.Fatal(err)
+	}
+	if replay, err := s.Begin("scope", "k", "fp"); err != nil || replay != nil {
+		t.Fatalf("Begin after Abandon = %v, %v", replay, err)
+	}
+
+	// A reservation left behind by a crashed command eventually lapses.
+	now = now.Add(PendingTimeout + time.Second)
+	if replay, err := s.Begin("scope", "k", "fp"); err != nil || replay != nil {
+		t.Fatalf("Begin after stale pending = %v, %v", replay, err)
+	}
+
+	// Abandon never discards a completed result.
+	if err := s.Complete("scope", "k", "", []byte("done")); err != nil {
+		t.Fatal(err)
+	}
+	_ = s.Abandon("scope", "k")
+	if replay, _ := s.Begin("scope", "k", "fp"); replay == nil {
+		t.Error("completed entry was removed by Abandon")
+	}
+}
+
+func TestStore_PruneAndValidate(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	s := newTestStore(t, &now)
+
+	_, _ = s.Begin("scope", "old", "fp")
+	_ = s.Complete("scope", "old", "", []byte("x"))
+	now = now.Add(2 * time.Hour)
+	_, _ = s.Begin("scope", "new", "fp")
+	_ = s.Complete("scope", "new", "", []byte("y"))
+
+	if _, err := os.Stat(s.path("scope", "old")); !os.IsNotExist(err) {
+		t.Error("expired entry should be pruned")
+	}
+	if _, err := os.Stat(s.path("scope", "new")); err != nil {
+		t.Errorf("live entry missing: %v", err)
+	}
+
+	if err := ValidateKey(" "); err == nil {
+		t.Error("blank key should be rejected")
+	}
+	if err := ValidateKey(strings.Repeat("k", MaxKeyLength+1)); err == nil {
+		t.Error("overlong key should be rejected")
+	}
+	if _, err := s.Begin("scope", "", "fp"); err == nil {
+		t.Error("Begin should validate the key")
+	}
+}
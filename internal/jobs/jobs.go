@@ -0,0 +1,528 @@
+// Package jobs runs long robot operations in the background. Submitting a
+// job records it and starts a detached worker, which runs the job's ntm
+// command and records how it ended. Callers poll the job from any process
+// instead of blocking for minutes.
+//
+// Each job has a directory under the store holding its record, the
+// command's output and what the command reports while it runs: commands
+// that take a while call Current() and report progress and partial
+// results through it.
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/process"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultDir is where jobs are stored.
+const DefaultDir = "~/.ntm/jobs"
+
+// DefaultRetention is how long finished jobs are kept.
+const DefaultRetention = 7 * 24 * time.Hour
+
+// Environment of a job's command, read by Current.
+const (
+	EnvJobID  = "NTM_JOB_ID"
+	EnvJobDir = "NTM_JOB_DIR"
+)
+
+// Files of a job directory.
+const (
+	JobFile      = "job.json"
+	ProgressFile = "progress.json"
+	PartialFile  = "partial.jsonl"
+	StdoutFile   = "stdout"
+	StderrFile   = "stderr"
+	CancelFile   = "cancel"
+)
+
+// pollInterval is how often a worker checks for cancellation.
+const pollInterval = 250 * time.Millisecond
+
+// ErrNotFound is returned for unknown job IDs.
+var ErrNotFound = errors.New("job not found")
+
+var validID = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// Status is a job's lifecycle state.
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Done reports whether the job has finished.
+func (s Status) Done() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// Job is a background ntm command.
+type Job struct {
+	ID              string    `json:"id"`
+	Args            []string  `json:"args"` // ntm arguments the job runs
+	Status          Status    `json:"status"`
+	Progress        float64   `json:"progress"` // Percent, as reported by the command
+	Message         string    `json:"message,omitempty"`
+	Partials        int       `json:"partials"` // Partial results reported so far
+	CancelRequested bool      `json:"cancel_requested,omitempty"`
+	PID             int       `json:"pid,omitempty"` // Worker process
+	ExitCode        *int      `json:"exit_code,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	StartedAt       time.Time `json:"started_at,omitzero"`
+	FinishedAt      time.Time `json:"finished_at,omitzero"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// progressRecord is what a running command reports in ProgressFile.
+type progressRecord struct {
+	Progress  float64   `json:"progress"`
+	Message   string    `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store keeps jobs in a directory.
+type Store struct {
+	// Dir holds one directory per job.
+	Dir string
+	// Exe is the ntm binary workers and commands run (the running
+	// executable if empty).
+	Exe string
+}
+
+// NewStore returns the store in dir (DefaultDir if empty).
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Store{Dir: util.ExpandPath(dir)}
+}
+
+func (s *Store) exe() (string, error) {
+	if s.Exe != "" {
+		return s.Exe, nil
+	}
+	return os.Executable()
+}
+
+func (s *Store) jobDir(id string) (string, error) {
+	if !validID.MatchString(id) {
+		return "", fmt.Errorf("invalid job id %q", id)
+	}
+	return filepath.Join(s.Dir, id), nil
+}
+
+// Create records a pending job for args. Submit also starts it.
+func (s *Store) Create(args []string) (*Job, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("job needs a command")
+	}
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	job := &Job{
+		ID:        now.Format("20060102T150405Z") + "-" + hex.EncodeToString(b[:]),
+		Args:      args,
+		Status:    StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	dir, _ := s.jobDir(job.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating job directory: %w", err)
+	}
+	if err := s.save(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Submit records a job for args and starts a detached worker for it.
+// Finished jobs older than DefaultRetention are removed.
+func (s *Store) Submit(args []string) (*Job, error) {
+	s.Prune(DefaultRetention)
+	job, err := s.Create(args)
+	if err != nil {
+		return nil, err
+	}
+	exe, err := s.exe()
+	if err == nil {
+		cmd := exec.Command(exe, "robot", "job", "run", job.ID)
+		cmd.Env = append(os.Environ(), EnvJobDir+"="+s.Dir)
+		setDetached(cmd)
+		if err = cmd.Start(); err == nil {
+			_ = cmd.Process.Release()
+			return job, nil
+		}
+	}
+	job.Status = StatusFailed
+	job.Error = fmt.Sprintf("starting worker: %v", err)
+	job.FinishedAt = time.Now().UTC()
+	_ = s.save(job)
+	return job, fmt.Errorf("starting job worker: %w", err)
+}
+
+// Get returns a job with the progress its command has reported. A job
+// whose worker died is reported as failed.
+func (s *Store) Get(id string) (*Job, error) {
+	dir, err := s.jobDir(id)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	job.Partials = countLines(filepath.Join(dir, PartialFile))
+	if job.Status.Done() {
+		return job, nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, CancelFile)); err == nil {
+		job.CancelRequested = true
+	}
+	s.overlayProgress(job)
+	if job.Status == StatusRunning && !process.IsAlive(job.PID) {
+		job.Status = StatusFailed
+		job.Error = "worker exited unexpectedly"
+	}
+	return job, nil
+}
+
+// List returns all jobs, newest first.
+func (s *Store) List() ([]*Job, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Job{}, nil
+		}
+		return nil, err
+	}
+	jobs := []*Job{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if job, err := s.Get(e.Name()); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID > jobs[j].ID
+	})
+	return jobs, nil
+}
+
+// Output returns what the job's command has written to stdout so far:
+// the result once the job completes.
+func (s *Store) Output(id string) ([]byte, error) {
+	if _, err := s.load(id); err != nil {
+		return nil, err
+	}
+	dir, _ := s.jobDir(id)
+	data, err := os.ReadFile(filepath.Join(dir, StdoutFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Partials returns the partial results the job's command has reported,
+// oldest first.
+func (s *Store) Partials(id string) ([]json.RawMessage, error) {
+	if _, err := s.load(id); err != nil {
+		return nil, err
+	}
+	dir, _ := s.jobDir(id)
+	f, err := os.Open(filepath.Join(dir, PartialFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []json.RawMessage{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	partials := []json.RawMessage{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if line := bytes.TrimSpace(sc.Bytes()); json.Valid(line) {
+			partials = append(partials, json.RawMessage(append([]byte(nil), line...)))
+		}
+	}
+	return partials, sc.Err()
+}
+
+// Cancel asks the job's worker to stop the job. A job that has not
+// started yet is cancelled at once.
+func (s *Store) Cancel(id string) (*Job, error) {
+	job, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.Done() {
+		return job, fmt.Errorf("job %s already %s", id, job.Status)
+	}
+	dir, _ := s.jobDir(id)
+	if err := os.WriteFile(filepath.Join(dir, CancelFile), nil, 0o644); err != nil {
+		return nil, err
+	}
+	if job.Status == StatusPending && job.PID == 0 {
+		s.finish(job, StatusCancelled, nil, "cancelled before start")
+	}
+	return s.Get(id)
+}
+
+// Run runs a pending job's command and records how it ended. It is the
+// worker started by Submit. Cancelling ctx or the job kills the command.
+func (s *Store) Run(ctx context.Context, id string) error {
+	dir, err := s.jobDir(id)
+	if err != nil {
+		return err
+	}
+	job, err := s.load(id)
+	if err != nil {
+		return err
+	}
+	if job.Status != StatusPending {
+		return fmt.Errorf("job %s already %s", id, job.Status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, CancelFile)); err == nil {
+		s.finish(job, StatusCancelled, nil, "cancelled before start")
+		return nil
+	}
+	exe, err := s.exe()
+	if err != nil {
+		s.finish(job, StatusFailed, nil, err.Error())
+		return err
+	}
+
+	stdout, err := os.Create(filepath.Join(dir, StdoutFile))
+	if err != nil {
+		s.finish(job, StatusFailed, nil, err.Error())
+		return err
+	}
+	defer stdout.Close()
+	stderr, err := os.Create(filepath.Join(dir, StderrFile))
+	if err != nil {
+		s.finish(job, StatusFailed, nil, err.Error())
+		return err
+	}
+	defer stderr.Close()
+
+	cmd := exec.Command(exe, job.Args...)
+	cmd.Env = append(os.Environ(), EnvJobID+"="+id, EnvJobDir+"="+s.Dir)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Start(); err != nil {
+		s.finish(job, StatusFailed, nil, err.Error())
+		return err
+	}
+	job.Status = StatusRunning
+	job.PID = os.Getpid()
+	job.StartedAt = time.Now().UTC()
+	if err := s.save(job); err != nil {
+		_ = cmd.Process.Kill()
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	cancelled := false
+	for {
+		select {
+		case err := <-done:
+			s.overlayProgress(job)
+			code := cmd.ProcessState.ExitCode()
+			switch {
+			case cancelled:
+				s.finish(job, StatusCancelled, &code, "cancelled")
+			case err != nil:
+				s.finish(job, StatusFailed, &code, failureMessage(filepath.Join(dir, StderrFile), err))
+			default:
+				job.Progress = 100
+				s.finish(job, StatusCompleted, &code, "")
+			}
+			return nil
+		case <-ctx.Done():
+			if !cancelled {
+				cancelled = true
+				_ = cmd.Process.Kill()
+			}
+		case <-ticker.C:
+			if _, err := os.Stat(filepath.Join(dir, CancelFile)); err == nil && !cancelled {
+				cancelled = true
+				_ = cmd.Process.Kill()
+			}
+		}
+	}
+}
+
+// Prune removes finished jobs that finished more than maxAge ago.
+func (s *Store) Prune(maxAge time.Duration) {
+	jobs, err := s.List()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for _, job := range jobs {
+		if job.Status.Done() && !job.FinishedAt.IsZero() && job.FinishedAt.Before(cutoff) {
+			_ = os.RemoveAll(filepath.Join(s.Dir, job.ID))
+		}
+	}
+}
+
+func (s *Store) finish(job *Job, status Status, exitCode *int, errMsg string) {
+	job.Status = status
+	job.ExitCode = exitCode
+	job.Error = errMsg
+	job.FinishedAt = time.Now().UTC()
+	_ = s.save(job)
+}
+
+func (s *Store) overlayProgress(job *Job) {
+	dir, _ := s.jobDir(job.ID)
+	data, err := os.ReadFile(filepath.Join(dir, ProgressFile))
+	if err != nil {
+		return
+	}
+	var p progressRecord
+	if json.Unmarshal(data, &p) == nil {
+		job.Progress, job.Message = p.Progress, p.Message
+		if p.UpdatedAt.After(job.UpdatedAt) {
+			job.UpdatedAt = p.UpdatedAt
+		}
+	}
+}
+
+func (s *Store) load(id string) (*Job, error) {
+	dir, err := s.jobDir(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, JobFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("reading job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+func (s *Store) save(job *Job) error {
+	dir, err := s.jobDir(job.ID)
+	if err != nil {
+		return err
+	}
+	job.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(filepath.Join(dir, JobFile), data, 0o644)
+}
+
+// failureMessage describes a failed command by the last line of its
+// stderr, or by its exit status.
+func failureMessage(stderrPath string, err error) string {
+	data, _ := os.ReadFile(stderrPath)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return err.Error()
+}
+
+func countLines(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+// Reporter reports a running job's progress and partial results. The
+// methods of a nil Reporter do nothing, so commands call them whether or
+// not they run as a job. Reporting is best effort.
+type Reporter struct {
+	dir string
+}
+
+// Current returns the reporter of the job this process runs as, or nil
+// when it is not a job.
+func Current() *Reporter {
+	id, dir := os.Getenv(EnvJobID), os.Getenv(EnvJobDir)
+	if id == "" || dir == "" {
+		return nil
+	}
+	return NewStore(dir).Reporter(id)
+}
+
+// Reporter returns the reporter of job id.
+func (s *Store) Reporter(id string) *Reporter {
+	dir, err := s.jobDir(id)
+	if err != nil {
+		return nil
+	}
+	return &Reporter{dir: dir}
+}
+
+// Progress reports the percentage done (clamped to 0-100) and what the
+// command is doing.
+func (r *Reporter) Progress(percent float64, message string) {
+	if r == nil {
+		return
+	}
+	data, err := json.Marshal(progressRecord{Progress: min(max(percent, 0), 100), Message: message, UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return
+	}
+	_ = util.AtomicWriteFile(filepath.Join(r.dir, ProgressFile), data, 0o644)
+}
+
+// Partial reports a partial result, available before the job completes.
+func (r *Reporter) Partial(v interface{}) {
+	if r == nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, PartialFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is the command of the test jobs, run by Store.Run as
+// the test binary itself.
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("JOBS_TEST_HELPER")
+	if mode == "" {
+		t.Skip("helper process")
+	}
+	rep := Current()
+	switch mode {
+	case "ok":
+		rep.Progress(40, "scanning")
+		rep.Partial(map[string]int{"found": 1})
+		rep.Partial(map[string]int{"found": 2})
+		fmt.Println(`{"success":true,"found":2}`)
+		os.Exit(0)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "warming up\nsearch index missing")
+		os.Exit(3)
+	case "slow":
+		rep.Progress(10, "waiting")
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
+
+func newTestStore(t *testing.T, mode string) *Store {
+	t.Helper()
+	t.Setenv("JOBS_TEST_HELPER", mode)
+	s := NewStore(t.TempDir())
+	s.Exe = os.Args[0]
+	return s
+}
+
+var helperArgs = []string{"-test.run=^TestHelperProcess$"}
+
+func TestStore_RunCompleted(t *testing.T) {
+	s := newTestStore(t, "ok")
+	job, err := s.Create(helperArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != StatusPending {
+		t.Fatalf("new job status = %s", job.Status)
+	}
+	if err := s.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got, err := s.Get(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusCompleted || got.Progress != 100 || got.Message != "scanning" || got.Partials != 2 {
+		t.Errorf("job = %+v", got)
+	}
+	if got.ExitCode == nil || *got.ExitCode != 0 || got.FinishedAt.IsZero() {
+		t.Errorf("exit = %v, finished %v", got.ExitCode, got.FinishedAt)
+	}
+	out, err := s.Output(job.ID)
+	if err != nil || !strings.Contains(string(out), `"found":2`) {
+		t.Errorf("Output = %q, %v", out, err)
+	}
+	partials, err := s.Partials(job.ID)
+	if err != nil || len(partials) != 2 || string(partials[1]) != `{"found":2}` {
+		t.Errorf("Partials = %s, %v", partials, err)
+	}
+	if err := s.Run(context.Background(), job.ID); err == nil {
+		t.Error("second Run of a finished job: want error")
+	}
+	if _, err := s.Cancel(job.ID); err == nil {
+		t.Error("Cancel of a finished job: want error")
+	}
+}
+
+func TestStore_RunFailed(t *testing.T) {
+	s := newTestStore(t, "fail")
+	job, err := s.Create(helperArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Run(context.Background(), job.ID); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got, _ := s.Get(job.ID)
+	if got.Status != StatusFailed || got.Error != "search index missing" || got.ExitCode == nil || *got.ExitCode != 3 {
+		t.Errorf("job = %+v", got)
+	}
+}
+
+func TestStore_Cancel(t *testing.T) {
+	s := newTestStore(t, "slow")
+	job, err := s.Create(helperArgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.Run(context.Background(), job.ID) }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		got, err := s.Get(job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status == StatusRunning && got.Message == "waiting" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job never reported progress: %+v", got)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	got, err := s.Cancel(job.ID)
+	if err != nil || !got.CancelRequested {
+		t.Fatalf("Cancel = %+v, %v", got, err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("cancelled job kept running")
+	}
+	if got, _ := s.Get(job.ID); got.Status != StatusCancelled || got.Progress != 10 {
+		t.Errorf("job = %+v", got)
+	}
+
+	// A job cancelled before its worker starts never runs.
+	pending, _ := s.Create(helperArgs)
+	if got, err := s.Cancel(pending.ID); err != nil || got.Status != StatusCancelled {
+		t.Errorf("Cancel pending = %+v, %v", got, err)
+	}
+	if err := s.Run(context.Background(), pending.ID); err == nil {
+		t.Error("Run of a cancelled job: want error")
+	}
+}
+
+func TestStore_ListGetPrune(t *testing.T) {
+	t.Parallel()
+	s := NewStore(t.TempDir())
+
+	if jobs, err := s.List(); err != nil || len(jobs) != 0 {
+		t.Fatalf("List of empty store = %v, %v", jobs, err)
+	}
+	if _, err := s.Get("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get unknown = %v", err)
+	}
+	if _, err := s.Get("../etc"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get with a path = %v, want invalid id", err)
+	}
+	if _, err := s.Create(nil); err == nil {
+		t.Error("Create without a command: want error")
+	}
+
+	old, _ := s.Create([]string{"robot", "status"})
+	recent, _ := s.Create([]string{"robot", "status"})
+	s.finish(old, StatusCompleted, nil, "")
+	old.FinishedAt = time.Now().Add(-48 * time.Hour)
+	if err := s.save(old); err != nil {
+		t.Fatal(err)
+	}
+
+	// A running job whose worker is gone is reported failed.
+	recent.Status, recent.PID = StatusRunning, 1<<30
+	if err := s.save(recent); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Get(recent.ID); got.Status != StatusFailed || got.Error == "" {
+		t.Errorf("orphaned job = %+v", got)
+	}
+
+	jobs, err := s.List()
+	if err != nil || len(jobs) != 2 || jobs[0].ID != recent.ID {
+		t.Fatalf("List = %+v, %v; want newest first", jobs, err)
+	}
+	s.Prune(24 * time.Hour)
+	if jobs, _ := s.List(); len(jobs) != 1 || jobs[0].ID != recent.ID {
+		t.Errorf("after Prune: %+v", jobs)
+	}
+}
+
+func TestSubmit_WorkerFailsToStart(t *testing.T) {
+	t.Parallel()
+	s := NewStore(t.TempDir())
+	s.Exe = filepath.Join(t.TempDir(), "missing-ntm")
+	job, err := s.Submit([]string{"robot", "status"})
+	if err == nil || job == nil || job.Status != StatusFailed {
+		t.Fatalf("Submit = %+v, %v", job, err)
+	}
+	got, _ := s.Get(job.ID)
+	if got.Status != StatusFailed || !strings.Contains(got.Error, "starting worker") {
+		t.Errorf("job = %+v", got)
+	}
+}
+
+func TestReporter(t *testing.T) {
+	t.Parallel()
+	var nilRep *Reporter
+	nilRep.Progress(50, "ignored")
+	nilRep.Partial("ignored")
+
+	s := NewStore(t.TempDir())
+	job, _ := s.Create([]string{"robot", "status"})
+	rep := s.Reporter(job.ID)
+	rep.Progress(250, "over")
+	rep.Partial(json.RawMessage(`{"a":1}`))
+	got, _ := s.Get(job.ID)
+	if got.Progress != 100 || got.Message != "over" || got.Partials != 1 {
+		t.Errorf("job = %+v", got)
+	}
+	if s.Reporter("../x") != nil {
+		t.Error("Reporter with a path: want nil")
+	}
+}
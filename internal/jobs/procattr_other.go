@@ -0,0 +1,9 @@
+//go:build !unix
+
+package jobs
+
+import "os/exec"
+
+// setDetached is a no-op where sessions are not available; the worker
+// still outlives the submitting process.
+func setDetached(cmd *exec.Cmd) {}
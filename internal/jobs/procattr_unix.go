@@ -0,0 +1,14 @@
+//go:build unix
+
+package jobs
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setDetached starts the worker in its own session so it outlives the
+// submitting process and its terminal.
+func setDetached(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
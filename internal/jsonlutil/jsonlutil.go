@@ -0,0 +1,333 @@
+// Package jsonlutil provides tolerant readers and repair helpers for the JSONL
+// files ntm appends to (audit logs, scores, archives, event logs).
+//
+// Appends interrupted by a crash or power loss leave partial or garbled lines
+// behind. The readers here skip such lines and report them instead of failing
+// the whole file, and Repair moves them aside so later appends start from a
+// clean record boundary.
+package jsonlutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// MaxLineSize is the longest line accepted as a record. Longer lines are
+// reported as bad rather than aborting the scan.
+const MaxLineSize = 10 * 1024 * 1024
+
+// BadLine describes a line that could not be parsed.
+type BadLine struct {
+	Line   int    `json:"line"`
+	Offset int64  `json:"offset"`
+	Reason string `json:"reason"`
+	Data   []byte `json:"-"`
+}
+
+// Result summarizes a tolerant scan.
+type Result struct {
+	Lines int       `json:"lines"`
+	Valid int       `json:"valid"`
+	Bad   []BadLine `json:"bad,omitempty"`
+}
+
+// OK reports whether every line was valid.
+func (r *Result) OK() bool {
+	return r == nil || len(r.Bad) == 0
+}
+
+// Options configures a scan.
+type Options struct {
+	// Validate checks a non-empty line. Defaults to ValidRecord.
+	Validate func(line []byte) error
+}
+
+// ValidRecord accepts a line holding a JSON value, or an encrypted record
+// (base64 ciphertext, as written when at-rest encryption is enabled).
+func ValidRecord(line []byte) error {
+	if len(line) == 0 {
+		return errors.New("empty line")
+	}
+	if line[0] == '{' || line[0] == '[' {
+		if !json.Valid(line) {
+			return errors.New("invalid JSON")
+		}
+		return nil
+	}
+	if _, err := base64.StdEncoding.DecodeString(string(line)); err != nil {
+		return errors.New("neither JSON nor an encrypted record")
+	}
+	return nil
+}
+
+// Scan reads newline-delimited records from r, calling fn with each valid
+// line (1-based line number). Invalid lines are recorded in the result and
+// skipped. Blank lines are ignored. The line slice passed to fn is only valid
+// for the duration of the call. An error returned by fn stops the scan.
+func Scan(r io.Reader, opts Options, fn func(lineNo int, line []byte) error) (*Result, error) {
+	validate := opts.Validate
+	if validate == nil {
+		validate = ValidRecord
+	}
+
+	res := &Result{}
+	br := bufio.NewReaderSize(r, 64*1024)
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		raw, n, readErr := readLine(br)
+		if readErr != nil && readErr != io.EOF && readErr != errLineTooLong {
+			return res, readErr
+		}
+		start := offset
+		offset += n
+
+		line := bytes.TrimRight(raw, "\r\n")
+		if len(bytes.TrimSpace(line)) > 0 {
+			res.Lines++
+			var reason string
+			if readErr == errLineTooLong {
+				reason = fmt.Sprintf("line exceeds %d bytes", MaxLineSize)
+			} else if err := validate(line); err != nil {
+				reason = err.Error()
+				if readErr == io.EOF && !bytes.HasSuffix(raw, []byte("\n")) {
+					reason = "truncated record at end of file"
+				}
+			}
+			if reason != "" {
+				res.Bad = append(res.Bad, BadLine{
+					Line:   lineNo,
+					Offset: start,
+					Reason: reason,
+					Data:   append([]byte(nil), line...),
+				})
+			} else {
+				res.Valid++
+				if fn != nil {
+					if err := fn(lineNo, line); err != nil {
+						return res, err
+					}
+				}
+			}
+		}
+
+		if readErr == io.EOF {
+			return res, nil
+		}
+	}
+}
+
+var errLineTooLong = errors.New("line too long")
+
+// readLine returns the next line including its newline and the number of
+// bytes consumed. Lines longer than MaxLineSize are consumed in full but
+// returned truncated, with errLineTooLong.
+func readLine(br *bufio.Reader) ([]byte, int64, error) {
+	var buf []byte
+	var n int64
+	tooLong := false
+	for {
+		chunk, err := br.ReadSlice('\n')
+		n += int64(len(chunk))
+		if !tooLong {
+			if len(buf)+len(chunk) > MaxLineSize {
+				tooLong = true
+			} else {
+				buf = append(buf, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLong {
+			return buf, n, errLineTooLong
+		}
+		return buf, n, err
+	}
+}
+
+// ScanFile is Scan over the file at path. A missing file yields an empty
+// result and no error.
+func ScanFile(path string, opts Options, fn func(lineNo int, line []byte) error) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Result{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return Scan(f, opts, fn)
+}
+
+// ReadFile decodes every record in path into a T. Lines that are not valid
+// JSON or do not decode into T are skipped and reported in the result.
+func ReadFile[T any](path string) ([]T, *Result, error) {
+	var out []T
+	var decodeBad []BadLine
+	res, err := ScanFile(path, Options{Validate: validJSON}, func(lineNo int, line []byte) error {
+		var v T
+		if err := json.Unmarshal(line, &v); err != nil {
+			decodeBad = append(decodeBad, BadLine{
+				Line:   lineNo,
+				Reason: err.Error(),
+				Data:   append([]byte(nil), line...),
+			})
+			return nil
+		}
+		out = append(out, v)
+		return nil
+	})
+	if res != nil && len(decodeBad) > 0 {
+		res.Valid -= len(decodeBad)
+		res.Bad = append(res.Bad, decodeBad...)
+	}
+	return out, res, err
+}
+
+func validJSON(line []byte) error {
+	if !json.Valid(line) {
+		return errors.New("invalid JSON")
+	}
+	return nil
+}
+
+// TruncatePartialLine removes a trailing record that was not terminated by a
+// newline, which is what an interrupted append leaves behind. Without this a
+// later append would be glued onto the fragment, corrupting both records and
+// (for audit logs) breaking the hash chain. It returns the number of bytes
+// removed.
+func TruncatePartialLine(path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return 0, nil
+	}
+
+	// Scan backwards for the last newline.
+	const chunk = 4096
+	buf := make([]byte, chunk)
+	end := size
+	for end > 0 {
+		start := end - chunk
+		if start < 0 {
+			start = 0
+		}
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := n - 1; i >= 0; i-- {
+			if buf[i] != '\n' {
+				continue
+			}
+			keep := start + int64(i) + 1
+			if keep == size {
+				return 0, nil
+			}
+			return size - keep, truncateSync(f, keep)
+		}
+		end = start
+	}
+	// No newline at all: the whole file is a single partial record.
+	return size, truncateSync(f, 0)
+}
+
+func truncateSync(f *os.File, size int64) error {
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// RepairResult describes a repaired file.
+type RepairResult struct {
+	Path       string `json:"path"`
+	Removed    int    `json:"removed"`
+	Quarantine string `json:"quarantine,omitempty"`
+}
+
+// Repair rewrites path without its bad lines, first copying them to a
+// timestamped file under quarantineDir so nothing is lost. It returns nil if
+// the file had no bad lines. The rewrite is atomic: readers see either the
+// old or the repaired file.
+func Repair(path string, opts Options, quarantineDir string) (*RepairResult, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept bytes.Buffer
+	res, err := ScanFile(path, opts, func(_ int, line []byte) error {
+		kept.Write(line)
+		kept.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.OK() {
+		return nil, nil
+	}
+
+	out := &RepairResult{Path: path, Removed: len(res.Bad)}
+	if quarantineDir != "" {
+		qpath, err := quarantine(path, quarantineDir, res.Bad)
+		if err != nil {
+			return nil, fmt.Errorf("quarantining bad lines: %w", err)
+		}
+		out.Quarantine = qpath
+	}
+	if err := util.AtomicWriteFile(path, kept.Bytes(), info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("rewriting %s: %w", path, err)
+	}
+	return out, nil
+}
+
+// quarantine writes bad lines verbatim, one per line, preceded by a comment
+// header naming their source.
+func quarantine(path, dir string, bad []BadLine) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s.%s.bad", filepath.Base(path), time.Now().UTC().Format("20060102T150405Z"))
+	qpath := filepath.Join(dir, name)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# quarantined from %s\n", path)
+	for _, b := range bad {
+		fmt.Fprintf(&buf, "# line %d (offset %d): %s\n", b.Line, b.Offset, b.Reason)
+		buf.Write(b.Data)
+		buf.WriteByte('\n')
+	}
+	f, err := os.OpenFile(qpath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return "", err
+	}
+	return qpath, f.Close()
+}
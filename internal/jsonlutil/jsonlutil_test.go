@@ -0,0 +1,170 @@
+package jsonlutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanSkipsAndReportsBadLines(t *testing.T) {
+	input := "{\"a\":1}\n\nnot json {\n{\"b\":2}\n{\"c\":"
+	var got []string
+	res, err := Scan(strings.NewReader(input), Options{}, func(lineNo int, line []byte) error {
+		got = append(got, string(line))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if strings.Join(got, "|") != "{\"a\":1}|{\"b\":2}" {
+		t.Fatalf("valid lines = %v", got)
+	}
+	if res.Lines != 4 || res.Valid != 2 || len(res.Bad) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+	if res.Bad[0].Line != 3 || res.Bad[0].Offset != 9 {
+		t.Errorf("first bad line = %+v, want line 3 offset 9", res.Bad[0])
+	}
+	if res.Bad[1].Line != 5 || res.Bad[1].Reason != "truncated record at end of file" {
+		t.Errorf("second bad line = %+v", res.Bad[1])
+	}
+}
+
+func TestValidRecordAcceptsEncryptedLines(t *testing.T) {
+	if err := ValidRecord([]byte("c2VjcmV0IGRhdGE=")); err != nil {
+		t.Errorf("base64 record rejected: %v", err)
+	}
+	if err := ValidRecord([]byte("garbage!!")); err == nil {
+		t.Error("garbage accepted")
+	}
+}
+
+func TestReadFileDecodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scores.jsonl")
+	content := "{\"name\":\"a\",\"n\":1}\n{\"name\":\"b\",\"n\":\"oops\"}\n{\"name\":\"c\",\"n\":3}\n{\"name\""
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	type rec struct {
+		Name string `json:"name"`
+		N    int    `json:"n"`
+	}
+	recs, res, err := ReadFile[rec](path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(recs) != 2 || recs[0].Name != "a" || recs[1].Name != "c" {
+		t.Fatalf("records = %+v", recs)
+	}
+	if res.Valid != 2 || len(res.Bad) != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+
+	if recs, res, err := ReadFile[rec](filepath.Join(t.TempDir(), "missing.jsonl")); err != nil || len(recs) != 0 || !res.OK() {
+		t.Fatalf("missing file: %v %+v %v", recs, res, err)
+	}
+}
+
+func TestScanLongLine(t *testing.T) {
+	input := "{\"a\":1}\n\"" + strings.Repeat("x", MaxLineSize+10) + "\"\n{\"b\":2}\n"
+	res, err := Scan(strings.NewReader(input), Options{}, nil)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if res.Valid != 2 || len(res.Bad) != 1 || res.Bad[0].Line != 2 {
+		t.Fatalf("result = %+v", res)
+	}
+}
+
+func TestRepairQuarantinesBadLines(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "archive.jsonl")
+	if err := os.WriteFile(path, []byte("{\"a\":1}\n{broken\n{\"b\":2}\n{\"c\""), 0640); err != nil {
+		t.Fatal(err)
+	}
+	qdir := filepath.Join(dir, "quarantine")
+
+	res, err := Repair(path, Options{}, qdir)
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if res == nil || res.Removed != 2 {
+		t.Fatalf("repair result = %+v", res)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "{\"a\":1}\n{\"b\":2}\n" {
+		t.Fatalf("repaired content = %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640", info.Mode().Perm())
+	}
+	quarantined, err := os.ReadFile(res.Quarantine)
+	if err != nil {
+		t.Fatalf("reading quarantine: %v", err)
+	}
+	for _, want := range []string{"{broken", "{\"c\"", "line 2", "line 4"} {
+		if !strings.Contains(string(quarantined), want) {
+			t.Errorf("quarantine missing %q:\n%s", want, quarantined)
+		}
+	}
+
+	// A clean file is left alone.
+	res, err = Repair(path, Options{}, qdir)
+	if err != nil || res != nil {
+		t.Fatalf("second repair: %+v %v", res, err)
+	}
+}
+
+func TestTruncatePartialLine(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		removed int64
+	}{
+		{"empty", "", "", 0},
+		{"complete", "{\"a\":1}\n{\"b\":2}\n", "{\"a\":1}\n{\"b\":2}\n", 0},
+		{"partial tail", "{\"a\":1}\n{\"b\":", "{\"a\":1}\n", 5},
+		{"single partial", "{\"a\"", "", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			removed, err := TruncatePartialLine(path)
+			if err != nil {
+				t.Fatalf("TruncatePartialLine: %v", err)
+			}
+			if removed != tt.removed {
+				t.Errorf("removed = %d, want %d", removed, tt.removed)
+			}
+			data, _ := os.ReadFile(path)
+			if string(data) != tt.want {
+				t.Errorf("content = %q, want %q", data, tt.want)
+			}
+		})
+	}
+
+	if n, err := TruncatePartialLine(filepath.Join(dir, "missing.jsonl")); err != nil || n != 0 {
+		t.Fatalf("missing file: n=%d err=%v", n, err)
+	}
+}
+
+func TestTruncatePartialLineLongTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.jsonl")
+	tail := strings.Repeat("x", 10000)
+	if err := os.WriteFile(path, []byte("{\"ok\":true}\n"+tail), 0644); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := TruncatePartialLine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != int64(len(tail)) {
+		t.Fatalf("removed = %d, want %d", removed, len(tail))
+	}
+}
@@ -43,12 +43,9 @@ func NewCollector(store *state.Store, sessionID string) *Collector {
 
 // subscribeToEvents registers handlers for relevant events.
 func (c *Collector) subscribeToEvents() {
-	c.unsubscribe = events.SubscribeAll(func(e events.BusEvent) {
-		switch evt := e.(type) {
-		case events.AgentErrorEvent:
-			if evt.ErrorType == "blocked_command" {
-				c.RecordBlockedCommand(evt.AgentID, evt.Message, "policy")
-			}
+	c.unsubscribe = events.SubscribeTyped(events.DefaultBus, events.BusAgentError, func(evt events.AgentErrorEvent) {
+		if evt.ErrorType == "blocked_command" {
+			c.RecordBlockedCommand(evt.AgentID, evt.Message, "policy")
 		}
 	})
 }
@@ -38,6 +38,7 @@ const (
 	EventSessionCreated EventType = "session.created"  // New session spawned
 	EventSessionKilled  EventType = "session.killed"   // Session terminated
 	EventHealthDegraded EventType = "health.degraded"  // Overall health dropped
+	EventScheduleFailed EventType = "schedule.failed"  // Scheduled fleet run failed
 )
 
 // Event represents a notification event
@@ -106,7 +107,7 @@ type FileBoxConfig struct {
 func DefaultConfig() Config {
 	return Config{
 		Enabled:  true,
-		Events:   []string{string(EventAgentError), string(EventAgentCrashed)},
+		Events:   []string{string(EventAgentError), string(EventAgentCrashed), string(EventScheduleFailed)},
 		Primary:  "desktop",
 		Fallback: "filebox",
 		Routing:  nil, // Use default (all enabled channels in parallel)
@@ -781,3 +782,16 @@ func NewHealthDegradedEvent(session string, healthy, warning, error int) Event {
 		},
 	}
 }
+
+// NewScheduleFailedEvent creates a scheduled run failed notification event
+func NewScheduleFailedEvent(schedule, runID, session, reason string) Event {
+	return Event{
+		Type:    EventScheduleFailed,
+		Session: session,
+		Message: fmt.Sprintf("Scheduled run %s failed: %s", schedule, reason),
+		Details: map[string]string{
+			"schedule": schedule,
+			"run_id":   runID,
+		},
+	}
+}
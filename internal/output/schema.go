@@ -79,6 +79,11 @@ type PaneResponse struct {
 	ContextLimit   int     `json:"context_limit,omitempty"`
 	ContextPercent float64 `json:"context_percent,omitempty"`
 	ContextModel   string  `json:"context_model,omitempty"`
+	Account        string  `json:"account,omitempty"`        // Pool account assigned at spawn
+	CPUPercent     float64 `json:"cpu_percent,omitempty"`    // Process tree CPU; 100 = one core
+	MemoryBytes    uint64  `json:"memory_bytes,omitempty"`   // Process tree resident memory
+	MemoryPercent  float64 `json:"memory_percent,omitempty"` // Share of system memory
+	Processes      int     `json:"processes,omitempty"`      // Processes in the pane's tree
 }
 
 // AgentCountsResponse is the standard format for agent counts
@@ -289,6 +294,7 @@ type AssignmentStats struct {
 	Total      int `json:"total"`
 	Assigned   int `json:"assigned"`
 	Working    int `json:"working"`
+	Verifying  int `json:"verifying,omitempty"`
 	Completed  int `json:"completed"`
 	Failed     int `json:"failed"`
 	Reassigned int `json:"reassigned"`
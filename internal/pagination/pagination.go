@@ -0,0 +1,122 @@
+// Package pagination implements cursor-based paging for list outputs.
+//
+// A cursor is an opaque token naming the sort key of the last item on the
+// previous page. The next page starts strictly after that key, so items
+// added or removed between requests never cause entries to be skipped or
+// repeated, as they would with offsets.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// DefaultLimit is the page size used when a caller asks for a page without
+// naming a limit.
+const DefaultLimit = 100
+
+// MaxLimit bounds the page size a caller may request.
+const MaxLimit = 1000
+
+// ErrInvalidCursor reports a cursor that was not produced by Encode.
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Encode returns the opaque cursor for the position after an item with the
+// given sort key. Multi-column keys are passed as separate parts.
+func Encode(key ...string) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode returns the sort key parts of a cursor produced by Encode. An empty
+// cursor decodes to nil.
+func Decode(cursor string) ([]string, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var key []string
+	if err := json.Unmarshal(data, &key); err != nil || len(key) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
+
+// ParseLimit parses a limit query value. Empty means def; values above
+// MaxLimit are clamped.
+func ParseLimit(raw string, def int) (int, error) {
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("limit must be a positive integer, got %q", raw)
+	}
+	if n > MaxLimit {
+		n = MaxLimit
+	}
+	return n, nil
+}
+
+// Page is one page of a list.
+type Page[T any] struct {
+	Items []T
+	// Offset is the position of the first item in the full ordering.
+	Offset int
+	// Total counts the items across all pages.
+	Total int
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// HasMore reports whether another page follows.
+func (p Page[T]) HasMore() bool { return p.NextCursor != "" }
+
+// Slice pages items ordered by key, ascending or descending. Keys must be
+// unique; items are sorted stably by key first, so callers need not
+// pre-sort. A limit <= 0 returns every item after the cursor.
+func Slice[T any](items []T, key func(T) string, desc bool, cursor string, limit int) (Page[T], error) {
+	after, err := Decode(cursor)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	if len(after) > 1 {
+		return Page[T]{}, ErrInvalidCursor
+	}
+
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if desc {
+			return key(sorted[i]) > key(sorted[j])
+		}
+		return key(sorted[i]) < key(sorted[j])
+	})
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(sorted), func(i int) bool {
+			if desc {
+				return key(sorted[i]) < after[0]
+			}
+			return key(sorted[i]) > after[0]
+		})
+	}
+	end := len(sorted)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	page := Page[T]{Items: sorted[start:end], Offset: start, Total: len(sorted)}
+	if end < len(sorted) && end > start {
+		page.NextCursor = Encode(key(sorted[end-1]))
+	}
+	return page, nil
+}
@@ -0,0 +1,75 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlice_WalksAllPages(t *testing.T) {
+	items := []string{"d", "a", "c", "e", "b"}
+	id := func(s string) string { return s }
+
+	var got []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := Slice(items, id, false, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != 5 {
+			t.Errorf("Total = %d, want 5", page.Total)
+		}
+		got = append(got, page.Items...)
+		if !page.HasMore() {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("walked %v, want %v", got, want)
+	}
+}
+
+func TestSlice_StableUnderInsertAndDelete(t *testing.T) {
+	id := func(s string) string { return s }
+	first, _ := Slice([]string{"e", "d", "c", "b", "a"}, id, true, "", 2)
+	if !reflect.DeepEqual(first.Items, []string{"e", "d"}) {
+		t.Fatalf("first page = %v", first.Items)
+	}
+
+	// "d" (the cursor item) is removed and "f" is added before the next
+	// request; the second page still continues right after "d".
+	second, err := Slice([]string{"f", "e", "c", "b", "a"}, id, true, first.NextCursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(second.Items, []string{"c", "b"}) {
+		t.Errorf("second page = %v, want [c b]", second.Items)
+	}
+}
+
+func TestDecodeAndParseLimit(t *testing.T) {
+	if key, err := Decode(Encode("2026-01-01", "id-1")); err != nil || !reflect.DeepEqual(key, []string{"2026-01-01", "id-1"}) {
+		t.Errorf("round trip = %v, %v", key, err)
+	}
+	for _, bad := range []string{"!!", Encode(), "bnVsbA"} {
+		if _, err := Decode(bad); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Decode(%q) err = %v, want ErrInvalidCursor", bad, err)
+		}
+	}
+
+	if n, err := ParseLimit("", 50); err != nil || n != 50 {
+		t.Errorf("ParseLimit default = %d, %v", n, err)
+	}
+	if n, _ := ParseLimit(strings.Repeat("9", 6), 50); n != MaxLimit {
+		t.Errorf("ParseLimit should clamp to MaxLimit, got %d", n)
+	}
+	if _, err := ParseLimit("0", 50); err == nil {
+		t.Error("zero limit should be rejected")
+	}
+}
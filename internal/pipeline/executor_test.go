@@ -955,6 +955,7 @@ func TestExecutor_ResolvePrompt(t *testing.T) {
 // Integration-style test for the execution workflow
 func TestExecutor_Run_ValidationError(t *testing.T) {
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	e := NewExecutor(cfg)
 
 	// Create workflow with circular dependency
@@ -1307,6 +1308,7 @@ func TestExecutor_Run_DryRun(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -1352,6 +1354,7 @@ func TestExecutor_Run_DryRun_WithVariables(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -1388,6 +1391,7 @@ func TestExecutor_Run_DryRun_WithConditional(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -1428,6 +1432,7 @@ func TestExecutor_Resume_DryRun(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -1572,6 +1577,7 @@ func TestExecutor_Run_DryRun_ProgressEvents(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -1725,7 +1731,8 @@ func TestPersistState_NilState(t *testing.T) {
 
 // TestPersistState_EmptyProjectDir tests persistState with empty project dir
 func TestPersistState_EmptyProjectDir(t *testing.T) {
-	t.Parallel()
+	// Falls back to the working directory.
+	t.Chdir(t.TempDir())
 
 	cfg := DefaultExecutorConfig("test")
 	cfg.ProjectDir = "" // Empty project dir
@@ -1802,6 +1809,7 @@ func TestExecutor_Run_DryRun_WithParallel(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -1838,6 +1846,7 @@ func TestExecutor_Run_DryRun_WithLoop(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2034,6 +2043,7 @@ func TestResume_CompletedStepsPreserved(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2073,6 +2083,7 @@ func TestResume_FillsDefaults(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	cfg.RunID = "config-run-id"
 	cfg.WorkflowFile = "test.yaml"
@@ -2219,6 +2230,7 @@ func TestExecutor_Run_DryRun_WithConditions(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2262,6 +2274,7 @@ func TestExecutor_Run_DryRun_WithOutputVars(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2296,6 +2309,7 @@ func TestExecutor_Run_DryRun_WithWhileLoop(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2333,6 +2347,7 @@ func TestExecutor_Run_DryRun_Cancel(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2365,6 +2380,7 @@ func TestExecutor_Run_DryRun_WithTimesLoop(t *testing.T) {
 	t.Parallel()
 
 	cfg := DefaultExecutorConfig("test-session")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2664,6 +2680,7 @@ func TestExecutor_Run_DryRun_FailedDependency(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2693,6 +2710,7 @@ func TestExecutor_Run_DryRun_WhenConditionTrue(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2716,6 +2734,7 @@ func TestExecutor_Run_DryRun_WhenConditionFalse(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2740,6 +2759,7 @@ func TestExecutor_Run_DryRun_OutputVar(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2769,6 +2789,7 @@ func TestExecutor_Run_DryRun_PromptFile(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -2792,6 +2813,7 @@ func TestExecutor_Run_DryRun_MissingPromptFile(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -9,8 +9,9 @@ import (
 )
 
 // createTestExecutor creates a configured executor for testing
-func createTestExecutor() (*Executor, *Workflow) {
+func createTestExecutor(t *testing.T) (*Executor, *Workflow) {
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 
@@ -46,7 +47,7 @@ func createTestExecutor() (*Executor, *Workflow) {
 func TestExecuteParallel_BasicExecution(t *testing.T) {
 	t.Parallel()
 
-	e, workflow := createTestExecutor()
+	e, workflow := createTestExecutor(t)
 
 	// Create a parallel group with 3 steps
 	step := &Step{
@@ -106,7 +107,7 @@ func TestExecuteParallel_ErrorModes(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			e, workflow := createTestExecutor()
+			e, workflow := createTestExecutor(t)
 			workflow.Settings.OnError = tt.onError
 
 			step := &Step{
@@ -129,7 +130,7 @@ func TestExecuteParallel_ErrorModes(t *testing.T) {
 func TestExecuteParallel_GroupTimeout(t *testing.T) {
 	t.Parallel()
 
-	e, workflow := createTestExecutor()
+	e, workflow := createTestExecutor(t)
 
 	// Create a parallel group with a timeout
 	// In dry run mode, steps complete instantly, so timeout won't be hit
@@ -154,7 +155,7 @@ func TestExecuteParallel_GroupTimeout(t *testing.T) {
 func TestExecuteParallel_ContextCancellation(t *testing.T) {
 	t.Parallel()
 
-	e, workflow := createTestExecutor()
+	e, workflow := createTestExecutor(t)
 
 	step := &Step{
 		ID: "parallel_group",
@@ -179,7 +180,7 @@ func TestExecuteParallel_ContextCancellation(t *testing.T) {
 func TestExecuteParallel_ResultAggregation(t *testing.T) {
 	t.Parallel()
 
-	e, _ := createTestExecutor()
+	e, _ := createTestExecutor(t)
 
 	// Create workflow with task_a and task_b for this test
 	workflow := &Workflow{
@@ -260,6 +261,7 @@ func TestExecuteParallel_Concurrency(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 	e.graph = NewDependencyGraph(workflow)
@@ -353,6 +355,7 @@ func TestExecuteParallelStep_WithCondition(t *testing.T) {
 	}
 
 	cfg := DefaultExecutorConfig("test")
+	cfg.ProjectDir = t.TempDir()
 	cfg.DryRun = true
 	e := NewExecutor(cfg)
 	e.graph = NewDependencyGraph(workflow)
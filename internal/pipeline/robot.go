@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
 )
 
 // PipelineRegistry tracks running pipeline executions
@@ -28,11 +30,13 @@ const (
 
 // RobotResponse is the base structure for robot command outputs
 type RobotResponse struct {
-	Success   bool   `json:"success"`
-	Timestamp string `json:"timestamp"`
-	Error     string `json:"error,omitempty"`
-	ErrorCode string `json:"error_code,omitempty"`
-	Hint      string `json:"hint,omitempty"`
+	Success     bool               `json:"success"`
+	Timestamp   string             `json:"timestamp"`
+	Error       string             `json:"error,omitempty"`
+	ErrorCode   string             `json:"error_code,omitempty"`
+	Hint        string             `json:"hint,omitempty"`
+	ErrorID     string             `json:"error_id,omitempty"`
+	Remediation *robot.Remediation `json:"remediation,omitempty"`
 }
 
 // NewRobotResponse creates a new robot response
@@ -43,14 +47,18 @@ func NewRobotResponse(success bool) RobotResponse {
 	}
 }
 
-// NewErrorResponse creates an error robot response
+// NewErrorResponse creates an error robot response classified by the robot
+// error taxonomy
 func NewErrorResponse(err error, code string, hint string) RobotResponse {
+	info, _ := robot.LookupErrorCode(code)
 	return RobotResponse{
-		Success:   false,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
-		Error:     err.Error(),
-		ErrorCode: code,
-		Hint:      hint,
+		Success:     false,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Error:       err.Error(),
+		ErrorCode:   code,
+		Hint:        hint,
+		ErrorID:     info.ID,
+		Remediation: robot.RemediationFor(code, hint),
 	}
 }
 
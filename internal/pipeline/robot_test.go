@@ -1328,6 +1328,8 @@ func TestUpdatePipelineFromState_WithErrors(t *testing.T) {
 
 func TestPrintPipelineRun_DryRun(t *testing.T) {
 	tmpDir := t.TempDir()
+	// Run state is persisted under the working directory.
+	t.Chdir(tmpDir)
 
 	workflowContent := `schema_version: "1.0"
 name: dry-run-test
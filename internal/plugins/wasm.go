@@ -0,0 +1,366 @@
+package plugins
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/Dicklesworthstone/ntm/internal/codeblock"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+)
+
+// WASM plugin ABI. A module exports its linear memory, an allocator, and
+// one or more entry points. The host allocates room for a JSON request
+// with ntm_alloc(len) -> ptr, writes it there, and calls the entry point
+// with (ptr, len). The entry point returns the location of its JSON
+// response packed as ptr<<32 | len. A response may set "error" to fail the
+// call.
+//
+// Plugins may import ntm.log(ptr, len) to write a debug log line, and WASI
+// preview 1 so common toolchains work, but get no files, network,
+// environment, or arguments: clocks and randomness are deterministic.
+const (
+	WasmExportAlloc  = "ntm_alloc"
+	WasmHostModule   = "ntm"
+	wasmExportMemory = "memory"
+)
+
+// WasmKind is an extension point a WASM plugin implements.
+type WasmKind string
+
+// WASM plugin kinds, each named for its ntm_<kind> entry point.
+const (
+	WasmExtract  WasmKind = "extract"  // Extract code blocks from pane output
+	WasmEvaluate WasmKind = "evaluate" // Score work quality as a quality evaluator
+	WasmCheck    WasmKind = "check"    // Check commands against policy
+)
+
+// AllWasmKinds returns all WASM plugin kinds.
+func AllWasmKinds() []WasmKind {
+	return []WasmKind{WasmExtract, WasmEvaluate, WasmCheck}
+}
+
+// Export returns the name of the kind's entry point.
+func (k WasmKind) Export() string {
+	return "ntm_" + string(k)
+}
+
+// Default WASM limits
+const (
+	DefaultWasmMemoryLimitMB = 16
+	DefaultWasmTimeout       = time.Second
+)
+
+// WasmOptions limits what a plugin call may use.
+type WasmOptions struct {
+	MemoryLimitMB int           // Linear memory an instance may grow to (0 = default)
+	Timeout       time.Duration // Per-call time limit (0 = default)
+}
+
+// WasmRuntime compiles and runs WASM plugins. It is safe for concurrent
+// use: each call runs in a fresh instance of the plugin's module.
+type WasmRuntime struct {
+	rt      wazero.Runtime
+	timeout time.Duration
+}
+
+// NewWasmRuntime creates a runtime with the given limits.
+func NewWasmRuntime(ctx context.Context, opts WasmOptions) (*WasmRuntime, error) {
+	mb := opts.MemoryLimitMB
+	if mb <= 0 {
+		mb = DefaultWasmMemoryLimitMB
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWasmTimeout
+	}
+
+	// A page is 64KiB
+	cfg := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(mb) * 16).
+		WithCloseOnContextDone(true)
+	rt := wazero.NewRuntimeWithConfig(ctx, cfg)
+
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, rt); err != nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("instantiating wasi: %w", err)
+	}
+	_, err := rt.NewHostModuleBuilder(WasmHostModule).
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, m api.Module, ptr, n uint32) {
+			if msg, ok := m.Memory().Read(ptr, n); ok {
+				name, _ := ctx.Value(wasmPluginKey{}).(string)
+				slog.Debug("wasm plugin", "plugin", name, "msg", string(msg))
+			}
+		}).
+		Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		rt.Close(ctx)
+		return nil, fmt.Errorf("instantiating host module: %w", err)
+	}
+	return &WasmRuntime{rt: rt, timeout: timeout}, nil
+}
+
+// Close releases the runtime and every plugin compiled by it.
+func (r *WasmRuntime) Close(ctx context.Context) error {
+	return r.rt.Close(ctx)
+}
+
+// WasmPlugin is a compiled WASM plugin module.
+type WasmPlugin struct {
+	Name  string     `json:"name"`
+	Path  string     `json:"path,omitempty"`
+	Kinds []WasmKind `json:"kinds"`
+
+	runtime  *WasmRuntime
+	compiled wazero.CompiledModule
+}
+
+// Load compiles the plugin at path, named for its file name.
+func (r *WasmRuntime) Load(ctx context.Context, path string) (*WasmPlugin, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := r.Compile(ctx, strings.TrimSuffix(filepath.Base(path), ".wasm"), data)
+	if err != nil {
+		return nil, err
+	}
+	p.Path = path
+	return p, nil
+}
+
+// Compile compiles a plugin module and checks that it implements the ABI.
+func (r *WasmRuntime) Compile(ctx context.Context, name string, wasm []byte) (*WasmPlugin, error) {
+	compiled, err := r.rt.CompileModule(ctx, wasm)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	fail := func(format string, args ...interface{}) (*WasmPlugin, error) {
+		compiled.Close(ctx)
+		return nil, fmt.Errorf("plugin %s: %s", name, fmt.Sprintf(format, args...))
+	}
+
+	for _, imp := range compiled.ImportedFunctions() {
+		mod, field, _ := imp.Import()
+		if mod != WasmHostModule && mod != wasi_snapshot_preview1.ModuleName {
+			return fail("imports %s.%s; only %s and %s are available", mod, field, WasmHostModule, wasi_snapshot_preview1.ModuleName)
+		}
+	}
+	if _, ok := compiled.ExportedMemories()[wasmExportMemory]; !ok {
+		return fail("does not export %q", wasmExportMemory)
+	}
+	exports := compiled.ExportedFunctions()
+	if !hasSignature(exports[WasmExportAlloc], []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}) {
+		return fail("does not export %s(i32) -> i32", WasmExportAlloc)
+	}
+	p := &WasmPlugin{Name: name, runtime: r, compiled: compiled}
+	for _, kind := range AllWasmKinds() {
+		if hasSignature(exports[kind.Export()], []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}) {
+			p.Kinds = append(p.Kinds, kind)
+		}
+	}
+	if len(p.Kinds) == 0 {
+		return fail("exports no entry point (ntm_extract, ntm_evaluate or ntm_check with (i32, i32) -> i64)")
+	}
+	return p, nil
+}
+
+func hasSignature(def api.FunctionDefinition, params, results []api.ValueType) bool {
+	if def == nil {
+		return false
+	}
+	return string(def.ParamTypes()) == string(params) && string(def.ResultTypes()) == string(results)
+}
+
+// LoadDir compiles the .wasm files in dir in name order. A missing
+// directory has no plugins. Plugins that fail to load are skipped and
+// their errors joined.
+func (r *WasmRuntime) LoadDir(ctx context.Context, dir string) ([]*WasmPlugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var loaded []*WasmPlugin
+	var errs []error
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".wasm" {
+			continue
+		}
+		p, err := r.Load(ctx, filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		loaded = append(loaded, p)
+	}
+	return loaded, errors.Join(errs...)
+}
+
+// Has reports whether the plugin implements kind.
+func (p *WasmPlugin) Has(kind WasmKind) bool {
+	for _, k := range p.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Call runs the plugin's kind entry point with req encoded as JSON and
+// decodes its response into resp.
+func (p *WasmPlugin) Call(ctx context.Context, kind WasmKind, req, resp interface{}) error {
+	if !p.Has(kind) {
+		return fmt.Errorf("plugin %s does not implement %s", p.Name, kind)
+	}
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	output, err := p.call(ctx, kind.Export(), input)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", p.Name, err)
+	}
+
+	var failure struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(output, &failure); err != nil {
+		return fmt.Errorf("plugin %s returned invalid JSON: %w", p.Name, err)
+	}
+	if failure.Error != "" {
+		return fmt.Errorf("plugin %s: %s", p.Name, failure.Error)
+	}
+	if resp != nil {
+		if err := json.Unmarshal(output, resp); err != nil {
+			return fmt.Errorf("plugin %s returned an invalid response: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// wasmPluginKey carries the calling plugin's name to host functions.
+type wasmPluginKey struct{}
+
+// call runs export in a fresh instance and returns the response bytes.
+func (p *WasmPlugin) call(ctx context.Context, export string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.runtime.timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, wasmPluginKey{}, p.Name)
+
+	// Instances are anonymous so calls to one plugin can run concurrently.
+	mod, err := p.runtime.rt.InstantiateModule(ctx, p.compiled, wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions("_initialize"))
+	if err != nil {
+		return nil, wasmError(ctx, "instantiating", err)
+	}
+	defer mod.Close(context.Background())
+
+	res, err := mod.ExportedFunction(WasmExportAlloc).Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, wasmError(ctx, WasmExportAlloc, err)
+	}
+	ptr := uint32(res[0])
+	if !mod.Memory().Write(ptr, input) {
+		return nil, fmt.Errorf("%s returned %d, outside memory", WasmExportAlloc, ptr)
+	}
+
+	res, err = mod.ExportedFunction(export).Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, wasmError(ctx, export, err)
+	}
+	outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+	output, ok := mod.Memory().Read(outPtr, outLen)
+	if !ok {
+		return nil, fmt.Errorf("%s returned %d bytes at %d, outside memory", export, outLen, outPtr)
+	}
+	// The view is invalid once the instance is closed.
+	return append([]byte(nil), output...), nil
+}
+
+func wasmError(ctx context.Context, what string, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s timed out", what)
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
+
+// Extract asks an extract plugin for the code blocks in text. language,
+// if set, is the language filter the user asked for.
+func (p *WasmPlugin) Extract(ctx context.Context, text, language string) ([]codeblock.CodeBlock, error) {
+	var resp struct {
+		Blocks []codeblock.CodeBlock `json:"blocks"`
+	}
+	req := map[string]string{"text": text, "language": language}
+	if err := p.Call(ctx, WasmExtract, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Blocks, nil
+}
+
+// Evaluate asks an evaluate plugin to score s. A response without a score
+// means the signals were insufficient.
+func (p *WasmPlugin) Evaluate(ctx context.Context, s *scoring.QualitySignals) (float64, bool, error) {
+	// QualitySignals leaves the diff out of its JSON.
+	req := struct {
+		*scoring.QualitySignals
+		Diff string `json:"diff,omitempty"`
+	}{s, s.Diff}
+	var resp struct {
+		Score *float64 `json:"score"`
+	}
+	if err := p.Call(ctx, WasmEvaluate, req, &resp); err != nil {
+		return 0, false, err
+	}
+	if resp.Score == nil {
+		return 0, false, nil
+	}
+	return min(max(*resp.Score, 0), 1), true, nil
+}
+
+// WasmCheckResult is a check plugin's verdict on a command.
+type WasmCheckResult struct {
+	Action string `json:"action,omitempty"` // "block", "approve", "allow", or empty for no opinion
+	Reason string `json:"reason,omitempty"`
+}
+
+// Check asks a check plugin for its verdict on command.
+func (p *WasmPlugin) Check(ctx context.Context, command string) (WasmCheckResult, error) {
+	var resp WasmCheckResult
+	if err := p.Call(ctx, WasmCheck, map[string]string{"command": command}, &resp); err != nil {
+		return WasmCheckResult{}, err
+	}
+	switch resp.Action {
+	case "", "allow", "approve", "block":
+		return resp, nil
+	}
+	return WasmCheckResult{}, fmt.Errorf("plugin %s returned unknown action %q", p.Name, resp.Action)
+}
+
+// WasmEvaluator adapts an evaluate plugin to scoring.QualityEvaluator.
+type WasmEvaluator struct {
+	Plugin *WasmPlugin
+}
+
+// Name returns the evaluator name, "wasm:<plugin>".
+func (e WasmEvaluator) Name() string { return "wasm:" + e.Plugin.Name }
+
+// Evaluate scores s with the plugin.
+func (e WasmEvaluator) Evaluate(ctx context.Context, s *scoring.QualitySignals) (float64, bool, error) {
+	return e.Plugin.Evaluate(ctx, s)
+}
@@ -0,0 +1,256 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+)
+
+// WASM opcodes used by the test modules.
+const (
+	opLoop      = 0x03
+	opBr        = 0x0c
+	opEnd       = 0x0b
+	opCall      = 0x10
+	opLocalGet  = 0x20
+	opGlobalGet = 0x23
+	opGlobalSet = 0x24
+	opI32Const  = 0x41
+	opI64Const  = 0x42
+	opI32Add    = 0x6a
+)
+
+// Function types of the test modules.
+const (
+	typeLog   = 0 // (i32, i32) -> ()
+	typeAlloc = 1 // (i32) -> i32
+	typeEntry = 2 // (i32, i32) -> i64
+)
+
+type wasmFunc struct {
+	export string
+	typ    byte
+	body   []byte // Instructions, without the final end
+}
+
+// testModule assembles a plugin module: it imports importMod.log as
+// function 0, exports one page of memory, keeps a bump allocator's heap
+// pointer in global 0, and places data at the given offsets.
+func testModule(importMod string, funcs []wasmFunc, data map[uint32]string) []byte {
+	vec := func(items ...[]byte) []byte {
+		out := uleb(uint64(len(items)))
+		for _, item := range items {
+			out = append(out, item...)
+		}
+		return out
+	}
+	name := func(s string) []byte { return append(uleb(uint64(len(s))), s...) }
+	section := func(id byte, content []byte) []byte {
+		return append(append([]byte{id}, uleb(uint64(len(content)))...), content...)
+	}
+	i32, i64 := byte(0x7f), byte(0x7e)
+
+	out := []byte{0x00, 'a', 's', 'm', 0x01, 0x00, 0x00, 0x00}
+	out = append(out, section(1, vec(
+		[]byte{0x60, 2, i32, i32, 0},
+		[]byte{0x60, 1, i32, 1, i32},
+		[]byte{0x60, 2, i32, i32, 1, i64},
+	))...)
+	out = append(out, section(2, vec(append(append(name(importMod), name("log")...), 0x00, typeLog)))...)
+
+	var types, exports, bodies [][]byte
+	exports = append(exports, append(name("memory"), 0x02, 0))
+	for i, f := range funcs {
+		types = append(types, []byte{f.typ})
+		exports = append(exports, append(append(name(f.export), 0x00), uleb(uint64(i+1))...))
+		body := append([]byte{0}, append(f.body, opEnd)...)
+		bodies = append(bodies, append(uleb(uint64(len(body))), body...))
+	}
+	out = append(out, section(3, vec(types...))...)
+	out = append(out, section(5, vec([]byte{0x00, 1}))...)
+	out = append(out, section(6, vec(append([]byte{i32, 1, opI32Const}, append(sleb(4096), opEnd)...)))...)
+	out = append(out, section(7, vec(exports...))...)
+	out = append(out, section(10, vec(bodies...))...)
+
+	var segments [][]byte
+	for offset, s := range data {
+		seg := append([]byte{0x00, opI32Const}, sleb(int64(offset))...)
+		seg = append(append(seg, opEnd), name(s)...)
+		segments = append(segments, seg)
+	}
+	return append(out, section(11, vec(segments...))...)
+}
+
+func uleb(v uint64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func sleb(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// allocFunc is a bump allocator over global 0.
+var allocFunc = wasmFunc{WasmExportAlloc, typeAlloc, []byte{
+	opGlobalGet, 0,
+	opGlobalGet, 0, opLocalGet, 0, opI32Add, opGlobalSet, 0,
+}}
+
+// respond returns an entry point that logs its request and answers with the
+// response stored at offset.
+func respond(kind WasmKind, offset uint32, response string) wasmFunc {
+	body := []byte{opLocalGet, 0, opLocalGet, 1, opCall, 0, opI64Const}
+	body = append(body, sleb(int64(offset)<<32|int64(len(response)))...)
+	return wasmFunc{kind.Export(), typeEntry, body}
+}
+
+func newTestRuntime(t *testing.T, opts WasmOptions) *WasmRuntime {
+	t.Helper()
+	rt, err := NewWasmRuntime(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("NewWasmRuntime: %v", err)
+	}
+	t.Cleanup(func() { rt.Close(context.Background()) })
+	return rt
+}
+
+func TestWasmPlugin_Calls(t *testing.T) {
+	t.Parallel()
+
+	const (
+		check   = `{"action":"block","reason":"no force pushes"}`
+		extract = `{"blocks":[{"language":"sh","content":"make test","file_path":"run.sh"}]}`
+		eval    = `{"score":1.5}`
+	)
+	wasm := testModule("ntm", []wasmFunc{
+		allocFunc,
+		respond(WasmCheck, 0, check),
+		respond(WasmExtract, 256, extract),
+		respond(WasmEvaluate, 1024, eval),
+	}, map[uint32]string{0: check, 256: extract, 1024: eval})
+
+	rt := newTestRuntime(t, WasmOptions{})
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "guard.wasm"), wasm, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a plugin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := rt.LoadDir(context.Background(), dir)
+	if err != nil || len(loaded) != 1 {
+		t.Fatalf("LoadDir = %v, %v", loaded, err)
+	}
+	p := loaded[0]
+	if p.Name != "guard" || len(p.Kinds) != 3 {
+		t.Fatalf("plugin = %+v", p)
+	}
+
+	ctx := context.Background()
+	verdict, err := p.Check(ctx, "git push --force")
+	if err != nil || verdict.Action != "block" || verdict.Reason != "no force pushes" {
+		t.Errorf("Check = %+v, %v", verdict, err)
+	}
+	blocks, err := p.Extract(ctx, "output", "")
+	if err != nil || len(blocks) != 1 || blocks[0].Content != "make test" || blocks[0].FilePath != "run.sh" {
+		t.Errorf("Extract = %+v, %v", blocks, err)
+	}
+	signals := scoring.NewQualitySignals()
+	signals.Diff = "+x"
+	score, ok, err := WasmEvaluator{p}.Evaluate(ctx, signals)
+	if err != nil || !ok || score != 1 {
+		t.Errorf("Evaluate = %v, %v, %v; want score clamped to 1", score, ok, err)
+	}
+
+	// Instances are independent, so one plugin serves concurrent calls.
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := p.Check(ctx, "ls")
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("concurrent Check: %v", err)
+		}
+	}
+}
+
+func TestWasmPlugin_Sandbox(t *testing.T) {
+	t.Parallel()
+	rt := newTestRuntime(t, WasmOptions{Timeout: 100 * time.Millisecond})
+	ctx := context.Background()
+
+	// Only the ntm host module and WASI may be imported.
+	_, err := rt.Compile(ctx, "escape", testModule("env", []wasmFunc{allocFunc, respond(WasmCheck, 0, "{}")}, nil))
+	if err == nil || !strings.Contains(err.Error(), "imports env.log") {
+		t.Errorf("Compile with env import = %v", err)
+	}
+
+	_, err = rt.Compile(ctx, "noentry", testModule("ntm", []wasmFunc{allocFunc}, nil))
+	if err == nil || !strings.Contains(err.Error(), "no entry point") {
+		t.Errorf("Compile without entry points = %v", err)
+	}
+
+	spin := wasmFunc{WasmCheck.Export(), typeEntry, []byte{opLoop, 0x40, opBr, 0, opEnd, opI64Const, 0}}
+	p, err := rt.Compile(ctx, "spin", testModule("ntm", []wasmFunc{allocFunc, spin}, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := p.Check(ctx, "ls"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Check of spinning plugin = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout took %v", elapsed)
+	}
+
+	// Responses outside memory, invalid JSON and plugin errors fail the call.
+	tests := []struct {
+		name, data, want string
+		offset           uint32
+	}{
+		{"out of bounds", "", "outside memory", 70000},
+		{"invalid json", "nope", "invalid JSON", 0},
+		{"plugin error", `{"error":"bad input"}`, "bad input", 0},
+		{"unknown action", `{"action":"maybe"}`, "unknown action", 0},
+	}
+	for _, tt := range tests {
+		resp := tt.data
+		if resp == "" {
+			resp = "{}"
+		}
+		data := map[uint32]string{}
+		if tt.data != "" {
+			data[tt.offset] = tt.data
+		}
+		p, err := rt.Compile(ctx, tt.name, testModule("ntm", []wasmFunc{allocFunc, respond(WasmCheck, tt.offset, resp)}, data))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if _, err := p.Check(ctx, "ls"); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Check = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
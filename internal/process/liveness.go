@@ -1,4 +1,5 @@
-// Package process provides PID-based process liveness checks.
+// Package process provides PID-based process liveness checks and
+// resource usage sampling of process trees.
 package process
 
 import (
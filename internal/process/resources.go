@@ -0,0 +1,189 @@
+package process
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/mem"
+	gproc "github.com/shirou/gopsutil/v4/process"
+)
+
+// DefaultCPUWindow is how long SampleTrees waits to measure CPU for trees
+// it has not seen before.
+const DefaultCPUWindow = 500 * time.Millisecond
+
+// sampleTTL is how long a CPU baseline is kept for a tree that is no longer
+// being sampled.
+const sampleTTL = 10 * time.Minute
+
+// Usage is the resource usage of a process and all of its descendants.
+type Usage struct {
+	PID        int     `json:"pid"`
+	Processes  int     `json:"processes"`
+	CPUPercent float64 `json:"cpu_percent"` // Since the previous sample; 100 = one core
+	RSSBytes   uint64  `json:"rss_bytes"`
+	MemPercent float64 `json:"mem_percent"`   // Share of total system memory
+	Top        string  `json:"top,omitempty"` // Name of the process using the most memory
+}
+
+// Sampler measures process trees. CPU usage is the CPU time the tree used
+// between two samples, so a Sampler is meant to be kept and reused.
+type Sampler struct {
+	mu     sync.Mutex
+	prev   map[int]cpuSample
+	window time.Duration
+	now    func() time.Time
+}
+
+type cpuSample struct {
+	cpuSeconds float64
+	at         time.Time
+}
+
+// NewSampler returns a Sampler that waits window to measure CPU for trees
+// it has no earlier sample of (DefaultCPUWindow if zero).
+func NewSampler(window time.Duration) *Sampler {
+	if window <= 0 {
+		window = DefaultCPUWindow
+	}
+	return &Sampler{prev: make(map[int]cpuSample), window: window, now: time.Now}
+}
+
+var (
+	defaultSampler     *Sampler
+	defaultSamplerOnce sync.Once
+)
+
+// DefaultSampler returns the process-wide Sampler, so periodic callers
+// (health checks, alerts) measure CPU over the interval between them.
+func DefaultSampler() *Sampler {
+	defaultSamplerOnce.Do(func() {
+		defaultSampler = NewSampler(0)
+	})
+	return defaultSampler
+}
+
+// treeSnapshot is one reading of a process tree.
+type treeSnapshot struct {
+	usage      Usage
+	cpuSeconds float64
+}
+
+// SampleTrees returns the usage of the trees rooted at pids, keyed by root
+// PID. Roots that no longer exist are omitted. Trees seen for the first
+// time are sampled twice, one window apart, so their CPU figure is current
+// rather than a lifetime average.
+func (s *Sampler) SampleTrees(ctx context.Context, pids []int) (map[int]Usage, error) {
+	snaps, err := snapshotTrees(ctx, pids)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	var fresh []int
+	for pid := range snaps {
+		if _, ok := s.prev[pid]; !ok {
+			fresh = append(fresh, pid)
+		}
+	}
+	at := s.now()
+	for _, pid := range fresh {
+		s.prev[pid] = cpuSample{cpuSeconds: snaps[pid].cpuSeconds, at: at}
+	}
+	s.mu.Unlock()
+
+	if len(fresh) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.window):
+		}
+		if snaps, err = snapshotTrees(ctx, pids); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	result := make(map[int]Usage, len(snaps))
+	for pid, snap := range snaps {
+		usage := snap.usage
+		if prev, ok := s.prev[pid]; ok {
+			if elapsed := now.Sub(prev.at).Seconds(); elapsed > 0 && snap.cpuSeconds >= prev.cpuSeconds {
+				usage.CPUPercent = (snap.cpuSeconds - prev.cpuSeconds) / elapsed * 100
+			}
+		}
+		s.prev[pid] = cpuSample{cpuSeconds: snap.cpuSeconds, at: now}
+		result[pid] = usage
+	}
+	for pid, prev := range s.prev {
+		if now.Sub(prev.at) > sampleTTL {
+			delete(s.prev, pid)
+		}
+	}
+	return result, nil
+}
+
+// snapshotTrees reads CPU time and memory for every process under each root.
+func snapshotTrees(ctx context.Context, roots []int) (map[int]treeSnapshot, error) {
+	procs, err := gproc.ProcessesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPID := make(map[int32]*gproc.Process, len(procs))
+	children := make(map[int32][]int32)
+	for _, p := range procs {
+		byPID[p.Pid] = p
+		if ppid, err := p.PpidWithContext(ctx); err == nil {
+			children[ppid] = append(children[ppid], p.Pid)
+		}
+	}
+
+	var totalMem uint64
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		totalMem = vm.Total
+	}
+
+	snaps := make(map[int]treeSnapshot, len(roots))
+	for _, root := range roots {
+		if _, ok := byPID[int32(root)]; !ok || root <= 0 {
+			continue
+		}
+		snap := treeSnapshot{usage: Usage{PID: root}}
+		var topRSS uint64
+		queue := []int32{int32(root)}
+		seen := map[int32]bool{}
+		for len(queue) > 0 {
+			pid := queue[0]
+			queue = queue[1:]
+			if seen[pid] {
+				continue
+			}
+			seen[pid] = true
+			queue = append(queue, children[pid]...)
+
+			p := byPID[pid]
+			if p == nil {
+				continue
+			}
+			snap.usage.Processes++
+			if times, err := p.TimesWithContext(ctx); err == nil {
+				snap.cpuSeconds += times.User + times.System
+			}
+			if mi, err := p.MemoryInfoWithContext(ctx); err == nil {
+				snap.usage.RSSBytes += mi.RSS
+				if mi.RSS > topRSS {
+					topRSS = mi.RSS
+					snap.usage.Top, _ = p.NameWithContext(ctx)
+				}
+			}
+		}
+		if totalMem > 0 {
+			snap.usage.MemPercent = float64(snap.usage.RSSBytes) / float64(totalMem) * 100
+		}
+		snaps[root] = snap
+	}
+	return snaps, nil
+}
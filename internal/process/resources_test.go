@@ -0,0 +1,60 @@
+package process
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSampleTrees_CurrentProcess(t *testing.T) {
+	t.Parallel()
+
+	s := NewSampler(50 * time.Millisecond)
+	pid := os.Getpid()
+
+	// Burn some CPU while the first sample measures.
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+	usage, err := s.SampleTrees(context.Background(), []int{pid, 999999999})
+	close(done)
+	if err != nil {
+		t.Fatalf("SampleTrees: %v", err)
+	}
+
+	if _, ok := usage[999999999]; ok {
+		t.Error("missing process should be omitted")
+	}
+	u, ok := usage[pid]
+	if !ok {
+		t.Fatalf("no usage for current process %d", pid)
+	}
+	if u.PID != pid || u.Processes < 1 {
+		t.Errorf("usage = %+v, want pid %d with at least one process", u, pid)
+	}
+	if u.RSSBytes == 0 || u.MemPercent <= 0 {
+		t.Errorf("memory not measured: %+v", u)
+	}
+	if u.CPUPercent <= 0 {
+		t.Errorf("CPUPercent = %v, want > 0 while busy", u.CPUPercent)
+	}
+
+}
+
+func TestSampleTrees_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewSampler(time.Hour).SampleTrees(ctx, []int{os.Getpid()}); err == nil {
+		t.Error("expected error for canceled context")
+	}
+}
@@ -0,0 +1,132 @@
+package prompt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// File handoff defaults.
+const (
+	// DefaultHandoffOver is the prompt size (bytes) above which prompts are
+	// handed off through a file instead of being typed into the pane.
+	DefaultHandoffOver = 50000
+	// DefaultHandoffKeep is how long handoff files are kept before cleanup.
+	DefaultHandoffKeep = 24 * time.Hour
+
+	// handoffPrefix marks files written by WriteHandoff so cleanup never
+	// touches anything else in the directory.
+	handoffPrefix = "ntm-prompt-"
+)
+
+// Handoff is a prompt written to a file for an agent to read.
+type Handoff struct {
+	Path      string    `json:"path"`
+	Bytes     int       `json:"bytes"`
+	SHA256    string    `json:"sha256"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DefaultHandoffDir returns the directory handoff files are written to when
+// none is configured.
+func DefaultHandoffDir() string {
+	return filepath.Join(os.TempDir(), "ntm-prompts")
+}
+
+var handoffNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// WriteHandoff writes content to a new, owner-only file in dir (the default
+// directory if empty) named after the session and pane. dir must be a real
+// directory owned by the current user; it is made owner-only if it is not.
+func WriteHandoff(dir, session string, pane int, content string) (Handoff, error) {
+	if dir == "" {
+		dir = DefaultHandoffDir()
+	}
+	dir = util.ExpandPath(dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return Handoff{}, fmt.Errorf("creating handoff directory: %w", err)
+	}
+	if err := checkHandoffDir(dir); err != nil {
+		return Handoff{}, err
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s%s-%d-%d.md", handoffPrefix, handoffNameUnsafe.ReplaceAllString(session, "_"), pane, now.UnixNano())
+	path := filepath.Join(dir, name)
+	if err := util.AtomicWriteFile(path, []byte(content), 0600); err != nil {
+		return Handoff{}, fmt.Errorf("writing handoff file: %w", err)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return Handoff{
+		Path:      path,
+		Bytes:     len(content),
+		SHA256:    hex.EncodeToString(sum[:]),
+		CreatedAt: now.UTC(),
+	}, nil
+}
+
+// checkHandoffDir guards against a handoff directory another user created
+// first, which is possible under the shared temp directory: it must not be a
+// symlink and must be ours, and is then restricted to mode 0700.
+func checkHandoffDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("checking handoff directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("handoff directory %s is not a directory", dir)
+	}
+	if err := handoffDirOwned(info); err != nil {
+		return fmt.Errorf("handoff directory %s is %w", dir, err)
+	}
+	if info.Mode().Perm() != 0700 {
+		if err := os.Chmod(dir, 0700); err != nil {
+			return fmt.Errorf("restricting handoff directory: %w", err)
+		}
+	}
+	return nil
+}
+
+// HandoffInstruction fills an agent's handoff template; "{path}" is replaced
+// with the file path.
+func HandoffInstruction(template, path string) string {
+	return strings.ReplaceAll(template, "{path}", path)
+}
+
+// SweepHandoffs removes handoff files in dir older than keep and returns
+// their paths. Other files are left alone.
+func SweepHandoffs(dir string, keep time.Duration, now time.Time) ([]string, error) {
+	if dir == "" {
+		dir = DefaultHandoffDir()
+	}
+	dir = util.ExpandPath(dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var removed []string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), handoffPrefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) < keep {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if err := os.Remove(path); err == nil {
+			removed = append(removed, path)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package prompt
+
+import "os"
+
+// handoffDirOwned is a no-op where the temp directory is per user.
+func handoffDirOwned(os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,111 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteHandoff(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "handoff")
+	content := strings.Repeat("context line\n", 10000)
+
+	h, err := WriteHandoff(dir, "my proj/../x", 3, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(h.Path) != dir || !strings.HasPrefix(filepath.Base(h.Path), "ntm-prompt-my_proj_x-3-") {
+		t.Errorf("path = %s", h.Path)
+	}
+	data, err := os.ReadFile(h.Path)
+	if err != nil || string(data) != content {
+		t.Fatalf("file content mismatch (err=%v)", err)
+	}
+	if h.Bytes != len(content) || len(h.SHA256) != 64 {
+		t.Errorf("handoff = %+v", h)
+	}
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(h.Path)
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+		}
+	}
+
+	if got := HandoffInstruction("Read {path} now", h.Path); got != "Read "+h.Path+" now" {
+		t.Errorf("instruction = %q", got)
+	}
+}
+
+func TestWriteHandoff_CheckedDir(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("unix permissions")
+	}
+	base := t.TempDir()
+
+	loose := filepath.Join(base, "loose")
+	if err := os.Mkdir(loose, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(loose, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteHandoff(loose, "s", 1, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(loose); info.Mode().Perm() != 0700 {
+		t.Errorf("dir mode = %v, want 0700", info.Mode().Perm())
+	}
+
+	link := filepath.Join(base, "link")
+	if err := os.Symlink(loose, link); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteHandoff(link, "s", 1, "x"); err == nil {
+		t.Error("expected a symlinked handoff directory to be refused")
+	}
+}
+
+func TestSweepHandoffs(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	old, err := WriteHandoff(dir, "s", 1, "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := WriteHandoff(dir, "s", 2, "fresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(other, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-48 * time.Hour)
+	for _, p := range []string{old.Path, other} {
+		if err := os.Chtimes(p, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := SweepHandoffs(dir, 24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0] != old.Path {
+		t.Errorf("removed = %v, want only %s", removed, old.Path)
+	}
+	for _, p := range []string{fresh.Path, other} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s should remain: %v", p, err)
+		}
+	}
+
+	if removed, err := SweepHandoffs(filepath.Join(dir, "missing"), time.Hour, time.Now()); err != nil || removed != nil {
+		t.Errorf("missing dir: removed = %v, err = %v", removed, err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// handoffDirOwned reports an error unless info, from Lstat, belongs to the
+// current user.
+func handoffDirOwned(info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if uid := os.Getuid(); int(st.Uid) != uid {
+		return fmt.Errorf("owned by uid %d, not %d", st.Uid, uid)
+	}
+	return nil
+}
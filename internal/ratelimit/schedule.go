@@ -0,0 +1,264 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPauseLookahead bounds how far PausedUntil searches for the end of a
+// pause window; a schedule that never lifts is reported as lifting then.
+const maxPauseLookahead = 8 * 24 * time.Hour
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// ScheduleRule adjusts pacing for some providers during a recurring window,
+// e.g. raising delays while an org-wide quota is shared during business
+// hours, or relaxing them at night. Days and Hours use cron syntax for the
+// day-of-week and hour fields: "*", lists ("sat,sun"), ranges ("mon-fri",
+// "9-17") and steps ("*/2"). A range whose end precedes its start wraps,
+// so "22-5" covers the night.
+type ScheduleRule struct {
+	Name      string
+	Providers []string       // Providers or agent types; empty matches all
+	Days      string         // Day-of-week field, 0-7 or sun-sat (default "*")
+	Hours     string         // Hour field, 0-23 (default "*")
+	Location  *time.Location // Time zone for Days and Hours (default local)
+	Until     time.Time      // The rule expires at this time (zero = never)
+
+	MinDelay    time.Duration // Raise the delay to at least this
+	DelayFactor float64       // Scale the learned delay (<1 relaxes, >1 slows)
+	Pause       bool          // Hold new spawns while the window is active
+
+	days  uint64
+	hours uint64
+}
+
+// Schedule is a compiled set of rules applied on top of the learned delays.
+type Schedule struct {
+	rules []ScheduleRule
+}
+
+// ScheduleEffect is the combined effect of the rules active for a provider.
+type ScheduleEffect struct {
+	Rules       []string      // Names of the active rules
+	MinDelay    time.Duration // Highest floor among active rules
+	DelayFactor float64       // Largest factor among active rules (1 if none)
+	Pause       bool          // Whether any active rule pauses spawns
+}
+
+// NewSchedule compiles rules, rejecting malformed fields. A schedule with no
+// rules is valid and has no effect.
+func NewSchedule(rules []ScheduleRule) (*Schedule, error) {
+	s := &Schedule{rules: make([]ScheduleRule, 0, len(rules))}
+	for i, r := range rules {
+		label := r.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		var err error
+		if r.days, err = parseCronField(r.Days, 0, 7, dayNames); err != nil {
+			return nil, fmt.Errorf("rule %s: days: %w", label, err)
+		}
+		// Cron allows 7 as a second Sunday.
+		if r.days&(1<<7) != 0 {
+			r.days = r.days&^(1<<7) | 1
+		}
+		if r.hours, err = parseCronField(r.Hours, 0, 23, nil); err != nil {
+			return nil, fmt.Errorf("rule %s: hours: %w", label, err)
+		}
+		if r.MinDelay < 0 {
+			return nil, fmt.Errorf("rule %s: min delay must be non-negative", label)
+		}
+		if r.DelayFactor < 0 || math.IsNaN(r.DelayFactor) || math.IsInf(r.DelayFactor, 0) {
+			return nil, fmt.Errorf("rule %s: delay factor must be a non-negative number", label)
+		}
+		if r.MinDelay == 0 && r.DelayFactor == 0 && !r.Pause {
+			return nil, fmt.Errorf("rule %s: set min delay, delay factor or pause", label)
+		}
+		if r.Name == "" {
+			r.Name = label
+		}
+		providers := make([]string, len(r.Providers))
+		for j, p := range r.Providers {
+			providers[j] = NormalizeProvider(strings.ToLower(strings.TrimSpace(p)))
+		}
+		r.Providers = providers
+		s.rules = append(s.rules, r)
+	}
+	return s, nil
+}
+
+// parseCronField parses a cron field into a bitmask over [min, max].
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	field = strings.ToLower(strings.TrimSpace(field))
+	if field == "" {
+		field = "*"
+	}
+	value := func(s string) (int, error) {
+		if n, ok := names[s]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("%q is not in %d-%d", s, min, max)
+		}
+		return n, nil
+	}
+
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = value(b); err != nil {
+				return 0, err
+			}
+		default:
+			n, err := value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = n, n
+			if hasStep {
+				hi = max
+			}
+		}
+		span := max - min + 1
+		for i := 0; ; i += step {
+			n := lo + i
+			if hi < lo {
+				// Wrapping range, e.g. 22-5.
+				if i > hi+span-lo {
+					break
+				}
+				n = min + (n-min)%span
+			} else if n > hi {
+				break
+			}
+			mask |= 1 << n
+		}
+	}
+	return mask, nil
+}
+
+// matches reports whether the rule is active for provider at t.
+func (r *ScheduleRule) matches(provider string, t time.Time) bool {
+	if !r.Until.IsZero() && !t.Before(r.Until) {
+		return false
+	}
+	if len(r.Providers) > 0 {
+		found := false
+		for _, p := range r.Providers {
+			if p == provider {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.Location != nil {
+		t = t.In(r.Location)
+	}
+	return r.days&(1<<uint(t.Weekday())) != 0 && r.hours&(1<<uint(t.Hour())) != 0
+}
+
+// scheduleProvider maps a tracker key or agent type to the base provider
+// rules are written against.
+func scheduleProvider(key string) string {
+	provider, _ := SplitKey(key)
+	return NormalizeProvider(provider)
+}
+
+// At returns the effect of the rules active for provider (a provider, agent
+// type or account key) at t.
+func (s *Schedule) At(provider string, t time.Time) ScheduleEffect {
+	eff := ScheduleEffect{DelayFactor: 1}
+	if s == nil {
+		return eff
+	}
+	provider = scheduleProvider(provider)
+	factorSet := false
+	for i := range s.rules {
+		r := &s.rules[i]
+		if !r.matches(provider, t) {
+			continue
+		}
+		eff.Rules = append(eff.Rules, r.Name)
+		eff.MinDelay = max(eff.MinDelay, r.MinDelay)
+		if r.DelayFactor > 0 {
+			if !factorSet || r.DelayFactor > eff.DelayFactor {
+				eff.DelayFactor = r.DelayFactor
+			}
+			factorSet = true
+		}
+		eff.Pause = eff.Pause || r.Pause
+	}
+	return eff
+}
+
+// Adjust applies the rules active at t to a learned delay. Factors never
+// take the delay below the provider minimum or above MaxLearnedDelay; a
+// rule's MinDelay always applies.
+func (s *Schedule) Adjust(provider string, delay time.Duration, t time.Time) time.Duration {
+	eff := s.At(provider, t)
+	if eff.DelayFactor != 1 {
+		scaled := float64(delay) * eff.DelayFactor
+		if scaled > float64(MaxLearnedDelay) {
+			scaled = float64(MaxLearnedDelay)
+		}
+		delay = max(time.Duration(scaled), getMinDelay(scheduleProvider(provider)))
+	}
+	return max(delay, eff.MinDelay)
+}
+
+// PausedUntil reports whether spawns for provider are paused at t and, if
+// so, the names of the pausing rules and when the pause lifts.
+func (s *Schedule) PausedUntil(provider string, t time.Time) (rules []string, until time.Time, paused bool) {
+	eff := s.At(provider, t)
+	if !eff.Pause {
+		return nil, time.Time{}, false
+	}
+	// Rules change on local hour boundaries, which fall on a quarter hour
+	// in every time zone; walk forward until none pauses.
+	next := t.Truncate(15 * time.Minute).Add(15 * time.Minute)
+	for ; next.Sub(t) < maxPauseLookahead; next = next.Add(15 * time.Minute) {
+		if !s.At(provider, next).Pause {
+			break
+		}
+	}
+	var pausing []string
+	provider = scheduleProvider(provider)
+	for i := range s.rules {
+		if s.rules[i].Pause && s.rules[i].matches(provider, t) {
+			pausing = append(pausing, s.rules[i].Name)
+		}
+	}
+	return pausing, next, true
+}
+
+// Empty reports whether the schedule has no rules.
+func (s *Schedule) Empty() bool {
+	return s == nil || len(s.rules) == 0
+}
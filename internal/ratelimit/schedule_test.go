@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		field    string
+		min, max int
+		want     []int
+	}{
+		{"*", 0, 6, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"", 0, 6, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"mon-fri", 0, 7, []int{1, 2, 3, 4, 5}},
+		{"sat,sun", 0, 7, []int{0, 6}},
+		{"9-17", 0, 23, []int{9, 10, 11, 12, 13, 14, 15, 16, 17}},
+		{"22-2", 0, 23, []int{0, 1, 2, 22, 23}},
+		{"*/6", 0, 23, []int{0, 6, 12, 18}},
+		{"8-12/2,20", 0, 23, []int{8, 10, 12, 20}},
+	}
+	for _, tt := range tests {
+		mask, err := parseCronField(tt.field, tt.min, tt.max, dayNames)
+		if err != nil {
+			t.Errorf("%q: %v", tt.field, err)
+			continue
+		}
+		var want uint64
+		for _, n := range tt.want {
+			want |= 1 << n
+		}
+		if mask != want {
+			t.Errorf("%q = %b, want %b", tt.field, mask, want)
+		}
+	}
+
+	for _, bad := range []string{"24", "9-", "mon-xyz", "*/0", "-1"} {
+		if _, err := parseCronField(bad, 0, 23, dayNames); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
+
+func TestSchedule_AdjustAndPause(t *testing.T) {
+	schedule, err := NewSchedule([]ScheduleRule{
+		{Name: "business-hours", Days: "mon-fri", Hours: "9-17", Providers: []string{"cc"}, MinDelay: time.Minute},
+		{Name: "night", Hours: "22-5", DelayFactor: 0.5},
+		{Name: "quota-reset", Days: "1", Hours: "8", Providers: []string{"openai"}, Pause: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2026-10-12 is a Monday.
+	at := func(hour, min int) time.Time { return time.Date(2026, 10, 12, hour, min, 0, 0, time.Local) }
+
+	if got := schedule.Adjust("anthropic", 15*time.Second, at(10, 0)); got != time.Minute {
+		t.Errorf("business hours anthropic = %v, want 1m", got)
+	}
+	if got := schedule.Adjust("openai/work", 10*time.Second, at(10, 0)); got != 10*time.Second {
+		t.Errorf("business hours openai = %v, want unchanged", got)
+	}
+	if got := schedule.Adjust("claude", 20*time.Second, at(23, 30)); got != 10*time.Second {
+		t.Errorf("night = %v, want halved", got)
+	}
+	// Relaxing never goes below the provider minimum.
+	if got := schedule.Adjust("google", 3*time.Second, at(1, 0)); got != MinDelayGoogle {
+		t.Errorf("night floor = %v, want %v", got, MinDelayGoogle)
+	}
+
+	eff := schedule.At("cc", at(10, 0))
+	if strings.Join(eff.Rules, ",") != "business-hours" || eff.Pause {
+		t.Errorf("effect = %+v", eff)
+	}
+
+	rules, until, paused := schedule.PausedUntil("cod", at(8, 20))
+	if !paused || strings.Join(rules, ",") != "quota-reset" || !until.Equal(at(9, 0)) {
+		t.Errorf("pause = %v %v %v", rules, until, paused)
+	}
+	if _, _, paused := schedule.PausedUntil("cc", at(8, 20)); paused {
+		t.Error("cc paused by an openai rule")
+	}
+	if _, _, paused := schedule.PausedUntil("cod", at(9, 0)); paused {
+		t.Error("pause still active after the window")
+	}
+}
+
+func TestSchedule_Location(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*3600)
+	schedule, err := NewSchedule([]ScheduleRule{{Hours: "9-17", Location: loc, Pause: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 04:00 UTC is 09:00 in UTC+5.
+	if !schedule.At("cc", time.Date(2026, 10, 12, 4, 0, 0, 0, time.UTC)).Pause {
+		t.Error("rule not evaluated in its time zone")
+	}
+	if schedule.At("cc", time.Date(2026, 10, 12, 13, 0, 0, 0, time.UTC)).Pause {
+		t.Error("rule evaluated in UTC")
+	}
+}
+
+func TestSchedule_Until(t *testing.T) {
+	end := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	schedule, err := NewSchedule([]ScheduleRule{{Providers: []string{"openai"}, Until: end, Pause: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schedule.At("cod", end.Add(-time.Minute)).Pause {
+		t.Error("rule inactive before it expires")
+	}
+	if schedule.At("cod", end).Pause {
+		t.Error("rule active after it expired")
+	}
+	if _, until, paused := schedule.PausedUntil("cod", end.Add(-2*time.Hour)); !paused || until.Before(end) || until.After(end.Add(15*time.Minute)) {
+		t.Errorf("paused until %v, want about %v", until, end)
+	}
+}
+
+func TestNewSchedule_Errors(t *testing.T) {
+	for _, rule := range []ScheduleRule{
+		{Name: "noop", Hours: "9-17"},
+		{Hours: "25", Pause: true},
+		{Days: "weekdays", Pause: true},
+		{DelayFactor: -1},
+		{MinDelay: -time.Second},
+	} {
+		if _, err := NewSchedule([]ScheduleRule{rule}); err == nil {
+			t.Errorf("expected error for %+v", rule)
+		}
+	}
+}
+
+func TestTracker_GetOptimalDelayWithSchedule(t *testing.T) {
+	tracker := NewRateLimitTracker("")
+	tracker.RecordRateLimit("anthropic", "spawn")
+	learned := tracker.GetOptimalDelay("anthropic")
+
+	schedule, err := NewSchedule([]ScheduleRule{{Name: "always", Providers: []string{"anthropic"}, DelayFactor: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracker.SetSchedule(schedule)
+	if got := tracker.GetOptimalDelay("anthropic"); got != 2*learned {
+		t.Errorf("scheduled delay = %v, want %v", got, 2*learned)
+	}
+	if got := tracker.GetOptimalDelay("openai"); got != DefaultDelayOpenAI {
+		t.Errorf("unmatched provider = %v, want default", got)
+	}
+	// The learned state itself is untouched.
+	if state := tracker.GetProviderState("anthropic"); state.CurrentDelay != learned {
+		t.Errorf("learned delay changed to %v", state.CurrentDelay)
+	}
+
+	tracker.SetSchedule(nil)
+	if got := tracker.GetOptimalDelay("anthropic"); got != learned {
+		t.Errorf("delay after clearing schedule = %v", got)
+	}
+}
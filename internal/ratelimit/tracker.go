@@ -7,11 +7,15 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // Default delays per provider (initial values before learning).
@@ -58,6 +62,23 @@ type RateLimitTracker struct {
 	history map[string][]RateLimitEvent // provider -> recent events
 	state   map[string]*ProviderState   // provider -> current state
 	dataDir string
+
+	// schedule adjusts learned delays during configured windows (quiet
+	// hours, burst windows). It is applied on read and never persisted.
+	schedule *Schedule
+
+	// base is what was persisted as of the last load or save.
+	base rateLimitBase
+}
+
+// rateLimitBase is the data a tracker last loaded or saved, plus the resets
+// made since. SaveToDir uses it to merge only this tracker's changes into
+// the file, which other ntm processes update too.
+type rateLimitBase struct {
+	state     map[string]ProviderState
+	lastEvent map[string]time.Time // Newest persisted event per provider
+	reset     map[string]bool      // Providers reset since
+	resetAll  bool
 }
 
 // persistedData is the JSON structure for persistence.
@@ -104,13 +125,38 @@ func getMinDelay(provider string) time.Duration {
 	}
 }
 
+// AccountKey returns the tracker key for an account of a provider. State
+// for "openai/work" is kept apart from "openai/personal", so one exhausted
+// account does not slow down the others. An empty account yields the
+// provider-wide key.
+func AccountKey(provider, account string) string {
+	provider = NormalizeProvider(provider)
+	if account == "" {
+		return provider
+	}
+	return provider + "/" + account
+}
+
+// SplitKey splits a tracker key into its provider and account.
+func SplitKey(key string) (provider, account string) {
+	provider, account, _ = strings.Cut(key, "/")
+	return provider, account
+}
+
+// normalizeKey normalizes the provider part of a tracker key.
+func normalizeKey(key string) string {
+	provider, account := SplitKey(key)
+	return AccountKey(provider, account)
+}
+
 // getOrCreateState returns the provider state, creating it if needed.
 func (t *RateLimitTracker) getOrCreateState(provider string) *ProviderState {
 	if s, ok := t.state[provider]; ok {
 		return s
 	}
+	base, _ := SplitKey(provider)
 	s := &ProviderState{
-		CurrentDelay: getDefaultDelay(provider),
+		CurrentDelay: getDefaultDelay(base),
 	}
 	t.state[provider] = s
 	return s
@@ -118,7 +164,7 @@ func (t *RateLimitTracker) getOrCreateState(provider string) *ProviderState {
 
 // RecordRateLimit records a rate limit event and adjusts delays.
 func (t *RateLimitTracker) RecordRateLimit(provider, action string) {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -130,7 +176,7 @@ func (t *RateLimitTracker) RecordRateLimit(provider, action string) {
 // If waitSeconds is <= 0, the current adaptive delay is used as the cooldown duration.
 // Returns the applied cooldown duration.
 func (t *RateLimitTracker) RecordRateLimitWithCooldown(provider, action string, waitSeconds int) time.Duration {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -187,7 +233,7 @@ func (t *RateLimitTracker) recordRateLimitLocked(provider, action string, now ti
 
 // RecordSuccess records a successful request.
 func (t *RateLimitTracker) RecordSuccess(provider string) {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -197,7 +243,8 @@ func (t *RateLimitTracker) RecordSuccess(provider string) {
 
 	// After 10 consecutive successes, decrease delay by 10%
 	if state.ConsecutiveSuccess >= successesBeforeDecrease {
-		minDelay := getMinDelay(provider)
+		base, _ := SplitKey(provider)
+		minDelay := getMinDelay(base)
 		newDelay := time.Duration(float64(state.CurrentDelay) * delayDecreaseRate)
 		if newDelay < minDelay {
 			newDelay = minDelay
@@ -208,23 +255,57 @@ func (t *RateLimitTracker) RecordSuccess(provider string) {
 }
 
 // CooldownRemaining returns how much cooldown time remains for a provider.
+// For an account key (see AccountKey) a provider-wide cooldown also counts.
 // Returns 0 if no cooldown is active or the provider is unknown.
 func (t *RateLimitTracker) CooldownRemaining(provider string) time.Duration {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	state, ok := t.state[provider]
-	if !ok {
-		return 0
+	var until time.Time
+	if state, ok := t.state[provider]; ok {
+		until = state.CooldownUntil
+	}
+	if base, account := SplitKey(provider); account != "" {
+		if state, ok := t.state[base]; ok && state.CooldownUntil.After(until) {
+			until = state.CooldownUntil
+		}
 	}
-	remaining := time.Until(state.CooldownUntil)
+	remaining := time.Until(until)
 	if remaining < 0 {
 		return 0
 	}
 	return remaining
 }
 
+// PickAccount chooses an account of provider for a new pane, round-robin
+// from index start and skipping accounts in cooldown. If every account is
+// cooling down, the one that frees up first is returned with its remaining
+// cooldown. It returns "" for an empty pool.
+func (t *RateLimitTracker) PickAccount(provider string, accounts []string, start int) (string, time.Duration) {
+	if len(accounts) == 0 {
+		return "", 0
+	}
+	if start < 0 {
+		start = 0
+	}
+	best, bestWait := "", time.Duration(-1)
+	for i := 0; i < len(accounts); i++ {
+		account := accounts[(start+i)%len(accounts)]
+		var wait time.Duration
+		if t != nil {
+			wait = t.CooldownRemaining(AccountKey(provider, account))
+		}
+		if wait == 0 {
+			return account, 0
+		}
+		if bestWait < 0 || wait < bestWait {
+			best, bestWait = account, wait
+		}
+	}
+	return best, bestWait
+}
+
 // IsInCooldown reports whether the provider is currently in a cooldown window.
 func (t *RateLimitTracker) IsInCooldown(provider string) bool {
 	return t.CooldownRemaining(provider) > 0
@@ -232,7 +313,7 @@ func (t *RateLimitTracker) IsInCooldown(provider string) bool {
 
 // ClearCooldown clears any active cooldown for a provider.
 func (t *RateLimitTracker) ClearCooldown(provider string) {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -241,21 +322,34 @@ func (t *RateLimitTracker) ClearCooldown(provider string) {
 	}
 }
 
-// GetOptimalDelay returns the current optimal delay for a provider.
+// GetOptimalDelay returns the current optimal delay for a provider: the
+// learned delay, adjusted by the schedule rules active now (see SetSchedule).
 func (t *RateLimitTracker) GetOptimalDelay(provider string) time.Duration {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
+	delay := getDefaultDelay(provider)
 	if state, ok := t.state[provider]; ok {
-		return state.CurrentDelay
+		delay = state.CurrentDelay
+	}
+	if t.schedule != nil {
+		delay = t.schedule.Adjust(provider, delay, time.Now())
 	}
-	return getDefaultDelay(provider)
+	return delay
+}
+
+// SetSchedule installs the rules GetOptimalDelay applies on top of the
+// learned delays. A nil schedule removes them.
+func (t *RateLimitTracker) SetSchedule(s *Schedule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.schedule = s
 }
 
 // GetProviderState returns a copy of the state for a provider.
 func (t *RateLimitTracker) GetProviderState(provider string) *ProviderState {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
@@ -282,7 +376,7 @@ func (t *RateLimitTracker) GetAllProviders() []string {
 
 // GetRecentEvents returns recent rate limit events for a provider.
 func (t *RateLimitTracker) GetRecentEvents(provider string, limit int) []RateLimitEvent {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
@@ -303,12 +397,18 @@ func (t *RateLimitTracker) GetRecentEvents(provider string, limit int) []RateLim
 
 // Reset resets the state for a provider to defaults.
 func (t *RateLimitTracker) Reset(provider string) {
-	provider = NormalizeProvider(provider)
+	provider = normalizeKey(provider)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	delete(t.state, provider)
 	delete(t.history, provider)
+	if t.base.reset == nil {
+		t.base.reset = make(map[string]bool)
+	}
+	t.base.reset[provider] = true
+	delete(t.base.state, provider)
+	delete(t.base.lastEvent, provider)
 }
 
 // ResetAll resets all provider states.
@@ -318,6 +418,7 @@ func (t *RateLimitTracker) ResetAll() {
 
 	t.state = make(map[string]*ProviderState)
 	t.history = make(map[string][]RateLimitEvent)
+	t.base = rateLimitBase{resetAll: true}
 }
 
 // LoadFromDir loads rate limit data from the .ntm directory.
@@ -358,16 +459,16 @@ func (t *RateLimitTracker) LoadFromDir(dir string) error {
 				ps.CooldownUntil = time.Time{}
 			}
 		}
-		t.state = pd.State
-	}
-	if pd.History != nil {
-		t.history = pd.History
 	}
+	t.adoptLocked(pd)
 
 	return nil
 }
 
-// SaveToDir saves rate limit data to the .ntm directory.
+// SaveToDir saves rate limit data to the .ntm directory. Other ntm processes
+// save to the same file, so under the file lock it re-reads the file, merges
+// in what changed here since the last load or save (see mergeLocked), writes
+// the result and adopts it.
 func (t *RateLimitTracker) SaveToDir(dir string) error {
 	if dir == "" {
 		dir = t.dataDir
@@ -376,37 +477,135 @@ func (t *RateLimitTracker) SaveToDir(dir string) error {
 		return nil // persistence disabled
 	}
 
-	t.mu.RLock()
-	pd := persistedData{
-		State:   make(map[string]*ProviderState),
-		History: make(map[string][]RateLimitEvent),
-	}
-	// Deep copy to release lock early
-	for k, v := range t.state {
-		val := *v
-		pd.State[k] = &val
-	}
-	for k, v := range t.history {
-		pd.History[k] = append([]RateLimitEvent(nil), v...)
-	}
-	t.mu.RUnlock()
-
 	ntmDir := filepath.Join(dir, ".ntm")
 	if err := os.MkdirAll(ntmDir, 0755); err != nil {
 		return fmt.Errorf("create .ntm dir: %w", err)
 	}
 
-	data, err := json.MarshalIndent(pd, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal rate limits: %w", err)
+	path := filepath.Join(ntmDir, "rate_limits.json")
+	return util.WithFileLock(path, func() error {
+		var pd persistedData
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, &pd); err != nil {
+				return fmt.Errorf("parse rate limits file: %w", err)
+			}
+		case !os.IsNotExist(err):
+			return fmt.Errorf("read rate limits file: %w", err)
+		}
+
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.mergeLocked(&pd)
+		data, err = json.MarshalIndent(pd, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal rate limits: %w", err)
+		}
+		if err := util.AtomicWriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("write rate limits file: %w", err)
+		}
+		t.adoptLocked(pd)
+		return nil
+	})
+}
+
+// mergeLocked merges the changes made by this tracker since the last load or
+// save into pd, the file's current contents:
+//
+//   - providers reset here are dropped first;
+//   - rate limit and success totals add this tracker's increments;
+//   - the learned delay follows whichever side changed it, or on both the
+//     one with the later rate limit, and the later cooldown is kept;
+//   - events recorded here are added to the history.
+//
+// The caller must hold t.mu.
+func (t *RateLimitTracker) mergeLocked(pd *persistedData) {
+	if pd.State == nil || t.base.resetAll {
+		pd.State = make(map[string]*ProviderState)
+	}
+	if pd.History == nil || t.base.resetAll {
+		pd.History = make(map[string][]RateLimitEvent)
+	}
+	for key := range t.base.reset {
+		delete(pd.State, key)
+		delete(pd.History, key)
+	}
+
+	for key, mem := range t.state {
+		base, known := t.base.state[key]
+		changed := *mem != base
+		disk, ok := pd.State[key]
+		if !ok {
+			if known && !changed {
+				continue // Reset by another process, unused here since
+			}
+			st := *mem
+			st.TotalRateLimits -= base.TotalRateLimits
+			st.TotalSuccesses -= base.TotalSuccesses
+			pd.State[key] = &st
+			continue
+		}
+		merged := *disk
+		merged.TotalRateLimits += mem.TotalRateLimits - base.TotalRateLimits
+		merged.TotalSuccesses += mem.TotalSuccesses - base.TotalSuccesses
+		if changed && (*disk == base || !disk.LastRateLimit.After(mem.LastRateLimit)) {
+			merged.CurrentDelay = mem.CurrentDelay
+			merged.ConsecutiveSuccess = mem.ConsecutiveSuccess
+			merged.LastRateLimit = mem.LastRateLimit
+		}
+		if mem.CooldownUntil.After(merged.CooldownUntil) || (changed && mem.CooldownUntil.IsZero() && !base.CooldownUntil.IsZero()) {
+			// Extended here, or cleared here.
+			merged.CooldownUntil = mem.CooldownUntil
+		}
+		pd.State[key] = &merged
 	}
 
-	path := filepath.Join(ntmDir, "rate_limits.json")
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("write rate limits file: %w", err)
+	for key, events := range t.history {
+		since := t.base.lastEvent[key]
+		merged := pd.History[key]
+		for _, e := range events {
+			if e.Time.After(since) && !slices.ContainsFunc(merged, func(m RateLimitEvent) bool {
+				return m.Time.Equal(e.Time) && m.Action == e.Action
+			}) {
+				merged = append(merged, e)
+			}
+		}
+		sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+		if len(merged) > 100 {
+			merged = merged[len(merged)-100:]
+		}
+		if len(merged) > 0 {
+			pd.History[key] = merged
+		}
 	}
+}
 
-	return nil
+// adoptLocked makes pd, as just read or written, the tracker's data and its
+// merge base. The caller must hold t.mu.
+func (t *RateLimitTracker) adoptLocked(pd persistedData) {
+	t.base = rateLimitBase{
+		state:     make(map[string]ProviderState, len(pd.State)),
+		lastEvent: make(map[string]time.Time, len(pd.History)),
+	}
+	if pd.State != nil {
+		t.state = make(map[string]*ProviderState, len(pd.State))
+		for key, ps := range pd.State {
+			st := *ps
+			t.state[key] = &st
+			t.base.state[key] = *ps
+		}
+	}
+	if pd.History != nil {
+		t.history = make(map[string][]RateLimitEvent, len(pd.History))
+		for key, events := range pd.History {
+			t.history[key] = append([]RateLimitEvent(nil), events...)
+			if len(events) > 0 {
+				t.base.lastEvent[key] = events[len(events)-1].Time
+			}
+		}
+	}
 }
 
 // FormatDelay formats a duration as a human-readable string.
@@ -469,18 +668,34 @@ var exitCodePatterns = []*regexp.Regexp{
 	regexp.MustCompile(`(?i)status[:\s]+(\d+)`),
 }
 
+// Budgets for parsing untrusted agent output.
+const (
+	// maxWaitScanBytes is how much of the end of the output is searched for
+	// a wait time; rate limit messages are recent.
+	maxWaitScanBytes = 64 << 10
+	// maxWaitSeconds caps a parsed wait time.
+	maxWaitSeconds = 7 * 24 * 60 * 60
+)
+
 // ParseWaitSeconds extracts a suggested wait time in seconds from output.
+// Only the last 64 KiB are searched and the result is capped at a week.
 // Returns 0 if no wait time is found.
 func ParseWaitSeconds(output string) int {
 	if output == "" {
 		return 0
 	}
+	if len(output) > maxWaitScanBytes {
+		output = output[len(output)-maxWaitScanBytes:]
+	}
 	output = status.StripANSI(output)
 
 	for _, pattern := range waitTimePatterns {
 		if matches := pattern.re.FindStringSubmatch(output); len(matches) > 1 {
 			seconds, err := strconv.Atoi(matches[1])
 			if err == nil && seconds > 0 {
+				if seconds > maxWaitSeconds/pattern.multiplier {
+					return maxWaitSeconds
+				}
 				return seconds * pattern.multiplier
 			}
 		}
@@ -637,6 +852,10 @@ type CodexThrottle struct {
 	lastRecoveryStep time.Time // When last additive increase happened
 	affectedPanes    []string  // Pane IDs that were rate-limited
 
+	// accountCooldown pauses single accounts of a pool without throttling
+	// the whole provider.
+	accountCooldown map[string]time.Time
+
 	// nowFn allows test time injection.
 	nowFn func() time.Time
 }
@@ -649,7 +868,9 @@ type CodexThrottleStatus struct {
 	MaxConcurrent     int           `json:"max_concurrent"`
 	RateLimitCount    int           `json:"rate_limit_count"`
 	AffectedPanes     []string      `json:"affected_panes,omitempty"`
-	Guidance          string        `json:"guidance"`
+	// PausedAccounts maps each paused pool account to its remaining cooldown.
+	PausedAccounts map[string]time.Duration `json:"paused_accounts,omitempty"`
+	Guidance       string                   `json:"guidance"`
 }
 
 // NewCodexThrottle creates a CodexThrottle with the given max concurrency ceiling.
@@ -715,6 +936,66 @@ func (ct *CodexThrottle) RecordRateLimit(paneID string, waitSeconds int) {
 	ct.phase = ThrottlePaused
 }
 
+// RecordAccountRateLimit is called when a cod pane using a pool account is
+// rate-limited. Only that account is paused; launches on other accounts
+// continue. An empty account falls back to RecordRateLimit.
+func (ct *CodexThrottle) RecordAccountRateLimit(paneID, account string, waitSeconds int) {
+	if account == "" {
+		ct.RecordRateLimit(paneID, waitSeconds)
+		return
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	now := ct.now()
+	ct.lastRateLimit = now
+	ct.addAffectedPane(paneID)
+
+	cooldown := DefaultCooldownWindow
+	if waitSeconds > 0 {
+		cooldown = time.Duration(waitSeconds) * time.Second
+	}
+	if cooldown > MaxCooldownWindow {
+		cooldown = MaxCooldownWindow
+	}
+	if ct.accountCooldown == nil {
+		ct.accountCooldown = make(map[string]time.Time)
+	}
+	if until := now.Add(cooldown); until.After(ct.accountCooldown[account]) {
+		ct.accountCooldown[account] = until
+	}
+}
+
+// AccountCooldownRemaining returns how long a pool account stays paused.
+func (ct *CodexThrottle) AccountCooldownRemaining(account string) time.Duration {
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	remaining := ct.accountCooldown[account].Sub(ct.now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// pausedAccountsLocked returns the accounts still cooling down, dropping
+// expired entries. Caller must hold ct.mu.
+func (ct *CodexThrottle) pausedAccountsLocked() map[string]time.Duration {
+	now := ct.now()
+	var paused map[string]time.Duration
+	for account, until := range ct.accountCooldown {
+		if !until.After(now) {
+			delete(ct.accountCooldown, account)
+			continue
+		}
+		if paused == nil {
+			paused = make(map[string]time.Duration)
+		}
+		paused[account] = until.Sub(now)
+	}
+	return paused
+}
+
 // addAffectedPane adds a pane ID to the affected list if not already present.
 func (ct *CodexThrottle) addAffectedPane(paneID string) {
 	if paneID == "" {
@@ -808,6 +1089,17 @@ func (ct *CodexThrottle) Status() CodexThrottleStatus {
 	panes := make([]string, len(ct.affectedPanes))
 	copy(panes, ct.affectedPanes)
 
+	paused := ct.pausedAccountsLocked()
+	guidance := ct.guidanceLocked()
+	if len(paused) > 0 {
+		names := make([]string, 0, len(paused))
+		for account := range paused {
+			names = append(names, account)
+		}
+		sort.Strings(names)
+		guidance += fmt.Sprintf(" Paused Codex accounts: %s; other accounts keep launching.", strings.Join(names, ", "))
+	}
+
 	return CodexThrottleStatus{
 		Phase:             ct.phase,
 		CooldownRemaining: remaining,
@@ -815,7 +1107,8 @@ func (ct *CodexThrottle) Status() CodexThrottleStatus {
 		MaxConcurrent:     ct.maxConcurrent,
 		RateLimitCount:    ct.rateLimitCount,
 		AffectedPanes:     panes,
-		Guidance:          ct.guidanceLocked(),
+		PausedAccounts:    paused,
+		Guidance:          guidance,
 	}
 }
 
@@ -870,4 +1163,107 @@ func (ct *CodexThrottle) Reset() {
 	ct.lastRateLimit = time.Time{}
 	ct.lastRecoveryStep = time.Time{}
 	ct.affectedPanes = nil
+	ct.accountCooldown = nil
+}
+
+// codexThrottleFile is where a project's throttle state is persisted, so
+// that commands outside the monitor process can report it.
+const codexThrottleFile = "codex_throttle.json"
+
+// persistedThrottle is the JSON structure for CodexThrottle persistence.
+type persistedThrottle struct {
+	Phase             ThrottlePhase        `json:"phase"`
+	CooldownUntil     time.Time            `json:"cooldown_until,omitempty"`
+	CooldownWindow    time.Duration        `json:"cooldown_window,omitempty"`
+	AllowedConcurrent int                  `json:"allowed_concurrent"`
+	RateLimitCount    int                  `json:"rate_limit_count"`
+	LastRateLimit     time.Time            `json:"last_rate_limit,omitempty"`
+	LastRecoveryStep  time.Time            `json:"last_recovery_step,omitempty"`
+	AffectedPanes     []string             `json:"affected_panes,omitempty"`
+	AccountCooldowns  map[string]time.Time `json:"account_cooldowns,omitempty"`
+}
+
+// LoadFromDir restores throttle state saved by SaveToDir in dir's .ntm
+// directory. A missing file leaves the throttle untouched. The
+// concurrency ceiling stays the one the throttle was created with.
+func (ct *CodexThrottle) LoadFromDir(dir string) error {
+	if dir == "" {
+		return nil // persistence disabled
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".ntm", codexThrottleFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("read codex throttle file: %w", err)
+	}
+
+	var pt persistedThrottle
+	if err := json.Unmarshal(data, &pt); err != nil {
+		return fmt.Errorf("parse codex throttle file: %w", err)
+	}
+
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	switch pt.Phase {
+	case ThrottleNormal, ThrottlePaused, ThrottleRecovering:
+		ct.phase = pt.Phase
+	default:
+		ct.phase = ThrottleNormal
+	}
+	ct.cooldownUntil = pt.CooldownUntil
+	ct.cooldownDur = pt.CooldownWindow
+	ct.allowedConcurrent = pt.AllowedConcurrent
+	if ct.allowedConcurrent < 0 || ct.allowedConcurrent > ct.maxConcurrent {
+		ct.allowedConcurrent = ct.maxConcurrent
+	}
+	ct.rateLimitCount = pt.RateLimitCount
+	ct.lastRateLimit = pt.LastRateLimit
+	ct.lastRecoveryStep = pt.LastRecoveryStep
+	ct.affectedPanes = pt.AffectedPanes
+	ct.accountCooldown = pt.AccountCooldowns
+	return nil
+}
+
+// SaveToDir persists the throttle state to dir's .ntm directory.
+func (ct *CodexThrottle) SaveToDir(dir string) error {
+	if dir == "" {
+		return nil // persistence disabled
+	}
+
+	ct.mu.RLock()
+	pt := persistedThrottle{
+		Phase:             ct.phase,
+		CooldownUntil:     ct.cooldownUntil,
+		CooldownWindow:    ct.cooldownDur,
+		AllowedConcurrent: ct.allowedConcurrent,
+		RateLimitCount:    ct.rateLimitCount,
+		LastRateLimit:     ct.lastRateLimit,
+		LastRecoveryStep:  ct.lastRecoveryStep,
+		AffectedPanes:     append([]string(nil), ct.affectedPanes...),
+	}
+	if len(ct.accountCooldown) > 0 {
+		pt.AccountCooldowns = make(map[string]time.Time, len(ct.accountCooldown))
+		for account, until := range ct.accountCooldown {
+			pt.AccountCooldowns[account] = until
+		}
+	}
+	ct.mu.RUnlock()
+
+	ntmDir := filepath.Join(dir, ".ntm")
+	if err := os.MkdirAll(ntmDir, 0755); err != nil {
+		return fmt.Errorf("create .ntm dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(pt, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal codex throttle: %w", err)
+	}
+
+	if err := util.AtomicWriteFile(filepath.Join(ntmDir, codexThrottleFile), data, 0644); err != nil {
+		return fmt.Errorf("write codex throttle file: %w", err)
+	}
+	return nil
 }
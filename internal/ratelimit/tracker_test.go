@@ -4,6 +4,7 @@ package ratelimit
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -292,6 +293,54 @@ func TestPersistence(t *testing.T) {
 	}
 }
 
+func TestSaveToDir_MergesConcurrentTrackers(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Two processes share rate_limits.json.
+	a := NewRateLimitTracker(tmpDir)
+	a.RecordSuccess("anthropic")
+	a.RecordSuccess("google")
+	if err := a.SaveToDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	b := NewRateLimitTracker(tmpDir)
+	if err := b.LoadFromDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	a.RecordSuccess("anthropic")
+	b.RecordRateLimit("anthropic", "spawn")
+	b.RecordSuccess("openai")
+	b.Reset("google")
+	if err := b.SaveToDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.SaveToDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewRateLimitTracker(tmpDir)
+	if err := loaded.LoadFromDir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+	st := loaded.GetProviderState("anthropic")
+	if st == nil || st.TotalSuccesses != 2 || st.TotalRateLimits != 1 {
+		t.Fatalf("anthropic = %+v, want 2 successes and 1 rate limit", st)
+	}
+	if st.CurrentDelay <= DefaultDelayAnthropic {
+		t.Errorf("CurrentDelay = %v, want b's increase kept", st.CurrentDelay)
+	}
+	if len(loaded.GetRecentEvents("anthropic", 0)) != 1 {
+		t.Errorf("events = %v, want b's rate limit", loaded.GetRecentEvents("anthropic", 0))
+	}
+	if loaded.GetProviderState("openai") == nil {
+		t.Error("openai state from b was dropped")
+	}
+	if loaded.GetProviderState("google") != nil {
+		t.Error("google was reset by b but came back")
+	}
+}
+
 func TestLoadFromDir_NoFile(t *testing.T) {
 	tmpDir := t.TempDir()
 	tracker := NewRateLimitTracker(tmpDir)
@@ -446,6 +495,7 @@ func TestParseWaitSeconds(t *testing.T) {
 		{"retry_minutes", "retry in 2m", 120},
 		{"cooldown_seconds", "10 seconds cooldown", 10},
 		{"no_wait", "rate limit exceeded", 0},
+		{"capped", "retry in 99999999999m", maxWaitSeconds},
 	}
 
 	for _, tt := range tests {
@@ -977,3 +1027,169 @@ func TestCodexThrottle_MayLaunch_RespectsCurrentRunning(t *testing.T) {
 		t.Error("expected MayLaunch(2)=false when allowed=2")
 	}
 }
+
+func TestRateLimitTracker_AccountKeys(t *testing.T) {
+	t.Parallel()
+
+	if got := AccountKey("cod", "work"); got != "openai/work" {
+		t.Errorf("AccountKey = %q", got)
+	}
+	if got := AccountKey("claude", ""); got != "anthropic" {
+		t.Errorf("AccountKey without account = %q", got)
+	}
+
+	tracker := NewRateLimitTracker("")
+	tracker.RecordRateLimitWithCooldown(AccountKey("cod", "work"), "send", 60)
+
+	if tracker.CooldownRemaining("codex/work") == 0 {
+		t.Error("exhausted account should be cooling down")
+	}
+	if tracker.CooldownRemaining(AccountKey("cod", "personal")) != 0 {
+		t.Error("other account should not be cooling down")
+	}
+	if tracker.CooldownRemaining("openai") != 0 {
+		t.Error("provider-wide state should be unaffected")
+	}
+	if got := tracker.GetOptimalDelay("openai/work"); got <= DefaultDelayOpenAI {
+		t.Errorf("account delay = %v, want above default", got)
+	}
+
+	// A provider-wide cooldown applies to every account.
+	tracker.RecordRateLimitWithCooldown("openai", "send", 30)
+	if tracker.CooldownRemaining(AccountKey("cod", "personal")) == 0 {
+		t.Error("provider-wide cooldown should cover accounts")
+	}
+}
+
+func TestRateLimitTracker_PickAccount(t *testing.T) {
+	t.Parallel()
+
+	accounts := []string{"a", "b", "c"}
+	tracker := NewRateLimitTracker("")
+
+	for start, want := range []string{"a", "b", "c", "a"} {
+		if got, wait := tracker.PickAccount("cc", accounts, start); got != want || wait != 0 {
+			t.Errorf("PickAccount(start=%d) = %q, %v; want %q", start, got, wait, want)
+		}
+	}
+
+	tracker.RecordRateLimitWithCooldown(AccountKey("cc", "b"), "send", 60)
+	if got, _ := tracker.PickAccount("cc", accounts, 1); got != "c" {
+		t.Errorf("cooling account not skipped: got %q", got)
+	}
+
+	tracker.RecordRateLimitWithCooldown(AccountKey("cc", "a"), "send", 10)
+	tracker.RecordRateLimitWithCooldown(AccountKey("cc", "c"), "send", 120)
+	got, wait := tracker.PickAccount("cc", accounts, 0)
+	if got != "a" || wait <= 0 || wait > 10*time.Second {
+		t.Errorf("all cooling: got %q, %v; want a with shortest wait", got, wait)
+	}
+
+	if got, _ := (*RateLimitTracker)(nil).PickAccount("cc", accounts, 4); got != "b" {
+		t.Errorf("nil tracker round-robin = %q", got)
+	}
+	if got, _ := tracker.PickAccount("cc", nil, 0); got != "" {
+		t.Errorf("empty pool = %q", got)
+	}
+}
+
+func TestCodexThrottle_AccountRateLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	ct := NewCodexThrottle(3)
+	ct.nowFn = func() time.Time { return now }
+
+	ct.RecordAccountRateLimit("p1", "work", 60)
+	if !ct.MayLaunch(0) {
+		t.Error("an account rate limit should not pause the provider")
+	}
+	if ct.AccountCooldownRemaining("work") != time.Minute || ct.AccountCooldownRemaining("personal") != 0 {
+		t.Error("only the exhausted account should be paused")
+	}
+	st := ct.Status()
+	if st.Phase != ThrottleNormal || st.PausedAccounts["work"] != time.Minute || !strings.Contains(st.Guidance, "Paused Codex accounts: work") {
+		t.Errorf("status = %+v", st)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if st := ct.Status(); len(st.PausedAccounts) != 0 {
+		t.Errorf("expired account still paused: %v", st.PausedAccounts)
+	}
+
+	// No account: provider-wide behavior.
+	ct.RecordAccountRateLimit("p2", "", 0)
+	if ct.MayLaunch(0) {
+		t.Error("provider-wide rate limit should pause launches")
+	}
+}
+
+func TestCodexThrottle_PersistRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	now := time.Now()
+	ct := NewCodexThrottle(4)
+	ct.nowFn = func() time.Time { return now }
+	ct.RecordRateLimit("%1", 60)
+	ct.RecordAccountRateLimit("%2", "work", 120)
+	if err := ct.SaveToDir(dir); err != nil {
+		t.Fatalf("SaveToDir: %v", err)
+	}
+
+	loaded := NewCodexThrottle(4)
+	loaded.nowFn = func() time.Time { return now }
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatalf("LoadFromDir: %v", err)
+	}
+	st := loaded.Status()
+	if st.Phase != ThrottlePaused || st.CooldownRemaining != time.Minute || st.AllowedConcurrent != 2 || st.MaxConcurrent != 4 {
+		t.Errorf("loaded status = %+v", st)
+	}
+	if len(st.AffectedPanes) != 2 || st.PausedAccounts["work"] != 2*time.Minute {
+		t.Errorf("loaded panes %v accounts %v", st.AffectedPanes, st.PausedAccounts)
+	}
+
+	// A lower ceiling than the saved window clamps it.
+	small := NewCodexThrottle(1)
+	if err := small.LoadFromDir(dir); err != nil {
+		t.Fatalf("LoadFromDir: %v", err)
+	}
+	if st := small.Status(); st.AllowedConcurrent > 1 {
+		t.Errorf("allowed %d exceeds ceiling 1", st.AllowedConcurrent)
+	}
+
+	if err := NewCodexThrottle(3).LoadFromDir(t.TempDir()); err != nil {
+		t.Errorf("missing file: %v", err)
+	}
+}
+
+func TestParseWaitSeconds_ScansTail(t *testing.T) {
+	old := "retry in 5s\n" + strings.Repeat("x", maxWaitScanBytes)
+	if got := ParseWaitSeconds(old); got != 0 {
+		t.Errorf("wait time outside the scanned tail = %d, want 0", got)
+	}
+	if got := ParseWaitSeconds(old + "\ntry again in 7s"); got != 7 {
+		t.Errorf("recent wait time = %d, want 7", got)
+	}
+}
+
+// FuzzParseWaitSeconds ensures ParseWaitSeconds never panics and stays in range.
+func FuzzParseWaitSeconds(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"Retry-After: 15",
+		"try again in 3s",
+		"retry in 2m",
+		"retry in 99999999999999m",
+		"\x1b[31mwait 5 seconds\x1b[0m",
+		strings.Repeat("retry in ", 1000),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, output string) {
+		if got := ParseWaitSeconds(output); got < 0 || got > maxWaitSeconds {
+			t.Errorf("ParseWaitSeconds(%q) = %d, out of range", output, got)
+		}
+	})
+}
@@ -14,15 +14,18 @@ import (
 //   - ModeWarn: scans and reports findings but doesn't modify output
 //   - ModeRedact: replaces sensitive content with placeholders
 //   - ModeBlock: scans and sets Blocked=true if findings exist
+//
+// Secrets registered with RegisterSecret are replaced in every mode.
 func ScanAndRedact(input string, cfg Config) Result {
 	result := Result{
 		Mode:           cfg.Mode,
 		OriginalLength: len(input),
 	}
 
-	// Fast path: if mode is off, skip scanning.
+	// Fast path: if mode is off, skip pattern scanning.
 	if cfg.Mode == ModeOff {
-		result.Output = input
+		result.Findings = toFindings(deduplicateMatches(injectedMatches(input)))
+		result.Output = applyRedactions(input, result.Findings)
 		return result
 	}
 
@@ -38,32 +41,40 @@ func ScanAndRedact(input string, cfg Config) Result {
 		return result
 	}
 
-	// Convert matches to findings.
-	result.Findings = make([]Finding, len(matches))
-	for i, m := range matches {
-		result.Findings[i] = Finding{
-			Category: m.category,
-			Match:    m.match,
-			Redacted: generatePlaceholder(m.category, m.match),
-			Start:    m.start,
-			End:      m.end,
-		}
-	}
+	result.Findings = toFindings(matches)
 
 	// Handle based on mode.
 	switch cfg.Mode {
 	case ModeWarn:
-		result.Output = input
+		result.Output = applyRedactions(input, onlyInjected(result.Findings))
 	case ModeRedact:
 		result.Output = applyRedactions(input, result.Findings)
 	case ModeBlock:
-		result.Output = input
+		result.Output = applyRedactions(input, onlyInjected(result.Findings))
 		result.Blocked = true
 	}
 
 	return result
 }
 
+// toFindings converts matches to findings with their placeholders.
+func toFindings(matches []match) []Finding {
+	if len(matches) == 0 {
+		return nil
+	}
+	findings := make([]Finding, len(matches))
+	for i, m := range matches {
+		findings[i] = Finding{
+			Category: m.category,
+			Match:    m.match,
+			Redacted: generatePlaceholder(m.category, m.match),
+			Start:    m.start,
+			End:      m.end,
+		}
+	}
+	return findings
+}
+
 // match represents an internal match during scanning.
 type match struct {
 	category Category
@@ -76,7 +87,7 @@ type match struct {
 // scan finds all sensitive content in input.
 func scan(input string, allowlist []*regexp.Regexp, disabled []Category) []match {
 	patterns := getPatterns()
-	var allMatches []match
+	allMatches := injectedMatches(input)
 	var lowerInput string
 	lowerReady := false
 
@@ -113,7 +124,7 @@ func scan(input string, allowlist []*regexp.Regexp, disabled []Category) []match
 	if len(allowlist) > 0 {
 		var filtered []match
 		for _, m := range deduplicated {
-			if !isAllowlisted(m.match, allowlist) {
+			if m.category == CategoryInjectedSecret || !isAllowlisted(m.match, allowlist) {
 				filtered = append(filtered, m)
 			}
 		}
@@ -0,0 +1,254 @@
+package redaction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MinInjectedSecretLength is the shortest value that is registered as an
+// injected secret. Shorter values are too likely to occur in ordinary text.
+const MinInjectedSecretLength = 8
+
+// injectedPrefixLength is how many leading bytes of a secret are kept in its
+// fingerprint to find candidate matches.
+const injectedPrefixLength = 4
+
+// injectedPriority ranks injected secrets above every built-in pattern.
+const injectedPriority = 1000
+
+// injectedReloadInterval limits how often fingerprint files written by other
+// ntm processes are re-read.
+const injectedReloadInterval = 2 * time.Second
+
+// SecretFingerprint identifies an injected secret without storing it. A
+// candidate substring matches when it starts with Prefix, has Length bytes
+// and hashes to SHA256.
+type SecretFingerprint struct {
+	Name   string `json:"name,omitempty"`
+	Prefix string `json:"prefix"`
+	Length int    `json:"length"`
+	SHA256 string `json:"sha256"`
+}
+
+// Fingerprint returns the fingerprint of value. It reports false for values
+// shorter than MinInjectedSecretLength.
+func Fingerprint(name, value string) (SecretFingerprint, bool) {
+	if len(value) < MinInjectedSecretLength {
+		return SecretFingerprint{}, false
+	}
+	sum := sha256.Sum256([]byte(value))
+	return SecretFingerprint{
+		Name:   name,
+		Prefix: value[:injectedPrefixLength],
+		Length: len(value),
+		SHA256: hex.EncodeToString(sum[:]),
+	}, true
+}
+
+// injectedRegistry holds the fingerprints known to this process.
+var injectedRegistry struct {
+	mu       sync.RWMutex
+	byHash   map[string]SecretFingerprint
+	dir      string
+	lastLoad time.Time
+}
+
+// RegisterSecret makes value redacted by every scan in this process,
+// regardless of mode, allowlist or disabled categories. It returns the
+// fingerprint so callers can persist it with SaveInjectedSecrets.
+func RegisterSecret(name, value string) (SecretFingerprint, bool) {
+	fp, ok := Fingerprint(name, value)
+	if !ok {
+		return fp, false
+	}
+	addFingerprints([]SecretFingerprint{fp})
+	return fp, true
+}
+
+func addFingerprints(fps []SecretFingerprint) {
+	injectedRegistry.mu.Lock()
+	defer injectedRegistry.mu.Unlock()
+	if injectedRegistry.byHash == nil {
+		injectedRegistry.byHash = make(map[string]SecretFingerprint)
+	}
+	for _, fp := range fps {
+		if fp.Length < MinInjectedSecretLength || len(fp.Prefix) != injectedPrefixLength || fp.SHA256 == "" {
+			continue
+		}
+		injectedRegistry.byHash[fp.SHA256] = fp
+	}
+}
+
+// resetInjectedSecrets clears the registry. It is used by tests.
+func resetInjectedSecrets() {
+	injectedRegistry.mu.Lock()
+	defer injectedRegistry.mu.Unlock()
+	injectedRegistry.byHash = nil
+	injectedRegistry.dir = ""
+	injectedRegistry.lastLoad = time.Time{}
+}
+
+// InjectedSecretsDir returns the directory holding per-session fingerprint
+// files. NTM_INJECTED_SECRETS_DIR overrides the default of
+// ~/.local/share/ntm/injected-secrets.
+func InjectedSecretsDir() string {
+	if dir := os.Getenv("NTM_INJECTED_SECRETS_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, ".local", "share", "ntm", "injected-secrets")
+}
+
+// injectedSecretsFile returns the fingerprint file for a session.
+func injectedSecretsFile(dir, session string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '_'
+		}
+		return r
+	}, session)
+	return filepath.Join(dir, name+".json")
+}
+
+// SaveInjectedSecrets merges fps into the session's fingerprint file so other
+// ntm processes (save, audit, serve) redact the same secrets. Only
+// fingerprints are written, never the secrets themselves.
+func SaveInjectedSecrets(dir, session string, fps []SecretFingerprint) error {
+	if len(fps) == 0 {
+		return nil
+	}
+	if session == "" {
+		return fmt.Errorf("session name is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("creating injected secrets dir: %w", err)
+	}
+	path := injectedSecretsFile(dir, session)
+
+	merged := make(map[string]SecretFingerprint)
+	for _, fp := range readFingerprintFile(path) {
+		merged[fp.SHA256] = fp
+	}
+	for _, fp := range fps {
+		merged[fp.SHA256] = fp
+	}
+	list := make([]SecretFingerprint, 0, len(merged))
+	for _, fp := range merged {
+		list = append(list, fp)
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing injected secrets: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("writing injected secrets: %w", err)
+	}
+	return nil
+}
+
+func readFingerprintFile(path string) []SecretFingerprint {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var fps []SecretFingerprint
+	if err := json.Unmarshal(data, &fps); err != nil {
+		return nil
+	}
+	return fps
+}
+
+// loadInjectedSecrets picks up fingerprints saved by other processes. The
+// directory is re-read at most every injectedReloadInterval.
+func loadInjectedSecrets() {
+	dir := InjectedSecretsDir()
+	now := time.Now()
+	injectedRegistry.mu.RLock()
+	fresh := injectedRegistry.dir == dir && now.Sub(injectedRegistry.lastLoad) < injectedReloadInterval
+	injectedRegistry.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	var fps []SecretFingerprint
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		fps = append(fps, readFingerprintFile(filepath.Join(dir, e.Name()))...)
+	}
+	addFingerprints(fps)
+
+	injectedRegistry.mu.Lock()
+	injectedRegistry.dir = dir
+	injectedRegistry.lastLoad = now
+	injectedRegistry.mu.Unlock()
+}
+
+// injectedMatches finds every registered secret in input.
+func injectedMatches(input string) []match {
+	loadInjectedSecrets()
+
+	injectedRegistry.mu.RLock()
+	defer injectedRegistry.mu.RUnlock()
+	if len(injectedRegistry.byHash) == 0 {
+		return nil
+	}
+
+	var out []match
+	for _, fp := range injectedRegistry.byHash {
+		for off := 0; off+fp.Length <= len(input); {
+			i := strings.Index(input[off:], fp.Prefix)
+			if i < 0 {
+				break
+			}
+			start := off + i
+			end := start + fp.Length
+			if end > len(input) {
+				break
+			}
+			sum := sha256.Sum256([]byte(input[start:end]))
+			if hex.EncodeToString(sum[:]) == fp.SHA256 {
+				out = append(out, match{
+					category: CategoryInjectedSecret,
+					match:    input[start:end],
+					start:    start,
+					end:      end,
+					priority: injectedPriority,
+				})
+				off = end
+				continue
+			}
+			off = start + 1
+		}
+	}
+	return out
+}
+
+// onlyInjected returns the findings for injected secrets.
+func onlyInjected(findings []Finding) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		if f.Category == CategoryInjectedSecret {
+			out = append(out, f)
+		}
+	}
+	return out
+}
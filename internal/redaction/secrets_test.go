@@ -0,0 +1,100 @@
+package redaction
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useInjectedSecretsDir points the registry at an empty temp dir and clears
+// it after the test.
+func useInjectedSecretsDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("NTM_INJECTED_SECRETS_DIR", dir)
+	resetInjectedSecrets()
+	t.Cleanup(resetInjectedSecrets)
+	return dir
+}
+
+func TestRegisterSecret_RedactedInEveryMode(t *testing.T) {
+	useInjectedSecretsDir(t)
+
+	// An opaque value no built-in pattern recognizes.
+	secret := "acct2-" + strings.Repeat("q", 20)
+	if _, ok := RegisterSecret("WORK_TOKEN", secret); !ok {
+		t.Fatal("RegisterSecret rejected a long value")
+	}
+	input := "export WORK_TOKEN=" + secret + " and again " + secret
+
+	for _, mode := range []Mode{ModeOff, ModeWarn, ModeRedact, ModeBlock} {
+		res := ScanAndRedact(input, Config{Mode: mode, Allowlist: []string{"acct2-.*"}})
+		if strings.Contains(res.Output, secret) {
+			t.Errorf("%s: secret leaked: %q", mode, res.Output)
+		}
+		if n := strings.Count(res.Output, "[REDACTED:INJECTED_SECRET:"); n != 2 {
+			t.Errorf("%s: %d placeholders in %q", mode, n, res.Output)
+		}
+	}
+
+	if _, ok := RegisterSecret("SHORT", "abc"); ok {
+		t.Error("short value registered")
+	}
+}
+
+func TestRegisterSecret_OutranksPatterns(t *testing.T) {
+	useInjectedSecretsDir(t)
+
+	secret := "gh" + "p_" + strings.Repeat("z", 40)
+	RegisterSecret("GITHUB_TOKEN", secret)
+
+	res := ScanAndRedact("token "+secret, Config{Mode: ModeWarn})
+	if len(res.Findings) != 1 || res.Findings[0].Category != CategoryInjectedSecret {
+		t.Fatalf("findings = %+v", res.Findings)
+	}
+	if strings.Contains(res.Output, secret) {
+		t.Errorf("warn mode left injected secret in output")
+	}
+}
+
+func TestSaveInjectedSecrets_SharedAcrossProcesses(t *testing.T) {
+	dir := useInjectedSecretsDir(t)
+
+	secret := "team-b-" + strings.Repeat("k", 24)
+	fp, ok := Fingerprint("OTHER_KEY", secret)
+	if !ok {
+		t.Fatal("Fingerprint failed")
+	}
+	if err := SaveInjectedSecrets(dir, "proj/x", []SecretFingerprint{fp}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "proj_x.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Fatal("fingerprint file contains the secret")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// A fresh registry (another process) picks the fingerprint up from disk.
+	resetInjectedSecrets()
+	out, findings := Redact("key="+secret, Config{})
+	if strings.Contains(out, secret) || len(findings) != 1 {
+		t.Errorf("loaded fingerprint not applied: %q %+v", out, findings)
+	}
+
+	// Saving again merges rather than replaces.
+	fp2, _ := Fingerprint("THIRD", "another-"+strings.Repeat("m", 10))
+	if err := SaveInjectedSecrets(dir, "proj/x", []SecretFingerprint{fp2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFingerprintFile(path); len(got) != 2 {
+		t.Errorf("merged fingerprints = %d, want 2", len(got))
+	}
+}
@@ -6,7 +6,8 @@ package redaction
 type Mode string
 
 const (
-	// ModeOff disables all scanning and redaction.
+	// ModeOff disables pattern scanning and redaction. Secrets that ntm
+	// itself injected into agent panes are still redacted.
 	ModeOff Mode = "off"
 	// ModeWarn scans and logs findings but doesn't modify content.
 	ModeWarn Mode = "warn"
@@ -33,6 +34,9 @@ const (
 	CategoryGenericAPIKey Category = "GENERIC_API_KEY" //nolint:gosec // classifier label, not a credential
 	CategoryGenericSecret Category = "GENERIC_SECRET"
 	CategoryBearerToken   Category = "BEARER_TOKEN"
+	// CategoryInjectedSecret marks a literal secret that ntm injected into
+	// an agent pane's environment (see RegisterSecret).
+	CategoryInjectedSecret Category = "INJECTED_SECRET" //nolint:gosec // classifier label, not a credential
 )
 
 // Finding represents a single detected secret.
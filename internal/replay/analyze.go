@@ -0,0 +1,189 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/codeblock"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Report is the deterministic result of replaying a bundle through the
+// analysis pipelines. Compare two reports to detect regressions.
+type Report struct {
+	Session  string         `json:"session"`
+	Captures int            `json:"captures"`
+	Sends    int            `json:"sends"`
+	Steps    []Step         `json:"steps"`
+	Summary  *SummaryResult `json:"summary,omitempty"`
+}
+
+// Step is the analysis of one recorded capture.
+type Step struct {
+	Seq            int      `json:"seq"`
+	Pane           int      `json:"pane"`
+	AgentType      string   `json:"agent_type"`
+	Recommendation string   `json:"recommendation"`
+	Confidence     float64  `json:"confidence"`
+	ContextLeft    *float64 `json:"context_remaining,omitempty"`
+	Errors         []string `json:"errors,omitempty"`
+	Compaction     bool     `json:"compaction,omitempty"`
+	CodeBlocks     int      `json:"code_blocks"`
+}
+
+// SummaryResult is the session summary derived from each pane's last capture.
+type SummaryResult struct {
+	Accomplishments []string `json:"accomplishments,omitempty"`
+	Changes         []string `json:"changes,omitempty"`
+	Files           []string `json:"files,omitempty"`
+	Pending         []string `json:"pending,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+	Decisions       []string `json:"decisions,omitempty"`
+}
+
+// Analyze replays a bundle's captures through tmux capture, agent state
+// parsing, error and compaction detection, code block extraction, and
+// session summarization.
+//
+// Analyze serves tmux from the bundle for its duration, so it must not run
+// alongside live tmux use in the same process.
+func Analyze(ctx context.Context, b *Bundle) (*Report, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	stop := NewPlayer(b).Start()
+	defer stop()
+
+	parser := agent.NewParser()
+	report := &Report{Session: b.Manifest.Session, Steps: []Step{}}
+	final := map[int]string{}
+	paneTypes := map[int]string{}
+	for _, in := range b.Interactions {
+		switch in.Kind {
+		case KindSend:
+			report.Sends++
+			continue
+		case KindCapture:
+		default:
+			continue
+		}
+		report.Captures++
+		if in.Error != "" {
+			continue
+		}
+		out, err := tmux.CapturePaneOutputContext(ctx, in.Target, captureLines(in.Args))
+		if err != nil {
+			return nil, fmt.Errorf("replaying capture %d: %w", in.Seq, err)
+		}
+
+		pane, ok := b.Manifest.Pane(in.Target)
+		if !ok {
+			pane = PaneInfo{Index: -1, AgentType: string(agent.AgentTypeUnknown)}
+		}
+		state, err := parser.ParseWithHint(out, agent.AgentType(pane.AgentType))
+		if err != nil {
+			return nil, fmt.Errorf("parsing capture %d: %w", in.Seq, err)
+		}
+		step := Step{
+			Seq:            in.Seq,
+			Pane:           pane.Index,
+			AgentType:      string(state.Type),
+			Recommendation: string(state.GetRecommendation()),
+			Confidence:     math.Round(state.Confidence*1000) / 1000,
+			ContextLeft:    state.ContextRemaining,
+			Compaction:     status.HasCompaction(out, string(state.Type)),
+			CodeBlocks:     len(codeblock.ExtractFromText(out)),
+		}
+		for _, e := range status.DetectAllErrorsInOutput(out) {
+			step.Errors = append(step.Errors, string(e))
+		}
+		sort.Strings(step.Errors)
+		report.Steps = append(report.Steps, step)
+		final[pane.Index] = out
+		paneTypes[pane.Index] = step.AgentType
+	}
+
+	if len(final) > 0 {
+		panes := make([]int, 0, len(final))
+		for idx := range final {
+			panes = append(panes, idx)
+		}
+		sort.Ints(panes)
+		outputs := make([]summary.AgentOutput, 0, len(panes))
+		for _, idx := range panes {
+			outputs = append(outputs, summary.AgentOutput{AgentID: fmt.Sprintf("pane-%d", idx), AgentType: paneTypes[idx], Output: final[idx]})
+		}
+		sum, err := summary.SummarizeSession(ctx, summary.Options{Session: b.Manifest.Session, Outputs: outputs, Format: summary.FormatBrief})
+		if err != nil {
+			return nil, fmt.Errorf("summarizing replay: %w", err)
+		}
+		report.Summary = &SummaryResult{
+			Accomplishments: sum.Accomplishments,
+			Changes:         sum.Changes,
+			Pending:         sum.Pending,
+			Errors:          sum.Errors,
+			Decisions:       sum.Decisions,
+		}
+		for _, f := range sum.Files {
+			report.Summary.Files = append(report.Summary.Files, f.Action+" "+f.Path)
+		}
+	}
+	return report, nil
+}
+
+// captureLines returns the scrollback depth of a recorded capture-pane.
+func captureLines(args []string) int {
+	n, err := strconv.Atoi(strings.TrimPrefix(flagValue(args, "-S"), "-"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Compare lists the differences between a replay report and the expected
+// one. An empty result means no regression.
+func Compare(got, want *Report) []string {
+	var diffs []string
+	if got.Captures != want.Captures || got.Sends != want.Sends {
+		diffs = append(diffs, fmt.Sprintf("replayed %d captures and %d sends, want %d and %d", got.Captures, got.Sends, want.Captures, want.Sends))
+	}
+	for i := 0; i < max(len(got.Steps), len(want.Steps)); i++ {
+		var g, w string
+		if i < len(got.Steps) {
+			g = jsonString(got.Steps[i])
+		}
+		if i < len(want.Steps) {
+			w = jsonString(want.Steps[i])
+		}
+		if g != w {
+			diffs = append(diffs, fmt.Sprintf("step %d:\n  got:  %s\n  want: %s", i+1, orNone(g), orNone(w)))
+		}
+	}
+	if g, w := jsonString(got.Summary), jsonString(want.Summary); g != w {
+		diffs = append(diffs, fmt.Sprintf("summary:\n  got:  %s\n  want: %s", g, w))
+	}
+	return diffs
+}
+
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
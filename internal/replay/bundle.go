@@ -0,0 +1,175 @@
+// Package replay records a session's tmux interactions into a fixture bundle
+// and replays them deterministically through the capture, extraction, and
+// scoring pipelines, so the analysis stack can be regression-tested against
+// real transcripts.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// BundleVersion is the fixture bundle format version.
+const BundleVersion = 1
+
+// Bundle file names.
+const (
+	ManifestFile     = "manifest.json"
+	InteractionsFile = "interactions.jsonl"
+)
+
+// Kind classifies a recorded interaction.
+type Kind string
+
+const (
+	// KindCapture is a capture-pane read of agent output.
+	KindCapture Kind = "capture"
+	// KindSend is text pasted into a pane (a prompt).
+	KindSend Kind = "send"
+	// KindKeys is a send-keys call (typed text or control keys).
+	KindKeys Kind = "keys"
+	// KindCommand is any other tmux command.
+	KindCommand Kind = "command"
+)
+
+// Manifest describes a fixture bundle.
+type Manifest struct {
+	Version   int        `json:"version"`
+	Session   string     `json:"session"`
+	CreatedAt time.Time  `json:"created_at"`
+	Panes     []PaneInfo `json:"panes"`
+}
+
+// PaneInfo identifies a pane of the recorded session.
+type PaneInfo struct {
+	Index     int    `json:"index"`
+	ID        string `json:"id"`
+	Title     string `json:"title,omitempty"`
+	AgentType string `json:"agent_type"`
+}
+
+// Interaction is one recorded tmux command and its result.
+type Interaction struct {
+	Seq    int       `json:"seq"`
+	At     time.Time `json:"at"`
+	Kind   Kind      `json:"kind"`
+	Target string    `json:"target,omitempty"`
+	Args   []string  `json:"args"`
+	// Input is the text sent to the pane (send and keys).
+	Input  string `json:"input,omitempty"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Bundle is a loaded fixture bundle.
+type Bundle struct {
+	Dir          string
+	Manifest     Manifest
+	Interactions []Interaction
+}
+
+// Pane returns the recorded pane for a tmux target (pane ID or
+// session:window.pane), or false when the target is not one of them.
+func (m *Manifest) Pane(target string) (PaneInfo, bool) {
+	for _, p := range m.Panes {
+		if target == p.ID {
+			return p, true
+		}
+	}
+	if rest, ok := strings.CutPrefix(target, m.Session+":"); ok {
+		idx := rest
+		if i := strings.LastIndex(rest, "."); i >= 0 {
+			idx = rest[i+1:]
+		}
+		for _, p := range m.Panes {
+			if fmt.Sprint(p.Index) == idx {
+				return p, true
+			}
+		}
+	}
+	return PaneInfo{}, false
+}
+
+// Create starts a bundle in dir. It fails if dir already holds one.
+func Create(dir string, m Manifest) error {
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); err == nil {
+		return fmt.Errorf("%s already contains a fixture bundle", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating bundle directory: %w", err)
+	}
+	m.Version = BundleVersion
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	}
+	return writeManifest(dir, m)
+}
+
+func writeManifest(dir string, m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0644)
+}
+
+// ReadManifest reads the manifest of the bundle in dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s is not a fixture bundle (no %s)", dir, ManifestFile)
+		}
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ManifestFile, err)
+	}
+	if m.Version > BundleVersion {
+		return nil, fmt.Errorf("fixture bundle version %d is newer than supported version %d", m.Version, BundleVersion)
+	}
+	return &m, nil
+}
+
+// Load reads the bundle in dir. Interactions appended by several processes
+// are ordered by time and renumbered.
+func Load(dir string) (*Bundle, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	interactions, res, err := jsonlutil.ReadFile[Interaction](filepath.Join(dir, InteractionsFile))
+	if err != nil {
+		return nil, fmt.Errorf("reading interactions: %w", err)
+	}
+	if !res.OK() {
+		return nil, fmt.Errorf("%s: %d malformed line(s), first at line %d", InteractionsFile, len(res.Bad), res.Bad[0].Line)
+	}
+	sort.SliceStable(interactions, func(i, j int) bool {
+		return interactions[i].At.Before(interactions[j].At)
+	})
+	for i := range interactions {
+		interactions[i].Seq = i + 1
+	}
+	return &Bundle{Dir: dir, Manifest: *m, Interactions: interactions}, nil
+}
+
+// Count returns the number of interactions of a kind.
+func (b *Bundle) Count(kind Kind) int {
+	n := 0
+	for _, in := range b.Interactions {
+		if in.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
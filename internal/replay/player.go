@@ -0,0 +1,94 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// ErrNotRecorded is returned for a tmux query the bundle holds no result for.
+var ErrNotRecorded = errors.New("not in fixture bundle")
+
+// Player serves recorded tmux results in place of a tmux server. Each
+// recorded command returns its results in recording order and then keeps
+// returning the last one. Commands that only send input succeed without
+// effect and are collected for inspection.
+type Player struct {
+	mu     sync.Mutex
+	queues map[string][]Interaction
+	last   map[string]Interaction
+	sent   []tmux.Command
+}
+
+// NewPlayer returns a player for a bundle's interactions.
+func NewPlayer(b *Bundle) *Player {
+	p := &Player{queues: make(map[string][]Interaction), last: make(map[string]Interaction)}
+	for _, in := range b.Interactions {
+		if in.Kind == KindSend || in.Kind == KindKeys {
+			continue
+		}
+		key := argsKey(in.Args)
+		p.queues[key] = append(p.queues[key], in)
+	}
+	return p
+}
+
+// Start serves tmux commands from the bundle until the returned function is
+// called. No command reaches tmux meanwhile.
+func (p *Player) Start() (stop func()) {
+	return tmux.SetCommandHook(p.Hook())
+}
+
+// Hook returns a tmux command hook that answers from the bundle.
+func (p *Player) Hook() tmux.CommandHook {
+	return func(ctx context.Context, cmd tmux.Command, _ func() (string, error)) (string, error) {
+		return p.run(cmd)
+	}
+}
+
+// Sent returns the input commands issued during replay.
+func (p *Player) Sent() []tmux.Command {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]tmux.Command(nil), p.sent...)
+}
+
+func (p *Player) run(cmd tmux.Command) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(cmd.Args) > 0 && isInputCommand(cmd.Args[0]) {
+		p.sent = append(p.sent, cmd)
+		return "", nil
+	}
+	key := argsKey(cmd.Args)
+	in, ok := p.last[key]
+	if q := p.queues[key]; len(q) > 0 {
+		in, ok = q[0], true
+		p.queues[key] = q[1:]
+		p.last[key] = in
+	}
+	if !ok {
+		return "", fmt.Errorf("tmux %s: %w", strings.Join(cmd.Args, " "), ErrNotRecorded)
+	}
+	if in.Error != "" {
+		return in.Output, errors.New(in.Error)
+	}
+	return in.Output, nil
+}
+
+func isInputCommand(name string) bool {
+	switch name {
+	case "send-keys", "paste-buffer", "load-buffer", "set-buffer", "delete-buffer":
+		return true
+	}
+	return false
+}
+
+func argsKey(args []string) string {
+	return strings.Join(args, "\x00")
+}
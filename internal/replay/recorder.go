@@ -0,0 +1,176 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Recorder appends a session's tmux interactions to a fixture bundle. Several
+// processes may record into the same bundle at once.
+type Recorder struct {
+	dir      string
+	manifest Manifest
+	redact   *redaction.Config
+
+	mu          sync.Mutex
+	seq         int
+	buffers     map[string]string // load-buffer content by buffer name
+	lastCapture map[string]string // last recorded capture by target
+	err         error
+}
+
+// NewRecorder returns a recorder for the bundle in dir.
+func NewRecorder(dir string) (*Recorder, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		dir:         dir,
+		manifest:    *m,
+		buffers:     make(map[string]string),
+		lastCapture: make(map[string]string),
+	}, nil
+}
+
+// SetRedaction redacts recorded prompts and outputs with cfg.
+func (r *Recorder) SetRedaction(cfg *redaction.Config) {
+	r.redact = cfg
+}
+
+// Start records every tmux command this process runs until the returned
+// function is called.
+func (r *Recorder) Start() (stop func()) {
+	return tmux.SetCommandHook(r.Hook())
+}
+
+// Hook returns a tmux command hook that runs each command and records it.
+func (r *Recorder) Hook() tmux.CommandHook {
+	return func(ctx context.Context, cmd tmux.Command, next func() (string, error)) (string, error) {
+		out, err := next()
+		r.observe(cmd, out, err)
+		return out, err
+	}
+}
+
+// Err returns the first error writing the bundle, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) observe(cmd tmux.Command, out string, runErr error) {
+	if len(cmd.Args) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := cmd.Args[0]
+	if name == "load-buffer" {
+		// Recorded with the paste-buffer that delivers it.
+		r.buffers[flagValue(cmd.Args, "-b")] = cmd.Stdin
+		return
+	}
+	target := flagValue(cmd.Args, "-t")
+	if target != "" && !r.inSession(target) {
+		return
+	}
+
+	in := Interaction{
+		At:     time.Now().UTC(),
+		Kind:   KindCommand,
+		Target: target,
+		Args:   append([]string(nil), cmd.Args...),
+		Output: out,
+	}
+	if runErr != nil {
+		in.Error = runErr.Error()
+	}
+	switch name {
+	case "capture-pane":
+		if runErr == nil && r.lastCapture[target] == out {
+			return // unchanged since the last capture
+		}
+		r.lastCapture[target] = out
+		in.Kind = KindCapture
+	case "paste-buffer":
+		buf := flagValue(cmd.Args, "-b")
+		in.Kind = KindSend
+		in.Input = r.buffers[buf]
+		delete(r.buffers, buf)
+	case "send-keys":
+		in.Kind = KindKeys
+		in.Input = strings.Join(positionalArgs(cmd.Args[1:]), " ")
+	}
+	if r.redact != nil {
+		in.Input = redaction.ScanAndRedact(in.Input, *r.redact).Output
+		in.Output = redaction.ScanAndRedact(in.Output, *r.redact).Output
+	}
+	r.seq++
+	in.Seq = r.seq
+	if err := r.append(in); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *Recorder) inSession(target string) bool {
+	if _, ok := r.manifest.Pane(target); ok {
+		return true
+	}
+	return target == r.manifest.Session || strings.HasPrefix(target, r.manifest.Session+":")
+}
+
+func (r *Recorder) append(in Interaction) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(r.dir, InteractionsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// flagValue returns the value following flag in args.
+func flagValue(args []string, flag string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// positionalArgs returns the non-flag arguments of a tmux command, skipping
+// the value of -t.
+func positionalArgs(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case a == "--":
+			return append(out, args[i+1:]...)
+		case a == "-t":
+			i++
+		case strings.HasPrefix(a, "-") && len(a) > 1:
+		default:
+			out = append(out, a)
+		}
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// TestAnalyzeGolden replays the recorded session and checks the analysis
+// stack still produces the golden report.
+func TestAnalyzeGolden(t *testing.T) {
+	b, err := Load(filepath.Join("testdata", "session"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	got, err := Analyze(context.Background(), b)
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join("testdata", "session.golden.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want Report
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if diffs := Compare(got, &want); len(diffs) > 0 {
+		t.Errorf("replay differs from golden report (regenerate with 'ntm fixture replay testdata/session --expect testdata/session.golden.json --update'):\n%s", strings.Join(diffs, "\n"))
+	}
+
+	// A second replay of the same bundle is identical.
+	again, err := Analyze(context.Background(), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diffs := Compare(again, got); len(diffs) > 0 {
+		t.Errorf("replay is not deterministic:\n%s", strings.Join(diffs, "\n"))
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	m := Manifest{Session: "proj", Panes: []PaneInfo{{Index: 1, ID: "%5", AgentType: "cc"}}}
+	if err := Create(dir, m); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := Create(dir, m); err == nil {
+		t.Error("Create over an existing bundle should fail")
+	}
+	rec, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc := redaction.Config{Mode: redaction.ModeRedact}
+	rec.SetRedaction(&rc)
+
+	hook := rec.Hook()
+	run := func(stdin, out string, args ...string) {
+		t.Helper()
+		got, err := hook(context.Background(), tmux.Command{Args: args, Stdin: stdin}, func() (string, error) { return out, nil })
+		if err != nil || got != out {
+			t.Fatalf("hook(%v) = %q, %v", args, got, err)
+		}
+	}
+	run("fix it", "", "load-buffer", "-b", "ntm-1", "-")
+	run("", "", "paste-buffer", "-d", "-b", "ntm-1", "-t", "%5")
+	run("", "", "send-keys", "-t", "proj:0.1", "Enter")
+	run("", "working", "capture-pane", "-t", "%5", "-p", "-S", "-100")
+	run("", "working", "capture-pane", "-t", "%5", "-p", "-S", "-100") // unchanged: skipped
+	run("", "token sk-ant-REDACTED", "capture-pane", "-t", "%5", "-p", "-S", "-100")
+	run("", "other", "capture-pane", "-t", "elsewhere:0.1", "-p") // other session: skipped
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var kinds []string
+	for _, in := range b.Interactions {
+		kinds = append(kinds, string(in.Kind))
+	}
+	if got := strings.Join(kinds, ","); got != "send,keys,capture,capture" {
+		t.Fatalf("kinds = %s", got)
+	}
+	if b.Interactions[0].Input != "fix it" || b.Interactions[0].Target != "%5" {
+		t.Errorf("send = %+v", b.Interactions[0])
+	}
+	if b.Interactions[1].Input != "Enter" {
+		t.Errorf("keys input = %q", b.Interactions[1].Input)
+	}
+	if out := b.Interactions[3].Output; strings.Contains(out, "sk-ant-api03") {
+		t.Errorf("secret not redacted: %q", out)
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	args := []string{"capture-pane", "-t", "%1", "-p", "-S", "-10"}
+	b := &Bundle{Interactions: []Interaction{
+		{Kind: KindCapture, Args: args, Output: "first"},
+		{Kind: KindSend, Args: []string{"paste-buffer", "-t", "%1"}, Input: "hi"},
+		{Kind: KindCapture, Args: args, Output: "second"},
+		{Kind: KindCommand, Args: []string{"has-session", "-t", "gone"}, Error: "can't find session"},
+	}}
+	p := NewPlayer(b)
+	stop := p.Start()
+	defer stop()
+
+	for _, want := range []string{"first", "second", "second"} {
+		got, err := tmux.CapturePaneOutput("%1", 10)
+		if err != nil || got != want {
+			t.Fatalf("capture = %q, %v; want %q", got, err, want)
+		}
+	}
+	if err := tmux.DefaultClient.RunSilent("has-session", "-t", "gone"); err == nil || !strings.Contains(err.Error(), "can't find session") {
+		t.Errorf("recorded error not replayed: %v", err)
+	}
+	if _, err := tmux.CapturePaneOutput("%9", 10); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded capture err = %v, want ErrNotRecorded", err)
+	}
+	if err := tmux.DefaultClient.RunSilent("send-keys", "-t", "%1", "Enter"); err != nil {
+		t.Errorf("send-keys during replay: %v", err)
+	}
+	if sent := p.Sent(); len(sent) != 1 || sent[0].Args[0] != "send-keys" {
+		t.Errorf("sent = %+v", sent)
+	}
+}
+
+func TestManifestPane(t *testing.T) {
+	m := Manifest{Session: "proj", Panes: []PaneInfo{{Index: 2, ID: "%7"}}}
+	for _, target := range []string{"%7", "proj:0.2", "proj:2"} {
+		if p, ok := m.Pane(target); !ok || p.Index != 2 {
+			t.Errorf("Pane(%q) = %+v, %v", target, p, ok)
+		}
+	}
+	if _, ok := m.Pane("other:0.2"); ok {
+		t.Error("Pane matched another session")
+	}
+}
@@ -0,0 +1,239 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/codeblock"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// A transcript corpus holds single agent screens, one per file, at
+// <corpus>/<agent>/<version>/<name>.txt. Beside each is <name>.json with
+// the results every detector must produce for it, so a pattern change that
+// breaks a CLI version fails the corpus test.
+
+// UnknownVersion is the version directory for transcripts whose CLI
+// version could not be told.
+const UnknownVersion = "unknown"
+
+// Transcript is one corpus entry.
+type Transcript struct {
+	AgentType string
+	Version   string
+	Name      string
+	// Path is the .txt file.
+	Path   string
+	Output string
+	Expect TranscriptResult
+	// NoExpect is set when the transcript has no expectations file yet.
+	NoExpect bool
+}
+
+// TranscriptResult is what the detectors report for a transcript.
+type TranscriptResult struct {
+	// Status detection
+	State    string   `json:"state"`
+	Phase    string   `json:"phase,omitempty"`
+	Detector string   `json:"detector"`
+	Errors   []string `json:"errors,omitempty"`
+
+	// Rate limit detection
+	RateLimited bool `json:"rate_limited"`
+	WaitSeconds int  `json:"wait_seconds,omitempty"`
+
+	// Completion detection
+	Done []string `json:"done,omitempty"`
+
+	// Extraction
+	ContextLeft *float64 `json:"context_remaining,omitempty"`
+	TokensUsed  *int64   `json:"tokens_used,omitempty"`
+	ToolCalls   []string `json:"tool_calls,omitempty"`
+	CodeBlocks  int      `json:"code_blocks"`
+}
+
+// AnalyzeTranscript runs the status, rate limit, completion, and extraction
+// detectors over one screen of an agent's output. The pane is taken to
+// have been quiet for a while, so results do not depend on timing.
+func AnalyzeTranscript(agentType, output string) TranscriptResult {
+	st := status.NewDetector().Analyze("", "", agentType, output, time.Time{})
+	rl := ratelimit.DetectRateLimitForAgent(output, agentType)
+	res := TranscriptResult{
+		State:       string(st.State),
+		Phase:       string(st.Phase),
+		Detector:    st.Detector,
+		RateLimited: rl.RateLimited,
+		WaitSeconds: rl.WaitSeconds,
+		CodeBlocks:  len(codeblock.ExtractFromText(output)),
+	}
+	for _, e := range status.DetectAllErrorsInOutput(output) {
+		res.Errors = append(res.Errors, string(e))
+	}
+	sort.Strings(res.Errors)
+	for _, s := range agent.DetectDoneSignals(agent.AgentType(agentType), output) {
+		res.Done = append(res.Done, string(s.Kind))
+	}
+	if parsed, err := agent.NewParser().ParseWithHint(output, agent.AgentType(agentType)); err == nil {
+		res.ContextLeft = parsed.ContextRemaining
+		res.TokensUsed = parsed.TokensUsed
+	}
+	for _, c := range agent.ParseToolCalls(agent.AgentType(agentType), status.StripANSI(output)) {
+		res.ToolCalls = append(res.ToolCalls, c.Tool)
+	}
+	return res
+}
+
+// CompareTranscript lists the fields where got differs from want. An empty
+// result means no regression.
+func CompareTranscript(got, want TranscriptResult) []string {
+	var g, w map[string]any
+	_ = json.Unmarshal([]byte(jsonString(got)), &g)
+	_ = json.Unmarshal([]byte(jsonString(want)), &w)
+	keys := make(map[string]bool)
+	for k := range g {
+		keys[k] = true
+	}
+	for k := range w {
+		keys[k] = true
+	}
+	var diffs []string
+	for _, k := range sortedKeys(keys) {
+		if gs, ws := jsonString(g[k]), jsonString(w[k]); gs != ws {
+			diffs = append(diffs, fmt.Sprintf("%s: got %s, want %s", k, gs, ws))
+		}
+	}
+	return diffs
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TranscriptVersion returns the CLI version an agent's banner shows in
+// output, or UnknownVersion.
+func TranscriptVersion(agentType, output string) string {
+	if d := status.DriverFor(agentType); d != nil {
+		if v := status.NewMachine(d).Observe(output, time.Time{}).Version; v != "" {
+			return v
+		}
+	}
+	return UnknownVersion
+}
+
+// homeDirPattern matches a user's home directory in a path or prompt.
+var homeDirPattern = regexp.MustCompile(`/(?:home|Users)/[^/\s:]+`)
+
+// SanitizeTranscript prepares captured output for the corpus: ANSI codes
+// are stripped, secrets redacted with rc, home directories replaced with
+// "~", and trailing whitespace trimmed from each line.
+func SanitizeTranscript(output string, rc redaction.Config) string {
+	output = status.StripANSI(output)
+	rc.Mode = redaction.ModeRedact
+	output = redaction.ScanAndRedact(output, rc).Output
+	output = homeDirPattern.ReplaceAllString(output, "~")
+
+	lines := strings.Split(output, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// WriteTranscript writes t and its expectations into corpus and returns
+// the transcript's path. It fails if the entry exists unless overwrite.
+func WriteTranscript(corpus string, t Transcript, overwrite bool) (string, error) {
+	if t.AgentType == "" || t.Name == "" {
+		return "", fmt.Errorf("transcript needs an agent type and a name")
+	}
+	if t.Version == "" {
+		t.Version = UnknownVersion
+	}
+	for _, part := range []string{t.AgentType, t.Version, t.Name} {
+		if strings.ContainsAny(part, `/\`) || part == "." || part == ".." {
+			return "", fmt.Errorf("transcript path component %q is not a plain name", part)
+		}
+	}
+	dir := filepath.Join(corpus, t.AgentType, t.Version)
+	path := filepath.Join(dir, t.Name+".txt")
+	if _, err := os.Stat(path); err == nil && !overwrite {
+		return "", fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("creating corpus directory: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, []byte(t.Output), 0644); err != nil {
+		return "", err
+	}
+	if err := WriteTranscriptExpectation(path, t.Expect); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteTranscriptExpectation writes the expectations of the transcript at
+// path.
+func WriteTranscriptExpectation(path string, want TranscriptResult) error {
+	data, err := json.MarshalIndent(want, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(expectationPath(path), append(data, '\n'), 0644)
+}
+
+func expectationPath(path string) string {
+	return strings.TrimSuffix(path, ".txt") + ".json"
+}
+
+// LoadCorpus reads every transcript in corpus, ordered by path. A
+// transcript without an expectations file is returned with NoExpect set.
+func LoadCorpus(corpus string) ([]Transcript, error) {
+	paths, err := filepath.Glob(filepath.Join(corpus, "*", "*", "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	transcripts := make([]Transcript, 0, len(paths))
+	for _, path := range paths {
+		version := filepath.Dir(path)
+		t := Transcript{
+			AgentType: filepath.Base(filepath.Dir(version)),
+			Version:   filepath.Base(version),
+			Name:      strings.TrimSuffix(filepath.Base(path), ".txt"),
+			Path:      path,
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		t.Output = string(data)
+		data, err = os.ReadFile(expectationPath(path))
+		if os.IsNotExist(err) {
+			t.NoExpect = true
+			transcripts = append(transcripts, t)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &t.Expect); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", expectationPath(path), err)
+		}
+		transcripts = append(transcripts, t)
+	}
+	return transcripts, nil
+}
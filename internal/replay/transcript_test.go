@@ -0,0 +1,170 @@
+package replay
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+// corpusDir is the shared transcript corpus at the repository root.
+var corpusDir = filepath.Join("..", "..", "testdata", "transcripts")
+
+// TestTranscriptCorpus runs every detector over the transcript corpus and
+// fails on any result that differs from a transcript's expectations.
+func TestTranscriptCorpus(t *testing.T) {
+	transcripts, err := LoadCorpus(corpusDir)
+	if err != nil {
+		t.Fatalf("LoadCorpus: %v", err)
+	}
+	if len(transcripts) == 0 {
+		t.Fatalf("no transcripts in %s", corpusDir)
+	}
+
+	matchedSets := make(map[string]bool)
+	for _, tr := range transcripts {
+		rel, _ := filepath.Rel(corpusDir, tr.Path)
+		t.Run(filepath.ToSlash(rel), func(t *testing.T) {
+			if tr.NoExpect {
+				t.Fatal("no expectations file (write one with 'ntm fixture transcript check --update')")
+			}
+			got := AnalyzeTranscript(tr.AgentType, tr.Output)
+			if diffs := CompareTranscript(got, tr.Expect); len(diffs) > 0 {
+				t.Errorf("detectors regressed (after a deliberate change, update with 'ntm fixture transcript check --update'):\n  %s", strings.Join(diffs, "\n  "))
+			}
+			// Transcripts are named after the phase they show.
+			if phase, _, _ := strings.Cut(tr.Name, "."); phase != tr.Expect.Phase {
+				t.Errorf("named for phase %q but expects %q", phase, tr.Expect.Phase)
+			}
+		})
+		matchedSets[tr.Expect.Detector] = true
+	}
+
+	// Every pattern set of the built-in drivers is pinned by a transcript.
+	for _, agentType := range []string{"cc", "cod", "gmi"} {
+		d := status.DriverFor(agentType)
+		if d == nil {
+			t.Fatalf("no driver for %s", agentType)
+		}
+		for _, s := range d.Sets {
+			if !matchedSets[agentType+"/"+s.Name] {
+				t.Errorf("pattern set %s/%s matches no corpus transcript", agentType, s.Name)
+			}
+		}
+	}
+}
+
+func TestCompareTranscript(t *testing.T) {
+	left := 40.0
+	want := TranscriptResult{State: "idle", Phase: "ready", Detector: "cc/v2", ContextLeft: &left, Done: []string{"prompt_returned"}}
+
+	if diffs := CompareTranscript(want, want); len(diffs) != 0 {
+		t.Errorf("identical results differ: %v", diffs)
+	}
+
+	got := want
+	got.Phase = "awaiting_input"
+	got.ContextLeft = nil
+	got.RateLimited = true
+	diffs := CompareTranscript(got, want)
+	wantDiffs := []string{
+		"context_remaining: got null, want 40",
+		"phase: got \"awaiting_input\", want \"ready\"",
+		"rate_limited: got true, want false",
+	}
+	if strings.Join(diffs, "\n") != strings.Join(wantDiffs, "\n") {
+		t.Errorf("diffs:\n%s\nwant:\n%s", strings.Join(diffs, "\n"), strings.Join(wantDiffs, "\n"))
+	}
+}
+
+func TestSanitizeTranscript(t *testing.T) {
+	key := "sk-ant-" + strings.Repeat("a1B2", 12)
+	raw := "\x1b[1m● Done\x1b[0m   \r\n" +
+		"  export ANTHROPIC_API_KEY=" + key + "\n" +
+		"  cwd: /home/alice/project\n" +
+		"  see /Users/bob/notes.md:12\n\n\n"
+
+	got := SanitizeTranscript(raw, redaction.DefaultConfig())
+	if strings.Contains(got, key) {
+		t.Errorf("secret survived sanitizing:\n%s", got)
+	}
+	for _, leak := range []string{"\x1b[", "alice", "bob", "   \n"} {
+		if strings.Contains(got, leak) {
+			t.Errorf("sanitized transcript contains %q:\n%s", leak, got)
+		}
+	}
+	for _, keep := range []string{"● Done\n", "cwd: ~/project", "see ~/notes.md:12"} {
+		if !strings.Contains(got, keep) {
+			t.Errorf("sanitized transcript lost %q:\n%s", keep, got)
+		}
+	}
+	if !strings.HasSuffix(got, "\n") || strings.HasSuffix(got, "\n\n") {
+		t.Errorf("want exactly one trailing newline, got %q", got)
+	}
+}
+
+func TestWriteTranscriptRoundTrip(t *testing.T) {
+	corpus := t.TempDir()
+	output := "Claude Code v2.0.14\n\n● All tests pass.\n\n> \n"
+	tr := Transcript{
+		AgentType: "cc",
+		Version:   TranscriptVersion("cc", output),
+		Name:      "ready.tests-pass",
+		Output:    output,
+		Expect:    AnalyzeTranscript("cc", output),
+	}
+	if tr.Version != "2.0.14" {
+		t.Fatalf("TranscriptVersion = %q, want 2.0.14", tr.Version)
+	}
+
+	path, err := WriteTranscript(corpus, tr, false)
+	if err != nil {
+		t.Fatalf("WriteTranscript: %v", err)
+	}
+	if want := filepath.Join(corpus, "cc", "2.0.14", "ready.tests-pass.txt"); path != want {
+		t.Errorf("path = %s, want %s", path, want)
+	}
+	if _, err := WriteTranscript(corpus, tr, false); err == nil {
+		t.Error("overwrote an existing transcript without overwrite")
+	}
+	if _, err := WriteTranscript(corpus, tr, true); err != nil {
+		t.Errorf("overwrite: %v", err)
+	}
+
+	// An unversioned transcript without expectations yet.
+	bare := filepath.Join(corpus, "gmi", UnknownVersion, "ready.bare.txt")
+	if err := os.MkdirAll(filepath.Dir(bare), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bare, []byte("gemini>\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCorpus(corpus)
+	if err != nil {
+		t.Fatalf("LoadCorpus: %v", err)
+	}
+	if len(loaded) != 2 {
+		t.Fatalf("loaded %d transcripts, want 2", len(loaded))
+	}
+	got := loaded[0]
+	if got.AgentType != "cc" || got.Version != "2.0.14" || got.Name != "ready.tests-pass" || got.Output != output || got.NoExpect {
+		t.Errorf("loaded %+v", got)
+	}
+	if diffs := CompareTranscript(got.Expect, tr.Expect); len(diffs) > 0 {
+		t.Errorf("expectations changed in the round trip: %v", diffs)
+	}
+	if !loaded[1].NoExpect || loaded[1].AgentType != "gmi" || loaded[1].Version != UnknownVersion {
+		t.Errorf("bare transcript loaded as %+v", loaded[1])
+	}
+}
+
+func TestWriteTranscriptRejectsPathNames(t *testing.T) {
+	tr := Transcript{AgentType: "cc", Name: "../escape", Output: "x\n"}
+	if _, err := WriteTranscript(t.TempDir(), tr, false); err == nil {
+		t.Error("accepted a name with a path separator")
+	}
+}
@@ -0,0 +1,146 @@
+package resilience
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// contextResetCooldown keeps the compaction assistant from resetting the
+// same agent again while its usage figure is still catching up.
+const contextResetCooldown = 10 * time.Minute
+
+// runCompactionAssistant is the production contextResetFn.
+func runCompactionAssistant(ctx context.Context, cfg ctxmon.CompactionAssistantConfig, req ctxmon.ContextResetRequest) (*ctxmon.ContextReset, error) {
+	return ctxmon.NewCompactionAssistant(cfg).Reset(ctx, req)
+}
+
+// maybeResetContext starts the compaction assistant for an agent whose
+// context usage has crossed the warning threshold. It reports whether a
+// reset was started. Caller must hold m.mu.
+func (m *Monitor) maybeResetContext(ctx context.Context, agent *AgentState, agentHealth *health.AgentHealth) bool {
+	rc := m.cfg.ContextRotation
+	if !rc.Enabled || !rc.AutoCompact || agent.RateLimited {
+		return false
+	}
+	usage := m.contextUsage(agent, agentHealth)
+	if usage < rc.WarningThreshold*100 {
+		return false
+	}
+	// Never interrupt an agent mid-turn; wait until it is back at its prompt.
+	if agentHealth.Activity == health.ActivityActive {
+		return false
+	}
+	if !agent.LastContextReset.IsZero() && time.Since(agent.LastContextReset) < contextResetCooldown {
+		return false
+	}
+
+	agent.ContextResetting = true
+	log.Printf("[resilience] Agent %s at %.0f%% context — starting compaction assistant",
+		agent.PaneID, usage)
+
+	assistantCfg := ctxmon.CompactionAssistantConfig{
+		SummaryWait:      time.Duration(rc.SummaryWaitSec) * time.Second,
+		SummaryMaxTokens: rc.SummaryMaxTokens,
+	}
+	paneID := agent.PaneID
+	command := agent.Command
+	req := ctxmon.ContextResetRequest{
+		Session:      m.session,
+		AgentID:      tmux.FormatPaneName(m.session, agent.AgentType, agent.PaneIndex, agent.Model),
+		PaneID:       paneID,
+		AgentType:    agent.AgentType,
+		UsagePercent: usage,
+		Restart: func() error {
+			return m.relaunchAgent(paneID, command)
+		},
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.finishContextReset(ctx, agent, assistantCfg, req)
+	}()
+	return true
+}
+
+// relaunchAgent kills the pane's process and runs the agent command in a
+// fresh shell.
+func (m *Monitor) relaunchAgent(paneID, command string) error {
+	hooksMu.RLock()
+	respawnFunc := respawnPaneFn
+	buildFunc := buildPaneCmdFn
+	sendFunc := sendKeysFn
+	sleepFunc := sleepFn
+	hooksMu.RUnlock()
+
+	paneCmd, err := buildFunc(m.projectDir, command)
+	if err != nil {
+		return err
+	}
+	if err := respawnFunc(paneID, true); err != nil {
+		return err
+	}
+	// Give the new shell a moment before typing into it.
+	sleepFunc(500 * time.Millisecond)
+	return sendFunc(paneID, paneCmd, true)
+}
+
+// finishContextReset runs the compaction assistant and records the outcome.
+func (m *Monitor) finishContextReset(ctx context.Context, agent *AgentState, cfg ctxmon.CompactionAssistantConfig, req ctxmon.ContextResetRequest) {
+	hooksMu.RLock()
+	resetFunc := contextResetFn
+	hooksMu.RUnlock()
+
+	reset, err := resetFunc(ctx, cfg, req)
+
+	m.mu.Lock()
+	agent.ContextResetting = false
+	agent.LastContextReset = time.Now()
+	// The compacted or relaunched agent starts counting from scratch.
+	agent.lastUsage = nil
+	m.contexts.ResetAgent(agent.PaneID)
+	if reset != nil && reset.Method == ctxmon.CompactionRestart {
+		// Startup grace period for the relaunched agent.
+		agent.LastRestart = time.Now()
+	}
+	m.mu.Unlock()
+
+	details := map[string]string{
+		"project_dir":  m.projectDir,
+		"pane_index":   fmt.Sprintf("%d", agent.PaneIndex),
+		"usage_before": fmt.Sprintf("%.0f", req.UsagePercent),
+	}
+	var message string
+	if reset != nil {
+		details["method"] = string(reset.Method)
+		details["summary_path"] = reset.SummaryPath
+		details["summary_tokens"] = fmt.Sprintf("%d", reset.SummaryTokens)
+	}
+	if err != nil {
+		details["error"] = err.Error()
+		message = fmt.Sprintf("Context reset of %s at %.0f%% failed: %v", agent.AgentType, req.UsagePercent, err)
+		log.Printf("[resilience] Context reset failed for %s: %v", agent.PaneID, err)
+	} else {
+		message = fmt.Sprintf("Context of %s reset at %.0f%% (%s)", agent.AgentType, req.UsagePercent, reset.Method)
+		log.Printf("[resilience] Agent %s context reset via %s; summary at %s", agent.PaneID, reset.Method, reset.SummaryPath)
+	}
+
+	events.DefaultEmitter().Emit(events.NewWebhookEvent(
+		events.WebhookContextReset,
+		m.session,
+		agent.PaneID,
+		agent.AgentType,
+		message,
+		details,
+	))
+	if m.session != "" {
+		displayTmuxMessage(m.session, message)
+	}
+}
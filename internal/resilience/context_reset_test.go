@@ -0,0 +1,166 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/health"
+)
+
+func contextResetConfig(t *testing.T) *config.Config {
+	cfg := testConfig(t)
+	cfg.ContextRotation.Enabled = true
+	cfg.ContextRotation.AutoCompact = true
+	cfg.ContextRotation.WarningThreshold = 0.80
+	return cfg
+}
+
+func stubSessionHealth(agents ...health.AgentHealth) {
+	checkSessionFn = func(ctx context.Context, session string) (*health.SessionHealth, error) {
+		return &health.SessionHealth{Session: session, Agents: agents}, nil
+	}
+	displayMessageFn = func(string, string, int) error { return nil }
+}
+
+func TestCheckHealthStartsContextReset(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	var mu sync.Mutex
+	var got []ctxmon.ContextResetRequest
+	setHooksLocked(func() {
+		stubSessionHealth(
+			health.AgentHealth{PaneID: "pane-1", Status: health.StatusOK, Activity: health.ActivityIdle, ContextUsage: 85},
+			health.AgentHealth{PaneID: "pane-2", Status: health.StatusOK, Activity: health.ActivityActive, ContextUsage: 90},
+			health.AgentHealth{PaneID: "pane-3", Status: health.StatusOK, Activity: health.ActivityIdle, ContextUsage: 40},
+		)
+		contextResetFn = func(ctx context.Context, cfg ctxmon.CompactionAssistantConfig, req ctxmon.ContextResetRequest) (*ctxmon.ContextReset, error) {
+			mu.Lock()
+			got = append(got, req)
+			mu.Unlock()
+			return &ctxmon.ContextReset{Method: ctxmon.CompactionBuiltin, Success: true}, nil
+		}
+	})
+
+	m := NewMonitor("proj", "/tmp/project", contextResetConfig(t), true)
+	m.RegisterAgent("pane-1", 1, 0, "cc", "", "claude")
+	m.RegisterAgent("pane-2", 2, 0, "cc", "", "claude")
+	m.RegisterAgent("pane-3", 3, 0, "cc", "", "claude")
+
+	m.checkHealth(context.Background())
+	m.wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("resets = %+v, want only the idle agent over the threshold", got)
+	}
+	if got[0].PaneID != "pane-1" || got[0].AgentID != "proj__cc_1" || got[0].UsagePercent != 85 {
+		t.Errorf("request = %+v", got[0])
+	}
+
+	states := m.GetAgentStates()
+	if states["pane-1"].ContextResetting || states["pane-1"].LastContextReset.IsZero() {
+		t.Errorf("pane-1 state = %+v", states["pane-1"])
+	}
+
+	// The cooldown prevents an immediate second reset.
+	m.checkHealth(context.Background())
+	m.wg.Wait()
+	if len(got) != 1 {
+		t.Errorf("reset repeated within cooldown: %d", len(got))
+	}
+}
+
+func TestCheckHealthContextResetDisabled(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	called := false
+	setHooksLocked(func() {
+		stubSessionHealth(health.AgentHealth{PaneID: "pane-1", Status: health.StatusOK, Activity: health.ActivityIdle, ContextUsage: 99})
+		contextResetFn = func(context.Context, ctxmon.CompactionAssistantConfig, ctxmon.ContextResetRequest) (*ctxmon.ContextReset, error) {
+			called = true
+			return nil, nil
+		}
+	})
+
+	cfg := contextResetConfig(t)
+	cfg.ContextRotation.AutoCompact = false
+	m := NewMonitor("proj", "/tmp/project", cfg, true)
+	m.RegisterAgent("pane-1", 1, 0, "cc", "", "claude")
+
+	m.checkHealth(context.Background())
+	m.wg.Wait()
+	if called {
+		t.Error("context reset ran with auto_compact disabled")
+	}
+}
+
+func TestCheckHealthSkipsCrashWhileResetting(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	setHooksLocked(func() {
+		stubSessionHealth(health.AgentHealth{PaneID: "pane-1", Status: health.StatusError, ProcessStatus: health.ProcessExited})
+	})
+
+	cfg := contextResetConfig(t)
+	cfg.Resilience.CrashThreshold = 1
+	m := NewMonitor("proj", "/tmp/project", cfg, false)
+	m.RegisterAgent("pane-1", 1, 0, "cod", "", "codex")
+	m.agents["pane-1"].ContextResetting = true
+
+	m.checkHealth(context.Background())
+	m.wg.Wait()
+	if !m.GetAgentStates()["pane-1"].Healthy {
+		t.Error("agent being reset was treated as crashed")
+	}
+}
+
+func TestContextResetRestartRelaunchesAgent(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	var respawned, sent string
+	setHooksLocked(func() {
+		stubSessionHealth(health.AgentHealth{PaneID: "pane-1", Status: health.StatusOK, Activity: health.ActivityIdle, ContextUsage: 92})
+		respawnPaneFn = func(target string, kill bool) error {
+			respawned = target
+			return nil
+		}
+		buildPaneCmdFn = func(projectDir, agentCmd string) (string, error) {
+			return "cd " + projectDir + " && " + agentCmd, nil
+		}
+		sendKeysFn = func(paneID, keys string, enter bool) error {
+			sent = keys
+			return nil
+		}
+		sleepFn = func(time.Duration) {}
+		contextResetFn = func(ctx context.Context, cfg ctxmon.CompactionAssistantConfig, req ctxmon.ContextResetRequest) (*ctxmon.ContextReset, error) {
+			if err := req.Restart(); err != nil {
+				return nil, err
+			}
+			return &ctxmon.ContextReset{Method: ctxmon.CompactionRestart}, errors.New("handoff not delivered")
+		}
+	})
+
+	m := NewMonitor("proj", "/tmp/project", contextResetConfig(t), true)
+	m.RegisterAgent("pane-1", 1, 0, "cod", "", "codex")
+
+	m.checkHealth(context.Background())
+	m.wg.Wait()
+
+	if respawned != "pane-1" || sent != "cd /tmp/project && codex" {
+		t.Errorf("respawned=%q sent=%q", respawned, sent)
+	}
+	state := m.GetAgentStates()["pane-1"]
+	if state.ContextResetting || state.LastRestart.IsZero() {
+		t.Errorf("state after failed restart reset = %+v", state)
+	}
+}
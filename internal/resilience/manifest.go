@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // SpawnManifest represents the configuration of a spawned session for monitoring
@@ -50,20 +52,48 @@ func LogDir() string {
 	return filepath.Join(dataDir, "ntm", "logs")
 }
 
-// SaveManifest saves the spawn manifest for a session
+// SaveManifest saves the spawn manifest for a session, replacing any
+// earlier one. It is written under the manifest's file lock, like
+// UpdateManifest.
 func SaveManifest(manifest *SpawnManifest) error {
+	return UpdateManifest(manifest.Session, func(m *SpawnManifest) error {
+		*m = *manifest
+		return nil
+	})
+}
+
+// UpdateManifest applies fn to the session's manifest and saves it. The
+// manifest is re-read and written under its file lock, so concurrent ntm
+// processes updating it do not lose each other's changes. fn gets an empty
+// manifest for the session if none is saved yet.
+func UpdateManifest(session string, fn func(*SpawnManifest) error) error {
 	dir := ManifestDir()
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("creating manifest directory: %w", err)
 	}
 
-	path := filepath.Join(dir, manifest.Session+".json")
-	data, err := json.MarshalIndent(manifest, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling manifest: %w", err)
-	}
+	path := filepath.Join(dir, session+".json")
+	return util.WithFileLock(path, func() error {
+		manifest := &SpawnManifest{Session: session}
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return fmt.Errorf("unmarshaling manifest: %w", err)
+			}
+		case !os.IsNotExist(err):
+			return fmt.Errorf("reading manifest: %w", err)
+		}
 
-	return os.WriteFile(path, data, 0644)
+		if err := fn(manifest); err != nil {
+			return err
+		}
+		data, err = json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling manifest: %w", err)
+		}
+		return util.AtomicWriteFile(path, data, 0644)
+	})
 }
 
 // LoadManifest loads the spawn manifest for a session
@@ -85,5 +115,6 @@ func LoadManifest(session string) (*SpawnManifest, error) {
 // DeleteManifest removes the manifest for a session
 func DeleteManifest(session string) error {
 	path := filepath.Join(ManifestDir(), session+".json")
+	_ = os.Remove(path + ".lock")
 	return os.Remove(path)
 }
@@ -1,9 +1,11 @@
 package resilience
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -152,3 +154,34 @@ func TestSaveManifest_EmptyAgents(t *testing.T) {
 		t.Errorf("Agents count = %d, want 0", len(loaded.Agents))
 	}
 }
+
+func TestUpdateManifest_ConcurrentUpdatesKeepEveryChange(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	if err := SaveManifest(&SpawnManifest{Session: "s", ProjectDir: "/p"}); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := UpdateManifest("s", func(m *SpawnManifest) error {
+				m.Agents = append(m.Agents, AgentConfig{PaneID: fmt.Sprintf("%%%d", i), PaneIndex: i})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	m, err := LoadManifest("s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Agents) != 8 || m.ProjectDir != "/p" {
+		t.Errorf("manifest = %+v, want all 8 agents and the project dir kept", m)
+	}
+}
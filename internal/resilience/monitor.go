@@ -10,12 +10,17 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/crashbundle"
 	"github.com/Dicklesworthstone/ntm/internal/events"
 	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/history"
 	"github.com/Dicklesworthstone/ntm/internal/notify"
 	"github.com/Dicklesworthstone/ntm/internal/process"
 	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
 )
 
 // Overridable hooks for tests.
@@ -27,7 +32,14 @@ var (
 	sleepFn          = time.Sleep
 	checkSessionFn   = health.CheckSession
 	displayMessageFn = tmux.DisplayMessage
+	paneOptionFn     = tmux.GetPaneOption
 	isChildAliveFn   = process.IsChildAlive
+	respawnPaneFn    = tmux.RespawnPane
+	contextResetFn   = runCompactionAssistant
+	crashBundleFn    = crashbundle.Collect
+	promptsFn        = history.ReadForSession
+	sendPromptFn     = tmux.SendKeysForAgent
+	resumeJitterFn   = resumeJitter
 )
 
 // AgentState tracks the state of an individual agent for restart purposes
@@ -47,6 +59,17 @@ type AgentState struct {
 	RateLimited         bool      // Currently rate limited
 	LastRateLimitTime   time.Time // When rate limit was last detected
 	WaitSeconds         int       // Suggested wait time from rate limit message
+	Account             string    // Pool account the pane was spawned with (see tmux.PaneAccountOption)
+	ContextResetting    bool      // Compaction assistant is running on this pane
+	LastContextReset    time.Time // When the compaction assistant last finished
+	Parked              bool      // Waiting for its rate-limit cooldown to end to be resumed
+	PendingPrompt       string    // Prompt re-sent when a parked agent is resumed
+	ResumeAt            time.Time // When a parked agent whose cooldown ended is resumed (after jitter)
+	LastResume          time.Time // When the agent was last resumed
+	ResumeCount         int       // Resumes after rate limits
+
+	accountLoaded bool
+	lastUsage     *tokens.TokenUsage // Last usage record read from the status line
 }
 
 // Monitor watches agent health and handles auto-restart
@@ -57,6 +80,9 @@ type Monitor struct {
 	notifier         *notify.Notifier
 	rateLimitTracker *ratelimit.RateLimitTracker
 	codexThrottle    *ratelimit.CodexThrottle // AIMD throttle for cod launches (bd-3qoly)
+	costs            *cost.CostTracker        // Token usage reported by the agents
+	unsavedUsage     bool                     // costs has usage not yet saved
+	contexts         *ctxmon.ContextMonitor   // Context usage reported by the agents
 
 	autoRestart bool // Whether to automatically restart crashed agents
 
@@ -88,6 +114,8 @@ func NewMonitor(session, projectDir string, cfg *config.Config, autoRestart bool
 		cfg:              cfg,
 		notifier:         notifier,
 		rateLimitTracker: tracker,
+		costs:            cost.NewCostTracker(projectDir),
+		contexts:         ctxmon.NewContextMonitor(ctxmon.DefaultMonitorConfig()),
 		autoRestart:      autoRestart,
 		agents:           make(map[string]*AgentState),
 		done:             make(chan struct{}),
@@ -235,6 +263,27 @@ func (m *Monitor) Stop() {
 	m.wg.Wait()
 }
 
+// FlushState persists the rate-limit history and Codex throttle so cooldowns
+// survive a restart, and the token usage recorded since the last save.
+// Call it after Stop so no health check is still recording.
+func (m *Monitor) FlushState() error {
+	if err := m.costs.SaveToDir(m.projectDir); err != nil {
+		return fmt.Errorf("saving token usage: %w", err)
+	}
+	if m.codexThrottle != nil {
+		if err := m.codexThrottle.SaveToDir(m.projectDir); err != nil {
+			return fmt.Errorf("saving codex throttle: %w", err)
+		}
+	}
+	if m.rateLimitTracker == nil {
+		return nil
+	}
+	if err := m.rateLimitTracker.SaveToDir(m.projectDir); err != nil {
+		return fmt.Errorf("saving rate limit history: %w", err)
+	}
+	return nil
+}
+
 // GetRestartCount returns the number of restarts for an agent
 func (m *Monitor) GetRestartCount(paneID string) int {
 	m.mu.RLock()
@@ -317,24 +366,49 @@ func (m *Monitor) checkHealth(ctx context.Context) {
 			continue
 		}
 
+		m.recordUsage(agentState, agentHealth.Usage)
+
+		// A human has taken the pane over: no rate-limit handling,
+		// compaction, or restarts until it is handed back.
+		if m.takenOver(paneID) {
+			continue
+		}
+
 		// Check for rate limit (separate from crash handling)
 		if m.cfg.Resilience.RateLimit.Detect && agentHealth.RateLimited {
-			// Only notify if this is a new rate limit event (not already rate limited)
-			if !agentState.RateLimited {
+			// Only notify if this is a new rate limit event (not already
+			// rate limited, or hit again after a resume)
+			if !agentState.RateLimited || rateLimitedAgain(agentState, time.Now()) {
 				m.handleRateLimit(agentState, agentHealth.WaitSeconds)
 			}
 		} else if agentState.RateLimited {
 			// Rate limit cleared
-			m.recordRateLimitSuccess(agentState.AgentType)
+			m.recordRateLimitSuccess(agentState.AgentType, agentState.Account)
 			agentState.RateLimited = false
 			agentState.WaitSeconds = 0
 			log.Printf("[resilience] Agent %s rate limit cleared", agentState.PaneID)
 			// Notify Codex throttle of success (bd-3qoly)
 			if agentState.AgentType == "cod" && m.codexThrottle != nil {
 				m.codexThrottle.RecordSuccess()
+				m.codexThrottle.ClearAffectedPane(agentState.PaneID)
+				m.saveCodexThrottle()
 			}
 		}
 
+		// Resume a pane parked by a rate limit once its cooldown ends.
+		if m.maybeResume(agentState, agentHealth) {
+			continue
+		}
+
+		// A pane being compacted or restarted by the compaction assistant
+		// is expected to look odd; leave it alone until the reset finishes.
+		if agentState.ContextResetting {
+			continue
+		}
+		if m.maybeResetContext(ctx, agentState, agentHealth) {
+			continue
+		}
+
 		// Check for error status or process exit
 		if agentHealth.Status == health.StatusError ||
 			agentHealth.ProcessStatus == health.ProcessExited {
@@ -405,6 +479,14 @@ func (m *Monitor) checkHealth(ctx context.Context) {
 			agentState.Healthy = true
 		}
 	}
+
+	if m.unsavedUsage {
+		if err := m.costs.SaveToDir(m.projectDir); err != nil {
+			log.Printf("[resilience] Warning: failed to save token usage: %v", err)
+		} else {
+			m.unsavedUsage = false
+		}
+	}
 }
 
 // handleRateLimit processes a detected rate limit event
@@ -413,16 +495,19 @@ func (m *Monitor) handleRateLimit(agent *AgentState, waitSeconds int) {
 	agent.LastRateLimitTime = time.Now()
 	agent.WaitSeconds = waitSeconds
 
-	log.Printf("[resilience] Agent %s (pane %d, type %s) hit rate limit (wait %ds)",
-		agent.PaneID, agent.PaneIndex, agent.AgentType, waitSeconds)
+	m.loadAccount(agent)
+	log.Printf("[resilience] Agent %s (pane %d, type %s, account %q) hit rate limit (wait %ds)",
+		agent.PaneID, agent.PaneIndex, agent.AgentType, agent.Account, waitSeconds)
 
-	// Propagate to Codex throttle for cod agents (bd-3qoly)
+	// Propagate to Codex throttle for cod agents (bd-3qoly). Panes on a
+	// pool account pause only that account.
 	if agent.AgentType == "cod" && m.codexThrottle != nil {
-		m.codexThrottle.RecordRateLimit(agent.PaneID, waitSeconds)
+		m.codexThrottle.RecordAccountRateLimit(agent.PaneID, agent.Account, waitSeconds)
 		status := m.codexThrottle.Status()
 		log.Printf("[resilience] Codex throttle engaged: phase=%s, allowed=%d/%d, cooldown=%s",
 			status.Phase, status.AllowedConcurrent, status.MaxConcurrent,
 			ratelimit.FormatDelay(status.CooldownRemaining))
+		m.saveCodexThrottle()
 	}
 
 	events.DefaultEmitter().Emit(events.NewWebhookEvent(
@@ -435,10 +520,14 @@ func (m *Monitor) handleRateLimit(agent *AgentState, waitSeconds int) {
 			"project_dir":  m.projectDir,
 			"pane_index":   fmt.Sprintf("%d", agent.PaneIndex),
 			"wait_seconds": fmt.Sprintf("%d", waitSeconds),
+			"account":      agent.Account,
 		},
 	))
 
-	m.recordRateLimitHit(agent.AgentType, waitSeconds)
+	m.recordRateLimitHit(agent.AgentType, agent.Account, waitSeconds)
+	if m.cfg.Resilience.RateLimit.AutoResume {
+		m.park(agent)
+	}
 
 	// Snapshot values for async operations
 	session := m.session
@@ -467,30 +556,62 @@ func (m *Monitor) handleRateLimit(agent *AgentState, waitSeconds int) {
 	}()
 }
 
-func (m *Monitor) recordRateLimitHit(agentType string, waitSeconds int) {
+// takenOver reports whether a human has taken over the pane (see
+// internal/takeover).
+func (m *Monitor) takenOver(paneID string) bool {
+	hooksMu.RLock()
+	fn := paneOptionFn
+	hooksMu.RUnlock()
+	value, err := fn(paneID, tmux.PaneTakeoverOption)
+	return err == nil && strings.TrimSpace(value) != ""
+}
+
+// loadAccount reads the pane's pool account once.
+func (m *Monitor) loadAccount(agent *AgentState) {
+	if agent.accountLoaded {
+		return
+	}
+	agent.accountLoaded = true
+	hooksMu.RLock()
+	fn := paneOptionFn
+	hooksMu.RUnlock()
+	if account, err := fn(agent.PaneID, tmux.PaneAccountOption); err == nil {
+		agent.Account = account
+	}
+}
+
+// recordRateLimitHit records a rate limit against the agent's provider, or
+// only against its pool account when it has one.
+func (m *Monitor) recordRateLimitHit(agentType, account string, waitSeconds int) {
 	tracker := m.ensureRateLimitTracker()
 	if tracker == nil {
 		return
 	}
-	provider := ratelimit.NormalizeProvider(agentType)
-	tracker.RecordRateLimitWithCooldown(provider, "send", waitSeconds)
+	tracker.RecordRateLimitWithCooldown(ratelimit.AccountKey(agentType, account), "send", waitSeconds)
 	if err := tracker.SaveToDir(m.projectDir); err != nil {
 		log.Printf("[resilience] Warning: failed to persist rate limit history: %v", err)
 	}
 }
 
-func (m *Monitor) recordRateLimitSuccess(agentType string) {
+func (m *Monitor) recordRateLimitSuccess(agentType, account string) {
 	tracker := m.ensureRateLimitTracker()
 	if tracker == nil {
 		return
 	}
-	provider := ratelimit.NormalizeProvider(agentType)
-	tracker.RecordSuccess(provider)
+	tracker.RecordSuccess(ratelimit.AccountKey(agentType, account))
 	if err := tracker.SaveToDir(m.projectDir); err != nil {
 		log.Printf("[resilience] Warning: failed to persist rate limit history: %v", err)
 	}
 }
 
+// saveCodexThrottle persists the throttle so 'ntm robot ratelimit' can
+// report it from another process.
+func (m *Monitor) saveCodexThrottle() {
+	if err := m.codexThrottle.SaveToDir(m.projectDir); err != nil {
+		log.Printf("[resilience] Warning: failed to persist codex throttle: %v", err)
+	}
+}
+
 func (m *Monitor) ensureRateLimitTracker() *ratelimit.RateLimitTracker {
 	if m.rateLimitTracker != nil {
 		return m.rateLimitTracker
@@ -585,6 +706,8 @@ func (m *Monitor) handleCrash(ctx context.Context, agent *AgentState, reason str
 		},
 	))
 
+	m.collectCrashBundle(ctx, agent, reason)
+
 	// Snapshot values for async operations
 	session := m.session
 	paneID := agent.PaneID
@@ -665,6 +788,54 @@ func (m *Monitor) handleCrash(ctx context.Context, agent *AgentState, reason str
 	}
 }
 
+// collectCrashBundle saves a forensic bundle for a crashed agent in the
+// background. Called with m.mu held, so agent state is snapshotted here.
+func (m *Monitor) collectCrashBundle(ctx context.Context, agent *AgentState, reason string) {
+	rc := m.cfg.Resilience
+	if !rc.CrashBundles || m.projectDir == "" {
+		return
+	}
+
+	opts := crashbundle.Options{
+		ProjectDir:   m.projectDir,
+		Session:      m.session,
+		PaneID:       agent.PaneID,
+		PaneIndex:    agent.PaneIndex,
+		AgentType:    agent.AgentType,
+		Model:        agent.Model,
+		Reason:       reason,
+		RestartCount: agent.RestartCount,
+		CrashedAt:    agent.LastCrash,
+		RateLimits: crashbundle.RateLimits{
+			PaneRateLimited: agent.RateLimited,
+			WaitSeconds:     agent.WaitSeconds,
+			Providers:       crashbundle.ProviderRateLimits(m.rateLimitTracker),
+		},
+		Segments:  rc.CrashBundleSegments,
+		Keep:      rc.CrashBundleKeep,
+		Redaction: m.cfg.Redaction.ToRedactionLibConfig(),
+	}
+	if !agent.LastRateLimitTime.IsZero() {
+		last := agent.LastRateLimitTime
+		opts.RateLimits.LastRateLimit = &last
+	}
+
+	hooksMu.RLock()
+	collect := crashBundleFn
+	hooksMu.RUnlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		manifest, err := collect(ctx, opts)
+		if err != nil {
+			log.Printf("[resilience] crash bundle for %s failed: %v", opts.PaneID, err)
+			return
+		}
+		log.Printf("[resilience] crash bundle for %s saved to %s", opts.PaneID, manifest.Path)
+	}()
+}
+
 func (m *Monitor) suggestManualRespawn(agent *AgentState) {
 	if m.session == "" {
 		return
@@ -3,15 +3,30 @@ package resilience
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/crashbundle"
 	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
 )
 
+// TestMain keeps crashes handled by tests from writing bundles into the
+// fake project directories they use.
+func TestMain(m *testing.M) {
+	crashBundleFn = func(ctx context.Context, opts crashbundle.Options) (*crashbundle.Manifest, error) {
+		return &crashbundle.Manifest{}, nil
+	}
+	promptsFn = func(session string) ([]history.HistoryEntry, error) { return nil, nil }
+	os.Exit(m.Run())
+}
+
 // saveHooks saves all original hooks and returns a restore function.
 // Uses hooksMu to synchronize with spawned goroutines that read hooks.
 func saveHooks() func() {
@@ -21,6 +36,13 @@ func saveHooks() func() {
 	origSleep := sleepFn
 	origCheckSession := checkSessionFn
 	origDisplayMessage := displayMessageFn
+	origPaneOption := paneOptionFn
+	origRespawnPane := respawnPaneFn
+	origContextReset := contextResetFn
+	origCrashBundle := crashBundleFn
+	origPrompts := promptsFn
+	origSendPrompt := sendPromptFn
+	origResumeJitter := resumeJitterFn
 	hooksMu.Unlock()
 
 	return func() {
@@ -30,6 +52,13 @@ func saveHooks() func() {
 		sleepFn = origSleep
 		checkSessionFn = origCheckSession
 		displayMessageFn = origDisplayMessage
+		paneOptionFn = origPaneOption
+		respawnPaneFn = origRespawnPane
+		contextResetFn = origContextReset
+		crashBundleFn = origCrashBundle
+		promptsFn = origPrompts
+		sendPromptFn = origSendPrompt
+		resumeJitterFn = origResumeJitter
 		hooksMu.Unlock()
 	}
 }
@@ -42,6 +71,15 @@ func setHooksLocked(fn func()) {
 	fn()
 }
 
+// testConfig returns the default config with the human inbox in a temp dir,
+// so notifications sent by a test do not land in the package directory.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Notifications.FileBox.Path = t.TempDir()
+	return cfg
+}
+
 func TestRestartAgentUsesBuiltPaneCommandAndSendKeys(t *testing.T) {
 	restore := saveHooks()
 	defer restore()
@@ -71,7 +109,7 @@ func TestRestartAgentUsesBuiltPaneCommandAndSendKeys(t *testing.T) {
 		sleepFn = func(d time.Duration) {} // no-op for speed
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.AutoRestart = true
 	cfg.Resilience.RestartDelaySeconds = 0
 
@@ -97,7 +135,7 @@ func TestRestartAgentUsesBuiltPaneCommandAndSendKeys(t *testing.T) {
 }
 
 func TestRegisterAgent(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude --model opus")
@@ -135,7 +173,7 @@ func TestRegisterAgent(t *testing.T) {
 }
 
 func TestGetRestartCount(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 
 	// Non-existent agent should return 0
@@ -161,7 +199,7 @@ func TestGetRestartCount(t *testing.T) {
 }
 
 func TestGetAgentStatesReturnsCopy(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
@@ -181,7 +219,7 @@ func TestStartAndStop(t *testing.T) {
 	restore := saveHooks()
 	defer restore()
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.HealthCheckSeconds = 1 // Fast for testing
 
 	// Mock checkSessionFn to avoid actual tmux calls
@@ -218,7 +256,7 @@ func TestStartAndStop(t *testing.T) {
 }
 
 func TestStopWithoutStart(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 
 	// Should not panic or hang
@@ -255,7 +293,7 @@ func TestCheckHealthWithHealthyAgent(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
@@ -303,7 +341,7 @@ func TestCheckHealthDetectsCrash(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.AutoRestart = true
 	cfg.Resilience.MaxRestarts = 3
 	cfg.Resilience.RestartDelaySeconds = 0
@@ -328,6 +366,51 @@ func TestCheckHealthDetectsCrash(t *testing.T) {
 	}
 }
 
+func TestCheckHealthSkipsTakenOverPane(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	var sent int
+	setHooksLocked(func() {
+		checkSessionFn = func(ctx context.Context, session string) (*health.SessionHealth, error) {
+			return &health.SessionHealth{
+				Session: session,
+				Agents: []health.AgentHealth{{
+					PaneID:        "pane-1",
+					Status:        health.StatusError,
+					ProcessStatus: health.ProcessExited,
+					Issues:        []health.Issue{{Type: "crash", Message: "Process exited"}},
+				}},
+			}, nil
+		}
+		paneOptionFn = func(paneID, name string) (string, error) {
+			if name == tmux.PaneTakeoverOption {
+				return "2026-10-15T10:00:00Z|alice", nil
+			}
+			return "", nil
+		}
+		sleepFn = func(d time.Duration) {}
+		sendKeysFn = func(paneID, cmd string, enter bool) error { sent++; return nil }
+	})
+
+	cfg := testConfig(t)
+	cfg.Resilience.AutoRestart = true
+	cfg.Resilience.CrashThreshold = 1
+	m := NewMonitor("test-session", "/tmp/project", cfg, true)
+	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
+
+	m.checkHealth(context.Background())
+	time.Sleep(50 * time.Millisecond)
+
+	m.mu.RLock()
+	agent := m.agents["pane-1"]
+	healthy, restarts := agent.Healthy, agent.RestartCount
+	m.mu.RUnlock()
+	if !healthy || restarts != 0 || sent != 0 {
+		t.Errorf("taken-over pane was handled: healthy=%v restarts=%d sends=%d", healthy, restarts, sent)
+	}
+}
+
 func TestCheckHealthDetectsPaneMissing(t *testing.T) {
 	restore := saveHooks()
 	defer restore()
@@ -348,7 +431,7 @@ func TestCheckHealthDetectsPaneMissing(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.AutoRestart = true
 	cfg.Resilience.MaxRestarts = 3
 	cfg.Resilience.RestartDelaySeconds = 0
@@ -396,7 +479,7 @@ func TestCheckHealthDetectsRateLimit(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
@@ -440,7 +523,7 @@ func TestCheckHealthRateLimitUpdatesTracker(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	projectDir := t.TempDir()
 	m := NewMonitor("test-session", projectDir, cfg, true)
@@ -480,7 +563,7 @@ func TestCheckHealthRateLimitCleared(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
@@ -525,7 +608,7 @@ func TestCheckHealthRateLimitClearedRecordsSuccess(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	projectDir := t.TempDir()
 	m := NewMonitor("test-session", projectDir, cfg, true)
@@ -556,7 +639,7 @@ func TestCheckHealthError(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
@@ -589,7 +672,7 @@ func TestHandleCrashMaxRestartsExceeded(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.MaxRestarts = 3
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
@@ -600,9 +683,7 @@ func TestHandleCrashMaxRestartsExceeded(t *testing.T) {
 	m.mu.Unlock()
 
 	m.handleCrash(context.Background(), m.agents["pane-1"], "test crash")
-
-	// Give time for any goroutine
-	time.Sleep(50 * time.Millisecond)
+	m.wg.Wait()
 
 	if restartAttempted {
 		t.Error("should not restart when max restarts exceeded")
@@ -622,13 +703,14 @@ func TestHandleCrashSuggestsManualRespawnWhenAutoRestartDisabled(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.AutoRestart = false
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, false)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
 
 	m.handleCrash(context.Background(), m.agents["pane-1"], "test crash")
+	m.wg.Wait()
 
 	if capturedSession != "test-session" {
 		t.Fatalf("expected session 'test-session', got %s", capturedSession)
@@ -638,6 +720,55 @@ func TestHandleCrashSuggestsManualRespawnWhenAutoRestartDisabled(t *testing.T) {
 	}
 }
 
+func TestHandleCrashCollectsBundle(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	got := make(chan crashbundle.Options, 1)
+	setHooksLocked(func() {
+		displayMessageFn = func(session, msg string, durationMs int) error { return nil }
+		crashBundleFn = func(ctx context.Context, opts crashbundle.Options) (*crashbundle.Manifest, error) {
+			got <- opts
+			return &crashbundle.Manifest{}, nil
+		}
+	})
+
+	cfg := testConfig(t)
+	m := NewMonitor("test-session", "/tmp/project", cfg, false)
+	m.RegisterAgent("pane-1", 2, 0, "cod", "gpt-5", "codex")
+	m.mu.Lock()
+	m.agents["pane-1"].RateLimited = true
+	m.agents["pane-1"].WaitSeconds = 30
+	m.handleCrash(context.Background(), m.agents["pane-1"], "exit status 1")
+	m.mu.Unlock()
+	m.wg.Wait()
+
+	select {
+	case opts := <-got:
+		if opts.ProjectDir != "/tmp/project" || opts.PaneIndex != 2 || opts.AgentType != "cod" || opts.Reason != "exit status 1" {
+			t.Errorf("options = %+v", opts)
+		}
+		if !opts.RateLimits.PaneRateLimited || opts.RateLimits.WaitSeconds != 30 {
+			t.Errorf("rate limits = %+v", opts.RateLimits)
+		}
+		if opts.CrashedAt.IsZero() {
+			t.Error("crash time should be set")
+		}
+	default:
+		t.Fatal("no crash bundle collected")
+	}
+
+	cfg.Resilience.CrashBundles = false
+	m.mu.Lock()
+	m.agents["pane-1"].Healthy = true
+	m.handleCrash(context.Background(), m.agents["pane-1"], "again")
+	m.mu.Unlock()
+	m.wg.Wait()
+	if len(got) != 0 {
+		t.Error("bundle collected with crash_bundles disabled")
+	}
+}
+
 func TestRestartAgentIncreasesCount(t *testing.T) {
 	restore := saveHooks()
 	defer restore()
@@ -650,7 +781,7 @@ func TestRestartAgentIncreasesCount(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RestartDelaySeconds = 0
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
@@ -690,7 +821,7 @@ func TestRestartAgentSkipsIfHealthy(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RestartDelaySeconds = 0
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
 	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
@@ -719,7 +850,7 @@ func TestRestartAgentHandlesBuildError(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RestartDelaySeconds = 0
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
@@ -750,7 +881,7 @@ func TestRestartAgentHandlesSendKeysError(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RestartDelaySeconds = 0
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
@@ -788,7 +919,7 @@ func TestMonitorLoopRespectsMinCheckInterval(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.HealthCheckSeconds = 0 // Should become 10 seconds minimum
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
@@ -813,7 +944,7 @@ func TestMonitorLoopRespectsMinCheckInterval(t *testing.T) {
 }
 
 func TestNewMonitorWithNotifications(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Notifications.Enabled = true
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
@@ -824,7 +955,7 @@ func TestNewMonitorWithNotifications(t *testing.T) {
 }
 
 func TestNewMonitorWithoutNotifications(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Notifications.Enabled = false
 
 	m := NewMonitor("test-session", "/tmp/project", cfg, true)
@@ -891,7 +1022,7 @@ func TestHandleRateLimitTriggersRotationAssistance(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	cfg.Resilience.RateLimit.Notify = false // Disable to avoid notification errors
 	cfg.Rotation.Enabled = true
@@ -926,7 +1057,7 @@ func TestTriggerRotationAssistanceWithNotifier(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Notifications.Enabled = true
 	cfg.Rotation.AutoInitiate = true // Test this branch even though it's a no-op
 
@@ -951,7 +1082,7 @@ func TestTriggerRotationAssistanceEmptySession(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	m := NewMonitor("", "/tmp/project", cfg, true)
 
 	// With empty session, should not call displayTmuxMessage
@@ -963,7 +1094,7 @@ func TestTriggerRotationAssistanceEmptySession(t *testing.T) {
 }
 
 func TestEnsureRateLimitTracker_LazyInit(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	projectDir := t.TempDir()
 
@@ -988,7 +1119,7 @@ func TestEnsureRateLimitTracker_LazyInit(t *testing.T) {
 }
 
 func TestEnsureRateLimitTracker_DisabledReturnsNil(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = false
 	m := NewMonitor("test-session", t.TempDir(), cfg, true)
 	m.rateLimitTracker = nil
@@ -1000,12 +1131,12 @@ func TestEnsureRateLimitTracker_DisabledReturnsNil(t *testing.T) {
 }
 
 func TestRecordRateLimitHit_Direct(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	projectDir := t.TempDir()
 	m := NewMonitor("test-session", projectDir, cfg, true)
 
-	m.recordRateLimitHit("cod", 30)
+	m.recordRateLimitHit("cod", "", 30)
 
 	state := m.rateLimitTracker.GetProviderState("openai")
 	if state == nil {
@@ -1016,22 +1147,59 @@ func TestRecordRateLimitHit_Direct(t *testing.T) {
 	}
 }
 
+func TestHandleRateLimit_PoolAccount(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+	setHooksLocked(func() {
+		paneOptionFn = func(paneID, name string) (string, error) {
+			if paneID == "pane-1" && name == tmux.PaneAccountOption {
+				return "work", nil
+			}
+			return "", nil
+		}
+	})
+
+	cfg := testConfig(t)
+	cfg.Resilience.RateLimit.Detect = true
+	cfg.Resilience.RateLimit.Notify = false
+	m := NewMonitor("test-session", t.TempDir(), cfg, true)
+	throttle := ratelimit.NewCodexThrottle(3)
+	m.SetCodexThrottle(throttle)
+	m.RegisterAgent("pane-1", 1, 0, "cod", "", "codex")
+
+	m.handleRateLimit(m.agents["pane-1"], 60)
+
+	if got := m.agents["pane-1"].Account; got != "work" {
+		t.Fatalf("account = %q, want work", got)
+	}
+	if m.rateLimitTracker.CooldownRemaining(ratelimit.AccountKey("cod", "work")) == 0 {
+		t.Error("pool account should be cooling down")
+	}
+	if m.rateLimitTracker.CooldownRemaining(ratelimit.AccountKey("cod", "personal")) != 0 ||
+		m.rateLimitTracker.CooldownRemaining("openai") != 0 {
+		t.Error("rate limit leaked beyond the exhausted account")
+	}
+	if !throttle.MayLaunch(0) || throttle.AccountCooldownRemaining("work") == 0 {
+		t.Errorf("throttle status = %+v", throttle.Status())
+	}
+}
+
 func TestRecordRateLimitHit_DisabledIsNoOp(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = false
 	m := NewMonitor("test-session", t.TempDir(), cfg, true)
 
 	// Should not panic
-	m.recordRateLimitHit("cc", 60)
+	m.recordRateLimitHit("cc", "", 60)
 }
 
 func TestRecordRateLimitSuccess_Direct(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = true
 	projectDir := t.TempDir()
 	m := NewMonitor("test-session", projectDir, cfg, true)
 
-	m.recordRateLimitSuccess("cod")
+	m.recordRateLimitSuccess("cod", "")
 
 	state := m.rateLimitTracker.GetProviderState("openai")
 	if state == nil {
@@ -1043,16 +1211,16 @@ func TestRecordRateLimitSuccess_Direct(t *testing.T) {
 }
 
 func TestRecordRateLimitSuccess_DisabledIsNoOp(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.RateLimit.Detect = false
 	m := NewMonitor("test-session", t.TempDir(), cfg, true)
 
 	// Should not panic
-	m.recordRateLimitSuccess("cc")
+	m.recordRateLimitSuccess("cc", "")
 }
 
 func TestMonitorStart_NilContextAndDoubleStartAreSafe(t *testing.T) {
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.AutoRestart = false
 
 	m := NewMonitor("test-session", t.TempDir(), cfg, false)
@@ -1112,7 +1280,7 @@ func TestCheckHealthIsWorkingGuardSkipsCrash(t *testing.T) {
 		}
 	})
 
-	cfg := config.Default()
+	cfg := testConfig(t)
 	cfg.Resilience.AutoRestart = true
 	cfg.Resilience.MaxRestarts = 3
 	cfg.Resilience.RestartDelaySeconds = 0
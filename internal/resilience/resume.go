@@ -0,0 +1,208 @@
+package resilience
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// resumeGrace is how long after a resume a rate-limit message still on the
+// pane is taken to be the old one. A message seen after it is a new hit.
+const resumeGrace = time.Minute
+
+// resumeJitter returns a random delay in [0, max).
+func resumeJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// park marks a rate-limited agent for resumption and remembers the last
+// prompt sent to it. Caller must hold m.mu.
+func (m *Monitor) park(agent *AgentState) {
+	agent.Parked = true
+	agent.ResumeAt = time.Time{}
+	agent.PendingPrompt = m.pendingPrompt(agent)
+	if agent.PendingPrompt == "" {
+		log.Printf("[resilience] Agent %s parked, but no prompt to it was found in history; it will not be resumed", agent.PaneID)
+		agent.Parked = false
+	}
+}
+
+// pendingPrompt returns the last prompt successfully sent to the agent's
+// pane, or "".
+func (m *Monitor) pendingPrompt(agent *AgentState) string {
+	hooksMu.RLock()
+	fn := promptsFn
+	hooksMu.RUnlock()
+
+	entries, err := fn(m.session)
+	if err != nil {
+		log.Printf("[resilience] Warning: reading prompt history: %v", err)
+		return ""
+	}
+	pane := strconv.Itoa(agent.PaneIndex)
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !e.Success || e.Prompt == "" {
+			continue
+		}
+		if len(e.Targets) == 0 || containsString(e.Targets, pane) {
+			return e.Prompt
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// resumeWait returns how long a parked agent must still wait: the cooldown
+// of its provider or account, and for cod panes the Codex throttle.
+// Caller must hold m.mu.
+func (m *Monitor) resumeWait(agent *AgentState) time.Duration {
+	var wait time.Duration
+	if tracker := m.ensureRateLimitTracker(); tracker != nil {
+		wait = tracker.CooldownRemaining(ratelimit.AccountKey(agent.AgentType, agent.Account))
+	}
+	if agent.AgentType != "cod" || m.codexThrottle == nil {
+		return wait
+	}
+	if agent.Account != "" {
+		if w := m.codexThrottle.AccountCooldownRemaining(agent.Account); w > wait {
+			wait = w
+		}
+	}
+	if !m.codexThrottle.MayLaunch(m.runningCodexLocked()) {
+		w := m.codexThrottle.Status().CooldownRemaining
+		if w <= 0 {
+			// Recovering: wait for the AIMD window to open.
+			w = ratelimit.RecoveryCheckInterval
+		}
+		if w > wait {
+			wait = w
+		}
+	}
+	return wait
+}
+
+// runningCodexLocked counts the cod agents that are not held up by a rate
+// limit. Caller must hold m.mu.
+func (m *Monitor) runningCodexLocked() int {
+	n := 0
+	for _, a := range m.agents {
+		if a.AgentType == "cod" && !a.Parked && !a.RateLimited {
+			n++
+		}
+	}
+	return n
+}
+
+// maybeResume re-sends a parked agent's pending prompt once its cooldown
+// has ended and its jitter delay has passed. It reports whether the agent
+// was resumed. Caller must hold m.mu.
+func (m *Monitor) maybeResume(agent *AgentState, agentHealth *health.AgentHealth) bool {
+	if !agent.Parked {
+		return false
+	}
+	// Someone (or the agent's own retry) already got it going again.
+	if agentHealth.Activity == health.ActivityActive {
+		log.Printf("[resilience] Agent %s is working again; no resume needed", agent.PaneID)
+		agent.Parked = false
+		agent.PendingPrompt = ""
+		agent.ResumeAt = time.Time{}
+		return false
+	}
+	if wait := m.resumeWait(agent); wait > 0 {
+		agent.ResumeAt = time.Time{}
+		return false
+	}
+
+	now := time.Now()
+	if agent.ResumeAt.IsZero() {
+		hooksMu.RLock()
+		jitterFn := resumeJitterFn
+		hooksMu.RUnlock()
+		agent.ResumeAt = now.Add(jitterFn(time.Duration(m.cfg.Resilience.RateLimit.ResumeJitterSeconds) * time.Second))
+		log.Printf("[resilience] Agent %s cooldown over; resuming at %s", agent.PaneID, agent.ResumeAt.Format(time.TimeOnly))
+	}
+	if now.Before(agent.ResumeAt) {
+		return false
+	}
+
+	prompt := agent.PendingPrompt
+	parkedFor := now.Sub(agent.LastRateLimitTime).Truncate(time.Second)
+	agent.Parked = false
+	agent.PendingPrompt = ""
+	agent.ResumeAt = time.Time{}
+	agent.LastResume = now
+	agent.ResumeCount++
+
+	paneID := agent.PaneID
+	agentType := agent.AgentType
+	details := map[string]string{
+		"project_dir":    m.projectDir,
+		"pane_index":     fmt.Sprintf("%d", agent.PaneIndex),
+		"account":        agent.Account,
+		"parked_seconds": fmt.Sprintf("%d", int(parkedFor.Seconds())),
+		"resume_count":   fmt.Sprintf("%d", agent.ResumeCount),
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.finishResume(paneID, agentType, prompt, parkedFor, details)
+	}()
+	return true
+}
+
+// finishResume sends the prompt and emits the agent.resumed event.
+func (m *Monitor) finishResume(paneID, agentType, prompt string, parkedFor time.Duration, details map[string]string) {
+	hooksMu.RLock()
+	sendFunc := sendPromptFn
+	hooksMu.RUnlock()
+
+	var message string
+	if err := sendFunc(paneID, prompt, true, tmux.AgentType(agentType)); err != nil {
+		details["error"] = err.Error()
+		message = fmt.Sprintf("Resuming %s after rate limit failed: %v", agentType, err)
+		log.Printf("[resilience] Resume of %s failed: %v", paneID, err)
+	} else {
+		message = fmt.Sprintf("Resumed %s after %s rate-limit pause", agentType, parkedFor)
+		log.Printf("[resilience] Agent %s resumed after %s; pending prompt re-sent", paneID, parkedFor)
+	}
+
+	events.DefaultEmitter().Emit(events.NewWebhookEvent(
+		events.WebhookAgentResumed,
+		m.session,
+		paneID,
+		agentType,
+		message,
+		details,
+	))
+	if m.session != "" {
+		displayTmuxMessage(m.session, message)
+	}
+}
+
+// rateLimitedAgain reports whether a rate limit seen on a resumed agent is
+// a new hit rather than the message that parked it.
+func rateLimitedAgain(agent *AgentState, now time.Time) bool {
+	return !agent.Parked && !agent.LastResume.IsZero() &&
+		agent.LastRateLimitTime.Before(agent.LastResume) &&
+		now.Sub(agent.LastResume) >= resumeGrace
+}
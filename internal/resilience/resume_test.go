@@ -0,0 +1,169 @@
+package resilience
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+type sentPrompt struct {
+	paneID, prompt string
+	agentType      tmux.AgentType
+}
+
+// resumeMonitor returns a monitor whose prompt history holds prompts, with
+// sends recorded instead of typed and no resume jitter.
+func resumeMonitor(t *testing.T, prompts []history.HistoryEntry) (*Monitor, func() []sentPrompt) {
+	t.Helper()
+	restore := saveHooks()
+	t.Cleanup(restore)
+
+	var mu sync.Mutex
+	var sent []sentPrompt
+	setHooksLocked(func() {
+		promptsFn = func(session string) ([]history.HistoryEntry, error) { return prompts, nil }
+		sendPromptFn = func(target, keys string, enter bool, agentType tmux.AgentType) error {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, sentPrompt{target, keys, agentType})
+			return nil
+		}
+		resumeJitterFn = func(time.Duration) time.Duration { return 0 }
+		displayMessageFn = func(session, msg string, durationMs int) error { return nil }
+		paneOptionFn = func(paneID, name string) (string, error) { return "", nil }
+	})
+
+	cfg := testConfig(t)
+	cfg.Resilience.RateLimit.Notify = false
+	m := NewMonitor("test-session", t.TempDir(), cfg, false)
+	return m, func() []sentPrompt {
+		m.wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]sentPrompt(nil), sent...)
+	}
+}
+
+func TestResumeAfterCooldown(t *testing.T) {
+	m, sent := resumeMonitor(t, []history.HistoryEntry{
+		{Targets: []string{"1"}, Prompt: "fix the parser", Success: true},
+		{Targets: []string{"2"}, Prompt: "other pane", Success: true},
+		{Targets: []string{"1"}, Prompt: "failed send", Success: false},
+	})
+	m.RegisterAgent("pane-1", 1, 0, "cc", "opus", "claude")
+	agent := m.agents["pane-1"]
+	idle := &health.AgentHealth{PaneID: "pane-1", Activity: health.ActivityIdle}
+
+	m.handleRateLimit(agent, 60)
+	if !agent.Parked || agent.PendingPrompt != "fix the parser" {
+		t.Fatalf("parked %v with prompt %q", agent.Parked, agent.PendingPrompt)
+	}
+
+	if m.maybeResume(agent, idle) {
+		t.Fatal("resumed during the cooldown")
+	}
+
+	m.rateLimitTracker.ClearCooldown(ratelimit.AccountKey("cc", ""))
+	if !m.maybeResume(agent, idle) {
+		t.Fatal("not resumed after the cooldown")
+	}
+	got := sent()
+	if len(got) != 1 || got[0] != (sentPrompt{"pane-1", "fix the parser", tmux.AgentClaude}) {
+		t.Errorf("sent %+v", got)
+	}
+	if agent.Parked || agent.ResumeCount != 1 || agent.LastResume.IsZero() {
+		t.Errorf("after resume: %+v", agent)
+	}
+	if m.maybeResume(agent, idle) || len(sent()) != 1 {
+		t.Error("resumed twice")
+	}
+}
+
+func TestResumeWaitsForJitter(t *testing.T) {
+	m, sent := resumeMonitor(t, []history.HistoryEntry{{Prompt: "broadcast", Success: true}})
+	setHooksLocked(func() {
+		resumeJitterFn = func(max time.Duration) time.Duration { return max }
+	})
+	m.cfg.Resilience.RateLimit.ResumeJitterSeconds = 3600
+	m.RegisterAgent("pane-1", 1, 0, "gmi", "", "gemini")
+	agent := m.agents["pane-1"]
+
+	m.handleRateLimit(agent, 1)
+	m.rateLimitTracker.ClearCooldown(ratelimit.AccountKey("gmi", ""))
+	if m.maybeResume(agent, &health.AgentHealth{}) || len(sent()) != 0 {
+		t.Fatal("resumed before the jitter delay")
+	}
+	if until := time.Until(agent.ResumeAt); until < 59*time.Minute {
+		t.Errorf("resume in %s, want about an hour", until)
+	}
+}
+
+func TestResumeSkippedWhenAgentWorksAgain(t *testing.T) {
+	m, sent := resumeMonitor(t, []history.HistoryEntry{{Targets: []string{"1"}, Prompt: "go", Success: true}})
+	m.RegisterAgent("pane-1", 1, 0, "cc", "", "claude")
+	agent := m.agents["pane-1"]
+
+	m.handleRateLimit(agent, 60)
+	if m.maybeResume(agent, &health.AgentHealth{Activity: health.ActivityActive}) {
+		t.Error("resumed a working agent")
+	}
+	if agent.Parked || len(sent()) != 0 {
+		t.Errorf("parked %v, sent %v", agent.Parked, sent())
+	}
+}
+
+func TestParkWithoutPromptHistory(t *testing.T) {
+	m, _ := resumeMonitor(t, nil)
+	m.RegisterAgent("pane-1", 1, 0, "cc", "", "claude")
+	m.handleRateLimit(m.agents["pane-1"], 60)
+	if m.agents["pane-1"].Parked {
+		t.Error("parked a pane with nothing to re-send")
+	}
+}
+
+func TestResumeDisabled(t *testing.T) {
+	m, _ := resumeMonitor(t, []history.HistoryEntry{{Prompt: "go", Success: true}})
+	m.cfg.Resilience.RateLimit.AutoResume = false
+	m.RegisterAgent("pane-1", 1, 0, "cc", "", "claude")
+	m.handleRateLimit(m.agents["pane-1"], 60)
+	if m.agents["pane-1"].Parked {
+		t.Error("parked with auto_resume off")
+	}
+}
+
+func TestResumeWaitsForCodexThrottle(t *testing.T) {
+	m, _ := resumeMonitor(t, []history.HistoryEntry{{Prompt: "go", Success: true}})
+	throttle := ratelimit.NewCodexThrottle(2)
+	m.SetCodexThrottle(throttle)
+	m.RegisterAgent("pane-1", 1, 0, "cod", "", "codex")
+	agent := m.agents["pane-1"]
+
+	m.handleRateLimit(agent, 60)
+	m.rateLimitTracker.ClearCooldown(ratelimit.AccountKey("cod", ""))
+	if wait := m.resumeWait(agent); wait <= 0 {
+		t.Errorf("resume wait = %s while the throttle is paused", wait)
+	}
+	throttle.Reset()
+	if wait := m.resumeWait(agent); wait != 0 {
+		t.Errorf("resume wait = %s after the throttle reset", wait)
+	}
+}
+
+func TestRateLimitedAgain(t *testing.T) {
+	now := time.Now()
+	resumed := &AgentState{LastRateLimitTime: now.Add(-10 * time.Minute), LastResume: now.Add(-2 * resumeGrace)}
+	if !rateLimitedAgain(resumed, now) {
+		t.Error("rate limit after the grace period is not a new hit")
+	}
+	if rateLimitedAgain(resumed, resumed.LastResume.Add(resumeGrace/2)) {
+		t.Error("rate limit within the grace period is a new hit")
+	}
+	if rateLimitedAgain(&AgentState{LastRateLimitTime: now}, now) {
+		t.Error("never-resumed agent is hit again")
+	}
+}
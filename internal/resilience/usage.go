@@ -0,0 +1,38 @@
+package resilience
+
+import (
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+)
+
+// recordUsage feeds the usage an agent printed in its status line to the
+// cost tracker and the context monitor. The CLIs print running totals, so
+// only the tokens used since the previous health check are recorded; the
+// first record is the baseline, as an earlier monitor may already have
+// counted what came before it. Caller must hold m.mu.
+func (m *Monitor) recordUsage(agent *AgentState, usage *tokens.TokenUsage) {
+	if usage == nil {
+		return
+	}
+	prev := agent.lastUsage
+	agent.lastUsage = usage
+	if delta := usage.Since(prev); prev != nil && delta.HasTokenCounts() {
+		m.costs.RecordUsage(m.session, agent.PaneID, agent.Model, delta)
+		m.unsavedUsage = true
+	}
+
+	m.contexts.RegisterAgent(agent.PaneID, agent.PaneID, agent.Model)
+	m.contexts.SetAgentType(agent.PaneID, agent.AgentType)
+	m.contexts.UpdateFromUsage(agent.PaneID, usage)
+}
+
+// contextUsage returns the agent's context usage in percent: the context
+// monitor's estimate if the agent reported one recently, otherwise the
+// figure from the health check.
+func (m *Monitor) contextUsage(agent *AgentState, agentHealth *health.AgentHealth) float64 {
+	if est := m.contexts.GetEstimate(agent.PaneID); est != nil && est.Method == ctxmon.MethodProviderReport {
+		return est.UsagePercent
+	}
+	return agentHealth.ContextUsage
+}
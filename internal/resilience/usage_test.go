@@ -0,0 +1,68 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ctxmon "github.com/Dicklesworthstone/ntm/internal/context"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/health"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+)
+
+func TestCheckHealthRecordsUsageDeltas(t *testing.T) {
+	restore := saveHooks()
+	defer restore()
+
+	sample := func(input, output int64, contextPct float64) {
+		setHooksLocked(func() {
+			stubSessionHealth(health.AgentHealth{
+				PaneID:       "pane-1",
+				Status:       health.StatusOK,
+				Activity:     health.ActivityIdle,
+				ContextUsage: contextPct,
+				Usage: &tokens.TokenUsage{
+					Source:         tokens.UsageSourceClaude,
+					InputTokens:    input,
+					OutputTokens:   output,
+					ContextPercent: contextPct,
+					CapturedAt:     time.Now(),
+				},
+			})
+		})
+	}
+
+	projectDir := t.TempDir()
+	m := NewMonitor("proj", projectDir, testConfig(t), true)
+	m.RegisterAgent("pane-1", 1, 0, "cc", "sonnet", "claude")
+
+	// The first sample is the baseline; later ones add what changed.
+	sample(10000, 2000, 20)
+	m.checkHealth(context.Background())
+	sample(12500, 2300, 25)
+	m.checkHealth(context.Background())
+	sample(12500, 2300, 25)
+	m.checkHealth(context.Background())
+
+	got := m.costs.GetSession("proj")
+	if got == nil || got.Agents["pane-1"] == nil {
+		t.Fatalf("no usage recorded for pane-1: %+v", got)
+	}
+	if a := got.Agents["pane-1"]; a.InputTokens != 2500 || a.OutputTokens != 300 {
+		t.Errorf("recorded %d in / %d out, want 2500 / 300", a.InputTokens, a.OutputTokens)
+	}
+
+	est := m.contexts.GetEstimate("pane-1")
+	if est == nil || est.Method != ctxmon.MethodProviderReport || est.UsagePercent != 25 {
+		t.Errorf("context estimate = %+v, want the reported 25%%", est)
+	}
+
+	saved := cost.NewCostTracker(projectDir)
+	if err := saved.LoadFromDir(projectDir); err != nil {
+		t.Fatalf("LoadFromDir: %v", err)
+	}
+	if s := saved.GetSession("proj"); s == nil || s.Agents["pane-1"] == nil || s.Agents["pane-1"].InputTokens != 2500 {
+		t.Errorf("saved usage = %+v, want 2500 input tokens for pane-1", s)
+	}
+}
@@ -213,7 +213,7 @@ func GetAck(opts AckOptions) (*AckOutput, error) {
 				output.Confirmations = append(output.Confirmations, AckConfirmation{
 					Pane:      paneKey,
 					AckType:   string(ackType),
-					AckAt:     time.Now().UTC().Format(time.RFC3339),
+					AckAt:     FormatTimestamp(time.Now()),
 					LatencyMs: int(latency.Milliseconds()),
 				})
 			} else {
@@ -453,7 +453,7 @@ func GetSendAndAck(opts SendAndAckOptions) (*SendAndAckOutput, error) {
 		if parts := formatRedactionCategoryCounts(redactionSummary.Categories); parts != "" {
 			errMsg = fmt.Sprintf("refusing to proceed: potential secrets detected (%s) (redaction mode: block)", parts)
 		}
-		errResp := NewErrorResponse(fmt.Errorf("%s", errMsg), "SENSITIVE_DATA_BLOCKED", "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact")
+		errResp := NewErrorResponse(fmt.Errorf("%s", errMsg), ErrCodeSensitiveDataBlocked, "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact")
 		return &SendAndAckOutput{
 			RobotResponse: errResp,
 			Send: SendOutput{
@@ -729,7 +729,7 @@ func GetSendAndAck(opts SendAndAckOptions) (*SendAndAckOutput, error) {
 				ackOutput.Confirmations = append(ackOutput.Confirmations, AckConfirmation{
 					Pane:      paneKey,
 					AckType:   string(ackType),
-					AckAt:     time.Now().UTC().Format(time.RFC3339),
+					AckAt:     FormatTimestamp(time.Now()),
 					LatencyMs: int(latency.Milliseconds()),
 				})
 			} else {
@@ -366,7 +366,7 @@ func convertProviderUsage(payload *caut.ProviderPayload) *ProviderUsageInfo {
 			ResetDescription: payload.GetResetDescription(),
 		}
 		if window.ResetsAt != nil {
-			info.PrimaryWindow.ResetsAt = window.ResetsAt.Format(time.RFC3339)
+			info.PrimaryWindow.ResetsAt = FormatTimestampPtr(window.ResetsAt)
 		}
 	}
 
@@ -467,7 +467,7 @@ func convertPTState(state *pt.AgentState, isWorking bool) *PTHealthInfo {
 	}
 
 	if !state.Since.IsZero() {
-		info.Since = state.Since.Format(time.RFC3339)
+		info.Since = FormatTimestamp(state.Since)
 	}
 
 	// Extract signals from recent history if available
@@ -8,6 +8,7 @@ import (
 
 	"github.com/Dicklesworthstone/ntm/internal/assign"
 	"github.com/Dicklesworthstone/ntm/internal/bv"
+	"github.com/Dicklesworthstone/ntm/internal/takeover"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 )
 
@@ -118,12 +119,16 @@ func GetAssignRecommendations(opts AssignOptions) ([]DistributeRecommendation, e
 	// Build agent info
 	var agents []assignAgentInfo
 	var idleAgentPanes []string
+	takenOver := takeover.Panes(opts.Session)
 
 	for _, pane := range panes {
 		agentType := detectAgentType(pane.Title)
 		if agentType == "user" || agentType == "unknown" {
 			continue
 		}
+		if takenOver[pane.ID] != nil {
+			continue // A human has the pane
+		}
 
 		// Capture state — use 20 lines to reliably detect Claude Code
 		// welcome screens and status bars (matches LinesStatusDetection).
@@ -249,6 +254,7 @@ func GetAssign(opts AssignOptions) (*AssignOutput, error) {
 	// Build agent info
 	var agents []assignAgentInfo
 	var idleAgentPanes []string
+	takenOver := takeover.Panes(opts.Session)
 
 	for _, pane := range panes {
 		agentType := detectAgentType(pane.Title)
@@ -262,6 +268,9 @@ func GetAssign(opts AssignOptions) (*AssignOutput, error) {
 		// welcome screens and status bars (matches LinesStatusDetection).
 		scrollback, _ := tmux.CapturePaneOutput(pane.ID, 20)
 		state := determineState(scrollback, agentType)
+		if takenOver[pane.ID] != nil {
+			state = "taken_over" // A human has the pane; never assign to it
+		}
 
 		agents = append(agents, assignAgentInfo{
 			paneIdx:   pane.Index,
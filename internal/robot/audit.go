@@ -0,0 +1,100 @@
+// Package robot provides machine-readable output for AI agents.
+// audit.go implements `ntm robot audit`.
+package robot
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/pagination"
+)
+
+// AuditOptions configures `ntm robot audit`.
+type AuditOptions struct {
+	// Dir overrides the audit log directory (audit.Dir()).
+	Dir        string
+	Sessions   []string
+	EventTypes []audit.EventType
+	Actors     []audit.Actor
+	// Target is a glob matched against entry targets.
+	Target string
+	// Grep is a regex matched against the raw entries.
+	Grep  string
+	Since time.Time
+	Until time.Time
+	// Limit is the page size (default pagination.DefaultLimit).
+	Limit int
+	// Cursor is the next_page_cursor of the previous page.
+	Cursor string
+}
+
+// AuditOutput is the response for `ntm robot audit`.
+type AuditOutput struct {
+	RobotResponse
+	Dir     string `json:"dir"`
+	Count   int    `json:"count"`
+	Scanned int    `json:"scanned"`
+	HasMore bool   `json:"has_more"`
+	// NextPageCursor resumes after this page; pass it back as --cursor.
+	NextPageCursor string             `json:"next_page_cursor,omitempty"`
+	Entries        []audit.AuditEntry `json:"entries"`
+}
+
+// GetAudit searches the audit logs one page at a time, in log order.
+func GetAudit(opts AuditOptions) (*AuditOutput, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = audit.Dir()
+	}
+	out := &AuditOutput{
+		RobotResponse: NewRobotResponse(true),
+		Dir:           dir,
+		Entries:       []audit.AuditEntry{},
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+	q := audit.Query{
+		Sessions:      opts.Sessions,
+		EventTypes:    opts.EventTypes,
+		Actors:        opts.Actors,
+		TargetPattern: opts.Target,
+		GrepPattern:   opts.Grep,
+		Limit:         min(limit, pagination.MaxLimit),
+		Cursor:        opts.Cursor,
+	}
+	if !opts.Since.IsZero() {
+		q.Since = &opts.Since
+	}
+	if !opts.Until.IsZero() {
+		q.Until = &opts.Until
+	}
+
+	result, err := audit.NewSearcherWithPath(dir).Search(q)
+	if err != nil {
+		code, hint := ErrCodeInternalError, "Check that the audit directory is readable"
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			code, hint = ErrCodeInvalidFlag, "Pass the next_page_cursor of a previous page, or omit --cursor"
+		}
+		out.RobotResponse = NewErrorResponse(err, code, hint)
+		return out, nil
+	}
+	out.Entries = result.Entries
+	out.Count = len(result.Entries)
+	out.Scanned = result.Scanned
+	out.NextPageCursor = result.NextPageCursor
+	out.HasMore = result.NextPageCursor != ""
+	return out, nil
+}
+
+// PrintAudit handles `ntm robot audit`.
+func PrintAudit(opts AuditOptions) error {
+	out, err := GetAudit(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
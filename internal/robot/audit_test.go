@@ -0,0 +1,44 @@
+package robot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+)
+
+func TestGetAudit(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	var data []byte
+	for i := 1; i <= 3; i++ {
+		line, _ := json.Marshal(audit.AuditEntry{
+			Timestamp: now, SessionID: "proj", EventType: audit.EventTypeCommand, Actor: audit.ActorUser, SequenceNum: uint64(i),
+		})
+		data = append(append(data, line...), '\n')
+	}
+	if err := os.WriteFile(filepath.Join(dir, "proj-"+now.Format("2006-01-02")+".jsonl"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GetAudit(AuditOptions{Dir: dir, Sessions: []string{"proj"}, Limit: 2})
+	if err != nil || !out.Success {
+		t.Fatalf("GetAudit() = %+v, %v", out, err)
+	}
+	if out.Count != 2 || !out.HasMore || out.NextPageCursor == "" {
+		t.Fatalf("first page = %+v", out)
+	}
+
+	out, _ = GetAudit(AuditOptions{Dir: dir, Sessions: []string{"proj"}, Limit: 2, Cursor: out.NextPageCursor})
+	if out.Count != 1 || out.HasMore || out.Entries[0].SequenceNum != 3 {
+		t.Errorf("second page = %+v", out)
+	}
+
+	out, _ = GetAudit(AuditOptions{Dir: dir, Cursor: "bogus"})
+	if out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("invalid cursor = %+v", out)
+	}
+}
@@ -0,0 +1,379 @@
+// Package robot provides machine-readable output for AI agents.
+// blame.go implements `ntm robot blame`, file-level history across agents.
+package robot
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/deadline"
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+// Overridable for tests.
+var (
+	blameAssignments = func(session string) []assignment.Assignment {
+		store, err := assignment.LoadStore(session)
+		if err != nil || store == nil {
+			return nil
+		}
+		return store.GetAll()
+	}
+	blameRecords = func(session string, since time.Time) ([]archive.ArchiveRecord, error) {
+		return archive.SessionRecords("", session, since)
+	}
+	blameWorktrees = func(projectDir, session string) ([]*worktrees.WorktreeInfo, error) {
+		return worktrees.NewManager(projectDir, session).ListWorktrees()
+	}
+)
+
+// Blame event sources.
+const (
+	BlameSourceWorkingSet = "working_set" // An assignment's working set lists the file as written
+	BlameSourceToolCall   = "tool_call"   // An archived pane capture shows an edit or write of the file
+	BlameSourceCommit     = "git_commit"  // A commit on an agent's worktree branch changed the file
+)
+
+// BlameOptions configures `ntm robot blame`.
+type BlameOptions struct {
+	Session string
+	// Path is the file, directory, or glob to blame, relative to ProjectDir.
+	Path string
+	// ProjectDir is the repository agent worktrees belong to.
+	ProjectDir string
+	// Since drops events older than this (0 = all history).
+	Since time.Duration
+	// Limit keeps the newest events (0 = all).
+	Limit int
+}
+
+// BlameEvent is one modification of the blamed path.
+type BlameEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source"`
+	File      string    `json:"file"`
+	Pane      string    `json:"pane,omitempty"`       // Pane name, e.g. cc_2
+	AgentType string    `json:"agent_type,omitempty"` // cc, cod, gmi
+	AgentName string    `json:"agent_name,omitempty"` // Agent Mail name or worktree agent
+	BeadID    string    `json:"bead_id,omitempty"`
+	BeadTitle string    `json:"bead_title,omitempty"`
+	Tool      string    `json:"tool,omitempty"`
+	Commit    string    `json:"commit,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Branch    string    `json:"branch,omitempty"`
+}
+
+// BlameAgent summarizes one agent's changes to the blamed path.
+type BlameAgent struct {
+	Agent     string    `json:"agent"` // Pane name, or worktree agent for commits
+	AgentType string    `json:"agent_type,omitempty"`
+	Events    int       `json:"events"`
+	Sources   []string  `json:"sources"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// BlameOutput is the response for `ntm robot blame`.
+type BlameOutput struct {
+	RobotResponse
+	Session     string         `json:"session"`
+	Path        string         `json:"path"`
+	Since       string         `json:"since,omitempty"`
+	TotalEvents int            `json:"total_events"`
+	Truncated   bool           `json:"truncated,omitempty"`
+	LastChange  *BlameEvent    `json:"last_change,omitempty"`
+	Agents      []BlameAgent   `json:"agents"`
+	Events      []BlameEvent   `json:"events"` // Oldest first
+	Sources     map[string]int `json:"sources"`
+	Warnings    []string       `json:"warnings"`
+}
+
+// GetBlame reports which agents of a session modified a path and when. It
+// merges the files written in assignment working sets, the edit and write
+// tool calls in archived pane output, and the commits on agent worktree
+// branches. The git commands stop when ctx is done.
+func GetBlame(ctx context.Context, opts BlameOptions) (*BlameOutput, error) {
+	out := &BlameOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		Agents:        []BlameAgent{},
+		Events:        []BlameEvent{},
+		Sources:       map[string]int{},
+		Warnings:      []string{},
+	}
+	if opts.Session == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session is required"), ErrCodeInvalidFlag, "Pass --session")
+		return out, nil
+	}
+	target := repoPath(opts.ProjectDir, opts.Path)
+	if target == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("path %q is not inside the project", opts.Path), ErrCodeInvalidFlag,
+			"Pass a path relative to the project root")
+		return out, nil
+	}
+	out.Path = target
+
+	var since time.Time
+	if opts.Since > 0 {
+		since = time.Now().Add(-opts.Since)
+		out.Since = FormatTimestamp(since)
+	}
+
+	// As a background job, each source's events are a partial result.
+	rep := jobs.Current()
+	var events []BlameEvent
+	setEvents := blameWorkingSets(opts.Session, target, since)
+	events = append(events, setEvents...)
+	rep.Partial(map[string]interface{}{"source": BlameSourceWorkingSet, "events": setEvents})
+	rep.Progress(10, "searching pane archives")
+
+	toolEvents, err := blameToolCalls(opts.Session, opts.ProjectDir, target, since)
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("pane archives unavailable: %v", err))
+	}
+	events = append(events, toolEvents...)
+	rep.Partial(map[string]interface{}{"source": BlameSourceToolCall, "events": toolEvents})
+	rep.Progress(70, "searching worktree commits")
+
+	commitEvents, warnings := blameCommits(ctx, opts.ProjectDir, opts.Session, target, since)
+	out.Warnings = append(out.Warnings, warnings...)
+	events = append(events, commitEvents...)
+	rep.Partial(map[string]interface{}{"source": BlameSourceCommit, "events": commitEvents})
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	out.TotalEvents = len(events)
+	out.Agents = summarizeBlame(events)
+	for _, ev := range events {
+		out.Sources[ev.Source]++
+	}
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		out.LastChange = &last
+	}
+	if opts.Limit > 0 && len(events) > opts.Limit {
+		events = events[len(events)-opts.Limit:]
+		out.Truncated = true
+	}
+	out.Events = append(out.Events, events...)
+	return out, nil
+}
+
+// PrintBlame outputs the blame of a path as JSON.
+func PrintBlame(ctx context.Context, opts BlameOptions) error {
+	out, err := GetBlame(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// blameWorkingSets returns an event for each assignment whose working set
+// lists a written file under target.
+func blameWorkingSets(session, target string, since time.Time) []BlameEvent {
+	var events []BlameEvent
+	for _, a := range blameAssignments(session) {
+		if a.WorkingSet == nil {
+			continue
+		}
+		at := a.WorkingSet.UpdatedAt
+		if at.IsZero() {
+			at = a.AssignedAt
+		}
+		if at.Before(since) {
+			continue
+		}
+		agentType := string(assign.ParseAgentType(a.AgentType))
+		for _, file := range a.WorkingSet.FilesWritten {
+			if !assignment.MatchPath(file, target) {
+				continue
+			}
+			events = append(events, BlameEvent{
+				Timestamp: at,
+				Source:    BlameSourceWorkingSet,
+				File:      file,
+				Pane:      fmt.Sprintf("%s_%d", agentType, a.Pane),
+				AgentType: agentType,
+				AgentName: a.AgentName,
+				BeadID:    a.BeadID,
+				BeadTitle: a.BeadTitle,
+			})
+		}
+	}
+	return events
+}
+
+// blameToolCalls returns an event for each edit, write, or delete of a file
+// under target found in the session's archived pane output.
+func blameToolCalls(session, projectDir, target string, since time.Time) ([]BlameEvent, error) {
+	records, err := blameRecords(session, since)
+	if err != nil {
+		return nil, err
+	}
+	var events []BlameEvent
+	for _, r := range records {
+		if r.Human {
+			continue
+		}
+		for _, call := range agent.ParseToolCalls(agent.AgentType(r.Agent), r.Content) {
+			if !call.Modifies() {
+				continue
+			}
+			for _, f := range call.Files {
+				file := repoPath(projectDir, f)
+				if file == "" || !assignment.MatchPath(file, target) {
+					continue
+				}
+				events = append(events, BlameEvent{
+					Timestamp: r.Timestamp,
+					Source:    BlameSourceToolCall,
+					File:      file,
+					Pane:      r.Pane,
+					AgentType: r.Agent,
+					Tool:      call.Tool,
+				})
+			}
+		}
+	}
+	return events, nil
+}
+
+// blameCommits returns an event for each commit made on an agent worktree
+// branch that changed target. The branch reflog holds exactly the commits
+// made on it, so history shared with the base branch is not attributed to
+// the agent.
+func blameCommits(ctx context.Context, projectDir, session, target string, since time.Time) ([]BlameEvent, []string) {
+	if projectDir == "" {
+		return nil, nil
+	}
+	trees, err := blameWorktrees(projectDir, session)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("worktrees unavailable: %v", err)}
+	}
+	var (
+		events   []BlameEvent
+		warnings []string
+	)
+	pathspec := target
+	if strings.Contains(target, "*") {
+		pathspec = ":(glob)" + target
+	}
+	for _, wt := range trees {
+		args := []string{"-C", projectDir, "log", "-g", "--name-only",
+			"--format=%x1e%H%x1f%aI%x1f%gs%x1f%s", "refs/heads/" + wt.BranchName}
+		if !since.IsZero() {
+			args = append(args, "--since="+since.Format(time.RFC3339))
+		}
+		args = append(args, "--", pathspec)
+		output, err := blameGitLog(ctx, args)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("git log %s: %v", wt.BranchName, err))
+			continue
+		}
+		events = append(events, parseBlameLog(string(output), wt)...)
+	}
+	return events, warnings
+}
+
+// blameGitLog runs one git log of blameCommits under the git deadline.
+func blameGitLog(ctx context.Context, args []string) ([]byte, error) {
+	ctx, cancel := deadline.With(ctx, deadline.Git)
+	defer cancel()
+	return exec.CommandContext(ctx, "git", args...).Output()
+}
+
+// parseBlameLog turns `git log -g --name-only` output into commit events,
+// skipping reflog entries that did not commit (branch creation, resets).
+func parseBlameLog(output string, wt *worktrees.WorktreeInfo) []BlameEvent {
+	var events []BlameEvent
+	for _, entry := range strings.Split(output, "\x1e") {
+		header, files, _ := strings.Cut(strings.TrimSpace(entry), "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 4 || !strings.HasPrefix(fields[2], "commit") {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			continue
+		}
+		agentType, _, _ := strings.Cut(wt.AgentName, "_")
+		for _, file := range strings.Fields(files) {
+			events = append(events, BlameEvent{
+				Timestamp: at,
+				Source:    BlameSourceCommit,
+				File:      file,
+				AgentType: agentType,
+				AgentName: wt.AgentName,
+				Commit:    fields[0],
+				Subject:   fields[3],
+				Branch:    wt.BranchName,
+			})
+		}
+	}
+	return events
+}
+
+// summarizeBlame groups events by agent, most recently active first.
+func summarizeBlame(events []BlameEvent) []BlameAgent {
+	byAgent := map[string]*BlameAgent{}
+	var agents []*BlameAgent
+	for _, ev := range events {
+		key := ev.Pane
+		if key == "" {
+			key = ev.AgentName
+		}
+		a, ok := byAgent[key]
+		if !ok {
+			a = &BlameAgent{Agent: key, AgentType: ev.AgentType, FirstSeen: ev.Timestamp}
+			byAgent[key] = a
+			agents = append(agents, a)
+		}
+		a.Events++
+		a.LastSeen = ev.Timestamp
+		if !slices.Contains(a.Sources, ev.Source) {
+			a.Sources = append(a.Sources, ev.Source)
+		}
+	}
+	sort.SliceStable(agents, func(i, j int) bool { return agents[i].LastSeen.After(agents[j].LastSeen) })
+	result := make([]BlameAgent, 0, len(agents))
+	for _, a := range agents {
+		result = append(result, *a)
+	}
+	return result
+}
+
+// repoPath makes file relative to projectDir. Paths inside an agent
+// worktree map to the same file in the project; paths outside the project
+// return "".
+func repoPath(projectDir, file string) string {
+	if file == "" {
+		return ""
+	}
+	if filepath.IsAbs(file) {
+		if projectDir == "" {
+			return ""
+		}
+		rel, err := filepath.Rel(projectDir, file)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return ""
+		}
+		file = rel
+	}
+	file = filepath.ToSlash(filepath.Clean(file))
+	if rest, ok := strings.CutPrefix(file, ".ntm/worktrees/"); ok {
+		if _, inTree, ok := strings.Cut(rest, "/"); ok {
+			file = inTree
+		}
+	}
+	return file
+}
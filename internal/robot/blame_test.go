@@ -0,0 +1,164 @@
+package robot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/worktrees"
+)
+
+func stubBlame(t *testing.T, assignments []assignment.Assignment, records []archive.ArchiveRecord, trees []*worktrees.WorktreeInfo) {
+	t.Helper()
+	oldAssignments, oldRecords, oldTrees := blameAssignments, blameRecords, blameWorktrees
+	t.Cleanup(func() {
+		blameAssignments, blameRecords, blameWorktrees = oldAssignments, oldRecords, oldTrees
+	})
+	blameAssignments = func(string) []assignment.Assignment { return assignments }
+	blameRecords = func(_ string, since time.Time) ([]archive.ArchiveRecord, error) {
+		var kept []archive.ArchiveRecord
+		for _, r := range records {
+			if !r.Timestamp.Before(since) {
+				kept = append(kept, r)
+			}
+		}
+		return kept, nil
+	}
+	blameWorktrees = func(string, string) ([]*worktrees.WorktreeInfo, error) { return trees, nil }
+}
+
+func TestGetBlame(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	stubBlame(t,
+		[]assignment.Assignment{
+			{BeadID: "bd-1", Pane: 2, AgentType: "claude", WorkingSet: &assignment.WorkingSet{
+				FilesWritten: []string{"internal/auth/login.go", "README.md"},
+				UpdatedAt:    now.Add(-time.Hour),
+			}},
+			{BeadID: "bd-2", Pane: 3, AgentType: "codex", WorkingSet: &assignment.WorkingSet{
+				FilesRead: []string{"internal/auth/login.go"},
+				UpdatedAt: now.Add(-time.Hour),
+			}},
+		},
+		[]archive.ArchiveRecord{
+			{Pane: "cc_2", Agent: "cc", Timestamp: now.Add(-50 * time.Minute),
+				Content: "⏺ Update(/repo/internal/auth/login.go)\n⏺ Read(/repo/internal/auth/token.go)"},
+			{Pane: "cc_4", Agent: "cc", Timestamp: now.Add(-10 * time.Minute),
+				Content: "⏺ Write(/repo/.ntm/worktrees/cc_2/internal/auth/login.go)"},
+			{Pane: "cc_4", Agent: "cc", Timestamp: now.Add(-5 * time.Minute), Human: true,
+				Content: "⏺ Write(/repo/internal/auth/login.go)"},
+		},
+		nil,
+	)
+
+	out, err := GetBlame(context.Background(), BlameOptions{Session: "proj", Path: "/repo/internal/auth/login.go", ProjectDir: "/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success || out.Path != "internal/auth/login.go" {
+		t.Fatalf("out = %+v", out)
+	}
+	if out.TotalEvents != 3 || out.Sources[BlameSourceWorkingSet] != 1 || out.Sources[BlameSourceToolCall] != 2 {
+		t.Fatalf("events = %+v, sources %v", out.Events, out.Sources)
+	}
+	if first := out.Events[0]; first.Pane != "cc_2" || first.BeadID != "bd-1" || first.Source != BlameSourceWorkingSet {
+		t.Errorf("first event = %+v", first)
+	}
+	if out.LastChange == nil || out.LastChange.Pane != "cc_4" || out.LastChange.Tool != "Write" {
+		t.Errorf("last change = %+v", out.LastChange)
+	}
+	if len(out.Agents) != 2 || out.Agents[0].Agent != "cc_4" || out.Agents[1].Events != 2 || len(out.Agents[1].Sources) != 2 {
+		t.Errorf("agents = %+v", out.Agents)
+	}
+
+	// Directories match the files under them; --since and --limit narrow.
+	out, _ = GetBlame(context.Background(), BlameOptions{Session: "proj", Path: "internal/auth", ProjectDir: "/repo", Since: 30 * time.Minute})
+	if out.TotalEvents != 1 || out.Events[0].Pane != "cc_4" {
+		t.Errorf("since: events = %+v", out.Events)
+	}
+	out, _ = GetBlame(context.Background(), BlameOptions{Session: "proj", Path: "internal/**/*.go", ProjectDir: "/repo", Limit: 1})
+	if out.TotalEvents != 3 || len(out.Events) != 1 || !out.Truncated {
+		t.Errorf("limit: total %d, events %d, truncated %v", out.TotalEvents, len(out.Events), out.Truncated)
+	}
+}
+
+func TestGetBlame_Errors(t *testing.T) {
+	stubBlame(t, nil, nil, nil)
+	if out, _ := GetBlame(context.Background(), BlameOptions{Path: "main.go"}); out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("missing session = %+v", out.RobotResponse)
+	}
+	if out, _ := GetBlame(context.Background(), BlameOptions{Session: "proj", Path: "/elsewhere/main.go", ProjectDir: "/repo"}); out.Success {
+		t.Error("path outside the project should fail")
+	}
+}
+
+func TestGetBlame_WorktreeCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git(dir, "init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(dir, "add", ".")
+	git(dir, "commit", "-qm", "base")
+
+	wt := filepath.Join(dir, ".ntm", "worktrees", "cod_1")
+	git(dir, "worktree", "add", "-q", "-b", "ntm/proj/cod_1", wt)
+	if err := os.WriteFile(filepath.Join(wt, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, "other.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git(wt, "add", ".")
+	git(wt, "commit", "-qm", "add main func")
+
+	stubBlame(t, nil, nil, []*worktrees.WorktreeInfo{{AgentName: "cod_1", Path: wt, BranchName: "ntm/proj/cod_1"}})
+	out, err := GetBlame(context.Background(), BlameOptions{Session: "proj", Path: "main.go", ProjectDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Warnings) != 0 {
+		t.Fatalf("warnings = %v", out.Warnings)
+	}
+	// The base commit is history the branch shares, not the agent's.
+	if len(out.Events) != 1 {
+		t.Fatalf("events = %+v", out.Events)
+	}
+	ev := out.Events[0]
+	if ev.Source != BlameSourceCommit || ev.AgentName != "cod_1" || ev.AgentType != "cod" ||
+		ev.Subject != "add main func" || ev.File != "main.go" || len(ev.Commit) != 40 {
+		t.Errorf("commit event = %+v", ev)
+	}
+}
+
+func TestRepoPath(t *testing.T) {
+	tests := map[string]string{
+		"internal/cli/root.go":                  "internal/cli/root.go",
+		"./internal/cli/":                       "internal/cli",
+		"/repo/internal/cli/root.go":            "internal/cli/root.go",
+		"/repo/.ntm/worktrees/cc_1/cmd/main.go": "cmd/main.go",
+		".ntm/worktrees/cod_2/internal/x/y.go":  "internal/x/y.go",
+		"/other/internal/cli/root.go":           "",
+		"":                                      "",
+	}
+	for in, want := range tests {
+		if got := repoPath("/repo", in); got != want {
+			t.Errorf("repoPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -551,7 +551,7 @@ func bulkAssignReason(bead bulkBead) string {
 		if bead.UpdatedAt.IsZero() {
 			return "stale_in_progress (unknown)"
 		}
-		return fmt.Sprintf("stale_in_progress (%s)", bead.UpdatedAt.UTC().Format(time.RFC3339))
+		return fmt.Sprintf("stale_in_progress (%s)", FormatTimestamp(bead.UpdatedAt.UTC()))
 	default:
 		if bead.Priority > 0 {
 			return fmt.Sprintf("ready_priority P%d", bead.Priority)
@@ -97,6 +97,7 @@ func buildCommandRegistry() []RobotCommandInfo {
 			Parameters: []RobotParameter{
 				{Name: "robot-limit", Flag: "--robot-limit", Type: "int", Required: false, Default: "0", Description: "Max sessions to return (alias: --limit)"},
 				{Name: "robot-offset", Flag: "--robot-offset", Type: "int", Required: false, Default: "0", Description: "Pagination offset for sessions (alias: --offset)"},
+				{Name: "robot-cursor", Flag: "--robot-cursor", Type: "string", Required: false, Description: "Resume after a previous page's next_page_cursor (instead of --robot-offset)"},
 			},
 			Examples: []string{"ntm --robot-status"},
 		},
@@ -174,6 +175,7 @@ func buildCommandRegistry() []RobotCommandInfo {
 				{Name: "bead-limit", Flag: "--bead-limit", Type: "int", Required: false, Default: "5", Description: "Max beads per category"},
 				{Name: "robot-limit", Flag: "--robot-limit", Type: "int", Required: false, Default: "0", Description: "Max sessions to return (alias: --limit)"},
 				{Name: "robot-offset", Flag: "--robot-offset", Type: "int", Required: false, Default: "0", Description: "Pagination offset for sessions (alias: --offset)"},
+				{Name: "robot-cursor", Flag: "--robot-cursor", Type: "string", Required: false, Description: "Resume after a previous page's next_page_cursor (instead of --robot-offset)"},
 			},
 			Examples: []string{
 				"ntm --robot-snapshot",
@@ -1158,6 +1160,7 @@ func buildCommandRegistry() []RobotCommandInfo {
 				{Name: "history-stats", Flag: "--history-stats", Type: "bool", Required: false, Description: "Show statistics instead of entries"},
 				{Name: "robot-limit", Flag: "--robot-limit", Type: "int", Required: false, Default: "0", Description: "Max history entries to return (alias: --limit)"},
 				{Name: "robot-offset", Flag: "--robot-offset", Type: "int", Required: false, Default: "0", Description: "Pagination offset for history entries (alias: --offset)"},
+				{Name: "robot-cursor", Flag: "--robot-cursor", Type: "string", Required: false, Description: "Resume after a previous page's next_page_cursor (instead of --robot-offset)"},
 			},
 			Examples: []string{"ntm --robot-history=myproject --history-last=10"},
 		},
@@ -0,0 +1,107 @@
+// Package robot provides machine-readable output for AI agents.
+// captures.go implements `ntm robot captures`.
+package robot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/pagination"
+)
+
+// CapturesOptions configures `ntm robot captures`.
+type CapturesOptions struct {
+	// Dir overrides the archive directory (archive.OutputDir()).
+	Dir     string
+	Session string
+	// Pane is an archived pane name (cc_1) or pane index; empty lists all.
+	Pane  string
+	Since time.Time
+	// Limit is the page size (default pagination.DefaultLimit).
+	Limit int
+	// Cursor is the next_page_cursor of the previous page.
+	Cursor string
+}
+
+// CapturesOutput is the response for `ntm robot captures`.
+type CapturesOutput struct {
+	RobotResponse
+	Session string `json:"session"`
+	Pane    string `json:"pane,omitempty"`
+	Count   int    `json:"count"`
+	Total   int    `json:"total"`
+	HasMore bool   `json:"has_more"`
+	// NextPageCursor resumes after this page; pass it back as --cursor.
+	NextPageCursor string                  `json:"next_page_cursor,omitempty"`
+	Captures       []archive.ArchiveRecord `json:"captures"` // Oldest first
+}
+
+// GetCaptures lists the pane output ntm monitor archived for a session, one
+// page at a time.
+func GetCaptures(opts CapturesOptions) (*CapturesOutput, error) {
+	out := &CapturesOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		Pane:          opts.Pane,
+		Captures:      []archive.ArchiveRecord{},
+	}
+	if opts.Session == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session is required"), ErrCodeInvalidFlag, "Pass --session")
+		return out, nil
+	}
+
+	records, err := archive.SessionRecords(opts.Dir, opts.Session, opts.Since)
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that the archive directory is readable")
+		return out, nil
+	}
+	if opts.Pane != "" {
+		var pane []archive.ArchiveRecord
+		for _, r := range records {
+			if r.Pane == opts.Pane || strconv.Itoa(r.PaneIndex) == opts.Pane {
+				pane = append(pane, r)
+			}
+		}
+		records = pane
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+	page, err := pagination.Slice(records, capturePageKey, false, opts.Cursor, min(limit, pagination.MaxLimit))
+	if err != nil {
+		code := ErrCodeInternalError
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			code = ErrCodeInvalidFlag
+		}
+		out.RobotResponse = NewErrorResponse(err, code, "Pass the next_page_cursor of a previous page, or omit --cursor")
+		return out, nil
+	}
+	if len(page.Items) > 0 {
+		out.Captures = page.Items
+	}
+	out.Count = len(page.Items)
+	out.Total = page.Total
+	out.HasMore = page.HasMore()
+	out.NextPageCursor = page.NextCursor
+	return out, nil
+}
+
+// capturePageKey orders captures by time; the pane and its capture
+// sequence break ties.
+func capturePageKey(r archive.ArchiveRecord) string {
+	return fmt.Sprintf("%s|%s|%010d", r.Timestamp.UTC().Format("2006-01-02T15:04:05.000000000Z"), r.Pane, r.Sequence)
+}
+
+// PrintCaptures handles `ntm robot captures`.
+func PrintCaptures(opts CapturesOptions) error {
+	out, err := GetCaptures(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
@@ -0,0 +1,63 @@
+package robot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+)
+
+func TestGetCaptures(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour).UTC()
+	var data []byte
+	for i := 0; i < 5; i++ {
+		for _, pane := range []string{"cc_1", "cod_2"} {
+			line, _ := json.Marshal(archive.ArchiveRecord{
+				Session: "proj", Pane: pane, PaneIndex: int(pane[len(pane)-1] - '0'),
+				Timestamp: base.Add(time.Duration(i) * time.Minute), Sequence: i + 1, Content: "out",
+			})
+			data = append(append(data, line...), '\n')
+		}
+	}
+	path := filepath.Join(dir, "proj_"+base.Local().Format("2006-01-02")+".jsonl")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GetCaptures(CapturesOptions{Dir: dir, Session: "proj", Pane: "1", Limit: 2})
+	if err != nil || !out.Success {
+		t.Fatalf("GetCaptures() = %+v, %v", out, err)
+	}
+	if out.Count != 2 || out.Total != 5 || !out.HasMore || out.Captures[0].Sequence != 1 || out.Captures[1].Pane != "cc_1" {
+		t.Fatalf("first page = %+v", out)
+	}
+
+	var seqs []int
+	cursor := ""
+	for {
+		out, _ = GetCaptures(CapturesOptions{Dir: dir, Session: "proj", Pane: "cc_1", Limit: 2, Cursor: cursor})
+		for _, c := range out.Captures {
+			seqs = append(seqs, c.Sequence)
+		}
+		if !out.HasMore {
+			break
+		}
+		cursor = out.NextPageCursor
+	}
+	if len(seqs) != 5 || seqs[0] != 1 || seqs[4] != 5 {
+		t.Errorf("paged sequences = %v, want 1..5", seqs)
+	}
+
+	out, _ = GetCaptures(CapturesOptions{Dir: dir, Session: "proj", Cursor: "bogus"})
+	if out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("invalid cursor = %+v", out)
+	}
+	out, _ = GetCaptures(CapturesOptions{Dir: dir})
+	if out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("missing session = %+v", out)
+	}
+}
@@ -0,0 +1,123 @@
+// Package robot provides machine-readable output for AI agents.
+// commands.go implements 'ntm robot commands', a JSON dump of the CLI surface.
+package robot
+
+import (
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// CLI Introspection
+// =============================================================================
+//
+// The CLI walks its command tree and hands the result here, so agents can
+// discover every command, flag, and output schema without scraping --help.
+
+// OutputSchemaAnnotation is the cobra command annotation naming the
+// SchemaCommand type a command prints.
+const OutputSchemaAnnotation = "ntm_output_schema"
+
+// CLICommand describes one CLI command.
+type CLICommand struct {
+	Path         string    `json:"path"` // e.g. "ntm robot send"
+	Use          string    `json:"use"`
+	Short        string    `json:"short"`
+	Aliases      []string  `json:"aliases,omitempty"`
+	Runnable     bool      `json:"runnable"`
+	Subcommands  []string  `json:"subcommands,omitempty"`
+	Flags        []CLIFlag `json:"flags"`
+	OutputSchema string    `json:"output_schema,omitempty"`
+}
+
+// CLIFlag describes one flag of a CLI command.
+type CLIFlag struct {
+	Name         string `json:"name"`
+	Shorthand    string `json:"shorthand,omitempty"`
+	Type         string `json:"type"` // pflag type: bool, string, int, duration, stringSlice, ...
+	Default      string `json:"default,omitempty"`
+	Usage        string `json:"usage"`
+	Persistent   bool   `json:"persistent,omitempty"`
+	OutputSchema string `json:"output_schema,omitempty"`
+}
+
+// CommandsOptions configures 'ntm robot commands'.
+type CommandsOptions struct {
+	Commands []CLICommand // collected by the CLI
+	Schemas  bool         // embed the referenced output schemas
+}
+
+// CommandsOutput is the response for 'ntm robot commands'.
+type CommandsOutput struct {
+	RobotResponse
+	Version  string                 `json:"version"`
+	Commands []CLICommand           `json:"commands"`
+	Schemas  map[string]*JSONSchema `json:"schemas,omitempty"`
+}
+
+// flagSchemaOverrides maps --robot-* flags whose name does not match their
+// SchemaCommand type.
+var flagSchemaOverrides = map[string]string{
+	"robot-inspect-pane":         "inspect",
+	"robot-health-restart-stuck": "auto_restart_stuck",
+}
+
+// SchemaTypeForFlag returns the SchemaCommand type printed by a --robot-*
+// flag, or "" when it has none.
+func SchemaTypeForFlag(name string) string {
+	if t, ok := flagSchemaOverrides[name]; ok {
+		return t
+	}
+	rest, ok := strings.CutPrefix(name, "robot-")
+	if !ok {
+		return ""
+	}
+	t := strings.ReplaceAll(rest, "-", "_")
+	if _, ok := SchemaCommand[t]; ok {
+		return t
+	}
+	return ""
+}
+
+// GetCommands returns the CLI surface, sorted by command path.
+// This function returns the data struct directly, enabling CLI/REST parity.
+func GetCommands(opts CommandsOptions) (*CommandsOutput, error) {
+	commands := append([]CLICommand(nil), opts.Commands...)
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Path < commands[j].Path })
+
+	output := &CommandsOutput{
+		RobotResponse: NewRobotResponse(true),
+		Version:       Version,
+		Commands:      commands,
+	}
+	if !opts.Schemas {
+		return output, nil
+	}
+
+	output.Schemas = make(map[string]*JSONSchema)
+	addSchema := func(name string) {
+		if name == "" || output.Schemas[name] != nil {
+			return
+		}
+		if typ, ok := SchemaCommand[name]; ok {
+			output.Schemas[name] = generateSchema(typ, name)
+		}
+	}
+	for _, c := range commands {
+		addSchema(c.OutputSchema)
+		for _, f := range c.Flags {
+			addSchema(f.OutputSchema)
+		}
+	}
+	return output, nil
+}
+
+// PrintCommands outputs the CLI surface as JSON.
+// This is a thin wrapper around GetCommands() for CLI output.
+func PrintCommands(opts CommandsOptions) error {
+	output, err := GetCommands(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(output)
+}
@@ -0,0 +1,71 @@
+package robot
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaTypeForFlag(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"robot-status":               "status",
+		"robot-watch-bead":           "watch_bead",
+		"robot-inspect-pane":         "inspect",
+		"robot-health-restart-stuck": "auto_restart_stuck",
+		"robot-search":               "",
+		"status":                     "",
+	}
+	for flag, want := range tests {
+		if got := SchemaTypeForFlag(flag); got != want {
+			t.Errorf("SchemaTypeForFlag(%q) = %q, want %q", flag, got, want)
+		}
+	}
+}
+
+func TestGetCommands(t *testing.T) {
+	t.Parallel()
+
+	opts := CommandsOptions{Commands: []CLICommand{
+		{Path: "ntm spawn", Flags: []CLIFlag{}},
+		{Path: "ntm", Flags: []CLIFlag{{Name: "robot-tail", OutputSchema: "tail"}}},
+		{Path: "ntm robot commands", OutputSchema: "commands", Flags: []CLIFlag{}},
+	}}
+
+	out, err := GetCommands(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success || out.Schemas != nil {
+		t.Fatalf("out = %+v", out)
+	}
+	if out.Commands[0].Path != "ntm" || out.Commands[2].Path != "ntm spawn" {
+		t.Errorf("commands not sorted by path: %+v", out.Commands)
+	}
+
+	opts.Schemas = true
+	out, err = GetCommands(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Schemas) != 2 || out.Schemas["tail"] == nil || out.Schemas["commands"] == nil {
+		t.Errorf("schemas = %v", out.Schemas)
+	}
+	if _, err := json.Marshal(out); err != nil {
+		t.Errorf("marshal: %v", err)
+	}
+}
+
+func TestTypeToSchema_SelfReferencing(t *testing.T) {
+	t.Parallel()
+
+	type node struct {
+		Children []*node `json:"children"`
+	}
+	defs := make(map[string]*JSONSchema)
+	schema := typeToSchema(reflect.TypeOf(node{}), defs)
+	if schema.Ref != "#/definitions/node" || defs["node"] == nil {
+		t.Errorf("schema = %+v, defs = %v", schema, defs)
+	}
+}
@@ -0,0 +1,286 @@
+// Package robot provides machine-readable output for AI agents.
+// confirm.go implements two-phase confirmation for destructive commands.
+package robot
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Confirmation classes group destructive commands so a policy can require
+// confirmation for, say, every kind of restart at once.
+const (
+	ConfirmClassKill      = "kill"      // kill a session or its panes
+	ConfirmClassRestart   = "restart"   // restart agents in panes
+	ConfirmClassInterrupt = "interrupt" // Ctrl+C running agents
+	ConfirmClassStop      = "stop"      // stop an ensemble
+	ConfirmClassPurge     = "purge"     // remove stored session data
+)
+
+// DefaultConfirmStore holds issued, not yet used confirmation tokens.
+const DefaultConfirmStore = "~/.local/share/ntm/robot/confirmations.json"
+
+// DefaultConfirmTTL is how long an issued token stays valid.
+const DefaultConfirmTTL = 5 * time.Minute
+
+// ConfirmPolicy decides which command classes need a confirmation token.
+type ConfirmPolicy struct {
+	Require map[string]bool
+	TTL     time.Duration
+	// Store overrides DefaultConfirmStore.
+	Store string
+}
+
+// DefaultConfirmPolicy requires confirmation for purge only.
+func DefaultConfirmPolicy() ConfirmPolicy {
+	return ConfirmPolicy{
+		Require: map[string]bool{ConfirmClassPurge: true},
+		TTL:     DefaultConfirmTTL,
+	}
+}
+
+var (
+	confirmPolicyMu sync.RWMutex
+	confirmPolicy   = DefaultConfirmPolicy()
+)
+
+// SetConfirmPolicy replaces the process-wide confirmation policy.
+func SetConfirmPolicy(p ConfirmPolicy) {
+	if p.TTL <= 0 {
+		p.TTL = DefaultConfirmTTL
+	}
+	confirmPolicyMu.Lock()
+	confirmPolicy = p
+	confirmPolicyMu.Unlock()
+}
+
+func currentConfirmPolicy() ConfirmPolicy {
+	confirmPolicyMu.RLock()
+	defer confirmPolicyMu.RUnlock()
+	return confirmPolicy
+}
+
+// ConfirmRequired reports whether commands of class need a token.
+func ConfirmRequired(class string) bool {
+	return currentConfirmPolicy().Require[class]
+}
+
+// ConfirmRequest describes a destructive action awaiting confirmation.
+type ConfirmRequest struct {
+	Class   string
+	Command string // e.g. "robot-restart-pane", "kill"
+	Session string
+	// Target identifies exactly what the command acts on (panes, filters,
+	// cutoffs); a token only confirms the target it was issued for.
+	Target  string
+	Summary string
+	Effects []string
+}
+
+// ConfirmationInfo describes an issued or rejected confirmation.
+type ConfirmationInfo struct {
+	Token     string   `json:"token,omitempty"`
+	Class     string   `json:"class"`
+	Command   string   `json:"command"`
+	Session   string   `json:"session,omitempty"`
+	Summary   string   `json:"summary"`
+	Effects   []string `json:"effects,omitempty"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+// ConfirmationOutput is returned instead of running a destructive command
+// that has not been confirmed.
+type ConfirmationOutput struct {
+	RobotResponse
+	Confirmation ConfirmationInfo `json:"confirmation"`
+}
+
+// pendingConfirmation is a stored, unused token.
+type pendingConfirmation struct {
+	Class     string    `json:"class"`
+	Command   string    `json:"command"`
+	Session   string    `json:"session"`
+	Digest    string    `json:"digest"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// CheckConfirmation gates a destructive command. It returns nil when the
+// command may run: its class needs no confirmation, or token is a valid,
+// unexpired token issued for this exact request (the token is consumed).
+// Otherwise it returns the output to print instead: a fresh token with a
+// summary of what will happen, or the reason the token was rejected.
+// Every issue, confirmation, and rejection is audited.
+func CheckConfirmation(req ConfirmRequest, token string) *ConfirmationOutput {
+	policy := currentConfirmPolicy()
+	if !policy.Require[req.Class] {
+		return nil
+	}
+	store := confirmStore{path: util.ExpandPath(defaultString(policy.Store, DefaultConfirmStore))}
+	info := ConfirmationInfo{
+		Class:   req.Class,
+		Command: req.Command,
+		Session: req.Session,
+		Summary: req.Summary,
+		Effects: req.Effects,
+	}
+
+	if token == "" {
+		pending, err := store.issue(req, policy.TTL)
+		if err != nil {
+			auditConfirm(req, "", "error", err.Error())
+			return &ConfirmationOutput{
+				RobotResponse: NewErrorResponse(fmt.Errorf("issuing confirmation token: %w", err), ErrCodeInternalError, "Check that ~/.local/share/ntm is writable"),
+				Confirmation:  info,
+			}
+		}
+		info.Token = pending.token
+		info.ExpiresAt = FormatTimestamp(pending.ExpiresAt)
+		auditConfirm(req, pending.token, "requested", "")
+		return &ConfirmationOutput{
+			RobotResponse: NewErrorResponse(
+				fmt.Errorf("%s requires confirmation: %s", req.Command, req.Summary),
+				ErrCodeConfirmationRequired,
+				fmt.Sprintf("Review the summary, then re-run the same command with --confirm-token %s before %s", pending.token, info.ExpiresAt)),
+			Confirmation: info,
+		}
+	}
+
+	if err := store.consume(token, req); err != nil {
+		auditConfirm(req, token, "rejected", err.Error())
+		return &ConfirmationOutput{
+			RobotResponse: NewErrorResponse(err, ErrCodeConfirmationRequired,
+				"Re-run without --confirm-token to get a new token"),
+			Confirmation: info,
+		}
+	}
+	auditConfirm(req, token, "confirmed", "")
+	return nil
+}
+
+// PrintConfirmation writes a confirmation response.
+func PrintConfirmation(out *ConfirmationOutput) error {
+	return encodeJSON(out)
+}
+
+func auditConfirm(req ConfirmRequest, token, phase, reason string) {
+	payload := map[string]interface{}{
+		"phase":   phase,
+		"class":   req.Class,
+		"command": req.Command,
+		"summary": req.Summary,
+	}
+	if token != "" {
+		payload["token"] = token
+	}
+	if reason != "" {
+		payload["reason"] = reason
+	}
+	_ = audit.LogEvent(req.Session, audit.EventTypeConfirm, audit.ActorAgent, "confirm."+req.Class, payload, nil)
+}
+
+func confirmDigest(req ConfirmRequest) string {
+	sum := sha256.Sum256([]byte(req.Class + "\x00" + req.Command + "\x00" + req.Session + "\x00" + req.Target))
+	return hex.EncodeToString(sum[:])
+}
+
+// confirmStore persists pending tokens so the two phases can run in
+// separate processes.
+type confirmStore struct {
+	path string
+}
+
+type issuedConfirmation struct {
+	pendingConfirmation
+	token string
+}
+
+func (s confirmStore) update(fn func(map[string]pendingConfirmation) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	unlock, err := util.LockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	pending := make(map[string]pendingConfirmation)
+	if data, err := os.ReadFile(s.path); err == nil {
+		_ = json.Unmarshal(data, &pending)
+	}
+	now := time.Now()
+	for tok, p := range pending {
+		if now.After(p.ExpiresAt) {
+			delete(pending, tok)
+		}
+	}
+	if err := fn(pending); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.AtomicWriteFile(s.path, data, 0600)
+}
+
+func (s confirmStore) issue(req ConfirmRequest, ttl time.Duration) (*issuedConfirmation, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	issued := &issuedConfirmation{
+		token: "cfm_" + hex.EncodeToString(buf),
+		pendingConfirmation: pendingConfirmation{
+			Class:     req.Class,
+			Command:   req.Command,
+			Session:   req.Session,
+			Digest:    confirmDigest(req),
+			IssuedAt:  now,
+			ExpiresAt: now.Add(ttl),
+		},
+	}
+	err := s.update(func(pending map[string]pendingConfirmation) error {
+		pending[issued.token] = issued.pendingConfirmation
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// consume validates token against req and removes it. Tokens are single
+// use: a mismatched token is left in place, a matching one is deleted.
+func (s confirmStore) consume(token string, req ConfirmRequest) error {
+	return s.update(func(pending map[string]pendingConfirmation) error {
+		p, ok := pending[token]
+		if !ok {
+			return fmt.Errorf("confirmation token %s is unknown, expired, or already used", token)
+		}
+		if p.Digest != confirmDigest(req) {
+			return fmt.Errorf("confirmation token %s was issued for a different action (%s on %s)", token, p.Command, p.Session)
+		}
+		delete(pending, token)
+		return nil
+	})
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package robot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setTestConfirmPolicy(t *testing.T, p ConfirmPolicy) {
+	t.Helper()
+	prev := currentConfirmPolicy()
+	p.Store = filepath.Join(t.TempDir(), "confirmations.json")
+	SetConfirmPolicy(p)
+	t.Cleanup(func() { SetConfirmPolicy(prev) })
+}
+
+func TestCheckConfirmation_NotRequired(t *testing.T) {
+	setTestConfirmPolicy(t, ConfirmPolicy{Require: map[string]bool{ConfirmClassPurge: true}})
+	req := ConfirmRequest{Class: ConfirmClassRestart, Command: "robot-restart-pane", Session: "proj"}
+	if out := CheckConfirmation(req, ""); out != nil {
+		t.Fatalf("restart is not required by the policy, got %+v", out)
+	}
+}
+
+func TestCheckConfirmation_TwoPhase(t *testing.T) {
+	setTestConfirmPolicy(t, ConfirmPolicy{Require: map[string]bool{ConfirmClassRestart: true}})
+	req := ConfirmRequest{
+		Class:   ConfirmClassRestart,
+		Command: "robot-restart-pane",
+		Session: "proj",
+		Target:  "panes=1,2",
+		Summary: "Restart panes 1,2 in session proj",
+		Effects: []string{"context is lost"},
+	}
+
+	out := CheckConfirmation(req, "")
+	if out == nil {
+		t.Fatal("first call should require confirmation")
+	}
+	if out.Success || out.ErrorCode != ErrCodeConfirmationRequired {
+		t.Errorf("response = %+v, want CONFIRMATION_REQUIRED", out.RobotResponse)
+	}
+	info := out.Confirmation
+	if info.Token == "" || info.Summary != req.Summary || len(info.Effects) != 1 || info.ExpiresAt == "" {
+		t.Fatalf("confirmation = %+v", info)
+	}
+
+	// A token only confirms the request it was issued for.
+	other := req
+	other.Target = "panes=3"
+	if out := CheckConfirmation(other, info.Token); out == nil || out.Confirmation.Token != "" {
+		t.Fatalf("token for panes=1,2 should not confirm panes=3: %+v", out)
+	}
+
+	if out := CheckConfirmation(req, info.Token); out != nil {
+		t.Fatalf("valid token rejected: %+v", out.RobotResponse)
+	}
+	// Tokens are single use.
+	if out := CheckConfirmation(req, info.Token); out == nil {
+		t.Fatal("reused token should be rejected")
+	}
+	if out := CheckConfirmation(req, "cfm_bogus"); out == nil || out.ErrorCode != ErrCodeConfirmationRequired {
+		t.Fatal("unknown token should be rejected")
+	}
+}
+
+func TestCheckConfirmation_Expiry(t *testing.T) {
+	setTestConfirmPolicy(t, ConfirmPolicy{Require: map[string]bool{ConfirmClassKill: true}, TTL: time.Millisecond})
+	req := ConfirmRequest{Class: ConfirmClassKill, Command: "kill", Session: "proj", Target: "session:proj"}
+
+	out := CheckConfirmation(req, "")
+	if out == nil || out.Confirmation.Token == "" {
+		t.Fatal("expected a token")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if CheckConfirmation(req, out.Confirmation.Token) == nil {
+		t.Error("expired token should be rejected")
+	}
+}
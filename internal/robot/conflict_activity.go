@@ -0,0 +1,114 @@
+// Package robot provides machine-readable output for AI agents and automation.
+// conflict_activity.go derives conflict activity windows from status detection.
+package robot
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+// DefaultActivityLookback is how far back the first observation of a pane
+// counts its last output as activity.
+const DefaultActivityLookback = 15 * time.Minute
+
+// ObserveStatus records a pane's activity from status detection. While the
+// pane is working its activity window stays open and is extended by each
+// observation; output since the previous observation counts as activity
+// even if the pane is idle again by now. The first observation of a pane
+// counts its last output within the detector's activity lookback.
+func (cd *ConflictDetector) ObserveStatus(st status.AgentStatus) {
+	now := st.UpdatedAt
+	if now.IsZero() {
+		now = time.Now()
+	}
+	agentType := st.AgentType
+	if agentType == "" || agentType == "unknown" {
+		agentType = "user"
+	}
+
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	prev, seen := cd.lastObserved[st.PaneID]
+	cd.lastObserved[st.PaneID] = now
+	working := st.State == status.StateWorking
+
+	var start, end time.Time
+	switch {
+	case working:
+		start, end = prev, now
+		if !seen {
+			start = now
+			if !st.LastActive.IsZero() && st.LastActive.Before(now) {
+				start = st.LastActive
+			}
+		}
+	case seen && st.LastActive.After(prev):
+		start, end = prev, st.LastActive
+	case !seen && !st.LastActive.IsZero() && now.Sub(st.LastActive) <= cd.activityLookback:
+		start, end = st.LastActive, st.LastActive
+	default:
+		cd.openWindows[st.PaneID] = false
+		return
+	}
+
+	windows := cd.activityWindows[st.PaneID]
+	if cd.openWindows[st.PaneID] && len(windows) > 0 {
+		last := &windows[len(windows)-1]
+		if end.After(last.End) {
+			last.End = end
+		}
+	} else {
+		cd.activityWindows[st.PaneID] = append(windows, ActivityWindow{
+			PaneID:    st.PaneID,
+			AgentType: agentType,
+			Start:     start,
+			End:       end,
+			HasOutput: true,
+		})
+	}
+	cd.openWindows[st.PaneID] = working
+	cd.pruneWindowsLocked(now.Add(-1 * time.Hour))
+}
+
+// ObserveSession detects the status of every pane of the detector's session
+// and records their activity. It does nothing without a session.
+func (cd *ConflictDetector) ObserveSession(ctx context.Context) error {
+	if cd.session == "" {
+		return nil
+	}
+	statuses, err := cd.statusDetector.DetectAll(cd.session)
+	if err != nil {
+		return err
+	}
+	for _, st := range statuses {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		cd.ObserveStatus(st)
+	}
+	return nil
+}
+
+// Track observes the detector's session every interval until ctx is
+// cancelled, so windows cover activity between conflict checks.
+func (cd *ConflictDetector) Track(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := cd.ObserveSession(ctx); err != nil && ctx.Err() == nil {
+			slog.Debug("conflict activity: observe session", "session", cd.session, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
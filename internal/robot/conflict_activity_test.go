@@ -0,0 +1,85 @@
+package robot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/status"
+)
+
+type fakeStatusDetector struct {
+	statuses []status.AgentStatus
+}
+
+func (f *fakeStatusDetector) Detect(paneID string) (status.AgentStatus, error) {
+	for _, st := range f.statuses {
+		if st.PaneID == paneID {
+			return st, nil
+		}
+	}
+	return status.AgentStatus{}, nil
+}
+
+func (f *fakeStatusDetector) DetectAll(string) ([]status.AgentStatus, error) {
+	return f.statuses, nil
+}
+
+func TestObserveStatusWindows(t *testing.T) {
+	cd := NewConflictDetector(&ConflictDetectorConfig{RepoPath: t.TempDir()})
+	t0 := time.Now().Add(-10 * time.Minute)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+
+	// Working across observations grows one window from the last output.
+	cd.ObserveStatus(status.AgentStatus{PaneID: "%1", AgentType: "cc", State: status.StateWorking, LastActive: at(-2 * time.Second), UpdatedAt: at(0)})
+	cd.ObserveStatus(status.AgentStatus{PaneID: "%1", AgentType: "cc", State: status.StateWorking, LastActive: at(9 * time.Second), UpdatedAt: at(10 * time.Second)})
+	// Idle with no output since: the window closes where it was.
+	cd.ObserveStatus(status.AgentStatus{PaneID: "%1", AgentType: "cc", State: status.StateIdle, LastActive: at(9 * time.Second), UpdatedAt: at(20 * time.Second)})
+	// Output between observations counts even though the pane is idle again.
+	cd.ObserveStatus(status.AgentStatus{PaneID: "%1", AgentType: "cc", State: status.StateIdle, LastActive: at(25 * time.Second), UpdatedAt: at(30 * time.Second)})
+
+	windows := cd.GetActivityWindows()["%1"]
+	if len(windows) != 2 {
+		t.Fatalf("windows = %+v", windows)
+	}
+	if !windows[0].Start.Equal(at(-2*time.Second)) || !windows[0].End.Equal(at(10*time.Second)) || windows[0].AgentType != "cc" {
+		t.Errorf("working window = %+v", windows[0])
+	}
+	if !windows[1].Start.Equal(at(20*time.Second)) || !windows[1].End.Equal(at(25*time.Second)) {
+		t.Errorf("between-observations window = %+v", windows[1])
+	}
+
+	// A pane first seen idle counts its last output within the lookback;
+	// older output and user panes without activity do not.
+	cd.ObserveStatus(status.AgentStatus{PaneID: "%2", AgentType: "", State: status.StateIdle, LastActive: at(-5 * time.Minute), UpdatedAt: at(0)})
+	cd.ObserveStatus(status.AgentStatus{PaneID: "%3", AgentType: "cod", State: status.StateIdle, LastActive: at(-time.Hour), UpdatedAt: at(0)})
+	all := cd.GetActivityWindows()
+	if w := all["%2"]; len(w) != 1 || w[0].AgentType != "user" || !w[0].Start.Equal(at(-5*time.Minute)) {
+		t.Errorf("recent idle pane windows = %+v", w)
+	}
+	if w := all["%3"]; len(w) != 0 {
+		t.Errorf("stale pane windows = %+v", w)
+	}
+}
+
+func TestDetectConflictsObservesSession(t *testing.T) {
+	dir := initConflictsRepo(t)
+	now := time.Now()
+	fake := &fakeStatusDetector{statuses: []status.AgentStatus{
+		{PaneID: "%1", AgentType: "cc", State: status.StateWorking, LastActive: now, UpdatedAt: now},
+		{PaneID: "%2", AgentType: "cod", State: status.StateWorking, LastActive: now, UpdatedAt: now},
+	}}
+	cd := NewConflictDetector(&ConflictDetectorConfig{RepoPath: dir, Session: "proj", StatusDetector: fake})
+	writeRepoFile(t, dir, "shared.go", "package shared\n")
+
+	conflicts, err := cd.DetectConflicts(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Reason != ReasonConcurrentActivity || len(conflicts[0].LikelyModifiers) != 2 {
+		t.Fatalf("conflicts = %+v", conflicts)
+	}
+	if len(cd.GetActivityWindows()) != 2 {
+		t.Errorf("windows = %+v", cd.GetActivityWindows())
+	}
+}
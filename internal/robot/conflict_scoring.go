@@ -0,0 +1,89 @@
+// Package robot provides machine-readable output for AI agents and automation.
+// conflict_scoring.go defines the profile conflict detection scores with.
+package robot
+
+import (
+	"maps"
+	"time"
+)
+
+// Scoring situations, the keys of ConflictScoring.Weights.
+const (
+	ScoreConcurrentActivity      = "concurrent_activity"      // Several panes modified the file
+	ScoreReservationViolation    = "reservation_violation"    // One pane modified a file reserved by others
+	ScoreHolderModification      = "holder_modification"      // The reservation holder modified the file
+	ScoreOverlappingReservations = "overlapping_reservations" // No modifier, several reservation holders
+	ScoreReservedUnattributed    = "reserved_unattributed"    // No modifier, one reservation holder
+	ScoreUnclaimedModification   = "unclaimed_modification"   // No modifier, no reservation holder
+	ScoreSingleModifier          = "single_modifier"          // One pane modified the file, no reservation holder
+)
+
+// DefaultConflictScoringProfile names the built-in scoring profile.
+const DefaultConflictScoringProfile = "default"
+
+// ConflictScoring is the profile conflict detection scores with. It is
+// echoed in conflict output so a result can be reproduced.
+type ConflictScoring struct {
+	// Profile names the profile; "custom" when weights were set without one.
+	Profile string `json:"profile"`
+	// Weights maps each scoring situation to its confidence (0.0-1.0).
+	Weights map[string]float64 `json:"weights"`
+	// ToleranceSeconds is how far an activity window may lie from a file's
+	// modification time for its pane to count as a likely modifier.
+	ToleranceSeconds int `json:"tolerance_seconds"`
+	// MinConfidence is the lowest confidence reported as a conflict.
+	MinConfidence float64 `json:"min_confidence"`
+}
+
+// DefaultConflictScoring returns the built-in scoring profile.
+func DefaultConflictScoring() ConflictScoring {
+	return ConflictScoring{
+		Profile: DefaultConflictScoringProfile,
+		Weights: map[string]float64{
+			ScoreConcurrentActivity:      0.9,
+			ScoreReservationViolation:    0.85,
+			ScoreHolderModification:      0.3,
+			ScoreOverlappingReservations: 0.75,
+			ScoreReservedUnattributed:    0.5,
+			ScoreUnclaimedModification:   0.6,
+			ScoreSingleModifier:          0.4,
+		},
+		ToleranceSeconds: 60,
+		MinConfidence:    0.5,
+	}
+}
+
+// withDefaults completes a partial profile from the built-in one: missing
+// weights, tolerance, and threshold take the default values.
+func (s ConflictScoring) withDefaults() ConflictScoring {
+	def := DefaultConflictScoring()
+	custom := len(s.Weights) > 0 || s.ToleranceSeconds > 0 || s.MinConfidence > 0
+
+	weights := def.Weights
+	maps.Copy(weights, s.Weights)
+	s.Weights = weights
+	if s.ToleranceSeconds <= 0 {
+		s.ToleranceSeconds = def.ToleranceSeconds
+	}
+	if s.MinConfidence <= 0 {
+		s.MinConfidence = def.MinConfidence
+	}
+	if s.Profile == "" {
+		s.Profile = def.Profile
+		if custom {
+			s.Profile = "custom"
+		}
+	}
+	return s
+}
+
+// tolerance returns the activity tolerance window as a duration.
+func (s ConflictScoring) tolerance() time.Duration {
+	return time.Duration(s.ToleranceSeconds) * time.Second
+}
+
+// clone returns a copy that does not share the weights map.
+func (s ConflictScoring) clone() ConflictScoring {
+	s.Weights = maps.Clone(s.Weights)
+	return s
+}
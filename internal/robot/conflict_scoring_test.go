@@ -0,0 +1,63 @@
+package robot
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/config"
+)
+
+func TestConflictScoringWithDefaults(t *testing.T) {
+	t.Parallel()
+
+	def := ConflictScoring{}.withDefaults()
+	if def.Profile != DefaultConflictScoringProfile || def.ToleranceSeconds != 60 || def.MinConfidence != 0.5 {
+		t.Errorf("zero profile = %+v", def)
+	}
+
+	partial := ConflictScoring{Weights: map[string]float64{ScoreSingleModifier: 0.7}}.withDefaults()
+	if partial.Profile != "custom" || partial.Weights[ScoreSingleModifier] != 0.7 || partial.Weights[ScoreConcurrentActivity] != 0.9 {
+		t.Errorf("partial profile = %+v", partial)
+	}
+
+	// The weight keys are the ones config validation accepts.
+	if keys := slices.Sorted(maps.Keys(def.Weights)); !slices.Equal(keys, slices.Sorted(slices.Values(config.ConflictScoringKeys))) {
+		t.Errorf("weight keys = %v, config keys = %v", keys, config.ConflictScoringKeys)
+	}
+}
+
+func TestConflictDetectorCustomScoring(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	cd := NewConflictDetector(&ConflictDetectorConfig{Scoring: ConflictScoring{
+		Profile:          "strict",
+		Weights:          map[string]float64{ScoreSingleModifier: 0.65},
+		ToleranceSeconds: 5,
+		MinConfidence:    0.6,
+	}})
+	cd.RecordActivity("%1", "claude", now.Add(-30*time.Second), now.Add(-20*time.Second), true)
+
+	conflict := &DetectedConflict{LikelyModifiers: []string{"%1"}}
+	cd.scoreConflict(conflict, 1, 0)
+	if conflict.Confidence != 0.65 {
+		t.Errorf("Confidence = %v, want 0.65", conflict.Confidence)
+	}
+
+	// Activity 10s before the modification is outside a 5s tolerance.
+	if got := cd.findLikelyModifiers(GitFileStatus{Path: "a.go", ModifiedAt: now.Add(-10 * time.Second)}); len(got) != 0 {
+		t.Errorf("modifiers = %v, want none", got)
+	}
+	if got := cd.findLikelyModifiers(GitFileStatus{Path: "a.go", ModifiedAt: now.Add(-16 * time.Second)}); len(got) != 1 {
+		t.Errorf("modifiers = %v, want %%1", got)
+	}
+
+	// Callers get a copy of the profile.
+	scoring := cd.Scoring()
+	scoring.Weights[ScoreSingleModifier] = 0
+	if cd.Scoring().Weights[ScoreSingleModifier] != 0.65 || cd.Scoring().Profile != "strict" {
+		t.Errorf("Scoring() = %+v", cd.Scoring())
+	}
+}
@@ -0,0 +1,82 @@
+// Package robot provides machine-readable output for AI agents.
+// conflict_stats.go implements `ntm robot conflict-stats`.
+package robot
+
+import (
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+// DefaultConflictStatsTop is how many files and pairs are listed by default.
+const DefaultConflictStatsTop = 10
+
+// ConflictStatsOptions configures `ntm robot conflict-stats`.
+type ConflictStatsOptions struct {
+	// Since limits the analysis to conflicts detected at or after this time.
+	Since time.Time
+	// Session, if set, limits the analysis to one session.
+	Session string
+	// Top limits the file and pair lists (0 = unlimited).
+	Top int
+	// HistoryPath overrides the conflict history location.
+	HistoryPath string
+}
+
+// ConflictStatsOutput is the response for `ntm robot conflict-stats`.
+type ConflictStatsOutput struct {
+	RobotResponse
+	HistoryPath string `json:"history_path"`
+	Since       string `json:"since,omitempty"`
+	Session     string `json:"session,omitempty"`
+	tracker.ConflictStats
+	// OpenConflicts lists the episodes still awaiting resolution.
+	OpenConflicts []tracker.ConflictEpisode `json:"open_conflicts"`
+}
+
+// GetConflictStats analyzes the persisted conflict history.
+func GetConflictStats(opts ConflictStatsOptions) (*ConflictStatsOutput, error) {
+	history := tracker.NewConflictHistory(opts.HistoryPath)
+	out := &ConflictStatsOutput{
+		RobotResponse: NewRobotResponse(true),
+		HistoryPath:   history.Path(),
+		Session:       opts.Session,
+		OpenConflicts: []tracker.ConflictEpisode{},
+	}
+	if !opts.Since.IsZero() {
+		out.Since = FormatTimestamp(opts.Since)
+	}
+
+	episodes, err := history.Episodes(opts.Since)
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that the conflict history file is readable")
+		out.ConflictStats = *tracker.AnalyzeConflicts(nil, 0, time.Now())
+		return out, nil
+	}
+	if opts.Session != "" {
+		filtered := episodes[:0]
+		for _, ep := range episodes {
+			if ep.Session == opts.Session {
+				filtered = append(filtered, ep)
+			}
+		}
+		episodes = filtered
+	}
+
+	out.ConflictStats = *tracker.AnalyzeConflicts(episodes, opts.Top, time.Now())
+	for _, ep := range episodes {
+		if ep.Open() {
+			out.OpenConflicts = append(out.OpenConflicts, ep)
+		}
+	}
+	return out, nil
+}
+
+// PrintConflictStats handles `ntm robot conflict-stats`.
+func PrintConflictStats(opts ConflictStatsOptions) error {
+	out, err := GetConflictStats(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
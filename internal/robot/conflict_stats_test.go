@@ -0,0 +1,46 @@
+package robot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func TestGetConflictStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conflicts.jsonl")
+	history := tracker.NewConflictHistory(path)
+	for _, rec := range []tracker.ConflictRecord{
+		{Source: tracker.ConflictSourceReservation, Session: "proj", Path: "a.go", Agents: []string{"Blue", "Red"}},
+		{Source: tracker.ConflictSourceReservation, Session: "proj", Path: "b.go", Agents: []string{"Blue", "Red"}},
+		{Source: tracker.ConflictSourceReservation, Session: "other", Path: "c.go"},
+	} {
+		if _, err := history.RecordDetected(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := history.RecordResolved(tracker.ConflictSourceReservation, "proj", "a.go", tracker.ConflictOutcomeRequest); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GetConflictStats(ConflictStatsOptions{HistoryPath: path, Session: "proj", Since: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success {
+		t.Fatalf("expected success, got %+v", out.RobotResponse)
+	}
+	if out.Episodes != 2 || out.Open != 1 || out.Resolved != 1 {
+		t.Errorf("counts = %d/%d/%d, want 2/1/1", out.Episodes, out.Open, out.Resolved)
+	}
+	if len(out.OpenConflicts) != 1 || out.OpenConflicts[0].Path != "b.go" {
+		t.Errorf("OpenConflicts = %+v, want b.go", out.OpenConflicts)
+	}
+	if len(out.AgentPairs) != 1 || out.AgentPairs[0].Count != 2 {
+		t.Errorf("AgentPairs = %+v, want Blue/Red x2", out.AgentPairs)
+	}
+	if len(out.FilePairs) != 1 {
+		t.Errorf("FilePairs = %+v, want a.go/b.go", out.FilePairs)
+	}
+}
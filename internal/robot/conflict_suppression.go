@@ -0,0 +1,131 @@
+// Package robot provides machine-readable output for AI agents and automation.
+// conflict_suppression.go mutes known conflicts: suppression rules for noisy
+// paths and acknowledgement of individual conflicts.
+package robot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+// ConflictSuppression mutes conflicts on paths matching Path (a pattern as
+// for reservations) until Expires. Muted conflicts are still detected and
+// recorded in the conflict history, but do not alert.
+type ConflictSuppression struct {
+	Path    string    `json:"path"`
+	Reason  string    `json:"reason"`
+	Expires time.Time `json:"expires,omitzero"` // Zero never expires
+}
+
+// Expired reports whether the rule no longer applies at now.
+func (s ConflictSuppression) Expired(now time.Time) bool {
+	return !s.Expires.IsZero() && !now.Before(s.Expires)
+}
+
+// ConflictAck records the acknowledgement of a conflict. It holds until
+// the conflict clears.
+type ConflictAck struct {
+	At   time.Time `json:"at"`
+	Note string    `json:"note,omitempty"`
+}
+
+// ConflictAckOptions configures `ntm conflicts ack`.
+type ConflictAckOptions struct {
+	// ID is the conflict identifier shown by `ntm robot conflicts`.
+	ID   string
+	Note string
+	// Conflicts locates the conflict when it has no open episode in the
+	// history yet; its History receives the acknowledgement.
+	Conflicts ConflictsOptions
+}
+
+// ConflictAckOutput is the response for `ntm conflicts ack`.
+type ConflictAckOutput struct {
+	RobotResponse
+	ID   string `json:"id"`
+	Path string `json:"path,omitempty"`
+	Note string `json:"note,omitempty"`
+	// Episodes is the number of open conflict episodes acknowledged.
+	Episodes int `json:"episodes"`
+}
+
+// AckConflict acknowledges the conflict with the given ID so it stops
+// alerting. The acknowledgement is recorded in the conflict history on the
+// conflict's open episodes; a conflict only seen by one-shot checks is
+// detected again and recorded first.
+func AckConflict(opts ConflictAckOptions) (*ConflictAckOutput, error) {
+	out := &ConflictAckOutput{RobotResponse: NewRobotResponse(true), ID: opts.ID, Note: opts.Note}
+	history := opts.Conflicts.History
+	if opts.ID == "" || history == nil {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("conflict id is required"), ErrCodeInvalidFlag,
+			"Pass an id from 'ntm robot conflicts'")
+		return out, nil
+	}
+
+	n, err := history.Acknowledge(opts.ID, opts.Note)
+	if err != nil {
+		return nil, fmt.Errorf("acknowledging conflict: %w", err)
+	}
+	if n == 0 {
+		cw, err := newConflictWatch(opts.Conflicts)
+		if err != nil {
+			out.RobotResponse = NewErrorResponse(err, ErrCodeInvalidFlag, "Run inside a git repository or pass --repo")
+			return out, nil
+		}
+		ev := cw.check(context.Background())
+		var found *DetectedConflict
+		for _, c := range append(ev.Conflicts, ev.Muted...) {
+			if c.ID == opts.ID {
+				found = &c
+				break
+			}
+		}
+		if found == nil {
+			out.RobotResponse = NewErrorResponse(fmt.Errorf("no current conflict with id %s", opts.ID), ErrCodeInvalidFlag,
+				"Run 'ntm robot conflicts' to list current conflicts")
+			return out, nil
+		}
+		if _, err := history.RecordDetected(cw.historyRecord(*found)); err != nil {
+			return nil, fmt.Errorf("recording conflict: %w", err)
+		}
+		if n, err = history.Acknowledge(opts.ID, opts.Note); err != nil {
+			return nil, fmt.Errorf("acknowledging conflict: %w", err)
+		}
+	}
+	out.Episodes = n
+
+	episodes, err := history.Episodes(time.Time{})
+	if err != nil {
+		slog.Debug("reading conflict history", "error", err)
+	}
+	for _, ep := range episodes {
+		if ep.Open() && ep.ID() == opts.ID {
+			out.Path = ep.Path
+		}
+	}
+	return out, nil
+}
+
+// conflictAcks returns the acknowledgements of open conflict episodes by
+// conflict ID.
+func conflictAcks(history *tracker.ConflictHistory) map[string]*ConflictAck {
+	if history == nil {
+		return nil
+	}
+	episodes, err := history.Episodes(time.Time{})
+	if err != nil {
+		slog.Debug("reading conflict acknowledgements", "error", err)
+		return nil
+	}
+	acks := make(map[string]*ConflictAck)
+	for _, ep := range episodes {
+		if ep.Open() && ep.Acknowledged() {
+			acks[ep.ID()] = &ConflictAck{At: *ep.AckedAt, Note: ep.AckNote}
+		}
+	}
+	return acks
+}
@@ -0,0 +1,117 @@
+package robot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func TestConflictSuppressionAndAck(t *testing.T) {
+	dir := initConflictsRepo(t)
+	writeRepoFile(t, dir, "main.go", "package main\n")
+	writeRepoFile(t, dir, "go.sum", "sum\n")
+	writeRepoFile(t, dir, "gen/api.go", "package gen\n")
+	history := tracker.NewConflictHistory(filepath.Join(t.TempDir(), "conflicts.jsonl"))
+	opts := ConflictsOptions{
+		RepoPath: dir,
+		History:  history,
+		Suppress: []ConflictSuppression{
+			{Path: "go.sum", Reason: "lockfile churn"},
+			{Path: "gen/**", Reason: "codegen", Expires: time.Now().Add(-time.Hour)},
+		},
+	}
+
+	out, err := GetConflicts(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Conflicts) != 2 || out.Conflicts[0].Path != "gen/api.go" || out.Conflicts[1].Path != "main.go" {
+		t.Fatalf("conflicts = %+v", out.Conflicts)
+	}
+	if len(out.Muted) != 1 || out.Muted[0].Path != "go.sum" || out.Muted[0].Suppressed != "lockfile churn" {
+		t.Fatalf("muted = %+v", out.Muted)
+	}
+	mainID := out.Conflicts[1].ID
+	if mainID != tracker.ConflictID(filepath.Join(out.RepoPath, "main.go")) {
+		t.Errorf("ID = %q", mainID)
+	}
+
+	// A conflict seen only by one-shot checks is recorded, then acknowledged.
+	ack, err := AckConflict(ConflictAckOptions{ID: mainID, Note: "intentional", Conflicts: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ack.Success || ack.Episodes != 1 || ack.Path != filepath.Join(out.RepoPath, "main.go") {
+		t.Fatalf("ack = %+v", ack)
+	}
+	if bad, _ := AckConflict(ConflictAckOptions{ID: "cf-00000000", Conflicts: opts}); bad.Success {
+		t.Error("acknowledging an unknown id should fail")
+	}
+
+	out, _ = GetConflicts(context.Background(), opts)
+	if len(out.Conflicts) != 1 || len(out.Muted) != 2 || out.Muted[1].Ack == nil || out.Muted[1].Ack.Note != "intentional" {
+		t.Fatalf("after ack: conflicts = %+v, muted = %+v", out.Conflicts, out.Muted)
+	}
+}
+
+func TestConflictWatchMutedConflicts(t *testing.T) {
+	dir := initConflictsRepo(t)
+	history := tracker.NewConflictHistory(filepath.Join(t.TempDir(), "conflicts.jsonl"))
+	cw, err := newConflictWatch(ConflictsOptions{
+		RepoPath: dir,
+		History:  history,
+		Suppress: []ConflictSuppression{{Path: "go.sum", Reason: "lockfile churn"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cw.recordHistory(cw.check(context.Background()), true)
+
+	writeRepoFile(t, dir, "go.sum", "sum\n")
+	writeRepoFile(t, dir, "main.go", "package main\n")
+	ev := cw.check(context.Background())
+	if ev == nil || len(ev.Added) != 1 || ev.Added[0] != "main.go" || len(ev.Muted) != 1 {
+		t.Fatalf("event = %+v, want only main.go added", ev)
+	}
+	cw.recordHistory(ev, false)
+
+	// Muted conflicts stay in the history.
+	episodes, _ := history.Episodes(time.Time{})
+	if len(episodes) != 2 {
+		t.Fatalf("episodes = %+v, want go.sum and main.go", episodes)
+	}
+
+	// Acknowledging moves the conflict to muted without adding anything.
+	if n, err := history.Acknowledge(ev.Conflicts[0].ID, "intentional"); err != nil || n != 1 {
+		t.Fatalf("Acknowledge = %d, %v", n, err)
+	}
+	ev = cw.check(context.Background())
+	if ev == nil || len(ev.Added) != 0 || len(ev.Conflicts) != 0 || len(ev.Muted) != 2 {
+		t.Fatalf("event after ack = %+v", ev)
+	}
+	writeRepoFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	if ev := cw.check(context.Background()); ev != nil {
+		t.Fatalf("changes to muted conflicts should not emit, got %+v", ev)
+	}
+}
+
+func TestGetConflicts_Cancelled(t *testing.T) {
+	dir := initConflictsRepo(t)
+	writeRepoFile(t, dir, "main.go", "package main\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewConflictDetector(&ConflictDetectorConfig{RepoPath: dir}).GetGitStatus(ctx); err == nil {
+		t.Error("GetGitStatus with a cancelled context: want error")
+	}
+	out, err := GetConflicts(ctx, ConflictsOptions{RepoPath: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Success || len(out.Conflicts) != 0 {
+		t.Errorf("cancelled check = %+v, want an error response", out)
+	}
+}
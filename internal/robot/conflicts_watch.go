@@ -0,0 +1,493 @@
+// Package robot provides machine-readable output for AI agents.
+// conflicts_watch.go implements `ntm robot conflicts`, including the watch
+// mode that re-runs conflict detection when the repository changes.
+package robot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+	"github.com/Dicklesworthstone/ntm/internal/watcher"
+)
+
+// DefaultConflictsDebounce coalesces bursts of file events (a checkout or an
+// agent writing many files) into a single conflict check.
+const DefaultConflictsDebounce = 500 * time.Millisecond
+
+// conflictsActivityLookback is how far back the first check attributes pane
+// output, matching the --robot-diff default window.
+const conflictsActivityLookback = 15 * time.Minute
+
+// conflictsTrackInterval is how often watch mode observes pane status.
+const conflictsTrackInterval = 5 * time.Second
+
+// conflictsIgnoreDirs are worktree directories not worth watching.
+var conflictsIgnoreDirs = []string{
+	".git",
+	"node_modules",
+	"__pycache__",
+	".venv",
+	"target",
+	"dist",
+	"build",
+	"vendor",
+}
+
+// ConflictsOptions configures `ntm robot conflicts`.
+type ConflictsOptions struct {
+	// RepoPath is any path inside the repository (default: working directory).
+	RepoPath string
+	// Session, if set, attributes changes to panes of this tmux session that
+	// produced output around the time a file was modified.
+	Session string
+	// Debounce is the quiet period before re-checking in watch mode.
+	Debounce time.Duration
+	// Output receives NDJSON events in watch mode (default: stdout).
+	Output io.Writer
+	// History, if set, records conflicts and their clearing in watch mode.
+	// Its acknowledgements mute conflicts in either mode.
+	History *tracker.ConflictHistory
+	// Exclude lists path patterns left out of conflict analysis.
+	Exclude []string
+	// IncludeIgnored analyzes changed files matching .gitignore rules.
+	IncludeIgnored bool
+	// Repos lists more repository roots of a multi-repo workspace.
+	Repos []string
+	// SkipSubmodules reports changed submodules as single entries instead
+	// of scanning their files.
+	SkipSubmodules bool
+	// Scoring weights conflicts and sets the reporting threshold; unset
+	// values come from DefaultConflictScoring.
+	Scoring ConflictScoring
+	// Suppress mutes conflicts on matching paths until each rule expires.
+	Suppress []ConflictSuppression
+	// OnConflict, if set, is called in watch mode for each event that adds
+	// conflicts.
+	OnConflict func(*ConflictEvent)
+}
+
+// ConflictsOutput is the response for a one-shot conflict check.
+type ConflictsOutput struct {
+	RobotResponse
+	RepoPath  string             `json:"repo_path"`
+	Session   string             `json:"session,omitempty"`
+	Repos     []WorkspaceRepo    `json:"repos,omitempty"` // Set when the workspace has more than one repository
+	Scoring   *ConflictScoring   `json:"scoring,omitempty"`
+	Conflicts []DetectedConflict `json:"conflicts"`
+	Muted     []DetectedConflict `json:"muted,omitempty"` // Suppressed or acknowledged
+}
+
+// ConflictEvent is one NDJSON line in watch mode. It is emitted for the
+// initial state and then only when the conflict set changes. Muted
+// conflicts are never added; changes to them do not emit events.
+type ConflictEvent struct {
+	Type      string             `json:"type"` // "conflicts" or "error"
+	Timestamp string             `json:"timestamp"`
+	RepoPath  string             `json:"repo_path"`
+	Conflicts []DetectedConflict `json:"conflicts"`
+	Muted     []DetectedConflict `json:"muted,omitempty"` // Suppressed or acknowledged
+	Added     []string           `json:"added,omitempty"`
+	Resolved  []string           `json:"resolved,omitempty"`
+	Scoring   *ConflictScoring   `json:"scoring,omitempty"` // Set on the first event
+	Error     string             `json:"error,omitempty"`
+
+	detected []string // Paths newly present, muted or not, for the history
+}
+
+// GetConflicts runs a single conflict check, which stops when ctx is done.
+func GetConflicts(ctx context.Context, opts ConflictsOptions) (*ConflictsOutput, error) {
+	cw, err := newConflictWatch(opts)
+	if err != nil {
+		return &ConflictsOutput{
+			RobotResponse: NewErrorResponse(err, ErrCodeInvalidFlag, "Run inside a git repository or pass --repo"),
+			RepoPath:      opts.RepoPath,
+			Conflicts:     []DetectedConflict{},
+		}, nil
+	}
+
+	ev := cw.check(ctx)
+	out := &ConflictsOutput{
+		RobotResponse: NewRobotResponse(true),
+		RepoPath:      cw.repoPath,
+		Session:       opts.Session,
+		Conflicts:     ev.Conflicts,
+		Muted:         ev.Muted,
+	}
+	scoring := cw.detector.Scoring()
+	out.Scoring = &scoring
+	if repos := cw.detector.Repos(); len(repos) > 1 {
+		out.Repos = repos
+	}
+	if ev.Error != "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("%s", ev.Error), ErrCodeInternalError, "Check that git is installed and the repository is readable")
+	}
+	return out, nil
+}
+
+// PrintConflicts handles `ntm robot conflicts`.
+func PrintConflicts(ctx context.Context, opts ConflictsOptions) error {
+	out, err := GetConflicts(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// WatchConflicts watches the worktree and git directory and writes a
+// ConflictEvent whenever the detected conflict set changes. It blocks until
+// ctx is cancelled.
+func WatchConflicts(ctx context.Context, opts ConflictsOptions) error {
+	cw, err := newConflictWatch(opts)
+	if err != nil {
+		return err
+	}
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultConflictsDebounce
+	}
+
+	trigger := make(chan struct{}, 1)
+	notify := func([]watcher.Event) {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+	onError := func(err error) {
+		emitConflictEvent(out, &ConflictEvent{
+			Type:      "error",
+			Timestamp: FormatTimestamp(time.Now()),
+			RepoPath:  cw.repoPath,
+			Conflicts: []DetectedConflict{},
+			Error:     err.Error(),
+		})
+	}
+	filter := watcher.WithEventFilter(watcher.Write | watcher.Create | watcher.Remove | watcher.Rename)
+
+	// The worktree is watched recursively; the git directory only at its top
+	// level, where index, HEAD, and their lock files are replaced.
+	worktree, err := watcher.New(notify,
+		watcher.WithDebounceDuration(debounce),
+		watcher.WithRecursive(true),
+		watcher.WithIgnorePaths(conflictsIgnoreDirs),
+		watcher.WithErrorHandler(onError),
+		filter,
+	)
+	if err != nil {
+		return fmt.Errorf("creating worktree watcher: %w", err)
+	}
+	defer worktree.Close()
+	if err := worktree.Add(cw.repoPath); err != nil {
+		return fmt.Errorf("watching %s: %w", cw.repoPath, err)
+	}
+	repos := cw.detector.Repos()
+	for _, repo := range repos[1:] {
+		// Repositories inside the primary one are already watched.
+		if repo.Prefix != "" {
+			continue
+		}
+		if err := worktree.Add(repo.Path); err != nil {
+			return fmt.Errorf("watching %s: %w", repo.Path, err)
+		}
+	}
+
+	gitDir, err := watcher.New(notify,
+		watcher.WithDebounceDuration(debounce),
+		watcher.WithErrorHandler(onError),
+		filter,
+	)
+	if err != nil {
+		return fmt.Errorf("creating git watcher: %w", err)
+	}
+	defer gitDir.Close()
+	if err := gitDir.Add(cw.gitDir); err != nil {
+		return fmt.Errorf("watching %s: %w", cw.gitDir, err)
+	}
+	for _, repo := range repos[1:] {
+		dir, err := gitRevParse(repo.Path, "--absolute-git-dir")
+		if err != nil {
+			return fmt.Errorf("locating git directory of %s: %w", repo.Path, err)
+		}
+		if err := gitDir.Add(dir); err != nil {
+			return fmt.Errorf("watching %s: %w", dir, err)
+		}
+	}
+
+	// Observe pane status between checks too, so a pane that worked and
+	// went idle before the next file change still has its window.
+	if opts.Session != "" {
+		go cw.detector.Track(ctx, conflictsTrackInterval)
+	}
+
+	if ev := cw.check(ctx); ev != nil {
+		cw.recordHistory(ev, true)
+		scoring := cw.detector.Scoring()
+		ev.Scoring = &scoring
+		emitConflictEvent(out, ev)
+		notifyConflict(opts.OnConflict, ev)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-trigger:
+			if ev := cw.check(ctx); ev != nil {
+				cw.recordHistory(ev, false)
+				emitConflictEvent(out, ev)
+				notifyConflict(opts.OnConflict, ev)
+			}
+		}
+	}
+}
+
+func emitConflictEvent(out io.Writer, ev *ConflictEvent) {
+	data, _ := json.Marshal(ev)
+	fmt.Fprintln(out, string(data))
+}
+
+func notifyConflict(fn func(*ConflictEvent), ev *ConflictEvent) {
+	if fn != nil && ev.Type == "conflicts" && len(ev.Added) > 0 {
+		fn(ev)
+	}
+}
+
+// conflictWatch holds the state carried between conflict checks.
+type conflictWatch struct {
+	repoPath string
+	gitDir   string
+	session  string
+	detector *ConflictDetector
+	seen     map[string]string // path -> conflict signature, or conflictMutedSignature
+	lastErr  string
+	checked  bool
+	history  *tracker.ConflictHistory
+}
+
+func newConflictWatch(opts ConflictsOptions) (*conflictWatch, error) {
+	repo := opts.RepoPath
+	if repo == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		repo = wd
+	}
+	top, err := gitRevParse(repo, "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not in a git repository: %w", repo, err)
+	}
+	gitDir, err := gitRevParse(repo, "--absolute-git-dir")
+	if err != nil {
+		return nil, fmt.Errorf("locating git directory: %w", err)
+	}
+
+	return &conflictWatch{
+		repoPath: top,
+		gitDir:   gitDir,
+		session:  opts.Session,
+		detector: NewConflictDetector(&ConflictDetectorConfig{
+			RepoPath:       top,
+			Exclude:        opts.Exclude,
+			IncludeIgnored: opts.IncludeIgnored,
+			Repos:          opts.Repos,
+			SkipSubmodules: opts.SkipSubmodules,
+			Scoring:        opts.Scoring,
+			Suppress:       opts.Suppress,
+			// Panes' activity windows come from status detection.
+			Session:          opts.Session,
+			ActivityLookback: conflictsActivityLookback,
+		}),
+		seen:    make(map[string]string),
+		history: opts.History,
+	}, nil
+}
+
+func gitRevParse(dir, flag string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", flag).Output()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(strings.TrimSpace(string(out))), nil
+}
+
+// conflictMutedSignature stands for any muted conflict, so changes to one
+// are not re-reported.
+const conflictMutedSignature = "muted"
+
+// check records the panes' tool calls, re-runs conflict detection (which
+// observes the panes' status), and returns an event if the result differs from the last one
+// (always on the first call). It returns nil when nothing changed.
+func (cw *conflictWatch) check(ctx context.Context) *ConflictEvent {
+	now := time.Now()
+	cw.recordToolCalls()
+
+	detected, err := cw.detector.DetectConflicts(ctx)
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	acks := conflictAcks(cw.history)
+	conflicts := []DetectedConflict{}
+	var muted []DetectedConflict
+	current := make(map[string]string, len(detected))
+	for _, c := range detected {
+		c.Ack = acks[c.ID]
+		if c.Muted() {
+			muted = append(muted, c)
+			current[c.Path] = conflictMutedSignature
+			continue
+		}
+		conflicts = append(conflicts, c)
+		current[c.Path] = conflictSignature(c)
+	}
+
+	var added, resolved, fresh []string
+	for path, sig := range current {
+		prev, seen := cw.seen[path]
+		if !seen {
+			fresh = append(fresh, path)
+		}
+		if sig != conflictMutedSignature && prev != sig {
+			added = append(added, path)
+		}
+	}
+	for path := range cw.seen {
+		if _, ok := current[path]; !ok {
+			resolved = append(resolved, path)
+		}
+	}
+	if cw.checked && len(added) == 0 && len(resolved) == 0 && errMsg == cw.lastErr && maps.Equal(current, cw.seen) {
+		return nil
+	}
+	cw.checked = true
+	cw.seen = current
+	cw.lastErr = errMsg
+
+	sort.Strings(added)
+	sort.Strings(resolved)
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Path < conflicts[j].Path })
+	sort.Slice(muted, func(i, j int) bool { return muted[i].Path < muted[j].Path })
+	ev := &ConflictEvent{
+		Type:      "conflicts",
+		Timestamp: FormatTimestamp(now),
+		RepoPath:  cw.repoPath,
+		Conflicts: conflicts,
+		Muted:     muted,
+		Added:     added,
+		Resolved:  resolved,
+		Error:     errMsg,
+		detected:  fresh,
+	}
+	if errMsg != "" && len(conflicts) == 0 {
+		ev.Type = "error"
+	}
+	return ev
+}
+
+// recordHistory persists the conflicts an event added and resolved. On the
+// first event it also clears episodes left open in this repository by an
+// earlier watch that are no longer present.
+func (cw *conflictWatch) recordHistory(ev *ConflictEvent, first bool) {
+	if cw.history == nil || ev.Type != "conflicts" {
+		return
+	}
+	all := append(append([]DetectedConflict(nil), ev.Conflicts...), ev.Muted...)
+	current := make(map[string]bool, len(all))
+	for _, c := range all {
+		current[cw.detector.AbsPath(c.Path)] = true
+	}
+	resolved := make(map[string]bool, len(ev.Resolved))
+	for _, p := range ev.Resolved {
+		resolved[cw.detector.AbsPath(p)] = true
+	}
+	added := make(map[string]bool, len(ev.Added)+len(ev.detected))
+	for _, p := range append(ev.Added, ev.detected...) {
+		added[p] = true
+	}
+
+	// Muted conflicts are recorded too; they only stop alerting.
+	for _, c := range all {
+		if !added[c.Path] {
+			continue
+		}
+		if _, err := cw.history.RecordDetected(cw.historyRecord(c)); err != nil {
+			slog.Debug("recording conflict", "path", c.Path, "error", err)
+		}
+	}
+
+	repos := cw.detector.Repos()
+	_, err := cw.history.ResolveWhere(func(ep tracker.ConflictEpisode) bool {
+		if ep.Source != tracker.ConflictSourceGit || ep.Session != cw.session {
+			return false
+		}
+		if _, _, ok := repoFor(repos, ep.Path); !ok {
+			return false
+		}
+		if first {
+			return !current[ep.Path]
+		}
+		return resolved[ep.Path]
+	}, tracker.ConflictOutcomeCleared)
+	if err != nil {
+		slog.Debug("recording conflict resolution", "error", err)
+	}
+}
+
+// historyRecord returns the conflict history record of a detected conflict.
+func (cw *conflictWatch) historyRecord(c DetectedConflict) tracker.ConflictRecord {
+	return tracker.ConflictRecord{
+		Source:  tracker.ConflictSourceGit,
+		Session: cw.session,
+		Path:    cw.detector.AbsPath(c.Path),
+		Agents:  append(append([]string(nil), c.LikelyModifiers...), c.ReservationHolders...),
+		Reason:  string(c.Reason),
+	}
+}
+
+// conflictSignature identifies what makes a conflict worth re-reporting:
+// its reason and who is involved, not its confidence or timestamps.
+func conflictSignature(c DetectedConflict) string {
+	modifiers := append([]string(nil), c.LikelyModifiers...)
+	holders := append([]string(nil), c.ReservationHolders...)
+	sort.Strings(modifiers)
+	sort.Strings(holders)
+	return string(c.Reason) + "|" + c.GitStatus + "|" + strings.Join(modifiers, ",") + "|" + strings.Join(holders, ",")
+}
+
+// recordToolCalls attributes the files named by edits in each pane's
+// output to that pane. Activity windows come from status detection when
+// the detector runs.
+func (cw *conflictWatch) recordToolCalls() {
+	if cw.session == "" || !tmux.SessionExists(cw.session) {
+		return
+	}
+	panes, err := tmux.GetPanes(cw.session)
+	if err != nil {
+		return
+	}
+	for _, pane := range panes {
+		captured, err := tmux.CapturePaneOutput(pane.ID, 100)
+		if err != nil {
+			continue
+		}
+		cw.detector.RecordToolCalls(pane.ID, agent.ParseToolCalls(agent.AgentType(pane.Type), captured))
+	}
+}
@@ -0,0 +1,236 @@
+package robot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func initConflictsRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "test"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestConflictWatchCheckReportsOnlyChanges(t *testing.T) {
+	dir := initConflictsRepo(t)
+	cw, err := newConflictWatch(ConflictsOptions{RepoPath: dir})
+	if err != nil {
+		t.Fatalf("newConflictWatch: %v", err)
+	}
+
+	ev := cw.check(context.Background())
+	if ev == nil || len(ev.Conflicts) != 0 {
+		t.Fatalf("first check should report the empty initial state, got %+v", ev)
+	}
+	if ev := cw.check(context.Background()); ev != nil {
+		t.Fatalf("unchanged repo should not emit, got %+v", ev)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ev = cw.check(context.Background())
+	if ev == nil || len(ev.Added) != 1 || ev.Added[0] != "main.go" {
+		t.Fatalf("expected main.go to be added, got %+v", ev)
+	}
+
+	// Editing the same file again leaves the conflict set unchanged.
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ev := cw.check(context.Background()); ev != nil {
+		t.Fatalf("same conflict set should not emit, got %+v", ev)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	ev = cw.check(context.Background())
+	if ev == nil || len(ev.Resolved) != 1 || ev.Resolved[0] != "main.go" || len(ev.Conflicts) != 0 {
+		t.Fatalf("expected main.go to be resolved, got %+v", ev)
+	}
+}
+
+func TestConflictWatchRecordsHistory(t *testing.T) {
+	dir := initConflictsRepo(t)
+	history := tracker.NewConflictHistory(filepath.Join(t.TempDir(), "conflicts.jsonl"))
+	cw, err := newConflictWatch(ConflictsOptions{RepoPath: dir, History: history})
+	if err != nil {
+		t.Fatalf("newConflictWatch: %v", err)
+	}
+	cw.recordHistory(cw.check(context.Background()), true)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cw.recordHistory(cw.check(context.Background()), false)
+	episodes, err := history.Episodes(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 1 || !episodes[0].Open() || episodes[0].Path != filepath.Join(cw.repoPath, "main.go") {
+		t.Fatalf("episodes = %+v, want one open main.go episode", episodes)
+	}
+
+	// A new watch that no longer sees the conflict clears the stale episode.
+	if err := os.Remove(filepath.Join(dir, "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	next, err := newConflictWatch(ConflictsOptions{RepoPath: dir, History: history})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next.recordHistory(next.check(context.Background()), true)
+	episodes, _ = history.Episodes(time.Time{})
+	if len(episodes) != 1 || episodes[0].Outcome != tracker.ConflictOutcomeCleared {
+		t.Fatalf("episodes = %+v, want main.go cleared", episodes)
+	}
+}
+
+func TestNewConflictWatchRequiresRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if _, err := newConflictWatch(ConflictsOptions{RepoPath: t.TempDir()}); err == nil {
+		t.Fatal("expected error outside a git repository")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for a writer and a polling reader.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) events(t *testing.T) []ConflictEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var events []ConflictEvent
+	sc := bufio.NewScanner(strings.NewReader(b.buf.String()))
+	for sc.Scan() {
+		var ev ConflictEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", sc.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestWatchConflictsEmitsOnChange(t *testing.T) {
+	dir := initConflictsRepo(t)
+	out := &syncBuffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchConflicts(ctx, ConflictsOptions{RepoPath: dir, Debounce: 50 * time.Millisecond, Output: out})
+	}()
+
+	waitFor := func(n int) []ConflictEvent {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if events := out.events(t); len(events) >= n {
+				return events
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %d events, got %+v", n, out.events(t))
+		return nil
+	}
+
+	waitFor(1)
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	events := waitFor(2)
+	if got := events[1]; got.Type != "conflicts" || len(got.Added) != 1 || got.Added[0] != "notes.txt" {
+		t.Fatalf("unexpected event: %+v", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("WatchConflicts: %v", err)
+	}
+}
+
+func TestGetGitStatusFiltersPaths(t *testing.T) {
+	dir := initConflictsRepo(t)
+	write := func(name, content string) {
+		t.Helper()
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "build/\n")
+	write("build/out.bin", "v1")
+	if out, err := exec.Command("git", "-C", dir, "add", "-f", ".gitignore", "build/out.bin").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+	if out, err := exec.Command("git", "-C", dir, "commit", "-qm", "init").CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+
+	write("build/out.bin", "v2")     // Tracked, but ignored
+	write("pkg/a/a.go", "package a") // In an untracked directory
+	write("pkg/b/b.go", "package b")
+	write("gen/api.pb.go", "package gen")
+
+	paths := func(cfg *ConflictDetectorConfig) []string {
+		t.Helper()
+		cfg.RepoPath = dir
+		files, err := NewConflictDetector(cfg).GetGitStatus(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, f.Path)
+		}
+		return got
+	}
+
+	if got := paths(&ConflictDetectorConfig{}); !slices.Equal(got, []string{"gen/api.pb.go", "pkg/a/a.go", "pkg/b/b.go"}) {
+		t.Errorf("default = %v", got)
+	}
+	if got := paths(&ConflictDetectorConfig{Exclude: []string{"gen/**"}}); !slices.Equal(got, []string{"pkg/a/a.go", "pkg/b/b.go"}) {
+		t.Errorf("excluding gen = %v", got)
+	}
+	if got := paths(&ConflictDetectorConfig{IncludeIgnored: true}); !slices.Contains(got, "build/out.bin") {
+		t.Errorf("include ignored = %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+// Package robot provides machine-readable output for AI agents.
+// crashes.go implements `ntm robot crashes` and `ntm robot crash`.
+package robot
+
+import (
+	"fmt"
+
+	"github.com/Dicklesworthstone/ntm/internal/crashbundle"
+)
+
+// CrashesOptions configures `ntm robot crashes`.
+type CrashesOptions struct {
+	ProjectDir string
+	// Session limits the listing to one session.
+	Session string
+	// Limit keeps only the most recent N bundles (0 = all).
+	Limit int
+}
+
+// CrashesOutput is the response for `ntm robot crashes`.
+type CrashesOutput struct {
+	RobotResponse
+	Dir     string                 `json:"dir"`
+	Session string                 `json:"session,omitempty"`
+	Count   int                    `json:"count"`
+	Crashes []crashbundle.Manifest `json:"crashes"` // Newest first
+}
+
+// GetCrashes lists the crash bundles saved for a project.
+func GetCrashes(opts CrashesOptions) (*CrashesOutput, error) {
+	out := &CrashesOutput{
+		RobotResponse: NewRobotResponse(true),
+		Dir:           crashbundle.Dir(opts.ProjectDir),
+		Session:       opts.Session,
+		Crashes:       []crashbundle.Manifest{},
+	}
+	manifests, err := crashbundle.List(opts.ProjectDir)
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that .ntm/crashes is readable")
+		return out, nil
+	}
+	for _, m := range manifests {
+		if opts.Session != "" && m.Session != opts.Session {
+			continue
+		}
+		out.Crashes = append(out.Crashes, m)
+		if opts.Limit > 0 && len(out.Crashes) == opts.Limit {
+			break
+		}
+	}
+	out.Count = len(out.Crashes)
+	return out, nil
+}
+
+// PrintCrashes handles `ntm robot crashes`.
+func PrintCrashes(opts CrashesOptions) error {
+	out, err := GetCrashes(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// CrashOutput is the response for `ntm robot crash`: one bundle's manifest
+// and contents.
+type CrashOutput struct {
+	RobotResponse
+	Crash *crashbundle.Bundle `json:"crash,omitempty"`
+}
+
+// GetCrash loads one crash bundle by ID.
+func GetCrash(projectDir, id string) (*CrashOutput, error) {
+	b, err := crashbundle.Load(projectDir, id)
+	if err != nil {
+		return &CrashOutput{
+			RobotResponse: NewErrorResponse(err, ErrCodeInvalidFlag,
+				fmt.Sprintf("List bundles with: ntm robot crashes (looked in %s)", crashbundle.Dir(projectDir))),
+		}, nil
+	}
+	return &CrashOutput{RobotResponse: NewRobotResponse(true), Crash: b}, nil
+}
+
+// PrintCrash handles `ntm robot crash`.
+func PrintCrash(projectDir, id string) error {
+	out, err := GetCrash(projectDir, id)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
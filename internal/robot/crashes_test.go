@@ -0,0 +1,59 @@
+package robot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/crashbundle"
+)
+
+func writeCrashManifest(t *testing.T, project string, m crashbundle.Manifest) {
+	t.Helper()
+	dir := filepath.Join(crashbundle.Dir(project), m.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(m)
+	if err := os.WriteFile(filepath.Join(dir, crashbundle.ManifestFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetCrashes(t *testing.T) {
+	project := t.TempDir()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	writeCrashManifest(t, project, crashbundle.Manifest{ID: "a", Session: "proj", CrashedAt: base})
+	writeCrashManifest(t, project, crashbundle.Manifest{ID: "b", Session: "other", CrashedAt: base.Add(time.Minute)})
+	writeCrashManifest(t, project, crashbundle.Manifest{ID: "c", Session: "proj", CrashedAt: base.Add(2 * time.Minute)})
+
+	out, err := GetCrashes(CrashesOptions{ProjectDir: project, Session: "proj"})
+	if err != nil || !out.Success {
+		t.Fatalf("GetCrashes() = %+v, %v", out, err)
+	}
+	if out.Count != 2 || out.Crashes[0].ID != "c" || out.Crashes[1].ID != "a" {
+		t.Errorf("crashes = %+v, want c then a", out.Crashes)
+	}
+
+	out, _ = GetCrashes(CrashesOptions{ProjectDir: project, Limit: 1})
+	if out.Count != 1 || out.Crashes[0].ID != "c" {
+		t.Errorf("limited crashes = %+v", out.Crashes)
+	}
+}
+
+func TestGetCrash(t *testing.T) {
+	project := t.TempDir()
+	writeCrashManifest(t, project, crashbundle.Manifest{ID: "a", Session: "proj"})
+
+	out, err := GetCrash(project, "a")
+	if err != nil || !out.Success || out.Crash == nil || out.Crash.Manifest.Session != "proj" {
+		t.Fatalf("GetCrash(a) = %+v, %v", out, err)
+	}
+
+	out, err = GetCrash(project, "missing")
+	if err != nil || out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("GetCrash(missing) = %+v, %v", out, err)
+	}
+}
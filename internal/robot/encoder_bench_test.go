@@ -0,0 +1,155 @@
+package robot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Baseline performance numbers (Intel Xeon, Go 1.25+), TOON with the pure
+// Go encoder:
+//
+// BenchmarkRender_FleetStatus/JSON                 225539 ns/op    156292 B/op        5 allocs/op
+// BenchmarkRender_FleetStatus/TOON                 582971 ns/op    326108 B/op     1186 allocs/op
+// BenchmarkRender_CaptureListing/JSON             1304564 ns/op    754871 B/op        6 allocs/op
+// BenchmarkRender_CaptureListing/TOON             3103810 ns/op   1275406 B/op    11558 allocs/op
+// BenchmarkRender_CaptureListing/TOON_Aligned     3382271 ns/op   1783486 B/op    11562 allocs/op
+//
+// A significant regression (>3x slowdown, or allocations past the budgets
+// in TestEncoderAllocationBudget) warrants investigation.
+
+// benchFleetStatus is a --robot-status response for a busy fleet: 10
+// sessions of 8 agents each, with the usual summary sections.
+func benchFleetStatus() StatusOutput {
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	types := []string{"claude", "codex", "gemini"}
+	out := StatusOutput{
+		RobotResponse: RobotResponse{Success: true, Timestamp: FormatTimestamp(at), Version: EnvelopeVersion},
+		GeneratedAt:   at,
+		System: SystemInfo{
+			Version: "1.8.0", Commit: "abc1234", BuildDate: "2026-04-30",
+			GoVersion: "go1.25", OS: "linux", Arch: "amd64", TmuxOK: true,
+		},
+	}
+	for s := 0; s < 10; s++ {
+		created := at.Add(-time.Duration(s) * time.Hour)
+		session := SessionInfo{
+			Name: fmt.Sprintf("project-%d", s), Exists: true, Attached: s%2 == 0,
+			Windows: 1, Panes: 8, CreatedAt: &created,
+		}
+		for p := 0; p < 8; p++ {
+			session.Agents = append(session.Agents, Agent{
+				Type:               types[p%len(types)],
+				Pane:               fmt.Sprintf("%%%d", s*8+p),
+				Name:               fmt.Sprintf("%s-%d", types[p%len(types)], p),
+				PaneIdx:            p,
+				IsActive:           p == 0,
+				PID:                1000 + p,
+				LastOutputTS:       at.Add(-time.Duration(p) * time.Minute),
+				SecondsSinceOutput: p * 60,
+				ProcessState:       "S",
+				ProcessStateName:   "sleeping",
+				MemoryMB:           300 + p,
+				ContextTokens:      40000 + p*1000,
+				ContextLimit:       200000,
+				ContextPercent:     20.5 + float64(p),
+			})
+		}
+		out.Sessions = append(out.Sessions, session)
+		out.Summary.TotalSessions++
+		out.Summary.TotalAgents += len(session.Agents)
+	}
+	return out
+}
+
+// benchCaptureListing is a listing of 1000 captured outputs, a quarter of
+// them with a file mention: the large uniform table TOON is for.
+func benchCaptureListing() struct {
+	RobotResponse
+	Captures []CapturedOutput `json:"captures"`
+} {
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	out := struct {
+		RobotResponse
+		Captures []CapturedOutput `json:"captures"`
+	}{RobotResponse: RobotResponse{Success: true, Timestamp: FormatTimestamp(at), Version: EnvelopeVersion}}
+	for i := 0; i < 1000; i++ {
+		c := CapturedOutput{
+			PaneID:    fmt.Sprintf("%%%d", i%16),
+			AgentType: "claude",
+			Timestamp: at.Add(time.Duration(i) * time.Second),
+			RawLength: 4096 + i,
+			Prompt:    fmt.Sprintf("fix the failing test in pkg%d", i%7),
+		}
+		if i%4 == 0 {
+			c.FilePaths = []FileMention{{Path: fmt.Sprintf("internal/pkg%d/file.go", i%7), Action: "modified", Confidence: 0.9}}
+		}
+		out.Captures = append(out.Captures, c)
+	}
+	return out
+}
+
+func benchmarkRender(b *testing.B, payload any, r Renderer) {
+	b.Helper()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Render(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// toonPureGoRenderer renders with the pure Go encoder whether or not
+// toon_rust is installed, so numbers compare across machines.
+type toonPureGoRenderer struct{ TOONRenderer }
+
+func (r *toonPureGoRenderer) Render(payload any) (string, error) {
+	return toonEncodeWithOptions(payload, r.Delimiter, r.Options)
+}
+
+func BenchmarkRender_FleetStatus(b *testing.B) {
+	payload := benchFleetStatus()
+	b.Run("JSON", func(b *testing.B) { benchmarkRender(b, payload, NewJSONRenderer()) })
+	b.Run("TOON", func(b *testing.B) {
+		benchmarkRender(b, payload, &toonPureGoRenderer{*NewTOONRenderer()})
+	})
+}
+
+func BenchmarkRender_CaptureListing(b *testing.B) {
+	payload := benchCaptureListing()
+	b.Run("JSON", func(b *testing.B) { benchmarkRender(b, payload, NewJSONRenderer()) })
+	b.Run("TOON", func(b *testing.B) {
+		benchmarkRender(b, payload, &toonPureGoRenderer{*NewTOONRenderer()})
+	})
+	b.Run("TOON_Aligned", func(b *testing.B) {
+		r := &toonPureGoRenderer{*NewTOONRenderer()}
+		r.Options.Align = true
+		benchmarkRender(b, payload, r)
+	})
+}
+
+// TestEncoderAllocationBudget keeps TOON encoding of large responses from
+// drifting back to per-cell reflection and JSON round trips.
+func TestEncoderAllocationBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload any
+		budget  float64
+	}{
+		{"fleet status", benchFleetStatus(), 1500},
+		{"1000-row capture listing", benchCaptureListing(), 15000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(5, func() {
+				if _, err := toonEncodePureGo(tt.payload, "\t"); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if allocs > tt.budget {
+				t.Errorf("TOON encode: %.0f allocs, budget %.0f", allocs, tt.budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package robot
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// =============================================================================
+// Error Taxonomy
+// =============================================================================
+// Every error code maps to a stable NTM-Exxx identifier, a category, the HTTP
+// status REST clients see, and a default remediation. Identifiers are grouped
+// by hundreds:
+//
+//	NTM-E1xx  request   the request itself is invalid or needs confirmation
+//	NTM-E2xx  not_found the named session, pane, bead, ... does not exist
+//	NTM-E3xx  environment a tool, permission, or feature is unavailable
+//	NTM-E4xx  transient contention, rate limits, timeouts; retrying can help
+//	NTM-E5xx  agent     an agent process misbehaved
+//	NTM-E6xx  integration an external service (beads, mail, cass, ...) failed
+//	NTM-E9xx  internal  a bug in ntm
+//
+// Identifiers are never reused or renumbered once published.
+
+// Error categories.
+const (
+	ErrCategoryRequest     = "request"
+	ErrCategoryNotFound    = "not_found"
+	ErrCategoryEnvironment = "environment"
+	ErrCategoryTransient   = "transient"
+	ErrCategoryAgent       = "agent"
+	ErrCategoryIntegration = "integration"
+	ErrCategoryInternal    = "internal"
+)
+
+// Remediation actions tell a client what kind of step resolves an error.
+const (
+	ActionFixRequest        = "fix_request"        // change the arguments and retry
+	ActionConfirm           = "confirm"            // repeat with the confirmation token
+	ActionCheckTarget       = "check_target"       // list what exists and pick a valid target
+	ActionInstallDependency = "install_dependency" // install or start the missing tool
+	ActionGrantAccess       = "grant_access"       // fix credentials or permissions
+	ActionRetry             = "retry"              // retry, possibly after a delay
+	ActionWait              = "wait"               // wait for the named condition, then retry
+	ActionRestartAgent      = "restart_agent"      // restart the affected agent pane
+	ActionReportBug         = "report_bug"         // not recoverable by the client
+)
+
+// UnknownErrorID identifies codes missing from the taxonomy.
+const UnknownErrorID = "NTM-E999"
+
+// ErrorInfo describes one error code in the taxonomy.
+type ErrorInfo struct {
+	Code       string `json:"code"`
+	ID         string `json:"id"`
+	Category   string `json:"category"`
+	HTTPStatus int    `json:"http_status"`
+	Action     string `json:"action"`
+	Retryable  bool   `json:"retryable"`
+	Guidance   string `json:"guidance"`
+}
+
+// Remediation is the machine-readable recovery guidance included in every
+// failure envelope.
+type Remediation struct {
+	// Action is one of the Action* constants.
+	Action string `json:"action"`
+	// Retryable reports whether repeating the same request can succeed.
+	Retryable bool `json:"retryable"`
+	// RetryAfterSeconds is the suggested wait before retrying, when known.
+	RetryAfterSeconds int `json:"retry_after_seconds,omitempty"`
+	// Guidance explains the remediation in a sentence.
+	Guidance string `json:"guidance"`
+}
+
+var (
+	errorTaxonomyMu sync.RWMutex
+	errorTaxonomy   = map[string]ErrorInfo{}
+)
+
+func init() {
+	RegisterErrorCodes(
+		// Request
+		ErrorInfo{ErrCodeInvalidFlag, "NTM-E100", ErrCategoryRequest, http.StatusBadRequest, ActionFixRequest, false, "Correct the flag or parameter value; see --help for accepted values"},
+		ErrorInfo{ErrCodeConfirmationRequired, "NTM-E101", ErrCategoryRequest, http.StatusPreconditionRequired, ActionConfirm, true, "Repeat the request with the confirmation token from the response"},
+		ErrorInfo{ErrCodeIdempotencyConflict, "NTM-E102", ErrCategoryRequest, http.StatusUnprocessableEntity, ActionFixRequest, false, "Use a new idempotency key for a different request"},
+		ErrorInfo{ErrCodeOutputSchemaInvalid, "NTM-E103", ErrCategoryRequest, http.StatusUnprocessableEntity, ActionFixRequest, false, "Fix the output so it matches the expected schema"},
+
+		ErrorInfo{ErrCodeInvalidArgs, "NTM-E104", ErrCategoryRequest, http.StatusBadRequest, ActionFixRequest, false, "Provide the required arguments; see --help"},
+		ErrorInfo{ErrCodeSensitiveDataBlocked, "NTM-E105", ErrCategoryRequest, http.StatusUnprocessableEntity, ActionFixRequest, false, "Remove the secrets, re-run with --allow-secret, or use --redact=warn/--redact=redact"},
+
+		// Not found
+		ErrorInfo{ErrCodeSessionNotFound, "NTM-E200", ErrCategoryNotFound, http.StatusNotFound, ActionCheckTarget, false, "Use 'ntm list' to see available sessions"},
+		ErrorInfo{ErrCodePaneNotFound, "NTM-E201", ErrCategoryNotFound, http.StatusNotFound, ActionCheckTarget, false, "Use 'ntm --robot-status' to see the panes in the session"},
+		ErrorInfo{ErrCodeBeadNotFound, "NTM-E202", ErrCategoryNotFound, http.StatusNotFound, ActionCheckTarget, false, "Use 'br list' to see existing beads"},
+		ErrorInfo{ErrCodeEnsembleNotFound, "NTM-E203", ErrCategoryNotFound, http.StatusNotFound, ActionCheckTarget, false, "Start an ensemble first or check the session name"},
+		ErrorInfo{ErrCodeJobNotFound, "NTM-E204", ErrCategoryNotFound, http.StatusNotFound, ActionCheckTarget, false, "Use 'ntm robot job status' to list jobs"},
+
+		// Environment
+		ErrorInfo{ErrCodeDependencyMissing, "NTM-E300", ErrCategoryEnvironment, http.StatusServiceUnavailable, ActionInstallDependency, false, "Install or start the required tool, then retry"},
+		ErrorInfo{ErrCodePermissionDenied, "NTM-E301", ErrCategoryEnvironment, http.StatusForbidden, ActionGrantAccess, false, "Check file permissions and credentials"},
+		ErrorInfo{ErrCodeNotImplemented, "NTM-E302", ErrCategoryEnvironment, http.StatusNotImplemented, ActionReportBug, false, "This feature is not available in this build"},
+
+		// Transient
+		ErrorInfo{ErrCodeTimeout, "NTM-E400", ErrCategoryTransient, http.StatusGatewayTimeout, ActionRetry, true, "Retry, optionally with a longer timeout"},
+		ErrorInfo{ErrCodeResourceBusy, "NTM-E401", ErrCategoryTransient, http.StatusConflict, ActionWait, true, "Wait for the current operation to finish, then retry"},
+		ErrorInfo{ErrCodeRateLimited, "NTM-E402", ErrCategoryTransient, http.StatusTooManyRequests, ActionWait, true, "Wait for the provider cooldown, reduce concurrent agents of that provider, then retry"},
+		ErrorInfo{ErrCodeSynthesisNotReady, "NTM-E403", ErrCategoryTransient, http.StatusConflict, ActionWait, true, "Wait for the ensemble agents to finish, then retry"},
+
+		// Agent
+		ErrorInfo{ErrCodeSoftExitFailed, "NTM-E500", ErrCategoryAgent, http.StatusBadGateway, ActionRestartAgent, true, "Retry with a hard kill fallback"},
+		ErrorInfo{ErrCodeHardKillFailed, "NTM-E501", ErrCategoryAgent, http.StatusBadGateway, ActionRestartAgent, false, "Kill the process manually or respawn the pane"},
+		ErrorInfo{ErrCodeShellNotReturned, "NTM-E502", ErrCategoryAgent, http.StatusBadGateway, ActionRestartAgent, true, "Respawn the pane to get a clean shell"},
+		ErrorInfo{ErrCodeCCLaunchFailed, "NTM-E503", ErrCategoryAgent, http.StatusBadGateway, ActionRestartAgent, true, "Check the agent CLI is installed and authenticated, then relaunch"},
+		ErrorInfo{ErrCodeCCInitTimeout, "NTM-E504", ErrCategoryAgent, http.StatusGatewayTimeout, ActionRestartAgent, true, "Relaunch the agent, allowing more time to initialize"},
+		ErrorInfo{ErrCodePromptSendFailed, "NTM-E505", ErrCategoryAgent, http.StatusBadGateway, ActionRetry, true, "Retry the send; check the pane is alive with 'ntm --robot-status'"},
+		ErrorInfo{ErrCodeAgentError, "NTM-E506", ErrCategoryAgent, http.StatusConflict, ActionRestartAgent, false, "Check agent output with --robot-tail and restart the agent if it is stuck"},
+
+		// Integration: prompt library (jfp) and skills (ms) tools
+		ErrorInfo{"HEALTH_CHECK_FAILED", "NTM-E600", ErrCategoryIntegration, http.StatusBadGateway, ActionInstallDependency, true, "Run 'jfp doctor' to diagnose issues"},
+		ErrorInfo{"LIST_FAILED", "NTM-E601", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"SEARCH_FAILED", "NTM-E602", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Try a different search query, or check the search tool is available"},
+		ErrorInfo{"SUGGEST_FAILED", "NTM-E603", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"INSTALLED_FAILED", "NTM-E604", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"CATEGORIES_FAILED", "NTM-E605", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"TAGS_FAILED", "NTM-E606", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"BUNDLES_FAILED", "NTM-E607", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"INSTALL_FAILED", "NTM-E608", ErrCategoryIntegration, http.StatusBadGateway, ActionFixRequest, false, "Check prompt IDs and try again"},
+		ErrorInfo{"EXPORT_FAILED", "NTM-E609", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"UPDATE_FAILED", "NTM-E610", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the jfp installation and retry"},
+		ErrorInfo{"MS_SEARCH_FAILED", "NTM-E611", ErrCategoryIntegration, http.StatusBadGateway, ActionRetry, true, "Check the ms installation and retry"},
+
+		// Internal
+		ErrorInfo{ErrCodeInternalError, "NTM-E900", ErrCategoryInternal, http.StatusInternalServerError, ActionReportBug, false, "Unexpected error; rerun with --verbose and report it if it persists"},
+	)
+}
+
+// RegisterErrorCodes adds codes to the taxonomy. Packages with their own
+// codes (such as the REST server) register them at init.
+func RegisterErrorCodes(infos ...ErrorInfo) {
+	errorTaxonomyMu.Lock()
+	defer errorTaxonomyMu.Unlock()
+	for _, info := range infos {
+		errorTaxonomy[info.Code] = info
+	}
+}
+
+// LookupErrorCode returns the taxonomy entry for code. Unknown codes get
+// UnknownErrorID and are treated as internal errors.
+func LookupErrorCode(code string) (ErrorInfo, bool) {
+	errorTaxonomyMu.RLock()
+	info, ok := errorTaxonomy[code]
+	errorTaxonomyMu.RUnlock()
+	if ok {
+		return info, true
+	}
+	return ErrorInfo{
+		Code:       code,
+		ID:         UnknownErrorID,
+		Category:   ErrCategoryInternal,
+		HTTPStatus: http.StatusInternalServerError,
+		Action:     ActionReportBug,
+		Guidance:   "Unrecognized error code; report it if it persists",
+	}, false
+}
+
+// ErrorCodes returns the taxonomy ordered by identifier.
+func ErrorCodes() []ErrorInfo {
+	errorTaxonomyMu.RLock()
+	infos := make([]ErrorInfo, 0, len(errorTaxonomy))
+	for _, info := range errorTaxonomy {
+		infos = append(infos, info)
+	}
+	errorTaxonomyMu.RUnlock()
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].ID != infos[j].ID {
+			return infos[i].ID < infos[j].ID
+		}
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
+
+// HTTPStatusForCode returns the HTTP status an error code maps to.
+func HTTPStatusForCode(code string) int {
+	info, _ := LookupErrorCode(code)
+	return info.HTTPStatus
+}
+
+// RemediationFor returns the default remediation for an error code. A
+// non-empty hint replaces the default guidance.
+func RemediationFor(code, hint string) *Remediation {
+	info, _ := LookupErrorCode(code)
+	r := &Remediation{Action: info.Action, Retryable: info.Retryable, Guidance: info.Guidance}
+	if hint != "" {
+		r.Guidance = hint
+	}
+	return r
+}
+
+// classify fills the taxonomy fields of a failure envelope that lacks them.
+func (r *RobotResponse) classify() {
+	if r.Success {
+		return
+	}
+	code := r.ErrorCode
+	if code == "" && r.StructuredError != nil {
+		code = r.StructuredError.Code
+	}
+	if code == "" {
+		code = ErrCodeInternalError
+	}
+	if r.ErrorID == "" {
+		info, _ := LookupErrorCode(code)
+		r.ErrorID = info.ID
+	}
+	if r.Remediation == nil {
+		hint := r.Hint
+		if hint == "" && r.StructuredError != nil {
+			hint = r.StructuredError.RecoveryHint
+		}
+		r.Remediation = RemediationFor(code, hint)
+	}
+}
+
+var robotResponseType = reflect.TypeOf(RobotResponse{})
+
+// withErrorTaxonomy classifies the failure envelope in a robot payload:
+// a RobotResponse, or a struct (or pointer to one) embedding it. Values
+// passed by value are copied rather than modified.
+func withErrorTaxonomy(payload any) any {
+	switch p := payload.(type) {
+	case RobotResponse:
+		p.classify()
+		return p
+	case *RobotResponse:
+		if p != nil {
+			p.classify()
+		}
+		return p
+	}
+
+	v := reflect.ValueOf(payload)
+	if !v.IsValid() {
+		return payload
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return payload
+		}
+		if f := v.Elem().FieldByName("RobotResponse"); f.IsValid() && f.Type() == robotResponseType && f.CanAddr() {
+			f.Addr().Interface().(*RobotResponse).classify()
+		}
+		return payload
+	}
+	if v.Kind() != reflect.Struct {
+		return payload
+	}
+	f := v.FieldByName("RobotResponse")
+	if !f.IsValid() || f.Type() != robotResponseType || f.Interface().(RobotResponse).Success {
+		return payload
+	}
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	cp.FieldByName("RobotResponse").Addr().Interface().(*RobotResponse).classify()
+	return cp.Interface()
+}
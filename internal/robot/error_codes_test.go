@@ -0,0 +1,176 @@
+package robot
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestErrorCodes_UniqueWellFormedIDs(t *testing.T) {
+	t.Parallel()
+
+	idPattern := regexp.MustCompile(`^NTM-E\d{3}$`)
+	seen := make(map[string]string)
+	for _, info := range ErrorCodes() {
+		if !idPattern.MatchString(info.ID) {
+			t.Errorf("%s: malformed id %q", info.Code, info.ID)
+		}
+		if info.ID == UnknownErrorID {
+			t.Errorf("%s: uses reserved id %s", info.Code, info.ID)
+		}
+		// Serve maps its idempotency code onto the robot one.
+		if prev, ok := seen[info.ID]; ok && info.ID != "NTM-E102" {
+			t.Errorf("id %s shared by %s and %s", info.ID, prev, info.Code)
+		}
+		seen[info.ID] = info.Code
+		if info.HTTPStatus < 200 || info.HTTPStatus > 599 {
+			t.Errorf("%s: http status %d", info.Code, info.HTTPStatus)
+		}
+		if info.Action == "" || info.Guidance == "" || info.Category == "" {
+			t.Errorf("%s: incomplete entry %+v", info.Code, info)
+		}
+	}
+}
+
+func TestErrorCodes_AllRobotCodesRegistered(t *testing.T) {
+	t.Parallel()
+
+	codes := []string{
+		ErrCodeSessionNotFound, ErrCodePaneNotFound, ErrCodeInvalidFlag,
+		ErrCodeTimeout, ErrCodeNotImplemented, ErrCodeDependencyMissing,
+		ErrCodeInternalError, ErrCodePermissionDenied, ErrCodeResourceBusy,
+		ErrCodeSoftExitFailed, ErrCodeHardKillFailed, ErrCodeShellNotReturned,
+		ErrCodeCCLaunchFailed, ErrCodeCCInitTimeout, ErrCodeBeadNotFound,
+		ErrCodePromptSendFailed, ErrCodeConfirmationRequired,
+		ErrCodeIdempotencyConflict, ErrCodeInvalidArgs,
+		ErrCodeSensitiveDataBlocked, ErrCodeRateLimited, ErrCodeAgentError,
+		ErrCodeSynthesisNotReady, ErrCodeOutputSchemaInvalid, ErrCodeJobNotFound,
+	}
+	for _, code := range codes {
+		if _, ok := LookupErrorCode(code); !ok {
+			t.Errorf("%s is not in the taxonomy", code)
+		}
+	}
+}
+
+func TestLookupErrorCode_Unknown(t *testing.T) {
+	t.Parallel()
+
+	info, ok := LookupErrorCode("NO_SUCH_CODE")
+	if ok {
+		t.Fatal("unknown code reported as registered")
+	}
+	if info.ID != UnknownErrorID || info.HTTPStatus != http.StatusInternalServerError {
+		t.Errorf("info = %+v", info)
+	}
+}
+
+func TestHTTPStatusForCode(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		ErrCodeSessionNotFound:      http.StatusNotFound,
+		ErrCodeInvalidFlag:          http.StatusBadRequest,
+		ErrCodeConfirmationRequired: http.StatusPreconditionRequired,
+		ErrCodeRateLimited:          http.StatusTooManyRequests,
+		ErrCodeDependencyMissing:    http.StatusServiceUnavailable,
+		ErrCodeInternalError:        http.StatusInternalServerError,
+	}
+	for code, want := range tests {
+		if got := HTTPStatusForCode(code); got != want {
+			t.Errorf("HTTPStatusForCode(%s) = %d, want %d", code, got, want)
+		}
+	}
+}
+
+func TestRemediationFor_HintOverridesGuidance(t *testing.T) {
+	t.Parallel()
+
+	r := RemediationFor(ErrCodeRateLimited, "")
+	if r.Action != ActionWait || !r.Retryable || r.Guidance == "" {
+		t.Errorf("default remediation = %+v", r)
+	}
+	r = RemediationFor(ErrCodeRateLimited, "wait 30s")
+	if r.Guidance != "wait 30s" {
+		t.Errorf("Guidance = %q, want hint", r.Guidance)
+	}
+}
+
+func TestNewErrorResponse_Classified(t *testing.T) {
+	t.Parallel()
+
+	resp := NewErrorResponse(errors.New("gone"), ErrCodeSessionNotFound, "")
+	if resp.ErrorID != "NTM-E200" {
+		t.Errorf("ErrorID = %q", resp.ErrorID)
+	}
+	if resp.Remediation == nil || resp.Remediation.Action != ActionCheckTarget {
+		t.Errorf("Remediation = %+v", resp.Remediation)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["error_id"] != "NTM-E200" || m["remediation"] == nil {
+		t.Errorf("envelope = %s", data)
+	}
+
+	ok, _ := json.Marshal(NewRobotResponse(true))
+	var okMap map[string]any
+	if err := json.Unmarshal(ok, &okMap); err != nil {
+		t.Fatal(err)
+	}
+	if _, has := okMap["error_id"]; has {
+		t.Errorf("success envelope has error_id: %s", ok)
+	}
+}
+
+func TestWithErrorTaxonomy(t *testing.T) {
+	t.Parallel()
+
+	type payload struct {
+		RobotResponse
+		Extra string `json:"extra"`
+	}
+	failed := RobotResponse{Success: false, ErrorCode: ErrCodeTimeout, Hint: "try --timeout=60s"}
+
+	byValue := payload{RobotResponse: failed}
+	got, ok := withErrorTaxonomy(byValue).(payload)
+	if !ok {
+		t.Fatalf("value payload changed type")
+	}
+	if got.ErrorID != "NTM-E400" || got.Remediation == nil || got.Remediation.Guidance != "try --timeout=60s" {
+		t.Errorf("classified = %+v", got.RobotResponse)
+	}
+	if byValue.ErrorID != "" {
+		t.Error("value payload was modified in place")
+	}
+
+	byPointer := &payload{RobotResponse: failed}
+	withErrorTaxonomy(byPointer)
+	if byPointer.ErrorID != "NTM-E400" {
+		t.Errorf("pointer payload ErrorID = %q", byPointer.ErrorID)
+	}
+
+	structured := &RobotResponse{StructuredError: &StructuredError{Code: ErrCodePaneNotFound}}
+	withErrorTaxonomy(structured)
+	if structured.ErrorID != "NTM-E201" {
+		t.Errorf("structured ErrorID = %q", structured.ErrorID)
+	}
+
+	success := &payload{RobotResponse: NewRobotResponse(true)}
+	withErrorTaxonomy(success)
+	if success.ErrorID != "" || success.Remediation != nil {
+		t.Errorf("success payload classified: %+v", success.RobotResponse)
+	}
+
+	if withErrorTaxonomy(nil) != nil {
+		t.Error("nil payload changed")
+	}
+}
@@ -162,7 +162,7 @@ func GetErrors(opts ErrorsOptions) (*ErrorsOutput, error) {
 	if opts.Session == "" {
 		output.RobotResponse = NewErrorResponse(
 			nil,
-			"INVALID_ARGS",
+			ErrCodeInvalidArgs,
 			"Provide session name: ntm --robot-errors=myproject",
 		)
 		output.Error = "session name required"
@@ -0,0 +1,106 @@
+// Package robot provides machine-readable output for AI agents.
+// etag.go implements --if-changed: content hashes that let pollers skip
+// unchanged output.
+package robot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// IfChanged controls --if-changed. When Enabled, successful outputs carry
+// an "etag" content hash; if the hash equals ETag the full output is
+// replaced by a short not_modified response.
+var IfChanged struct {
+	Enabled bool
+	ETag    string
+}
+
+// etagVolatileKeys are fields describing when or how an output was
+// produced rather than what it contains. They are ignored at any depth
+// when hashing, so re-polling an unchanged state yields the same ETag.
+var etagVolatileKeys = map[string]bool{
+	"timestamp":    true,
+	"generated_at": true,
+	"captured_at":  true,
+	"checked_at":   true,
+	"request_id":   true,
+	"etag":         true,
+	"_meta":        true,
+	"_debug":       true,
+	"_agent_hints": true,
+}
+
+// NotModifiedOutput replaces an output whose ETag matches --if-changed.
+type NotModifiedOutput struct {
+	RobotResponse
+	NotModified bool   `json:"not_modified"`
+	ETag        string `json:"etag"`
+}
+
+// ContentETag returns a hash of payload's content, ignoring volatile
+// fields such as timestamps.
+func ContentETag(payload any) (string, error) {
+	normalized, err := normalizePayload(payload)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(stripVolatile(normalized))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// applyIfChanged implements --if-changed for one output. Error responses
+// and non-object payloads pass through unchanged.
+func applyIfChanged(payload any) any {
+	if !IfChanged.Enabled {
+		return payload
+	}
+	normalized, err := normalizePayload(payload)
+	if err != nil {
+		return payload
+	}
+	obj, ok := normalized.(map[string]any)
+	if !ok || obj["success"] == false {
+		return payload
+	}
+	etag, err := ContentETag(obj)
+	if err != nil {
+		return payload
+	}
+	if IfChanged.ETag != "" && IfChanged.ETag == etag {
+		return NotModifiedOutput{
+			RobotResponse: NewRobotResponse(true),
+			NotModified:   true,
+			ETag:          etag,
+		}
+	}
+	obj["etag"] = etag
+	return obj
+}
+
+func stripVolatile(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(typed))
+		for k, v := range typed {
+			if etagVolatileKeys[k] {
+				continue
+			}
+			out[k] = stripVolatile(v)
+		}
+		return out
+	case []any:
+		out := make([]any, len(typed))
+		for i, v := range typed {
+			out[i] = stripVolatile(v)
+		}
+		return out
+	default:
+		return value
+	}
+}
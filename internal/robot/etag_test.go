@@ -0,0 +1,56 @@
+package robot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContentETag_IgnoresVolatileFields(t *testing.T) {
+	a := StatusOutput{RobotResponse: NewRobotResponse(true), GeneratedAt: time.Now(), Sessions: []SessionInfo{{Name: "proj"}}}
+	b := a
+	b.RobotResponse.Timestamp = "2000-01-01T00:00:00Z"
+	b.GeneratedAt = a.GeneratedAt.Add(time.Hour)
+
+	ea, err := ContentETag(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eb, _ := ContentETag(b); ea != eb {
+		t.Errorf("ETag changed with only timestamps: %s vs %s", ea, eb)
+	}
+
+	c := a
+	c.Sessions = []SessionInfo{{Name: "other"}}
+	if ec, _ := ContentETag(c); ec == ea {
+		t.Error("ETag should change when content changes")
+	}
+}
+
+func TestApplyIfChanged(t *testing.T) {
+	prev := IfChanged
+	t.Cleanup(func() { IfChanged = prev })
+
+	payload := SuccessResponse{RobotResponse: NewRobotResponse(true)}
+	IfChanged.Enabled = false
+	if got := applyIfChanged(payload); got != any(payload) {
+		t.Fatal("disabled --if-changed should not touch the payload")
+	}
+
+	IfChanged.Enabled = true
+	IfChanged.ETag = ""
+	first, ok := applyIfChanged(payload).(map[string]any)
+	if !ok || first["etag"] == "" {
+		t.Fatalf("first poll should add an etag, got %#v", first)
+	}
+
+	IfChanged.ETag = first["etag"].(string)
+	out, ok := applyIfChanged(payload).(NotModifiedOutput)
+	if !ok || !out.NotModified || out.ETag != IfChanged.ETag {
+		t.Fatalf("matching etag should yield not_modified, got %#v", out)
+	}
+
+	errResp := NewErrorResponse(nil, ErrCodeInternalError, "")
+	if got := applyIfChanged(errResp); got != any(errResp) {
+		t.Error("error responses should pass through")
+	}
+}
@@ -0,0 +1,231 @@
+// Package robot provides machine-readable output for AI agents.
+// exchanges.go implements `ntm robot exchanges`.
+package robot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/exchange"
+	"github.com/Dicklesworthstone/ntm/internal/history"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// DefaultExchangesInterval is how often panes are captured in watch mode.
+const DefaultExchangesInterval = time.Second
+
+// exchangeCaptureLines is how much scrollback each capture covers.
+const exchangeCaptureLines = 200
+
+// ExchangesOptions configures `ntm robot exchanges`.
+type ExchangesOptions struct {
+	// Session limits the report (and is required in watch mode).
+	Session string
+	// AgentType filters by agent type.
+	AgentType string
+	// Since limits the report to exchanges sent after this time.
+	Since time.Time
+	// Limit keeps only the most recent N exchanges (0 = all).
+	Limit int
+	// Path overrides exchange.DefaultPath.
+	Path string
+
+	// Interval, QuietPeriod, and MaxWait tune watch mode.
+	Interval    time.Duration
+	QuietPeriod time.Duration
+	MaxWait     time.Duration
+	// Output receives NDJSON in watch mode (default stdout).
+	Output io.Writer
+}
+
+// ExchangesOutput is the response for `ntm robot exchanges`.
+type ExchangesOutput struct {
+	RobotResponse
+	Path      string                    `json:"path"`
+	Session   string                    `json:"session,omitempty"`
+	Since     string                    `json:"since,omitempty"`
+	Exchanges []exchange.PromptExchange `json:"exchanges"`
+	Summary   []exchange.AgentSummary   `json:"summary"`
+}
+
+// ExchangeEvent is one NDJSON line in watch mode.
+type ExchangeEvent struct {
+	Type      string                   `json:"type"` // "exchange" or "error"
+	Timestamp string                   `json:"timestamp"`
+	Exchange  *exchange.PromptExchange `json:"exchange,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// GetExchanges reads recorded prompt exchanges and summarizes them per
+// agent type.
+func GetExchanges(opts ExchangesOptions) (*ExchangesOutput, error) {
+	store := exchange.NewStore(opts.Path)
+	out := &ExchangesOutput{
+		RobotResponse: NewRobotResponse(true),
+		Path:          store.Path(),
+		Session:       opts.Session,
+		Exchanges:     []exchange.PromptExchange{},
+		Summary:       []exchange.AgentSummary{},
+	}
+	if !opts.Since.IsZero() {
+		out.Since = FormatTimestamp(opts.Since)
+	}
+
+	exchanges, err := store.Query(exchange.Query{
+		Since:     opts.Since,
+		Session:   opts.Session,
+		AgentType: opts.AgentType,
+		Limit:     opts.Limit,
+	})
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that the exchanges file is readable")
+		return out, nil
+	}
+	if len(exchanges) > 0 {
+		out.Exchanges = exchanges
+		out.Summary = exchange.Summarize(exchanges)
+	}
+	return out, nil
+}
+
+// PrintExchanges handles `ntm robot exchanges`.
+func PrintExchanges(opts ExchangesOptions) error {
+	out, err := GetExchanges(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// WatchExchanges captures the session's panes every Interval and pairs each
+// prompt recorded in the send history with the output that follows it.
+// Finished exchanges are persisted and written as NDJSON. It blocks until
+// ctx is cancelled; exchanges still open at that point are dropped.
+func WatchExchanges(ctx context.Context, opts ExchangesOptions) error {
+	if opts.Session == "" {
+		return fmt.Errorf("--session is required with --watch")
+	}
+	if !tmux.SessionExists(opts.Session) {
+		return fmt.Errorf("session '%s' not found", opts.Session)
+	}
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultExchangesInterval
+	}
+
+	ew := &exchangeWatch{
+		opts:    opts,
+		store:   exchange.NewStore(opts.Path),
+		started: time.Now(),
+		seen:    make(map[string]bool),
+		last:    make(map[string]exchange.Sample),
+		pending: make(map[string][]*exchange.Pending),
+	}
+	// Only prompts sent after the watch starts are paired.
+	if entries, err := history.ReadForSession(opts.Session); err == nil {
+		for _, e := range entries {
+			ew.seen[e.ID] = true
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ew.tick(out, time.Now())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+type exchangeWatch struct {
+	opts    ExchangesOptions
+	store   *exchange.Store
+	started time.Time
+	seen    map[string]bool                // history entry IDs already handled
+	last    map[string]exchange.Sample     // pane index -> previous capture
+	pending map[string][]*exchange.Pending // pane index -> open exchanges
+}
+
+func (ew *exchangeWatch) tick(out io.Writer, now time.Time) {
+	panes, err := tmux.GetPanes(ew.opts.Session)
+	if err != nil {
+		emitExchangeEvent(out, &ExchangeEvent{Type: "error", Timestamp: FormatTimestamp(now), Error: err.Error()})
+		return
+	}
+	byIndex := make(map[string]tmux.Pane, len(panes))
+	for _, p := range panes {
+		byIndex[strconv.Itoa(p.Index)] = p
+	}
+
+	// Open exchanges for new prompts. The previous tick's capture predates
+	// the send and serves as the baseline.
+	if entries, err := history.ReadForSession(ew.opts.Session); err == nil {
+		for _, e := range entries {
+			if ew.seen[e.ID] {
+				continue
+			}
+			ew.seen[e.ID] = true
+			if !e.Success || e.Timestamp.Before(ew.started) {
+				continue
+			}
+			for _, target := range e.Targets {
+				pane, ok := byIndex[target]
+				if !ok {
+					continue
+				}
+				ew.pending[target] = append(ew.pending[target], exchange.Start(exchange.PromptExchange{
+					ID:          e.ID + ":" + target,
+					PromptID:    e.ID,
+					Session:     e.Session,
+					Pane:        target,
+					AgentType:   string(pane.Type),
+					PromptBytes: len(e.Prompt),
+					SentAt:      e.Timestamp,
+				}, ew.last[target].Content, ew.opts.QuietPeriod, ew.opts.MaxWait))
+			}
+		}
+	}
+
+	for idx, pane := range byIndex {
+		content, err := tmux.CapturePaneOutput(pane.ID, exchangeCaptureLines)
+		if err != nil {
+			continue
+		}
+		sample := exchange.Sample{At: now, Content: content}
+		ew.last[idx] = sample
+
+		open := ew.pending[idx][:0]
+		for _, p := range ew.pending[idx] {
+			ex, done := p.Observe(sample)
+			if !done {
+				open = append(open, p)
+				continue
+			}
+			if err := ew.store.Append(ex); err != nil {
+				emitExchangeEvent(out, &ExchangeEvent{Type: "error", Timestamp: FormatTimestamp(now), Error: err.Error()})
+			}
+			if ew.opts.AgentType != "" && ex.AgentType != ew.opts.AgentType {
+				continue
+			}
+			emitExchangeEvent(out, &ExchangeEvent{Type: "exchange", Timestamp: FormatTimestamp(now), Exchange: &ex})
+		}
+		ew.pending[idx] = open
+	}
+}
+
+func emitExchangeEvent(out io.Writer, ev *ExchangeEvent) {
+	data, _ := json.Marshal(ev)
+	fmt.Fprintln(out, string(data))
+}
@@ -0,0 +1,134 @@
+package robot
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+)
+
+// FuzzExtractJSONOutputs ensures ExtractJSONOutputs never panics and only
+// reports valid JSON with sane line ranges.
+func FuzzExtractJSONOutputs(f *testing.F) {
+	seeds := []string{
+		"",
+		`{"key": "value"}`,
+		"[1, 2, 3]",
+		"Result:\n{\n  \"a\": [1, {\"b\": 2}]\n}\ndone",
+		`{"s": "brace } in \" string"}`,
+		"{\n[\n{",
+		"}]",
+		strings.Repeat("[", 1000) + strings.Repeat("]", 1000),
+		strings.Repeat("{\n", 200),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, content string) {
+		lineCount := strings.Count(content, "\n") + 1
+		prevEnd := 0
+		for _, out := range ExtractJSONOutputs(content) {
+			if !json.Valid([]byte(out.Raw)) {
+				t.Fatalf("invalid JSON reported: %q", out.Raw)
+			}
+			if out.LineStart <= prevEnd || out.LineEnd < out.LineStart || out.LineEnd > lineCount {
+				t.Fatalf("bad line range %d-%d (previous end %d, %d lines)", out.LineStart, out.LineEnd, prevEnd, lineCount)
+			}
+			prevEnd = out.LineEnd
+		}
+	})
+}
+
+// FuzzParseGitStatusPorcelain ensures porcelain parsing never panics and
+// never yields empty paths.
+func FuzzParseGitStatusPorcelain(f *testing.F) {
+	seeds := []string{
+		"",
+		" M file.go",
+		"M  staged.go\n?? new.go",
+		"R  old.go -> new.go",
+		`?? "caf\303\251.go"`,
+		`?? "bad\q"`,
+		"M",
+		"XY",
+		" M \r\n",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, output string) {
+		results, err := parseGitStatusPorcelain(output, dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, r := range results {
+			if r.Path == "" {
+				t.Fatalf("empty path parsed from %q", output)
+			}
+		}
+	})
+}
+
+func TestExtractJSONOutputs_Budgets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"deep nesting", strings.Repeat("[", summary.JSONMaxDepth+1) + strings.Repeat("]", summary.JSONMaxDepth+1), 0},
+		{"nesting within budget", strings.Repeat("[", 10) + strings.Repeat("]", 10), 1},
+		{"oversized line", `["` + strings.Repeat("a", summary.JSONMaxBlockBytes) + `"]`, 0},
+		{"oversized block", "[\n" + strings.Repeat(`"`+strings.Repeat("a", 1<<16)+"\",\n", 20) + "1]", 0},
+		{"nested values reported once", "{\n  \"a\": [\n    1\n  ]\n}", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := ExtractJSONOutputs(tt.content); len(got) != tt.want {
+				t.Errorf("ExtractJSONOutputs() returned %d outputs, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJSONOutputs_ManyCandidates(t *testing.T) {
+	t.Parallel()
+
+	// Every line opens a block that never closes; the candidate budget keeps
+	// this from rescanning the window for each of them.
+	content := strings.Repeat("[\n", 100000) + `{"late": true}`
+	start := time.Now()
+	if got := ExtractJSONOutputs(content); len(got) != 0 {
+		t.Errorf("ExtractJSONOutputs() returned %d outputs past the candidate budget", len(got))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ExtractJSONOutputs() took %v", elapsed)
+	}
+}
+
+func TestParseGitStatusPorcelain_QuotedPaths(t *testing.T) {
+	t.Parallel()
+
+	got, err := parseGitStatusPorcelain("?? \"caf\\303\\251.go\"\nM  \"a\\tb.go\"\nR  old.go -> \"new name.go\"\n?? \"\"", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"café.go", "a\tb.go", "new name.go"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Path != w || !utf8.ValidString(got[i].Path) {
+			t.Errorf("path[%d] = %q, want %q", i, got[i].Path, w)
+		}
+	}
+}
@@ -176,27 +176,7 @@ func getSystemHealth() SystemHealthInfo {
 func getDiskFreeGB() float64 {
 	switch runtime.GOOS {
 	case "darwin", "linux":
-		// Use df command
-		cmd := exec.Command("df", "-k", ".")
-		out, err := cmd.Output()
-		if err != nil {
-			return -1
-		}
-		lines := strings.Split(string(out), "\n")
-		if len(lines) < 2 {
-			return -1
-		}
-		// Parse the second line (data line)
-		fields := strings.Fields(lines[1])
-		if len(fields) < 4 {
-			return -1
-		}
-		// Field 3 is available space in KB
-		availKB, err := strconv.ParseFloat(fields[3], 64)
-		if err != nil {
-			return -1
-		}
-		return availKB / (1024 * 1024) // Convert KB to GB
+		return getDiskFreeGBAt(".")
 	default:
 		return -1
 	}
@@ -2187,7 +2167,7 @@ func BuildAutoRestartStuckOutput(session string, stuckPanes []int, restarted []i
 		Failed:        failed,
 		Threshold:     threshold.String(),
 		DryRun:        dryRun,
-		CheckedAt:     time.Now().UTC().Format(time.RFC3339),
+		CheckedAt:     FormatTimestamp(time.Now()),
 	}
 	if len(stuckPanes) == 0 {
 		output.StuckPanes = []int{}
@@ -2219,7 +2199,7 @@ func GetAutoRestartStuck(opts AutoRestartStuckOptions) (*AutoRestartStuckOutput,
 			Restarted:     []int{},
 			Threshold:     opts.Threshold.String(),
 			DryRun:        opts.DryRun,
-			CheckedAt:     time.Now().UTC().Format(time.RFC3339),
+			CheckedAt:     FormatTimestamp(time.Now()),
 		}
 		return output, nil
 	}
@@ -0,0 +1,351 @@
+// Package robot provides machine-readable output for AI agents.
+// health_probes.go contains the deep component health checks.
+package robot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// ComponentStatus is the outcome of a single component probe.
+type ComponentStatus string
+
+const (
+	ComponentOK       ComponentStatus = "ok"
+	ComponentDegraded ComponentStatus = "degraded"
+	ComponentFailed   ComponentStatus = "failed"
+	ComponentSkipped  ComponentStatus = "skipped"
+)
+
+// ComponentHealth is the result of probing one component.
+type ComponentHealth struct {
+	Name      string            `json:"name"`
+	Status    ComponentStatus   `json:"status"`
+	LatencyMs int64             `json:"latency_ms"`
+	Message   string            `json:"message,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// HealthProbe checks a single component. Check should honour ctx and return
+// quickly; latency is measured by the caller.
+type HealthProbe struct {
+	Name  string
+	Check func(ctx context.Context) ComponentHealth
+}
+
+// DeepHealthOutput is the response for deep component health checks.
+type DeepHealthOutput struct {
+	RobotResponse
+	CheckedAt  time.Time         `json:"checked_at"`
+	Status     ComponentStatus   `json:"status"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// DeepHealthOptions configures the default probe set.
+type DeepHealthOptions struct {
+	// ProjectDir is used for the Agent Mail project and archive disk checks.
+	ProjectDir string
+	// StatePath overrides the state store location (default ~/.config/ntm/state.db).
+	StatePath string
+	// AuditDir overrides the audit log directory (default audit.Dir()).
+	AuditDir string
+	// ArchiveDir is where archives are written (default ProjectDir).
+	ArchiveDir string
+	// MinDiskFreeGB marks the disk probe degraded below this threshold.
+	MinDiskFreeGB float64
+	// JWKSURL enables the JWKS probe when set.
+	JWKSURL string
+	// Timeout bounds each probe (default 5s).
+	Timeout time.Duration
+}
+
+// DefaultMinDiskFreeGB is the free space below which the disk probe degrades.
+const DefaultMinDiskFreeGB = 1.0
+
+// auditChainMaxFiles bounds how many recent audit logs are verified.
+const auditChainMaxFiles = 10
+
+// DefaultHealthProbes returns the standard component probes.
+func DefaultHealthProbes(opts DeepHealthOptions) []HealthProbe {
+	probes := []HealthProbe{
+		{Name: "tmux", Check: probeTmux},
+		{Name: "state_store", Check: func(ctx context.Context) ComponentHealth { return probeStateStore(ctx, opts.StatePath) }},
+		{Name: "agent_mail", Check: func(ctx context.Context) ComponentHealth { return probeAgentMail(ctx, opts.ProjectDir) }},
+		{Name: "archive_disk", Check: func(ctx context.Context) ComponentHealth {
+			dir := opts.ArchiveDir
+			if dir == "" {
+				dir = opts.ProjectDir
+			}
+			return probeDiskSpace(dir, opts.MinDiskFreeGB)
+		}},
+		{Name: "audit_chain", Check: func(ctx context.Context) ComponentHealth { return probeAuditChain(opts.AuditDir) }},
+		{Name: "jwks", Check: func(ctx context.Context) ComponentHealth { return probeJWKS(ctx, opts.JWKSURL) }},
+	}
+	return probes
+}
+
+// GetDeepHealth runs all probes concurrently and aggregates their status.
+// The overall status is the worst component status, with skipped probes
+// ignored.
+func GetDeepHealth(ctx context.Context, opts DeepHealthOptions) *DeepHealthOutput {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	probes := DefaultHealthProbes(opts)
+
+	results := make([]ComponentHealth, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func(i int, p HealthProbe) {
+			defer wg.Done()
+			results[i] = runProbe(ctx, p, timeout)
+		}(i, p)
+	}
+	wg.Wait()
+
+	out := &DeepHealthOutput{
+		RobotResponse: NewRobotResponse(true),
+		CheckedAt:     time.Now().UTC(),
+		Status:        ComponentOK,
+		Components:    results,
+	}
+	for _, r := range results {
+		out.Status = worseStatus(out.Status, r.Status)
+	}
+	return out
+}
+
+// PrintDeepHealth outputs deep component health for AI consumption.
+// This is a thin wrapper around GetDeepHealth() for CLI output.
+func PrintDeepHealth(opts DeepHealthOptions) error {
+	return encodeJSON(GetDeepHealth(context.Background(), opts))
+}
+
+func runProbe(ctx context.Context, p HealthProbe, timeout time.Duration) (result ComponentHealth) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan ComponentHealth, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- ComponentHealth{Status: ComponentFailed, Message: fmt.Sprintf("probe panicked: %v", r)}
+			}
+		}()
+		done <- p.Check(ctx)
+	}()
+
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = ComponentHealth{Status: ComponentFailed, Message: fmt.Sprintf("probe timed out after %s", timeout)}
+	}
+	result.Name = p.Name
+	result.LatencyMs = time.Since(start).Milliseconds()
+	return result
+}
+
+func worseStatus(a, b ComponentStatus) ComponentStatus {
+	rank := map[ComponentStatus]int{ComponentSkipped: 0, ComponentOK: 0, ComponentDegraded: 1, ComponentFailed: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+func probeTmux(ctx context.Context) ComponentHealth {
+	if !tmux.IsInstalled() {
+		return ComponentHealth{Status: ComponentFailed, Message: "tmux is not installed"}
+	}
+	sessions, err := tmux.DefaultClient.ListSessions()
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: err.Error()}
+	}
+	return ComponentHealth{
+		Status:  ComponentOK,
+		Details: map[string]string{"sessions": strconv.Itoa(len(sessions))},
+	}
+}
+
+func probeStateStore(ctx context.Context, path string) ComponentHealth {
+	store, err := state.Open(path)
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: err.Error()}
+	}
+	defer store.Close()
+
+	// Taking the write lock fails on read-only or locked-out databases
+	// without modifying anything.
+	conn, err := store.DB().Conn(ctx)
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: err.Error()}
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: fmt.Sprintf("state store not writable: %v", err)}
+	}
+	_, _ = conn.ExecContext(ctx, "ROLLBACK")
+
+	// WAL and shm files live next to the database, so the directory must
+	// accept writes too.
+	probe, err := os.CreateTemp(filepath.Dir(store.Path()), ".ntm-health-*")
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: fmt.Sprintf("state directory not writable: %v", err)}
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return ComponentHealth{Status: ComponentOK, Details: map[string]string{"path": store.Path()}}
+}
+
+func probeAgentMail(ctx context.Context, projectDir string) ComponentHealth {
+	client := agentmail.NewClient(agentmail.WithProjectKey(projectDir))
+	health, err := client.HealthCheck(ctx)
+	details := map[string]string{"url": client.BaseURL()}
+	if err != nil {
+		// Agent Mail is optional; an unreachable server degrades rather
+		// than fails the overall health.
+		return ComponentHealth{Status: ComponentDegraded, Message: err.Error(), Details: details}
+	}
+	if health != nil && health.Status != "" {
+		details["server_status"] = health.Status
+	}
+	return ComponentHealth{Status: ComponentOK, Details: details}
+}
+
+func probeDiskSpace(dir string, minFreeGB float64) ComponentHealth {
+	if dir == "" {
+		dir = "."
+	}
+	if minFreeGB <= 0 {
+		minFreeGB = DefaultMinDiskFreeGB
+	}
+	free := getDiskFreeGBAt(dir)
+	if free < 0 {
+		return ComponentHealth{Status: ComponentSkipped, Message: "disk space not available on this platform"}
+	}
+	details := map[string]string{
+		"path":    dir,
+		"free_gb": strconv.FormatFloat(free, 'f', 1, 64),
+	}
+	if free < minFreeGB {
+		return ComponentHealth{
+			Status:  ComponentDegraded,
+			Message: fmt.Sprintf("only %.1f GB free (minimum %.1f GB)", free, minFreeGB),
+			Details: details,
+		}
+	}
+	return ComponentHealth{Status: ComponentOK, Details: details}
+}
+
+func probeAuditChain(dir string) ComponentHealth {
+	if dir == "" {
+		dir = audit.Dir()
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: err.Error()}
+	}
+	if len(files) == 0 {
+		return ComponentHealth{Status: ComponentOK, Message: "no audit logs yet"}
+	}
+
+	// Verify the most recently modified logs; older ones are immutable.
+	sort.Slice(files, func(i, j int) bool { return modTime(files[i]).After(modTime(files[j])) })
+	if len(files) > auditChainMaxFiles {
+		files = files[:auditChainMaxFiles]
+	}
+	var broken []string
+	for _, f := range files {
+		if err := audit.VerifyIntegrity(f); err != nil {
+			broken = append(broken, fmt.Sprintf("%s: %v", filepath.Base(f), err))
+		}
+	}
+	details := map[string]string{"verified_files": strconv.Itoa(len(files))}
+	if len(broken) > 0 {
+		return ComponentHealth{Status: ComponentFailed, Message: strings.Join(broken, "; "), Details: details}
+	}
+	return ComponentHealth{Status: ComponentOK, Details: details}
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func probeJWKS(ctx context.Context, url string) ComponentHealth {
+	if url == "" {
+		return ComponentHealth{Status: ComponentSkipped, Message: "OIDC not configured"}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: err.Error()}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ComponentHealth{Status: ComponentFailed, Message: fmt.Sprintf("status %d", resp.StatusCode)}
+	}
+	var payload struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1024*1024)).Decode(&payload); err != nil {
+		return ComponentHealth{Status: ComponentFailed, Message: fmt.Sprintf("invalid JWKS: %v", err)}
+	}
+	if len(payload.Keys) == 0 {
+		return ComponentHealth{Status: ComponentDegraded, Message: "JWKS has no keys"}
+	}
+	return ComponentHealth{Status: ComponentOK, Details: map[string]string{"keys": strconv.Itoa(len(payload.Keys))}}
+}
+
+// getDiskFreeGBAt returns the free disk space in GB for the filesystem
+// containing dir, or -1 if it cannot be determined.
+func getDiskFreeGBAt(dir string) float64 {
+	out, err := exec.Command("df", "-k", dir).Output()
+	if err != nil {
+		return -1
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) < 2 {
+		return -1
+	}
+	// Long device names wrap onto their own line; available KB is the
+	// third-from-last field of the final line.
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return -1
+	}
+	availKB, err := strconv.ParseFloat(fields[len(fields)-3], 64)
+	if err != nil {
+		return -1
+	}
+	return availKB / (1024 * 1024)
+}
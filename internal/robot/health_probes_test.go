@@ -0,0 +1,120 @@
+package robot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunProbe_TimeoutAndPanic(t *testing.T) {
+	t.Parallel()
+
+	slow := HealthProbe{Name: "slow", Check: func(ctx context.Context) ComponentHealth {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return ComponentHealth{Status: ComponentOK}
+	}}
+	got := runProbe(context.Background(), slow, 20*time.Millisecond)
+	if got.Name != "slow" || got.Status != ComponentFailed {
+		t.Errorf("timed-out probe = %+v, want failed", got)
+	}
+
+	panicky := HealthProbe{Name: "panicky", Check: func(ctx context.Context) ComponentHealth {
+		panic("boom")
+	}}
+	got = runProbe(context.Background(), panicky, time.Second)
+	if got.Status != ComponentFailed || got.Message == "" {
+		t.Errorf("panicking probe = %+v, want failed with message", got)
+	}
+}
+
+func TestWorseStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b, want ComponentStatus
+	}{
+		{ComponentOK, ComponentSkipped, ComponentOK},
+		{ComponentOK, ComponentDegraded, ComponentDegraded},
+		{ComponentDegraded, ComponentOK, ComponentDegraded},
+		{ComponentDegraded, ComponentFailed, ComponentFailed},
+	}
+	for _, tt := range tests {
+		if got := worseStatus(tt.a, tt.b); got != tt.want {
+			t.Errorf("worseStatus(%s, %s) = %s, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestProbeStateStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.db")
+	got := probeStateStore(context.Background(), path)
+	if got.Status != ComponentOK || got.Details["path"] != path {
+		t.Errorf("probeStateStore() = %+v, want ok", got)
+	}
+}
+
+func TestProbeAuditChain(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if got := probeAuditChain(dir); got.Status != ComponentOK {
+		t.Errorf("empty audit dir = %+v, want ok", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "s-2026-01-01.jsonl"), []byte(`{"sequence_num":2}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := probeAuditChain(dir); got.Status != ComponentFailed || got.Message == "" {
+		t.Errorf("broken audit chain = %+v, want failed", got)
+	}
+}
+
+func TestProbeJWKS(t *testing.T) {
+	t.Parallel()
+
+	if got := probeJWKS(context.Background(), ""); got.Status != ComponentSkipped {
+		t.Errorf("unconfigured JWKS = %+v, want skipped", got)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jwks":
+			w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"k1"}]}`))
+		case "/empty":
+			w.Write([]byte(`{"keys":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if got := probeJWKS(ctx, server.URL+"/jwks"); got.Status != ComponentOK || got.Details["keys"] != "1" {
+		t.Errorf("valid JWKS = %+v, want ok with 1 key", got)
+	}
+	if got := probeJWKS(ctx, server.URL+"/empty"); got.Status != ComponentDegraded {
+		t.Errorf("empty JWKS = %+v, want degraded", got)
+	}
+	if got := probeJWKS(ctx, server.URL+"/missing"); got.Status != ComponentFailed {
+		t.Errorf("missing JWKS = %+v, want failed", got)
+	}
+}
+
+func TestProbeDiskSpace(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if got := probeDiskSpace(dir, 1e9); got.Status != ComponentDegraded && got.Status != ComponentSkipped {
+		t.Errorf("probeDiskSpace(huge minimum) = %+v, want degraded", got)
+	}
+	if got := probeDiskSpace(dir, 1e-9); got.Status != ComponentOK && got.Status != ComponentSkipped {
+		t.Errorf("probeDiskSpace(tiny minimum) = %+v, want ok", got)
+	}
+}
@@ -20,6 +20,7 @@ type HistoryOptions struct {
 	Stats     bool   // show statistics instead of entries
 	Limit     int    // pagination limit
 	Offset    int    // pagination offset
+	Cursor    string // opaque pagination cursor (replaces Offset)
 }
 
 // HistoryOutput is the structured output for --robot-history
@@ -178,7 +179,11 @@ func GetHistory(opts HistoryOptions) (*HistoryOutput, error) {
 	}
 
 	output.Filtered = len(filtered)
-	if paged, page := ApplyPagination(filtered, PaginationOptions{Limit: opts.Limit, Offset: opts.Offset}); page != nil {
+	paged, page, err := ApplyKeyedPagination(filtered, historyPageKey, false, PaginationOptions{Limit: opts.Limit, Offset: opts.Offset, Cursor: opts.Cursor})
+	if err != nil {
+		return nil, err
+	}
+	if page != nil {
 		output.Entries = paged
 		output.Pagination = page
 	} else {
@@ -189,6 +194,12 @@ func GetHistory(opts HistoryOptions) (*HistoryOutput, error) {
 	return output, nil
 }
 
+// historyPageKey orders history entries chronologically; the entry ID
+// breaks ties.
+func historyPageKey(e history.HistoryEntry) string {
+	return e.Timestamp.UTC().Format("2006-01-02T15:04:05.000000000Z") + "|" + e.ID
+}
+
 // PrintHistory outputs command history as JSON.
 // This is a thin wrapper around GetHistory() for CLI output.
 func PrintHistory(opts HistoryOptions) error {
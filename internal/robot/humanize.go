@@ -0,0 +1,123 @@
+// Package robot provides machine-readable output for AI agents.
+// humanize.go normalizes timestamps in robot output to UTC and, with
+// --humanize, adds human-readable siblings such as "3m ago".
+package robot
+
+import (
+	"strings"
+	"time"
+)
+
+// Humanize controls --humanize. When set, every timestamp field "x" in robot
+// output gains an "x_human" sibling relative to now ("3m ago", "in 5m"), and
+// every duration field "x_seconds" or "x_ms" gains "x_human" ("3m 12s").
+// Machine fields are never changed beyond being normalized to UTC.
+var Humanize bool
+
+// humanizeNow is the reference time for relative timestamps; tests override it.
+var humanizeNow = time.Now
+
+// HumanizeTime describes t relative to now: "just now", "3m ago" or "in 2h 5m".
+func HumanizeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d > -time.Second && d < time.Second:
+		return "just now"
+	case d > 0:
+		return formatDuration(d.Truncate(time.Second)) + " ago"
+	default:
+		return "in " + formatDuration((-d).Truncate(time.Second))
+	}
+}
+
+// HumanizeDuration describes d as "45s", "3m 12s" or "2h 5m".
+func HumanizeDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + formatDuration(-d)
+	}
+	return formatDuration(d)
+}
+
+// applyTimeFields rewrites RFC3339 timestamps with a non-UTC offset to UTC
+// and, with Humanize, adds "_human" siblings. The payload is returned as is
+// when there is nothing to change, so its field order is kept.
+func applyTimeFields(payload any) any {
+	normalized, err := normalizePayload(payload)
+	if err != nil {
+		return payload
+	}
+	if !normalizeTimes(normalized, humanizeNow()) {
+		return payload
+	}
+	return normalized
+}
+
+// normalizeTimes walks value in place and reports whether it changed anything.
+func normalizeTimes(value any, now time.Time) bool {
+	changed := false
+	switch typed := value.(type) {
+	case map[string]any:
+		human := map[string]string{}
+		for key, val := range typed {
+			switch v := val.(type) {
+			case string:
+				t, utc, ok := parseTimestamp(v)
+				if !ok {
+					continue
+				}
+				if utc != v {
+					typed[key] = utc
+					changed = true
+				}
+				if Humanize && !t.IsZero() {
+					human[key+"_human"] = HumanizeTime(t, now)
+				}
+			case float64:
+				if !Humanize {
+					continue
+				}
+				if base, ok := strings.CutSuffix(key, "_seconds"); ok {
+					human[base+"_human"] = HumanizeDuration(time.Duration(v * float64(time.Second)).Truncate(time.Second))
+				} else if base, ok := strings.CutSuffix(key, "_ms"); ok {
+					human[base+"_human"] = HumanizeDuration(time.Duration(v * float64(time.Millisecond)).Truncate(time.Second))
+				}
+			default:
+				if normalizeTimes(val, now) {
+					changed = true
+				}
+			}
+		}
+		for key, text := range human {
+			if _, exists := typed[key]; !exists {
+				typed[key] = text
+				changed = true
+			}
+		}
+	case []any:
+		for _, item := range typed {
+			if normalizeTimes(item, now) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// parseTimestamp recognizes RFC3339 strings and returns the time and its
+// UTC form, keeping fractional seconds when present.
+func parseTimestamp(s string) (time.Time, string, bool) {
+	if len(s) < len("2006-01-02T15:04:05Z") || s[4] != '-' || s[10] != 'T' {
+		return time.Time{}, "", false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	if strings.HasSuffix(s, "Z") {
+		return t, s, true
+	}
+	if strings.Contains(s[19:], ".") {
+		return t, t.UTC().Format(time.RFC3339Nano), true
+	}
+	return t, FormatTimestamp(t), true
+}
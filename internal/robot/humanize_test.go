@@ -0,0 +1,71 @@
+package robot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeTime(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[time.Duration]string{
+		0:                                    "just now",
+		-3 * time.Minute:                     "3m ago",
+		-(2*time.Hour + 5*time.Minute):       "2h 5m ago",
+		90 * time.Second:                     "in 1m 30s",
+		-(45*time.Second + time.Millisecond): "45s ago",
+	}
+	for offset, want := range cases {
+		if got := HumanizeTime(now.Add(offset), now); got != want {
+			t.Errorf("HumanizeTime(now%+v) = %q, want %q", offset, got, want)
+		}
+	}
+}
+
+func TestApplyTimeFields(t *testing.T) {
+	prevHumanize, prevNow := Humanize, humanizeNow
+	t.Cleanup(func() { Humanize, humanizeNow = prevHumanize, prevNow })
+	humanizeNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	Humanize = false
+	payload := map[string]any{"timestamp": "2026-03-01T11:57:00Z", "name": "proj"}
+	if got := applyTimeFields(payload); got.(map[string]any)["timestamp"] != "2026-03-01T11:57:00Z" || len(got.(map[string]any)) != 2 {
+		t.Errorf("UTC payload without --humanize should be unchanged, got %v", got)
+	}
+
+	zone := time.FixedZone("EST", -5*3600)
+	out, ok := applyTimeFields(struct {
+		At time.Time `json:"at"`
+	}{At: time.Date(2026, 3, 1, 6, 0, 0, 500, zone)}).(map[string]any)
+	if !ok || out["at"] != "2026-03-01T11:00:00.0000005Z" {
+		t.Errorf("local time should be converted to UTC, got %v", out)
+	}
+	if _, ok := out["at_human"]; ok {
+		t.Error("no _human fields without --humanize")
+	}
+
+	Humanize = true
+	out = applyTimeFields(map[string]any{
+		"timestamp":      "2026-03-01T11:57:00Z",
+		"uptime_seconds": 192.0,
+		"latency_ms":     1500.0,
+		"items":          []any{map[string]any{"created_at": "2026-03-01T14:00:00+02:00"}},
+		"nothing":        "0001-01-01T00:00:00Z",
+	}).(map[string]any)
+	want := map[string]string{
+		"timestamp_human": "3m ago",
+		"uptime_human":    "3m 12s",
+		"latency_human":   "1s",
+	}
+	for key, text := range want {
+		if out[key] != text {
+			t.Errorf("%s = %v, want %q", key, out[key], text)
+		}
+	}
+	item := out["items"].([]any)[0].(map[string]any)
+	if item["created_at"] != "2026-03-01T12:00:00Z" || item["created_at_human"] != "just now" {
+		t.Errorf("nested item = %v", item)
+	}
+	if _, ok := out["nothing_human"]; ok {
+		t.Error("zero time should not be humanized")
+	}
+}
@@ -0,0 +1,143 @@
+package robot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+)
+
+// ErrCodeJobNotFound is returned for unknown background job IDs.
+const ErrCodeJobNotFound = "JOB_NOT_FOUND"
+
+// JobsOutput is the response for `ntm robot job status` without an ID.
+type JobsOutput struct {
+	RobotResponse
+	Dir   string      `json:"dir"`
+	Count int         `json:"count"`
+	Jobs  []*jobs.Job `json:"jobs"` // Newest first
+}
+
+// JobOutput is the response for `ntm robot job submit`, `status <id>` and
+// `cancel`.
+type JobOutput struct {
+	RobotResponse
+	Job *jobs.Job `json:"job,omitempty"`
+}
+
+// JobResultOutput is the response for `ntm robot job result`: the job's
+// partial results so far and, once it completed, its result. The result is
+// the command's JSON output, or its text output as Output.
+type JobResultOutput struct {
+	RobotResponse
+	Job      *jobs.Job         `json:"job,omitempty"`
+	Partials []json.RawMessage `json:"partials"`
+	Result   json.RawMessage   `json:"result,omitempty"`
+	Output   string            `json:"output,omitempty"`
+}
+
+func jobError(err error) RobotResponse {
+	if errors.Is(err, jobs.ErrNotFound) {
+		return NewErrorResponse(err, ErrCodeJobNotFound, "Use 'ntm robot job status' to list jobs")
+	}
+	return NewErrorResponse(err, ErrCodeInternalError, "Check that the jobs directory is readable")
+}
+
+// GetJobs lists the jobs in store.
+func GetJobs(store *jobs.Store) *JobsOutput {
+	out := &JobsOutput{RobotResponse: NewRobotResponse(true), Dir: store.Dir, Jobs: []*jobs.Job{}}
+	list, err := store.List()
+	if err != nil {
+		out.RobotResponse = jobError(err)
+		return out
+	}
+	out.Jobs, out.Count = list, len(list)
+	return out
+}
+
+// GetJob returns one job's status and progress.
+func GetJob(store *jobs.Store, id string) *JobOutput {
+	job, err := store.Get(id)
+	if err != nil {
+		return &JobOutput{RobotResponse: jobError(err)}
+	}
+	return &JobOutput{RobotResponse: NewRobotResponse(true), Job: job}
+}
+
+// SubmitJob starts args as a background job.
+func SubmitJob(store *jobs.Store, args []string) *JobOutput {
+	if len(args) == 0 {
+		return &JobOutput{RobotResponse: NewErrorResponse(fmt.Errorf("no command to run"), ErrCodeInvalidArgs, "Pass the ntm arguments after --, e.g. ntm robot job submit -- robot summarize --session s --pane 1")}
+	}
+	job, err := store.Submit(args)
+	if err != nil {
+		return &JobOutput{RobotResponse: NewErrorResponse(err, ErrCodeInternalError, "Check that the ntm binary can be executed"), Job: job}
+	}
+	return &JobOutput{RobotResponse: NewRobotResponse(true), Job: job}
+}
+
+// CancelJob asks a job to stop.
+func CancelJob(store *jobs.Store, id string) *JobOutput {
+	job, err := store.Cancel(id)
+	if err != nil {
+		if job != nil {
+			return &JobOutput{RobotResponse: NewErrorResponse(err, ErrCodeInvalidArgs, "Only pending or running jobs can be cancelled"), Job: job}
+		}
+		return &JobOutput{RobotResponse: jobError(err)}
+	}
+	return &JobOutput{RobotResponse: NewRobotResponse(true), Job: job}
+}
+
+// GetJobResult returns a job's partial results and, once it completed, its
+// result.
+func GetJobResult(store *jobs.Store, id string) *JobResultOutput {
+	job, err := store.Get(id)
+	if err != nil {
+		return &JobResultOutput{RobotResponse: jobError(err), Partials: []json.RawMessage{}}
+	}
+	out := &JobResultOutput{RobotResponse: NewRobotResponse(true), Job: job}
+	if out.Partials, err = store.Partials(id); err != nil {
+		out.RobotResponse = jobError(err)
+		return out
+	}
+	if job.Status != jobs.StatusCompleted && job.Status != jobs.StatusFailed {
+		return out
+	}
+	data, err := store.Output(id)
+	if err != nil {
+		out.RobotResponse = jobError(err)
+		return out
+	}
+	if json.Valid(data) {
+		out.Result = data
+	} else {
+		out.Output = string(data)
+	}
+	return out
+}
+
+// PrintJobs handles `ntm robot job status` without an ID.
+func PrintJobs(store *jobs.Store) error {
+	return encodeJSON(GetJobs(store))
+}
+
+// PrintJob handles `ntm robot job status <id>`.
+func PrintJob(store *jobs.Store, id string) error {
+	return encodeJSON(GetJob(store, id))
+}
+
+// PrintSubmitJob handles `ntm robot job submit`.
+func PrintSubmitJob(store *jobs.Store, args []string) error {
+	return encodeJSON(SubmitJob(store, args))
+}
+
+// PrintCancelJob handles `ntm robot job cancel`.
+func PrintCancelJob(store *jobs.Store, id string) error {
+	return encodeJSON(CancelJob(store, id))
+}
+
+// PrintJobResult handles `ntm robot job result`.
+func PrintJobResult(store *jobs.Store, id string) error {
+	return encodeJSON(GetJobResult(store, id))
+}
@@ -0,0 +1,67 @@
+package robot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+)
+
+// finishTestJob marks a job completed with stdout as its output, as its
+// worker would.
+func finishTestJob(t *testing.T, store *jobs.Store, job *jobs.Job, stdout string) {
+	t.Helper()
+	job.Status = jobs.StatusCompleted
+	data, err := json.Marshal(job)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(store.Dir, job.ID)
+	if err := os.WriteFile(filepath.Join(dir, jobs.JobFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, jobs.StdoutFile), []byte(stdout), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetJobResult(t *testing.T) {
+	t.Parallel()
+	store := jobs.NewStore(t.TempDir())
+
+	if out := GetJobResult(store, "missing"); out.Success || out.ErrorCode != ErrCodeJobNotFound {
+		t.Errorf("unknown job = %+v, want %s", out.RobotResponse, ErrCodeJobNotFound)
+	}
+	if out := SubmitJob(store, nil); out.Success || out.ErrorCode != ErrCodeInvalidArgs {
+		t.Errorf("submit without args = %+v", out.RobotResponse)
+	}
+
+	job, err := store.Create([]string{"robot", "blame", "main.go", "--session", "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Reporter(job.ID).Partial(map[string]int{"events": 3})
+
+	// A running job has partial results but no result yet.
+	out := GetJobResult(store, job.ID)
+	if !out.Success || len(out.Partials) != 1 || out.Result != nil || out.Output != "" {
+		t.Fatalf("pending result = %+v", out)
+	}
+
+	finishTestJob(t, store, job, `{"success":true,"total_events":3}`+"\n")
+	out = GetJobResult(store, job.ID)
+	if !out.Success || out.Job.Status != jobs.StatusCompleted || !json.Valid(out.Result) || out.Output != "" {
+		t.Errorf("JSON result = %+v", out)
+	}
+
+	finishTestJob(t, store, job, "plain text\n")
+	if out := GetJobResult(store, job.ID); out.Result != nil || out.Output != "plain text\n" {
+		t.Errorf("text result = %+v", out)
+	}
+
+	if out := CancelJob(store, job.ID); out.Success || out.Job == nil {
+		t.Errorf("cancel of a finished job = %+v", out)
+	}
+}
@@ -279,7 +279,7 @@ func GetMailCheck(opts MailCheckOptions) (*MailCheckOutput, error) {
 			ThreadID:   msg.ThreadID,
 			Importance: msg.Importance,
 			Read:       msg.ReadAt != nil,
-			Timestamp:  msg.CreatedTS.Format(time.RFC3339),
+			Timestamp:  FormatTimestamp(msg.CreatedTS.Time),
 		}
 	}
 
@@ -324,7 +324,7 @@ func GetMailCheck(opts MailCheckOptions) (*MailCheckOutput, error) {
 		}
 
 		if oldestUnread != nil {
-			ts := oldestUnread.Format(time.RFC3339)
+			ts := FormatTimestampPtr(oldestUnread)
 			hints.OldestUnread = &ts
 		}
 
@@ -310,6 +310,8 @@ func writeAlertsSection(sb *strings.Builder, cfg *config.Config, opts MarkdownOp
 			cfg.Alerts.ResolvedPruneMinutes,
 			cfg.ProjectsBase,
 		)
+		alertCfg.AgentCPUPercent = cfg.Alerts.AgentCPUPercent
+		alertCfg.AgentMemoryPercent = cfg.Alerts.AgentMemoryPercent
 	} else {
 		alertCfg = alerts.DefaultConfig()
 	}
@@ -291,7 +291,7 @@ func (m *Monitor) emitWarning(w Warning) {
 func (m *Monitor) emitError(msg string, err error) {
 	w := Warning{
 		Level:     LevelAlert,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Timestamp: FormatTimestamp(time.Now()),
 		Session:   m.config.Session,
 		Message:   fmt.Sprintf("%s: %v", msg, err),
 	}
@@ -1,9 +1,18 @@
 package robot
 
-// PaginationOptions configures limit/offset pagination.
+import (
+	"sort"
+
+	"github.com/Dicklesworthstone/ntm/internal/pagination"
+)
+
+// PaginationOptions configures limit/offset or limit/cursor pagination.
 type PaginationOptions struct {
 	Limit  int
 	Offset int
+	// Cursor is an opaque next_page_cursor from a previous page. It takes
+	// the place of Offset for outputs with a stable ordering.
+	Cursor string
 }
 
 // PaginationInfo describes pagination state for array outputs.
@@ -14,6 +23,9 @@ type PaginationInfo struct {
 	Total      int  `json:"total"`
 	HasMore    bool `json:"has_more"`
 	NextCursor *int `json:"next_cursor,omitempty"`
+	// NextPageCursor resumes after the last item of this page even if items
+	// are added or removed in the meantime. Pass it back as --robot-cursor.
+	NextPageCursor string `json:"next_page_cursor,omitempty"`
 }
 
 // ApplyPagination slices items based on limit/offset and returns pagination metadata.
@@ -61,6 +73,53 @@ func ApplyPagination[T any](items []T, opts PaginationOptions) ([]T, *Pagination
 	return paged, info
 }
 
+// ApplyKeyedPagination pages items ordered by a unique key (ascending, or
+// descending if desc). With opts.Cursor it resumes after the cursor's key;
+// otherwise it behaves like ApplyPagination on the ordered items. Either
+// way, pages that have more items carry a NextPageCursor.
+func ApplyKeyedPagination[T any](items []T, key func(T) string, desc bool, opts PaginationOptions) ([]T, *PaginationInfo, error) {
+	if opts.Cursor == "" {
+		if opts.Limit <= 0 && opts.Offset <= 0 {
+			return items, nil, nil
+		}
+		sorted := make([]T, len(items))
+		copy(sorted, items)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if desc {
+				return key(sorted[i]) > key(sorted[j])
+			}
+			return key(sorted[i]) < key(sorted[j])
+		})
+		paged, info := ApplyPagination(sorted, opts)
+		if info != nil && info.HasMore && len(paged) > 0 {
+			info.NextPageCursor = pagination.Encode(key(paged[len(paged)-1]))
+		}
+		return paged, info, nil
+	}
+
+	page, err := pagination.Slice(items, key, desc, opts.Cursor, opts.Limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = len(page.Items)
+	}
+	info := &PaginationInfo{
+		Limit:          limit,
+		Offset:         page.Offset,
+		Count:          len(page.Items),
+		Total:          page.Total,
+		HasMore:        page.HasMore(),
+		NextPageCursor: page.NextCursor,
+	}
+	if info.HasMore {
+		n := page.Offset + len(page.Items)
+		info.NextCursor = &n
+	}
+	return page.Items, info, nil
+}
+
 func paginationHintOffsets(page *PaginationInfo) (*int, *int) {
 	if page == nil || page.Limit <= 0 {
 		return nil, nil
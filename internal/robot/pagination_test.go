@@ -248,3 +248,34 @@ func TestApplyPagination_LargePayloadDeterministicNextCursor(t *testing.T) {
 		t.Fatalf("expected next_cursor=100 and has_more=true, got %+v", page)
 	}
 }
+
+func TestApplyKeyedPagination_Cursor(t *testing.T) {
+	items := []string{"gamma", "alpha", "delta", "beta"}
+	id := func(s string) string { return s }
+
+	first, page, err := ApplyKeyedPagination(items, id, false, PaginationOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, []string{"alpha", "beta"}) || page.NextPageCursor == "" {
+		t.Fatalf("first page = %v, %+v", first, page)
+	}
+
+	// "alpha" disappears before the next request; the cursor still resumes
+	// right after "beta" where an offset of 2 would skip "delta".
+	second, page, err := ApplyKeyedPagination([]string{"gamma", "delta", "beta"}, id, false,
+		PaginationOptions{Limit: 2, Cursor: page.NextPageCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(second, []string{"delta", "gamma"}) {
+		t.Fatalf("second page = %v", second)
+	}
+	if page.HasMore || page.NextPageCursor != "" || page.Offset != 1 {
+		t.Errorf("last page info = %+v", page)
+	}
+
+	if _, _, err := ApplyKeyedPagination(items, id, false, PaginationOptions{Cursor: "%%"}); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+}
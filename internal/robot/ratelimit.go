@@ -0,0 +1,334 @@
+// Package robot provides machine-readable output for AI agents.
+// ratelimit.go implements `ntm robot ratelimit`.
+package robot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Reasons a pane is blocked by rate limiting.
+const (
+	RateLimitReasonCooldown      = "provider_cooldown" // tracker cooldown of the pane's provider or account
+	RateLimitReasonAccountPaused = "account_paused"    // Codex throttle paused the pane's pool account
+	RateLimitReasonThrottle      = "codex_throttle"    // Codex throttle paused after this pane's rate limit
+	RateLimitReasonOutput        = "output"            // the pane shows a rate-limit message now
+)
+
+// RateLimitOptions configures `ntm robot ratelimit`.
+type RateLimitOptions struct {
+	ProjectDir string
+	// Session adds the per-pane impact for the session's agent panes.
+	Session string
+	// MaxConcurrent is the Codex throttle's concurrency ceiling.
+	MaxConcurrent int
+	// Schedule adjusts learned delays the way spawn and send do (may be nil).
+	Schedule *ratelimit.Schedule
+	// Lines is how many lines of each pane to scan for a rate-limit message.
+	Lines int
+}
+
+// RateLimitOutput is the response for `ntm robot ratelimit`.
+type RateLimitOutput struct {
+	RobotResponse
+	ProjectDir    string              `json:"project_dir"`
+	Session       string              `json:"session,omitempty"`
+	Summary       string              `json:"summary"`
+	Providers     []RateLimitProvider `json:"providers"`
+	CodexThrottle RateLimitThrottle   `json:"codex_throttle"`
+	Panes         []RateLimitPane     `json:"panes,omitempty"`
+	BlockedPanes  int                 `json:"blocked_panes"`
+}
+
+// RateLimitProvider is the learned state of one provider or provider
+// account (see ratelimit.AccountKey).
+type RateLimitProvider struct {
+	Key                      string     `json:"key"`
+	Provider                 string     `json:"provider"`
+	Account                  string     `json:"account,omitempty"`
+	LearnedDelayMs           int64      `json:"learned_delay_ms"`
+	EffectiveDelayMs         int64      `json:"effective_delay_ms"` // Learned delay after schedule rules
+	InCooldown               bool       `json:"in_cooldown"`
+	CooldownUntil            *time.Time `json:"cooldown_until,omitempty"`
+	CooldownRemainingSeconds int        `json:"cooldown_remaining_seconds,omitempty"`
+	LastRateLimit            *time.Time `json:"last_rate_limit,omitempty"`
+	TotalRateLimits          int        `json:"total_rate_limits"`
+	TotalSuccesses           int        `json:"total_successes"`
+	ConsecutiveSuccesses     int        `json:"consecutive_successes"`
+}
+
+// RateLimitThrottle is the Codex launch throttle as last saved by the
+// session monitor.
+type RateLimitThrottle struct {
+	Phase                    ratelimit.ThrottlePhase  `json:"phase"`
+	CooldownUntil            *time.Time               `json:"cooldown_until,omitempty"`
+	CooldownRemainingSeconds int                      `json:"cooldown_remaining_seconds,omitempty"`
+	AllowedConcurrent        int                      `json:"allowed_concurrent"`
+	MaxConcurrent            int                      `json:"max_concurrent"`
+	RateLimitCount           int                      `json:"rate_limit_count"`
+	AffectedPanes            []string                 `json:"affected_panes,omitempty"`
+	PausedAccounts           []RateLimitPausedAccount `json:"paused_accounts,omitempty"`
+	Guidance                 string                   `json:"guidance"`
+}
+
+// RateLimitPausedAccount is a Codex pool account the throttle paused.
+type RateLimitPausedAccount struct {
+	Account          string    `json:"account"`
+	Until            time.Time `json:"until"`
+	RemainingSeconds int       `json:"remaining_seconds"`
+}
+
+// RateLimitPane is the rate-limit impact on one agent pane.
+type RateLimitPane struct {
+	Pane      int    `json:"pane"`
+	PaneID    string `json:"pane_id"`
+	AgentType string `json:"agent_type"`
+	Account   string `json:"account,omitempty"`
+	Key       string `json:"key"` // Tracker key the pane's sends are paced by
+	Blocked   bool   `json:"blocked"`
+	// BlockedUntil is the latest known end of the pane's blocks; it is
+	// absent for a blocked pane whose rate-limit message gives no wait.
+	BlockedUntil            *time.Time `json:"blocked_until,omitempty"`
+	BlockedRemainingSeconds int        `json:"blocked_remaining_seconds,omitempty"`
+	Reasons                 []string   `json:"reasons,omitempty"`
+}
+
+// rateLimitPaneInput is what the impact of a pane is computed from.
+type rateLimitPaneInput struct {
+	Pane      int
+	PaneID    string
+	AgentType string
+	Account   string
+	Output    string
+}
+
+// GetRateLimit merges the project's rate-limit tracker, the Codex throttle
+// and, with a session, the live state of its agent panes.
+func GetRateLimit(opts RateLimitOptions) (*RateLimitOutput, error) {
+	out := &RateLimitOutput{
+		RobotResponse: NewRobotResponse(true),
+		ProjectDir:    opts.ProjectDir,
+		Session:       opts.Session,
+		Providers:     []RateLimitProvider{},
+	}
+
+	tracker := ratelimit.NewRateLimitTracker(opts.ProjectDir)
+	if err := tracker.LoadFromDir(opts.ProjectDir); err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that .ntm/rate_limits.json is readable")
+		return out, nil
+	}
+	tracker.SetSchedule(opts.Schedule)
+	throttle := ratelimit.NewCodexThrottle(opts.MaxConcurrent)
+	if err := throttle.LoadFromDir(opts.ProjectDir); err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that .ntm/codex_throttle.json is readable")
+		return out, nil
+	}
+
+	now := time.Now().UTC()
+	out.Providers = rateLimitProviders(tracker, now)
+	out.CodexThrottle = rateLimitThrottle(throttle.Status(), now)
+
+	if opts.Session != "" {
+		if !tmux.SessionExists(opts.Session) {
+			out.RobotResponse = NewErrorResponse(
+				fmt.Errorf("session '%s' not found", opts.Session),
+				ErrCodeSessionNotFound,
+				"Omit the session to report provider state only",
+			)
+			return out, nil
+		}
+		panes, err := tmux.GetPanes(opts.Session)
+		if err != nil {
+			out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check tmux session state")
+			return out, nil
+		}
+		lines := opts.Lines
+		if lines <= 0 {
+			lines = 20
+		}
+		out.Panes = []RateLimitPane{}
+		for _, pane := range panes {
+			if pane.Type == tmux.AgentUser {
+				continue
+			}
+			in := rateLimitPaneInput{Pane: pane.Index, PaneID: pane.ID, AgentType: string(pane.Type)}
+			in.Account, _ = tmux.GetPaneOption(pane.ID, tmux.PaneAccountOption)
+			in.Account = strings.TrimSpace(in.Account)
+			in.Output, _ = tmux.CapturePaneOutput(pane.ID, lines)
+			out.Panes = append(out.Panes, rateLimitPaneImpact(in, tracker, out.CodexThrottle, now))
+		}
+	}
+
+	for _, p := range out.Panes {
+		if p.Blocked {
+			out.BlockedPanes++
+		}
+	}
+	out.Summary = rateLimitSummary(out)
+	return out, nil
+}
+
+// PrintRateLimit handles `ntm robot ratelimit`.
+func PrintRateLimit(opts RateLimitOptions) error {
+	out, err := GetRateLimit(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// rateLimitProviders lists every key the tracker knows, sorted.
+func rateLimitProviders(tracker *ratelimit.RateLimitTracker, now time.Time) []RateLimitProvider {
+	keys := tracker.GetAllProviders()
+	sort.Strings(keys)
+	providers := make([]RateLimitProvider, 0, len(keys))
+	for _, key := range keys {
+		state := tracker.GetProviderState(key)
+		if state == nil {
+			continue
+		}
+		provider, account := ratelimit.SplitKey(key)
+		p := RateLimitProvider{
+			Key:                  key,
+			Provider:             provider,
+			Account:              account,
+			LearnedDelayMs:       state.CurrentDelay.Milliseconds(),
+			EffectiveDelayMs:     tracker.GetOptimalDelay(key).Milliseconds(),
+			TotalRateLimits:      state.TotalRateLimits,
+			TotalSuccesses:       state.TotalSuccesses,
+			ConsecutiveSuccesses: state.ConsecutiveSuccess,
+		}
+		if remaining := tracker.CooldownRemaining(key); remaining > 0 {
+			until := now.Add(remaining).Truncate(time.Second)
+			p.InCooldown = true
+			p.CooldownUntil = &until
+			p.CooldownRemainingSeconds = ceilSeconds(remaining)
+		}
+		if !state.LastRateLimit.IsZero() {
+			last := state.LastRateLimit.UTC()
+			p.LastRateLimit = &last
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// rateLimitThrottle converts a throttle snapshot to absolute times.
+func rateLimitThrottle(st ratelimit.CodexThrottleStatus, now time.Time) RateLimitThrottle {
+	t := RateLimitThrottle{
+		Phase:             st.Phase,
+		AllowedConcurrent: st.AllowedConcurrent,
+		MaxConcurrent:     st.MaxConcurrent,
+		RateLimitCount:    st.RateLimitCount,
+		AffectedPanes:     st.AffectedPanes,
+		Guidance:          st.Guidance,
+	}
+	if len(t.AffectedPanes) == 0 {
+		t.AffectedPanes = nil
+	}
+	if st.CooldownRemaining > 0 {
+		until := now.Add(st.CooldownRemaining).Truncate(time.Second)
+		t.CooldownUntil = &until
+		t.CooldownRemainingSeconds = ceilSeconds(st.CooldownRemaining)
+	}
+	for account, remaining := range st.PausedAccounts {
+		t.PausedAccounts = append(t.PausedAccounts, RateLimitPausedAccount{
+			Account:          account,
+			Until:            now.Add(remaining).Truncate(time.Second),
+			RemainingSeconds: ceilSeconds(remaining),
+		})
+	}
+	sort.Slice(t.PausedAccounts, func(i, j int) bool {
+		return t.PausedAccounts[i].Account < t.PausedAccounts[j].Account
+	})
+	return t
+}
+
+// rateLimitPaneImpact reports whether a pane is blocked, why, and until
+// when. The pane is blocked while its provider or account cools down, while
+// the Codex throttle pauses its account or is paused after its rate limit,
+// and while its output shows a rate-limit message.
+func rateLimitPaneImpact(in rateLimitPaneInput, tracker *ratelimit.RateLimitTracker, throttle RateLimitThrottle, now time.Time) RateLimitPane {
+	p := RateLimitPane{
+		Pane:      in.Pane,
+		PaneID:    in.PaneID,
+		AgentType: in.AgentType,
+		Account:   in.Account,
+		Key:       ratelimit.AccountKey(in.AgentType, in.Account),
+	}
+	var until time.Time
+	block := func(reason string, end time.Time) {
+		p.Reasons = append(p.Reasons, reason)
+		if end.After(until) {
+			until = end
+		}
+	}
+
+	if remaining := tracker.CooldownRemaining(p.Key); remaining > 0 {
+		block(RateLimitReasonCooldown, now.Add(remaining))
+	}
+	if in.AgentType == string(tmux.AgentCodex) {
+		for _, a := range throttle.PausedAccounts {
+			if in.Account != "" && a.Account == in.Account {
+				block(RateLimitReasonAccountPaused, a.Until)
+			}
+		}
+		if throttle.Phase == ratelimit.ThrottlePaused && throttle.CooldownUntil != nil {
+			for _, id := range throttle.AffectedPanes {
+				if id == in.PaneID {
+					block(RateLimitReasonThrottle, *throttle.CooldownUntil)
+				}
+			}
+		}
+	}
+	if det := ratelimit.DetectRateLimitForAgent(in.Output, in.AgentType); det.RateLimited {
+		var end time.Time
+		if det.WaitSeconds > 0 {
+			end = now.Add(time.Duration(det.WaitSeconds) * time.Second)
+		}
+		block(RateLimitReasonOutput, end)
+	}
+
+	p.Blocked = len(p.Reasons) > 0
+	if p.Blocked && !until.IsZero() {
+		until = until.Truncate(time.Second)
+		p.BlockedUntil = &until
+		p.BlockedRemainingSeconds = ceilSeconds(until.Sub(now))
+	}
+	return p
+}
+
+// rateLimitSummary is a one-line description for humans.
+func rateLimitSummary(out *RateLimitOutput) string {
+	var cooling []string
+	for _, p := range out.Providers {
+		if p.InCooldown {
+			cooling = append(cooling, fmt.Sprintf("%s (%s)", p.Key, HumanizeDuration(time.Duration(p.CooldownRemainingSeconds)*time.Second)))
+		}
+	}
+	var parts []string
+	if len(cooling) > 0 {
+		parts = append(parts, "cooling down: "+strings.Join(cooling, ", "))
+	} else {
+		parts = append(parts, "no provider cooling down")
+	}
+	if out.CodexThrottle.Phase != ratelimit.ThrottleNormal {
+		parts = append(parts, fmt.Sprintf("codex throttle %s (%d/%d launches)",
+			out.CodexThrottle.Phase, out.CodexThrottle.AllowedConcurrent, out.CodexThrottle.MaxConcurrent))
+	}
+	if out.Panes != nil {
+		parts = append(parts, fmt.Sprintf("%d of %d panes blocked", out.BlockedPanes, len(out.Panes)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ceilSeconds rounds d up to whole seconds, so a pane that is still
+// blocked never reports 0.
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
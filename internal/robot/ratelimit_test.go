@@ -0,0 +1,113 @@
+package robot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+)
+
+func TestGetRateLimit(t *testing.T) {
+	project := t.TempDir()
+	tracker := ratelimit.NewRateLimitTracker(project)
+	tracker.RecordRateLimitWithCooldown(ratelimit.AccountKey("cod", "work"), "send", 120)
+	tracker.RecordSuccess("cc")
+	if err := tracker.SaveToDir(project); err != nil {
+		t.Fatal(err)
+	}
+	throttle := ratelimit.NewCodexThrottle(4)
+	throttle.RecordAccountRateLimit("%2", "work", 120)
+	if err := throttle.SaveToDir(project); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GetRateLimit(RateLimitOptions{ProjectDir: project, MaxConcurrent: 4})
+	if err != nil || !out.Success {
+		t.Fatalf("GetRateLimit() = %+v, %v", out, err)
+	}
+	if len(out.Providers) != 2 || out.Providers[0].Key != "anthropic" || out.Providers[1].Key != "openai/work" {
+		t.Fatalf("providers = %+v", out.Providers)
+	}
+	work := out.Providers[1]
+	if work.Provider != "openai" || work.Account != "work" || !work.InCooldown || work.CooldownUntil == nil || work.TotalRateLimits != 1 {
+		t.Errorf("openai/work = %+v", work)
+	}
+	if out.Providers[0].InCooldown {
+		t.Errorf("anthropic in cooldown: %+v", out.Providers[0])
+	}
+	if out.CodexThrottle.MaxConcurrent != 4 || len(out.CodexThrottle.PausedAccounts) != 1 || out.CodexThrottle.PausedAccounts[0].Account != "work" {
+		t.Errorf("codex throttle = %+v", out.CodexThrottle)
+	}
+	if out.Panes != nil || !strings.Contains(out.Summary, "openai/work") {
+		t.Errorf("panes %v summary %q", out.Panes, out.Summary)
+	}
+
+	// Both renderings take the response.
+	if _, err := toonEncode(out, "\t"); err != nil {
+		t.Errorf("TOON: %v", err)
+	}
+}
+
+func TestRateLimitPaneImpact(t *testing.T) {
+	tracker := ratelimit.NewRateLimitTracker("")
+	tracker.RecordRateLimitWithCooldown("openai/work", "send", 60)
+	now := time.Now().UTC()
+	paused := now.Add(5 * time.Minute)
+	throttle := RateLimitThrottle{
+		Phase:          ratelimit.ThrottlePaused,
+		CooldownUntil:  &paused,
+		AffectedPanes:  []string{"%3"},
+		PausedAccounts: []RateLimitPausedAccount{{Account: "work", Until: now.Add(2 * time.Minute)}},
+	}
+
+	tests := []struct {
+		name        string
+		in          rateLimitPaneInput
+		wantReasons []string
+		wantUntil   time.Duration // 0: no known end
+	}{
+		{
+			name: "idle claude pane",
+			in:   rateLimitPaneInput{PaneID: "%1", AgentType: "cc", Output: "> "},
+		},
+		{
+			name:        "account cooldown and paused account",
+			in:          rateLimitPaneInput{PaneID: "%2", AgentType: "cod", Account: "work"},
+			wantReasons: []string{RateLimitReasonCooldown, RateLimitReasonAccountPaused},
+			wantUntil:   2 * time.Minute,
+		},
+		{
+			name:        "throttle paused after this pane",
+			in:          rateLimitPaneInput{PaneID: "%3", AgentType: "cod", Account: "personal"},
+			wantReasons: []string{RateLimitReasonThrottle},
+			wantUntil:   5 * time.Minute,
+		},
+		{
+			name:        "rate limit message without a wait",
+			in:          rateLimitPaneInput{PaneID: "%4", AgentType: "cc", Output: "Error: rate limit exceeded"},
+			wantReasons: []string{RateLimitReasonOutput},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := rateLimitPaneImpact(tt.in, tracker, throttle, now)
+			if p.Blocked != (len(tt.wantReasons) > 0) || strings.Join(p.Reasons, ",") != strings.Join(tt.wantReasons, ",") {
+				t.Fatalf("blocked %v reasons %v, want %v", p.Blocked, p.Reasons, tt.wantReasons)
+			}
+			if tt.wantUntil == 0 {
+				if p.BlockedUntil != nil {
+					t.Errorf("blocked until %v, want unknown", p.BlockedUntil)
+				}
+				return
+			}
+			want := now.Add(tt.wantUntil).Truncate(time.Second)
+			if p.BlockedUntil == nil || !p.BlockedUntil.Equal(want) {
+				t.Errorf("blocked until %v, want %v", p.BlockedUntil, want)
+			}
+			if p.BlockedRemainingSeconds <= 0 {
+				t.Errorf("remaining = %d", p.BlockedRemainingSeconds)
+			}
+		})
+	}
+}
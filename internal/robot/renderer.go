@@ -17,6 +17,12 @@
 //   - FormatTOON: TOON format (token-efficient tabular encoding)
 //   - FormatAuto: Automatic selection based on environment (future)
 //
+// # Field Order
+//
+// Output is byte-for-byte stable for the same data. JSON follows
+// encoding/json: struct fields in declaration order, map keys sorted. TOON
+// sorts every object's keys and every table's columns by name.
+//
 // # Content-Type Hints
 //
 // Each renderer provides a content-type hint for tooling integration:
@@ -41,6 +47,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 )
 
 // RobotFormat specifies the output serialization format for robot commands.
@@ -155,8 +162,35 @@ func (r *JSONRenderer) Format() RobotFormat {
 type TOONRenderer struct {
 	// Delimiter is the field separator. Default: "\t" (tab).
 	Delimiter string
+
+	// Options lays out tables. toon_rust has no such options, so a
+	// renderer with any set encodes with the pure Go encoder instead.
+	Options TOONOptions
+}
+
+// TOONOptions shrink or line up large TOON tables. Both are off by default.
+type TOONOptions struct {
+	// Align pads each table cell to its column's width, so columns line
+	// up for readers that scan them.
+	Align bool
+
+	// Abbreviations maps field names to short table header aliases, e.g.
+	// "agent_type" to "t". A table that uses an alias gets a legend line
+	// after its header naming the fields: " # legend: t=agent_type". An
+	// alias that would clash with another column of the table is not used.
+	// Abbreviated output is meant for reading, not for decoding back.
+	Abbreviations map[string]string
+}
+
+// IsZero reports whether no option is set.
+func (o TOONOptions) IsZero() bool {
+	return !o.Align && len(o.Abbreviations) == 0
 }
 
+// OutputTOONOptions are the options of the TOON renderer robot commands
+// use. Set them from config before calling Print* functions.
+var OutputTOONOptions TOONOptions
+
 // NewTOONRenderer creates a TOON renderer with default settings.
 func NewTOONRenderer() *TOONRenderer {
 	return &TOONRenderer{
@@ -167,7 +201,13 @@ func NewTOONRenderer() *TOONRenderer {
 // Render encodes the payload as TOON.
 // Returns an error for unsupported payload shapes.
 func (r *TOONRenderer) Render(payload any) (string, error) {
-	return toonEncode(payload, r.Delimiter)
+	if r.Options.IsZero() {
+		return toonEncode(payload, r.Delimiter)
+	}
+	if _, err := json.Marshal(payload); err != nil {
+		return "", fmt.Errorf("json marshal: %w", err)
+	}
+	return toonEncodeWithOptions(payload, r.Delimiter, r.Options)
 }
 
 // ContentType returns the TOON MIME type.
@@ -214,7 +254,9 @@ func GetRenderer(format RobotFormat) Renderer {
 	case FormatJSON:
 		return defaultRenderer
 	case FormatTOON:
-		return NewTOONRenderer()
+		r := NewTOONRenderer()
+		r.Options = OutputTOONOptions
+		return r
 	case FormatAuto:
 		// Auto currently defaults to JSON
 		// Future: detect from NTM_ROBOT_FORMAT env var or config
@@ -243,7 +285,46 @@ func GetContentType(format RobotFormat) string {
 //
 //	return robot.Output(myResponse, robot.FormatJSON)
 func Output(payload any, format RobotFormat) error {
-	return OutputTo(os.Stdout, payload, format)
+	hook := currentOutputHook()
+	if hook == nil {
+		return OutputTo(os.Stdout, payload, format)
+	}
+	output, err := Render(payload, format)
+	if err != nil {
+		return err
+	}
+	hook(payload, output)
+	_, err = io.WriteString(os.Stdout, output)
+	return err
+}
+
+// OutputHook observes each payload written by Output together with its
+// rendered form.
+type OutputHook func(payload any, rendered string)
+
+var (
+	outputHookMu sync.RWMutex
+	outputHook   OutputHook
+)
+
+// SetOutputHook installs fn to observe Output and returns a function that
+// removes it. Only one hook is active at a time.
+func SetOutputHook(fn OutputHook) (remove func()) {
+	outputHookMu.Lock()
+	prev := outputHook
+	outputHook = fn
+	outputHookMu.Unlock()
+	return func() {
+		outputHookMu.Lock()
+		outputHook = prev
+		outputHookMu.Unlock()
+	}
+}
+
+func currentOutputHook() OutputHook {
+	outputHookMu.RLock()
+	defer outputHookMu.RUnlock()
+	return outputHook
 }
 
 // OutputTo renders the payload and writes it to the specified writer.
@@ -0,0 +1,203 @@
+// Package robot provides machine-readable output for AI agents.
+// reports.go implements `ntm robot reports`, the agent self-report feed.
+package robot
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Overridable for tests.
+var (
+	reportsSessionExists = tmux.SessionExists
+	reportsGetPanes      = tmux.GetPanes
+	reportsCapture       = tmux.CapturePaneOutput
+)
+
+// ReportsOptions configures `ntm robot reports`.
+type ReportsOptions struct {
+	Session string
+	// Panes limits the report to these pane indices (empty = all).
+	Panes []int
+	// Since drops journaled reports detected before this time.
+	Since time.Time
+	// Lines is how much scrollback to scan for reports not yet journaled.
+	Lines int
+	// JournalPath overrides events.DefaultJournalPath.
+	JournalPath string
+}
+
+// PaneReport is one self-report and where it came from.
+type PaneReport struct {
+	PaneIndex  int                  `json:"pane_index"`
+	Pane       string               `json:"pane,omitempty"`
+	Agent      string               `json:"agent,omitempty"`
+	ReportedAt *time.Time           `json:"reported_at,omitempty"` // When the capture pipeline saw it; unset for live-only reports
+	Source     string               `json:"source"`                // journal or live
+	Report     agent.ProgressReport `json:"report"`
+}
+
+// ReportsSummary counts panes by their latest reported status.
+type ReportsSummary struct {
+	Panes   int   `json:"panes"`
+	Blocked []int `json:"blocked"`
+	Done    []int `json:"done"`
+}
+
+// ReportsOutput is the response for `ntm robot reports`.
+type ReportsOutput struct {
+	RobotResponse
+	Session  string                `json:"session"`
+	Live     bool                  `json:"live"`   // Panes were captured (the session is running)
+	Latest   map[string]PaneReport `json:"latest"` // Keyed by pane index
+	Reports  []PaneReport          `json:"reports"`
+	Summary  ReportsSummary        `json:"summary"`
+	Protocol string                `json:"protocol"` // Instruction that makes agents self-report
+}
+
+// GetReports collects the NTM-REPORT self-reports of a session's agents:
+// those the capture pipeline journaled as agent.progress events, plus any
+// still on screen that it has not picked up yet.
+func GetReports(opts ReportsOptions) (*ReportsOutput, error) {
+	out := &ReportsOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		Latest:        map[string]PaneReport{},
+		Reports:       []PaneReport{},
+		Summary:       ReportsSummary{Blocked: []int{}, Done: []int{}},
+		Protocol:      agent.ReportProtocol,
+	}
+	if opts.Session == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session is required"), ErrCodeInvalidFlag, "Pass --session")
+		return out, nil
+	}
+	if opts.Lines <= 0 {
+		opts.Lines = tmux.LinesFullContext
+	}
+	wanted := func(idx int) bool {
+		if len(opts.Panes) == 0 {
+			return true
+		}
+		for _, p := range opts.Panes {
+			if p == idx {
+				return true
+			}
+		}
+		return false
+	}
+
+	entries, err := events.ReadJournal(opts.JournalPath, events.JournalFilter{
+		Session: opts.Session,
+		From:    opts.Since,
+		Types:   []string{events.WebhookAgentProgress},
+	})
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInternalError, "Check that the event journal is readable")
+		return out, nil
+	}
+	latest := map[int]PaneReport{}
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		ev, ok := entry.Decode().(events.WebhookEvent)
+		if !ok {
+			continue
+		}
+		idx, err := strconv.Atoi(ev.Details["pane_index"])
+		if err != nil || !wanted(idx) {
+			continue
+		}
+		var report agent.ProgressReport
+		if err := json.Unmarshal([]byte(ev.Details["report"]), &report); err != nil {
+			continue
+		}
+		at := entry.Timestamp
+		r := PaneReport{PaneIndex: idx, Pane: ev.Pane, Agent: ev.Agent, ReportedAt: &at, Source: "journal", Report: report}
+		out.Reports = append(out.Reports, r)
+		latest[idx] = r
+		seen[reportKey(idx, report)] = true
+	}
+
+	if reportsSessionExists(opts.Session) {
+		panes, err := reportsGetPanes(opts.Session)
+		if err != nil {
+			out.RobotResponse = NewErrorResponse(fmt.Errorf("failed to get panes: %w", err), ErrCodeInternalError, "Check tmux session state")
+			return out, nil
+		}
+		out.Live = true
+		for _, p := range panes {
+			if p.Type == tmux.AgentUser || !wanted(p.Index) {
+				continue
+			}
+			content, err := reportsCapture(p.ID, opts.Lines)
+			if err != nil {
+				continue
+			}
+			reports := agent.ParseReports(content)
+			for _, report := range reports {
+				key := reportKey(p.Index, report)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				out.Reports = append(out.Reports, PaneReport{
+					PaneIndex: p.Index,
+					Pane:      fmt.Sprintf("%s_%d", p.Type, p.Index),
+					Agent:     string(p.Type),
+					Source:    "live",
+					Report:    report,
+				})
+			}
+			// The last report on screen is the pane's current state; keep
+			// the journaled copy when it is the same, as it has a time.
+			if n := len(reports); n > 0 {
+				lastKey := reportKey(p.Index, reports[n-1])
+				for i := len(out.Reports) - 1; i >= 0; i-- {
+					if r := out.Reports[i]; r.PaneIndex == p.Index && reportKey(r.PaneIndex, r.Report) == lastKey {
+						latest[p.Index] = r
+						break
+					}
+				}
+			}
+		}
+	}
+
+	indices := make([]int, 0, len(latest))
+	for idx := range latest {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+	for _, idx := range indices {
+		r := latest[idx]
+		out.Latest[strconv.Itoa(idx)] = r
+		switch {
+		case r.Report.IsBlocked():
+			out.Summary.Blocked = append(out.Summary.Blocked, idx)
+		case r.Report.Status == agent.ReportDone:
+			out.Summary.Done = append(out.Summary.Done, idx)
+		}
+	}
+	out.Summary.Panes = len(indices)
+	return out, nil
+}
+
+// reportKey identifies a pane's report for de-duplication.
+func reportKey(paneIndex int, r agent.ProgressReport) string {
+	data, _ := json.Marshal(r)
+	return strconv.Itoa(paneIndex) + ":" + string(data)
+}
+
+// PrintReports handles `ntm robot reports`.
+func PrintReports(opts ReportsOptions) error {
+	out, err := GetReports(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
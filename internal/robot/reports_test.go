@@ -0,0 +1,77 @@
+package robot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func TestGetReports(t *testing.T) {
+	journalPath := filepath.Join(t.TempDir(), "bus_events.jsonl")
+	journal := events.NewJournal(journalPath)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, report := range []string{
+		`{"task_id":"bd-1","percent":20,"status":"working"}`,
+		`{"task_id":"bd-1","percent":60,"status":"working"}`,
+	} {
+		ev := events.NewWebhookEvent(events.WebhookAgentProgress, "proj", "cc_2", "cc", "cc_2 reported",
+			map[string]string{"pane_index": "2", "report": report})
+		ev.Timestamp = at.Add(time.Duration(i) * time.Minute)
+		if err := journal.Append(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = journal.Close()
+
+	oldExists, oldPanes, oldCapture := reportsSessionExists, reportsGetPanes, reportsCapture
+	t.Cleanup(func() { reportsSessionExists, reportsGetPanes, reportsCapture = oldExists, oldPanes, oldCapture })
+	reportsSessionExists = func(string) bool { return true }
+	reportsGetPanes = func(string) ([]tmux.Pane, error) {
+		return []tmux.Pane{
+			{ID: "%1", Index: 1, Type: tmux.AgentUser},
+			{ID: "%2", Index: 2, Type: tmux.AgentClaude},
+			{ID: "%3", Index: 3, Type: tmux.AgentCodex},
+		}, nil
+	}
+	reportsCapture = func(target string, lines int) (string, error) {
+		switch target {
+		case "%2":
+			return "NTM-REPORT{\"task_id\":\"bd-1\",\"percent\":60,\"status\":\"working\"}\n", nil
+		case "%3":
+			return "NTM-REPORT{\"task_id\":\"bd-2\",\"status\":\"blocked\",\"blockers\":[\"bd-1\"]}\n", nil
+		}
+		return "", nil
+	}
+
+	out, err := GetReports(ReportsOptions{Session: "proj", JournalPath: journalPath})
+	if err != nil || !out.Success {
+		t.Fatalf("GetReports() = %+v, %v", out, err)
+	}
+	if !out.Live || len(out.Reports) != 3 {
+		t.Fatalf("reports = %+v, want 2 journaled + 1 live", out.Reports)
+	}
+	pane2 := out.Latest["2"]
+	if pane2.Source != "journal" || pane2.ReportedAt == nil || *pane2.Report.Percent != 60 {
+		t.Errorf("latest[2] = %+v, want the journaled 60%% report", pane2)
+	}
+	pane3 := out.Latest["3"]
+	if pane3.Source != "live" || pane3.Report.TaskID != "bd-2" {
+		t.Errorf("latest[3] = %+v", pane3)
+	}
+	if len(out.Summary.Blocked) != 1 || out.Summary.Blocked[0] != 3 || out.Summary.Panes != 2 {
+		t.Errorf("summary = %+v", out.Summary)
+	}
+
+	out, _ = GetReports(ReportsOptions{Session: "proj", JournalPath: journalPath, Panes: []int{3}})
+	if len(out.Latest) != 1 || out.Latest["3"].Report.TaskID != "bd-2" {
+		t.Errorf("pane filter: latest = %+v", out.Latest)
+	}
+
+	out, _ = GetReports(ReportsOptions{})
+	if out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("missing session = %+v", out.RobotResponse)
+	}
+}
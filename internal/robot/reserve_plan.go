@@ -0,0 +1,509 @@
+// Package robot provides machine-readable output for AI agents.
+// reserve_plan.go proposes the file reservations a task needs
+// (`ntm reserve plan`).
+package robot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+)
+
+// Overridable for tests.
+var (
+	reservePlanBead = func(dir, beadID string) (title, description string, err error) {
+		cmd := exec.Command("br", "show", beadID, "--json")
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		if err != nil {
+			return "", "", fmt.Errorf("br show %s failed: %w", beadID, err)
+		}
+		var issues []struct {
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		}
+		if err := json.Unmarshal(output, &issues); err != nil {
+			return "", "", fmt.Errorf("parse br show output: %w", err)
+		}
+		if len(issues) == 0 {
+			return "", "", fmt.Errorf("bead %s not found", beadID)
+		}
+		return issues[0].Title, issues[0].Description, nil
+	}
+	reservePlanFiles = func(dir string) ([]string, error) {
+		output, err := exec.Command("git", "-C", dir, "ls-files", "-z").Output()
+		if err != nil {
+			return nil, fmt.Errorf("git ls-files: %w", err)
+		}
+		return strings.FieldsFunc(string(output), func(r rune) bool { return r == 0 }), nil
+	}
+	reservePlanAssignments = func() map[string][]assignment.Assignment {
+		entries, err := os.ReadDir(assignment.StorageDir())
+		if err != nil {
+			return nil
+		}
+		bySession := make(map[string][]assignment.Assignment)
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			if store, err := assignment.LoadStore(e.Name()); err == nil && store != nil {
+				bySession[e.Name()] = store.GetAll()
+			}
+		}
+		return bySession
+	}
+)
+
+// Reservation plan sources.
+const (
+	ReserveSourceMentioned  = "mentioned"   // The task names the path
+	ReserveSourceKeyword    = "keyword"     // A configured keyword in the task maps to the path
+	ReserveSourceDirectory  = "directory"   // A directory is named like a word of the task
+	ReserveSourceWorkingSet = "working_set" // A similar task wrote files there
+)
+
+// reservePlanWeights is the score each source gives a pattern.
+var reservePlanWeights = map[string]float64{
+	ReserveSourceMentioned:  1.0,
+	ReserveSourceKeyword:    0.8,
+	ReserveSourceWorkingSet: 0.6,
+	ReserveSourceDirectory:  0.5,
+}
+
+const (
+	// DefaultReservePlanLimit caps the proposed patterns.
+	DefaultReservePlanLimit = 12
+	// reservePlanSimilarity is the minimum title similarity for a prior
+	// task's working set to count.
+	reservePlanSimilarity = 0.25
+	// reservePlanCollapse is how many files of one directory are proposed
+	// as that directory instead.
+	reservePlanCollapse = 3
+)
+
+// ReservePlanOptions configures `ntm reserve plan`.
+type ReservePlanOptions struct {
+	// Task is the bead to plan reservations for.
+	Task string
+	// Session holds the task's assignment, which names the agent.
+	Session string
+	// Agent overrides the assigned agent.
+	Agent string
+	// ProjectDir is the repository the patterns are relative to.
+	ProjectDir string
+	// Keywords maps task keywords to path patterns.
+	Keywords map[string][]string
+	// Ownership maps path patterns to the agent name or type owning them.
+	Ownership map[string]string
+	// Limit caps the proposed patterns (0 = DefaultReservePlanLimit).
+	Limit int
+}
+
+// ReservePattern is one proposed reservation.
+type ReservePattern struct {
+	Pattern string   `json:"pattern"`
+	Score   float64  `json:"score"`   // 0.0-1.0
+	Sources []string `json:"sources"` // mentioned, keyword, directory, working_set
+	Reasons []string `json:"reasons"`
+	Files   int      `json:"files"`           // Tracked files the pattern covers
+	Owner   string   `json:"owner,omitempty"` // Owner per the ownership map
+}
+
+// ReservePlanSimilar is a prior task whose working set informed the plan.
+type ReservePlanSimilar struct {
+	BeadID     string  `json:"bead_id"`
+	Title      string  `json:"title"`
+	Session    string  `json:"session"`
+	Similarity float64 `json:"similarity"`
+}
+
+// ReservePlanOutput is the response for `ntm reserve plan`.
+type ReservePlanOutput struct {
+	RobotResponse
+	Task      string               `json:"task"`
+	Title     string               `json:"title,omitempty"`
+	Session   string               `json:"session,omitempty"`
+	Agent     string               `json:"agent,omitempty"`
+	AgentType string               `json:"agent_type,omitempty"`
+	Patterns  []ReservePattern     `json:"patterns"`
+	Similar   []ReservePlanSimilar `json:"similar_tasks,omitempty"`
+	Reason    string               `json:"reason,omitempty"` // Reservation reason to apply the plan with
+	Warnings  []string             `json:"warnings"`
+}
+
+// PatternList returns the proposed patterns, best first.
+func (o *ReservePlanOutput) PatternList() []string {
+	patterns := make([]string, 0, len(o.Patterns))
+	for _, p := range o.Patterns {
+		patterns = append(patterns, p.Pattern)
+	}
+	return patterns
+}
+
+// GetReservePlan proposes reservation patterns for a task from the paths
+// its text names, configured keywords, directories named like its words,
+// and the files written by prior tasks with similar titles. Patterns
+// owned by another agent are flagged.
+func GetReservePlan(opts ReservePlanOptions) (*ReservePlanOutput, error) {
+	out := &ReservePlanOutput{
+		RobotResponse: NewRobotResponse(true),
+		Task:          opts.Task,
+		Session:       opts.Session,
+		Patterns:      []ReservePattern{},
+		Warnings:      []string{},
+	}
+	if opts.Task == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("task is required"), ErrCodeInvalidFlag, "Pass --task <bead-id>")
+		return out, nil
+	}
+	title, description, err := reservePlanBead(opts.ProjectDir, opts.Task)
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(err, ErrCodeInvalidFlag, "Check the bead ID with 'br show'")
+		return out, nil
+	}
+	out.Title = title
+	out.Reason = opts.Task
+	if title != "" {
+		out.Reason += ": " + title
+	}
+
+	files, err := reservePlanFiles(opts.ProjectDir)
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("repository files unavailable: %v", err))
+	}
+
+	assignments := reservePlanAssignments()
+	out.Agent = opts.Agent
+	for _, a := range assignments[opts.Session] {
+		if a.BeadID == opts.Task {
+			if out.Agent == "" {
+				out.Agent = a.AgentName
+			}
+			out.AgentType = string(assign.ParseAgentType(a.AgentType))
+		}
+	}
+
+	plan := newReservePlan(files)
+	words := taskWords(title + "\n" + description)
+
+	for _, p := range assign.ExtractFilePaths(title, description) {
+		p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/**/*")
+		if pattern, ok := plan.resolve(p); ok {
+			plan.add(pattern, ReserveSourceMentioned, fmt.Sprintf("task mentions %s", p))
+		} else {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("mentioned path %s matches no tracked file", p))
+		}
+	}
+
+	keywords := make([]string, 0, len(opts.Keywords))
+	for k := range opts.Keywords {
+		keywords = append(keywords, k)
+	}
+	sort.Strings(keywords)
+	for _, k := range keywords {
+		if !words[strings.ToLower(k)] {
+			continue
+		}
+		for _, pattern := range opts.Keywords[k] {
+			plan.add(pattern, ReserveSourceKeyword, fmt.Sprintf("keyword %q", k))
+		}
+	}
+
+	for _, dir := range plan.dirs {
+		if words[strings.ToLower(path.Base(dir))] {
+			plan.add(dir+"/**", ReserveSourceDirectory, fmt.Sprintf("directory %s matches the task", path.Base(dir)))
+		}
+	}
+
+	out.Similar = plan.addWorkingSets(opts.Task, title, assignments)
+
+	out.Patterns = plan.patterns(opts.Ownership, out.Agent, out.AgentType)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultReservePlanLimit
+	}
+	if len(out.Patterns) > limit {
+		out.Patterns = out.Patterns[:limit]
+	}
+	for _, p := range out.Patterns {
+		if p.Owner != "" && !ownedBy(p.Owner, out.Agent, out.AgentType) {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%s is owned by %s", p.Pattern, p.Owner))
+		}
+	}
+	if len(out.Patterns) == 0 {
+		out.Warnings = append(out.Warnings, "no paths found for the task; add keywords to [file_reservation.keywords]")
+	}
+	return out, nil
+}
+
+// PrintReservePlan outputs a reservation plan as JSON.
+func PrintReservePlan(opts ReservePlanOptions) error {
+	out, err := GetReservePlan(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// reservePlan accumulates candidate patterns.
+type reservePlan struct {
+	files      []string
+	dirs       []string // Every directory holding tracked files, sorted
+	candidates map[string]*ReservePattern
+	order      []string
+}
+
+func newReservePlan(files []string) *reservePlan {
+	seen := map[string]bool{}
+	var dirs []string
+	for _, f := range files {
+		for d := path.Dir(f); d != "." && !seen[d]; d = path.Dir(d) {
+			seen[d] = true
+			dirs = append(dirs, d)
+		}
+	}
+	sort.Strings(dirs)
+	return &reservePlan{files: files, dirs: dirs, candidates: map[string]*ReservePattern{}}
+}
+
+// resolve maps a path named by the task to a pattern covering tracked
+// files: the file itself, a directory as dir/**, or a glob. A bare file
+// name resolves when exactly one tracked file has it.
+func (rp *reservePlan) resolve(p string) (string, bool) {
+	if strings.Contains(p, "*") {
+		return p, rp.covered(p) > 0
+	}
+	for _, f := range rp.files {
+		if f == p {
+			return p, true
+		}
+	}
+	for _, d := range rp.dirs {
+		if d == p {
+			return p + "/**", true
+		}
+	}
+	if !strings.Contains(p, "/") {
+		var match string
+		for _, f := range rp.files {
+			if path.Base(f) == p {
+				if match != "" {
+					return "", false
+				}
+				match = f
+			}
+		}
+		return match, match != ""
+	}
+	return "", false
+}
+
+// covered counts the tracked files matching pattern.
+func (rp *reservePlan) covered(pattern string) int {
+	n := 0
+	for _, f := range rp.files {
+		if assignment.MatchPath(f, pattern) {
+			n++
+		}
+	}
+	return n
+}
+
+func (rp *reservePlan) add(pattern, source, reason string) {
+	c := rp.ensure(pattern)
+	if !slices.Contains(c.Sources, source) {
+		c.Sources = append(c.Sources, source)
+	}
+	if !slices.Contains(c.Reasons, reason) {
+		c.Reasons = append(c.Reasons, reason)
+	}
+}
+
+// ensure returns the candidate for pattern, adding it without sources if
+// it is new. Candidates without sources of their own get those of the
+// patterns they cover.
+func (rp *reservePlan) ensure(pattern string) *ReservePattern {
+	c, ok := rp.candidates[pattern]
+	if !ok {
+		c = &ReservePattern{Pattern: pattern}
+		rp.candidates[pattern] = c
+		rp.order = append(rp.order, pattern)
+	}
+	return c
+}
+
+// addWorkingSets adds the files written by prior tasks whose titles are
+// similar to title, and returns those tasks, most similar first.
+func (rp *reservePlan) addWorkingSets(task, title string, bySession map[string][]assignment.Assignment) []ReservePlanSimilar {
+	words := taskWords(title)
+	var similar []ReservePlanSimilar
+	sessions := make([]string, 0, len(bySession))
+	for s := range bySession {
+		sessions = append(sessions, s)
+	}
+	sort.Strings(sessions)
+	for _, session := range sessions {
+		for _, a := range bySession[session] {
+			if a.BeadID == task || a.WorkingSet == nil || len(a.WorkingSet.FilesWritten) == 0 {
+				continue
+			}
+			sim := wordSimilarity(words, taskWords(a.BeadTitle))
+			if sim < reservePlanSimilarity {
+				continue
+			}
+			similar = append(similar, ReservePlanSimilar{BeadID: a.BeadID, Title: a.BeadTitle, Session: session, Similarity: sim})
+			for _, f := range a.WorkingSet.FilesWritten {
+				if pattern, ok := rp.resolve(f); ok {
+					rp.add(pattern, ReserveSourceWorkingSet, fmt.Sprintf("%s (%s) wrote it", a.BeadID, a.BeadTitle))
+				}
+			}
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool { return similar[i].Similarity > similar[j].Similarity })
+	return similar
+}
+
+// patterns finalizes the candidates: files of one directory collapse into
+// the directory, patterns covered by a broader one merge into it, and the
+// rest are scored and sorted best first.
+func (rp *reservePlan) patterns(ownership map[string]string, agent, agentType string) []ReservePattern {
+	byDir := map[string][]string{}
+	for _, p := range rp.order {
+		if !strings.Contains(p, "*") {
+			byDir[path.Dir(p)] = append(byDir[path.Dir(p)], p)
+		}
+	}
+	for dir, files := range byDir {
+		if len(files) >= reservePlanCollapse && dir != "." {
+			rp.ensure(dir + "/*")
+		}
+	}
+
+	var result []ReservePattern
+	for _, p := range rp.order {
+		if broader := rp.broader(p); broader != "" {
+			c, b := rp.candidates[p], rp.candidates[broader]
+			for _, s := range c.Sources {
+				if !slices.Contains(b.Sources, s) {
+					b.Sources = append(b.Sources, s)
+				}
+			}
+			for _, r := range c.Reasons {
+				if !slices.Contains(b.Reasons, r) {
+					b.Reasons = append(b.Reasons, r)
+				}
+			}
+		}
+	}
+	for _, p := range rp.order {
+		if rp.broader(p) != "" {
+			continue
+		}
+		c := *rp.candidates[p]
+		for _, s := range c.Sources {
+			c.Score = max(c.Score, reservePlanWeights[s])
+		}
+		c.Score = min(1, c.Score+0.1*float64(len(c.Sources)-1))
+		c.Files = rp.covered(c.Pattern)
+		c.Owner = patternOwner(c.Pattern, ownership)
+		if c.Owner != "" && ownedBy(c.Owner, agent, agentType) {
+			c.Score = min(1, c.Score+0.1)
+			c.Reasons = append(c.Reasons, "owned by the assigned agent")
+		}
+		c.Score = float64(int(c.Score*100+0.5)) / 100
+		result = append(result, c)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	return result
+}
+
+// broader returns another candidate covering every file pattern p covers,
+// or "" if there is none.
+func (rp *reservePlan) broader(p string) string {
+	best := ""
+	for _, other := range rp.order {
+		if other == p || !patternCovers(other, p) {
+			continue
+		}
+		if rp.broader(other) == "" {
+			best = other
+		}
+	}
+	return best
+}
+
+// patternCovers reports whether broad matches everything narrow matches,
+// for the directory patterns plans use (dir/** and dir/*).
+func patternCovers(broad, narrow string) bool {
+	if dir, ok := strings.CutSuffix(broad, "/**"); ok {
+		return strings.HasPrefix(narrow, dir+"/")
+	}
+	if dir, ok := strings.CutSuffix(broad, "/*"); ok {
+		return !strings.Contains(narrow, "*") && path.Dir(narrow) == dir
+	}
+	return false
+}
+
+// patternOwner returns the owner of the longest ownership pattern that
+// covers or matches pattern.
+func patternOwner(pattern string, ownership map[string]string) string {
+	owner, longest := "", -1
+	for owned, who := range ownership {
+		if owned == pattern || patternCovers(owned, pattern) || assignment.MatchPath(pattern, owned) {
+			if len(owned) > longest {
+				owner, longest = who, len(owned)
+			}
+		}
+	}
+	return owner
+}
+
+// ownedBy reports whether owner names the agent or its type.
+func ownedBy(owner, agent, agentType string) bool {
+	return owner != "" && (strings.EqualFold(owner, agent) || strings.EqualFold(owner, agentType))
+}
+
+// taskWords returns the distinct lowercase words of text, without paths,
+// stop words, or words shorter than three letters. Plurals also count in
+// their singular form.
+func taskWords(text string) map[string]bool {
+	var prose []string
+	for _, field := range strings.Fields(text) {
+		if !strings.Contains(field, "/") {
+			prose = append(prose, field)
+		}
+	}
+	words := map[string]bool{}
+	for _, w := range tokenize(strings.ToLower(strings.Join(prose, " "))) {
+		if len(w) >= 3 && !isStopWord(w) {
+			words[w] = true
+			if singular, ok := strings.CutSuffix(w, "s"); ok && len(singular) >= 3 {
+				words[singular] = true
+			}
+		}
+	}
+	return words
+}
+
+// wordSimilarity is the Jaccard similarity of two word sets.
+func wordSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range a {
+		if b[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
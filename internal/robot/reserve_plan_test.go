@@ -0,0 +1,102 @@
+package robot
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+)
+
+func stubReservePlan(t *testing.T, title, description string, files []string, bySession map[string][]assignment.Assignment) {
+	t.Helper()
+	oldBead, oldFiles, oldAssignments := reservePlanBead, reservePlanFiles, reservePlanAssignments
+	t.Cleanup(func() {
+		reservePlanBead, reservePlanFiles, reservePlanAssignments = oldBead, oldFiles, oldAssignments
+	})
+	reservePlanBead = func(string, string) (string, string, error) { return title, description, nil }
+	reservePlanFiles = func(string) ([]string, error) { return files, nil }
+	reservePlanAssignments = func() map[string][]assignment.Assignment { return bySession }
+}
+
+func TestGetReservePlan(t *testing.T) {
+	files := []string{
+		"README.md",
+		"internal/auth/login.go",
+		"internal/auth/token.go",
+		"internal/session/store.go",
+		"internal/session/cookie.go",
+		"internal/tmux/tmux.go",
+		"web/src/login.tsx",
+		"web/src/form.tsx",
+		"web/src/button.tsx",
+	}
+	stubReservePlan(t,
+		"Fix login session expiry",
+		"Sessions expire early. See internal/auth/login.go and the token refresh.",
+		files,
+		map[string][]assignment.Assignment{
+			"proj": {{BeadID: "bd-1", BeadTitle: "Fix login session expiry", Pane: 2, AgentType: "claude", AgentName: "BlueLake"}},
+			"old": {{BeadID: "bd-9", BeadTitle: "Login session form validation", WorkingSet: &assignment.WorkingSet{
+				FilesWritten: []string{"web/src/login.tsx", "web/src/form.tsx", "web/src/button.tsx", "gone.go"},
+			}}},
+		},
+	)
+
+	out, err := GetReservePlan(ReservePlanOptions{
+		Task:      "bd-1",
+		Session:   "proj",
+		Keywords:  map[string][]string{"token": {"internal/auth/**"}},
+		Ownership: map[string]string{"internal/session/**": "GreenHill", "internal/auth/**": "cc"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success || out.Agent != "BlueLake" || out.AgentType != "cc" || out.Reason != "bd-1: Fix login session expiry" {
+		t.Fatalf("out = %+v", out)
+	}
+
+	byPattern := map[string]ReservePattern{}
+	for _, p := range out.Patterns {
+		byPattern[p.Pattern] = p
+	}
+	// The mentioned file merges into the keyword's directory pattern.
+	auth, ok := byPattern["internal/auth/**"]
+	if !ok || auth.Files != 2 || !slices.Contains(auth.Sources, ReserveSourceMentioned) || !slices.Contains(auth.Sources, ReserveSourceKeyword) {
+		t.Errorf("auth pattern = %+v", auth)
+	}
+	if _, ok := byPattern["internal/auth/login.go"]; ok {
+		t.Error("login.go should merge into internal/auth/**")
+	}
+	if out.Patterns[0].Pattern != "internal/auth/**" || auth.Score != 1 {
+		t.Errorf("best pattern = %+v", out.Patterns[0])
+	}
+	// A directory named like a task word, owned by another agent.
+	if session := byPattern["internal/session/**"]; session.Owner != "GreenHill" || !slices.Equal(session.Sources, []string{ReserveSourceDirectory}) {
+		t.Errorf("session pattern = %+v", session)
+	}
+	if !slices.Contains(out.Warnings, "internal/session/** is owned by GreenHill") {
+		t.Errorf("warnings = %v", out.Warnings)
+	}
+	// Three files written by a similar task collapse into their directory.
+	if web := byPattern["web/src/*"]; web.Files != 3 || !slices.Equal(web.Sources, []string{ReserveSourceWorkingSet}) {
+		t.Errorf("web pattern = %+v", web)
+	}
+	if len(out.Similar) != 1 || out.Similar[0].BeadID != "bd-9" {
+		t.Errorf("similar = %+v", out.Similar)
+	}
+	if _, ok := byPattern["internal/tmux/**"]; ok {
+		t.Error("unrelated directory proposed")
+	}
+
+	limited, _ := GetReservePlan(ReservePlanOptions{Task: "bd-1", Limit: 1})
+	if len(limited.Patterns) != 1 {
+		t.Errorf("limit: patterns = %+v", limited.Patterns)
+	}
+}
+
+func TestGetReservePlan_RequiresTask(t *testing.T) {
+	stubReservePlan(t, "", "", nil, nil)
+	if out, _ := GetReservePlan(ReservePlanOptions{}); out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("out = %+v", out.RobotResponse)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/agent"
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
 	"github.com/Dicklesworthstone/ntm/internal/alerts"
 	"github.com/Dicklesworthstone/ntm/internal/bv"
@@ -26,6 +27,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
 	"github.com/Dicklesworthstone/ntm/internal/status"
 	swarmlib "github.com/Dicklesworthstone/ntm/internal/swarm"
+	"github.com/Dicklesworthstone/ntm/internal/takeover"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
 	"github.com/Dicklesworthstone/ntm/internal/tools"
 	"github.com/Dicklesworthstone/ntm/internal/tracker"
@@ -1582,6 +1584,10 @@ type AgentMailSummary struct {
 	UrgentMessages     int    `json:"urgent_messages,omitempty"`
 	TotalLocks         int    `json:"total_locks,omitempty"`
 	Error              string `json:"error,omitempty"`
+
+	// Health is the client's view of the backend: breaker state, recent
+	// failures, and outbound operations waiting in the offline queue.
+	Health *agentmail.BackendHealth `json:"health,omitempty"`
 }
 
 // HandoffSummary is the latest handoff across all sessions.
@@ -1854,6 +1860,7 @@ Output Formats:
 --robot-format=toon     Token-efficient format
 --robot-markdown        Markdown tables (~50% fewer tokens)
 --robot-terse           Single-line state summary
+--if-changed[=ETAG]     Add an etag; print only not_modified when it matches
 
 Common Modifiers:
 -----------------
@@ -1861,6 +1868,7 @@ Common Modifiers:
 --offset=N      Pagination offset for list commands
 --robot-limit=N  Explicit pagination alias for robot list outputs
 --robot-offset=N Explicit pagination alias for robot list outputs
+--robot-cursor=C Resume after a page's next_page_cursor (stable under changes)
 --since=DURATION  Time filter (1d, 7d, 30d, ISO8601, or duration like 1h)
 --type=TYPE     Agent type filter (claude, codex, gemini)
 --panes=X,Y     Pane filter (comma-separated indices)
@@ -2045,7 +2053,11 @@ func GetStatusWithOptions(opts PaginationOptions) (*StatusOutput, error) {
 	appendFileChanges(output)
 	appendConflicts(output)
 
-	if paged, page := ApplyPagination(output.Sessions, opts); page != nil {
+	paged, page, err := ApplyKeyedPagination(output.Sessions, func(si SessionInfo) string { return si.Name }, false, opts)
+	if err != nil {
+		return nil, err
+	}
+	if page != nil {
 		output.Sessions = paged
 		output.Pagination = page
 		if next, pages := paginationHintOffsets(page); next != nil {
@@ -3052,7 +3064,7 @@ func detectModel(agentType, title string) string {
 // Despite the name (kept for backward compatibility), this now supports
 // multiple formats: json, toon, or auto (default).
 func encodeJSON(v interface{}) error {
-	return Output(applyVerbosity(v, OutputVerbosity), OutputFormat)
+	return Output(applyVerbosity(applyTimeFields(applyIfChanged(withErrorTaxonomy(v))), OutputVerbosity), OutputFormat)
 }
 
 // TailOutput is the structured output for --robot-tail
@@ -3602,7 +3614,7 @@ func GetSnapshotWithOptions(cfg *config.Config, opts PaginationOptions) (*Snapsh
 	}
 	output := &SnapshotOutput{
 		RobotResponse: NewRobotResponse(true),
-		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+		Timestamp:     FormatTimestamp(time.Now()),
 		SafetyProfile: cfg.Safety.Profile,
 		Sessions:      []SnapshotSession{},
 		Alerts:        []string{},
@@ -3748,6 +3760,8 @@ func GetSnapshotWithOptions(cfg *config.Config, opts PaginationOptions) (*Snapsh
 			cfg.Alerts.ResolvedPruneMinutes,
 			cfg.ProjectsBase,
 		)
+		alertCfg.AgentCPUPercent = cfg.Alerts.AgentCPUPercent
+		alertCfg.AgentMemoryPercent = cfg.Alerts.AgentMemoryPercent
 	} else {
 		alertCfg = alerts.DefaultConfig()
 	}
@@ -3765,7 +3779,7 @@ func GetSnapshotWithOptions(cfg *config.Config, opts PaginationOptions) (*Snapsh
 				Pane:       a.Pane,
 				BeadID:     a.BeadID,
 				Context:    a.Context,
-				CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+				CreatedAt:  FormatTimestamp(a.CreatedAt),
 				DurationMs: a.Duration().Milliseconds(),
 				Count:      a.Count,
 			}
@@ -3790,7 +3804,11 @@ func GetSnapshotWithOptions(cfg *config.Config, opts PaginationOptions) (*Snapsh
 		}
 	}
 
-	if paged, page := ApplyPagination(output.Sessions, opts); page != nil {
+	paged, page, err := ApplyKeyedPagination(output.Sessions, func(ss SnapshotSession) string { return ss.Name }, false, opts)
+	if err != nil {
+		return nil, err
+	}
+	if page != nil {
 		output.Sessions = paged
 		output.Pagination = page
 		if next, pages := paginationHintOffsets(page); next != nil {
@@ -3889,7 +3907,7 @@ func buildSwarmSnapshot(cfg *config.Config, sessions []tmux.Session) *SwarmSnaps
 
 func buildSwarmSnapshotPlan(cfg *config.Config, fallbackTotalAgents int) SwarmSnapshotPlan {
 	planOut := SwarmSnapshotPlan{
-		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+		CreatedAt:   FormatTimestamp(time.Now()),
 		ScanDir:     cfg.Swarm.DefaultScanDir,
 		TotalAgents: fallbackTotalAgents,
 		Allocations: []SwarmPlanAllocation{},
@@ -3914,7 +3932,7 @@ func buildSwarmSnapshotPlan(cfg *config.Config, fallbackTotalAgents int) SwarmSn
 		return planOut
 	}
 
-	planOut.CreatedAt = plan.CreatedAt.UTC().Format(time.RFC3339)
+	planOut.CreatedAt = FormatTimestamp(plan.CreatedAt.UTC())
 	planOut.ScanDir = plan.ScanDir
 	planOut.TotalAgents = plan.TotalAgents
 	planOut.Allocations = make([]SwarmPlanAllocation, 0, len(plan.Allocations))
@@ -4023,6 +4041,7 @@ type SendOutput struct {
 	Targets        []string           `json:"targets"`
 	Successful     []string           `json:"successful"`
 	Failed         []SendError        `json:"failed"`
+	TakenOver      []string           `json:"taken_over,omitempty"` // Panes skipped because a human has taken them over
 	MessagePreview string             `json:"message_preview"`
 	DryRun         bool               `json:"dry_run,omitempty"`
 	WouldSendTo    []string           `json:"would_send_to,omitempty"`
@@ -4248,7 +4267,7 @@ func GetSend(opts SendOptions) (*SendOutput, error) {
 			errMsg = fmt.Sprintf("refusing to proceed: potential secrets detected (%s) (redaction mode: block)", parts)
 		}
 		return &SendOutput{
-			RobotResponse:  NewErrorResponse(fmt.Errorf("%s", errMsg), "SENSITIVE_DATA_BLOCKED", "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact"),
+			RobotResponse:  NewErrorResponse(fmt.Errorf("%s", errMsg), ErrCodeSensitiveDataBlocked, "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact"),
 			Session:        opts.Session,
 			SentAt:         time.Now().UTC(),
 			Blocked:        true,
@@ -4340,6 +4359,9 @@ func GetSend(opts SendOptions) (*SendOutput, error) {
 	}
 	hasTypeFilter := len(typeFilterMap) > 0
 
+	// Panes a human has taken over never get automated prompts.
+	takenOver := takeover.Panes(opts.Session)
+
 	// Determine which panes to target
 	var targetPanes []tmux.Pane
 	for _, pane := range panes {
@@ -4380,6 +4402,12 @@ func GetSend(opts SendOptions) (*SendOutput, error) {
 			}
 		}
 
+		if s := takenOver[pane.ID]; s != nil {
+			output.TakenOver = append(output.TakenOver, paneKey)
+			output.Warnings = append(output.Warnings, fmt.Sprintf("pane %s skipped: taken over by %s", paneKey, s.By))
+			continue
+		}
+
 		targetPanes = append(targetPanes, pane)
 		output.Targets = append(output.Targets, paneKey)
 	}
@@ -4438,7 +4466,7 @@ func GetSend(opts SendOptions) (*SendOutput, error) {
 		if parts := formatRedactionCategoryCounts(summary.Categories); parts != "" {
 			errMsg = fmt.Sprintf("refusing to proceed: potential secrets detected (%s) (redaction mode: block)", parts)
 		}
-		output.RobotResponse = NewErrorResponse(fmt.Errorf("%s", errMsg), "SENSITIVE_DATA_BLOCKED", "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact")
+		output.RobotResponse = NewErrorResponse(fmt.Errorf("%s", errMsg), ErrCodeSensitiveDataBlocked, "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact")
 		output.Success = false
 		return &output, nil
 	}
@@ -4579,8 +4607,8 @@ type Change struct {
 func GetSnapshotDelta(since time.Time) (*SnapshotDeltaOutput, error) {
 	output := &SnapshotDeltaOutput{
 		RobotResponse: NewRobotResponse(true),
-		Timestamp:     time.Now().UTC().Format(time.RFC3339),
-		Since:         since.Format(time.RFC3339),
+		Timestamp:     FormatTimestamp(time.Now()),
+		Since:         FormatTimestamp(since),
 		Changes:       []Change{},
 	}
 
@@ -5147,7 +5175,7 @@ func GetAlertsDetailed(includeResolved bool) (*AlertsOutput, error) {
 			Pane:       a.Pane,
 			BeadID:     a.BeadID,
 			Context:    a.Context,
-			CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+			CreatedAt:  FormatTimestamp(a.CreatedAt),
 			DurationMs: a.Duration().Milliseconds(),
 			Count:      a.Count,
 		}
@@ -5165,7 +5193,7 @@ func GetAlertsDetailed(includeResolved bool) (*AlertsOutput, error) {
 				Pane:       a.Pane,
 				BeadID:     a.BeadID,
 				Context:    a.Context,
-				CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+				CreatedAt:  FormatTimestamp(a.CreatedAt),
 				DurationMs: a.Duration().Milliseconds(),
 				Count:      a.Count,
 			}
@@ -5327,7 +5355,7 @@ func GetTerse(cfg *config.Config) (*TerseOutput, error) {
 	output := &TerseOutput{
 		RobotResponse: RobotResponse{
 			Success:   true,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Timestamp: FormatTimestamp(time.Now()),
 		},
 		States:     []TerseState{},
 		TerseLines: []string{},
@@ -5345,6 +5373,8 @@ func GetTerse(cfg *config.Config) (*TerseOutput, error) {
 			cfg.Alerts.ResolvedPruneMinutes,
 			cfg.ProjectsBase,
 		)
+		alertCfg.AgentCPUPercent = cfg.Alerts.AgentCPUPercent
+		alertCfg.AgentMemoryPercent = cfg.Alerts.AgentMemoryPercent
 		activeAlerts := alerts.GetActiveAlerts(alertCfg)
 		for _, a := range activeAlerts {
 			switch a.Severity {
@@ -5633,11 +5663,19 @@ func getAgentMailSummary() (*AgentMailSummary, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 	defer cancel()
 
-	client := agentmail.NewClient(agentmail.WithProjectKey(projectKey))
+	opts := []agentmail.Option{agentmail.WithProjectKey(projectKey)}
+	if outbox, err := agentmail.OpenOutbox(agentmail.DefaultOutboxPath(projectKey)); err == nil {
+		opts = append(opts, agentmail.WithOutbox(outbox))
+	}
+	client := agentmail.NewClient(opts...)
 	summary := &AgentMailSummary{
 		Available: false,
 		ServerURL: client.BaseURL(),
 	}
+	defer func() {
+		health := client.Health()
+		summary.Health = &health
+	}()
 
 	if !client.IsAvailable() {
 		return summary, nil
@@ -5909,8 +5947,8 @@ func GetContext(session string, lines int) (*ContextOutput, error) {
 			SessionName:    p.SessionName,
 			PaneID:         p.PaneID,
 			ContextPercent: p.ContextPercent,
-			CreatedAt:      p.CreatedAt.Format(time.RFC3339),
-			TimeoutAt:      p.TimeoutAt.Format(time.RFC3339),
+			CreatedAt:      FormatTimestamp(p.CreatedAt),
+			TimeoutAt:      FormatTimestamp(p.TimeoutAt),
 			DefaultAction:  string(p.DefaultAction),
 			WorkDir:        p.WorkDir,
 		})
@@ -6232,6 +6270,10 @@ type DiffAgentInfo struct {
 	State       string `json:"state"`
 	OutputLines int    `json:"output_lines"`
 	ActiveTime  string `json:"active_time,omitempty"`
+	// ToolCalls counts the tool calls visible in the pane by kind
+	// (read, edit, shell, ...), and FilesEdited lists the files they changed.
+	ToolCalls   map[string]int `json:"tool_calls,omitempty"`
+	FilesEdited []string       `json:"files_edited,omitempty"`
 }
 
 // DiffAgentHints provides actionable hints for AI agents.
@@ -6243,7 +6285,8 @@ type DiffAgentHints struct {
 
 // GetDiff returns agent activity comparison and file change analysis.
 // This function returns the data struct directly, enabling CLI/REST parity.
-func GetDiff(opts DiffOptions) (*DiffOutput, error) {
+// Git and conflict analysis stop when ctx is done.
+func GetDiff(ctx context.Context, opts DiffOptions) (*DiffOutput, error) {
 	// Default to 15 minutes if not specified
 	if opts.Since == 0 {
 		opts.Since = 15 * time.Minute
@@ -6257,8 +6300,8 @@ func GetDiff(opts DiffOptions) (*DiffOutput, error) {
 		Session:       opts.Session,
 		Timeframe: DiffTimeframe{
 			Since:      opts.Since.String(),
-			SinceTS:    sinceTime.Format(time.RFC3339),
-			CapturedAt: now.Format(time.RFC3339),
+			SinceTS:    FormatTimestamp(sinceTime),
+			CapturedAt: FormatTimestamp(now),
 		},
 		Files: DiffFiles{
 			Modified:           []string{},
@@ -6322,17 +6365,26 @@ func GetDiff(opts DiffOptions) (*DiffOutput, error) {
 			State:       state,
 			OutputLines: len(lines),
 		}
+		calls := agent.ParseToolCalls(agent.AgentType(pane.Type), captured)
+		if len(calls) > 0 {
+			info.ToolCalls = make(map[string]int)
+			for _, c := range calls {
+				info.ToolCalls[string(c.Kind)]++
+			}
+			info.FilesEdited = agent.ModifiedFiles(calls)
+		}
 		output.AgentActivity = append(output.AgentActivity, info)
 
-		// Record activity window for conflict detection
+		// Record activity window and tool calls for conflict detection
 		detector.RecordActivity(pane.ID, agentType, sinceTime, now, len(lines) > 0)
+		detector.RecordToolCalls(pane.ID, calls)
 	}
 
 	// Track issues for hints
 	var analysisIssues []string
 
 	// Get modified files from git
-	gitStatus, gitErr := detector.GetGitStatus()
+	gitStatus, gitErr := detector.GetGitStatus(ctx)
 	if gitErr == nil {
 		for _, fs := range gitStatus {
 			output.Files.Modified = append(output.Files.Modified, fs.Path)
@@ -6343,7 +6395,6 @@ func GetDiff(opts DiffOptions) (*DiffOutput, error) {
 	}
 
 	// Detect potential conflicts
-	ctx := context.Background()
 	conflicts, conflictErr := detector.DetectConflicts(ctx)
 	if conflictErr != nil && wd != "" {
 		analysisIssues = append(analysisIssues, "Conflict detection incomplete")
@@ -6387,8 +6438,8 @@ func GetDiff(opts DiffOptions) (*DiffOutput, error) {
 
 // PrintDiff handles the --robot-diff command.
 // This is a thin wrapper around GetDiff() for CLI output.
-func PrintDiff(opts DiffOptions) error {
-	output, err := GetDiff(opts)
+func PrintDiff(ctx context.Context, opts DiffOptions) error {
+	output, err := GetDiff(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -128,7 +128,7 @@ func GetDashboard() (*DashboardOutput, error) {
 			Pane:       a.Pane,
 			BeadID:     a.BeadID,
 			Context:    a.Context,
-			CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+			CreatedAt:  FormatTimestamp(a.CreatedAt),
 			DurationMs: a.Duration().Milliseconds(),
 			Count:      a.Count,
 		})
@@ -179,7 +179,7 @@ func printDashboardMarkdown(output DashboardOutput) error {
 
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "# NTM Fleet Dashboard: %s\n\n", escapeMarkdownCell(output.Fleet, 120))
-	fmt.Fprintf(&sb, "_Generated: %s_\n\n", output.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(&sb, "_Generated: %s_\n\n", FormatTimestamp(output.GeneratedAt))
 
 	sb.WriteString("## System\n")
 	sb.WriteString("| Key | Value |\n")
@@ -414,7 +414,7 @@ func writeBeadsTables(sb *strings.Builder, beads *bv.BeadsSummary) {
 		for _, b := range beads.InProgressList {
 			updated := ""
 			if !b.UpdatedAt.IsZero() {
-				updated = b.UpdatedAt.UTC().Format(time.RFC3339)
+				updated = FormatTimestamp(b.UpdatedAt.UTC())
 			}
 			fmt.Fprintf(sb, "| %s | %s | %s |\n",
 				escapeMarkdownCell(b.ID, 32),
@@ -456,7 +456,7 @@ func writeConflictsTable(sb *strings.Builder, conflicts []tracker.Conflict, maxR
 		sort.Strings(agents)
 		lastAt := ""
 		if !c.LastAt.IsZero() {
-			lastAt = c.LastAt.UTC().Format(time.RFC3339)
+			lastAt = FormatTimestamp(c.LastAt.UTC())
 		}
 		fmt.Fprintf(sb, "| %s | %s | %s | %s |\n",
 			escapeMarkdownCell(c.Severity, 16),
@@ -497,7 +497,7 @@ func writeFileChangesTable(sb *strings.Builder, changes []FileChangeInfo, maxRow
 		}
 		at := ""
 		if !c.At.IsZero() {
-			at = c.At.UTC().Format(time.RFC3339)
+			at = FormatTimestamp(c.At.UTC())
 		}
 		agents := append([]string(nil), c.Agents...)
 		sort.Strings(agents)
@@ -16,6 +16,10 @@ var SchemaCommand = map[string]interface{}{
 	"snapshot": SnapshotOutput{},
 	"version":  VersionOutput{},
 
+	// Discovery
+	"capabilities": CapabilitiesOutput{},
+	"commands":     CommandsOutput{},
+
 	// Session operations
 	"spawn":        SpawnOutput{},
 	"send":         SendOutput{},
@@ -47,6 +51,27 @@ var SchemaCommand = map[string]interface{}{
 	"is_working":         IsWorkingOutput{},
 	"restart_pane":       RestartPaneOutput{},
 	"auto_restart_stuck": AutoRestartStuckOutput{},
+	"deep_health":        DeepHealthOutput{},
+
+	// Robot subcommands
+	"status_diff":    StatusDiffOutput{},
+	"conflicts":      ConflictsOutput{},
+	"conflict_stats": ConflictStatsOutput{},
+	"fanout_send":    FanoutSendOutput{},
+	"exchanges":      ExchangesOutput{},
+	"audit":          AuditOutput{},
+	"captures":       CapturesOutput{},
+	"task_graph":     TaskGraphOutput{},
+	"reports":        ReportsOutput{},
+	"crashes":        CrashesOutput{},
+	"crash":          CrashOutput{},
+	"summarize":      SummarizeOutput{},
+	"whatif":         WhatIfOutput{},
+	"blame":          BlameOutput{},
+	"ratelimit":      RateLimitOutput{},
+	"jobs":           JobsOutput{},
+	"job":            JobOutput{},
+	"job_result":     JobResultOutput{},
 }
 
 // JSONSchema represents a JSON Schema document.
@@ -127,6 +152,13 @@ func getSchemaTypes() []string {
 	return types
 }
 
+// SchemaFor returns the JSON Schema of v, a struct, generated the same way
+// as --robot-schema. Client generators use it for request bodies so that
+// they follow the server's types.
+func SchemaFor(v interface{}, name string) *JSONSchema {
+	return generateSchema(v, name)
+}
+
 // generateSchema creates a JSON Schema from a Go type.
 func generateSchema(v interface{}, name string) *JSONSchema {
 	schema := &JSONSchema{
@@ -269,16 +301,17 @@ func typeToSchema(t reflect.Type, defs map[string]*JSONSchema) *JSONSchema {
 			return schema
 		}
 
-		// Add to definitions if not already there
+		// Add to definitions if not already there. The definition is
+		// registered before its fields so self-referencing types terminate.
 		if _, exists := defs[typeName]; !exists {
 			schema := &JSONSchema{
 				Type:       "object",
 				Properties: make(map[string]*JSONSchema),
 			}
+			defs[typeName] = schema
 			var required []string
 			processStruct(t, schema.Properties, &required, defs)
 			schema.Required = required
-			defs[typeName] = schema
 		}
 
 		return &JSONSchema{
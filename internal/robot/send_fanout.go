@@ -0,0 +1,490 @@
+// Package robot provides machine-readable output for AI agents.
+// send_fanout.go implements `ntm robot send`: a per-pane templated fan-out
+// with rate-limit-aware staggering and confirmation of destructive prompts.
+package robot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/idempotency"
+	"github.com/Dicklesworthstone/ntm/internal/lint"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/redaction"
+	"github.com/Dicklesworthstone/ntm/internal/templates"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// fanoutIdempotencyScope namespaces fan-out keys in the idempotency store.
+const fanoutIdempotencyScope = "robot-send"
+
+// Fan-out pane result statuses.
+const (
+	FanoutStatusSent        = "sent"
+	FanoutStatusFailed      = "failed"
+	FanoutStatusDeferred    = "deferred"   // provider in rate-limit cooldown
+	FanoutStatusWouldSend   = "would_send" // dry run
+	FanoutStatusNeedConfirm = "needs_confirmation"
+)
+
+// fanoutSleep and fanoutSendKeys are replaced in tests.
+var (
+	fanoutSleep    = time.Sleep
+	fanoutSendKeys = tmux.SendKeysForAgentWithDelay
+)
+
+// FanoutSendOptions configures `ntm robot send`.
+type FanoutSendOptions struct {
+	Session string
+	// Panes selects targets by pane index, pane ID, title, or title suffix
+	// (e.g. "cc_1" matches "myproject__cc_1").
+	Panes []string
+	// Template names a prompt template; Message is used when it is empty.
+	// Either is rendered once per pane with Vars and the pane's agent context.
+	Template string
+	Message  string
+	Vars     map[string]string
+	// ConfirmToken confirms a destructive send (it must equal the token
+	// returned by the unconfirmed attempt). When IdempotencyKey is empty it
+	// also serves as one, so a confirmed send is delivered at most once.
+	ConfirmToken string
+	// IdempotencyKey makes retries replay the recorded results instead of
+	// sending again.
+	IdempotencyKey string
+	DryRun         bool
+	Enter          *bool
+	// DelayMs is the minimum stagger between sends; the rate-limit tracker's
+	// per-provider delay is used when it is longer.
+	DelayMs int
+	// ProjectDir locates project templates and .ntm/rate_limits.json.
+	ProjectDir string
+	// Schedule adjusts the tracker's delays during quiet hours and burst
+	// windows.
+	Schedule  *ratelimit.Schedule
+	Redaction redaction.Config
+	// IdempotencyDir overrides idempotency.DefaultDir.
+	IdempotencyDir string
+}
+
+// FanoutPaneResult is the delivery result for one pane.
+type FanoutPaneResult struct {
+	Pane          string `json:"pane"` // selector as given
+	PaneID        string `json:"pane_id"`
+	Index         int    `json:"index"`
+	Title         string `json:"title"`
+	AgentType     string `json:"agent_type"`
+	Status        string `json:"status"`
+	DelayMs       int64  `json:"delay_ms,omitempty"` // stagger applied before this send
+	PromptChars   int    `json:"prompt_chars"`
+	PromptPreview string `json:"prompt_preview"`
+	RetryAfterMs  int64  `json:"retry_after_ms,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// FanoutSendOutput is the response for `ntm robot send`.
+type FanoutSendOutput struct {
+	RobotResponse
+	Session            string             `json:"session"`
+	Template           string             `json:"template,omitempty"`
+	Destructive        bool               `json:"destructive"`
+	DestructiveReasons []string           `json:"destructive_reasons,omitempty"`
+	ConfirmToken       string             `json:"confirm_token,omitempty"`
+	Replayed           bool               `json:"replayed,omitempty"`
+	DryRun             bool               `json:"dry_run,omitempty"`
+	Redaction          RedactionSummary   `json:"redaction"`
+	Warnings           []string           `json:"warnings,omitempty"`
+	Delivered          int                `json:"delivered"`
+	Failed             int                `json:"failed"`
+	Deferred           int                `json:"deferred"`
+	Results            []FanoutPaneResult `json:"results"`
+}
+
+// fanoutTarget is a resolved pane with its rendered prompt.
+type fanoutTarget struct {
+	selector string
+	pane     tmux.Pane
+	agent    string
+	prompt   string
+}
+
+// GetFanoutSend renders and delivers a prompt to each selected pane.
+func GetFanoutSend(opts FanoutSendOptions) (*FanoutSendOutput, error) {
+	out := &FanoutSendOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		Template:      opts.Template,
+		DryRun:        opts.DryRun,
+		Results:       []FanoutPaneResult{},
+	}
+	fail := func(err error, code, hint string) (*FanoutSendOutput, error) {
+		out.RobotResponse = NewErrorResponse(err, code, hint)
+		return out, nil
+	}
+
+	switch {
+	case strings.TrimSpace(opts.Session) == "":
+		return fail(fmt.Errorf("session name is required"), ErrCodeInvalidFlag, "Pass --session")
+	case len(opts.Panes) == 0:
+		return fail(fmt.Errorf("at least one pane is required"), ErrCodeInvalidFlag, "Pass --panes cc_1,cc_2")
+	case opts.Template == "" && opts.Message == "":
+		return fail(fmt.Errorf("--template or --msg is required"), ErrCodeInvalidFlag, "Use 'ntm template list' to see templates")
+	case opts.Template != "" && opts.Message != "":
+		return fail(fmt.Errorf("--template and --msg are mutually exclusive"), ErrCodeInvalidFlag, "Pass only one of --template or --msg")
+	}
+	if !tmux.SessionExists(opts.Session) {
+		return fail(fmt.Errorf("session '%s' not found", opts.Session), ErrCodeSessionNotFound, "Use 'ntm list' to see available sessions")
+	}
+	panes, err := tmux.GetPanes(opts.Session)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get panes: %w", err), ErrCodeInternalError, "Check tmux is running")
+	}
+
+	tmpl, err := loadFanoutTemplate(opts)
+	if err != nil {
+		return fail(err, ErrCodeInvalidFlag, "Use 'ntm template list' to see templates")
+	}
+	targets, err := planFanout(opts, panes, tmpl)
+	if err != nil {
+		code := ErrCodeInvalidFlag
+		if strings.Contains(err.Error(), "not found") {
+			code = ErrCodePaneNotFound
+		}
+		return fail(err, code, "Select panes by index, ID, or name (e.g. cc_1)")
+	}
+	return deliverFanout(opts, out, targets), nil
+}
+
+// PrintFanoutSend handles `ntm robot send`.
+func PrintFanoutSend(opts FanoutSendOptions) error {
+	out, err := GetFanoutSend(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+func loadFanoutTemplate(opts FanoutSendOptions) (*templates.Template, error) {
+	if opts.Template == "" {
+		return &templates.Template{Name: "inline", Body: opts.Message}, nil
+	}
+	loader := templates.NewLoader()
+	if opts.ProjectDir != "" {
+		loader = templates.NewLoaderWithProject(opts.ProjectDir)
+	}
+	tmpl, err := loader.Load(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("loading template '%s': %w", opts.Template, err)
+	}
+	return tmpl, nil
+}
+
+// planFanout resolves the pane selectors and renders one prompt per pane.
+func planFanout(opts FanoutSendOptions, panes []tmux.Pane, tmpl *templates.Template) ([]fanoutTarget, error) {
+	var targets []fanoutTarget
+	seen := make(map[string]bool)
+	for _, sel := range opts.Panes {
+		sel = strings.TrimSpace(sel)
+		if sel == "" {
+			continue
+		}
+		pane, ok := resolveFanoutPane(panes, sel)
+		if !ok {
+			return nil, fmt.Errorf("pane '%s' not found in session '%s'", sel, opts.Session)
+		}
+		if seen[pane.ID] {
+			continue
+		}
+		seen[pane.ID] = true
+		agent := agentTypeString(pane.Type)
+		if agent == "user" || agent == "unknown" {
+			agent = detectAgentType(pane.Title)
+		}
+		targets = append(targets, fanoutTarget{selector: sel, pane: pane, agent: agent})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no panes selected")
+	}
+
+	for i := range targets {
+		t := &targets[i]
+		agentNum := t.pane.NTMIndex
+		if agentNum == 0 {
+			agentNum = i + 1
+		}
+		ctx := templates.ExecutionContext{Variables: opts.Vars, Session: opts.Session}.
+			WithAgent(agentNum, t.agent, t.pane.Variant, t.pane.ID).
+			WithSendBatch(i, len(targets))
+		prompt, err := tmpl.Execute(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rendering prompt for pane %s: %w", t.selector, err)
+		}
+		t.prompt = prompt
+	}
+	return targets, nil
+}
+
+// resolveFanoutPane matches a selector by index, exact title, title
+// suffix, or pane ID, in that order.
+func resolveFanoutPane(panes []tmux.Pane, sel string) (tmux.Pane, bool) {
+	for _, p := range panes {
+		if strconv.Itoa(p.Index) == sel {
+			return p, true
+		}
+	}
+	for _, p := range panes {
+		if p.Title == sel {
+			return p, true
+		}
+	}
+	for _, p := range panes {
+		if strings.HasSuffix(p.Title, "__"+sel) {
+			return p, true
+		}
+	}
+	for _, p := range panes {
+		if p.ID == sel {
+			return p, true
+		}
+	}
+	return tmux.Pane{}, false
+}
+
+// fanoutToken derives the confirmation token for a set of targets and
+// prompts, so a token only confirms exactly the send it was issued for.
+func fanoutToken(session string, targets []fanoutTarget) string {
+	h := sha256.New()
+	h.Write([]byte(session + "\x00"))
+	for _, t := range targets {
+		h.Write([]byte(t.pane.ID + "\x00" + t.prompt + "\x00"))
+	}
+	return "snd_" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+func deliverFanout(opts FanoutSendOptions, out *FanoutSendOutput, targets []fanoutTarget) *FanoutSendOutput {
+	redactCfg := normalizeSendRedactionConfig(opts.Redaction)
+	reasons := make(map[string]bool)
+	for i := range targets {
+		t := &targets[i]
+		redacted, _, summary, warnings, blocked := applySendMessageRedaction(t.prompt, redactCfg)
+		mergeRedactionSummary(&out.Redaction, summary)
+		if blocked {
+			out.RobotResponse = NewErrorResponse(
+				fmt.Errorf("refusing to proceed: potential secrets detected in prompt for pane %s (redaction mode: block)", t.selector),
+				ErrCodeSensitiveDataBlocked, "Re-run with --allow-secret to bypass, or use --redact=warn/--redact=redact")
+			return out
+		}
+		out.Warnings = append(out.Warnings, warnings...)
+		t.prompt = redacted
+		for _, f := range lint.CheckDestructive(t.prompt, lint.SeverityError) {
+			if reason, ok := f.Metadata["reason"].(string); ok {
+				reasons[reason] = true
+			}
+		}
+	}
+	for r := range reasons {
+		out.DestructiveReasons = append(out.DestructiveReasons, r)
+	}
+	sort.Strings(out.DestructiveReasons)
+	out.Destructive = len(reasons) > 0
+
+	token := fanoutToken(opts.Session, targets)
+	if out.Destructive {
+		out.ConfirmToken = token
+		switch {
+		case opts.ConfirmToken == "":
+			out.Results = plannedResults(targets, FanoutStatusNeedConfirm)
+			out.RobotResponse = NewErrorResponse(
+				fmt.Errorf("prompt contains destructive commands: %s", strings.Join(out.DestructiveReasons, "; ")),
+				ErrCodeConfirmationRequired,
+				fmt.Sprintf("Review the prompts, then re-run with --confirm-token %s", token))
+			return out
+		case opts.ConfirmToken != token:
+			out.Results = plannedResults(targets, FanoutStatusNeedConfirm)
+			out.RobotResponse = NewErrorResponse(
+				fmt.Errorf("confirmation token does not match this send (targets or prompts changed)"),
+				ErrCodeConfirmationRequired,
+				fmt.Sprintf("Re-run with --confirm-token %s", token))
+			return out
+		}
+	}
+
+	if opts.DryRun {
+		out.Results = plannedResults(targets, FanoutStatusWouldSend)
+		return out
+	}
+
+	key := opts.IdempotencyKey
+	if key == "" {
+		key = opts.ConfirmToken
+	}
+	var store *idempotency.Store
+	if key != "" {
+		store = idempotency.NewStore(opts.IdempotencyDir, 0)
+		prev, err := store.Begin(fanoutIdempotencyScope, key, token)
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			out.RobotResponse = NewErrorResponse(err, ErrCodeIdempotencyConflict, "Use a new key for a different send")
+			return out
+		case errors.Is(err, idempotency.ErrInProgress):
+			out.RobotResponse = NewErrorResponse(err, ErrCodeResourceBusy, "Wait for the original send to finish, then retry")
+			return out
+		case err != nil:
+			out.Warnings = append(out.Warnings, fmt.Sprintf("idempotency store unavailable: %v", err))
+			store = nil
+		case prev != nil:
+			if err := json.Unmarshal(prev.Body, &out.Results); err == nil {
+				out.Replayed = true
+				tallyFanout(out)
+				return out
+			}
+		}
+	}
+
+	var tracker *ratelimit.RateLimitTracker
+	if opts.ProjectDir != "" {
+		tracker = ratelimit.NewRateLimitTracker(opts.ProjectDir)
+		if err := tracker.LoadFromDir(opts.ProjectDir); err != nil {
+			tracker = nil
+		} else {
+			tracker.SetSchedule(opts.Schedule)
+		}
+	}
+	sendEnter := true
+	if opts.Enter != nil {
+		sendEnter = *opts.Enter
+	}
+
+	// Panes of the same provider are staggered by the tracker's learned
+	// delay; DelayMs applies between any two sends.
+	sentAny := false
+	sentProviders := make(map[string]bool)
+	for _, t := range targets {
+		res := newFanoutResult(t, "")
+		provider := ratelimit.NormalizeProvider(t.agent)
+		if tracker != nil {
+			if wait := tracker.CooldownRemaining(provider); wait > 0 {
+				res.Status = FanoutStatusDeferred
+				res.RetryAfterMs = wait.Milliseconds()
+				res.Error = fmt.Sprintf("%s is rate limited; retry in %s", provider, ratelimit.FormatDelay(wait))
+				out.Results = append(out.Results, res)
+				continue
+			}
+		}
+
+		var delay time.Duration
+		if sentAny {
+			delay = time.Duration(opts.DelayMs) * time.Millisecond
+		}
+		if tracker != nil && sentProviders[provider] {
+			delay = max(delay, tracker.GetOptimalDelay(provider))
+		}
+		if delay > 0 {
+			fanoutSleep(delay)
+			res.DelayMs = delay.Milliseconds()
+		}
+
+		enterDelay := tmux.DefaultEnterDelay
+		if t.pane.Type == tmux.AgentUser || t.agent == "user" || t.agent == "unknown" {
+			enterDelay = tmux.ShellEnterDelay
+		}
+		if err := fanoutSendKeys(t.pane.ID, t.prompt, sendEnter, enterDelay, t.pane.Type); err != nil {
+			res.Status = FanoutStatusFailed
+			res.Error = err.Error()
+		} else {
+			res.Status = FanoutStatusSent
+		}
+		sentAny = true
+		sentProviders[provider] = true
+		out.Results = append(out.Results, res)
+	}
+	tallyFanout(out)
+
+	if store != nil {
+		// Record any delivery, even partial, so a retry cannot resend to
+		// panes that already received the prompt.
+		if out.Delivered > 0 {
+			body, _ := json.Marshal(out.Results)
+			if err := store.Complete(fanoutIdempotencyScope, key, "application/json", body); err != nil {
+				out.Warnings = append(out.Warnings, fmt.Sprintf("could not record idempotency key: %v", err))
+			}
+		} else {
+			_ = store.Abandon(fanoutIdempotencyScope, key)
+		}
+	}
+	return out
+}
+
+func newFanoutResult(t fanoutTarget, status string) FanoutPaneResult {
+	return FanoutPaneResult{
+		Pane:          t.selector,
+		PaneID:        t.pane.ID,
+		Index:         t.pane.Index,
+		Title:         t.pane.Title,
+		AgentType:     t.agent,
+		Status:        status,
+		PromptChars:   len([]rune(t.prompt)),
+		PromptPreview: truncateMessage(t.prompt),
+	}
+}
+
+func plannedResults(targets []fanoutTarget, status string) []FanoutPaneResult {
+	results := make([]FanoutPaneResult, 0, len(targets))
+	for _, t := range targets {
+		results = append(results, newFanoutResult(t, status))
+	}
+	return results
+}
+
+// tallyFanout counts results and sets the overall outcome: success only if
+// every pane was delivered.
+func tallyFanout(out *FanoutSendOutput) {
+	out.Delivered, out.Failed, out.Deferred = 0, 0, 0
+	for _, r := range out.Results {
+		switch r.Status {
+		case FanoutStatusSent:
+			out.Delivered++
+		case FanoutStatusFailed:
+			out.Failed++
+		case FanoutStatusDeferred:
+			out.Deferred++
+		}
+	}
+	if out.Failed > 0 || out.Deferred > 0 {
+		out.RobotResponse = NewErrorResponse(
+			fmt.Errorf("%d of %d sends not delivered", out.Failed+out.Deferred, len(out.Results)),
+			ErrCodePromptSendFailed,
+			"Retry the failed or deferred panes; deferred panes list retry_after_ms")
+	}
+	if out.Failed == 0 && out.Deferred > 0 {
+		// Only rate limits held sends back: say when to retry
+		var retryAfterMs int64
+		for _, r := range out.Results {
+			if r.Status == FanoutStatusDeferred {
+				retryAfterMs = max(retryAfterMs, r.RetryAfterMs)
+			}
+		}
+		out.Remediation = RemediationFor(ErrCodeRateLimited, "")
+		out.Remediation.RetryAfterSeconds = int((retryAfterMs + 999) / 1000)
+	}
+}
+
+func mergeRedactionSummary(dst *RedactionSummary, s RedactionSummary) {
+	if dst.Mode == "" {
+		dst.Mode = s.Mode
+		dst.Action = s.Action
+	}
+	dst.Findings += s.Findings
+	for k, v := range s.Categories {
+		if dst.Categories == nil {
+			dst.Categories = make(map[string]int)
+		}
+		dst.Categories[k] += v
+	}
+}
@@ -0,0 +1,225 @@
+package robot
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/templates"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func fanoutTestPanes() []tmux.Pane {
+	return []tmux.Pane{
+		{ID: "%0", Index: 0, Title: "proj__user", Type: tmux.AgentUser},
+		{ID: "%1", Index: 1, Title: "proj__cc_1", Type: tmux.AgentClaude, NTMIndex: 1},
+		{ID: "%2", Index: 2, Title: "proj__cc_2", Type: tmux.AgentClaude, NTMIndex: 2},
+		{ID: "%3", Index: 3, Title: "proj__cod_1", Type: tmux.AgentCodex, NTMIndex: 1},
+	}
+}
+
+type fanoutSend struct {
+	pane, msg string
+}
+
+// stubFanout records sends and sleeps instead of touching tmux.
+func stubFanout(t *testing.T, fail map[string]bool) (*[]fanoutSend, *[]time.Duration) {
+	t.Helper()
+	var sends []fanoutSend
+	var sleeps []time.Duration
+	origSend, origSleep := fanoutSendKeys, fanoutSleep
+	fanoutSendKeys = func(target, keys string, enter bool, enterDelay time.Duration, agentType tmux.AgentType) error {
+		if fail[target] {
+			return errors.New("pane is dead")
+		}
+		sends = append(sends, fanoutSend{target, keys})
+		return nil
+	}
+	fanoutSleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	t.Cleanup(func() { fanoutSendKeys, fanoutSleep = origSend, origSleep })
+	return &sends, &sleeps
+}
+
+func TestPlanFanout(t *testing.T) {
+	tmpl := &templates.Template{Body: "Review PR #{{pr}} as {{agent_type}} {{agent_num}} ({{send_index}}/{{send_total}})"}
+	opts := FanoutSendOptions{
+		Session: "proj",
+		Panes:   []string{"cc_2", "3", "%2", "proj__cc_1"},
+		Vars:    map[string]string{"pr": "42"},
+	}
+	targets, err := planFanout(opts, fanoutTestPanes(), tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// "%2" duplicates cc_2 and is dropped.
+	if len(targets) != 3 {
+		t.Fatalf("got %d targets, want 3", len(targets))
+	}
+	want := []string{
+		"Review PR #42 as claude 2 (0/3)",
+		"Review PR #42 as codex 1 (1/3)",
+		"Review PR #42 as claude 1 (2/3)",
+	}
+	for i, tgt := range targets {
+		if tgt.prompt != want[i] {
+			t.Errorf("target %d prompt = %q, want %q", i, tgt.prompt, want[i])
+		}
+	}
+
+	opts.Panes = []string{"cc_9"}
+	if _, err := planFanout(opts, fanoutTestPanes(), tmpl); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unknown pane error = %v", err)
+	}
+
+	opts.Panes = []string{"cc_1"}
+	opts.Vars = nil
+	required := &templates.Template{Body: "{{pr}}", Variables: []templates.VariableSpec{{Name: "pr", Required: true}}}
+	if _, err := planFanout(opts, fanoutTestPanes(), required); err == nil {
+		t.Error("expected missing required variable error")
+	}
+}
+
+func TestDeliverFanout_StaggerAndDefer(t *testing.T) {
+	sends, sleeps := stubFanout(t, nil)
+	dir := t.TempDir()
+
+	tracker := ratelimit.NewRateLimitTracker(dir)
+	tracker.RecordSuccess("claude")
+	tracker.RecordRateLimitWithCooldown("openai", "send", 60)
+	if err := tracker.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	claudeDelay := tracker.GetOptimalDelay("claude")
+
+	opts := FanoutSendOptions{
+		Session:        "proj",
+		Panes:          []string{"cc_1", "cod_1", "cc_2"},
+		Message:        "status please",
+		DelayMs:        100,
+		ProjectDir:     dir,
+		IdempotencyDir: filepath.Join(dir, "idempotency"),
+	}
+	targets, err := planFanout(opts, fanoutTestPanes(), &templates.Template{Body: opts.Message})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := deliverFanout(opts, &FanoutSendOutput{RobotResponse: NewRobotResponse(true)}, targets)
+
+	if out.Delivered != 2 || out.Deferred != 1 || out.Success {
+		t.Fatalf("delivered=%d deferred=%d success=%v, want 2/1/false", out.Delivered, out.Deferred, out.Success)
+	}
+	if out.ErrorCode != ErrCodePromptSendFailed {
+		t.Errorf("ErrorCode = %q", out.ErrorCode)
+	}
+	if rem := out.Remediation; rem == nil || rem.Action != ActionWait || rem.RetryAfterSeconds <= 0 {
+		t.Errorf("Remediation = %+v, want wait with retry_after_seconds", rem)
+	}
+	if r := out.Results[1]; r.Status != FanoutStatusDeferred || r.RetryAfterMs <= 0 {
+		t.Errorf("codex result = %+v, want deferred with retry_after_ms", r)
+	}
+	if len(*sends) != 2 || (*sends)[0].pane != "%1" || (*sends)[1].pane != "%2" {
+		t.Errorf("sends = %+v", *sends)
+	}
+	// The second claude pane waits for the provider's learned delay.
+	if len(*sleeps) != 1 || (*sleeps)[0] != max(claudeDelay, 100*time.Millisecond) {
+		t.Errorf("sleeps = %v, want [%v]", *sleeps, claudeDelay)
+	}
+	if out.Results[2].DelayMs != (*sleeps)[0].Milliseconds() {
+		t.Errorf("reported delay = %d", out.Results[2].DelayMs)
+	}
+}
+
+func TestDeliverFanout_DestructiveConfirmation(t *testing.T) {
+	sends, _ := stubFanout(t, nil)
+	dir := t.TempDir()
+	opts := FanoutSendOptions{
+		Session:        "proj",
+		Panes:          []string{"cc_1", "cc_2"},
+		Message:        "clean up with rm -rf / and start over",
+		IdempotencyDir: filepath.Join(dir, "idempotency"),
+	}
+	run := func() *FanoutSendOutput {
+		targets, err := planFanout(opts, fanoutTestPanes(), &templates.Template{Body: opts.Message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return deliverFanout(opts, &FanoutSendOutput{RobotResponse: NewRobotResponse(true)}, targets)
+	}
+
+	out := run()
+	if out.Success || out.ErrorCode != ErrCodeConfirmationRequired || !out.Destructive || out.ConfirmToken == "" {
+		t.Fatalf("unconfirmed destructive send = %+v", out.RobotResponse)
+	}
+	if len(*sends) != 0 {
+		t.Fatal("nothing should be sent without confirmation")
+	}
+	if out.Results[0].Status != FanoutStatusNeedConfirm {
+		t.Errorf("status = %q", out.Results[0].Status)
+	}
+	token := out.ConfirmToken
+
+	opts.ConfirmToken = "snd_wrong"
+	if out := run(); out.ErrorCode != ErrCodeConfirmationRequired || len(*sends) != 0 {
+		t.Fatalf("mismatched token should be rejected: %+v", out.RobotResponse)
+	}
+
+	opts.ConfirmToken = token
+	out = run()
+	if !out.Success || out.Delivered != 2 || out.Replayed {
+		t.Fatalf("confirmed send = %+v", out)
+	}
+
+	// Repeating the confirmed command replays instead of sending again.
+	out = run()
+	if !out.Replayed || out.Delivered != 2 || len(*sends) != 2 {
+		t.Fatalf("replay = replayed %v delivered %d, sends %d", out.Replayed, out.Delivered, len(*sends))
+	}
+}
+
+func TestDeliverFanout_DryRunAndFailure(t *testing.T) {
+	sends, _ := stubFanout(t, map[string]bool{"%2": true})
+	opts := FanoutSendOptions{Session: "proj", Panes: []string{"cc_1", "cc_2"}, Message: "hi", DryRun: true}
+	targets, err := planFanout(opts, fanoutTestPanes(), &templates.Template{Body: opts.Message})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := deliverFanout(opts, &FanoutSendOutput{RobotResponse: NewRobotResponse(true)}, targets)
+	if !out.Success || len(*sends) != 0 || out.Results[1].Status != FanoutStatusWouldSend {
+		t.Fatalf("dry run = %+v", out)
+	}
+
+	opts.DryRun = false
+	out = deliverFanout(opts, &FanoutSendOutput{RobotResponse: NewRobotResponse(true)}, targets)
+	if out.Delivered != 1 || out.Failed != 1 || out.Results[1].Error == "" {
+		t.Fatalf("partial failure = %+v", out)
+	}
+}
+
+func TestDeliverFanout_IdempotencyKey(t *testing.T) {
+	sends, _ := stubFanout(t, nil)
+	dir := filepath.Join(t.TempDir(), "idempotency")
+	opts := FanoutSendOptions{Session: "proj", Panes: []string{"cc_1"}, Message: "hi", IdempotencyKey: "retry-1", IdempotencyDir: dir}
+	run := func() *FanoutSendOutput {
+		targets, err := planFanout(opts, fanoutTestPanes(), &templates.Template{Body: opts.Message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return deliverFanout(opts, &FanoutSendOutput{RobotResponse: NewRobotResponse(true)}, targets)
+	}
+
+	if out := run(); !out.Success || out.Replayed {
+		t.Fatalf("first send = %+v", out)
+	}
+	if out := run(); !out.Replayed || out.Delivered != 1 || len(*sends) != 1 {
+		t.Fatalf("retry should replay: replayed %v, sends %d", out.Replayed, len(*sends))
+	}
+
+	opts.Message = "something else"
+	if out := run(); out.ErrorCode != ErrCodeIdempotencyConflict || len(*sends) != 1 {
+		t.Fatalf("reused key for a different send = %+v", out.RobotResponse)
+	}
+}
@@ -155,7 +155,7 @@ func GetSmartRestart(opts SmartRestartOptions) (*SmartRestartOutput, error) {
 	output := &SmartRestartOutput{
 		RobotResponse: NewRobotResponse(true),
 		Session:       opts.Session,
-		Timestamp:     time.Now().UTC().Format(time.RFC3339),
+		Timestamp:     FormatTimestamp(time.Now()),
 		DryRun:        opts.DryRun,
 		Force:         opts.Force,
 		Actions:       make(map[string]RestartAction),
@@ -126,7 +126,7 @@ func GetSpawn(opts SpawnOptions, cfg *config.Config) (*SpawnOutput, error) {
 	output := &SpawnOutput{
 		RobotResponse: NewRobotResponse(true),
 		Session:       opts.Session,
-		CreatedAt:     startTime.UTC().Format(time.RFC3339),
+		CreatedAt:     FormatTimestamp(startTime.UTC()),
 		PresetUsed:    opts.Preset,
 		Agents:        []SpawnedAgent{},
 		Layout:        "tiled",
@@ -0,0 +1,366 @@
+// Package robot provides machine-readable output for AI agents.
+// status_diff.go implements `ntm robot status --diff`.
+package robot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultStatusSnapshotDir holds the status snapshots that
+// `ntm robot status --diff` compares against.
+const DefaultStatusSnapshotDir = "~/.config/ntm/status"
+
+// LastStatusSnapshot is the snapshot every --diff run compares against and
+// then replaces, so successive runs report incremental progress.
+const LastStatusSnapshot = "last"
+
+var statusSnapshotNameRE = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// StatusSnapshot is the part of the fleet status that --diff compares.
+type StatusSnapshot struct {
+	Name         string                `json:"name"`
+	TakenAt      time.Time             `json:"taken_at"`
+	Agents       []StatusSnapshotAgent `json:"agents"`
+	Conflicts    []string              `json:"conflicts"`
+	CostUSD      float64               `json:"cost_usd"`
+	SessionCosts map[string]float64    `json:"session_costs,omitempty"`
+}
+
+// StatusSnapshotAgent identifies an agent and its coarse status.
+type StatusSnapshotAgent struct {
+	Session string `json:"session"`
+	Pane    string `json:"pane"`
+	Name    string `json:"name,omitempty"`
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+}
+
+func (a StatusSnapshotAgent) key() string {
+	return a.Session + "/" + a.Pane
+}
+
+// StatusDiffOptions configures `ntm robot status --diff`.
+type StatusDiffOptions struct {
+	// Against names the snapshot to compare with (default LastStatusSnapshot).
+	Against string
+	// Save additionally stores the current status under this name.
+	Save string
+	// Dir overrides DefaultStatusSnapshotDir.
+	Dir string
+	// ProjectDir is where the cost tracker's .ntm/costs.json is read from.
+	ProjectDir string
+}
+
+// StatusDiffOutput is the response for `ntm robot status --diff`.
+type StatusDiffOutput struct {
+	RobotResponse
+	GeneratedAt time.Time `json:"generated_at"`
+	// Baseline is the snapshot compared against; nil on the first run, when
+	// every agent is reported as added.
+	Baseline          *StatusBaseline       `json:"baseline,omitempty"`
+	Changed           bool                  `json:"changed"`
+	AgentsAdded       []StatusSnapshotAgent `json:"agents_added"`
+	AgentsRemoved     []StatusSnapshotAgent `json:"agents_removed"`
+	StatusChanges     []AgentStatusChange   `json:"status_changes"`
+	NewConflicts      []string              `json:"new_conflicts"`
+	ResolvedConflicts []string              `json:"resolved_conflicts"`
+	Cost              StatusCostDelta       `json:"cost"`
+	SavedAs           []string              `json:"saved_as,omitempty"`
+}
+
+// StatusBaseline identifies the snapshot a diff was computed against.
+type StatusBaseline struct {
+	Name    string `json:"name"`
+	TakenAt string `json:"taken_at"`
+}
+
+// AgentStatusChange is an agent whose status differs from the baseline.
+type AgentStatusChange struct {
+	Session string `json:"session"`
+	Pane    string `json:"pane"`
+	Name    string `json:"name,omitempty"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// StatusCostDelta reports spend since the baseline.
+type StatusCostDelta struct {
+	BeforeUSD float64 `json:"before_usd"`
+	AfterUSD  float64 `json:"after_usd"`
+	DeltaUSD  float64 `json:"delta_usd"`
+	// Sessions lists per-session deltas, omitting sessions that spent nothing.
+	Sessions map[string]float64 `json:"sessions,omitempty"`
+}
+
+// agentDiffStatus reduces an agent to a status worth reporting a
+// transition for: rate limits first, then the process state.
+func agentDiffStatus(a Agent) string {
+	switch {
+	case a.RateLimitDetected:
+		return "rate_limited"
+	case a.ProcessStateName != "":
+		return a.ProcessStateName
+	default:
+		return "unknown"
+	}
+}
+
+// SnapshotFromStatus extracts the comparable parts of a status report.
+func SnapshotFromStatus(name string, st *StatusOutput, sessionCosts map[string]float64) StatusSnapshot {
+	snap := StatusSnapshot{
+		Name:         name,
+		TakenAt:      st.GeneratedAt,
+		Agents:       []StatusSnapshotAgent{},
+		Conflicts:    []string{},
+		SessionCosts: sessionCosts,
+	}
+	for _, sess := range st.Sessions {
+		for _, a := range sess.Agents {
+			snap.Agents = append(snap.Agents, StatusSnapshotAgent{
+				Session: sess.Name,
+				Pane:    a.Pane,
+				Name:    a.Name,
+				Type:    a.Type,
+				Status:  agentDiffStatus(a),
+			})
+		}
+	}
+	seen := make(map[string]bool)
+	for _, c := range st.Conflicts {
+		if !seen[c.Path] {
+			seen[c.Path] = true
+			snap.Conflicts = append(snap.Conflicts, c.Path)
+		}
+	}
+	sort.Strings(snap.Conflicts)
+	for _, usd := range sessionCosts {
+		snap.CostUSD += usd
+	}
+	snap.CostUSD = roundUSD(snap.CostUSD)
+	return snap
+}
+
+// DiffStatusSnapshots reports what changed from before to after. A nil
+// before is treated as an empty fleet.
+func DiffStatusSnapshots(before *StatusSnapshot, after StatusSnapshot) *StatusDiffOutput {
+	out := &StatusDiffOutput{
+		RobotResponse:     NewRobotResponse(true),
+		GeneratedAt:       after.TakenAt,
+		AgentsAdded:       []StatusSnapshotAgent{},
+		AgentsRemoved:     []StatusSnapshotAgent{},
+		StatusChanges:     []AgentStatusChange{},
+		NewConflicts:      []string{},
+		ResolvedConflicts: []string{},
+	}
+	if before == nil {
+		before = &StatusSnapshot{}
+	} else {
+		out.Baseline = &StatusBaseline{Name: before.Name, TakenAt: FormatTimestamp(before.TakenAt)}
+	}
+
+	prev := make(map[string]StatusSnapshotAgent, len(before.Agents))
+	for _, a := range before.Agents {
+		prev[a.key()] = a
+	}
+	curr := make(map[string]bool, len(after.Agents))
+	for _, a := range after.Agents {
+		curr[a.key()] = true
+		old, ok := prev[a.key()]
+		switch {
+		case !ok || old.Type != a.Type:
+			// A different agent type in the same pane is a replacement.
+			if ok {
+				out.AgentsRemoved = append(out.AgentsRemoved, old)
+			}
+			out.AgentsAdded = append(out.AgentsAdded, a)
+		case old.Status != a.Status:
+			out.StatusChanges = append(out.StatusChanges, AgentStatusChange{
+				Session: a.Session, Pane: a.Pane, Name: a.Name, From: old.Status, To: a.Status,
+			})
+		}
+	}
+	for _, a := range before.Agents {
+		if !curr[a.key()] {
+			out.AgentsRemoved = append(out.AgentsRemoved, a)
+		}
+	}
+
+	out.NewConflicts = setDifference(after.Conflicts, before.Conflicts)
+	out.ResolvedConflicts = setDifference(before.Conflicts, after.Conflicts)
+
+	out.Cost = StatusCostDelta{
+		BeforeUSD: before.CostUSD,
+		AfterUSD:  after.CostUSD,
+		DeltaUSD:  roundUSD(after.CostUSD - before.CostUSD),
+	}
+	for session, usd := range after.SessionCosts {
+		if d := roundUSD(usd - before.SessionCosts[session]); d != 0 {
+			if out.Cost.Sessions == nil {
+				out.Cost.Sessions = make(map[string]float64)
+			}
+			out.Cost.Sessions[session] = d
+		}
+	}
+
+	out.Changed = len(out.AgentsAdded) > 0 || len(out.AgentsRemoved) > 0 ||
+		len(out.StatusChanges) > 0 || len(out.NewConflicts) > 0 ||
+		len(out.ResolvedConflicts) > 0 || out.Cost.DeltaUSD != 0
+	return out
+}
+
+// setDifference returns the elements of a not in b, preserving a's order.
+func setDifference(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, s := range b {
+		in[s] = true
+	}
+	out := []string{}
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func roundUSD(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+func statusSnapshotPath(dir, name string) (string, error) {
+	if !statusSnapshotNameRE.MatchString(name) {
+		return "", fmt.Errorf("invalid snapshot name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	if dir == "" {
+		dir = DefaultStatusSnapshotDir
+	}
+	return filepath.Join(util.ExpandPath(dir), name+".json"), nil
+}
+
+// LoadStatusSnapshot reads a named snapshot; it returns nil, nil if the
+// snapshot does not exist.
+func LoadStatusSnapshot(dir, name string) (*StatusSnapshot, error) {
+	path, err := statusSnapshotPath(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read status snapshot: %w", err)
+	}
+	var snap StatusSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parse status snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// SaveStatusSnapshot writes snap under its name.
+func SaveStatusSnapshot(dir string, snap StatusSnapshot) error {
+	path, err := statusSnapshotPath(dir, snap.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create status snapshot dir: %w", err)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal status snapshot: %w", err)
+	}
+	return util.AtomicWriteFile(path, data, 0644)
+}
+
+// statusSessionCosts returns the tracked spend of each session in st.
+func statusSessionCosts(projectDir string, st *StatusOutput) map[string]float64 {
+	tracker := cost.NewCostTracker(projectDir)
+	if projectDir == "" || tracker.LoadFromDir(projectDir) != nil {
+		return nil
+	}
+	costs := make(map[string]float64)
+	for _, sess := range st.Sessions {
+		if usd := tracker.GetSessionCost(sess.Name); usd > 0 {
+			costs[sess.Name] = roundUSD(usd)
+		}
+	}
+	return costs
+}
+
+// GetStatusDiff compares the current status with a stored snapshot, then
+// stores the current status as LastStatusSnapshot (and opts.Save, if set).
+func GetStatusDiff(opts StatusDiffOptions) (*StatusDiffOutput, error) {
+	against := opts.Against
+	if against == "" {
+		against = LastStatusSnapshot
+	}
+	baseline, err := LoadStatusSnapshot(opts.Dir, against)
+	if err != nil {
+		return statusDiffError(err, "Check the --against snapshot name"), nil
+	}
+	if baseline == nil && opts.Against != "" {
+		err := fmt.Errorf("status snapshot %q not found", opts.Against)
+		return statusDiffError(err, "Save one first with: ntm robot status --save "+opts.Against), nil
+	}
+
+	st, err := GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	snap := SnapshotFromStatus(LastStatusSnapshot, st, statusSessionCosts(opts.ProjectDir, st))
+	out := DiffStatusSnapshots(baseline, snap)
+
+	names := []string{LastStatusSnapshot}
+	if opts.Save != "" && opts.Save != LastStatusSnapshot {
+		names = append(names, opts.Save)
+	}
+	for _, name := range names {
+		snap.Name = name
+		if err := SaveStatusSnapshot(opts.Dir, snap); err != nil {
+			return nil, err
+		}
+		out.SavedAs = append(out.SavedAs, name)
+	}
+	return out, nil
+}
+
+func statusDiffError(err error, hint string) *StatusDiffOutput {
+	out := DiffStatusSnapshots(nil, StatusSnapshot{TakenAt: time.Now()})
+	out.RobotResponse = NewErrorResponse(err, ErrCodeInvalidFlag, hint)
+	return out
+}
+
+// PrintStatusDiff handles `ntm robot status --diff`.
+func PrintStatusDiff(opts StatusDiffOptions) error {
+	out, err := GetStatusDiff(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+// SaveCurrentStatus stores the current status under name without diffing.
+func SaveCurrentStatus(opts StatusDiffOptions, name string) (*StatusSnapshot, error) {
+	st, err := GetStatus()
+	if err != nil {
+		return nil, err
+	}
+	snap := SnapshotFromStatus(name, st, statusSessionCosts(opts.ProjectDir, st))
+	if err := SaveStatusSnapshot(opts.Dir, snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
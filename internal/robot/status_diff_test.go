@@ -0,0 +1,120 @@
+package robot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func TestSnapshotFromStatus(t *testing.T) {
+	t.Parallel()
+	st := &StatusOutput{
+		GeneratedAt: time.Now(),
+		Sessions: []SessionInfo{{
+			Name: "proj",
+			Agents: []Agent{
+				{Type: "claude", Pane: "%1", ProcessStateName: "running"},
+				{Type: "codex", Pane: "%2", ProcessStateName: "sleeping", RateLimitDetected: true},
+				{Type: "gemini", Pane: "%3"},
+			},
+		}},
+		Conflicts: []tracker.Conflict{{Path: "b.go"}, {Path: "a.go"}, {Path: "b.go"}},
+	}
+	snap := SnapshotFromStatus("x", st, map[string]float64{"proj": 1.25, "other": 0.5})
+
+	wantStatus := []string{"running", "rate_limited", "unknown"}
+	for i, a := range snap.Agents {
+		if a.Status != wantStatus[i] || a.Session != "proj" {
+			t.Errorf("agent %d = %+v, want status %s", i, a, wantStatus[i])
+		}
+	}
+	if len(snap.Conflicts) != 2 || snap.Conflicts[0] != "a.go" {
+		t.Errorf("conflicts = %v, want deduplicated and sorted", snap.Conflicts)
+	}
+	if snap.CostUSD != 1.75 {
+		t.Errorf("cost = %v, want 1.75", snap.CostUSD)
+	}
+}
+
+func TestDiffStatusSnapshots(t *testing.T) {
+	t.Parallel()
+	before := &StatusSnapshot{
+		Name:    "last",
+		TakenAt: time.Now().Add(-time.Hour),
+		Agents: []StatusSnapshotAgent{
+			{Session: "proj", Pane: "%1", Type: "claude", Status: "running"},
+			{Session: "proj", Pane: "%2", Type: "codex", Status: "running"},
+			{Session: "proj", Pane: "%3", Type: "gemini", Status: "running"},
+		},
+		Conflicts:    []string{"a.go", "b.go"},
+		CostUSD:      1.0,
+		SessionCosts: map[string]float64{"proj": 1.0},
+	}
+	after := StatusSnapshot{
+		TakenAt: time.Now(),
+		Agents: []StatusSnapshotAgent{
+			{Session: "proj", Pane: "%1", Type: "claude", Status: "rate_limited"},
+			{Session: "proj", Pane: "%2", Type: "codex", Status: "running"},
+			{Session: "proj", Pane: "%4", Type: "claude", Status: "running"},
+		},
+		Conflicts:    []string{"b.go", "c.go"},
+		CostUSD:      1.5,
+		SessionCosts: map[string]float64{"proj": 1.5},
+	}
+
+	d := DiffStatusSnapshots(before, after)
+	if !d.Changed || d.Baseline == nil || d.Baseline.Name != "last" {
+		t.Fatalf("diff = %+v", d)
+	}
+	if len(d.AgentsAdded) != 1 || d.AgentsAdded[0].Pane != "%4" {
+		t.Errorf("added = %+v", d.AgentsAdded)
+	}
+	if len(d.AgentsRemoved) != 1 || d.AgentsRemoved[0].Pane != "%3" {
+		t.Errorf("removed = %+v", d.AgentsRemoved)
+	}
+	if len(d.StatusChanges) != 1 || d.StatusChanges[0].From != "running" || d.StatusChanges[0].To != "rate_limited" {
+		t.Errorf("status changes = %+v", d.StatusChanges)
+	}
+	if len(d.NewConflicts) != 1 || d.NewConflicts[0] != "c.go" || len(d.ResolvedConflicts) != 1 || d.ResolvedConflicts[0] != "a.go" {
+		t.Errorf("conflicts new=%v resolved=%v", d.NewConflicts, d.ResolvedConflicts)
+	}
+	if d.Cost.DeltaUSD != 0.5 || d.Cost.Sessions["proj"] != 0.5 {
+		t.Errorf("cost = %+v", d.Cost)
+	}
+
+	if same := DiffStatusSnapshots(&after, after); same.Changed {
+		t.Errorf("identical snapshots reported as changed: %+v", same)
+	}
+}
+
+func TestDiffStatusSnapshots_NoBaseline(t *testing.T) {
+	t.Parallel()
+	after := StatusSnapshot{Agents: []StatusSnapshotAgent{{Session: "proj", Pane: "%1", Type: "claude"}}}
+	d := DiffStatusSnapshots(nil, after)
+	if d.Baseline != nil || len(d.AgentsAdded) != 1 || d.AgentsRemoved == nil {
+		t.Fatalf("diff = %+v", d)
+	}
+}
+
+func TestStatusSnapshotRoundTrip(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if snap, err := LoadStatusSnapshot(dir, "missing"); snap != nil || err != nil {
+		t.Fatalf("missing snapshot = %v, %v; want nil, nil", snap, err)
+	}
+
+	want := StatusSnapshot{Name: "standup", TakenAt: time.Now().UTC().Truncate(time.Second), CostUSD: 2,
+		Agents: []StatusSnapshotAgent{{Session: "s", Pane: "%1", Type: "claude", Status: "running"}}}
+	if err := SaveStatusSnapshot(dir, want); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := LoadStatusSnapshot(dir, "standup")
+	if err != nil || got == nil || !got.TakenAt.Equal(want.TakenAt) || len(got.Agents) != 1 {
+		t.Fatalf("load = %+v, %v", got, err)
+	}
+
+	if err := SaveStatusSnapshot(dir, StatusSnapshot{Name: "../escape"}); err == nil {
+		t.Error("expected error for snapshot name with path separators")
+	}
+}
@@ -0,0 +1,193 @@
+// Package robot provides machine-readable output for AI agents.
+// summarize.go implements `ntm robot summarize`, a token-budgeted catch-up
+// on one pane.
+package robot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// DefaultSummarizeBudget is the token budget when none is given.
+const DefaultSummarizeBudget = 800
+
+// Overridable for tests.
+var (
+	summarizeSessionExists = tmux.SessionExists
+	summarizeGetPanes      = tmux.GetPanes
+	summarizeCapture       = tmux.CapturePaneOutput
+	summarizeArchived      = archive.RecentPaneRecords
+)
+
+// SummarizeOptions configures `ntm robot summarize`.
+type SummarizeOptions struct {
+	Session string
+	// Pane is a pane index, a title or title suffix (cc_1), or a pane ID.
+	Pane string
+	// Budget is the maximum size of the summary in tokens.
+	Budget int
+	// Lines is how much live scrollback to capture.
+	Lines int
+	// Archived is how many archived capture segments to include before the
+	// live capture, for output that has scrolled away.
+	Archived int
+	// ArchiveDir overrides archive.DefaultOutputDir.
+	ArchiveDir string
+	// Summarizer optionally rewrites the extractive digest; nil keeps it.
+	Summarizer summary.Summarizer
+	// Timeout bounds the summarizer call.
+	Timeout time.Duration
+}
+
+// SummarizeSources describes the captures a summary was built from.
+type SummarizeSources struct {
+	LiveLines        int `json:"live_lines"`        // Lines of live scrollback captured
+	ArchivedSegments int `json:"archived_segments"` // Archived capture segments read
+}
+
+// SummarizeOutput is the response for `ntm robot summarize`.
+type SummarizeOutput struct {
+	RobotResponse
+	Session   string             `json:"session"`
+	Pane      string             `json:"pane"`
+	PaneIndex int                `json:"pane_index"`
+	Agent     string             `json:"agent,omitempty"`
+	Budget    int                `json:"budget"`
+	Summary   summary.PaneDigest `json:"summary"`
+	Sources   SummarizeSources   `json:"sources"`
+	Warning   string             `json:"warning,omitempty"`
+}
+
+// GetSummarize summarizes the recent output of one pane within a token
+// budget: archived and live captures are combined, stripped of spinners,
+// progress bars and other noise, and reduced to their most informative
+// lines (or rewritten by the summarizer, when one is given).
+func GetSummarize(opts SummarizeOptions) (*SummarizeOutput, error) {
+	out := &SummarizeOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		Pane:          opts.Pane,
+		PaneIndex:     -1,
+		Budget:        opts.Budget,
+	}
+	if opts.Session == "" || opts.Pane == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session and pane are required"), ErrCodeInvalidFlag, "Pass --session and --pane")
+		return out, nil
+	}
+	if out.Budget <= 0 {
+		out.Budget = DefaultSummarizeBudget
+	}
+	if opts.Lines <= 0 {
+		opts.Lines = tmux.LinesFullContext
+	}
+
+	live := summarizeSessionExists(opts.Session)
+	var pane *tmux.Pane
+	if live {
+		panes, err := summarizeGetPanes(opts.Session)
+		if err != nil {
+			out.RobotResponse = NewErrorResponse(fmt.Errorf("failed to get panes: %w", err), ErrCodeInternalError, "Check tmux session state")
+			return out, nil
+		}
+		pane = findSummarizePane(panes, opts.Pane)
+		if pane == nil {
+			out.RobotResponse = NewErrorResponse(fmt.Errorf("pane %q not found in session %q", opts.Pane, opts.Session), ErrCodeInvalidFlag, "Use a pane index, a name such as cc_1, or a pane ID")
+			return out, nil
+		}
+		out.PaneIndex = pane.Index
+		out.Agent = string(pane.Type)
+		if _, name, ok := strings.Cut(pane.Title, "__"); ok {
+			out.Pane = name
+		}
+	} else if idx, err := strconv.Atoi(opts.Pane); err == nil {
+		// A stopped session can still be summarized from its archive.
+		out.PaneIndex = idx
+	} else {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session %q not found", opts.Session), ErrCodeSessionNotFound, "Summarize a stopped session's archive by pane index")
+		return out, nil
+	}
+
+	var parts []string
+	if opts.Archived > 0 {
+		records, err := summarizeArchived(opts.ArchiveDir, opts.Session, out.PaneIndex, opts.Archived)
+		if err == nil {
+			for _, r := range records {
+				parts = append(parts, r.Content)
+				if out.Agent == "" {
+					out.Agent = r.Agent
+				}
+			}
+			out.Sources.ArchivedSegments = len(records)
+		}
+	}
+	if pane != nil {
+		content, err := summarizeCapture(pane.ID, opts.Lines)
+		if err != nil {
+			out.RobotResponse = NewErrorResponse(fmt.Errorf("failed to capture pane: %w", err), ErrCodeInternalError, "Check that the pane is still running")
+			return out, nil
+		}
+		parts = append(parts, content)
+		out.Sources.LiveLines = strings.Count(content, "\n") + 1
+	}
+	if len(parts) == 0 {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("no captured output for pane %s", opts.Pane), ErrCodeSessionNotFound, "Run 'ntm monitor' to archive pane output")
+		return out, nil
+	}
+
+	text := strings.Join(parts, "\n")
+	// As a background job, the extractive digest is available as a partial
+	// result while the summarizer runs.
+	if rep := jobs.Current(); rep != nil && opts.Summarizer != nil {
+		rep.Partial(summary.DigestPaneOutput(text, out.Budget))
+		rep.Progress(50, "waiting for the summarizer")
+	}
+
+	ctx := context.Background()
+	if opts.Summarizer != nil && opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	digest, err := summary.SummarizePaneOutput(ctx, text, out.Budget, opts.Summarizer)
+	if err != nil {
+		out.Warning = fmt.Sprintf("summarizer failed, returning extractive summary: %v", err)
+	}
+	out.Summary = digest
+	return out, nil
+}
+
+// findSummarizePane resolves a pane by index, exact title, title suffix
+// ("cc_1" matches "myproject__cc_1"), or ID.
+func findSummarizePane(panes []tmux.Pane, ref string) *tmux.Pane {
+	matchers := []func(tmux.Pane) bool{
+		func(p tmux.Pane) bool { return strconv.Itoa(p.Index) == ref },
+		func(p tmux.Pane) bool { return p.Title == ref },
+		func(p tmux.Pane) bool { return strings.HasSuffix(p.Title, "__"+ref) },
+		func(p tmux.Pane) bool { return p.ID == ref },
+	}
+	for _, match := range matchers {
+		for i := range panes {
+			if match(panes[i]) {
+				return &panes[i]
+			}
+		}
+	}
+	return nil
+}
+
+// PrintSummarize handles `ntm robot summarize`.
+func PrintSummarize(opts SummarizeOptions) error {
+	out, err := GetSummarize(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
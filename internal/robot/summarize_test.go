@@ -0,0 +1,82 @@
+package robot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func stubSummarize(t *testing.T, live bool) {
+	t.Helper()
+	oldExists, oldPanes, oldCapture, oldArchived := summarizeSessionExists, summarizeGetPanes, summarizeCapture, summarizeArchived
+	t.Cleanup(func() {
+		summarizeSessionExists, summarizeGetPanes, summarizeCapture, summarizeArchived = oldExists, oldPanes, oldCapture, oldArchived
+	})
+	summarizeSessionExists = func(string) bool { return live }
+	summarizeGetPanes = func(string) ([]tmux.Pane, error) {
+		return []tmux.Pane{
+			{ID: "%1", Index: 1, Title: "proj__user_1", Type: tmux.AgentUser},
+			{ID: "%2", Index: 2, Title: "proj__cc_1", Type: tmux.AgentClaude},
+		}, nil
+	}
+	summarizeCapture = func(target string, lines int) (string, error) {
+		if target != "%2" {
+			t.Errorf("captured %s, want %%2", target)
+		}
+		return "⠋ Working…\n⏺ Update(internal/api/routes.go)\n--- FAIL: TestRoutes (0.01s)\n", nil
+	}
+	summarizeArchived = func(dir, session string, paneIndex, n int) ([]archive.ArchiveRecord, error) {
+		if paneIndex != 2 {
+			t.Errorf("archive pane = %d, want 2", paneIndex)
+		}
+		return []archive.ArchiveRecord{
+			{Agent: "cc", Timestamp: time.Now(), Content: "⏺ I'll start with the routes.\n⏺ Read(internal/api/routes.go)\n"},
+		}, nil
+	}
+}
+
+func TestGetSummarize(t *testing.T) {
+	stubSummarize(t, true)
+
+	out, err := GetSummarize(SummarizeOptions{Session: "proj", Pane: "cc_1", Archived: 5})
+	if err != nil || !out.Success {
+		t.Fatalf("GetSummarize() = %+v, %v", out, err)
+	}
+	if out.Pane != "cc_1" || out.PaneIndex != 2 || out.Agent != string(tmux.AgentClaude) || out.Budget != DefaultSummarizeBudget {
+		t.Errorf("out = %+v", out)
+	}
+	if out.Sources.ArchivedSegments != 1 || out.Sources.LiveLines == 0 {
+		t.Errorf("sources = %+v", out.Sources)
+	}
+	want := "⏺ I'll start with the routes.\n⏺ Read(internal/api/routes.go)\n⏺ Update(internal/api/routes.go)\n--- FAIL: TestRoutes (0.01s)"
+	if out.Summary.Method != summary.MethodExtractive || out.Summary.Text != want {
+		t.Errorf("summary = %+v, want text %q", out.Summary, want)
+	}
+	if out.Summary.NoiseLines != 1 {
+		t.Errorf("noise = %d, want the spinner line", out.Summary.NoiseLines)
+	}
+}
+
+func TestGetSummarizeErrors(t *testing.T) {
+	stubSummarize(t, true)
+	if out, _ := GetSummarize(SummarizeOptions{Session: "proj"}); out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("missing pane: %+v", out.RobotResponse)
+	}
+	if out, _ := GetSummarize(SummarizeOptions{Session: "proj", Pane: "cod_3"}); out.Success || !strings.Contains(out.Error, "not found") {
+		t.Errorf("unknown pane: %+v", out.RobotResponse)
+	}
+
+	// A stopped session falls back to its archive by pane index.
+	stubSummarize(t, false)
+	out, _ := GetSummarize(SummarizeOptions{Session: "proj", Pane: "2", Archived: 3})
+	if !out.Success || out.Sources.LiveLines != 0 || !strings.Contains(out.Summary.Text, "start with the routes") {
+		t.Errorf("archive only: %+v", out)
+	}
+	if out, _ := GetSummarize(SummarizeOptions{Session: "proj", Pane: "cc_1"}); out.ErrorCode != ErrCodeSessionNotFound {
+		t.Errorf("stopped session by name: %+v", out.RobotResponse)
+	}
+}
@@ -6,17 +6,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/agent"
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/deadline"
+	"github.com/Dicklesworthstone/ntm/internal/status"
+	"github.com/Dicklesworthstone/ntm/internal/summary"
 	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/tokens"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
 )
 
 // ConflictReason describes why a file conflict was detected.
@@ -36,16 +44,26 @@ const (
 // DetectedConflict represents a detected or potential file conflict from synthesis analysis.
 // This extends the simpler FileConflict in tui_parity.go with more detailed conflict analysis.
 type DetectedConflict struct {
-	// Path is the file path relative to the repository root.
+	// ID identifies conflicts on this file across detections; see
+	// tracker.ConflictID.
+	ID string `json:"id"`
+
+	// Path is the file path relative to the repository root, as
+	// "repo:path" for submodules and other workspace repositories.
 	Path string `json:"path"`
 
+	// Repo names the workspace repository holding the file; empty for the
+	// primary repository.
+	Repo string `json:"repo,omitempty"`
+
 	// LikelyModifiers are pane IDs that may have modified this file.
 	LikelyModifiers []string `json:"likely_modifiers"`
 
 	// GitStatus is the git status code (M=modified, A=added, D=deleted, ??=untracked).
 	GitStatus string `json:"git_status"`
 
-	// Confidence is a score from 0.0-1.0 indicating conflict likelihood.
+	// Confidence is a score from 0.0-1.0 indicating conflict likelihood,
+	// weighted by the scoring profile (see ConflictScoring).
 	// 0.9+ = high, 0.7-0.9 = medium, 0.5-0.7 = low
 	Confidence float64 `json:"confidence"`
 
@@ -60,6 +78,18 @@ type DetectedConflict struct {
 
 	// Details provides additional context for the conflict.
 	Details string `json:"details,omitempty"`
+
+	// Suppressed is the reason of the suppression rule muting this conflict.
+	Suppressed string `json:"suppressed,omitempty"`
+
+	// Ack is set when the conflict was acknowledged (ntm conflicts ack).
+	Ack *ConflictAck `json:"ack,omitempty"`
+}
+
+// Muted reports whether the conflict is suppressed or acknowledged, and so
+// should not alert.
+func (dc *DetectedConflict) Muted() bool {
+	return dc.Suppressed != "" || dc.Ack != nil
 }
 
 // ConflictConfidence categorizes confidence levels.
@@ -112,7 +142,8 @@ func (aw *ActivityWindow) Contains(t time.Time) bool {
 // GitFileStatus represents a file's status from git.
 type GitFileStatus struct {
 	Path       string    `json:"path"`
-	Status     string    `json:"status"` // M, A, D, ??, etc.
+	Repo       string    `json:"repo,omitempty"` // Workspace repository; empty for the primary one
+	Status     string    `json:"status"`         // M, A, D, ??, etc.
 	Staged     bool      `json:"staged"`
 	ModifiedAt time.Time `json:"modified_at,omitempty"`
 }
@@ -121,8 +152,22 @@ type GitFileStatus struct {
 type ConflictDetector struct {
 	repoPath        string
 	activityWindows map[string][]ActivityWindow // paneID -> windows
+	toolEdits       map[string]map[string]bool  // paneID -> files its tool calls changed
 	amClient        *agentmail.Client
 	projectKey      string
+	exclude         []string
+	includeIgnored  bool
+	extraRepos      []string
+	skipSubmodules  bool
+	repos           []WorkspaceRepo // Discovered on first use and each git status
+	scoring         ConflictScoring
+	suppress        []ConflictSuppression
+
+	session          string
+	statusDetector   status.Detector
+	activityLookback time.Duration
+	lastObserved     map[string]time.Time // paneID -> last status observation
+	openWindows      map[string]bool      // paneID -> last window still growing
 
 	mu sync.RWMutex
 }
@@ -132,6 +177,32 @@ type ConflictDetectorConfig struct {
 	RepoPath   string
 	ProjectKey string
 	AMClient   *agentmail.Client
+	// Exclude lists path patterns (as for reservations) left out of
+	// conflict analysis, e.g. generated code.
+	Exclude []string
+	// IncludeIgnored analyzes changed files that match .gitignore rules,
+	// such as tracked build artifacts. By default they are skipped.
+	IncludeIgnored bool
+	// Repos lists more repository roots of a multi-repo workspace,
+	// absolute or relative to RepoPath. Their paths are namespaced as
+	// "repo:path".
+	Repos []string
+	// SkipSubmodules treats git submodules as single entries instead of
+	// scanning their files.
+	SkipSubmodules bool
+	// Session, if set, has DetectConflicts record each pane's activity
+	// window from status detection before analyzing files.
+	Session string
+	// StatusDetector detects pane status (default: status.NewDetector()).
+	StatusDetector status.Detector
+	// ActivityLookback is how far back a pane's last output counts when it
+	// is first observed (default: DefaultActivityLookback).
+	ActivityLookback time.Duration
+	// Scoring weights conflicts and sets the reporting threshold; unset
+	// values come from DefaultConflictScoring.
+	Scoring ConflictScoring
+	// Suppress mutes conflicts on matching paths until each rule expires.
+	Suppress []ConflictSuppression
 }
 
 // NewConflictDetector creates a new conflict detector.
@@ -145,15 +216,101 @@ func NewConflictDetector(cfg *ConflictDetectorConfig) *ConflictDetector {
 		repoPath, _ = os.Getwd()
 	}
 
+	statusDetector := cfg.StatusDetector
+	if statusDetector == nil {
+		statusDetector = status.NewDetector()
+	}
+	lookback := cfg.ActivityLookback
+	if lookback <= 0 {
+		lookback = DefaultActivityLookback
+	}
+
 	return &ConflictDetector{
 		repoPath:        repoPath,
 		activityWindows: make(map[string][]ActivityWindow),
+		toolEdits:       make(map[string]map[string]bool),
 		amClient:        cfg.AMClient,
 		projectKey:      cfg.ProjectKey,
+		exclude:         cfg.Exclude,
+		includeIgnored:  cfg.IncludeIgnored,
+		extraRepos:      cfg.Repos,
+		skipSubmodules:  cfg.SkipSubmodules,
+		scoring:         cfg.Scoring.withDefaults(),
+		suppress:        cfg.Suppress,
+
+		session:          cfg.Session,
+		statusDetector:   statusDetector,
+		activityLookback: lookback,
+		lastObserved:     make(map[string]time.Time),
+		openWindows:      make(map[string]bool),
+	}
+}
+
+// Scoring returns the scoring profile the detector uses.
+func (cd *ConflictDetector) Scoring() ConflictScoring {
+	return cd.scoring.clone()
+}
+
+// Repos returns the repositories of the detector's workspace, the primary
+// one first.
+func (cd *ConflictDetector) Repos() []WorkspaceRepo {
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+	return append([]WorkspaceRepo(nil), cd.workspaceLocked()...)
+}
+
+// workspaceLocked returns the workspace repositories, discovering them on
+// first use. Must be called with mu held.
+func (cd *ConflictDetector) workspaceLocked() []WorkspaceRepo {
+	if cd.repos == nil {
+		cd.repos = discoverWorkspace(cd.repoPath, cd.extraRepos, cd.skipSubmodules)
 	}
+	return cd.repos
 }
 
-// RecordActivity records an activity window for a pane.
+// repoNamedLocked returns the workspace repository called name. Must be
+// called with mu held.
+func (cd *ConflictDetector) repoNamedLocked(name string) (WorkspaceRepo, bool) {
+	for _, r := range cd.repos {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return WorkspaceRepo{}, false
+}
+
+// workspacePathLocked maps a namespaced path to its path relative to the
+// primary repository, the form reservations use. Paths of repositories
+// outside the primary one stay namespaced. Must be called with mu held.
+func (cd *ConflictDetector) workspacePathLocked(p string) string {
+	name, rel := splitRepoPath(p)
+	if name == "" {
+		return p
+	}
+	if r, ok := cd.repoNamedLocked(name); ok && r.Prefix != "" {
+		return r.Prefix + "/" + rel
+	}
+	return p
+}
+
+// AbsPath returns the absolute path of a file named by a conflict.
+func (cd *ConflictDetector) AbsPath(p string) string {
+	cd.mu.RLock()
+	defer cd.mu.RUnlock()
+	return cd.absPathLocked(p)
+}
+
+// absPathLocked is AbsPath with mu held.
+func (cd *ConflictDetector) absPathLocked(p string) string {
+	name, rel := splitRepoPath(p)
+	if r, ok := cd.repoNamedLocked(name); ok {
+		return filepath.Join(r.Path, filepath.FromSlash(rel))
+	}
+	return filepath.Join(cd.repoPath, filepath.FromSlash(p))
+}
+
+// RecordActivity records an activity window for a pane. Detectors with a
+// session record windows from status detection (see ObserveStatus).
 func (cd *ConflictDetector) RecordActivity(paneID, agentType string, start, end time.Time, hasOutput bool) {
 	cd.mu.Lock()
 	defer cd.mu.Unlock()
@@ -173,6 +330,45 @@ func (cd *ConflictDetector) RecordActivity(paneID, agentType string, start, end
 	cd.pruneWindowsLocked(cutoff)
 }
 
+// RecordToolCalls records the tool calls parsed from a pane's output. Files
+// they changed are attributed to the pane directly, and a pane whose calls
+// are visible is no longer suspected of changing files it did not name.
+// Calls accumulate across captures, so edits that scrolled away still count.
+func (cd *ConflictDetector) RecordToolCalls(paneID string, calls []agent.ToolCall) {
+	if len(calls) == 0 {
+		return
+	}
+	cd.mu.Lock()
+	defer cd.mu.Unlock()
+
+	edits := cd.toolEdits[paneID]
+	if edits == nil {
+		edits = make(map[string]bool)
+		cd.toolEdits[paneID] = edits
+	}
+	for _, f := range agent.ModifiedFiles(calls) {
+		if rel := cd.repoRelative(f); rel != "" {
+			edits[rel] = true
+		}
+	}
+}
+
+// repoRelative converts a path named by a tool call to the form git status
+// uses: relative to its repository and namespaced for repositories other
+// than the primary one. Relative paths are taken from the primary
+// repository; paths outside the workspace yield "". Must be called with mu
+// held.
+func (cd *ConflictDetector) repoRelative(p string) string {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(cd.repoPath, p)
+	}
+	repo, rel, ok := repoFor(cd.workspaceLocked(), p)
+	if !ok {
+		return ""
+	}
+	return repo.qualify(rel)
+}
+
 // pruneWindowsLocked removes activity windows older than cutoff.
 // Must be called with mu held.
 func (cd *ConflictDetector) pruneWindowsLocked(cutoff time.Time) {
@@ -191,18 +387,159 @@ func (cd *ConflictDetector) pruneWindowsLocked(cutoff time.Time) {
 	}
 }
 
-// GetGitStatus returns the current git status of modified files.
-func (cd *ConflictDetector) GetGitStatus() ([]GitFileStatus, error) {
-	cmd := exec.Command("git", "-C", cd.repoPath, "status", "--porcelain")
+// GetGitStatus returns the current git status of modified files across the
+// workspace. Files in untracked directories are listed one by one; files
+// matching ignore rules or the detector's exclude patterns are left out.
+// Each repository is scanned on its own; files of repositories other than
+// the primary one are namespaced as "repo:path", and a submodule that is
+// scanned is not also reported as a changed entry of its parent. Each git
+// command stops when ctx is done or the git deadline runs out.
+func (cd *ConflictDetector) GetGitStatus(ctx context.Context) ([]GitFileStatus, error) {
+	repos := discoverWorkspace(cd.repoPath, cd.extraRepos, cd.skipSubmodules)
+	cd.mu.Lock()
+	cd.repos = repos
+	cd.mu.Unlock()
+
+	var results []GitFileStatus
+	for _, repo := range repos {
+		files, err := cd.repoStatus(ctx, repo.Path)
+		if err != nil {
+			if repo.Name == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("repo %s: %w", repo.Name, err)
+		}
+		scanned := make(map[string]bool)
+		for _, r := range repos {
+			if r.Submodule {
+				if rel, err := filepath.Rel(repo.Path, r.Path); err == nil {
+					scanned[filepath.ToSlash(rel)] = true
+				}
+			}
+		}
+		for _, f := range files {
+			if scanned[strings.TrimSuffix(f.Path, "/")] {
+				continue
+			}
+			f.Path = repo.qualify(f.Path)
+			f.Repo = repo.Name
+			results = append(results, f)
+		}
+	}
+
+	cd.mu.RLock()
+	defer cd.mu.RUnlock()
+	return cd.dropExcluded(results), nil
+}
+
+// repoStatus returns the changed files of the repository at dir, relative
+// to it.
+func (cd *ConflictDetector) repoStatus(ctx context.Context, dir string) ([]GitFileStatus, error) {
+	ctx, cancel := deadline.With(ctx, deadline.Git)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "status", "--porcelain", "--untracked-files=all", "--ignore-submodules=none")
+	// Don't let status refresh the index: that write would retrigger
+	// watchers of the git directory (ntm robot conflicts --watch).
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
-	return parseGitStatusPorcelain(string(output), cd.repoPath)
+	files, err := parseGitStatusPorcelain(string(output), dir)
+	if err != nil {
+		return nil, err
+	}
+	if !cd.includeIgnored {
+		files = dropIgnored(ctx, dir, files)
+	}
+	return files, nil
+}
+
+// dropIgnored removes files that match the repository's ignore rules. git
+// status already omits untracked ones; this catches tracked files under
+// ignored paths and files expanded from untracked directories. If git
+// cannot check, files are returned unfiltered.
+func dropIgnored(ctx context.Context, dir string, files []GitFileStatus) []GitFileStatus {
+	if len(files) == 0 {
+		return files
+	}
+	var input strings.Builder
+	for _, f := range files {
+		input.WriteString(f.Path)
+		input.WriteByte(0)
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "check-ignore", "--no-index", "-z", "--stdin")
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit status 1 means no path is ignored.
+		return files
+	}
+	ignored := make(map[string]bool)
+	for _, p := range strings.Split(string(output), "\x00") {
+		if p != "" {
+			ignored[p] = true
+		}
+	}
+	kept := files[:0]
+	for _, f := range files {
+		if !ignored[f.Path] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// dropExcluded removes files matching the detector's exclude patterns.
+// Must be called with mu held.
+func (cd *ConflictDetector) dropExcluded(files []GitFileStatus) []GitFileStatus {
+	if len(cd.exclude) == 0 {
+		return files
+	}
+	kept := files[:0]
+	for _, f := range files {
+		if !cd.excluded(f.Path) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// suppressedLocked returns the first unexpired suppression rule matching
+// path, namespaced or as a path in the primary repository. Must be called
+// with mu held.
+func (cd *ConflictDetector) suppressedLocked(path string, now time.Time) (ConflictSuppression, bool) {
+	workspacePath := cd.workspacePathLocked(path)
+	for _, rule := range cd.suppress {
+		if rule.Expired(now) {
+			continue
+		}
+		if matchesPattern(path, rule.Path) || matchesPattern(workspacePath, rule.Path) {
+			return rule, true
+		}
+	}
+	return ConflictSuppression{}, false
+}
+
+// excluded reports whether path matches one of the exclude patterns,
+// namespaced or as a path in the primary repository. Must be called with
+// mu held.
+func (cd *ConflictDetector) excluded(path string) bool {
+	workspacePath := cd.workspacePathLocked(path)
+	for _, pattern := range cd.exclude {
+		if matchesPattern(path, pattern) || matchesPattern(workspacePath, pattern) {
+			return true
+		}
+	}
+	return false
 }
 
-// parseGitStatusPorcelain parses `git status --porcelain` output.
+// parseGitStatusPorcelain parses `git status --porcelain` output. An
+// untracked directory ("?? dir/", printed without --untracked-files=all)
+// is expanded into the files under it; nested repositories stay a single
+// entry.
 func parseGitStatusPorcelain(output, repoPath string) ([]GitFileStatus, error) {
 	var results []GitFileStatus
 
@@ -211,7 +548,7 @@ func parseGitStatusPorcelain(output, repoPath string) ([]GitFileStatus, error) {
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
 		line = strings.TrimRight(line, "\r") // Handle CRLF
-		if len(line) < 3 {
+		if len(line) < 4 || line[2] != ' ' {
 			continue
 		}
 
@@ -224,6 +561,17 @@ func parseGitStatusPorcelain(output, repoPath string) ([]GitFileStatus, error) {
 		if idx := strings.Index(path, " -> "); idx >= 0 {
 			path = path[idx+4:]
 		}
+		path = unquoteGitPath(path)
+		if path == "" {
+			continue
+		}
+
+		if xy == "??" && strings.HasSuffix(path, "/") {
+			if files := untrackedDirFiles(repoPath, path); len(files) > 0 {
+				results = append(results, files...)
+				continue
+			}
+		}
 
 		status := GitFileStatus{
 			Path:   path,
@@ -243,10 +591,69 @@ func parseGitStatusPorcelain(output, repoPath string) ([]GitFileStatus, error) {
 	return results, nil
 }
 
+// untrackedDirFiles lists the files under the untracked directory dir of
+// the repository, skipping nested repositories. It returns nil if dir is a
+// repository itself or cannot be read.
+func untrackedDirFiles(repoPath, dir string) []GitFileStatus {
+	root := filepath.Join(repoPath, filepath.FromSlash(dir))
+	if _, err := os.Stat(filepath.Join(root, ".git")); err == nil {
+		return nil
+	}
+	var files []GitFileStatus
+	err := filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root {
+				if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(repoPath, p)
+		if err != nil {
+			return err
+		}
+		file := GitFileStatus{Path: filepath.ToSlash(rel), Status: "??"}
+		if info, err := d.Info(); err == nil {
+			file.ModifiedAt = info.ModTime()
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return files
+}
+
+// unquoteGitPath decodes a path git quoted because it holds special
+// characters ("a\tb.go", "caf\303\251.go"). Other paths are returned as is.
+func unquoteGitPath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
 // DetectConflicts analyzes git status and activity windows to detect conflicts.
+// If Agent Mail reservations cannot be listed, the conflicts found from git
+// and activity data are still returned together with the error. The scan
+// stops when ctx is done or the conflict scan deadline runs out.
 func (cd *ConflictDetector) DetectConflicts(ctx context.Context) ([]DetectedConflict, error) {
+	ctx, cancel := deadline.With(ctx, deadline.ConflictScan)
+	defer cancel()
+	if err := cd.ObserveSession(ctx); err != nil {
+		slog.Debug("conflict activity: observe session", "session", cd.session, "error", err)
+	}
+
 	// Get current git status
-	gitStatus, err := cd.GetGitStatus()
+	gitStatus, err := cd.GetGitStatus(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -256,10 +663,17 @@ func (cd *ConflictDetector) DetectConflicts(ctx context.Context) ([]DetectedConf
 	}
 
 	// Get file reservations from Agent Mail if available
+	// A reservation lookup failure still lets git/activity analysis run, but is
+	// reported so callers know reservation conflicts may be missing.
 	var reservations []agentmail.FileReservation
+	var reservationErr error
 	if cd.amClient != nil && cd.projectKey != "" {
 		// List all reservations (not filtered by agent)
-		reservations, _ = cd.amClient.ListReservations(ctx, cd.projectKey, "", true)
+		var err error
+		reservations, err = cd.amClient.ListReservations(ctx, cd.projectKey, "", true)
+		if err != nil {
+			reservationErr = fmt.Errorf("list reservations: %w", err)
+		}
 	}
 
 	cd.mu.RLock()
@@ -269,18 +683,20 @@ func (cd *ConflictDetector) DetectConflicts(ctx context.Context) ([]DetectedConf
 
 	for _, file := range gitStatus {
 		conflict := cd.analyzeFileConflict(file, reservations)
-		if conflict != nil && conflict.Confidence >= 0.5 {
+		if conflict != nil && conflict.Confidence >= cd.scoring.MinConfidence {
 			conflicts = append(conflicts, *conflict)
 		}
 	}
 
-	return conflicts, nil
+	return conflicts, reservationErr
 }
 
 // analyzeFileConflict analyzes a single file for conflicts.
 func (cd *ConflictDetector) analyzeFileConflict(file GitFileStatus, reservations []agentmail.FileReservation) *DetectedConflict {
 	conflict := &DetectedConflict{
+		ID:         tracker.ConflictID(cd.absPathLocked(file.Path)),
 		Path:       file.Path,
+		Repo:       file.Repo,
 		GitStatus:  file.Status,
 		ModifiedAt: file.ModifiedAt,
 		Confidence: 0.0,
@@ -297,13 +713,20 @@ func (cd *ConflictDetector) analyzeFileConflict(file GitFileStatus, reservations
 	// Score the conflict
 	cd.scoreConflict(conflict, len(modifiers), len(holders))
 
+	if rule, ok := cd.suppressedLocked(file.Path, time.Now()); ok {
+		conflict.Suppressed = rule.Reason
+	}
+
 	return conflict
 }
 
-// findReservationHolders returns agents with reservations matching the file path.
+// findReservationHolders returns agents with reservations matching the file
+// path. Patterns match the namespaced path ("repo:path") or, for
+// repositories inside the primary one, the path relative to it.
 func (cd *ConflictDetector) findReservationHolders(filePath string, reservations []agentmail.FileReservation) []string {
 	var holders []string
 	seen := make(map[string]bool)
+	workspacePath := cd.workspacePathLocked(filePath)
 
 	for _, r := range reservations {
 		// Skip released reservations
@@ -315,7 +738,8 @@ func (cd *ConflictDetector) findReservationHolders(filePath string, reservations
 			continue
 		}
 
-		if matchesPattern(filePath, r.PathPattern) && !seen[r.AgentName] {
+		matched := matchesPattern(filePath, r.PathPattern) || matchesPattern(workspacePath, r.PathPattern)
+		if matched && !seen[r.AgentName] {
 			holders = append(holders, r.AgentName)
 			seen[r.AgentName] = true
 		}
@@ -402,22 +826,34 @@ func matchesPattern(filePath, pattern string) bool {
 	return strings.HasPrefix(filePath, pattern+"/")
 }
 
-// findLikelyModifiers returns pane IDs with activity around the file modification time.
+// findLikelyModifiers returns the pane IDs that may have modified the file.
+// Panes whose tool calls changed it are certain; panes without visible tool
+// calls are suspected when they were active around the modification time.
 func (cd *ConflictDetector) findLikelyModifiers(file GitFileStatus) []string {
-	if file.ModifiedAt.IsZero() {
-		return nil
-	}
-
 	var modifiers []string
 	seen := make(map[string]bool)
 
-	// Look for activity windows that contain the file modification time
-	// Use a tolerance window of 60 seconds before and after
-	tolerance := 60 * time.Second
+	for paneID, edits := range cd.toolEdits {
+		if edits[file.Path] {
+			modifiers = append(modifiers, paneID)
+			seen[paneID] = true
+		}
+	}
+
+	if file.ModifiedAt.IsZero() {
+		return modifiers
+	}
+
+	// Look for activity windows that contain the file modification time,
+	// within the scoring profile's tolerance before and after
+	tolerance := cd.scoring.tolerance()
 	checkStart := file.ModifiedAt.Add(-tolerance)
 	checkEnd := file.ModifiedAt.Add(tolerance)
 
 	for paneID, windows := range cd.activityWindows {
+		if _, visible := cd.toolEdits[paneID]; visible {
+			continue
+		}
 		for _, w := range windows {
 			// Check if window overlaps with modification time window
 			if w.Start.Before(checkEnd) && w.End.After(checkStart) {
@@ -433,13 +869,14 @@ func (cd *ConflictDetector) findLikelyModifiers(file GitFileStatus) []string {
 	return modifiers
 }
 
-// scoreConflict calculates the conflict confidence score.
+// scoreConflict calculates the conflict confidence score from the weight
+// the scoring profile gives the situation.
 func (cd *ConflictDetector) scoreConflict(conflict *DetectedConflict, modifierCount, holderCount int) {
-	// Base confidence based on situation
+	var situation string
 	switch {
 	case modifierCount > 1:
 		// Multiple modifiers - high confidence of conflict
-		conflict.Confidence = 0.9
+		situation = ScoreConcurrentActivity
 		conflict.Reason = ReasonConcurrentActivity
 		conflict.Details = "Multiple agents had activity when this file was modified"
 
@@ -447,38 +884,40 @@ func (cd *ConflictDetector) scoreConflict(conflict *DetectedConflict, modifierCo
 		// Single modifier with reservation holders
 		if !containsAny(conflict.LikelyModifiers, conflict.ReservationHolders) {
 			// Modifier doesn't hold the reservation
-			conflict.Confidence = 0.85
+			situation = ScoreReservationViolation
 			conflict.Reason = ReasonReservationViolation
 			conflict.Details = "File modified by agent without active reservation"
 		} else {
 			// Modifier holds reservation - likely OK
-			conflict.Confidence = 0.3
+			situation = ScoreHolderModification
 			conflict.Reason = ReasonConcurrentActivity
 			conflict.Details = "File modified by reservation holder"
 		}
 
 	case modifierCount == 0 && holderCount > 1:
 		// No detected modifier but multiple reservation holders
-		conflict.Confidence = 0.75
+		situation = ScoreOverlappingReservations
 		conflict.Reason = ReasonOverlappingReservations
 		conflict.Details = "Multiple agents have reservations for this file"
 
 	case modifierCount == 0 && holderCount == 0:
 		// Unknown modifier, no reservations
-		conflict.Confidence = 0.6
+		situation = ScoreUnclaimedModification
 		conflict.Reason = ReasonUnclaimedModification
 		conflict.Details = "File modified with no tracked activity or reservations"
 
 	case modifierCount == 1 && holderCount == 0:
 		// Single modifier, no reservations (normal case)
-		conflict.Confidence = 0.4
+		situation = ScoreSingleModifier
 		conflict.Reason = ReasonConcurrentActivity
 		conflict.Details = "File modified by single agent without reservation"
 
 	default:
-		conflict.Confidence = 0.5
+		// Unknown modifier, one reservation holder
+		situation = ScoreReservedUnattributed
 		conflict.Reason = ReasonUnclaimedModification
 	}
+	conflict.Confidence = cd.scoring.Weights[situation]
 }
 
 // containsAny returns true if any element of a is in b.
@@ -525,15 +964,18 @@ type ConflictSummary struct {
 	LowConfidence  int                `json:"low_confidence"`  // 0.5-0.7
 	ByReason       map[string]int     `json:"by_reason"`
 	Conflicts      []DetectedConflict `json:"conflicts"`
+	Scoring        ConflictScoring    `json:"scoring"` // Profile the conflicts were scored with
 	Timestamp      string             `json:"timestamp"`
 }
 
-// SummarizeConflicts generates a summary from a list of conflicts.
-func SummarizeConflicts(conflicts []DetectedConflict) *ConflictSummary {
+// SummarizeConflicts generates a summary from a list of conflicts scored
+// with the given profile.
+func SummarizeConflicts(conflicts []DetectedConflict, scoring ConflictScoring) *ConflictSummary {
 	summary := &ConflictSummary{
 		TotalConflicts: len(conflicts),
 		ByReason:       make(map[string]int),
 		Conflicts:      conflicts,
+		Scoring:        scoring,
 		Timestamp:      FormatTimestamp(time.Now()),
 	}
 
@@ -559,12 +1001,12 @@ type ConflictDetectionResponse struct {
 }
 
 // NewConflictDetectionResponse creates a new conflict detection response.
-func NewConflictDetectionResponse(conflicts []DetectedConflict) *ConflictDetectionResponse {
+func NewConflictDetectionResponse(conflicts []DetectedConflict, scoring ConflictScoring) *ConflictDetectionResponse {
 	resp := &ConflictDetectionResponse{
 		RobotResponse: NewRobotResponse(true),
 	}
 	if len(conflicts) > 0 {
-		resp.Summary = SummarizeConflicts(conflicts)
+		resp.Summary = SummarizeConflicts(conflicts, scoring)
 	}
 	return resp
 }
@@ -579,13 +1021,19 @@ type CapturedOutput struct {
 	AgentType string    `json:"agent_type,omitempty"`
 	Timestamp time.Time `json:"timestamp"`
 	RawLength int       `json:"raw_length"` // Length of raw content (for metrics)
-	Prompt    string    `json:"prompt,omitempty"`
+	// DroppedBytes is how much of the raw content was truncated away
+	// before extraction.
+	DroppedBytes int    `json:"dropped_bytes,omitempty"`
+	Prompt       string `json:"prompt,omitempty"`
 
 	// Extracted structures
 	CodeBlocks  []CodeBlock      `json:"code_blocks,omitempty"`
 	JSONOutputs []JSONOutput     `json:"json_outputs,omitempty"`
 	FilePaths   []FileMention    `json:"file_paths,omitempty"`
 	Commands    []CommandMention `json:"commands,omitempty"`
+
+	// TokenUsage is the provider-reported usage parsed from CLI status lines, if any.
+	TokenUsage *tokens.TokenUsage `json:"token_usage,omitempty"`
 }
 
 // CodeBlock represents an extracted code block from agent output.
@@ -672,23 +1120,26 @@ func ExtractCodeBlocks(content string) []CodeBlock {
 }
 
 // ExtractJSONOutputs detects JSON objects and arrays in output.
-// Only extracts complete, valid JSON.
+// Only extracts complete, valid JSON; values nested in an extracted block
+// are not reported again.
 func ExtractJSONOutputs(content string) []JSONOutput {
 	var outputs []JSONOutput
 	lines := strings.Split(content, "\n")
 
 	// Track potential JSON start positions
-	for i, line := range lines {
+	candidates := 0
+	for i := 0; i < len(lines) && candidates < summary.JSONMaxCandidates; i++ {
 		lineNum := i + 1
-		trimmed := strings.TrimSpace(line)
+		trimmed := strings.TrimSpace(lines[i])
 
 		// Look for lines starting with { or [
-		if len(trimmed) == 0 {
+		if len(trimmed) == 0 || len(trimmed) > summary.JSONMaxBlockBytes {
 			continue
 		}
 
 		if trimmed[0] == '{' || trimmed[0] == '[' {
 			// Try to find a complete JSON block starting here
+			candidates++
 			jsonStr, endLine := extractCompleteJSON(lines, i)
 			if jsonStr != "" {
 				outputs = append(outputs, JSONOutput{
@@ -697,6 +1148,7 @@ func ExtractJSONOutputs(content string) []JSONOutput {
 					LineStart: lineNum,
 					LineEnd:   endLine,
 				})
+				i = endLine - 1
 			}
 		}
 	}
@@ -705,7 +1157,8 @@ func ExtractJSONOutputs(content string) []JSONOutput {
 }
 
 // extractCompleteJSON tries to extract a complete JSON object/array starting at line index.
-// Returns the JSON string and end line number (1-indexed), or empty string if invalid.
+// Returns the JSON string and end line number (1-indexed), or empty string if invalid
+// or over the extraction budgets.
 func extractCompleteJSON(lines []string, startIdx int) (string, int) {
 	// Build potential JSON string line by line until we get valid JSON
 	var builder strings.Builder
@@ -713,10 +1166,13 @@ func extractCompleteJSON(lines []string, startIdx int) (string, int) {
 	inString := false
 	escaped := false
 
-	for i := startIdx; i < len(lines) && i < startIdx+100; i++ { // Limit to 100 lines
+	for i := startIdx; i < len(lines) && i < startIdx+summary.JSONMaxBlockLines; i++ {
 		if builder.Len() > 0 {
 			builder.WriteByte('\n')
 		}
+		if builder.Len()+len(lines[i]) > summary.JSONMaxBlockBytes {
+			return "", 0
+		}
 		builder.WriteString(lines[i])
 
 		// Track bracket depth to know when JSON is complete
@@ -739,6 +1195,9 @@ func extractCompleteJSON(lines []string, startIdx int) (string, int) {
 			switch ch {
 			case '{', '[':
 				depth++
+				if depth > summary.JSONMaxDepth {
+					return "", 0
+				}
 			case '}', ']':
 				depth--
 			}
@@ -769,11 +1228,8 @@ func isValidJSON(s string) bool {
 	if len(s) == 0 {
 		return false
 	}
-	// Quick validation: try to decode
-	var js interface{}
-	decoder := json.NewDecoder(strings.NewReader(s))
-	decoder.UseNumber()
-	return decoder.Decode(&js) == nil
+	// Reject trailing data after the value, not just malformed prefixes
+	return json.Valid([]byte(s))
 }
 
 // ExtractFileMentions extracts file path mentions from output with action context.
@@ -986,13 +1442,19 @@ func parseExitCode(line string) *int {
 type OutputCaptureConfig struct {
 	MaxCapturesPerPane int           // Maximum captures per pane (ring buffer size)
 	MaxRetention       time.Duration // Maximum age of captures to keep
+	MaxCaptureBytes    int           // Raw content beyond this is truncated before extraction (0 = unlimited)
+	MaxPaneBytes       int           // Oldest captures are dropped once a pane's raw bytes exceed this (0 = unlimited)
 }
 
-// DefaultOutputCaptureConfig returns default configuration.
+// DefaultOutputCaptureConfig returns default configuration, with byte limits
+// taken from the tmux capture limits.
 func DefaultOutputCaptureConfig() *OutputCaptureConfig {
+	limits := tmux.GetCaptureLimits()
 	return &OutputCaptureConfig{
 		MaxCapturesPerPane: 100,
 		MaxRetention:       1 * time.Hour,
+		MaxCaptureBytes:    limits.MaxCaptureBytes,
+		MaxPaneBytes:       limits.MaxPaneBytes,
 	}
 }
 
@@ -1001,6 +1463,9 @@ type OutputCapture struct {
 	config   *OutputCaptureConfig
 	captures map[string][]CapturedOutput // paneID -> ring buffer of captures
 	mu       sync.RWMutex
+
+	truncated    int   // captures truncated before extraction
+	droppedBytes int64 // raw bytes truncated away
 }
 
 // NewOutputCapture creates a new output capture store.
@@ -1024,11 +1489,15 @@ func (oc *OutputCapture) CaptureAndExtract(paneID, agentType, rawContent, prompt
 		Prompt:    prompt,
 	}
 
+	// Bound the content before any extractor sees it
+	rawContent, capture.DroppedBytes = tmux.TruncateCapture(rawContent, oc.config.MaxCaptureBytes)
+
 	// Extract all structures
 	capture.CodeBlocks = ExtractCodeBlocks(rawContent)
 	capture.JSONOutputs = ExtractJSONOutputs(rawContent)
 	capture.FilePaths = ExtractFileMentions(rawContent)
 	capture.Commands = ExtractCommands(rawContent)
+	capture.TokenUsage = tokens.ParseUsage(agentType, rawContent)
 
 	// Store in ring buffer
 	oc.store(paneID, *capture)
@@ -1053,6 +1522,22 @@ func (oc *OutputCapture) store(paneID string, capture CapturedOutput) {
 		captures = captures[len(captures)-oc.config.MaxCapturesPerPane:]
 	}
 
+	// Enforce the per-pane byte budget, always keeping the newest capture
+	if oc.config.MaxPaneBytes > 0 {
+		total := 0
+		for _, c := range captures {
+			total += c.RawLength - c.DroppedBytes
+		}
+		for len(captures) > 1 && total > oc.config.MaxPaneBytes {
+			total -= captures[0].RawLength - captures[0].DroppedBytes
+			captures = captures[1:]
+		}
+	}
+
+	if capture.DroppedBytes > 0 {
+		oc.truncated++
+		oc.droppedBytes += int64(capture.DroppedBytes)
+	}
 	oc.captures[paneID] = captures
 }
 
@@ -1151,12 +1636,18 @@ func (oc *OutputCapture) Stats() OutputCaptureStats {
 	defer oc.mu.RUnlock()
 
 	stats := OutputCaptureStats{
-		PaneCount: len(oc.captures),
-		Timestamp: time.Now(),
+		PaneCount:         len(oc.captures),
+		TruncatedCaptures: oc.truncated,
+		DroppedBytes:      oc.droppedBytes,
+		Timestamp:         time.Now(),
 	}
 
 	for paneID, captures := range oc.captures {
 		stats.TotalCaptures += len(captures)
+		var paneBytes int
+		for _, c := range captures {
+			paneBytes += c.RawLength - c.DroppedBytes
+		}
 		if len(captures) > 0 {
 			stats.OldestCapture = captures[0].Timestamp
 			if stats.NewestCapture.IsZero() || captures[len(captures)-1].Timestamp.After(stats.NewestCapture) {
@@ -1166,6 +1657,7 @@ func (oc *OutputCapture) Stats() OutputCaptureStats {
 		stats.CaptureCounts = append(stats.CaptureCounts, PaneCaptureCount{
 			PaneID: paneID,
 			Count:  len(captures),
+			Bytes:  paneBytes,
 		})
 	}
 
@@ -1174,18 +1666,22 @@ func (oc *OutputCapture) Stats() OutputCaptureStats {
 
 // OutputCaptureStats provides statistics about the capture store.
 type OutputCaptureStats struct {
-	PaneCount     int                `json:"pane_count"`
-	TotalCaptures int                `json:"total_captures"`
-	OldestCapture time.Time          `json:"oldest_capture,omitempty"`
-	NewestCapture time.Time          `json:"newest_capture,omitempty"`
-	CaptureCounts []PaneCaptureCount `json:"capture_counts,omitempty"`
-	Timestamp     time.Time          `json:"timestamp"`
+	PaneCount     int `json:"pane_count"`
+	TotalCaptures int `json:"total_captures"`
+	// TruncatedCaptures and DroppedBytes count content cut by MaxCaptureBytes.
+	TruncatedCaptures int                `json:"truncated_captures,omitempty"`
+	DroppedBytes      int64              `json:"dropped_bytes,omitempty"`
+	OldestCapture     time.Time          `json:"oldest_capture,omitempty"`
+	NewestCapture     time.Time          `json:"newest_capture,omitempty"`
+	CaptureCounts     []PaneCaptureCount `json:"capture_counts,omitempty"`
+	Timestamp         time.Time          `json:"timestamp"`
 }
 
 // PaneCaptureCount shows capture count for a single pane.
 type PaneCaptureCount struct {
 	PaneID string `json:"pane_id"`
 	Count  int    `json:"count"`
+	Bytes  int    `json:"bytes"` // Raw bytes retained after truncation
 }
 
 // OutputCaptureResponse is the robot command response for output capture info.
@@ -1735,12 +2231,8 @@ func getPanesForSession(session string) ([]paneInfo, error) {
 	return panes, nil
 }
 
-// capturePaneOutput captures output from a tmux pane.
+// capturePaneOutput captures output from a tmux pane, within the capture
+// size limits.
 func capturePaneOutput(paneID string, lines int) (string, error) {
-	cmd := exec.Command(tmux.BinaryPath(), "capture-pane", "-t", paneID, "-p", "-S", fmt.Sprintf("-%d", lines))
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return string(output), nil
+	return tmux.CapturePaneOutput(paneID, lines)
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/agent"
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
 )
 
@@ -268,6 +270,38 @@ func TestParseGitStatusPorcelain(t *testing.T) {
 	}
 }
 
+func TestParseGitStatusPorcelain_UntrackedDir(t *testing.T) {
+	t.Parallel()
+
+	repo := t.TempDir()
+	for _, name := range []string{"new/a.go", "new/sub/b.go", "new/nested/.git/HEAD", "new/nested/c.go", "vendored/.git/HEAD"} {
+		p := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := parseGitStatusPorcelain("?? new/\n?? vendored/\n", repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range got {
+		if f.Status != "??" || f.Staged {
+			t.Errorf("%s: status %q, staged %v", f.Path, f.Status, f.Staged)
+		}
+		paths = append(paths, f.Path)
+	}
+	// Nested repositories are not walked into.
+	want := []string{"new/a.go", "new/sub/b.go", "vendored/"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+}
+
 func TestMatchesPattern(t *testing.T) {
 	t.Parallel()
 
@@ -454,6 +488,41 @@ func TestConflictDetector_FindLikelyModifiers(t *testing.T) {
 	}
 }
 
+func TestConflictDetector_FindLikelyModifiersFromToolCalls(t *testing.T) {
+	t.Parallel()
+
+	cd := NewConflictDetector(&ConflictDetectorConfig{RepoPath: "/repo"})
+	now := time.Now()
+
+	// All three panes were active; %1 and %2 show their tool calls.
+	for _, pane := range []string{"%1", "%2", "%3"} {
+		cd.RecordActivity(pane, "claude", now.Add(-time.Minute), now, true)
+	}
+	cd.RecordToolCalls("%1", agent.ParseToolCalls(agent.AgentTypeClaudeCode,
+		"⏺ Update(/repo/internal/a.go)\n⏺ Write(/elsewhere/b.go)"))
+	cd.RecordToolCalls("%2", agent.ParseToolCalls(agent.AgentTypeCodex,
+		"• Explored\n  └ Read internal/a.go\n• Deleted ./gone.go (+0 -3)"))
+
+	tests := []struct {
+		name string
+		file GitFileStatus
+		want []string
+	}{
+		{"edited by a visible pane", GitFileStatus{Path: "internal/a.go", ModifiedAt: now}, []string{"%1", "%3"}},
+		{"no visible editor", GitFileStatus{Path: "internal/c.go", ModifiedAt: now}, []string{"%3"}},
+		{"deleted file has no mtime", GitFileStatus{Path: "gone.go", Status: "D"}, []string{"%2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cd.findLikelyModifiers(tt.file)
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("modifiers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestConflictDetector_FindReservationHolders(t *testing.T) {
 	t.Parallel()
 
@@ -516,7 +585,7 @@ func TestSummarizeConflicts(t *testing.T) {
 		{Path: "file4.go", Confidence: 0.85, Reason: ReasonConcurrentActivity},
 	}
 
-	summary := SummarizeConflicts(conflicts)
+	summary := SummarizeConflicts(conflicts, DefaultConflictScoring())
 
 	if summary.TotalConflicts != 4 {
 		t.Errorf("TotalConflicts = %d, want 4", summary.TotalConflicts)
@@ -533,6 +602,9 @@ func TestSummarizeConflicts(t *testing.T) {
 	if summary.ByReason["concurrent_activity"] != 2 {
 		t.Errorf("ByReason[concurrent_activity] = %d, want 2", summary.ByReason["concurrent_activity"])
 	}
+	if summary.Scoring.Profile != DefaultConflictScoringProfile {
+		t.Errorf("Scoring.Profile = %q, want %q", summary.Scoring.Profile, DefaultConflictScoringProfile)
+	}
 }
 
 func TestNewConflictDetectionResponse(t *testing.T) {
@@ -540,7 +612,7 @@ func TestNewConflictDetectionResponse(t *testing.T) {
 
 	t.Run("no conflicts", func(t *testing.T) {
 		t.Parallel()
-		resp := NewConflictDetectionResponse(nil)
+		resp := NewConflictDetectionResponse(nil, DefaultConflictScoring())
 		if !resp.Success {
 			t.Error("Success should be true")
 		}
@@ -554,7 +626,7 @@ func TestNewConflictDetectionResponse(t *testing.T) {
 		conflicts := []DetectedConflict{
 			{Path: "file.go", Confidence: 0.9},
 		}
-		resp := NewConflictDetectionResponse(conflicts)
+		resp := NewConflictDetectionResponse(conflicts, DefaultConflictScoring())
 		if !resp.Success {
 			t.Error("Success should be true")
 		}
@@ -797,6 +869,8 @@ func TestIsValidJSON(t *testing.T) {
 		{`{invalid}`, false},
 		{`{"key": }`, false},
 		{`just text`, false},
+		{`{}0`, false},
+		{`{} trailing`, false},
 	}
 
 	for _, tt := range tests {
@@ -1152,6 +1226,43 @@ func TestOutputCapture_Stats(t *testing.T) {
 	}
 }
 
+func TestOutputCapture_ByteLimits(t *testing.T) {
+	t.Parallel()
+
+	oc := NewOutputCapture(&OutputCaptureConfig{
+		MaxCapturesPerPane: 100,
+		MaxRetention:       time.Hour,
+		MaxCaptureBytes:    4096,
+		MaxPaneBytes:       10000,
+	})
+
+	// The JSON at the end survives truncation of the middle
+	big := strings.Repeat("noise line\n", 10000) + `{"status": "done"}`
+	capture := oc.CaptureAndExtract("%1", "claude", big, "")
+	if capture.RawLength != len(big) || capture.DroppedBytes <= 0 {
+		t.Errorf("RawLength = %d, DroppedBytes = %d", capture.RawLength, capture.DroppedBytes)
+	}
+	if len(capture.JSONOutputs) != 1 {
+		t.Errorf("JSONOutputs = %+v, want the trailing object", capture.JSONOutputs)
+	}
+
+	// Each retained capture is at most 4096 bytes; the pane keeps 10000
+	for i := 0; i < 5; i++ {
+		oc.CaptureAndExtract("%1", "claude", big, "")
+	}
+	if n := len(oc.GetCaptures("%1", 0, nil)); n != 2 {
+		t.Errorf("retained %d captures, want 2 within the pane budget", n)
+	}
+
+	stats := oc.Stats()
+	if stats.TruncatedCaptures != 6 || stats.DroppedBytes != int64(6*capture.DroppedBytes) {
+		t.Errorf("TruncatedCaptures = %d, DroppedBytes = %d", stats.TruncatedCaptures, stats.DroppedBytes)
+	}
+	if len(stats.CaptureCounts) != 1 || stats.CaptureCounts[0].Bytes > 10000 {
+		t.Errorf("CaptureCounts = %+v", stats.CaptureCounts)
+	}
+}
+
 // =============================================================================
 // countErrors
 // =============================================================================
@@ -0,0 +1,510 @@
+// Package robot provides machine-readable output for AI agents.
+// task_graph.go implements `ntm robot plan`.
+package robot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+)
+
+// DefaultTaskGraphLimit is how many ready and blocked tasks are planned by default.
+const DefaultTaskGraphLimit = 50
+
+// planReservationTimeout bounds the Agent Mail reservation lookup.
+const planReservationTimeout = 5 * time.Second
+
+// Task states in a plan.
+const (
+	PlanTaskInProgress = "in_progress"
+	PlanTaskReady      = "ready"
+	PlanTaskBlocked    = "blocked"
+)
+
+// Plan edge kinds.
+const (
+	// PlanEdgeDependsOn means To cannot start before From is done.
+	PlanEdgeDependsOn = "depends_on"
+	// PlanEdgeFileOverlap means From and To touch the same files and must
+	// not run at the same time; From is scheduled first.
+	PlanEdgeFileOverlap = "file_overlap"
+)
+
+// TaskGraphOptions configures `ntm robot plan`.
+type TaskGraphOptions struct {
+	// ProjectKey is the Agent Mail project for reservations (default: cwd).
+	ProjectKey string
+	// Limit caps the ready and blocked tasks planned (0 = all).
+	Limit int
+	// DOT also renders the graph in Graphviz DOT format.
+	DOT  bool
+	Deps *TaskGraphDependencies
+}
+
+// TaskGraphDependencies allows tests to stub external interactions.
+type TaskGraphDependencies struct {
+	FetchTriage       func(dir string) (*bv.TriageResponse, error)
+	FetchInProgress   func(dir string, limit int) ([]bv.BeadInProgress, error)
+	FetchBeadFiles    func(dir, beadID string) ([]string, error)
+	FetchReservations func(ctx context.Context, projectKey string) ([]agentmail.FileReservation, error)
+	Cwd               func() (string, error)
+}
+
+// TaskGraphOutput is the response for `ntm robot plan`.
+type TaskGraphOutput struct {
+	RobotResponse
+	Source  string           `json:"source"`
+	Summary TaskGraphSummary `json:"summary"`
+	Tasks   []PlanTask       `json:"tasks"`
+	Edges   []PlanEdge       `json:"edges"`
+	// Waves groups tasks that can run in parallel; wave N can start once
+	// the tasks it depends on in earlier waves are done.
+	Waves []PlanWave `json:"waves"`
+	// StartNow lists ready tasks that can be assigned right away.
+	StartNow []string `json:"start_now"`
+	Warnings []string `json:"warnings,omitempty"`
+	DOT      string   `json:"dot,omitempty"`
+}
+
+// TaskGraphSummary counts the plan's tasks and edges.
+type TaskGraphSummary struct {
+	Tasks        int `json:"tasks"`
+	InProgress   int `json:"in_progress"`
+	Ready        int `json:"ready"`
+	Blocked      int `json:"blocked"`
+	Dependencies int `json:"dependencies"`
+	FileOverlaps int `json:"file_overlaps"`
+	Waves        int `json:"waves"`
+}
+
+// PlanTask is one node of the task graph.
+type PlanTask struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority int    `json:"priority"`
+	Assignee string `json:"assignee,omitempty"`
+	// Files are the paths and globs the task is expected to touch,
+	// extracted from its title and description.
+	Files []string `json:"files"`
+	// BlockedBy lists blockers that are not part of the plan.
+	BlockedBy []string `json:"blocked_by,omitempty"`
+	// ReservedBy lists active reservations by other agents on the task's files.
+	ReservedBy []PlanReservation `json:"reserved_by,omitempty"`
+	// Wave is the task's parallel wave, or -1 when it is part of a
+	// dependency cycle.
+	Wave int `json:"wave"`
+}
+
+// PlanReservation is a reservation that overlaps a task's files.
+type PlanReservation struct {
+	Agent   string `json:"agent"`
+	Pattern string `json:"pattern"`
+}
+
+// PlanEdge orders two tasks.
+type PlanEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  string   `json:"kind"`
+	Files []string `json:"files,omitempty"`
+}
+
+// PlanWave is a set of tasks that can run in parallel.
+type PlanWave struct {
+	Wave  int      `json:"wave"`
+	Tasks []string `json:"tasks"`
+}
+
+// GetTaskGraph builds the dependency-aware task graph.
+func GetTaskGraph(opts TaskGraphOptions) (*TaskGraphOutput, error) {
+	deps := taskGraphDeps(opts.Deps)
+	out := &TaskGraphOutput{
+		RobotResponse: NewRobotResponse(true),
+		Source:        "bv",
+		Tasks:         []PlanTask{},
+		Edges:         []PlanEdge{},
+		Waves:         []PlanWave{},
+		StartNow:      []string{},
+	}
+
+	wd, err := deps.Cwd()
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("failed to resolve working directory: %w", err), ErrCodeInternalError, "Run from a valid project directory")
+		return out, nil
+	}
+	triage, err := deps.FetchTriage(wd)
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("bv triage failed: %w", err), ErrCodeDependencyMissing, "Ensure bv is installed and .beads exists")
+		return out, nil
+	}
+	inProgress, err := deps.FetchInProgress(wd, 200)
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("in-progress tasks unavailable: %v", err))
+	}
+
+	tasks := collectPlanTasks(triage, inProgress, opts.Limit)
+	blockers := map[string][]string{}
+	for i := range tasks {
+		blockers[tasks[i].ID] = tasks[i].BlockedBy
+		tasks[i].BlockedBy = nil
+		files, err := deps.FetchBeadFiles(wd, tasks[i].ID)
+		if err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("%s: files unavailable: %v", tasks[i].ID, err))
+		}
+		tasks[i].Files = files
+		if tasks[i].Files == nil {
+			tasks[i].Files = []string{}
+		}
+	}
+
+	projectKey := opts.ProjectKey
+	if projectKey == "" {
+		projectKey = wd
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), planReservationTimeout)
+	reservations, err := deps.FetchReservations(ctx, projectKey)
+	cancel()
+	if err != nil {
+		out.Warnings = append(out.Warnings, fmt.Sprintf("reservations unavailable: %v", err))
+	}
+
+	plan := buildPlanGraph(tasks, blockers, reservations)
+	out.Tasks, out.Edges, out.Waves, out.StartNow = plan.tasks, plan.edges, plan.waves, plan.startNow
+	out.Warnings = append(out.Warnings, plan.warnings...)
+	out.Summary = summarizeTaskGraph(out)
+	if opts.DOT {
+		out.DOT = RenderPlanDOT(out.Tasks, out.Edges)
+	}
+	return out, nil
+}
+
+// PrintTaskGraph handles `ntm robot plan`.
+func PrintTaskGraph(opts TaskGraphOptions) error {
+	out, err := GetTaskGraph(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
+
+func taskGraphDeps(custom *TaskGraphDependencies) TaskGraphDependencies {
+	deps := TaskGraphDependencies{
+		FetchTriage:       bv.GetTriage,
+		FetchInProgress:   func(dir string, limit int) ([]bv.BeadInProgress, error) { return bv.GetInProgressList(dir, limit), nil },
+		FetchBeadFiles:    fetchBeadFiles,
+		FetchReservations: fetchPlanReservations,
+		Cwd:               os.Getwd,
+	}
+	if custom == nil {
+		return deps
+	}
+	if custom.FetchTriage != nil {
+		deps.FetchTriage = custom.FetchTriage
+	}
+	if custom.FetchInProgress != nil {
+		deps.FetchInProgress = custom.FetchInProgress
+	}
+	if custom.FetchBeadFiles != nil {
+		deps.FetchBeadFiles = custom.FetchBeadFiles
+	}
+	if custom.FetchReservations != nil {
+		deps.FetchReservations = custom.FetchReservations
+	}
+	if custom.Cwd != nil {
+		deps.Cwd = custom.Cwd
+	}
+	return deps
+}
+
+// fetchBeadFiles extracts the files a bead mentions in its title and
+// description.
+func fetchBeadFiles(dir, beadID string) ([]string, error) {
+	cmd := exec.Command("br", "show", beadID, "--json")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("br show %s failed: %w", beadID, err)
+	}
+	var issues []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(output, &issues); err != nil {
+		return nil, fmt.Errorf("parse br show output: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("bead %s not found", beadID)
+	}
+	return assign.ExtractFilePaths(issues[0].Title, issues[0].Description), nil
+}
+
+func fetchPlanReservations(ctx context.Context, projectKey string) ([]agentmail.FileReservation, error) {
+	client := agentmail.NewClient(agentmail.WithProjectKey(projectKey))
+	return client.ListReservations(ctx, projectKey, "", true)
+}
+
+// collectPlanTasks gathers in-progress, ready, and blocked tasks in
+// scheduling order: in-progress first, then bv's recommendation order.
+// BlockedBy holds every blocker ID at this point.
+func collectPlanTasks(triage *bv.TriageResponse, inProgress []bv.BeadInProgress, limit int) []PlanTask {
+	var tasks []PlanTask
+	seen := map[string]bool{}
+	for _, item := range inProgress {
+		if item.ID == "" || seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		tasks = append(tasks, PlanTask{ID: item.ID, Title: item.Title, Status: PlanTaskInProgress, Assignee: item.Assignee})
+	}
+	if triage == nil {
+		return tasks
+	}
+
+	planned := 0
+	add := func(t PlanTask) {
+		if t.ID == "" || seen[t.ID] || (limit > 0 && planned >= limit) {
+			return
+		}
+		seen[t.ID] = true
+		planned++
+		tasks = append(tasks, t)
+	}
+	for _, rec := range triage.Triage.Recommendations {
+		status := PlanTaskReady
+		if len(rec.BlockedBy) > 0 || strings.EqualFold(rec.Status, "blocked") {
+			status = PlanTaskBlocked
+		}
+		add(PlanTask{ID: rec.ID, Title: rec.Title, Status: status, Priority: rec.Priority, BlockedBy: rec.BlockedBy})
+	}
+	for _, b := range triage.Triage.BlockersToClear {
+		status := PlanTaskReady
+		if !b.Actionable || len(b.BlockedBy) > 0 {
+			status = PlanTaskBlocked
+		}
+		add(PlanTask{ID: b.ID, Title: b.Title, Status: status, BlockedBy: b.BlockedBy})
+	}
+	return tasks
+}
+
+type planGraph struct {
+	tasks    []PlanTask
+	edges    []PlanEdge
+	waves    []PlanWave
+	startNow []string
+	warnings []string
+}
+
+// buildPlanGraph links tasks by dependency and file overlap and layers them
+// into parallel waves. Tasks earlier in the list win file overlaps unless a
+// dependency already orders them the other way.
+func buildPlanGraph(tasks []PlanTask, blockers map[string][]string, reservations []agentmail.FileReservation) planGraph {
+	g := planGraph{tasks: tasks, edges: []PlanEdge{}, waves: []PlanWave{}, startNow: []string{}}
+	index := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+	succ := make([][]int, len(tasks))
+
+	for i, t := range tasks {
+		for _, b := range blockers[t.ID] {
+			j, ok := index[b]
+			if !ok {
+				g.tasks[i].BlockedBy = append(g.tasks[i].BlockedBy, b)
+				continue
+			}
+			succ[j] = append(succ[j], i)
+			g.edges = append(g.edges, PlanEdge{From: b, To: t.ID, Kind: PlanEdgeDependsOn})
+		}
+	}
+
+	for i := range tasks {
+		for j := i + 1; j < len(tasks); j++ {
+			shared := overlappingFiles(tasks[i].Files, tasks[j].Files)
+			if len(shared) == 0 || planReaches(succ, i, j) {
+				continue
+			}
+			from, to := i, j
+			if planReaches(succ, j, i) {
+				from, to = j, i
+			}
+			succ[from] = append(succ[from], to)
+			g.edges = append(g.edges, PlanEdge{From: tasks[from].ID, To: tasks[to].ID, Kind: PlanEdgeFileOverlap, Files: shared})
+		}
+	}
+
+	for i := range g.tasks {
+		t := &g.tasks[i]
+		for _, r := range reservations {
+			if r.ReleasedTS != nil || r.AgentName == "" || r.AgentName == t.Assignee {
+				continue
+			}
+			for _, f := range t.Files {
+				if agentmail.PatternsOverlap(f, r.PathPattern) {
+					t.ReservedBy = append(t.ReservedBy, PlanReservation{Agent: r.AgentName, Pattern: r.PathPattern})
+					break
+				}
+			}
+		}
+	}
+
+	g.layer(succ)
+	return g
+}
+
+// layer assigns waves with Kahn's algorithm. A task's wave is one more than
+// its latest predecessor; ready tasks waiting on outside blockers or other
+// agents' reservations cannot start before wave 1.
+func (g *planGraph) layer(succ [][]int) {
+	n := len(g.tasks)
+	indeg := make([]int, n)
+	for _, next := range succ {
+		for _, j := range next {
+			indeg[j]++
+		}
+	}
+	wave := make([]int, n)
+	for i, t := range g.tasks {
+		if t.Status != PlanTaskInProgress && (len(t.BlockedBy) > 0 || len(t.ReservedBy) > 0 || t.Status == PlanTaskBlocked) {
+			wave[i] = 1
+		}
+	}
+	var queue []int
+	for i := range g.tasks {
+		if indeg[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	done := make([]bool, n)
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		done[i] = true
+		for _, j := range succ[i] {
+			wave[j] = max(wave[j], wave[i]+1)
+			if indeg[j]--; indeg[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	byWave := map[int][]string{}
+	var cycle []string
+	for i := range g.tasks {
+		if !done[i] {
+			g.tasks[i].Wave = -1
+			cycle = append(cycle, g.tasks[i].ID)
+			continue
+		}
+		g.tasks[i].Wave = wave[i]
+		byWave[wave[i]] = append(byWave[wave[i]], g.tasks[i].ID)
+		if wave[i] == 0 && g.tasks[i].Status == PlanTaskReady {
+			g.startNow = append(g.startNow, g.tasks[i].ID)
+		}
+	}
+	if len(cycle) > 0 {
+		g.warnings = append(g.warnings, "dependency cycle among: "+strings.Join(cycle, ", "))
+	}
+	waves := make([]int, 0, len(byWave))
+	for w := range byWave {
+		waves = append(waves, w)
+	}
+	sort.Ints(waves)
+	for _, w := range waves {
+		g.waves = append(g.waves, PlanWave{Wave: w, Tasks: byWave[w]})
+	}
+}
+
+// planReaches reports whether to is reachable from from.
+func planReaches(succ [][]int, from, to int) bool {
+	seen := make([]bool, len(succ))
+	stack := []int{from}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if i == to {
+			return true
+		}
+		if seen[i] {
+			continue
+		}
+		seen[i] = true
+		stack = append(stack, succ[i]...)
+	}
+	return false
+}
+
+// overlappingFiles returns the patterns of a that overlap any pattern of b.
+func overlappingFiles(a, b []string) []string {
+	var shared []string
+	for _, fa := range a {
+		for _, fb := range b {
+			if agentmail.PatternsOverlap(fa, fb) {
+				shared = append(shared, fa)
+				break
+			}
+		}
+	}
+	return shared
+}
+
+func summarizeTaskGraph(out *TaskGraphOutput) TaskGraphSummary {
+	s := TaskGraphSummary{Tasks: len(out.Tasks), Waves: len(out.Waves)}
+	for _, t := range out.Tasks {
+		switch t.Status {
+		case PlanTaskInProgress:
+			s.InProgress++
+		case PlanTaskReady:
+			s.Ready++
+		case PlanTaskBlocked:
+			s.Blocked++
+		}
+	}
+	for _, e := range out.Edges {
+		switch e.Kind {
+		case PlanEdgeDependsOn:
+			s.Dependencies++
+		case PlanEdgeFileOverlap:
+			s.FileOverlaps++
+		}
+	}
+	return s
+}
+
+// RenderPlanDOT renders a task graph in Graphviz DOT format. Dependencies
+// are solid edges, file overlaps dashed; reserved tasks have a red border.
+func RenderPlanDOT(tasks []PlanTask, edges []PlanEdge) string {
+	var sb strings.Builder
+	sb.WriteString("digraph plan {\n  rankdir=LR;\n  node [shape=box, style=filled];\n")
+	fill := map[string]string{PlanTaskInProgress: "lightblue", PlanTaskReady: "palegreen", PlanTaskBlocked: "lightgray"}
+	for _, t := range tasks {
+		attrs := fmt.Sprintf("label=%s, fillcolor=%s", dotQuote(t.ID+"\n"+t.Title), fill[t.Status])
+		if len(t.ReservedBy) > 0 {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(&sb, "  %s [%s];\n", dotQuote(t.ID), attrs)
+	}
+	for _, e := range edges {
+		if e.Kind == PlanEdgeFileOverlap {
+			fmt.Fprintf(&sb, "  %s -> %s [style=dashed, label=%s];\n", dotQuote(e.From), dotQuote(e.To), dotQuote(strings.Join(e.Files, "\n")))
+			continue
+		}
+		fmt.Fprintf(&sb, "  %s -> %s;\n", dotQuote(e.From), dotQuote(e.To))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
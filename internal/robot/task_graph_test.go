@@ -0,0 +1,144 @@
+package robot
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+)
+
+func taskGraphTestDeps(files map[string][]string, reservations []agentmail.FileReservation) *TaskGraphDependencies {
+	triage := &bv.TriageResponse{Triage: bv.TriageData{
+		Recommendations: []bv.TriageRecommendation{
+			{ID: "bd-api", Title: "Add API handler", Priority: 1},
+			{ID: "bd-docs", Title: "Document API", Priority: 2, BlockedBy: []string{"bd-api"}},
+			{ID: "bd-cli", Title: "CLI flag", Priority: 1},
+			{ID: "bd-ui", Title: "Dashboard", Priority: 2},
+			{ID: "bd-ext", Title: "Waits on other repo", Priority: 3, BlockedBy: []string{"bd-elsewhere"}},
+		},
+	}}
+	return &TaskGraphDependencies{
+		FetchTriage: func(string) (*bv.TriageResponse, error) { return triage, nil },
+		FetchInProgress: func(string, int) ([]bv.BeadInProgress, error) {
+			return []bv.BeadInProgress{{ID: "bd-run", Title: "Refactor send", Assignee: "BlueLake"}}, nil
+		},
+		FetchBeadFiles: func(_, id string) ([]string, error) { return files[id], nil },
+		FetchReservations: func(context.Context, string) ([]agentmail.FileReservation, error) {
+			return reservations, nil
+		},
+		Cwd: func() (string, error) { return "/repo", nil },
+	}
+}
+
+func TestGetTaskGraph(t *testing.T) {
+	files := map[string][]string{
+		"bd-run":  {"internal/cli/send.go"},
+		"bd-api":  {"internal/serve/**/*"},
+		"bd-docs": {"docs/api.md"},
+		"bd-cli":  {"internal/cli/send.go", "internal/cli/root.go"},
+		"bd-ui":   {"internal/tui/dashboard.go"},
+	}
+	reservations := []agentmail.FileReservation{
+		{AgentName: "GreenCastle", PathPattern: "internal/tui/*.go"},
+		{AgentName: "BlueLake", PathPattern: "internal/cli/send.go"},
+	}
+	out, err := GetTaskGraph(TaskGraphOptions{DOT: true, Deps: taskGraphTestDeps(files, reservations)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success {
+		t.Fatalf("plan failed: %+v", out.RobotResponse)
+	}
+
+	wantEdges := []PlanEdge{
+		{From: "bd-api", To: "bd-docs", Kind: PlanEdgeDependsOn},
+		{From: "bd-run", To: "bd-cli", Kind: PlanEdgeFileOverlap, Files: []string{"internal/cli/send.go"}},
+	}
+	if !reflect.DeepEqual(out.Edges, wantEdges) {
+		t.Errorf("edges = %+v", out.Edges)
+	}
+
+	waves := map[string]int{}
+	for _, task := range out.Tasks {
+		waves[task.ID] = task.Wave
+	}
+	want := map[string]int{"bd-run": 0, "bd-api": 0, "bd-docs": 1, "bd-cli": 1, "bd-ui": 1, "bd-ext": 1}
+	if !reflect.DeepEqual(waves, want) {
+		t.Errorf("waves = %v, want %v", waves, want)
+	}
+	if !reflect.DeepEqual(out.StartNow, []string{"bd-api"}) {
+		t.Errorf("start_now = %v", out.StartNow)
+	}
+
+	byID := map[string]PlanTask{}
+	for _, task := range out.Tasks {
+		byID[task.ID] = task
+	}
+	if r := byID["bd-ui"].ReservedBy; len(r) != 1 || r[0].Agent != "GreenCastle" {
+		t.Errorf("bd-ui reserved_by = %+v", r)
+	}
+	if len(byID["bd-run"].ReservedBy) != 0 {
+		t.Error("a task's own assignee's reservation should not count")
+	}
+	if !reflect.DeepEqual(byID["bd-ext"].BlockedBy, []string{"bd-elsewhere"}) || byID["bd-docs"].BlockedBy != nil {
+		t.Errorf("blocked_by: ext=%v docs=%v", byID["bd-ext"].BlockedBy, byID["bd-docs"].BlockedBy)
+	}
+
+	s := out.Summary
+	if s.Tasks != 6 || s.InProgress != 1 || s.Ready != 3 || s.Blocked != 2 || s.Dependencies != 1 || s.FileOverlaps != 1 || s.Waves != 2 {
+		t.Errorf("summary = %+v", s)
+	}
+	if !strings.Contains(out.DOT, `"bd-api" -> "bd-docs";`) || !strings.Contains(out.DOT, `"bd-run" -> "bd-cli" [style=dashed`) {
+		t.Errorf("dot =\n%s", out.DOT)
+	}
+}
+
+func TestGetTaskGraphOverlapRespectsDependencies(t *testing.T) {
+	deps := taskGraphTestDeps(map[string][]string{
+		"bd-api":  {"docs/api.md"},
+		"bd-docs": {"docs/api.md"},
+	}, nil)
+	triage, _ := deps.FetchTriage("")
+	// List the dependent task first: the overlap must still follow the dependency.
+	recs := triage.Triage.Recommendations
+	recs[0], recs[1] = recs[1], recs[0]
+
+	out, _ := GetTaskGraph(TaskGraphOptions{Deps: deps})
+	for _, e := range out.Edges {
+		if e.From == "bd-docs" && e.To == "bd-api" {
+			t.Errorf("edge contradicts dependency: %+v", e)
+		}
+	}
+	for _, w := range out.Warnings {
+		if strings.Contains(w, "cycle") {
+			t.Errorf("unexpected cycle: %s", w)
+		}
+	}
+}
+
+func TestGetTaskGraphTriageError(t *testing.T) {
+	deps := taskGraphTestDeps(nil, nil)
+	deps.FetchTriage = func(string) (*bv.TriageResponse, error) { return nil, errors.New("bv missing") }
+	out, err := GetTaskGraph(TaskGraphOptions{Deps: deps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Success || out.ErrorCode != ErrCodeDependencyMissing {
+		t.Errorf("response = %+v", out.RobotResponse)
+	}
+}
+
+func TestTaskGraphCycle(t *testing.T) {
+	tasks := []PlanTask{{ID: "a", Status: PlanTaskBlocked}, {ID: "b", Status: PlanTaskBlocked}, {ID: "c", Status: PlanTaskReady}}
+	g := buildPlanGraph(tasks, map[string][]string{"a": {"b"}, "b": {"a"}}, nil)
+	if g.tasks[0].Wave != -1 || g.tasks[1].Wave != -1 || g.tasks[2].Wave != 0 {
+		t.Errorf("waves = %d %d %d", g.tasks[0].Wave, g.tasks[1].Wave, g.tasks[2].Wave)
+	}
+	if len(g.warnings) != 1 || !strings.Contains(g.warnings[0], "a, b") {
+		t.Errorf("warnings = %v", g.warnings)
+	}
+}
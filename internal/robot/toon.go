@@ -8,14 +8,27 @@
 //   - Primitive values (strings, numbers, booleans, null)
 //   - Simple objects with scalar fields
 //
+// Values render as encoding/json writes them, so robot structs need no
+// map[string]any workarounds: a json.Marshaler (time.Time, say) as what its
+// MarshalJSON returns, an encoding.TextMarshaler as its text, []byte as
+// base64, and embedded structs' fields promoted into the outer object.
+// Objects honour omitempty and omitzero; tabular rows keep every column.
+//
 // For unsupported shapes (deeply nested structures), the encoder returns an error.
 // Use FormatAuto to fall back to JSON for such payloads.
 //
+// Field order is deterministic: object keys and tabular columns are sorted
+// by name (byte order), whether they come from a map or a struct, so the
+// output of two runs over the same data diffs cleanly. Both the pure Go
+// encoder and toon_rust produce this order.
+//
 // Reference: https://github.com/toon-format/spec
 package robot
 
 import (
 	"bytes"
+	"encoding"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -25,14 +38,15 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 // toonEncode encodes a payload as TOON format.
 // Returns an error for unsupported payload shapes.
 func toonEncode(payload any, delimiter string) (string, error) {
-	jsonBytes, err := json.Marshal(payload)
+	jsonBytes, err := toonCanonicalJSON(payload)
 	if err != nil {
-		return "", fmt.Errorf("json marshal: %w", err)
+		return "", err
 	}
 
 	truPath, err := toonBinaryPath()
@@ -64,16 +78,45 @@ func toonEncode(payload any, delimiter string) (string, error) {
 	return stdout.String(), nil
 }
 
+// toonCanonicalJSON marshals payload with every object's keys sorted. Go
+// keeps struct fields in declaration order, which toon_rust would preserve;
+// decoding into maps and encoding again sorts them like the pure Go encoder.
+func toonCanonicalJSON(payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("json canonicalize: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(generic); err != nil {
+		return nil, fmt.Errorf("json canonicalize: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 // toonEncodePureGo encodes a payload as TOON using the pure Go implementation.
 func toonEncodePureGo(payload any, delimiter string) (string, error) {
-	enc := &toonEncoder{delimiter: toonDelimiterArg(delimiter)}
-	v := reflect.ValueOf(payload)
+	return toonEncodeWithOptions(payload, delimiter, TOONOptions{})
+}
 
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-		if v.IsNil() {
-			return "null\n", nil
-		}
-		v = v.Elem()
+// toonEncodeWithOptions encodes a payload as TOON in pure Go with the
+// given table layout, which toon_rust does not offer.
+func toonEncodeWithOptions(payload any, delimiter string, opts TOONOptions) (string, error) {
+	enc := &toonEncoder{
+		delimiter:     toonDelimiterChar(delimiter),
+		align:         opts.Align,
+		abbreviations: opts.Abbreviations,
+	}
+	v, err := enc.resolve(reflect.ValueOf(payload))
+	if err != nil {
+		return "", err
 	}
 
 	if !v.IsValid() {
@@ -192,9 +235,28 @@ func toonDelimiterArg(delimiter string) string {
 	}
 }
 
+// toonDelimiterChar returns the separator the pure Go encoder writes
+// for a delimiter given as a character or as toon_rust's name for one.
+func toonDelimiterChar(delimiter string) string {
+	switch arg := toonDelimiterArg(delimiter); arg {
+	case "tab":
+		return "\t"
+	case "comma":
+		return ","
+	case "pipe":
+		return "|"
+	default:
+		return arg
+	}
+}
+
 // toonEncoder holds encoding state.
 type toonEncoder struct {
-	delimiter string
+	delimiter     string
+	align         bool              // pad table cells to column width
+	abbreviations map[string]string // field name -> table header alias
+
+	types map[reflect.Type]*toonTypeInfo // see typeInfo
 }
 
 // renderArray renders a slice/array as TOON tabular format.
@@ -205,12 +267,12 @@ func (enc *toonEncoder) renderArray(v reflect.Value) (string, error) {
 	}
 
 	// Check if it's an array of objects (maps or structs)
-	first := v.Index(0)
-	for first.Kind() == reflect.Ptr || first.Kind() == reflect.Interface {
-		if first.IsNil() {
-			return "", fmt.Errorf("TOON: nil element in array")
-		}
-		first = first.Elem()
+	first, err := enc.resolve(v.Index(0))
+	if err != nil {
+		return "", err
+	}
+	if !first.IsValid() {
+		return "", fmt.Errorf("TOON: nil element in array")
 	}
 
 	if first.Kind() == reflect.Map || first.Kind() == reflect.Struct {
@@ -242,8 +304,7 @@ func (enc *toonEncoder) renderTabular(v reflect.Value) (string, error) {
 		return "[]\n", nil
 	}
 
-	// Extract field names from the first element
-	fields, err := enc.extractFields(v.Index(0))
+	fields, err := enc.tabularFields(v)
 	if err != nil {
 		return "", err
 	}
@@ -251,44 +312,73 @@ func (enc *toonEncoder) renderTabular(v reflect.Value) (string, error) {
 		return "", fmt.Errorf("TOON: empty object in array")
 	}
 
-	// Sort fields for deterministic output
-	sort.Strings(fields)
+	// Encode every cell first: aligned columns need their widths, and
+	// the tab delimiter whether any value holds a tab or newline.
+	tabSafe := true
+	rows := make([][]string, length)
+	for i := 0; i < length; i++ {
+		elem, err := enc.resolve(v.Index(i))
+		if err != nil {
+			return "", err
+		}
+		if !elem.IsValid() {
+			return "", fmt.Errorf("TOON: nil element at index %d", i)
+		}
+
+		rows[i] = make([]string, len(fields))
+		for j, field := range fields {
+			// A field other elements have but this one lacks is null.
+			val, _ := enc.getFieldValue(elem, field)
+			val, err := enc.resolve(val)
+			if err != nil {
+				return "", fmt.Errorf("TOON: field %q at index %d: %w", field, i, err)
+			}
+			tabSafe = tabSafe && toonTabSafe(val)
+			encoded, err := enc.encodeValue(val)
+			if err != nil {
+				return "", fmt.Errorf("TOON: field %q at index %d: %w", field, i, err)
+			}
+			rows[i][j] = encoded
+		}
+	}
 
-	// Check if tab delimiter is safe (no tabs/newlines in values)
 	safeDelim := enc.delimiter
-	if enc.delimiter == "\t" && !enc.isTabSafe(v, fields) {
+	if enc.delimiter == "\t" && !tabSafe {
 		safeDelim = ","
 	}
 
+	var widths []int
+	if enc.align {
+		widths = make([]int, len(fields))
+		for _, row := range rows {
+			for j, cell := range row {
+				widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
 	var buf strings.Builder
 
 	// Header: key[count]{field1,field2,...}:
-	buf.WriteString(fmt.Sprintf("[%d]{%s}:\n", length, strings.Join(fields, ",")))
+	header, legend := enc.tableHeader(fields)
+	buf.WriteString(fmt.Sprintf("[%d]{%s}:\n", length, strings.Join(header, ",")))
+	if legend != "" {
+		buf.WriteString(" # " + legend + "\n")
+	}
 
 	// Rows
-	for i := 0; i < length; i++ {
-		elem := v.Index(i)
-		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
-			if elem.IsNil() {
-				return "", fmt.Errorf("TOON: nil element at index %d", i)
-			}
-			elem = elem.Elem()
-		}
-
+	for _, row := range rows {
 		buf.WriteString(" ") // TOON requires single space indent for rows
-		for j, field := range fields {
-			val, err := enc.getFieldValue(elem, field)
-			if err != nil {
-				return "", err
-			}
-			encoded, err := enc.encodeValue(val)
-			if err != nil {
-				return "", fmt.Errorf("TOON: field %q at index %d: %w", field, i, err)
-			}
+		for j, cell := range row {
 			if j > 0 {
 				buf.WriteString(safeDelim)
 			}
-			buf.WriteString(encoded)
+			buf.WriteString(cell)
+			if widths != nil && j < len(row)-1 {
+				for pad := widths[j] - utf8.RuneCountInString(cell); pad > 0; pad -= len(toonPadding) {
+					buf.WriteString(toonPadding[:min(pad, len(toonPadding))])
+				}
+			}
 		}
 		buf.WriteString("\n")
 	}
@@ -296,6 +386,38 @@ func (enc *toonEncoder) renderTabular(v reflect.Value) (string, error) {
 	return buf.String(), nil
 }
 
+// toonPadding is written in slices to align table cells.
+const toonPadding = "                                "
+
+// tableHeader returns the column names of a table with fields, each
+// replaced by its abbreviation unless that would clash with another
+// column, and a legend mapping the aliases used back to the fields.
+func (enc *toonEncoder) tableHeader(fields []string) (header []string, legend string) {
+	if len(enc.abbreviations) == 0 {
+		return fields, ""
+	}
+	taken := make(map[string]int, len(fields))
+	for _, f := range fields {
+		taken[f]++
+	}
+	header = make([]string, len(fields))
+	var pairs []string
+	for i, f := range fields {
+		header[i] = f
+		alias, ok := enc.abbreviations[f]
+		if !ok || alias == "" || alias == f || taken[alias] > 0 {
+			continue
+		}
+		taken[alias]++
+		header[i] = alias
+		pairs = append(pairs, alias+"="+f)
+	}
+	if len(pairs) == 0 {
+		return header, ""
+	}
+	return header, "legend: " + strings.Join(pairs, ", ")
+}
+
 // renderObject renders a map or struct as TOON key-value pairs.
 func (enc *toonEncoder) renderObject(v reflect.Value, indent int) (string, error) {
 	fields, err := enc.extractFields(v)
@@ -306,9 +428,6 @@ func (enc *toonEncoder) renderObject(v reflect.Value, indent int) (string, error
 		return "{}\n", nil
 	}
 
-	// Sort fields for deterministic output
-	sort.Strings(fields)
-
 	var buf strings.Builder
 	indentStr := strings.Repeat("  ", indent)
 
@@ -317,24 +436,17 @@ func (enc *toonEncoder) renderObject(v reflect.Value, indent int) (string, error
 		if err != nil {
 			return "", err
 		}
-
-		// Handle nil/invalid values
-		if !val.IsValid() {
-			buf.WriteString(indentStr + field + ": null\n")
+		if enc.omitField(v, field, val) {
 			continue
 		}
 
-		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
-			if val.IsNil() {
-				buf.WriteString(indentStr + field + ": null\n")
-				val = reflect.Value{} // mark as handled
-				break
-			}
-			val = val.Elem()
+		val, err = enc.resolve(val)
+		if err != nil {
+			return "", err
 		}
-
 		if !val.IsValid() {
-			continue // already handled as null
+			buf.WriteString(indentStr + field + ": null\n")
+			continue
 		}
 
 		// Check if value is complex (needs nested rendering)
@@ -363,13 +475,36 @@ func (enc *toonEncoder) renderObject(v reflect.Value, indent int) (string, error
 	return buf.String(), nil
 }
 
-// extractFields returns the field names from a map or struct.
-func (enc *toonEncoder) extractFields(v reflect.Value) ([]string, error) {
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-		if v.IsNil() {
-			return nil, nil
+// tabularFields returns the columns of a tabular array: the sorted union
+// of its elements' fields, so a key missing from the first row is neither
+// dropped nor made to depend on which element comes first. An element
+// without a field renders null in that column.
+func (enc *toonEncoder) tabularFields(v reflect.Value) ([]string, error) {
+	seen := make(map[string]bool)
+	var fields []string
+	for i := 0; i < v.Len(); i++ {
+		elemFields, err := enc.extractFields(v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range elemFields {
+			if !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
 		}
-		v = v.Elem()
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// extractFields returns the field names of a map or struct, sorted. A
+// struct's fields are those encoding/json would write, embedded structs'
+// fields promoted to the top level.
+func (enc *toonEncoder) extractFields(v reflect.Value) ([]string, error) {
+	v, err := enc.resolve(v)
+	if err != nil || !v.IsValid() {
+		return nil, err
 	}
 
 	switch v.Kind() {
@@ -382,78 +517,381 @@ func (enc *toonEncoder) extractFields(v reflect.Value) ([]string, error) {
 			}
 			fields[i] = key.String()
 		}
+		sort.Strings(fields)
 		return fields, nil
 	case reflect.Struct:
-		t := v.Type()
-		fields := make([]string, 0, t.NumField())
-		for i := 0; i < t.NumField(); i++ {
-			f := t.Field(i)
-			if !f.IsExported() {
-				continue
-			}
-			name := f.Tag.Get("json")
-			if name == "-" {
-				continue
-			}
-			if idx := strings.Index(name, ","); idx != -1 {
-				name = name[:idx]
-			}
-			if name == "" {
-				name = f.Name
-			}
-			fields = append(fields, name)
-		}
-		return fields, nil
+		return enc.typeInfo(v.Type()).fields.names, nil
 	default:
 		return nil, fmt.Errorf("TOON: expected map or struct, got %s", v.Kind())
 	}
 }
 
-// getFieldValue retrieves a field value from a map or struct.
+// getFieldValue retrieves a field value from a map or struct. A field
+// promoted through a nil embedded pointer is invalid, as is a key the map
+// lacks.
 func (enc *toonEncoder) getFieldValue(v reflect.Value, field string) (reflect.Value, error) {
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-		if v.IsNil() {
-			return reflect.Value{}, nil
-		}
-		v = v.Elem()
+	v, err := enc.resolve(v)
+	if err != nil || !v.IsValid() {
+		return reflect.Value{}, err
 	}
 
 	switch v.Kind() {
 	case reflect.Map:
 		return v.MapIndex(reflect.ValueOf(field)), nil
 	case reflect.Struct:
-		// First try JSON tag
-		t := v.Type()
-		for i := 0; i < t.NumField(); i++ {
-			f := t.Field(i)
-			name := f.Tag.Get("json")
-			if idx := strings.Index(name, ","); idx != -1 {
-				name = name[:idx]
+		f, ok := enc.typeInfo(v.Type()).fields.byName[field]
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("field %q not found", field)
+		}
+		val, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			return reflect.Value{}, nil
+		}
+		return val, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("expected map or struct")
+	}
+}
+
+// omitField reports whether renderObject leaves out a struct field, as
+// encoding/json would: fields promoted through a nil embedded pointer, and
+// empty omitempty or zero omitzero fields. Tabular rows keep every column.
+func (enc *toonEncoder) omitField(v reflect.Value, field string, val reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	f := enc.typeInfo(v.Type()).fields.byName[field]
+	switch {
+	case !val.IsValid():
+		return true
+	case f.omitEmpty && toonIsEmpty(val):
+		return true
+	case f.omitZero && toonIsZero(val):
+		return true
+	}
+	return false
+}
+
+// resolve dereferences pointers and interfaces and replaces values that
+// encode themselves with what they encode to, so each renders the way
+// encoding/json writes it: a json.Marshaler (time.Time among them) as its
+// JSON decoded to plain values, an encoding.TextMarshaler as its text and
+// a []byte as base64. Nil, including nil slices and maps, resolves to the
+// invalid Value.
+func (enc *toonEncoder) resolve(v reflect.Value) (reflect.Value, error) {
+	for v.IsValid() {
+		if v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+			v = v.Elem()
+			continue
+		}
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return reflect.Value{}, nil
+		}
+
+		info := enc.typeInfo(v.Type())
+		if info.json || (info.jsonPtr && v.CanAddr()) {
+			m := v
+			if !info.json {
+				m = v.Addr()
+			}
+			data, err := m.Interface().(json.Marshaler).MarshalJSON()
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("TOON: marshal %s: %w", v.Type(), err)
 			}
-			if name == "" {
-				name = f.Name
+			if s, ok := toonPlainJSONString(data); ok {
+				return reflect.ValueOf(s), nil
 			}
-			if name == field {
-				return v.Field(i), nil
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var decoded any
+			if err := dec.Decode(&decoded); err != nil {
+				return reflect.Value{}, fmt.Errorf("TOON: marshal %s: %w", v.Type(), err)
 			}
+			return reflect.ValueOf(decoded), nil
 		}
-		return reflect.Value{}, fmt.Errorf("field %q not found", field)
-	default:
-		return reflect.Value{}, fmt.Errorf("expected map or struct")
+		if info.text || (info.textPtr && v.CanAddr()) {
+			m := v
+			if !info.text {
+				m = v.Addr()
+			}
+			text, err := m.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("TOON: marshal %s: %w", v.Type(), err)
+			}
+			return reflect.ValueOf(string(text)), nil
+		}
+
+		switch v.Kind() {
+		case reflect.Ptr:
+			v = v.Elem()
+		case reflect.Map:
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+			return v, nil
+		case reflect.Slice:
+			if v.IsNil() {
+				return reflect.Value{}, nil
+			}
+			if v.Type().Elem().Kind() == reflect.Uint8 {
+				return reflect.ValueOf(base64.StdEncoding.EncodeToString(v.Bytes())), nil
+			}
+			return v, nil
+		default:
+			return v, nil
+		}
+	}
+	return v, nil
+}
+
+// toonPlainJSONString returns the string data encodes when it is a JSON
+// string without escapes, as time.Time's is, sparing a decoder for the
+// commonest Marshaler output.
+func toonPlainJSONString(data []byte) (string, bool) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return "", false
+	}
+	inner := data[1 : len(data)-1]
+	for _, b := range inner {
+		if b == '"' || b == '\\' || b < 0x20 || b >= utf8.RuneSelf {
+			return "", false
+		}
+	}
+	return string(inner), true
+}
+
+// toonTypeInfo is what the encoder needs to know about a type: whether its
+// values encode themselves, and for structs their fields.
+type toonTypeInfo struct {
+	json, jsonPtr bool // json.Marshaler on the value, or only its pointer
+	text, textPtr bool // encoding.TextMarshaler on the value, or only its pointer
+	fields        *toonFieldSet
+}
+
+// typeInfo returns t's info, worked out once per encoder: reflection on
+// method sets costs more than the encoding itself on large tables.
+func (enc *toonEncoder) typeInfo(t reflect.Type) *toonTypeInfo {
+	if info, ok := enc.types[t]; ok {
+		return info
+	}
+	info := &toonTypeInfo{
+		json: t.Implements(toonJSONMarshalerType),
+		text: t.Implements(toonTextMarshalerType),
+	}
+	if t.Kind() != reflect.Ptr {
+		pt := reflect.PointerTo(t)
+		info.jsonPtr = !info.json && pt.Implements(toonJSONMarshalerType)
+		info.textPtr = !info.text && pt.Implements(toonTextMarshalerType)
+	}
+	if t.Kind() == reflect.Struct {
+		info.fields = toonStructFields(t)
+	}
+	if enc.types == nil {
+		enc.types = make(map[reflect.Type]*toonTypeInfo)
+	}
+	enc.types[t] = info
+	return info
+}
+
+var (
+	toonJSONMarshalerType = reflect.TypeFor[json.Marshaler]()
+	toonTextMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	toonJSONNumberType    = reflect.TypeFor[json.Number]()
+)
+
+// toonMarshaler returns v as an implementation of iface, through its
+// address when only the pointer implements it and v is addressable, the
+// same methods encoding/json calls. A nil pointer does not count, its
+// dereference renders null.
+func toonMarshaler(v reflect.Value, iface reflect.Type) (any, bool) {
+	if v.Kind() == reflect.Interface {
+		return nil, false
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, false
+	}
+	if v.Type().Implements(iface) {
+		return v.Interface(), true
+	}
+	if v.Kind() != reflect.Ptr && v.CanAddr() && reflect.PointerTo(v.Type()).Implements(iface) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+// toonField is a struct field as encoding/json sees it.
+type toonField struct {
+	name      string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	omitZero  bool
+}
+
+// toonFieldSet is the encoded fields of a struct type.
+type toonFieldSet struct {
+	names  []string // sorted
+	byName map[string]toonField
+}
+
+var toonFieldCache sync.Map // reflect.Type -> *toonFieldSet
+
+// toonStructFields returns the fields encoding/json writes for t: exported
+// fields under their tag names, minus those tagged "-", with the fields of
+// untagged embedded structs promoted. When names collide the shallowest
+// field wins, then the only tagged one; otherwise none is written.
+func toonStructFields(t reflect.Type) *toonFieldSet {
+	if cached, ok := toonFieldCache.Load(t); ok {
+		return cached.(*toonFieldSet)
+	}
+
+	type level struct {
+		typ   reflect.Type
+		index []int
+	}
+	var candidates []toonField
+	visited := map[reflect.Type]bool{}
+	for next := []level{{typ: t}}; len(next) > 0; {
+		current := next
+		next = nil
+		for _, l := range current {
+			if visited[l.typ] {
+				continue
+			}
+			visited[l.typ] = true
+			for i := 0; i < l.typ.NumField(); i++ {
+				sf := l.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(append([]int(nil), l.index...), i)
+
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous {
+					if !sf.IsExported() && (ft.Kind() != reflect.Struct || sf.Type.Kind() == reflect.Ptr) {
+						continue
+					}
+					if name == "" && ft.Kind() == reflect.Struct {
+						next = append(next, level{typ: ft, index: index})
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				f := toonField{name: name, index: index, tagged: name != ""}
+				if f.name == "" {
+					f.name = sf.Name
+				}
+				for _, opt := range strings.Split(opts, ",") {
+					switch opt {
+					case "omitempty":
+						f.omitEmpty = true
+					case "omitzero":
+						f.omitZero = true
+					}
+				}
+				candidates = append(candidates, f)
+			}
+		}
+	}
+
+	// Candidates come in depth order, so the first of a name is shallowest.
+	set := &toonFieldSet{byName: make(map[string]toonField)}
+	groups := make(map[string][]toonField)
+	var order []string
+	for _, f := range candidates {
+		if _, ok := groups[f.name]; !ok {
+			order = append(order, f.name)
+		}
+		groups[f.name] = append(groups[f.name], f)
+	}
+	for _, name := range order {
+		if f, ok := toonDominantField(groups[name]); ok {
+			set.names = append(set.names, name)
+			set.byName[name] = f
+		}
+	}
+	sort.Strings(set.names)
+
+	cached, _ := toonFieldCache.LoadOrStore(t, set)
+	return cached.(*toonFieldSet)
+}
+
+// toonDominantField picks the field a name refers to among fields sharing
+// it, in depth order.
+func toonDominantField(fields []toonField) (toonField, bool) {
+	depth := len(fields[0].index)
+	var shallow []toonField
+	for _, f := range fields {
+		if len(f.index) == depth {
+			shallow = append(shallow, f)
+		}
+	}
+	if len(shallow) == 1 {
+		return shallow[0], true
+	}
+	var tagged []toonField
+	for _, f := range shallow {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+	return toonField{}, false
+}
+
+// toonIsEmpty reports whether omitempty leaves v out.
+func toonIsEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// toonIsZero reports whether omitzero leaves v out: its IsZero method
+// says so, or it is the zero value of its type.
+func toonIsZero(v reflect.Value) bool {
+	if z, ok := toonMarshaler(v, toonIsZeroerType); ok {
+		return z.(interface{ IsZero() bool }).IsZero()
 	}
+	return v.IsZero()
 }
 
+var toonIsZeroerType = reflect.TypeFor[interface{ IsZero() bool }]()
+
 // encodeValue encodes a single value as TOON.
 func (enc *toonEncoder) encodeValue(v reflect.Value) (string, error) {
+	v, err := enc.resolve(v)
+	if err != nil {
+		return "", err
+	}
 	if !v.IsValid() {
 		return "null", nil
 	}
-
-	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-		if v.IsNil() {
-			return "null", nil
-		}
-		v = v.Elem()
+	if v.Type() == toonJSONNumberType {
+		return v.String(), nil
 	}
 
 	switch v.Kind() {
@@ -471,21 +909,170 @@ func (enc *toonEncoder) encodeValue(v reflect.Value) (string, error) {
 	case reflect.Float32, reflect.Float64:
 		return enc.formatFloat(v.Float()), nil
 	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
-		// Nested complex types in tabular rows: fall back to JSON inline
-		var buf bytes.Buffer
-		encoder := json.NewEncoder(&buf)
-		encoder.SetEscapeHTML(false)
-		if err := encoder.Encode(v.Interface()); err != nil {
+		// Nested complex types in tabular rows: fall back to JSON inline,
+		// keys sorted like everywhere else.
+		var buf strings.Builder
+		if err := enc.writeJSON(&buf, v); err != nil {
 			return "", fmt.Errorf("encoding nested value: %w", err)
 		}
-		// Remove trailing newline from JSON
-		s := strings.TrimSuffix(buf.String(), "\n")
-		return enc.encodeString(s), nil
+		return enc.encodeString(buf.String()), nil
 	default:
 		return "", fmt.Errorf("unsupported value type %s", v.Kind())
 	}
 }
 
+// writeJSON writes v as compact JSON with sorted keys, the same text
+// toonCanonicalJSON produces, without its marshal and decode round trip.
+func (enc *toonEncoder) writeJSON(buf *strings.Builder, v reflect.Value) error {
+	v, err := enc.resolve(v)
+	if err != nil {
+		return err
+	}
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+	if v.Type() == toonJSONNumberType {
+		buf.WriteString(v.String())
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		toonWriteJSONString(buf, v.String())
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	case reflect.Slice, reflect.Array:
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := enc.writeJSON(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			// Integer and TextMarshaler keys: let encoding/json name them.
+			data, err := toonCanonicalJSON(v.Interface())
+			if err != nil {
+				return err
+			}
+			buf.Write(data)
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			toonWriteJSONString(buf, k)
+			buf.WriteByte(':')
+			if err := enc.writeJSON(buf, v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key()))); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case reflect.Struct:
+		buf.WriteByte('{')
+		first := true
+		for _, name := range enc.typeInfo(v.Type()).fields.names {
+			val, _ := enc.getFieldValue(v, name)
+			if enc.omitField(v, name, val) {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			toonWriteJSONString(buf, name)
+			buf.WriteByte(':')
+			if err := enc.writeJSON(buf, val); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value type %s", v.Kind())
+	}
+	return nil
+}
+
+// toonWriteJSONString writes s as a JSON string the way encoding/json does
+// without HTML escaping.
+func toonWriteJSONString(buf *strings.Builder, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[b>>4])
+				buf.WriteByte(hex[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteRune(utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
 // encodeString encodes a string, quoting only when necessary.
 // Unquoted strings match: ^[A-Za-z_][A-Za-z0-9_]*$
 func (enc *toonEncoder) encodeString(s string) string {
@@ -518,25 +1105,32 @@ func (enc *toonEncoder) encodeString(s string) string {
 		return s
 	}
 
-	// Quote and escape
+	// Quote and escape, copying the runs between escapes whole
 	var buf strings.Builder
+	buf.Grow(len(s) + 2)
 	buf.WriteByte('"')
-	for _, c := range s {
-		switch c {
+	start := 0
+	for i := 0; i < len(s); i++ {
+		var esc string
+		switch s[i] {
 		case '\\':
-			buf.WriteString(`\\`)
+			esc = `\\`
 		case '"':
-			buf.WriteString(`\"`)
+			esc = `\"`
 		case '\n':
-			buf.WriteString(`\n`)
+			esc = `\n`
 		case '\r':
-			buf.WriteString(`\r`)
+			esc = `\r`
 		case '\t':
-			buf.WriteString(`\t`)
+			esc = `\t`
 		default:
-			buf.WriteRune(c)
+			continue
 		}
+		buf.WriteString(s[start:i])
+		buf.WriteString(esc)
+		start = i + 1
 	}
+	buf.WriteString(s[start:])
 	buf.WriteByte('"')
 	return buf.String()
 }
@@ -556,29 +1150,29 @@ func (enc *toonEncoder) formatFloat(f float64) string {
 // isTabSafe checks if tab delimiter is safe for all values.
 func (enc *toonEncoder) isTabSafe(v reflect.Value, fields []string) bool {
 	for i := 0; i < v.Len(); i++ {
-		elem := v.Index(i)
-		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
-			if elem.IsNil() {
-				continue
-			}
-			elem = elem.Elem()
+		elem, err := enc.resolve(v.Index(i))
+		if err != nil || !elem.IsValid() {
+			continue
 		}
 		for _, field := range fields {
 			val, err := enc.getFieldValue(elem, field)
 			if err != nil {
 				continue
 			}
-			if val.Kind() == reflect.String {
-				s := val.String()
-				if strings.ContainsAny(s, "\t\n\r") {
-					return false
-				}
+			if val, err = enc.resolve(val); err == nil && !toonTabSafe(val) {
+				return false
 			}
 		}
 	}
 	return true
 }
 
+// toonTabSafe reports whether a resolved value can sit in a tab-delimited
+// row: it is not a string holding a tab or line break.
+func toonTabSafe(v reflect.Value) bool {
+	return v.Kind() != reflect.String || !strings.ContainsAny(v.String(), "\t\n\r")
+}
+
 func toonIsIdentifierStart(c rune) bool {
 	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || c == '_'
 }
@@ -1,9 +1,14 @@
 package robot
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 // =============================================================================
@@ -201,18 +206,9 @@ func TestExtractFields_Map(t *testing.T) {
 	if err != nil {
 		t.Fatalf("extractFields error: %v", err)
 	}
-	if len(fields) != 3 {
-		t.Fatalf("expected 3 fields, got %d", len(fields))
-	}
-	// Fields come in map iteration order (unspecified)
-	found := map[string]bool{}
-	for _, f := range fields {
-		found[f] = true
-	}
-	for _, expected := range []string{"alpha", "beta", "gamma"} {
-		if !found[expected] {
-			t.Errorf("missing field %q", expected)
-		}
+	// Fields are sorted, not in map iteration order
+	if strings.Join(fields, ",") != "alpha,beta,gamma" {
+		t.Errorf("fields = %v, want [alpha beta gamma]", fields)
 	}
 }
 
@@ -1278,3 +1274,409 @@ func TestRenderObject_MixedScalarAndComplex(t *testing.T) {
 		t.Errorf("missing indented author field in %q", got)
 	}
 }
+
+// =============================================================================
+// Deterministic field order
+// =============================================================================
+
+func TestRenderTabular_NonUniformRowsUnionFields(t *testing.T) {
+	t.Parallel()
+	enc := &toonEncoder{delimiter: ","}
+
+	data := []map[string]any{
+		{"name": "a"},
+		{"name": "b", "pane": 2},
+	}
+	got, err := enc.renderTabular(reflect.ValueOf(data))
+	if err != nil {
+		t.Fatalf("renderTabular error: %v", err)
+	}
+	want := "[2]{name,pane}:\n a,null\n b,2\n"
+	if got != want {
+		t.Errorf("renderTabular = %q, want %q", got, want)
+	}
+}
+
+func TestToonEncodePureGo_StableAcrossRuns(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{"agents": []map[string]any{}}
+	row := map[string]any{}
+	for i := 0; i < 40; i++ {
+		key := string(rune('a'+i%26)) + strings.Repeat("x", i/26)
+		payload[key] = i
+		row[key] = i
+	}
+	payload["agents"] = []map[string]any{row, row}
+
+	first, err := toonEncodePureGo(payload, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if got, _ := toonEncodePureGo(payload, ","); got != first {
+			t.Fatalf("run %d differs:\n%s\nvs\n%s", i, got, first)
+		}
+	}
+}
+
+func TestToonCanonicalJSON_SortsStructFields(t *testing.T) {
+	t.Parallel()
+
+	type pane struct {
+		Type  string `json:"type"`
+		Index int    `json:"index"`
+	}
+	payload := struct {
+		Zeta  int    `json:"zeta"`
+		Alpha []pane `json:"alpha"`
+		Big   int64  `json:"big"`
+	}{Zeta: 1, Alpha: []pane{{Type: "cc", Index: 1}}, Big: 1 << 60}
+
+	got, err := toonCanonicalJSON(payload)
+	if err != nil {
+		t.Fatalf("toonCanonicalJSON error: %v", err)
+	}
+	want := `{"alpha":[{"index":1,"type":"cc"}],"big":1152921504606846976,"zeta":1}`
+	if string(got) != want {
+		t.Errorf("toonCanonicalJSON = %s, want %s", got, want)
+	}
+}
+
+// =============================================================================
+// Marshalers and embedded structs
+// =============================================================================
+
+type toonTestLevel int
+
+func (l toonTestLevel) MarshalJSON() ([]byte, error) {
+	return []byte(`{"name":"warn","value":` + strconv.Itoa(int(l)) + `}`), nil
+}
+
+type toonTestColor struct{ r, g, b uint8 }
+
+func (c toonTestColor) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)), nil
+}
+
+type toonTestPtrMarshaler struct{ id string }
+
+func (p *toonTestPtrMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal("id-" + p.id)
+}
+
+func TestToonEncodePureGo_Marshalers(t *testing.T) {
+	t.Parallel()
+
+	at := time.Date(2026, 3, 4, 5, 6, 7, 890, time.UTC)
+	payload := struct {
+		At     time.Time             `json:"at"`
+		AtPtr  *time.Time            `json:"at_ptr"`
+		Color  toonTestColor         `json:"color"`
+		Level  toonTestLevel         `json:"level"`
+		Raw    []byte                `json:"raw"`
+		Num    json.Number           `json:"num"`
+		Ptr    *toonTestPtrMarshaler `json:"ptr"`
+		Nested map[string]time.Time  `json:"nested"`
+	}{
+		At:     at,
+		Color:  toonTestColor{0x12, 0xab, 0xff},
+		Level:  2,
+		Raw:    []byte("hi"),
+		Num:    json.Number("1.50"),
+		Ptr:    &toonTestPtrMarshaler{id: "x"},
+		Nested: map[string]time.Time{"t": at},
+	}
+
+	got, err := toonEncodePureGo(&payload, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	want := `at: "2026-03-04T05:06:07.00000089Z"
+at_ptr: null
+color: "#12abff"
+level:
+  name: warn
+  value: 2
+nested:
+  t: "2026-03-04T05:06:07.00000089Z"
+num: 1.50
+ptr: "id-x"
+raw: "aGk="
+`
+	if got != want {
+		t.Errorf("toonEncodePureGo =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestToonEncodePureGo_MarshalersInTabularRows(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		At    time.Time     `json:"at"`
+		Level toonTestLevel `json:"level"`
+	}
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	got, err := toonEncodePureGo([]event{{At: at, Level: 1}}, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	want := "[1]{at,level}:\n \"2026-01-02T03:04:05Z\",\"{\\\"name\\\":\\\"warn\\\",\\\"value\\\":1}\"\n"
+	if got != want {
+		t.Errorf("toonEncodePureGo = %q, want %q", got, want)
+	}
+
+	// An array of Marshalers that encode to objects is tabular.
+	got, err = toonEncodePureGo([]toonTestLevel{3, 4}, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	if want := "[2]{name,value}:\n warn,3\n warn,4\n"; got != want {
+		t.Errorf("toonEncodePureGo = %q, want %q", got, want)
+	}
+}
+
+func TestToonEncodePureGo_EmbeddedStructs(t *testing.T) {
+	t.Parallel()
+
+	type Meta struct {
+		Source string `json:"source"`
+		Count  int    `json:"count"`
+	}
+	type Extra struct {
+		Note string `json:"note"`
+	}
+	type output struct {
+		RobotResponse
+		Meta
+		*Extra
+		Count int    `json:"count"` // shadows Meta.Count
+		Named Extra  `json:"named"`
+		Skip  string `json:"-"`
+	}
+
+	out := output{
+		RobotResponse: RobotResponse{Success: true, Timestamp: "2026-01-01T00:00:00Z"},
+		Meta:          Meta{Source: "cli", Count: 1},
+		Count:         7,
+		Named:         Extra{Note: "n"},
+		Skip:          "hidden",
+	}
+
+	got, err := toonEncodePureGo(out, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	for _, line := range []string{"success: true\n", "timestamp: \"2026-01-01T00:00:00Z\"\n", "source: cli\n", "count: 7\n", "named:\n  note: n\n"} {
+		if !strings.Contains(got, line) {
+			t.Errorf("output missing %q:\n%s", line, got)
+		}
+	}
+	for _, absent := range []string{"RobotResponse", "Meta", "Extra", "note: null", "hidden"} {
+		if strings.Contains(got, absent) {
+			t.Errorf("output contains %q:\n%s", absent, got)
+		}
+	}
+
+	// With the embedded pointer set, its fields are promoted too.
+	out.Extra = &Extra{Note: "e"}
+	got, err = toonEncodePureGo(out, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	if !strings.Contains(got, "\nnote: e\n") {
+		t.Errorf("output missing promoted note:\n%s", got)
+	}
+}
+
+func TestToonStructFields_MatchesEncodingJSON(t *testing.T) {
+	t.Parallel()
+
+	type A struct {
+		X int `json:"x"`
+		Y int
+	}
+	type B struct {
+		X int
+		Y int `json:"Y"`
+	}
+	type C struct {
+		Z int `json:"z"`
+	}
+	type payload struct {
+		A                 // x tagged vs B.X untagged: A wins
+		B                 // Y: B tagged wins over A.Y
+		C      `json:"c"` // tagged embedded struct is a named field
+		W      int        `json:"w,omitempty"`
+		hidden int
+	}
+
+	got := toonStructFields(reflect.TypeOf(payload{})).names
+	data, err := json.Marshal(payload{})
+	if err != nil {
+		t.Fatalf("json.Marshal error: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	want := []string{"w"} // omitempty drops it from JSON; the field exists
+	for k := range m {
+		want = append(want, k)
+	}
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toonStructFields = %v, want %v", got, want)
+	}
+}
+
+func TestRenderObject_OmitEmptyAndZero(t *testing.T) {
+	t.Parallel()
+	enc := &toonEncoder{delimiter: ","}
+
+	type item struct {
+		Name    string    `json:"name,omitempty"`
+		Tags    []string  `json:"tags,omitempty"`
+		Started time.Time `json:"started,omitzero"`
+		Count   int       `json:"count"`
+	}
+
+	got, err := enc.renderObject(reflect.ValueOf(item{}), 0)
+	if err != nil {
+		t.Fatalf("renderObject error: %v", err)
+	}
+	if got != "count: 0\n" {
+		t.Errorf("renderObject = %q, want %q", got, "count: 0\n")
+	}
+
+	// Tabular rows keep every column.
+	got, err = toonEncodePureGo([]item{{Count: 1}, {Name: "a", Count: 2}}, ",")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	if !strings.HasPrefix(got, "[2]{count,name,started,tags}:\n") {
+		t.Errorf("tabular header = %q", got)
+	}
+}
+
+// =============================================================================
+// Table layout options
+// =============================================================================
+
+func TestToonEncodeWithOptions_Align(t *testing.T) {
+	t.Parallel()
+
+	rows := []map[string]any{
+		{"agent_type": "claude", "pane": 1, "status": "idle"},
+		{"agent_type": "cod", "pane": 12, "status": "working"},
+	}
+	got, err := toonEncodeWithOptions(rows, ",", TOONOptions{Align: true})
+	if err != nil {
+		t.Fatalf("toonEncodeWithOptions error: %v", err)
+	}
+	want := "[2]{agent_type,pane,status}:\n" +
+		" claude,1 ,idle\n" +
+		" cod   ,12,working\n"
+	if got != want {
+		t.Errorf("aligned =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestToonEncodeWithOptions_Abbreviations(t *testing.T) {
+	t.Parallel()
+
+	payload := map[string]any{
+		"agents": []map[string]any{
+			{"agent_type": "claude", "status": "idle", "s": 1},
+			{"agent_type": "cod", "status": "working", "s": 2},
+		},
+		"agent_type": "not a table",
+	}
+	opts := TOONOptions{Abbreviations: map[string]string{
+		"agent_type": "t",
+		"status":     "s", // clashes with the "s" column: kept in full
+	}}
+	got, err := toonEncodeWithOptions(payload, ",", opts)
+	if err != nil {
+		t.Fatalf("toonEncodeWithOptions error: %v", err)
+	}
+	want := "agent_type: \"not a table\"\n" +
+		"agents[2]{t,s,status}:\n" +
+		" # legend: t=agent_type\n" +
+		" claude,1,idle\n" +
+		" cod,2,working\n"
+	if got != want {
+		t.Errorf("abbreviated =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestToonEncodePureGo_TabDelimiter(t *testing.T) {
+	t.Parallel()
+
+	rows := []map[string]any{{"a": 1, "b": 2}}
+	got, err := toonEncodePureGo(rows, "\t")
+	if err != nil {
+		t.Fatalf("toonEncodePureGo error: %v", err)
+	}
+	if want := "[1]{a,b}:\n 1\t2\n"; got != want {
+		t.Errorf("toonEncodePureGo(tab) = %q, want %q", got, want)
+	}
+}
+
+func TestTOONRenderer_OptionsUsePureGo(t *testing.T) {
+	t.Parallel()
+
+	r := NewTOONRenderer()
+	r.Delimiter = ","
+	r.Options = TOONOptions{Abbreviations: map[string]string{"agent_type": "t"}}
+	got, err := r.Render([]map[string]any{{"agent_type": "cc"}})
+	if err != nil {
+		t.Fatalf("Render error: %v", err)
+	}
+	if want := "[1]{t}:\n # legend: t=agent_type\n cc\n"; got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+
+	if _, err := r.Render(make(chan int)); err == nil {
+		t.Error("expected json marshal error, got nil")
+	}
+}
+
+func TestWriteJSON_MatchesCanonicalJSON(t *testing.T) {
+	t.Parallel()
+	enc := &toonEncoder{delimiter: ","}
+
+	exit := 1
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6, time.FixedZone("x", 3600))
+	values := []any{
+		"plain",
+		"quote \" back \\ <html> & \x01 \b \f \n\r\t",
+		"unicode é 日本     bad:\xff",
+		3.25, 1e21, float32(0.1), -0.0,
+		[]any{1, "a", nil, true},
+		map[string]any{"z": 1, "a": []int{1, 2}, "m": map[string]string{"y": "1", "b": "2"}},
+		map[int]string{10: "a", 2: "b"},
+		[]CommandMention{{Command: "go test", LineNum: 3, ExitCode: &exit}, {Command: "ls"}},
+		CapturedOutput{PaneID: "%1", Timestamp: at, FilePaths: []FileMention{{Path: "a.go", Action: "read", Confidence: 0.5}}},
+		struct {
+			B      []byte            `json:"b"`
+			N      json.Number       `json:"n"`
+			L      toonTestLevel     `json:"l"`
+			Nil    []string          `json:"nil"`
+			NilMap map[string]string `json:"nil_map"`
+		}{B: []byte{0, 255}, N: "12.50", L: 5},
+	}
+	for _, v := range values {
+		want, err := toonCanonicalJSON(v)
+		if err != nil {
+			t.Fatalf("toonCanonicalJSON(%#v) error: %v", v, err)
+		}
+		var buf strings.Builder
+		if err := enc.writeJSON(&buf, reflect.ValueOf(v)); err != nil {
+			t.Fatalf("writeJSON(%#v) error: %v", v, err)
+		}
+		if buf.String() != string(want) {
+			t.Errorf("writeJSON(%#v)\n got %s\nwant %s", v, buf.String(), want)
+		}
+	}
+}
@@ -511,9 +511,19 @@ type MetricsOutput struct {
 	TokenUsage   MetricsTokenUsage       `json:"token_usage"`
 	AgentStats   map[string]AgentMetrics `json:"agent_stats"`
 	SessionStats MetricsSessionStats     `json:"session_stats"`
+	Capture      MetricsCaptureStats     `json:"capture"`
 	AgentHints   *AgentHints             `json:"_agent_hints,omitempty"`
 }
 
+// MetricsCaptureStats reports pane output dropped by the capture size limits
+// in this process.
+type MetricsCaptureStats struct {
+	tmux.CaptureStats
+	MaxCaptureBytes int                          `json:"max_capture_bytes"`
+	MaxPaneBytes    int                          `json:"max_pane_bytes"`
+	ByPane          map[string]tmux.CaptureStats `json:"by_pane,omitempty"`
+}
+
 // MetricsTokenUsage contains token consumption data
 type MetricsTokenUsage struct {
 	TotalTokens    int64            `json:"total_tokens"`
@@ -623,6 +633,23 @@ func GetMetrics(opts MetricsOptions) (*MetricsOutput, error) {
 		output.SessionStats.FilesChanged = len(uniqueFiles)
 	}
 
+	totals, byPane := tmux.GetCaptureStats()
+	limits := tmux.GetCaptureLimits()
+	output.Capture = MetricsCaptureStats{
+		CaptureStats:    totals,
+		MaxCaptureBytes: limits.MaxCaptureBytes,
+		MaxPaneBytes:    limits.MaxPaneBytes,
+	}
+	for target, st := range byPane {
+		if st.Truncated == 0 {
+			continue
+		}
+		if output.Capture.ByPane == nil {
+			output.Capture.ByPane = make(map[string]tmux.CaptureStats)
+		}
+		output.Capture.ByPane[target] = st
+	}
+
 	sessionDesc := opts.Session
 	if sessionDesc == "" {
 		sessionDesc = "all sessions"
@@ -1014,6 +1041,8 @@ func GetAlertsTUI(cfg *config.Config, opts TUIAlertsOptions) (*TUIAlertsOutput,
 			DiskLowThresholdGB:   cfg.Alerts.DiskLowThresholdGB,
 			MailBacklogThreshold: cfg.Alerts.MailBacklogThreshold,
 			BeadStaleHours:       cfg.Alerts.BeadStaleHours,
+			AgentCPUPercent:      cfg.Alerts.AgentCPUPercent,
+			AgentMemoryPercent:   cfg.Alerts.AgentMemoryPercent,
 		}
 	}
 	alertList := alerts.GetActiveAlerts(alertCfg)
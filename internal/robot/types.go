@@ -130,6 +130,29 @@ const (
 
 	// ErrCodePromptSendFailed indicates failed to send prompt.
 	ErrCodePromptSendFailed = "PROMPT_SEND_FAILED"
+
+	// ErrCodeConfirmationRequired indicates a destructive operation needs a
+	// confirmation token before it will run.
+	ErrCodeConfirmationRequired = "CONFIRMATION_REQUIRED"
+
+	// ErrCodeIdempotencyConflict indicates an idempotency key was reused
+	// for a different request.
+	ErrCodeIdempotencyConflict = "IDEMPOTENCY_KEY_REUSED"
+
+	// ErrCodeInvalidArgs indicates required arguments are missing.
+	ErrCodeInvalidArgs = "INVALID_ARGS"
+
+	// ErrCodeSensitiveDataBlocked indicates redaction in block mode refused
+	// content containing potential secrets.
+	ErrCodeSensitiveDataBlocked = "SENSITIVE_DATA_BLOCKED"
+
+	// ErrCodeRateLimited indicates the provider is rate limited and the
+	// operation was deferred.
+	ErrCodeRateLimited = "RATE_LIMITED"
+
+	// ErrCodeAgentError indicates an agent reported an error while being
+	// waited on.
+	ErrCodeAgentError = "AGENT_ERROR"
 )
 
 // ResponseMeta provides optional metadata about response generation.
@@ -169,7 +192,9 @@ type ResponseMeta struct {
 // Error responses additionally include:
 //   - error: Human-readable error message
 //   - error_code: Machine-readable code for programmatic handling
+//   - error_id: Stable NTM-Exxx identifier for the code
 //   - hint: Actionable guidance for resolving the error
+//   - remediation: Action, retryability, and guidance for recovery
 type RobotResponse struct {
 	// Success indicates whether the operation completed successfully.
 	// This is the first field agents should check.
@@ -202,6 +227,13 @@ type RobotResponse struct {
 	// Example: "Use 'ntm list' to see available sessions"
 	Hint string `json:"hint,omitempty"`
 
+	// ErrorID is the stable NTM-Exxx identifier of ErrorCode in the error
+	// taxonomy (see error_codes.go).
+	ErrorID string `json:"error_id,omitempty"`
+
+	// Remediation is machine-readable recovery guidance for the error.
+	Remediation *Remediation `json:"remediation,omitempty"`
+
 	// StructuredError provides detailed error information when simple error fields
 	// are not sufficient. This is used for complex failure modes that require
 	// debugging context. When set, this takes precedence over Error/ErrorCode/Hint.
@@ -531,7 +563,7 @@ func ExpandTerseKey(short string) (long string, ok bool) {
 func NewRobotResponse(success bool) RobotResponse {
 	return RobotResponse{
 		Success:      success,
-		Timestamp:    time.Now().UTC().Format(time.RFC3339),
+		Timestamp:    FormatTimestamp(time.Now()),
 		Version:      EnvelopeVersion,
 		OutputFormat: OutputFormat.String(),
 	}
@@ -594,6 +626,7 @@ func NewErrorResponse(err error, code, hint string) RobotResponse {
 	}
 	resp.ErrorCode = code
 	resp.Hint = hint
+	resp.classify()
 	return resp
 }
 
@@ -661,7 +694,7 @@ func PrintRobotUnavailable(feature, message, plannedVersion, hint string) {
 	resp := NotImplementedResponse{
 		RobotResponse: RobotResponse{
 			Success:   false,
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Timestamp: FormatTimestamp(time.Now()),
 			Error:     message,
 			ErrorCode: ErrCodeNotImplemented,
 			Hint:      hint,
@@ -738,10 +771,13 @@ func AddAgentHints(data interface{}, hints *AgentHints) WithAgentHints {
 // Timestamp Helpers - RFC3339 Standardization
 // =============================================================================
 // All robot command timestamps use RFC3339 format (ISO8601) in UTC.
-// These helpers ensure consistency across all output types.
+// These helpers ensure consistency across all output types; encodeJSON also
+// converts any RFC3339 value with a local offset to UTC and, with --humanize,
+// adds "_human" siblings (see humanize.go).
 
 // FormatTimestamp returns an RFC3339 string for any time.Time in UTC.
-// Use this for all timestamp fields in robot output.
+// It is the one timestamp formatter for robot output; use it for every
+// timestamp field rather than calling Format directly.
 func FormatTimestamp(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
@@ -195,7 +195,7 @@ func GetWait(opts WaitOptions) (*WaitResponse, int) {
 					return &WaitResponse{
 						RobotResponse: NewErrorResponse(
 							fmt.Errorf("agent error detected in pane '%s'", a.PaneID),
-							"AGENT_ERROR",
+							ErrCodeAgentError,
 							"Check agent output with --robot-tail",
 						),
 						Session:       opts.Session,
@@ -136,7 +136,7 @@ func formatWarningMessage(format string, threshold float64) string {
 func NewWarning(level WarningLevel, session string, pane int, agentType, message, suggestedAction string) Warning {
 	return Warning{
 		Level:           level,
-		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		Timestamp:       FormatTimestamp(time.Now()),
 		Session:         session,
 		Pane:            pane,
 		AgentType:       agentType,
@@ -0,0 +1,596 @@
+// Package robot provides machine-readable output for AI agents.
+// whatif.go implements `ntm robot what-if`, the fleet scaling advisor.
+package robot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/assign"
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/bv"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// Overridable for tests.
+var (
+	whatifSessionExists = tmux.SessionExists
+	whatifGetPanes      = tmux.GetPanes
+	whatifAssignments   = func(session string) []assignment.Assignment {
+		store, err := assignment.LoadStore(session)
+		if err != nil || store == nil {
+			return nil
+		}
+		return store.GetAll()
+	}
+	whatifReadyBeads = func(dir string) int {
+		return len(bv.GetReadyPreview(dir, 1000))
+	}
+)
+
+const (
+	// whatifDefaultWindow is how much history pacing is learned from.
+	whatifDefaultWindow = 24 * time.Hour
+	// whatifDefaultTaskMinutes is the task duration assumed with no history.
+	whatifDefaultTaskMinutes = 30.0
+	// whatifStallPerRateLimit is the work an agent loses to one rate limit.
+	whatifStallPerRateLimit = 5 * time.Minute
+	// whatifMaxStall caps the share of time rate limits can take.
+	whatifMaxStall = 0.9
+)
+
+// WhatIfOptions configures `ntm robot what-if`.
+type WhatIfOptions struct {
+	Session string
+	// Add is the change in agents per type (cc, cod, gmi, ...); negative
+	// counts remove agents.
+	Add map[tmux.AgentType]int
+	// Tasks overrides the remaining work (0 = active assignments plus
+	// ready beads).
+	Tasks int
+	// Window is how much history pacing is learned from (default 24h).
+	Window time.Duration
+	// BudgetUSD overrides the session's hard budget limit.
+	BudgetUSD float64
+	// ProjectDir holds the .ntm cost, budget, and rate-limit state.
+	ProjectDir string
+}
+
+// WhatIfAgentPace is what one agent type is expected to do per hour.
+type WhatIfAgentPace struct {
+	Type           string  `json:"type"`
+	Provider       string  `json:"provider"`
+	Current        int     `json:"current"`
+	Projected      int     `json:"projected"`
+	Completed      int     `json:"completed"`         // Tasks finished in the window
+	AvgTaskMinutes float64 `json:"avg_task_minutes"`  // Assigned to completed
+	TasksPerHour   float64 `json:"tasks_per_hour"`    // Per agent, before rate limits
+	PaceSource     string  `json:"pace_source"`       // history, fleet, or default
+	CostPerHourUSD float64 `json:"cost_per_hour_usd"` // Per agent
+	CostSource     string  `json:"cost_source"`       // tracked, fleet, or none
+}
+
+// WhatIfRateLimit is a provider's rate-limit headroom under the change.
+type WhatIfRateLimit struct {
+	Provider          string  `json:"provider"`
+	Agents            int     `json:"agents"`
+	ProjectedAgents   int     `json:"projected_agents"`
+	EventsInWindow    int     `json:"events_in_window"`
+	EventsPerHour     float64 `json:"events_per_hour"`           // Fleet-wide, now
+	ProjectedPerHour  float64 `json:"projected_events_per_hour"` // Fleet-wide, after the change
+	LearnedDelay      string  `json:"learned_delay,omitempty"`
+	InCooldown        bool    `json:"in_cooldown"`
+	CooldownRemaining string  `json:"cooldown_remaining,omitempty"`
+	Efficiency        float64 `json:"efficiency"`           // Share of time not stalled, now
+	ProjectedEff      float64 `json:"projected_efficiency"` // After the change
+	Risk              string  `json:"risk"`                 // none, low, medium, high
+}
+
+// WhatIfScenario is the projected outcome for one fleet.
+type WhatIfScenario struct {
+	Agents         map[string]int `json:"agents"`
+	TasksPerHour   float64        `json:"tasks_per_hour"`
+	ETAHours       float64        `json:"eta_hours"`
+	CompletesAt    *time.Time     `json:"completes_at,omitempty"`
+	Unfinished     bool           `json:"unfinished,omitempty"` // No agents left to do the work
+	BurnUSDPerHour float64        `json:"burn_usd_per_hour"`
+	CostUSD        float64        `json:"cost_usd"` // To finish the remaining work
+}
+
+// WhatIfBudget compares the projected spend with the session's budget.
+type WhatIfBudget struct {
+	SpentUSD          float64  `json:"spent_usd"`
+	SoftLimitUSD      float64  `json:"soft_limit_usd,omitempty"`
+	HardLimitUSD      float64  `json:"hard_limit_usd,omitempty"`
+	RemainingUSD      float64  `json:"remaining_usd"`
+	CurrentTotalUSD   float64  `json:"current_total_usd"`
+	ProjectedTotalUSD float64  `json:"projected_total_usd"`
+	Exceeds           bool     `json:"exceeds"`      // The change runs past the hard limit
+	ExceedsSoft       bool     `json:"exceeds_soft"` // The change runs past the soft limit
+	RunsOutInHours    *float64 `json:"runs_out_in_hours,omitempty"`
+}
+
+// WhatIfOutput is the response for `ntm robot what-if`.
+type WhatIfOutput struct {
+	RobotResponse
+	Session              string            `json:"session"`
+	Change               map[string]int    `json:"change"`
+	RemainingTasks       int               `json:"remaining_tasks"`
+	RemainingSource      string            `json:"remaining_source"` // flag or assignments+beads
+	Window               string            `json:"window"`
+	ObservedTasksPerHour float64           `json:"observed_tasks_per_hour"`
+	Agents               []WhatIfAgentPace `json:"agents"`
+	RateLimits           []WhatIfRateLimit `json:"rate_limits"`
+	Current              WhatIfScenario    `json:"current"`
+	Projected            WhatIfScenario    `json:"projected"`
+	ETADeltaHours        float64           `json:"eta_delta_hours"`
+	CostDeltaUSD         float64           `json:"cost_delta_usd"`
+	Budget               *WhatIfBudget     `json:"budget,omitempty"`
+	Verdict              string            `json:"verdict"`
+	Assumptions          []string          `json:"assumptions"`
+	Warnings             []string          `json:"warnings"`
+}
+
+// GetWhatIf projects how a change to a session's fleet would affect when
+// its remaining work finishes, what it costs, and how hard each provider's
+// rate limits are hit. Pacing comes from the session's completed
+// assignments, spend from the cost tracker, and rate-limit pressure from
+// the adaptive limiter's history, all over the window.
+func GetWhatIf(opts WhatIfOptions) (*WhatIfOutput, error) {
+	out := &WhatIfOutput{
+		RobotResponse: NewRobotResponse(true),
+		Session:       opts.Session,
+		Change:        map[string]int{},
+		Agents:        []WhatIfAgentPace{},
+		RateLimits:    []WhatIfRateLimit{},
+		Assumptions:   []string{},
+		Warnings:      []string{},
+	}
+	if opts.Session == "" {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session is required"), ErrCodeInvalidFlag, "Pass --session")
+		return out, nil
+	}
+	if len(opts.Add) == 0 {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("no fleet change given"), ErrCodeInvalidFlag,
+			"Pass --cc, --cod, or --gmi with the number of agents to add (negative removes)")
+		return out, nil
+	}
+	if !whatifSessionExists(opts.Session) {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("session '%s' not found", opts.Session), ErrCodeSessionNotFound,
+			"Use 'ntm list' to see available sessions")
+		return out, nil
+	}
+	panes, err := whatifGetPanes(opts.Session)
+	if err != nil {
+		out.RobotResponse = NewErrorResponse(fmt.Errorf("get panes: %w", err), ErrCodeInternalError, "")
+		return out, nil
+	}
+
+	window := opts.Window
+	if window <= 0 {
+		window = whatifDefaultWindow
+	}
+	out.Window = window.String()
+	now := time.Now()
+	cutoff := now.Add(-window)
+
+	current := map[tmux.AgentType]int{}
+	for _, p := range panes {
+		if p.Type == tmux.AgentUser || p.Type == tmux.AgentUnknown || p.Type == "" {
+			continue
+		}
+		current[p.Type]++
+	}
+	projected := map[tmux.AgentType]int{}
+	for t, n := range current {
+		projected[t] = n
+	}
+	for t, delta := range opts.Add {
+		if current[t]+delta < 0 {
+			out.RobotResponse = NewErrorResponse(
+				fmt.Errorf("cannot remove %d %s agents: %d running", -delta, t, current[t]),
+				ErrCodeInvalidFlag, "")
+			return out, nil
+		}
+		projected[t] = current[t] + delta
+		out.Change[string(t)] = delta
+	}
+
+	// Pacing: how long each agent type takes per task, from the window.
+	type paceStats struct {
+		completed int
+		minutes   float64
+	}
+	paces := map[tmux.AgentType]*paceStats{}
+	fleet := &paceStats{}
+	active := 0
+	first := now
+	for _, a := range whatifAssignments(opts.Session) {
+		switch a.Status {
+		case assignment.StatusAssigned, assignment.StatusWorking, assignment.StatusVerifying:
+			active++
+		}
+		if a.AssignedAt.After(cutoff) && a.AssignedAt.Before(first) {
+			first = a.AssignedAt
+		}
+		if a.Status != assignment.StatusCompleted || a.CompletedAt == nil || a.CompletedAt.Before(cutoff) {
+			continue
+		}
+		start := a.AssignedAt
+		if a.StartedAt != nil {
+			start = *a.StartedAt
+		}
+		d := a.CompletedAt.Sub(start)
+		if d <= 0 {
+			continue
+		}
+		t := assign.ParseAgentType(a.AgentType)
+		if paces[t] == nil {
+			paces[t] = &paceStats{}
+		}
+		paces[t].completed++
+		paces[t].minutes += d.Minutes()
+		fleet.completed++
+		fleet.minutes += d.Minutes()
+	}
+	// Rates are per hour of observed history: the window, or less if the
+	// session is younger.
+	observed := now.Sub(first)
+	if observed <= 0 || observed > window {
+		observed = window
+	}
+	hours := math.Max(observed.Hours(), 1.0/60)
+	out.ObservedTasksPerHour = round2(float64(fleet.completed) / hours)
+
+	if opts.Tasks > 0 {
+		out.RemainingTasks = opts.Tasks
+		out.RemainingSource = "flag"
+	} else {
+		out.RemainingTasks = active + whatifReadyBeads(opts.ProjectDir)
+		out.RemainingSource = "assignments+beads"
+		if out.RemainingTasks == 0 {
+			out.Warnings = append(out.Warnings, "no active assignments or ready beads; pass --tasks to size the work")
+		}
+	}
+
+	// Spend per agent-hour, by the model each tracked agent runs.
+	tracker := cost.NewCostTracker(opts.ProjectDir)
+	if opts.ProjectDir != "" {
+		if err := tracker.LoadFromDir(opts.ProjectDir); err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("cost data unavailable: %v", err))
+		}
+	}
+	costPerHour := map[tmux.AgentType]float64{}
+	fleetCostPerHour := 0.0
+	if sc := tracker.GetSession(opts.Session); sc != nil && len(sc.Agents) > 0 {
+		agentHours := math.Max(now.Sub(sc.StartTime).Hours(), 1.0/60)
+		spend := map[tmux.AgentType]float64{}
+		count := map[tmux.AgentType]int{}
+		total := 0.0
+		for _, ac := range sc.Agents {
+			c := ac.Cost()
+			total += c
+			if t := whatifModelAgentType(ac.Model); t != "" {
+				spend[t] += c
+				count[t]++
+			}
+		}
+		for t, c := range spend {
+			costPerHour[t] = c / (float64(count[t]) * agentHours)
+		}
+		fleetCostPerHour = total / (float64(len(sc.Agents)) * agentHours)
+	} else {
+		out.Warnings = append(out.Warnings, "no token usage tracked for this session; cost is not projected")
+	}
+
+	// Per-type pace and cost, for every type in either fleet.
+	types := make([]tmux.AgentType, 0, len(projected))
+	for t := range projected {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	rates := map[tmux.AgentType]float64{}
+	burns := map[tmux.AgentType]float64{}
+	for _, t := range types {
+		pace := WhatIfAgentPace{
+			Type:      string(t),
+			Provider:  ratelimit.NormalizeProvider(string(t)),
+			Current:   current[t],
+			Projected: projected[t],
+		}
+		switch ps := paces[t]; {
+		case ps != nil:
+			pace.Completed = ps.completed
+			pace.AvgTaskMinutes = ps.minutes / float64(ps.completed)
+			pace.PaceSource = "history"
+		case fleet.completed > 0:
+			pace.AvgTaskMinutes = fleet.minutes / float64(fleet.completed)
+			pace.PaceSource = "fleet"
+		default:
+			pace.AvgTaskMinutes = whatifDefaultTaskMinutes
+			pace.PaceSource = "default"
+		}
+		rates[t] = 60 / pace.AvgTaskMinutes
+		pace.AvgTaskMinutes = round2(pace.AvgTaskMinutes)
+		pace.TasksPerHour = round2(rates[t])
+
+		switch c, ok := costPerHour[t]; {
+		case ok:
+			burns[t] = c
+			pace.CostSource = "tracked"
+		case fleetCostPerHour > 0:
+			burns[t] = fleetCostPerHour
+			pace.CostSource = "fleet"
+		default:
+			pace.CostSource = "none"
+		}
+		pace.CostPerHourUSD = roundUSD(burns[t])
+		if projected[t] > current[t] && pace.PaceSource != "history" {
+			out.Assumptions = append(out.Assumptions, fmt.Sprintf(
+				"%s has no completed tasks in the window; assuming %.0f min per task (%s)",
+				t, pace.AvgTaskMinutes, pace.PaceSource))
+		}
+		out.Agents = append(out.Agents, pace)
+	}
+
+	// Rate-limit pressure per provider. Agents of one provider share its
+	// quota, so each added agent also makes every other one hit limits more
+	// often: per-agent events scale with the provider's agent count.
+	limiter := ratelimit.NewRateLimitTracker(opts.ProjectDir)
+	if opts.ProjectDir != "" {
+		if err := limiter.LoadFromDir(opts.ProjectDir); err != nil {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("rate-limit history unavailable: %v", err))
+		}
+	}
+	providerAgents := func(fleet map[tmux.AgentType]int) map[string]int {
+		byProvider := map[string]int{}
+		for t, n := range fleet {
+			byProvider[ratelimit.NormalizeProvider(string(t))] += n
+		}
+		return byProvider
+	}
+	nowByProvider, nextByProvider := providerAgents(current), providerAgents(projected)
+	providers := make([]string, 0, len(nextByProvider))
+	for p := range nextByProvider {
+		if nowByProvider[p] > 0 || nextByProvider[p] > 0 {
+			providers = append(providers, p)
+		}
+	}
+	sort.Strings(providers)
+
+	efficiency := map[string]func(n int) float64{}
+	for _, p := range providers {
+		rl := whatifProviderRateLimits(limiter, p, cutoff, now)
+		n, next := nowByProvider[p], nextByProvider[p]
+		rl.Agents, rl.ProjectedAgents = n, next
+
+		perAgent := float64(rl.EventsInWindow) / (float64(max(n, 1)) * hours)
+		eventsPerAgent := func(k int) float64 { return perAgent * float64(k) / float64(max(n, 1)) }
+		eff := func(k int) float64 {
+			return 1 - math.Min(whatifMaxStall, eventsPerAgent(k)*whatifStallPerRateLimit.Hours())
+		}
+		efficiency[p] = eff
+
+		rl.EventsPerHour = round2(perAgent * float64(n))
+		rl.ProjectedPerHour = round2(eventsPerAgent(next) * float64(next))
+		rl.Efficiency = round2(eff(n))
+		rl.ProjectedEff = round2(eff(next))
+		stall := 1 - rl.ProjectedEff
+		switch {
+		case rl.InCooldown || stall >= 0.25:
+			rl.Risk = "high"
+		case stall >= 0.05 || rl.ProjectedPerHour >= 1:
+			rl.Risk = "medium"
+		case rl.EventsInWindow > 0:
+			rl.Risk = "low"
+		default:
+			rl.Risk = "none"
+		}
+		out.RateLimits = append(out.RateLimits, rl)
+	}
+	out.Assumptions = append(out.Assumptions,
+		fmt.Sprintf("each rate limit costs an agent about %s of work", whatifStallPerRateLimit),
+		"agents of one provider share its quota, so rate limits per agent grow with the provider's agent count",
+		"added agents work at their type's observed pace and spend")
+
+	scenario := func(fleet map[tmux.AgentType]int) WhatIfScenario {
+		s := WhatIfScenario{Agents: map[string]int{}}
+		var throughput, burn float64
+		for t, n := range fleet {
+			s.Agents[string(t)] = n
+			if n == 0 {
+				continue
+			}
+			eff := 1.0
+			if f, ok := efficiency[ratelimit.NormalizeProvider(string(t))]; ok {
+				eff = f(providerAgentCount(fleet, t))
+			}
+			throughput += float64(n) * rates[t] * eff
+			burn += float64(n) * burns[t] * eff
+		}
+		s.TasksPerHour = round2(throughput)
+		s.BurnUSDPerHour = roundUSD(burn)
+		if out.RemainingTasks == 0 {
+			return s
+		}
+		if throughput == 0 {
+			s.Unfinished = true
+			return s
+		}
+		eta := float64(out.RemainingTasks) / throughput
+		s.ETAHours = round2(eta)
+		s.CostUSD = roundUSD(burn * eta)
+		at := now.Add(time.Duration(eta * float64(time.Hour))).UTC()
+		s.CompletesAt = &at
+		return s
+	}
+	out.Current = scenario(current)
+	out.Projected = scenario(projected)
+	out.ETADeltaHours = round2(out.Projected.ETAHours - out.Current.ETAHours)
+	out.CostDeltaUSD = roundUSD(out.Projected.CostUSD - out.Current.CostUSD)
+	if out.Projected.Unfinished {
+		out.Warnings = append(out.Warnings, "the changed fleet has no agents to do the remaining work")
+	}
+
+	out.Budget = whatifBudget(opts, tracker, out.Current, out.Projected)
+	out.Verdict = whatifVerdict(out)
+	return out, nil
+}
+
+// providerAgentCount returns the number of agents sharing t's provider in fleet.
+func providerAgentCount(fleet map[tmux.AgentType]int, t tmux.AgentType) int {
+	provider := ratelimit.NormalizeProvider(string(t))
+	n := 0
+	for other, k := range fleet {
+		if ratelimit.NormalizeProvider(string(other)) == provider {
+			n += k
+		}
+	}
+	return n
+}
+
+// whatifProviderRateLimits sums a provider's rate-limit history since
+// cutoff across its accounts.
+func whatifProviderRateLimits(limiter *ratelimit.RateLimitTracker, provider string, cutoff, now time.Time) WhatIfRateLimit {
+	rl := WhatIfRateLimit{Provider: provider}
+	var delay, cooldown time.Duration
+	for _, key := range limiter.GetAllProviders() {
+		if p, _ := ratelimit.SplitKey(key); p != provider {
+			continue
+		}
+		for _, ev := range limiter.GetRecentEvents(key, 0) {
+			if ev.Time.After(cutoff) {
+				rl.EventsInWindow++
+			}
+		}
+		if st := limiter.GetProviderState(key); st != nil {
+			delay = max(delay, st.CurrentDelay)
+			if st.CooldownUntil.After(now) {
+				cooldown = max(cooldown, st.CooldownUntil.Sub(now))
+			}
+		}
+	}
+	if delay > 0 {
+		rl.LearnedDelay = ratelimit.FormatDelay(delay)
+	}
+	if cooldown > 0 {
+		rl.InCooldown = true
+		rl.CooldownRemaining = ratelimit.FormatDelay(cooldown)
+	}
+	return rl
+}
+
+// whatifBudget compares both scenarios with the session's budget, or nil
+// when it has none.
+func whatifBudget(opts WhatIfOptions, tracker *cost.CostTracker, current, projected WhatIfScenario) *WhatIfBudget {
+	b := &WhatIfBudget{HardLimitUSD: opts.BudgetUSD}
+	guard := cost.NewBudgetGuard()
+	if opts.ProjectDir != "" && guard.LoadFromDir(opts.ProjectDir) == nil {
+		if sb := guard.Get(opts.Session); sb != nil {
+			b.SoftLimitUSD = sb.SoftLimitUSD
+			if b.HardLimitUSD <= 0 {
+				b.HardLimitUSD = sb.EffectiveHardLimit()
+			}
+		}
+	}
+	if b.HardLimitUSD <= 0 && b.SoftLimitUSD <= 0 {
+		return nil
+	}
+	spent := tracker.GetSessionCost(opts.Session)
+	b.SpentUSD = roundUSD(spent)
+	b.CurrentTotalUSD = roundUSD(spent + current.CostUSD)
+	b.ProjectedTotalUSD = roundUSD(spent + projected.CostUSD)
+	if b.HardLimitUSD > 0 {
+		remaining := math.Max(b.HardLimitUSD-spent, 0)
+		b.RemainingUSD = roundUSD(remaining)
+		b.Exceeds = spent+projected.CostUSD > b.HardLimitUSD
+		if projected.BurnUSDPerHour > 0 {
+			h := round2(remaining / projected.BurnUSDPerHour)
+			b.RunsOutInHours = &h
+		}
+	}
+	b.ExceedsSoft = b.SoftLimitUSD > 0 && spent+projected.CostUSD > b.SoftLimitUSD
+	return b
+}
+
+// whatifVerdict sums up the projection in one sentence.
+func whatifVerdict(out *WhatIfOutput) string {
+	types := make([]string, 0, len(out.Change))
+	for t := range out.Change {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	var change []string
+	for _, t := range types {
+		n := out.Change[t]
+		if n >= 0 {
+			change = append(change, fmt.Sprintf("adding %d %s", n, t))
+		} else {
+			change = append(change, fmt.Sprintf("removing %d %s", -n, t))
+		}
+	}
+	verdict := strings.Join(change, " and ")
+	switch {
+	case out.RemainingTasks == 0:
+		return verdict + " changes throughput from " + fmt.Sprintf("%.1f to %.1f tasks/hour; no remaining work is known",
+			out.Current.TasksPerHour, out.Projected.TasksPerHour)
+	case out.Projected.Unfinished:
+		verdict += fmt.Sprintf(" leaves no agents for %d remaining tasks", out.RemainingTasks)
+	case out.Current.Unfinished:
+		verdict += fmt.Sprintf(" finishes %d tasks in %.1fh", out.RemainingTasks, out.Projected.ETAHours)
+	default:
+		verdict += fmt.Sprintf(" finishes %d tasks in %.1fh instead of %.1fh (%+.1fh)",
+			out.RemainingTasks, out.Projected.ETAHours, out.Current.ETAHours, out.ETADeltaHours)
+	}
+	if out.Projected.CostUSD > 0 || out.Current.CostUSD > 0 {
+		sign := "+"
+		if out.CostDeltaUSD < 0 {
+			sign = "-"
+		}
+		verdict += fmt.Sprintf(", %s to finish (%s%s)", cost.FormatCost(out.Projected.CostUSD), sign, cost.FormatCost(math.Abs(out.CostDeltaUSD)))
+	}
+	for _, rl := range out.RateLimits {
+		if rl.Risk == "medium" || rl.Risk == "high" {
+			verdict += fmt.Sprintf("; %s rate-limit risk %s", rl.Provider, rl.Risk)
+		}
+	}
+	if out.Budget != nil && out.Budget.Exceeds {
+		verdict += "; exceeds the budget"
+	}
+	return verdict
+}
+
+// whatifModelAgentType maps a tracked model name to the agent type that
+// runs it, or "" if unknown.
+func whatifModelAgentType(model string) tmux.AgentType {
+	m := strings.ToLower(model)
+	switch {
+	case strings.Contains(m, "claude"), strings.Contains(m, "opus"), strings.Contains(m, "sonnet"), strings.Contains(m, "haiku"):
+		return tmux.AgentClaude
+	case strings.Contains(m, "gpt"), strings.Contains(m, "codex"), strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"), strings.HasPrefix(m, "o4"):
+		return tmux.AgentCodex
+	case strings.Contains(m, "gemini"):
+		return tmux.AgentGemini
+	default:
+		return ""
+	}
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// PrintWhatIf outputs the what-if projection.
+func PrintWhatIf(opts WhatIfOptions) error {
+	out, err := GetWhatIf(opts)
+	if err != nil {
+		return err
+	}
+	return encodeJSON(out)
+}
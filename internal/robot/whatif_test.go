@@ -0,0 +1,181 @@
+package robot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/assignment"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/ratelimit"
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+func stubWhatIf(t *testing.T, panes []tmux.Pane, assignments []assignment.Assignment, ready int) {
+	t.Helper()
+	oldExists, oldPanes, oldAssignments, oldReady := whatifSessionExists, whatifGetPanes, whatifAssignments, whatifReadyBeads
+	t.Cleanup(func() {
+		whatifSessionExists, whatifGetPanes, whatifAssignments, whatifReadyBeads = oldExists, oldPanes, oldAssignments, oldReady
+	})
+	whatifSessionExists = func(string) bool { return true }
+	whatifGetPanes = func(string) ([]tmux.Pane, error) { return panes, nil }
+	whatifAssignments = func(string) []assignment.Assignment { return assignments }
+	whatifReadyBeads = func(string) int { return ready }
+}
+
+func completedAssignment(agentType string, assigned time.Time, took time.Duration) assignment.Assignment {
+	done := assigned.Add(took)
+	return assignment.Assignment{
+		AgentType:   agentType,
+		Status:      assignment.StatusCompleted,
+		AssignedAt:  assigned,
+		CompletedAt: &done,
+	}
+}
+
+func TestGetWhatIf(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+
+	limiter := ratelimit.NewRateLimitTracker(dir)
+	for i := 0; i < 3; i++ {
+		limiter.RecordRateLimit("openai", "send")
+	}
+	if err := limiter.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	tracker := cost.NewCostTracker(dir)
+	tracker.RecordTokens("proj", "%2", "claude-sonnet-4", 100000, 20000)
+	tracker.RecordTokens("proj", "%4", "gpt-5-codex", 100000, 20000)
+	if err := tracker.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	guard := cost.NewBudgetGuard()
+	if err := guard.SetLimits("proj", 0, 0.01, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.SaveToDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	stubWhatIf(t, []tmux.Pane{
+		{Index: 1, Type: tmux.AgentUser},
+		{Index: 2, Type: tmux.AgentClaude},
+		{Index: 3, Type: tmux.AgentClaude},
+		{Index: 4, Type: tmux.AgentCodex},
+	}, []assignment.Assignment{
+		completedAssignment("claude", now.Add(-3*time.Hour), 30*time.Minute),
+		completedAssignment("claude", now.Add(-2*time.Hour), 30*time.Minute),
+		completedAssignment("codex", now.Add(-2*time.Hour), time.Hour),
+		completedAssignment("codex", now.Add(-48*time.Hour), 10*time.Minute), // Outside the window
+		{AgentType: "claude", Status: assignment.StatusWorking, AssignedAt: now.Add(-10 * time.Minute)},
+		{AgentType: "codex", Status: assignment.StatusWorking, AssignedAt: now.Add(-10 * time.Minute)},
+	}, 8)
+
+	out, err := GetWhatIf(WhatIfOptions{
+		Session:    "proj",
+		Add:        map[tmux.AgentType]int{tmux.AgentCodex: 2},
+		ProjectDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success {
+		t.Fatalf("what-if failed: %s", out.Error)
+	}
+	if out.RemainingTasks != 10 || out.RemainingSource != "assignments+beads" {
+		t.Errorf("remaining = %d (%s), want 10 from assignments+beads", out.RemainingTasks, out.RemainingSource)
+	}
+	if len(out.Agents) != 2 {
+		t.Fatalf("agents = %+v, want cc and cod", out.Agents)
+	}
+	cc, cod := out.Agents[0], out.Agents[1]
+	if cc.Type != "cc" || cc.AvgTaskMinutes != 30 || cc.PaceSource != "history" || cc.CostSource != "tracked" {
+		t.Errorf("cc pace = %+v", cc)
+	}
+	if cod.Type != "cod" || cod.Current != 1 || cod.Projected != 3 || cod.AvgTaskMinutes != 60 {
+		t.Errorf("cod pace = %+v, want 1 -> 3 at 60 min/task", cod)
+	}
+
+	if len(out.RateLimits) != 2 {
+		t.Fatalf("rate limits = %+v", out.RateLimits)
+	}
+	openai := out.RateLimits[1]
+	// 3 limits in 3h on one agent: 1/h each, 3/h each with three agents,
+	// and 5 minutes lost per limit.
+	if openai.Provider != "openai" || openai.EventsInWindow != 3 || openai.Risk != "high" {
+		t.Errorf("openai = %+v, want 3 events and high risk", openai)
+	}
+	if openai.ProjectedEff < 0.74 || openai.ProjectedEff > 0.76 || openai.Efficiency < 0.91 || openai.Efficiency > 0.92 {
+		t.Errorf("openai efficiency = %v -> %v, want 0.92 -> 0.75", openai.Efficiency, openai.ProjectedEff)
+	}
+	if out.RateLimits[0].Risk != "none" {
+		t.Errorf("anthropic risk = %s, want none", out.RateLimits[0].Risk)
+	}
+
+	// cc: 2 agents x 2/h; cod: 1 x 1/h x 0.92, then 3 x 1/h x 0.75.
+	if out.Current.TasksPerHour < 4.9 || out.Current.TasksPerHour > 4.93 {
+		t.Errorf("current throughput = %v, want ~4.92", out.Current.TasksPerHour)
+	}
+	if out.Projected.TasksPerHour < 6.24 || out.Projected.TasksPerHour > 6.26 {
+		t.Errorf("projected throughput = %v, want ~6.25", out.Projected.TasksPerHour)
+	}
+	if out.ETADeltaHours >= 0 || out.Projected.CompletesAt == nil {
+		t.Errorf("projection = %+v, want an earlier finish", out.Projected)
+	}
+	if out.CostDeltaUSD <= 0 {
+		t.Errorf("cost delta = %v, want more spend with more agents", out.CostDeltaUSD)
+	}
+	if out.Budget == nil || !out.Budget.Exceeds {
+		t.Errorf("budget = %+v, want exceeded", out.Budget)
+	}
+	for _, want := range []string{"adding 2 cod", "10 tasks", "openai rate-limit risk high", "exceeds the budget"} {
+		if !strings.Contains(out.Verdict, want) {
+			t.Errorf("verdict %q missing %q", out.Verdict, want)
+		}
+	}
+}
+
+func TestGetWhatIfDefaultsWithoutHistory(t *testing.T) {
+	stubWhatIf(t, []tmux.Pane{{Index: 1, Type: tmux.AgentClaude}}, nil, 0)
+
+	out, err := GetWhatIf(WhatIfOptions{
+		Session: "proj",
+		Add:     map[tmux.AgentType]int{tmux.AgentGemini: 1},
+		Tasks:   6,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success {
+		t.Fatalf("what-if failed: %s", out.Error)
+	}
+	for _, a := range out.Agents {
+		if a.PaceSource != "default" || a.AvgTaskMinutes != whatifDefaultTaskMinutes {
+			t.Errorf("%s pace = %+v, want the default", a.Type, a)
+		}
+	}
+	if out.Current.ETAHours != 3 || out.Projected.ETAHours != 1.5 {
+		t.Errorf("eta = %v -> %v, want 3h -> 1.5h", out.Current.ETAHours, out.Projected.ETAHours)
+	}
+	if out.Budget != nil || len(out.Warnings) == 0 {
+		t.Errorf("want no budget and a cost warning, got %+v / %v", out.Budget, out.Warnings)
+	}
+}
+
+func TestGetWhatIfRejectsRemovingMissingAgents(t *testing.T) {
+	stubWhatIf(t, []tmux.Pane{{Index: 1, Type: tmux.AgentCodex}}, nil, 0)
+
+	out, err := GetWhatIf(WhatIfOptions{Session: "proj", Add: map[tmux.AgentType]int{tmux.AgentCodex: -2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Success || out.ErrorCode != ErrCodeInvalidFlag {
+		t.Errorf("removing more agents than run = %+v, want invalid flag", out.RobotResponse)
+	}
+
+	out, _ = GetWhatIf(WhatIfOptions{Session: "proj", Add: map[tmux.AgentType]int{tmux.AgentCodex: -1}, Tasks: 5})
+	if !out.Success || !out.Projected.Unfinished || !strings.Contains(out.Verdict, "leaves no agents") {
+		t.Errorf("removing every agent = %+v / %q", out.Projected, out.Verdict)
+	}
+}
@@ -0,0 +1,151 @@
+// Package robot provides machine-readable output for AI agents and automation.
+// workspace.go maps conflict detection over several repositories: the
+// primary repository, its git submodules, and extra workspace roots.
+package robot
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WorkspaceRepo is one repository scanned by conflict detection.
+type WorkspaceRepo struct {
+	// Name namespaces the repository's paths as "name:path". It is empty
+	// for the primary repository, whose paths are not namespaced.
+	Name string `json:"name"`
+	// Path is the repository's absolute root.
+	Path string `json:"path"`
+	// Prefix is the repository's root relative to the primary one, or ""
+	// when it lies outside of it.
+	Prefix string `json:"prefix,omitempty"`
+	// Submodule is set for git submodules.
+	Submodule bool `json:"submodule,omitempty"`
+}
+
+// qualify namespaces a repository-relative path.
+func (r WorkspaceRepo) qualify(path string) string {
+	if r.Name == "" {
+		return path
+	}
+	return r.Name + ":" + path
+}
+
+// splitRepoPath splits a namespaced "repo:path" into its parts. Paths of
+// the primary repository have no repo.
+func splitRepoPath(p string) (repo, path string) {
+	if repo, path, ok := strings.Cut(p, ":"); ok && repo != "" && !strings.ContainsAny(repo, "*?[") {
+		return repo, path
+	}
+	return "", p
+}
+
+// discoverWorkspace lists the repositories of a workspace: primary, the
+// extra roots, and, unless skipSubmodules is set, the initialized
+// submodules of each, recursively. Extra roots inside primary are named by
+// their relative path, others by their directory name.
+func discoverWorkspace(primary string, extra []string, skipSubmodules bool) []WorkspaceRepo {
+	primary = filepath.Clean(primary)
+	repos := []WorkspaceRepo{{Path: primary}}
+	seen := map[string]bool{primary: true}
+	names := map[string]bool{"": true}
+
+	add := func(repo WorkspaceRepo) {
+		repo.Path = filepath.Clean(repo.Path)
+		if seen[repo.Path] {
+			return
+		}
+		for base, n := repo.Name, 2; names[repo.Name]; n++ {
+			repo.Name = base + "-" + strconv.Itoa(n)
+		}
+		seen[repo.Path] = true
+		names[repo.Name] = true
+		repos = append(repos, repo)
+	}
+
+	for _, root := range extra {
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(primary, root)
+		}
+		repo := WorkspaceRepo{Path: root, Name: filepath.Base(root)}
+		if prefix := workspacePrefix(primary, root); prefix != "" {
+			repo.Name, repo.Prefix = prefix, prefix
+		}
+		add(repo)
+	}
+
+	if !skipSubmodules {
+		// repos grows as submodules are found, so theirs are scanned too.
+		for i := 0; i < len(repos); i++ {
+			parent := repos[i]
+			for _, sub := range gitSubmodules(parent.Path) {
+				root := filepath.Join(parent.Path, filepath.FromSlash(sub))
+				repo := WorkspaceRepo{Path: root, Submodule: true, Prefix: workspacePrefix(primary, root)}
+				repo.Name = repo.Prefix
+				if repo.Name == "" {
+					repo.Name = parent.qualify(sub)
+				}
+				add(repo)
+			}
+		}
+	}
+	return repos
+}
+
+// workspacePrefix returns root relative to primary, or "" if it lies
+// outside of it.
+func workspacePrefix(primary, root string) string {
+	rel, err := filepath.Rel(primary, root)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// gitSubmodules returns the paths of the initialized submodules of the
+// repository at dir. Gitlinks are listed by ls-files with mode 160000;
+// uninitialized ones have no checkout to scan.
+func gitSubmodules(dir string) []string {
+	cmd := exec.Command("git", "-C", dir, "ls-files", "--stage", "-z")
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var subs []string
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// Format: <mode> <object> <stage>\t<path>
+		meta, path, ok := strings.Cut(entry, "\t")
+		if !ok || !strings.HasPrefix(meta, "160000 ") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(path), ".git")); err == nil {
+			subs = append(subs, path)
+		}
+	}
+	sort.Strings(subs)
+	return subs
+}
+
+// repoFor returns the repository holding the absolute path p: the one
+// with the deepest root containing it. ok is false when p lies outside
+// the workspace.
+func repoFor(repos []WorkspaceRepo, p string) (repo WorkspaceRepo, rel string, ok bool) {
+	best := -1
+	for i, r := range repos {
+		candidate, err := filepath.Rel(r.Path, p)
+		if err != nil || candidate == ".." || strings.HasPrefix(candidate, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if best < 0 || len(r.Path) > len(repos[best].Path) {
+			best, rel = i, candidate
+		}
+	}
+	if best < 0 {
+		return WorkspaceRepo{}, "", false
+	}
+	return repos[best], filepath.ToSlash(filepath.Clean(rel)), true
+}
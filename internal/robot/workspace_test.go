@@ -0,0 +1,136 @@
+package robot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t", "-c", "protocol.file.allow=always"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeRepoFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// initWorkspace creates a primary repository with a submodule at libs/core
+// and a sibling repository "api".
+func initWorkspace(t *testing.T) (primary, api string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	primary, api = filepath.Join(root, "app"), filepath.Join(root, "api")
+	core := filepath.Join(root, "core")
+	for _, dir := range []string{primary, api, core} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, dir, "init", "-q")
+		writeRepoFile(t, dir, "README.md", "init\n")
+		runGit(t, dir, "add", ".")
+		runGit(t, dir, "commit", "-qm", "init")
+	}
+	runGit(t, primary, "submodule", "add", "-q", core, "libs/core")
+	runGit(t, primary, "commit", "-qm", "add core")
+	return primary, api
+}
+
+func TestConflictDetectorWorkspace(t *testing.T) {
+	primary, api := initWorkspace(t)
+	writeRepoFile(t, primary, "main.go", "package main\n")
+	writeRepoFile(t, primary, "libs/core/core.go", "package core\n")
+	writeRepoFile(t, api, "handler.go", "package api\n")
+
+	cd := NewConflictDetector(&ConflictDetectorConfig{RepoPath: primary, Repos: []string{"../api"}})
+	files, err := cd.GetGitStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	slices.Sort(paths)
+	// The submodule's own entry in the primary repository is replaced by
+	// its files.
+	if want := []string{"api:handler.go", "libs/core:core.go", "main.go"}; !slices.Equal(paths, want) {
+		t.Fatalf("paths = %v, want %v", paths, want)
+	}
+
+	repos := cd.Repos()
+	if len(repos) != 3 || repos[1].Name != "api" || repos[1].Prefix != "" || repos[2].Name != "libs/core" || !repos[2].Submodule {
+		t.Errorf("repos = %+v", repos)
+	}
+	if got := cd.AbsPath("libs/core:core.go"); got != filepath.Join(primary, "libs", "core", "core.go") {
+		t.Errorf("AbsPath = %q", got)
+	}
+
+	// Tool calls are attributed by the repository holding the file.
+	cd.RecordToolCalls("%1", []agent.ToolCall{{Kind: agent.ToolEdit, Files: []string{filepath.Join(primary, "libs/core/core.go")}}})
+	cd.RecordToolCalls("%2", []agent.ToolCall{{Kind: agent.ToolWrite, Files: []string{filepath.Join(api, "handler.go")}}})
+	if !cd.toolEdits["%1"]["libs/core:core.go"] || !cd.toolEdits["%2"]["api:handler.go"] {
+		t.Errorf("toolEdits = %v", cd.toolEdits)
+	}
+
+	// Reservations match the namespaced path or the path in the project.
+	reservations := []agentmail.FileReservation{
+		{AgentName: "BlueLake", PathPattern: "libs/core/**", ExpiresTS: agentmail.FlexTime{Time: time.Now().Add(time.Hour)}},
+		{AgentName: "GreenHill", PathPattern: "api:*.go", ExpiresTS: agentmail.FlexTime{Time: time.Now().Add(time.Hour)}},
+	}
+	if got := cd.findReservationHolders("libs/core:core.go", reservations); !slices.Equal(got, []string{"BlueLake"}) {
+		t.Errorf("submodule holders = %v", got)
+	}
+	if got := cd.findReservationHolders("api:handler.go", reservations); !slices.Equal(got, []string{"GreenHill"}) {
+		t.Errorf("api holders = %v", got)
+	}
+
+	// Without submodule scanning the submodule is one changed entry.
+	cd = NewConflictDetector(&ConflictDetectorConfig{RepoPath: primary, SkipSubmodules: true})
+	files, err = cd.GetGitStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = paths[:0]
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	slices.Sort(paths)
+	if want := []string{"libs/core", "main.go"}; !slices.Equal(paths, want) {
+		t.Errorf("skip submodules paths = %v, want %v", paths, want)
+	}
+}
+
+func TestSplitRepoPath(t *testing.T) {
+	tests := []struct{ in, repo, path string }{
+		{"main.go", "", "main.go"},
+		{"api:handler.go", "api", "handler.go"},
+		{"libs/core:src/a.go", "libs/core", "src/a.go"},
+		{"*:x.go", "", "*:x.go"},
+	}
+	for _, tt := range tests {
+		if repo, path := splitRepoPath(tt.in); repo != tt.repo || path != tt.path {
+			t.Errorf("splitRepoPath(%q) = %q, %q", tt.in, repo, path)
+		}
+	}
+}
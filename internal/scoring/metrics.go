@@ -40,7 +40,10 @@ package scoring
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // MetricName identifies a specific effectiveness metric.
@@ -330,6 +333,29 @@ type WeightsConfig struct {
 
 	// TaskTypeWeights maps task types to specific weights
 	TaskTypeWeights map[string]Weights `json:"task_types,omitempty"`
+
+	// QualityWeights maps quality evaluator names to weights (see NewQualityScorer)
+	QualityWeights map[string]float64 `json:"quality_weights,omitempty"`
+}
+
+// DefaultWeightsPath is where the weight configuration used when recording
+// scores is read from.
+const DefaultWeightsPath = "~/.config/ntm/analytics/weights.json"
+
+// LoadDefaultWeightsConfig loads DefaultWeightsPath. A missing file, or one
+// without default weights, falls back to DefaultWeights.
+func LoadDefaultWeightsConfig() (*WeightsConfig, error) {
+	config, err := LoadWeightsConfig(util.ExpandPath(DefaultWeightsPath))
+	if errors.Is(err, os.ErrNotExist) {
+		config, err = &WeightsConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if config.Default.Sum() == 0 {
+		config.Default = DefaultWeights()
+	}
+	return config, nil
 }
 
 // LoadWeightsConfig loads weight configuration from a JSON file.
@@ -394,6 +420,13 @@ type RawMetrics struct {
 
 	// AvgContextUsage is average context utilization (0-1)
 	AvgContextUsage float64 `json:"avg_context_usage,omitempty"`
+
+	// AvgResponseLatencyMs is the mean time from prompt to first output,
+	// from paired prompt exchanges
+	AvgResponseLatencyMs int `json:"avg_response_latency_ms,omitempty"`
+
+	// BaselineLatencyMs is the expected response latency for the task type
+	BaselineLatencyMs int `json:"baseline_latency_ms,omitempty"`
 }
 
 // ToEffectivenessScore converts raw metrics to a normalized effectiveness score.
@@ -416,6 +449,11 @@ func (r *RawMetrics) ToEffectivenessScore(w Weights) *EffectivenessScore {
 		score.TimeEfficiency = min(1, float64(r.EstimatedMinutes)/float64(r.ActualMinutes))
 	}
 
+	// Without task durations, fall back to prompt response latency
+	if score.TimeEfficiency == 0 && r.BaselineLatencyMs > 0 && r.AvgResponseLatencyMs > 0 {
+		score.TimeEfficiency = min(1, float64(r.BaselineLatencyMs)/float64(r.AvgResponseLatencyMs))
+	}
+
 	// Token efficiency
 	if r.BaselineTokens > 0 && r.ActualTokens > 0 {
 		score.TokenEfficiency = min(1, float64(r.BaselineTokens)/float64(r.ActualTokens))
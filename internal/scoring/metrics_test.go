@@ -251,6 +251,34 @@ func TestLoadWeightsConfig(t *testing.T) {
 	}
 }
 
+func TestLoadDefaultWeightsConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	config, err := LoadDefaultWeightsConfig()
+	if err != nil {
+		t.Fatalf("missing file: %v", err)
+	}
+	if config.Default != DefaultWeights() || config.QualityWeights != nil {
+		t.Errorf("missing file should give the defaults, got %+v", config)
+	}
+
+	path := filepath.Join(home, ".config", "ntm", "analytics", "weights.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"quality_weights": {"test_pass_rate": 1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err = LoadDefaultWeightsConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Default != DefaultWeights() || config.QualityWeights["test_pass_rate"] != 1 {
+		t.Errorf("config = %+v, want the quality weights with default weights", config)
+	}
+}
+
 func TestWeightsConfig_GetWeights(t *testing.T) {
 	config := &WeightsConfig{
 		Default: DefaultWeights(),
@@ -304,6 +332,17 @@ func TestRawMetrics_EdgeCases(t *testing.T) {
 			t.Errorf("TimeEfficiency with zero duration = %v, want 0", score.TimeEfficiency)
 		}
 	})
+
+	t.Run("response latency fallback", func(t *testing.T) {
+		raw := RawMetrics{
+			AvgResponseLatencyMs: 4000,
+			BaselineLatencyMs:    2000,
+		}
+		score := raw.ToEffectivenessScore(DefaultWeights())
+		if score.TimeEfficiency != 0.5 {
+			t.Errorf("TimeEfficiency from latency = %v, want 0.5", score.TimeEfficiency)
+		}
+	})
 }
 
 // =============================================================================
@@ -0,0 +1,340 @@
+package scoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// QualitySignals holds the objective measurements quality evaluators work from.
+// Callers fill in whatever they have; evaluators skip themselves when the
+// signals they need are missing.
+type QualitySignals struct {
+	// TestsBefore/TestsAfter are test pass rates (0-1) before and after the
+	// agent's commits. Negative means not measured.
+	TestsBefore float64 `json:"tests_before"`
+	TestsAfter  float64 `json:"tests_after"`
+
+	// LintBefore/LintAfter are lint warning counts. Negative means not measured.
+	LintBefore int `json:"lint_before"`
+	LintAfter  int `json:"lint_after"`
+
+	// LinesAdded/LinesRemoved/FilesChanged describe the diff churn.
+	LinesAdded   int `json:"lines_added"`
+	LinesRemoved int `json:"lines_removed"`
+	FilesChanged int `json:"files_changed"`
+
+	// Diff is the unified diff of the agent's work, used by review evaluators.
+	Diff string `json:"-"`
+}
+
+// NewQualitySignals returns signals with all optional measurements marked as
+// not measured.
+func NewQualitySignals() *QualitySignals {
+	return &QualitySignals{
+		TestsBefore: -1,
+		TestsAfter:  -1,
+		LintBefore:  -1,
+		LintAfter:   -1,
+	}
+}
+
+// QualityEvaluator computes a 0-1 quality component from objective signals.
+type QualityEvaluator interface {
+	// Name returns the evaluator identifier used for weights and reporting.
+	Name() string
+
+	// Evaluate returns the component score and whether the signals were
+	// sufficient to produce one.
+	Evaluate(ctx context.Context, s *QualitySignals) (score float64, ok bool, err error)
+}
+
+// TestPassRateEvaluator scores the change in test pass rate. Holding steady
+// at pass rate r scores 0.75r+0.25; improvements push toward 1 and any
+// regression drops the score sharply. Without a baseline the final pass rate
+// is used as-is.
+type TestPassRateEvaluator struct{}
+
+// Name returns the evaluator name.
+func (TestPassRateEvaluator) Name() string { return "test_pass_rate" }
+
+// Evaluate scores the test pass rate delta.
+func (TestPassRateEvaluator) Evaluate(_ context.Context, s *QualitySignals) (float64, bool, error) {
+	if s.TestsAfter < 0 {
+		return 0, false, nil
+	}
+	if s.TestsBefore < 0 {
+		return clamp01(s.TestsAfter), true, nil
+	}
+	delta := s.TestsAfter - s.TestsBefore
+	return clamp01(0.75*s.TestsAfter + delta + 0.25*boolFloat(delta >= 0)), true, nil
+}
+
+// LintDeltaEvaluator scores the change in lint warnings. No new warnings
+// scores 1; each new warning costs PenaltyPerWarning.
+type LintDeltaEvaluator struct {
+	PenaltyPerWarning float64 // default 0.1
+}
+
+// Name returns the evaluator name.
+func (LintDeltaEvaluator) Name() string { return "lint_delta" }
+
+// Evaluate scores the lint warning delta.
+func (e LintDeltaEvaluator) Evaluate(_ context.Context, s *QualitySignals) (float64, bool, error) {
+	if s.LintBefore < 0 || s.LintAfter < 0 {
+		return 0, false, nil
+	}
+	penalty := e.PenaltyPerWarning
+	if penalty <= 0 {
+		penalty = 0.1
+	}
+	added := s.LintAfter - s.LintBefore
+	if added <= 0 {
+		return 1, true, nil
+	}
+	return clamp01(1 - float64(added)*penalty), true, nil
+}
+
+// DiffChurnEvaluator penalises oversized diffs, which correlate with
+// unfocused changes. Diffs up to TargetLines score 1, decaying to 0 at
+// MaxLines.
+type DiffChurnEvaluator struct {
+	TargetLines int // default 200
+	MaxLines    int // default 2000
+}
+
+// Name returns the evaluator name.
+func (DiffChurnEvaluator) Name() string { return "diff_churn" }
+
+// Evaluate scores the diff size.
+func (e DiffChurnEvaluator) Evaluate(_ context.Context, s *QualitySignals) (float64, bool, error) {
+	churn := s.LinesAdded + s.LinesRemoved
+	if churn == 0 && s.FilesChanged == 0 {
+		return 0, false, nil
+	}
+	target, max := e.TargetLines, e.MaxLines
+	if target <= 0 {
+		target = 200
+	}
+	if max <= target {
+		max = 2000
+	}
+	if churn <= target {
+		return 1, true, nil
+	}
+	return clamp01(1 - float64(churn-target)/float64(max-target)), true, nil
+}
+
+// JudgeFunc scores a diff against a rubric, returning a value in 0-1.
+// It is typically backed by an LLM.
+type JudgeFunc func(ctx context.Context, rubric, diff string) (float64, error)
+
+// DefaultReviewRubric is the rubric passed to the judge when none is set.
+const DefaultReviewRubric = `Score the following change from 0 to 1 for correctness, readability, test coverage, and adherence to the surrounding code style. Reply with only the number.`
+
+// ReviewScoreEvaluator delegates to an LLM judge with a rubric.
+type ReviewScoreEvaluator struct {
+	Judge  JudgeFunc
+	Rubric string
+}
+
+// Name returns the evaluator name.
+func (ReviewScoreEvaluator) Name() string { return "review_score" }
+
+// Evaluate asks the judge to score the diff.
+func (e ReviewScoreEvaluator) Evaluate(ctx context.Context, s *QualitySignals) (float64, bool, error) {
+	if e.Judge == nil || strings.TrimSpace(s.Diff) == "" {
+		return 0, false, nil
+	}
+	rubric := e.Rubric
+	if rubric == "" {
+		rubric = DefaultReviewRubric
+	}
+	score, err := e.Judge(ctx, rubric, s.Diff)
+	if err != nil {
+		return 0, false, fmt.Errorf("review judge: %w", err)
+	}
+	return clamp01(score), true, nil
+}
+
+// DefaultQualityWeights returns the default weights for the built-in evaluators.
+func DefaultQualityWeights() map[string]float64 {
+	return map[string]float64{
+		"test_pass_rate": 0.45,
+		"lint_delta":     0.20,
+		"diff_churn":     0.10,
+		"review_score":   0.25,
+	}
+}
+
+// QualityComponent is one evaluator's contribution to a QualityResult.
+type QualityComponent struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// QualityResult is the combined quality score and its breakdown.
+type QualityResult struct {
+	// Score is the weighted quality (0-1) over evaluators that produced a value
+	Score float64 `json:"score"`
+
+	// HasData is false when no evaluator had enough signal
+	HasData bool `json:"has_data"`
+
+	// Components lists each evaluator that ran, sorted by name
+	Components []QualityComponent `json:"components"`
+}
+
+// QualityScorer combines evaluators with configurable weights.
+type QualityScorer struct {
+	mu         sync.RWMutex
+	evaluators map[string]QualityEvaluator
+	weights    map[string]float64
+}
+
+// NewQualityScorer creates a scorer with the built-in test, lint and churn
+// evaluators. Nil weights uses DefaultQualityWeights. The review evaluator is
+// only active once registered with a judge via Register.
+func NewQualityScorer(weights map[string]float64) *QualityScorer {
+	if weights == nil {
+		weights = DefaultQualityWeights()
+	}
+	q := &QualityScorer{
+		evaluators: make(map[string]QualityEvaluator),
+		weights:    make(map[string]float64, len(weights)),
+	}
+	for k, v := range weights {
+		q.weights[k] = v
+	}
+	q.Register(TestPassRateEvaluator{})
+	q.Register(LintDeltaEvaluator{})
+	q.Register(DiffChurnEvaluator{})
+	return q
+}
+
+// Register adds or replaces an evaluator by name.
+func (q *QualityScorer) Register(e QualityEvaluator) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.evaluators[e.Name()] = e
+}
+
+// SetWeight sets the weight for an evaluator. Zero disables it.
+func (q *QualityScorer) SetWeight(name string, weight float64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.weights[name] = weight
+}
+
+// Evaluate runs every weighted evaluator and combines the results. Weights
+// are renormalised over evaluators that produced a score, so missing signals
+// don't drag the result toward zero. Evaluator errors are recorded in the
+// breakdown rather than failing the whole evaluation.
+func (q *QualityScorer) Evaluate(ctx context.Context, s *QualitySignals) *QualityResult {
+	q.mu.RLock()
+	names := make([]string, 0, len(q.evaluators))
+	for name := range q.evaluators {
+		if q.weights[name] > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	evaluators := make([]QualityEvaluator, len(names))
+	weights := make([]float64, len(names))
+	for i, name := range names {
+		evaluators[i] = q.evaluators[name]
+		weights[i] = q.weights[name]
+	}
+	q.mu.RUnlock()
+
+	result := &QualityResult{}
+	var weighted, totalWeight float64
+	for i, e := range evaluators {
+		score, ok, err := e.Evaluate(ctx, s)
+		if err != nil {
+			result.Components = append(result.Components, QualityComponent{Name: names[i], Weight: weights[i], Error: err.Error()})
+			continue
+		}
+		if !ok {
+			continue
+		}
+		result.Components = append(result.Components, QualityComponent{Name: names[i], Score: score, Weight: weights[i]})
+		weighted += score * weights[i]
+		totalWeight += weights[i]
+	}
+
+	if totalWeight > 0 {
+		result.Score = weighted / totalWeight
+		result.HasData = true
+	}
+	return result
+}
+
+// ApplyTo sets m.Quality from the result when it has data and recomputes Overall.
+func (r *QualityResult) ApplyTo(m *ScoreMetrics) {
+	if r == nil || !r.HasData {
+		return
+	}
+	m.Quality = r.Score
+	m.ComputeOverall()
+}
+
+// CollectDiffSignals fills churn and diff fields of s from `git diff` between
+// two refs in repoDir.
+func CollectDiffSignals(ctx context.Context, repoDir, fromRef, toRef string, s *QualitySignals) error {
+	rangeSpec := fromRef + ".." + toRef
+
+	cmd := exec.CommandContext(ctx, "git", "-C", repoDir, "diff", "--numstat", rangeSpec)
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git diff --numstat: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		s.FilesChanged++
+		// Binary files report "-" for both counts.
+		if added, err := strconv.Atoi(fields[0]); err == nil {
+			s.LinesAdded += added
+		}
+		if removed, err := strconv.Atoi(fields[1]); err == nil {
+			s.LinesRemoved += removed
+		}
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "-C", repoDir, "diff", rangeSpec)
+	diff, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git diff: %w", err)
+	}
+	s.Diff = string(diff)
+	return nil
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+package scoring
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestTestPassRateEvaluator(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after float64
+		want          float64
+		ok            bool
+	}{
+		{"not measured", -1, -1, 0, false},
+		{"no baseline", -1, 0.9, 0.9, true},
+		{"steady full pass", 1, 1, 1, true},
+		{"improved", 0.8, 0.9, 1, true},
+		{"regressed", 1, 0.8, 0.4, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewQualitySignals()
+			s.TestsBefore, s.TestsAfter = tc.before, tc.after
+			got, ok, err := TestPassRateEvaluator{}.Evaluate(context.Background(), s)
+			if err != nil {
+				t.Fatalf("Evaluate() error: %v", err)
+			}
+			if ok != tc.ok || math.Abs(got-tc.want) > 0.001 {
+				t.Errorf("Evaluate() = %v, %v; want %v, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
+
+func TestLintAndChurnEvaluators(t *testing.T) {
+	s := NewQualitySignals()
+	s.LintBefore, s.LintAfter = 4, 7
+	if got, ok, _ := (LintDeltaEvaluator{}).Evaluate(context.Background(), s); !ok || math.Abs(got-0.7) > 0.001 {
+		t.Errorf("lint delta = %v, %v; want 0.7, true", got, ok)
+	}
+	s.LintAfter = 2
+	if got, _, _ := (LintDeltaEvaluator{}).Evaluate(context.Background(), s); got != 1 {
+		t.Errorf("lint improvement = %v, want 1", got)
+	}
+
+	s.LinesAdded, s.LinesRemoved, s.FilesChanged = 1000, 100, 5
+	// (1100-200)/(2000-200) = 0.5 penalty
+	if got, ok, _ := (DiffChurnEvaluator{}).Evaluate(context.Background(), s); !ok || math.Abs(got-0.5) > 0.001 {
+		t.Errorf("diff churn = %v, %v; want 0.5, true", got, ok)
+	}
+}
+
+func TestQualityScorer_Evaluate(t *testing.T) {
+	scorer := NewQualityScorer(map[string]float64{
+		"test_pass_rate": 0.5,
+		"lint_delta":     0.5,
+		"review_score":   1,
+	})
+
+	s := NewQualitySignals()
+	s.TestsBefore, s.TestsAfter = 1, 1
+	s.Diff = "diff --git a/x b/x"
+
+	// Lint not measured and no judge registered: only tests contribute.
+	res := scorer.Evaluate(context.Background(), s)
+	if !res.HasData || res.Score != 1 || len(res.Components) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	scorer.Register(ReviewScoreEvaluator{Judge: func(ctx context.Context, rubric, diff string) (float64, error) {
+		if rubric != DefaultReviewRubric {
+			t.Errorf("judge got rubric %q", rubric)
+		}
+		return 0.4, nil
+	}})
+	// (1*0.5 + 0.4*1) / 1.5 = 0.6
+	res = scorer.Evaluate(context.Background(), s)
+	if math.Abs(res.Score-0.6) > 0.001 {
+		t.Errorf("Score = %v, want 0.6", res.Score)
+	}
+
+	scorer.Register(ReviewScoreEvaluator{Judge: func(context.Context, string, string) (float64, error) {
+		return 0, errors.New("judge offline")
+	}})
+	res = scorer.Evaluate(context.Background(), s)
+	if res.Score != 1 {
+		t.Errorf("Score with failing judge = %v, want 1", res.Score)
+	}
+	var found bool
+	for _, c := range res.Components {
+		if c.Name == "review_score" && c.Error != "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected review_score error in components")
+	}
+
+	m := ScoreMetrics{Completion: 1, Efficiency: 1}
+	res.ApplyTo(&m)
+	if m.Quality != 1 || m.Overall != 1 {
+		t.Errorf("ApplyTo() metrics = %+v", m)
+	}
+}
+
+func TestQualityScorer_NoData(t *testing.T) {
+	res := NewQualityScorer(nil).Evaluate(context.Background(), NewQualitySignals())
+	if res.HasData {
+		t.Errorf("expected no data, got %+v", res)
+	}
+}
@@ -0,0 +1,234 @@
+package scoring
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultSignificanceLevel is the alpha used when comparing agents.
+	DefaultSignificanceLevel = 0.05
+
+	// MinSamplesForComparison is the minimum samples per group before a
+	// comparison is attempted. Below this the test has no power at all.
+	MinSamplesForComparison = 3
+)
+
+// SignificanceTest is the result of a two-sample Mann-Whitney U test.
+type SignificanceTest struct {
+	// U is the Mann-Whitney U statistic for the first sample
+	U float64 `json:"u"`
+
+	// Z is the normal approximation z-score (tie corrected)
+	Z float64 `json:"z"`
+
+	// PValue is the two-sided p-value
+	PValue float64 `json:"p_value"`
+
+	// EffectSize is the rank-biserial correlation in [-1, 1].
+	// Positive values mean the first sample tends to be larger.
+	EffectSize float64 `json:"effect_size"`
+}
+
+// MannWhitneyU runs a two-sided Mann-Whitney U test on two independent
+// samples using the normal approximation with tie and continuity correction.
+// Returns nil if either sample is empty.
+func MannWhitneyU(a, b []float64) *SignificanceTest {
+	n1, n2 := len(a), len(b)
+	if n1 == 0 || n2 == 0 {
+		return nil
+	}
+
+	type ranked struct {
+		value float64
+		group int
+	}
+	all := make([]ranked, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, ranked{v, 0})
+	}
+	for _, v := range b {
+		all = append(all, ranked{v, 1})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Assign average ranks to ties and accumulate the tie correction term.
+	var rankSumA, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		avgRank := float64(i+j+1) / 2 // ranks are 1-based: (i+1 + j) / 2
+		for k := i; k < j; k++ {
+			if all[k].group == 0 {
+				rankSumA += avgRank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			tieTerm += t*t*t - t
+		}
+		i = j
+	}
+
+	fn1, fn2 := float64(n1), float64(n2)
+	n := fn1 + fn2
+	u := rankSumA - fn1*(fn1+1)/2
+	mean := fn1 * fn2 / 2
+	variance := fn1 * fn2 / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+
+	result := &SignificanceTest{
+		U:          u,
+		PValue:     1,
+		EffectSize: 2*u/(fn1*fn2) - 1,
+	}
+	if variance <= 0 {
+		// All values identical: no evidence of a difference.
+		return result
+	}
+
+	diff := u - mean
+	// Continuity correction toward the mean.
+	switch {
+	case diff > 0.5:
+		diff -= 0.5
+	case diff < -0.5:
+		diff += 0.5
+	default:
+		diff = 0
+	}
+	result.Z = diff / math.Sqrt(variance)
+	result.PValue = math.Erfc(math.Abs(result.Z) / math.Sqrt2)
+	return result
+}
+
+// AgentComparison reports whether two agent types differ significantly on a
+// task type. Better is only set when the difference is significant, so callers
+// can keep their current preference when the data is just noise.
+type AgentComparison struct {
+	TaskType    string            `json:"task_type,omitempty"`
+	AgentA      string            `json:"agent_a"`
+	AgentB      string            `json:"agent_b"`
+	SamplesA    int               `json:"samples_a"`
+	SamplesB    int               `json:"samples_b"`
+	MeanA       float64           `json:"mean_a"`
+	MeanB       float64           `json:"mean_b"`
+	Test        *SignificanceTest `json:"test,omitempty"`
+	Alpha       float64           `json:"alpha"`
+	Significant bool              `json:"significant"`
+	Better      string            `json:"better,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+}
+
+// CompareAgents tests whether agentA and agentB have significantly different
+// overall scores for taskType (empty = all task types) within the window.
+// alpha <= 0 uses DefaultSignificanceLevel.
+func (t *Tracker) CompareAgents(agentA, agentB, taskType string, windowDays int, alpha float64) (*AgentComparison, error) {
+	if windowDays <= 0 {
+		windowDays = TrendWindowDays
+	}
+	since := time.Now().AddDate(0, 0, -windowDays)
+
+	a, err := t.QueryScores(Query{AgentType: agentA, TaskType: taskType, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	b, err := t.QueryScores(Query{AgentType: agentB, TaskType: taskType, Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	return compareSamples(agentA, agentB, taskType, overallValues(a), overallValues(b), alpha), nil
+}
+
+// CompareAllAgents runs pairwise comparisons between every agent type that
+// has scores for taskType within the window. Results are ordered by agent
+// names for deterministic output.
+func (t *Tracker) CompareAllAgents(taskType string, windowDays int, alpha float64) ([]*AgentComparison, error) {
+	if windowDays <= 0 {
+		windowDays = TrendWindowDays
+	}
+
+	scores, err := t.QueryScores(Query{
+		TaskType: taskType,
+		Since:    time.Now().AddDate(0, 0, -windowDays),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byAgent := make(map[string][]float64)
+	for _, s := range scores {
+		byAgent[s.AgentType] = append(byAgent[s.AgentType], s.Metrics.Overall)
+	}
+
+	agents := make([]string, 0, len(byAgent))
+	for agent := range byAgent {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	var results []*AgentComparison
+	for i := 0; i < len(agents); i++ {
+		for j := i + 1; j < len(agents); j++ {
+			results = append(results, compareSamples(agents[i], agents[j], taskType, byAgent[agents[i]], byAgent[agents[j]], alpha))
+		}
+	}
+	return results, nil
+}
+
+func compareSamples(agentA, agentB, taskType string, a, b []float64, alpha float64) *AgentComparison {
+	if alpha <= 0 {
+		alpha = DefaultSignificanceLevel
+	}
+
+	c := &AgentComparison{
+		TaskType: taskType,
+		AgentA:   agentA,
+		AgentB:   agentB,
+		SamplesA: len(a),
+		SamplesB: len(b),
+		MeanA:    mean(a),
+		MeanB:    mean(b),
+		Alpha:    alpha,
+	}
+
+	if len(a) < MinSamplesForComparison || len(b) < MinSamplesForComparison {
+		c.Reason = "insufficient samples"
+		return c
+	}
+
+	c.Test = MannWhitneyU(a, b)
+	if c.Test.PValue >= alpha {
+		c.Reason = "difference not significant"
+		return c
+	}
+
+	c.Significant = true
+	if c.Test.EffectSize > 0 {
+		c.Better = agentA
+	} else {
+		c.Better = agentB
+	}
+	return c
+}
+
+func overallValues(scores []*Score) []float64 {
+	values := make([]float64, len(scores))
+	for i, s := range scores {
+		values[i] = s.Metrics.Overall
+	}
+	return values
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,89 @@
+package scoring
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	// Completely separated samples: U = 0.
+	res := MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10})
+	if res == nil {
+		t.Fatal("MannWhitneyU() returned nil")
+	}
+	if res.U != 0 {
+		t.Errorf("U = %v, want 0", res.U)
+	}
+	// z = (0 - 12.5 + 0.5) / sqrt(25*11/12) ≈ -2.5067, p ≈ 0.0122
+	if math.Abs(res.Z+2.5067) > 0.001 {
+		t.Errorf("Z = %v, want ~-2.5067", res.Z)
+	}
+	if math.Abs(res.PValue-0.0122) > 0.001 {
+		t.Errorf("PValue = %v, want ~0.0122", res.PValue)
+	}
+	if res.EffectSize != -1 {
+		t.Errorf("EffectSize = %v, want -1", res.EffectSize)
+	}
+
+	// Identical samples: no difference.
+	res = MannWhitneyU([]float64{0.5, 0.5, 0.5}, []float64{0.5, 0.5, 0.5})
+	if res.PValue != 1 {
+		t.Errorf("identical samples PValue = %v, want 1", res.PValue)
+	}
+
+	if MannWhitneyU(nil, []float64{1}) != nil {
+		t.Error("expected nil for empty sample")
+	}
+}
+
+func TestTracker_CompareAgents(t *testing.T) {
+	tracker, err := NewTracker(TrackerOptions{Path: filepath.Join(t.TempDir(), "scores.jsonl"), Enabled: true})
+	if err != nil {
+		t.Fatalf("NewTracker() error: %v", err)
+	}
+	defer tracker.Close()
+
+	now := time.Now().UTC()
+	record := func(agent string, values ...float64) {
+		for i, v := range values {
+			tracker.Record(&Score{
+				Timestamp: now.Add(-time.Duration(i+1) * time.Minute),
+				AgentType: agent,
+				TaskType:  "bug_fix",
+				Metrics:   ScoreMetrics{Overall: v, Completion: v},
+			})
+		}
+	}
+	record("claude", 0.90, 0.92, 0.88, 0.91, 0.93, 0.89)
+	record("codex", 0.60, 0.62, 0.58, 0.61, 0.63, 0.59)
+	record("gemini", 0.95, 0.40)
+
+	cmp, err := tracker.CompareAgents("claude", "codex", "bug_fix", 7, 0)
+	if err != nil {
+		t.Fatalf("CompareAgents() error: %v", err)
+	}
+	if !cmp.Significant || cmp.Better != "claude" {
+		t.Errorf("expected significant win for claude, got %+v", cmp)
+	}
+
+	cmp, err = tracker.CompareAgents("claude", "gemini", "bug_fix", 7, 0)
+	if err != nil {
+		t.Fatalf("CompareAgents() error: %v", err)
+	}
+	if cmp.Significant || cmp.Better != "" || cmp.Reason != "insufficient samples" {
+		t.Errorf("expected insufficient samples, got %+v", cmp)
+	}
+
+	all, err := tracker.CompareAllAgents("bug_fix", 7, 0)
+	if err != nil {
+		t.Fatalf("CompareAllAgents() error: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("CompareAllAgents() returned %d comparisons, want 3", len(all))
+	}
+	if all[0].AgentA != "claude" || all[0].AgentB != "codex" {
+		t.Errorf("first comparison = %s vs %s, want claude vs codex", all[0].AgentA, all[0].AgentB)
+	}
+}
@@ -5,13 +5,16 @@ package scoring
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
 	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
@@ -54,6 +57,10 @@ type Score struct {
 
 	// Context provides additional information about the scoring context
 	Context map[string]interface{} `json:"context,omitempty"`
+
+	// Human marks work done while a human had taken over the agent's pane.
+	// It is kept out of agent summaries and effectiveness.
+	Human bool `json:"human,omitempty"`
 }
 
 // ScoreMetrics contains the quantitative effectiveness measures.
@@ -211,6 +218,13 @@ func (t *Tracker) Record(score *Score) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	// Other ntm processes append to and prune the same file.
+	unlock, err := util.LockFile(t.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	if err := t.pruneLocked(now); err != nil {
 		return err
 	}
@@ -241,8 +255,11 @@ func (t *Tracker) RecordSessionEnd(session string, agentScores []Score) error {
 	return nil
 }
 
-// Close closes the tracker file.
+// Close closes the tracker file. Records are written through on every call,
+// so Close only waits for an in-flight Record or Prune to finish.
 func (t *Tracker) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	return nil
 }
 
@@ -257,6 +274,11 @@ func (t *Tracker) pruneAt(now time.Time) error {
 	}
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	unlock, err := util.LockFile(t.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	return t.pruneLocked(now)
 }
 
@@ -266,18 +288,47 @@ func (t *Tracker) pruneLocked(now time.Time) error {
 	}
 
 	cutoff := now.AddDate(0, 0, -t.retentionDays)
+	_, err := t.removeLocked("prune", func(score *Score) bool {
+		return !score.Timestamp.IsZero() && score.Timestamp.Before(cutoff)
+	})
+	return err
+}
+
+// PurgeSession removes all score records for session. If before is
+// non-zero, only records from before it are removed. It returns the number
+// of records removed.
+func (t *Tracker) PurgeSession(session string, before time.Time) (int, error) {
+	if !t.enabled || t.path == "" {
+		return 0, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	unlock, err := util.LockFile(t.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
 
+	return t.removeLocked("purge", func(score *Score) bool {
+		return score.Session == session && (before.IsZero() || score.Timestamp.Before(before))
+	})
+}
+
+// removeLocked rewrites the score file without the records for which drop
+// returns true. Unparseable lines are kept. op names the caller in temp file
+// names and errors.
+func (t *Tracker) removeLocked(op string, drop func(*Score) bool) (int, error) {
 	f, err := os.Open(t.path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil
+			return 0, nil
 		}
-		return fmt.Errorf("opening score file: %w", err)
+		return 0, fmt.Errorf("opening score file: %w", err)
 	}
 	defer f.Close()
 
 	var kept [][]byte
-	pruned := false
+	removed := 0
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
 		line := scanner.Bytes()
@@ -290,45 +341,45 @@ func (t *Tracker) pruneLocked(now time.Time) error {
 			kept = append(kept, append([]byte(nil), line...))
 			continue
 		}
-		if score.Timestamp.IsZero() || !score.Timestamp.Before(cutoff) {
-			kept = append(kept, append([]byte(nil), line...))
+		if drop(&score) {
+			removed++
 		} else {
-			pruned = true
+			kept = append(kept, append([]byte(nil), line...))
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scanning scores: %w", err)
+		return 0, fmt.Errorf("scanning scores: %w", err)
 	}
 
-	if !pruned {
-		return nil
+	if removed == 0 {
+		return 0, nil
 	}
 
 	dir := filepath.Dir(t.path)
-	tmpFile, err := os.CreateTemp(dir, "scores-prune-*.jsonl")
+	tmpFile, err := os.CreateTemp(dir, "scores-"+op+"-*.jsonl")
 	if err != nil {
-		return fmt.Errorf("creating prune temp file: %w", err)
+		return 0, fmt.Errorf("creating %s temp file: %w", op, err)
 	}
 	for _, line := range kept {
 		if _, err := tmpFile.Write(line); err != nil {
 			tmpFile.Close()
-			return fmt.Errorf("writing prune temp file: %w", err)
+			return 0, fmt.Errorf("writing %s temp file: %w", op, err)
 		}
 		if _, err := tmpFile.Write([]byte("\n")); err != nil {
 			tmpFile.Close()
-			return fmt.Errorf("writing prune temp file: %w", err)
+			return 0, fmt.Errorf("writing %s temp file: %w", op, err)
 		}
 	}
 	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("closing prune temp file: %w", err)
+		return 0, fmt.Errorf("closing %s temp file: %w", op, err)
 	}
 
 	if err := os.Rename(tmpFile.Name(), t.path); err != nil {
-		return fmt.Errorf("replacing score file: %w", err)
+		return 0, fmt.Errorf("replacing score file: %w", err)
 	}
 
-	return nil
+	return removed, nil
 }
 
 // Query retrieves scores matching the given criteria.
@@ -347,6 +398,9 @@ type Query struct {
 
 	// Limit caps the number of results (0 = unlimited)
 	Limit int
+
+	// IncludeHuman also returns scores for human work (see Score.Human)
+	IncludeHuman bool
 }
 
 // QueryScores returns scores matching the query.
@@ -355,57 +409,50 @@ func (t *Tracker) QueryScores(q Query) ([]*Score, error) {
 		return nil, nil
 	}
 
-	f, err := os.Open(t.path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("opening score file: %w", err)
-	}
-	defer f.Close()
-
 	var scores []*Score
-	scanner := bufio.NewScanner(f)
-
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
-
+	res, err := jsonlutil.ScanFile(t.path, jsonlutil.Options{}, func(_ int, line []byte) error {
 		var score Score
 		if err := json.Unmarshal(line, &score); err != nil {
-			continue // Skip malformed
+			return nil // Skip malformed
 		}
 
 		// Apply filters
 		if !q.Since.IsZero() && !score.Timestamp.After(q.Since) {
-			continue
+			return nil
 		}
 		if q.AgentType != "" && score.AgentType != q.AgentType {
-			continue
+			return nil
 		}
 		if q.TaskType != "" && score.TaskType != q.TaskType {
-			continue
+			return nil
 		}
 		if q.Session != "" && score.Session != q.Session {
-			continue
+			return nil
+		}
+		if score.Human && !q.IncludeHuman {
+			return nil
 		}
 
 		scores = append(scores, &score)
 
 		if q.Limit > 0 && len(scores) >= q.Limit {
-			break
+			return errScanDone
 		}
-	}
-
-	if err := scanner.Err(); err != nil {
+		return nil
+	})
+	if err != nil && err != errScanDone {
 		return nil, fmt.Errorf("scanning scores: %w", err)
 	}
+	if !res.OK() {
+		slog.Debug("skipped corrupt score records", "path", t.path, "count", len(res.Bad))
+	}
 
 	return scores, nil
 }
 
+// errScanDone stops a scan once a query's limit is reached.
+var errScanDone = errors.New("scan done")
+
 // RollingAverage computes the rolling average of overall scores.
 func (t *Tracker) RollingAverage(q Query, windowDays int) (float64, error) {
 	if windowDays <= 0 {
@@ -593,7 +640,7 @@ func (t *Tracker) SummarizeByAgentList(since time.Time) ([]*AgentSummary, error)
 
 // Export writes all scores to a JSON file for external analysis.
 func (t *Tracker) Export(outputPath string, since time.Time) error {
-	scores, err := t.QueryScores(Query{Since: since})
+	scores, err := t.QueryScores(Query{Since: since, IncludeHuman: true})
 	if err != nil {
 		return err
 	}
@@ -430,6 +430,40 @@ func TestTracker_SummarizeByAgent(t *testing.T) {
 	}
 }
 
+func TestTracker_HumanScoresExcluded(t *testing.T) {
+	tmpDir := t.TempDir()
+	scorePath := filepath.Join(tmpDir, "scores.jsonl")
+
+	tracker, err := NewTracker(TrackerOptions{Path: scorePath, Enabled: true})
+	if err != nil {
+		t.Fatalf("NewTracker() error: %v", err)
+	}
+	defer tracker.Close()
+
+	now := time.Now().UTC()
+	tracker.Record(&Score{Timestamp: now, AgentType: "claude", BeadID: "bd-1", Metrics: ScoreMetrics{Overall: 0.4}})
+	tracker.Record(&Score{Timestamp: now, AgentType: "claude", BeadID: "bd-2", Metrics: ScoreMetrics{Overall: 1}, Human: true})
+
+	scores, err := tracker.QueryScores(Query{AgentType: "claude"})
+	if err != nil {
+		t.Fatalf("QueryScores() error: %v", err)
+	}
+	if len(scores) != 1 || scores[0].BeadID != "bd-1" {
+		t.Errorf("QueryScores() = %d scores, want only the agent's", len(scores))
+	}
+	if all, _ := tracker.QueryScores(Query{IncludeHuman: true}); len(all) != 2 {
+		t.Errorf("QueryScores(IncludeHuman) = %d scores, want 2", len(all))
+	}
+
+	summaries, err := tracker.SummarizeByAgent(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("SummarizeByAgent() error: %v", err)
+	}
+	if claude := summaries["claude"]; claude == nil || claude.TotalScores != 1 || claude.AvgOverall != 0.4 {
+		t.Errorf("claude summary = %+v, want human work left out", claude)
+	}
+}
+
 func TestTracker_SummarizeByAgentList(t *testing.T) {
 	tmpDir := t.TempDir()
 	scorePath := filepath.Join(tmpDir, "scores.jsonl")
@@ -101,13 +101,7 @@ func (s *Server) handleListAccountsV1(w http.ResponseWriter, r *http.Request) {
 
 	// Check if robot mode returned an error
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
-			"hint": output.Hint,
-		}, reqID)
+		writeRobotFailure(w, output.ErrorCode, output.Error, output.Hint, reqID)
 		return
 	}
 
@@ -128,13 +122,7 @@ func (s *Server) handleAccountStatusV1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
-			"hint": output.Hint,
-		}, reqID)
+		writeRobotFailure(w, output.ErrorCode, output.Error, output.Hint, reqID)
 		return
 	}
 
@@ -155,13 +143,7 @@ func (s *Server) handleActiveAccountsV1(w http.ResponseWriter, r *http.Request)
 	}
 
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
-			"hint": output.Hint,
-		}, reqID)
+		writeRobotFailure(w, output.ErrorCode, output.Error, output.Hint, reqID)
 		return
 	}
 
@@ -190,13 +172,7 @@ func (s *Server) handleAccountQuotaV1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
-			"hint": output.Hint,
-		}, reqID)
+		writeRobotFailure(w, output.ErrorCode, output.Error, output.Hint, reqID)
 		return
 	}
 
@@ -379,7 +355,7 @@ func (s *Server) handleRotateAccountV1(w http.ResponseWriter, r *http.Request) {
 
 	// Publish WebSocket event for rotation
 	if s.wsHub != nil {
-		s.wsHub.Publish("accounts:"+req.Provider, "account.rotated", map[string]interface{}{
+		s.publishForRequest(r, "accounts:"+req.Provider, "account.rotated", map[string]interface{}{
 			"provider":         req.Provider,
 			"previous_account": output.Switch.PreviousAccount,
 			"new_account":      output.Switch.NewAccount,
@@ -400,11 +376,7 @@ func (s *Server) handleRotateAccountV1(w http.ResponseWriter, r *http.Request) {
 	logAccountQuotaSnapshot(reqID, req.Provider)
 
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
+		writeErrorResponse(w, robot.HTTPStatusForCode(output.ErrorCode), output.ErrorCode, output.Error, map[string]interface{}{
 			"switch": output.Switch,
 			"hint":   output.Hint,
 		}, reqID)
@@ -437,13 +409,7 @@ func (s *Server) handleListAccountsByProviderV1(w http.ResponseWriter, r *http.R
 	}
 
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
-			"hint": output.Hint,
-		}, reqID)
+		writeRobotFailure(w, output.ErrorCode, output.Error, output.Hint, reqID)
 		return
 	}
 
@@ -508,7 +474,7 @@ func (s *Server) handleRotateProviderAccountV1(w http.ResponseWriter, r *http.Re
 
 	// Publish WebSocket event for rotation
 	if s.wsHub != nil {
-		s.wsHub.Publish("accounts:"+provider, "account.rotated", map[string]interface{}{
+		s.publishForRequest(r, "accounts:"+provider, "account.rotated", map[string]interface{}{
 			"provider":         provider,
 			"previous_account": output.Switch.PreviousAccount,
 			"new_account":      output.Switch.NewAccount,
@@ -529,11 +495,7 @@ func (s *Server) handleRotateProviderAccountV1(w http.ResponseWriter, r *http.Re
 	logAccountQuotaSnapshot(reqID, provider)
 
 	if !output.Success {
-		statusCode := http.StatusInternalServerError
-		if output.ErrorCode == robot.ErrCodeDependencyMissing {
-			statusCode = http.StatusServiceUnavailable
-		}
-		writeErrorResponse(w, statusCode, output.ErrorCode, output.Error, map[string]interface{}{
+		writeErrorResponse(w, robot.HTTPStatusForCode(output.ErrorCode), output.ErrorCode, output.Error, map[string]interface{}{
 			"switch": output.Switch,
 			"hint":   output.Hint,
 		}, reqID)
@@ -0,0 +1,95 @@
+package serve
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+)
+
+type auditLogCall struct {
+	session   string
+	eventType audit.EventType
+	target    string
+	payload   map[string]interface{}
+	metadata  map[string]interface{}
+}
+
+func captureAuditLog(t *testing.T) *[]auditLogCall {
+	t.Helper()
+	var calls []auditLogCall
+	old := logAuditEvent
+	t.Cleanup(func() { logAuditEvent = old })
+	logAuditEvent = func(session string, eventType audit.EventType, _ audit.Actor, target string, payload, metadata map[string]interface{}) error {
+		calls = append(calls, auditLogCall{session, eventType, target, payload, metadata})
+		return nil
+	}
+	return &calls
+}
+
+func TestAPIAuditMiddleware(t *testing.T) {
+	calls := captureAuditLog(t)
+	srv := New(Config{})
+
+	r := chi.NewRouter()
+	r.Use(srv.requestIDMiddlewareFunc)
+	r.Use(srv.apiAuditMiddleware)
+	r.Get("/api/v1/sessions/{sessionId}/agents", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Post("/api/v1/sessions/{sessionId}/agents/send", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["message"] == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/sessions/proj/agents", nil))
+	if len(*calls) != 0 {
+		t.Fatalf("reads should not be audited, got %+v", *calls)
+	}
+
+	body := `{"message":"` + strings.Repeat("x", 300) + `","panes":[1,2],"opts":{"a":1}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/proj/agents/send?dry_run=1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("handler did not see the body: status %d", rec.Code)
+	}
+
+	if len(*calls) != 1 {
+		t.Fatalf("calls = %+v, want 1", *calls)
+	}
+	c := (*calls)[0]
+	if c.session != "proj" || c.eventType != audit.EventTypeAPIRequest || c.target != "POST /api/v1/sessions/{sessionId}/agents/send" {
+		t.Errorf("entry = %+v", c)
+	}
+	if c.payload["status"] != http.StatusAccepted || c.payload["route"] != "/api/v1/sessions/{sessionId}/agents/send" {
+		t.Errorf("payload = %+v", c.payload)
+	}
+	if _, ok := c.payload["latency_ms"].(int64); !ok {
+		t.Errorf("latency_ms = %#v", c.payload["latency_ms"])
+	}
+	summary := c.payload["request"].(map[string]interface{})
+	if msg, _ := summary["message"].(string); len(msg) != auditSummaryMaxValue {
+		t.Errorf("message not truncated: %d bytes", len(msg))
+	}
+	if got := summarizeValue(strings.Repeat("x", 100) + " ghp_" + strings.Repeat("a", 36)); strings.Contains(got.(string), "ghp_") {
+		t.Errorf("secret straddling the truncation limit was not redacted: %q", got)
+	}
+	if summary["panes"] != "[2 items]" || summary["opts"] != "{1 fields}" || summary["query"] != "dry_run=1" {
+		t.Errorf("summary = %+v", summary)
+	}
+	if c.metadata["principal"] == "" || c.metadata["request_id"] == "" || c.metadata["source"] != "serve" {
+		t.Errorf("metadata = %+v", c.metadata)
+	}
+}
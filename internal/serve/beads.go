@@ -207,7 +207,7 @@ func (s *Server) handleCreateBead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "bead.created", bead)
+	s.publishForRequest(r, "beads:*", "bead.created", bead)
 
 	writeSuccessResponse(w, http.StatusCreated, map[string]interface{}{
 		"bead": bead,
@@ -295,7 +295,7 @@ func (s *Server) handleUpdateBead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "bead.updated", bead)
+	s.publishForRequest(r, "beads:*", "bead.updated", bead)
 
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
 		"bead": bead,
@@ -330,7 +330,7 @@ func (s *Server) handleCloseBead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "bead.closed", map[string]interface{}{
+	s.publishForRequest(r, "beads:*", "bead.closed", map[string]interface{}{
 		"id":   beadID,
 		"bead": bead,
 	})
@@ -382,7 +382,7 @@ func (s *Server) handleClaimBead(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "bead.claimed", map[string]interface{}{
+	s.publishForRequest(r, "beads:*", "bead.claimed", map[string]interface{}{
 		"id":       beadID,
 		"assignee": req.Assignee,
 		"bead":     bead,
@@ -569,7 +569,7 @@ func (s *Server) handleAddBeadDep(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "bead.dependency_added", map[string]interface{}{
+	s.publishForRequest(r, "beads:*", "bead.dependency_added", map[string]interface{}{
 		"bead_id":    beadID,
 		"blocked_by": req.BlockedBy,
 	})
@@ -602,7 +602,7 @@ func (s *Server) handleRemoveBeadDep(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "bead.dependency_removed", map[string]interface{}{
+	s.publishForRequest(r, "beads:*", "bead.dependency_removed", map[string]interface{}{
 		"bead_id": beadID,
 		"dep_id":  depID,
 	})
@@ -830,7 +830,7 @@ func (s *Server) handleBeadsSync(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Publish WebSocket event
-	s.wsHub.Publish("beads:*", "beads.synced", map[string]interface{}{
+	s.publishForRequest(r, "beads:*", "beads.synced", map[string]interface{}{
 		"synced": true,
 	})
 
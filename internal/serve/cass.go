@@ -256,7 +256,7 @@ func (s *Server) handleCASSCapabilities(w http.ResponseWriter, r *http.Request)
 	caps, err := client.Capabilities(ctx)
 	if err != nil {
 		slog.Warn("failed to get cass capabilities", "error", err, "request_id", reqID)
-		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeCASSUnavailable,
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeCASSUnavailable,
 			"Failed to get CASS capabilities", map[string]interface{}{"error": err.Error()}, reqID)
 		return
 	}
@@ -482,7 +482,7 @@ func (s *Server) handleCASSTimeline(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		slog.Warn("cass timeline failed", "error", err, "request_id", reqID)
-		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeCASSUnavailable,
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeCASSUnavailable,
 			"Failed to get timeline", map[string]interface{}{"error": err.Error()}, reqID)
 		return
 	}
@@ -541,7 +541,7 @@ func (s *Server) handleCASSPreview(w http.ResponseWriter, r *http.Request) {
 	})
 	if err != nil {
 		slog.Warn("cass preview failed", "error", err, "request_id", reqID)
-		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeCASSUnavailable,
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeCASSUnavailable,
 			"Failed to get preview", map[string]interface{}{"error": err.Error()}, reqID)
 		return
 	}
@@ -0,0 +1,280 @@
+package serve
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressionConfig configures response compression.
+type CompressionConfig struct {
+	// Disabled turns compression off entirely.
+	Disabled bool
+	// Encodings lists the content codings offered, in server preference
+	// order. Empty means DefaultCompressionEncodings.
+	Encodings []string
+	// Level is the encoder level; 0 means the encoder's default.
+	Level int
+	// MinSize is the smallest body, in bytes, worth compressing. 0 means
+	// DefaultCompressionMinSize.
+	MinSize int
+}
+
+// DefaultCompressionEncodings are offered when none are configured.
+var DefaultCompressionEncodings = []string{"gzip"}
+
+// DefaultCompressionMinSize skips bodies too small to benefit.
+const DefaultCompressionMinSize = 1024
+
+// compressor is a streaming encoder for one content coding.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressionEncoders maps supported content codings to their encoders.
+var compressionEncoders = map[string]func(level int) (compressor, error){
+	"gzip": func(level int) (compressor, error) {
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(io.Discard, level)
+	},
+}
+
+// validateCompression checks that every configured encoding is supported.
+func validateCompression(cfg CompressionConfig) error {
+	if cfg.Disabled {
+		return nil
+	}
+	for _, enc := range cfg.Encodings {
+		newEncoder, ok := compressionEncoders[strings.ToLower(enc)]
+		if !ok {
+			return fmt.Errorf("unsupported compression encoding %q (supported: gzip)", enc)
+		}
+		if _, err := newEncoder(cfg.Level); err != nil {
+			return fmt.Errorf("compression level %d for %s: %w", cfg.Level, enc, err)
+		}
+	}
+	if cfg.MinSize < 0 {
+		return fmt.Errorf("compression min size must be >= 0")
+	}
+	return nil
+}
+
+// compression negotiates and pools encoders for compressMiddleware.
+type compression struct {
+	encodings []string
+	level     int
+	minSize   int
+	pools     map[string]*sync.Pool
+}
+
+func newCompression(cfg CompressionConfig) *compression {
+	if cfg.Disabled {
+		return nil
+	}
+	c := &compression{level: cfg.Level, minSize: cfg.MinSize, pools: make(map[string]*sync.Pool)}
+	if c.minSize == 0 {
+		c.minSize = DefaultCompressionMinSize
+	}
+	encodings := cfg.Encodings
+	if len(encodings) == 0 {
+		encodings = DefaultCompressionEncodings
+	}
+	for _, enc := range encodings {
+		enc = strings.ToLower(enc)
+		newEncoder, ok := compressionEncoders[enc]
+		if !ok {
+			continue
+		}
+		c.encodings = append(c.encodings, enc)
+		level := c.level
+		c.pools[enc] = &sync.Pool{New: func() any {
+			e, err := newEncoder(level)
+			if err != nil {
+				return nil
+			}
+			return e
+		}}
+	}
+	if len(c.encodings) == 0 {
+		return nil
+	}
+	return c
+}
+
+// negotiate picks the encoding to use for an Accept-Encoding header, or "".
+// Client q-values win; ties go to the server's preference order.
+func (c *compression) negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	qs := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		qs[strings.ToLower(strings.TrimSpace(name))] = q
+	}
+	best, bestQ := "", 0.0
+	for _, enc := range c.encodings {
+		q, ok := qs[enc]
+		if !ok {
+			q, ok = qs["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressMiddleware compresses responses for clients that accept it.
+// Event streams and WebSocket upgrades are passed through untouched, as
+// are bodies below the configured minimum size.
+func (s *Server) compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.compression
+		if c == nil || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" ||
+			strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		enc := c.negotiate(r.Header.Get("Accept-Encoding"))
+		if enc == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, c: c, encoding: enc, status: http.StatusOK}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// compressWriter buffers the start of a body until it knows whether the
+// response is worth compressing, then either streams it through an
+// encoder or writes it unchanged.
+type compressWriter struct {
+	http.ResponseWriter
+	c        *compression
+	encoding string
+	status   int
+
+	headerWritten bool // WriteHeader called by the handler
+	decided       bool
+	enc           compressor // nil when passing through
+	buf           []byte
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.headerWritten {
+		return
+	}
+	cw.headerWritten = true
+	cw.status = status
+	// Responses without a body are never compressed.
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.c.minSize {
+			return len(p), nil
+		}
+		cw.decide(true)
+		return len(p), nil
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// decide commits the headers and flushes any buffered bytes, compressing
+// them if compress is true and the response is eligible.
+func (cw *compressWriter) decide(compress bool) {
+	if cw.decided {
+		return
+	}
+	cw.decided = true
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" || strings.HasPrefix(h.Get("Content-Type"), "text/event-stream") {
+		compress = false
+	}
+	if compress {
+		if e, ok := cw.c.pools[cw.encoding].Get().(compressor); ok && e != nil {
+			e.Reset(cw.ResponseWriter)
+			cw.enc = e
+			h.Set("Content-Encoding", cw.encoding)
+			h.Del("Content-Length")
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) > 0 {
+		if cw.enc != nil {
+			_, _ = cw.enc.Write(cw.buf)
+		} else {
+			_, _ = cw.ResponseWriter.Write(cw.buf)
+		}
+		cw.buf = nil
+	}
+}
+
+// Flush sends buffered data so streaming handlers keep working. A flush
+// before the minimum size is reached commits to an uncompressed response.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		cw.decide(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack supports connection takeover by handlers below the middleware.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	cw.decided = true
+	return hj.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) finish() {
+	if !cw.decided {
+		// The whole body fit under the minimum size.
+		cw.decide(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		cw.enc.Reset(io.Discard)
+		cw.c.pools[cw.encoding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
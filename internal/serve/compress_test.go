@@ -0,0 +1,125 @@
+package serve
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompression_Negotiate(t *testing.T) {
+	c := newCompression(CompressionConfig{})
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"br, gzip;q=0.5", "gzip"},
+		{"gzip;q=0", ""},
+		{"*", "gzip"},
+		{"identity", ""},
+	}
+	for _, tt := range tests {
+		if got := c.negotiate(tt.header); got != tt.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+
+	if newCompression(CompressionConfig{Disabled: true}) != nil {
+		t.Error("disabled compression should yield nil")
+	}
+	if err := validateCompression(CompressionConfig{Encodings: []string{"zstd"}}); err == nil {
+		t.Error("unsupported encoding should fail validation")
+	}
+	if err := validateCompression(CompressionConfig{Encodings: []string{"gzip"}, Level: 42}); err == nil {
+		t.Error("invalid gzip level should fail validation")
+	}
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	srv := New(Config{Compression: CompressionConfig{MinSize: 64}})
+	large := strings.Repeat(`{"event":"pane.output","lines":["hello"]}`, 100)
+
+	handler := srv.compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(large))
+		case "/small":
+			w.Write([]byte("ok"))
+		case "/sse":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte(large))
+			w.(http.Flusher).Flush()
+		case "/status":
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/large", "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("large response headers = %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(zr)
+	if string(body) != large {
+		t.Error("decompressed body does not match")
+	}
+
+	for _, tc := range []struct{ path, accept string }{
+		{"/large", ""},
+		{"/small", "gzip"},
+		{"/sse", "gzip"},
+		{"/status", "gzip"},
+	} {
+		rec := get(tc.path, tc.accept)
+		if enc := rec.Header().Get("Content-Encoding"); enc != "" {
+			t.Errorf("%s (Accept-Encoding %q) was compressed with %s", tc.path, tc.accept, enc)
+		}
+	}
+	if rec := get("/status", "gzip"); rec.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", rec.Code)
+	}
+	if rec := get("/small", "gzip"); rec.Body.String() != "ok" {
+		t.Errorf("small body = %q", rec.Body.String())
+	}
+}
+
+func TestCompressMiddleware_HTTP2(t *testing.T) {
+	srv := New(Config{Compression: CompressionConfig{MinSize: 16}})
+	ts := httptest.NewUnstartedServer(srv.compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("compressible ", 100)))
+	})))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	resp, err := ts.Client().Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ProtoMajor != 2 {
+		t.Errorf("proto = %s, want HTTP/2", resp.Proto)
+	}
+	// The client transparently decompresses gzip it asked for.
+	if !resp.Uncompressed || !strings.HasPrefix(string(body), "compressible") {
+		t.Errorf("uncompressed=%v body=%q", resp.Uncompressed, body[:min(len(body), 20)])
+	}
+}
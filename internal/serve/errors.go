@@ -0,0 +1,71 @@
+package serve
+
+import (
+	"net/http"
+
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+// Server error codes join the robot error taxonomy so REST and robot
+// clients see the same identifiers, statuses, and remediations.
+func init() {
+	robot.RegisterErrorCodes(
+		// Request
+		robot.ErrorInfo{Code: ErrCodeBadRequest, ID: "NTM-E110", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusBadRequest, Action: robot.ActionFixRequest, Guidance: "Fix the request body or query parameters"},
+		robot.ErrorInfo{Code: ErrCodeMethodNotAllowed, ID: "NTM-E111", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusMethodNotAllowed, Action: robot.ActionFixRequest, Guidance: "Use a method the endpoint supports; see /api/v1/openapi.json"},
+		robot.ErrorInfo{Code: ErrCodeIdempotencyReuse, ID: "NTM-E102", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusUnprocessableEntity, Action: robot.ActionFixRequest, Guidance: "Use a new Idempotency-Key for a different request"},
+		robot.ErrorInfo{Code: ErrCodeInvalidWorkflow, ID: "NTM-E112", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusBadRequest, Action: robot.ActionFixRequest, Guidance: "Fix the workflow definition and resubmit"},
+		robot.ErrorInfo{Code: ErrCodeMissingWorkflow, ID: "NTM-E113", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusBadRequest, Action: robot.ActionFixRequest, Guidance: "Provide a workflow file or inline workflow"},
+		robot.ErrorInfo{Code: ErrCodeMissingSession, ID: "NTM-E114", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusBadRequest, Action: robot.ActionFixRequest, Guidance: "Provide the session to run in"},
+		robot.ErrorInfo{Code: ErrCodeApprovalRequired, ID: "NTM-E115", Category: robot.ErrCategoryRequest, HTTPStatus: http.StatusConflict, Action: robot.ActionConfirm, Retryable: true, Guidance: "Have an approver grant the request, then retry"},
+
+		// Not found
+		robot.ErrorInfo{Code: ErrCodeNotFound, ID: "NTM-E210", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "Check the resource identifier"},
+		robot.ErrorInfo{Code: ErrCodeAgentNotFound, ID: "NTM-E211", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "List registered agents with GET /api/v1/mail/agents"},
+		robot.ErrorInfo{Code: ErrCodeMessageNotFound, ID: "NTM-E212", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "Check the message ID"},
+		robot.ErrorInfo{Code: ErrCodeThreadNotFound, ID: "NTM-E213", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "Check the thread ID"},
+		robot.ErrorInfo{Code: ErrCodePipelineNotFound, ID: "NTM-E214", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "List pipeline runs with GET /api/v1/pipelines"},
+		robot.ErrorInfo{Code: ErrCodeTemplateNotFound, ID: "NTM-E215", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "List templates with GET /api/v1/pipelines/templates"},
+		robot.ErrorInfo{Code: ErrCodeNoResumableState, ID: "NTM-E216", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "Start the pipeline again instead of resuming it"},
+		robot.ErrorInfo{Code: ErrCodeScanNotFound, ID: "NTM-E217", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "List scans with GET /api/v1/scanner/history"},
+		robot.ErrorInfo{Code: ErrCodeFindingNotFound, ID: "NTM-E218", Category: robot.ErrCategoryNotFound, HTTPStatus: http.StatusNotFound, Action: robot.ActionCheckTarget, Guidance: "List findings with GET /api/v1/scanner/findings"},
+
+		// Environment
+		robot.ErrorInfo{Code: ErrCodeUnauthorized, ID: "NTM-E310", Category: robot.ErrCategoryEnvironment, HTTPStatus: http.StatusUnauthorized, Action: robot.ActionGrantAccess, Guidance: "Send valid credentials"},
+		robot.ErrorInfo{Code: ErrCodeForbidden, ID: "NTM-E311", Category: robot.ErrCategoryEnvironment, HTTPStatus: http.StatusForbidden, Action: robot.ActionGrantAccess, Guidance: "Use a principal with the required role"},
+		robot.ErrorInfo{Code: ErrCodeServiceUnavail, ID: "NTM-E312", Category: robot.ErrCategoryEnvironment, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionRetry, Retryable: true, Guidance: "The service is not ready; retry shortly"},
+
+		// Transient
+		robot.ErrorInfo{Code: ErrCodeConflict, ID: "NTM-E410", Category: robot.ErrCategoryTransient, HTTPStatus: http.StatusConflict, Action: robot.ActionWait, Retryable: true, Guidance: "The resource changed or is in use; refresh and retry"},
+		robot.ErrorInfo{Code: ErrCodeJobPending, ID: "NTM-E411", Category: robot.ErrCategoryTransient, HTTPStatus: http.StatusAccepted, Action: robot.ActionWait, Retryable: true, Guidance: "Poll the job until it completes"},
+		robot.ErrorInfo{Code: ErrCodePipelineRunning, ID: "NTM-E412", Category: robot.ErrCategoryTransient, HTTPStatus: http.StatusConflict, Action: robot.ActionWait, Retryable: true, Guidance: "Wait for the running pipeline to finish or cancel it"},
+		robot.ErrorInfo{Code: ErrCodeScanInProgress, ID: "NTM-E413", Category: robot.ErrCategoryTransient, HTTPStatus: http.StatusConflict, Action: robot.ActionWait, Retryable: true, Guidance: "Wait for the running scan to finish"},
+		robot.ErrorInfo{Code: ErrCodeDaemonRunning, ID: "NTM-E414", Category: robot.ErrCategoryTransient, HTTPStatus: http.StatusConflict, Action: robot.ActionCheckTarget, Guidance: "The daemon is already running; stop it first to restart"},
+
+		// Integration
+		robot.ErrorInfo{Code: ErrCodeBeadsUnavailable, ID: "NTM-E620", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Install br and initialize beads in the project"},
+		robot.ErrorInfo{Code: ErrCodeBVUnavailable, ID: "NTM-E621", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Install bv to enable triage endpoints"},
+		robot.ErrorInfo{Code: ErrCodeCASSUnavailable, ID: "NTM-E622", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Install cass and build its index"},
+		robot.ErrorInfo{Code: ErrCodeMemoryUnavailable, ID: "NTM-E623", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Install cm to enable memory endpoints"},
+		robot.ErrorInfo{Code: ErrCodeDaemonNotRunning, ID: "NTM-E624", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Start the daemon first"},
+		robot.ErrorInfo{Code: ErrCodeMailUnavailable, ID: "NTM-E625", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Start the Agent Mail server and check [agent_mail] url"},
+		robot.ErrorInfo{Code: ErrCodeScannerUnavailable, ID: "NTM-E626", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusServiceUnavailable, Action: robot.ActionInstallDependency, Guidance: "Install ubs to enable scanning"},
+		robot.ErrorInfo{Code: ErrCodeContextFailed, ID: "NTM-E627", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusInternalServerError, Action: robot.ActionRetry, Retryable: true, Guidance: "Building context failed; retry"},
+		robot.ErrorInfo{Code: ErrCodeOutcomeFailed, ID: "NTM-E628", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusInternalServerError, Action: robot.ActionRetry, Retryable: true, Guidance: "Recording the outcome failed; retry"},
+		robot.ErrorInfo{Code: ErrCodePrivacyFailed, ID: "NTM-E629", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusInternalServerError, Action: robot.ActionRetry, Retryable: true, Guidance: "Applying privacy settings failed; retry"},
+		robot.ErrorInfo{Code: ErrCodeReservationFailed, ID: "NTM-E630", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusInternalServerError, Action: robot.ActionRetry, Retryable: true, Guidance: "Reserving files failed; check Agent Mail and retry"},
+		robot.ErrorInfo{Code: ErrCodeContactDenied, ID: "NTM-E631", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusForbidden, Action: robot.ActionGrantAccess, Guidance: "The recipient does not accept contact from this agent"},
+		robot.ErrorInfo{Code: ErrCodePipelineFailed, ID: "NTM-E632", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusInternalServerError, Action: robot.ActionRetry, Retryable: true, Guidance: "Inspect the pipeline steps and retry"},
+		robot.ErrorInfo{Code: ErrCodeScanFailed, ID: "NTM-E633", Category: robot.ErrCategoryIntegration, HTTPStatus: http.StatusInternalServerError, Action: robot.ActionRetry, Retryable: true, Guidance: "The scanner failed; check its output and retry"},
+	)
+}
+
+// writeRobotFailure writes a failed robot result with the HTTP status its
+// error code maps to in the taxonomy.
+func writeRobotFailure(w http.ResponseWriter, code, message, hint string, requestID string) {
+	var details map[string]interface{}
+	if hint != "" {
+		details = map[string]interface{}{"hint": hint}
+	}
+	writeErrorResponse(w, robot.HTTPStatusForCode(code), code, message, details, requestID)
+}
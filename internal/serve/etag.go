@@ -0,0 +1,72 @@
+package serve
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// contentETag returns a strong ETag for a response payload. Callers hash
+// the payload before adding per-request fields such as timestamp and
+// request_id, so an unchanged resource keeps its ETag across polls.
+func contentETag(v interface{}) (string, bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, true
+}
+
+// etagMatches reports whether an If-None-Match header value matches etag.
+// Comparison is weak, as RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified tags the response with the ETag of data and reports whether
+// the request's If-None-Match already names it, in which case a 304 has
+// been written and the handler must not write a body.
+func notModified(w http.ResponseWriter, r *http.Request, data interface{}) bool {
+	etag, ok := contentETag(data)
+	if !ok {
+		return false
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// writeCacheableResponse is writeSuccessResponse for read endpoints that
+// dashboards poll: the response carries an ETag over data and a matching
+// If-None-Match gets 304 Not Modified without a body.
+func writeCacheableResponse(w http.ResponseWriter, r *http.Request, data map[string]interface{}, requestID string) {
+	if notModified(w, r, data) {
+		return
+	}
+	writeSuccessResponse(w, http.StatusOK, data, requestID)
+}
+
+// writeCacheableJSON is the legacy /api counterpart of
+// writeCacheableResponse.
+func writeCacheableJSON(w http.ResponseWriter, r *http.Request, data map[string]interface{}) {
+	if notModified(w, r, data) {
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
@@ -0,0 +1,66 @@
+package serve
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `"abc"`); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSessionsV1_ETag(t *testing.T) {
+	srv, store := setupTestServer(t)
+	if err := store.CreateSession(&state.Session{ID: "etag-1", Name: "etag", ProjectPath: "/p", CreatedAt: time.Now(), Status: state.SessionActive}); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		srv.handleSessionsV1(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first poll: status %d, ETag %q", first.Code, etag)
+	}
+
+	// Unchanged content: 304 without a body.
+	second := get(etag)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Fatalf("unchanged poll: status %d, body %q", second.Code, second.Body.String())
+	}
+
+	if err := store.CreateSession(&state.Session{ID: "etag-2", Name: "etag2", ProjectPath: "/p", CreatedAt: time.Now(), Status: state.SessionActive}); err != nil {
+		t.Fatal(err)
+	}
+	third := get(etag)
+	if third.Code != http.StatusOK || third.Header().Get("ETag") == etag {
+		t.Errorf("changed poll: status %d, ETag %q (was %q)", third.Code, third.Header().Get("ETag"), etag)
+	}
+}
@@ -4376,7 +4376,7 @@ func TestFetchJWKSKeys_InvalidJSON(t *testing.T) {
 func TestFetchJWKSKeys_NoValidKeys(t *testing.T) {
 	t.Parallel()
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Return JWKS with non-RSA key type
+		// Return JWKS with an EC key missing its curve and coordinates
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"keys": []map[string]string{
 				{"kty": "EC", "kid": "k1", "n": "", "e": ""},
@@ -4386,8 +4386,8 @@ func TestFetchJWKSKeys_NoValidKeys(t *testing.T) {
 	defer srv.Close()
 
 	_, err := fetchJWKSKeys(context.Background(), srv.URL)
-	if err == nil || !strings.Contains(err.Error(), "no valid RSA keys") {
-		t.Fatalf("expected 'no valid RSA keys' error, got: %v", err)
+	if err == nil || !strings.Contains(err.Error(), "no valid signing keys") {
+		t.Fatalf("expected 'no valid signing keys' error, got: %v", err)
 	}
 }
 
@@ -8650,7 +8650,7 @@ func TestValidateOIDCToken_IncompleteConfig(t *testing.T) {
 	// Empty OIDC config → "oidc config incomplete"
 	s.auth = AuthConfig{Mode: AuthModeOIDC}
 
-	err := s.validateOIDCToken(context.Background(), "any-token")
+	_, err := s.validateOIDCToken(context.Background(), "any-token")
 	if err == nil || !strings.Contains(err.Error(), "oidc config incomplete") {
 		t.Fatalf("expected oidc config incomplete error, got: %v", err)
 	}
@@ -8675,7 +8675,7 @@ func TestValidateOIDCToken_UnsupportedAlg(t *testing.T) {
 	sig := base64.RawURLEncoding.EncodeToString([]byte("fake-signature"))
 	token := header + "." + payload + "." + sig
 
-	err := s.validateOIDCToken(context.Background(), token)
+	_, err := s.validateOIDCToken(context.Background(), token)
 	if err == nil || !strings.Contains(err.Error(), "unsupported jwt alg") {
 		t.Fatalf("expected unsupported jwt alg error, got: %v", err)
 	}
@@ -8699,7 +8699,7 @@ func TestValidateOIDCToken_InvalidIssuer(t *testing.T) {
 	sig := base64.RawURLEncoding.EncodeToString([]byte("fake-signature"))
 	token := header + "." + payload + "." + sig
 
-	err := s.validateOIDCToken(context.Background(), token)
+	_, err := s.validateOIDCToken(context.Background(), token)
 	if err == nil || !strings.Contains(err.Error(), "invalid issuer") {
 		t.Fatalf("expected invalid issuer error, got: %v", err)
 	}
@@ -8724,7 +8724,7 @@ func TestValidateOIDCToken_InvalidAudience(t *testing.T) {
 	sig := base64.RawURLEncoding.EncodeToString([]byte("fake-signature"))
 	token := header + "." + payload + "." + sig
 
-	err := s.validateOIDCToken(context.Background(), token)
+	_, err := s.validateOIDCToken(context.Background(), token)
 	if err == nil || !strings.Contains(err.Error(), "invalid audience") {
 		t.Fatalf("expected invalid audience error, got: %v", err)
 	}
@@ -8749,7 +8749,7 @@ func TestValidateOIDCToken_ExpiredToken(t *testing.T) {
 	sig := base64.RawURLEncoding.EncodeToString([]byte("fake-signature"))
 	token := header + "." + payload + "." + sig
 
-	err := s.validateOIDCToken(context.Background(), token)
+	_, err := s.validateOIDCToken(context.Background(), token)
 	if err == nil || !strings.Contains(err.Error(), "token expired") {
 		t.Fatalf("expected token expired error, got: %v", err)
 	}
@@ -8774,7 +8774,7 @@ func TestValidateOIDCToken_NotYetValid(t *testing.T) {
 	sig := base64.RawURLEncoding.EncodeToString([]byte("fake-signature"))
 	token := header + "." + payload + "." + sig
 
-	err := s.validateOIDCToken(context.Background(), token)
+	_, err := s.validateOIDCToken(context.Background(), token)
 	if err == nil || !strings.Contains(err.Error(), "token not yet valid") {
 		t.Fatalf("expected token not yet valid error, got: %v", err)
 	}
@@ -8803,7 +8803,7 @@ func TestValidateOIDCToken_JWKSFetchError(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	err := s.validateOIDCToken(ctx, token)
+	_, err := s.validateOIDCToken(ctx, token)
 	// Should fail on JWKS fetch
 	if err == nil {
 		t.Fatal("expected JWKS fetch error")
@@ -8850,7 +8850,7 @@ func TestValidateOIDCToken_JWKSKidNotFound(t *testing.T) {
 	sig := base64.RawURLEncoding.EncodeToString([]byte("fake-signature"))
 	token := header + "." + payload + "." + sig
 
-	err := s.validateOIDCToken(context.Background(), token)
+	_, err := s.validateOIDCToken(context.Background(), token)
 	if err == nil || !strings.Contains(err.Error(), "kid not found") {
 		t.Fatalf("expected kid not found error, got: %v", err)
 	}
@@ -160,6 +160,9 @@ func TestBuildMTLSConfigValid(t *testing.T) {
 	if cfg.ClientCAs == nil {
 		t.Fatal("expected ClientCAs to be set")
 	}
+	if len(cfg.NextProtos) != 2 || cfg.NextProtos[0] != "h2" {
+		t.Errorf("NextProtos = %v, want HTTP/2 offered first", cfg.NextProtos)
+	}
 }
 
 // ---------------------------------------------------------------------------
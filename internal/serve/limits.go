@@ -0,0 +1,271 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LimitsConfig bounds request sizes and durations so a client cannot wedge
+// the server by sending huge bodies, trickling bytes, or never finishing.
+// Zero values mean the defaults below; a negative value disables a limit.
+type LimitsConfig struct {
+	// MaxBodyBytes caps request bodies. Larger bodies are rejected with 413.
+	MaxBodyBytes int64
+	// RequestTimeout bounds how long a handler may run. Event streams and
+	// WebSocket upgrades are exempt.
+	RequestTimeout time.Duration
+	// RouteTimeouts overrides RequestTimeout for paths matching a
+	// path.Match pattern, e.g. "/api/v1/sessions/*/agents/wait". They are
+	// merged over DefaultRouteTimeouts; 0 means no timeout for the route.
+	RouteTimeouts map[string]time.Duration
+	// ReadHeaderTimeout bounds reading request headers (slow-loris).
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout bounds reading a whole request, body included.
+	ReadTimeout time.Duration
+	// IdleTimeout bounds how long a keep-alive connection may sit idle.
+	IdleTimeout time.Duration
+	// MaxHeaderBytes caps the size of request headers.
+	MaxHeaderBytes int
+}
+
+// Default limits, applied to zero LimitsConfig fields.
+const (
+	DefaultMaxBodyBytes      = 1 << 20 // 1 MiB
+	DefaultRequestTimeout    = 30 * time.Second
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultMaxHeaderBytes    = 64 << 10 // 64 KiB
+)
+
+// DefaultRouteTimeouts gives routes that block by design more time than
+// DefaultRequestTimeout.
+var DefaultRouteTimeouts = map[string]time.Duration{
+	"/api/v1/wait":                    10 * time.Minute,
+	"/api/v1/wait/":                   10 * time.Minute,
+	"/api/v1/sessions":                2 * time.Minute,
+	"/api/v1/sessions/*/agents/wait":  10 * time.Minute,
+	"/api/v1/sessions/*/agents/spawn": 2 * time.Minute,
+}
+
+// validateLimits checks that route timeout patterns are well formed.
+func validateLimits(cfg LimitsConfig) error {
+	for pattern := range cfg.RouteTimeouts {
+		if !strings.HasPrefix(pattern, "/") {
+			return fmt.Errorf("route timeout pattern %q must start with /", pattern)
+		}
+		if _, err := path.Match(pattern, "/"); err != nil {
+			return fmt.Errorf("route timeout pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// limits is the resolved form of LimitsConfig; zero fields are disabled.
+type limits struct {
+	maxBodyBytes      int64
+	requestTimeout    time.Duration
+	routeTimeouts     map[string]time.Duration
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+}
+
+func newLimits(cfg LimitsConfig) limits {
+	l := limits{
+		maxBodyBytes:      limitOr(cfg.MaxBodyBytes, DefaultMaxBodyBytes),
+		requestTimeout:    limitOr(cfg.RequestTimeout, DefaultRequestTimeout),
+		readHeaderTimeout: limitOr(cfg.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		readTimeout:       limitOr(cfg.ReadTimeout, DefaultReadTimeout),
+		idleTimeout:       limitOr(cfg.IdleTimeout, DefaultIdleTimeout),
+		maxHeaderBytes:    limitOr(cfg.MaxHeaderBytes, DefaultMaxHeaderBytes),
+		routeTimeouts:     make(map[string]time.Duration, len(DefaultRouteTimeouts)+len(cfg.RouteTimeouts)),
+	}
+	for pattern, d := range DefaultRouteTimeouts {
+		l.routeTimeouts[pattern] = d
+	}
+	for pattern, d := range cfg.RouteTimeouts {
+		l.routeTimeouts[pattern] = max(d, 0)
+	}
+	return l
+}
+
+// limitOr resolves a configured limit: zero means def, negative disables.
+func limitOr[T int | int64 | time.Duration](v, def T) T {
+	switch {
+	case v == 0:
+		return def
+	case v < 0:
+		return 0
+	}
+	return v
+}
+
+// timeoutFor returns the handler timeout for a request path; 0 means none.
+// An exact pattern wins over wildcards; among wildcards the longest does.
+func (l limits) timeoutFor(p string) time.Duration {
+	if d, ok := l.routeTimeouts[p]; ok {
+		return d
+	}
+	best, timeout := -1, l.requestTimeout
+	for pattern, d := range l.routeTimeouts {
+		if ok, _ := path.Match(pattern, p); ok && len(pattern) > best {
+			best, timeout = len(pattern), d
+		}
+	}
+	return timeout
+}
+
+// isStreamingRequest reports requests whose responses are long-lived by
+// design and must not be cut off by the request timeout.
+func isStreamingRequest(r *http.Request) bool {
+	return r.Header.Get("Upgrade") != "" ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream") ||
+		r.URL.Path == "/events"
+}
+
+// limitsMiddleware caps request bodies and bounds handler run time.
+func (s *Server) limitsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := s.limits
+		if l.maxBodyBytes > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.ContentLength > l.maxBodyBytes {
+				writePayloadTooLarge(w, l.maxBodyBytes, requestIDFromContext(r.Context()))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, l.maxBodyBytes)
+		}
+
+		timeout := l.timeoutFor(r.URL.Path)
+		if timeout <= 0 || isStreamingRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		serveWithTimeout(next, w, r, timeout)
+	})
+}
+
+// serveWithTimeout runs next with a deadline. If the handler has not
+// started its response when the deadline passes, the client gets a 503 and
+// anything the handler writes afterwards is discarded. A handler that is
+// already writing is left to finish.
+func serveWithTimeout(next http.Handler, w http.ResponseWriter, r *http.Request, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	tw := &timeoutWriter{w: w, h: w.Header().Clone()}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+			close(done)
+		}()
+		next.ServeHTTP(tw, r.WithContext(ctx))
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		tw.mu.Lock()
+		if !tw.wroteHeader {
+			tw.timedOut = true
+			tw.mu.Unlock()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeTimeout,
+					fmt.Sprintf("request timed out after %s", timeout),
+					map[string]interface{}{"hint": "Retry, or raise the route timeout with --request-timeout"},
+					requestIDFromContext(r.Context()))
+			}
+			return
+		}
+		tw.mu.Unlock()
+		<-done
+	}
+	select {
+	case p := <-panicked:
+		panic(p)
+	default:
+	}
+}
+
+// timeoutWriter guards a ResponseWriter shared with a handler that may
+// outlive its deadline. Headers are staged so that a timed-out handler
+// never touches the real response.
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	h           http.Header
+	mu          sync.Mutex
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.h }
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	dst := tw.w.Header()
+	for k, vv := range tw.h {
+		dst[k] = vv
+	}
+	tw.w.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	return tw.w.Write(p)
+}
+
+// Flush implements http.Flusher when the underlying writer does.
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeBodyError reports a request body that could not be read, as 413
+// when it was cut off by the size limit.
+func writeBodyError(w http.ResponseWriter, err error, message, requestID string) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writePayloadTooLarge(w, tooLarge.Limit, requestID)
+		return
+	}
+	writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, message, nil, requestID)
+}
+
+func writePayloadTooLarge(w http.ResponseWriter, limit int64, requestID string) {
+	writeErrorResponse(w, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge,
+		fmt.Sprintf("request body exceeds %d bytes", limit),
+		map[string]interface{}{"limit_bytes": limit, "hint": "Send a smaller body, or raise --max-body-size"},
+		requestID)
+}
@@ -0,0 +1,146 @@
+package serve
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimits_TimeoutFor(t *testing.T) {
+	l := newLimits(LimitsConfig{
+		RequestTimeout: 5 * time.Second,
+		RouteTimeouts: map[string]time.Duration{
+			"/api/v1/slow":                   time.Minute,
+			"/api/v1/sessions/*/agents/wait": 0,
+		},
+	})
+	tests := []struct {
+		path string
+		want time.Duration
+	}{
+		{"/api/v1/health", 5 * time.Second},
+		{"/api/v1/slow", time.Minute},
+		{"/api/v1/wait", 10 * time.Minute},
+		{"/api/v1/sessions/proj/agents/wait", 0},
+		{"/api/v1/sessions/proj/agents/spawn", 2 * time.Minute},
+		{"/api/v1/sessions/proj/agents/send", 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := l.timeoutFor(tt.path); got != tt.want {
+			t.Errorf("timeoutFor(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	disabled := newLimits(LimitsConfig{MaxBodyBytes: -1, RequestTimeout: -1, ReadHeaderTimeout: -1})
+	if disabled.maxBodyBytes != 0 || disabled.readHeaderTimeout != 0 || disabled.timeoutFor("/api/v1/health") != 0 {
+		t.Errorf("negative limits should disable, got %+v", disabled)
+	}
+	if defaults := newLimits(LimitsConfig{}); defaults.maxBodyBytes != DefaultMaxBodyBytes || defaults.readHeaderTimeout != DefaultReadHeaderTimeout {
+		t.Errorf("zero limits should default, got %+v", defaults)
+	}
+
+	if err := validateLimits(LimitsConfig{RouteTimeouts: map[string]time.Duration{"api/v1": time.Second}}); err == nil {
+		t.Error("relative route pattern should fail validation")
+	}
+	if err := validateLimits(LimitsConfig{RouteTimeouts: map[string]time.Duration{"/api/[": time.Second}}); err == nil {
+		t.Error("malformed route pattern should fail validation")
+	}
+}
+
+func TestLimitsMiddleware_Body(t *testing.T) {
+	srv := New(Config{Limits: LimitsConfig{MaxBodyBytes: 16}})
+	handler := srv.limitsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			writeBodyError(w, err, "bad body", "")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// Declared too large: rejected before the handler runs.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(strings.Repeat("x", 17))))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("declared body: status = %d, want 413", rec.Code)
+	}
+	var resp APIError
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.ErrorCode != ErrCodePayloadTooLarge {
+		t.Errorf("response = %s", rec.Body.String())
+	}
+
+	// Undeclared (chunked) and too large: cut off while reading.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", io.NopCloser(strings.NewReader(strings.Repeat("x", 17))))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("chunked body: status = %d, want 413", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader("small")))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("small body: status = %d, want 204", rec.Code)
+	}
+}
+
+func TestLimitsMiddleware_Timeout(t *testing.T) {
+	srv := New(Config{Limits: LimitsConfig{RequestTimeout: 20 * time.Millisecond}})
+	release := make(chan struct{})
+	defer close(release)
+	handler := srv.limitsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fast":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			select {
+			case <-release:
+			case <-time.After(200 * time.Millisecond):
+			}
+			w.Write([]byte("late"))
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"ok":true}` || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("fast: status = %d, body = %q, headers = %v", rec.Code, rec.Body.String(), rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	start := time.Now()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), ErrCodeTimeout) {
+		t.Errorf("slow: status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Error("timed-out request waited for the handler")
+	}
+
+	// Event streams are exempt.
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "late" {
+		t.Errorf("stream: status = %d, body = %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLimitsMiddleware_TimeoutPropagatesPanic(t *testing.T) {
+	srv := New(Config{})
+	handler := srv.limitsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	defer func() {
+		if p := recover(); p != "boom" {
+			t.Errorf("recovered %v, want boom", p)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+}
@@ -0,0 +1,74 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// unixListenPrefix selects a Unix domain socket in Config.Listen.
+const unixListenPrefix = "unix://"
+
+// unixSocketPath returns the socket path of a unix:// listen address, or
+// "" if listen is not one.
+func unixSocketPath(listen string) string {
+	if !strings.HasPrefix(listen, unixListenPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(listen, unixListenPrefix)
+}
+
+// validateListen checks a Config.Listen value. Only unix:// addresses are
+// accepted; TCP listeners are configured with Host and Port.
+func validateListen(listen string) error {
+	if listen == "" {
+		return nil
+	}
+	path := unixSocketPath(listen)
+	if path == "" {
+		return fmt.Errorf("unsupported listen address %q (use unix:///path/ntm.sock, or --host/--port for TCP)", listen)
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("unix socket path must be absolute: %q", path)
+	}
+	return nil
+}
+
+// listenUnix listens on a Unix domain socket readable and writable only by
+// the current user; the file permissions are the auth boundary. A stale
+// socket left by a crashed server is replaced, but a live one is not.
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create socket directory: %w", err)
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another server", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("remove stale socket: %w", err)
+		}
+	}
+
+	// Create the socket with no group/other access so there is no window
+	// in which another user could connect before the chmod below.
+	restore := restrictUmask()
+	ln, err := net.Listen("unix", path)
+	restore()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil && !errors.Is(err, os.ErrNotExist) {
+		ln.Close()
+		return nil, fmt.Errorf("chmod socket: %w", err)
+	}
+	return ln, nil
+}
@@ -0,0 +1,145 @@
+package serve
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// shortSocketDir returns a temp dir short enough for a socket path; the
+// sun_path limit is ~104 bytes and t.TempDir paths can exceed it.
+func shortSocketDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "ntm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func unixClient(path string) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+func TestValidateListen(t *testing.T) {
+	tests := []struct {
+		listen  string
+		wantErr string
+	}{
+		{"", ""},
+		{"unix:///run/ntm.sock", ""},
+		{"unix://ntm.sock", "must be absolute"},
+		{"tcp://127.0.0.1:7337", "unsupported listen address"},
+		{"127.0.0.1:7337", "unsupported listen address"},
+	}
+	for _, tt := range tests {
+		err := validateListen(tt.listen)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validateListen(%q) = %v, want nil", tt.listen, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("validateListen(%q) = %v, want error containing %q", tt.listen, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateConfig_UnixSocketAllowsLocalAuth(t *testing.T) {
+	cfg := Config{Host: "0.0.0.0", Listen: "unix:///tmp/ntm.sock", Auth: AuthConfig{Mode: AuthModeLocal}}
+	if err := ValidateConfig(cfg); err != nil {
+		t.Fatalf("unix socket with local auth should be allowed, got %v", err)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(shortSocketDir(t), "sub", "ntm.sock")
+
+	ln, err := listenUnix(path)
+	if err != nil {
+		t.Fatalf("listenUnix: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("socket mode = %o, want 600", perm)
+		}
+	}
+
+	// A live socket must not be stolen.
+	if _, err := listenUnix(path); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("second listen on live socket = %v, want in-use error", err)
+	}
+
+	// A stale socket (no listener) is replaced. Go removes the socket file
+	// on Close, so keep the file to simulate a crashed server.
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	ln2, err := listenUnix(path)
+	if err != nil {
+		t.Fatalf("listen over stale socket: %v", err)
+	}
+	ln2.Close()
+
+	// A regular file is never removed.
+	file := filepath.Join(filepath.Dir(path), "file.sock")
+	if err := os.WriteFile(file, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenUnix(file); err == nil || !strings.Contains(err.Error(), "not a socket") {
+		t.Errorf("listen on regular file = %v, want not-a-socket error", err)
+	}
+}
+
+func TestStart_UnixSocket(t *testing.T) {
+	path := filepath.Join(shortSocketDir(t), "ntm.sock")
+	srv := New(Config{Listen: unixListenPrefix + path})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Start(ctx) }()
+
+	client := unixClient(path)
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if resp, err = client.Get("http://ntm/health"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		cancel()
+		t.Fatalf("request over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/health status = %d, want 200", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket should be removed on shutdown, stat err = %v", err)
+	}
+}
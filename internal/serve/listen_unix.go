@@ -0,0 +1,12 @@
+//go:build unix
+
+package serve
+
+import "syscall"
+
+// restrictUmask clears group and other permission bits for files created
+// until the returned function restores the previous umask.
+func restrictUmask() (restore func()) {
+	old := syscall.Umask(0077)
+	return func() { syscall.Umask(old) }
+}
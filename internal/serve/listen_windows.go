@@ -0,0 +1,9 @@
+//go:build windows
+
+package serve
+
+// restrictUmask is a no-op on Windows, where socket access is governed by
+// the directory ACL.
+func restrictUmask() (restore func()) {
+	return func() {}
+}
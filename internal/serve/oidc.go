@@ -0,0 +1,255 @@
+package serve
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// supportedJWTAlgs are the JWS algorithms accepted for OIDC tokens.
+var supportedJWTAlgs = map[string]bool{
+	"RS256": true,
+	"ES256": true,
+	"EdDSA": true,
+}
+
+// parseJWK converts a JWKS entry into an RSA, P-256 ECDSA, or Ed25519
+// public key.
+func parseJWK(key jwk) (crypto.PublicKey, error) {
+	switch key.Kty {
+	case "RSA":
+		if key.N == "" || key.E == "" {
+			return nil, fmt.Errorf("rsa jwk missing n or e")
+		}
+		return parseRSAPublicKey(key.N, key.E)
+	case "EC":
+		if key.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported ec curve %q", key.Crv)
+		}
+		return parseP256PublicKey(key.X, key.Y)
+	case "OKP":
+		if key.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported okp curve %q", key.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return nil, fmt.Errorf("decode jwk x: %w", err)
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key length %d", len(x))
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported jwk kty %q", key.Kty)
+	}
+}
+
+func parseP256PublicKey(xStr, yStr string) (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(xStr)
+	if err != nil {
+		return nil, fmt.Errorf("decode jwk x: %w", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(yStr)
+	if err != nil {
+		return nil, fmt.Errorf("decode jwk y: %w", err)
+	}
+	if len(x) > 32 || len(y) > 32 {
+		return nil, fmt.Errorf("invalid p-256 coordinate length")
+	}
+	// Uncompressed SEC 1 point: 0x04 || X || Y, coordinates left-padded.
+	point := make([]byte, 65)
+	point[0] = 4
+	copy(point[33-len(x):33], x)
+	copy(point[65-len(y):], y)
+	return ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+}
+
+// verifyJWTSignature checks a JWS signature. The key type must match the
+// algorithm, so an RSA key can never verify an ES256 token or vice versa.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	errInvalid := errors.New("invalid token signature")
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("jwk type does not match alg %s", alg)
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], signature) != nil {
+			return errInvalid
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return fmt.Errorf("jwk type does not match alg %s", alg)
+		}
+		// JWS encodes ECDSA signatures as fixed-width R || S, not ASN.1.
+		if len(signature) != 64 {
+			return errInvalid
+		}
+		hash := sha256.Sum256([]byte(signingInput))
+		r := new(big.Int).SetBytes(signature[:32])
+		sig := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, hash[:], r, sig) {
+			return errInvalid
+		}
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("jwk type does not match alg %s", alg)
+		}
+		if !ed25519.Verify(pub, []byte(signingInput), signature) {
+			return errInvalid
+		}
+	default:
+		return fmt.Errorf("unsupported jwt alg %q", alg)
+	}
+	return nil
+}
+
+// oidcTokenCacheMax bounds the validated-token cache.
+const oidcTokenCacheMax = 4096
+
+// oidcTokenCache remembers tokens that passed full validation, keyed by
+// their SHA-256 hash, so repeat requests skip parsing and signature checks.
+// An entry lives until the token's exp or the cache TTL, whichever is first.
+type oidcTokenCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]oidcTokenEntry
+	ttl     time.Duration
+}
+
+type oidcTokenEntry struct {
+	claims  map[string]interface{}
+	expires time.Time
+}
+
+func newOIDCTokenCache(ttl time.Duration) *oidcTokenCache {
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+	return &oidcTokenCache{
+		entries: make(map[[sha256.Size]byte]oidcTokenEntry),
+		ttl:     ttl,
+	}
+}
+
+// get returns the claims of a cached, still-valid token.
+func (c *oidcTokenCache) get(token string, now time.Time) (map[string]interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	key := sha256.Sum256([]byte(token))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.claims, true
+}
+
+func (c *oidcTokenCache) add(token string, claims map[string]interface{}, now time.Time) {
+	if c == nil {
+		return
+	}
+	expires := now.Add(c.ttl)
+	if exp, ok := claimInt64(claims, "exp"); ok {
+		if tokenExp := time.Unix(exp, 0); tokenExp.Before(expires) {
+			expires = tokenExp
+		}
+	}
+	if !now.Before(expires) {
+		return
+	}
+	key := sha256.Sum256([]byte(token))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= oidcTokenCacheMax {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= oidcTokenCacheMax {
+			c.entries = make(map[[sha256.Size]byte]oidcTokenEntry)
+		}
+	}
+	c.entries[key] = oidcTokenEntry{claims: claims, expires: expires}
+}
+
+// oidcDiscovery caches the jwks_uri from the issuer's discovery document.
+type oidcDiscovery struct {
+	mu        sync.Mutex
+	jwksURL   string
+	fetchedAt time.Time
+	ttl       time.Duration
+}
+
+func newOIDCDiscovery(ttl time.Duration) *oidcDiscovery {
+	if ttl <= 0 {
+		ttl = defaultJWKSCacheTTL
+	}
+	return &oidcDiscovery{ttl: ttl}
+}
+
+type oidcProviderMetadata struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// getJWKSURL returns the issuer's jwks_uri, fetching
+// <issuer>/.well-known/openid-configuration when the cached value is stale.
+func (d *oidcDiscovery) getJWKSURL(ctx context.Context, issuer string) (string, error) {
+	d.mu.Lock()
+	if d.jwksURL != "" && time.Since(d.fetchedAt) < d.ttl {
+		url := d.jwksURL
+		d.mu.Unlock()
+		return url, nil
+	}
+	d.mu.Unlock()
+
+	var meta oidcProviderMetadata
+	configURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := fetchOIDCJSON(ctx, configURL, "oidc discovery", &meta); err != nil {
+		return "", err
+	}
+	// OpenID Connect Discovery 1.0 §4.3: the document must name the issuer
+	// it was fetched for.
+	if strings.TrimSuffix(meta.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return "", fmt.Errorf("oidc discovery issuer mismatch: got %q", meta.Issuer)
+	}
+	if meta.JWKSURI == "" {
+		return "", fmt.Errorf("oidc discovery missing jwks_uri")
+	}
+
+	d.mu.Lock()
+	d.jwksURL = meta.JWKSURI
+	d.fetchedAt = time.Now()
+	d.mu.Unlock()
+	return meta.JWKSURI, nil
+}
+
+// oidcJWKSURL returns the configured JWKS URL, or discovers it from the
+// issuer when none is configured.
+func (s *Server) oidcJWKSURL(ctx context.Context) (string, error) {
+	if s.auth.OIDC.JWKSURL != "" {
+		return s.auth.OIDC.JWKSURL, nil
+	}
+	return s.oidcDiscovery.getJWKSURL(ctx, s.auth.OIDC.Issuer)
+}
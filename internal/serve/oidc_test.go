@@ -0,0 +1,193 @@
+package serve
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// oidcTestIssuer serves a discovery document and JWKS for the given keys.
+type oidcTestIssuer struct {
+	srv         *httptest.Server
+	jwksFetches atomic.Int32
+}
+
+func newOIDCTestIssuer(t *testing.T, keys ...map[string]string) *oidcTestIssuer {
+	t.Helper()
+	iss := &oidcTestIssuer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   iss.srv.URL,
+			"jwks_uri": iss.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		iss.jwksFetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": keys})
+	})
+	iss.srv = httptest.NewServer(mux)
+	t.Cleanup(iss.srv.Close)
+	return iss
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func signTestJWT(t *testing.T, alg, kid, issuer string, key crypto.Signer) string {
+	t.Helper()
+	header := b64([]byte(fmt.Sprintf(`{"alg":%q,"kid":%q}`, alg, kid)))
+	payload := b64([]byte(fmt.Sprintf(`{"iss":%q,"sub":"user","exp":%d}`, issuer, time.Now().Add(time.Hour).Unix())))
+	input := header + "." + payload
+
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		hash := sha256.Sum256([]byte(input))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, hash[:])
+	case *ecdsa.PrivateKey:
+		hash := sha256.Sum256([]byte(input))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, hash[:])
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(k, []byte(input))
+	}
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return input + "." + b64(sig)
+}
+
+func TestValidateOIDCToken_Algorithms(t *testing.T) {
+	t.Parallel()
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	ecX, ecY := make([]byte, 32), make([]byte, 32)
+	ecKey.X.FillBytes(ecX)
+	ecKey.Y.FillBytes(ecY)
+
+	iss := newOIDCTestIssuer(t,
+		map[string]string{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		map[string]string{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecX), "y": b64(ecY)},
+		map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPub)},
+	)
+
+	tests := []struct {
+		alg, kid string
+		key      crypto.Signer
+	}{
+		{"RS256", "rsa", rsaKey},
+		{"ES256", "ec", ecKey},
+		{"EdDSA", "ed", edKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			// Issuer only: the JWKS URL comes from discovery.
+			s := New(Config{Auth: AuthConfig{Mode: AuthModeOIDC, OIDC: OIDCConfig{Issuer: iss.srv.URL}}})
+			token := signTestJWT(t, tt.alg, tt.kid, iss.srv.URL, tt.key)
+			if _, err := s.validateOIDCToken(context.Background(), token); err != nil {
+				t.Fatalf("valid %s token rejected: %v", tt.alg, err)
+			}
+
+			parts := strings.Split(token, ".")
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			sig[0] ^= 0xff
+			tampered := parts[0] + "." + parts[1] + "." + b64(sig)
+			if _, err := s.validateOIDCToken(context.Background(), tampered); err == nil {
+				t.Fatalf("tampered %s token accepted", tt.alg)
+			}
+		})
+	}
+
+	// A key may only verify its own algorithm.
+	s := New(Config{Auth: AuthConfig{Mode: AuthModeOIDC, OIDC: OIDCConfig{Issuer: iss.srv.URL}}})
+	mismatched := signTestJWT(t, "ES256", "rsa", iss.srv.URL, ecKey)
+	if _, err := s.validateOIDCToken(context.Background(), mismatched); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("expected key/alg mismatch error, got %v", err)
+	}
+}
+
+func TestValidateOIDCToken_CachesValidatedTokens(t *testing.T) {
+	t.Parallel()
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	iss := newOIDCTestIssuer(t, map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPub)})
+	s := New(Config{Auth: AuthConfig{Mode: AuthModeOIDC, OIDC: OIDCConfig{Issuer: iss.srv.URL, JWKSURL: iss.srv.URL + "/jwks"}}})
+
+	token := signTestJWT(t, "EdDSA", "ed", iss.srv.URL, edKey)
+	if _, err := s.validateOIDCToken(context.Background(), token); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	// With the issuer gone, only the token cache can accept it.
+	iss.srv.Close()
+	if _, err := s.validateOIDCToken(context.Background(), token); err != nil {
+		t.Fatalf("cached token rejected: %v", err)
+	}
+	if n := iss.jwksFetches.Load(); n != 1 {
+		t.Errorf("jwks fetches = %d, want 1", n)
+	}
+}
+
+func (c *oidcTokenCache) cached(token string, now time.Time) bool {
+	_, ok := c.get(token, now)
+	return ok
+}
+
+func TestOIDCTokenCache_HonorsExp(t *testing.T) {
+	t.Parallel()
+	c := newOIDCTokenCache(time.Hour)
+	now := time.Now()
+
+	c.add("short", map[string]interface{}{"exp": float64(now.Add(time.Minute).Unix())}, now)
+	if !c.cached("short", now) {
+		t.Fatal("fresh token should be cached")
+	}
+	if c.cached("short", now.Add(2*time.Minute)) {
+		t.Error("token should not outlive its exp")
+	}
+
+	c.add("long", map[string]interface{}{}, now)
+	if c.cached("long", now.Add(2*time.Hour)) {
+		t.Error("token should not outlive the cache TTL")
+	}
+
+	c.add("expired", map[string]interface{}{"exp": float64(now.Add(-time.Minute).Unix())}, now)
+	if c.cached("expired", now) {
+		t.Error("expired token should never be cached")
+	}
+}
+
+func TestOIDCDiscovery_IssuerMismatch(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   "https://evil.example.com",
+			"jwks_uri": "https://evil.example.com/jwks",
+		})
+	}))
+	defer srv.Close()
+
+	_, err := newOIDCDiscovery(0).getJWKSURL(context.Background(), srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("expected issuer mismatch, got %v", err)
+	}
+}
@@ -171,7 +171,7 @@ func (s *Server) handleRunPipeline(w http.ResponseWriter, r *http.Request) {
 	result := s.runPipelineWithResult(r.Context(), opts)
 
 	if !result.Success {
-		writeErrorResponse(w, http.StatusBadRequest, result.ErrorCode, result.Error, nil, reqID)
+		writeRobotFailure(w, result.ErrorCode, result.Error, result.Hint, reqID)
 		return
 	}
 
@@ -223,7 +223,7 @@ func (s *Server) handleExecPipeline(w http.ResponseWriter, r *http.Request) {
 	result := s.execPipelineInline(r.Context(), &req.Workflow, req.Session, req.Variables, req.Background)
 
 	if !result.Success {
-		writeErrorResponse(w, http.StatusBadRequest, result.ErrorCode, result.Error, nil, reqID)
+		writeRobotFailure(w, result.ErrorCode, result.Error, result.Hint, reqID)
 		return
 	}
 
@@ -358,7 +358,7 @@ func (s *Server) handleResumePipeline(w http.ResponseWriter, r *http.Request) {
 	result := s.resumePipelineWithResult(r.Context(), runID, session, req.Variables, state)
 
 	if !result.Success {
-		writeErrorResponse(w, http.StatusBadRequest, result.ErrorCode, result.Error, nil, reqID)
+		writeRobotFailure(w, result.ErrorCode, result.Error, result.Hint, reqID)
 		return
 	}
 
@@ -0,0 +1,72 @@
+package serve
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+)
+
+// Principal identifies the authenticated caller of a request.
+type Principal struct {
+	// ID is the OIDC sub claim, the API key ID, or the mTLS client
+	// certificate CN; "anonymous" in local mode.
+	ID string `json:"id"`
+	// AuthMethod is the server auth mode that authenticated the caller.
+	AuthMethod string `json:"auth_method"`
+}
+
+// apiKeyID derives a stable, non-secret identifier for an API key so audit
+// entries can name the key without recording it.
+func apiKeyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "apikey:" + hex.EncodeToString(sum[:6])
+}
+
+// withAuthClaims stores the authenticated principal's claims on the request
+// for rbacMiddleware, auditing, and WebSocket clients.
+func withAuthClaims(r *http.Request, claims map[string]interface{}) *http.Request {
+	if claims == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), authContextKey, claims))
+}
+
+// requestPrincipal returns the authenticated principal for r.
+func (s *Server) requestPrincipal(r *http.Request) Principal {
+	method := string(s.auth.Mode)
+	if method == "" {
+		method = string(AuthModeLocal)
+	}
+	if rc := RoleFromContext(r.Context()); rc != nil {
+		return Principal{ID: rc.UserID, AuthMethod: method}
+	}
+	return Principal{ID: extractUserIDFromClaims(extractAuthClaims(r)), AuthMethod: method}
+}
+
+// auditRequest writes an audit log entry for an action taken through the
+// API, recording the principal so multi-user deployments can tell exactly
+// who sent a prompt or keystrokes.
+func (s *Server) auditRequest(r *http.Request, session string, eventType audit.EventType, target string, payload map[string]interface{}) {
+	_ = logAuditEvent(session, eventType, audit.ActorUser, target, payload, s.auditMetadata(r))
+}
+
+// auditMetadata identifies who sent r, for audit entries.
+func (s *Server) auditMetadata(r *http.Request) map[string]interface{} {
+	p := s.requestPrincipal(r)
+	return map[string]interface{}{
+		"source":      "serve",
+		"principal":   p.ID,
+		"auth_method": p.AuthMethod,
+		"request_id":  requestIDFromContext(r.Context()),
+		"remote_addr": r.RemoteAddr,
+	}
+}
+
+// publishForRequest publishes a WebSocket event attributed to the request's
+// principal.
+func (s *Server) publishForRequest(r *http.Request, topic, eventType string, data interface{}) {
+	s.wsHub.PublishAs(topic, eventType, s.requestPrincipal(r).ID, data)
+}
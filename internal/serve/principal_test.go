@@ -0,0 +1,86 @@
+package serve
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// principalVia runs req through the auth and RBAC middleware and returns
+// the principal seen by the handler.
+func principalVia(t *testing.T, s *Server, req *http.Request) Principal {
+	t.Helper()
+	var got Principal
+	h := s.authMiddlewareFunc(s.rbacMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = s.requestPrincipal(r)
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	return got
+}
+
+func TestRequestPrincipal_APIKey(t *testing.T) {
+	t.Parallel()
+	s := New(Config{Auth: AuthConfig{Mode: AuthModeAPIKey, APIKey: "s3cret-key"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions", nil)
+	req.Header.Set("X-API-Key", "s3cret-key")
+
+	p := principalVia(t, s, req)
+	if p.ID != apiKeyID("s3cret-key") || p.AuthMethod != string(AuthModeAPIKey) {
+		t.Fatalf("principal = %+v", p)
+	}
+	if strings.Contains(p.ID, "s3cret") {
+		t.Fatalf("key ID %q leaks the key", p.ID)
+	}
+}
+
+func TestRequestPrincipal_OIDCSubject(t *testing.T) {
+	t.Parallel()
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	iss := newOIDCTestIssuer(t, map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPub)})
+	s := New(Config{Auth: AuthConfig{Mode: AuthModeOIDC, OIDC: OIDCConfig{Issuer: iss.srv.URL}}})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "EdDSA", "ed", iss.srv.URL, edKey))
+
+	// A second request hits the token cache and must keep the identity.
+	for i := 0; i < 2; i++ {
+		if p := principalVia(t, s, req.Clone(req.Context())); p.ID != "user" || p.AuthMethod != string(AuthModeOIDC) {
+			t.Fatalf("request %d: principal = %+v, want sub=user", i, p)
+		}
+	}
+}
+
+func TestRequestPrincipal_MTLSCommonName(t *testing.T) {
+	t.Parallel()
+	s := New(Config{Auth: AuthConfig{Mode: AuthModeMTLS}})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "ci-runner"}}},
+	}
+	if p := principalVia(t, s, req); p.ID != "ci-runner" {
+		t.Fatalf("principal = %+v, want ci-runner", p)
+	}
+}
+
+func TestPublishForRequest_SetsActor(t *testing.T) {
+	t.Parallel()
+	s := New(Config{Auth: AuthConfig{Mode: AuthModeAPIKey, APIKey: "k"}})
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req = withAuthClaims(req, map[string]interface{}{"sub": "alice"})
+
+	s.publishForRequest(req, "sessions:proj", "agents.send", map[string]interface{}{"delivered": 1})
+	event := <-s.wsHub.broadcast
+	if event.Actor != "alice" || event.EventType != "agents.send" {
+		t.Fatalf("event = %+v, want actor alice", event)
+	}
+}
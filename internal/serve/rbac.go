@@ -219,6 +219,11 @@ func (s *Server) rbacMiddleware(next http.Handler) http.Handler {
 
 // extractRoleFromClaims determines the user's role from auth claims.
 func (s *Server) extractRoleFromClaims(claims map[string]interface{}) Role {
+	// Share links are read-only in every auth mode
+	if _, ok := claims[shareSessionClaim]; ok {
+		return RoleViewer
+	}
+
 	// For local mode without auth, grant admin access
 	if s.auth.Mode == AuthModeLocal || s.auth.Mode == "" {
 		return RoleAdmin
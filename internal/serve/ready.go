@@ -0,0 +1,127 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+)
+
+// readinessCheckTimeout bounds each readiness check so a wedged dependency
+// fails the probe instead of hanging it.
+const readinessCheckTimeout = 2 * time.Second
+
+// ReadinessCheck is the result of one dependency check behind /ready.
+type ReadinessCheck struct {
+	Name      string `json:"name"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// muxReachable checks that the terminal multiplexer backend answers. A
+// tmux with no server running is reachable: it just has no sessions yet.
+var muxReachable = func(ctx context.Context) error {
+	if !tmux.DefaultClient.IsInstalled() {
+		return errors.New("tmux is not installed")
+	}
+	_, err := tmux.DefaultClient.RunContext(ctx, "list-sessions", "-F", "#{session_name}")
+	if err != nil && !strings.Contains(err.Error(), "no server running") &&
+		!strings.Contains(err.Error(), "error connecting to") {
+		return err
+	}
+	return nil
+}
+
+// readinessProbe is one named readiness check.
+type readinessProbe struct {
+	name  string
+	check func(context.Context) error
+}
+
+// readinessChecks lists what must work before the server takes traffic.
+func (s *Server) readinessChecks() []readinessProbe {
+	return []readinessProbe{
+		{"server", func(context.Context) error {
+			if !s.accepting.Load() {
+				return errors.New("not accepting requests (starting or shutting down)")
+			}
+			return nil
+		}},
+		{"state_store", func(ctx context.Context) error {
+			if s.stateStore == nil {
+				return errors.New("state store not open")
+			}
+			return s.stateStore.DB().PingContext(ctx)
+		}},
+		{"event_bus", func(context.Context) error {
+			if s.eventBus == nil {
+				return errors.New("event bus not attached")
+			}
+			return nil
+		}},
+		{"mux", muxReachable},
+	}
+}
+
+// Readiness runs the readiness checks and reports whether all passed.
+func (s *Server) Readiness(ctx context.Context) (bool, []ReadinessCheck) {
+	ready := true
+	var results []ReadinessCheck
+	for _, c := range s.readinessChecks() {
+		checkCtx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+		start := time.Now()
+		err := c.check(checkCtx)
+		cancel()
+		result := ReadinessCheck{Name: c.name, OK: err == nil, LatencyMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			ready = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return ready, results
+}
+
+// handleReady handles /ready: 200 when the server and its dependencies are
+// usable, 503 otherwise. Unlike /health (liveness), a failing /ready means
+// "don't route traffic here yet", not "restart me".
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	ready, checks := s.Readiness(r.Context())
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"success": ready,
+		"status":  status,
+		"checks":  checks,
+		"time":    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handleReadyV1 handles GET /api/v1/ready.
+func (s *Server) handleReadyV1(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	ready, checks := s.Readiness(r.Context())
+	if !ready {
+		var failed []string
+		for _, c := range checks {
+			if !c.OK {
+				failed = append(failed, c.Name)
+			}
+		}
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail,
+			fmt.Sprintf("not ready: %s", strings.Join(failed, ", ")),
+			map[string]interface{}{"checks": checks}, reqID)
+		return
+	}
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"status": "ready",
+		"checks": checks,
+	}, reqID)
+}
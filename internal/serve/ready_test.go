@@ -0,0 +1,75 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func stubMuxReachable(t *testing.T, err error) {
+	t.Helper()
+	old := muxReachable
+	t.Cleanup(func() { muxReachable = old })
+	muxReachable = func(context.Context) error { return err }
+}
+
+func TestReady(t *testing.T) {
+	stubMuxReachable(t, nil)
+	srv, _ := setupTestServer(t)
+
+	get := func(path string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		srv.Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v: %s", path, err, rec.Body.String())
+		}
+		return rec.Code, body
+	}
+
+	// Not serving yet: live but not ready.
+	if code, _ := get("/health"); code != http.StatusOK {
+		t.Errorf("/health = %d, want 200", code)
+	}
+	code, body := get("/ready")
+	if code != http.StatusServiceUnavailable || body["status"] != "not_ready" {
+		t.Errorf("/ready before start = %d %v", code, body)
+	}
+
+	srv.accepting.Store(true)
+	if code, body := get("/ready"); code != http.StatusOK || body["status"] != "ready" || len(body["checks"].([]interface{})) != 4 {
+		t.Errorf("/ready = %d %v", code, body)
+	}
+	if code, _ := get("/api/v1/ready"); code != http.StatusOK {
+		t.Errorf("/api/v1/ready = %d, want 200", code)
+	}
+
+	stubMuxReachable(t, errors.New("tmux: permission denied"))
+	code, body = get("/api/v1/ready")
+	if code != http.StatusServiceUnavailable || body["error"] != "not ready: mux" || body["error_code"] != ErrCodeServiceUnavail {
+		t.Errorf("/api/v1/ready with mux down = %d %v", code, body)
+	}
+}
+
+func TestReadiness_MissingDependencies(t *testing.T) {
+	stubMuxReachable(t, nil)
+	srv := New(Config{})
+	srv.accepting.Store(true)
+
+	ready, checks := srv.Readiness(context.Background())
+	if ready {
+		t.Fatal("server without state store or event bus should not be ready")
+	}
+	failed := map[string]bool{}
+	for _, c := range checks {
+		if !c.OK {
+			failed[c.Name] = true
+		}
+	}
+	if len(failed) != 2 || !failed["state_store"] || !failed["event_bus"] {
+		t.Errorf("failed = %v", failed)
+	}
+}
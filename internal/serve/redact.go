@@ -59,7 +59,7 @@ func (s *Server) redactionMiddleware(next http.Handler) http.Handler {
 				body, err := io.ReadAll(r.Body)
 				r.Body.Close()
 				if err != nil {
-					writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "failed to read request body", nil, reqID)
+					writeBodyError(w, err, "failed to read request body", reqID)
 					return
 				}
 
@@ -0,0 +1,181 @@
+package serve
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+)
+
+// robotJobType is the job type of robot commands run in the background by
+// the jobs package, the same jobs `ntm robot job` shows.
+const robotJobType = "robot"
+
+// robotJobCommands are the robot subcommands a robot job may run. They only
+// read and report; `robot send` acts on panes and `robot job` would nest jobs.
+var robotJobCommands = map[string]bool{
+	"audit":          true,
+	"blame":          true,
+	"captures":       true,
+	"commands":       true,
+	"conflict-stats": true,
+	"conflicts":      true,
+	"crash":          true,
+	"crashes":        true,
+	"exchanges":      true,
+	"health":         true,
+	"plan":           true,
+	"ratelimit":      true,
+	"reports":        true,
+	"status":         true,
+	"summarize":      true,
+	"what-if":        true,
+}
+
+// robotJobRootFlags are ntm's root persistent flags (see internal/cli/root.go).
+// A job must not set them: they would point the worker at another config or
+// host, or loosen the server's redaction policy.
+var robotJobRootFlags = map[string]bool{
+	"allow-secret":    true,
+	"config":          true,
+	"json":            true,
+	"no-color":        true,
+	"profile-startup": true,
+	"redact":          true,
+	"ssh":             true,
+}
+
+// robotJobArgs returns the ntm arguments of a robot job request. Only the
+// robotJobCommands may run, and without ntm's root persistent flags.
+func robotJobArgs(params map[string]interface{}) ([]string, error) {
+	raw, ok := params["args"].([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, errors.New(`params.args must list the ntm arguments, e.g. ["robot", "summarize", "--session", "s", "--pane", "1"]`)
+	}
+	args := make([]string, 0, len(raw))
+	for _, a := range raw {
+		s, ok := a.(string)
+		if !ok {
+			return nil, errors.New("params.args must be strings")
+		}
+		args = append(args, s)
+	}
+	if args[0] != "robot" || len(args) < 2 || !robotJobCommands[args[1]] {
+		return nil, fmt.Errorf("robot jobs run one of the robot commands %s, not %q", strings.Join(slices.Sorted(maps.Keys(robotJobCommands)), ", "), strings.Join(args[:min(2, len(args))], " "))
+	}
+	for _, a := range args[2:] {
+		name, _, _ := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if strings.HasPrefix(a, "-") && robotJobRootFlags[name] {
+			return nil, fmt.Errorf("robot jobs may not set the global flag %s", a)
+		}
+	}
+	return args, nil
+}
+
+// fromRobotJob converts a robot job to the API's job. With detail, the
+// result carries the partial results and, once finished, the output.
+func (s *Server) fromRobotJob(job *jobs.Job, detail bool) *Job {
+	result := map[string]interface{}{
+		"args":     job.Args,
+		"partials": job.Partials,
+	}
+	if job.Message != "" {
+		result["message"] = job.Message
+	}
+	if job.ExitCode != nil {
+		result["exit_code"] = *job.ExitCode
+	}
+	if detail {
+		if out := robot.GetJobResult(s.robotJobs, job.ID); out.Success {
+			result["partials"] = out.Partials
+			if out.Result != nil {
+				result["result"] = out.Result
+			} else if out.Output != "" {
+				result["output"] = out.Output
+			}
+		}
+	}
+	return &Job{
+		ID:        job.ID,
+		Type:      robotJobType,
+		Status:    JobStatus(job.Status),
+		Progress:  job.Progress,
+		Result:    result,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: job.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// listRobotJobs returns the robot jobs, or none when they are disabled or
+// unreadable.
+func (s *Server) listRobotJobs() []*Job {
+	if s.robotJobs == nil {
+		return nil
+	}
+	list, err := s.robotJobs.List()
+	if err != nil {
+		return nil
+	}
+	out := make([]*Job, 0, len(list))
+	for _, job := range list {
+		out = append(out, s.fromRobotJob(job, false))
+	}
+	return out
+}
+
+// getRobotJob returns the robot job with id, or nil.
+func (s *Server) getRobotJob(id string) *Job {
+	if s.robotJobs == nil {
+		return nil
+	}
+	job, err := s.robotJobs.Get(id)
+	if err != nil {
+		return nil
+	}
+	return s.fromRobotJob(job, true)
+}
+
+// handleCreateRobotJob handles POST /api/v1/jobs for type "robot".
+func (s *Server) handleCreateRobotJob(w http.ResponseWriter, req CreateJobRequest, reqID string) {
+	if s.robotJobs == nil {
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeServiceUnavail, "robot jobs are not enabled on this server", nil, reqID)
+		return
+	}
+	args, err := robotJobArgs(req.Params)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	job, err := s.robotJobs.Submit(args)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	writeSuccessResponse(w, http.StatusAccepted, map[string]interface{}{
+		"job": s.fromRobotJob(job, false),
+	}, reqID)
+}
+
+// handleCancelRobotJob handles DELETE /api/v1/jobs/{id} for robot jobs.
+func (s *Server) handleCancelRobotJob(w http.ResponseWriter, job *Job, reqID string) {
+	if job.Status != JobStatusPending && job.Status != JobStatusRunning {
+		writeErrorResponse(w, http.StatusConflict, ErrCodeConflict, "job cannot be cancelled", map[string]interface{}{
+			"status": job.Status,
+		}, reqID)
+		return
+	}
+	if _, err := s.robotJobs.Cancel(job.ID); err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+		"job": s.getRobotJob(job.ID),
+	}, reqID)
+}
@@ -0,0 +1,107 @@
+package serve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
+)
+
+func robotJobRequest(method, id, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/jobs/"+id, strings.NewReader(body))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func decodeJob(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	job, _ := resp["job"].(map[string]interface{})
+	return job
+}
+
+func TestRobotJobs(t *testing.T) {
+	t.Parallel()
+	srv, _ := setupTestServer(t)
+	srv.robotJobs = jobs.NewStore(t.TempDir())
+
+	job, err := srv.robotJobs.Create([]string{"robot", "summarize", "--session", "s", "--pane", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.robotJobs.Reporter(job.ID).Progress(30, "summarizing chunk 1/3")
+	srv.robotJobs.Reporter(job.ID).Partial(map[string]string{"digest": "first chunk"})
+	srv.jobStore.Create("scan")
+
+	rec := httptest.NewRecorder()
+	srv.handleListJobs(rec, httptest.NewRequest(http.MethodGet, "/api/v1/jobs", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"type":"robot"`) || !strings.Contains(rec.Body.String(), `"type":"scan"`) {
+		t.Fatalf("list = %d %s, want both job kinds", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleGetJob(rec, robotJobRequest(http.MethodGet, job.ID, ""))
+	got := decodeJob(t, rec)
+	if rec.Code != http.StatusOK || got["progress"] != 30.0 {
+		t.Fatalf("get = %d %s", rec.Code, rec.Body.String())
+	}
+	result, _ := got["result"].(map[string]interface{})
+	if result["message"] != "summarizing chunk 1/3" || !strings.Contains(rec.Body.String(), "first chunk") {
+		t.Errorf("result = %v, want message and partials", result)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.handleCancelJob(rec, robotJobRequest(http.MethodDelete, job.ID, ""))
+	if got := decodeJob(t, rec); rec.Code != http.StatusOK || got["status"] != "cancelled" {
+		t.Fatalf("cancel = %d %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	srv.handleCancelJob(rec, robotJobRequest(http.MethodDelete, job.ID, ""))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("second cancel = %d, want 409", rec.Code)
+	}
+}
+
+func TestHandleCreateJob_Robot(t *testing.T) {
+	t.Parallel()
+	srv, _ := setupTestServer(t)
+
+	create := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.handleCreateJob(rec, httptest.NewRequest(http.MethodPost, "/api/v1/jobs", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := create(`{"type":"robot","params":{"args":["robot","status"]}}`); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("without JobsDir = %d, want 503", rec.Code)
+	}
+
+	srv.robotJobs = jobs.NewStore(t.TempDir())
+	for _, body := range []string{
+		`{"type":"robot"}`,
+		`{"type":"robot","params":{"args":["kill","s"]}}`,
+		`{"type":"robot","params":{"args":["robot","job","submit"]}}`,
+		`{"type":"robot","params":{"args":["robot",1]}}`,
+		`{"type":"robot","params":{"args":["robot"]}}`,
+		`{"type":"robot","params":{"args":["robot","send","--session","s","--msg","x"]}}`,
+		`{"type":"robot","params":{"args":["robot","--config","/tmp/x.toml","status"]}}`,
+		`{"type":"robot","params":{"args":["robot","summarize","--config","/tmp/x.toml"]}}`,
+		`{"type":"robot","params":{"args":["robot","status","--ssh=user@host"]}}`,
+		`{"type":"robot","params":{"args":["robot","status","--redact","off"]}}`,
+		`{"type":"robot","params":{"args":["robot","status","--allow-secret"]}}`,
+	} {
+		if rec := create(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s = %d, want 400", body, rec.Code)
+		}
+	}
+}
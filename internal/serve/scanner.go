@@ -647,7 +647,7 @@ func (s *Server) handleCreateBeadFromFinding(w http.ResponseWriter, r *http.Requ
 
 	// Check if bead already exists
 	if finding.BeadID != "" {
-		writeErrorResponse(w, http.StatusConflict, ErrCodeBadRequest,
+		writeErrorResponse(w, http.StatusConflict, ErrCodeConflict,
 			"Bead already created for this finding", map[string]interface{}{"bead_id": finding.BeadID}, reqID)
 		return
 	}
@@ -709,7 +709,7 @@ func (s *Server) handleCreateBeadFromFinding(w http.ResponseWriter, r *http.Requ
 	if err != nil {
 		slog.Error("failed to create bead from finding", "request_id", reqID,
 			"finding_id", findingID, "error", err)
-		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeBeadsUnavailable,
+		writeErrorResponse(w, http.StatusServiceUnavailable, ErrCodeBeadsUnavailable,
 			"Failed to create bead", map[string]interface{}{"error": err.Error()}, reqID)
 		return
 	}
@@ -2,11 +2,11 @@
 package serve
 
 import (
+	"bytes"
 	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
-	"crypto/sha256"
 	"crypto/subtle"
 	"crypto/tls"
 	"crypto/x509"
@@ -28,6 +28,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -35,11 +36,16 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/Dicklesworthstone/ntm/internal/agentmail"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
 	"github.com/Dicklesworthstone/ntm/internal/config"
+	"github.com/Dicklesworthstone/ntm/internal/deadline"
 	"github.com/Dicklesworthstone/ntm/internal/ensemble"
 	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/idempotency"
+	"github.com/Dicklesworthstone/ntm/internal/jobs"
 	"github.com/Dicklesworthstone/ntm/internal/kernel"
 	"github.com/Dicklesworthstone/ntm/internal/metrics"
+	"github.com/Dicklesworthstone/ntm/internal/pagination"
 	"github.com/Dicklesworthstone/ntm/internal/redaction"
 	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/state"
@@ -62,12 +68,15 @@ type Server struct {
 
 	corsAllowedOrigins []string
 	jwksCache          *jwksCache
+	oidcTokens         *oidcTokenCache
+	oidcDiscovery      *oidcDiscovery
 
 	// Idempotency support
 	idempotencyStore *IdempotencyStore
 
 	// Job management
-	jobStore *JobStore
+	jobStore  *JobStore
+	robotJobs *jobs.Store
 
 	// Chi router for /api/v1
 	router chi.Router
@@ -85,6 +94,22 @@ type Server struct {
 
 	// Redaction configuration for REST API
 	redactionCfg *RedactionConfig
+
+	// Response compression (nil when disabled) and HTTP/2 settings
+	compression       *compression
+	compressionConfig CompressionConfig
+	disableHTTP2      bool
+
+	// Request size and duration limits
+	limits       limits
+	limitsConfig LimitsConfig
+
+	// accepting is set while Start is serving, for /ready
+	accepting atomic.Bool
+
+	h2c          bool
+	listen       string
+	shareKeyPath string
 }
 
 // AuthMode configures authentication for the server.
@@ -109,7 +134,11 @@ type AuthConfig struct {
 type OIDCConfig struct {
 	Issuer   string
 	Audience string
-	JWKSURL  string
+	// JWKSURL is optional; when empty it is discovered from the issuer's
+	// /.well-known/openid-configuration.
+	JWKSURL string
+	// CacheTTL bounds how long keys, discovery results, and validated
+	// tokens are cached.
 	CacheTTL time.Duration
 }
 
@@ -124,6 +153,10 @@ type MTLSConfig struct {
 type Config struct {
 	Host string
 	Port int
+	// Listen, when set to unix:///path/ntm.sock, serves on a Unix domain
+	// socket instead of Host:Port. The socket is created mode 0600, so file
+	// permissions rather than the network decide who may connect.
+	Listen string
 	// PublicBaseURL advertises the externally reachable base URL for clients.
 	// Optional: leave empty to derive from host/port in documentation or clients.
 	PublicBaseURL string
@@ -132,6 +165,23 @@ type Config struct {
 	Auth          AuthConfig
 	// AllowedOrigins controls CORS origin allowlist. Empty means default localhost only.
 	AllowedOrigins []string
+	// Compression configures gzip response compression.
+	Compression CompressionConfig
+	// Limits bounds request bodies, handler run time, and connection
+	// timeouts.
+	Limits LimitsConfig
+	// DisableHTTP2 restricts the server to HTTP/1.1. HTTP/2 is otherwise
+	// negotiated via ALPN on TLS (mtls) listeners.
+	DisableHTTP2 bool
+	// H2C additionally accepts cleartext HTTP/2 with prior knowledge on
+	// non-TLS listeners, for trusted reverse proxies.
+	H2C bool
+	// ShareKeyPath is the signing key for `ntm share` tokens. Empty means
+	// share.DefaultKeyPath.
+	ShareKeyPath string
+	// JobsDir holds the background robot jobs shared with `ntm robot job`
+	// (job type "robot"). Empty disables robot jobs.
+	JobsDir string
 }
 
 const (
@@ -158,10 +208,12 @@ type APIResponse struct {
 // APIError represents a structured error response.
 type APIError struct {
 	APIResponse
-	Error     string                 `json:"error"`
-	ErrorCode string                 `json:"error_code,omitempty"`
-	Details   map[string]interface{} `json:"details,omitempty"`
-	Hint      string                 `json:"hint,omitempty"`
+	Error       string                 `json:"error"`
+	ErrorCode   string                 `json:"error_code,omitempty"`
+	ErrorID     string                 `json:"error_id,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+	Hint        string                 `json:"hint,omitempty"`
+	Remediation *robot.Remediation     `json:"remediation,omitempty"`
 }
 
 // Common error codes (matching robot mode conventions).
@@ -175,7 +227,10 @@ const (
 	ErrCodeInternalError    = "INTERNAL_ERROR"
 	ErrCodeServiceUnavail   = "SERVICE_UNAVAILABLE"
 	ErrCodeIdempotentReplay = "IDEMPOTENT_REPLAY"
+	ErrCodeIdempotencyReuse = "IDEMPOTENCY_KEY_REUSED"
 	ErrCodeJobPending       = "JOB_PENDING"
+	ErrCodePayloadTooLarge  = "PAYLOAD_TOO_LARGE"
+	ErrCodeTimeout          = "TIMEOUT"
 )
 
 // IdempotencyStore caches responses by idempotency key.
@@ -188,9 +243,19 @@ type IdempotencyStore struct {
 }
 
 type idempotencyEntry struct {
-	response   []byte
-	statusCode int
-	createdAt  time.Time
+	response    []byte
+	statusCode  int
+	contentType string
+	fingerprint string
+	pending     bool // request still being handled
+	createdAt   time.Time
+}
+
+func (e *idempotencyEntry) expired(ttl time.Duration, now time.Time) bool {
+	if e.pending {
+		return now.Sub(e.createdAt) > idempotency.PendingTimeout
+	}
+	return now.Sub(e.createdAt) > ttl
 }
 
 // NewIdempotencyStore creates an idempotency cache with the given TTL.
@@ -227,7 +292,7 @@ func (s *IdempotencyStore) cleanup() {
 			s.mu.Lock()
 			now := time.Now()
 			for key, entry := range s.entries {
-				if now.Sub(entry.createdAt) > s.ttl {
+				if entry.expired(s.ttl, now) {
 					delete(s.entries, key)
 				}
 			}
@@ -241,10 +306,10 @@ func (s *IdempotencyStore) Get(key string) ([]byte, int, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	entry, ok := s.entries[key]
-	if !ok {
+	if !ok || entry.pending {
 		return nil, 0, false
 	}
-	if time.Since(entry.createdAt) > s.ttl {
+	if entry.expired(s.ttl, time.Now()) {
 		return nil, 0, false
 	}
 	return entry.response, entry.statusCode, true
@@ -261,6 +326,50 @@ func (s *IdempotencyStore) Set(key string, response []byte, statusCode int) {
 	}
 }
 
+// Begin claims key for a request with the given fingerprint. It returns
+// the recorded entry when the response should be replayed, or nil when the
+// caller should handle the request and then call Complete or Abandon.
+// idempotency.ErrInProgress and idempotency.ErrMismatch report keys that
+// cannot be used.
+func (s *IdempotencyStore) Begin(key, fingerprint string) (*idempotencyEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && !entry.expired(s.ttl, now) {
+		if entry.fingerprint != fingerprint {
+			return nil, idempotency.ErrMismatch
+		}
+		if entry.pending {
+			return nil, idempotency.ErrInProgress
+		}
+		return entry, nil
+	}
+	s.entries[key] = &idempotencyEntry{fingerprint: fingerprint, pending: true, createdAt: now}
+	return nil, nil
+}
+
+// Complete records the response for a key claimed by Begin.
+func (s *IdempotencyStore) Complete(key, fingerprint, contentType string, response []byte, statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &idempotencyEntry{
+		response:    response,
+		statusCode:  statusCode,
+		contentType: contentType,
+		fingerprint: fingerprint,
+		createdAt:   time.Now(),
+	}
+}
+
+// Abandon releases a key claimed by Begin without recording a response.
+func (s *IdempotencyStore) Abandon(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry, ok := s.entries[key]; ok && entry.pending {
+		delete(s.entries, key)
+	}
+}
+
 // Job represents an asynchronous operation.
 type Job struct {
 	ID        string                 `json:"id"`
@@ -396,6 +505,7 @@ type WSEvent struct {
 	Seq       int64         `json:"seq"`
 	Topic     string        `json:"topic"`
 	EventType string        `json:"event_type"`
+	Actor     string        `json:"actor,omitempty"` // Principal whose request caused the event
 	Data      interface{}   `json:"data"`
 }
 
@@ -546,11 +656,18 @@ func redactWSEventData(data interface{}, cfg redaction.Config) interface{} {
 
 // Publish publishes an event to a topic.
 func (h *WSHub) Publish(topic, eventType string, data interface{}) {
+	h.PublishAs(topic, eventType, "", data)
+}
+
+// PublishAs publishes an event attributed to actor, the principal whose
+// request caused it.
+func (h *WSHub) PublishAs(topic, eventType, actor string, data interface{}) {
 	event := &WSEvent{
 		Type:      WSMsgEvent,
 		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
 		Topic:     topic,
 		EventType: eventType,
+		Actor:     actor,
 		Data:      data,
 	}
 	select {
@@ -726,13 +843,8 @@ func ValidateConfig(cfg Config) error {
 	if mode == AuthModeAPIKey && cfg.Auth.APIKey == "" {
 		return fmt.Errorf("auth mode api_key requires --api-key")
 	}
-	if mode == AuthModeOIDC {
-		if cfg.Auth.OIDC.Issuer == "" {
-			return fmt.Errorf("auth mode oidc requires --oidc-issuer")
-		}
-		if cfg.Auth.OIDC.JWKSURL == "" {
-			return fmt.Errorf("auth mode oidc requires --oidc-jwks-url")
-		}
+	if mode == AuthModeOIDC && cfg.Auth.OIDC.Issuer == "" {
+		return fmt.Errorf("auth mode oidc requires --oidc-issuer")
 	}
 	if mode == AuthModeMTLS {
 		if cfg.Auth.MTLS.CertFile == "" || cfg.Auth.MTLS.KeyFile == "" || cfg.Auth.MTLS.ClientCAFile == "" {
@@ -740,7 +852,21 @@ func ValidateConfig(cfg Config) error {
 		}
 	}
 
-	if mode == AuthModeLocal && !isLoopbackHost(cfg.Host) {
+	if err := validateCompression(cfg.Compression); err != nil {
+		return err
+	}
+	if err := validateLimits(cfg.Limits); err != nil {
+		return err
+	}
+	if cfg.H2C && cfg.DisableHTTP2 {
+		return fmt.Errorf("h2c requires HTTP/2; remove --no-http2")
+	}
+
+	if err := validateListen(cfg.Listen); err != nil {
+		return err
+	}
+
+	if mode == AuthModeLocal && cfg.Listen == "" && !isLoopbackHost(cfg.Host) {
 		return fmt.Errorf("refusing to bind %s without auth; set --auth-mode and required credentials", cfg.Host)
 	}
 	if cfg.PublicBaseURL != "" {
@@ -765,9 +891,22 @@ func New(cfg Config) *Server {
 		sseClients:         make(map[chan events.BusEvent]struct{}),
 		corsAllowedOrigins: cfg.AllowedOrigins,
 		jwksCache:          newJWKSCache(cfg.Auth.OIDC.CacheTTL),
+		oidcTokens:         newOIDCTokenCache(cfg.Auth.OIDC.CacheTTL),
+		oidcDiscovery:      newOIDCDiscovery(cfg.Auth.OIDC.CacheTTL),
 		idempotencyStore:   NewIdempotencyStore(24 * time.Hour),
 		jobStore:           NewJobStore(),
 		wsHub:              NewWSHub(),
+		compression:        newCompression(cfg.Compression),
+		compressionConfig:  cfg.Compression,
+		limits:             newLimits(cfg.Limits),
+		limitsConfig:       cfg.Limits,
+		disableHTTP2:       cfg.DisableHTTP2,
+		h2c:                cfg.H2C,
+		listen:             cfg.Listen,
+		shareKeyPath:       cfg.ShareKeyPath,
+	}
+	if cfg.JobsDir != "" {
+		s.robotJobs = jobs.NewStore(cfg.JobsDir)
 	}
 
 	// Initialize pane output streaming
@@ -794,15 +933,19 @@ func (s *Server) buildRouter() chi.Router {
 	// Base middleware stack
 	r.Use(chimw.RealIP)
 	r.Use(s.requestIDMiddlewareFunc)
+	r.Use(s.compressMiddleware) // outside the recoverer so 500s are written through it
 	r.Use(s.recovererMiddleware)
 	r.Use(s.loggingMiddlewareFunc)
+	r.Use(s.limitsMiddleware) // before anything reads the body
 	r.Use(s.corsMiddlewareFunc)
 	r.Use(s.authMiddlewareFunc)
 	r.Use(s.rbacMiddleware)      // Extract role from auth claims
+	r.Use(s.apiAuditMiddleware)  // Audit mutating calls with the caller's principal
 	r.Use(s.redactionMiddleware) // Redact sensitive content in requests/responses
 
-	// Health check (no versioning)
+	// Liveness and readiness probes (no versioning)
 	r.Get("/health", s.handleHealth)
+	r.Get("/ready", s.handleReady)
 
 	// SSE event stream (no versioning)
 	r.Get("/events", s.handleEventStream)
@@ -826,8 +969,11 @@ func (s *Server) buildRouter() chi.Router {
 
 	// /api/v1 routes (canonical)
 	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(s.idempotencyMiddleware)
+
 		// System endpoints (read-only, require PermReadHealth)
 		r.With(s.RequirePermission(PermReadHealth)).Get("/health", s.handleHealthV1)
+		r.With(s.RequirePermission(PermReadHealth)).Get("/ready", s.handleReadyV1)
 		r.With(s.RequirePermission(PermReadHealth)).Get("/version", s.handleVersionV1)
 		r.With(s.RequirePermission(PermReadHealth)).Get("/capabilities", s.handleCapabilitiesV1)
 		r.With(s.RequirePermission(PermReadHealth)).Get("/deps", s.handleDepsV1)
@@ -889,7 +1035,6 @@ func (s *Server) buildRouter() chi.Router {
 
 		// Jobs API - read requires PermReadJobs, write requires PermWriteJobs
 		r.Route("/jobs", func(r chi.Router) {
-			r.Use(s.idempotencyMiddleware)
 			r.With(s.RequirePermission(PermReadJobs)).Get("/", s.handleListJobs)
 			r.With(s.RequirePermission(PermWriteJobs)).Post("/", s.handleCreateJob)
 			r.With(s.RequirePermission(PermReadJobs)).Get("/{id}", s.handleGetJob)
@@ -959,6 +1104,10 @@ func (s *Server) buildRouter() chi.Router {
 			r.With(s.RequirePermission(PermReadSessions)).Get("/stats", s.handleHistoryStatsV1)
 		})
 
+		// Audit log search and archived pane captures, paged by cursor
+		r.With(s.RequirePermission(PermReadEvents)).Get("/audit", s.handleAuditV1)
+		r.With(s.RequirePermission(PermReadSessions)).Get("/captures", s.handleCapturesV1)
+
 		// Route API - agent routing recommendations
 		r.Route("/route", func(r chi.Router) {
 			r.With(s.RequirePermission(PermReadSessions)).Get("/", s.handleRouteV1)
@@ -1017,21 +1166,35 @@ func (s *Server) Start(ctx context.Context) error {
 	}
 
 	s.server = &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", s.host, s.port),
-		Handler:      s.router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 0, // Disabled to support long-lived SSE streams at /events
-		IdleTimeout:  60 * time.Second,
-	}
-
-	scheme := "http"
-	if s.auth.Mode == AuthModeMTLS {
-		scheme = "https"
+		Addr:              fmt.Sprintf("%s:%d", s.host, s.port),
+		Handler:           s.router,
+		ReadHeaderTimeout: s.limits.readHeaderTimeout,
+		ReadTimeout:       s.limits.readTimeout,
+		WriteTimeout:      0, // Disabled to support long-lived SSE streams at /events; handlers are bounded by limitsMiddleware
+		IdleTimeout:       s.limits.idleTimeout,
+		MaxHeaderBytes:    s.limits.maxHeaderBytes,
+		Protocols:         s.httpProtocols(),
+	}
+
+	var ln net.Listener
+	if path := unixSocketPath(s.listen); path != "" {
+		var err error
+		if ln, err = listenUnix(path); err != nil {
+			return fmt.Errorf("listen on %s: %w", s.listen, err)
+		}
+		log.Printf("Starting NTM server on %s (auth=%s)", s.listen, s.auth.Mode)
+	} else {
+		scheme := "http"
+		if s.auth.Mode == AuthModeMTLS {
+			scheme = "https"
+		}
+		log.Printf("Starting NTM server on %s://%s:%d (auth=%s)", scheme, s.host, s.port, s.auth.Mode)
 	}
-	log.Printf("Starting NTM server on %s://%s:%d (auth=%s)", scheme, s.host, s.port, s.auth.Mode)
 
 	// Start server in goroutine
 	errCh := make(chan error, 1)
+	s.accepting.Store(true)
+	defer s.accepting.Store(false)
 	go func() {
 		var err error
 		if s.auth.Mode == AuthModeMTLS {
@@ -1041,7 +1204,13 @@ func (s *Server) Start(ctx context.Context) error {
 				return
 			}
 			s.server.TLSConfig = tlsConfig
-			err = s.server.ListenAndServeTLS(s.auth.MTLS.CertFile, s.auth.MTLS.KeyFile)
+			if ln != nil {
+				err = s.server.ServeTLS(ln, s.auth.MTLS.CertFile, s.auth.MTLS.KeyFile)
+			} else {
+				err = s.server.ListenAndServeTLS(s.auth.MTLS.CertFile, s.auth.MTLS.KeyFile)
+			}
+		} else if ln != nil {
+			err = s.server.Serve(ln)
 		} else {
 			err = s.server.ListenAndServe()
 		}
@@ -1054,6 +1223,7 @@ func (s *Server) Start(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		log.Println("Shutting down server...")
+		s.accepting.Store(false)
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		return s.server.Shutdown(shutdownCtx)
@@ -1075,6 +1245,11 @@ func (s *Server) validate() error {
 		StateStore:     s.stateStore,
 		Auth:           s.auth,
 		AllowedOrigins: s.corsAllowedOrigins,
+		Compression:    s.compressionConfig,
+		Limits:         s.limitsConfig,
+		DisableHTTP2:   s.disableHTTP2,
+		H2C:            s.h2c,
+		Listen:         s.listen,
 	}
 	applyDefaults(&cfg)
 	mode, err := ParseAuthMode(string(cfg.Auth.Mode))
@@ -1104,13 +1279,28 @@ func (s *Server) buildMTLSConfig() (*tls.Config, error) {
 	if !caPool.AppendCertsFromPEM(caPEM) {
 		return nil, fmt.Errorf("parse mtls CA: no certs found")
 	}
+	nextProtos := []string{"h2", "http/1.1"}
+	if s.disableHTTP2 {
+		nextProtos = []string{"http/1.1"}
+	}
 	return &tls.Config{
 		ClientCAs:  caPool,
 		ClientAuth: tls.RequireAndVerifyClientCert,
 		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
 	}, nil
 }
 
+// httpProtocols returns the protocols the server accepts: HTTP/1.1 always,
+// HTTP/2 over TLS unless disabled, and cleartext HTTP/2 only with H2C.
+func (s *Server) httpProtocols() *http.Protocols {
+	p := new(http.Protocols)
+	p.SetHTTP1(true)
+	p.SetHTTP2(!s.disableHTTP2)
+	p.SetUnencryptedHTTP2(s.h2c && !s.disableHTTP2)
+	return p
+}
+
 // requestIDMiddleware assigns a request ID and stores it in context and response headers.
 // Deprecated: Use requestIDMiddlewareFunc for chi router.
 func requestIDMiddleware(next http.Handler) http.Handler {
@@ -1177,7 +1367,8 @@ func (s *Server) corsMiddlewareFunc(next http.Handler) http.Handler {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Vary", "Origin")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key, "+requestIDHeader)
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Idempotency-Key, If-None-Match, "+requestIDHeader)
+			w.Header().Set("Access-Control-Expose-Headers", "ETag, "+requestIDHeader)
 		}
 
 		if r.Method == "OPTIONS" {
@@ -1192,27 +1383,37 @@ func (s *Server) corsMiddlewareFunc(next http.Handler) http.Handler {
 // authMiddlewareFunc is the chi middleware version.
 func (s *Server) authMiddlewareFunc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tok := shareTokenFromRequest(r); tok != "" {
+			s.serveShareRequest(w, r, next, tok)
+			return
+		}
 		if s.auth.Mode == AuthModeLocal || s.auth.Mode == "" || r.Method == http.MethodOptions {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		if err := s.authenticateRequest(r); err != nil {
+		claims, err := s.authenticateRequest(r)
+		if err != nil {
 			reqID := requestIDFromContext(r.Context())
 			log.Printf("auth failed mode=%s path=%s remote=%s request_id=%s err=%v", s.auth.Mode, r.URL.Path, r.RemoteAddr, reqID, err)
 			writeErrorResponse(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized", nil, reqID)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withAuthClaims(r, claims))
 	})
 }
 
-// idempotencyMiddleware handles Idempotency-Key header for mutating requests.
+// idempotencyMiddleware handles the Idempotency-Key header for mutating
+// requests. A key is scoped to the method and path and bound to the request
+// body: a retry replays the original 2xx response, a concurrent retry gets
+// 409, and reusing the key for a different request gets 422.
 func (s *Server) idempotencyMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Only apply to mutating methods
-		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -1222,23 +1423,62 @@ func (s *Server) idempotencyMiddleware(next http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 			return
 		}
+		reqID := requestIDFromContext(r.Context())
+		if err := idempotency.ValidateKey(key); err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+			return
+		}
 
-		// Check cache
-		if cached, status, ok := s.idempotencyStore.Get(key); ok {
-			w.Header().Set("Content-Type", "application/json")
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				writeBodyError(w, err, "reading request body: "+err.Error(), reqID)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		scoped := r.Method + " " + r.URL.Path + "\x00" + key
+		fingerprint := idempotency.Fingerprint(r.Method, r.URL.Path, r.URL.RawQuery, string(body))
+
+		cached, err := s.idempotencyStore.Begin(scoped, fingerprint)
+		switch {
+		case errors.Is(err, idempotency.ErrMismatch):
+			writeErrorResponse(w, http.StatusUnprocessableEntity, ErrCodeIdempotencyReuse, err.Error(),
+				map[string]interface{}{"hint": "Use a new Idempotency-Key for a different request"}, reqID)
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			writeErrorResponse(w, http.StatusConflict, ErrCodeConflict, err.Error(),
+				map[string]interface{}{"hint": "Retry after the original request completes"}, reqID)
+			return
+		case cached != nil:
+			contentType := cached.contentType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			w.Header().Set("Content-Type", contentType)
 			w.Header().Set("X-Idempotent-Replay", "true")
-			w.WriteHeader(status)
-			_, _ = w.Write(cached) // Best-effort: client may have disconnected
+			w.WriteHeader(cached.statusCode)
+			_, _ = w.Write(cached.response) // Best-effort: client may have disconnected
 			return
 		}
 
-		// Capture response
+		// Capture response; release the key if the handler fails or panics.
 		rec := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		completed := false
+		defer func() {
+			if !completed {
+				s.idempotencyStore.Abandon(scoped)
+			}
+		}()
 		next.ServeHTTP(rec, r)
 
 		// Cache successful responses
 		if rec.statusCode >= 200 && rec.statusCode < 300 {
-			s.idempotencyStore.Set(key, rec.body, rec.statusCode)
+			s.idempotencyStore.Complete(scoped, fingerprint, w.Header().Get("Content-Type"), rec.body, rec.statusCode)
+			completed = true
 		}
 	})
 }
@@ -1260,6 +1500,13 @@ func (r *responseRecorder) Write(b []byte) (int, error) {
 	return r.ResponseWriter.Write(b)
 }
 
+// Flush forwards to the underlying writer so streaming handlers still work.
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 func (r *responseRecorder) Bytes() []byte {
 	return r.body
 }
@@ -1323,23 +1570,30 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 // authMiddleware enforces configured authentication for all routes.
 func (s *Server) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tok := shareTokenFromRequest(r); tok != "" {
+			s.serveShareRequest(w, r, next, tok)
+			return
+		}
 		if s.auth.Mode == AuthModeLocal || s.auth.Mode == "" || r.Method == http.MethodOptions {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		if err := s.authenticateRequest(r); err != nil {
+		claims, err := s.authenticateRequest(r)
+		if err != nil {
 			reqID := requestIDFromContext(r.Context())
 			log.Printf("auth failed mode=%s path=%s remote=%s request_id=%s err=%v", s.auth.Mode, r.URL.Path, r.RemoteAddr, reqID, err)
 			writeError(w, http.StatusUnauthorized, "unauthorized")
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, withAuthClaims(r, claims))
 	})
 }
 
-func (s *Server) authenticateRequest(r *http.Request) error {
+// authenticateRequest verifies the request's credentials and returns the
+// claims identifying the principal (nil in local mode).
+func (s *Server) authenticateRequest(r *http.Request) (map[string]interface{}, error) {
 	switch s.auth.Mode {
 	case AuthModeAPIKey:
 		return s.authenticateAPIKey(r)
@@ -1348,39 +1602,45 @@ func (s *Server) authenticateRequest(r *http.Request) error {
 	case AuthModeMTLS:
 		return s.authenticateMTLS(r)
 	case AuthModeLocal, "":
-		return nil
+		return nil, nil
 	default:
-		return fmt.Errorf("unsupported auth mode %q", s.auth.Mode)
+		return nil, fmt.Errorf("unsupported auth mode %q", s.auth.Mode)
 	}
 }
 
-func (s *Server) authenticateAPIKey(r *http.Request) error {
+func (s *Server) authenticateAPIKey(r *http.Request) (map[string]interface{}, error) {
 	if s.auth.APIKey == "" {
-		return errors.New("api key not configured")
+		return nil, errors.New("api key not configured")
 	}
 	key := extractAPIKey(r)
 	if key == "" {
-		return errors.New("missing api key")
+		return nil, errors.New("missing api key")
 	}
 	if subtle.ConstantTimeCompare([]byte(key), []byte(s.auth.APIKey)) != 1 {
-		return errors.New("invalid api key")
+		return nil, errors.New("invalid api key")
 	}
-	return nil
+	id := apiKeyID(key)
+	return map[string]interface{}{"sub": id, "key_id": id}, nil
 }
 
-func (s *Server) authenticateOIDC(r *http.Request) error {
+func (s *Server) authenticateOIDC(r *http.Request) (map[string]interface{}, error) {
 	token := extractBearerToken(r)
 	if token == "" {
-		return errors.New("missing bearer token")
+		return nil, errors.New("missing bearer token")
 	}
 	return s.validateOIDCToken(r.Context(), token)
 }
 
-func (s *Server) authenticateMTLS(r *http.Request) error {
+func (s *Server) authenticateMTLS(r *http.Request) (map[string]interface{}, error) {
 	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
-		return errors.New("missing client certificate")
+		return nil, errors.New("missing client certificate")
 	}
-	return nil
+	subject := r.TLS.PeerCertificates[0].Subject
+	id := subject.CommonName
+	if id == "" {
+		id = subject.String()
+	}
+	return map[string]interface{}{"sub": id}, nil
 }
 
 // writeJSON writes a JSON response.
@@ -1429,16 +1689,19 @@ func writeErrorResponse(w http.ResponseWriter, status int, code, message string,
 		}
 	}
 
+	info, _ := robot.LookupErrorCode(code)
 	resp := APIError{
 		APIResponse: APIResponse{
 			Success:   false,
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
 			RequestID: requestID,
 		},
-		Error:     message,
-		ErrorCode: code,
-		Details:   details,
-		Hint:      hint,
+		Error:       message,
+		ErrorCode:   code,
+		ErrorID:     info.ID,
+		Details:     details,
+		Hint:        hint,
+		Remediation: robot.RemediationFor(code, hint),
 	}
 	writeJSON(w, status, resp)
 }
@@ -1456,7 +1719,8 @@ func writeSuccessResponse(w http.ResponseWriter, status int, data map[string]int
 	writeJSON(w, status, data)
 }
 
-// handleHealth handles health check requests.
+// handleHealth handles /health, the liveness probe: it answers whenever the
+// process can serve HTTP. Dependency checks live in /ready.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -1481,16 +1745,27 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessions, err := s.stateStore.ListSessions("")
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sessions, next, err := s.stateStore.ListSessionsPage("", cursor, limit)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"success":  true,
-		"sessions": sessions,
-		"count":    len(sessions),
+	writeCacheableJSON(w, r, map[string]interface{}{
+		"success":     true,
+		"sessions":    sessions,
+		"count":       len(sessions),
+		"has_more":    next != "",
+		"next_cursor": next,
 	})
 }
 
@@ -1537,7 +1812,7 @@ func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	writeCacheableJSON(w, r, map[string]interface{}{
 		"success": true,
 		"session": session,
 	})
@@ -1556,7 +1831,7 @@ func (s *Server) handleSessionAgents(w http.ResponseWriter, r *http.Request, ses
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	writeCacheableJSON(w, r, map[string]interface{}{
 		"success":    true,
 		"session_id": sessionID,
 		"agents":     agents,
@@ -1571,23 +1846,55 @@ func (s *Server) handleSessionEvents(w http.ResponseWriter, r *http.Request, ses
 		return
 	}
 
-	// Get recent events from event bus history
-	eventsData := s.eventBus.History(100)
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := s.sessionEventsPage(sessionID, cursor, limit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeCacheableJSON(w, r, map[string]interface{}{
+		"success":     true,
+		"session_id":  sessionID,
+		"events":      page.Items,
+		"count":       len(page.Items),
+		"total":       page.Total,
+		"has_more":    page.HasMore(),
+		"next_cursor": page.NextCursor,
+	})
+}
 
-	// Filter to session if specified
-	var filtered []events.BusEvent
-	for _, e := range eventsData {
+// pageParams reads the limit and cursor query parameters of a list
+// endpoint. Lists are always bounded: limit defaults to
+// pagination.DefaultLimit.
+func pageParams(r *http.Request) (int, string, error) {
+	limit, err := pagination.ParseLimit(r.URL.Query().Get("limit"), pagination.DefaultLimit)
+	if err != nil {
+		return 0, "", err
+	}
+	return limit, r.URL.Query().Get("cursor"), nil
+}
+
+// eventPageKey orders events newest first; the type and session break ties
+// between events with the same timestamp.
+func eventPageKey(e events.BusEvent) string {
+	return e.EventTimestamp().UTC().Format("2006-01-02T15:04:05.000000000Z") + "|" + e.EventType() + "|" + e.EventSession()
+}
+
+// sessionEventsPage pages the event bus history for sessionID (all
+// sessions if empty), newest first.
+func (s *Server) sessionEventsPage(sessionID, cursor string, limit int) (pagination.Page[events.BusEvent], error) {
+	filtered := []events.BusEvent{}
+	for _, e := range s.eventBus.History(0) {
 		if sessionID == "" || e.EventSession() == sessionID {
 			filtered = append(filtered, e)
 		}
 	}
-
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"success":    true,
-		"session_id": sessionID,
-		"events":     filtered,
-		"count":      len(filtered),
-	})
+	return pagination.Slice(filtered, eventPageKey, true, cursor, limit)
 }
 
 // handleRobotStatus handles /api/robot/status - proxies to robot status.
@@ -1612,10 +1919,19 @@ func (s *Server) handleRobotHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"success":   true,
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"note":      "full robot health requires robot package integration",
+	writeJSON(w, http.StatusOK, s.deepHealth(r.Context()))
+}
+
+// deepHealth runs the robot component probes using the server's project
+// directory and OIDC configuration.
+func (s *Server) deepHealth(ctx context.Context) *robot.DeepHealthOutput {
+	s.mu.Lock()
+	projectDir := s.projectDir
+	s.mu.Unlock()
+
+	return robot.GetDeepHealth(ctx, robot.DeepHealthOptions{
+		ProjectDir: projectDir,
+		JWKSURL:    s.auth.OIDC.JWKSURL,
 	})
 }
 
@@ -1640,6 +1956,9 @@ func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
 
+	// Optional session filter; share links always have one.
+	session := r.URL.Query().Get("session")
+
 	// Create client channel
 	clientCh := make(chan events.BusEvent, 100)
 	s.addSSEClient(clientCh)
@@ -1666,6 +1985,9 @@ func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
 		case <-ctx.Done():
 			return
 		case event := <-clientCh:
+			if session != "" && event.EventSession() != session {
+				continue
+			}
 			data, err := json.Marshal(map[string]interface{}{
 				"type":      event.EventType(),
 				"timestamp": event.EventTimestamp().Format(time.RFC3339),
@@ -1829,42 +2151,50 @@ func isLoopbackHost(host string) bool {
 	return ip.IsLoopback()
 }
 
-func (s *Server) validateOIDCToken(ctx context.Context, token string) error {
-	if s.auth.OIDC.JWKSURL == "" || s.auth.OIDC.Issuer == "" {
-		return errors.New("oidc config incomplete")
+// validateOIDCToken verifies token and returns its claims.
+func (s *Server) validateOIDCToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	if s.auth.OIDC.Issuer == "" {
+		return nil, errors.New("oidc config incomplete")
+	}
+	if claims, ok := s.oidcTokens.get(token, time.Now()); ok {
+		return claims, nil
 	}
 	header, claims, signingInput, signature, err := parseJWT(token)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if header.Alg != "RS256" {
-		return fmt.Errorf("unsupported jwt alg %q", header.Alg)
+	if !supportedJWTAlgs[header.Alg] {
+		return nil, fmt.Errorf("unsupported jwt alg %q", header.Alg)
 	}
 	if iss, ok := claimString(claims, "iss"); !ok || iss != s.auth.OIDC.Issuer {
-		return fmt.Errorf("invalid issuer")
+		return nil, fmt.Errorf("invalid issuer")
 	}
 	if s.auth.OIDC.Audience != "" && !claimAudienceContains(claims, s.auth.OIDC.Audience) {
-		return fmt.Errorf("invalid audience")
+		return nil, fmt.Errorf("invalid audience")
 	}
 	if exp, ok := claimInt64(claims, "exp"); ok {
 		if time.Now().After(time.Unix(exp, 0).Add(30 * time.Second)) {
-			return fmt.Errorf("token expired")
+			return nil, fmt.Errorf("token expired")
 		}
 	}
 	if nbf, ok := claimInt64(claims, "nbf"); ok {
 		if time.Now().Before(time.Unix(nbf, 0).Add(-30 * time.Second)) {
-			return fmt.Errorf("token not yet valid")
+			return nil, fmt.Errorf("token not yet valid")
 		}
 	}
-	key, err := s.jwksCache.getKey(ctx, s.auth.OIDC.JWKSURL, header.Kid)
+	jwksURL, err := s.oidcJWKSURL(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	hash := sha256.Sum256([]byte(signingInput))
-	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
-		return fmt.Errorf("invalid token signature")
+	key, err := s.jwksCache.getKey(ctx, jwksURL, header.Kid)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if err := verifyJWTSignature(header.Alg, key, signingInput, signature); err != nil {
+		return nil, err
+	}
+	s.oidcTokens.add(token, claims, time.Now())
+	return claims, nil
 }
 
 type jwtHeader struct {
@@ -1948,22 +2278,26 @@ func claimAudienceContains(claims map[string]interface{}, expected string) bool
 
 type jwksCache struct {
 	mu        sync.Mutex
-	keys      map[string]*rsa.PublicKey
+	keys      map[string]crypto.PublicKey
 	fetchedAt time.Time
 	ttl       time.Duration
 }
 
+// jwksMinRefetch limits how often an unknown kid forces a JWKS refetch, so
+// tokens with bogus kids cannot make every request hit the issuer.
+const jwksMinRefetch = 30 * time.Second
+
 func newJWKSCache(ttl time.Duration) *jwksCache {
 	if ttl <= 0 {
 		ttl = defaultJWKSCacheTTL
 	}
 	return &jwksCache{
-		keys: make(map[string]*rsa.PublicKey),
+		keys: make(map[string]crypto.PublicKey),
 		ttl:  ttl,
 	}
 }
 
-func (c *jwksCache) getKey(ctx context.Context, jwksURL, kid string) (*rsa.PublicKey, error) {
+func (c *jwksCache) getKey(ctx context.Context, jwksURL, kid string) (crypto.PublicKey, error) {
 	c.mu.Lock()
 	if time.Since(c.fetchedAt) < c.ttl && len(c.keys) > 0 {
 		if kid == "" && len(c.keys) == 1 {
@@ -1976,6 +2310,10 @@ func (c *jwksCache) getKey(ctx context.Context, jwksURL, kid string) (*rsa.Publi
 			c.mu.Unlock()
 			return key, nil
 		}
+		if time.Since(c.fetchedAt) < jwksMinRefetch {
+			c.mu.Unlock()
+			return nil, fmt.Errorf("jwt kid not found in jwks")
+		}
 	}
 	c.mu.Unlock()
 
@@ -2012,41 +2350,22 @@ type jwk struct {
 	Use string `json:"use"`
 	N   string `json:"n"`
 	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
 }
 
-func fetchJWKSKeys(ctx context.Context, jwksURL string) (map[string]*rsa.PublicKey, error) {
+func fetchJWKSKeys(ctx context.Context, jwksURL string) (map[string]crypto.PublicKey, error) {
 	if jwksURL == "" {
 		return nil, fmt.Errorf("jwks url missing")
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("build jwks request: %w", err)
-	}
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("fetch jwks: %w", err)
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) // Read small error snippet
-		return nil, fmt.Errorf("fetch jwks: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
-	}
-	// Limit JWKS to 1MB to prevent memory exhaustion DoS
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
-	if err != nil {
-		return nil, fmt.Errorf("read jwks: %w", err)
-	}
 	var payload jwksPayload
-	if err := json.Unmarshal(body, &payload); err != nil {
-		return nil, fmt.Errorf("parse jwks: %w", err)
+	if err := fetchOIDCJSON(ctx, jwksURL, "jwks", &payload); err != nil {
+		return nil, err
 	}
-	keys := make(map[string]*rsa.PublicKey)
+	keys := make(map[string]crypto.PublicKey)
 	for _, key := range payload.Keys {
-		if key.Kty != "RSA" || key.N == "" || key.E == "" {
-			continue
-		}
-		pub, err := parseRSAPublicKey(key.N, key.E)
+		pub, err := parseJWK(key)
 		if err != nil {
 			continue
 		}
@@ -2057,11 +2376,40 @@ func fetchJWKSKeys(ctx context.Context, jwksURL string) (map[string]*rsa.PublicK
 		keys[kid] = pub
 	}
 	if len(keys) == 0 {
-		return nil, fmt.Errorf("no valid RSA keys in jwks")
+		return nil, fmt.Errorf("no valid signing keys in jwks")
 	}
 	return keys, nil
 }
 
+// fetchOIDCJSON GETs an issuer document (JWKS or discovery) into v. what
+// names the document in errors.
+func fetchOIDCJSON(ctx context.Context, rawURL, what string, v any) error {
+	ctx, cancel := deadline.With(ctx, deadline.OIDC)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return fmt.Errorf("build %s request: %w", what, err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) // Read small error snippet
+		return fmt.Errorf("fetch %s: status %d: %s", what, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	// Limit to 1MB to prevent memory exhaustion DoS
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", what, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse %s: %w", what, err)
+	}
+	return nil
+}
+
 func parseRSAPublicKey(nStr, eStr string) (*rsa.PublicKey, error) {
 	nBytes, err := base64.RawURLEncoding.DecodeString(nStr)
 	if err != nil {
@@ -2335,7 +2683,16 @@ func (s *Server) handleSessionsV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessions, err := s.stateStore.ListSessions("")
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	sessions, next, err := s.stateStore.ListSessionsPage("", cursor, limit)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
 		return
@@ -2346,9 +2703,11 @@ func (s *Server) handleSessionsV1(w http.ResponseWriter, r *http.Request) {
 		sessions = []state.Session{}
 	}
 
-	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
-		"sessions": sessions,
-		"count":    len(sessions),
+	writeCacheableResponse(w, r, map[string]interface{}{
+		"sessions":    sessions,
+		"count":       len(sessions),
+		"has_more":    next != "",
+		"next_cursor": next,
 	}, reqID)
 }
 
@@ -2377,7 +2736,7 @@ func (s *Server) handleSessionV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+	writeCacheableResponse(w, r, map[string]interface{}{
 		"session": session,
 	}, reqID)
 }
@@ -2402,7 +2761,7 @@ func (s *Server) handleSessionAgentsV1(w http.ResponseWriter, r *http.Request, s
 		agents = []state.Agent{}
 	}
 
-	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+	writeCacheableResponse(w, r, map[string]interface{}{
 		"session_id": sessionID,
 		"agents":     agents,
 		"count":      len(agents),
@@ -2418,31 +2777,31 @@ func (s *Server) handleSessionEventsV1(w http.ResponseWriter, r *http.Request, s
 		return
 	}
 
-	eventsData := s.eventBus.History(100)
-
-	var filtered []events.BusEvent
-	for _, e := range eventsData {
-		if sessionID == "" || e.EventSession() == sessionID {
-			filtered = append(filtered, e)
-		}
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
 	}
-
-	// Ensure events is never null
-	if filtered == nil {
-		filtered = []events.BusEvent{}
+	page, err := s.sessionEventsPage(sessionID, cursor, limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
 	}
 
-	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
-		"session_id": sessionID,
-		"events":     filtered,
-		"count":      len(filtered),
+	writeCacheableResponse(w, r, map[string]interface{}{
+		"session_id":  sessionID,
+		"events":      page.Items,
+		"count":       len(page.Items),
+		"total":       page.Total,
+		"has_more":    page.HasMore(),
+		"next_cursor": page.NextCursor,
 	}, reqID)
 }
 
 // handleRobotStatusV1 handles GET /api/v1/robot/status.
 func (s *Server) handleRobotStatusV1(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
-	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
+	writeCacheableResponse(w, r, map[string]interface{}{
 		"note": "full robot status requires robot package integration",
 	}, reqID)
 }
@@ -2450,8 +2809,11 @@ func (s *Server) handleRobotStatusV1(w http.ResponseWriter, r *http.Request) {
 // handleRobotHealthV1 handles GET /api/v1/robot/health.
 func (s *Server) handleRobotHealthV1(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
+	health := s.deepHealth(r.Context())
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
-		"note": "full robot health requires robot package integration",
+		"checked_at": health.CheckedAt,
+		"status":     health.Status,
+		"components": health.Components,
 	}, reqID)
 }
 
@@ -2749,6 +3111,16 @@ func (s *Server) handlePaneInputV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.auditRequest(r, sessionID, audit.EventTypeSend, "pane.input", map[string]interface{}{
+		"pane":          paneTarget,
+		"prompt_length": len(req.Text),
+		"enter":         req.Enter,
+	})
+	s.publishForRequest(r, "sessions:"+sessionID, "pane.input", map[string]interface{}{
+		"pane":          paneTarget,
+		"prompt_length": len(req.Text),
+	})
+
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
 		"sent": true,
 		"pane": paneTarget,
@@ -2781,6 +3153,10 @@ func (s *Server) handlePaneInterruptV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.auditRequest(r, sessionID, audit.EventTypeCommand, "pane.interrupt", map[string]interface{}{
+		"pane": paneTarget,
+	})
+
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
 		"interrupted": true,
 		"pane":        paneTarget,
@@ -3124,6 +3500,21 @@ func (s *Server) handleAgentSendV1(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.auditRequest(r, sessionID, audit.EventTypeSend, "agents.send", map[string]interface{}{
+		"prompt_preview": result.MessagePreview,
+		"prompt_length":  len(req.Message),
+		"panes":          req.Panes,
+		"agent_types":    req.AgentTypes,
+		"all":            req.All,
+		"delivered":      len(result.Successful),
+		"failed":         len(result.Failed),
+	})
+	s.publishForRequest(r, "sessions:"+sessionID, "agents.send", map[string]interface{}{
+		"prompt_length": len(req.Message),
+		"delivered":     len(result.Successful),
+		"failed":        len(result.Failed),
+	})
+
 	data, err := toJSONMap(result)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to serialize response", nil, reqID)
@@ -3171,6 +3562,11 @@ func (s *Server) handleAgentInterruptV1(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	s.auditRequest(r, sessionID, audit.EventTypeCommand, "agents.interrupt", map[string]interface{}{
+		"panes": req.Panes,
+		"force": req.Force,
+	})
+
 	data, err := toJSONMap(result)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to serialize response", nil, reqID)
@@ -3767,6 +4163,14 @@ type GitSyncRequest struct {
 	DryRun   bool   `json:"dry_run,omitempty"`
 }
 
+// gitOutput runs git in workDir and returns its output. It stops when ctx
+// is done or op's deadline runs out.
+func gitOutput(ctx context.Context, op deadline.Op, workDir string, args ...string) ([]byte, error) {
+	ctx, cancel := deadline.With(ctx, op)
+	defer cancel()
+	return exec.CommandContext(ctx, "git", append([]string{"-C", workDir}, args...)...).Output()
+}
+
 // handleGitSyncV1 handles POST /api/v1/git/sync.
 func (s *Server) handleGitSyncV1(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
@@ -3790,8 +4194,7 @@ func (s *Server) handleGitSyncV1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if git repo
-	cmd := exec.Command("git", "-C", workDir, "rev-parse", "--git-dir")
-	if err := cmd.Run(); err != nil {
+	if _, err := gitOutput(r.Context(), deadline.Git, workDir, "rev-parse", "--git-dir"); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "NOT_GIT_REPO", "not a git repository", nil, reqID)
 		return
 	}
@@ -3799,14 +4202,12 @@ func (s *Server) handleGitSyncV1(w http.ResponseWriter, r *http.Request) {
 	// Perform git operations based on request
 	if !req.PushOnly {
 		// Fetch first
-		fetchCmd := exec.Command("git", "-C", workDir, "fetch")
-		if err := fetchCmd.Run(); err != nil {
+		if _, err := gitOutput(r.Context(), deadline.GitRemote, workDir, "fetch"); err != nil {
 			result["pull_error"] = "fetch failed"
 			result["success"] = false
 		} else if !req.DryRun {
 			// Pull with rebase
-			pullCmd := exec.Command("git", "-C", workDir, "pull", "--rebase")
-			if err := pullCmd.Run(); err != nil {
+			if _, err := gitOutput(r.Context(), deadline.GitRemote, workDir, "pull", "--rebase"); err != nil {
 				result["pull_error"] = "pull failed"
 				result["success"] = false
 			} else {
@@ -3817,15 +4218,14 @@ func (s *Server) handleGitSyncV1(w http.ResponseWriter, r *http.Request) {
 
 	if !req.PullOnly && result["success"] == true {
 		// Push
-		pushArgs := []string{"-C", workDir, "push"}
+		pushArgs := []string{"push"}
 		if req.Force {
 			pushArgs = append(pushArgs, "--force")
 		}
 		if req.DryRun {
 			pushArgs = append(pushArgs, "--dry-run")
 		}
-		pushCmd := exec.Command("git", pushArgs...)
-		if err := pushCmd.Run(); err != nil {
+		if _, err := gitOutput(r.Context(), deadline.GitRemote, workDir, pushArgs...); err != nil {
 			result["push_error"] = "push failed"
 			result["success"] = false
 		} else {
@@ -3855,22 +4255,19 @@ func (s *Server) handleGitStatusV1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if git repo
-	cmd := exec.Command("git", "-C", workDir, "rev-parse", "--git-dir")
-	if err := cmd.Run(); err != nil {
+	if _, err := gitOutput(r.Context(), deadline.Git, workDir, "rev-parse", "--git-dir"); err != nil {
 		writeErrorResponse(w, http.StatusBadRequest, "NOT_GIT_REPO", "not a git repository", nil, reqID)
 		return
 	}
 
 	// Get branch
-	branchCmd := exec.Command("git", "-C", workDir, "rev-parse", "--abbrev-ref", "HEAD")
-	branchOut, err := branchCmd.Output()
+	branchOut, err := gitOutput(r.Context(), deadline.Git, workDir, "rev-parse", "--abbrev-ref", "HEAD")
 	if err == nil {
 		result["branch"] = strings.TrimSpace(string(branchOut))
 	}
 
 	// Get commit
-	commitCmd := exec.Command("git", "-C", workDir, "rev-parse", "HEAD")
-	commitOut, err := commitCmd.Output()
+	commitOut, err := gitOutput(r.Context(), deadline.Git, workDir, "rev-parse", "HEAD")
 	if err == nil {
 		commit := strings.TrimSpace(string(commitOut))
 		result["commit"] = commit
@@ -3880,12 +4277,11 @@ func (s *Server) handleGitStatusV1(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if dirty
-	dirtyCmd := exec.Command("git", "-C", workDir, "diff", "--quiet", "HEAD")
-	result["dirty"] = dirtyCmd.Run() != nil
+	_, err = gitOutput(r.Context(), deadline.Git, workDir, "diff", "--quiet", "HEAD")
+	result["dirty"] = err != nil
 
 	// Get status summary
-	statusCmd := exec.Command("git", "-C", workDir, "status", "--porcelain")
-	statusOut, err := statusCmd.Output()
+	statusOut, err := gitOutput(r.Context(), deadline.Git, workDir, "status", "--porcelain")
 	if err == nil {
 		lines := strings.Split(strings.TrimSpace(string(statusOut)), "\n")
 		if len(lines) == 1 && lines[0] == "" {
@@ -3967,7 +4363,7 @@ func (s *Server) handleOutputDiffV1(w http.ResponseWriter, r *http.Request) {
 		Since:   since,
 	}
 
-	result, err := robot.GetDiff(opts)
+	result, err := robot.GetDiff(r.Context(), opts)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
 		return
@@ -4140,6 +4536,126 @@ func (s *Server) handleHistoryV1(w http.ResponseWriter, r *http.Request) {
 	writeSuccessResponse(w, http.StatusOK, data, reqID)
 }
 
+// handleAuditV1 handles GET /api/v1/audit: one page of audit log entries
+// matching session, event_type, actor (comma-separated), target, grep,
+// since and until.
+func (s *Server) handleAuditV1(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	q := r.URL.Query()
+
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	opts := robot.AuditOptions{
+		Sessions: splitQueryList(q.Get("session")),
+		Target:   q.Get("target"),
+		Grep:     q.Get("grep"),
+		Limit:    limit,
+		Cursor:   cursor,
+	}
+	for _, et := range splitQueryList(q.Get("event_type")) {
+		opts.EventTypes = append(opts.EventTypes, audit.EventType(et))
+	}
+	for _, a := range splitQueryList(q.Get("actor")) {
+		opts.Actors = append(opts.Actors, audit.Actor(a))
+	}
+	if opts.Since, err = parseTimeParam(q.Get("since")); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid since: "+err.Error(), nil, reqID)
+		return
+	}
+	if opts.Until, err = parseTimeParam(q.Get("until")); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid until: "+err.Error(), nil, reqID)
+		return
+	}
+
+	result, err := robot.GetAudit(opts)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	writeRobotPageV1(w, result, result.RobotResponse, reqID)
+}
+
+// handleCapturesV1 handles GET /api/v1/captures: one page of a session's
+// archived pane output, optionally for one pane and since a time.
+func (s *Server) handleCapturesV1(w http.ResponseWriter, r *http.Request) {
+	reqID := requestIDFromContext(r.Context())
+	q := r.URL.Query()
+
+	session := q.Get("session")
+	if session == "" {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "session parameter required", nil, reqID)
+		return
+	}
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	since, err := parseTimeParam(q.Get("since"))
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid since: "+err.Error(), nil, reqID)
+		return
+	}
+
+	result, err := robot.GetCaptures(robot.CapturesOptions{
+		Session: session,
+		Pane:    q.Get("pane"),
+		Since:   since,
+		Limit:   limit,
+		Cursor:  cursor,
+	})
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, err.Error(), nil, reqID)
+		return
+	}
+	writeRobotPageV1(w, result, result.RobotResponse, reqID)
+}
+
+// writeRobotPageV1 writes a paged robot result, mapping a bad cursor or
+// filter to 400.
+func writeRobotPageV1(w http.ResponseWriter, result interface{}, resp robot.RobotResponse, reqID string) {
+	if !resp.Success {
+		status, code := http.StatusInternalServerError, ErrCodeInternalError
+		if resp.ErrorCode == robot.ErrCodeInvalidFlag {
+			status, code = http.StatusBadRequest, ErrCodeBadRequest
+		}
+		writeErrorResponse(w, status, code, resp.Error, nil, reqID)
+		return
+	}
+	data, err := toJSONMap(result)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, ErrCodeInternalError, "failed to serialize response", nil, reqID)
+		return
+	}
+	writeSuccessResponse(w, http.StatusOK, data, reqID)
+}
+
+// splitQueryList splits a comma-separated query value, dropping empty items.
+func splitQueryList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// parseTimeParam parses an RFC3339 time or a duration before now ("24h").
+// Empty is the zero time.
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
 // handleHistoryStatsV1 handles GET /api/v1/history/stats.
 func (s *Server) handleHistoryStatsV1(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
@@ -4210,7 +4726,7 @@ func (s *Server) handleRouteV1(w http.ResponseWriter, r *http.Request) {
 
 	result, exitCode := robot.GetRoute(opts)
 	if exitCode != 0 && !result.Success {
-		writeErrorResponse(w, http.StatusBadRequest, result.ErrorCode, result.Error, nil, reqID)
+		writeRobotFailure(w, result.ErrorCode, result.Error, result.Hint, reqID)
 		return
 	}
 
@@ -4297,7 +4813,7 @@ func (s *Server) handleWaitV1(w http.ResponseWriter, r *http.Request) {
 	}
 	if exitCode == 1 { // Timeout
 		data, _ := toJSONMap(result)
-		writeErrorResponse(w, http.StatusRequestTimeout, "TIMEOUT", result.Error, data, reqID)
+		writeErrorResponse(w, http.StatusRequestTimeout, ErrCodeTimeout, result.Error, data, reqID)
 		return
 	}
 	if exitCode == 3 { // Agent error
@@ -4322,16 +4838,26 @@ func (s *Server) handleWaitV1(w http.ResponseWriter, r *http.Request) {
 // handleListJobs handles GET /api/v1/jobs.
 func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
 	reqID := requestIDFromContext(r.Context())
-	jobs := s.jobStore.List()
-
-	// Ensure jobs is never null
-	if jobs == nil {
-		jobs = []*Job{}
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
+	}
+	// Newest first; the ID breaks ties between jobs created in the same second.
+	page, err := pagination.Slice(append(s.jobStore.List(), s.listRobotJobs()...), func(j *Job) string {
+		return j.CreatedAt + "|" + j.ID
+	}, true, cursor, limit)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error(), nil, reqID)
+		return
 	}
 
 	writeSuccessResponse(w, http.StatusOK, map[string]interface{}{
-		"jobs":  jobs,
-		"count": len(jobs),
+		"jobs":        page.Items,
+		"count":       len(page.Items),
+		"total":       page.Total,
+		"has_more":    page.HasMore(),
+		"next_cursor": page.NextCursor,
 	}, reqID)
 }
 
@@ -4357,6 +4883,11 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Type == robotJobType {
+		s.handleCreateRobotJob(w, req, reqID)
+		return
+	}
+
 	// Validate job type
 	validTypes := map[string]bool{
 		"spawn":      true,
@@ -4367,7 +4898,7 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 	}
 	if !validTypes[req.Type] {
 		writeErrorResponse(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid job type", map[string]interface{}{
-			"valid_types": []string{"spawn", "scan", "checkpoint", "import", "export"},
+			"valid_types": []string{"spawn", "scan", "checkpoint", "import", "export", robotJobType},
 		}, reqID)
 		return
 	}
@@ -4409,6 +4940,9 @@ func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "id")
 
 	job := s.jobStore.Get(jobID)
+	if job == nil {
+		job = s.getRobotJob(jobID)
+	}
 	if job == nil {
 		writeErrorResponse(w, http.StatusNotFound, ErrCodeNotFound, "job not found", nil, reqID)
 		return
@@ -4426,6 +4960,10 @@ func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
 
 	job := s.jobStore.Get(jobID)
 	if job == nil {
+		if robotJob := s.getRobotJob(jobID); robotJob != nil {
+			s.handleCancelRobotJob(w, robotJob, reqID)
+			return
+		}
 		writeErrorResponse(w, http.StatusNotFound, ErrCodeNotFound, "job not found", nil, reqID)
 		return
 	}
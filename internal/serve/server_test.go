@@ -10,6 +10,7 @@ import (
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
 	"math/big"
 	"net/http"
@@ -23,7 +24,10 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/Dicklesworthstone/ntm/internal/archive"
+	"github.com/Dicklesworthstone/ntm/internal/audit"
 	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/robot"
 	"github.com/Dicklesworthstone/ntm/internal/state"
 )
 
@@ -569,6 +573,134 @@ func TestSessionEventsEndpoint(t *testing.T) {
 	}
 }
 
+func TestAuditAndCapturesEndpoints_Pagination(t *testing.T) {
+	srv, _ := setupTestServer(t)
+	auditDir, archiveDir := t.TempDir(), t.TempDir()
+	audit.SetDir(auditDir)
+	archive.SetOutputDir(archiveDir)
+	t.Cleanup(func() {
+		audit.SetDir("")
+		archive.SetOutputDir("")
+	})
+
+	now := time.Now().UTC()
+	var auditLines, archiveLines []byte
+	for i := 1; i <= 3; i++ {
+		line, _ := json.Marshal(audit.AuditEntry{Timestamp: now, SessionID: "paged", EventType: audit.EventTypeCommand, SequenceNum: uint64(i)})
+		auditLines = append(append(auditLines, line...), '\n')
+		line, _ = json.Marshal(archive.ArchiveRecord{Session: "paged", Pane: "cc_1", PaneIndex: 1, Timestamp: now, Sequence: i})
+		archiveLines = append(append(archiveLines, line...), '\n')
+	}
+	if err := os.WriteFile(filepath.Join(auditDir, "paged-"+now.Format("2006-01-02")+".jsonl"), auditLines, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(archiveDir, "paged_"+now.Local().Format("2006-01-02")+".jsonl"), archiveLines, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path    string
+		handler http.HandlerFunc
+		items   string
+	}{
+		{"/api/v1/audit?session=paged", srv.handleAuditV1, "entries"},
+		{"/api/v1/captures?session=paged", srv.handleCapturesV1, "captures"},
+	} {
+		seen := 0
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 3 {
+				t.Fatalf("%s: pagination did not terminate", tc.path)
+			}
+			rec := httptest.NewRecorder()
+			tc.handler(rec, httptest.NewRequest(http.MethodGet, tc.path+"&limit=2&cursor="+cursor, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: Status = %d, body=%s", tc.path, rec.Code, rec.Body.String())
+			}
+			var resp map[string]interface{}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			items, _ := resp[tc.items].([]interface{})
+			seen += len(items)
+			next, _ := resp["next_page_cursor"].(string)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if seen != 3 {
+			t.Errorf("%s: paged %d items, want 3", tc.path, seen)
+		}
+
+		rec := httptest.NewRecorder()
+		tc.handler(rec, httptest.NewRequest(http.MethodGet, tc.path+"&cursor=%21%21", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: invalid cursor Status = %d, want 400", tc.path, rec.Code)
+		}
+	}
+}
+
+func TestSessionEventsEndpoint_Pagination(t *testing.T) {
+	srv, _ := setupTestServer(t)
+
+	base := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		srv.eventBus.PublishSync(events.BaseEvent{
+			Type:      fmt.Sprintf("event_%d", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+			Session:   "paged",
+		})
+	}
+
+	var seen []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/paged/events?limit=2&cursor="+cursor, nil)
+		rec := httptest.NewRecorder()
+		srv.handleSessionEventsV1(rec, req, "paged")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Events []struct {
+				Type string `json:"type"`
+			} `json:"events"`
+			Total      int    `json:"total"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Events) > 2 || resp.Total != 5 {
+			t.Fatalf("page has %d events, total %d", len(resp.Events), resp.Total)
+		}
+		for _, e := range resp.Events {
+			seen = append(seen, e.Type)
+		}
+		if !resp.HasMore {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+	if got := strings.Join(seen, ","); got != "event_4,event_3,event_2,event_1,event_0" {
+		t.Errorf("events = %s, want newest first without gaps", got)
+	}
+
+	for _, q := range []string{"limit=0", "limit=abc", "cursor=%21%21"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/paged/events?"+q, nil)
+		rec := httptest.NewRecorder()
+		srv.handleSessionEventsV1(rec, req, "paged")
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
 func TestSSEClientManagement(t *testing.T) {
 	srv, _ := setupTestServer(t)
 
@@ -1258,6 +1390,57 @@ func TestIdempotencyMiddleware(t *testing.T) {
 	}
 }
 
+func TestIdempotencyMiddleware_ConflictAndMismatch(t *testing.T) {
+	srv, _ := setupTestServer(t)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	calls := 0
+	handler := srv.idempotencyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			close(started)
+			<-release
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}))
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/thing", strings.NewReader(body))
+		req.Header.Set("Idempotency-Key", "k-conflict")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- send(`{"a":1}`) }()
+	<-started
+
+	if rec := send(`{"a":1}`); rec.Code != http.StatusConflict {
+		t.Errorf("in-flight retry status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	close(release)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d", rec.Code)
+	}
+
+	rec := send(`{"a":1}`)
+	if rec.Code != http.StatusCreated || rec.Body.String() != "created" || rec.Header().Get("X-Idempotent-Replay") != "true" {
+		t.Errorf("replay = %d %q replay=%q", rec.Code, rec.Body.String(), rec.Header().Get("X-Idempotent-Replay"))
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("replay Content-Type = %q, want text/plain", ct)
+	}
+	if rec := send(`{"a":2}`); rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), ErrCodeIdempotencyReuse) {
+		t.Errorf("different body status = %d body=%s, want 422 %s", rec.Code, rec.Body.String(), ErrCodeIdempotencyReuse)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
 // =============================================================================
 // Panic Recovery Tests
 // =============================================================================
@@ -2654,7 +2837,7 @@ func TestValidateConfig_OIDCMissingIssuer(t *testing.T) {
 	}
 }
 
-func TestValidateConfig_OIDCMissingJWKS(t *testing.T) {
+func TestValidateConfig_OIDCIssuerOnly(t *testing.T) {
 	t.Parallel()
 	cfg := Config{
 		Host: "0.0.0.0",
@@ -2663,9 +2846,9 @@ func TestValidateConfig_OIDCMissingJWKS(t *testing.T) {
 			OIDC: OIDCConfig{Issuer: "https://example.com"},
 		},
 	}
-	err := ValidateConfig(cfg)
-	if err == nil || !strings.Contains(err.Error(), "requires --oidc-jwks-url") {
-		t.Errorf("expected oidc-jwks error, got %v", err)
+	// The JWKS URL is discovered from the issuer at request time.
+	if err := ValidateConfig(cfg); err != nil {
+		t.Errorf("issuer-only oidc config should be valid, got %v", err)
 	}
 }
 
@@ -2852,7 +3035,7 @@ func TestAuthenticateRequest_UnsupportedMode(t *testing.T) {
 	t.Parallel()
 	srv := &Server{auth: AuthConfig{Mode: "foobar"}}
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	err := srv.authenticateRequest(req)
+	_, err := srv.authenticateRequest(req)
 	if err == nil {
 		t.Error("expected error for unsupported auth mode")
 	}
@@ -2866,7 +3049,7 @@ func TestAuthenticateRequest_APIKeyPath(t *testing.T) {
 	srv := &Server{auth: AuthConfig{Mode: AuthModeAPIKey, APIKey: "key123"}}
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("X-API-Key", "key123")
-	if err := srv.authenticateRequest(req); err != nil {
+	if _, err := srv.authenticateRequest(req); err != nil {
 		t.Errorf("expected nil error, got: %v", err)
 	}
 }
@@ -2878,7 +3061,7 @@ func TestAuthenticateRequest_MTLSPath(t *testing.T) {
 	req.TLS = &tls.ConnectionState{
 		PeerCertificates: []*x509.Certificate{{}},
 	}
-	if err := srv.authenticateRequest(req); err != nil {
+	if _, err := srv.authenticateRequest(req); err != nil {
 		t.Errorf("expected nil error for mTLS, got: %v", err)
 	}
 }
@@ -2892,7 +3075,7 @@ func TestAuthenticateAPIKey_InvalidKey(t *testing.T) {
 	srv := &Server{auth: AuthConfig{Mode: AuthModeAPIKey, APIKey: "correct-key"}}
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	req.Header.Set("X-API-Key", "wrong-key")
-	err := srv.authenticateAPIKey(req)
+	_, err := srv.authenticateAPIKey(req)
 	if err == nil || !strings.Contains(err.Error(), "invalid api key") {
 		t.Errorf("expected 'invalid api key' error, got: %v", err)
 	}
@@ -2902,7 +3085,7 @@ func TestAuthenticateAPIKey_MissingKey(t *testing.T) {
 	t.Parallel()
 	srv := &Server{auth: AuthConfig{Mode: AuthModeAPIKey, APIKey: "correct-key"}}
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	err := srv.authenticateAPIKey(req)
+	_, err := srv.authenticateAPIKey(req)
 	if err == nil || !strings.Contains(err.Error(), "missing api key") {
 		t.Errorf("expected 'missing api key' error, got: %v", err)
 	}
@@ -3137,6 +3320,56 @@ func TestWriteErrorResponse_WithHint(t *testing.T) {
 	if resp.Details == nil || resp.Details["extra"] != "detail" {
 		t.Errorf("expected extra detail preserved, got %v", resp.Details)
 	}
+	if resp.ErrorID != "NTM-E110" {
+		t.Errorf("expected error_id NTM-E110, got %q", resp.ErrorID)
+	}
+	if resp.Remediation == nil || resp.Remediation.Guidance != "try using a different key" {
+		t.Errorf("expected remediation with hint guidance, got %+v", resp.Remediation)
+	}
+}
+
+func TestWriteRobotFailure_TaxonomyStatus(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		code string
+		want int
+	}{
+		{"SESSION_NOT_FOUND", http.StatusNotFound},
+		{"CONFIRMATION_REQUIRED", http.StatusPreconditionRequired},
+		{ErrCodeCASSUnavailable, http.StatusServiceUnavailable},
+		{"NO_SUCH_CODE", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		writeRobotFailure(w, tt.code, "failed", "", "req-1")
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.code, w.Code, tt.want)
+		}
+		var resp APIError
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("json decode error: %v", err)
+		}
+		if resp.ErrorID == "" || resp.Remediation == nil || resp.Remediation.Action == "" {
+			t.Errorf("%s: missing taxonomy fields: %+v", tt.code, resp)
+		}
+	}
+}
+
+func TestServeErrorCodes_Registered(t *testing.T) {
+	t.Parallel()
+	ids := make(map[string]string)
+	for _, info := range robot.ErrorCodes() {
+		if prev, ok := ids[info.ID]; ok && info.ID != "NTM-E102" {
+			t.Errorf("id %s shared by %s and %s", info.ID, prev, info.Code)
+		}
+		ids[info.ID] = info.Code
+	}
+	for _, code := range []string{ErrCodeBadRequest, ErrCodeNotFound, ErrCodeUnauthorized,
+		ErrCodeConflict, ErrCodeBeadsUnavailable, ErrCodePipelineFailed, ErrCodeScanFailed} {
+		if _, ok := robot.LookupErrorCode(code); !ok {
+			t.Errorf("%s is not in the taxonomy", code)
+		}
+	}
 }
 
 func TestWriteErrorResponse_HintOnlyDetail(t *testing.T) {
@@ -0,0 +1,103 @@
+package serve
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/share"
+)
+
+// shareTokenQueryParam carries a share token for clients that cannot set
+// headers, such as a browser EventSource.
+const shareTokenQueryParam = "share_token"
+
+// shareSessionClaim marks claims minted from a share token; rbacMiddleware
+// caps such principals at viewer regardless of auth mode.
+const shareSessionClaim = "share_session"
+
+// shareTokenFromRequest returns the share token presented as a bearer token
+// or query parameter, or "" if there is none.
+func shareTokenFromRequest(r *http.Request) string {
+	if tok := extractBearerToken(r); share.IsToken(tok) {
+		return tok
+	}
+	if tok := r.URL.Query().Get(shareTokenQueryParam); share.IsToken(tok) {
+		return tok
+	}
+	return ""
+}
+
+// shareAllowsPath reports whether a read-only share for session may reach
+// path. Only the session's status, agents, and events are exposed, plus the
+// global SSE stream (which serveShareRequest pins to the session).
+func shareAllowsPath(path, session string) bool {
+	parts := splitPath(path)
+	if len(parts) == 1 && parts[0] == "events" {
+		return true
+	}
+	if len(parts) >= 2 && parts[0] == "api" && parts[1] == "v1" {
+		parts = parts[2:]
+	} else if len(parts) >= 1 && parts[0] == "api" {
+		parts = parts[1:]
+	} else {
+		return false
+	}
+	if len(parts) < 2 || parts[0] != "sessions" || parts[1] != session {
+		return false
+	}
+	switch len(parts) {
+	case 2:
+		return true
+	case 3:
+		switch parts[2] {
+		case "agents", "events", "status":
+			return true
+		}
+	}
+	return false
+}
+
+// serveShareRequest authenticates a request bearing a share token. The
+// signing key is re-read on every request so that rotating it with
+// `ntm share --revoke-all` takes effect immediately.
+func (s *Server) serveShareRequest(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	reqID := requestIDFromContext(r.Context())
+	key, err := share.LoadKey(s.shareKeyPath)
+	if err != nil {
+		log.Printf("share auth failed path=%s remote=%s request_id=%s err=%v", r.URL.Path, r.RemoteAddr, reqID, err)
+		writeErrorResponse(w, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized", nil, reqID)
+		return
+	}
+	grant, err := share.Verify(key, token, time.Now())
+	if err != nil {
+		log.Printf("share auth failed path=%s remote=%s request_id=%s err=%v", r.URL.Path, r.RemoteAddr, reqID, err)
+		msg := "unauthorized"
+		if errors.Is(err, share.ErrExpired) {
+			msg = "share link expired"
+		}
+		writeErrorResponse(w, http.StatusUnauthorized, ErrCodeUnauthorized, msg, nil, reqID)
+		return
+	}
+
+	if (r.Method != http.MethodGet && r.Method != http.MethodHead) || !shareAllowsPath(r.URL.Path, grant.Session) {
+		writeErrorResponse(w, http.StatusForbidden, ErrCodeForbidden,
+			"share link is read-only and limited to session "+grant.Session, nil, reqID)
+		return
+	}
+
+	if strings.Trim(r.URL.Path, "/") == "events" {
+		r = r.Clone(r.Context())
+		q := r.URL.Query()
+		q.Set("session", grant.Session)
+		r.URL.RawQuery = q.Encode()
+	}
+
+	next.ServeHTTP(w, withAuthClaims(r, map[string]interface{}{
+		"sub":             "share:" + grant.ID,
+		"role":            string(RoleViewer),
+		shareSessionClaim: grant.Session,
+	}))
+}
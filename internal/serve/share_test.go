@@ -0,0 +1,118 @@
+package serve
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/share"
+)
+
+func newShareTestServer(t *testing.T, mode AuthMode) (*Server, []byte) {
+	t.Helper()
+	keyPath := filepath.Join(t.TempDir(), "share.key")
+	key, err := share.LoadOrCreateKey(keyPath)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	return New(Config{Auth: AuthConfig{Mode: mode, APIKey: "k"}, ShareKeyPath: keyPath}), key
+}
+
+// shareRequest runs req through auth and RBAC, returning the response and
+// the role the handler saw.
+func shareRequest(s *Server, req *http.Request) (*httptest.ResponseRecorder, Role) {
+	var role Role
+	h := s.authMiddlewareFunc(s.rbacMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role = RoleFromContext(r.Context()).Role
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec, role
+}
+
+func TestShareToken_ScopedReadOnly(t *testing.T) {
+	t.Parallel()
+	for _, mode := range []AuthMode{AuthModeLocal, AuthModeAPIKey} {
+		s, key := newShareTestServer(t, mode)
+		token, _, err := share.Mint(key, "proj", time.Hour, time.Now())
+		if err != nil {
+			t.Fatalf("mint: %v", err)
+		}
+
+		tests := []struct {
+			method, path string
+			want         int
+		}{
+			{http.MethodGet, "/api/v1/sessions/proj/status", http.StatusOK},
+			{http.MethodGet, "/api/sessions/proj/agents", http.StatusOK},
+			{http.MethodGet, "/events", http.StatusOK},
+			{http.MethodGet, "/api/v1/sessions/other/status", http.StatusForbidden},
+			{http.MethodGet, "/api/v1/sessions", http.StatusForbidden},
+			{http.MethodGet, "/api/v1/sessions/proj/panes/0/output", http.StatusForbidden},
+			{http.MethodPost, "/api/v1/sessions/proj/agents/send", http.StatusForbidden},
+		}
+		for _, tt := range tests {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec, role := shareRequest(s, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s: %s %s = %d, want %d", mode, tt.method, tt.path, rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusOK && role != RoleViewer {
+				t.Errorf("%s: %s role = %s, want viewer", mode, tt.path, role)
+			}
+		}
+	}
+}
+
+func TestShareToken_RejectsExpiredAndRevoked(t *testing.T) {
+	t.Parallel()
+	s, key := newShareTestServer(t, AuthModeLocal)
+
+	expired, _, _ := share.Mint(key, "proj", time.Minute, time.Now().Add(-time.Hour))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/proj?share_token="+expired, nil)
+	if rec, _ := shareRequest(s, req); rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "expired") {
+		t.Fatalf("expired token: %d %s", rec.Code, rec.Body.String())
+	}
+
+	token, _, _ := share.Mint(key, "proj", time.Hour, time.Now())
+	if _, err := share.RotateKey(s.shareKeyPath); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/sessions/proj?share_token="+token, nil)
+	if rec, _ := shareRequest(s, req); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked token: status = %d, want 401", rec.Code)
+	}
+}
+
+func TestShareToken_EventStreamPinnedToSession(t *testing.T) {
+	t.Parallel()
+	s, key := newShareTestServer(t, AuthModeLocal)
+	token, _, _ := share.Mint(key, "proj", time.Hour, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	// The session parameter is overridden by the grant.
+	req := httptest.NewRequest(http.MethodGet, "/events?session=other&share_token="+token, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		s.authMiddlewareFunc(http.HandlerFunc(s.handleEventStream)).ServeHTTP(rec, req)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	s.broadcastEvent(testSSEEvent{eventType: "other.event", session: "other", timestamp: time.Now()})
+	s.broadcastEvent(testSSEEvent{eventType: "proj.event", session: "proj", timestamp: time.Now()})
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: proj.event") || strings.Contains(body, "other.event") {
+		t.Fatalf("stream not pinned to shared session:\n%s", body)
+	}
+}
@@ -74,12 +74,12 @@ type PromptHistory struct {
 // SessionDir returns the path to the session-specific directory.
 // Creates the directory if it doesn't exist.
 func SessionDir(sessionName string) (string, error) {
-	ntmDir, err := util.NTMDir()
+	sessionsDir, err := util.SessionsDir()
 	if err != nil {
 		return "", err
 	}
 
-	dir := filepath.Join(ntmDir, "sessions", sanitizeFilename(sessionName))
+	dir := filepath.Join(sessionsDir, sanitizeFilename(sessionName))
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return "", fmt.Errorf("failed to create session directory: %w", err)
 	}
@@ -263,12 +263,11 @@ func ClearPromptHistory(sessionName string) error {
 
 // ListSessionDirs returns all sessions that have prompt history.
 func ListSessionDirs() ([]string, error) {
-	ntmDir, err := util.NTMDir()
+	sessionsDir, err := util.SessionsDir()
 	if err != nil {
 		return nil, err
 	}
 
-	sessionsDir := filepath.Join(ntmDir, "sessions")
 	entries, err := os.ReadDir(sessionsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -19,16 +19,16 @@ const (
 )
 
 // StorageDir returns the path to the session storage directory.
-// Uses ~/.ntm/sessions by default.
+// Uses util.SessionsDir, ~/.ntm/sessions by default.
 // Falls back to temp directory if home directory is unavailable.
 func StorageDir() string {
-	ntmDir, err := util.NTMDir()
-	if err != nil || ntmDir == "" {
+	dir, err := util.SessionsDir()
+	if err != nil || dir == "" {
 		// Fallback to temp directory to ensure an absolute path
 		// (relative paths would fragment sessions across working directories)
 		return filepath.Join(os.TempDir(), "ntm", sessionDirName)
 	}
-	return filepath.Join(ntmDir, sessionDirName)
+	return dir
 }
 
 // Save writes a session state to disk.
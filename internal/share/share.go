@@ -0,0 +1,173 @@
+// Package share mints and verifies session share tokens: scoped, expiring
+// credentials that let a teammate watch one session through ntm serve
+// without full API credentials.
+//
+// Tokens are HMAC-signed with a key stored alongside the ntm config, so the
+// CLI can mint them offline and the server verifies them with the same key.
+// Rotating the key revokes every outstanding token.
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultKeyPath holds the signing key shared by the CLI and ntm serve.
+const DefaultKeyPath = "~/.config/ntm/share.key"
+
+// TokenPrefix marks share tokens so servers can tell them apart from API
+// keys and OIDC bearer tokens.
+const TokenPrefix = "ntms_"
+
+// ScopeRead grants read-only access: status, agents, events, and the SSE
+// stream of one session.
+const ScopeRead = "read"
+
+// DefaultTTL and MaxTTL bound how long a share stays valid.
+const (
+	DefaultTTL = time.Hour
+	MaxTTL     = 7 * 24 * time.Hour
+)
+
+const keySize = 32
+
+var (
+	// ErrInvalidToken means the token is malformed or its signature does
+	// not match the current key (including after a key rotation).
+	ErrInvalidToken = errors.New("invalid share token")
+	// ErrExpired means the token was valid but its TTL has passed.
+	ErrExpired = errors.New("share token expired")
+)
+
+// Grant is the access a share token carries.
+type Grant struct {
+	ID        string    `json:"id"`
+	Session   string    `json:"session"`
+	Scope     string    `json:"scope"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LoadKey reads the signing key at path (DefaultKeyPath if empty).
+func LoadKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(keyPath(path))
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("share key %s is corrupt", keyPath(path))
+	}
+	return key, nil
+}
+
+// LoadOrCreateKey reads the signing key, creating it with mode 0600 if it
+// does not exist yet.
+func LoadOrCreateKey(path string) ([]byte, error) {
+	key, err := LoadKey(path)
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return key, err
+	}
+	return RotateKey(path)
+}
+
+// RotateKey replaces the signing key, revoking every token minted with the
+// old one.
+func RotateKey(path string) ([]byte, error) {
+	path = keyPath(path)
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate share key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("create share key dir: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("write share key: %w", err)
+	}
+	return key, nil
+}
+
+// Mint creates a token for session valid for ttl from now.
+func Mint(key []byte, session string, ttl time.Duration, now time.Time) (string, Grant, error) {
+	if strings.TrimSpace(session) == "" {
+		return "", Grant{}, fmt.Errorf("session is required")
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if ttl > MaxTTL {
+		return "", Grant{}, fmt.Errorf("ttl %s exceeds maximum %s", ttl, MaxTTL)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", Grant{}, fmt.Errorf("generate share id: %w", err)
+	}
+	grant := Grant{
+		ID:        hex.EncodeToString(id),
+		Session:   session,
+		Scope:     ScopeRead,
+		IssuedAt:  now.UTC().Truncate(time.Second),
+		ExpiresAt: now.Add(ttl).UTC().Truncate(time.Second),
+	}
+	payload, err := json.Marshal(grant)
+	if err != nil {
+		return "", Grant{}, err
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return TokenPrefix + body + "." + sign(key, body), grant, nil
+}
+
+// Verify checks token against key and returns its grant.
+func Verify(key []byte, token string, now time.Time) (Grant, error) {
+	rest, ok := strings.CutPrefix(token, TokenPrefix)
+	if !ok {
+		return Grant{}, ErrInvalidToken
+	}
+	body, sig, ok := strings.Cut(rest, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(sign(key, body))) {
+		return Grant{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return Grant{}, ErrInvalidToken
+	}
+	var grant Grant
+	if err := json.Unmarshal(payload, &grant); err != nil || grant.Session == "" || grant.Scope != ScopeRead {
+		return Grant{}, ErrInvalidToken
+	}
+	if !now.Before(grant.ExpiresAt) {
+		return Grant{}, ErrExpired
+	}
+	return grant, nil
+}
+
+// IsToken reports whether s looks like a share token.
+func IsToken(s string) bool {
+	return strings.HasPrefix(s, TokenPrefix)
+}
+
+func sign(key []byte, body string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(TokenPrefix + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func keyPath(path string) string {
+	if path == "" {
+		path = DefaultKeyPath
+	}
+	return util.ExpandPath(path)
+}
@@ -0,0 +1,80 @@
+package share
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMintVerify(t *testing.T) {
+	t.Parallel()
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), "share.key"))
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey: %v", err)
+	}
+	now := time.Now()
+	token, grant, err := Mint(key, "proj", 2*time.Hour, now)
+	if err != nil {
+		t.Fatalf("Mint: %v", err)
+	}
+	if !IsToken(token) {
+		t.Fatalf("token %q lacks prefix", token)
+	}
+
+	got, err := Verify(key, token, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.Session != "proj" || got.ID != grant.ID || got.Scope != ScopeRead {
+		t.Fatalf("grant = %+v", got)
+	}
+
+	if _, err := Verify(key, token, now.Add(3*time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+	tampered := strings.Replace(token, ".", "x.", 1)
+	if _, err := Verify(key, tampered, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken for tampered token, got %v", err)
+	}
+}
+
+func TestMint_Validation(t *testing.T) {
+	t.Parallel()
+	key := make([]byte, keySize)
+	if _, _, err := Mint(key, "", time.Hour, time.Now()); err == nil {
+		t.Error("expected error for empty session")
+	}
+	if _, _, err := Mint(key, "proj", MaxTTL+time.Hour, time.Now()); err == nil {
+		t.Error("expected error for ttl above MaxTTL")
+	}
+}
+
+func TestRotateKey_RevokesTokens(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "share.key")
+	key, _ := LoadOrCreateKey(path)
+	token, _, _ := Mint(key, "proj", time.Hour, time.Now())
+
+	newKey, err := RotateKey(path)
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if _, err := Verify(newKey, token, time.Now()); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token survived rotation: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key mode = %o, want 0600", info.Mode().Perm())
+	}
+	loaded, err := LoadKey(path)
+	if err != nil || string(loaded) != string(newKey) {
+		t.Errorf("LoadKey after rotate = %x, %v", loaded, err)
+	}
+}
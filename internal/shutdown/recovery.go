@@ -0,0 +1,183 @@
+package shutdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/process"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultMarkerDir holds the running markers of long-lived ntm processes.
+const DefaultMarkerDir = "~/.ntm/run"
+
+// DefaultRecoveryDirs are the directories whose JSONL files are repaired
+// after an unclean shutdown.
+var DefaultRecoveryDirs = []string{
+	"~/.local/share/ntm/audit",
+	"~/.config/ntm/analytics",
+	"~/.ntm/archive",
+}
+
+// Marker records a running ntm process.
+type Marker struct {
+	Name      string    `json:"name"`
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	path      string
+}
+
+// Path returns the marker file location.
+func (m *Marker) Path() string {
+	return m.path
+}
+
+// MarkRunning writes a running marker for the current process under dir
+// (DefaultMarkerDir if empty). The returned function removes the marker and
+// must be called on clean exit.
+func MarkRunning(dir, name string) (func(), error) {
+	if dir == "" {
+		dir = util.ExpandPath(DefaultMarkerDir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return func() {}, fmt.Errorf("creating marker directory: %w", err)
+	}
+	m := Marker{Name: name, PID: os.Getpid(), StartedAt: time.Now().UTC()}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return func() {}, err
+	}
+	path := filepath.Join(dir, markerFilename(name, m.PID))
+	if err := util.AtomicWriteFile(path, data, 0644); err != nil {
+		return func() {}, fmt.Errorf("writing running marker: %w", err)
+	}
+	return func() { _ = os.Remove(path) }, nil
+}
+
+func markerFilename(name string, pid int) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	return fmt.Sprintf("%s.%d.json", safe, pid)
+}
+
+// IsRunning reports whether a live process holds a marker named name under
+// dir (DefaultMarkerDir if empty).
+func IsRunning(dir, name string) bool {
+	if dir == "" {
+		dir = util.ExpandPath(DefaultMarkerDir)
+	}
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range paths {
+		var m Marker
+		data, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(data, &m) != nil {
+			continue
+		}
+		if m.Name == name && process.IsAlive(m.PID) {
+			return true
+		}
+	}
+	return false
+}
+
+// StaleMarkers returns markers under dir (DefaultMarkerDir if empty) whose
+// process is no longer alive, i.e. runs that ended without a clean shutdown.
+// Unreadable markers are treated as stale.
+func StaleMarkers(dir string) ([]Marker, error) {
+	if dir == "" {
+		dir = util.ExpandPath(DefaultMarkerDir)
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var stale []Marker
+	for _, path := range paths {
+		var m Marker
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(data, &m)
+		}
+		m.path = path
+		if err != nil || !process.IsAlive(m.PID) {
+			if m.Name == "" {
+				m.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+			}
+			stale = append(stale, m)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].StartedAt.Before(stale[j].StartedAt) })
+	return stale, nil
+}
+
+// RepairedFile describes a JSONL file whose partial trailing record was
+// removed.
+type RepairedFile struct {
+	Path           string `json:"path"`
+	TruncatedBytes int64  `json:"truncated_bytes"`
+}
+
+// RecoveryReport summarizes startup recovery after an unclean shutdown.
+type RecoveryReport struct {
+	Markers  []Marker       `json:"markers"`
+	Repaired []RepairedFile `json:"repaired,omitempty"`
+	Errors   []string       `json:"errors,omitempty"`
+}
+
+// RecoverUnclean checks markerDir for runs that exited without a clean
+// shutdown. If any are found it repairs the JSONL files in dirs
+// (DefaultRecoveryDirs if nil) and clears the stale markers. It returns nil
+// when there was nothing to recover.
+func RecoverUnclean(markerDir string, dirs []string) (*RecoveryReport, error) {
+	stale, err := StaleMarkers(markerDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(stale) == 0 {
+		return nil, nil
+	}
+	if dirs == nil {
+		dirs = DefaultRecoveryDirs
+	}
+
+	report := &RecoveryReport{Markers: stale}
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(util.ExpandPath(dir), "*.jsonl"))
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		for _, path := range paths {
+			// Another ntm process may be appending to the file; hold its
+			// lock so a record it is writing is not taken for a torn one.
+			var n int64
+			err := util.WithFileLock(path, func() error {
+				var err error
+				n, err = jsonlutil.TruncatePartialLine(path)
+				return err
+			})
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+			if n > 0 {
+				report.Repaired = append(report.Repaired, RepairedFile{Path: path, TruncatedBytes: n})
+			}
+		}
+	}
+	for _, m := range stale {
+		_ = os.Remove(m.path)
+	}
+	return report, nil
+}
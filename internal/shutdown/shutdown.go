@@ -0,0 +1,180 @@
+// Package shutdown coordinates flushing persisted state when ntm exits and
+// recovering from exits that skipped it.
+//
+// Components that buffer state (rate-limit trackers, capture archives, audit
+// loggers, the bus journal) register a flush hook with a Coordinator. When a
+// long-running command receives SIGTERM it runs the coordinator, which calls
+// the hooks in reverse registration order under a shared deadline.
+//
+// Long-running processes also leave a running marker on disk. A marker whose
+// process is gone means the previous run never reached its clean shutdown
+// path, and the next ntm invocation repairs any JSONL files it may have left
+// with a partially written trailing record.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the flush deadline used when none is configured.
+const DefaultTimeout = 10 * time.Second
+
+// HookFunc flushes one component's state. It should return promptly once ctx
+// is done.
+type HookFunc func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   HookFunc
+}
+
+// HookResult records the outcome of one shutdown hook.
+type HookResult struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+}
+
+// Report summarizes a shutdown run.
+type Report struct {
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Hooks    []HookResult  `json:"hooks"`
+	TimedOut bool          `json:"timed_out,omitempty"`
+}
+
+// Err returns an error describing failed or timed-out hooks, or nil.
+func (r *Report) Err() error {
+	var errs []error
+	for _, h := range r.Hooks {
+		switch {
+		case h.TimedOut:
+			errs = append(errs, fmt.Errorf("%s: deadline exceeded", h.Name))
+		case h.Error != "":
+			errs = append(errs, fmt.Errorf("%s: %s", h.Name, h.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Coordinator runs registered shutdown hooks exactly once.
+type Coordinator struct {
+	mu     sync.Mutex
+	hooks  []hook
+	done   bool
+	report *Report
+}
+
+// NewCoordinator creates an empty coordinator.
+func NewCoordinator() *Coordinator {
+	return &Coordinator{}
+}
+
+// Register adds a hook. Hooks run in reverse registration order, like defers,
+// so state is flushed before the components it depends on are closed.
+// Hooks registered after Run has started are ignored.
+func (c *Coordinator) Register(name string, fn HookFunc) {
+	if fn == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done {
+		return
+	}
+	c.hooks = append(c.hooks, hook{name: name, fn: fn})
+}
+
+// Run calls every registered hook sequentially, giving them timeout in total
+// (DefaultTimeout if zero or negative). A hook that overruns the deadline is
+// abandoned and the remaining hooks are still attempted with an already
+// expired context, so fast synchronous flushes get a chance to complete.
+// Subsequent calls return the first run's report.
+func (c *Coordinator) Run(timeout time.Duration) *Report {
+	c.mu.Lock()
+	if c.done {
+		report := c.report
+		c.mu.Unlock()
+		return report
+	}
+	c.done = true
+	hooks := c.hooks
+	c.hooks = nil
+	report := &Report{Started: time.Now()}
+	c.report = report
+	c.mu.Unlock()
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		result := runHook(ctx, hooks[i])
+		if result.TimedOut {
+			report.TimedOut = true
+		}
+		report.Hooks = append(report.Hooks, result)
+	}
+	report.Duration = time.Since(report.Started)
+	return report
+}
+
+// runHook calls h, returning early if ctx expires first. Panics are reported
+// as hook errors so one faulty component cannot skip the others.
+func runHook(ctx context.Context, h hook) HookResult {
+	start := time.Now()
+	result := HookResult{Name: h.name}
+
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errCh <- h.fn(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			result.Error = err.Error()
+		}
+	case <-ctx.Done():
+		// Give hooks started after the deadline a brief window to finish
+		// synchronous work before giving up on them.
+		select {
+		case err := <-errCh:
+			if err != nil {
+				result.Error = err.Error()
+			}
+		case <-time.After(expiredGrace):
+			result.TimedOut = true
+		}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// expiredGrace is how long a hook may run once the deadline has passed.
+var expiredGrace = 100 * time.Millisecond
+
+// Default coordinator shared by the CLI.
+var defaultCoordinator = NewCoordinator()
+
+// Register adds a hook to the default coordinator.
+func Register(name string, fn HookFunc) {
+	defaultCoordinator.Register(name, fn)
+}
+
+// Run runs the default coordinator's hooks.
+func Run(timeout time.Duration) *Report {
+	return defaultCoordinator.Run(timeout)
+}
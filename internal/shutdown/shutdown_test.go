@@ -0,0 +1,208 @@
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+func TestCoordinatorRunsHooksInReverseOrder(t *testing.T) {
+	c := NewCoordinator()
+	var order []string
+	for _, name := range []string{"audit", "journal", "archive"} {
+		name := name
+		c.Register(name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	report := c.Run(time.Second)
+	if got := strings.Join(order, ","); got != "archive,journal,audit" {
+		t.Fatalf("order = %s, want archive,journal,audit", got)
+	}
+	if err := report.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Hooks) != 3 {
+		t.Fatalf("expected 3 hook results, got %d", len(report.Hooks))
+	}
+}
+
+func TestCoordinatorRunsOnce(t *testing.T) {
+	c := NewCoordinator()
+	calls := 0
+	c.Register("flush", func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	first := c.Run(time.Second)
+	c.Register("late", func(context.Context) error {
+		t.Fatal("hook registered after Run should not be called")
+		return nil
+	})
+	second := c.Run(time.Second)
+
+	if calls != 1 {
+		t.Fatalf("hook called %d times, want 1", calls)
+	}
+	if first != second {
+		t.Fatal("second Run should return the first report")
+	}
+}
+
+func TestCoordinatorDeadline(t *testing.T) {
+	oldGrace := expiredGrace
+	expiredGrace = 10 * time.Millisecond
+	defer func() { expiredGrace = oldGrace }()
+
+	c := NewCoordinator()
+	flushed := false
+	c.Register("fast", func(context.Context) error {
+		flushed = true
+		return nil
+	})
+	c.Register("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := c.Run(50 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Run took %v, deadline not enforced", elapsed)
+	}
+	if !report.TimedOut {
+		t.Fatal("expected report to be marked timed out")
+	}
+	if !report.Hooks[0].TimedOut || report.Hooks[0].Name != "stuck" {
+		t.Fatalf("expected stuck hook to time out, got %+v", report.Hooks[0])
+	}
+	if !flushed {
+		t.Fatal("hooks after a timed-out hook should still run")
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "stuck") {
+		t.Fatalf("Err() = %v, want mention of stuck hook", err)
+	}
+}
+
+func TestCoordinatorHookErrorsAndPanics(t *testing.T) {
+	c := NewCoordinator()
+	c.Register("fails", func(context.Context) error { return errors.New("disk full") })
+	c.Register("panics", func(context.Context) error { panic("boom") })
+
+	report := c.Run(time.Second)
+	err := report.Err()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"fails: disk full", "panics: panic: boom"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Err() = %q, missing %q", err, want)
+		}
+	}
+}
+
+func TestMarkRunningAndRecoverUnclean(t *testing.T) {
+	markerDir := t.TempDir()
+	logDir := t.TempDir()
+
+	remove, err := MarkRunning(markerDir, "monitor-proj")
+	if err != nil {
+		t.Fatalf("MarkRunning: %v", err)
+	}
+	if !IsRunning(markerDir, "monitor-proj") || IsRunning(markerDir, "monitor-other") {
+		t.Fatal("IsRunning should match only the live marker's name")
+	}
+	// Our own process is alive, so nothing is stale yet.
+	if report, err := RecoverUnclean(markerDir, []string{logDir}); err != nil || report != nil {
+		t.Fatalf("live marker: report=%v err=%v", report, err)
+	}
+	remove()
+	if entries, _ := os.ReadDir(markerDir); len(entries) != 0 {
+		t.Fatalf("marker not removed: %v", entries)
+	}
+
+	// Simulate a run that was killed: a marker for a dead pid and a log
+	// with a half-written record.
+	stale, _ := json.Marshal(Marker{Name: "serve", PID: 999999999, StartedAt: time.Now()})
+	if err := os.WriteFile(filepath.Join(markerDir, "serve.999999999.json"), stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(logDir, "audit.jsonl")
+	if err := os.WriteFile(logPath, []byte("{\"seq\":1}\n{\"seq\":2,\"che"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if IsRunning(markerDir, "serve") {
+		t.Fatal("IsRunning reported a dead process")
+	}
+
+	report, err := RecoverUnclean(markerDir, []string{logDir})
+	if err != nil {
+		t.Fatalf("RecoverUnclean: %v", err)
+	}
+	if report == nil || len(report.Markers) != 1 || report.Markers[0].Name != "serve" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Repaired) != 1 || report.Repaired[0].Path != logPath {
+		t.Fatalf("expected audit log to be repaired, got %+v", report.Repaired)
+	}
+	data, _ := os.ReadFile(logPath)
+	if string(data) != "{\"seq\":1}\n" {
+		t.Fatalf("log content = %q", data)
+	}
+	if entries, _ := os.ReadDir(markerDir); len(entries) != 0 {
+		t.Fatalf("stale marker not cleared: %v", entries)
+	}
+}
+
+func TestRecoverUncleanWaitsForFileLock(t *testing.T) {
+	markerDir := t.TempDir()
+	logDir := t.TempDir()
+	stale, _ := json.Marshal(Marker{Name: "serve", PID: 999999999, StartedAt: time.Now()})
+	if err := os.WriteFile(filepath.Join(markerDir, "serve.999999999.json"), stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(logDir, "events.jsonl")
+	if err := os.WriteFile(logPath, []byte("{\"seq\":1}\n{\"seq\":2,"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A writer holding the lock is mid-append; recovery must wait for it.
+	unlock, err := util.LockFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan *RecoveryReport, 1)
+	go func() {
+		report, _ := RecoverUnclean(markerDir, []string{logDir})
+		done <- report
+	}()
+	select {
+	case <-done:
+		t.Fatal("RecoverUnclean repaired a file while its lock was held")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := os.WriteFile(logPath, []byte("{\"seq\":1}\n{\"seq\":2}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+
+	select {
+	case report := <-done:
+		if report == nil || len(report.Repaired) != 0 {
+			t.Fatalf("report = %+v, want the completed record kept", report)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RecoverUnclean did not finish after the lock was released")
+	}
+}
@@ -411,6 +411,53 @@ func TestListSessions(t *testing.T) {
 	}
 }
 
+func TestListSessionsPage(t *testing.T) {
+	store := testStore(t)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// page-b and page-c share a timestamp; id breaks the tie.
+	for i, id := range []string{"page-a", "page-b", "page-c", "page-d"} {
+		created := base.Add(time.Duration(i) * time.Minute)
+		if id == "page-c" {
+			created = base.Add(time.Minute)
+		}
+		if err := store.CreateSession(&Session{ID: id, Name: id, ProjectPath: "/p", CreatedAt: created, Status: SessionActive}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 4 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, next, err := store.ListSessionsPage("", cursor, 2)
+		if err != nil {
+			t.Fatalf("ListSessionsPage error: %v", err)
+		}
+		for _, sess := range page {
+			got = append(got, sess.ID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+		// A session created mid-walk sorts first and must not shift later pages.
+		if pages == 0 {
+			_ = store.CreateSession(&Session{ID: "page-new", Name: "new", ProjectPath: "/p", CreatedAt: base.Add(time.Hour), Status: SessionActive})
+		}
+	}
+	want := []string{"page-d", "page-c", "page-b", "page-a"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("walked %v, want %v", got, want)
+	}
+
+	if _, _, err := store.ListSessionsPage("", "not-a-cursor", 2); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+}
+
 func TestUpdateSessionNotFound(t *testing.T) {
 	store := testStore(t)
 
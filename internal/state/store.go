@@ -10,6 +10,8 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
+
+	"github.com/Dicklesworthstone/ntm/internal/pagination"
 )
 
 // Store provides SQLite-backed storage for NTM state.
@@ -180,11 +182,11 @@ func (s *Store) ListSessions(status string) ([]Session, error) {
 	if status == "" {
 		rows, err = s.db.Query(`
 			SELECT id, name, project_path, created_at, status, COALESCE(config_snapshot, ''), COALESCE(coordinator_agent, '')
-			FROM sessions ORDER BY created_at DESC`)
+			FROM sessions ORDER BY created_at DESC, id DESC`)
 	} else {
 		rows, err = s.db.Query(`
 			SELECT id, name, project_path, created_at, status, COALESCE(config_snapshot, ''), COALESCE(coordinator_agent, '')
-			FROM sessions WHERE status = ? ORDER BY created_at DESC`, status)
+			FROM sessions WHERE status = ? ORDER BY created_at DESC, id DESC`, status)
 	}
 
 	if err != nil {
@@ -203,6 +205,72 @@ func (s *Store) ListSessions(status string) ([]Session, error) {
 	return sessions, rows.Err()
 }
 
+// ListSessionsPage returns up to limit sessions (newest first) after the
+// position named by cursor, plus the cursor for the following page, which
+// is empty on the last page. Paging is keyed on (created_at, id), so
+// sessions created between requests never shift later pages.
+func (s *Store) ListSessionsPage(status, cursor string, limit int) ([]Session, string, error) {
+	after, err := pagination.Decode(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if after != nil && len(after) != 2 {
+		return nil, "", pagination.ErrInvalidCursor
+	}
+	if limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	query := `
+		SELECT id, name, project_path, created_at, status, COALESCE(config_snapshot, ''), COALESCE(coordinator_agent, ''), CAST(created_at AS TEXT)
+		FROM sessions WHERE 1 = 1`
+	var args []interface{}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	if after != nil {
+		query += ` AND (CAST(created_at AS TEXT) < ? OR (CAST(created_at AS TEXT) = ? AND id < ?))`
+		args = append(args, after[0], after[0], after[1])
+	}
+	// Fetch one extra row to learn whether another page follows.
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		sessions []Session
+		keys     []string
+	)
+	for rows.Next() {
+		var sess Session
+		var createdKey string
+		if err := rows.Scan(&sess.ID, &sess.Name, &sess.ProjectPath, &sess.CreatedAt, &sess.Status, &sess.ConfigSnapshot, &sess.CoordinatorAgent, &createdKey); err != nil {
+			return nil, "", fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, sess)
+		keys = append(keys, createdKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(sessions) > limit {
+		sessions = sessions[:limit]
+		next = pagination.Encode(keys[limit-1], sessions[limit-1].ID)
+	}
+	return sessions, next, nil
+}
+
 // DeleteSession deletes a session and all related data (cascading).
 func (s *Store) DeleteSession(id string) error {
 	s.mu.Lock()
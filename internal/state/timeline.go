@@ -46,6 +46,15 @@ const (
 	MarkerStart MarkerType = "start"
 	// MarkerStop indicates session/agent stop (◆).
 	MarkerStop MarkerType = "stop"
+	// MarkerConflict indicates agents touched the same files (⚠).
+	MarkerConflict MarkerType = "conflict"
+	// MarkerRateLimit indicates the agent was paused by a rate limit (⏸).
+	// Details["wait_seconds"] holds the pause length when known.
+	MarkerRateLimit MarkerType = "rate_limit"
+	// MarkerHandoff indicates a handoff, rotation or context reset (⇄).
+	MarkerHandoff MarkerType = "handoff"
+	// MarkerCost indicates a spend checkpoint or budget limit ($).
+	MarkerCost MarkerType = "cost"
 )
 
 // String returns the string representation of MarkerType.
@@ -64,6 +73,14 @@ func (m MarkerType) Symbol() string {
 		return "✗"
 	case MarkerStart, MarkerStop:
 		return "◆"
+	case MarkerConflict:
+		return "⚠"
+	case MarkerRateLimit:
+		return "⏸"
+	case MarkerHandoff:
+		return "⇄"
+	case MarkerCost:
+		return "$"
 	default:
 		return "•"
 	}
@@ -777,6 +777,10 @@ func TestMarkerTypeSymbol(t *testing.T) {
 		{MarkerError, "✗"},
 		{MarkerStart, "◆"},
 		{MarkerStop, "◆"},
+		{MarkerConflict, "⚠"},
+		{MarkerRateLimit, "⏸"},
+		{MarkerHandoff, "⇄"},
+		{MarkerCost, "$"},
 		{MarkerType("unknown"), "•"},
 	}
 
@@ -0,0 +1,289 @@
+package status
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase is where an agent is in its turn, finer than AgentState: an agent
+// at its prompt is ready for new work, one that asked a question (a
+// permission prompt, a yes/no choice) is awaiting input. Both are idle.
+type Phase string
+
+const (
+	// PhaseUnknown means no driver pattern matched.
+	PhaseUnknown Phase = ""
+	// PhaseReady indicates the agent waits at its prompt for new work
+	PhaseReady Phase = "ready"
+	// PhaseWorking indicates the agent shows its busy indicator
+	PhaseWorking Phase = "working"
+	// PhaseAwaitingInput indicates the agent waits on an answer to a question
+	PhaseAwaitingInput Phase = "awaiting_input"
+)
+
+// State returns the AgentState a phase reports as.
+func (p Phase) State() AgentState {
+	switch p {
+	case PhaseReady, PhaseAwaitingInput:
+		return StateIdle
+	case PhaseWorking:
+		return StateWorking
+	default:
+		return StateUnknown
+	}
+}
+
+// phaseForState is the phase the generic detector's state implies.
+func phaseForState(s AgentState) Phase {
+	switch s {
+	case StateIdle:
+		return PhaseReady
+	case StateWorking:
+		return PhaseWorking
+	default:
+		return PhaseUnknown
+	}
+}
+
+// GenericDetector names the pattern-agnostic fallback in AgentStatus.Detector.
+const GenericDetector = "generic"
+
+// PatternSet is a driver's patterns for the CLI versions from MinVersion
+// up to the next set's. Patterns are matched against the last TailLines
+// non-empty lines of ANSI-stripped output; a question outranks a busy
+// indicator, which outranks a prompt, since CLIs keep their prompt or
+// status bar drawn while they work or ask.
+type PatternSet struct {
+	// Name identifies the set in AgentStatus.Detector ("cc/v2").
+	Name string
+	// MinVersion is the first CLI version the set fits; empty for any.
+	MinVersion string
+	// TailLines is how many non-empty lines from the end to scan.
+	TailLines int
+
+	Ready    []*regexp.Regexp // Prompt waiting for new work
+	Working  []*regexp.Regexp // Busy indicators: spinners, "esc to interrupt"
+	Awaiting []*regexp.Regexp // Questions: permission prompts, confirmations
+}
+
+// classify returns the phase the set's patterns show in tail.
+func (s *PatternSet) classify(tail string) Phase {
+	switch {
+	case matchAnyPattern(tail, s.Awaiting):
+		return PhaseAwaitingInput
+	case matchAnyPattern(tail, s.Working):
+		return PhaseWorking
+	case matchAnyPattern(tail, s.Ready):
+		return PhaseReady
+	default:
+		return PhaseUnknown
+	}
+}
+
+func matchAnyPattern(s string, patterns []*regexp.Regexp) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Driver detects the phase of one agent CLI. Its pattern sets are
+// versioned because CLIs redraw their prompt and spinner between
+// releases: the set for the version seen in the pane's banner is used, or
+// newest first when no version was seen.
+type Driver struct {
+	// AgentType is the pane agent type the driver handles ("cc").
+	AgentType string
+	// Version extracts the CLI version from its banner, in the first
+	// capturing group that matched.
+	Version *regexp.Regexp
+	// Sets are the pattern sets, in any order.
+	Sets []PatternSet
+}
+
+// setsFor returns the sets to try for version, best first.
+func (d *Driver) setsFor(version string) []*PatternSet {
+	sets := make([]*PatternSet, len(d.Sets))
+	for i := range d.Sets {
+		sets[i] = &d.Sets[i]
+	}
+	// Newest first.
+	sort.SliceStable(sets, func(i, j int) bool {
+		return compareCLIVersions(sets[i].MinVersion, sets[j].MinVersion) > 0
+	})
+	if version == "" {
+		return sets
+	}
+	for i, s := range sets {
+		if compareCLIVersions(version, s.MinVersion) >= 0 {
+			return sets[i : i+1]
+		}
+	}
+	return nil
+}
+
+// compareCLIVersions compares dotted numeric versions; empty is lowest.
+func compareCLIVersions(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	if a == "" {
+		pa = nil
+	}
+	if b == "" {
+		pb = nil
+	}
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]*Driver{}
+)
+
+// RegisterDriver adds or replaces the driver for its agent type. It is
+// safe to call concurrently with detection.
+func RegisterDriver(d *Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[d.AgentType] = d
+}
+
+// DriverFor returns the driver for agentType, or nil when only the
+// generic detector applies.
+func DriverFor(agentType string) *Driver {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	return drivers[agentType]
+}
+
+// driftAfter is how many observations in a row with output but no driver
+// match mark a pane's patterns as drifted. The CLIs with drivers always
+// show a prompt, spinner or question, so a run of misses means the CLI
+// changed under the patterns.
+const driftAfter = 3
+
+// Machine tracks one pane's phase across observations. Each observation
+// is classified by the driver's patterns and moves the machine along:
+//
+//	any  --question-->  awaiting_input
+//	any  --busy------>  working
+//	any  --prompt---->  ready
+//	any  --no match-->  unknown (the generic detector decides)
+//
+// The machine remembers the CLI version once a banner shows it, since
+// banners scroll away, and counts consecutive misses to flag drift. It is
+// safe for concurrent use.
+type Machine struct {
+	mu      sync.Mutex
+	driver  *Driver
+	version string
+	phase   Phase
+	since   time.Time
+	misses  int
+}
+
+// NewMachine returns a machine for driver, starting in PhaseUnknown.
+func NewMachine(driver *Driver) *Machine {
+	return &Machine{driver: driver}
+}
+
+// Observation is what one look at a pane showed.
+type Observation struct {
+	Phase    Phase     // PhaseUnknown when no pattern matched
+	Previous Phase     // Phase before this observation
+	Since    time.Time // When the machine entered Phase
+	Detector string    // "<agent>/<set>" that matched, or GenericDetector
+	Version  string    // CLI version, if a banner showed it
+	Drift    bool      // Patterns missed driftAfter observations in a row
+}
+
+// Changed reports whether the observation moved the machine.
+func (o Observation) Changed() bool {
+	return o.Phase != o.Previous
+}
+
+// Observe classifies output captured at now and steps the machine.
+func (m *Machine) Observe(output string, now time.Time) Observation {
+	clean := StripANSI(output)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.driver.Version != nil {
+		// The last banner wins: the CLI may have been relaunched after
+		// an upgrade.
+		if all := m.driver.Version.FindAllStringSubmatch(clean, -1); len(all) > 0 {
+			for _, v := range all[len(all)-1][1:] {
+				if v != "" {
+					m.version = v
+					break
+				}
+			}
+		}
+	}
+
+	phase, detector := PhaseUnknown, GenericDetector
+	for _, set := range m.driver.setsFor(m.version) {
+		if p := set.classify(lastNonEmptyLines(clean, set.TailLines)); p != PhaseUnknown {
+			phase, detector = p, m.driver.AgentType+"/"+set.Name
+			break
+		}
+	}
+
+	switch {
+	case phase != PhaseUnknown:
+		m.misses = 0
+	case strings.TrimSpace(clean) != "":
+		m.misses++
+	}
+
+	obs := Observation{
+		Previous: m.phase,
+		Phase:    phase,
+		Detector: detector,
+		Version:  m.version,
+		Drift:    m.misses >= driftAfter,
+	}
+	if phase != m.phase || m.since.IsZero() {
+		m.since = now
+	}
+	m.phase = phase
+	obs.Since = m.since
+	return obs
+}
+
+// lastNonEmptyLines returns the last n non-empty lines of s, in order.
+func lastNonEmptyLines(s string, n int) string {
+	if n <= 0 {
+		n = 10
+	}
+	lines := strings.Split(s, "\n")
+	var tail []string
+	for i := len(lines) - 1; i >= 0 && len(tail) < n; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			tail = append(tail, lines[i])
+		}
+	}
+	for i, j := 0, len(tail)-1; i < j; i, j = i+1, j-1 {
+		tail[i], tail[j] = tail[j], tail[i]
+	}
+	return strings.Join(tail, "\n")
+}
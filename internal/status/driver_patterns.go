@@ -0,0 +1,110 @@
+package status
+
+import "regexp"
+
+// Built-in drivers. When a CLI release redraws its prompt or spinner, add
+// a PatternSet with that release as MinVersion rather than editing the old
+// set, and collect its screens into the transcript corpus with
+// 'ntm fixture transcript add'.
+var builtinDrivers = []*Driver{
+	{
+		AgentType: "cc",
+		// Welcome banner ("Claude Code v2.0.14") or `claude --version`
+		// ("1.0.51 (Claude Code)"); 1.x banners carry no version.
+		Version: regexp.MustCompile(`(?i)claude code v(\d+(?:\.\d+)+)|(\d+(?:\.\d+)+) \(claude code\)`),
+		Sets: []PatternSet{
+			{
+				// Boxed input ("│ > │") under a "✻ Thinking… (12s · esc to
+				// interrupt)" spinner while working.
+				Name:      "v1",
+				TailLines: 12,
+				Ready: []*regexp.Regexp{
+					regexp.MustCompile(`(?m)^\s*│\s*>[\s\x{00a0}]*│?\s*$`),
+					regexp.MustCompile(`(?m)^>\s*$`),
+				},
+				Working: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)\besc to interrupt\b`),
+					regexp.MustCompile(`(?m)^\s*\S\s+\w+…\s+\(\d+s`),
+				},
+				Awaiting: ccQuestionPatterns,
+			},
+			{
+				// Open "❯" input between rules, status bar below it.
+				Name:       "v2",
+				MinVersion: "2.0.0",
+				TailLines:  12,
+				Ready: []*regexp.Regexp{
+					regexp.MustCompile(`(?m)^\s*[>❯][\s\x{00a0}]*$`),
+				},
+				Working: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)\besc to interrupt\b`),
+					regexp.MustCompile(`(?m)^\s*\S\s+\w+…\s+\(`),
+					regexp.MustCompile(`·\s*thinking`),
+				},
+				Awaiting: ccQuestionPatterns,
+			},
+		},
+	},
+	{
+		AgentType: "cod",
+		Version:   regexp.MustCompile(`(?i)openai codex(?: cli)?\s*\(?v(\d+(?:\.\d+)+)`),
+		Sets: []PatternSet{
+			{
+				// "› " composer over a "47% context left · ? for shortcuts"
+				// footer; "• Working (5s • esc to interrupt)" while busy.
+				Name:      "v0",
+				TailLines: 8,
+				Ready: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)\d+%\s*context\s*left`),
+					regexp.MustCompile(`\?\s*for\s*shortcuts`),
+					regexp.MustCompile(`(?m)^codex>\s*$`),
+				},
+				Working: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)\besc to interrupt\b`),
+				},
+				Awaiting: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)allow command\?`),
+					regexp.MustCompile(`(?i)would you like to (run|make|apply) the following`),
+					regexp.MustCompile(`(?m)^\s*›\s*1\.\s+Yes`),
+				},
+			},
+		},
+	},
+	{
+		AgentType: "gmi",
+		Version:   regexp.MustCompile(`(?i)gemini(?: cli)?\s+v(\d+(?:\.\d+)+)`),
+		Sets: []PatternSet{
+			{
+				// "> Type your message" input box; "(esc to cancel, 12s)"
+				// beside the spinner while busy.
+				Name:      "v0",
+				TailLines: 10,
+				Ready: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)type your message`),
+					regexp.MustCompile(`(?m)^gemini>\s*$`),
+				},
+				Working: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)\(esc to cancel`),
+				},
+				Awaiting: []*regexp.Regexp{
+					regexp.MustCompile(`(?i)allow execution`),
+					regexp.MustCompile(`(?i)apply this change\?`),
+					regexp.MustCompile(`(?i)waiting for user confirmation`),
+				},
+			},
+		},
+	},
+}
+
+// ccQuestionPatterns match Claude Code's permission and choice prompts,
+// unchanged across its releases so far.
+var ccQuestionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)do you want to (proceed|make this edit|create|overwrite|run)`),
+	regexp.MustCompile(`(?m)^[│\s]*❯\s*1\.\s+Yes`),
+}
+
+func init() {
+	for _, d := range builtinDrivers {
+		RegisterDriver(d)
+	}
+}
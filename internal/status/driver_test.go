@@ -0,0 +1,227 @@
+package status
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMachine_VersionSelectsPatternSet(t *testing.T) {
+	working := "✻ Pondering… (34s · esc to interrupt)\n\n│ >   │\n"
+
+	// No version seen: newest set first.
+	obs := NewMachine(DriverFor("cc")).Observe(working, time.Now())
+	if obs.Detector != "cc/v2" {
+		t.Errorf("unversioned detector = %q, want cc/v2", obs.Detector)
+	}
+
+	m := NewMachine(DriverFor("cc"))
+	obs = m.Observe("1.0.51 (Claude Code)\n"+working, time.Now())
+	if obs.Version != "1.0.51" || obs.Detector != "cc/v1" {
+		t.Errorf("got version %q detector %q, want 1.0.51 cc/v1", obs.Version, obs.Detector)
+	}
+
+	// The banner has scrolled away; the version sticks.
+	obs = m.Observe(working, time.Now())
+	if obs.Version != "1.0.51" || obs.Detector != "cc/v1" {
+		t.Errorf("after scroll got version %q detector %q, want 1.0.51 cc/v1", obs.Version, obs.Detector)
+	}
+
+	// A relaunch after an upgrade shows a newer banner below the old one.
+	obs = m.Observe("1.0.51 (Claude Code)\n ▐▛███▜▌   Claude Code v2.0.14\n"+working, time.Now())
+	if obs.Version != "2.0.14" || obs.Detector != "cc/v2" {
+		t.Errorf("after upgrade got version %q detector %q, want 2.0.14 cc/v2", obs.Version, obs.Detector)
+	}
+}
+
+func TestMachine_VersionedSetDoesNotFallBack(t *testing.T) {
+	// A 2.x pane showing only the 1.x boxed prompt is drift, not v1.
+	m := NewMachine(DriverFor("cc"))
+	obs := m.Observe("Claude Code v2.0.14\n│ >   │\n", time.Now())
+	if obs.Phase != PhaseUnknown || obs.Detector != GenericDetector {
+		t.Errorf("got phase %q detector %q, want unknown generic", obs.Phase, obs.Detector)
+	}
+}
+
+func TestMachine_Transitions(t *testing.T) {
+	m := NewMachine(DriverFor("cod"))
+	t0 := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		output    string
+		at        time.Time
+		want      Phase
+		wantSince time.Time
+		changed   bool
+	}{
+		{"›\n  100% context left · ? for shortcuts", t0, PhaseReady, t0, true},
+		{"• Working (1s • esc to interrupt)\n  99% context left", t0.Add(time.Second), PhaseWorking, t0.Add(time.Second), true},
+		{"• Working (5s • esc to interrupt)\n  99% context left", t0.Add(5 * time.Second), PhaseWorking, t0.Add(time.Second), false},
+		{"  Allow command?\n› 1. Yes", t0.Add(6 * time.Second), PhaseAwaitingInput, t0.Add(6 * time.Second), true},
+		{"›\n  97% context left · ? for shortcuts", t0.Add(9 * time.Second), PhaseReady, t0.Add(9 * time.Second), true},
+	}
+	for i, s := range steps {
+		obs := m.Observe(s.output, s.at)
+		if obs.Phase != s.want {
+			t.Errorf("step %d: phase = %q, want %q", i, obs.Phase, s.want)
+		}
+		if !obs.Since.Equal(s.wantSince) {
+			t.Errorf("step %d: since = %v, want %v", i, obs.Since, s.wantSince)
+		}
+		if obs.Changed() != s.changed {
+			t.Errorf("step %d: changed = %v, want %v", i, obs.Changed(), s.changed)
+		}
+	}
+}
+
+func TestMachine_DriftAfterConsecutiveMisses(t *testing.T) {
+	m := NewMachine(DriverFor("gmi"))
+	now := time.Now()
+	unrecognized := "◆ Gemini 3 ◆\n\n⟩ _\n"
+
+	for i := 1; i <= driftAfter; i++ {
+		obs := m.Observe(unrecognized, now)
+		if obs.Phase != PhaseUnknown {
+			t.Fatalf("miss %d: phase = %q, want unknown", i, obs.Phase)
+		}
+		if want := i >= driftAfter; obs.Drift != want {
+			t.Errorf("miss %d: drift = %v, want %v", i, obs.Drift, want)
+		}
+	}
+
+	// Blank captures are not evidence either way.
+	if obs := m.Observe("\n\n", now); !obs.Drift {
+		t.Error("blank capture cleared drift")
+	}
+	if obs := m.Observe("│ >   Type your message │", now); obs.Drift {
+		t.Error("match did not clear drift")
+	}
+}
+
+func TestRegisterDriver(t *testing.T) {
+	RegisterDriver(&Driver{
+		AgentType: "test-agent",
+		Sets: []PatternSet{{
+			Name:  "v0",
+			Ready: []*regexp.Regexp{regexp.MustCompile(`(?m)^test>\s*$`)},
+		}},
+	})
+	t.Cleanup(func() {
+		driversMu.Lock()
+		delete(drivers, "test-agent")
+		driversMu.Unlock()
+	})
+
+	d := NewDetector()
+	st := d.Analyze("%1", "proj__test_1", "test-agent", "done\ntest>", time.Now().Add(-time.Minute))
+	if st.State != StateIdle || st.Phase != PhaseReady || st.Detector != "test-agent/v0" {
+		t.Errorf("got state %q phase %q detector %q", st.State, st.Phase, st.Detector)
+	}
+}
+
+func TestCompareCLIVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.0.0", "2.0.0", 0},
+		{"2.0.14", "2.0.0", 1},
+		{"1.0.51", "2.0.0", -1},
+		{"2.10.0", "2.9.0", 1},
+		{"2.0", "2.0.0", -1},
+		{"", "0.0.1", -1},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		got := compareCLIVersions(tt.a, tt.b)
+		if (got > 0) != (tt.want > 0) || (got < 0) != (tt.want < 0) {
+			t.Errorf("compareCLIVersions(%q, %q) = %d, want sign of %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestUnifiedDetector_DriverPhases(t *testing.T) {
+	d := NewDetector()
+	stale := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name         string
+		agentType    string
+		output       string
+		wantState    AgentState
+		wantPhase    Phase
+		wantDetector string
+	}{
+		{
+			name:         "working spinner",
+			agentType:    "cc",
+			output:       "Claude Code v2.0.14\n✽ Channelling… (esc to interrupt · 12s)\n> \n",
+			wantState:    StateWorking,
+			wantPhase:    PhaseWorking,
+			wantDetector: "cc/v2",
+		},
+		{
+			name:         "permission prompt is idle awaiting input",
+			agentType:    "cc",
+			output:       "Claude Code v2.0.14\n Do you want to proceed?\n ❯ 1. Yes\n   2. No\n",
+			wantState:    StateIdle,
+			wantPhase:    PhaseAwaitingInput,
+			wantDetector: "cc/v2",
+		},
+		{
+			name:         "stale error above prompt is idle",
+			agentType:    "cod",
+			output:       "Error: rate limit exceeded\n›\n  90% context left · ? for shortcuts",
+			wantState:    StateIdle,
+			wantPhase:    PhaseReady,
+			wantDetector: "cod/v0",
+		},
+		{
+			name:         "no driver match falls back to generic",
+			agentType:    "gmi",
+			output:       "some unrelated output\n$ ",
+			wantState:    StateIdle,
+			wantPhase:    PhaseReady,
+			wantDetector: GenericDetector,
+		},
+		{
+			name:         "user pane uses generic",
+			agentType:    "user",
+			output:       "dev@box:~$ ",
+			wantState:    StateIdle,
+			wantPhase:    PhaseReady,
+			wantDetector: GenericDetector,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := d.Analyze("", "", tt.agentType, tt.output, stale)
+			if st.State != tt.wantState || st.Phase != tt.wantPhase || st.Detector != tt.wantDetector {
+				t.Errorf("got state %q phase %q detector %q, want %q %q %q",
+					st.State, st.Phase, st.Detector, tt.wantState, tt.wantPhase, tt.wantDetector)
+			}
+		})
+	}
+}
+
+func TestUnifiedDetector_PatternDriftPerPane(t *testing.T) {
+	d := NewDetector()
+	stale := time.Now().Add(-time.Minute)
+	unrecognized := "╔ new UI ╗\n▸ say something"
+
+	var st AgentStatus
+	for i := 0; i < driftAfter; i++ {
+		st = d.Analyze("%3", "proj__cc_1", "cc", unrecognized, stale)
+	}
+	if !st.PatternDrift {
+		t.Error("expected pattern drift after repeated misses")
+	}
+	if st.Detector != GenericDetector || st.State == StateUnknown {
+		t.Errorf("drifted pane: detector %q state %q, want generic fallback with a state", st.Detector, st.State)
+	}
+
+	// Another pane has its own machine.
+	if other := d.Analyze("%4", "proj__cc_2", "cc", unrecognized, stale); other.PatternDrift {
+		t.Error("drift leaked to another pane")
+	}
+}
@@ -91,6 +91,13 @@ type AgentStatus struct {
 	State AgentState `json:"state"`
 	// ErrorType categorizes the error if State == StateError
 	ErrorType ErrorType `json:"error_type,omitempty"`
+	// Phase refines State: ready or awaiting_input when idle
+	Phase Phase `json:"phase,omitempty"`
+	// Detector names the pattern set that decided State ("cc/v2"), or "generic"
+	Detector string `json:"detector,omitempty"`
+	// PatternDrift is set when the agent's driver patterns stopped matching
+	// its output, which usually means the CLI changed its prompt
+	PatternDrift bool `json:"pattern_drift,omitempty"`
 	// LastActive is when the pane last had output activity
 	LastActive time.Time `json:"last_active"`
 	// LastOutput contains the last N characters of output (for preview)
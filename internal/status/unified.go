@@ -3,6 +3,7 @@ package status
 import (
 	"context"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/agent"
@@ -13,6 +14,10 @@ import (
 // activity, prompt, and error detection into a unified status check.
 type UnifiedDetector struct {
 	config DetectorConfig
+
+	// machines holds each pane's driver state machine, keyed by pane ID.
+	mu       sync.Mutex
+	machines map[string]*Machine
 }
 
 // NewDetector creates a new UnifiedDetector with default configuration
@@ -47,9 +52,7 @@ func (d *UnifiedDetector) Analyze(paneID, paneName, agentType string, output str
 		LastOutput: truncateOutput(output, d.config.OutputPreviewLength),
 	}
 
-	state, errType := d.determineState(output, agentType, lastActivity)
-	status.State = state
-	status.ErrorType = errType
+	d.applyState(&status, output)
 
 	// Extract metrics using agent parser
 	if isKnownAgentType(agentType) {
@@ -67,6 +70,68 @@ func (d *UnifiedDetector) Analyze(paneID, paneName, agentType string, output str
 	return status
 }
 
+// applyState sets status's state, phase and detector from output. The
+// pane's driver state machine decides when its patterns match; otherwise
+// the generic determineState does, and the status records which one did.
+func (d *UnifiedDetector) applyState(status *AgentStatus, output string) {
+	if m := d.machine(status.PaneID, status.AgentType); m != nil {
+		obs := m.Observe(output, time.Now())
+		status.PatternDrift = obs.Drift
+		if obs.Phase != PhaseUnknown {
+			status.Phase = obs.Phase
+			status.Detector = obs.Detector
+			status.State, status.ErrorType = d.stateForPhase(obs.Phase, output, status.LastActive)
+			return
+		}
+	}
+
+	status.State, status.ErrorType = d.determineState(output, status.AgentType, status.LastActive)
+	status.Phase = phaseForState(status.State)
+	status.Detector = GenericDetector
+}
+
+// stateForPhase maps a driver phase to a state. A question or busy
+// indicator is trusted outright; a prompt is treated like the generic
+// detector's prompt match, so an error printed just before it still
+// reports while the pane is active.
+func (d *UnifiedDetector) stateForPhase(phase Phase, output string, lastActivity time.Time) (AgentState, ErrorType) {
+	if phase != PhaseReady {
+		return phase.State(), ErrorNone
+	}
+	threshold := time.Duration(d.config.ActivityThreshold) * time.Second
+	if time.Since(lastActivity) < threshold {
+		if errType := DetectErrorInOutput(output); errType != ErrorNone {
+			return StateError, errType
+		}
+	}
+	return StateIdle, ErrorNone
+}
+
+// machine returns paneID's state machine for agentType's driver, or nil
+// when the agent type has none. A pane respawned with another agent gets
+// a fresh machine.
+func (d *UnifiedDetector) machine(paneID, agentType string) *Machine {
+	driver := DriverFor(agentType)
+	if driver == nil {
+		return nil
+	}
+	if paneID == "" {
+		return NewMachine(driver)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if m, ok := d.machines[paneID]; ok && m.driver == driver {
+		return m
+	}
+	if d.machines == nil {
+		d.machines = make(map[string]*Machine)
+	}
+	m := NewMachine(driver)
+	d.machines[paneID] = m
+	return m
+}
+
 // determineState calculates state based on output and activity
 func (d *UnifiedDetector) determineState(output, agentType string, lastActivity time.Time) (AgentState, ErrorType) {
 	// Detection priority:
@@ -254,9 +319,7 @@ func (d *UnifiedDetector) Detect(paneID string) (AgentStatus, error) {
 	}
 
 	// Use shared logic
-	state, errType := d.determineState(output, status.AgentType, status.LastActive)
-	status.State = state
-	status.ErrorType = errType
+	d.applyState(&status, output)
 
 	// Extract metrics using agent parser
 	if isKnownAgentType(status.AgentType) {
@@ -348,9 +411,7 @@ func (d *UnifiedDetector) DetectAllContext(ctx context.Context, session string)
 		status.LastOutput = truncateOutput(output, d.config.OutputPreviewLength)
 
 		// Use shared logic
-		state, errType := d.determineState(output, status.AgentType, status.LastActive)
-		status.State = state
-		status.ErrorType = errType
+		d.applyState(&status, output)
 
 		// Extract metrics using agent parser
 		if isKnownAgentType(status.AgentType) {
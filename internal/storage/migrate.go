@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MigrateResult describes moving a category from one root to another.
+type MigrateResult struct {
+	Name   string `json:"name"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dry_run,omitempty"`
+	// Moved lists the files moved, relative to From.
+	Moved []string `json:"moved,omitempty"`
+	Bytes int64    `json:"bytes"`
+	// Conflicts lists files left in From because To already has a file of
+	// the same name.
+	Conflicts []string `json:"conflicts,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// Migrate moves the contents of from into to, merging directories that
+// exist on both sides. Files are renamed where possible and copied then
+// removed across filesystems. A file already present in to is left in
+// place in from and reported as a conflict. from is removed once empty. With
+// dryRun nothing is moved.
+func Migrate(name, from, to string, dryRun bool) MigrateResult {
+	res := MigrateResult{Name: name, From: from, To: to, DryRun: dryRun}
+	from, to = filepath.Clean(from), filepath.Clean(to)
+	if from == to {
+		return res
+	}
+	if strings.HasPrefix(to, from+string(filepath.Separator)) {
+		res.Error = fmt.Sprintf("cannot move %s into itself (%s)", from, to)
+		return res
+	}
+	if _, err := os.Stat(from); err != nil {
+		if !os.IsNotExist(err) {
+			res.Error = err.Error()
+		}
+		return res
+	}
+
+	err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		if _, err := os.Lstat(target); err == nil {
+			res.Conflicts = append(res.Conflicts, rel)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := moveFile(path, target, info.Mode()); err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+		}
+		res.Moved = append(res.Moved, rel)
+		res.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if !dryRun {
+		removeEmptyDirs(from)
+	}
+	return res
+}
+
+// moveFile renames src to dst, falling back to copy and remove when they
+// are on different filesystems.
+func moveFile(src, dst string, mode fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || mode&fs.ModeType != 0 {
+		return err
+	}
+	if err := copyFile(src, dst, mode.Perm()); err != nil {
+		_ = os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// removeEmptyDirs removes root and every directory below it that holds no
+// files, deepest first.
+func removeEmptyDirs(root string) {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i]) // Fails unless empty
+	}
+}
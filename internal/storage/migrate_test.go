@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMigrateMergesAndKeepsConflicts(t *testing.T) {
+	t.Parallel()
+
+	base := t.TempDir()
+	from := filepath.Join(base, "old")
+	to := filepath.Join(base, "new")
+	writeArtifact(t, filepath.Join(from, "proj", "assignments.json"), 10, 0)
+	writeArtifact(t, filepath.Join(from, "proj", "prompts.json"), 5, 0)
+	writeArtifact(t, filepath.Join(from, "other", "assignments.json"), 3, 0)
+	writeArtifact(t, filepath.Join(to, "proj", "prompts.json"), 7, 0)
+
+	dry := Migrate("state", from, to, true)
+	if len(dry.Moved) != 2 || dry.Error != "" {
+		t.Fatalf("dry run = %+v", dry)
+	}
+	if _, err := os.Stat(filepath.Join(from, "proj", "assignments.json")); err != nil {
+		t.Fatalf("dry run moved files: %v", err)
+	}
+
+	res := Migrate("state", from, to, false)
+	if res.Error != "" {
+		t.Fatal(res.Error)
+	}
+	sort.Strings(res.Moved)
+	want := []string{filepath.Join("other", "assignments.json"), filepath.Join("proj", "assignments.json")}
+	if !reflect.DeepEqual(res.Moved, want) || res.Bytes != 13 {
+		t.Errorf("moved = %v (%d bytes), want %v", res.Moved, res.Bytes, want)
+	}
+	if want := []string{filepath.Join("proj", "prompts.json")}; !reflect.DeepEqual(res.Conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", res.Conflicts, want)
+	}
+
+	if info, err := os.Stat(filepath.Join(to, "proj", "prompts.json")); err != nil || info.Size() != 7 {
+		t.Errorf("existing file at destination was touched: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(from, "other")); !os.IsNotExist(err) {
+		t.Errorf("emptied directory should be removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(from, "proj", "prompts.json")); err != nil {
+		t.Errorf("conflicting file should stay: %v", err)
+	}
+}
+
+func TestMigrateEdgeCases(t *testing.T) {
+	t.Parallel()
+
+	base := t.TempDir()
+	if res := Migrate("audit", filepath.Join(base, "missing"), filepath.Join(base, "to"), false); res.Error != "" || len(res.Moved) != 0 {
+		t.Errorf("missing source = %+v", res)
+	}
+	if res := Migrate("audit", base, filepath.Join(base, "sub"), false); res.Error == "" {
+		t.Error("moving a directory into itself should fail")
+	}
+	if res := Migrate("audit", base, base+string(filepath.Separator), false); res.Error != "" || len(res.Moved) != 0 {
+		t.Errorf("same directory = %+v", res)
+	}
+}
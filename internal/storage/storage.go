@@ -0,0 +1,282 @@
+// Package storage manages the disk footprint of ntm's artifacts: output
+// archives, checkpoint captures and audit logs. Each category has a quota and
+// retention rules; Prune removes the oldest artifacts that break them.
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// Rule limits how much of a category is kept.
+type Rule struct {
+	// QuotaBytes is the most the category may use (0 = unlimited). When it
+	// is exceeded the oldest artifacts are removed first.
+	QuotaBytes int64 `json:"quota_bytes,omitempty"`
+	// MaxAge removes artifacts older than this regardless of size (0 = never).
+	MaxAge time.Duration `json:"max_age,omitempty"`
+	// KeepFor protects artifacts newer than this from any pruning, so a
+	// quota can never delete the data of the session being worked on.
+	KeepFor time.Duration `json:"keep_for,omitempty"`
+}
+
+// Category is one kind of artifact stored under a directory.
+type Category struct {
+	Name string
+	Dir  string
+	// Depth is where artifacts sit below Dir: 1 for files or directories
+	// directly in Dir, 2 for entries of per-session subdirectories.
+	Depth int
+	Rule  Rule
+}
+
+// Artifact is one prunable file or directory.
+type Artifact struct {
+	Path    string    `json:"path"`
+	Bytes   int64     `json:"bytes"`
+	ModTime time.Time `json:"mod_time"` // Newest file modification inside the artifact
+}
+
+// Scan lists the artifacts of a category, oldest first. A missing directory
+// has no artifacts.
+func Scan(c Category) ([]Artifact, error) {
+	depth := c.Depth
+	if depth < 1 {
+		depth = 1
+	}
+	paths, err := entriesAt(c.Dir, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts := make([]Artifact, 0, len(paths))
+	for _, path := range paths {
+		a := Artifact{Path: path}
+		var dirTime time.Time
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil // Vanished while scanning
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			switch {
+			case !d.IsDir():
+				a.Bytes += info.Size()
+				if info.ModTime().After(a.ModTime) {
+					a.ModTime = info.ModTime()
+				}
+			case p == path:
+				dirTime = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if a.ModTime.IsZero() {
+			a.ModTime = dirTime // Empty directory
+		}
+		artifacts = append(artifacts, a)
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		if !artifacts[i].ModTime.Equal(artifacts[j].ModTime) {
+			return artifacts[i].ModTime.Before(artifacts[j].ModTime)
+		}
+		return artifacts[i].Path < artifacts[j].Path
+	})
+	return artifacts, nil
+}
+
+// entriesAt returns the non-hidden entries depth levels below dir.
+func entriesAt(dir string, depth int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if depth == 1 {
+			paths = append(paths, path)
+			continue
+		}
+		if !e.IsDir() {
+			continue
+		}
+		sub, err := entriesAt(path, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, sub...)
+	}
+	return paths, nil
+}
+
+// Plan returns the artifacts (oldest first, as from Scan) that rule says
+// should be removed at now: those past MaxAge, then the oldest until the
+// total fits QuotaBytes. Artifacts within KeepFor are never selected.
+func Plan(artifacts []Artifact, rule Rule, now time.Time) []Artifact {
+	var total int64
+	for _, a := range artifacts {
+		total += a.Bytes
+	}
+
+	var remove []Artifact
+	for _, a := range artifacts {
+		age := now.Sub(a.ModTime)
+		if rule.KeepFor > 0 && age < rule.KeepFor {
+			continue
+		}
+		expired := rule.MaxAge > 0 && age > rule.MaxAge
+		overQuota := rule.QuotaBytes > 0 && total > rule.QuotaBytes
+		if !expired && !overQuota {
+			continue
+		}
+		remove = append(remove, a)
+		total -= a.Bytes
+	}
+	return remove
+}
+
+// CategoryStatus summarizes a category's disk use against its rule.
+type CategoryStatus struct {
+	Name           string     `json:"name"`
+	Dir            string     `json:"dir"`
+	Artifacts      int        `json:"artifacts"`
+	Bytes          int64      `json:"bytes"`
+	QuotaBytes     int64      `json:"quota_bytes,omitempty"`
+	MaxAgeDays     int        `json:"max_age_days,omitempty"`
+	KeepDays       int        `json:"keep_days,omitempty"`
+	Oldest         *time.Time `json:"oldest,omitempty"`
+	Newest         *time.Time `json:"newest,omitempty"`
+	Prunable       int        `json:"prunable"`
+	PrunableBytes  int64      `json:"prunable_bytes"`
+	OverQuota      bool       `json:"over_quota"`
+	Error          string     `json:"error,omitempty"`
+	RemovedBytes   int64      `json:"removed_bytes,omitempty"`
+	RemovedEntries []string   `json:"removed,omitempty"`
+}
+
+// Status reports a category's usage and what Prune would remove.
+func Status(c Category, now time.Time) CategoryStatus {
+	st := CategoryStatus{
+		Name:       c.Name,
+		Dir:        c.Dir,
+		QuotaBytes: c.Rule.QuotaBytes,
+		MaxAgeDays: int(c.Rule.MaxAge / (24 * time.Hour)),
+		KeepDays:   int(c.Rule.KeepFor / (24 * time.Hour)),
+	}
+	artifacts, err := Scan(c)
+	if err != nil {
+		st.Error = err.Error()
+		return st
+	}
+	st.Artifacts = len(artifacts)
+	for _, a := range artifacts {
+		st.Bytes += a.Bytes
+	}
+	if len(artifacts) > 0 {
+		oldest, newest := artifacts[0].ModTime, artifacts[len(artifacts)-1].ModTime
+		st.Oldest, st.Newest = &oldest, &newest
+	}
+	st.OverQuota = c.Rule.QuotaBytes > 0 && st.Bytes > c.Rule.QuotaBytes
+	for _, a := range Plan(artifacts, c.Rule, now) {
+		st.Prunable++
+		st.PrunableBytes += a.Bytes
+	}
+	return st
+}
+
+// Prune removes the artifacts Plan selects and returns the category's status
+// afterwards, with the removed paths. With dryRun nothing is deleted.
+func Prune(c Category, now time.Time, dryRun bool) CategoryStatus {
+	artifacts, err := Scan(c)
+	if err != nil {
+		return Status(c, now)
+	}
+
+	var removed []string
+	var removedBytes int64
+	var failures []string
+	for _, a := range Plan(artifacts, c.Rule, now) {
+		if !dryRun {
+			if err := os.RemoveAll(a.Path); err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			removeEmptyParents(filepath.Dir(a.Path), c.Dir)
+		}
+		removed = append(removed, a.Path)
+		removedBytes += a.Bytes
+	}
+
+	st := Status(c, now)
+	st.RemovedEntries = removed
+	st.RemovedBytes = removedBytes
+	if len(failures) > 0 {
+		st.Error = fmt.Sprintf("%d removal(s) failed: %s", len(failures), failures[0])
+	}
+	return st
+}
+
+// removeEmptyParents removes dir and its parents up to (not including) root
+// while they are empty, so pruning the last capture of a session does not
+// leave its directory behind.
+func removeEmptyParents(dir, root string) {
+	root = filepath.Clean(root)
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return // Not empty
+		}
+	}
+}
+
+// Disk is the free space of the filesystem holding a path.
+type Disk struct {
+	Path       string `json:"path"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	TotalBytes uint64 `json:"total_bytes"`
+	Low        bool   `json:"low"`
+}
+
+// CheckDisk reports free space on the filesystem holding path, walking up to
+// the nearest existing directory. Low is set when free space is below
+// minFree (0 disables the check).
+func CheckDisk(path string, minFree uint64) (Disk, error) {
+	probe := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(probe); err == nil {
+			break
+		}
+		parent := filepath.Dir(probe)
+		if parent == probe {
+			break
+		}
+		probe = parent
+	}
+	usage, err := disk.Usage(probe)
+	if err != nil {
+		return Disk{Path: path}, err
+	}
+	return Disk{
+		Path:       path,
+		FreeBytes:  usage.Free,
+		TotalBytes: usage.Total,
+		Low:        minFree > 0 && usage.Free < minFree,
+	}, nil
+}
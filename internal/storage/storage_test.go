@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeArtifact creates a file of size bytes last modified age ago.
+func writeArtifact(t *testing.T, path string, size int, age time.Duration) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-age)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func names(paths []string) string {
+	var out []string
+	for _, p := range paths {
+		out = append(out, filepath.Base(p))
+	}
+	return strings.Join(out, ",")
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	day := 24 * time.Hour
+	artifacts := []Artifact{
+		{Path: "a", Bytes: 100, ModTime: now.Add(-40 * day)},
+		{Path: "b", Bytes: 100, ModTime: now.Add(-20 * day)},
+		{Path: "c", Bytes: 100, ModTime: now.Add(-10 * day)},
+		{Path: "d", Bytes: 100, ModTime: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"no limits", Rule{}, ""},
+		{"max age", Rule{MaxAge: 30 * day}, "a"},
+		{"quota removes oldest first", Rule{QuotaBytes: 250}, "a,b"},
+		{"max age then quota", Rule{QuotaBytes: 150, MaxAge: 30 * day}, "a,b,c"},
+		{"keep protects recent", Rule{QuotaBytes: 50, KeepFor: 15 * day}, "a,b"},
+		{"under quota", Rule{QuotaBytes: 1000}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, a := range Plan(artifacts, tt.rule, now) {
+				got = append(got, a.Path)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("Plan = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneNestedCategory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	day := 24 * time.Hour
+	writeArtifact(t, filepath.Join(dir, "old-session", "cp1", "panes", "1.txt"), 400, 10*day)
+	writeArtifact(t, filepath.Join(dir, "proj", "cp2", "metadata.json"), 300, 5*day)
+	writeArtifact(t, filepath.Join(dir, "proj", "cp3", "metadata.json"), 300, time.Hour)
+	writeArtifact(t, filepath.Join(dir, ".lock"), 10, 30*day)
+
+	c := Category{Name: "captures", Dir: dir, Depth: 2, Rule: Rule{QuotaBytes: 700, KeepFor: day}}
+	now := time.Now()
+
+	st := Status(c, now)
+	if st.Artifacts != 3 || st.Bytes != 1000 || !st.OverQuota {
+		t.Fatalf("Status = %+v, want 3 artifacts, 1000 bytes, over quota", st)
+	}
+	if st.Prunable != 1 || st.PrunableBytes != 400 {
+		t.Errorf("Prunable = %d (%d bytes), want the oldest capture", st.Prunable, st.PrunableBytes)
+	}
+
+	dry := Prune(c, now, true)
+	if names(dry.RemovedEntries) != "cp1" {
+		t.Errorf("dry run would remove %v", dry.RemovedEntries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old-session", "cp1")); err != nil {
+		t.Fatal("dry run deleted the artifact")
+	}
+
+	st = Prune(c, now, false)
+	if st.Error != "" {
+		t.Fatal(st.Error)
+	}
+	if names(st.RemovedEntries) != "cp1" || st.RemovedBytes != 400 {
+		t.Errorf("removed %v (%d bytes)", st.RemovedEntries, st.RemovedBytes)
+	}
+	if st.Bytes != 600 || st.OverQuota {
+		t.Errorf("after prune: %d bytes, over quota %v", st.Bytes, st.OverQuota)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old-session")); !os.IsNotExist(err) {
+		t.Error("emptied session directory left behind")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".lock")); err != nil {
+		t.Error("hidden files must not be pruned")
+	}
+}
+
+func TestScanMissingDir(t *testing.T) {
+	t.Parallel()
+
+	artifacts, err := Scan(Category{Dir: filepath.Join(t.TempDir(), "missing"), Depth: 1})
+	if err != nil || len(artifacts) != 0 {
+		t.Errorf("Scan(missing) = %v, %v", artifacts, err)
+	}
+}
+
+func TestCheckDisk(t *testing.T) {
+	t.Parallel()
+
+	// A path that does not exist yet is measured on its nearest parent.
+	path := filepath.Join(t.TempDir(), "not", "yet", "created")
+	d, err := CheckDisk(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Path != path || d.TotalBytes == 0 || d.Low {
+		t.Errorf("CheckDisk = %+v", d)
+	}
+	if d, _ := CheckDisk(path, d.FreeBytes+1<<40); !d.Low {
+		t.Error("Low = false with min free above free space")
+	}
+}
@@ -677,15 +677,30 @@ func mergeFileChanges(existing, incoming []FileChange) []FileChange {
 
 // JSON extraction helpers
 
+// Budgets for JSON extraction from untrusted agent output, shared with
+// robot's ExtractJSONOutputs.
+const (
+	// JSONMaxBlockLines bounds how many lines one block may span.
+	JSONMaxBlockLines = 100
+	// JSONMaxBlockBytes bounds the size of one block.
+	JSONMaxBlockBytes = 1 << 20
+	// JSONMaxDepth rejects pathologically nested blocks.
+	JSONMaxDepth = 256
+	// JSONMaxCandidates bounds how many blocks one call tries to parse.
+	JSONMaxCandidates = 512
+)
+
 func extractJSONBlocks(text string) []string {
 	lines := strings.Split(text, "\n")
 	var blocks []string
-	for i := 0; i < len(lines); i++ {
+	candidates := 0
+	for i := 0; i < len(lines) && candidates < JSONMaxCandidates; i++ {
 		trimmed := strings.TrimSpace(lines[i])
-		if trimmed == "" {
+		if trimmed == "" || len(trimmed) > JSONMaxBlockBytes {
 			continue
 		}
 		if trimmed[0] == '{' || trimmed[0] == '[' {
+			candidates++
 			block, end := extractCompleteJSON(lines, i)
 			if block != "" {
 				blocks = append(blocks, block)
@@ -714,10 +729,13 @@ func extractCompleteJSON(lines []string, startIdx int) (string, int) {
 	inString := false
 	escaped := false
 
-	for i := startIdx; i < len(lines) && i < startIdx+100; i++ {
+	for i := startIdx; i < len(lines) && i < startIdx+JSONMaxBlockLines; i++ {
 		if builder.Len() > 0 {
 			builder.WriteByte('\n')
 		}
+		if builder.Len()+len(lines[i]) > JSONMaxBlockBytes {
+			return "", 0
+		}
 		builder.WriteString(lines[i])
 
 		for _, ch := range lines[i] {
@@ -739,6 +757,9 @@ func extractCompleteJSON(lines []string, startIdx int) (string, int) {
 			switch ch {
 			case '{', '[':
 				depth++
+				if depth > JSONMaxDepth {
+					return "", 0
+				}
 			case '}', ']':
 				depth--
 			}
@@ -759,10 +780,7 @@ func extractCompleteJSON(lines []string, startIdx int) (string, int) {
 }
 
 func isValidJSON(s string) bool {
-	var js interface{}
-	dec := json.NewDecoder(strings.NewReader(strings.TrimSpace(s)))
-	dec.UseNumber()
-	return dec.Decode(&js) == nil
+	return json.Valid([]byte(strings.TrimSpace(s)))
 }
 
 // Formatting
@@ -2,6 +2,7 @@ package summary
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -824,6 +825,16 @@ func TestExtractJSONBlocks(t *testing.T) {
 			text:     `{"unclosed": `,
 			expected: 0,
 		},
+		{
+			name:     "nesting over budget",
+			text:     strings.Repeat("[", JSONMaxDepth+1) + strings.Repeat("]", JSONMaxDepth+1),
+			expected: 0,
+		},
+		{
+			name:     "oversized line",
+			text:     `["` + strings.Repeat("a", JSONMaxBlockBytes) + `"]`,
+			expected: 0,
+		},
 	}
 
 	for _, tc := range tests {
@@ -837,6 +848,27 @@ func TestExtractJSONBlocks(t *testing.T) {
 	}
 }
 
+// FuzzExtractJSONBlocks ensures extractJSONBlocks never panics and only
+// returns valid JSON.
+func FuzzExtractJSONBlocks(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		`{"summary": {"accomplishments": ["done"]}}`,
+		"text\n[\n  1,\n  2\n]\nmore",
+		`{"s": "\" ] }"}`,
+		strings.Repeat("{", 500),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		for _, block := range extractJSONBlocks(text) {
+			if !json.Valid([]byte(block)) {
+				t.Fatalf("invalid JSON block: %q", block)
+			}
+		}
+	})
+}
+
 // =============================================================================
 // Formatting Tests
 // =============================================================================
@@ -0,0 +1,221 @@
+package summary
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Dicklesworthstone/ntm/internal/agent"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Pane digest methods.
+const (
+	MethodExtractive = "extractive"
+	MethodSummarizer = "summarizer"
+)
+
+// maxDigestLineLen bounds a single selected line so one huge line cannot
+// take the whole budget.
+const maxDigestLineLen = 240
+
+// PaneDigest is a summary of one pane's captured output.
+type PaneDigest struct {
+	Text         string `json:"text"`
+	Method       string `json:"method"`        // extractive or summarizer
+	Tokens       int    `json:"tokens"`        // Estimated tokens in Text
+	SourceLines  int    `json:"source_lines"`  // Lines left after noise stripping
+	SourceTokens int    `json:"source_tokens"` // Estimated tokens in those lines
+	NoiseLines   int    `json:"noise_lines"`   // Lines dropped as noise or repeats
+	Selected     int    `json:"selected"`      // Source lines kept by extraction
+}
+
+var (
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07]*\x07`)
+
+	// statusLinePattern matches agent CLI status lines that are repainted
+	// while an agent works.
+	statusLinePattern = regexp.MustCompile(`(?i)esc to (interrupt|cancel)|ctrl\+c to (interrupt|cancel|quit)|\? for shortcuts|context left until auto-compact|^[✻✢✳✶✽∗*·]\s+\w+…`)
+
+	// progressBarPattern matches drawn progress bars.
+	progressBarPattern = regexp.MustCompile(`[█▉▊▋▌▍▎▏▓▒░■□━]{4,}|\[[=#>\-. ]{5,}\]`)
+
+	// decorationPattern matches lines made only of borders and prompt glyphs.
+	decorationPattern = regexp.MustCompile(`^[\s─━│┃╭╮╰╯┌┐└┘├┤┬┴┼═║╔╗╚╝>›❯$%\-=_~.]*$`)
+
+	digestErrorPattern  = regexp.MustCompile(`(?i)\b(error|errors|failed|failure|fail|panic|exception|fatal|traceback)\b`)
+	digestTestPattern   = regexp.MustCompile(`^(ok|FAIL|PASS|---)\s|(?i)\b\d+ (tests?|passed|failed|passing|failing)\b`)
+	digestDonePattern   = regexp.MustCompile(`(?i)\b(done|implemented|fixed|added|created|completed|resolved|committed|blocked|next)\b`)
+	digestPathPattern   = regexp.MustCompile(`[\w.\-]+/[\w.\-/]+\.\w+|\b[\w\-]+\.(go|py|ts|tsx|js|rs|md|json|yaml|yml|toml)\b`)
+	digestBulletPattern = regexp.MustCompile(`^[⏺●•]\s`)
+)
+
+// StripNoise removes terminal noise from captured output: ANSI codes,
+// spinners and status lines, progress bars, borders, blank lines and lines
+// already seen. It returns the remaining lines in order and how many were
+// dropped.
+func StripNoise(text string) ([]string, int) {
+	var kept []string
+	noise := 0
+	seen := make(map[string]bool)
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimRight(ansiPattern.ReplaceAllString(raw, ""), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		// Keep the content of boxed lines ("│ text │").
+		if strings.HasPrefix(trimmed, "│") && strings.HasSuffix(trimmed, "│") && len(trimmed) > len("││") {
+			trimmed = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(trimmed, "│"), "│"))
+			line = trimmed
+		}
+		first, _ := utf8.DecodeRuneInString(trimmed)
+		switch {
+		case first >= 0x2800 && first <= 0x28FF, // Braille spinner frames
+			statusLinePattern.MatchString(trimmed),
+			progressBarPattern.MatchString(trimmed),
+			decorationPattern.MatchString(trimmed),
+			seen[trimmed]:
+			noise++
+			continue
+		}
+		seen[trimmed] = true
+		kept = append(kept, line)
+	}
+	return kept, noise
+}
+
+// DigestPaneOutput builds an extractive summary of captured pane output:
+// after stripping noise, the most informative lines (self-reports, errors,
+// test results, tool calls, agent messages, recent lines) are kept in their
+// original order until the token budget is used.
+func DigestPaneOutput(text string, budget int) PaneDigest {
+	lines, noise := StripNoise(text)
+	digest := PaneDigest{
+		Method:      MethodExtractive,
+		SourceLines: len(lines),
+		NoiseLines:  noise,
+	}
+	for _, line := range lines {
+		digest.SourceTokens += estimateTokens(line) + 1
+	}
+	if len(lines) == 0 || budget <= 0 {
+		return digest
+	}
+
+	type candidate struct {
+		index int
+		line  string
+		score float64
+	}
+	candidates := make([]candidate, len(lines))
+	for i, line := range lines {
+		// Later lines say more about where the pane is now.
+		recency := 3 * float64(i+1) / float64(len(lines))
+		candidates[i] = candidate{index: i, line: clipLine(strings.TrimSpace(line)), score: scoreLine(line) + recency}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	used := 0
+	var picked []candidate
+	for _, c := range candidates {
+		cost := estimateTokens(c.line) + 1
+		if used+cost > budget {
+			continue
+		}
+		used += cost
+		picked = append(picked, c)
+	}
+	sort.Slice(picked, func(i, j int) bool { return picked[i].index < picked[j].index })
+
+	out := make([]string, len(picked))
+	for i, c := range picked {
+		out[i] = c.line
+	}
+	digest.Text = strings.Join(out, "\n")
+	digest.Tokens = estimateTokens(digest.Text)
+	digest.Selected = len(picked)
+	return digest
+}
+
+// SummarizePaneOutput summarizes captured pane output within budget tokens.
+// With a Summarizer, a larger extractive digest is handed to it to rewrite;
+// if it fails, the extractive digest is returned along with the error.
+func SummarizePaneOutput(ctx context.Context, text string, budget int, s Summarizer) (PaneDigest, error) {
+	if s == nil {
+		return DigestPaneOutput(text, budget), nil
+	}
+	source := DigestPaneOutput(text, budget*4)
+	if source.Text == "" {
+		return DigestPaneOutput(text, budget), nil
+	}
+	prompt := fmt.Sprintf(`Summarize this AI coding agent's terminal output for an orchestrator that needs to catch up.
+Cover the current task, what is done, errors or blockers, and what the agent is doing now.
+Be factual and terse; use at most %d tokens.
+
+%s`, budget, source.Text)
+	summarized, err := s.Summarize(ctx, prompt, budget)
+	if err == nil && strings.TrimSpace(summarized) == "" {
+		err = fmt.Errorf("summarizer returned no text")
+	}
+	if err != nil {
+		return DigestPaneOutput(text, budget), err
+	}
+
+	digest := source
+	digest.Method = MethodSummarizer
+	digest.Text = strings.TrimSpace(summarized)
+	if estimateTokens(digest.Text) > budget {
+		digest.Text = util.SafeSlice(digest.Text, budget*4)
+	}
+	digest.Tokens = estimateTokens(digest.Text)
+	return digest, nil
+}
+
+// scoreLine rates how much a line tells a reader catching up on a pane.
+func scoreLine(line string) float64 {
+	trimmed := strings.TrimSpace(line)
+	score := 1.0
+	switch {
+	case strings.Contains(trimmed, agent.ReportMarker):
+		score = 10
+	case digestErrorPattern.MatchString(trimmed):
+		score = 8
+	case digestTestPattern.MatchString(trimmed):
+		score = 6
+	default:
+		if calls := agent.ParseToolCalls(agent.AgentTypeUnknown, trimmed); len(calls) > 0 {
+			score = 4
+			if calls[0].Modifies() {
+				score = 6
+			}
+		} else if digestBulletPattern.MatchString(trimmed) {
+			score = 5 // An agent message
+		}
+	}
+	if digestDonePattern.MatchString(trimmed) {
+		score += 2
+	}
+	if digestPathPattern.MatchString(trimmed) {
+		score++
+	}
+	return score
+}
+
+// clipLine shortens long lines, leaving self-reports intact so they still
+// parse.
+func clipLine(line string) string {
+	if len(line) <= maxDigestLineLen || strings.Contains(line, agent.ReportMarker) {
+		return line
+	}
+	return util.SafeSlice(line, maxDigestLineLen-len("…")) + "…"
+}
+
+// estimateTokens uses the same ~4 characters per token as the session
+// summaries.
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
@@ -0,0 +1,102 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const paneCapture = "\x1b[32m⏺ I'll fix the failing parser test.\x1b[0m\n" +
+	"⠋ Thinking…\n" +
+	"⠙ Thinking…\n" +
+	"✻ Pondering… (esc to interrupt)\n" +
+	"⏺ Read(internal/agent/parser.go)\n" +
+	"  ⎿  Read 120 lines (ctrl+r to expand)\n" +
+	"Downloading modules [=====>      ] 45%\n" +
+	"████████░░░░ 67%\n" +
+	"╭──────────────────────────────╮\n" +
+	"│ > fix it                     │\n" +
+	"╰──────────────────────────────╯\n" +
+	"\n" +
+	"⏺ Update(internal/agent/parser.go)\n" +
+	"--- FAIL: TestParse (0.00s)\n" +
+	"--- FAIL: TestParse (0.00s)\n" +
+	"ok  \tgithub.com/x/ntm/internal/agent\t0.3s\n" +
+	"⏺ Done: the parser handles empty input now.\n" +
+	"  ? for shortcuts\n"
+
+func TestStripNoise(t *testing.T) {
+	lines, noise := StripNoise(paneCapture)
+	got := strings.Join(lines, "\n")
+	for _, gone := range []string{"Thinking", "Pondering", "45%", "67%", "╭", "for shortcuts", "\x1b"} {
+		if strings.Contains(got, gone) {
+			t.Errorf("noise %q kept:\n%s", gone, got)
+		}
+	}
+	if !strings.Contains(got, "> fix it") {
+		t.Errorf("boxed content dropped:\n%s", got)
+	}
+	if strings.Count(got, "--- FAIL") != 1 {
+		t.Errorf("repeated line kept:\n%s", got)
+	}
+	if len(lines) != 8 || noise != 9 {
+		t.Errorf("got %d lines, %d noise; want 8, 9:\n%s", len(lines), noise, got)
+	}
+}
+
+func TestDigestPaneOutputBudget(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 200; i++ {
+		fmt.Fprintf(&b, "compiling package number %d of the build\n", i)
+	}
+	b.WriteString("error: undefined: parseConfig in cmd/main.go\n")
+	for i := 200; i < 400; i++ {
+		fmt.Fprintf(&b, "compiling package number %d of the build\n", i)
+	}
+	b.WriteString(`NTM-REPORT{"task_id":"bd-7","status":"blocked","blockers":["needs the config schema from bd-6 before the loader can be finished"]}` + "\n")
+
+	digest := DigestPaneOutput(b.String(), 60)
+	if digest.Method != MethodExtractive || digest.Tokens > 60 {
+		t.Fatalf("digest = %+v, want extractive within 60 tokens", digest)
+	}
+	if digest.SourceLines != 402 || digest.SourceTokens <= 60 {
+		t.Errorf("source = %d lines / %d tokens", digest.SourceLines, digest.SourceTokens)
+	}
+	lines := strings.Split(digest.Text, "\n")
+	if !strings.HasPrefix(lines[0], "error: undefined") || !strings.HasPrefix(lines[len(lines)-1], "NTM-REPORT{") {
+		t.Errorf("digest should keep the error and report in order:\n%s", digest.Text)
+	}
+	if !strings.Contains(digest.Text, "number 399") {
+		t.Errorf("digest should prefer recent lines:\n%s", digest.Text)
+	}
+}
+
+type fakeSummarizer struct {
+	text   string
+	err    error
+	prompt string
+}
+
+func (f *fakeSummarizer) Summarize(_ context.Context, prompt string, _ int) (string, error) {
+	f.prompt = prompt
+	return f.text, f.err
+}
+
+func TestSummarizePaneOutput(t *testing.T) {
+	s := &fakeSummarizer{text: "Fixing the parser test; it passes now."}
+	digest, err := SummarizePaneOutput(context.Background(), paneCapture, 100, s)
+	if err != nil || digest.Method != MethodSummarizer || digest.Text != s.text {
+		t.Fatalf("digest = %+v, %v", digest, err)
+	}
+	if !strings.Contains(s.prompt, "--- FAIL: TestParse") || strings.Contains(s.prompt, "Thinking") {
+		t.Errorf("prompt should carry the stripped digest:\n%s", s.prompt)
+	}
+
+	failing := &fakeSummarizer{err: errors.New("connection refused")}
+	digest, err = SummarizePaneOutput(context.Background(), paneCapture, 100, failing)
+	if err == nil || digest.Method != MethodExtractive || digest.Text == "" {
+		t.Errorf("failed summarizer: digest = %+v, err = %v; want extractive fallback and error", digest, err)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // DaemonState represents the current state of a managed daemon.
@@ -580,7 +582,7 @@ func (s *Supervisor) writePIDFile(d *ManagedDaemon) error {
 		return err
 	}
 
-	return os.WriteFile(path, data, 0644)
+	return util.AtomicWriteFile(path, data, 0644)
 }
 
 // removePIDFile removes the PID file for a daemon.
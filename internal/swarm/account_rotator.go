@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/Dicklesworthstone/ntm/internal/tools"
+	"github.com/Dicklesworthstone/ntm/internal/util"
 )
 
 // agentToProvider maps agent type aliases to caam provider names.
@@ -91,7 +92,10 @@ type AccountRotationHistory struct {
 	mu      sync.RWMutex
 	dataDir string
 	history map[string][]RotationRecord // sessionPane -> records
-	logger  *slog.Logger
+	// saved counts the records per pane as last loaded or saved; later
+	// records are the ones SaveToDir adds to the file.
+	saved  map[string]int
+	logger *slog.Logger
 }
 
 func NewAccountRotationHistory(dataDir string, logger *slog.Logger) *AccountRotationHistory {
@@ -226,30 +230,21 @@ func (h *AccountRotationHistory) LoadFromDir(dir string) error {
 		return nil
 	}
 
-	path := filepath.Join(dir, ".ntm", "rotation_history.json")
-	data, err := os.ReadFile(path)
+	pd, err := readRotationHistory(filepath.Join(dir, ".ntm", "rotation_history.json"))
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("read rotation history: %w", err)
-	}
-
-	var pd persistedRotationHistory
-	if err := json.Unmarshal(data, &pd); err != nil {
-		return fmt.Errorf("parse rotation history: %w", err)
+		return err
 	}
 
 	h.mu.Lock()
-	if pd.History != nil {
-		h.history = pd.History
-	} else {
-		h.history = make(map[string][]RotationRecord)
-	}
+	h.adoptLocked(pd.History)
 	h.mu.Unlock()
 	return nil
 }
 
+// SaveToDir saves the history to the .ntm directory. Other ntm processes
+// record rotations to the same file, so under the file lock it re-reads the
+// file, appends the rotations recorded here since the last load or save,
+// writes the result and adopts it.
 func (h *AccountRotationHistory) SaveToDir(dir string) error {
 	if dir == "" {
 		h.mu.RLock()
@@ -260,30 +255,66 @@ func (h *AccountRotationHistory) SaveToDir(dir string) error {
 		return nil
 	}
 
-	h.mu.RLock()
-	pd := persistedRotationHistory{
-		History: make(map[string][]RotationRecord, len(h.history)),
-	}
-	for pane, records := range h.history {
-		pd.History[pane] = append([]RotationRecord(nil), records...)
-	}
-	h.mu.RUnlock()
-
 	ntmDir := filepath.Join(dir, ".ntm")
 	if err := os.MkdirAll(ntmDir, 0o755); err != nil {
 		return fmt.Errorf("create .ntm dir: %w", err)
 	}
 
-	data, err := json.MarshalIndent(pd, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshal rotation history: %w", err)
+	path := filepath.Join(ntmDir, "rotation_history.json")
+	return util.WithFileLock(path, func() error {
+		pd, err := readRotationHistory(path)
+		if err != nil {
+			return err
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		for pane, records := range h.history {
+			if n := h.saved[pane]; n < len(records) {
+				pd.History[pane] = append(pd.History[pane], records[n:]...)
+			}
+		}
+		data, err := json.MarshalIndent(pd, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal rotation history: %w", err)
+		}
+		if err := util.AtomicWriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("write rotation history: %w", err)
+		}
+		h.adoptLocked(pd.History)
+		return nil
+	})
+}
+
+// readRotationHistory reads a rotation history file. A missing file gives
+// an empty history.
+func readRotationHistory(path string) (persistedRotationHistory, error) {
+	var pd persistedRotationHistory
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &pd); err != nil {
+			return pd, fmt.Errorf("parse rotation history: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return pd, fmt.Errorf("read rotation history: %w", err)
+	}
+	if pd.History == nil {
+		pd.History = make(map[string][]RotationRecord)
 	}
+	return pd, nil
+}
 
-	path := filepath.Join(ntmDir, "rotation_history.json")
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return fmt.Errorf("write rotation history: %w", err)
+// adoptLocked makes history, as just read or written, the tracked history.
+// The caller must hold h.mu.
+func (h *AccountRotationHistory) adoptLocked(history map[string][]RotationRecord) {
+	h.history = make(map[string][]RotationRecord, len(history))
+	h.saved = make(map[string]int, len(history))
+	for pane, records := range history {
+		h.history[pane] = append([]RotationRecord(nil), records...)
+		h.saved[pane] = len(records)
 	}
-	return nil
 }
 
 // AccountRotator manages account rotation via caam CLI.
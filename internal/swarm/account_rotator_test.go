@@ -820,6 +820,43 @@ func TestAccountRotationHistory_SaveLoad_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestAccountRotationHistory_SaveMergesConcurrentHistories(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	pane := "sess:1.1"
+
+	// Two processes record rotations to the same file.
+	a := NewAccountRotationHistory(dir, logger)
+	b := NewAccountRotationHistory(dir, logger)
+	for i, h := range []*AccountRotationHistory{a, b, a} {
+		if err := h.RecordRotation(RotationRecord{
+			Provider:    "openai",
+			ToAccount:   fmt.Sprintf("acct-%d", i),
+			RotatedAt:   base.Add(time.Duration(i) * time.Minute),
+			SessionPane: pane,
+		}); err != nil {
+			t.Fatalf("RecordRotation: %v", err)
+		}
+	}
+
+	loaded := NewAccountRotationHistory(dir, logger)
+	if err := loaded.LoadFromDir(dir); err != nil {
+		t.Fatalf("LoadFromDir: %v", err)
+	}
+	records := loaded.RecordsForPane(pane, 0)
+	if len(records) != 3 {
+		t.Fatalf("records = %+v, want all 3 rotations", records)
+	}
+	seen := map[string]bool{}
+	for _, r := range records {
+		seen[r.ToAccount] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("records = %+v, want each rotation once", records)
+	}
+}
+
 func TestAccountRotationHistory_GetRotationStats(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))
 	h := NewAccountRotationHistory("", logger)
@@ -0,0 +1,209 @@
+// Package takeover hands agent panes to a human and back. While a pane is
+// taken over ntm sends it no automated prompts, and the commands the human
+// runs there are journaled so their work is told apart from the agent's.
+package takeover
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/tmux"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// ErrNotTakenOver is returned when handing back a pane no human holds.
+var ErrNotTakenOver = errors.New("pane is not taken over")
+
+// Overridable for tests.
+var (
+	getPaneOption   = tmux.GetPaneOption
+	setPaneOption   = tmux.SetPaneOption
+	unsetPaneOption = tmux.UnsetPaneOption
+	paneOptions     = tmux.PaneOptions
+)
+
+// State records who holds a pane and since when. It is kept in the pane's
+// tmux.PaneTakeoverOption, so it goes away with the pane.
+type State struct {
+	By    string    `json:"by"`
+	Since time.Time `json:"since"`
+}
+
+func (s State) encode() string {
+	return s.Since.UTC().Format(time.RFC3339) + "|" + s.By
+}
+
+func parseState(value string) *State {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	since, by, _ := strings.Cut(value, "|")
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		// Set by hand; the pane is taken over all the same.
+		return &State{By: value}
+	}
+	return &State{By: by, Since: t}
+}
+
+// Get returns the takeover state of a pane, or nil if an agent holds it.
+func Get(paneID string) (*State, error) {
+	value, err := getPaneOption(paneID, tmux.PaneTakeoverOption)
+	if err != nil {
+		return nil, err
+	}
+	return parseState(value), nil
+}
+
+// Start marks a pane human-controlled.
+func Start(paneID, by string) (*State, error) {
+	current, err := Get(paneID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, fmt.Errorf("pane already taken over by %s", current.By)
+	}
+	s := &State{By: by, Since: time.Now().UTC().Truncate(time.Second)}
+	if err := setPaneOption(paneID, tmux.PaneTakeoverOption, s.encode()); err != nil {
+		return nil, fmt.Errorf("mark pane taken over: %w", err)
+	}
+	return s, nil
+}
+
+// End hands a pane back to its agent and returns the takeover it ends.
+func End(paneID string) (*State, error) {
+	s, err := Get(paneID)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, ErrNotTakenOver
+	}
+	if err := unsetPaneOption(paneID, tmux.PaneTakeoverOption); err != nil {
+		return nil, fmt.Errorf("hand pane back: %w", err)
+	}
+	return s, nil
+}
+
+// Panes returns the taken-over panes of a session, keyed by pane ID. If
+// tmux cannot be asked, no pane is reported as taken over.
+func Panes(session string) map[string]*State {
+	held := make(map[string]*State)
+	values, err := paneOptions(session, tmux.PaneTakeoverOption)
+	if err != nil {
+		return held
+	}
+	for id, value := range values {
+		if s := parseState(value); s != nil {
+			held[id] = s
+		}
+	}
+	return held
+}
+
+// SnapshotPath returns where a pane's scrollback is kept from takeover to
+// handback, under the session's directory in dir.
+func SnapshotPath(dir, session string, paneIndex int) string {
+	return filepath.Join(dir, session, "takeover", fmt.Sprintf("pane_%d.txt", paneIndex))
+}
+
+// SaveSnapshot stores a pane's scrollback at takeover.
+func SaveSnapshot(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create takeover dir: %w", err)
+	}
+	return util.AtomicWriteFile(path, []byte(content), 0600)
+}
+
+// LoadSnapshot reads the scrollback stored at takeover and removes it. A
+// missing snapshot reads as empty.
+func LoadSnapshot(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read takeover snapshot: %w", err)
+	}
+	_ = os.Remove(path)
+	return string(data), nil
+}
+
+// promptPattern matches a shell or agent prompt line and captures the
+// command typed at it: "$ make", "user@host:~/src$ make", "(venv) % make",
+// "❯ make", or "> fix the test" in an agent's input box. A bare "#" is
+// left out since it starts Markdown headings; root prompts need user@host.
+var promptPattern = regexp.MustCompile(`^(?:\([^)]*\)\s*)?(?:[\w.-]+@[\w.-]+[:\s][^$#%>❯]*[$#%>❯›]|[$%>❯›])\s+(\S.*)$`)
+
+// Commands returns the commands typed in a pane between two captures of
+// its scrollback: prompt lines in what after adds to before, in order, with
+// repeats from screen redraws collapsed.
+func Commands(before, after string) []string {
+	var commands []string
+	for _, line := range newLines(splitLines(before), splitLines(after)) {
+		line = strings.TrimSpace(strings.Trim(line, "│┃| "))
+		m := promptPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		cmd := strings.TrimSpace(m[1])
+		if len(commands) > 0 && commands[len(commands)-1] == cmd {
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+// splitLines splits a capture into lines without its trailing blank ones.
+func splitLines(s string) []string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// The earlier capture's tail is looked for in the later one with between
+// minAnchorLines and maxAnchorLines lines; fewer could match a bare prompt
+// anywhere.
+const (
+	minAnchorLines = 3
+	maxAnchorLines = 50
+)
+
+// newLines returns the lines of after that follow the end of before. The
+// tail of before is located in after, longest first. Its last line may be
+// the prompt the human then typed at, so it is tried without that line
+// too. If no part of it is found it has scrolled out and all of after is
+// new.
+func newLines(before, after []string) []string {
+	for drop := 0; drop <= 1 && drop < len(before); drop++ {
+		anchor := before[:len(before)-drop]
+		for n := min(len(anchor), maxAnchorLines); n >= min(len(anchor), minAnchorLines); n-- {
+			tail := anchor[len(anchor)-n:]
+			for i := 0; i+n <= len(after); i++ {
+				if equalLines(after[i:i+n], tail) {
+					return after[i+n:]
+				}
+			}
+		}
+	}
+	return after
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package takeover
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func stubPaneOptions(t *testing.T) map[string]string {
+	t.Helper()
+	options := map[string]string{}
+	oldGet, oldSet, oldUnset, oldList := getPaneOption, setPaneOption, unsetPaneOption, paneOptions
+	t.Cleanup(func() { getPaneOption, setPaneOption, unsetPaneOption, paneOptions = oldGet, oldSet, oldUnset, oldList })
+	getPaneOption = func(paneID, name string) (string, error) { return options[paneID], nil }
+	setPaneOption = func(paneID, name, value string) error { options[paneID] = value; return nil }
+	unsetPaneOption = func(paneID, name string) error { delete(options, paneID); return nil }
+	paneOptions = func(session, name string) (map[string]string, error) { return options, nil }
+	return options
+}
+
+func TestStartEnd(t *testing.T) {
+	stubPaneOptions(t)
+
+	s, err := Start("%2", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.By != "alice" || s.Since.IsZero() {
+		t.Errorf("state = %+v", s)
+	}
+	if _, err := Start("%2", "bob"); err == nil {
+		t.Error("second takeover of a pane should fail")
+	}
+	held := Panes("proj")
+	if len(held) != 1 || held["%2"] == nil || !held["%2"].Since.Equal(s.Since) {
+		t.Errorf("panes = %+v", held)
+	}
+
+	ended, err := End("%2")
+	if err != nil || ended.By != "alice" {
+		t.Fatalf("End = %+v, %v", ended, err)
+	}
+	if _, err := End("%2"); !errors.Is(err, ErrNotTakenOver) {
+		t.Errorf("End twice = %v, want ErrNotTakenOver", err)
+	}
+	if got, _ := Get("%2"); got != nil {
+		t.Errorf("Get after End = %+v", got)
+	}
+}
+
+func TestParseStateSetByHand(t *testing.T) {
+	if s := parseState("me"); s == nil || s.By != "me" {
+		t.Errorf("parseState = %+v", s)
+	}
+	if s := parseState("  "); s != nil {
+		t.Errorf("blank option should not be a takeover: %+v", s)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	before := "building...\nok  pkg/a\nuser@host:~/src$ "
+	after := "building...\nok  pkg/a\nuser@host:~/src$ go test ./...\n" +
+		"--- FAIL: TestX\n# not a prompt\n" +
+		"user@host:~/src$ vim x.go\n" +
+		"(venv) ❯ make lint\n" +
+		"│ > fix the race in TestX │\n" +
+		"│ > fix the race in TestX │\n" +
+		"100% done\n\n"
+	want := []string{"go test ./...", "vim x.go", "make lint", "fix the race in TestX"}
+	if got := Commands(before, after); !reflect.DeepEqual(got, want) {
+		t.Errorf("Commands = %q, want %q", got, want)
+	}
+}
+
+func TestCommandsScrolledOut(t *testing.T) {
+	got := Commands("gone\nlong\nago", "$ ls\n$ git status")
+	if want := []string{"ls", "git status"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Commands = %q, want %q", got, want)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	path := SnapshotPath(t.TempDir(), "proj", 2)
+	if filepath.Base(path) != "pane_2.txt" {
+		t.Errorf("path = %s", path)
+	}
+	if err := SaveSnapshot(path, "$ ls"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadSnapshot(path); err != nil || got != "$ ls" {
+		t.Fatalf("LoadSnapshot = %q, %v", got, err)
+	}
+	if got, err := LoadSnapshot(path); err != nil || got != "" {
+		t.Errorf("snapshot should be removed once read: %q, %v", got, err)
+	}
+}
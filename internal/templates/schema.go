@@ -244,6 +244,12 @@ type SessionOptionsSpec struct {
 
 	// Checkpoint enables automatic session checkpointing.
 	Checkpoint *CheckpointSpec `yaml:"checkpoint,omitempty"`
+
+	// Budget sets spend guardrails for sessions created from this template.
+	Budget *BudgetSpec `yaml:"budget,omitempty"`
+
+	// Guards refuse prompts to sessions created from this template.
+	Guards *GuardsSpec `yaml:"guards,omitempty"`
 }
 
 // StaggerSpec defines staggered spawn configuration.
@@ -264,6 +270,42 @@ type CheckpointSpec struct {
 	Interval string `yaml:"interval,omitempty"`
 }
 
+// BudgetSpec defines the default budget of sessions spawned from a
+// template. Dollar limits are in USD. Zero disables a limit.
+type BudgetSpec struct {
+	// SoftLimit raises a budget event when session spend crosses it.
+	SoftLimit float64 `yaml:"softLimit,omitempty"`
+
+	// HardLimit pauses prompt sends until an extension is approved.
+	HardLimit float64 `yaml:"hardLimit,omitempty"`
+
+	// Tokens refuses prompt sends once the session has used this many
+	// tokens (input + output).
+	Tokens int64 `yaml:"tokens,omitempty"`
+
+	// MaxAgents caps the agents a session may run, at spawn and on add.
+	MaxAgents int `yaml:"maxAgents,omitempty"`
+
+	// MaxRuntime refuses prompt sends once the session has run this long.
+	MaxRuntime string `yaml:"maxRuntime,omitempty"`
+}
+
+// GuardsSpec defines policy guards inherited by sessions spawned from a
+// template.
+type GuardsSpec struct {
+	// Blocked refuses prompts matching any of these rules.
+	Blocked []GuardRuleSpec `yaml:"blocked,omitempty"`
+}
+
+// GuardRuleSpec is a regular expression matched against prompts.
+type GuardRuleSpec struct {
+	// Pattern is the regular expression.
+	Pattern string `yaml:"pattern"`
+
+	// Reason is shown when a prompt is refused.
+	Reason string `yaml:"reason,omitempty"`
+}
+
 // Error definitions for template validation.
 var (
 	ErrMissingAPIVersion = errors.New("apiVersion is required")
@@ -499,6 +541,19 @@ func (t *SessionTemplate) MergeFrom(parent *SessionTemplate) {
 		checkpoint := *parent.Spec.Options.Checkpoint
 		t.Spec.Options.Checkpoint = &checkpoint
 	}
+	if parent.Spec.Options.Budget != nil && t.Spec.Options.Budget == nil {
+		budget := *parent.Spec.Options.Budget
+		t.Spec.Options.Budget = &budget
+	}
+	// Guards accumulate: a child template can add rules but not drop its
+	// parent's.
+	if parent.Spec.Options.Guards != nil {
+		guards := GuardsSpec{Blocked: append([]GuardRuleSpec{}, parent.Spec.Options.Guards.Blocked...)}
+		if t.Spec.Options.Guards != nil {
+			guards.Blocked = append(guards.Blocked, t.Spec.Options.Guards.Blocked...)
+		}
+		t.Spec.Options.Guards = &guards
+	}
 	if parent.Spec.Options.AutoRestart && !t.Spec.Options.AutoRestart {
 		t.Spec.Options.AutoRestart = parent.Spec.Options.AutoRestart
 	}
@@ -623,6 +678,31 @@ func (o *SessionOptionsSpec) Validate() error {
 		}
 	}
 
+	if o.Budget != nil {
+		if o.Budget.SoftLimit < 0 || o.Budget.HardLimit < 0 {
+			return fmt.Errorf("options.budget: limits must not be negative")
+		}
+		if o.Budget.SoftLimit > 0 && o.Budget.HardLimit > 0 && o.Budget.SoftLimit > o.Budget.HardLimit {
+			return fmt.Errorf("options.budget: softLimit must not exceed hardLimit")
+		}
+		if o.Budget.Tokens < 0 || o.Budget.MaxAgents < 0 {
+			return fmt.Errorf("options.budget: tokens and maxAgents must not be negative")
+		}
+		if o.Budget.MaxRuntime != "" {
+			if d, err := time.ParseDuration(o.Budget.MaxRuntime); err != nil || d <= 0 {
+				return fmt.Errorf("options.budget.maxRuntime: %w", ErrInvalidDuration)
+			}
+		}
+	}
+
+	if o.Guards != nil {
+		for i, rule := range o.Guards.Blocked {
+			if _, err := regexp.Compile(rule.Pattern); err != nil || rule.Pattern == "" {
+				return fmt.Errorf("options.guards.blocked[%d]: %w", i, ErrInvalidPattern)
+			}
+		}
+	}
+
 	return nil
 }
 
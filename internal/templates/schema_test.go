@@ -601,6 +601,65 @@ func TestSessionOptionsSpecValidate_InvalidDurations(t *testing.T) {
 	}
 }
 
+func TestSessionOptionsSpecValidate_Budget(t *testing.T) {
+	spec := SessionOptionsSpec{Budget: &BudgetSpec{SoftLimit: 5, HardLimit: 10}}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec.Budget = &BudgetSpec{SoftLimit: 20, HardLimit: 10}
+	if err := spec.Validate(); err == nil || !strings.Contains(err.Error(), "softLimit") {
+		t.Fatalf("expected softLimit error, got %v", err)
+	}
+
+	spec.Budget = &BudgetSpec{HardLimit: -1}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for negative limit")
+	}
+
+	spec.Budget = &BudgetSpec{Tokens: 1_000_000, MaxAgents: 4, MaxRuntime: "8h"}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec.Budget = &BudgetSpec{MaxRuntime: "overnight"}
+	if err := spec.Validate(); !errors.Is(err, ErrInvalidDuration) {
+		t.Fatalf("expected ErrInvalidDuration, got %v", err)
+	}
+}
+
+func TestSessionOptionsSpecValidate_Guards(t *testing.T) {
+	spec := SessionOptionsSpec{Guards: &GuardsSpec{Blocked: []GuardRuleSpec{{Pattern: `git\s+push`}}}}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec.Guards.Blocked = append(spec.Guards.Blocked, GuardRuleSpec{Pattern: "("})
+	if err := spec.Validate(); !errors.Is(err, ErrInvalidPattern) {
+		t.Fatalf("expected ErrInvalidPattern, got %v", err)
+	}
+}
+
+func TestMergeFrom_GuardsAccumulate(t *testing.T) {
+	parent := &SessionTemplate{}
+	parent.Spec.Options.Budget = &BudgetSpec{HardLimit: 50, MaxAgents: 6}
+	parent.Spec.Options.Guards = &GuardsSpec{Blocked: []GuardRuleSpec{{Pattern: "rm -rf"}}}
+
+	child := &SessionTemplate{}
+	child.Spec.Options.Guards = &GuardsSpec{Blocked: []GuardRuleSpec{{Pattern: "git push"}}}
+	child.MergeFrom(parent)
+
+	if child.Spec.Options.Budget == nil || child.Spec.Options.Budget.MaxAgents != 6 {
+		t.Errorf("budget not inherited: %+v", child.Spec.Options.Budget)
+	}
+	if got := len(child.Spec.Options.Guards.Blocked); got != 2 {
+		t.Errorf("got %d guard rules, want parent's and child's", got)
+	}
+	if len(parent.Spec.Options.Guards.Blocked) != 1 {
+		t.Error("merge modified the parent's guards")
+	}
+}
+
 func TestEnvironmentSpecValidate_Errors(t *testing.T) {
 	spec := EnvironmentSpec{
 		PreSpawn: []HookSpec{{Command: ""}},
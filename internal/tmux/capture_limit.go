@@ -0,0 +1,168 @@
+package tmux
+
+import (
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+// Capture size defaults.
+const (
+	// DefaultMaxCaptureBytes is the most output kept from one capture-pane.
+	DefaultMaxCaptureBytes = 4 << 20
+	// DefaultMaxPaneBytes is the most captured output retained per pane by
+	// stores that accumulate captures.
+	DefaultMaxPaneBytes = 16 << 20
+
+	// captureHeadShare is the fraction (1/n) of a truncated capture taken
+	// from its start; the rest comes from the end, where agents report
+	// their latest state.
+	captureHeadShare = 4
+)
+
+// CaptureLimits bounds how much pane output is held in memory.
+type CaptureLimits struct {
+	// MaxCaptureBytes truncates a single capture to this many bytes.
+	MaxCaptureBytes int
+	// MaxPaneBytes caps the captured bytes retained per pane.
+	MaxPaneBytes int
+}
+
+// DefaultCaptureLimits returns the default capture limits.
+func DefaultCaptureLimits() CaptureLimits {
+	return CaptureLimits{
+		MaxCaptureBytes: DefaultMaxCaptureBytes,
+		MaxPaneBytes:    DefaultMaxPaneBytes,
+	}
+}
+
+// CaptureStats counts captures and the output dropped by truncation.
+type CaptureStats struct {
+	Captures     int64 `json:"captures"`
+	Truncated    int64 `json:"truncated"`
+	DroppedBytes int64 `json:"dropped_bytes"`
+}
+
+var (
+	captureMu     sync.RWMutex
+	captureLimits = DefaultCaptureLimits()
+	captureTotals CaptureStats
+	capturePanes  = map[string]CaptureStats{}
+)
+
+// SetCaptureLimits replaces the capture limits used by all clients.
+// Non-positive limits fall back to the defaults.
+func SetCaptureLimits(limits CaptureLimits) {
+	if limits.MaxCaptureBytes <= 0 {
+		limits.MaxCaptureBytes = DefaultMaxCaptureBytes
+	}
+	if limits.MaxPaneBytes <= 0 {
+		limits.MaxPaneBytes = DefaultMaxPaneBytes
+	}
+	captureMu.Lock()
+	captureLimits = limits
+	captureMu.Unlock()
+}
+
+// GetCaptureLimits returns the current capture limits.
+func GetCaptureLimits() CaptureLimits {
+	captureMu.RLock()
+	defer captureMu.RUnlock()
+	return captureLimits
+}
+
+// GetCaptureStats returns capture totals and the per-target breakdown.
+func GetCaptureStats() (CaptureStats, map[string]CaptureStats) {
+	captureMu.RLock()
+	defer captureMu.RUnlock()
+	panes := make(map[string]CaptureStats, len(capturePanes))
+	for target, s := range capturePanes {
+		panes[target] = s
+	}
+	return captureTotals, panes
+}
+
+// ResetCaptureStats clears the capture counters.
+func ResetCaptureStats() {
+	captureMu.Lock()
+	captureTotals = CaptureStats{}
+	capturePanes = map[string]CaptureStats{}
+	captureMu.Unlock()
+}
+
+// limitCapture applies the per-capture limit to output captured from target
+// and records the result.
+func limitCapture(target, output string) string {
+	limited, dropped := TruncateCapture(output, GetCaptureLimits().MaxCaptureBytes)
+
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	pane := capturePanes[target]
+	for _, s := range []*CaptureStats{&captureTotals, &pane} {
+		s.Captures++
+		if dropped > 0 {
+			s.Truncated++
+			s.DroppedBytes += int64(dropped)
+		}
+	}
+	capturePanes[target] = pane
+	return limited
+}
+
+// TruncateCapture shortens output to at most maxBytes, keeping its start and
+// its end around a marker that says how much was dropped. It returns the
+// result and the number of bytes dropped. Cuts fall on line boundaries when
+// one is near, and never split a multi-byte character.
+func TruncateCapture(output string, maxBytes int) (string, int) {
+	if maxBytes <= 0 || len(output) <= maxBytes {
+		return output, 0
+	}
+	// Reserve room for the marker; its digits never exceed those of len(output).
+	budget := maxBytes - len(truncationMarker(len(output)))
+	if budget <= 0 {
+		cut := runeStart(output, maxBytes)
+		return output[:cut], len(output) - cut
+	}
+	headEnd := lineCut(output, budget/captureHeadShare, true)
+	tailStart := lineCut(output, len(output)-(budget-headEnd), false)
+	dropped := tailStart - headEnd
+	return output[:headEnd] + truncationMarker(dropped) + output[tailStart:], dropped
+}
+
+func truncationMarker(dropped int) string {
+	return fmt.Sprintf("\n[... ntm truncated %d bytes of output ...]\n", dropped)
+}
+
+// lineCut moves a cut at i back (head) or forward (tail) to the nearest line
+// boundary within a short distance, else to a character boundary.
+func lineCut(s string, i int, head bool) int {
+	const window = 256
+	if head {
+		for j := i; j > 0 && j > i-window; j-- {
+			if s[j-1] == '\n' {
+				return j
+			}
+		}
+		return runeStart(s, i)
+	}
+	for j := i; j < len(s) && j < i+window; j++ {
+		if j > 0 && s[j-1] == '\n' {
+			return j
+		}
+	}
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return i
+}
+
+// runeStart moves i back to the start of the character containing it.
+func runeStart(s string, i int) int {
+	if i >= len(s) {
+		return len(s)
+	}
+	for i > 0 && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,85 @@
+package tmux
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateCapture(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < 2000; i++ {
+		b.WriteString("line ")
+		b.WriteString(strings.Repeat("é", i%7))
+		b.WriteString(" of output\n")
+	}
+	output := b.String()
+
+	if got, dropped := TruncateCapture(output, 0); got != output || dropped != 0 {
+		t.Error("zero limit should not truncate")
+	}
+	if got, dropped := TruncateCapture("short", 100); got != "short" || dropped != 0 {
+		t.Error("output under the limit should not change")
+	}
+
+	for _, limit := range []int{10, 60, 1000, 4096, len(output) - 1} {
+		got, dropped := TruncateCapture(output, limit)
+		if len(got) > limit {
+			t.Errorf("limit %d: result is %d bytes", limit, len(got))
+		}
+		if dropped <= 0 || dropped > len(output) {
+			t.Errorf("limit %d: dropped = %d", limit, dropped)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("limit %d: result splits a character", limit)
+		}
+		if limit >= 1000 {
+			if !strings.Contains(got, "ntm truncated") {
+				t.Errorf("limit %d: missing truncation marker", limit)
+			}
+			if !strings.HasPrefix(got, "line  of output\n") || !strings.HasSuffix(got, " of output\n") {
+				t.Errorf("limit %d: head or tail not kept on line boundaries", limit)
+			}
+		}
+	}
+}
+
+func TestCapturePaneOutputLimits(t *testing.T) {
+	defer SetCaptureLimits(GetCaptureLimits())
+	defer ResetCaptureStats()
+	ResetCaptureStats()
+	SetCaptureLimits(CaptureLimits{MaxCaptureBytes: 1024})
+
+	big := strings.Repeat("x", 100) + "\n"
+	big = strings.Repeat(big, 100)
+	restore := SetCommandHook(func(ctx context.Context, cmd Command, next func() (string, error)) (string, error) {
+		if cmd.Args[2] == "%1" {
+			return big, nil
+		}
+		return "small", nil
+	})
+	defer restore()
+
+	out, err := CapturePaneOutput("%1", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) > 1024 || !strings.Contains(out, "ntm truncated") {
+		t.Errorf("capture not truncated: %d bytes", len(out))
+	}
+	if _, err := CapturePaneOutput("%2", 100); err != nil {
+		t.Fatal(err)
+	}
+
+	totals, panes := GetCaptureStats()
+	if totals.Captures != 2 || totals.Truncated != 1 || totals.DroppedBytes <= 0 {
+		t.Errorf("totals = %+v", totals)
+	}
+	if panes["%1"].DroppedBytes != totals.DroppedBytes || panes["%2"].Truncated != 0 {
+		t.Errorf("per-pane stats = %+v", panes)
+	}
+	if got := GetCaptureLimits(); got.MaxPaneBytes != DefaultMaxPaneBytes {
+		t.Errorf("unset MaxPaneBytes = %d, want default", got.MaxPaneBytes)
+	}
+}
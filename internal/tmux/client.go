@@ -86,14 +86,16 @@ func (c *Client) RunContext(ctx context.Context, args ...string) (string, error)
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	if c.Remote == "" {
-		return runLocalContext(ctx, args...)
-	}
+	return c.intercept(ctx, Command{Args: args}, func() (string, error) {
+		if c.Remote == "" {
+			return runLocalContext(ctx, args...)
+		}
 
-	// Remote execution via ssh
-	remoteCmd := buildRemoteShellCommand("tmux", args...)
-	// Use "--" to prevent Remote from being parsed as an ssh option.
-	return runSSHContext(ctx, "--", c.Remote, remoteCmd)
+		// Remote execution via ssh
+		remoteCmd := buildRemoteShellCommand("tmux", args...)
+		// Use "--" to prevent Remote from being parsed as an ssh option.
+		return runSSHContext(ctx, "--", c.Remote, remoteCmd)
+	})
 }
 
 // ShellQuote returns a POSIX-shell-safe single-quoted string.
@@ -0,0 +1,157 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Delivery verification defaults.
+const (
+	// DefaultDeliveryRetries is how many times an unconfirmed prompt is resent.
+	DefaultDeliveryRetries = 2
+	// DefaultDeliveryTimeout is how long to wait for a send to show up in the pane.
+	DefaultDeliveryTimeout = 3 * time.Second
+	// DefaultDeliveryPollInterval is how often the pane is captured while verifying.
+	DefaultDeliveryPollInterval = 250 * time.Millisecond
+
+	// deliveryMarkerLen is how many non-space runes of the prompt are matched.
+	deliveryMarkerLen = 24
+	// deliveryBackoff is the pause before the first resend; it doubles per attempt.
+	deliveryBackoff = 500 * time.Millisecond
+)
+
+// ErrDeliveryUnconfirmed is returned when a prompt never appeared in the pane.
+var ErrDeliveryUnconfirmed = errors.New("prompt delivery could not be confirmed")
+
+// DeliveryOptions configures verified sends.
+type DeliveryOptions struct {
+	// Retries is how many times to resend after the first attempt (0 = no retries).
+	Retries int
+	// Timeout bounds how long each attempt waits for confirmation.
+	Timeout time.Duration
+	// PollInterval is how often the pane is captured while waiting.
+	PollInterval time.Duration
+}
+
+// DefaultDeliveryOptions returns the default retry and verification budget.
+func DefaultDeliveryOptions() DeliveryOptions {
+	return DeliveryOptions{
+		Retries:      DefaultDeliveryRetries,
+		Timeout:      DefaultDeliveryTimeout,
+		PollInterval: DefaultDeliveryPollInterval,
+	}
+}
+
+// DeliveryResult reports how a verified send went.
+type DeliveryResult struct {
+	Attempts  int  `json:"attempts"`
+	Confirmed bool `json:"confirmed"`
+}
+
+// DeliveryConfirmed reports whether current shows the prompt arriving since
+// baseline was captured: the prompt's opening or closing text (or the paste
+// placeholder agents show for large inputs) appears more often than it did
+// before. Whitespace is ignored so that line wrapping does not hide the echo.
+func DeliveryConfirmed(baseline, current, prompt string) bool {
+	marker := deliveryMarker(prompt)
+	if marker == "" {
+		return baseline != current
+	}
+	before, after := squashSpace(baseline), squashSpace(current)
+	for _, m := range []string{marker, tailMarker(prompt), "[Pasted"} {
+		if strings.Count(after, m) > strings.Count(before, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// deliveryMarker returns the first non-space runes of the prompt's first
+// non-empty line.
+func deliveryMarker(prompt string) string {
+	for _, line := range strings.Split(prompt, "\n") {
+		squashed := []rune(squashSpace(line))
+		if len(squashed) == 0 {
+			continue
+		}
+		if len(squashed) > deliveryMarkerLen {
+			squashed = squashed[:deliveryMarkerLen]
+		}
+		return string(squashed)
+	}
+	return ""
+}
+
+func squashSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// SendKeysForAgentVerified sends a prompt with the double-Enter protocol and
+// confirms it reached the pane by diffing captures taken before and after.
+// Unconfirmed sends are retried with backoff up to opts.Retries times; if
+// none is confirmed the error wraps ErrDeliveryUnconfirmed. Long prompts that
+// arrive but never echo in full are not resent (that would duplicate the
+// part that landed); the error wraps ErrPromptTruncated instead.
+func SendKeysForAgentVerified(target, keys string, agentType AgentType, opts DeliveryOptions) (DeliveryResult, error) {
+	return deliverVerified(
+		func() error { return SendKeysForAgentDoubleEnter(target, keys, agentType) },
+		func() (string, error) { return CapturePaneOutput(target, LinesFullContext) },
+		keys, opts, time.Sleep,
+	)
+}
+
+func deliverVerified(send func() error, capture func() (string, error), prompt string, opts DeliveryOptions, sleep func(time.Duration)) (DeliveryResult, error) {
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultDeliveryTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultDeliveryPollInterval
+	}
+
+	var res DeliveryResult
+	backoff := deliveryBackoff
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			sleep(backoff)
+			backoff *= 2
+		}
+		res.Attempts++
+
+		baseline, err := capture()
+		if err != nil {
+			return res, fmt.Errorf("capturing pane before send: %w", err)
+		}
+		if err := send(); err != nil {
+			return res, err
+		}
+		var echoErr error
+		for waited := time.Duration(0); ; waited += opts.PollInterval {
+			current, err := capture()
+			if err == nil && DeliveryConfirmed(baseline, current, prompt) {
+				if echoErr = verifyEcho(baseline, current, prompt); echoErr == nil {
+					res.Confirmed = true
+					return res, nil
+				}
+			}
+			if waited >= opts.Timeout {
+				break
+			}
+			sleep(opts.PollInterval)
+		}
+		if echoErr != nil {
+			return res, echoErr
+		}
+	}
+	return res, fmt.Errorf("%w after %d attempt(s)", ErrDeliveryUnconfirmed, res.Attempts)
+}
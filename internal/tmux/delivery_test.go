@@ -0,0 +1,79 @@
+package tmux
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeliveryConfirmed(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name, baseline, current, prompt string
+		want                            bool
+	}{
+		{"echoed", "> ", "> fix the login bug\nWorking", "fix the login bug", true},
+		{"wrapped", "> ", "> fix the lo\ngin bug", "fix the login bug", true},
+		{"unchanged", "> ", "> ", "fix the login bug", false},
+		{"redraw only", "> \nstatus: 1", "> \nstatus: 2", "fix the login bug", false},
+		{"repeat of earlier prompt", "> run tests\ndone\n> ", "> run tests\ndone\n> ", "run tests", false},
+		{"repeat delivered", "> run tests\ndone\n> ", "> run tests\ndone\n> run tests", "run tests", true},
+		{"paste placeholder", "> ", "> [Pasted Content 2048 chars]", "line one\nline two", true},
+		{"multi-line uses first line", "", "\nstep one\n", "\n\nstep one\nstep two", true},
+		{"blank prompt", "a", "a\n", "  \n", true},
+	}
+	for _, tt := range tests {
+		if got := DeliveryConfirmed(tt.baseline, tt.current, tt.prompt); got != tt.want {
+			t.Errorf("%s: DeliveryConfirmed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDeliverVerified(t *testing.T) {
+	t.Parallel()
+	opts := DeliveryOptions{Retries: 2, Timeout: time.Second, PollInterval: 250 * time.Millisecond}
+
+	// The first send is swallowed by a redraw; the second lands.
+	pane := "> "
+	sends := 0
+	var slept time.Duration
+	res, err := deliverVerified(
+		func() error {
+			sends++
+			if sends == 2 {
+				pane += "hello agent"
+			}
+			return nil
+		},
+		func() (string, error) { return pane, nil },
+		"hello agent", opts, func(d time.Duration) { slept += d },
+	)
+	if err != nil || !res.Confirmed || res.Attempts != 2 {
+		t.Fatalf("result = %+v, err = %v; want confirmed on attempt 2", res, err)
+	}
+	if want := time.Second + deliveryBackoff; slept != want {
+		t.Errorf("slept %s, want %s (one timeout plus one backoff)", slept, want)
+	}
+
+	// Never lands: all attempts are used and the error is typed.
+	sends = 0
+	res, err = deliverVerified(
+		func() error { sends++; return nil },
+		func() (string, error) { return "> ", nil },
+		"hello agent", opts, func(time.Duration) {},
+	)
+	if !errors.Is(err, ErrDeliveryUnconfirmed) || res.Confirmed || res.Attempts != 3 || sends != 3 {
+		t.Fatalf("result = %+v, sends = %d, err = %v", res, sends, err)
+	}
+
+	// Send errors are returned without retrying.
+	boom := errors.New("no such pane")
+	res, err = deliverVerified(
+		func() error { return boom },
+		func() (string, error) { return "", nil },
+		"x", opts, func(time.Duration) {},
+	)
+	if !errors.Is(err, boom) || res.Attempts != 1 {
+		t.Fatalf("result = %+v, err = %v", res, err)
+	}
+}
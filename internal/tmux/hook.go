@@ -0,0 +1,44 @@
+package tmux
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Command is one tmux invocation as seen by a CommandHook.
+type Command struct {
+	Remote string
+	Args   []string
+	// Stdin is the content piped to the command (load-buffer).
+	Stdin string
+}
+
+// CommandHook intercepts tmux commands run by any Client. It calls next to
+// run the command for real, or returns a result of its own instead.
+type CommandHook func(ctx context.Context, cmd Command, next func() (string, error)) (string, error)
+
+var commandHook atomic.Pointer[CommandHook]
+
+// SetCommandHook installs hook for every Client and returns a function that
+// restores the previous hook. A nil hook removes interception.
+func SetCommandHook(hook CommandHook) (restore func()) {
+	var p *CommandHook
+	if hook != nil {
+		p = &hook
+	}
+	prev := commandHook.Swap(p)
+	return func() { commandHook.Store(prev) }
+}
+
+// intercept runs cmd through the installed hook, if any.
+func (c *Client) intercept(ctx context.Context, cmd Command, run func() (string, error)) (string, error) {
+	hook := commandHook.Load()
+	if hook == nil {
+		return run()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cmd.Remote = c.Remote
+	return (*hook)(ctx, cmd, run)
+}
@@ -0,0 +1,31 @@
+package tmux
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetCommandHook(t *testing.T) {
+	var seen []Command
+	restore := SetCommandHook(func(ctx context.Context, cmd Command, next func() (string, error)) (string, error) {
+		seen = append(seen, cmd)
+		return "hooked", nil
+	})
+
+	c := NewClient("user@host")
+	out, err := c.Run("display-message", "-p", "x")
+	if err != nil || out != "hooked" {
+		t.Fatalf("Run = %q, %v", out, err)
+	}
+	if err := c.loadBufferRemote("buf", "payload"); err != nil {
+		t.Fatalf("loadBufferRemote: %v", err)
+	}
+	if len(seen) != 2 || seen[0].Remote != "user@host" || seen[1].Stdin != "payload" || seen[1].Args[0] != "load-buffer" {
+		t.Errorf("seen = %+v", seen)
+	}
+
+	restore()
+	if commandHook.Load() != nil {
+		t.Error("restore did not remove the hook")
+	}
+}
@@ -0,0 +1,174 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Paste defaults.
+const (
+	// DefaultPasteChunkSize is the largest piece of a prompt sent per tmux command.
+	DefaultPasteChunkSize = 4096
+	// DefaultPasteChunkDelay is the pause between chunks of a long prompt.
+	DefaultPasteChunkDelay = 20 * time.Millisecond
+
+	// echoCheckMinLen is the prompt size above which the echo is checked for
+	// truncation; shorter prompts are covered by the opening-text check alone.
+	echoCheckMinLen = 512
+)
+
+// ErrPromptTruncated is returned when a long prompt only partly arrived.
+var ErrPromptTruncated = errors.New("prompt arrived truncated")
+
+// PasteOptions controls how prompts are typed into panes.
+type PasteOptions struct {
+	// BracketedPaste pastes buffers with bracketed-paste markers (when the
+	// pane's application asked for them), so newlines arrive as text.
+	BracketedPaste bool
+	// ChunkSize is the largest piece sent in one tmux command, in bytes.
+	ChunkSize int
+	// ChunkDelay is the pause between chunks.
+	ChunkDelay time.Duration
+}
+
+// DefaultPasteOptions returns the default paste behavior.
+func DefaultPasteOptions() PasteOptions {
+	return PasteOptions{
+		BracketedPaste: true,
+		ChunkSize:      DefaultPasteChunkSize,
+		ChunkDelay:     DefaultPasteChunkDelay,
+	}
+}
+
+var (
+	pasteOptionsMu sync.RWMutex
+	pasteOptions   = DefaultPasteOptions()
+)
+
+// SetPasteOptions replaces the paste behavior used by all clients.
+// Non-positive sizes and negative delays fall back to the defaults.
+func SetPasteOptions(opts PasteOptions) {
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultPasteChunkSize
+	}
+	if opts.ChunkDelay < 0 {
+		opts.ChunkDelay = DefaultPasteChunkDelay
+	}
+	pasteOptionsMu.Lock()
+	pasteOptions = opts
+	pasteOptionsMu.Unlock()
+}
+
+// GetPasteOptions returns the current paste behavior.
+func GetPasteOptions() PasteOptions {
+	pasteOptionsMu.RLock()
+	defer pasteOptionsMu.RUnlock()
+	return pasteOptions
+}
+
+// splitChunks splits s into pieces of at most size bytes without breaking
+// multi-byte characters.
+func splitChunks(s string, size int) []string {
+	if size <= 0 || len(s) <= size {
+		return []string{s}
+	}
+	var chunks []string
+	for start := 0; start < len(s); {
+		end := start + size
+		if end >= len(s) {
+			end = len(s)
+		} else {
+			for end > start && !utf8.RuneStart(s[end]) {
+				end--
+			}
+			if end == start {
+				end = start + size
+			}
+		}
+		chunks = append(chunks, s[start:end])
+		start = end
+	}
+	return chunks
+}
+
+var (
+	pastedCharsRe = regexp.MustCompile(`\[Pasted Content (\d+) chars\]`)
+	pastedLinesRe = regexp.MustCompile(`\[Pasted text #\d+ \+(\d+) lines\]`)
+)
+
+// EchoCheck compares a prompt with what the pane shows after it was sent.
+type EchoCheck struct {
+	Method   string `json:"method"` // "chars", "lines", or "text"
+	Expected int    `json:"expected"`
+	Echoed   int    `json:"echoed"`
+	Complete bool   `json:"complete"`
+}
+
+// CheckEcho measures how much of prompt appeared in the pane between the
+// baseline and current captures. Agents that collapse large pastes into a
+// placeholder ("[Pasted Content 5120 chars]", "[Pasted text #1 +80 lines]")
+// are checked against the counts they report; otherwise the prompt's closing
+// text must be visible, since truncation loses the end first.
+func CheckEcho(baseline, current, prompt string) EchoCheck {
+	if n := sumMatches(pastedCharsRe, current) - sumMatches(pastedCharsRe, baseline); n > 0 {
+		want := utf8.RuneCountInString(prompt)
+		// Line endings may be counted as one or two characters.
+		slack := strings.Count(prompt, "\n")
+		return EchoCheck{Method: "chars", Expected: want, Echoed: n, Complete: n >= want-slack}
+	}
+	if n := sumMatches(pastedLinesRe, current) - sumMatches(pastedLinesRe, baseline); n > 0 {
+		want := strings.Count(strings.TrimRight(prompt, "\n"), "\n")
+		// Each placeholder may count its first line differently.
+		slack := len(pastedLinesRe.FindAllString(current, -1))
+		return EchoCheck{Method: "lines", Expected: want, Echoed: n, Complete: n >= want-slack}
+	}
+
+	want := utf8.RuneCountInString(squashSpace(prompt))
+	tail := tailMarker(prompt)
+	before, after := squashSpace(baseline), squashSpace(current)
+	check := EchoCheck{Method: "text", Expected: want}
+	if tail != "" && strings.Count(after, tail) > strings.Count(before, tail) {
+		check.Complete = true
+		check.Echoed = want
+	} else if grown := utf8.RuneCountInString(after) - utf8.RuneCountInString(before); grown > 0 {
+		check.Echoed = min(grown, want)
+	}
+	return check
+}
+
+// verifyEcho returns an error wrapping ErrPromptTruncated when a long prompt
+// did not arrive intact. Short prompts always pass.
+func verifyEcho(baseline, current, prompt string) error {
+	if len(prompt) < echoCheckMinLen {
+		return nil
+	}
+	check := CheckEcho(baseline, current, prompt)
+	if check.Complete {
+		return nil
+	}
+	return fmt.Errorf("%w: echoed %d of %d %s", ErrPromptTruncated, check.Echoed, check.Expected, check.Method)
+}
+
+// tailMarker returns the last non-space runes of the prompt.
+func tailMarker(prompt string) string {
+	squashed := []rune(squashSpace(prompt))
+	if len(squashed) > deliveryMarkerLen {
+		squashed = squashed[len(squashed)-deliveryMarkerLen:]
+	}
+	return string(squashed)
+}
+
+func sumMatches(re *regexp.Regexp, s string) int {
+	total := 0
+	for _, m := range re.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[1])
+		total += n
+	}
+	return total
+}
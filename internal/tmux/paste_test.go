@@ -0,0 +1,88 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitChunks(t *testing.T) {
+	t.Parallel()
+	if got := splitChunks("short", 10); len(got) != 1 || got[0] != "short" {
+		t.Errorf("short input = %q", got)
+	}
+	got := splitChunks("abcdefghij", 4)
+	if strings.Join(got, "|") != "abcd|efgh|ij" {
+		t.Errorf("chunks = %q", got)
+	}
+	// "é" is two bytes; a chunk boundary must not split it.
+	got = splitChunks("aéééé", 4)
+	for _, c := range got {
+		if !strings.HasPrefix(c, "a") && !strings.HasPrefix(c, "é") {
+			t.Errorf("chunk %q starts mid-rune", c)
+		}
+	}
+	if strings.Join(got, "") != "aéééé" {
+		t.Errorf("chunks lost content: %q", got)
+	}
+}
+
+func TestSetPasteOptions(t *testing.T) {
+	orig := GetPasteOptions()
+	defer SetPasteOptions(orig)
+
+	SetPasteOptions(PasteOptions{ChunkSize: 0, ChunkDelay: -1})
+	got := GetPasteOptions()
+	if got.ChunkSize != DefaultPasteChunkSize || got.ChunkDelay != DefaultPasteChunkDelay || got.BracketedPaste {
+		t.Errorf("options = %+v, want defaults for size/delay and bracketed paste off", got)
+	}
+}
+
+func TestCheckEcho(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("word ", 300) + "the end marker"
+	lines := strings.TrimSuffix(strings.Repeat("line\n", 40), "\n")
+
+	tests := []struct {
+		name             string
+		baseline, screen string
+		prompt           string
+		method           string
+		complete         bool
+	}{
+		{"codex placeholder intact", "> ", fmt.Sprintf("> [Pasted Content %d chars]", len(long)), long, "chars", true},
+		{"codex placeholder short", "> ", "> [Pasted Content 900 chars]", long, "chars", false},
+		{"codex chunked placeholders", "> ", "> [Pasted Content 1000 chars][Pasted Content 514 chars]", long, "chars", true},
+		{"claude placeholder", "> ", "> [Pasted text #1 +39 lines]", lines, "lines", true},
+		{"claude placeholder short", "> ", "> [Pasted text #1 +10 lines]", lines, "lines", false},
+		{"literal echo with wrapping", "> ", "> " + long[:700] + "\n" + long[700:], long, "text", true},
+		{"literal echo cut off", "> ", "> " + long[:700], long, "text", false},
+	}
+	for _, tt := range tests {
+		got := CheckEcho(tt.baseline, tt.screen, tt.prompt)
+		if got.Method != tt.method || got.Complete != tt.complete {
+			t.Errorf("%s: CheckEcho = %+v, want method %s complete %v", tt.name, got, tt.method, tt.complete)
+		}
+	}
+}
+
+func TestDeliverVerified_TruncatedIsNotResent(t *testing.T) {
+	t.Parallel()
+	var b strings.Builder
+	for i := 0; i < 150; i++ {
+		fmt.Fprintf(&b, "step %d ", i)
+	}
+	prompt := b.String()
+	sends := 0
+	pane := "> "
+	res, err := deliverVerified(
+		func() error { sends++; pane += prompt[:300]; return nil },
+		func() (string, error) { return pane, nil },
+		prompt, DeliveryOptions{Retries: 2, Timeout: time.Second}, func(time.Duration) {},
+	)
+	if !errors.Is(err, ErrPromptTruncated) || res.Confirmed || sends != 1 {
+		t.Fatalf("result = %+v, sends = %d, err = %v; want one truncated attempt", res, sends, err)
+	}
+}
@@ -0,0 +1,303 @@
+package tmux
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PasteMode selects when a prompt is pasted through a tmux buffer instead of
+// being typed with send-keys.
+type PasteMode string
+
+const (
+	// PasteNever always types the prompt.
+	PasteNever PasteMode = "never"
+	// PasteMultiline pastes prompts that contain newlines.
+	PasteMultiline PasteMode = "multiline"
+	// PasteAlways pastes every prompt.
+	PasteAlways PasteMode = "always"
+)
+
+// EscapeMode selects how terminal escape sequences in a prompt are handled.
+type EscapeMode string
+
+const (
+	// EscapesKeep sends the prompt unchanged.
+	EscapesKeep EscapeMode = "keep"
+	// EscapesStrip removes escape sequences and control characters other
+	// than newlines and tabs, so stray sequences cannot trigger TUI shortcuts.
+	EscapesStrip EscapeMode = "strip"
+)
+
+// autoConfirmLines is how much of the pane is checked for confirmation prompts.
+const autoConfirmLines = 10
+
+// autoConfirmSettle is the pause after answering a confirmation prompt.
+const autoConfirmSettle = 300 * time.Millisecond
+
+// DefaultFileHandoff is the built-in file handoff instruction.
+const DefaultFileHandoff = "Read the file {path} in full and follow the instructions in it. It contains a prompt too large to paste here."
+
+// SendProfile describes how prompts are typed into and submitted to one kind
+// of agent CLI.
+type SendProfile struct {
+	// Submit lists the tmux keys pressed after the prompt text, in order
+	// (e.g. "Enter", "C-j").
+	Submit []string `json:"submit"`
+	// SubmitDelay is the pause between the prompt text and the first submit key.
+	SubmitDelay time.Duration `json:"submit_delay"`
+	// SubmitInterval is the pause between submit keys.
+	SubmitInterval time.Duration `json:"submit_interval"`
+	// Paste selects when the prompt is pasted rather than typed.
+	Paste PasteMode `json:"paste"`
+	// PasteOver also pastes prompts longer than this many bytes (0 = no limit).
+	PasteOver int `json:"paste_over,omitempty"`
+	// Newline is the key typed for each line break when a multi-line prompt
+	// is typed rather than pasted. Empty types the newline character itself.
+	Newline string `json:"newline,omitempty"`
+	// PreKeys are pressed before the prompt, e.g. "Escape" to leave a menu.
+	PreKeys []string `json:"pre_keys,omitempty"`
+	// Escapes selects how escape sequences in the prompt are handled.
+	Escapes EscapeMode `json:"escapes"`
+	// AutoConfirm answers "are you sure" prompts the agent shows before it
+	// accepts input. Rules are checked once per send, in order.
+	AutoConfirm []AutoConfirmRule `json:"auto_confirm,omitempty"`
+	// FileHandoff is the instruction sent in place of a prompt that was
+	// written to a file; "{path}" is replaced with the file's path.
+	FileHandoff string `json:"file_handoff"`
+}
+
+// AutoConfirmRule sends Keys when Match (a regular expression) matches the
+// last lines of the pane.
+type AutoConfirmRule struct {
+	Match string   `json:"match"`
+	Keys  []string `json:"keys"`
+
+	re *regexp.Regexp
+}
+
+// DefaultSendProfile returns the built-in profile for an agent type.
+func DefaultSendProfile(agentType AgentType) SendProfile {
+	p := SendProfile{
+		Submit:         []string{"Enter", "Enter"},
+		SubmitDelay:    DoubleEnterFirstDelay,
+		SubmitInterval: DoubleEnterSecondDelay,
+		Paste:          PasteNever,
+		Escapes:        EscapesKeep,
+		FileHandoff:    DefaultFileHandoff,
+	}
+	switch agentType {
+	case AgentClaude:
+		// send-keys -l silently strips newlines (tmux 3.6+), while
+		// paste-buffer converts them to CR which Claude Code interprets as
+		// Enter, enabling multi-line prompt submission.
+		p.Paste = PasteMultiline
+	case AgentGemini:
+		// Gemini's TUI interprets newlines in send-keys as actual Enter presses.
+		p.Paste = PasteMultiline
+	case AgentCodex:
+		// Codex uses bracketed paste mode and shows "[Pasted Content N chars]"
+		// instead of actual content when receiving large send-keys input, and
+		// may not auto-execute.
+		p.Paste = PasteMultiline
+		p.PasteOver = 512
+	}
+	return p
+}
+
+var (
+	sendProfilesMu sync.RWMutex
+	sendProfiles   = map[AgentType]SendProfile{}
+)
+
+// RegisterSendProfile sets the profile used for an agent type, replacing the
+// built-in one.
+func RegisterSendProfile(agentType AgentType, p SendProfile) error {
+	if err := p.compile(); err != nil {
+		return fmt.Errorf("send profile %s: %w", agentType, err)
+	}
+	sendProfilesMu.Lock()
+	sendProfiles[agentType] = p
+	sendProfilesMu.Unlock()
+	return nil
+}
+
+// ResetSendProfiles drops registered profiles, restoring the built-ins.
+func ResetSendProfiles() {
+	sendProfilesMu.Lock()
+	sendProfiles = map[AgentType]SendProfile{}
+	sendProfilesMu.Unlock()
+}
+
+// SendProfileFor returns the registered profile for an agent type, or its
+// built-in profile.
+func SendProfileFor(agentType AgentType) SendProfile {
+	sendProfilesMu.RLock()
+	p, ok := sendProfiles[agentType]
+	sendProfilesMu.RUnlock()
+	if ok {
+		return p
+	}
+	return DefaultSendProfile(agentType)
+}
+
+// Merge returns p with every field that is set in o replaced by o's value.
+func (p SendProfile) Merge(o SendProfile) SendProfile {
+	if o.Submit != nil {
+		p.Submit = o.Submit
+	}
+	if o.SubmitDelay != 0 {
+		p.SubmitDelay = o.SubmitDelay
+	}
+	if o.SubmitInterval != 0 {
+		p.SubmitInterval = o.SubmitInterval
+	}
+	if o.Paste != "" {
+		p.Paste = o.Paste
+	}
+	if o.PasteOver != 0 {
+		p.PasteOver = o.PasteOver
+	}
+	if o.Newline != "" {
+		p.Newline = o.Newline
+	}
+	if o.PreKeys != nil {
+		p.PreKeys = o.PreKeys
+	}
+	if o.Escapes != "" {
+		p.Escapes = o.Escapes
+	}
+	if o.AutoConfirm != nil {
+		p.AutoConfirm = o.AutoConfirm
+	}
+	if o.FileHandoff != "" {
+		p.FileHandoff = o.FileHandoff
+	}
+	return p
+}
+
+// compile validates the profile and compiles its auto-confirm patterns.
+func (p *SendProfile) compile() error {
+	switch p.Paste {
+	case "", PasteNever, PasteMultiline, PasteAlways:
+	default:
+		return fmt.Errorf("invalid paste mode %q (use never, multiline, or always)", p.Paste)
+	}
+	switch p.Escapes {
+	case "", EscapesKeep, EscapesStrip:
+	default:
+		return fmt.Errorf("invalid escapes mode %q (use keep or strip)", p.Escapes)
+	}
+	if p.PasteOver < 0 {
+		return fmt.Errorf("paste_over must be >= 0")
+	}
+	if p.FileHandoff != "" && !strings.Contains(p.FileHandoff, "{path}") {
+		return fmt.Errorf("file_handoff must contain {path}")
+	}
+	rules := make([]AutoConfirmRule, len(p.AutoConfirm))
+	for i, r := range p.AutoConfirm {
+		if len(r.Keys) == 0 {
+			return fmt.Errorf("auto_confirm %q has no keys", r.Match)
+		}
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("auto_confirm %q: %w", r.Match, err)
+		}
+		r.re = re
+		rules[i] = r
+	}
+	if p.AutoConfirm != nil {
+		p.AutoConfirm = rules
+	}
+	return nil
+}
+
+// pastes reports whether content should go through a tmux buffer.
+func (p SendProfile) pastes(content string) bool {
+	switch {
+	case p.Paste == PasteAlways:
+		return true
+	case p.Paste == PasteMultiline && strings.Contains(content, "\n"):
+		return true
+	case p.Paste != PasteNever && p.PasteOver > 0 && len(content) > p.PasteOver:
+		return true
+	}
+	return false
+}
+
+// prepare applies the profile's escape handling to a prompt.
+func (p SendProfile) prepare(content string) string {
+	if p.Escapes != EscapesStrip {
+		return content
+	}
+	content = escapeSeqRe.ReplaceAllString(content, "")
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, content)
+}
+
+// confirmKeys returns the keys of the first auto-confirm rule matching the
+// pane content, or nil.
+func (p SendProfile) confirmKeys(content string) []string {
+	if len(p.AutoConfirm) == 0 {
+		return nil
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > autoConfirmLines {
+		lines = lines[len(lines)-autoConfirmLines:]
+	}
+	tail := strings.Join(lines, "\n")
+	for _, r := range p.AutoConfirm {
+		re := r.re
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(r.Match); err != nil {
+				continue
+			}
+		}
+		if re.MatchString(tail) {
+			return r.Keys
+		}
+	}
+	return nil
+}
+
+// escapeSeqRe matches CSI, OSC, and two-character ESC sequences.
+var escapeSeqRe = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[@-Z\\-_])`)
+
+// sendKeyNames presses each named tmux key in order.
+func (c *Client) sendKeyNames(target string, keys []string) error {
+	for _, k := range keys {
+		if err := c.RunSilent("send-keys", "-t", target, k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeLines types content line by line, pressing newline between lines.
+func (c *Client) typeLines(target, content, newline string) error {
+	for i, line := range strings.Split(content, "\n") {
+		if i > 0 {
+			if err := c.sendKeyNames(target, []string{newline}); err != nil {
+				return err
+			}
+		}
+		if line == "" {
+			continue
+		}
+		if err := c.SendKeysWithDelay(target, line, false, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
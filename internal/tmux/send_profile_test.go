@@ -0,0 +1,95 @@
+package tmux
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultSendProfile(t *testing.T) {
+	t.Parallel()
+	for _, at := range []AgentType{AgentClaude, AgentCodex, AgentGemini, AgentAider, AgentUnknown} {
+		p := DefaultSendProfile(at)
+		if !reflect.DeepEqual(p.Submit, []string{"Enter", "Enter"}) || p.SubmitDelay != DoubleEnterFirstDelay || p.SubmitInterval != DoubleEnterSecondDelay {
+			t.Errorf("%s: submit = %v after %s/%s, want double-Enter", at, p.Submit, p.SubmitDelay, p.SubmitInterval)
+		}
+	}
+	long := strings.Repeat("x", 600)
+	if !DefaultSendProfile(AgentCodex).pastes(long) || DefaultSendProfile(AgentClaude).pastes(long) {
+		t.Error("only codex should paste long single-line prompts")
+	}
+	if DefaultSendProfile(AgentAider).pastes("a\nb") {
+		t.Error("agents without a profile should type multi-line prompts")
+	}
+}
+
+func TestRegisterSendProfile(t *testing.T) {
+	defer ResetSendProfiles()
+
+	p := DefaultSendProfile(AgentCodex).Merge(SendProfile{
+		Submit:      []string{"C-j"},
+		Paste:       PasteNever,
+		AutoConfirm: []AutoConfirmRule{{Match: `(?i)trust this folder\?`, Keys: []string{"y", "Enter"}}},
+	})
+	if err := RegisterSendProfile(AgentCodex, p); err != nil {
+		t.Fatal(err)
+	}
+	got := SendProfileFor(AgentCodex)
+	if !reflect.DeepEqual(got.Submit, []string{"C-j"}) || got.SubmitDelay != DoubleEnterFirstDelay {
+		t.Errorf("merged profile = %+v", got)
+	}
+	if needsBufferSend(AgentCodex, strings.Repeat("x", 600)) {
+		t.Error("paste = never should override the built-in paste_over")
+	}
+	if keys := got.confirmKeys("loading\nDo you trust this folder? [y/n]\n"); !reflect.DeepEqual(keys, []string{"y", "Enter"}) {
+		t.Errorf("confirmKeys = %v", keys)
+	}
+	if keys := got.confirmKeys("> "); keys != nil {
+		t.Errorf("confirmKeys on idle prompt = %v", keys)
+	}
+	if SendProfileFor(AgentClaude).Submit[0] != "Enter" {
+		t.Error("registering codex changed claude")
+	}
+
+	for _, bad := range []SendProfile{
+		{Paste: "sometimes"},
+		{Escapes: "maybe"},
+		{PasteOver: -1},
+		{AutoConfirm: []AutoConfirmRule{{Match: "(", Keys: []string{"y"}}}},
+		{AutoConfirm: []AutoConfirmRule{{Match: "sure"}}},
+	} {
+		if err := RegisterSendProfile(AgentGemini, bad); err == nil {
+			t.Errorf("RegisterSendProfile(%+v) should fail", bad)
+		}
+	}
+
+	ResetSendProfiles()
+	if got := SendProfileFor(AgentCodex); got.Paste != PasteMultiline || got.PasteOver != 512 {
+		t.Errorf("reset profile = %+v", got)
+	}
+}
+
+func TestSendProfilePrepare(t *testing.T) {
+	t.Parallel()
+	in := "fix \x1b[31mred\x1b[0m text\x07\n\tindented\x1b]0;title\x07 done"
+	strip := SendProfile{Escapes: EscapesStrip}
+	if got, want := strip.prepare(in), "fix red text\n\tindented done"; got != want {
+		t.Errorf("prepare = %q, want %q", got, want)
+	}
+	if got := (SendProfile{Escapes: EscapesKeep}).prepare(in); got != in {
+		t.Errorf("keep mode changed the prompt: %q", got)
+	}
+}
+
+func TestSendProfileMerge(t *testing.T) {
+	t.Parallel()
+	base := DefaultSendProfile(AgentClaude)
+	if got := base.Merge(SendProfile{}); !reflect.DeepEqual(got, base) {
+		t.Errorf("empty merge changed profile: %+v", got)
+	}
+	got := base.Merge(SendProfile{SubmitDelay: 2 * time.Second, Newline: "C-j", PreKeys: []string{"Escape"}})
+	if got.SubmitDelay != 2*time.Second || got.Newline != "C-j" || got.PreKeys[0] != "Escape" || got.Paste != PasteMultiline {
+		t.Errorf("merge = %+v", got)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 
 	"github.com/Dicklesworthstone/ntm/internal/agent"
 )
@@ -208,6 +207,24 @@ func SessionExists(name string) bool {
 	return DefaultClient.SessionExists(name)
 }
 
+// SessionCreated returns when a session was created.
+func (c *Client) SessionCreated(session string) (time.Time, error) {
+	out, err := c.Run("display-message", "-p", "-t", session, "#{session_created}")
+	if err != nil {
+		return time.Time{}, err
+	}
+	secs, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse session_created %q: %w", out, err)
+	}
+	return time.Unix(secs, 0), nil
+}
+
+// SessionCreated returns when a session was created (default client)
+func SessionCreated(session string) (time.Time, error) {
+	return DefaultClient.SessionCreated(session)
+}
+
 // ListSessions returns all tmux sessions
 func (c *Client) ListSessions() ([]Session, error) {
 	sep := FieldSeparator
@@ -626,6 +643,66 @@ func GetPaneTitle(paneID string) (string, error) {
 	return DefaultClient.GetPaneTitle(paneID)
 }
 
+// PaneAccountOption is the pane user option that records the pool account
+// an agent pane was spawned with.
+const PaneAccountOption = "@ntm_account"
+
+// PaneTakeoverOption is the pane user option set while a human has taken
+// over an agent pane (see internal/takeover).
+const PaneTakeoverOption = "@ntm_takeover"
+
+// SetPaneOption sets a pane-level user option (name starts with "@").
+func (c *Client) SetPaneOption(paneID, name, value string) error {
+	return c.RunSilent("set-option", "-p", "-t", paneID, name, value)
+}
+
+// SetPaneOption sets a pane-level user option (default client)
+func SetPaneOption(paneID, name, value string) error {
+	return DefaultClient.SetPaneOption(paneID, name, value)
+}
+
+// UnsetPaneOption removes a pane-level user option.
+func (c *Client) UnsetPaneOption(paneID, name string) error {
+	return c.RunSilent("set-option", "-p", "-u", "-t", paneID, name)
+}
+
+// UnsetPaneOption removes a pane-level user option (default client)
+func UnsetPaneOption(paneID, name string) error {
+	return DefaultClient.UnsetPaneOption(paneID, name)
+}
+
+// PaneOptions returns a pane user option for every pane in a session that
+// has it set, keyed by pane ID.
+func (c *Client) PaneOptions(session, name string) (map[string]string, error) {
+	out, err := c.Run("list-panes", "-s", "-t", session, "-F", "#{pane_id}"+FieldSeparator+"#{"+name+"}")
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		id, value, ok := strings.Cut(line, FieldSeparator)
+		if ok && value != "" {
+			values[id] = value
+		}
+	}
+	return values, nil
+}
+
+// PaneOptions returns a pane user option for a session's panes (default client)
+func PaneOptions(session, name string) (map[string]string, error) {
+	return DefaultClient.PaneOptions(session, name)
+}
+
+// GetPaneOption returns a pane-level user option, or "" if it is unset.
+func (c *Client) GetPaneOption(paneID, name string) (string, error) {
+	return c.Run("display-message", "-p", "-t", paneID, "#{"+name+"}")
+}
+
+// GetPaneOption returns a pane-level user option (default client)
+func GetPaneOption(paneID, name string) (string, error) {
+	return DefaultClient.GetPaneOption(paneID, name)
+}
+
 // GetPaneTags returns the tags for a pane parsed from its title.
 // Returns nil if no tags are found.
 func (c *Client) GetPaneTags(paneID string) ([]string, error) {
@@ -808,35 +885,15 @@ func (c *Client) SendKeys(target, keys string, enter bool) error {
 // SendKeysWithDelay sends keys to a pane with a configurable delay before Enter.
 // Use ShellEnterDelay for shell panes (bash, zsh) or DefaultEnterDelay for agent TUIs.
 func (c *Client) SendKeysWithDelay(target, keys string, enter bool, enterDelay time.Duration) error {
-	// Send large payloads in chunks to avoid ARG_MAX limits or tmux buffer issues
-	const chunkSize = 4096
-
-	if len(keys) <= chunkSize {
-		if err := c.RunSilent("send-keys", "-t", target, "-l", "--", keys); err != nil {
-			return err
+	// Send large payloads in chunks to avoid ARG_MAX limits and to keep agent
+	// TUIs from dropping keystrokes when input arrives faster than they redraw.
+	opts := GetPasteOptions()
+	for i, chunk := range splitChunks(keys, opts.ChunkSize) {
+		if i > 0 && opts.ChunkDelay > 0 {
+			time.Sleep(opts.ChunkDelay)
 		}
-	} else {
-		start := 0
-		for start < len(keys) {
-			end := start + chunkSize
-			if end >= len(keys) {
-				end = len(keys)
-			} else {
-				// Backtrack end until it hits a rune start to avoid splitting multi-byte characters
-				for end > start && !utf8.RuneStart(keys[end]) {
-					end--
-				}
-				// If we backtracked all the way (single char > chunkSize?), just split at chunk size
-				if end == start {
-					end = start + chunkSize
-				}
-			}
-
-			chunk := keys[start:end]
-			if err := c.RunSilent("send-keys", "-t", target, "-l", "--", chunk); err != nil {
-				return err
-			}
-			start = end
+		if err := c.RunSilent("send-keys", "-t", target, "-l", "--", chunk); err != nil {
+			return err
 		}
 	}
 
@@ -908,7 +965,29 @@ func (c *Client) SendBuffer(target, content string, enter bool) error {
 }
 
 // SendBufferWithDelay sends content using the buffer mechanism with a configurable Enter delay.
+// Content larger than the configured chunk size is pasted in pieces, pausing
+// between them; with bracketed paste enabled each piece is wrapped in paste
+// markers if the pane's application requested them.
 func (c *Client) SendBufferWithDelay(target, content string, enter bool, enterDelay time.Duration) error {
+	opts := GetPasteOptions()
+	for i, chunk := range splitChunks(content, opts.ChunkSize) {
+		if i > 0 && opts.ChunkDelay > 0 {
+			time.Sleep(opts.ChunkDelay)
+		}
+		if err := c.pasteBuffer(target, chunk, opts.BracketedPaste); err != nil {
+			return err
+		}
+	}
+
+	if enter {
+		time.Sleep(enterDelay)
+		return c.RunSilent("send-keys", "-t", target, "Enter")
+	}
+	return nil
+}
+
+// pasteBuffer loads content into a uniquely named tmux buffer and pastes it.
+func (c *Client) pasteBuffer(target, content string, bracketed bool) error {
 	// Use a unique buffer name to avoid conflicts with concurrent operations
 	// Include timestamp to prevent race conditions when multiple agents send simultaneously
 	bufferName := fmt.Sprintf("ntm-%d", time.Now().UnixNano())
@@ -928,22 +1007,29 @@ func (c *Client) SendBufferWithDelay(target, content string, enter bool, enterDe
 	}
 
 	// Paste the buffer into the target pane
-	// -p = paste from buffer, -d = delete buffer after pasting, -b = buffer name
-	if err := c.RunSilent("paste-buffer", "-p", "-d", "-b", bufferName, "-t", target); err != nil {
+	// -d = delete buffer after pasting, -b = buffer name,
+	// -p = bracketed paste if the application requested it
+	args := []string{"paste-buffer", "-d", "-b", bufferName, "-t", target}
+	if bracketed {
+		args = append(args, "-p")
+	}
+	if err := c.RunSilent(args...); err != nil {
 		// Clean up buffer on error
 		_ = c.RunSilent("delete-buffer", "-b", bufferName)
 		return fmt.Errorf("paste buffer: %w", err)
 	}
-
-	if enter {
-		time.Sleep(enterDelay)
-		return c.RunSilent("send-keys", "-t", target, "Enter")
-	}
 	return nil
 }
 
 // loadBufferLocal loads content into a tmux buffer using stdin (for local operations).
 func (c *Client) loadBufferLocal(bufferName, content string) error {
+	_, err := c.intercept(context.Background(), Command{Args: []string{"load-buffer", "-b", bufferName, "-"}, Stdin: content}, func() (string, error) {
+		return "", c.runLoadBufferLocal(bufferName, content)
+	})
+	return err
+}
+
+func (c *Client) runLoadBufferLocal(bufferName, content string) error {
 	binary := BinaryPath()
 	cmd := exec.Command(binary, "load-buffer", "-b", bufferName, "-")
 	cmd.Stdin = strings.NewReader(content)
@@ -958,6 +1044,13 @@ func (c *Client) loadBufferLocal(bufferName, content string) error {
 
 // loadBufferRemote loads content into a tmux buffer for remote operations.
 func (c *Client) loadBufferRemote(bufferName, content string) error {
+	_, err := c.intercept(context.Background(), Command{Args: []string{"load-buffer", "-b", bufferName, "-"}, Stdin: content}, func() (string, error) {
+		return "", c.runLoadBufferRemote(bufferName, content)
+	})
+	return err
+}
+
+func (c *Client) runLoadBufferRemote(bufferName, content string) error {
 	// For remote, we need to pipe the content through ssh
 	// Use printf with escaped content to avoid shell interpretation issues
 	quotedContent := ShellQuote(content)
@@ -984,48 +1077,48 @@ func SendBufferWithDelay(target, content string, enter bool, enterDelay time.Dur
 	return DefaultClient.SendBufferWithDelay(target, content, enter, enterDelay)
 }
 
-// SendKeysForAgent sends keys to a pane using the appropriate method for the agent type.
-// For Gemini and Claude Code agents with multi-line content, it uses the buffer mechanism
-// to avoid newlines being interpreted as Enter key presses.
-// For other agents, it uses the standard send-keys method.
+// SendKeysForAgent sends keys to a pane using the agent type's send profile.
+// The profile decides whether the prompt is pasted through a buffer (so that
+// newlines are not interpreted as Enter key presses) or typed, how escape
+// sequences are handled, and which keys are pressed first.
 func (c *Client) SendKeysForAgent(target, keys string, enter bool, agentType AgentType) error {
 	return c.SendKeysForAgentWithDelay(target, keys, enter, DefaultEnterDelay, agentType)
 }
 
-// SendKeysForAgentWithDelay sends keys using the appropriate method with a configurable delay.
+// SendKeysForAgentWithDelay sends keys using the agent's send profile with a configurable delay.
 func (c *Client) SendKeysForAgentWithDelay(target, keys string, enter bool, enterDelay time.Duration, agentType AgentType) error {
-	// Use buffer mechanism for Gemini and Claude Code when content contains newlines
-	// Their TUIs interpret newlines in send-keys as actual Enter presses,
-	// causing them to enter "quote mode" or submit prompts prematurely
-	if needsBufferSend(agentType, keys) {
+	p := SendProfileFor(agentType)
+	if len(p.AutoConfirm) > 0 {
+		if screen, err := c.CapturePaneOutput(target, autoConfirmLines); err == nil {
+			if confirm := p.confirmKeys(screen); confirm != nil {
+				if err := c.sendKeyNames(target, confirm); err != nil {
+					return fmt.Errorf("auto-confirm: %w", err)
+				}
+				time.Sleep(autoConfirmSettle)
+			}
+		}
+	}
+	if err := c.sendKeyNames(target, p.PreKeys); err != nil {
+		return err
+	}
+
+	keys = p.prepare(keys)
+	switch {
+	case p.pastes(keys):
 		return c.SendBufferWithDelay(target, keys, enter, enterDelay)
+	case p.Newline != "" && strings.Contains(keys, "\n"):
+		if err := c.typeLines(target, keys, p.Newline); err != nil {
+			return err
+		}
+		return c.SendKeysWithDelay(target, "", enter, enterDelay)
 	}
 	return c.SendKeysWithDelay(target, keys, enter, enterDelay)
 }
 
 // needsBufferSend returns true if the content should be sent via buffer mechanism
-// rather than send-keys, based on agent type and content.
+// rather than send-keys, based on the agent type's send profile.
 func needsBufferSend(agentType AgentType, content string) bool {
-	// Gemini and Codex need buffer-based sending for multi-line content or large prompts.
-	// Gemini's TUI interprets newlines in send-keys as actual Enter presses.
-	// Codex uses bracketed paste mode and shows "[Pasted Content N chars]" instead of
-	// actual content when receiving large send-keys input, and may not auto-execute.
-	switch agentType {
-	case AgentClaude:
-		// Use buffer if content contains newlines — send-keys -l silently strips
-		// newlines (tmux 3.6+), while paste-buffer converts them to CR which
-		// Claude Code interprets as Enter, enabling multi-line prompt submission.
-		return strings.Contains(content, "\n")
-	case AgentGemini:
-		// Use buffer if content contains newlines
-		return strings.Contains(content, "\n")
-	case AgentCodex:
-		// Use buffer for Codex when content contains newlines or is large (>512 chars)
-		// This avoids the "[Pasted Content N chars]" truncation and auto-execute issues
-		return strings.Contains(content, "\n") || len(content) > 512
-	default:
-		return false
-	}
+	return SendProfileFor(agentType).pastes(content)
 }
 
 // SendKeysForAgent sends keys using the appropriate method for the agent type (default client)
@@ -1045,28 +1138,36 @@ const (
 	DoubleEnterSecondDelay = 500 * time.Millisecond
 )
 
-// SendKeysForAgentDoubleEnter sends text to an agent pane using the double-Enter
-// submission protocol: send text (no enter), wait 1s, Enter, wait 500ms, Enter.
-// This is the reliable way to submit prompts to CLI agents (Claude, Codex, Gemini)
-// that need the double-Enter to confirm submission.
-func SendKeysForAgentDoubleEnter(target, keys string, agentType AgentType) error {
-	// Send the text without pressing Enter
-	if err := SendKeysForAgent(target, keys, false, agentType); err != nil {
-		return err
-	}
-	time.Sleep(DoubleEnterFirstDelay)
-	// First Enter
-	if err := SendKeys(target, "", true); err != nil {
+// SubmitPromptForAgent types a prompt into an agent pane and submits it with
+// the agent's send profile: text first (no Enter), then each submit key after
+// the profile's delays. The built-in profiles use the double-Enter protocol:
+// wait 1s, Enter, wait 500ms, Enter.
+func (c *Client) SubmitPromptForAgent(target, keys string, agentType AgentType) error {
+	if err := c.SendKeysForAgent(target, keys, false, agentType); err != nil {
 		return err
 	}
-	time.Sleep(DoubleEnterSecondDelay)
-	// Second Enter
-	if err := SendKeys(target, "", true); err != nil {
-		return err
+	p := SendProfileFor(agentType)
+	for i, key := range p.Submit {
+		if i == 0 {
+			time.Sleep(p.SubmitDelay)
+		} else {
+			time.Sleep(p.SubmitInterval)
+		}
+		if err := c.sendKeyNames(target, []string{key}); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// SendKeysForAgentDoubleEnter sends text to an agent pane using the agent's
+// submission protocol (double-Enter unless its send profile says otherwise).
+// This is the reliable way to submit prompts to CLI agents (Claude, Codex, Gemini)
+// that need the double-Enter to confirm submission.
+func SendKeysForAgentDoubleEnter(target, keys string, agentType AgentType) error {
+	return DefaultClient.SubmitPromptForAgent(target, keys, agentType)
+}
+
 // SendInterrupt sends Ctrl+C to a pane
 func (c *Client) SendInterrupt(target string) error {
 	return c.RunSilent("send-keys", "-t", target, "C-c")
@@ -1238,7 +1339,11 @@ func (c *Client) CapturePaneOutputContext(ctx context.Context, target string, li
 	if lines < 0 {
 		lines = -lines
 	}
-	return c.RunContext(ctx, "capture-pane", "-t", target, "-p", "-S", fmt.Sprintf("-%d", lines))
+	output, err := c.RunContext(ctx, "capture-pane", "-t", target, "-p", "-S", fmt.Sprintf("-%d", lines))
+	if err != nil {
+		return output, err
+	}
+	return limitCapture(target, output), nil
 }
 
 // CapturePaneOutput captures the output of a pane (default client)
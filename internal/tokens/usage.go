@@ -0,0 +1,273 @@
+package tokens
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UsageSource identifies which provider parser produced a TokenUsage record.
+type UsageSource string
+
+const (
+	UsageSourceClaude UsageSource = "claude"
+	UsageSourceCodex  UsageSource = "codex"
+	UsageSourceGemini UsageSource = "gemini"
+)
+
+// TokenUsage is a structured token/context usage record extracted from the
+// status lines an agent CLI prints. Unlike the estimators in this package these
+// are the numbers the provider itself reported. Fields the CLI did not print
+// are left at zero; ContextPercent is -1 when no context figure was seen.
+type TokenUsage struct {
+	Source           UsageSource `json:"source"`
+	InputTokens      int64       `json:"input_tokens,omitempty"`
+	OutputTokens     int64       `json:"output_tokens,omitempty"`
+	CacheReadTokens  int64       `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int64       `json:"cache_write_tokens,omitempty"`
+	TotalTokens      int64       `json:"total_tokens,omitempty"`
+	ContextUsed      int64       `json:"context_used,omitempty"`
+	ContextLimit     int64       `json:"context_limit,omitempty"`
+	ContextPercent   float64     `json:"context_percent"` // 0.0-100.0 used, -1 if unknown
+	CostUSD          float64     `json:"cost_usd,omitempty"`
+	CapturedAt       time.Time   `json:"captured_at"`
+}
+
+// HasTokenCounts reports whether the record carries input/output token counts.
+func (u *TokenUsage) HasTokenCounts() bool {
+	return u != nil && (u.InputTokens > 0 || u.OutputTokens > 0 || u.TotalTokens > 0)
+}
+
+// HasContext reports whether the record carries a context window figure.
+func (u *TokenUsage) HasContext() bool {
+	return u != nil && (u.ContextPercent >= 0 || u.ContextUsed > 0)
+}
+
+// Since returns the usage added since prev, an earlier record from the same
+// agent. CLIs print running totals, so successive records must be diffed
+// before they are summed. A counter lower than in prev means the agent started
+// a new conversation, and all of u is new. Context figures are levels, not
+// totals, and are copied from u.
+func (u *TokenUsage) Since(prev *TokenUsage) *TokenUsage {
+	if u == nil {
+		return nil
+	}
+	delta := *u
+	if prev == nil || prev.Source != u.Source ||
+		u.InputTokens < prev.InputTokens || u.OutputTokens < prev.OutputTokens ||
+		u.CacheReadTokens < prev.CacheReadTokens || u.CacheWriteTokens < prev.CacheWriteTokens ||
+		u.TotalTokens < prev.TotalTokens || u.CostUSD < prev.CostUSD {
+		return &delta
+	}
+	delta.InputTokens -= prev.InputTokens
+	delta.OutputTokens -= prev.OutputTokens
+	delta.CacheReadTokens -= prev.CacheReadTokens
+	delta.CacheWriteTokens -= prev.CacheWriteTokens
+	delta.TotalTokens -= prev.TotalTokens
+	delta.CostUSD -= prev.CostUSD
+	return &delta
+}
+
+// UsageParser extracts TokenUsage from captured pane output for one provider.
+type UsageParser interface {
+	// Source returns the provider this parser understands.
+	Source() UsageSource
+	// Parse returns the most recent usage reported in output, or nil if none.
+	Parse(output string) *TokenUsage
+}
+
+// usageParsers maps agent type aliases to their provider parser.
+var usageParsers = map[string]UsageParser{
+	"cc":     claudeUsageParser{},
+	"claude": claudeUsageParser{},
+	"cod":    codexUsageParser{},
+	"codex":  codexUsageParser{},
+	"gmi":    geminiUsageParser{},
+	"gemini": geminiUsageParser{},
+}
+
+// ParserForAgent returns the usage parser for an agent type (cc, cod, gmi or
+// their long names). Returns nil for agent types without a known format.
+func ParserForAgent(agentType string) UsageParser {
+	return usageParsers[strings.ToLower(strings.TrimSpace(agentType))]
+}
+
+// ParseUsage extracts the most recent usage record from captured output for
+// the given agent type. Returns nil if the agent type is unknown or no usage
+// line is present.
+func ParseUsage(agentType, output string) *TokenUsage {
+	p := ParserForAgent(agentType)
+	if p == nil {
+		return nil
+	}
+	return p.Parse(stripANSI(output))
+}
+
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]|\x1b\][^\a\x1b]*(\a|\x1b\\)`)
+
+func stripANSI(s string) string {
+	return ansiRegex.ReplaceAllString(s, "")
+}
+
+// tokenNum matches counts like "12,345", "12.3k", "1.5M".
+const tokenNum = `(\d[\d,]*(?:\.\d+)?\s*[kKmM]?)`
+
+// parseTokenNumber converts "12,345", "12.3k", "1.5M" into an integer count.
+func parseTokenNumber(s string) (int64, bool) {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), ",", ""))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier = 1e3
+		s = strings.TrimSpace(strings.TrimSuffix(s, "k"))
+	case strings.HasSuffix(s, "m"):
+		multiplier = 1e6
+		s = strings.TrimSpace(strings.TrimSuffix(s, "m"))
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return int64(f*multiplier + 0.5), true
+}
+
+// lastMatch returns the submatches of the last occurrence of re in s.
+func lastMatch(re *regexp.Regexp, s string) []string {
+	all := re.FindAllStringSubmatch(s, -1)
+	if len(all) == 0 {
+		return nil
+	}
+	return all[len(all)-1]
+}
+
+func newUsage(src UsageSource) *TokenUsage {
+	return &TokenUsage{Source: src, ContextPercent: -1, CapturedAt: time.Now()}
+}
+
+// finish returns u if any field was populated, nil otherwise.
+func finish(u *TokenUsage) *TokenUsage {
+	if !u.HasTokenCounts() && !u.HasContext() && u.CostUSD == 0 {
+		return nil
+	}
+	if u.TotalTokens == 0 {
+		u.TotalTokens = u.InputTokens + u.OutputTokens
+	}
+	if u.ContextPercent < 0 && u.ContextUsed > 0 && u.ContextLimit > 0 {
+		u.ContextPercent = float64(u.ContextUsed) / float64(u.ContextLimit) * 100
+	}
+	return u
+}
+
+// claudeUsageParser understands Claude Code's cost summary and context banner:
+//
+//	Total cost:            $0.5512
+//	Usage:                 12.3k input, 4.5k output, 100.2k cache read, 0 cache write
+//	Context left until auto-compact: 12%
+type claudeUsageParser struct{}
+
+var (
+	claudeUsageLine   = regexp.MustCompile(`(?i)usage:\s*` + tokenNum + `\s*input,\s*` + tokenNum + `\s*output(?:,\s*` + tokenNum + `\s*cache read)?(?:,\s*` + tokenNum + `\s*cache write)?`)
+	claudeCostLine    = regexp.MustCompile(`(?i)total cost:\s*\$([\d.]+)`)
+	claudeContextLeft = regexp.MustCompile(`(?i)context left until auto-compact:\s*(\d+(?:\.\d+)?)%`)
+)
+
+func (claudeUsageParser) Source() UsageSource { return UsageSourceClaude }
+
+func (claudeUsageParser) Parse(output string) *TokenUsage {
+	u := newUsage(UsageSourceClaude)
+	if m := lastMatch(claudeUsageLine, output); m != nil {
+		u.InputTokens, _ = parseTokenNumber(m[1])
+		u.OutputTokens, _ = parseTokenNumber(m[2])
+		if m[3] != "" {
+			u.CacheReadTokens, _ = parseTokenNumber(m[3])
+		}
+		if m[4] != "" {
+			u.CacheWriteTokens, _ = parseTokenNumber(m[4])
+		}
+	}
+	if m := lastMatch(claudeCostLine, output); m != nil {
+		u.CostUSD, _ = strconv.ParseFloat(m[1], 64)
+	}
+	if m := lastMatch(claudeContextLeft, output); m != nil {
+		if left, err := strconv.ParseFloat(m[1], 64); err == nil {
+			u.ContextPercent = 100 - left
+		}
+	}
+	return finish(u)
+}
+
+// codexUsageParser understands Codex CLI's exit summary and footer:
+//
+//	Token usage: total=12,345 input=10,000 (+ 2,000 cached) output=2,345
+//	123K tokens used · 45% context left
+type codexUsageParser struct{}
+
+var (
+	codexUsageLine   = regexp.MustCompile(`(?i)token usage:\s*total=` + tokenNum + `\s+input=` + tokenNum + `(?:\s*\(\+\s*` + tokenNum + `\s*cached\))?\s+output=` + tokenNum)
+	codexFooter      = regexp.MustCompile(`(?i)` + tokenNum + `\s*tokens used`)
+	codexContextLeft = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)%\s*context left`)
+)
+
+func (codexUsageParser) Source() UsageSource { return UsageSourceCodex }
+
+func (codexUsageParser) Parse(output string) *TokenUsage {
+	u := newUsage(UsageSourceCodex)
+	if m := lastMatch(codexUsageLine, output); m != nil {
+		u.TotalTokens, _ = parseTokenNumber(m[1])
+		u.InputTokens, _ = parseTokenNumber(m[2])
+		if m[3] != "" {
+			u.CacheReadTokens, _ = parseTokenNumber(m[3])
+		}
+		u.OutputTokens, _ = parseTokenNumber(m[4])
+	}
+	if m := lastMatch(codexFooter, output); m != nil {
+		u.ContextUsed, _ = parseTokenNumber(m[1])
+	}
+	if m := lastMatch(codexContextLeft, output); m != nil {
+		if left, err := strconv.ParseFloat(m[1], 64); err == nil {
+			u.ContextPercent = 100 - left
+		}
+	}
+	return finish(u)
+}
+
+// geminiUsageParser understands Gemini CLI's footer and /stats output:
+//
+//	gemini-2.5-pro (87% context left)
+//	Input Tokens    12,345
+//	Output Tokens   2,345
+//	Cached Tokens   1,000
+type geminiUsageParser struct{}
+
+var (
+	geminiContextLeft = regexp.MustCompile(`(?i)\((\d+(?:\.\d+)?)%\s*context left\)`)
+	geminiInput       = regexp.MustCompile(`(?i)input tokens[:\s]+` + tokenNum)
+	geminiOutput      = regexp.MustCompile(`(?i)output tokens[:\s]+` + tokenNum)
+	geminiCached      = regexp.MustCompile(`(?i)cached tokens[:\s]+` + tokenNum)
+	geminiTotal       = regexp.MustCompile(`(?i)total tokens[:\s]+` + tokenNum)
+)
+
+func (geminiUsageParser) Source() UsageSource { return UsageSourceGemini }
+
+func (geminiUsageParser) Parse(output string) *TokenUsage {
+	u := newUsage(UsageSourceGemini)
+	if m := lastMatch(geminiInput, output); m != nil {
+		u.InputTokens, _ = parseTokenNumber(m[1])
+	}
+	if m := lastMatch(geminiOutput, output); m != nil {
+		u.OutputTokens, _ = parseTokenNumber(m[1])
+	}
+	if m := lastMatch(geminiCached, output); m != nil {
+		u.CacheReadTokens, _ = parseTokenNumber(m[1])
+	}
+	if m := lastMatch(geminiTotal, output); m != nil {
+		u.TotalTokens, _ = parseTokenNumber(m[1])
+	}
+	if m := lastMatch(geminiContextLeft, output); m != nil {
+		if left, err := strconv.ParseFloat(m[1], 64); err == nil {
+			u.ContextPercent = 100 - left
+		}
+	}
+	return finish(u)
+}
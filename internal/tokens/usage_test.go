@@ -0,0 +1,129 @@
+package tokens
+
+import "testing"
+
+func TestParseUsageClaude(t *testing.T) {
+	output := "some work\n" +
+		"Total cost:            $0.5512\n" +
+		"Usage:                 12.3k input, 4,500 output, 100.2k cache read, 0 cache write\n" +
+		"\x1b[2mContext left until auto-compact: 12%\x1b[0m\n"
+
+	u := ParseUsage("cc", output)
+	if u == nil {
+		t.Fatal("expected usage, got nil")
+	}
+	if u.Source != UsageSourceClaude {
+		t.Errorf("Source = %q, want %q", u.Source, UsageSourceClaude)
+	}
+	if u.InputTokens != 12300 || u.OutputTokens != 4500 {
+		t.Errorf("tokens = %d/%d, want 12300/4500", u.InputTokens, u.OutputTokens)
+	}
+	if u.CacheReadTokens != 100200 {
+		t.Errorf("CacheReadTokens = %d, want 100200", u.CacheReadTokens)
+	}
+	if u.TotalTokens != 16800 {
+		t.Errorf("TotalTokens = %d, want 16800", u.TotalTokens)
+	}
+	if u.CostUSD != 0.5512 {
+		t.Errorf("CostUSD = %v, want 0.5512", u.CostUSD)
+	}
+	if u.ContextPercent != 88 {
+		t.Errorf("ContextPercent = %v, want 88", u.ContextPercent)
+	}
+}
+
+func TestParseUsageCodex(t *testing.T) {
+	output := "› fix the bug\n" +
+		"45K tokens used · 80% context left\n" +
+		"123K tokens used · 45% context left\n" +
+		"Token usage: total=12,345 input=10,000 (+ 2,000 cached) output=2,345\n"
+
+	u := ParseUsage("cod", output)
+	if u == nil {
+		t.Fatal("expected usage, got nil")
+	}
+	if u.TotalTokens != 12345 || u.InputTokens != 10000 || u.OutputTokens != 2345 {
+		t.Errorf("tokens = %d/%d/%d, want 12345/10000/2345", u.TotalTokens, u.InputTokens, u.OutputTokens)
+	}
+	if u.CacheReadTokens != 2000 {
+		t.Errorf("CacheReadTokens = %d, want 2000", u.CacheReadTokens)
+	}
+	// Last footer wins.
+	if u.ContextUsed != 123000 {
+		t.Errorf("ContextUsed = %d, want 123000", u.ContextUsed)
+	}
+	if u.ContextPercent != 55 {
+		t.Errorf("ContextPercent = %v, want 55", u.ContextPercent)
+	}
+}
+
+func TestParseUsageGemini(t *testing.T) {
+	output := "Input Tokens    12,345\nOutput Tokens   2,345\nCached Tokens   1,000\n" +
+		"gemini-2.5-pro (87% context left)\n"
+
+	u := ParseUsage("gmi", output)
+	if u == nil {
+		t.Fatal("expected usage, got nil")
+	}
+	if u.InputTokens != 12345 || u.OutputTokens != 2345 || u.CacheReadTokens != 1000 {
+		t.Errorf("tokens = %d/%d/%d, want 12345/2345/1000", u.InputTokens, u.OutputTokens, u.CacheReadTokens)
+	}
+	if u.ContextPercent != 13 {
+		t.Errorf("ContextPercent = %v, want 13", u.ContextPercent)
+	}
+}
+
+func TestParseUsageNoMatch(t *testing.T) {
+	if u := ParseUsage("cc", "hello world\n> "); u != nil {
+		t.Errorf("expected nil for output without usage, got %+v", u)
+	}
+	if u := ParseUsage("unknown", "Usage: 1k input, 1k output"); u != nil {
+		t.Errorf("expected nil for unknown agent type, got %+v", u)
+	}
+	if u := ParseUsage("cod", "80% context left"); u == nil || u.HasTokenCounts() || !u.HasContext() {
+		t.Errorf("expected context-only usage, got %+v", u)
+	}
+}
+
+func TestTokenUsageSince(t *testing.T) {
+	first := &TokenUsage{Source: UsageSourceClaude, InputTokens: 1000, OutputTokens: 200, ContextPercent: 10}
+	second := &TokenUsage{Source: UsageSourceClaude, InputTokens: 1500, OutputTokens: 260, ContextPercent: 14}
+
+	if d := first.Since(nil); d.InputTokens != 1000 || d.OutputTokens != 200 {
+		t.Errorf("first sample delta = %+v, want the whole record", d)
+	}
+	d := second.Since(first)
+	if d.InputTokens != 500 || d.OutputTokens != 60 || d.ContextPercent != 14 {
+		t.Errorf("delta = %+v, want 500 in, 60 out, context 14%%", d)
+	}
+	if d := second.Since(second); d.HasTokenCounts() {
+		t.Errorf("unchanged sample delta = %+v, want no tokens", d)
+	}
+
+	// Counters went backwards: a new conversation, all of it is new.
+	restarted := &TokenUsage{Source: UsageSourceClaude, InputTokens: 300, OutputTokens: 40, ContextPercent: 2}
+	if d := restarted.Since(second); d.InputTokens != 300 || d.OutputTokens != 40 {
+		t.Errorf("delta after restart = %+v, want the whole record", d)
+	}
+}
+
+func TestParseTokenNumber(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"12,345", 12345, true},
+		{"12.3k", 12300, true},
+		{"1.5M", 1500000, true},
+		{"123K", 123000, true},
+		{"0", 0, true},
+		{"abc", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTokenNumber(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseTokenNumber(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
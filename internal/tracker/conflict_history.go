@@ -0,0 +1,422 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/jsonlutil"
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultConflictHistoryPath is where detected conflicts and their
+// resolutions are persisted.
+const DefaultConflictHistoryPath = "~/.config/ntm/analytics/conflicts.jsonl"
+
+// Conflict sources.
+const (
+	ConflictSourceReservation = "reservation" // Agent Mail reservation clash
+	ConflictSourceGit         = "git"         // concurrent worktree edits
+)
+
+// Conflict resolution outcomes. The first four mirror the dashboard actions.
+const (
+	ConflictOutcomeWait    = "wait"
+	ConflictOutcomeRequest = "request"
+	ConflictOutcomeForce   = "force"
+	ConflictOutcomeDismiss = "dismiss"
+	ConflictOutcomeCleared = "cleared" // the conflict went away on its own
+	ConflictOutcomeExpired = "expired" // the blocking reservation expired
+)
+
+// Conflict history record events.
+const (
+	conflictEventDetected     = "detected"
+	conflictEventResolved     = "resolved"
+	conflictEventAcknowledged = "acknowledged"
+)
+
+// ConflictRecord is one line of the conflict history file.
+type ConflictRecord struct {
+	Event     string     `json:"event"` // "detected", "resolved", or "acknowledged"
+	Timestamp time.Time  `json:"timestamp"`
+	Source    string     `json:"source"`
+	Session   string     `json:"session,omitempty"`
+	Path      string     `json:"path"`
+	Agents    []string   `json:"agents,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Outcome   string     `json:"outcome,omitempty"`
+	Note      string     `json:"note,omitempty"` // Acknowledgement note
+}
+
+func (r ConflictRecord) key() string {
+	return r.Source + "|" + r.Session + "|" + r.Path
+}
+
+// ConflictEpisode is a conflict from detection to resolution.
+type ConflictEpisode struct {
+	Source     string     `json:"source"`
+	Session    string     `json:"session,omitempty"`
+	Path       string     `json:"path"`
+	Agents     []string   `json:"agents,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	DetectedAt time.Time  `json:"detected_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	Outcome    string     `json:"outcome,omitempty"`
+	AckedAt    *time.Time `json:"acked_at,omitempty"`
+	AckNote    string     `json:"ack_note,omitempty"`
+}
+
+// ConflictID returns the short identifier of conflicts on path, the one
+// passed to `ntm conflicts ack`. It is stable across detections.
+func ConflictID(path string) string {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	return fmt.Sprintf("cf-%08x", h.Sum32())
+}
+
+// ID returns the episode's conflict identifier.
+func (e ConflictEpisode) ID() string {
+	return ConflictID(e.Path)
+}
+
+// Acknowledged reports whether the episode was acknowledged while open.
+func (e ConflictEpisode) Acknowledged() bool {
+	return e.AckedAt != nil
+}
+
+// Open reports whether the episode is unresolved.
+func (e ConflictEpisode) Open() bool {
+	return e.ResolvedAt == nil
+}
+
+// Duration is the time to resolution, or zero for open episodes.
+func (e ConflictEpisode) Duration() time.Duration {
+	if e.ResolvedAt == nil {
+		return 0
+	}
+	return e.ResolvedAt.Sub(e.DetectedAt)
+}
+
+// ConflictHistory persists conflict episodes to an append-only JSONL file
+// shared by every ntm process.
+type ConflictHistory struct {
+	path string
+	mu   sync.Mutex
+	now  func() time.Time
+}
+
+// NewConflictHistory returns a history stored at path
+// (DefaultConflictHistoryPath if empty).
+func NewConflictHistory(path string) *ConflictHistory {
+	if path == "" {
+		path = DefaultConflictHistoryPath
+	}
+	return &ConflictHistory{path: util.ExpandPath(path), now: time.Now}
+}
+
+// Path returns the history file location.
+func (h *ConflictHistory) Path() string {
+	return h.path
+}
+
+// RecordDetected starts an episode for rec unless one is already open for
+// the same source, session, and path, in which case it reports false.
+// Callers can therefore report a conflict every time they see it.
+func (h *ConflictHistory) RecordDetected(rec ConflictRecord) (bool, error) {
+	rec.Event = conflictEventDetected
+	rec.Outcome = ""
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = h.now()
+	}
+	rec.Timestamp = rec.Timestamp.UTC()
+	rec.Agents = uniqueSorted(rec.Agents)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	unlock, err := util.LockFile(h.path)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	episodes, err := h.readLocked()
+	if err != nil {
+		return false, err
+	}
+	for _, ep := range episodes {
+		if ep.Open() && episodeKey(ep) == rec.key() {
+			return false, nil
+		}
+	}
+	return true, h.appendLocked(rec)
+}
+
+// RecordResolved closes the open episode for source, session, and path with
+// outcome. It reports false if no such episode is open.
+func (h *ConflictHistory) RecordResolved(source, session, path, outcome string) (bool, error) {
+	n, err := h.ResolveWhere(func(ep ConflictEpisode) bool {
+		return ep.Source == source && ep.Session == session && ep.Path == path
+	}, outcome)
+	return n > 0, err
+}
+
+// ResolveWhere closes every open episode matched by match with outcome and
+// returns how many were closed.
+func (h *ConflictHistory) ResolveWhere(match func(ConflictEpisode) bool, outcome string) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	unlock, err := util.LockFile(h.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	episodes, err := h.readLocked()
+	if err != nil {
+		return 0, err
+	}
+	now := h.now().UTC()
+	closed := 0
+	for _, ep := range episodes {
+		if !ep.Open() || !match(ep) {
+			continue
+		}
+		rec := ConflictRecord{
+			Event:     conflictEventResolved,
+			Timestamp: now,
+			Source:    ep.Source,
+			Session:   ep.Session,
+			Path:      ep.Path,
+			Outcome:   outcome,
+		}
+		if err := h.appendLocked(rec); err != nil {
+			return closed, err
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// Acknowledge marks every open episode with conflict identifier id as
+// acknowledged with note and returns how many were marked. Acknowledged
+// episodes stay open, and in the history, until the conflict clears; a later
+// detection of the same file starts an unacknowledged episode.
+func (h *ConflictHistory) Acknowledge(id, note string) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	unlock, err := util.LockFile(h.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	episodes, err := h.readLocked()
+	if err != nil {
+		return 0, err
+	}
+	now := h.now().UTC()
+	acked := 0
+	for _, ep := range episodes {
+		if !ep.Open() || ep.ID() != id {
+			continue
+		}
+		rec := ConflictRecord{
+			Event:     conflictEventAcknowledged,
+			Timestamp: now,
+			Source:    ep.Source,
+			Session:   ep.Session,
+			Path:      ep.Path,
+			Note:      note,
+		}
+		if err := h.appendLocked(rec); err != nil {
+			return acked, err
+		}
+		acked++
+	}
+	return acked, nil
+}
+
+// Episodes returns the episodes detected at or after since (all if zero),
+// oldest first.
+func (h *ConflictHistory) Episodes(since time.Time) ([]ConflictEpisode, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	episodes, err := h.readLocked()
+	if err != nil {
+		return nil, err
+	}
+	if since.IsZero() {
+		return episodes, nil
+	}
+	filtered := episodes[:0]
+	for _, ep := range episodes {
+		if !ep.DetectedAt.Before(since) {
+			filtered = append(filtered, ep)
+		}
+	}
+	return filtered, nil
+}
+
+// PurgeSession removes the records of session, or only those recorded
+// before before if it is non-zero. It returns the number of records removed.
+func (h *ConflictHistory) PurgeSession(session string, before time.Time) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	unlock, err := util.LockFile(h.path)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	info, err := os.Stat(h.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var kept bytes.Buffer
+	removed := 0
+	accept := func([]byte) error { return nil }
+	_, err = jsonlutil.ScanFile(h.path, jsonlutil.Options{Validate: accept}, func(_ int, line []byte) error {
+		var rec ConflictRecord
+		if json.Unmarshal(line, &rec) == nil && rec.Session == session &&
+			(before.IsZero() || rec.Timestamp.Before(before)) {
+			removed++
+			return nil
+		}
+		kept.Write(line)
+		kept.WriteByte('\n')
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("scanning conflict history: %w", err)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	if err := util.AtomicWriteFile(h.path, kept.Bytes(), info.Mode().Perm()); err != nil {
+		return 0, fmt.Errorf("rewriting conflict history: %w", err)
+	}
+	return removed, nil
+}
+
+func (h *ConflictHistory) appendLocked(rec ConflictRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("marshaling conflict record: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("creating conflict history directory: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening conflict history: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("writing conflict record: %w", err)
+	}
+	return f.Close()
+}
+
+// readLocked folds the record log into episodes. An open episode whose
+// reservation has expired is treated as resolved at its expiry.
+func (h *ConflictHistory) readLocked() ([]ConflictEpisode, error) {
+	var episodes []ConflictEpisode
+	open := make(map[string]int) // key -> index into episodes
+	res, err := jsonlutil.ScanFile(h.path, jsonlutil.Options{}, func(_ int, line []byte) error {
+		var rec ConflictRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil // Skip malformed
+		}
+		switch rec.Event {
+		case conflictEventDetected:
+			if i, ok := open[rec.key()]; ok {
+				prev := &episodes[i]
+				if prev.ExpiresAt == nil || !prev.ExpiresAt.Before(rec.Timestamp) {
+					return nil
+				}
+				expired := *prev.ExpiresAt
+				prev.ResolvedAt = &expired
+				prev.Outcome = ConflictOutcomeExpired
+			}
+			open[rec.key()] = len(episodes)
+			episodes = append(episodes, ConflictEpisode{
+				Source:     rec.Source,
+				Session:    rec.Session,
+				Path:       rec.Path,
+				Agents:     rec.Agents,
+				Reason:     rec.Reason,
+				DetectedAt: rec.Timestamp,
+				ExpiresAt:  rec.ExpiresAt,
+			})
+		case conflictEventResolved:
+			i, ok := open[rec.key()]
+			if !ok {
+				return nil
+			}
+			resolvedAt := rec.Timestamp
+			episodes[i].ResolvedAt = &resolvedAt
+			episodes[i].Outcome = rec.Outcome
+			delete(open, rec.key())
+		case conflictEventAcknowledged:
+			if i, ok := open[rec.key()]; ok {
+				ackedAt := rec.Timestamp
+				episodes[i].AckedAt = &ackedAt
+				episodes[i].AckNote = rec.Note
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scanning conflict history: %w", err)
+	}
+	if !res.OK() {
+		slog.Debug("skipped corrupt conflict records", "path", h.path, "count", len(res.Bad))
+	}
+
+	now := h.now()
+	for _, i := range open {
+		ep := &episodes[i]
+		if ep.ExpiresAt != nil && ep.ExpiresAt.Before(now) {
+			expired := *ep.ExpiresAt
+			ep.ResolvedAt = &expired
+			ep.Outcome = ConflictOutcomeExpired
+		}
+	}
+	return episodes, nil
+}
+
+func episodeKey(ep ConflictEpisode) string {
+	return ep.Source + "|" + ep.Session + "|" + ep.Path
+}
+
+func uniqueSorted(items []string) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(items))
+	out := make([]string, 0, len(items))
+	for _, s := range items {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,208 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestConflictHistory(t *testing.T, now *time.Time) *ConflictHistory {
+	t.Helper()
+	h := NewConflictHistory(filepath.Join(t.TempDir(), "conflicts.jsonl"))
+	h.now = func() time.Time { return *now }
+	return h
+}
+
+func TestConflictHistory_DetectResolve(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	h := newTestConflictHistory(t, &now)
+
+	rec := ConflictRecord{Source: ConflictSourceReservation, Session: "proj", Path: "api.go", Agents: []string{"Blue", "Red", "Blue"}}
+	started, err := h.RecordDetected(rec)
+	if err != nil || !started {
+		t.Fatalf("RecordDetected = %v, %v; want true, nil", started, err)
+	}
+
+	// Reporting the same conflict again while it is open is a no-op.
+	now = now.Add(time.Minute)
+	if started, err := h.RecordDetected(rec); err != nil || started {
+		t.Fatalf("duplicate RecordDetected = %v, %v; want false, nil", started, err)
+	}
+
+	now = now.Add(4 * time.Minute)
+	resolved, err := h.RecordResolved(ConflictSourceReservation, "proj", "api.go", ConflictOutcomeForce)
+	if err != nil || !resolved {
+		t.Fatalf("RecordResolved = %v, %v; want true, nil", resolved, err)
+	}
+	if resolved, _ := h.RecordResolved(ConflictSourceReservation, "proj", "api.go", ConflictOutcomeForce); resolved {
+		t.Error("resolving a closed episode should report false")
+	}
+
+	episodes, err := h.Episodes(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 1 {
+		t.Fatalf("got %d episodes, want 1", len(episodes))
+	}
+	ep := episodes[0]
+	if ep.Open() || ep.Outcome != ConflictOutcomeForce || ep.Duration() != 5*time.Minute {
+		t.Errorf("episode = %+v, want forced after 5m", ep)
+	}
+	if len(ep.Agents) != 2 || ep.Agents[0] != "Blue" || ep.Agents[1] != "Red" {
+		t.Errorf("agents = %v, want [Blue Red]", ep.Agents)
+	}
+
+	// A new detection after resolution starts a new episode.
+	if started, _ := h.RecordDetected(rec); !started {
+		t.Error("detection after resolution should start a new episode")
+	}
+	if episodes, _ := h.Episodes(time.Time{}); len(episodes) != 2 || !episodes[1].Open() {
+		t.Errorf("episodes = %+v, want a second open episode", episodes)
+	}
+}
+
+func TestConflictHistory_Expiry(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	h := newTestConflictHistory(t, &now)
+
+	expires := now.Add(10 * time.Minute)
+	rec := ConflictRecord{Source: ConflictSourceReservation, Session: "proj", Path: "db.go", ExpiresAt: &expires}
+	if _, err := h.RecordDetected(rec); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour)
+	rec.ExpiresAt = nil
+	if started, err := h.RecordDetected(rec); err != nil || !started {
+		t.Fatalf("RecordDetected after expiry = %v, %v; want true, nil", started, err)
+	}
+
+	episodes, err := h.Episodes(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 2 {
+		t.Fatalf("got %d episodes, want 2", len(episodes))
+	}
+	if episodes[0].Outcome != ConflictOutcomeExpired || episodes[0].Duration() != 10*time.Minute {
+		t.Errorf("first episode = %+v, want expired after 10m", episodes[0])
+	}
+	if !episodes[1].Open() {
+		t.Errorf("second episode = %+v, want open", episodes[1])
+	}
+}
+
+func TestConflictHistory_Acknowledge(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	h := newTestConflictHistory(t, &now)
+
+	if n, err := h.Acknowledge(ConflictID("/repo/go.sum"), "lockfile"); err != nil || n != 0 {
+		t.Fatalf("Acknowledge without episode = %d, %v; want 0, nil", n, err)
+	}
+	rec := ConflictRecord{Source: ConflictSourceGit, Session: "proj", Path: "/repo/go.sum"}
+	if _, err := h.RecordDetected(rec); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if n, err := h.Acknowledge(ConflictID("/repo/go.sum"), "lockfile"); err != nil || n != 1 {
+		t.Fatalf("Acknowledge = %d, %v; want 1, nil", n, err)
+	}
+
+	episodes, _ := h.Episodes(time.Time{})
+	ep := episodes[0]
+	if !ep.Open() || !ep.Acknowledged() || ep.AckNote != "lockfile" || !ep.AckedAt.Equal(now) || ep.ID() != ConflictID("/repo/go.sum") {
+		t.Fatalf("episode = %+v, want open and acknowledged", ep)
+	}
+
+	// The acknowledgement ends with the episode.
+	if _, err := h.RecordResolved(ConflictSourceGit, "proj", "/repo/go.sum", ConflictOutcomeCleared); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.RecordDetected(rec); err != nil {
+		t.Fatal(err)
+	}
+	episodes, _ = h.Episodes(time.Time{})
+	if len(episodes) != 2 || !episodes[0].Acknowledged() || episodes[1].Acknowledged() {
+		t.Errorf("episodes = %+v, want only the first acknowledged", episodes)
+	}
+}
+
+func TestConflictHistory_PurgeSession(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	h := newTestConflictHistory(t, &now)
+
+	for _, rec := range []ConflictRecord{
+		{Source: ConflictSourceGit, Session: "a", Path: "/repo/x.go", Timestamp: now.Add(-2 * time.Hour)},
+		{Source: ConflictSourceGit, Session: "a", Path: "/repo/y.go", Timestamp: now},
+		{Source: ConflictSourceGit, Session: "b", Path: "/repo/x.go", Timestamp: now.Add(-2 * time.Hour)},
+	} {
+		if _, err := h.RecordDetected(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := h.PurgeSession("a", now.Add(-time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeSession = %d, %v; want 1, nil", n, err)
+	}
+	n, err = h.PurgeSession("a", time.Time{})
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeSession (all) = %d, %v; want 1, nil", n, err)
+	}
+
+	episodes, _ := h.Episodes(time.Time{})
+	if len(episodes) != 1 || episodes[0].Session != "b" {
+		t.Errorf("episodes after purge = %+v, want only session b", episodes)
+	}
+
+	empty := NewConflictHistory(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if n, err := empty.PurgeSession("a", time.Time{}); err != nil || n != 0 {
+		t.Errorf("PurgeSession on missing file = %d, %v", n, err)
+	}
+	if _, err := os.Stat(empty.Path()); !os.IsNotExist(err) {
+		t.Error("PurgeSession should not create the history file")
+	}
+}
+
+func TestAnalyzeConflicts(t *testing.T) {
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	at := func(min int) *time.Time {
+		ts := base.Add(time.Duration(min) * time.Minute)
+		return &ts
+	}
+	episodes := []ConflictEpisode{
+		{Session: "s", Path: "api.go", Agents: []string{"Blue", "Red"}, DetectedAt: base, ResolvedAt: at(10), Outcome: ConflictOutcomeForce},
+		{Session: "s", Path: "api_test.go", Agents: []string{"Blue", "Red"}, DetectedAt: *at(5), ResolvedAt: at(8), Outcome: ConflictOutcomeWait},
+		{Session: "s", Path: "api.go", Agents: []string{"Green", "Red"}, DetectedAt: *at(60), ResolvedAt: at(62), Outcome: ConflictOutcomeForce},
+		{Session: "s", Path: "api_test.go", Agents: []string{"Red", "Blue"}, DetectedAt: *at(61)},
+		{Session: "other", Path: "db.go", DetectedAt: *at(61)},
+	}
+
+	stats := AnalyzeConflicts(episodes, 0, base.Add(2*time.Hour))
+	if stats.Episodes != 5 || stats.Open != 2 || stats.Resolved != 3 {
+		t.Errorf("counts = %d/%d/%d, want 5/2/3", stats.Episodes, stats.Open, stats.Resolved)
+	}
+	if stats.MeanResolutionSeconds != 300 {
+		t.Errorf("MeanResolutionSeconds = %v, want 300", stats.MeanResolutionSeconds)
+	}
+	if len(stats.ByOutcome) != 2 || stats.ByOutcome[0].Outcome != ConflictOutcomeForce || stats.ByOutcome[0].MeanResolutionSeconds != 360 {
+		t.Errorf("ByOutcome = %+v", stats.ByOutcome)
+	}
+	if stats.Files[0].Path != "api.go" || stats.Files[0].Count != 2 {
+		t.Errorf("top file = %+v, want api.go x2", stats.Files[0])
+	}
+	if top := stats.AgentPairs[0]; top.A != "Blue" || top.B != "Red" || top.Count != 3 {
+		t.Errorf("top agent pair = %+v, want Blue/Red x3", top)
+	}
+	// api.go and api_test.go overlapped twice; db.go is in another session.
+	if len(stats.FilePairs) != 1 || stats.FilePairs[0].A != "api.go" || stats.FilePairs[0].B != "api_test.go" || stats.FilePairs[0].Count != 2 {
+		t.Errorf("FilePairs = %+v, want api.go/api_test.go x2", stats.FilePairs)
+	}
+
+	limited := AnalyzeConflicts(episodes, 1, base.Add(2*time.Hour))
+	if len(limited.Files) != 1 || len(limited.AgentPairs) != 1 {
+		t.Errorf("top=1 returned %d files, %d agent pairs", len(limited.Files), len(limited.AgentPairs))
+	}
+}
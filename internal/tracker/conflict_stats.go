@@ -0,0 +1,196 @@
+package tracker
+
+import (
+	"sort"
+	"time"
+)
+
+// ConflictStats summarizes conflict history for re-partitioning work:
+// which files and agents keep colliding and how long collisions last.
+type ConflictStats struct {
+	Episodes int `json:"episodes"`
+	Open     int `json:"open"`
+	Resolved int `json:"resolved"`
+	// MeanResolutionSeconds is the mean time-to-resolution of resolved episodes.
+	MeanResolutionSeconds float64         `json:"mean_resolution_seconds"`
+	ByOutcome             []OutcomeStat   `json:"by_outcome"`
+	Files                 []ConflictCount `json:"files"`
+	// FilePairs are files that were in conflict at the same time in the same
+	// session, i.e. likely to belong to the same unit of ownership.
+	FilePairs  []ConflictPair `json:"file_pairs"`
+	AgentPairs []ConflictPair `json:"agent_pairs"`
+}
+
+// OutcomeStat aggregates the episodes resolved one way.
+type OutcomeStat struct {
+	Outcome               string  `json:"outcome"`
+	Count                 int     `json:"count"`
+	MeanResolutionSeconds float64 `json:"mean_resolution_seconds"`
+}
+
+// ConflictCount is how often a single file was in conflict.
+type ConflictCount struct {
+	Path                  string  `json:"path"`
+	Count                 int     `json:"count"`
+	MeanResolutionSeconds float64 `json:"mean_resolution_seconds,omitempty"`
+}
+
+// ConflictPair is how often two files or two agents collided.
+type ConflictPair struct {
+	A                     string  `json:"a"`
+	B                     string  `json:"b"`
+	Count                 int     `json:"count"`
+	MeanResolutionSeconds float64 `json:"mean_resolution_seconds,omitempty"`
+}
+
+// durationMean accumulates resolution times.
+type durationMean struct {
+	count int
+	n     int
+	total time.Duration
+}
+
+func (d *durationMean) add(ep ConflictEpisode) {
+	d.count++
+	if !ep.Open() {
+		d.n++
+		d.total += ep.Duration()
+	}
+}
+
+func (d *durationMean) seconds() float64 {
+	if d.n == 0 {
+		return 0
+	}
+	return (d.total / time.Duration(d.n)).Seconds()
+}
+
+// AnalyzeConflicts computes ConflictStats over episodes. Open episodes count
+// toward recurrence and, for file pairs, are treated as lasting until now.
+// top limits the file and pair lists (0 = unlimited).
+func AnalyzeConflicts(episodes []ConflictEpisode, top int, now time.Time) *ConflictStats {
+	stats := &ConflictStats{
+		Episodes:   len(episodes),
+		ByOutcome:  []OutcomeStat{},
+		Files:      []ConflictCount{},
+		FilePairs:  []ConflictPair{},
+		AgentPairs: []ConflictPair{},
+	}
+
+	var overall durationMean
+	outcomes := make(map[string]*durationMean)
+	files := make(map[string]*durationMean)
+	agentPairs := make(map[[2]string]*durationMean)
+	for _, ep := range episodes {
+		overall.add(ep)
+		if ep.Open() {
+			stats.Open++
+		} else {
+			stats.Resolved++
+			meanFor(outcomes, ep.Outcome).add(ep)
+		}
+		meanFor(files, ep.Path).add(ep)
+
+		agents := uniqueSorted(ep.Agents)
+		for i := 0; i < len(agents); i++ {
+			for j := i + 1; j < len(agents); j++ {
+				meanFor(agentPairs, [2]string{agents[i], agents[j]}).add(ep)
+			}
+		}
+	}
+	stats.MeanResolutionSeconds = overall.seconds()
+
+	for outcome, m := range outcomes {
+		stats.ByOutcome = append(stats.ByOutcome, OutcomeStat{Outcome: outcome, Count: m.count, MeanResolutionSeconds: m.seconds()})
+	}
+	sort.Slice(stats.ByOutcome, func(i, j int) bool {
+		a, b := stats.ByOutcome[i], stats.ByOutcome[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Outcome < b.Outcome
+	})
+
+	for path, m := range files {
+		stats.Files = append(stats.Files, ConflictCount{Path: path, Count: m.count, MeanResolutionSeconds: m.seconds()})
+	}
+	sort.Slice(stats.Files, func(i, j int) bool {
+		a, b := stats.Files[i], stats.Files[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Path < b.Path
+	})
+
+	for pair, m := range agentPairs {
+		stats.AgentPairs = append(stats.AgentPairs, ConflictPair{A: pair[0], B: pair[1], Count: m.count, MeanResolutionSeconds: m.seconds()})
+	}
+	sortPairs(stats.AgentPairs)
+
+	for pair, n := range overlappingFilePairs(episodes, now) {
+		stats.FilePairs = append(stats.FilePairs, ConflictPair{A: pair[0], B: pair[1], Count: n})
+	}
+	sortPairs(stats.FilePairs)
+
+	if top > 0 {
+		stats.Files = stats.Files[:min(top, len(stats.Files))]
+		stats.FilePairs = stats.FilePairs[:min(top, len(stats.FilePairs))]
+		stats.AgentPairs = stats.AgentPairs[:min(top, len(stats.AgentPairs))]
+	}
+	return stats
+}
+
+func meanFor[K comparable](m map[K]*durationMean, k K) *durationMean {
+	d, ok := m[k]
+	if !ok {
+		d = &durationMean{}
+		m[k] = d
+	}
+	return d
+}
+
+// overlappingFilePairs counts, per pair of distinct paths, how many times
+// episodes of the two were open at the same time in the same session.
+func overlappingFilePairs(episodes []ConflictEpisode, now time.Time) map[[2]string]int {
+	sorted := append([]ConflictEpisode(nil), episodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].DetectedAt.Before(sorted[j].DetectedAt) })
+
+	end := func(ep ConflictEpisode) time.Time {
+		if ep.ResolvedAt != nil {
+			return *ep.ResolvedAt
+		}
+		return now
+	}
+
+	pairs := make(map[[2]string]int)
+	for i, a := range sorted {
+		aEnd := end(a)
+		for _, b := range sorted[i+1:] {
+			if b.DetectedAt.After(aEnd) {
+				break
+			}
+			if a.Path == b.Path || a.Session != b.Session {
+				continue
+			}
+			key := [2]string{a.Path, b.Path}
+			if key[0] > key[1] {
+				key[0], key[1] = key[1], key[0]
+			}
+			pairs[key]++
+		}
+	}
+	return pairs
+}
+
+func sortPairs(pairs []ConflictPair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.A != b.A {
+			return a.A < b.A
+		}
+		return a.B < b.B
+	})
+}
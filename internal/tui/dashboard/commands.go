@@ -54,6 +54,8 @@ func (m *Model) fetchAlertsCmd() tea.Cmd {
 				cfg.Alerts.ResolvedPruneMinutes,
 				cfg.ProjectsBase,
 			)
+			alertCfg.AgentCPUPercent = cfg.Alerts.AgentCPUPercent
+			alertCfg.AgentMemoryPercent = cfg.Alerts.AgentMemoryPercent
 		} else {
 			alertCfg = alerts.DefaultConfig()
 		}
@@ -99,6 +101,32 @@ func (m *Model) fetchHistoryCmd() tea.Cmd {
 	}
 }
 
+// conflictStatsWindow is how far back the dashboard analyzes conflict history.
+const conflictStatsWindow = 7 * 24 * time.Hour
+
+// fetchConflictStatsCmd analyzes the recorded conflicts of this session
+func (m *Model) fetchConflictStatsCmd() tea.Cmd {
+	gen := m.nextGen(refreshConflictStats)
+	session := m.session
+	return func() tea.Msg {
+		now := time.Now()
+		episodes, err := tracker.NewConflictHistory("").Episodes(now.Add(-conflictStatsWindow))
+		if err != nil {
+			return ConflictStatsUpdateMsg{Err: err, Gen: gen}
+		}
+		if session != "" {
+			filtered := episodes[:0]
+			for _, ep := range episodes {
+				if ep.Session == session {
+					filtered = append(filtered, ep)
+				}
+			}
+			episodes = filtered
+		}
+		return ConflictStatsUpdateMsg{Stats: tracker.AnalyzeConflicts(episodes, 0, now), Gen: gen}
+	}
+}
+
 // fetchFileChangesCmd queries tracker
 func (m *Model) fetchFileChangesCmd() tea.Cmd {
 	gen := m.nextGen(refreshFiles)
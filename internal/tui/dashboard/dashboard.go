@@ -49,6 +49,7 @@ import (
 	"github.com/Dicklesworthstone/ntm/internal/tui/styles"
 	synthtui "github.com/Dicklesworthstone/ntm/internal/tui/synthesizer"
 	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+	"github.com/Dicklesworthstone/ntm/internal/util"
 	"github.com/Dicklesworthstone/ntm/internal/watcher"
 )
 
@@ -172,6 +173,13 @@ type HistoryUpdateMsg struct {
 	Gen     uint64
 }
 
+// ConflictStatsUpdateMsg is sent when conflict history analytics are computed
+type ConflictStatsUpdateMsg struct {
+	Stats *tracker.ConflictStats
+	Err   error
+	Gen   uint64
+}
+
 // FileChangeMsg is sent when file changes are detected
 type FileChangeMsg struct {
 	Changes []tracker.RecordedFileChange
@@ -286,6 +294,11 @@ type PaneHealthInfo struct {
 	Issues       []string // Issue messages
 	RestartCount int      // Restarts in last hour
 	Uptime       int      // Seconds of uptime
+
+	// Resource usage of the pane's process tree (zero if not sampled)
+	CPUPercent    float64 // 100 = one core
+	MemoryBytes   int64   // Resident memory
+	MemoryPercent float64 // Share of system memory
 }
 
 // PanelID identifies a dashboard panel
@@ -326,6 +339,7 @@ const (
 	refreshDCG
 	refreshPendingRotations
 	refreshPTHealth
+	refreshConflictStats
 	refreshSourceCount
 )
 
@@ -392,6 +406,7 @@ type Model struct {
 	fetchingMetrics     bool
 	fetchingRouting     bool
 	fetchingHistory     bool
+	fetchingConflicts   bool
 	fetchingFileChanges bool
 	fetchingScan        bool
 	fetchingHandoff     bool
@@ -501,6 +516,7 @@ type Model struct {
 	tickerPanel          *panels.TickerPanel
 	spawnPanel           *panels.SpawnPanel
 	conflictsPanel       *panels.ConflictsPanel
+	conflictStatsPanel   *panels.ConflictStatsPanel
 	rotationConfirmPanel *panels.RotationConfirmPanel
 
 	// Data for new panels
@@ -636,6 +652,11 @@ type PaneStatus struct {
 	RestartCount  int      // Number of restarts in last hour
 	UptimeSeconds int      // Seconds since agent started (negative = uptime from tracker)
 
+	// Process tree resource usage (from health checks)
+	ProcessCPUPercent    float64 // 100 = one core
+	ProcessMemoryBytes   int64   // Resident memory of the pane's process tree
+	ProcessMemoryPercent float64 // Share of system memory
+
 	// Rotation tracking
 	IsRotating bool       // True when agent rotation is in progress
 	RotatedAt  *time.Time // When agent was last rotated (nil if never)
@@ -819,6 +840,7 @@ func New(session, projectDir string) Model {
 		tickerPanel:          panels.NewTickerPanel(),
 		spawnPanel:           panels.NewSpawnPanel(),
 		conflictsPanel:       panels.NewConflictsPanel(),
+		conflictStatsPanel:   panels.NewConflictStatsPanel(),
 		rotationConfirmPanel: panels.NewRotationConfirmPanel(),
 
 		// Init() kicks off these fetches immediately; mark as fetching so the tick loop
@@ -831,6 +853,7 @@ func New(session, projectDir string) Model {
 		fetchingMetrics:     true,
 		fetchingRouting:     true,
 		fetchingHistory:     true,
+		fetchingConflicts:   true,
 		fetchingFileChanges: true,
 		fetchingCheckpoint:  true,
 		fetchingHandoff:     true,
@@ -924,6 +947,7 @@ func (m Model) Init() tea.Cmd {
 		m.fetchMetricsCmd(),
 		m.fetchRoutingCmd(),
 		m.fetchHistoryCmd(),
+		m.fetchConflictStatsCmd(),
 		m.fetchFileChangesCmd(),
 		m.fetchCASSContextCmd(),
 		m.fetchCheckpointStatus(),
@@ -1952,6 +1976,10 @@ func (m *Model) fullRefresh(cancelInFlight bool) []tea.Cmd {
 		m.fetchingHistory = true
 		cmds = append(cmds, m.fetchHistoryCmd())
 	}
+	if !m.fetchingConflicts {
+		m.fetchingConflicts = true
+		cmds = append(cmds, m.fetchConflictStatsCmd())
+	}
 	if !m.fetchingFileChanges {
 		m.fetchingFileChanges = true
 		cmds = append(cmds, m.fetchFileChangesCmd())
@@ -2252,6 +2280,12 @@ func (m Model) fetchHealthCmd() tea.Cmd {
 			info.Uptime = int(tracker.GetUptime(agent.PaneID).Seconds())
 			info.RestartCount = tracker.GetRestartsInWindow(agent.PaneID)
 
+			if r := agent.Resources; r != nil {
+				info.CPUPercent = r.CPUPercent
+				info.MemoryBytes = int64(r.RSSBytes)
+				info.MemoryPercent = r.MemPercent
+			}
+
 			healthMap[agent.PaneID] = info
 		}
 
@@ -2400,6 +2434,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Remove the conflict from the panel if action was successful or dismissed
 		if msg.Err == nil {
 			m.conflictsPanel.RemoveConflict(msg.Conflict.Path, msg.Conflict.RequestorAgent)
+			return m, m.recordConflictResolutionCmd(msg.Conflict, msg.Action)
 		}
 		return m, nil
 
@@ -2470,6 +2505,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.historyPanel.SetEntries(m.cmdHistory, m.historyError)
 		return m, nil
 
+	case ConflictStatsUpdateMsg:
+		if !m.acceptUpdate(refreshConflictStats, msg.Gen) {
+			return m, nil
+		}
+		m.fetchingConflicts = false
+		if m.conflictStatsPanel != nil {
+			m.conflictStatsPanel.SetData(msg.Stats, msg.Err)
+		}
+		if msg.Err == nil {
+			m.markUpdated(refreshConflictStats, time.Now())
+		}
+		return m, nil
+
 	case FileChangeMsg:
 		if !m.acceptUpdate(refreshFiles, msg.Gen) {
 			return m, nil
@@ -2947,6 +2995,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				ps.HealthIssues = healthInfo.Issues
 				ps.RestartCount = healthInfo.RestartCount
 				ps.UptimeSeconds = healthInfo.Uptime
+				ps.ProcessCPUPercent = healthInfo.CPUPercent
+				ps.ProcessMemoryBytes = healthInfo.MemoryBytes
+				ps.ProcessMemoryPercent = healthInfo.MemoryPercent
 				m.paneStatus[idx] = ps
 			}
 		}
@@ -5023,6 +5074,18 @@ func (m Model) renderPaneGrid() string {
 				cardContent.WriteString(restartBadge + "\n")
 			}
 
+			// Process tree CPU and memory, colored when an agent runs away
+			if ps.ProcessMemoryBytes > 0 {
+				usageColor := t.Subtext
+				if ps.ProcessCPUPercent >= 300 || ps.ProcessMemoryPercent >= 50 {
+					usageColor = t.Red
+				} else if ps.ProcessCPUPercent >= 100 || ps.ProcessMemoryPercent >= 25 {
+					usageColor = t.Yellow
+				}
+				usage := fmt.Sprintf("%.0f%% CPU · %s", ps.ProcessCPUPercent, util.FormatBytes(ps.ProcessMemoryBytes))
+				cardContent.WriteString(cachedStyledText(usage, usageColor, false, false) + "\n")
+			}
+
 			// Show first health issue as tooltip
 			if len(ps.HealthIssues) > 0 && showExtendedInfo {
 				issue := layout.TruncateWidthDefault(ps.HealthIssues[0], maxInt(cardWidth-4, 10))
@@ -5765,6 +5828,10 @@ func (m *Model) scheduleRefreshes(now time.Time) []tea.Cmd {
 			m.fetchingHistory = true
 			cmds = append(cmds, m.fetchHistoryCmd())
 		}
+		if !m.fetchingConflicts {
+			m.fetchingConflicts = true
+			cmds = append(cmds, m.fetchConflictStatsCmd())
+		}
 		if !m.fetchingFileChanges {
 			m.fetchingFileChanges = true
 			cmds = append(cmds, m.fetchFileChangesCmd())
@@ -6117,6 +6184,26 @@ func (m Model) renderSidebar(width, height int) string {
 		}
 	}
 
+	// Conflict history (best-effort, height-gated)
+	if m.conflictStatsPanel != nil && height > 0 && m.conflictStatsPanel.HasData() {
+		used := lipgloss.Height(strings.Join(lines, "\n"))
+		spacer := 1
+		panelHeight := height - used - spacer
+		if panelHeight >= m.conflictStatsPanel.Config().MinHeight {
+			if panelHeight > 16 {
+				panelHeight = 16
+			}
+
+			if m.focusedPanel == PanelSidebar {
+				m.conflictStatsPanel.Focus()
+			} else {
+				m.conflictStatsPanel.Blur()
+			}
+			m.conflictStatsPanel.SetSize(width, panelHeight)
+			lines = append(lines, "", m.conflictStatsPanel.View())
+		}
+	}
+
 	// File activity (best-effort, height-gated)
 	if m.filesPanel != nil && height > 0 && (len(m.fileChanges) > 0 || m.fileChangesError != nil) {
 		used := lipgloss.Height(strings.Join(lines, "\n"))
@@ -6675,6 +6762,18 @@ func activitySummaryLine(rows []PaneTableRow, t theme.Theme) string {
 	return label.Render("Activity:") + " " + strings.Join(compactBadges, " ")
 }
 
+// recordConflictResolutionCmd records how a reservation conflict was
+// resolved from the conflicts panel.
+func (m *Model) recordConflictResolutionCmd(conflict watcher.FileConflict, action watcher.ConflictAction) tea.Cmd {
+	return func() tea.Msg {
+		_, err := tracker.NewConflictHistory("").RecordResolved(tracker.ConflictSourceReservation, conflict.SessionName, conflict.Path, action.String())
+		if err != nil {
+			log.Printf("[Dashboard] Recording conflict resolution: %v", err)
+		}
+		return nil
+	}
+}
+
 // handleConflictAction handles user actions on file reservation conflicts.
 // It integrates with Agent Mail to send messages or force-release reservations.
 func (m *Model) handleConflictAction(conflict watcher.FileConflict, action watcher.ConflictAction) error {
@@ -847,6 +847,15 @@ func RenderPaneDetail(pane tmux.Pane, ps PaneStatus, dims LayoutDimensions, t th
 		}
 	}
 
+	// Process tree resources (from health checks)
+	if ps.ProcessMemoryBytes > 0 {
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(t.Lavender).Render("Resources"))
+		lines = append(lines, "")
+		lines = append(lines, fmt.Sprintf("  %.0f%% CPU", ps.ProcessCPUPercent))
+		lines = append(lines, fmt.Sprintf("  %s RAM (%.1f%% of system)", util.FormatBytes(ps.ProcessMemoryBytes), ps.ProcessMemoryPercent))
+		lines = append(lines, "")
+	}
+
 	// Status section
 	lines = append(lines, lipgloss.NewStyle().Bold(true).Foreground(t.Lavender).Render("Status"))
 	lines = append(lines, "")
@@ -0,0 +1,200 @@
+package panels
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+	"github.com/Dicklesworthstone/ntm/internal/tui/components"
+	"github.com/Dicklesworthstone/ntm/internal/tui/layout"
+	"github.com/Dicklesworthstone/ntm/internal/tui/theme"
+)
+
+// conflictStatsRows caps each list so all three fit in a sidebar panel.
+const conflictStatsRows = 3
+
+func conflictStatsConfig() PanelConfig {
+	return PanelConfig{
+		ID:              "conflict_stats",
+		Title:           "Conflict History",
+		Priority:        PriorityNormal,
+		RefreshInterval: 30 * time.Second,
+		MinWidth:        30,
+		MinHeight:       8,
+		Collapsible:     true,
+	}
+}
+
+// ConflictStatsPanel summarizes recorded conflicts: how often they happen,
+// how long they take to resolve, and which files and agents keep colliding.
+type ConflictStatsPanel struct {
+	PanelBase
+	stats *tracker.ConflictStats
+	theme theme.Theme
+	err   error
+}
+
+// NewConflictStatsPanel creates a new conflict history panel.
+func NewConflictStatsPanel() *ConflictStatsPanel {
+	return &ConflictStatsPanel{
+		PanelBase: NewPanelBase(conflictStatsConfig()),
+		theme:     theme.Current(),
+	}
+}
+
+func (c *ConflictStatsPanel) Init() tea.Cmd { return nil }
+
+func (c *ConflictStatsPanel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	return c, nil
+}
+
+// SetData updates the panel with freshly computed stats.
+func (c *ConflictStatsPanel) SetData(stats *tracker.ConflictStats, err error) {
+	c.err = err
+	if err == nil {
+		c.stats = stats
+		c.SetLastUpdate(time.Now())
+	}
+}
+
+func (c *ConflictStatsPanel) HasError() bool { return c.err != nil }
+
+// HasData reports whether any conflict has been recorded.
+func (c *ConflictStatsPanel) HasData() bool {
+	return c.err != nil || (c.stats != nil && c.stats.Episodes > 0)
+}
+
+func (c *ConflictStatsPanel) View() string {
+	t := c.theme
+	w, h := c.Width(), c.Height()
+
+	borderColor := t.Surface1
+	bgColor := t.Base
+	if c.IsFocused() {
+		borderColor = t.Primary
+		bgColor = t.Surface0
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderColor).
+		Background(bgColor).
+		Width(w-2).
+		Height(h-2).
+		Padding(0, 1)
+
+	var content strings.Builder
+
+	title := c.Config().Title
+	if c.err != nil {
+		errorBadge := lipgloss.NewStyle().
+			Background(t.Red).
+			Foreground(t.Base).
+			Bold(true).
+			Padding(0, 1).
+			Render("!")
+		title = title + " " + errorBadge
+	} else if staleBadge := components.RenderStaleBadge(c.LastUpdate(), c.Config().RefreshInterval); staleBadge != "" {
+		title = title + " " + staleBadge
+	}
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(t.Lavender).
+		Border(lipgloss.NormalBorder(), false, false, true, false).
+		BorderForeground(t.Surface1).
+		Width(w - 4).
+		Align(lipgloss.Center)
+
+	content.WriteString(headerStyle.Render(title) + "\n")
+
+	if c.err != nil {
+		content.WriteString(components.ErrorState(c.err.Error(), "Waiting for refresh", w-4) + "\n")
+		return boxStyle.Render(FitToHeight(content.String(), h-4))
+	}
+
+	if c.stats == nil || c.stats.Episodes == 0 {
+		content.WriteString("\n" + components.RenderEmptyState(components.EmptyStateOptions{
+			Icon:        components.IconWaiting,
+			Title:       "No conflicts recorded",
+			Description: "Conflicts are recorded as they are detected",
+			Width:       w - 4,
+			Centered:    true,
+		}))
+		return boxStyle.Render(FitToHeight(content.String(), h-4))
+	}
+
+	s := c.stats
+	innerWidth := w - 4
+	countW := 4
+	labelW := innerWidth - countW - 1
+	if labelW < 8 {
+		labelW = 8
+	}
+
+	summary := fmt.Sprintf("%d conflicts", s.Episodes)
+	if s.Open > 0 {
+		summary += fmt.Sprintf(" · %d open", s.Open)
+	}
+	if s.Resolved > 0 {
+		summary += " · MTTR " + formatConflictDuration(time.Duration(s.MeanResolutionSeconds*float64(time.Second)))
+	}
+	summaryColor := t.Text
+	if s.Open > 0 {
+		summaryColor = t.Yellow
+	}
+	content.WriteString(lipgloss.NewStyle().Foreground(summaryColor).Bold(true).Render(summary) + "\n")
+
+	section := func(name string, rows []string) {
+		if len(rows) == 0 {
+			return
+		}
+		content.WriteString(lipgloss.NewStyle().Foreground(t.Subtext).Render(name) + "\n")
+		for _, row := range rows {
+			content.WriteString(row + "\n")
+		}
+	}
+	row := func(count int, label string) string {
+		return lipgloss.NewStyle().Foreground(t.Peach).Render(padLeft(fmt.Sprintf("%d×", count), countW)) + " " +
+			lipgloss.NewStyle().Foreground(t.Text).Render(layout.TruncateMiddle(label, labelW))
+	}
+
+	var files, agents, filePairs []string
+	for i, f := range s.Files {
+		if i >= conflictStatsRows {
+			break
+		}
+		files = append(files, row(f.Count, f.Path))
+	}
+	for i, p := range s.AgentPairs {
+		if i >= conflictStatsRows {
+			break
+		}
+		agents = append(agents, row(p.Count, p.A+" ↔ "+p.B))
+	}
+	for i, p := range s.FilePairs {
+		if i >= conflictStatsRows {
+			break
+		}
+		filePairs = append(filePairs, row(p.Count, shortConflictPath(p.A)+" ↔ "+shortConflictPath(p.B)))
+	}
+	section("Hot files", files)
+	section("Agent pairs", agents)
+	section("Co-conflicting files", filePairs)
+
+	return boxStyle.Render(FitToHeight(content.String(), h-4))
+}
+
+// shortConflictPath keeps the last two path elements, which is usually
+// enough to tell files apart when two are shown on one line.
+func shortConflictPath(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) <= 2 {
+		return path
+	}
+	return strings.Join(parts[len(parts)-2:], "/")
+}
@@ -0,0 +1,50 @@
+package panels
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+func TestConflictStatsPanel_HasData(t *testing.T) {
+	panel := NewConflictStatsPanel()
+	if panel.Config().ID != "conflict_stats" {
+		t.Errorf("Expected ID 'conflict_stats', got %q", panel.Config().ID)
+	}
+	if panel.HasData() {
+		t.Error("new panel should have no data")
+	}
+
+	panel.SetData(&tracker.ConflictStats{}, nil)
+	if panel.HasData() {
+		t.Error("panel with zero episodes should have no data")
+	}
+
+	panel.SetData(nil, errors.New("boom"))
+	if !panel.HasData() || !panel.HasError() {
+		t.Error("panel with an error should be shown")
+	}
+}
+
+func TestConflictStatsPanel_View(t *testing.T) {
+	panel := NewConflictStatsPanel()
+	panel.SetSize(60, 16)
+	panel.SetData(&tracker.ConflictStats{
+		Episodes:              4,
+		Open:                  1,
+		Resolved:              3,
+		MeanResolutionSeconds: 300,
+		Files:                 []tracker.ConflictCount{{Path: "internal/api/handler.go", Count: 3}},
+		AgentPairs:            []tracker.ConflictPair{{A: "BlueLake", B: "RedStone", Count: 2}},
+		FilePairs:             []tracker.ConflictPair{{A: "internal/api/handler.go", B: "internal/api/routes.go", Count: 2}},
+	}, nil)
+
+	view := panel.View()
+	for _, want := range []string{"4 conflicts", "1 open", "MTTR 5m", "handler.go", "BlueLake ↔ RedStone", "api/routes.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
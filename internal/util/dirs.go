@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // NTMDir returns the path to the ~/.ntm directory.
@@ -17,6 +18,34 @@ func NTMDir() (string, error) {
 	return filepath.Join(home, ".ntm"), nil
 }
 
+var (
+	sessionsDirMu       sync.RWMutex
+	sessionsDirOverride string
+)
+
+// SetSessionsDir moves per-session state (assignments, prompt history) to
+// dir. An empty dir restores ~/.ntm/sessions.
+func SetSessionsDir(dir string) {
+	sessionsDirMu.Lock()
+	defer sessionsDirMu.Unlock()
+	sessionsDirOverride = dir
+}
+
+// SessionsDir returns the directory holding per-session state.
+func SessionsDir() (string, error) {
+	sessionsDirMu.RLock()
+	override := sessionsDirOverride
+	sessionsDirMu.RUnlock()
+	if override != "" {
+		return ExpandPath(override), nil
+	}
+	ntmDir, err := NTMDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(ntmDir, "sessions"), nil
+}
+
 // ExpandPath expands a leading "~/" (or "~\\") to the current user's home directory.
 //
 // It intentionally does not expand "~user/..." (which is shell-specific).
@@ -36,3 +36,17 @@ func TestExpandPath(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionsDirOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { SetSessionsDir("") })
+
+	if got, err := SessionsDir(); err != nil || got != filepath.Join(home, ".ntm", "sessions") {
+		t.Errorf("default SessionsDir = %q, %v", got, err)
+	}
+	SetSessionsDir("~/fast/state")
+	if got, _ := SessionsDir(); got != filepath.Join(home, "fast", "state") {
+		t.Errorf("SessionsDir = %q", got)
+	}
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LockFile takes an exclusive advisory lock guarding path, blocking until it
+// is available. The lock is held on a sidecar "<path>.lock" file so the data
+// file itself can be replaced by an atomic rename while locked. The lock is
+// per open file, so it also serializes goroutines within one process.
+//
+// On Unix this uses flock(2); on Windows, LockFileEx. The returned function
+// releases the lock.
+func LockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating lock directory: %w", err)
+	}
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// WithFileLock runs fn while holding the lock for path.
+func WithFileLock(path string, fn func() error) error {
+	unlock, err := LockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithFileLockSerializesReadModifyWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "counter.json")
+
+	const workers = 8
+	const iterations = 25
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				err := WithFileLock(path, func() error {
+					n := 0
+					if data, err := os.ReadFile(path); err == nil {
+						n, _ = strconv.Atoi(string(data))
+					}
+					return AtomicWriteFile(path, []byte(strconv.Itoa(n+1)), 0644)
+				})
+				if err != nil {
+					t.Errorf("WithFileLock: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), strconv.Itoa(workers*iterations); got != want {
+		t.Fatalf("counter = %s, want %s (lost updates)", got, want)
+	}
+}
+
+func TestLockFileCreateDirError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LockFile(filepath.Join(file, "sub", "x.json")); err == nil {
+		t.Fatal("expected error when parent is a file")
+	}
+}
@@ -0,0 +1,236 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// QuarantineFile is the quarantine list's file name under a project's .ntm
+// directory.
+const QuarantineFile = "flaky.json"
+
+// QuarantinePath returns the quarantine list path for a project.
+func QuarantinePath(projectDir string) string {
+	return filepath.Join(projectDir, ".ntm", QuarantineFile)
+}
+
+// FlakyCheck is a check seen to fail intermittently.
+type FlakyCheck struct {
+	Name          string     `json:"name"`
+	Command       string     `json:"command,omitempty"`
+	Flakes        int        `json:"flakes"` // Runs where it failed, then passed or failed differently
+	FirstSeen     time.Time  `json:"first_seen"`
+	LastSeen      time.Time  `json:"last_seen"`
+	Quarantined   bool       `json:"quarantined"`
+	QuarantinedAt *time.Time `json:"quarantined_at,omitempty"`
+	Reason        string     `json:"reason,omitempty"`
+}
+
+// Quarantine is a project's list of known-flaky checks. Failures of
+// quarantined checks are reported but do not fail verification.
+type Quarantine struct {
+	Checks map[string]*FlakyCheck `json:"checks"` // By check name
+
+	path string
+	base map[string]FlakyCheck // Checks as last loaded or saved
+}
+
+// LoadQuarantine reads the quarantine list at path. A missing file gives an
+// empty list.
+func LoadQuarantine(path string) (*Quarantine, error) {
+	checks, err := readQuarantine(path)
+	if err != nil {
+		return nil, err
+	}
+	q := &Quarantine{path: path}
+	q.adopt(checks)
+	return q, nil
+}
+
+// readQuarantine reads the checks listed at path. A missing file lists none.
+func readQuarantine(path string) (map[string]*FlakyCheck, error) {
+	var q Quarantine
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return make(map[string]*FlakyCheck), nil
+		}
+		return nil, fmt.Errorf("read quarantine list: %w", err)
+	}
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("parse quarantine list: %w", err)
+	}
+	if q.Checks == nil {
+		q.Checks = make(map[string]*FlakyCheck)
+	}
+	return q.Checks, nil
+}
+
+// adopt makes checks, as just read or written, the list's contents.
+func (q *Quarantine) adopt(checks map[string]*FlakyCheck) {
+	q.Checks = checks
+	q.base = make(map[string]FlakyCheck, len(checks))
+	for name, c := range checks {
+		q.base[name] = *c
+	}
+}
+
+// Save writes the quarantine list back to its file. Other ntm processes
+// record flakes to the same file, so under the file lock it re-reads the
+// file and merges in the changes made here since the list was loaded: checks
+// released here are removed, and a check changed here takes this list's
+// entry with the flakes counted here added to the file's count. It then
+// writes the result and adopts it.
+func (q *Quarantine) Save() error {
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return fmt.Errorf("create .ntm dir: %w", err)
+	}
+	return util.WithFileLock(q.path, func() error {
+		checks, err := readQuarantine(q.path)
+		if err != nil {
+			return err
+		}
+		q.merge(checks)
+
+		data, err := json.MarshalIndent(Quarantine{Checks: checks}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal quarantine list: %w", err)
+		}
+		if err := util.AtomicWriteFile(q.path, data, 0644); err != nil {
+			return fmt.Errorf("write quarantine list: %w", err)
+		}
+		q.adopt(checks)
+		return nil
+	})
+}
+
+// merge folds the changes made since the last load or save into checks,
+// the file's current contents.
+func (q *Quarantine) merge(checks map[string]*FlakyCheck) {
+	for name := range q.base {
+		if _, ok := q.Checks[name]; !ok {
+			delete(checks, name)
+		}
+	}
+	for name, c := range q.Checks {
+		base, known := q.base[name]
+		if known && *c == base {
+			continue
+		}
+		merged := *c
+		if disk, ok := checks[name]; ok {
+			merged.Flakes = disk.Flakes + c.Flakes - base.Flakes
+			if disk.FirstSeen.Before(merged.FirstSeen) {
+				merged.FirstSeen = disk.FirstSeen
+			}
+			if disk.Quarantined && !merged.Quarantined {
+				merged.Quarantined = true
+				merged.QuarantinedAt = disk.QuarantinedAt
+				merged.Reason = disk.Reason
+			}
+		}
+		checks[name] = &merged
+	}
+}
+
+// IsQuarantined reports whether the named check is quarantined.
+func (q *Quarantine) IsQuarantined(name string) bool {
+	c, ok := q.Checks[name]
+	return ok && c.Quarantined
+}
+
+// RecordFlake counts a flaky run of a check and quarantines it once it has
+// flaked threshold times (0 = never automatically). It reports whether this
+// flake quarantined the check.
+func (q *Quarantine) RecordFlake(name, command string, threshold int) bool {
+	now := time.Now().UTC()
+	c, ok := q.Checks[name]
+	if !ok {
+		c = &FlakyCheck{Name: name, FirstSeen: now}
+		q.Checks[name] = c
+	}
+	c.Command = command
+	c.Flakes++
+	c.LastSeen = now
+	if !c.Quarantined && threshold > 0 && c.Flakes >= threshold {
+		c.Quarantined = true
+		c.QuarantinedAt = &now
+		c.Reason = fmt.Sprintf("flaked %d times", c.Flakes)
+		return true
+	}
+	return false
+}
+
+// Add quarantines a check by hand.
+func (q *Quarantine) Add(name, reason string) {
+	now := time.Now().UTC()
+	c, ok := q.Checks[name]
+	if !ok {
+		c = &FlakyCheck{Name: name, FirstSeen: now, LastSeen: now}
+		q.Checks[name] = c
+	}
+	c.Quarantined = true
+	c.QuarantinedAt = &now
+	c.Reason = reason
+}
+
+// Release takes a check out of quarantine and resets its flake count. It
+// reports whether the check was listed.
+func (q *Quarantine) Release(name string) bool {
+	if _, ok := q.Checks[name]; !ok {
+		return false
+	}
+	delete(q.Checks, name)
+	return true
+}
+
+// List returns the listed checks, quarantined first, then by flake count.
+func (q *Quarantine) List() []FlakyCheck {
+	list := make([]FlakyCheck, 0, len(q.Checks))
+	for _, c := range q.Checks {
+		list = append(list, *c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Quarantined != list[j].Quarantined {
+			return list[i].Quarantined
+		}
+		if list[i].Flakes != list[j].Flakes {
+			return list[i].Flakes > list[j].Flakes
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+var (
+	failureLinePattern = regexp.MustCompile(`(?i)\b(fail|failed|failure|error|panic|assert|expected)\b`)
+	volatilePattern    = regexp.MustCompile(`0x[0-9a-fA-F]+|\d+(\.\d+)?(ns|µs|ms|s|m|h)?\b`)
+)
+
+// failureSignature reduces a failed check to what should repeat if the
+// failure is real: the exit code and its failure lines with numbers, times,
+// and addresses masked. Without failure lines the output's last lines stand
+// in.
+func failureSignature(exitCode int, output string) string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if failureLinePattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		lines = strings.Split(lastLines(output, 5), "\n")
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(volatilePattern.ReplaceAllString(line, "#"))
+	}
+	return fmt.Sprintf("%d|%s", exitCode, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,290 @@
+// Package verify runs verification commands (tests, build, lint) against
+// work an agent reports as complete.
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Default runner settings.
+const (
+	DefaultTimeout     = 10 * time.Minute
+	DefaultOutputLines = 40
+)
+
+// Check is one verification command.
+type Check struct {
+	Name    string `json:"name"`    // e.g. test, build, lint
+	Command string `json:"command"` // Run with sh -c in the project directory
+}
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Name       string `json:"name"`
+	Command    string `json:"command"`
+	Passed     bool   `json:"passed"`
+	ExitCode   int    `json:"exit_code"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	Output     string `json:"output,omitempty"` // Last lines of combined output; the failed attempt's for a flaky pass
+
+	Attempts    int  `json:"attempts,omitempty"`
+	Flaky       bool `json:"flaky,omitempty"`       // Passed on retry, or failed differently each time
+	Quarantined bool `json:"quarantined,omitempty"` // On the project's quarantine list; failures do not count
+}
+
+// Result is the outcome of a verification run.
+type Result struct {
+	Passed           bool          `json:"passed"`
+	Checks           []CheckResult `json:"checks"`
+	NewlyQuarantined []string      `json:"newly_quarantined,omitempty"` // Checks this run quarantined
+	Warnings         []string      `json:"warnings,omitempty"`
+	StartedAt        time.Time     `json:"started_at"`
+	FinishedAt       time.Time     `json:"finished_at"`
+}
+
+// Failed returns the failed checks that fail verification, i.e. those not
+// quarantined.
+func (r *Result) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, c := range r.Checks {
+		if !c.Passed && !c.Quarantined {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Flaky returns the checks whose outcome is noise: flaky this run, or
+// quarantined and failing.
+func (r *Result) Flaky() []CheckResult {
+	var flaky []CheckResult
+	for _, c := range r.Checks {
+		if c.Flaky || (c.Quarantined && !c.Passed) {
+			flaky = append(flaky, c)
+		}
+	}
+	return flaky
+}
+
+// scored returns the checks that count toward an agent's score: those that
+// are neither flaky nor quarantined.
+func (r *Result) scored() []CheckResult {
+	var scored []CheckResult
+	for _, c := range r.Checks {
+		if !c.Flaky && !c.Quarantined {
+			scored = append(scored, c)
+		}
+	}
+	return scored
+}
+
+// PassRate returns the fraction of scored checks that passed (1 with none).
+// Flaky and quarantined checks are left out so noise does not count
+// against the agent.
+func (r *Result) PassRate() float64 {
+	scored := r.scored()
+	if len(scored) == 0 {
+		return 1
+	}
+	return float64(len(scored)-r.Errors()) / float64(len(scored))
+}
+
+// Errors returns how many scored checks failed.
+func (r *Result) Errors() int {
+	n := 0
+	for _, c := range r.scored() {
+		if !c.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// FailedNames returns the names of the failed checks, comma-separated.
+func (r *Result) FailedNames() string {
+	var names []string
+	for _, c := range r.Failed() {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// BouncePrompt builds the message that sends a failed task back to its
+// agent with the failing output.
+func (r *Result) BouncePrompt(beadID string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Verification failed for %s. Fix the problems below, then finish the task again.\n", beadID)
+	for _, c := range r.Failed() {
+		status := fmt.Sprintf("exit %d", c.ExitCode)
+		if c.TimedOut {
+			status = "timed out"
+		}
+		if c.Flaky {
+			status += ", failed differently on retry, may be flaky"
+		}
+		fmt.Fprintf(&b, "\n%s (`%s`, %s):\n", c.Name, c.Command, status)
+		if c.Output != "" {
+			b.WriteString(c.Output)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Runner runs checks as subprocesses, one after another. Each runs in its
+// own process group so a timeout stops everything it started.
+//
+// A failed check is retried up to Retries times. Passing on retry, or
+// failing differently, marks it flaky; flakes are counted in the quarantine
+// list, and a check is quarantined after QuarantineAfter of them.
+type Runner struct {
+	Dir         string        // Working directory (the project)
+	Checks      []Check       // Run in order
+	Timeout     time.Duration // Per check (default DefaultTimeout)
+	OutputLines int           // Output lines kept per check (default DefaultOutputLines)
+	Env         []string      // Added to the environment, KEY=value
+	StopOnFail  bool          // Skip the remaining checks after a failure
+
+	Retries         int    // Reruns of a failed check
+	QuarantinePath  string // Quarantine list ("" = none)
+	QuarantineAfter int    // Flakes before a check is quarantined (0 = only by hand)
+}
+
+// Run runs the checks and returns their results. A run with no checks passes.
+func (r *Runner) Run(ctx context.Context) *Result {
+	result := &Result{Passed: true, Checks: []CheckResult{}, StartedAt: time.Now().UTC()}
+
+	var quarantine *Quarantine
+	if r.QuarantinePath != "" {
+		q, err := LoadQuarantine(r.QuarantinePath)
+		if err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+		quarantine = q
+	}
+	flaked := false
+
+	for _, check := range r.Checks {
+		if ctx.Err() != nil {
+			break
+		}
+		quarantined := quarantine != nil && quarantine.IsQuarantined(check.Name)
+		retries := r.Retries
+		if quarantined {
+			retries = 0 // Its outcome does not count; no need to confirm it
+		}
+		res := r.runWithRetries(ctx, check, retries)
+		res.Quarantined = quarantined
+		if res.Flaky && quarantine != nil {
+			flaked = true
+			if quarantine.RecordFlake(check.Name, check.Command, r.QuarantineAfter) {
+				result.NewlyQuarantined = append(result.NewlyQuarantined, check.Name)
+				res.Quarantined = true
+			}
+		}
+		result.Checks = append(result.Checks, res)
+		if !res.Passed && !res.Quarantined {
+			result.Passed = false
+			if r.StopOnFail {
+				break
+			}
+		}
+	}
+	if ctx.Err() != nil && len(result.Checks) < len(r.Checks) {
+		result.Passed = false
+	}
+	if flaked {
+		if err := quarantine.Save(); err != nil {
+			result.Warnings = append(result.Warnings, err.Error())
+		}
+	}
+	result.FinishedAt = time.Now().UTC()
+	return result
+}
+
+// runWithRetries runs a check, rerunning it while it fails, and classifies
+// it: the same failure every time is real; a pass on retry or a different
+// failure is flaky.
+func (r *Runner) runWithRetries(ctx context.Context, check Check, retries int) CheckResult {
+	res := r.runCheck(ctx, check)
+	res.Attempts = 1
+	for attempt := 1; attempt <= retries && !res.Passed && ctx.Err() == nil; attempt++ {
+		retry := r.runCheck(ctx, check)
+		retry.Attempts = res.Attempts + 1
+		retry.DurationMs += res.DurationMs
+		if retry.Passed {
+			retry.Flaky = true
+			retry.Output = res.Output
+			return retry
+		}
+		retry.Flaky = res.Flaky || failureSignature(retry.ExitCode, retry.Output) != failureSignature(res.ExitCode, res.Output)
+		res = retry
+	}
+	return res
+}
+
+func (r *Runner) runCheck(ctx context.Context, check Check) CheckResult {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	lines := r.OutputLines
+	if lines <= 0 {
+		lines = DefaultOutputLines
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(checkCtx, "sh", "-c", check.Command)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), r.Env...)
+	isolate(cmd)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	start := time.Now()
+	err := cmd.Run()
+	res := CheckResult{
+		Name:       check.Name,
+		Command:    check.Command,
+		Passed:     err == nil,
+		DurationMs: time.Since(start).Milliseconds(),
+		Output:     lastLines(out.String(), lines),
+	}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(checkCtx.Err(), context.DeadlineExceeded):
+		res.TimedOut = true
+		res.ExitCode = -1
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+	default:
+		res.ExitCode = -1
+		res.Output = strings.TrimSpace(res.Output + "\n" + err.Error())
+	}
+	return res
+}
+
+// lastLines returns the last n lines of s, ignoring trailing newlines.
+func lastLines(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,217 @@
+//go:build unix
+
+package verify
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunnerRun(t *testing.T) {
+	r := &Runner{
+		Dir: t.TempDir(),
+		Checks: []Check{
+			{Name: "build", Command: "echo building"},
+			{Name: "test", Command: "printf 'a\\nb\\nc\\nFAIL: TestX\\n'; exit 3"},
+			{Name: "lint", Command: "true"},
+		},
+		OutputLines: 2,
+	}
+	res := r.Run(context.Background())
+	if res.Passed {
+		t.Fatal("expected run to fail")
+	}
+	if len(res.Checks) != 3 {
+		t.Fatalf("checks = %d, want 3", len(res.Checks))
+	}
+	test := res.Checks[1]
+	if test.Passed || test.ExitCode != 3 {
+		t.Errorf("test check = %+v, want exit 3", test)
+	}
+	if test.Output != "c\nFAIL: TestX" {
+		t.Errorf("output = %q, want last 2 lines", test.Output)
+	}
+	if got := res.FailedNames(); got != "test" {
+		t.Errorf("FailedNames = %q", got)
+	}
+	if got := res.PassRate(); got < 0.66 || got > 0.67 {
+		t.Errorf("PassRate = %v, want 2/3", got)
+	}
+	prompt := res.BouncePrompt("bd-7")
+	for _, want := range []string{"bd-7", "test (`", "exit 3", "FAIL: TestX"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("bounce prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "building") {
+		t.Error("bounce prompt should only include failed checks")
+	}
+}
+
+func TestRunnerStopOnFail(t *testing.T) {
+	r := &Runner{
+		Checks:     []Check{{Name: "a", Command: "false"}, {Name: "b", Command: "true"}},
+		StopOnFail: true,
+	}
+	if res := r.Run(context.Background()); len(res.Checks) != 1 {
+		t.Errorf("checks = %d, want 1", len(res.Checks))
+	}
+}
+
+func TestRunnerTimeoutKillsGroup(t *testing.T) {
+	r := &Runner{
+		Checks:  []Check{{Name: "slow", Command: "sleep 30 & sleep 30"}},
+		Timeout: 200 * time.Millisecond,
+	}
+	start := time.Now()
+	res := r.Run(context.Background())
+	if time.Since(start) > 10*time.Second {
+		t.Fatal("timeout did not stop the check")
+	}
+	if res.Passed || !res.Checks[0].TimedOut {
+		t.Errorf("check = %+v, want timed out", res.Checks[0])
+	}
+}
+
+func TestRunnerNoChecks(t *testing.T) {
+	res := (&Runner{}).Run(context.Background())
+	if !res.Passed || res.PassRate() != 1 {
+		t.Errorf("empty run = %+v, want pass", res)
+	}
+}
+
+func TestRunnerRetryClassifiesFlakes(t *testing.T) {
+	dir := t.TempDir()
+	r := &Runner{
+		Dir: dir,
+		Checks: []Check{
+			{Name: "pass-on-retry", Command: "if [ -f a ]; then exit 0; fi; touch a; echo 'FAIL: TestA'; exit 1"},
+			{Name: "same-failure", Command: "echo \"--- FAIL: TestB ($$ ms)\"; exit 1"},
+			{Name: "different-failure", Command: "if [ -f c ]; then echo 'FAIL: TestD'; else touch c; echo 'FAIL: TestC'; fi; exit 1"},
+		},
+		Retries: 1,
+	}
+	res := r.Run(context.Background())
+
+	retried, same, different := res.Checks[0], res.Checks[1], res.Checks[2]
+	if !retried.Passed || !retried.Flaky || retried.Attempts != 2 || !strings.Contains(retried.Output, "TestA") {
+		t.Errorf("pass on retry = %+v, want flaky pass keeping the failed output", retried)
+	}
+	if same.Passed || same.Flaky || same.Attempts != 2 {
+		t.Errorf("same failure = %+v, want real failure", same)
+	}
+	if different.Passed || !different.Flaky {
+		t.Errorf("different failure = %+v, want flaky failure", different)
+	}
+	if res.Passed {
+		t.Error("failures outside quarantine should fail the run")
+	}
+	// Only same-failure is scored.
+	if got := res.PassRate(); got != 0 {
+		t.Errorf("PassRate = %v, want 0", got)
+	}
+	if got := res.Errors(); got != 1 {
+		t.Errorf("Errors = %d, want 1", got)
+	}
+	if got := len(res.Flaky()); got != 2 {
+		t.Errorf("Flaky = %d, want 2", got)
+	}
+}
+
+func TestRunnerQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	path := QuarantinePath(dir)
+	alternating := Check{Name: "test", Command: "if [ -f m ]; then rm m; exit 0; fi; touch m; echo boom; exit 1"}
+	r := &Runner{Dir: dir, Checks: []Check{alternating}, Retries: 1, QuarantinePath: path, QuarantineAfter: 2}
+
+	if res := r.Run(context.Background()); !res.Passed || len(res.NewlyQuarantined) != 0 {
+		t.Fatalf("first flake = %+v", res)
+	}
+	res := r.Run(context.Background())
+	if len(res.NewlyQuarantined) != 1 || res.NewlyQuarantined[0] != "test" || !res.Checks[0].Quarantined {
+		t.Fatalf("second flake should quarantine: %+v", res)
+	}
+
+	q, err := LoadQuarantine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.IsQuarantined("test") || q.Checks["test"].Flakes != 2 {
+		t.Fatalf("quarantine = %+v", q.Checks["test"])
+	}
+
+	// A quarantined check that fails does not fail verification or count.
+	r.Checks = []Check{{Name: "test", Command: "exit 1"}, {Name: "build", Command: "true"}}
+	res = r.Run(context.Background())
+	if !res.Passed || res.Checks[0].Attempts != 1 || res.PassRate() != 1 || len(res.Flaky()) != 1 {
+		t.Errorf("quarantined failure = %+v", res)
+	}
+
+	if !q.Release("test") || q.Release("test") {
+		t.Error("Release should remove the check once")
+	}
+	q.Add("lint", "known bad")
+	if err := q.Save(); err != nil {
+		t.Fatal(err)
+	}
+	q, _ = LoadQuarantine(path)
+	if list := q.List(); len(list) != 1 || list[0].Name != "lint" || list[0].Reason != "known bad" {
+		t.Errorf("list = %+v", list)
+	}
+}
+
+func TestQuarantineSaveMergesConcurrentLists(t *testing.T) {
+	path := QuarantinePath(t.TempDir())
+
+	// Two processes load the same list and record flakes independently.
+	a, err := LoadQuarantine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.RecordFlake("test", "go test", 0)
+	a.Add("lint", "known bad")
+	if err := a.Save(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := LoadQuarantine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.RecordFlake("test", "go test", 0)
+	b.RecordFlake("test", "go test", 0)
+	b.RecordFlake("e2e", "make e2e", 0)
+	b.Release("lint")
+	for _, q := range []*Quarantine{a, b, a} {
+		if err := q.Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := LoadQuarantine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := loaded.Checks["test"]; c == nil || c.Flakes != 3 {
+		t.Errorf("test = %+v, want both processes' flakes counted", c)
+	}
+	if loaded.Checks["e2e"] == nil {
+		t.Error("e2e recorded by b was lost")
+	}
+	if loaded.Checks["lint"] != nil {
+		t.Error("lint released by b came back")
+	}
+}
+
+func TestFailureSignatureMasksVolatileParts(t *testing.T) {
+	a := failureSignature(1, "--- FAIL: TestX (0.12s)\n    x_test.go:14: got 0xc000123 want 3")
+	b := failureSignature(1, "--- FAIL: TestX (3.40s)\n    x_test.go:14: got 0xc000999 want 3")
+	if a != b {
+		t.Errorf("signatures differ:\n%s\n%s", a, b)
+	}
+	if a == failureSignature(2, "--- FAIL: TestX (0.12s)\n    x_test.go:14: got 0xc000123 want 3") {
+		t.Error("exit code should be part of the signature")
+	}
+}
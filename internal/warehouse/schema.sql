@@ -0,0 +1,88 @@
+-- NTM analytics warehouse schema
+--
+-- analytics.db is derived data: every table is filled from ntm's own stores
+-- by `ntm sql` (or `ntm sql --sync`) and can be deleted and rebuilt at any
+-- time. Times are UTC text in the form 2006-01-02T15:04:05.000000000Z, so they
+-- sort correctly and work with SQLite's date and time functions.
+
+-- sync_state records how far each source has been ingested.
+CREATE TABLE IF NOT EXISTS sync_state (
+    source     TEXT PRIMARY KEY,        -- costs, scores, conflicts, rate_limits, audit
+    watermark  TEXT,                    -- newest record time ingested (NULL = never)
+    synced_at  TEXT NOT NULL,           -- when the source was last synced
+    rows_added INTEGER NOT NULL DEFAULT 0 -- rows inserted or updated by the last sync
+);
+
+-- costs: token usage and spend per agent pane, from <project>/.ntm/costs.json.
+-- One row per pane; re-syncing updates it in place.
+CREATE TABLE IF NOT EXISTS costs (
+    project       TEXT NOT NULL,        -- project directory the cost file belongs to
+    session       TEXT NOT NULL,
+    pane          TEXT NOT NULL,
+    model         TEXT,
+    input_tokens  INTEGER NOT NULL,
+    output_tokens INTEGER NOT NULL,
+    cost_usd      REAL NOT NULL,        -- estimated from the model's pricing
+    last_updated  TEXT,
+    PRIMARY KEY (project, session, pane)
+);
+
+-- scores: agent effectiveness scores, from ~/.config/ntm/analytics/scores.jsonl.
+-- Metrics are 0-1 except the counts.
+CREATE TABLE IF NOT EXISTS scores (
+    timestamp        TEXT NOT NULL,
+    session          TEXT,
+    agent_type       TEXT,
+    agent_name       TEXT,
+    task_type        TEXT,
+    bead_id          TEXT,
+    completion       REAL,
+    quality          REAL,
+    efficiency       REAL,
+    overall          REAL,
+    prompts_used     INTEGER,
+    tokens_used      INTEGER,
+    duration_minutes INTEGER,
+    error_count      INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_scores_session ON scores(session);
+CREATE INDEX IF NOT EXISTS idx_scores_agent_type ON scores(agent_type);
+
+-- conflicts: file conflict episodes from detection to resolution, from
+-- ~/.config/ntm/analytics/conflicts.jsonl. Open episodes have NULL
+-- resolved_at and are updated when they resolve.
+CREATE TABLE IF NOT EXISTS conflicts (
+    source           TEXT NOT NULL,     -- reservation or git
+    session          TEXT NOT NULL,
+    path             TEXT NOT NULL,
+    agents           TEXT,              -- comma-separated
+    reason           TEXT,
+    detected_at      TEXT NOT NULL,
+    resolved_at      TEXT,
+    outcome          TEXT,              -- wait, request, force, dismiss, cleared, expired
+    duration_seconds REAL,              -- time to resolution
+    PRIMARY KEY (source, session, path, detected_at)
+);
+
+-- rate_limits: agent.rate_limit events from the event journal
+-- (~/.config/ntm/analytics/bus_events.jsonl).
+CREATE TABLE IF NOT EXISTS rate_limits (
+    timestamp    TEXT NOT NULL,
+    session      TEXT,
+    pane         TEXT,
+    agent        TEXT,
+    wait_seconds INTEGER,               -- suggested wait, NULL if unknown
+    message      TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_rate_limits_session ON rate_limits(session);
+
+-- audit_daily: audit log entry counts per day, from
+-- ~/.local/share/ntm/audit. Payloads are not copied.
+CREATE TABLE IF NOT EXISTS audit_daily (
+    day        TEXT NOT NULL,           -- 2006-01-02 (UTC)
+    session    TEXT NOT NULL,
+    event_type TEXT NOT NULL,           -- command, spawn, send, response, error, ...
+    actor      TEXT NOT NULL,           -- user, agent or system
+    entries    INTEGER NOT NULL,
+    PRIMARY KEY (day, session, event_type, actor)
+);
@@ -0,0 +1,378 @@
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+// Warehouse sources.
+const (
+	SourceCosts      = "costs"
+	SourceScores     = "scores"
+	SourceConflicts  = "conflicts"
+	SourceRateLimits = "rate_limits"
+	SourceAudit      = "audit"
+)
+
+// Sources locates the stores a sync reads. Empty paths use each store's
+// default location. Costs are per project, so they are skipped when
+// ProjectDir is empty.
+type Sources struct {
+	ProjectDir    string
+	ScoresPath    string
+	ConflictsPath string
+	JournalPath   string
+	AuditDir      string
+}
+
+// SourceSync is the sync result for one source.
+type SourceSync struct {
+	Source    string     `json:"source"`
+	Rows      int        `json:"rows"`
+	Watermark *time.Time `json:"watermark,omitempty"`
+	Skipped   bool       `json:"skipped,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// SyncReport is the outcome of Sync.
+type SyncReport struct {
+	Path    string       `json:"path"`
+	Sources []SourceSync `json:"sources"`
+}
+
+// Failed returns the sources that could not be synced.
+func (r *SyncReport) Failed() []string {
+	var failed []string
+	for _, s := range r.Sources {
+		if s.Error != "" {
+			failed = append(failed, s.Source)
+		}
+	}
+	return failed
+}
+
+// syncFunc ingests records newer than since and returns the number of rows
+// written and the watermark to store.
+type syncFunc func(ctx context.Context, tx *sql.Tx, since time.Time) (int, time.Time, error)
+
+// Sync brings the warehouse up to date. Each source is read only from its
+// watermark onward and committed separately, so one unreadable store does
+// not hold back the others.
+func (w *Warehouse) Sync(ctx context.Context, src Sources) *SyncReport {
+	report := &SyncReport{Path: w.path}
+	steps := []struct {
+		name string
+		fn   syncFunc
+	}{
+		{SourceCosts, src.syncCosts},
+		{SourceScores, src.syncScores},
+		{SourceConflicts, src.syncConflicts},
+		{SourceRateLimits, src.syncRateLimits},
+		{SourceAudit, src.syncAudit},
+	}
+	for _, step := range steps {
+		if step.name == SourceCosts && src.ProjectDir == "" {
+			report.Sources = append(report.Sources, SourceSync{Source: step.name, Skipped: true})
+			continue
+		}
+		report.Sources = append(report.Sources, w.syncSource(ctx, step.name, step.fn))
+	}
+	return report
+}
+
+func (w *Warehouse) syncSource(ctx context.Context, name string, fn syncFunc) SourceSync {
+	result := SourceSync{Source: name}
+	fail := func(err error) SourceSync {
+		result.Error = err.Error()
+		return result
+	}
+
+	var since time.Time
+	var mark sql.NullString
+	err := w.db.QueryRowContext(ctx, `SELECT watermark FROM sync_state WHERE source = ?`, name).Scan(&mark)
+	if err != nil && err != sql.ErrNoRows {
+		return fail(fmt.Errorf("read watermark: %w", err))
+	}
+	if mark.Valid {
+		if since, err = time.Parse(timeLayout, mark.String); err != nil {
+			return fail(fmt.Errorf("parse watermark: %w", err))
+		}
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after commit
+
+	rows, newest, err := fn(ctx, tx, since)
+	if err != nil {
+		return fail(err)
+	}
+	if newest.IsZero() {
+		newest = since
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO sync_state (source, watermark, synced_at, rows_added) VALUES (?, ?, ?, ?)
+		ON CONFLICT(source) DO UPDATE SET watermark = excluded.watermark,
+			synced_at = excluded.synced_at, rows_added = excluded.rows_added`,
+		name, nullTime(newest), formatTime(time.Now()), rows); err != nil {
+		return fail(fmt.Errorf("update sync state: %w", err))
+	}
+	if err := tx.Commit(); err != nil {
+		return fail(err)
+	}
+
+	result.Rows = rows
+	if !newest.IsZero() {
+		result.Watermark = &newest
+	}
+	return result
+}
+
+// syncCosts replaces the project's cost rows with the current cost file,
+// which holds running totals rather than individual records.
+func (s Sources) syncCosts(ctx context.Context, tx *sql.Tx, _ time.Time) (int, time.Time, error) {
+	ct := cost.NewCostTracker(s.ProjectDir)
+	if err := ct.LoadFromDir(s.ProjectDir); err != nil {
+		return 0, time.Time{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM costs WHERE project = ?`, s.ProjectDir); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var newest time.Time
+	rows := 0
+	for _, session := range ct.GetAllSessions() {
+		sc := ct.GetSession(session)
+		if sc == nil {
+			continue
+		}
+		panes := make([]string, 0, len(sc.Agents))
+		for pane := range sc.Agents {
+			panes = append(panes, pane)
+		}
+		sort.Strings(panes)
+		for _, pane := range panes {
+			ac := sc.Agents[pane]
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO costs (project, session, pane, model, input_tokens, output_tokens, cost_usd, last_updated)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				s.ProjectDir, session, pane, ac.Model, ac.InputTokens, ac.OutputTokens, ac.Cost(), nullTime(ac.LastUpdated)); err != nil {
+				return rows, newest, fmt.Errorf("insert cost: %w", err)
+			}
+			rows++
+			if ac.LastUpdated.After(newest) {
+				newest = ac.LastUpdated
+			}
+		}
+	}
+	return rows, newest, nil
+}
+
+func (s Sources) syncScores(ctx context.Context, tx *sql.Tx, since time.Time) (int, time.Time, error) {
+	t, err := scoring.NewTracker(scoring.TrackerOptions{Path: s.ScoresPath, Enabled: true})
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	scores, err := t.QueryScores(scoring.Query{Since: since})
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	newest := since
+	for _, sc := range scores {
+		m := sc.Metrics
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO scores (timestamp, session, agent_type, agent_name, task_type, bead_id,
+				completion, quality, efficiency, overall, prompts_used, tokens_used, duration_minutes, error_count)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			formatTime(sc.Timestamp), sc.Session, sc.AgentType, sc.AgentName, sc.TaskType, sc.BeadID,
+			m.Completion, m.Quality, m.Efficiency, m.Overall, m.PromptsUsed, m.TokensUsed, m.DurationMinutes, m.ErrorCount); err != nil {
+			return 0, since, fmt.Errorf("insert score: %w", err)
+		}
+		if sc.Timestamp.After(newest) {
+			newest = sc.Timestamp
+		}
+	}
+	return len(scores), newest, nil
+}
+
+// syncConflicts upserts episodes detected since the watermark. The
+// watermark stays at the oldest episode still open so its resolution is
+// picked up by a later sync.
+func (s Sources) syncConflicts(ctx context.Context, tx *sql.Tx, since time.Time) (int, time.Time, error) {
+	episodes, err := tracker.NewConflictHistory(s.ConflictsPath).Episodes(since)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var newest, oldestOpen time.Time
+	for _, ep := range episodes {
+		var resolved, duration any
+		if ep.ResolvedAt != nil {
+			resolved = formatTime(*ep.ResolvedAt)
+			duration = ep.Duration().Seconds()
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO conflicts (source, session, path, agents, reason, detected_at, resolved_at, outcome, duration_seconds)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(source, session, path, detected_at) DO UPDATE SET agents = excluded.agents,
+				reason = excluded.reason, resolved_at = excluded.resolved_at,
+				outcome = excluded.outcome, duration_seconds = excluded.duration_seconds`,
+			ep.Source, ep.Session, ep.Path, strings.Join(ep.Agents, ","), ep.Reason,
+			formatTime(ep.DetectedAt), resolved, ep.Outcome, duration); err != nil {
+			return 0, since, fmt.Errorf("upsert conflict: %w", err)
+		}
+		if ep.DetectedAt.After(newest) {
+			newest = ep.DetectedAt
+		}
+		if ep.Open() && (oldestOpen.IsZero() || ep.DetectedAt.Before(oldestOpen)) {
+			oldestOpen = ep.DetectedAt
+		}
+	}
+	if !oldestOpen.IsZero() {
+		newest = oldestOpen
+	}
+	return len(episodes), newest, nil
+}
+
+func (s Sources) syncRateLimits(ctx context.Context, tx *sql.Tx, since time.Time) (int, time.Time, error) {
+	entries, err := events.ReadJournal(s.JournalPath, events.JournalFilter{
+		From:  since,
+		Types: []string{events.WebhookAgentRateLimit},
+	})
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	newest := since
+	rows := 0
+	for _, entry := range entries {
+		if !entry.Timestamp.After(since) {
+			continue
+		}
+		ev, ok := entry.Decode().(events.WebhookEvent)
+		if !ok {
+			continue
+		}
+		var wait any
+		if secs, err := strconv.Atoi(ev.Details["wait_seconds"]); err == nil {
+			wait = secs
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO rate_limits (timestamp, session, pane, agent, wait_seconds, message)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			formatTime(entry.Timestamp), entry.Session, ev.Pane, ev.Agent, wait, ev.Message); err != nil {
+			return rows, since, fmt.Errorf("insert rate limit: %w", err)
+		}
+		rows++
+		if entry.Timestamp.After(newest) {
+			newest = entry.Timestamp
+		}
+	}
+	return rows, newest, nil
+}
+
+// syncAudit adds the counts of audit entries logged since the watermark to
+// the daily summary.
+func (s Sources) syncAudit(ctx context.Context, tx *sql.Tx, since time.Time) (int, time.Time, error) {
+	var searcher *audit.Searcher
+	if s.AuditDir != "" {
+		searcher = audit.NewSearcherWithPath(s.AuditDir)
+	} else {
+		var err error
+		if searcher, err = audit.NewSearcher(); err != nil {
+			return 0, time.Time{}, err
+		}
+	}
+	q := audit.Query{Limit: math.MaxInt}
+	if !since.IsZero() {
+		q.Since = &since
+	}
+	results, err := searcher.StreamSearch(ctx, q)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	type key struct{ day, session, eventType, actor string }
+	counts := make(map[key]int)
+	newest := since
+	total := 0
+	for res := range results {
+		if res.Err != nil {
+			return 0, since, res.Err
+		}
+		entry := res.Entry
+		if !entry.Timestamp.After(since) {
+			continue
+		}
+		counts[key{entry.Timestamp.UTC().Format("2006-01-02"), entry.SessionID, string(entry.EventType), string(entry.Actor)}]++
+		total++
+		if entry.Timestamp.After(newest) {
+			newest = entry.Timestamp
+		}
+	}
+
+	for k, n := range counts {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO audit_daily (day, session, event_type, actor, entries) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(day, session, event_type, actor) DO UPDATE SET entries = entries + excluded.entries`,
+			k.day, k.session, k.eventType, k.actor, n); err != nil {
+			return 0, since, fmt.Errorf("upsert audit summary: %w", err)
+		}
+	}
+	return total, newest, nil
+}
+
+// PurgeSession removes a session's rows, or only those recorded before
+// before if it is non-zero, and returns the number removed. Purged records
+// are older than the sync watermarks, so they are not ingested again.
+func (w *Warehouse) PurgeSession(ctx context.Context, session string, before time.Time) (int, error) {
+	tables := []struct{ table, timeCol string }{
+		{"costs", "last_updated"},
+		{"scores", "timestamp"},
+		{"conflicts", "detected_at"},
+		{"rate_limits", "timestamp"},
+		{"audit_daily", "day"},
+	}
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }() // no-op after commit
+
+	removed := 0
+	for _, t := range tables {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE session = ?`, t.table)
+		args := []any{session}
+		if !before.IsZero() {
+			cutoff := formatTime(before)
+			if t.table == "audit_daily" {
+				// Only whole days before the cutoff.
+				cutoff = before.UTC().Format("2006-01-02")
+			}
+			query += fmt.Sprintf(` AND %s < ?`, t.timeCol)
+			args = append(args, cutoff)
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("purge %s: %w", t.table, err)
+		}
+		n, _ := res.RowsAffected()
+		removed += int(n)
+	}
+	return removed, tx.Commit()
+}
@@ -0,0 +1,140 @@
+// Package warehouse maintains analytics.db, a SQLite database that gathers
+// costs, effectiveness scores, conflicts, rate-limit events and audit
+// summaries from ntm's individual stores so they can be queried with SQL.
+package warehouse
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// DefaultPath is where the warehouse lives unless overridden.
+const DefaultPath = "~/.config/ntm/analytics/analytics.db"
+
+// timeLayout is the fixed-width UTC layout used for every stored time, so
+// text comparison orders times correctly.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// schemaVersion is stored in PRAGMA user_version.
+const schemaVersion = 1
+
+//go:embed schema.sql
+var schema string
+
+// Schema returns the documented warehouse schema.
+func Schema() string {
+	return schema
+}
+
+// Warehouse is an open analytics database.
+type Warehouse struct {
+	db   *sql.DB
+	path string
+}
+
+// Open opens or creates the warehouse at path (DefaultPath if empty) and
+// ensures its schema exists.
+func Open(path string) (*Warehouse, error) {
+	if path == "" {
+		path = DefaultPath
+	}
+	path = util.ExpandPath(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create analytics dir: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("%s?_journal_mode=WAL&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, fmt.Errorf("open warehouse: %w", err)
+	}
+	// A single connection keeps syncs serialized within the process; the
+	// busy timeout covers other ntm processes.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create warehouse schema: %w", err)
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("set schema version: %w", err)
+	}
+	return &Warehouse{db: db, path: path}, nil
+}
+
+// Close closes the database.
+func (w *Warehouse) Close() error {
+	return w.db.Close()
+}
+
+// Path returns the database file path.
+func (w *Warehouse) Path() string {
+	return w.path
+}
+
+// Result is the outcome of a query.
+type Result struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// Query runs SQL against a read-only connection, so ad-hoc analysis cannot
+// modify the warehouse.
+func (w *Warehouse) Query(ctx context.Context, query string, args ...any) (*Result, error) {
+	ro, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro&_busy_timeout=5000", w.path))
+	if err != nil {
+		return nil, fmt.Errorf("open warehouse read-only: %w", err)
+	}
+	defer ro.Close()
+
+	rows, err := ro.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Columns: cols, Rows: [][]any{}}
+	for rows.Next() {
+		values := make([]any, len(cols))
+		ptrs := make([]any, len(cols))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			// TEXT columns scan as []byte; present them as strings.
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		res.Rows = append(res.Rows, values)
+	}
+	return res, rows.Err()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return formatTime(t)
+}
@@ -0,0 +1,247 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/audit"
+	"github.com/Dicklesworthstone/ntm/internal/cost"
+	"github.com/Dicklesworthstone/ntm/internal/events"
+	"github.com/Dicklesworthstone/ntm/internal/scoring"
+	"github.com/Dicklesworthstone/ntm/internal/tracker"
+)
+
+type testSources struct {
+	Sources
+	scores    *scoring.Tracker
+	conflicts *tracker.ConflictHistory
+	journal   *events.Journal
+}
+
+func newTestSources(t *testing.T) *testSources {
+	t.Helper()
+	dir := t.TempDir()
+	src := Sources{
+		ProjectDir:    filepath.Join(dir, "project"),
+		ScoresPath:    filepath.Join(dir, "scores.jsonl"),
+		ConflictsPath: filepath.Join(dir, "conflicts.jsonl"),
+		JournalPath:   filepath.Join(dir, "bus_events.jsonl"),
+		AuditDir:      filepath.Join(dir, "audit"),
+	}
+	scores, err := scoring.NewTracker(scoring.TrackerOptions{Path: src.ScoresPath, Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal := events.NewJournal(src.JournalPath)
+	t.Cleanup(func() { journal.Close() })
+	return &testSources{
+		Sources:   src,
+		scores:    scores,
+		conflicts: tracker.NewConflictHistory(src.ConflictsPath),
+		journal:   journal,
+	}
+}
+
+func (s *testSources) rateLimit(t *testing.T, session string, at time.Time, wait string) {
+	t.Helper()
+	ev := events.NewWebhookEvent(events.WebhookAgentRateLimit, session, "%3", "claude", "rate limited", map[string]string{"wait_seconds": wait})
+	ev.Timestamp = at
+	if err := s.journal.Append(ev); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (s *testSources) audit(t *testing.T, entries ...audit.AuditEntry) {
+	t.Helper()
+	if err := os.MkdirAll(s.AuditDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		name := filepath.Join(s.AuditDir, e.SessionID+"-"+e.Timestamp.Format("2006-01-02")+".jsonl")
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewEncoder(f).Encode(e); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func openTest(t *testing.T) *Warehouse {
+	t.Helper()
+	w, err := Open(filepath.Join(t.TempDir(), "analytics.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func queryInt(t *testing.T, w *Warehouse, query string) int64 {
+	t.Helper()
+	res, err := w.Query(context.Background(), query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	if len(res.Rows) != 1 || len(res.Rows[0]) != 1 {
+		t.Fatalf("%s: got %v", query, res.Rows)
+	}
+	n, _ := res.Rows[0][0].(int64)
+	return n
+}
+
+func TestSyncIncremental(t *testing.T) {
+	ctx := context.Background()
+	src := newTestSources(t)
+	w := openTest(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	ct := cost.NewCostTracker(src.ProjectDir)
+	ct.RecordTokens("proj", "%1", "claude-sonnet-4", 1000, 500)
+	if err := ct.SaveToDir(src.ProjectDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.scores.Record(&scoring.Score{Timestamp: now.Add(-time.Hour), Session: "proj", AgentType: "claude", Metrics: scoring.ScoreMetrics{Completion: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := src.conflicts.RecordDetected(tracker.ConflictRecord{Source: tracker.ConflictSourceGit, Session: "proj", Path: "main.go", Agents: []string{"cc_1", "cod_1"}}); err != nil {
+		t.Fatal(err)
+	}
+	src.rateLimit(t, "proj", now.Add(-time.Hour), "60")
+	src.audit(t,
+		audit.AuditEntry{Timestamp: now.Add(-time.Hour), SessionID: "proj", EventType: audit.EventTypeSend, Actor: audit.ActorUser},
+		audit.AuditEntry{Timestamp: now.Add(-time.Hour).Add(time.Second), SessionID: "proj", EventType: audit.EventTypeSend, Actor: audit.ActorUser},
+	)
+
+	report := w.Sync(ctx, src.Sources)
+	if failed := report.Failed(); len(failed) != 0 {
+		t.Fatalf("failed sources %v: %+v", failed, report.Sources)
+	}
+	for table, want := range map[string]int64{"costs": 1, "scores": 1, "conflicts": 1, "rate_limits": 1} {
+		if got := queryInt(t, w, "SELECT COUNT(*) FROM "+table); got != want {
+			t.Errorf("%s rows = %d, want %d", table, got, want)
+		}
+	}
+	if got := queryInt(t, w, "SELECT SUM(entries) FROM audit_daily WHERE event_type = 'send'"); got != 2 {
+		t.Errorf("audit send entries = %d, want 2", got)
+	}
+	if got := queryInt(t, w, "SELECT wait_seconds FROM rate_limits"); got != 60 {
+		t.Errorf("wait_seconds = %d", got)
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM conflicts WHERE resolved_at IS NULL AND agents = 'cc_1,cod_1'"); got != 1 {
+		t.Errorf("open conflicts = %d, want 1", got)
+	}
+
+	// A second sync only adds what is new, and picks up the resolution of
+	// the conflict that was open.
+	if _, err := src.conflicts.RecordResolved(tracker.ConflictSourceGit, "proj", "main.go", tracker.ConflictOutcomeCleared); err != nil {
+		t.Fatal(err)
+	}
+	src.rateLimit(t, "proj", now, "")
+	src.audit(t, audit.AuditEntry{Timestamp: now, SessionID: "proj", EventType: audit.EventTypeSend, Actor: audit.ActorUser})
+
+	report = w.Sync(ctx, src.Sources)
+	if failed := report.Failed(); len(failed) != 0 {
+		t.Fatalf("failed sources %v: %+v", failed, report.Sources)
+	}
+	for _, s := range report.Sources {
+		if s.Source == SourceScores && s.Rows != 0 {
+			t.Errorf("scores re-ingested %d rows", s.Rows)
+		}
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM scores"); got != 1 {
+		t.Errorf("scores rows = %d, want 1", got)
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM rate_limits"); got != 2 {
+		t.Errorf("rate_limits rows = %d, want 2", got)
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM rate_limits WHERE wait_seconds IS NULL"); got != 1 {
+		t.Errorf("rate limit without wait should store NULL")
+	}
+	if got := queryInt(t, w, "SELECT SUM(entries) FROM audit_daily"); got != 3 {
+		t.Errorf("audit entries = %d, want 3", got)
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM conflicts WHERE outcome = 'cleared' AND resolved_at IS NOT NULL"); got != 1 {
+		t.Errorf("conflict resolution not synced")
+	}
+}
+
+func TestSyncSkipsCostsWithoutProject(t *testing.T) {
+	src := newTestSources(t)
+	src.ProjectDir = ""
+	report := openTest(t).Sync(context.Background(), src.Sources)
+	if report.Sources[0].Source != SourceCosts || !report.Sources[0].Skipped {
+		t.Errorf("costs source = %+v, want skipped", report.Sources[0])
+	}
+}
+
+func TestQueryReadOnly(t *testing.T) {
+	w := openTest(t)
+	ctx := context.Background()
+
+	res, err := w.Query(ctx, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for _, row := range res.Rows {
+		tables = append(tables, row[0].(string))
+	}
+	if got := strings.Join(tables, ","); got != "audit_daily,conflicts,costs,rate_limits,scores,sync_state" {
+		t.Errorf("tables = %s", got)
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM scores",
+		"DROP TABLE costs",
+		"SELECT 1; DELETE FROM scores",
+	} {
+		if _, err := w.Query(ctx, stmt); err == nil {
+			t.Errorf("%q succeeded on read-only connection", stmt)
+		}
+	}
+}
+
+func TestPurgeSession(t *testing.T) {
+	ctx := context.Background()
+	src := newTestSources(t)
+	w := openTest(t)
+	now := time.Now().UTC()
+
+	src.rateLimit(t, "proj", now.Add(-48*time.Hour), "30")
+	src.rateLimit(t, "proj", now, "30")
+	src.rateLimit(t, "other", now, "30")
+	src.audit(t, audit.AuditEntry{Timestamp: now.Add(-48 * time.Hour), SessionID: "proj", EventType: audit.EventTypeSpawn, Actor: audit.ActorSystem})
+	w.Sync(ctx, src.Sources)
+
+	n, err := w.PurgeSession(ctx, "proj", now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("removed %d rows, want old rate limit and audit day", n)
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM rate_limits"); got != 2 {
+		t.Errorf("rate_limits rows = %d, want 2", got)
+	}
+
+	if _, err := w.PurgeSession(ctx, "proj", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM rate_limits WHERE session = 'proj'"); got != 0 {
+		t.Errorf("proj rows left after full purge: %d", got)
+	}
+
+	// Purged records are behind the watermark and stay gone.
+	w.Sync(ctx, src.Sources)
+	if got := queryInt(t, w, "SELECT COUNT(*) FROM rate_limits WHERE session = 'proj'"); got != 0 {
+		t.Errorf("purged rows re-ingested: %d", got)
+	}
+}
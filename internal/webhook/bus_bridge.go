@@ -197,14 +197,20 @@ func isWebhookDispatchType(eventType string) bool {
 		strings.ToLower(events.WebhookAgentIdle),
 		strings.ToLower(events.WebhookAgentBusy),
 		strings.ToLower(events.WebhookAgentRateLimit),
+		strings.ToLower(events.WebhookAgentResumed),
 		strings.ToLower(events.WebhookAgentCompleted),
+		strings.ToLower(events.WebhookAgentProgress),
 		strings.ToLower(events.WebhookRotationNeeded),
+		strings.ToLower(events.WebhookContextReset),
 		strings.ToLower(events.WebhookSessionCreated),
 		strings.ToLower(events.WebhookSessionKilled),
 		strings.ToLower(events.WebhookSessionEnded),
 		strings.ToLower(events.WebhookBeadAssigned),
 		strings.ToLower(events.WebhookBeadCompleted),
 		strings.ToLower(events.WebhookBeadFailed),
+		strings.ToLower(events.WebhookTaskCandidateComplete),
+		strings.ToLower(events.WebhookPromptUndelivered),
+		strings.ToLower(events.WebhookStorageLowDisk),
 		strings.ToLower(events.WebhookHealthDegraded):
 		return true
 	default:
@@ -254,6 +260,18 @@ func projectConfigToWebhookConfig(cfg config.WebhookConfig) (WebhookConfig, erro
 		out.Timeout = d
 	}
 
+	if interval := strings.TrimSpace(cfg.Digest.Interval); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return WebhookConfig{}, err
+		}
+		out.Digest = DigestConfig{
+			Interval:   d,
+			Severities: trimStrings(cfg.Digest.Severities),
+			MaxEvents:  cfg.Digest.MaxEvents,
+		}
+	}
+
 	// Retry policy (best-effort mapping; backoff strategy is currently fixed inside manager).
 	if cfg.Retry.MaxAttempts > 0 {
 		out.Retry = RetryConfig{
@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DigestEventType is the event type of a digest delivery.
+const DigestEventType = "digest"
+
+// DefaultDigestMaxEvents is how many events a digest holds before it is
+// flushed early.
+const DefaultDigestMaxEvents = 100
+
+// digestPreviewLines caps how many event messages are listed in a digest's
+// message text; all events are still included in Event.Digest.
+const digestPreviewLines = 10
+
+// DigestConfig batches low-severity events for a webhook into periodic
+// summaries. Events of other severities are delivered immediately.
+type DigestConfig struct {
+	// Interval is how long events are collected before a digest is sent.
+	// Zero disables digests.
+	Interval time.Duration `toml:"interval" json:"interval,omitempty"`
+	// Severities are the severities that are batched (default info, success).
+	Severities []string `toml:"severities" json:"severities,omitempty"`
+	// MaxEvents flushes the digest early once this many events are pending.
+	MaxEvents int `toml:"max_events" json:"max_events,omitempty"`
+}
+
+// Enabled reports whether digests are configured.
+func (c DigestConfig) Enabled() bool {
+	return c.Interval > 0
+}
+
+// batches reports whether events of eventType are held for the digest.
+func (c DigestConfig) batches(eventType string) bool {
+	if !c.Enabled() {
+		return false
+	}
+	sev := classifySeverity(eventType)
+	if len(c.Severities) == 0 {
+		return sev == severityInfo || sev == severitySuccess
+	}
+	for _, s := range c.Severities {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "warn" {
+			s = string(severityWarning)
+		}
+		if eventSeverity(s) == sev {
+			return true
+		}
+	}
+	return false
+}
+
+// digestBuffer holds a webhook's pending digest events.
+type digestBuffer struct {
+	events []Event
+	start  time.Time
+	timer  *time.Timer
+}
+
+// digests holds the pending buffers of all digest-enabled webhooks.
+type digests struct {
+	mu      sync.Mutex
+	buffers map[string]*digestBuffer // by webhook ID
+}
+
+// addToDigest buffers event for wh. The first event of a window arms a timer
+// that flushes the digest after the interval; reaching MaxEvents flushes it
+// at once.
+func (m *WebhookManager) addToDigest(wh *WebhookConfig, event Event) {
+	max := wh.Digest.MaxEvents
+	if max <= 0 {
+		max = DefaultDigestMaxEvents
+	}
+
+	m.digests.mu.Lock()
+	if m.digests.buffers == nil {
+		m.digests.buffers = make(map[string]*digestBuffer)
+	}
+	buf := m.digests.buffers[wh.ID]
+	if buf == nil {
+		buf = &digestBuffer{start: time.Now().UTC()}
+		m.digests.buffers[wh.ID] = buf
+		buf.timer = time.AfterFunc(wh.Digest.Interval, func() { m.flushDigest(wh, buf) })
+	}
+	buf.events = append(buf.events, event)
+	full := len(buf.events) >= max
+	m.digests.mu.Unlock()
+
+	if full {
+		m.flushDigest(wh, buf)
+	}
+}
+
+// takeDigest removes and returns the pending digest for a webhook. If want
+// is non-nil, only that buffer is taken, so a late timer cannot flush the
+// next window early.
+func (m *WebhookManager) takeDigest(id string, want *digestBuffer) *digestBuffer {
+	m.digests.mu.Lock()
+	defer m.digests.mu.Unlock()
+	buf := m.digests.buffers[id]
+	if buf == nil || (want != nil && buf != want) {
+		return nil
+	}
+	delete(m.digests.buffers, id)
+	buf.timer.Stop()
+	return buf
+}
+
+// flushDigest queues buf as a digest delivery to wh if it is still pending.
+func (m *WebhookManager) flushDigest(wh *WebhookConfig, buf *digestBuffer) {
+	buf = m.takeDigest(wh.ID, buf)
+	if buf == nil || len(buf.events) == 0 || !m.started.Load() {
+		return
+	}
+	event := buildDigestEvent(buf.events, buf.start, time.Now().UTC())
+	m.enqueue(Delivery{
+		ID:      fmt.Sprintf("%s_%s", event.ID, wh.ID),
+		Event:   event,
+		Webhook: wh,
+	})
+}
+
+// flushAllDigests sends every pending digest synchronously. It is used on
+// shutdown, when the workers are about to stop.
+func (m *WebhookManager) flushAllDigests() {
+	m.webhooksMu.RLock()
+	hooks := make(map[string]*WebhookConfig, len(m.webhooks))
+	for id, wh := range m.webhooks {
+		hooks[id] = wh
+	}
+	m.webhooksMu.RUnlock()
+
+	m.digests.mu.Lock()
+	ids := make([]string, 0, len(m.digests.buffers))
+	for id := range m.digests.buffers {
+		ids = append(ids, id)
+	}
+	m.digests.mu.Unlock()
+
+	for _, id := range ids {
+		buf := m.takeDigest(id, nil)
+		wh := hooks[id]
+		if buf == nil || wh == nil || len(buf.events) == 0 {
+			continue
+		}
+		event := buildDigestEvent(buf.events, buf.start, time.Now().UTC())
+		d := Delivery{ID: fmt.Sprintf("%s_%s", event.ID, wh.ID), Event: event, Webhook: wh}
+		m.processDelivery(&d)
+	}
+}
+
+// buildDigestEvent summarizes events collected between start and end.
+func buildDigestEvent(events []Event, start, end time.Time) Event {
+	counts := make(map[string]int)
+	sessions := make(map[string]bool)
+	for _, e := range events {
+		counts[e.Type]++
+		sessions[e.Session] = true
+	}
+	types := make([]string, 0, len(counts))
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if counts[types[i]] != counts[types[j]] {
+			return counts[types[i]] > counts[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[t], t))
+	}
+	noun := "events"
+	if len(events) == 1 {
+		noun = "event"
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "%d %s in %s: %s", len(events), noun, end.Sub(start).Round(time.Second), strings.Join(parts, ", "))
+	for i, e := range events {
+		if i == digestPreviewLines {
+			fmt.Fprintf(&msg, "\n… and %d more", len(events)-i)
+			break
+		}
+		line := e.Message
+		if line == "" {
+			line = e.Type
+		}
+		if e.Session != "" {
+			line = "[" + e.Session + "] " + line
+		}
+		msg.WriteString("\n• " + line)
+	}
+
+	digest := Event{
+		ID:        fmt.Sprintf("digest_%d", end.UnixNano()),
+		Type:      DigestEventType,
+		Timestamp: end,
+		Message:   msg.String(),
+		Details: map[string]string{
+			"count":        strconv.Itoa(len(events)),
+			"window_start": formatRFC3339(start),
+			"window_end":   formatRFC3339(end),
+		},
+		Digest: events,
+	}
+	if len(sessions) == 1 {
+		digest.Session = events[0].Session
+	}
+	return digest
+}
@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a webhook endpoint that records the events it receives.
+type collector struct {
+	mu     sync.Mutex
+	events []Event
+	got    chan struct{}
+}
+
+func newCollector(t *testing.T) (*collector, *httptest.Server) {
+	t.Helper()
+	c := &collector{got: make(chan struct{}, 100)}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ev Event
+		_ = json.Unmarshal(body, &ev)
+		c.mu.Lock()
+		c.events = append(c.events, ev)
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		c.got <- struct{}{}
+	}))
+	t.Cleanup(ts.Close)
+	return c, ts
+}
+
+func (c *collector) wait(t *testing.T, n int) []Event {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-c.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d of %d", i+1, n)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Event(nil), c.events...)
+}
+
+func TestDigestConfig_Batches(t *testing.T) {
+	t.Parallel()
+
+	def := DigestConfig{Interval: time.Minute}
+	for eventType, want := range map[string]bool{
+		"agent.completed":  true,  // success
+		"agent.started":    true,  // info
+		"agent.error":      false, // error
+		"agent.rate_limit": false, // warning
+	} {
+		if got := def.batches(eventType); got != want {
+			t.Errorf("default batches(%q) = %v, want %v", eventType, got, want)
+		}
+	}
+
+	custom := DigestConfig{Interval: time.Minute, Severities: []string{"warn"}}
+	if !custom.batches("agent.rate_limit") || custom.batches("agent.started") {
+		t.Error("custom severities not honored")
+	}
+	if (DigestConfig{}).batches("agent.started") {
+		t.Error("disabled digest batched an event")
+	}
+}
+
+func TestDispatch_DigestBatchesLowSeverity(t *testing.T) {
+	t.Parallel()
+
+	c, ts := newCollector(t)
+	m := NewManager(ManagerConfig{QueueSize: 10, WorkerCount: 2})
+	if err := m.Register(WebhookConfig{
+		ID:      "digest",
+		URL:     ts.URL,
+		Enabled: true,
+		Digest:  DigestConfig{Interval: 200 * time.Millisecond},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.Stop()
+
+	for _, ev := range []Event{
+		{Type: "agent.started", Session: "s", Message: "cc_1 started"},
+		{Type: "agent.completed", Session: "s", Message: "cc_1 done"},
+		{Type: "agent.error", Session: "s", Message: "cc_2 failed"},
+		{Type: "agent.completed", Session: "s", Message: "cod_1 done"},
+	} {
+		if err := m.Dispatch(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The error passes straight through; the rest arrive as one digest.
+	events := c.wait(t, 2)
+	if events[0].Type != "agent.error" {
+		t.Errorf("first delivery = %s, want agent.error", events[0].Type)
+	}
+	digest := events[1]
+	if digest.Type != DigestEventType || len(digest.Digest) != 3 || digest.Details["count"] != "3" {
+		t.Fatalf("digest = %+v", digest)
+	}
+	if digest.Session != "s" || !strings.HasPrefix(digest.Message, "3 events in ") ||
+		!strings.Contains(digest.Message, "2 agent.completed, 1 agent.started") ||
+		!strings.Contains(digest.Message, "• [s] cc_1 started") {
+		t.Errorf("digest message = %q", digest.Message)
+	}
+	if m.Stats().DigestPending != 0 {
+		t.Errorf("pending = %d after flush", m.Stats().DigestPending)
+	}
+}
+
+func TestDispatch_DigestFlushesAtMaxEventsAndStop(t *testing.T) {
+	t.Parallel()
+
+	c, ts := newCollector(t)
+	m := NewManager(ManagerConfig{QueueSize: 10, WorkerCount: 1})
+	if err := m.Register(WebhookConfig{
+		ID:      "digest",
+		URL:     ts.URL,
+		Enabled: true,
+		Digest:  DigestConfig{Interval: time.Hour, MaxEvents: 2},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = m.Dispatch(Event{Type: "agent.started", Message: "hi"})
+	}
+	if events := c.wait(t, 1); len(events[0].Digest) != 2 {
+		t.Fatalf("early flush = %+v", events[0])
+	}
+	if got := m.Stats().DigestPending; got != 1 {
+		t.Errorf("pending = %d, want 1", got)
+	}
+
+	// Stopping sends the partial digest.
+	if err := m.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	events := c.wait(t, 1)
+	if last := events[len(events)-1]; len(last.Digest) != 1 || last.Details["count"] != "1" {
+		t.Errorf("final digest = %+v", last)
+	}
+}
+
+func TestBuildDigestEvent_Preview(t *testing.T) {
+	t.Parallel()
+
+	var events []Event
+	for i := 0; i < digestPreviewLines+2; i++ {
+		events = append(events, Event{Type: "agent.idle", Session: "s" + string(rune('a'+i%2))})
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ev := buildDigestEvent(events, start, start.Add(15*time.Minute))
+	if !strings.HasPrefix(ev.Message, "12 events in 15m0s: 12 agent.idle") || !strings.Contains(ev.Message, "… and 2 more") {
+		t.Errorf("message = %q", ev.Message)
+	}
+	if ev.Session != "" {
+		t.Errorf("session = %q for mixed sessions", ev.Session)
+	}
+	if ev.Details["window_start"] != "2026-01-01T00:00:00Z" {
+		t.Errorf("details = %v", ev.Details)
+	}
+}
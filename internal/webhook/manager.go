@@ -44,6 +44,8 @@ type Event struct {
 	Agent     string            `json:"agent,omitempty"`
 	Message   string            `json:"message"`
 	Details   map[string]string `json:"details,omitempty"`
+	// Digest holds the batched events of a digest event.
+	Digest []Event `json:"digest,omitempty"`
 }
 
 // WebhookConfig holds configuration for a single webhook endpoint
@@ -68,6 +70,9 @@ type WebhookConfig struct {
 
 	// HMAC signing configuration
 	Secret string `toml:"secret" json:"secret,omitempty"` // HMAC-SHA256 secret
+
+	// Digest batches low-severity events into periodic summaries
+	Digest DigestConfig `toml:"digest" json:"digest,omitempty"`
 }
 
 // RetryConfig holds retry policy for a webhook
@@ -181,6 +186,9 @@ type WebhookManager struct {
 	deadLetters   []DeadLetter
 	deadLettersMu sync.Mutex
 
+	// Pending digests for webhooks with digests enabled
+	digests digests
+
 	// HTTP client with connection pooling
 	httpClient *http.Client
 
@@ -318,39 +326,47 @@ func (m *WebhookManager) Dispatch(event Event) error {
 		return nil // No webhooks registered for this event type
 	}
 
-	// Queue delivery for each matching webhook
+	// Queue delivery for each matching webhook, holding back events that
+	// go into the webhook's digest
 	for _, wh := range webhooks {
-		delivery := Delivery{
+		if wh.Digest.batches(event.Type) {
+			m.addToDigest(wh, event)
+			continue
+		}
+		m.enqueue(Delivery{
 			ID:      fmt.Sprintf("%s_%s", event.ID, wh.ID),
 			Event:   event,
 			Webhook: wh,
 			Attempt: 0,
-		}
+		})
+	}
+
+	return nil
+}
 
-		// Non-blocking send to queue
+// enqueue adds a delivery to the queue without blocking, dropping the oldest
+// delivery when the queue is full.
+func (m *WebhookManager) enqueue(delivery Delivery) {
+	select {
+	case m.queue <- delivery:
+		// Successfully queued
+	default:
+		// Queue full. Drop the oldest delivery so we keep the newest.
+		select {
+		case <-m.queue:
+			// Count actual dropped deliveries (oldest).
+			m.queueFull.Add(1)
+		default:
+			// Race: another worker/drainer freed space before we could drop.
+		}
 		select {
 		case m.queue <- delivery:
-			// Successfully queued
 		default:
-			// Queue full. Drop the oldest delivery so we keep the newest.
-			select {
-			case <-m.queue:
-				// Count actual dropped deliveries (oldest).
-				m.queueFull.Add(1)
-			default:
-				// Race: another worker/drainer freed space before we could drop.
-			}
-			select {
-			case m.queue <- delivery:
-			default:
-				// Queue still full. Drop the new delivery too.
-				m.queueFull.Add(1)
-				m.log("webhook queue full, dropping event %s for webhook %s", event.ID, wh.ID)
-			}
+			// Queue still full. Drop the new delivery too.
+			m.queueFull.Add(1)
+			m.log("webhook queue full, dropping event %s for webhook %s", delivery.Event.ID, delivery.Webhook.ID)
 		}
 	}
-
-	return nil
 }
 
 func (m *WebhookManager) sanitizeEvent(event Event) Event {
@@ -410,6 +426,7 @@ func (m *WebhookManager) Stop() error {
 	}
 
 	m.log("stopping webhook manager...")
+	m.flushAllDigests()
 	m.cancel()
 
 	// Signal retry processor to wake up and exit
@@ -443,6 +460,7 @@ type Stats struct {
 	Failures        int64 `json:"total_failures"`
 	DroppedEvents   int64 `json:"dropped_events"`
 	WebhookCount    int   `json:"webhook_count"`
+	DigestPending   int   `json:"digest_pending"`
 }
 
 // Stats returns current manager statistics
@@ -459,6 +477,13 @@ func (m *WebhookManager) Stats() Stats {
 	deadLetterCount := len(m.deadLetters)
 	m.deadLettersMu.Unlock()
 
+	m.digests.mu.Lock()
+	digestPending := 0
+	for _, buf := range m.digests.buffers {
+		digestPending += len(buf.events)
+	}
+	m.digests.mu.Unlock()
+
 	return Stats{
 		QueueLength:     len(m.queue),
 		QueueCapacity:   cap(m.queue),
@@ -468,6 +493,7 @@ func (m *WebhookManager) Stats() Stats {
 		Failures:        m.failures.Load(),
 		DroppedEvents:   m.queueFull.Load(),
 		WebhookCount:    webhookCount,
+		DigestPending:   digestPending,
 	}
 }
 
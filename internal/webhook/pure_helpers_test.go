@@ -23,6 +23,7 @@ func TestIsWebhookDispatchType_ValidTypes(t *testing.T) {
 		events.WebhookAgentBusy,
 		events.WebhookAgentRateLimit,
 		events.WebhookAgentCompleted,
+		events.WebhookAgentProgress,
 		events.WebhookRotationNeeded,
 		events.WebhookSessionCreated,
 		events.WebhookSessionKilled,
@@ -30,6 +31,9 @@ func TestIsWebhookDispatchType_ValidTypes(t *testing.T) {
 		events.WebhookBeadAssigned,
 		events.WebhookBeadCompleted,
 		events.WebhookBeadFailed,
+		events.WebhookTaskCandidateComplete,
+		events.WebhookPromptUndelivered,
+		events.WebhookStorageLowDisk,
 		events.WebhookHealthDegraded,
 	}
 	for _, et := range validTypes {
@@ -0,0 +1,232 @@
+// Package winddown ends sessions that reach their maximum runtime. Ahead of
+// the deadline ntm stops assigning new tasks and asks agents to wrap up; at
+// the deadline it runs final verification, writes the session summary,
+// checkpoints and, if configured, kills the session. Progress is persisted so
+// each step runs once even if the process driving it restarts.
+package winddown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+// Phase is where a session is in its wind-down.
+type Phase string
+
+const (
+	PhaseRunning Phase = "running"  // Before the wrap-up window
+	PhaseWrapUp  Phase = "wrap_up"  // No new tasks; agents asked to wrap up
+	PhaseFinal   Phase = "final"    // Deadline reached; finalizing
+	PhaseDone    Phase = "finished" // Finalized
+)
+
+// Step names, in the order they run.
+const (
+	StepStopAssigning = "stop_assigning"
+	StepWrapUp        = "wrap_up"
+	StepVerify        = "verify"
+	StepSummarize     = "summarize"
+	StepCheckpoint    = "checkpoint"
+	StepKill          = "kill"
+)
+
+// Schedule is a session's runtime limit.
+type Schedule struct {
+	StartedAt  time.Time
+	MaxRuntime time.Duration // 0 = no limit
+	WrapUp     time.Duration // Lead time before the deadline
+}
+
+// Deadline returns when the session reaches its maximum runtime, or the zero
+// time without a limit.
+func (s Schedule) Deadline() time.Time {
+	if s.MaxRuntime <= 0 || s.StartedAt.IsZero() {
+		return time.Time{}
+	}
+	return s.StartedAt.Add(s.MaxRuntime)
+}
+
+// PhaseAt returns the phase the session should be in at now.
+func (s Schedule) PhaseAt(now time.Time) Phase {
+	deadline := s.Deadline()
+	switch {
+	case deadline.IsZero():
+		return PhaseRunning
+	case !now.Before(deadline):
+		return PhaseFinal
+	case !now.Before(deadline.Add(-s.WrapUp)):
+		return PhaseWrapUp
+	}
+	return PhaseRunning
+}
+
+// StepResult records one step of the wind-down.
+type StepResult struct {
+	Name   string    `json:"name"`
+	OK     bool      `json:"ok"`
+	Detail string    `json:"detail,omitempty"` // Summary path, checkpoint ID, ...
+	Error  string    `json:"error,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// State is the persisted wind-down progress of a session.
+type State struct {
+	Session    string       `json:"session"`
+	Deadline   time.Time    `json:"deadline"`
+	Phase      Phase        `json:"phase"`
+	WrapUpAt   *time.Time   `json:"wrap_up_at,omitempty"`
+	FinishedAt *time.Time   `json:"finished_at,omitempty"`
+	Steps      []StepResult `json:"steps,omitempty"`
+}
+
+// Stopping reports whether the session no longer takes new tasks.
+func (s *State) Stopping() bool {
+	return s != nil && s.WrapUpAt != nil
+}
+
+// Step returns the result of the named step, or nil if it has not run.
+func (s *State) Step(name string) *StepResult {
+	for i := range s.Steps {
+		if s.Steps[i].Name == name {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Path returns where the session's wind-down state is kept.
+func Path(session string) string {
+	dir, err := util.SessionsDir()
+	if err != nil {
+		dir = filepath.Join(os.TempDir(), "ntm", "sessions")
+	}
+	return filepath.Join(dir, session, "winddown.json")
+}
+
+// Load reads the session's wind-down state, or returns nil if it has none.
+func Load(session string) (*State, error) {
+	data, err := os.ReadFile(Path(session))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read wind-down state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse wind-down state: %w", err)
+	}
+	return &st, nil
+}
+
+// Save writes the wind-down state.
+func Save(st *State) error {
+	path := Path(st.Session)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create wind-down dir: %w", err)
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal wind-down state: %w", err)
+	}
+	if err := util.AtomicWriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write wind-down state: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the session's wind-down state.
+func Remove(session string) error {
+	if err := os.Remove(Path(session)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Steps are the actions of a wind-down. A nil step is skipped. Steps that
+// return a detail (a path or ID) have it recorded in the state.
+type Steps struct {
+	StopAssigning func(ctx context.Context) error
+	WrapUp        func(ctx context.Context) error
+	Verify        func(ctx context.Context) (string, error)
+	Summarize     func(ctx context.Context) (string, error)
+	Checkpoint    func(ctx context.Context) (string, error)
+	Kill          func(ctx context.Context) error
+}
+
+// Advance runs the steps that are due at now and have not run yet, and
+// returns the steps it ran. The wrap-up steps run once the session enters
+// the wrap-up window; the final steps run at the deadline, in order, each
+// regardless of earlier failures, except that the session is only killed
+// after a successful checkpoint.
+func Advance(ctx context.Context, st *State, sched Schedule, steps Steps, now time.Time) []StepResult {
+	if st.FinishedAt != nil {
+		return nil
+	}
+	st.Deadline = sched.Deadline()
+	phase := sched.PhaseAt(now)
+
+	var ran []StepResult
+	run := func(name string, fn func(ctx context.Context) (string, error)) bool {
+		if fn == nil || st.Step(name) != nil {
+			return true
+		}
+		detail, err := fn(ctx)
+		res := StepResult{Name: name, OK: err == nil, Detail: detail, At: now.UTC()}
+		if err != nil {
+			res.Error = err.Error()
+		}
+		st.Steps = append(st.Steps, res)
+		ran = append(ran, res)
+		return res.OK
+	}
+	noDetail := func(fn func(ctx context.Context) error) func(ctx context.Context) (string, error) {
+		if fn == nil {
+			return nil
+		}
+		return func(ctx context.Context) (string, error) { return "", fn(ctx) }
+	}
+
+	if phase == PhaseRunning {
+		st.Phase = PhaseRunning
+		return nil
+	}
+
+	if st.WrapUpAt == nil {
+		at := now.UTC()
+		st.WrapUpAt = &at
+		run(StepStopAssigning, noDetail(steps.StopAssigning))
+		// Agents get no wrap-up prompt when the window was missed entirely.
+		if phase == PhaseWrapUp {
+			run(StepWrapUp, noDetail(steps.WrapUp))
+		}
+	}
+	st.Phase = PhaseWrapUp
+	if phase == PhaseWrapUp {
+		return ran
+	}
+
+	st.Phase = PhaseFinal
+	run(StepVerify, steps.Verify)
+	run(StepSummarize, steps.Summarize)
+	checkpointed := run(StepCheckpoint, steps.Checkpoint)
+	if checkpointed {
+		run(StepKill, noDetail(steps.Kill))
+	} else if steps.Kill != nil && st.Step(StepKill) == nil {
+		res := StepResult{Name: StepKill, Error: "skipped: checkpoint failed", At: now.UTC()}
+		st.Steps = append(st.Steps, res)
+		ran = append(ran, res)
+	}
+
+	at := now.UTC()
+	st.FinishedAt = &at
+	st.Phase = PhaseDone
+	return ran
+}
@@ -0,0 +1,135 @@
+package winddown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/util"
+)
+
+func TestSchedule_PhaseAt(t *testing.T) {
+	start := time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC)
+	s := Schedule{StartedAt: start, MaxRuntime: 8 * time.Hour, WrapUp: 30 * time.Minute}
+
+	tests := []struct {
+		at   time.Time
+		want Phase
+	}{
+		{start.Add(time.Hour), PhaseRunning},
+		{start.Add(7*time.Hour + 30*time.Minute), PhaseWrapUp},
+		{start.Add(8 * time.Hour), PhaseFinal},
+		{start.Add(20 * time.Hour), PhaseFinal},
+	}
+	for _, tt := range tests {
+		if got := s.PhaseAt(tt.at); got != tt.want {
+			t.Errorf("PhaseAt(%v) = %s, want %s", tt.at.Sub(start), got, tt.want)
+		}
+	}
+
+	if got := (Schedule{StartedAt: start}).PhaseAt(start.Add(1000 * time.Hour)); got != PhaseRunning {
+		t.Errorf("no limit: PhaseAt = %s, want running", got)
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	start := time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC)
+	sched := Schedule{StartedAt: start, MaxRuntime: 2 * time.Hour, WrapUp: 15 * time.Minute}
+
+	var calls []string
+	step := func(name string) func(context.Context) error {
+		return func(context.Context) error { calls = append(calls, name); return nil }
+	}
+	detail := func(name, out string) func(context.Context) (string, error) {
+		return func(context.Context) (string, error) { calls = append(calls, name); return out, nil }
+	}
+	steps := Steps{
+		StopAssigning: step(StepStopAssigning),
+		WrapUp:        step(StepWrapUp),
+		Verify:        detail(StepVerify, "2/2 checks passed"),
+		Summarize:     detail(StepSummarize, "/tmp/summary.md"),
+		Checkpoint:    detail(StepCheckpoint, "cp-1"),
+		Kill:          step(StepKill),
+	}
+
+	st := &State{Session: "s1"}
+	if ran := Advance(context.Background(), st, sched, steps, start.Add(time.Hour)); len(ran) != 0 || st.Stopping() {
+		t.Fatalf("before the window: ran %v, state %+v", ran, st)
+	}
+
+	Advance(context.Background(), st, sched, steps, start.Add(110*time.Minute))
+	if !st.Stopping() || st.Phase != PhaseWrapUp {
+		t.Fatalf("in the window: state %+v", st)
+	}
+	// Steps run once.
+	Advance(context.Background(), st, sched, steps, start.Add(115*time.Minute))
+	if len(calls) != 2 {
+		t.Fatalf("calls after wrap-up = %v", calls)
+	}
+
+	Advance(context.Background(), st, sched, steps, start.Add(2*time.Hour))
+	want := []string{StepStopAssigning, StepWrapUp, StepVerify, StepSummarize, StepCheckpoint, StepKill}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls = %v, want %v", calls, want)
+		}
+	}
+	if st.Phase != PhaseDone || st.FinishedAt == nil || st.Step(StepCheckpoint).Detail != "cp-1" {
+		t.Errorf("final state %+v", st)
+	}
+	if ran := Advance(context.Background(), st, sched, steps, start.Add(3*time.Hour)); ran != nil {
+		t.Errorf("ran %v after finishing", ran)
+	}
+}
+
+func TestAdvance_NoKillWithoutCheckpoint(t *testing.T) {
+	start := time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC)
+	sched := Schedule{StartedAt: start, MaxRuntime: time.Hour, WrapUp: 10 * time.Minute}
+
+	killed, wrapped := false, false
+	steps := Steps{
+		WrapUp:     func(context.Context) error { wrapped = true; return nil },
+		Checkpoint: func(context.Context) (string, error) { return "", errors.New("disk full") },
+		Kill:       func(context.Context) error { killed = true; return nil },
+	}
+	st := &State{Session: "s1"}
+	// The daemon was down for the whole wrap-up window.
+	Advance(context.Background(), st, sched, steps, start.Add(2*time.Hour))
+
+	if killed {
+		t.Error("session killed after a failed checkpoint")
+	}
+	if wrapped {
+		t.Error("wrap-up prompt sent after the deadline")
+	}
+	if k := st.Step(StepKill); k == nil || k.OK {
+		t.Errorf("kill step = %+v, want recorded as skipped", k)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	util.SetSessionsDir(t.TempDir())
+	t.Cleanup(func() { util.SetSessionsDir("") })
+
+	if st, err := Load("s1"); err != nil || st != nil {
+		t.Fatalf("Load() missing = %+v, %v", st, err)
+	}
+	at := time.Now().UTC().Truncate(time.Second)
+	if err := Save(&State{Session: "s1", Phase: PhaseWrapUp, WrapUpAt: &at}); err != nil {
+		t.Fatal(err)
+	}
+	st, err := Load("s1")
+	if err != nil || !st.Stopping() || st.Phase != PhaseWrapUp {
+		t.Fatalf("Load() = %+v, %v", st, err)
+	}
+	if err := Remove("s1"); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := Load("s1"); st != nil {
+		t.Error("state still present after Remove")
+	}
+}
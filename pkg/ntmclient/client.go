@@ -0,0 +1,260 @@
+package ntmclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultBaseURL is the address 'ntm serve' listens on by default.
+const DefaultBaseURL = "http://127.0.0.1:7337"
+
+// maxErrorBody bounds how much of an unparseable error response is kept.
+const maxErrorBody = 4 << 10
+
+// Client calls the REST API of 'ntm serve'. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	apiKey     string
+	token      string
+	userAgent  string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. The event stream
+// needs a client without an overall timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithAPIKey authenticates with an API key (X-API-Key header).
+func WithAPIKey(key string) Option {
+	return func(c *Client) { c.apiKey = key }
+}
+
+// WithBearerToken authenticates with an OIDC bearer token.
+func WithBearerToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the server at baseURL, e.g. DefaultBaseURL.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parse base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("base URL %q: scheme must be http or https", baseURL)
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{},
+		userAgent:  "ntmclient",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Health reports whether the server is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.Do(ctx, http.MethodGet, "/api/v1/health", nil, nil)
+}
+
+// Version returns the server version.
+func (c *Client) Version(ctx context.Context) (*VersionInfo, error) {
+	var out VersionInfo
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/version", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeepHealth runs the server's component health probes.
+func (c *Client) DeepHealth(ctx context.Context) (*DeepHealthOutput, error) {
+	var out DeepHealthOutput
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/robot/health", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSessions returns a page of sessions from the state store.
+func (c *Client) ListSessions(ctx context.Context, page PageOptions) (*SessionsPage, error) {
+	q := url.Values{}
+	if page.Limit > 0 {
+		q.Set("limit", strconv.Itoa(page.Limit))
+	}
+	if page.Cursor != "" {
+		q.Set("cursor", page.Cursor)
+	}
+	path := "/api/v1/sessions"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var out SessionsPage
+	if err := c.Do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSession returns one session from the state store.
+func (c *Client) GetSession(ctx context.Context, id string) (*Session, error) {
+	var out struct {
+		Session *Session `json:"session"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/sessions/"+url.PathEscape(id), nil, &out); err != nil {
+		return nil, err
+	}
+	if out.Session == nil {
+		return nil, &Error{StatusCode: http.StatusNotFound, Code: "NOT_FOUND", Message: "session not found"}
+	}
+	return out.Session, nil
+}
+
+// ListAgents returns the agents recorded for a session.
+func (c *Client) ListAgents(ctx context.Context, session string) ([]Agent, error) {
+	var out struct {
+		Agents []Agent `json:"agents"`
+	}
+	if err := c.Do(ctx, http.MethodGet, "/api/v1/sessions/"+url.PathEscape(session)+"/agents", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Agents, nil
+}
+
+// Spawn adds agents to a session, creating it if needed.
+func (c *Client) Spawn(ctx context.Context, session string, req SpawnRequest) (*SpawnOutput, error) {
+	var out SpawnOutput
+	if err := c.Do(ctx, http.MethodPost, agentsPath(session, "spawn"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Send delivers a message to agents in a session.
+func (c *Client) Send(ctx context.Context, session string, req SendRequest) (*SendOutput, error) {
+	var out SendOutput
+	if err := c.Do(ctx, http.MethodPost, agentsPath(session, "send"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Interrupt sends Ctrl+C to agents in a session, optionally followed by a
+// new message.
+func (c *Client) Interrupt(ctx context.Context, session string, req InterruptRequest) (*InterruptOutput, error) {
+	var out InterruptOutput
+	if err := c.Do(ctx, http.MethodPost, agentsPath(session, "interrupt"), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func agentsPath(session, action string) string {
+	return "/api/v1/sessions/" + url.PathEscape(session) + "/agents/" + action
+}
+
+// Do sends a request to path (relative to the base URL) with in encoded as
+// the JSON body, and decodes a successful response into out. Either may be
+// nil. Error responses are returned as *Error.
+func (c *Client) Do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse path: %w", err)
+	}
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + ref.Path
+	u.RawPath = c.baseURL.EscapedPath() + ref.EscapedPath()
+	u.RawQuery = ref.RawQuery
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return req, nil
+}
+
+// decodeError reads an error response, which is normally the serve error
+// envelope.
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	e := &Error{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(data, e); err != nil || (e.Code == "" && e.Message == "") {
+		e.Message = strings.TrimSpace(string(data))
+		if e.Message == "" {
+			e.Message = http.StatusText(resp.StatusCode)
+		}
+	}
+	if e.Remediation != nil && e.Remediation.RetryAfterSeconds == 0 {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			e.Remediation.RetryAfterSeconds = secs
+		}
+	}
+	return e
+}
@@ -0,0 +1,185 @@
+package ntmclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dicklesworthstone/ntm/internal/serve"
+)
+
+func TestNew_RejectsBadURL(t *testing.T) {
+	t.Parallel()
+	if _, err := New("ftp://example.com"); err == nil {
+		t.Error("expected error for non-http scheme")
+	}
+	c, err := New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.baseURL.String() != DefaultBaseURL {
+		t.Errorf("baseURL = %s", c.baseURL)
+	}
+}
+
+func TestClient_SendEncodesRequestAndAuth(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/prefix/api/v1/sessions/my%20proj%2Fx/agents/send" {
+			t.Errorf("request = %s %s", r.Method, r.URL.EscapedPath())
+		}
+		if r.Header.Get("X-API-Key") != "k1" {
+			t.Errorf("X-API-Key = %q", r.Header.Get("X-API-Key"))
+		}
+		var req SendRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Message != "hi" || len(req.Panes) != 1 {
+			t.Errorf("body = %+v", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":true,"session":"my proj/x","successful":["1"],"failed":[],"message_preview":"hi"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL+"/prefix/", WithAPIKey("k1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := c.Send(context.Background(), "my proj/x", SendRequest{Message: "hi", Panes: []string{"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success || len(out.Successful) != 1 || out.MessagePreview != "hi" {
+		t.Errorf("out = %+v", out)
+	}
+}
+
+func TestClient_ErrorEnvelope(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"success":false,"error":"slow down","error_code":"RATE_LIMITED","error_id":"NTM-E402",` +
+			`"request_id":"req-1","remediation":{"action":"wait","retryable":true,"guidance":"wait"}}`))
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL, WithBearerToken("tok"))
+	_, err := c.Version(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Code != "RATE_LIMITED" || apiErr.RequestID != "req-1" {
+		t.Errorf("err = %+v", apiErr)
+	}
+	if !apiErr.Retryable() || apiErr.RetryAfter() != 30*time.Second {
+		t.Errorf("retryable=%v after=%v", apiErr.Retryable(), apiErr.RetryAfter())
+	}
+	if ErrorCode(err) != "RATE_LIMITED" || !strings.Contains(err.Error(), "NTM-E402") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
+
+func TestClient_NonJSONError(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	err := c.Health(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Message != "bad gateway" {
+		t.Errorf("err = %#v", err)
+	}
+}
+
+func TestClient_ListSessionsPaging(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("cursor"); got != "abc" || r.URL.Query().Get("limit") != "5" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"success":true,"sessions":[{"id":"s1","name":"proj"}],"count":1,"has_more":true,"next_cursor":"def"}`))
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	page, err := c.ListSessions(context.Background(), PageOptions{Limit: 5, Cursor: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Sessions) != 1 || page.Sessions[0].Name != "proj" || !page.HasMore || page.NextCursor != "def" {
+		t.Errorf("page = %+v", page)
+	}
+}
+
+func TestClient_Events(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("session") != "proj" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: connected\ndata: {\"status\":\"connected\",\"time\":\"2026-01-02T03:04:05Z\"}\n\n" +
+			": comment\n" +
+			"event: agent.send\ndata: {\"type\":\"agent.send\",\"session\":\"proj\",\"timestamp\":\"2026-01-02T03:04:06Z\"}\n\n"))
+	}))
+	defer srv.Close()
+
+	c, _ := New(srv.URL)
+	var got []Event
+	err := c.Events(context.Background(), EventsOptions{Session: "proj"}, func(ev Event) error {
+		got = append(got, ev)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("events = %+v", got)
+	}
+	if got[0].Type != "connected" || got[0].Timestamp.IsZero() {
+		t.Errorf("first = %+v", got[0])
+	}
+	if got[1].Type != "agent.send" || got[1].Session != "proj" || len(got[1].Data) == 0 {
+		t.Errorf("second = %+v", got[1])
+	}
+}
+
+// The request structs mirror the server's; their JSON fields must match.
+func TestRequestTypesMatchServer(t *testing.T) {
+	t.Parallel()
+	pairs := []struct {
+		client, server any
+	}{
+		{SpawnRequest{}, serve.AgentSpawnRequest{}},
+		{SendRequest{}, serve.AgentSendRequest{}},
+		{InterruptRequest{}, serve.AgentInterruptRequest{}},
+	}
+	for _, p := range pairs {
+		if got, want := jsonTags(p.client), jsonTags(p.server); !reflect.DeepEqual(got, want) {
+			t.Errorf("%T fields %v, server %T has %v", p.client, got, p.server, want)
+		}
+	}
+}
+
+func jsonTags(v any) []string {
+	t := reflect.TypeOf(v)
+	tags := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		tags = append(tags, t.Field(i).Tag.Get("json"))
+	}
+	return tags
+}
@@ -0,0 +1,26 @@
+// Package ntmclient lets Go programs control ntm without shelling out and
+// parsing JSON by hand.
+//
+// Two transports are provided:
+//
+//   - Client talks to a running 'ntm serve' over its REST API and follows
+//     its server-sent event stream.
+//   - Robot runs the ntm binary in robot mode and decodes its JSON output.
+//
+// Both return the same typed responses, which are the structs the robot
+// schemas ('ntm --robot-schema=all') are generated from, and both report
+// failures as *Error carrying the NTM-Exxx error taxonomy.
+//
+//	c, err := ntmclient.New("http://127.0.0.1:7337", ntmclient.WithAPIKey(key))
+//	if err != nil {
+//		return err
+//	}
+//	out, err := c.Send(ctx, "myproject", ntmclient.SendRequest{Message: "run the tests"})
+//	var apiErr *ntmclient.Error
+//	if errors.As(err, &apiErr) && apiErr.Retryable() {
+//		// back off and retry
+//	}
+//
+//	r := ntmclient.NewRobot()
+//	status, err := r.Status(ctx)
+package ntmclient
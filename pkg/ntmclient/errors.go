@@ -0,0 +1,68 @@
+package ntmclient
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Error is a failure reported by ntm, over REST or from a robot command.
+type Error struct {
+	// StatusCode is the HTTP status, or 0 for robot commands.
+	StatusCode int `json:"-"`
+	// Code is the machine-readable error code, e.g. SESSION_NOT_FOUND.
+	Code string `json:"error_code,omitempty"`
+	// ErrorID is the stable NTM-Exxx identifier of Code.
+	ErrorID     string       `json:"error_id,omitempty"`
+	Message     string       `json:"error,omitempty"`
+	Hint        string       `json:"hint,omitempty"`
+	Remediation *Remediation `json:"remediation,omitempty"`
+	RequestID   string       `json:"request_id,omitempty"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = "request failed"
+	}
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s: %s", e.Code, msg)
+	}
+	if e.ErrorID != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.ErrorID)
+	}
+	return msg
+}
+
+// Retryable reports whether repeating the request can succeed.
+func (e *Error) Retryable() bool {
+	return e.Remediation != nil && e.Remediation.Retryable
+}
+
+// RetryAfter is the suggested wait before retrying, or 0 if unknown.
+func (e *Error) RetryAfter() time.Duration {
+	if e.Remediation == nil {
+		return 0
+	}
+	return time.Duration(e.Remediation.RetryAfterSeconds) * time.Second
+}
+
+// ErrorCode returns the ntm error code of err, or "" if err is not an *Error.
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// envelopeError converts a failed robot envelope into an *Error.
+func envelopeError(r RobotResponse) *Error {
+	return &Error{
+		Code:        r.ErrorCode,
+		ErrorID:     r.ErrorID,
+		Message:     r.Error,
+		Hint:        r.Hint,
+		Remediation: r.Remediation,
+	}
+}
@@ -0,0 +1,122 @@
+package ntmclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxEventLine bounds a single line of the event stream.
+const maxEventLine = 1 << 20
+
+// Event is one server-sent event from GET /events.
+type Event struct {
+	// Type is the event name, e.g. "connected" or "agent.send".
+	Type      string    `json:"type"`
+	Session   string    `json:"session,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Data is the raw JSON payload of the event.
+	Data json.RawMessage `json:"-"`
+}
+
+// EventsOptions filters the event stream.
+type EventsOptions struct {
+	Session string // only events for this session
+}
+
+// Events follows the server's event stream, calling fn for each event
+// until ctx is done, the server closes the stream, or fn returns an error.
+// It returns nil when ctx is canceled.
+func (c *Client) Events(ctx context.Context, opts EventsOptions, fn func(Event) error) error {
+	path := "/events"
+	if opts.Session != "" {
+		path += "?" + url.Values{"session": {opts.Session}}.Encode()
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+
+	err = readEvents(resp.Body, fn)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// readEvents parses a text/event-stream body.
+func readEvents(r io.Reader, fn func(Event) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), maxEventLine)
+
+	var name string
+	var data []string
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if len(data) > 0 {
+				if err := fn(parseEvent(name, strings.Join(data, "\n"))); err != nil {
+					return err
+				}
+			}
+			name, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("read event stream: %w", err)
+	}
+	return nil
+}
+
+func parseEvent(name, data string) Event {
+	ev := Event{Data: json.RawMessage(data)}
+	var fields struct {
+		Type      string `json:"type"`
+		Session   string `json:"session"`
+		Timestamp string `json:"timestamp"`
+		Time      string `json:"time"`
+	}
+	if json.Unmarshal([]byte(data), &fields) == nil {
+		ev.Type = fields.Type
+		ev.Session = fields.Session
+		ts := fields.Timestamp
+		if ts == "" {
+			ts = fields.Time
+		}
+		ev.Timestamp, _ = time.Parse(time.RFC3339, ts)
+	} else {
+		ev.Data = nil
+	}
+	if name != "" {
+		ev.Type = name
+	}
+	return ev
+}
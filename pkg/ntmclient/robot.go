@@ -0,0 +1,182 @@
+package ntmclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// maxStderr bounds the stderr kept for error messages.
+const maxStderr = 4 << 10
+
+// Robot runs the ntm binary in robot mode and decodes its JSON output.
+// The zero value runs "ntm" from PATH in the current directory.
+type Robot struct {
+	// Path is the ntm binary; empty means "ntm" looked up in PATH.
+	Path string
+	// Dir is the working directory; empty means the current directory.
+	Dir string
+	// Env is added to the current environment.
+	Env []string
+}
+
+// NewRobot returns a Robot that runs ntm from PATH.
+func NewRobot() *Robot {
+	return &Robot{}
+}
+
+// Status returns sessions, panes, and agent states (--robot-status).
+func (r *Robot) Status(ctx context.Context) (*StatusOutput, error) {
+	var out StatusOutput
+	if err := r.Run(ctx, &out, "--robot-status"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TailOptions configures Tail.
+type TailOptions struct {
+	Lines int      // lines per pane; 0 = ntm default
+	Panes []string // pane indices; empty = all
+}
+
+// Tail captures recent pane output (--robot-tail).
+func (r *Robot) Tail(ctx context.Context, session string, opts TailOptions) (*TailOutput, error) {
+	args := []string{"--robot-tail=" + session}
+	if opts.Lines > 0 {
+		args = append(args, "--lines="+strconv.Itoa(opts.Lines))
+	}
+	if len(opts.Panes) > 0 {
+		args = append(args, "--panes="+strings.Join(opts.Panes, ","))
+	}
+	var out TailOutput
+	if err := r.Run(ctx, &out, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Send delivers a message to agents in a session (--robot-send). Only the
+// panes, agent type, and all-panes fields of req are used besides Message.
+func (r *Robot) Send(ctx context.Context, session string, req SendRequest) (*SendOutput, error) {
+	args := []string{"--robot-send=" + session, "--msg=" + req.Message}
+	if len(req.Panes) > 0 {
+		args = append(args, "--panes="+strings.Join(req.Panes, ","))
+	}
+	if len(req.AgentTypes) > 0 {
+		args = append(args, "--type="+strings.Join(req.AgentTypes, ","))
+	}
+	if req.All {
+		args = append(args, "--all")
+	}
+	var out SendOutput
+	if err := r.Run(ctx, &out, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Plan returns the dependency-aware task graph ('ntm robot plan').
+func (r *Robot) Plan(ctx context.Context) (*TaskGraphOutput, error) {
+	var out TaskGraphOutput
+	if err := r.Run(ctx, &out, "robot", "plan"); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Commands describes the CLI surface ('ntm robot commands'), with output
+// schemas embedded when schemas is set.
+func (r *Robot) Commands(ctx context.Context, schemas bool) (*CommandsOutput, error) {
+	args := []string{"robot", "commands"}
+	if schemas {
+		args = append(args, "--schemas")
+	}
+	var out CommandsOutput
+	if err := r.Run(ctx, &out, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Schema returns the JSON Schema of a robot output type, or of all types
+// for "all" (--robot-schema).
+func (r *Robot) Schema(ctx context.Context, schemaType string) (*SchemaOutput, error) {
+	var out SchemaOutput
+	if err := r.Run(ctx, &out, "--robot-schema="+schemaType); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Run executes ntm with args and decodes its JSON output into out, which
+// may be nil. A response with success=false is returned as *Error, whatever
+// the exit status.
+func (r *Robot) Run(ctx context.Context, out any, args ...string) error {
+	path := r.Path
+	if path == "" {
+		path = "ntm"
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = r.Dir
+	// Robot output must be JSON whatever the user's format settings.
+	cmd.Env = append(append(os.Environ(), r.Env...), "NTM_ROBOT_FORMAT=json")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &limitedBuffer{buf: &stderr, max: maxStderr}
+
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	data := bytes.TrimSpace(stdout.Bytes())
+	var env RobotResponse
+	if len(data) == 0 || json.Unmarshal(data, &env) != nil {
+		if runErr == nil {
+			return fmt.Errorf("ntm %s: output is not JSON", strings.Join(args, " "))
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = runErr.Error()
+		}
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			return fmt.Errorf("ntm %s: %s", strings.Join(args, " "), msg)
+		}
+		return runErr
+	}
+	if !env.Success && (env.Error != "" || env.ErrorCode != "") {
+		return envelopeError(env)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode ntm %s output: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// limitedBuffer keeps the first max bytes written to it.
+type limitedBuffer struct {
+	buf *bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
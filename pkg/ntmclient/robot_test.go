@@ -0,0 +1,79 @@
+package ntmclient
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// stubNTM writes a fake ntm that records its arguments and environment and
+// prints output, exiting with code.
+func stubNTM(t *testing.T, output string, code int) (*Robot, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub ntm uses sh")
+	}
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\n" +
+		"printf '%s\\n' \"$@\" > " + argsFile + "\n" +
+		"echo \"format=$NTM_ROBOT_FORMAT\" >> " + argsFile + "\n" +
+		"cat <<'EOF'\n" + output + "\nEOF\n" +
+		"echo 'stub stderr' >&2\n" +
+		"exit " + strconv.Itoa(code) + "\n"
+	path := filepath.Join(dir, "ntm")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return &Robot{Path: path, Env: []string{"NTM_ROBOT_FORMAT=toon"}}, argsFile
+}
+
+func TestRobot_TailArgsAndDecode(t *testing.T) {
+	r, argsFile := stubNTM(t, `{"success":true,"session":"proj","panes":{}}`, 0)
+
+	out, err := r.Tail(context.Background(), "proj", TailOptions{Lines: 50, Panes: []string{"1", "2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !out.Success || out.Session != "proj" {
+		t.Errorf("out = %+v", out)
+	}
+	data, _ := os.ReadFile(argsFile)
+	want := "--robot-tail=proj\n--lines=50\n--panes=1,2\nformat=json\n"
+	if string(data) != want {
+		t.Errorf("args = %q, want %q", data, want)
+	}
+}
+
+func TestRobot_FailureEnvelope(t *testing.T) {
+	// Robot commands print the envelope and may exit non-zero.
+	r, _ := stubNTM(t, `{"success":false,"error":"session not found: x","error_code":"SESSION_NOT_FOUND",`+
+		`"error_id":"NTM-E200","remediation":{"action":"check_target","retryable":false,"guidance":"Use 'ntm list'"}}`, 1)
+
+	_, err := r.Status(context.Background())
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *Error", err)
+	}
+	if apiErr.Code != "SESSION_NOT_FOUND" || apiErr.ErrorID != "NTM-E200" || apiErr.StatusCode != 0 || apiErr.Retryable() {
+		t.Errorf("err = %+v", apiErr)
+	}
+}
+
+func TestRobot_NonJSONOutput(t *testing.T) {
+	r, _ := stubNTM(t, "usage: ntm ...", 2)
+	err := r.Run(context.Background(), nil, "--bogus")
+	if err == nil || !strings.Contains(err.Error(), "stub stderr") {
+		t.Errorf("err = %v, want stderr in message", err)
+	}
+
+	r, _ = stubNTM(t, "not json", 0)
+	if err := r.Run(context.Background(), nil, "x"); err == nil {
+		t.Error("expected error for non-JSON output")
+	}
+}
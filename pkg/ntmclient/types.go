@@ -0,0 +1,78 @@
+package ntmclient
+
+import (
+	"github.com/Dicklesworthstone/ntm/internal/robot"
+	"github.com/Dicklesworthstone/ntm/internal/state"
+)
+
+// Responses are the structs the robot schemas are generated from, so they
+// match 'ntm --robot-schema' and the REST API field for field.
+type (
+	// RobotResponse is the envelope every robot response embeds.
+	RobotResponse = robot.RobotResponse
+	// Remediation is the recovery guidance attached to failures.
+	Remediation = robot.Remediation
+
+	StatusOutput       = robot.StatusOutput
+	TailOutput         = robot.TailOutput
+	SendOutput         = robot.SendOutput
+	SpawnOutput        = robot.SpawnOutput
+	InterruptOutput    = robot.InterruptOutput
+	DeepHealthOutput   = robot.DeepHealthOutput
+	TaskGraphOutput    = robot.TaskGraphOutput
+	CommandsOutput     = robot.CommandsOutput
+	SchemaOutput       = robot.SchemaOutput
+	CapabilitiesOutput = robot.CapabilitiesOutput
+
+	// Session is a session as recorded in the ntm state store.
+	Session = state.Session
+	// Agent is an agent as recorded in the ntm state store.
+	Agent = state.Agent
+)
+
+// SpawnRequest is the body of POST /api/v1/sessions/{id}/agents/spawn.
+type SpawnRequest struct {
+	CCCount   int    `json:"cc_count,omitempty"`
+	CodCount  int    `json:"cod_count,omitempty"`
+	GmiCount  int    `json:"gmi_count,omitempty"`
+	Preset    string `json:"preset,omitempty"`
+	WaitReady bool   `json:"wait_ready,omitempty"`
+	Label     string `json:"label,omitempty"`
+}
+
+// SendRequest is the body of POST /api/v1/sessions/{id}/agents/send.
+type SendRequest struct {
+	Panes      []string `json:"panes,omitempty"`
+	AgentTypes []string `json:"agent_types,omitempty"`
+	Message    string   `json:"message"`
+	All        bool     `json:"all,omitempty"`
+}
+
+// InterruptRequest is the body of POST /api/v1/sessions/{id}/agents/interrupt.
+type InterruptRequest struct {
+	Panes   []string `json:"panes,omitempty"`
+	Message string   `json:"message,omitempty"`
+	Force   bool     `json:"force,omitempty"`
+	NoWait  bool     `json:"no_wait,omitempty"`
+}
+
+// PageOptions selects a page of a list endpoint.
+type PageOptions struct {
+	Limit  int    // 0 = server default
+	Cursor string // next_cursor of the previous page
+}
+
+// SessionsPage is a page of GET /api/v1/sessions.
+type SessionsPage struct {
+	Sessions   []Session `json:"sessions"`
+	Count      int       `json:"count"`
+	HasMore    bool      `json:"has_more"`
+	NextCursor string    `json:"next_cursor"`
+}
+
+// VersionInfo is the response of GET /api/v1/version.
+type VersionInfo struct {
+	Version    string `json:"version"`
+	APIVersion string `json:"api_version"`
+	GoVersion  string `json:"go_version"`
+}